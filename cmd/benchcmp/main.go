@@ -0,0 +1,232 @@
+// Command benchcmp compares two runs of the repository benchmarks (the output
+// of `make bench`, i.e. go test -bench -benchmem) and fails when a benchmark
+// got slower than the threshold allows, so performance-sensitive changes can
+// be checked before they are merged:
+//
+//	make bench BENCH_OUT=old.txt  # on the base commit
+//	make bench BENCH_OUT=new.txt  # on the change
+//	go run ./cmd/benchcmp -threshold 10 old.txt new.txt
+//
+// Each benchmark is compared by the median of its runs (-count), per unit.
+// Only the -metrics units gate; the others are reported. Benchmarks found in
+// one file only are listed but never fail the comparison.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	Threshold float64
+	Metrics   string
+)
+
+func init() {
+	flag.Float64Var(&Threshold, "threshold", 10, "Largest allowed increase of a gated metric, in percent")
+	flag.StringVar(&Metrics, "metrics", "ns/op,allocs/op", "Comma-separated units that fail the comparison when they regress")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: benchcmp [flags] old.txt new.txt\n")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	before, err := parseFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	after, err := parseFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	gated := make(map[string]bool)
+	for _, unit := range strings.Split(Metrics, ",") {
+		if unit = strings.TrimSpace(unit); unit != "" {
+			gated[unit] = true
+		}
+	}
+	comparisons, missing := compare(before, after)
+	regressions := report(os.Stdout, comparisons, missing, gated, Threshold)
+	if regressions > 0 {
+		fmt.Printf("\n%d regression(s) above %.1f%%\n", regressions, Threshold)
+		os.Exit(1)
+	}
+}
+
+// results samples of each benchmark ("pkg/BenchmarkName") per unit
+type results map[string]map[string][]float64
+
+// benchLine a benchmark result line: name with the GOMAXPROCS suffix,
+// iterations, then value/unit pairs
+var benchLine = regexp.MustCompile(`^(Benchmark\S+?)(?:-\d+)?\s+\d+\s+(.+)$`)
+
+func parseFile(path string) (results, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("%s: no benchmark results", path)
+	}
+	return r, nil
+}
+
+// parse reads go test -bench output; the pkg: lines qualify the benchmark
+// names, as packages may have benchmarks of the same name
+func parse(r io.Reader) (results, error) {
+	res := make(results)
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = strings.TrimSpace(p)
+			continue
+		}
+		m := benchLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		fields := strings.Fields(m[2])
+		if len(fields)%2 != 0 {
+			continue
+		}
+		name := m[1]
+		if pkg != "" {
+			name = pkg + "/" + name
+		}
+		if res[name] == nil {
+			res[name] = make(map[string][]float64)
+		}
+		for i := 0; i < len(fields); i += 2 {
+			value, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				continue
+			}
+			unit := fields[i+1]
+			res[name][unit] = append(res[name][unit], value)
+		}
+	}
+	return res, scanner.Err()
+}
+
+// comparison medians of one benchmark metric in both runs
+type comparison struct {
+	name, unit string
+	old, new   float64
+}
+
+// delta the change in percent; an increase from zero counts as 100%
+func (c comparison) delta() float64 {
+	if c.old == 0 {
+		if c.new == 0 {
+			return 0
+		}
+		return 100
+	}
+	return (c.new - c.old) / c.old * 100
+}
+
+// slowdown how much worse the new run is in percent: throughput units (MB/s)
+// regress when they fall, the others when they rise
+func (c comparison) slowdown() float64 {
+	if strings.HasSuffix(c.unit, "/s") {
+		return -c.delta()
+	}
+	return c.delta()
+}
+
+// compare pairs the metrics of the benchmarks found in both runs, and lists
+// the benchmarks found in only one of them
+func compare(before, after results) ([]comparison, []string) {
+	var comparisons []comparison
+	var missing []string
+	for name, units := range before {
+		newUnits, ok := after[name]
+		if !ok {
+			missing = append(missing, name+" (old only)")
+			continue
+		}
+		for unit, samples := range units {
+			if newSamples, ok := newUnits[unit]; ok {
+				comparisons = append(comparisons, comparison{name: name, unit: unit, old: median(samples), new: median(newSamples)})
+			}
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			missing = append(missing, name+" (new only)")
+		}
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].name != comparisons[j].name {
+			return comparisons[i].name < comparisons[j].name
+		}
+		return comparisons[i].unit < comparisons[j].unit
+	})
+	sort.Strings(missing)
+	return comparisons, missing
+}
+
+func median(samples []float64) float64 {
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// report prints the comparisons and returns how many gated metrics regressed
+// by more than threshold percent
+func report(w io.Writer, comparisons []comparison, missing []string, gated map[string]bool, threshold float64) int {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "benchmark\tunit\told\tnew\tdelta\t\t")
+	regressions := 0
+	for _, c := range comparisons {
+		mark := ""
+		if gated[c.unit] && c.slowdown() > threshold {
+			mark = "REGRESSION"
+			regressions++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%+.1f%%\t%s\t\n", c.name, c.unit, formatValue(c.old), formatValue(c.new), c.delta(), mark)
+	}
+	tw.Flush()
+	if len(missing) > 0 {
+		fmt.Fprintln(w, "\nNot compared:")
+		for _, name := range missing {
+			fmt.Fprintln(w, "  "+name)
+		}
+	}
+	return regressions
+}
+
+func formatValue(v float64) string {
+	if v == float64(int64(v)) {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
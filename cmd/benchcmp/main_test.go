@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const benchOld = `goos: linux
+pkg: meta-file-system/database
+BenchmarkCreateIndexerFile/pebble-8   	    1000	    800000 ns/op	   16000 B/op	      60 allocs/op
+BenchmarkCreateIndexerFile/pebble-8   	    1000	    900000 ns/op	   16000 B/op	      60 allocs/op
+BenchmarkCreateIndexerFile/pebble-8   	    1000	   5000000 ns/op	   16000 B/op	      60 allocs/op
+BenchmarkRemoved-8                    	    1000	       100 ns/op
+PASS
+pkg: meta-file-system/indexer
+BenchmarkParseAllPINs/mvc-file-8      	     200	    300000 ns/op	  480000 B/op	     160 allocs/op
+`
+
+const benchNew = `pkg: meta-file-system/database
+BenchmarkCreateIndexerFile/pebble-8   	    1000	    850000 ns/op	   32000 B/op	      60 allocs/op
+BenchmarkCreateIndexerFile/pebble-8   	    1000	    870000 ns/op	   32000 B/op	      60 allocs/op
+pkg: meta-file-system/indexer
+BenchmarkParseAllPINs/mvc-file-8      	     200	    300000 ns/op	  480000 B/op	     200 allocs/op
+BenchmarkAdded-8                      	    1000	       100 ns/op
+`
+
+func TestCompare(t *testing.T) {
+	before, err := parse(strings.NewReader(benchOld))
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := parse(strings.NewReader(benchNew))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := before["meta-file-system/database/BenchmarkCreateIndexerFile/pebble"]["ns/op"]; len(got) != 3 {
+		t.Fatalf("parsed samples %v, want 3", got)
+	}
+
+	comparisons, missing := compare(before, after)
+	deltas := make(map[string]float64)
+	for _, c := range comparisons {
+		deltas[c.name[strings.LastIndex(c.name, "/Benchmark")+1:]+" "+c.unit] = c.delta()
+	}
+	// Medians: the 5 ms outlier does not count
+	if d := deltas["BenchmarkCreateIndexerFile/pebble ns/op"]; d < -4.5 || d > -4.4 {
+		t.Errorf("ns/op delta %.2f%%, want -4.4%%", d)
+	}
+	if d := deltas["BenchmarkCreateIndexerFile/pebble B/op"]; d != 100 {
+		t.Errorf("B/op delta %.2f%%, want 100%%", d)
+	}
+	if len(missing) != 2 || !strings.HasSuffix(missing[0], "BenchmarkRemoved (old only)") || !strings.HasSuffix(missing[1], "BenchmarkAdded (new only)") {
+		t.Errorf("missing = %v", missing)
+	}
+
+	// B/op doubled but is not gated; allocs/op of the parser rose by 25%
+	var out bytes.Buffer
+	gated := map[string]bool{"ns/op": true, "allocs/op": true}
+	if n := report(&out, comparisons, missing, gated, 10); n != 1 {
+		t.Errorf("%d regressions, want 1:\n%s", n, out.String())
+	}
+	if n := report(&out, comparisons, missing, gated, 30); n != 0 {
+		t.Errorf("%d regressions above 30%%, want 0", n)
+	}
+}
+
+func TestSlowdownOfThroughput(t *testing.T) {
+	if s := (comparison{unit: "MB/s", old: 100, new: 80}).slowdown(); s != 20 {
+		t.Errorf("MB/s 100 -> 80 slowdown %.1f%%, want 20%%", s)
+	}
+	if s := (comparison{unit: "ns/op", old: 100, new: 80}).slowdown(); s != -20 {
+		t.Errorf("ns/op 100 -> 80 slowdown %.1f%%, want -20%%", s)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"meta-file-system/conf"
+)
+
+func TestAcmeServerServesPublicRoutesOnly(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+	conf.Cfg = &conf.Config{}
+	conf.Cfg.Indexer.Domains.Acme = conf.IndexerAcmeConfig{Enabled: true, CacheDir: t.TempDir(), HttpsPort: "443", HttpPort: "80"}
+
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+	servers := newAcmeServers(api)
+	if len(servers) != 2 {
+		t.Fatalf("servers = %d, want 2", len(servers))
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/v1/files/abc", http.StatusOK},
+		{"/api/v1/admin/rescan", http.StatusNotFound},
+		{"/api/v1/admin", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "https://unmapped.example.com"+tt.path, nil)
+		servers[0].Handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
@@ -1,27 +1,37 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"meta-file-system/conf"
 	"meta-file-system/controller"
+	"meta-file-system/controller/respond"
 	"meta-file-system/database"
+	"meta-file-system/listener"
+	"meta-file-system/notify"
+	"meta-file-system/service/common_service/metaid"
+	"meta-file-system/service/common_service/metaid_protocols"
 	"meta-file-system/service/indexer_service"
+	"meta-file-system/shutdown"
 	"meta-file-system/storage"
+
+	"golang.org/x/crypto/acme/autocert"
 )
 
-var ENV string
+var (
+	ENV                  string
+	MigrateStorageLayout int
+	MigratePebbleLayout  bool
+)
 
 func init() {
 	flag.StringVar(&ENV, "env", "mainnet", "Environment: loc/mainnet/testnet")
+	flag.IntVar(&MigrateStorageLayout, "migrate-storage-layout", 0, "Relocate indexed blobs to this storage layout version and exit (run with the indexer stopped)")
+	flag.BoolVar(&MigratePebbleLayout, "migrate-pebble-layout", false, "Consolidate a Pebble data directory with one instance per collection into a single instance and exit (run with the indexer stopped)")
 }
 
 // @title           Meta File System Indexer API
@@ -37,33 +47,58 @@ func init() {
 // @license.url   http://www.apache.org/licenses/LICENSE-2.0.html
 
 // @host      localhost:7281
-// @BasePath  /api/v1
+// @BasePath  /api
 
 // @schemes https http
 
 func main() {
+	flag.Parse()
+	if MigrateStorageLayout > 0 {
+		runStorageLayoutMigration(MigrateStorageLayout)
+		return
+	}
+	if MigratePebbleLayout {
+		runPebbleLayoutMigration()
+		return
+	}
+
 	// Initialize all components
-	indexerService, srv, cleanup := initAll()
+	indexerService, router, cleanup := initAll()
 	defer cleanup()
 
-	// Start indexer service (in goroutine)
-	go indexerService.Start()
-	log.Println("Indexer service started successfully")
+	// Start indexer service (in goroutine); a mirror does not scan chains
+	if indexerService != nil {
+		go indexerService.Start()
+		log.Println("Indexer service started successfully")
+	}
 
-	// Start HTTP API service (in goroutine)
-	go startServer(srv)
+	// Start HTTP API service on every listener (indexer.listeners)
+	listeners, err := listener.Start("Indexer API service", conf.Cfg.Indexer.Listeners, router)
+	if err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
 	log.Println("Indexer API service started successfully")
 
-	// Wait for shutdown signal
-	waitForShutdown()
+	// Serve mapped custom domains over HTTPS (indexer.domains.acme)
+	acmeServers := startAcmeServers(router)
 
-	log.Println("Shutting down indexer service...")
+	// Components stopped together on shutdown, within shutdown.timeout_secs
+	shutdowns := shutdown.NewManager(time.Duration(conf.Cfg.Shutdown.TimeoutSecs) * time.Second)
+	if indexerService != nil {
+		shutdowns.Add("indexer service", indexerService.Shutdown)
+	}
+	shutdowns.Add("HTTP listeners", listeners.Shutdown)
+	for _, acmeSrv := range acmeServers {
+		shutdowns.Add("custom domain listener "+acmeSrv.Addr, acmeSrv.Shutdown)
+	}
 
-	// Stop indexer service
-	indexerService.Stop()
+	// Wait for shutdown signal; SIGHUP reloads the listener certificates
+	shutdown.WaitForSignal(listeners.ReloadCertificates)
 
-	// Gracefully shutdown HTTP service
-	shutdownServer(srv)
+	log.Println("Shutting down indexer service...")
+	if err := shutdowns.Shutdown(); err != nil {
+		log.Printf("Shutdown incomplete: %v", err)
+	}
 
 	log.Println("Server exited")
 }
@@ -83,7 +118,7 @@ func initEnv() {
 }
 
 // initAll initialize all components
-func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
+func initAll() (*indexer_service.IndexerService, http.Handler, func()) {
 	// Parse command line parameters
 	flag.Parse()
 
@@ -94,13 +129,38 @@ func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
 	if err := conf.InitConfig(); err != nil {
 		log.Fatalf("Failed to initialize config: %v", err)
 	}
+	if err := conf.Cfg.Validate(conf.ServiceIndexer); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 	log.Printf("Configuration loaded: env=%s, net=%s, port=%s", ENV, conf.Cfg.Net, conf.Cfg.IndexerPort)
 
+	// Apply protocol path filter and re-apply it whenever the config file changes
+	if err := metaid_protocols.SetPathFilter(conf.Cfg.Indexer.PathAllowlist, conf.Cfg.Indexer.PathDenylist); err != nil {
+		log.Fatalf("Invalid indexer path filter: %v", err)
+	}
+	conf.WatchConfig(func(runtime conf.RuntimeConfig) {
+		if err := metaid_protocols.SetPathFilter(runtime.PathAllowlist, runtime.PathDenylist); err != nil {
+			log.Printf("Ignoring invalid indexer path filter, keeping previous one: %v", err)
+		}
+	})
+
+	// ID addresses next to native addresses in responses (indexer.render_id_address)
+	respond.SetIDAddressRendering(conf.Cfg.Indexer.RenderIDAddress)
+
 	// Initialize database
 	if err := initDatabase(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
+	// MetaID derivation (metaid.derivation); genesis_txid records each
+	// address's first PIN in the indexer DB, which only Pebble implements
+	if err := metaid.SetDerivation(conf.Cfg.MetaID.Derivation, database.DB); err != nil {
+		log.Fatalf("Invalid metaid.derivation: %v", err)
+	}
+	if metaid.CurrentDerivation() == metaid.GenesisTxID && database.DBType(conf.Cfg.Database.IndexerType) != database.DBTypePebble {
+		log.Fatalf("metaid.derivation %s needs the pebble indexer database", metaid.GenesisTxID)
+	}
+
 	// Run schema migrations (Pebble: backfill extension/global_meta indexes when version < latest)
 	migrateSvc := indexer_service.NewMigrateService()
 	if err := migrateSvc.Run(); err != nil {
@@ -112,7 +172,13 @@ func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
 		log.Printf("⚠️  Redis initialization failed (cache will be disabled): %v", err)
 	}
 
-	// Initialize storage
+	// Operational alerts (notify.email / notify.telegram)
+	notify.Init("indexer")
+
+	// Initialize storage; a bad storage.path_template fails here, before any blob is written
+	if err := storage.ValidateLayout(); err != nil {
+		log.Fatalf("Invalid storage layout: %v", err)
+	}
 	stor, err := storage.NewStorage()
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
@@ -211,9 +277,12 @@ func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
 	// 	log.Println("[FIX]✅ FixUserInfoCollection completed successfully")
 	// }
 
-	// Create indexer service (multi-chain or single-chain)
+	// Create indexer service (mirror, multi-chain or single-chain)
 	var indexerService *indexer_service.IndexerService
-	if len(conf.Cfg.Indexer.Chains) > 0 {
+	if conf.Cfg.Indexer.Mirror.Enabled {
+		// Mirror mode: no block scanner; unknown PINs are fetched from upstream
+		log.Printf("Initializing in mirror mode, upstream: %s", conf.Cfg.Indexer.Mirror.Upstream)
+	} else if len(conf.Cfg.Indexer.Chains) > 0 {
 		// Multi-chain mode
 		log.Printf("Initializing in multi-chain mode with %d chains", len(conf.Cfg.Indexer.Chains))
 		indexerService, err = indexer_service.NewMultiChainIndexerService(stor, conf.Cfg.Indexer.Chains)
@@ -232,12 +301,6 @@ func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
 	// Setup indexer service router (pass indexerService for scanner access)
 	router := controller.SetupIndexerRouter(stor, indexerService)
 
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:    ":" + conf.Cfg.IndexerPort,
-		Handler: router,
-	}
-
 	// Return service instance and cleanup function
 	cleanup := func() {
 		if database.DB != nil {
@@ -248,7 +311,65 @@ func initAll() (*indexer_service.IndexerService, *http.Server, func()) {
 		}
 	}
 
-	return indexerService, srv, cleanup
+	return indexerService, router, cleanup
+}
+
+// runStorageLayoutMigration relocates indexed blobs to layout version and exits
+func runStorageLayoutMigration(version int) {
+	initEnv()
+	if err := conf.InitConfig(); err != nil {
+		log.Fatalf("Failed to initialize config: %v", err)
+	}
+	if err := initDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.DB.Close()
+
+	stor, err := storage.NewStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	result, err := indexer_service.NewStorageLayoutMigrator(stor).Migrate(version)
+	if err != nil {
+		log.Fatalf("Storage layout migration failed: %v", err)
+	}
+	if result.Failed > 0 {
+		log.Printf("⚠️  %d records were not migrated (see log above); run the migration again to retry", result.Failed)
+	}
+	if storage.CurrentLayout().Version() != version {
+		log.Printf("Set storage.layout_version: %d so newly indexed blobs use the same layout", version)
+	}
+}
+
+// runPebbleLayoutMigration consolidates the Pebble collections into a single
+// instance and exits
+func runPebbleLayoutMigration() {
+	initEnv()
+	if err := conf.InitConfig(); err != nil {
+		log.Fatalf("Failed to initialize config: %v", err)
+	}
+	if database.DBType(conf.Cfg.Database.IndexerType) != database.DBTypePebble {
+		log.Fatalf("-migrate-pebble-layout needs database.indexer_type: pebble (got %q)", conf.Cfg.Database.IndexerType)
+	}
+
+	result, err := database.MigratePebbleLayout(pebbleConfig())
+	if err != nil {
+		log.Fatalf("Pebble layout migration failed: %v", err)
+	}
+	log.Printf("✅ Migrated %d collections (%d keys) into a single Pebble instance", result.Collections, result.Keys)
+	log.Printf("The previous collections were kept in %s; delete it once the indexer runs fine", result.LegacyDir)
+}
+
+// pebbleConfig the Pebble database settings (database.data_dir, database.pebble)
+func pebbleConfig() *database.PebbleConfig {
+	tuning := conf.Cfg.Database.Pebble
+	return &database.PebbleConfig{
+		DataDir:                  conf.Cfg.Database.DataDir,
+		CacheSize:                int64(tuning.CacheMB) << 20,
+		MemTableSize:             uint64(max(tuning.MemTableMB, 0)) << 20,
+		MaxConcurrentCompactions: tuning.MaxConcurrentCompactions,
+	}
 }
 
 // initDatabase initialize database based on configuration
@@ -265,10 +386,13 @@ func initDatabase() error {
 		return database.InitDatabase(database.DBTypeMySQL, config)
 
 	case database.DBTypePebble:
-		config := &database.PebbleConfig{
-			DataDir: conf.Cfg.Database.DataDir,
+		return database.InitDatabase(database.DBTypePebble, pebbleConfig())
+
+	case database.DBTypeSQLite:
+		config := &database.SQLiteConfig{
+			Path: conf.Cfg.Database.SqlitePath,
 		}
-		return database.InitDatabase(database.DBTypePebble, config)
+		return database.InitDatabase(database.DBTypeSQLite, config)
 
 	default:
 		log.Printf("Indexer database type not specified, defaulting to MySQL")
@@ -281,27 +405,55 @@ func initDatabase() error {
 	}
 }
 
-// startServer start HTTP server
-func startServer(srv *http.Server) {
-	log.Printf("Indexer API service starting on port %s...", conf.Cfg.IndexerPort)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Failed to start server: %v", err)
+// startAcmeServers starts the HTTPS listener for mapped custom domains, with
+// certificates obtained from Let's Encrypt on first request, and the HTTP
+// listener answering http-01 challenges and redirecting other requests to
+// HTTPS. Returns nil when ACME is disabled.
+func startAcmeServers(handler http.Handler) []*http.Server {
+	servers := newAcmeServers(handler)
+	if servers == nil {
+		return nil
 	}
-}
+	httpsSrv, httpSrv := servers[0], servers[1]
+	acmeCfg := conf.Cfg.Indexer.Domains.Acme
 
-// waitForShutdown wait for shutdown signal
-func waitForShutdown() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
+	go func() {
+		log.Printf("Custom domain HTTPS service starting on port %s (ACME certificates in %s)...", acmeCfg.HttpsPort, acmeCfg.CacheDir)
+		if err := httpsSrv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start HTTPS server: %v", err)
+		}
+	}()
+	go func() {
+		if err := httpSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start ACME HTTP server: %v", err)
+		}
+	}()
+	return servers
 }
 
-// shutdownServer gracefully shutdown server
-func shutdownServer(srv *http.Server) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+// newAcmeServers the HTTPS and http-01 servers of startAcmeServers, not yet
+// listening. The custom domain port is public: it serves the public routes
+// only, as a public listener does, since unmapped hosts fall through to the
+// API.
+func newAcmeServers(handler http.Handler) []*http.Server {
+	acmeCfg := conf.Cfg.Indexer.Domains.Acme
+	if !acmeCfg.Enabled {
+		return nil
+	}
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(acmeCfg.CacheDir),
+		HostPolicy: indexer_service.DomainHostPolicy,
+		Email:      acmeCfg.Email,
+	}
+	httpsSrv := &http.Server{
+		Addr:      ":" + acmeCfg.HttpsPort,
+		Handler:   listener.FilterRoutes(conf.ListenerRoutesPublic, handler),
+		TLSConfig: manager.TLSConfig(),
+	}
+	httpSrv := &http.Server{
+		Addr:    ":" + acmeCfg.HttpPort,
+		Handler: manager.HTTPHandler(nil),
 	}
+	return []*http.Server{httpsSrv, httpSrv}
 }
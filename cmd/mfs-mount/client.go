@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errNotFound the indexer has no such file, directory or manifest
+var errNotFound = errors.New("not found")
+
+// maxBatchPinIDs PIN IDs per POST /files/batch request
+const maxBatchPinIDs = 100
+
+// indexerClient reads an indexer's public API (/api/v1)
+type indexerClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newIndexerClient(baseURL string, timeout time.Duration) *indexerClient {
+	return &indexerClient{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// remoteFile the fields of an indexer file response the mount uses
+type remoteFile struct {
+	PinID       string `json:"pin_id"`
+	FileName    string `json:"file_name"`
+	FileSize    int64  `json:"file_size"`
+	ContentType string `json:"content_type"`
+	Timestamp   int64  `json:"timestamp"` // Milliseconds
+}
+
+// remoteTree a directory of GET /files/metaid/{id}/tree
+type remoteTree struct {
+	Path        string `json:"path"`
+	Directories []struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	} `json:"directories"`
+	Files []remoteFile `json:"files"`
+}
+
+// tree lists the directory dir of metaID
+func (c *indexerClient) tree(metaID, dir string) (*remoteTree, error) {
+	var tree remoteTree
+	err := c.getJSON("/files/metaid/"+url.PathEscape(metaID)+"/tree?path="+url.QueryEscape(dir), &tree)
+	if err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// files returns the indexed files of pinIDs by PIN ID; unknown PINs are left out
+func (c *indexerClient) files(pinIDs []string) (map[string]remoteFile, error) {
+	files := make(map[string]remoteFile, len(pinIDs))
+	for start := 0; start < len(pinIDs); start += maxBatchPinIDs {
+		end := start + maxBatchPinIDs
+		if end > len(pinIDs) {
+			end = len(pinIDs)
+		}
+		body, err := json.Marshal(map[string][]string{"pin_ids": pinIDs[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		var batch struct {
+			Files []remoteFile `json:"files"`
+		}
+		if err := c.do(http.MethodPost, "/files/batch", bytes.NewReader(body), &batch); err != nil {
+			return nil, err
+		}
+		for _, file := range batch.Files {
+			files[file.PinID] = file
+		}
+	}
+	return files, nil
+}
+
+// content downloads the content of pinID
+func (c *indexerClient) content(pinID string) ([]byte, error) {
+	resp, err := c.client.Get(c.baseURL + "/files/content/" + url.PathEscape(pinID))
+	if err != nil {
+		return nil, fmt.Errorf("indexer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", errNotFound, pinID)
+	default:
+		return nil, fmt.Errorf("indexer returned HTTP %d", resp.StatusCode)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content of %s: %w", pinID, err)
+	}
+	return content, nil
+}
+
+func (c *indexerClient) getJSON(path string, data interface{}) error {
+	return c.do(http.MethodGet, path, nil, data)
+}
+
+// do sends a JSON API request and decodes the data of its response envelope
+func (c *indexerClient) do(method, path string, body io.Reader, data interface{}) error {
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("indexer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return fmt.Errorf("invalid indexer response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	switch {
+	case envelope.Code == 40400:
+		return fmt.Errorf("%w: %s", errNotFound, envelope.Message)
+	case envelope.Code != 0:
+		return fmt.Errorf("indexer error %d: %s", envelope.Code, envelope.Message)
+	}
+	return json.Unmarshal(envelope.Data, data)
+}
@@ -0,0 +1,68 @@
+// Command mfs-mount mounts indexed content as a local read-only filesystem:
+// the files of a MetaID arranged by MetaID path, or the files of a site
+// manifest arranged by site path. It talks to an indexer's public API, so it
+// runs anywhere the indexer is reachable; file content is fetched when a file
+// is first read.
+//
+//	mfs-mount -indexer http://localhost:7281 -metaid {metaidOrGlobalMetaId} /mnt/mfs
+//	mfs-mount -indexer http://localhost:7281 -manifest {manifestPinId} /mnt/site
+//
+// The FUSE binding is built with -tags fuse (Linux, macOS with macFUSE).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+var (
+	IndexerURL     string
+	MetaID         string
+	ManifestPinID  string
+	CacheMB        int
+	ListingSeconds int
+	TimeoutSeconds int
+)
+
+func init() {
+	flag.StringVar(&IndexerURL, "indexer", "http://localhost:7281", "Indexer base URL")
+	flag.StringVar(&MetaID, "metaid", "", "Mount the files of this MetaID or GlobalMetaID")
+	flag.StringVar(&ManifestPinID, "manifest", "", "Mount the files of this site manifest PIN")
+	flag.IntVar(&CacheMB, "cache-mb", 256, "File content kept in memory after reads (MB)")
+	flag.IntVar(&ListingSeconds, "listing-seconds", 10, "How long directory listings of a MetaID are reused")
+	flag.IntVar(&TimeoutSeconds, "timeout", 60, "Indexer request timeout (seconds)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-indexer URL] (-metaid ID | -manifest PINID) MOUNTPOINT\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 || (MetaID == "") == (ManifestPinID == "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := newIndexerClient(IndexerURL, time.Duration(TimeoutSeconds)*time.Second)
+	var src source
+	name := "mfs:" + MetaID
+	if MetaID != "" {
+		src = newMetaIDSource(client, MetaID, time.Duration(ListingSeconds)*time.Second)
+	} else {
+		manifest, err := newManifestSource(client, ManifestPinID)
+		if err != nil {
+			log.Fatalf("Failed to load site manifest: %v", err)
+		}
+		src = manifest
+		name = "mfs:" + ManifestPinID
+	}
+
+	tree := &mountTree{name: name, src: src, cache: newContentCache(client, int64(CacheMB)<<20)}
+	if err := mount(flag.Arg(0), tree); err != nil {
+		log.Fatalf("Failed to mount: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+//go:build fuse && (linux || darwin)
+
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+)
+
+// mountCacheTimeout how long the kernel caches names and attributes
+const mountCacheTimeout = 10 * time.Second
+
+type dirNode struct {
+	fs.Inode
+	tree *mountTree
+	dir  string
+}
+
+type fileNode struct {
+	fs.Inode
+	tree *mountTree
+	file remoteFile
+}
+
+// fileHandle an open file; its content is downloaded on the first read and
+// kept until the file is closed, also when it is too large for the cache
+type fileHandle struct {
+	once    sync.Once
+	content []byte
+	err     error
+}
+
+var (
+	_ fs.NodeGetattrer = (*dirNode)(nil)
+	_ fs.NodeReaddirer = (*dirNode)(nil)
+	_ fs.NodeLookuper  = (*dirNode)(nil)
+	_ fs.NodeGetattrer = (*fileNode)(nil)
+	_ fs.NodeOpener    = (*fileNode)(nil)
+	_ fs.NodeReader    = (*fileNode)(nil)
+)
+
+func (n *dirNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	out.Mode = syscall.S_IFDIR | 0555
+	return 0
+}
+
+func (n *dirNode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	entries, err := n.tree.src.list(n.dir)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	list := make([]fuse.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		mode := uint32(syscall.S_IFREG)
+		if e.isDir {
+			mode = syscall.S_IFDIR
+		}
+		list = append(list, fuse.DirEntry{Name: e.name, Mode: mode})
+	}
+	return fs.NewListDirStream(list), 0
+}
+
+func (n *dirNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	e, err := n.tree.lookup(n.dir, name)
+	if err != nil {
+		return nil, toErrno(err)
+	}
+	if e.isDir {
+		out.Mode = syscall.S_IFDIR | 0555
+		return n.NewInode(ctx, &dirNode{tree: n.tree, dir: e.dir}, fs.StableAttr{Mode: syscall.S_IFDIR}), 0
+	}
+	child := &fileNode{tree: n.tree, file: e.file}
+	child.fillAttr(&out.Attr)
+	return n.NewInode(ctx, child, fs.StableAttr{Mode: syscall.S_IFREG}), 0
+}
+
+func (n *fileNode) fillAttr(out *fuse.Attr) {
+	out.Mode = syscall.S_IFREG | 0444
+	out.Size = uint64(n.file.FileSize)
+	mtime := time.UnixMilli(n.file.Timestamp)
+	out.SetTimes(nil, &mtime, &mtime)
+}
+
+func (n *fileNode) Getattr(ctx context.Context, fh fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	n.fillAttr(&out.Attr)
+	return 0
+}
+
+func (n *fileNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if flags&uint32(syscall.O_WRONLY|syscall.O_RDWR) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+	// PINs never change, so the kernel may keep their pages
+	return &fileHandle{}, fuse.FOPEN_KEEP_CACHE, 0
+}
+
+func (n *fileNode) Read(ctx context.Context, fh fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	h, ok := fh.(*fileHandle)
+	if !ok {
+		return nil, syscall.EBADF
+	}
+	h.once.Do(func() {
+		h.content, h.err = n.tree.cache.get(n.file.PinID)
+	})
+	if h.err != nil {
+		log.Printf("Failed to read %s: %v", n.file.PinID, h.err)
+		return nil, syscall.EIO
+	}
+	if off >= int64(len(h.content)) {
+		return fuse.ReadResultData(nil), 0
+	}
+	end := off + int64(len(dest))
+	if end > int64(len(h.content)) {
+		end = int64(len(h.content))
+	}
+	return fuse.ReadResultData(h.content[off:end]), 0
+}
+
+func toErrno(err error) syscall.Errno {
+	if errors.Is(err, errNotFound) {
+		return syscall.ENOENT
+	}
+	log.Printf("Indexer request failed: %v", err)
+	return syscall.EIO
+}
+
+// mount serves tree at mountpoint until it is unmounted or the process is
+// interrupted
+func mount(mountpoint string, tree *mountTree) error {
+	timeout := mountCacheTimeout
+	server, err := fs.Mount(mountpoint, &dirNode{tree: tree, dir: "/"}, &fs.Options{
+		MountOptions: fuse.MountOptions{
+			FsName:  tree.name,
+			Name:    "mfs",
+			Options: []string{"ro"},
+		},
+		EntryTimeout: &timeout,
+		AttrTimeout:  &timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		if err := server.Unmount(); err != nil {
+			log.Printf("Failed to unmount %s: %v", mountpoint, err)
+		}
+	}()
+
+	log.Printf("Mounted %s at %s (read-only); press Ctrl+C or unmount to stop", tree.name, mountpoint)
+	server.Wait()
+	return nil
+}
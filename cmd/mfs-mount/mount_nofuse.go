@@ -0,0 +1,10 @@
+//go:build !fuse || !(linux || darwin)
+
+package main
+
+import "errors"
+
+// mount is unavailable without the FUSE binding
+func mount(mountpoint string, tree *mountTree) error {
+	return errors.New("mfs-mount was built without FUSE support; rebuild on Linux or macOS with: go build -tags fuse ./cmd/mfs-mount")
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// entry a file or directory of the mounted tree
+type entry struct {
+	name  string
+	isDir bool
+	dir   string     // Path of a directory within the source, "/" for the root
+	file  remoteFile // Files only
+}
+
+// source lists the directories of what is mounted; dir is "/" for the root
+type source interface {
+	list(dir string) ([]entry, error)
+}
+
+// mountTree what one mount serves: the directories of src and the content of
+// their files
+type mountTree struct {
+	name  string
+	src   source
+	cache *contentCache
+}
+
+// lookup finds name in dir, falling back to a case-insensitive match for
+// systems that change the case of names
+func (t *mountTree) lookup(dir, name string) (entry, error) {
+	entries, err := t.src.list(dir)
+	if err != nil {
+		return entry{}, err
+	}
+	for _, e := range entries {
+		if e.name == name {
+			return e, nil
+		}
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e.name, name) {
+			return e, nil
+		}
+	}
+	return entry{}, fmt.Errorf("%w: %s", errNotFound, path.Join(dir, name))
+}
+
+// metaIDSource the files of a MetaID by MetaID path, listed through the
+// indexer's path tree; listings are reused for ttl
+type metaIDSource struct {
+	client *indexerClient
+	metaID string
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	listings map[string]metaIDListing
+}
+
+type metaIDListing struct {
+	entries  []entry
+	loadedAt time.Time
+}
+
+func newMetaIDSource(client *indexerClient, metaID string, ttl time.Duration) *metaIDSource {
+	return &metaIDSource{client: client, metaID: metaID, ttl: ttl, listings: make(map[string]metaIDListing)}
+}
+
+func (s *metaIDSource) list(dir string) ([]entry, error) {
+	s.mu.Lock()
+	cached, ok := s.listings[dir]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < s.ttl {
+		return cached.entries, nil
+	}
+
+	tree, err := s.client.tree(s.metaID, dir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]entry, 0, len(tree.Directories)+len(tree.Files))
+	taken := make(map[string]struct{}, cap(entries))
+	for _, d := range tree.Directories {
+		entries = append(entries, entry{name: d.Name, isDir: true, dir: d.Path})
+		taken[d.Name] = struct{}{}
+	}
+	// Named as by the indexer's WebDAV gateway: of several files with one
+	// name the newest keeps it, the others are "{pinId}_{name}"
+	for _, f := range tree.Files {
+		name := f.FileName
+		if name == "" {
+			name = f.PinID
+		}
+		if _, ok := taken[name]; ok {
+			name = f.PinID + "_" + name
+		}
+		taken[name] = struct{}{}
+		entries = append(entries, entry{name: name, file: f})
+	}
+
+	s.mu.Lock()
+	s.listings[dir] = metaIDListing{entries: entries, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return entries, nil
+}
+
+// manifestSource the files of a site manifest by site path; manifests are
+// immutable, so the whole tree is built once
+type manifestSource struct {
+	dirs map[string][]entry
+}
+
+func newManifestSource(client *indexerClient, manifestPinID string) (*manifestSource, error) {
+	data, err := client.content(manifestPinID)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := metaid_protocols.ParseSiteManifest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pinIDs := make([]string, 0, len(manifest.Files))
+	seen := make(map[string]struct{}, len(manifest.Files))
+	for _, pinID := range manifest.Files {
+		if _, ok := seen[pinID]; !ok {
+			seen[pinID] = struct{}{}
+			pinIDs = append(pinIDs, pinID)
+		}
+	}
+	sort.Strings(pinIDs)
+	files, err := client.files(pinIDs)
+	if err != nil {
+		return nil, err
+	}
+	return buildManifestSource(manifest, files), nil
+}
+
+// buildManifestSource arranges the manifest paths as directories; files the
+// indexer does not know are left out
+func buildManifestSource(manifest *metaid_protocols.SiteManifest, files map[string]remoteFile) *manifestSource {
+	s := &manifestSource{dirs: map[string][]entry{"/": nil}}
+	paths := make([]string, 0, len(manifest.Files))
+	for p := range manifest.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		file, ok := files[manifest.Files[p]]
+		if !ok {
+			continue
+		}
+		dir, name := path.Split("/" + p)
+		dir = path.Clean(dir)
+		s.addDir(dir)
+		s.dirs[dir] = append(s.dirs[dir], entry{name: name, file: file})
+	}
+	return s
+}
+
+// addDir adds dir and its parents
+func (s *manifestSource) addDir(dir string) {
+	if _, ok := s.dirs[dir]; ok {
+		return
+	}
+	s.dirs[dir] = nil
+	parent, name := path.Split(dir)
+	parent = path.Clean(parent)
+	s.addDir(parent)
+	s.dirs[parent] = append(s.dirs[parent], entry{name: name, isDir: true, dir: dir})
+}
+
+func (s *manifestSource) list(dir string) ([]entry, error) {
+	entries, ok := s.dirs[dir]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNotFound, dir)
+	}
+	return entries, nil
+}
+
+// contentCache keeps the content of recently read files up to maxBytes;
+// concurrent reads of a file that is not cached share one download
+type contentCache struct {
+	client   *indexerClient
+	maxBytes int64
+
+	mu       sync.Mutex
+	order    *list.List // Most recently used first
+	entries  map[string]*list.Element
+	size     int64
+	inflight map[string]*contentFetch
+}
+
+type cachedContent struct {
+	pinID   string
+	content []byte
+}
+
+type contentFetch struct {
+	done    chan struct{}
+	content []byte
+	err     error
+}
+
+func newContentCache(client *indexerClient, maxBytes int64) *contentCache {
+	return &contentCache{
+		client:   client,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+		inflight: make(map[string]*contentFetch),
+	}
+}
+
+// get returns the content of pinID, downloading it when it is not cached
+func (c *contentCache) get(pinID string) ([]byte, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[pinID]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		return el.Value.(*cachedContent).content, nil
+	}
+	if f, ok := c.inflight[pinID]; ok {
+		c.mu.Unlock()
+		<-f.done
+		return f.content, f.err
+	}
+	f := &contentFetch{done: make(chan struct{})}
+	c.inflight[pinID] = f
+	c.mu.Unlock()
+
+	f.content, f.err = c.client.content(pinID)
+	c.mu.Lock()
+	delete(c.inflight, pinID)
+	if f.err == nil {
+		c.addLocked(pinID, f.content)
+	}
+	c.mu.Unlock()
+	close(f.done)
+	return f.content, f.err
+}
+
+// addLocked caches content, evicting the least recently used; content larger
+// than the whole cache is not kept
+func (c *contentCache) addLocked(pinID string, content []byte) {
+	if int64(len(content)) > c.maxBytes {
+		return
+	}
+	c.entries[pinID] = c.order.PushFront(&cachedContent{pinID: pinID, content: content})
+	c.size += int64(len(content))
+	for c.size > c.maxBytes {
+		evicted := c.order.Remove(c.order.Back()).(*cachedContent)
+		delete(c.entries, evicted.pinID)
+		c.size -= int64(len(evicted.content))
+	}
+}
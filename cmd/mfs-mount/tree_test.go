@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testManifestPinID = "1111111111111111111111111111111111111111111111111111111111111111i0"
+
+// newTestIndexer serves the API routes the mount uses
+func newTestIndexer(t *testing.T, downloads *int32) *httptest.Server {
+	t.Helper()
+	envelope := func(w http.ResponseWriter, data interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "message": "success", "data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/files/metaid/abc/tree", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("path") {
+		case "/":
+			envelope(w, map[string]interface{}{
+				"path":        "/",
+				"directories": []map[string]string{{"name": "file", "path": "/file"}},
+				"files":       []remoteFile{},
+			})
+		case "/file":
+			envelope(w, map[string]interface{}{
+				"path":        "/file",
+				"directories": []map[string]string{{"name": "a.txt", "path": "/file/a.txt"}},
+				"files": []remoteFile{
+					{PinID: "p2", FileName: "a.txt", FileSize: 2},
+					{PinID: "p1", FileName: "a.txt", FileSize: 1},
+				},
+			})
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 40400, "message": "not found"})
+		}
+	})
+	mux.HandleFunc("/api/v1/files/batch", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			PinIDs []string `json:"pin_ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		var files []remoteFile
+		for _, pinID := range req.PinIDs {
+			if strings.HasPrefix(pinID, "2") {
+				files = append(files, remoteFile{PinID: pinID, FileName: "f", FileSize: 7})
+			}
+		}
+		envelope(w, map[string]interface{}{"files": files, "missing": []string{}})
+	})
+	mux.HandleFunc("/api/v1/files/content/", func(w http.ResponseWriter, r *http.Request) {
+		pinID := strings.TrimPrefix(r.URL.Path, "/api/v1/files/content/")
+		if pinID == testManifestPinID {
+			w.Write([]byte(`{"version":1,"files":{"index.html":"` + strings.Repeat("2", 64) + `i0","assets/js/app.js":"` + strings.Repeat("2", 64) + `i1","gone.txt":"` + strings.Repeat("3", 64) + `i0"}}`))
+			return
+		}
+		atomic.AddInt32(downloads, 1)
+		w.Write([]byte("content of " + pinID))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func entryNames(entries []entry) []string {
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	return strings.Join(a, "|") == strings.Join(b, "|")
+}
+
+func TestMetaIDSourceListsPathTree(t *testing.T) {
+	var downloads int32
+	server := newTestIndexer(t, &downloads)
+	tree := &mountTree{src: newMetaIDSource(newIndexerClient(server.URL, time.Second), "abc", time.Minute)}
+
+	entries, err := tree.src.list("/file")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	// A file named like a directory, or like a newer file, gets its PIN ID prefixed
+	if got, want := entryNames(entries), []string{"a.txt", "p2_a.txt", "p1_a.txt"}; !equalNames(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+
+	e, err := tree.lookup("/", "FILE")
+	if err != nil || !e.isDir || e.dir != "/file" {
+		t.Errorf("lookup FILE = %+v, %v", e, err)
+	}
+	if _, err := tree.lookup("/", "missing"); !errors.Is(err, errNotFound) {
+		t.Errorf("lookup missing = %v, want errNotFound", err)
+	}
+	if _, err := tree.src.list("/nowhere"); !errors.Is(err, errNotFound) {
+		t.Errorf("list of an unknown directory = %v, want errNotFound", err)
+	}
+}
+
+func TestManifestSourceBuildsDirectories(t *testing.T) {
+	var downloads int32
+	server := newTestIndexer(t, &downloads)
+	src, err := newManifestSource(newIndexerClient(server.URL, time.Second), testManifestPinID)
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+
+	root, _ := src.list("/")
+	// gone.txt is not indexed and is left out
+	if got, want := entryNames(root), []string{"assets", "index.html"}; !equalNames(got, want) {
+		t.Errorf("root = %v, want %v", got, want)
+	}
+	js, err := src.list("/assets/js")
+	if err != nil {
+		t.Fatalf("list /assets/js: %v", err)
+	}
+	if len(js) != 1 || js[0].name != "app.js" || js[0].file.FileSize != 7 {
+		t.Errorf("/assets/js = %+v", js)
+	}
+}
+
+func TestContentCacheEvictsAndSkipsLargeFiles(t *testing.T) {
+	var downloads int32
+	server := newTestIndexer(t, &downloads)
+	cache := newContentCache(newIndexerClient(server.URL, time.Second), 30)
+
+	for _, pinID := range []string{"a", "a", "b", "a"} {
+		content, err := cache.get(pinID)
+		if err != nil || string(content) != "content of "+pinID {
+			t.Fatalf("get %s = %q, %v", pinID, content, err)
+		}
+	}
+	if downloads != 2 {
+		t.Errorf("downloads = %d, want 2 (a and b)", downloads)
+	}
+	// 13 bytes each: caching c evicts the least recently used (b)
+	cache.get("c")
+	cache.get("b")
+	if downloads != 4 {
+		t.Errorf("downloads = %d, want 4 after b was evicted", downloads)
+	}
+
+	large := newContentCache(newIndexerClient(server.URL, time.Second), 5)
+	large.get("a")
+	large.get("a")
+	if downloads != 6 {
+		t.Errorf("downloads = %d, want 6: content larger than the cache is not kept", downloads)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// adminClient calls an indexer's API (/api/v1); admin commands need
+// indexer.admin_enabled on the indexer
+type adminClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// newAdminClient a client of the indexer at baseURL: http(s)://host:port, or
+// unix:/path/to.sock for an indexer listener on a unix socket
+func newAdminClient(baseURL string, timeout time.Duration) *adminClient {
+	client := &http.Client{Timeout: timeout}
+	if socket, ok := strings.CutPrefix(baseURL, "unix:"); ok {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}
+		baseURL = "http://indexer"
+	}
+	return &adminClient{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/api/v1",
+		client:  client,
+	}
+}
+
+// apiError an error response of the indexer
+type apiError struct {
+	Code    int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	if e.Code == 40400 && e.Message == "" {
+		return "not found (is indexer.admin_enabled set?)"
+	}
+	return fmt.Sprintf("indexer error %d: %s", e.Code, e.Message)
+}
+
+// get sends a GET request and returns the data of the response envelope
+func (c *adminClient) get(path string) (json.RawMessage, error) {
+	return c.do(http.MethodGet, path, nil)
+}
+
+// post sends a POST request with body (nil for none) as JSON and returns the
+// data of the response envelope
+func (c *adminClient) post(path string, body interface{}) (json.RawMessage, error) {
+	return c.do(http.MethodPost, path, body)
+}
+
+func (c *adminClient) do(method, path string, body interface{}) (json.RawMessage, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("indexer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		// Route missing: the admin routes are off, or the indexer is older
+		return nil, &apiError{Code: 40400}
+	}
+	var envelope struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("invalid indexer response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if envelope.Code != 0 {
+		return nil, &apiError{Code: envelope.Code, Message: envelope.Message}
+	}
+	return envelope.Data, nil
+}
+
+// download fetches a file the indexer serves without the response envelope
+// (a rescan report); error responses still come in the envelope
+func (c *adminClient) download(path string) ([]byte, error) {
+	resp, err := c.client.Get(c.baseURL + path)
+	if err != nil {
+		return nil, fmt.Errorf("indexer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &apiError{Code: 40400}
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexer response: %w", err)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		var envelope struct {
+			Code    *int   `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &envelope) == nil && envelope.Code != nil && *envelope.Code != 0 {
+			return nil, &apiError{Code: *envelope.Code, Message: envelope.Message}
+		}
+	}
+	return content, nil
+}
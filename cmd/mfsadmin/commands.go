@@ -0,0 +1,582 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// errUsage a command was called with wrong arguments; its usage was printed
+var errUsage = errors.New("usage")
+
+// newFlagSet the flags of a command; usage is printed before them on -h or
+// a parse error
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: mfsadmin %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs parses args into fs and checks the positional argument count
+func parseArgs(fs *flag.FlagSet, args []string, positional int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != positional {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// printJSON writes data indented
+func printJSON(data json.RawMessage) error {
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := stdout.Write(out.Bytes())
+	return err
+}
+
+// newTable a column-aligned writer; Flush it when done
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+}
+
+func unixTime(seconds int64) string {
+	if seconds == 0 {
+		return "-"
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// syncStatus the fields of GET /status the status command shows
+type syncStatus struct {
+	Chains []struct {
+		ChainName           string    `json:"chain_name"`
+		CurrentSyncHeight   int64     `json:"current_sync_height"`
+		LatestBlockHeight   int64     `json:"latest_block_height"`
+		Pruned              bool      `json:"pruned"`
+		EarliestBlockHeight int64     `json:"earliest_block_height"`
+		Paused              string    `json:"paused"`
+		UpdatedAt           time.Time `json:"updated_at"`
+	} `json:"chains"`
+}
+
+func runStatus(c *adminClient, args []string) error {
+	fs := newFlagSet("status", "status")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := c.get("/status")
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printJSON(data)
+	}
+	var status syncStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+
+	table := newTable()
+	fmt.Fprintln(table, "CHAIN\tSYNCED\tNODE\tBEHIND\tPAUSED\tEARLIEST\tUPDATED")
+	for _, chain := range status.Chains {
+		behind := "-"
+		if chain.LatestBlockHeight > 0 {
+			behind = fmt.Sprint(chain.LatestBlockHeight - chain.CurrentSyncHeight)
+		}
+		earliest := "-"
+		if chain.Pruned {
+			earliest = fmt.Sprint(chain.EarliestBlockHeight)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", chain.ChainName, chain.CurrentSyncHeight, chain.LatestBlockHeight,
+			behind, orDash(chain.Paused), earliest, chain.UpdatedAt.UTC().Format(time.RFC3339))
+	}
+	return table.Flush()
+}
+
+// rescanStatus the fields of GET /admin/rescan/status the rescan command shows
+type rescanStatus struct {
+	TaskID            string  `json:"task_id"`
+	Chain             string  `json:"chain"`
+	Status            string  `json:"status"`
+	StartHeight       int64   `json:"start_height"`
+	EndHeight         int64   `json:"end_height"`
+	CurrentHeight     int64   `json:"current_height"`
+	ProcessedBlocks   int64   `json:"processed_blocks"`
+	TotalBlocks       int64   `json:"total_blocks"`
+	Progress          float64 `json:"progress"`
+	EstimatedTimeLeft int64   `json:"estimated_time_left"` // Milliseconds
+	ErrorMessage      string  `json:"error_message"`
+	IndexedPins       int64   `json:"indexed_pins"`
+	DuplicatePins     int64   `json:"duplicate_pins"`
+	SkippedPins       int64   `json:"skipped_pins"`
+	RejectedPins      int64   `json:"rejected_pins"`
+	FailedPins        int64   `json:"failed_pins"`
+	FailedBlocks      int64   `json:"failed_blocks"`
+}
+
+func (s *rescanStatus) String() string {
+	if s.TaskID == "" {
+		return "no rescan has run since the indexer started"
+	}
+	line := fmt.Sprintf("%s %s: %s %d/%d blocks", s.TaskID, s.Chain, s.Status, s.ProcessedBlocks, s.TotalBlocks)
+	if s.Status == "running" {
+		line += fmt.Sprintf(" (%.1f%%, height %d", s.Progress, s.CurrentHeight)
+		if s.EstimatedTimeLeft > 0 {
+			line += fmt.Sprintf(", %v left", (time.Duration(s.EstimatedTimeLeft) * time.Millisecond).Round(time.Second))
+		}
+		line += ")"
+	}
+	line += fmt.Sprintf("; pins indexed %d, duplicate %d, skipped %d, rejected %d, failed %d; failed blocks %d",
+		s.IndexedPins, s.DuplicatePins, s.SkippedPins, s.RejectedPins, s.FailedPins, s.FailedBlocks)
+	if s.ErrorMessage != "" {
+		line += "; error: " + s.ErrorMessage
+	}
+	return line
+}
+
+func runRescan(c *adminClient, args []string) error {
+	const usage = "rescan start|status|stop|report ..."
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: mfsadmin %s\n", usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "start":
+		return rescanStart(c, args[1:])
+	case "status":
+		return rescanStatusCmd(c, args[1:])
+	case "stop":
+		return rescanStop(c, args[1:])
+	case "report":
+		return rescanReport(c, args[1:])
+	}
+	fmt.Fprintf(os.Stderr, "Usage: mfsadmin %s\n", usage)
+	return errUsage
+}
+
+func rescanStart(c *adminClient, args []string) error {
+	fs := newFlagSet("rescan start", "rescan start -chain CHAIN -from HEIGHT [-to HEIGHT] [-watch]")
+	chain := fs.String("chain", "", "Chain to rescan")
+	from := fs.Int64("from", 0, "First block height")
+	to := fs.Int64("to", 0, "Last block height (default: -from)")
+	watch := fs.Bool("watch", false, "Follow the rescan until it ends")
+	interval := fs.Duration("interval", 5*time.Second, "Polling interval of -watch")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *chain == "" || *from <= 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if *to == 0 {
+		*to = *from
+	}
+
+	data, err := c.post("/admin/rescan", map[string]interface{}{"chain": *chain, "start_height": *from, "end_height": *to})
+	if err != nil {
+		return err
+	}
+	if JSONOutput && !*watch {
+		return printJSON(data)
+	}
+	var started struct {
+		TaskID string `json:"task_id"`
+	}
+	if err := json.Unmarshal(data, &started); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Started %s (%s %d-%d)\n", started.TaskID, *chain, *from, *to)
+	if *watch {
+		return watchRescan(c, *interval)
+	}
+	return nil
+}
+
+func rescanStatusCmd(c *adminClient, args []string) error {
+	fs := newFlagSet("rescan status", "rescan status [-watch]")
+	watch := fs.Bool("watch", false, "Follow the rescan until it ends")
+	interval := fs.Duration("interval", 5*time.Second, "Polling interval of -watch")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	if *watch {
+		return watchRescan(c, *interval)
+	}
+	data, err := c.get("/admin/rescan/status")
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printJSON(data)
+	}
+	var status rescanStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, status.String())
+	return nil
+}
+
+// watchRescan prints the rescan status every interval until the rescan is no
+// longer running; a failed rescan is returned as an error
+func watchRescan(c *adminClient, interval time.Duration) error {
+	for {
+		data, err := c.get("/admin/rescan/status")
+		if err != nil {
+			return err
+		}
+		var status rescanStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, status.String())
+		switch status.Status {
+		case "running":
+			time.Sleep(interval)
+			continue
+		case "failed":
+			return fmt.Errorf("rescan %s failed: %s", status.TaskID, status.ErrorMessage)
+		}
+		if status.FailedPins > 0 || status.FailedBlocks > 0 {
+			fmt.Fprintf(stdout, "See the failures with: mfsadmin rescan report -failed %s\n", status.TaskID)
+		}
+		return nil
+	}
+}
+
+func rescanStop(c *adminClient, args []string) error {
+	fs := newFlagSet("rescan stop", "rescan stop")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := c.post("/admin/rescan/stop", nil)
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printJSON(data)
+	}
+	var stopped struct {
+		TaskID string `json:"task_id"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &stopped); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: %s\n", stopped.TaskID, stopped.Status)
+	return nil
+}
+
+// rescanReportFailures the failed entries of a rescan report
+type rescanReportFailures struct {
+	TaskID string `json:"task_id"`
+	Blocks []struct {
+		Height int64  `json:"height"`
+		Error  string `json:"error"`
+	} `json:"blocks"`
+	Pins []struct {
+		Height  int64  `json:"height"`
+		PinID   string `json:"pin_id"`
+		Path    string `json:"path"`
+		Outcome string `json:"outcome"`
+		Reason  string `json:"reason"`
+	} `json:"pins"`
+	Truncated bool `json:"truncated"`
+}
+
+func rescanReport(c *adminClient, args []string) error {
+	fs := newFlagSet("rescan report", "rescan report [-format json|csv] [-o FILE] [-failed] TASK_ID")
+	format := fs.String("format", "json", "Report format: json or csv")
+	output := fs.String("o", "", "Write the report to this file instead of stdout")
+	failed := fs.Bool("failed", false, "Only list the failed and rejected PINs and the failed blocks")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	if *failed {
+		*format = "json"
+	}
+
+	report, err := c.download("/admin/rescan/" + url.PathEscape(fs.Arg(0)) + "/report?format=" + url.QueryEscape(*format))
+	if err != nil {
+		return err
+	}
+	if !*failed {
+		if *output != "" {
+			return os.WriteFile(*output, report, 0644)
+		}
+		_, err := stdout.Write(report)
+		return err
+	}
+
+	var failures rescanReportFailures
+	if err := json.Unmarshal(report, &failures); err != nil {
+		return fmt.Errorf("invalid rescan report: %w", err)
+	}
+	table := newTable()
+	fmt.Fprintln(table, "HEIGHT\tPIN\tPATH\tOUTCOME\tREASON")
+	for _, block := range failures.Blocks {
+		if block.Error != "" {
+			fmt.Fprintf(table, "%d\t-\t-\tblock failed\t%s\n", block.Height, block.Error)
+		}
+	}
+	for _, pin := range failures.Pins {
+		if pin.Outcome == "failed" || pin.Outcome == "rejected" {
+			fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n", pin.Height, pin.PinID, orDash(pin.Path), pin.Outcome, orDash(pin.Reason))
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	if failures.Truncated {
+		fmt.Fprintln(stdout, "The report was truncated; PINs beyond its cap are missing")
+	}
+	return nil
+}
+
+func runChain(c *adminClient, args []string) error {
+	fs := newFlagSet("chain", "chain pause|resume CHAIN")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+	action, chain := fs.Arg(0), fs.Arg(1)
+	if action != "pause" && action != "resume" {
+		fs.Usage()
+		return errUsage
+	}
+	data, err := c.post("/admin/chains/"+url.PathEscape(chain)+"/"+action, nil)
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printJSON(data)
+	}
+	var state struct {
+		Chain    string `json:"chain"`
+		Paused   bool   `json:"paused"`
+		PausedAt int64  `json:"paused_at"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	if state.Paused {
+		fmt.Fprintf(stdout, "%s: paused since %s\n", state.Chain, unixTime(state.PausedAt))
+	} else {
+		fmt.Fprintf(stdout, "%s: scanning\n", state.Chain)
+	}
+	return nil
+}
+
+func runRepair(c *adminClient, args []string) error {
+	fs := newFlagSet("repair", "repair latest|counters [-fix]")
+	fix := fs.Bool("fix", false, "Rewrite the entries that drifted (default: only report them)")
+	if len(args) == 0 || (args[0] != "latest" && args[0] != "counters") {
+		fs.Usage()
+		return errUsage
+	}
+	target := args[0]
+	if err := parseArgs(fs, args[1:], 0); err != nil {
+		return err
+	}
+
+	path := "/admin/latest/repair"
+	if target == "counters" {
+		path = "/admin/counters/reconcile"
+	}
+	if *fix {
+		path += "?fix=true"
+	}
+	data, err := c.post(path, nil)
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printJSON(data)
+	}
+
+	var result struct {
+		Drifts []struct {
+			Collection string          `json:"collection"`
+			Key        string          `json:"key"`
+			Name       string          `json:"name"`
+			Stored     json.RawMessage `json:"stored"`
+			Actual     json.RawMessage `json:"actual"`
+		} `json:"drifts"`
+		Fixed bool `json:"fixed"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	if len(result.Drifts) == 0 {
+		fmt.Fprintln(stdout, "No drift")
+		return nil
+	}
+	table := newTable()
+	if target == "latest" {
+		fmt.Fprintln(table, "COLLECTION\tKEY\tSTORED\tACTUAL")
+	} else {
+		fmt.Fprintln(table, "COUNTER\tSTORED\tACTUAL")
+	}
+	for _, d := range result.Drifts {
+		stored, actual := jsonValue(d.Stored), jsonValue(d.Actual)
+		if target == "latest" {
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", d.Collection, d.Key, orDash(stored), orDash(actual))
+		} else {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", d.Name, stored, actual)
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	switch {
+	case result.Fixed:
+		fmt.Fprintf(stdout, "%d drifted, rewritten\n", len(result.Drifts))
+	case *fix:
+		fmt.Fprintf(stdout, "%d drifted, nothing could be rewritten\n", len(result.Drifts))
+	default:
+		fmt.Fprintf(stdout, "%d drifted; run with -fix to rewrite them\n", len(result.Drifts))
+	}
+	return nil
+}
+
+// jsonValue a JSON string or number as text
+func jsonValue(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// pendingFailures the queues of GET /admin/storage/pending and
+// GET /admin/creator/pending
+type pendingFailures struct {
+	Storage struct {
+		Writes []struct {
+			PinID       string `json:"pin_id"`
+			ChainName   string `json:"chain_name"`
+			Kind        string `json:"kind"`
+			Attempts    int    `json:"attempts"`
+			LastError   string `json:"last_error"`
+			NextRetryAt int64  `json:"next_retry_at"`
+		} `json:"writes"`
+	}
+	Creator struct {
+		Lookups []struct {
+			PinID           string `json:"pin_id"`
+			ChainName       string `json:"chain_name"`
+			FallbackAddress string `json:"fallback_address"`
+			Attempts        int    `json:"attempts"`
+			LastError       string `json:"last_error"`
+			NextRetryAt     int64  `json:"next_retry_at"`
+		} `json:"lookups"`
+	}
+}
+
+func runFailed(c *adminClient, args []string) error {
+	fs := newFlagSet("failed", "failed [-chain CHAIN]")
+	chain := fs.String("chain", "", "Only list PINs of this chain")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	storage, err := c.get("/admin/storage/pending")
+	if err != nil {
+		return fmt.Errorf("storage queue: %w", err)
+	}
+	creator, err := c.get("/admin/creator/pending")
+	if err != nil {
+		return fmt.Errorf("creator lookup queue: %w", err)
+	}
+	if JSONOutput {
+		combined, err := json.Marshal(map[string]json.RawMessage{"storage": storage, "creator": creator})
+		if err != nil {
+			return err
+		}
+		return printJSON(combined)
+	}
+
+	var failures pendingFailures
+	if err := json.Unmarshal(storage, &failures.Storage); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(creator, &failures.Creator); err != nil {
+		return err
+	}
+	table := newTable()
+	fmt.Fprintln(table, "PIN\tCHAIN\tWAITING FOR\tATTEMPTS\tNEXT RETRY\tLAST ERROR")
+	rows := 0
+	for _, w := range failures.Storage.Writes {
+		if *chain == "" || w.ChainName == *chain {
+			fmt.Fprintf(table, "%s\t%s\tstorage (%s)\t%d\t%s\t%s\n", w.PinID, w.ChainName, w.Kind, w.Attempts, unixTime(w.NextRetryAt), orDash(w.LastError))
+			rows++
+		}
+	}
+	for _, l := range failures.Creator.Lookups {
+		if *chain == "" || l.ChainName == *chain {
+			fmt.Fprintf(table, "%s\t%s\tcreator (now %s)\t%d\t%s\t%s\n", l.PinID, l.ChainName, l.FallbackAddress, l.Attempts, unixTime(l.NextRetryAt), orDash(l.LastError))
+			rows++
+		}
+	}
+	if rows == 0 {
+		fmt.Fprintln(stdout, "No PINs waiting for a retry")
+		return nil
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "PINs that failed during a rescan are in its report: mfsadmin rescan report -failed TASK_ID")
+	return nil
+}
+
+func runCounters(c *adminClient, args []string) error {
+	fs := newFlagSet("counters", "counters [-prefix PREFIX]")
+	prefix := fs.String("prefix", "", "Counter name prefix, e.g. files:chain:")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	data, err := c.get("/admin/counters?prefix=" + url.QueryEscape(*prefix))
+	if err != nil {
+		return err
+	}
+	if JSONOutput {
+		return printJSON(data)
+	}
+	var result struct {
+		Counters map[string]int64 `json:"counters"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(result.Counters))
+	for name := range result.Counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	table := newTable()
+	for _, name := range names {
+		fmt.Fprintf(table, "%s\t%d\n", name, result.Counters[name])
+	}
+	return table.Flush()
+}
@@ -0,0 +1,96 @@
+// Command mfsadmin runs the indexer's operational tasks through its admin API:
+// sync status, block rescans and their reports, pausing chains, repairing the
+// latest entries and counters, and listing files whose indexing is still
+// being retried. The admin routes need indexer.admin_enabled.
+//
+//	mfsadmin status
+//	mfsadmin rescan start -chain mvc -from 350000 -to 350100 -watch
+//	mfsadmin rescan report -failed rescan_mvc_350000_350100_1735689600
+//	mfsadmin chain pause btc
+//	mfsadmin repair latest -fix
+//	mfsadmin -indexer https://indexer.example.com failed
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	IndexerURL     string
+	TimeoutSeconds int
+	JSONOutput     bool
+)
+
+// stdout receives the command output (replaced in tests)
+var stdout io.Writer = os.Stdout
+
+// command a subcommand; args are the arguments after its name
+type command struct {
+	usage string
+	run   func(c *adminClient, args []string) error
+}
+
+var commands = map[string]command{
+	"status":   {"status                              Sync status and pause state of every chain", runStatus},
+	"rescan":   {"rescan start|status|stop|report    Trigger, watch and stop rescans; download their reports", runRescan},
+	"chain":    {"chain pause|resume CHAIN           Pause or resume scanning of a chain", runChain},
+	"repair":   {"repair latest|counters [-fix]      Check (and with -fix rewrite) latest entries or counters", runRepair},
+	"failed":   {"failed [-chain CHAIN]              Files whose storage write or creator lookup is being retried", runFailed},
+	"counters": {"counters [-prefix PREFIX]          Maintained counters", runCounters},
+}
+
+func init() {
+	flag.StringVar(&IndexerURL, "indexer", envOr("MFSADMIN_INDEXER", "http://localhost:7281"), "Indexer base URL, or unix:/path/to.sock (default from $MFSADMIN_INDEXER)")
+	flag.IntVar(&TimeoutSeconds, "timeout", 300, "Request timeout (seconds); repairs walk every index")
+	flag.BoolVar(&JSONOutput, "json", false, "Print the API responses as JSON")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: mfsadmin [flags] COMMAND [ARGS]\n\nCommands:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %s\n", commands[name].usage)
+		}
+		fmt.Fprintf(out, "\nRun mfsadmin COMMAND -h for the flags of a command.\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "mfsadmin: unknown command %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	client := newAdminClient(IndexerURL, time.Duration(TimeoutSeconds)*time.Second)
+	if err := cmd.run(client, flag.Args()[1:]); err != nil {
+		if err == errUsage {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "mfsadmin %s: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeIndexer answers admin API requests from routes ("METHOD /path" ->
+// response code and data; code -1 serves data without the envelope)
+type fakeIndexer struct {
+	routes map[string]func(r *http.Request) (code int, data interface{})
+}
+
+func (f *fakeIndexer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/api/v1")
+	route, ok := f.routes[key]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	code, data := route(r)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if code == -1 {
+		json.NewEncoder(w).Encode(data)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "message": "msg", "data": data})
+}
+
+// run runs a command against f and returns its output
+func run(t *testing.T, f *fakeIndexer, args ...string) (string, error) {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	var out bytes.Buffer
+	stdout = &out
+	t.Cleanup(func() { stdout = nil })
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		t.Fatalf("unknown command %s", args[0])
+	}
+	err := cmd.run(newAdminClient(server.URL, 5*time.Second), args[1:])
+	return out.String(), err
+}
+
+func TestStatus(t *testing.T) {
+	f := &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"GET /status": func(*http.Request) (int, interface{}) {
+			return 0, map[string]interface{}{"chains": []map[string]interface{}{
+				{"chain_name": "btc", "current_sync_height": 870000, "latest_block_height": 870005, "paused": "operator", "updated_at": "2026-01-01T00:00:00Z"},
+				{"chain_name": "mvc", "current_sync_height": 120000, "latest_block_height": 120000, "updated_at": "2026-01-01T00:00:00Z"},
+			}}
+		},
+	}}
+	out, err := run(t, f, "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "870000") || !strings.Contains(lines[1], "operator") || strings.Fields(lines[1])[3] != "5" {
+		t.Errorf("status output:\n%s", out)
+	}
+}
+
+func TestRescanStartWatch(t *testing.T) {
+	polls := 0
+	f := &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"POST /admin/rescan": func(r *http.Request) (int, interface{}) {
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["chain"] != "mvc" || req["start_height"] != float64(100) || req["end_height"] != float64(100) {
+				return 40000, nil
+			}
+			return 0, map[string]interface{}{"task_id": "rescan_mvc_100_100_1"}
+		},
+		"GET /admin/rescan/status": func(*http.Request) (int, interface{}) {
+			polls++
+			status := "running"
+			if polls > 2 {
+				status = "completed"
+			}
+			return 0, map[string]interface{}{"task_id": "rescan_mvc_100_100_1", "chain": "mvc", "status": status, "total_blocks": 1, "failed_pins": 1}
+		},
+	}}
+	out, err := run(t, f, "rescan", "start", "-chain", "mvc", "-from", "100", "-watch", "-interval", "1ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 || !strings.Contains(out, "Started rescan_mvc_100_100_1") || !strings.Contains(out, "rescan report -failed rescan_mvc_100_100_1") {
+		t.Errorf("polls = %d, output:\n%s", polls, out)
+	}
+}
+
+func TestRescanReportFailed(t *testing.T) {
+	f := &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"GET /admin/rescan/task1/report": func(*http.Request) (int, interface{}) {
+			return -1, map[string]interface{}{
+				"task_id": "task1",
+				"blocks":  []map[string]interface{}{{"height": 10, "error": "rpc timeout"}, {"height": 11}},
+				"pins": []map[string]interface{}{
+					{"height": 11, "pin_id": "okpini0", "outcome": "indexed"},
+					{"height": 11, "pin_id": "badpini0", "path": "/file", "outcome": "failed", "reason": "storage down"},
+				},
+			}
+		},
+	}}
+	out, err := run(t, f, "rescan", "report", "-failed", "task1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "rpc timeout") || !strings.Contains(out, "badpini0") || strings.Contains(out, "okpini0") {
+		t.Errorf("report output:\n%s", out)
+	}
+
+	if _, err := run(t, &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"GET /admin/rescan/gone/report": func(*http.Request) (int, interface{}) { return 40400, nil },
+	}}, "rescan", "report", "gone"); err == nil || !strings.Contains(err.Error(), "40400") {
+		t.Errorf("missing report: err = %v", err)
+	}
+}
+
+func TestChainPause(t *testing.T) {
+	f := &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"POST /admin/chains/btc/pause": func(*http.Request) (int, interface{}) {
+			return 0, map[string]interface{}{"chain": "btc", "paused": true, "paused_at": 1767225600}
+		},
+	}}
+	out, err := run(t, f, "chain", "pause", "btc")
+	if err != nil || out != "btc: paused since 2026-01-01T00:00:00Z\n" {
+		t.Errorf("pause = %q, %v", out, err)
+	}
+
+	// Admin routes disabled
+	_, err = run(t, &fakeIndexer{}, "chain", "resume", "btc")
+	if err == nil || !strings.Contains(err.Error(), "admin_enabled") {
+		t.Errorf("disabled admin routes: err = %v", err)
+	}
+	if _, err := run(t, f, "chain", "stop", "btc"); err != errUsage {
+		t.Errorf("unknown action: err = %v, want usage", err)
+	}
+}
+
+func TestRepairLatest(t *testing.T) {
+	f := &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"POST /admin/latest/repair": func(r *http.Request) (int, interface{}) {
+			return 0, map[string]interface{}{
+				"drifts": []map[string]interface{}{{"collection": "latest_file_info", "key": "firsti0", "stored": "oldi0", "actual": "newi0"}},
+				"fixed":  r.URL.Query().Get("fix") == "true",
+			}
+		},
+	}}
+	out, err := run(t, f, "repair", "latest")
+	if err != nil || !strings.Contains(out, "newi0") || !strings.Contains(out, "run with -fix") {
+		t.Errorf("report only = %q, %v", out, err)
+	}
+	out, err = run(t, f, "repair", "latest", "-fix")
+	if err != nil || !strings.Contains(out, "1 drifted, rewritten") {
+		t.Errorf("fix = %q, %v", out, err)
+	}
+}
+
+func TestFailed(t *testing.T) {
+	f := &fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"GET /admin/storage/pending": func(*http.Request) (int, interface{}) {
+			return 0, map[string]interface{}{"total": 1, "writes": []map[string]interface{}{
+				{"pin_id": "storedi0", "chain_name": "mvc", "kind": "file", "attempts": 3, "last_error": "oss down"},
+			}}
+		},
+		"GET /admin/creator/pending": func(*http.Request) (int, interface{}) {
+			return 0, map[string]interface{}{"total": 1, "lookups": []map[string]interface{}{
+				{"pin_id": "creatori0", "chain_name": "btc", "fallback_address": "bc1qfallback", "attempts": 2},
+			}}
+		},
+	}}
+	out, err := run(t, f, "failed")
+	if err != nil || !strings.Contains(out, "storedi0") || !strings.Contains(out, "bc1qfallback") {
+		t.Errorf("failed = %q, %v", out, err)
+	}
+	out, err = run(t, f, "failed", "-chain", "btc")
+	if err != nil || strings.Contains(out, "storedi0") || !strings.Contains(out, "creatori0") {
+		t.Errorf("failed -chain btc = %q, %v", out, err)
+	}
+}
+
+func TestUnixSocketIndexer(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	ln, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(&fakeIndexer{routes: map[string]func(*http.Request) (int, interface{}){
+		"GET /status": func(*http.Request) (int, interface{}) {
+			return 0, map[string]interface{}{"chains": []map[string]interface{}{}}
+		},
+	}})
+	server.Listener = ln
+	server.Start()
+	t.Cleanup(server.Close)
+
+	if _, err := newAdminClient("unix:"+socket, 5*time.Second).get("/status"); err != nil {
+		t.Fatal(err)
+	}
+}
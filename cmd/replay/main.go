@@ -0,0 +1,330 @@
+// Command replay indexes historical blocks read from disk instead of the
+// node's RPC: from the block files of a bitcoind / mvcd data directory
+// (blocks/blk*.dat) or from a block archive exported earlier. Blocks go
+// through the indexer's parser and handleTransaction into the configured
+// database and storage, for fast local re-indexing and benchmarks that do
+// not load a live node. Run it with the indexer stopped.
+//
+//	replay -env mainnet -chain mvc -blocks-dir ~/.mvc/blocks -from 350000
+//	replay -env mainnet -chain btc -archive btc.blocks -export -from 800000 -to 800999
+//	replay -env mainnet -chain btc -archive btc.blocks
+//	replay -env mainnet -chain btc -archive btc.blocks -parse-only
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+	"meta-file-system/service/common_service/metaid"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/service/indexer_service"
+	"meta-file-system/storage"
+)
+
+var (
+	ENV             string
+	Chain           string
+	BlocksDir       string
+	Archive         string
+	FromHeight      int64
+	ToHeight        int64
+	Export          bool
+	ResolveCreators bool
+	ParseOnly       bool
+)
+
+func init() {
+	flag.StringVar(&ENV, "env", "mainnet", "Environment: loc/mainnet/testnet")
+	flag.StringVar(&Chain, "chain", "", "Chain of the blocks: btc or mvc")
+	flag.StringVar(&BlocksDir, "blocks-dir", "", "Blocks directory of a bitcoind / mvcd data directory (blk*.dat)")
+	flag.StringVar(&Archive, "archive", "", "Block archive to replay, or to write with -export")
+	flag.Int64Var(&FromHeight, "from", -1, "First height to replay (default: the first available)")
+	flag.Int64Var(&ToHeight, "to", -1, "Last height to replay (default: the last available)")
+	flag.BoolVar(&Export, "export", false, "Fetch -from to -to from the chain's node into -archive and exit")
+	flag.BoolVar(&ResolveCreators, "resolve-creators", false, "Look up creator addresses on the chain's node (default: queue them for the indexer's creator retrier)")
+	flag.BoolVar(&ParseOnly, "parse-only", false, "Only parse the blocks, writing nothing (parser benchmark)")
+}
+
+// replayStats running totals of a replay
+type replayStats struct {
+	blocks, txs, metaTxs, pins, persisted int64
+}
+
+func (r *replayStats) add(result indexer.PipelineResult) {
+	r.blocks++
+	r.txs += int64(result.Txs)
+	r.metaTxs += int64(result.MetaIDTxs)
+	r.pins += int64(result.Pins)
+	r.persisted += int64(result.Persisted)
+}
+
+// progressInterval how often replay progress is logged
+const progressInterval = 10 * time.Second
+
+func main() {
+	flag.Parse()
+	chainType := indexer.ChainType(strings.ToLower(Chain))
+	if chainType != indexer.ChainTypeBTC && chainType != indexer.ChainTypeMVC {
+		log.Fatalf("-chain must be btc or mvc (got %q)", Chain)
+	}
+	if (BlocksDir == "") == (Archive == "") {
+		log.Fatalf("Set one of -blocks-dir and -archive")
+	}
+
+	initEnv()
+	if err := conf.InitConfig(); err != nil {
+		log.Fatalf("Failed to initialize config: %v", err)
+	}
+
+	if Export {
+		if Archive == "" || FromHeight < 0 || ToHeight < FromHeight {
+			log.Fatalf("-export needs -archive, -from and -to")
+		}
+		exportArchive(chainType)
+		return
+	}
+
+	source, err := openSource(chainType)
+	if err != nil {
+		log.Fatalf("Failed to open blocks: %v", err)
+	}
+	defer source.Close()
+	first, last := source.Heights()
+	from, to := first, last
+	if FromHeight >= 0 {
+		from = FromHeight
+	}
+	if ToHeight >= 0 {
+		to = ToHeight
+	}
+	if from < first || to > last || from > to {
+		log.Fatalf("Cannot replay %d to %d: the blocks hold heights %d to %d", from, to, first, last)
+	}
+
+	// Stop after the current block on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	replayBlock := parseBlock(chainType)
+	if !ParseOnly {
+		cleanup := initIndexer()
+		defer cleanup()
+		var node *indexer.BlockScanner
+		if ResolveCreators {
+			node = newNodeClient(chainType)
+		}
+		stor, err := storage.NewStorage()
+		if err != nil {
+			log.Fatalf("Failed to initialize storage: %v", err)
+		}
+		replayBlock = indexer_service.NewReplayer(stor, chainType, node).ReplayBlock
+	}
+
+	mode := "indexing"
+	if ParseOnly {
+		mode = "parse only"
+	}
+	log.Printf("Replaying %s blocks %d to %d (%s)", chainType, from, to, mode)
+	var stats replayStats
+	start := time.Now()
+	lastLog := start
+	height := from
+	for ; height <= to && ctx.Err() == nil; height++ {
+		raw, err := source.RawBlock(height)
+		if err != nil {
+			log.Fatalf("Failed to read block %d: %v", height, err)
+		}
+		block, _, err := indexer.DecodeBlock(chainType, raw)
+		if err != nil {
+			log.Fatalf("Block %d: %v", height, err)
+		}
+		result, err := replayBlock(block, height)
+		if err != nil {
+			log.Fatalf("Failed to replay block %d: %v", height, err)
+		}
+		stats.add(result)
+
+		if time.Since(lastLog) >= progressInterval {
+			lastLog = time.Now()
+			logProgress("Replayed", height, &stats, lastLog.Sub(start))
+		}
+	}
+	if ctx.Err() != nil {
+		log.Printf("Interrupted after block %d; resume with -from %d", height-1, height)
+	}
+	logProgress("Done:", height-1, &stats, time.Since(start))
+}
+
+func logProgress(prefix string, height int64, stats *replayStats, elapsed time.Duration) {
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		seconds = 1e-9
+	}
+	log.Printf("%s %d blocks up to height %d in %s: %d transactions, %d MetaID transactions, %d PINs, %d persisted (%.1f blocks/s, %.0f tx/s)",
+		prefix, stats.blocks, height, elapsed.Round(time.Millisecond), stats.txs, stats.metaTxs, stats.pins, stats.persisted,
+		float64(stats.blocks)/seconds, float64(stats.txs)/seconds)
+}
+
+// parseBlock the -parse-only replay: the block pipeline without creator
+// lookups and with nothing persisted
+func parseBlock(chainType indexer.ChainType) func(block interface{}, height int64) (indexer.PipelineResult, error) {
+	cfg := conf.Cfg.Indexer.Pipeline
+	pipeline := indexer.NewBlockPipeline(chainType, indexer.PipelineConfig{
+		FetchWorkers:   cfg.FetchWorkers,
+		ParseWorkers:   cfg.ParseWorkers,
+		ResolveWorkers: cfg.ResolveWorkers,
+		QueueSize:      cfg.QueueSize,
+	}, nil)
+	return func(block interface{}, _ int64) (indexer.PipelineResult, error) {
+		return pipeline.Run(block, nil, func(interface{}, *indexer.MetaIDDataTx) error { return nil })
+	}
+}
+
+// openSource opens -blocks-dir or -archive
+func openSource(chainType indexer.ChainType) (indexer.BlockSource, error) {
+	if BlocksDir != "" {
+		log.Printf("Indexing the block files in %s...", BlocksDir)
+		files, err := indexer.OpenBlockFiles(BlocksDir)
+		if err != nil {
+			return nil, err
+		}
+		_, tip := files.Heights()
+		log.Printf("Block files hold the chain up to height %d", tip)
+		return files, nil
+	}
+	archive, err := indexer.OpenBlockArchive(Archive)
+	if err != nil {
+		return nil, err
+	}
+	if archive.Chain() != string(chainType) {
+		archive.Close()
+		return nil, fmt.Errorf("%s holds %s blocks, not %s", Archive, archive.Chain(), chainType)
+	}
+	return archive, nil
+}
+
+// exportArchive fetches -from to -to from the chain's node into -archive
+func exportArchive(chainType indexer.ChainType) {
+	node := newNodeClient(chainType)
+	w, err := indexer.CreateBlockArchive(Archive, string(chainType))
+	if err != nil {
+		log.Fatalf("Failed to create archive: %v", err)
+	}
+	start := time.Now()
+	lastLog := start
+	var size int64
+	for height := FromHeight; height <= ToHeight; height++ {
+		hash, err := node.GetBlockhash(height)
+		if err != nil {
+			log.Fatalf("Failed to get block hash %d: %v", height, err)
+		}
+		blockHex, err := node.GetBlockHex(hash)
+		if err != nil {
+			log.Fatalf("Failed to get block %d: %v", height, err)
+		}
+		raw, err := hex.DecodeString(blockHex)
+		if err != nil {
+			log.Fatalf("Failed to decode block %d: %v", height, err)
+		}
+		if err := w.Append(height, raw); err != nil {
+			log.Fatalf("Failed to write block %d: %v", height, err)
+		}
+		size += int64(len(raw))
+		if time.Since(lastLog) >= progressInterval {
+			lastLog = time.Now()
+			log.Printf("Exported blocks %d to %d (%d MB)", FromHeight, height, size>>20)
+		}
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("Failed to write archive: %v", err)
+	}
+	log.Printf("✅ Exported %s blocks %d to %d (%d MB) to %s in %s", chainType, FromHeight, ToHeight, size>>20, Archive, time.Since(start).Round(time.Second))
+}
+
+// newNodeClient an RPC client of the chain's node (indexer.chains, or the
+// single-chain chain config)
+func newNodeClient(chainType indexer.ChainType) *indexer.BlockScanner {
+	rpc := conf.Cfg.Chain
+	for _, chain := range conf.Cfg.Indexer.Chains {
+		if strings.EqualFold(chain.Name, string(chainType)) {
+			rpc = conf.ChainConfig{RpcUrl: chain.RpcUrl, RpcUser: chain.RpcUser, RpcPass: chain.RpcPass}
+		}
+	}
+	if rpc.RpcUrl == "" {
+		log.Fatalf("No RPC configured for %s", chainType)
+	}
+	return indexer.NewBlockScannerWithChain(rpc.RpcUrl, rpc.RpcUser, rpc.RpcPass, 0, conf.Cfg.Indexer.ScanInterval, chainType)
+}
+
+// initEnv selects the config file
+func initEnv() {
+	switch ENV {
+	case "loc":
+		conf.SystemEnvironmentEnum = conf.LocalEnvironmentEnum
+	case "mainnet":
+		conf.SystemEnvironmentEnum = conf.MainnetEnvironmentEnum
+	case "testnet":
+		conf.SystemEnvironmentEnum = conf.TestnetEnvironmentEnum
+	case "example":
+		conf.SystemEnvironmentEnum = conf.ExampleEnvironmentEnum
+	}
+	fmt.Printf("Environment: %s\n", ENV)
+}
+
+// initIndexer prepares the indexer database as the indexer does at startup:
+// path filter, MetaID derivation and schema migrations
+func initIndexer() func() {
+	if err := metaid_protocols.SetPathFilter(conf.Cfg.Indexer.PathAllowlist, conf.Cfg.Indexer.PathDenylist); err != nil {
+		log.Fatalf("Invalid indexer path filter: %v", err)
+	}
+	if err := initDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	if err := metaid.SetDerivation(conf.Cfg.MetaID.Derivation, database.DB); err != nil {
+		log.Fatalf("Invalid metaid.derivation: %v", err)
+	}
+	if metaid.CurrentDerivation() == metaid.GenesisTxID && database.DBType(conf.Cfg.Database.IndexerType) != database.DBTypePebble {
+		log.Fatalf("metaid.derivation %s needs the pebble indexer database", metaid.GenesisTxID)
+	}
+	if err := indexer_service.NewMigrateService().Run(); err != nil {
+		log.Fatalf("Failed to run schema migrations: %v", err)
+	}
+	if err := storage.ValidateLayout(); err != nil {
+		log.Fatalf("Invalid storage layout: %v", err)
+	}
+	return func() {
+		database.DB.Close()
+	}
+}
+
+// initDatabase opens the indexer database (database.indexer_type)
+func initDatabase() error {
+	switch database.DBType(conf.Cfg.Database.IndexerType) {
+	case database.DBTypePebble:
+		tuning := conf.Cfg.Database.Pebble
+		return database.InitDatabase(database.DBTypePebble, &database.PebbleConfig{
+			DataDir:                  conf.Cfg.Database.DataDir,
+			CacheSize:                int64(tuning.CacheMB) << 20,
+			MemTableSize:             uint64(max(tuning.MemTableMB, 0)) << 20,
+			MaxConcurrentCompactions: tuning.MaxConcurrentCompactions,
+		})
+	case database.DBTypeSQLite:
+		return database.InitDatabase(database.DBTypeSQLite, &database.SQLiteConfig{Path: conf.Cfg.Database.SqlitePath})
+	default:
+		return database.InitDatabase(database.DBTypeMySQL, &database.MySQLConfig{
+			DSN:          conf.Cfg.Database.Dsn,
+			MaxOpenConns: conf.Cfg.Database.MaxOpenConns,
+			MaxIdleConns: conf.Cfg.Database.MaxIdleConns,
+		})
+	}
+}
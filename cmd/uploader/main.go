@@ -1,27 +1,32 @@
 package main
 
 import (
-	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"meta-file-system/conf"
 	"meta-file-system/controller"
 	"meta-file-system/database"
+	"meta-file-system/listener"
+	"meta-file-system/notify"
+	"meta-file-system/service/common_service/metaid"
 	"meta-file-system/service/upload_service"
+	"meta-file-system/shutdown"
 	"meta-file-system/storage"
 )
 
-var ENV string
+var (
+	ENV            string
+	RecoverUploads bool
+)
 
 func init() {
 	flag.StringVar(&ENV, "env", "loc", "Environment: loc/mainnet/testnet")
+	flag.BoolVar(&RecoverUploads, "recover-uploads", false, "Resume all interrupted synchronous uploads, then exit")
 }
 
 // @title           Meta File System Uploader API
@@ -37,27 +42,35 @@ func init() {
 // @license.url   http://www.apache.org/licenses/LICENSE-2.0.html
 
 // @host      localhost:7282
-// @BasePath  /api/v1
+// @BasePath  /api
 
 // @schemes https http
 
 func main() {
 	// Initialize all components
-	srv, cleanup := initAll()
+	router, shutdowns, cleanup := initAll()
 	defer cleanup()
 
-	// Start server (in goroutine)
-	go startServer(srv)
+	// Start server on every listener (uploader.listeners)
+	listeners, err := listener.Start("Uploader service", conf.Cfg.Uploader.Listeners, router)
+	if err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
 
 	log.Println("Uploader service started successfully")
 
-	// Wait for shutdown signal
-	waitForShutdown()
+	shutdowns.Add("HTTP listeners", listeners.Shutdown)
+
+	// Wait for shutdown signal; SIGHUP reloads the listener certificates
+	shutdown.WaitForSignal(listeners.ReloadCertificates)
 
 	log.Println("Shutting down uploader service...")
 
-	// Graceful shutdown
-	shutdownServer(srv)
+	// Graceful shutdown: HTTP requests drain while upload tasks reach their
+	// next stage
+	if err := shutdowns.Shutdown(); err != nil {
+		log.Printf("Shutdown incomplete: %v", err)
+	}
 
 	log.Println("Server exited")
 }
@@ -76,8 +89,9 @@ func initEnv() {
 	fmt.Printf("Environment: %s\n", ENV)
 }
 
-// initAll initialize all components
-func initAll() (*http.Server, func()) {
+// initAll initialize all components. The background processors are
+// registered with the returned shutdown manager; cleanup closes the databases.
+func initAll() (http.Handler, *shutdown.Manager, func()) {
 	// Parse command line parameters
 	flag.Parse()
 
@@ -88,14 +102,33 @@ func initAll() (*http.Server, func()) {
 	if err := conf.InitConfig(); err != nil {
 		log.Fatalf("Failed to initialize config: %v", err)
 	}
+	if err := conf.Cfg.Validate(conf.ServiceUploader); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 	log.Printf("Configuration loaded: env=%s, net=%s, port=%s", ENV, conf.Cfg.Net, conf.Cfg.UploaderPort)
 
-	// Initialize database (Uploader always uses MySQL)
+	// MetaID derivation (metaid.derivation). The uploader has no genesis
+	// records: under genesis_txid it keeps the MetaIDs clients send
+	if err := metaid.SetDerivation(conf.Cfg.MetaID.Derivation, nil); err != nil {
+		log.Fatalf("Invalid metaid.derivation: %v", err)
+	}
+
+	// Initialize database (MySQL, or SQLite with database.uploader_type: sqlite)
 	if err := database.InitUploaderDB(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	// Initialize storage
+	// Redis shares upload rate limit buckets between instances (optional, falls back to in-memory buckets)
+	if conf.Cfg.Uploader.RateLimit.Enabled {
+		if err := database.InitRedis(); err != nil {
+			log.Printf("⚠️  Redis initialization failed (upload rate limits will be per instance): %v", err)
+		}
+	}
+
+	// Operational alerts (notify.email / notify.telegram)
+	notify.Init("uploader")
+
+	// Initialize storage (the watch-only gateway is refused by Validate)
 	stor, err := storage.NewStorage()
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
@@ -105,10 +138,11 @@ func initAll() (*http.Server, func()) {
 	// Setup upload service router
 	router, uploadService := controller.SetupUploadRouter(stor)
 
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:    ":" + conf.Cfg.UploaderPort,
-		Handler: router,
+	// One-off recovery run: no server or background processors
+	if RecoverUploads {
+		recoverUploads(uploadService)
+		database.CloseUploaderDB()
+		os.Exit(0)
 	}
 
 	// Start task processor
@@ -121,37 +155,33 @@ func initAll() (*http.Server, func()) {
 	cleanupProcessor.Start()
 	log.Println("Cleanup processor started")
 
-	// Return server instance and cleanup function
+	// Start broadcast scheduler (releases tasks with broadcastAt / targetFeeRate)
+	broadcastScheduler := upload_service.NewBroadcastScheduler()
+	broadcastScheduler.Start()
+
+	// Components stopped together on shutdown, within shutdown.timeout_secs
+	shutdowns := shutdown.NewManager(time.Duration(conf.Cfg.Shutdown.TimeoutSecs) * time.Second)
+	shutdowns.Add("task processor", taskProcessor.Shutdown)
+	shutdowns.Add("cleanup processor", cleanupProcessor.Shutdown)
+	shutdowns.Add("broadcast scheduler", broadcastScheduler.Shutdown)
+
+	// Return router, shutdown manager and cleanup function
 	cleanup := func() {
-		taskProcessor.Stop()
-		cleanupProcessor.Stop()
 		database.CloseUploaderDB()
+		database.CloseRedis()
 	}
 
-	return srv, cleanup
+	return router, shutdowns, cleanup
 }
 
-// startServer start HTTP server
-func startServer(srv *http.Server) {
-	log.Printf("Uploader service starting on port %s...", conf.Cfg.UploaderPort)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Failed to start server: %v", err)
+// recoverUploads resume every pending synchronous upload checkpoint
+func recoverUploads(uploadService *upload_service.UploadService) {
+	resp, err := uploadService.RecoverInFlightUploads(0, upload_service.MaxRecoverLimit)
+	if err != nil {
+		log.Fatalf("Failed to recover uploads: %v", err)
 	}
-}
-
-// waitForShutdown wait for shutdown signal
-func waitForShutdown() {
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	<-sigChan
-}
-
-// shutdownServer gracefully shutdown server
-func shutdownServer(srv *http.Server) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("Server forced to shutdown: %v", err)
+	for _, upload := range resp.Uploads {
+		log.Printf("Recovered upload: FileId=%s, fromStage=%s, status=%s %s", upload.FileId, upload.FromStage, upload.Status, upload.Error)
 	}
+	log.Printf("Upload recovery finished: %d uploads, %d succeeded, %d failed", len(resp.Uploads), resp.Succeeded, resp.Failed)
 }
@@ -0,0 +1,172 @@
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"meta-file-system/common/scripts"
+)
+
+// Placeholder signature sizes used to estimate the signed size of BTC inputs
+var (
+	btcSegwitWitnessSize  = wire.TxWitness{make([]byte, 72), make([]byte, 33)}.SerializeSize()
+	btcTaprootWitnessSize = wire.TxWitness{make([]byte, 64)}.SerializeSize()
+)
+
+// btcLegacySignatureScriptSize size of a P2PKH signature script (signature + compressed pubkey)
+const btcLegacySignatureScriptSize = 107
+
+// BtcTxBuilder ChainTxBuilder for BTC (btcd wire types, sat/vbyte fee rates).
+// Inputs are signed per SignMode: legacy (P2PKH), segwit (P2WPKH, the
+// default) or taproot (key path).
+type BtcTxBuilder struct {
+	NetParam *chaincfg.Params
+}
+
+// NewBtcTxBuilder returns the BTC builder for net ("mainnet" or testnet)
+func NewBtcTxBuilder(net string) *BtcTxBuilder {
+	if net == "mainnet" {
+		return &BtcTxBuilder{NetParam: &chaincfg.MainNetParams}
+	}
+	return &BtcTxBuilder{NetParam: &chaincfg.TestNet3Params}
+}
+
+func (b *BtcTxBuilder) Chain() string { return "btc" }
+
+func (b *BtcTxBuilder) PayToAddrScript(address string) ([]byte, error) {
+	return scripts.PayToBtcAddress(address, b.NetParam)
+}
+
+func (b *BtcTxBuilder) NewTx() ChainTxDraft {
+	return &BtcTx{MsgTx: wire.NewMsgTx(2)}
+}
+
+func (b *BtcTxBuilder) Fee(size int, feeRate int64) int64 {
+	return int64(size) * feeRate
+}
+
+// BtcTx BTC ChainTxDraft; MsgTx is the underlying btcd transaction
+type BtcTx struct {
+	*wire.MsgTx
+	ins []*TxInputUtxo
+}
+
+func (t *BtcTx) TxId() string { return t.TxHash().String() }
+
+func (t *BtcTx) Raw() (string, error) { return ToRaw(t.MsgTx) }
+
+// Size returns the virtual size
+func (t *BtcTx) Size() int {
+	return btcVirtualSize(t.SerializeSizeStripped(), t.SerializeSize())
+}
+
+func (t *BtcTx) OutputCount() int { return len(t.TxOut) }
+
+func (t *BtcTx) OutputValue(i int) int64 { return t.TxOut[i].Value }
+
+func (t *BtcTx) AddOutput(pkScript []byte, value int64) {
+	t.AddTxOut(wire.NewTxOut(value, pkScript))
+}
+
+func (t *BtcTx) SetOutputValue(i int, value int64) { t.TxOut[i].Value = value }
+
+func (t *BtcTx) TruncateOutputs(n int) { t.TxOut = t.TxOut[:n] }
+
+func (t *BtcTx) AddInput(in *TxInputUtxo) error {
+	hash, err := chainhash.NewHashFromStr(in.TxId)
+	if err != nil {
+		return err
+	}
+	t.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, uint32(in.TxIndex)), nil, nil))
+	t.ins = append(t.ins, in)
+	return nil
+}
+
+func (t *BtcTx) SignedSize() int {
+	baseSize, totalSize := t.SerializeSizeStripped(), t.SerializeSize()
+	hasWitness := t.HasWitness()
+	for i, txIn := range t.TxIn {
+		if len(txIn.SignatureScript) > 0 || len(txIn.Witness) > 0 {
+			continue
+		}
+		switch t.ins[i].SignMode {
+		case SignModeLegacy:
+			n := btcLegacySignatureScriptSize + wire.VarIntSerializeSize(btcLegacySignatureScriptSize) - 1
+			baseSize += n
+			totalSize += n
+		case SignModeTaproot:
+			totalSize += btcTaprootWitnessSize
+		default:
+			totalSize += btcSegwitWitnessSize
+		}
+	}
+	if !hasWitness && totalSize > baseSize {
+		// Marker, flag and the empty witness count of every other input
+		totalSize += 2
+		for i, txIn := range t.TxIn {
+			if len(txIn.Witness) == 0 && t.ins[i].SignMode == SignModeLegacy {
+				totalSize++
+			}
+		}
+	}
+	return btcVirtualSize(baseSize, totalSize)
+}
+
+func (t *BtcTx) Sign() error {
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(nil)
+	pkScripts := make([][]byte, len(t.ins))
+	for i, in := range t.ins {
+		pkScript, err := hex.DecodeString(in.PkScript)
+		if err != nil {
+			return err
+		}
+		pkScripts[i] = pkScript
+		prevOutFetcher.AddPrevOut(t.TxIn[i].PreviousOutPoint, wire.NewTxOut(int64(in.Amount), pkScript))
+	}
+	sigHashes := txscript.NewTxSigHashes(t.MsgTx, prevOutFetcher)
+
+	for i, in := range t.ins {
+		privateKeyBytes, err := hex.DecodeString(in.PriHex)
+		if err != nil {
+			return err
+		}
+		privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
+
+		switch in.SignMode {
+		case SignModeLegacy:
+			sigScript, err := txscript.SignatureScript(t.MsgTx, i, pkScripts[i], txscript.SigHashAll, privateKey, true)
+			if err != nil {
+				return err
+			}
+			t.TxIn[i].SignatureScript = sigScript
+		case SignModeTaproot:
+			witness, err := txscript.TaprootWitnessSignature(t.MsgTx, sigHashes, i, int64(in.Amount), pkScripts[i], txscript.SigHashDefault, privateKey)
+			if err != nil {
+				return err
+			}
+			t.TxIn[i].Witness = witness
+		case SignModeSegwit, "":
+			witness, err := txscript.WitnessSignature(t.MsgTx, sigHashes, i, int64(in.Amount), pkScripts[i], txscript.SigHashAll, privateKey, true)
+			if err != nil {
+				return err
+			}
+			t.TxIn[i].Witness = witness
+		default:
+			return fmt.Errorf("unsupported sign mode: %s", in.SignMode)
+		}
+	}
+	return nil
+}
+
+// btcVirtualSize converts stripped and total sizes to vbytes (BIP 141)
+func btcVirtualSize(baseSize, totalSize int) int {
+	weight := baseSize*(blockchain.WitnessScaleFactor-1) + totalSize
+	return (weight + blockchain.WitnessScaleFactor - 1) / blockchain.WitnessScaleFactor
+}
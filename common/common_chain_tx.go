@@ -0,0 +1,182 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"meta-file-system/common/scripts"
+)
+
+// DustLimit change below this value is dropped into the fee
+const DustLimit = 600
+
+// ChainTx is a built transaction, independent of the chain's wire types
+type ChainTx interface {
+	// TxId returns the transaction id as used by the chain's explorers
+	TxId() string
+	// Raw returns the serialized transaction hex
+	Raw() (string, error)
+	// Size returns the fee-relevant size (bytes, or vbytes on segwit chains)
+	Size() int
+	// OutputCount returns the number of outputs
+	OutputCount() int
+	// OutputValue returns the value of output i
+	OutputValue(i int) int64
+}
+
+// ChainTxDraft is a ChainTx under construction
+type ChainTxDraft interface {
+	ChainTx
+	AddOutput(pkScript []byte, value int64)
+	SetOutputValue(i int, value int64)
+	// TruncateOutputs keeps the first n outputs
+	TruncateOutputs(n int)
+	// AddInput spends in; PkScript, Amount and SignMode are kept for signing
+	AddInput(in *TxInputUtxo) error
+	// SignedSize returns Size() as it will be once every input is signed
+	SignedSize() int
+	// Sign signs every input with its PriHex
+	Sign() error
+}
+
+// ChainTxBuilder creates transactions for one chain. Implementations:
+// MvcTxBuilder, BtcTxBuilder.
+type ChainTxBuilder interface {
+	// Chain returns the chain name (mvc, btc)
+	Chain() string
+	// PayToAddrScript returns the locking script paying address on this chain
+	PayToAddrScript(address string) ([]byte, error)
+	// NewTx returns an empty transaction
+	NewTx() ChainTxDraft
+	// Fee returns the fee of a transaction of size at feeRate
+	Fee(size int, feeRate int64) int64
+}
+
+// MetaIdPayload MetaID protocol fields carried in an OP_RETURN output
+type MetaIdPayload struct {
+	Operation   string // create/modify/revoke (default create)
+	Path        string
+	Encryption  string // default "0"
+	Version     string // default "1.0.0"
+	ContentType string
+	Content     []byte
+}
+
+// BuildMetaIdScript builds the OP_0 OP_RETURN MetaID script of p
+func BuildMetaIdScript(p *MetaIdPayload) ([]byte, error) {
+	return scripts.MetaIdOpReturn(&scripts.MetaId{
+		Operation:   p.Operation,
+		Path:        p.Path,
+		Encryption:  p.Encryption,
+		Version:     p.Version,
+		ContentType: p.ContentType,
+		Payload:     p.Content,
+	}), nil
+}
+
+// BuildChainTx builds a transaction on b's chain: outs, then the MetaID
+// output of payload (if any), then otherOuts, then change to changeAddress
+// (dropped below DustLimit). The fee is charged on the signed size. With
+// isUnSign the inputs are left unsigned.
+func BuildChainTx(b ChainTxBuilder, ins []*TxInputUtxo, outs []*TxOutput, payload *MetaIdPayload, otherOuts []*TxOutput, changeAddress string, feeRate int64, isUnSign bool) (ChainTxDraft, error) {
+	tx := b.NewTx()
+	outAmount := int64(0)
+	addOutputs := func(outputs []*TxOutput) error {
+		for _, out := range outputs {
+			pkScript, err := b.PayToAddrScript(out.Address)
+			if err != nil {
+				return err
+			}
+			tx.AddOutput(pkScript, out.Amount)
+			outAmount += out.Amount
+		}
+		return nil
+	}
+
+	if err := addOutputs(outs); err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		script, err := BuildMetaIdScript(payload)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddOutput(script, 0)
+	}
+	if err := addOutputs(otherOuts); err != nil {
+		return nil, err
+	}
+	if changeAddress != "" {
+		pkScript, err := b.PayToAddrScript(changeAddress)
+		if err != nil {
+			return nil, err
+		}
+		tx.AddOutput(pkScript, 0)
+	}
+
+	totalAmount := int64(0)
+	for _, in := range ins {
+		if err := tx.AddInput(in); err != nil {
+			return nil, err
+		}
+		totalAmount += int64(in.Amount)
+	}
+
+	txFee := b.Fee(tx.SignedSize(), feeRate)
+	if totalAmount-outAmount < txFee {
+		return nil, errors.New("insufficient fee")
+	}
+	if changeAddress != "" {
+		changeVal := totalAmount - outAmount - txFee
+		if changeVal >= DustLimit {
+			tx.SetOutputValue(tx.OutputCount()-1, changeVal)
+		} else {
+			tx.TruncateOutputs(tx.OutputCount() - 1)
+		}
+	}
+
+	if !isUnSign {
+		if err := tx.Sign(); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// EstimateChainTxFee estimates the fee of a transaction spending one input
+// per entry of inputModes, paying outputScripts and carrying payload (may be
+// nil). No keys or real outpoints are needed.
+func EstimateChainTxFee(b ChainTxBuilder, inputModes []SignMode, outputScripts [][]byte, payload *MetaIdPayload, feeRate int64) (int64, int, error) {
+	tx := b.NewTx()
+	for _, script := range outputScripts {
+		tx.AddOutput(script, 0)
+	}
+	if payload != nil {
+		script, err := BuildMetaIdScript(payload)
+		if err != nil {
+			return 0, 0, err
+		}
+		tx.AddOutput(script, 0)
+	}
+	for i, mode := range inputModes {
+		placeholder := &TxInputUtxo{TxId: strings.Repeat("0", 64), TxIndex: int64(i), SignMode: mode}
+		if err := tx.AddInput(placeholder); err != nil {
+			return 0, 0, err
+		}
+	}
+	size := tx.SignedSize()
+	return b.Fee(size, feeRate), size, nil
+}
+
+// NewChainTxBuilder returns the builder of chain on the given network
+// ("mainnet" or anything else for testnet)
+func NewChainTxBuilder(chain, net string) (ChainTxBuilder, error) {
+	switch chain {
+	case "mvc":
+		return NewMvcTxBuilder(net), nil
+	case "btc":
+		return NewBtcTxBuilder(net), nil
+	}
+	return nil, fmt.Errorf("unsupported chain: %s", chain)
+}
@@ -0,0 +1,228 @@
+package common
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestBuildMetaIdScript(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 1100)
+	script, err := BuildMetaIdScript(&MetaIdPayload{Path: "/file", ContentType: "text/plain", Content: content})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushes, err := txscript.PushedData(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"", "metaid", "create", "/file", "0", "1.0.0", "text/plain"}
+	if len(pushes) != len(want)+3 {
+		t.Fatalf("got %d pushes, want %d", len(pushes), len(want)+3)
+	}
+	for i, w := range want {
+		if string(pushes[i]) != w {
+			t.Errorf("push %d = %q, want %q", i, pushes[i], w)
+		}
+	}
+	if got := bytes.Join(pushes[len(want):], nil); !bytes.Equal(got, content) {
+		t.Error("payload pushes do not reassemble the content")
+	}
+	if script[1] != txscript.OP_RETURN {
+		t.Errorf("script[1] = %x, want OP_RETURN", script[1])
+	}
+}
+
+func TestBuildChainTx_Mvc(t *testing.T) {
+	netParam := &chaincfg2.TestNet3Params
+	privateKey, err := bsvec2.NewPrivateKey(bsvec2.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := bsvutil2.NewAddressPubKeyHash(bsvutil2.Hash160(privateKey.PubKey().SerializeCompressed()), netParam)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &MvcTxBuilder{NetParam: netParam}
+	pkScript, err := b.PayToAddrScript(addr.EncodeAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := func(amount uint64) []*TxInputUtxo {
+		return []*TxInputUtxo{{
+			TxId:     strings.Repeat("ab", 32),
+			PkScript: hex.EncodeToString(pkScript),
+			Amount:   amount,
+			PriHex:   hex.EncodeToString(privateKey.Serialize()),
+		}}
+	}
+	payload := &MetaIdPayload{Path: "/info/name", ContentType: "text/plain", Content: []byte("alice")}
+
+	tests := []struct {
+		name       string
+		amount     uint64
+		wantErr    bool
+		wantChange bool
+	}{
+		{"with change", 100000, false, true},
+		{"change below dust", 700, false, false},
+		{"insufficient fee", 100, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := BuildChainTx(b, input(tt.amount), nil, payload, nil, addr.EncodeAddress(), 1, false)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BuildChainTx: %v", err)
+			}
+			if gotChange := tx.OutputCount() == 2; gotChange != tt.wantChange {
+				t.Fatalf("outputs = %d, want change %v", tx.OutputCount(), tt.wantChange)
+			}
+			if len(tx.(*MvcTx).TxIn[0].SignatureScript) == 0 {
+				t.Fatal("input not signed")
+			}
+			// The placeholder signature never undercharges, and overcharges by
+			// at most two bytes when the DER signature is short
+			if tt.wantChange {
+				fee := int64(tt.amount) - tx.OutputValue(1)
+				if fee < int64(tx.Size()) || fee > int64(tx.Size())+2 {
+					t.Errorf("fee %d outside [%d, %d]", fee, tx.Size(), tx.Size()+2)
+				}
+			}
+			raw, err := tx.Raw()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.TxId() != GetMvcTxhashFromRaw(raw) {
+				t.Errorf("TxId %s does not match raw hash", tx.TxId())
+			}
+		})
+	}
+}
+
+func TestBuildChainTx_BtcSignModes(t *testing.T) {
+	netParam := &chaincfg.TestNet3Params
+	b := &BtcTxBuilder{NetParam: netParam}
+
+	newInput := func(t *testing.T, mode SignMode, index int64) (*TxInputUtxo, btcutil.Address) {
+		privateKey, err := btcec.NewPrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubKey := privateKey.PubKey().SerializeCompressed()
+		var addr btcutil.Address
+		switch mode {
+		case SignModeLegacy:
+			addr, err = btcutil.NewAddressPubKeyHash(btcutil.Hash160(pubKey), netParam)
+		case SignModeTaproot:
+			addr, err = btcutil.NewAddressTaproot(schnorr.SerializePubKey(txscript.ComputeTaprootKeyNoScript(privateKey.PubKey())), netParam)
+		default:
+			addr, err = btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubKey), netParam)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &TxInputUtxo{
+			TxId:     strings.Repeat("cd", 32),
+			TxIndex:  index,
+			PkScript: hex.EncodeToString(pkScript),
+			Amount:   50000,
+			PriHex:   hex.EncodeToString(privateKey.Serialize()),
+			SignMode: mode,
+		}, addr
+	}
+
+	tests := []struct {
+		name  string
+		modes []SignMode
+	}{
+		{"legacy", []SignMode{SignModeLegacy}},
+		{"segwit", []SignMode{SignModeSegwit}},
+		{"taproot", []SignMode{SignModeTaproot}},
+		{"mixed", []SignMode{SignModeLegacy, SignModeSegwit, SignModeTaproot}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ins []*TxInputUtxo
+			var changeAddress string
+			for i, mode := range tt.modes {
+				in, addr := newInput(t, mode, int64(i))
+				ins = append(ins, in)
+				changeAddress = addr.EncodeAddress()
+			}
+			payload := &MetaIdPayload{Path: "/info/name", ContentType: "text/plain", Content: []byte("bob")}
+
+			changeScript, err := b.PayToAddrScript(changeAddress)
+			if err != nil {
+				t.Fatal(err)
+			}
+			estimate, _, err := EstimateChainTxFee(b, tt.modes, [][]byte{changeScript}, payload, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			built, err := BuildChainTx(b, ins, nil, payload, nil, changeAddress, 2, false)
+			if err != nil {
+				t.Fatalf("BuildChainTx: %v", err)
+			}
+			tx := built.(*BtcTx)
+
+			prevOuts := txscript.NewMultiPrevOutFetcher(nil)
+			for i, in := range ins {
+				pkScript, _ := hex.DecodeString(in.PkScript)
+				prevOuts.AddPrevOut(tx.TxIn[i].PreviousOutPoint, wire.NewTxOut(int64(in.Amount), pkScript))
+			}
+			sigHashes := txscript.NewTxSigHashes(tx.MsgTx, prevOuts)
+			for i, in := range ins {
+				pkScript, _ := hex.DecodeString(in.PkScript)
+				vm, err := txscript.NewEngine(pkScript, tx.MsgTx, i, txscript.StandardVerifyFlags, nil, sigHashes, int64(in.Amount), prevOuts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := vm.Execute(); err != nil {
+					t.Errorf("input %d (%s) does not verify: %v", i, in.SignMode, err)
+				}
+			}
+
+			// Estimates are within two bytes per input of the real vsize
+			if d := tx.SignedSize() - tx.Size(); d < 0 || d > 2*len(ins) {
+				t.Errorf("signed size estimate %d, actual %d", tx.SignedSize(), tx.Size())
+			}
+			fee := int64(len(ins))*50000 - tx.OutputValue(tx.OutputCount()-1)
+			if fee != estimate {
+				t.Errorf("fee = %d, estimate %d", fee, estimate)
+			}
+		})
+	}
+}
+
+func TestNewChainTxBuilder(t *testing.T) {
+	for _, chain := range []string{"mvc", "btc"} {
+		b, err := NewChainTxBuilder(chain, "testnet")
+		if err != nil || b.Chain() != chain {
+			t.Errorf("NewChainTxBuilder(%s) = %v, %v", chain, b, err)
+		}
+	}
+	if _, err := NewChainTxBuilder("doge", "testnet"); err == nil {
+		t.Error("expected error for unsupported chain")
+	}
+}
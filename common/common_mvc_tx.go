@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
-	"errors"
 	"fmt"
+	"meta-file-system/common/scripts"
 	"meta-file-system/tool"
 
 	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
@@ -14,7 +14,6 @@ import (
 	txscript2 "github.com/bitcoinsv/bsvd/txscript"
 	wire2 "github.com/bitcoinsv/bsvd/wire"
 	bsvutil2 "github.com/bitcoinsv/bsvutil"
-	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 )
 
@@ -40,133 +39,21 @@ const (
 	SignModeLegacy  SignMode = "legacy"
 )
 
+// BuildMvcCommonMetaIdTx builds a MetaID transaction on MVC, paying the fee
+// from ins and returning change to changeAddress
 func BuildMvcCommonMetaIdTx(netParam *chaincfg2.Params, ins []*TxInputUtxo, outs, otherOuts []*TxOutput, operation, path string, content []byte, changeAddress string, feeRate int64, isUnSign bool) (*wire2.MsgTx, error) {
-	tx := wire2.NewMsgTx(10)
-	totalAmount := int64(0)
-	outAmount := int64(0)
-	for _, out := range outs {
-		addr, err := bsvutil2.DecodeAddress(out.Address, netParam)
-		if err != nil {
-			return nil, err
-		}
-		pkScript, err := txscript2.PayToAddrScript(addr)
-		if err != nil {
-			return nil, err
-		}
-		tx.AddTxOut(wire2.NewTxOut(out.Amount, pkScript))
-		outAmount = outAmount + out.Amount
-	}
-
-	if operation == "" {
-		operation = "create"
-	}
-	inscriptionBuilder := txscript.NewScriptBuilder().
-		AddOp(txscript.OP_0).
-		AddOp(txscript.OP_RETURN).
-		AddData([]byte("metaid")). //<metaid_flag>
-		AddData([]byte(operation)) //<operation>
-
-	//inscriptionBuilder.AddData([]byte("/file/my-bitcoin.png")) //<path>
-	inscriptionBuilder.AddData([]byte(path))    //<path>
-	inscriptionBuilder.AddData([]byte("0"))     //<Encryption>
-	inscriptionBuilder.AddData([]byte("0.0.1")) //<version>
-	//inscriptionBuilder.AddData([]byte("image/jpeg;binary"))    //<content-type>
-	inscriptionBuilder.AddData([]byte("application/json")) //<content-type>
-
-	maxChunkSize := 520
-	bodySize := len(content)
-	for i := 0; i < bodySize; i += maxChunkSize {
-		end := i + maxChunkSize
-		if end > bodySize {
-			end = bodySize
-		}
-		inscriptionBuilder.AddFullData(content[i:end]) //<payload>
+	payload := &MetaIdPayload{
+		Operation:   operation,
+		Path:        path,
+		Version:     "0.0.1",
+		ContentType: "application/json",
+		Content:     content,
 	}
-
-	inscriptionScript, err := inscriptionBuilder.Script()
+	tx, err := BuildChainTx(&MvcTxBuilder{NetParam: netParam}, ins, outs, payload, otherOuts, changeAddress, feeRate, isUnSign)
 	if err != nil {
 		return nil, err
 	}
-
-	tx.AddTxOut(wire2.NewTxOut(0, inscriptionScript))
-
-	if otherOuts != nil {
-		for _, out := range otherOuts {
-			addr, err := bsvutil2.DecodeAddress(out.Address, netParam)
-			if err != nil {
-				return nil, err
-			}
-			pkScript, err := txscript2.PayToAddrScript(addr)
-			if err != nil {
-				return nil, err
-			}
-			tx.AddTxOut(wire2.NewTxOut(out.Amount, pkScript))
-			outAmount = outAmount + out.Amount
-		}
-	}
-
-	if changeAddress != "" {
-		addr, err := bsvutil2.DecodeAddress(changeAddress, netParam)
-		if err != nil {
-			return nil, err
-		}
-		pkScriptByte, err := txscript2.PayToAddrScript(addr)
-		tx.AddTxOut(wire2.NewTxOut(0, pkScriptByte))
-	}
-
-	for _, in := range ins {
-		hash, err := chainhash2.NewHashFromStr(in.TxId)
-		if err != nil {
-			return nil, err
-		}
-		prevOut := wire2.NewOutPoint(hash, uint32(in.TxIndex))
-		txIn := wire2.NewTxIn(prevOut, nil)
-		tx.AddTxIn(txIn)
-		totalAmount = totalAmount + int64(in.Amount)
-
-	}
-	txTotalSize := tx.SerializeSize()
-
-	//txSize := tx.SerializeSize() + SpendSize*len(tx.TxIn)
-	txFee := int64(txTotalSize) * feeRate
-
-	fmt.Printf("txTotalSize:%d, txFee:%d, feeRate:%d, totalAmount:%d, outAmount:%d\n", txTotalSize, txFee, feeRate, totalAmount, outAmount)
-	if totalAmount-outAmount < int64(txFee) {
-		return nil, errors.New("insufficient fee")
-	}
-
-	changeVal := totalAmount - outAmount - int64(txFee)
-	if changeVal >= 600 && changeAddress != "" {
-		tx.TxOut[len(tx.TxOut)-1].Value = changeVal
-	} else {
-		tx.TxOut = tx.TxOut[:len(tx.TxOut)-1]
-	}
-
-	if !isUnSign {
-		for i, in := range ins {
-			privateKeyBytes, err := hex.DecodeString(in.PriHex)
-			if err != nil {
-				return nil, err
-			}
-			privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
-
-			pkScriptByte, err := hex.DecodeString(in.PkScript)
-			if err != nil {
-				return nil, err
-			}
-
-			var sigScript []byte
-			sigScript, err = txscript2.SignatureScript(tx, i, int64(in.Amount), pkScriptByte, txscript2.SigHashAll, privateKey, true)
-			if err != nil {
-				fmt.Println(err)
-				return nil, err
-			}
-
-			tx.TxIn[i].SignatureScript = sigScript
-		}
-	}
-
-	return tx, nil
+	return tx.(*MvcTx).MsgTx, nil
 }
 
 func BuildMvcCommonMetaIdTxForUnkwonInput(netParam *chaincfg2.Params, ins []*TxInputUtxo, outs, otherOuts []*TxOutput, operation, path string, content []byte, contentType string, changeAddress string, feeRate int64, isUnSign bool) (*wire2.MsgTx, error) {
@@ -186,33 +73,12 @@ func BuildMvcCommonMetaIdTxForUnkwonInput(netParam *chaincfg2.Params, ins []*TxI
 		outAmount = outAmount + out.Amount
 	}
 
-	if operation == "" {
-		operation = "create"
-	}
-	inscriptionBuilder := txscript.NewScriptBuilder().
-		AddOp(txscript.OP_0).
-		AddOp(txscript.OP_RETURN).
-		AddData([]byte("metaid")). //<metaid_flag>
-		AddData([]byte(operation)) //<operation>
-
-	//inscriptionBuilder.AddData([]byte("/file/my-bitcoin.png")) //<path>
-	inscriptionBuilder.AddData([]byte(path))    //<path>
-	inscriptionBuilder.AddData([]byte("0"))     //<Encryption>
-	inscriptionBuilder.AddData([]byte("1.0.0")) //<version>
-	//inscriptionBuilder.AddData([]byte("image/jpeg;binary"))    //<content-type>
-	inscriptionBuilder.AddData([]byte(contentType)) //<content-type>
-
-	maxChunkSize := 520
-	bodySize := len(content)
-	for i := 0; i < bodySize; i += maxChunkSize {
-		end := i + maxChunkSize
-		if end > bodySize {
-			end = bodySize
-		}
-		inscriptionBuilder.AddFullData(content[i:end]) //<payload>
-	}
-
-	inscriptionScript, err := inscriptionBuilder.Script()
+	inscriptionScript, err := BuildMetaIdScript(&MetaIdPayload{
+		Operation:   operation,
+		Path:        path,
+		ContentType: contentType,
+		Content:     content,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -405,3 +271,105 @@ func getTxNewRawByte(tx *wire2.MsgTx) []byte {
 	newRawTxByte = append(newRawTxByte, tool.SHA256(newOutputsByte)...)
 	return newRawTxByte
 }
+
+// mvcSignatureScriptSize size of a P2PKH signature script (signature + compressed pubkey)
+const mvcSignatureScriptSize = 107
+
+// MvcTxBuilder ChainTxBuilder for MVC (bsvd wire types, sat/byte fee rates)
+type MvcTxBuilder struct {
+	NetParam *chaincfg2.Params
+}
+
+// NewMvcTxBuilder returns the MVC builder for net ("mainnet" or testnet)
+func NewMvcTxBuilder(net string) *MvcTxBuilder {
+	if net == "mainnet" {
+		return &MvcTxBuilder{NetParam: &chaincfg2.MainNetParams}
+	}
+	return &MvcTxBuilder{NetParam: &chaincfg2.TestNet3Params}
+}
+
+func (b *MvcTxBuilder) Chain() string { return "mvc" }
+
+func (b *MvcTxBuilder) PayToAddrScript(address string) ([]byte, error) {
+	return scripts.PayToMvcAddress(address, b.NetParam)
+}
+
+func (b *MvcTxBuilder) NewTx() ChainTxDraft {
+	return &MvcTx{MsgTx: wire2.NewMsgTx(10)}
+}
+
+func (b *MvcTxBuilder) Fee(size int, feeRate int64) int64 {
+	return int64(size) * feeRate
+}
+
+// MvcTx MVC ChainTxDraft; MsgTx is the underlying bsvd transaction
+type MvcTx struct {
+	*wire2.MsgTx
+	ins []*TxInputUtxo
+}
+
+func (t *MvcTx) TxId() string {
+	raw, err := MvcToRaw(t.MsgTx)
+	if err != nil {
+		return ""
+	}
+	return GetMvcTxhashFromRaw(raw)
+}
+
+func (t *MvcTx) Raw() (string, error) { return MvcToRaw(t.MsgTx) }
+
+func (t *MvcTx) Size() int { return t.SerializeSize() }
+
+func (t *MvcTx) OutputCount() int { return len(t.TxOut) }
+
+func (t *MvcTx) OutputValue(i int) int64 { return t.TxOut[i].Value }
+
+func (t *MvcTx) AddOutput(pkScript []byte, value int64) {
+	t.AddTxOut(wire2.NewTxOut(value, pkScript))
+}
+
+func (t *MvcTx) SetOutputValue(i int, value int64) { t.TxOut[i].Value = value }
+
+func (t *MvcTx) TruncateOutputs(n int) { t.TxOut = t.TxOut[:n] }
+
+func (t *MvcTx) AddInput(in *TxInputUtxo) error {
+	hash, err := chainhash2.NewHashFromStr(in.TxId)
+	if err != nil {
+		return err
+	}
+	t.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(hash, uint32(in.TxIndex)), nil))
+	t.ins = append(t.ins, in)
+	return nil
+}
+
+func (t *MvcTx) SignedSize() int {
+	size := t.SerializeSize()
+	for _, txIn := range t.TxIn {
+		if len(txIn.SignatureScript) == 0 {
+			size += mvcSignatureScriptSize
+		}
+	}
+	return size
+}
+
+func (t *MvcTx) Sign() error {
+	for i, in := range t.ins {
+		privateKeyBytes, err := hex.DecodeString(in.PriHex)
+		if err != nil {
+			return err
+		}
+		privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
+
+		pkScriptByte, err := hex.DecodeString(in.PkScript)
+		if err != nil {
+			return err
+		}
+
+		sigScript, err := txscript2.SignatureScript(t.MsgTx, i, int64(in.Amount), pkScriptByte, txscript2.SigHashAll, privateKey, true)
+		if err != nil {
+			return err
+		}
+		t.TxIn[i].SignatureScript = sigScript
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package scripts
+
+import (
+	"fmt"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// PayToMvcAddress returns the locking script of an MVC address (P2PKH, P2SH
+// or a raw public key, which is paid as P2PKH). As with bsvutil, base58
+// addresses are accepted on either network.
+func PayToMvcAddress(address string, netParam *chaincfg2.Params) ([]byte, error) {
+	addr, err := bsvutil2.DecodeAddress(address, netParam)
+	if err != nil {
+		return nil, err
+	}
+	// Base58 addresses decode to the Legacy* types, cashaddr to the others
+	switch a := addr.(type) {
+	case *bsvutil2.LegacyAddressPubKeyHash, *bsvutil2.AddressPubKeyHash:
+		return P2PKH(a.ScriptAddress())
+	case *bsvutil2.LegacyAddressScriptHash, *bsvutil2.AddressScriptHash:
+		return P2SH(a.ScriptAddress())
+	case *bsvutil2.AddressPubKey:
+		return P2PKH(a.AddressPubKeyHash().ScriptAddress())
+	}
+	return nil, fmt.Errorf("unsupported MVC address type %T", addr)
+}
+
+// PayToBtcAddress returns the locking script of a BTC address (P2PKH, P2SH,
+// P2WPKH, P2WSH, P2TR, or a raw public key, which is paid as P2PKH)
+func PayToBtcAddress(address string, netParam *chaincfg.Params) ([]byte, error) {
+	addr, err := btcutil.DecodeAddress(address, netParam)
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IsForNet(netParam) {
+		return nil, fmt.Errorf("address %s is not for %s", address, netParam.Name)
+	}
+	switch a := addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return P2PKH(a.ScriptAddress())
+	case *btcutil.AddressScriptHash:
+		return P2SH(a.ScriptAddress())
+	case *btcutil.AddressWitnessPubKeyHash:
+		return P2WPKH(a.ScriptAddress())
+	case *btcutil.AddressWitnessScriptHash:
+		return P2WSH(a.ScriptAddress())
+	case *btcutil.AddressTaproot:
+		return P2TR(a.ScriptAddress())
+	case *btcutil.AddressPubKey:
+		return P2PKH(a.AddressPubKeyHash().ScriptAddress())
+	}
+	return nil, fmt.Errorf("unsupported BTC address type %T", addr)
+}
+
+// PayToDogeAddress returns the locking script of a DOGE address (P2PKH or
+// P2SH; Dogecoin has no segwit). netParam is common.DogeMainNetParams or a
+// testnet equivalent.
+func PayToDogeAddress(address string, netParam *chaincfg.Params) ([]byte, error) {
+	addr, err := btcutil.DecodeAddress(address, netParam)
+	if err != nil {
+		return nil, err
+	}
+	switch a := addr.(type) {
+	case *btcutil.AddressPubKeyHash:
+		return P2PKH(a.ScriptAddress())
+	case *btcutil.AddressScriptHash:
+		return P2SH(a.ScriptAddress())
+	case *btcutil.AddressPubKey:
+		return P2PKH(a.AddressPubKeyHash().ScriptAddress())
+	}
+	return nil, fmt.Errorf("unsupported DOGE address type %T", addr)
+}
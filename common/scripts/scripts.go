@@ -0,0 +1,221 @@
+// Package scripts builds the locking scripts used by the uploader and the
+// transaction builders: standard payment templates (P2PKH, P2SH, P2WPKH,
+// P2WSH, P2TR, bare multisig) and MetaID OP_RETURN outputs.
+//
+// Every builder validates its inputs and writes the script bytes itself, so
+// the output does not depend on a particular txscript fork; the unit tests
+// pin each template to published vectors and to btcd's txscript.
+package scripts
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Opcodes used by the templates
+const (
+	OP_0             = 0x00
+	OP_PUSHDATA1     = 0x4c
+	OP_PUSHDATA2     = 0x4d
+	OP_PUSHDATA4     = 0x4e
+	OP_1NEGATE       = 0x4f
+	OP_1             = 0x51
+	OP_16            = 0x60
+	OP_RETURN        = 0x6a
+	OP_DUP           = 0x76
+	OP_EQUAL         = 0x87
+	OP_EQUALVERIFY   = 0x88
+	OP_HASH160       = 0xa9
+	OP_CHECKSIG      = 0xac
+	OP_CHECKMULTISIG = 0xae
+)
+
+// MaxMultiSigKeys largest n accepted by P2MS (standardness limit for bare multisig is 3)
+const MaxMultiSigKeys = 16
+
+// P2PKH OP_DUP OP_HASH160 <20-byte pubkey hash> OP_EQUALVERIFY OP_CHECKSIG
+func P2PKH(pubKeyHash []byte) ([]byte, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, fmt.Errorf("p2pkh: pubkey hash must be 20 bytes, got %d", len(pubKeyHash))
+	}
+	script := make([]byte, 0, 25)
+	script = append(script, OP_DUP, OP_HASH160, 20)
+	script = append(script, pubKeyHash...)
+	return append(script, OP_EQUALVERIFY, OP_CHECKSIG), nil
+}
+
+// P2SH OP_HASH160 <20-byte script hash> OP_EQUAL
+func P2SH(scriptHash []byte) ([]byte, error) {
+	if len(scriptHash) != 20 {
+		return nil, fmt.Errorf("p2sh: script hash must be 20 bytes, got %d", len(scriptHash))
+	}
+	script := make([]byte, 0, 23)
+	script = append(script, OP_HASH160, 20)
+	script = append(script, scriptHash...)
+	return append(script, OP_EQUAL), nil
+}
+
+// P2WPKH OP_0 <20-byte pubkey hash> (segwit v0)
+func P2WPKH(pubKeyHash []byte) ([]byte, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, fmt.Errorf("p2wpkh: pubkey hash must be 20 bytes, got %d", len(pubKeyHash))
+	}
+	return append([]byte{OP_0, 20}, pubKeyHash...), nil
+}
+
+// P2WSH OP_0 <32-byte script hash> (segwit v0)
+func P2WSH(scriptHash []byte) ([]byte, error) {
+	if len(scriptHash) != 32 {
+		return nil, fmt.Errorf("p2wsh: script hash must be 32 bytes, got %d", len(scriptHash))
+	}
+	return append([]byte{OP_0, 32}, scriptHash...), nil
+}
+
+// P2TR OP_1 <32-byte x-only output key> (segwit v1, BIP 341)
+func P2TR(outputKey []byte) ([]byte, error) {
+	if len(outputKey) != 32 {
+		return nil, fmt.Errorf("p2tr: output key must be 32 bytes, got %d", len(outputKey))
+	}
+	return append([]byte{OP_1, 32}, outputKey...), nil
+}
+
+// P2MS OP_m <pubkey>... OP_n OP_CHECKMULTISIG (bare multisig). Keys must be
+// 33-byte compressed or 65-byte uncompressed SEC encodings.
+func P2MS(required int, pubKeys [][]byte) ([]byte, error) {
+	n := len(pubKeys)
+	if n == 0 || n > MaxMultiSigKeys {
+		return nil, fmt.Errorf("p2ms: need 1-%d keys, got %d", MaxMultiSigKeys, n)
+	}
+	if required < 1 || required > n {
+		return nil, fmt.Errorf("p2ms: required signatures %d out of range 1-%d", required, n)
+	}
+	script := []byte{smallInt(required)}
+	for i, key := range pubKeys {
+		switch {
+		case len(key) == 33 && (key[0] == 0x02 || key[0] == 0x03):
+		case len(key) == 65 && key[0] == 0x04:
+		default:
+			return nil, fmt.Errorf("p2ms: key %d is not a SEC-encoded public key", i)
+		}
+		script = append(script, byte(len(key)))
+		script = append(script, key...)
+	}
+	return append(script, smallInt(n), OP_CHECKMULTISIG), nil
+}
+
+// MetaId MetaID protocol fields of an OP_RETURN output
+type MetaId struct {
+	Operation   string // create/modify/revoke (default create)
+	Path        string
+	Encryption  string // default "0"
+	Version     string // default "1.0.0"
+	ContentType string
+	Payload     []byte
+}
+
+// MaxPayloadPush payloads are split into pushes of at most this many bytes
+const MaxPayloadPush = 520
+
+// MetaIdOpReturn OP_0 OP_RETURN "metaid" <operation> <path> <encryption>
+// <version> <content-type> <payload>... The payload is split into
+// MaxPayloadPush-byte pushes; every push is minimal, as txscript encodes it.
+func MetaIdOpReturn(m *MetaId) []byte {
+	operation, encryption, version := m.Operation, m.Encryption, m.Version
+	if operation == "" {
+		operation = "create"
+	}
+	if encryption == "" {
+		encryption = "0"
+	}
+	if version == "" {
+		version = "1.0.0"
+	}
+
+	script := []byte{OP_0, OP_RETURN}
+	for _, field := range []string{"metaid", operation, m.Path, encryption, version, m.ContentType} {
+		script = appendMinimalPush(script, []byte(field))
+	}
+	for i := 0; i < len(m.Payload); i += MaxPayloadPush {
+		end := i + MaxPayloadPush
+		if end > len(m.Payload) {
+			end = len(m.Payload)
+		}
+		script = appendMinimalPush(script, m.Payload[i:end])
+	}
+	return script
+}
+
+// MetaIdOpReturnSize returns len(MetaIdOpReturn(m)) for a payload of
+// payloadLen bytes without building the script. It is one byte high when the
+// final payload push is a single byte that encodes as a small-int opcode.
+func MetaIdOpReturnSize(m *MetaId, payloadLen int) int {
+	size := len(MetaIdOpReturn(&MetaId{
+		Operation:   m.Operation,
+		Path:        m.Path,
+		Encryption:  m.Encryption,
+		Version:     m.Version,
+		ContentType: m.ContentType,
+	}))
+	for i := 0; i < payloadLen; i += MaxPayloadPush {
+		n := payloadLen - i
+		if n > MaxPayloadPush {
+			n = MaxPayloadPush
+		}
+		size += PushSize(n)
+	}
+	return size
+}
+
+// PushSize returns the size of a data push of n bytes (opcode + length + data)
+func PushSize(n int) int {
+	switch {
+	case n < OP_PUSHDATA1:
+		return 1 + n
+	case n <= 0xff:
+		return 2 + n
+	case n <= 0xffff:
+		return 3 + n
+	default:
+		return 5 + n
+	}
+}
+
+// appendPush appends data with the shortest length prefix
+func appendPush(script, data []byte) []byte {
+	n := len(data)
+	switch {
+	case n < OP_PUSHDATA1:
+		script = append(script, byte(n))
+	case n <= 0xff:
+		script = append(script, OP_PUSHDATA1, byte(n))
+	case n <= 0xffff:
+		script = append(script, OP_PUSHDATA2, 0, 0)
+		binary.LittleEndian.PutUint16(script[len(script)-2:], uint16(n))
+	default:
+		script = append(script, OP_PUSHDATA4, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(script[len(script)-4:], uint32(n))
+	}
+	return append(script, data...)
+}
+
+// appendMinimalPush appends data as a minimal push: empty data and the
+// single bytes 0, 1-16 and 0x81 become OP_0, OP_1-OP_16 and OP_1NEGATE
+func appendMinimalPush(script, data []byte) []byte {
+	if len(data) == 0 || len(data) == 1 && data[0] == 0 {
+		return append(script, OP_0)
+	}
+	if len(data) == 1 {
+		switch {
+		case data[0] <= 16:
+			return append(script, OP_1+data[0]-1)
+		case data[0] == 0x81:
+			return append(script, OP_1NEGATE)
+		}
+	}
+	return appendPush(script, data)
+}
+
+// smallInt returns the OP_1-OP_16 opcode of n
+func smallInt(n int) byte {
+	return byte(OP_1 + n - 1)
+}
@@ -0,0 +1,238 @@
+package scripts
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestTemplates_KnownVectors(t *testing.T) {
+	// Generator point G, compressed and x-only
+	const pubKeyG = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	const xOnlyG = "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+	tests := []struct {
+		name  string
+		build func() ([]byte, error)
+		want  string
+	}{
+		{
+			// Genesis coinbase address 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa
+			name:  "p2pkh",
+			build: func() ([]byte, error) { return P2PKH(mustHex(t, "62e907b15cbf27d5425399ebf6f0fb50ebb88f18")) },
+			want:  "76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac",
+		},
+		{
+			name:  "p2sh",
+			build: func() ([]byte, error) { return P2SH(mustHex(t, "8f55563b9a19f321c211e9b9f38cdf686ea07845")) },
+			want:  "a9148f55563b9a19f321c211e9b9f38cdf686ea0784587",
+		},
+		{
+			// BIP 173: bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4
+			name:  "p2wpkh",
+			build: func() ([]byte, error) { return P2WPKH(mustHex(t, "751e76e8199196d454941c45d1b3a323f1433bd6")) },
+			want:  "0014751e76e8199196d454941c45d1b3a323f1433bd6",
+		},
+		{
+			// BIP 173: bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3
+			name: "p2wsh",
+			build: func() ([]byte, error) {
+				return P2WSH(mustHex(t, "1863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262"))
+			},
+			want: "00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262",
+		},
+		{
+			// BIP 350: bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0
+			name:  "p2tr",
+			build: func() ([]byte, error) { return P2TR(mustHex(t, xOnlyG)) },
+			want:  "5120" + xOnlyG,
+		},
+		{
+			name:  "p2ms 1-of-1",
+			build: func() ([]byte, error) { return P2MS(1, [][]byte{mustHex(t, pubKeyG)}) },
+			want:  "5121" + pubKeyG + "51ae",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("got %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplates_RejectBadInput(t *testing.T) {
+	key := bytes.Repeat([]byte{0x02}, 33)
+	tests := []struct {
+		name  string
+		build func() ([]byte, error)
+	}{
+		{"p2pkh short hash", func() ([]byte, error) { return P2PKH(make([]byte, 19)) }},
+		{"p2sh long hash", func() ([]byte, error) { return P2SH(make([]byte, 32)) }},
+		{"p2wpkh short hash", func() ([]byte, error) { return P2WPKH(make([]byte, 32)) }},
+		{"p2wsh short hash", func() ([]byte, error) { return P2WSH(make([]byte, 20)) }},
+		{"p2tr compressed key", func() ([]byte, error) { return P2TR(key) }},
+		{"p2ms no keys", func() ([]byte, error) { return P2MS(1, nil) }},
+		{"p2ms m > n", func() ([]byte, error) { return P2MS(2, [][]byte{key}) }},
+		{"p2ms m = 0", func() ([]byte, error) { return P2MS(0, [][]byte{key}) }},
+		{"p2ms bad key", func() ([]byte, error) { return P2MS(1, [][]byte{make([]byte, 33)}) }},
+		{"p2ms too many keys", func() ([]byte, error) { return P2MS(1, make([][]byte, MaxMultiSigKeys+1)) }},
+	}
+	for _, tt := range tests {
+		if _, err := tt.build(); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestP2MS_MatchesTxscript(t *testing.T) {
+	keys := [][]byte{
+		mustHex(t, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"),
+		mustHex(t, "02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5"),
+		mustHex(t, "02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"),
+	}
+	got, err := P2MS(2, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder := txscript.NewScriptBuilder().AddInt64(2)
+	for _, key := range keys {
+		builder.AddData(key)
+	}
+	want, err := builder.AddInt64(3).AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+	if class := txscript.GetScriptClass(got); class != txscript.MultiSigTy {
+		t.Errorf("script class = %s, want multisig", class)
+	}
+}
+
+func TestMetaIdOpReturn(t *testing.T) {
+	// OP_0 OP_RETURN "metaid" "create" "/info/name" "0" "1.0.0" "text/plain" "alice"
+	want := "006a" +
+		"066d6574616964" +
+		"06637265617465" +
+		"0a2f696e666f2f6e616d65" +
+		"0130" +
+		"05312e302e30" +
+		"0a746578742f706c61696e" +
+		"05616c696365"
+	got := MetaIdOpReturn(&MetaId{Path: "/info/name", ContentType: "text/plain", Payload: []byte("alice")})
+	if hex.EncodeToString(got) != want {
+		t.Errorf("got %x\nwant %s", got, want)
+	}
+
+	// Byte-identical to the txscript builder the uploader used before
+	for _, n := range []int{0, 1, 75, 76, 255, 256, 520, 521, 1200, 70000} {
+		payload := bytes.Repeat([]byte{0x01}, n)
+		m := &MetaId{Operation: "modify", Path: "", Version: "0.0.1", ContentType: "application/json", Payload: payload}
+		builder := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_0).
+			AddOp(txscript.OP_RETURN).
+			AddData([]byte("metaid")).
+			AddData([]byte("modify")).
+			AddData([]byte("")).
+			AddData([]byte("0")).
+			AddData([]byte("0.0.1")).
+			AddData([]byte("application/json"))
+		for i := 0; i < n; i += MaxPayloadPush {
+			end := i + MaxPayloadPush
+			if end > n {
+				end = n
+			}
+			builder.AddFullData(payload[i:end])
+		}
+		want, err := builder.Script()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := MetaIdOpReturn(m)
+		if !bytes.Equal(got, want) {
+			t.Errorf("payload %d: script differs from txscript", n)
+		}
+		if n%MaxPayloadPush == 1 {
+			continue // the final 0x01 push becomes OP_1
+		}
+		if size := MetaIdOpReturnSize(m, n); size != len(got) {
+			t.Errorf("payload %d: MetaIdOpReturnSize = %d, want %d", n, size, len(got))
+		}
+	}
+}
+
+func TestPayToAddress(t *testing.T) {
+	dogeTestNet := chaincfg.TestNet3Params
+	dogeTestNet.PubKeyHashAddrID = 0x71
+	dogeTestNet.ScriptHashAddrID = 0xc4
+
+	tests := []struct {
+		name    string
+		pay     func(string) ([]byte, error)
+		address string
+		want    string
+		wantErr bool
+	}{
+		{"mvc p2pkh", func(a string) ([]byte, error) { return PayToMvcAddress(a, &chaincfg2.MainNetParams) },
+			"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac", false},
+		{"mvc malformed", func(a string) ([]byte, error) { return PayToMvcAddress(a, &chaincfg2.MainNetParams) },
+			"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", "", true},
+		{"btc p2pkh", func(a string) ([]byte, error) { return PayToBtcAddress(a, &chaincfg.MainNetParams) },
+			"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "76a91462e907b15cbf27d5425399ebf6f0fb50ebb88f1888ac", false},
+		{"btc p2wpkh", func(a string) ([]byte, error) { return PayToBtcAddress(a, &chaincfg.MainNetParams) },
+			"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "0014751e76e8199196d454941c45d1b3a323f1433bd6", false},
+		{"btc p2wsh", func(a string) ([]byte, error) { return PayToBtcAddress(a, &chaincfg.MainNetParams) },
+			"bc1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3qccfmv3",
+			"00201863143c14c5166804bd19203356da136c985678cd4d27a1b8c6329604903262", false},
+		{"btc p2tr", func(a string) ([]byte, error) { return PayToBtcAddress(a, &chaincfg.MainNetParams) },
+			"bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+			"512079be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", false},
+		{"btc mainnet address on testnet", func(a string) ([]byte, error) { return PayToBtcAddress(a, &chaincfg.TestNet3Params) },
+			"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "", true},
+		{"doge testnet p2pkh", func(a string) ([]byte, error) { return PayToDogeAddress(a, &dogeTestNet) },
+			"nbMFaHF9pjNoohS4fD1jefKBgDnETK9uPu", "", false},
+		{"doge rejects segwit", func(a string) ([]byte, error) { return PayToDogeAddress(a, &chaincfg.MainNetParams) },
+			"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pay(tt.address)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %x", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want != "" && hex.EncodeToString(got) != tt.want {
+				t.Errorf("got %x, want %s", got, tt.want)
+			}
+			if tt.want == "" && !strings.HasPrefix(hex.EncodeToString(got), "76a914") {
+				t.Errorf("got %x, want a P2PKH script", got)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package conf
 
 import (
 	"fmt"
+	"runtime"
+
+	"meta-file-system/conf/secrets"
 
 	"github.com/spf13/viper"
 )
@@ -31,15 +34,39 @@ type Config struct {
 
 	// Redis configuration
 	Redis RedisConfig
+
+	// HTTP middleware configuration (both services)
+	Http HttpConfig
+
+	// Operational alert channels (both services)
+	Notify NotifyConfig
+
+	// MetaID derivation (both services)
+	MetaID MetaIDConfig
+
+	// Graceful shutdown (both services)
+	Shutdown ShutdownConfig
 }
 
 // DatabaseConfig database configuration
 type DatabaseConfig struct {
-	IndexerType  string // Indexer database type: mysql, pebble
+	IndexerType  string // Indexer database type: mysql, pebble, sqlite
+	UploaderType string // Uploader database type: mysql (default), sqlite
 	Dsn          string // MySQL DSN
 	MaxOpenConns int    // MySQL max open connections
 	MaxIdleConns int    // MySQL max idle connections
 	DataDir      string // PebbleDB data directory
+	SqlitePath   string // SQLite database file (indexer_type/uploader_type sqlite)
+	Pebble       PebbleTuningConfig
+}
+
+// PebbleTuningConfig options of the PebbleDB instance (indexer_type pebble);
+// a data directory still in the legacy layout applies them to each
+// collection's instance. 0 keeps Pebble's own default.
+type PebbleTuningConfig struct {
+	CacheMB                  int // Block cache (MB); 0 = 8
+	MemTableMB               int // Memtable size (MB); 0 = 4
+	MaxConcurrentCompactions int // Compactions run at once; 0 = 1
 }
 
 // ChainConfig blockchain configuration
@@ -48,15 +75,36 @@ type ChainConfig struct {
 	RpcUser     string
 	RpcPass     string
 	StartHeight int64
+	EsploraUrl  string // Esplora API for blocks a pruned BTC node no longer has
 }
 
 // StorageConfig storage configuration
 type StorageConfig struct {
-	Type  string
-	Local LocalStorageConfig
-	OSS   OSSStorageConfig
-	S3    S3StorageConfig
-	MinIO MinIOStorageConfig
+	Type          string
+	LayoutVersion int                       // Path layout for newly indexed blobs (see storage.Layout)
+	PathTemplate  StoragePathTemplateConfig // Paths of layout_version 3
+	Local         LocalStorageConfig
+	OSS           OSSStorageConfig
+	S3            S3StorageConfig
+	MinIO         MinIOStorageConfig
+	Gateway       GatewayStorageConfig
+	Migration     StorageMigrationConfig
+}
+
+// StoragePathTemplateConfig blob paths of storage layout 3, built from
+// {chain} {pinId} {txId} {shard} {year} {month} {day} and, for files only,
+// {ext} {metaId}; validated at startup
+type StoragePathTemplateConfig struct {
+	File  string // "" = default (indexer/t/{chain}/{year}/{month}/{pinId}{ext})
+	Chunk string // "" = default (indexer/t/chunk/{chain}/{year}/{month}/{pinId})
+}
+
+// StorageMigrationConfig moving blobs from storage.type to another backend:
+// new writes go to both and indexed blobs are copied, verified and flipped
+type StorageMigrationConfig struct {
+	Enabled  bool
+	Target   string // local/oss/s3/minio, configured in its own section
+	Interval int    // Seconds between copy passes
 }
 
 // LocalStorageConfig local storage configuration
@@ -93,6 +141,14 @@ type MinIOStorageConfig struct {
 	Domain    string
 }
 
+// GatewayStorageConfig watch-only storage: blobs are pushed to an external
+// storage gateway (storage.GatewayStorage) and only metadata is kept
+type GatewayStorageConfig struct {
+	Url            string // Base URL of the gateway, objects live under {url}/objects/{key}
+	Token          string // Bearer token sent with every request (optional)
+	TimeoutSeconds int    // Per-request timeout
+}
+
 // ChainInstanceConfig single chain instance configuration
 type ChainInstanceConfig struct {
 	Name        string `mapstructure:"name"`         // Chain name: btc, mvc, etc.
@@ -102,6 +158,12 @@ type ChainInstanceConfig struct {
 	StartHeight int64  `mapstructure:"start_height"` // Start height for this chain
 	ZmqEnabled  bool   `mapstructure:"zmq_enabled"`  // Enable ZMQ for this chain
 	ZmqAddress  string `mapstructure:"zmq_address"`  // ZMQ server address
+	EsploraUrl  string `mapstructure:"esplora_url"`  // Esplora API for blocks a pruned BTC node no longer has
+
+	ParserMode     string          `mapstructure:"parser_mode"`     // Overrides indexer.parser_mode for this chain
+	ParserFeatures map[string]bool `mapstructure:"parser_features"` // Overrides entries of indexer.parser_features for this chain
+
+	MinConfirmations int64 `mapstructure:"min_confirmations"` // Overrides indexer.confirmations.min for this chain; 0 = inherit
 }
 
 // IndexerConfig indexer configuration
@@ -117,12 +179,211 @@ type IndexerConfig struct {
 	ZmqEnabled          bool   // Enable ZMQ real-time monitoring
 	ZmqAddress          string // ZMQ server address (e.g., "tcp://127.0.0.1:28332")
 
+	// Listeners: addresses the API is served on; empty = every IPv4 and IPv6 address on indexer.port
+	Listeners []ListenerConfig
+
 	// LargeBlockSizeMB: blocks larger than this (MB) are loaded tx-by-tx to avoid OOM. 0 = use default (50)
 	LargeBlockSizeMB int
 
 	// Multi-chain support
 	Chains              []ChainInstanceConfig // Multi-chain configurations
 	TimeOrderingEnabled bool                  // Enable strict time ordering across chains
+
+	// Protocol path filter (* = one segment, ** = any depth); reloaded when the config file changes
+	PathAllowlist []string // Paths to index, e.g. /file/**; empty = built-in protocol list
+	PathDenylist  []string // Paths never indexed, e.g. /protocols/garbage/**; wins over the allowlist
+
+	// PIN parser policy, per chain overrides in chains[].parser_mode / parser_features
+	ParserMode     string          // strict: malformed PINs are not indexed; lenient (default): indexed best-effort with a warning
+	ParserFeatures map[string]bool // Experimental protocol toggles, e.g. metafile_index_v2: false
+
+	// FeedLinkTemplate: explorer URL for RSS/Atom/sitemap entries, {pinId} is replaced; empty = indexer content URL
+	FeedLinkTemplate string
+
+	// RenderIDAddress: add the chain-independent ID address next to native addresses in file, avatar and user responses
+	RenderIDAddress bool
+
+	// Confirmations a file needs before it is served as final
+	Confirmations IndexerConfirmationsConfig
+
+	// Avatar validation at index time
+	AvatarMaxSizeKB    int  // Max avatar size in KB; 0 = default (2048)
+	AvatarMaxDimension int  // Max avatar width/height in pixels; 0 = default (2048)
+	AvatarDownscale    bool // Downscale oversized JPEG/PNG avatars instead of marking them invalid
+
+	// Gzip content limits at index time; content over either limit is rejected
+	GzipMaxOutputMB int // Max decompressed size in MB; 0 = default (100)
+	GzipMaxRatio    int // Max decompressed/compressed size ratio; 0 = default (100)
+
+	// Large multi-chunk files: over MaxMergedFileMB the chunks are not merged and
+	// the file is streamed from them; merged files over StreamThresholdMB are
+	// also streamed from their chunks instead of being read into memory whole
+	MaxMergedFileMB   int // 0 = default (1024)
+	StreamThresholdMB int // 0 = default (64)
+
+	// Custom domains mapped to hosted sites / MetaIDs (managed via /api/v1/admin/domains)
+	Domains IndexerDomainsConfig
+
+	// Read-through mirror of another indexer instead of scanning chains
+	Mirror IndexerMirrorConfig
+
+	// Other gateways asked for content whose blob this node lacks
+	Peers IndexerPeersConfig
+
+	// Stages a block's transactions go through while being indexed
+	Pipeline IndexerPipelineConfig
+
+	// Retry of storage writes that failed while indexing
+	StorageRetry IndexerStorageRetryConfig
+
+	// Retry of creator address lookups that failed while indexing
+	CreatorRetry IndexerCreatorRetryConfig
+
+	// Delivery of watch events from the outbox
+	WatchOutbox IndexerWatchOutboxConfig
+
+	// Change log events published to a message bus (NATS / Kafka)
+	EventStream IndexerEventStreamConfig
+
+	// Read-only WebDAV gateway over the creators' path trees
+	WebDAV IndexerWebDAVConfig
+
+	// Scanning paused while the storage / database volumes are nearly full
+	DiskWatchdog IndexerDiskWatchdogConfig
+
+	// Recurring rescans of the latest blocks, re-indexing PINs missed by transient failures
+	RescanSchedule IndexerRescanScheduleConfig
+}
+
+// IndexerRescanScheduleConfig recurring rescan jobs: at the times of its cron
+// expression (server local time) a job rescans the last blocks up to its
+// chain's sync height. Jobs run through the rescan task one at a time; a job
+// that comes due while another rescan runs starts once it is finished.
+type IndexerRescanScheduleConfig struct {
+	Enabled bool
+	Jobs    []IndexerRescanJobConfig
+}
+
+// IndexerRescanJobConfig one recurring rescan
+type IndexerRescanJobConfig struct {
+	Name   string `mapstructure:"name"`   // Shown in logs; empty = chain and cron expression
+	Cron   string `mapstructure:"cron"`   // minute hour day-of-month month day-of-week, or @daily, @hourly ...
+	Chain  string `mapstructure:"chain"`  // btc or mvc
+	Blocks int64  `mapstructure:"blocks"` // Blocks up to the sync height to rescan; 0 = default (144)
+}
+
+// IndexerDiskWatchdogConfig free space of the local storage directory and the
+// indexer database directory is checked periodically; below min_free_mb block
+// scanning pauses (and an alert is sent) until resume_free_mb is free again
+type IndexerDiskWatchdogConfig struct {
+	Enabled         bool
+	MinFreeMB       int      // Pause scanning below this much free space on a watched volume; 0 = default (1024)
+	ResumeFreeMB    int      // Resume once every watched volume has this much free; 0 = default (2 x min_free_mb)
+	IntervalSeconds int      // How often free space is checked; 0 = default (30)
+	Paths           []string // Extra directories to watch, besides local storage and the indexer database
+}
+
+// IndexerWebDAVConfig WebDAV gateway mounted at /webdav: /webdav/{metaid}/
+// browses a creator's files by MetaID path, so the indexer can be mounted as
+// a network drive
+type IndexerWebDAVConfig struct {
+	Enabled      bool // Serve /webdav
+	CacheSeconds int  // How long directory listings are reused; 0 = default (10)
+}
+
+// IndexerStorageRetryConfig blobs the storage backend refuses while indexing
+// are queued in the indexer DB (records get status pending_storage) and
+// written again in the background
+type IndexerStorageRetryConfig struct {
+	IntervalSeconds   int // How often the queue is retried; 0 = default (30)
+	MaxBackoffSeconds int // Longest wait between attempts on one blob, doubled per failure; 0 = default (1800)
+}
+
+// IndexerCreatorRetryConfig files whose creator input could not be looked up
+// while indexing keep the fallback address with resolution_pending set; the
+// lookup is queued in the indexer DB and retried in the background
+type IndexerCreatorRetryConfig struct {
+	IntervalSeconds   int // How often the queue is retried; 0 = default (60)
+	MaxBackoffSeconds int // Longest wait between attempts on one PIN, doubled per failure; 0 = default (3600)
+	MaxAttempts       int // Lookups per PIN before giving up (the file keeps the fallback); 0 = default (48)
+}
+
+// IndexerWatchOutboxConfig watch events are written to the indexer DB together
+// with an outbox entry per webhook and live subscription; a dispatcher
+// delivers the entries and retries failed webhooks
+type IndexerWatchOutboxConfig struct {
+	IntervalSeconds   int // How often the outbox is checked for retries; 0 = default (10)
+	MaxBackoffSeconds int // Longest wait between attempts on one delivery, doubled per failure; 0 = default (1800)
+	MaxAttempts       int // Deliveries attempted before an entry is dropped; 0 = default (20)
+}
+
+// IndexerEventStreamConfig change log events (new files, profile updates,
+// chunk merges ...) are published to {topic_prefix}.{action} on NATS or on
+// Kafka through a Kafka REST Proxy, resuming from a cursor file
+type IndexerEventStreamConfig struct {
+	Enabled     bool
+	Broker      string   // nats | kafka
+	URLs        []string // NATS servers (nats://[user:pass@]host:4222, tls://...) or Kafka REST Proxy base URLs
+	TopicPrefix string   // Subject / topic prefix; "" = default (metafs)
+	Format      string   // json | protobuf; "" = default (json)
+	Actions     []string // Change actions published; empty = all
+	BatchSize   int      // Events read and published per pass; 0 = default (500)
+	PollSeconds int      // Wait between passes once caught up, and after a failure; 0 = default (2)
+	CursorFile  string   // Seq of the last published event; "" = default (./data/event_stream.cursor)
+}
+
+// IndexerPipelineConfig block processing pipeline: fetch -> parse -> resolve
+// addresses run concurrently, connected by bounded queues; persist runs in
+// block order on one goroutine, then the block is post-processed
+type IndexerPipelineConfig struct {
+	FetchWorkers   int // Transactions of a lazy (large) block fetched in parallel; 0 = default (4)
+	ParseWorkers   int // Transactions decoded in parallel; 0 = default (number of CPUs)
+	ResolveWorkers int // PIN creator addresses looked up in parallel; 0 = default (4)
+	QueueSize      int // Transactions in flight between fetch and persist; 0 = default (256)
+}
+
+// IndexerMirrorConfig mirror mode: chains are not scanned; PINs not found
+// locally are fetched from an upstream meta-file-system indexer and cached
+type IndexerMirrorConfig struct {
+	Enabled        bool   // Serve as a mirror (the block scanner is not started)
+	Upstream       string // Upstream indexer base URL, e.g. https://indexer.example.com
+	TimeoutSeconds int    // Upstream request timeout; 0 = default (30)
+	MaxFileMB      int    // Largest file fetched from upstream (MB); 0 = default (100)
+}
+
+// IndexerConfirmationsConfig confirmation policy: files with fewer confirmations
+// than their chain's threshold (Min, overridden by chains[].min_confirmations)
+// are listed as provisional until the indexed chain reaches it
+type IndexerConfirmationsConfig struct {
+	Min               int64 // Confirmations before a file is no longer provisional; 0 = default (1)
+	RefuseProvisional bool  // Content endpoints refuse provisional files instead of serving them
+}
+
+// IndexerPeersConfig other meta-file-system gateways: content whose blob is
+// missing or unreadable here (pruned, metadata-only, storage failure) is
+// fetched from a peer, verified against the file hash, cached and served
+type IndexerPeersConfig struct {
+	Urls            []string // Peer gateway base URLs, e.g. https://peer.example.com, asked in order
+	Discover        bool     // Also use the peers listed by the peers (GET /api/v1/peers)
+	DiscoverMinutes int      // How often peer lists are re-read when discovering; 0 = default (10)
+	TimeoutSeconds  int      // Peer request timeout; 0 = default (30)
+	MaxFileMB       int      // Largest file fetched from a peer (MB); 0 = default (100)
+}
+
+// IndexerDomainsConfig Host-header routing of custom domains and optional
+// automatic TLS certificates for them (ACME, e.g. Let's Encrypt)
+type IndexerDomainsConfig struct {
+	CacheSeconds int // How long domain mappings are cached; 0 = default (30)
+	Acme         IndexerAcmeConfig
+}
+
+// IndexerAcmeConfig ACME TLS automation for mapped domains
+type IndexerAcmeConfig struct {
+	Enabled   bool   // Serve mapped domains over HTTPS with certificates obtained on first request
+	Email     string // Contact email registered with the ACME CA
+	CacheDir  string // Where certificates and the account key are kept (default ./data/acme)
+	HttpsPort string // HTTPS listener port (default 443)
+	HttpPort  string // HTTP listener for http-01 challenges and redirects to HTTPS (default 80)
 }
 
 // RedisConfig redis configuration
@@ -135,6 +396,77 @@ type RedisConfig struct {
 	CacheTTL int    // Cache TTL in seconds (default: 300)
 }
 
+// HttpConfig HTTP middleware shared by the indexer and uploader
+type HttpConfig struct {
+	Cors            CorsConfig
+	MaxBodyMB       int  // Request body limit of every route except uploads (MB); 0 = 10
+	UploadMaxBodyMB int  // Request body limit of uploader upload routes (MB); 0 = per-route limits derived from uploader.max_file_size
+	Gzip            bool // Gzip JSON/text responses for clients sending Accept-Encoding: gzip
+
+	// Reverse proxies (IPs or CIDRs) whose X-Forwarded-For / X-Real-IP give
+	// the client IP used by per-IP limits; empty = none, the client IP is the
+	// address of the connection
+	TrustedProxies []string
+
+	AccessLog HttpAccessLogConfig // Request/response log; replaces gin's request log when enabled
+}
+
+// HttpAccessLogConfig structured log of HTTP requests (http.access_log). The
+// request metrics at /metrics are kept whether or not it is enabled.
+type HttpAccessLogConfig struct {
+	Enabled      bool
+	SampleRate   float64                    // Share of requests logged, 0-1; 0 = 1. Server errors and slow requests are always logged
+	SlowMs       int                        // Requests taking longer are slow; 0 = 1000
+	LogBodies    bool                       // Log JSON and form bodies of requests and responses, sensitive fields redacted
+	MaxBodyBytes int                        // Bodies larger than this are logged by size only; 0 = 4096
+	RedactFields []string                   // Field names redacted besides the built-in keys, transaction hex and content fields
+	Routes       []HttpAccessLogRouteConfig // Per-route overrides
+}
+
+// HttpAccessLogRouteConfig access log settings of one route
+type HttpAccessLogRouteConfig struct {
+	Route      string   `mapstructure:"route"`       // Gin route, e.g. /api/v1/files/:pinId, optionally prefixed by a method: "POST /api/v1/files/direct-upload"
+	SampleRate *float64 `mapstructure:"sample_rate"` // Overrides sample_rate; 0 = only server errors and slow requests
+	LogBodies  *bool    `mapstructure:"log_bodies"`  // Overrides log_bodies
+}
+
+// CorsConfig cross-origin access for browser dApps
+type CorsConfig struct {
+	AllowOrigins     []string // Allowed origins, e.g. https://app.example.com; empty = any origin (*)
+	AllowMethods     []string // Empty = GET, POST, PUT, DELETE, OPTIONS, PATCH
+	AllowHeaders     []string // Empty = the common request headers (Content-Type, Authorization, ...)
+	ExposeHeaders    []string // Empty = Content-Length, Content-Type, Retry-After, X-Request-Id
+	AllowCredentials bool     // Allow cookies/credentials; ignored while any origin is allowed
+	MaxAge           int      // Seconds browsers may cache a preflight; 0 = 43200
+}
+
+// Routes served by a listener (ListenerConfig.Routes)
+const (
+	ListenerRoutesAll    = "all"    // Every route
+	ListenerRoutesPublic = "public" // Every route except /api/v1/admin/*
+	ListenerRoutesAdmin  = "admin"  // Only /api/v1/admin/*, /api/v1/status, /metrics and /health*
+)
+
+// ListenerConfig one address the HTTP API of a service is served on
+// (indexer.listeners / uploader.listeners)
+type ListenerConfig struct {
+	Address    string            `mapstructure:"address"`     // host:port, e.g. ":7281" or "[::]:7281" (every IPv4 and IPv6 address), "127.0.0.1:7281", "[::1]:7281"; or unix:/path/to.sock
+	Network    string            `mapstructure:"network"`     // TCP addresses: tcp (IPv4 and IPv6), tcp4 or tcp6 (IPv6 only); default tcp
+	Routes     string            `mapstructure:"routes"`      // all, public or admin; default all
+	SocketMode string            `mapstructure:"socket_mode"` // Unix socket permissions (octal); default 0660
+	TLS        ListenerTLSConfig `mapstructure:"tls"`         // TLS termination; plain HTTP without cert_file
+}
+
+// ListenerTLSConfig TLS termination of a listener. The certificate and key
+// are reloaded when their files change and on SIGHUP.
+type ListenerTLSConfig struct {
+	CertFile     string `mapstructure:"cert_file"`      // PEM certificate chain
+	KeyFile      string `mapstructure:"key_file"`       // PEM private key
+	ClientCAFile string `mapstructure:"client_ca_file"` // PEM CAs; when set, clients must present a certificate they signed
+	MinVersion   string `mapstructure:"min_version"`    // 1.2 or 1.3; default 1.2
+	ReloadSecs   int    `mapstructure:"reload_secs"`    // How often the files are checked for changes; 0 = 60
+}
+
 // UploaderChainConfig single chain configuration for uploader (RPC + per-chain params)
 type UploaderChainConfig struct {
 	Name           string `mapstructure:"name"`             // Chain name: mvc, doge, etc.
@@ -146,6 +478,11 @@ type UploaderChainConfig struct {
 	ChunkSize      int64  `mapstructure:"chunk_size"`       // Chunk size in MB, 0 = use global default
 	ChunkSizeBytes int64  `mapstructure:"chunk_size_bytes"` // Chunk size in bytes (for DOGE etc), 0 = use ChunkSize or chain default
 	FeeRate        int64  `mapstructure:"fee_rate"`         // Fee rate: MVC sat/byte, DOGE sat/KB, 0 = use global default
+	DustLimit      int64  `mapstructure:"dust_limit"`       // Smallest output/funding value in satoshis, 0 = use global default
+	MinChange      int64  `mapstructure:"min_change"`       // Smallest change output in satoshis, 0 = use global default
+
+	MaxSinglePayloadBytes int64 `mapstructure:"max_single_payload_bytes"` // Largest payload one PIN (single OP_RETURN) may carry, 0 = use global default
+	MaxTxSize             int64 `mapstructure:"max_tx_size"`              // Largest transaction the node relays (bytes), checked before broadcasting; 0 = chain default
 }
 
 // UploaderConfig uploader configuration
@@ -155,6 +492,221 @@ type UploaderConfig struct {
 	ChunkSize      int64                 // Global default (MB)
 	SwaggerBaseUrl string                // Swagger API base URL (e.g., "example.com:7282")
 	Chains         []UploaderChainConfig // Per-chain config (RPC + params), RpcConfigMap populated from here
+	Faucet         FaucetConfig          // Optional testnet faucet
+	Gzip           UploadGzipConfig      // Gzip compression of single-transaction uploads
+	ChunkBasePaths []string              // Extra base paths allowed in front of /file/_chunk and /file/index (host prefixes are always kept)
+	DustLimit      int64                 // Global default smallest output/funding value (satoshis)
+	MinChange      int64                 // Global default smallest change output (satoshis)
+
+	EphemeralRetentionHours int // Prune local records of finished ephemeral uploads after this many hours; 0 = keep
+
+	MaxSinglePayloadBytes int64 // Global default largest single-PIN payload (bytes); 0 = the chain's chunk size
+
+	AdminEnabled bool             // Enable uploader admin routes, including in-flight upload recovery
+	Listeners    []ListenerConfig // Addresses the API is served on; empty = every IPv4 and IPv6 address on uploader.port
+
+	RateLimit UploadRateLimitConfig // Per-address and per-IP limits of upload endpoints
+
+	UrlFetch UploadUrlFetchConfig // Server-side fetch of upload content from a source URL
+
+	Schedule UploadScheduleConfig // Delayed broadcast of async upload tasks
+
+	Batch UploadBatchConfig // Combine small direct uploads into shared transactions
+
+	TaskTTL UploadTaskTTLConfig // Expiry of async upload tasks that never get broadcast
+
+	Delegated UploadDelegatedConfig // Chunked uploads funded by the operator hot wallet and invoiced to the user
+
+	Idempotency UploadIdempotencyConfig // Idempotency-Key replay of retried upload requests
+
+	Preflight UploadPreflightConfig // Node policy checks of built transactions before the first broadcast
+
+	Delta UploadDeltaConfig // Chunked uploads inscribing only a delta against an earlier file
+
+	ChunkFunding UploadChunkFundingConfig // Who funds the chunk transactions of MVC chunked uploads
+
+	UtxoLock UploadUtxoLockConfig // Reservation of the inputs of uploads in flight
+
+	Keep UploadKeepConfig // Owner-signed retention of uploaded files
+}
+
+// UploadKeepConfig owners may ask the uploader to keep the local records of
+// their files with a signed request; kept files are never pruned
+type UploadKeepConfig struct {
+	Enabled             bool  // Accept keep and unkeep requests (default false)
+	MaxFilesPerOwner    int   // Files one address may keep at a time (default 100; 0 = no limit)
+	MaxMBPerOwner       int64 // Total size of the files one address may keep (MB, default 0 = no limit)
+	SignatureMaxAgeSecs int   // Requests signed longer ago (or further ahead) than this are refused (default 600)
+}
+
+// UploadUtxoLockConfig inputs of the transactions an upload builds are
+// reserved until it is broadcast or fails, so concurrent uploads cannot spend
+// the same outputs
+type UploadUtxoLockConfig struct {
+	Enabled    bool // Reserve inputs (default true)
+	TTLMinutes int  // A reservation not released by then expires, e.g. when the client broadcasts itself (default 30)
+}
+
+// UploadChunkFundingConfig how the chunk transactions of MVC chunked uploads
+// are funded: by a key generated per user (assistent, paid with the client's
+// chunk pre-tx) or by one operator hot wallet whose outputs the uploader
+// tracks and locks
+type UploadChunkFundingConfig struct {
+	Strategy             string // assistent (default) or hot_wallet
+	AllowRequestStrategy bool   // Let requests pick the strategy with fundingStrategy (default false)
+	HotWalletKey         string // Hot wallet private key, hex or WIF; use a secret reference (${env:...}, ${file:...}, ...)
+	MaxPerUpload         int64  // Largest chunk funding one upload may take from the hot wallet (satoshis, default 1000000)
+	LockMinutes          int    // Hot wallet outputs locked this long by an upload that neither broadcast nor released them are free again (default 60)
+}
+
+// UploadDeltaConfig delta uploads: the base file is read from an indexer
+// and only the binary delta to it is inscribed
+type UploadDeltaConfig struct {
+	IndexerUrl     string // Indexer base URL the base files are read from, e.g. https://indexer.example.com; empty = delta uploads off
+	TimeoutSeconds int    // Timeout of reading a base file (default 60)
+}
+
+// UploadPreflightConfig how built upload transactions are checked before
+// anything is broadcast. Size, value and conflict checks always run; the
+// node check asks the chain node with testmempoolaccept.
+type UploadPreflightConfig struct {
+	NodeCheck string // auto: use testmempoolaccept where the node has it (default); off: local checks only
+}
+
+// UploadIdempotencyConfig how long upload routes remember Idempotency-Key
+// headers and the responses they returned
+type UploadIdempotencyConfig struct {
+	TtlHours          int // Keys and stored responses are kept this many hours (default 24)
+	ProcessingMinutes int // A request still processing after this many minutes is assumed lost and its key may be reused (default 30)
+}
+
+// UploadDelegatedConfig delegated (custodial-fee) uploads: the operator's
+// node wallet funds the chunk and index transactions of MVC chunked uploads
+// requested by API clients, and the cost is recorded as a charge of the user
+type UploadDelegatedConfig struct {
+	Enabled         bool              // Enable POST /files/delegated-upload
+	RpcUrl          string            // Hot wallet node RPC (empty = mvc uploader chain RPC); should be the node uploads are broadcast to
+	RpcUser         string            // Hot wallet node RPC username
+	RpcPass         string            // Hot wallet node RPC password
+	ApiKeys         map[string]string // Client name -> API key sent in X-Api-Key; only these clients may spend the hot wallet
+	MaxFeePerUpload int64             // Largest funding of one upload (satoshis, default 1000000)
+}
+
+// UploadTaskTTLConfig how long async upload tasks that have not broadcast
+// anything may wait before the cleanup job expires them and drops their payload
+type UploadTaskTTLConfig struct {
+	PendingHours    int // Tasks still pending (never picked up) after this many hours (default 24)
+	ProcessingHours int // Tasks processing without progress for this many hours (default 24)
+}
+
+// UploadUrlFetchConfig limits of POST /files/fetch-url, which downloads a
+// remote file into storage for the chunked upload endpoints
+type UploadUrlFetchConfig struct {
+	Enabled              bool     // Enable fetching upload content from URLs (default false)
+	AllowedSchemes       []string // URL schemes that may be fetched, redirects included (default https)
+	AllowedContentTypes  []string // Accepted content types; "image/*" matches a whole type, empty = any
+	MaxSizeMB            int64    // Largest fetched file (MB); 0 = the chain's max_file_size
+	TimeoutSeconds       int      // Whole-download timeout (default 60)
+	AllowPrivateNetworks bool     // Allow loopback, private and link-local addresses (default false)
+}
+
+// UploadScheduleConfig limits of async upload tasks that build their
+// transactions at once but broadcast later (broadcastAt / targetFeeRate)
+type UploadScheduleConfig struct {
+	MaxDelayHours     int // Latest allowed broadcastAt, in hours from now (default 720)
+	IntervalSeconds   int // How often the scheduler checks held tasks (default 30)
+	FeeEstimateBlocks int // Confirmation target passed to the node fee estimate (default 2)
+}
+
+// UploadBatchConfig batching window of direct uploads sent with batch=true:
+// uploads with the same fee rate arriving within the window share one
+// transaction, one MetaID output each
+type UploadBatchConfig struct {
+	Enabled       bool // Accept batch=true direct uploads (default false)
+	WindowSeconds int  // How long a batch collects uploads after its first one (default 5)
+	MaxFiles      int  // Uploads per batch; a full batch is broadcast at once (default 20)
+	MaxFileBytes  int  // Largest inscribed payload accepted into a batch (default 10240)
+}
+
+// UploadRateLimitConfig token buckets limiting uploads per address and per
+// client IP (shared through Redis when redis.enabled)
+type UploadRateLimitConfig struct {
+	Enabled          bool // Enable upload rate limiting
+	AddressPerMinute int  // Sustained uploads per minute per address (default 10, negative = no address limit)
+	AddressBurst     int  // Uploads an idle address may send at once (default 2x AddressPerMinute)
+	IpPerMinute      int  // Sustained uploads per minute per client IP (default 30, negative = no IP limit)
+	IpBurst          int  // Uploads an idle client IP may send at once (default 2x IpPerMinute)
+}
+
+// UploaderChainPolicy dust and change thresholds used when building upload transactions
+type UploaderChainPolicy struct {
+	DustLimit int64 // Outputs and per-transaction funding are never below this (satoshis)
+	MinChange int64 // Change below this is left to the fee instead of creating an output (satoshis)
+}
+
+// UploadGzipConfig gzip compression applied to content before inscription
+type UploadGzipConfig struct {
+	Enabled          bool // Compress when the request does not choose (default false)
+	MinSavingPercent int  // Inscribe the gzip form only when it is at least this much smaller (default 10)
+}
+
+// FaucetConfig testnet faucet configuration (never active on mainnet)
+type FaucetConfig struct {
+	Enabled         bool   // Enable the faucet endpoint
+	RpcUrl          string // Faucet wallet node RPC (empty = mvc uploader chain RPC)
+	RpcUser         string // Faucet wallet node RPC username
+	RpcPass         string // Faucet wallet node RPC password
+	Amount          int64  // Satoshis sent per request
+	AddressCooldown int    // Seconds before the same address or client IP can be funded again
+	MaxPerHour      int    // Maximum grants per hour across all callers
+}
+
+// FaucetAllowed reports whether the faucet is enabled and the service runs outside mainnet
+func FaucetAllowed() bool {
+	if Cfg == nil || !Cfg.Uploader.Faucet.Enabled {
+		return false
+	}
+	return Cfg.Net != "mainnet" && SystemEnvironmentEnum != MainnetEnvironmentEnum
+}
+
+// NotifyConfig operational alerts by email and Telegram (both services)
+type NotifyConfig struct {
+	RateLimitSeconds    int                 // Alerts of one event type at most once per window; the rest are counted into the next one (0 = 300)
+	Routes              map[string][]string // Event type -> channels (email/telegram); unlisted events go to every enabled channel, [] mutes one
+	ScannerStallMinutes int                 // Indexer: alert when the sync height is behind the node and has not moved for this long (0 = 15)
+	Email               NotifyEmailConfig
+	Telegram            NotifyTelegramConfig
+}
+
+// ShutdownConfig graceful shutdown on SIGINT/SIGTERM: HTTP requests drain,
+// scanners and rescans stop after their current block and upload tasks at
+// their next stage, all within one deadline
+type ShutdownConfig struct {
+	TimeoutSecs int // Components still stopping after this are abandoned; 0 = 30
+}
+
+// MetaIDConfig how MetaIDs are derived from addresses; indexer and uploader
+// of one deployment must agree (see service/common_service/metaid)
+type MetaIDConfig struct {
+	Derivation string // address_sha256 (default) or genesis_txid
+}
+
+// NotifyEmailConfig SMTP alert channel
+type NotifyEmailConfig struct {
+	Enabled  bool
+	Host     string
+	Port     int // 0 = 587 (STARTTLS submission)
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// NotifyTelegramConfig Telegram bot alert channel
+type NotifyTelegramConfig struct {
+	Enabled  bool
+	BotToken string
+	ChatID   string
 }
 
 // RpcConfig RPC configuration
@@ -177,6 +729,9 @@ func InitConfig() error {
 	if err := viper.ReadInConfig(); err != nil {
 		return fmt.Errorf("Fatal error config file: %s", err)
 	}
+	if err := resolveSecrets(); err != nil {
+		return fmt.Errorf("failed to resolve config secrets: %w", err)
+	}
 
 	// Create configuration instance
 	Cfg = &Config{
@@ -187,10 +742,17 @@ func InitConfig() error {
 
 		Database: DatabaseConfig{
 			IndexerType:  viper.GetString("database.indexer_type"),
+			UploaderType: viper.GetString("database.uploader_type"),
 			Dsn:          viper.GetString("database.dsn"),
 			MaxOpenConns: viper.GetInt("database.max_open_conns"),
 			MaxIdleConns: viper.GetInt("database.max_idle_conns"),
 			DataDir:      viper.GetString("database.data_dir"),
+			SqlitePath:   viper.GetString("database.sqlite_path"),
+			Pebble: PebbleTuningConfig{
+				CacheMB:                  viper.GetInt("database.pebble.cache_mb"),
+				MemTableMB:               viper.GetInt("database.pebble.memtable_mb"),
+				MaxConcurrentCompactions: viper.GetInt("database.pebble.max_concurrent_compactions"),
+			},
 		},
 
 		Chain: ChainConfig{
@@ -198,10 +760,21 @@ func InitConfig() error {
 			RpcUser:     viper.GetString("chain.rpc_user"),
 			RpcPass:     viper.GetString("chain.rpc_pass"),
 			StartHeight: viper.GetInt64("chain.start_height"),
+			EsploraUrl:  viper.GetString("chain.esplora_url"),
 		},
 
 		Storage: StorageConfig{
-			Type: viper.GetString("storage.type"),
+			Type:          viper.GetString("storage.type"),
+			LayoutVersion: viper.GetInt("storage.layout_version"),
+			PathTemplate: StoragePathTemplateConfig{
+				File:  viper.GetString("storage.path_template.file"),
+				Chunk: viper.GetString("storage.path_template.chunk"),
+			},
+			Migration: StorageMigrationConfig{
+				Enabled:  viper.GetBool("storage.migration.enabled"),
+				Target:   viper.GetString("storage.migration.target"),
+				Interval: viper.GetInt("storage.migration.interval"),
+			},
 			Local: LocalStorageConfig{
 				BasePath: viper.GetString("storage.local.base_path"),
 			},
@@ -228,6 +801,11 @@ func InitConfig() error {
 				UseSSL:    viper.GetBool("storage.minio.use_ssl"),
 				Domain:    viper.GetString("storage.minio.domain"),
 			},
+			Gateway: GatewayStorageConfig{
+				Url:            viper.GetString("storage.gateway.url"),
+				Token:          viper.GetString("storage.gateway.token"),
+				TimeoutSeconds: viper.GetInt("storage.gateway.timeout_seconds"),
+			},
 		},
 
 		Indexer: IndexerConfig{
@@ -243,6 +821,91 @@ func InitConfig() error {
 			ZmqAddress:          viper.GetString("indexer.zmq_address"),
 			LargeBlockSizeMB:    viper.GetInt("indexer.large_block_size_mb"),
 			TimeOrderingEnabled: viper.GetBool("indexer.time_ordering_enabled"),
+			PathAllowlist:       viper.GetStringSlice("indexer.path_allowlist"),
+			PathDenylist:        viper.GetStringSlice("indexer.path_denylist"),
+			FeedLinkTemplate:    viper.GetString("indexer.feed_link_template"),
+			RenderIDAddress:     viper.GetBool("indexer.render_id_address"),
+			AvatarMaxSizeKB:     viper.GetInt("indexer.avatar_max_size_kb"),
+			AvatarMaxDimension:  viper.GetInt("indexer.avatar_max_dimension"),
+			AvatarDownscale:     viper.GetBool("indexer.avatar_downscale"),
+			GzipMaxOutputMB:     viper.GetInt("indexer.gzip_max_output_mb"),
+			GzipMaxRatio:        viper.GetInt("indexer.gzip_max_ratio"),
+			MaxMergedFileMB:     viper.GetInt("indexer.max_merged_file_mb"),
+			StreamThresholdMB:   viper.GetInt("indexer.stream_threshold_mb"),
+			ParserMode:          viper.GetString("indexer.parser_mode"),
+			ParserFeatures:      getBoolMapFromMap(viper.GetStringMap("indexer"), "parser_features"),
+			Domains: IndexerDomainsConfig{
+				CacheSeconds: viper.GetInt("indexer.domains.cache_seconds"),
+				Acme: IndexerAcmeConfig{
+					Enabled:   viper.GetBool("indexer.domains.acme.enabled"),
+					Email:     viper.GetString("indexer.domains.acme.email"),
+					CacheDir:  viper.GetString("indexer.domains.acme.cache_dir"),
+					HttpsPort: viper.GetString("indexer.domains.acme.https_port"),
+					HttpPort:  viper.GetString("indexer.domains.acme.http_port"),
+				},
+			},
+			Mirror: IndexerMirrorConfig{
+				Enabled:        viper.GetBool("indexer.mirror.enabled"),
+				Upstream:       viper.GetString("indexer.mirror.upstream"),
+				TimeoutSeconds: viper.GetInt("indexer.mirror.timeout_seconds"),
+				MaxFileMB:      viper.GetInt("indexer.mirror.max_file_mb"),
+			},
+			Confirmations: IndexerConfirmationsConfig{
+				Min:               viper.GetInt64("indexer.confirmations.min"),
+				RefuseProvisional: viper.GetBool("indexer.confirmations.refuse_provisional"),
+			},
+			Peers: IndexerPeersConfig{
+				Urls:            viper.GetStringSlice("indexer.peers.urls"),
+				Discover:        viper.GetBool("indexer.peers.discover"),
+				DiscoverMinutes: viper.GetInt("indexer.peers.discover_minutes"),
+				TimeoutSeconds:  viper.GetInt("indexer.peers.timeout_seconds"),
+				MaxFileMB:       viper.GetInt("indexer.peers.max_file_mb"),
+			},
+			Pipeline: IndexerPipelineConfig{
+				FetchWorkers:   viper.GetInt("indexer.pipeline.fetch_workers"),
+				ParseWorkers:   viper.GetInt("indexer.pipeline.parse_workers"),
+				ResolveWorkers: viper.GetInt("indexer.pipeline.resolve_workers"),
+				QueueSize:      viper.GetInt("indexer.pipeline.queue_size"),
+			},
+			StorageRetry: IndexerStorageRetryConfig{
+				IntervalSeconds:   viper.GetInt("indexer.storage_retry.interval_seconds"),
+				MaxBackoffSeconds: viper.GetInt("indexer.storage_retry.max_backoff_seconds"),
+			},
+			CreatorRetry: IndexerCreatorRetryConfig{
+				IntervalSeconds:   viper.GetInt("indexer.creator_retry.interval_seconds"),
+				MaxBackoffSeconds: viper.GetInt("indexer.creator_retry.max_backoff_seconds"),
+				MaxAttempts:       viper.GetInt("indexer.creator_retry.max_attempts"),
+			},
+			EventStream: IndexerEventStreamConfig{
+				Enabled:     viper.GetBool("indexer.event_stream.enabled"),
+				Broker:      viper.GetString("indexer.event_stream.broker"),
+				URLs:        viper.GetStringSlice("indexer.event_stream.urls"),
+				TopicPrefix: viper.GetString("indexer.event_stream.topic_prefix"),
+				Format:      viper.GetString("indexer.event_stream.format"),
+				Actions:     viper.GetStringSlice("indexer.event_stream.actions"),
+				BatchSize:   viper.GetInt("indexer.event_stream.batch_size"),
+				PollSeconds: viper.GetInt("indexer.event_stream.poll_seconds"),
+				CursorFile:  viper.GetString("indexer.event_stream.cursor_file"),
+			},
+			WatchOutbox: IndexerWatchOutboxConfig{
+				IntervalSeconds:   viper.GetInt("indexer.watch_outbox.interval_seconds"),
+				MaxBackoffSeconds: viper.GetInt("indexer.watch_outbox.max_backoff_seconds"),
+				MaxAttempts:       viper.GetInt("indexer.watch_outbox.max_attempts"),
+			},
+			WebDAV: IndexerWebDAVConfig{
+				Enabled:      viper.GetBool("indexer.webdav.enabled"),
+				CacheSeconds: viper.GetInt("indexer.webdav.cache_seconds"),
+			},
+			DiskWatchdog: IndexerDiskWatchdogConfig{
+				Enabled:         viper.GetBool("indexer.disk_watchdog.enabled"),
+				MinFreeMB:       viper.GetInt("indexer.disk_watchdog.min_free_mb"),
+				ResumeFreeMB:    viper.GetInt("indexer.disk_watchdog.resume_free_mb"),
+				IntervalSeconds: viper.GetInt("indexer.disk_watchdog.interval_seconds"),
+				Paths:           viper.GetStringSlice("indexer.disk_watchdog.paths"),
+			},
+			RescanSchedule: IndexerRescanScheduleConfig{
+				Enabled: viper.GetBool("indexer.rescan_schedule.enabled"),
+			},
 		},
 
 		Uploader: UploaderConfig{
@@ -251,6 +914,92 @@ func InitConfig() error {
 			ChunkSize:      viper.GetInt64("uploader.chunk_size") * 1024 * 1024, // MB to bytes
 			SwaggerBaseUrl: viper.GetString("uploader.swagger_base_url"),
 			Chains:         nil, // populated below from uploader.chains
+			Faucet: FaucetConfig{
+				Enabled:         viper.GetBool("uploader.faucet.enabled"),
+				RpcUrl:          viper.GetString("uploader.faucet.rpc_url"),
+				RpcUser:         viper.GetString("uploader.faucet.rpc_user"),
+				RpcPass:         viper.GetString("uploader.faucet.rpc_pass"),
+				Amount:          viper.GetInt64("uploader.faucet.amount"),
+				AddressCooldown: viper.GetInt("uploader.faucet.address_cooldown"),
+				MaxPerHour:      viper.GetInt("uploader.faucet.max_per_hour"),
+			},
+			Gzip: UploadGzipConfig{
+				Enabled:          viper.GetBool("uploader.gzip.enabled"),
+				MinSavingPercent: viper.GetInt("uploader.gzip.min_saving_percent"),
+			},
+			ChunkBasePaths: viper.GetStringSlice("uploader.chunk_base_paths"),
+			DustLimit:      viper.GetInt64("uploader.dust_limit"),
+			MinChange:      viper.GetInt64("uploader.min_change"),
+
+			EphemeralRetentionHours: viper.GetInt("uploader.ephemeral_retention_hours"),
+			MaxSinglePayloadBytes:   viper.GetInt64("uploader.max_single_payload_bytes"),
+			AdminEnabled:            viper.GetBool("uploader.admin_enabled"),
+			RateLimit: UploadRateLimitConfig{
+				Enabled:          viper.GetBool("uploader.rate_limit.enabled"),
+				AddressPerMinute: viper.GetInt("uploader.rate_limit.address_per_minute"),
+				AddressBurst:     viper.GetInt("uploader.rate_limit.address_burst"),
+				IpPerMinute:      viper.GetInt("uploader.rate_limit.ip_per_minute"),
+				IpBurst:          viper.GetInt("uploader.rate_limit.ip_burst"),
+			},
+			UrlFetch: UploadUrlFetchConfig{
+				Enabled:              viper.GetBool("uploader.url_fetch.enabled"),
+				AllowedSchemes:       viper.GetStringSlice("uploader.url_fetch.allowed_schemes"),
+				AllowedContentTypes:  viper.GetStringSlice("uploader.url_fetch.allowed_content_types"),
+				MaxSizeMB:            viper.GetInt64("uploader.url_fetch.max_size_mb"),
+				TimeoutSeconds:       viper.GetInt("uploader.url_fetch.timeout_seconds"),
+				AllowPrivateNetworks: viper.GetBool("uploader.url_fetch.allow_private_networks"),
+			},
+			Schedule: UploadScheduleConfig{
+				MaxDelayHours:     viper.GetInt("uploader.schedule.max_delay_hours"),
+				IntervalSeconds:   viper.GetInt("uploader.schedule.interval_seconds"),
+				FeeEstimateBlocks: viper.GetInt("uploader.schedule.fee_estimate_blocks"),
+			},
+			Batch: UploadBatchConfig{
+				Enabled:       viper.GetBool("uploader.batch.enabled"),
+				WindowSeconds: viper.GetInt("uploader.batch.window_seconds"),
+				MaxFiles:      viper.GetInt("uploader.batch.max_files"),
+				MaxFileBytes:  viper.GetInt("uploader.batch.max_file_bytes"),
+			},
+			TaskTTL: UploadTaskTTLConfig{
+				PendingHours:    viper.GetInt("uploader.task_ttl.pending_hours"),
+				ProcessingHours: viper.GetInt("uploader.task_ttl.processing_hours"),
+			},
+			Delegated: UploadDelegatedConfig{
+				Enabled:         viper.GetBool("uploader.delegated.enabled"),
+				RpcUrl:          viper.GetString("uploader.delegated.rpc_url"),
+				RpcUser:         viper.GetString("uploader.delegated.rpc_user"),
+				RpcPass:         viper.GetString("uploader.delegated.rpc_pass"),
+				ApiKeys:         viper.GetStringMapString("uploader.delegated.api_keys"),
+				MaxFeePerUpload: viper.GetInt64("uploader.delegated.max_fee_per_upload"),
+			},
+			Idempotency: UploadIdempotencyConfig{
+				TtlHours:          viper.GetInt("uploader.idempotency.ttl_hours"),
+				ProcessingMinutes: viper.GetInt("uploader.idempotency.processing_minutes"),
+			},
+			Preflight: UploadPreflightConfig{
+				NodeCheck: viper.GetString("uploader.preflight.node_check"),
+			},
+			Delta: UploadDeltaConfig{
+				IndexerUrl:     viper.GetString("uploader.delta.indexer_url"),
+				TimeoutSeconds: viper.GetInt("uploader.delta.timeout_seconds"),
+			},
+			ChunkFunding: UploadChunkFundingConfig{
+				Strategy:             viper.GetString("uploader.chunk_funding.strategy"),
+				AllowRequestStrategy: viper.GetBool("uploader.chunk_funding.allow_request_strategy"),
+				HotWalletKey:         viper.GetString("uploader.chunk_funding.hot_wallet_key"),
+				MaxPerUpload:         viper.GetInt64("uploader.chunk_funding.max_per_upload"),
+				LockMinutes:          viper.GetInt("uploader.chunk_funding.lock_minutes"),
+			},
+			UtxoLock: UploadUtxoLockConfig{
+				Enabled:    !viper.IsSet("uploader.utxo_lock.enabled") || viper.GetBool("uploader.utxo_lock.enabled"),
+				TTLMinutes: viper.GetInt("uploader.utxo_lock.ttl_minutes"),
+			},
+			Keep: UploadKeepConfig{
+				Enabled:             viper.GetBool("uploader.keep.enabled"),
+				MaxFilesPerOwner:    viper.GetInt("uploader.keep.max_files_per_owner"),
+				MaxMBPerOwner:       viper.GetInt64("uploader.keep.max_mb_per_owner"),
+				SignatureMaxAgeSecs: viper.GetInt("uploader.keep.signature_max_age_secs"),
+			},
 		},
 
 		Redis: RedisConfig{
@@ -261,6 +1010,57 @@ func InitConfig() error {
 			DB:       viper.GetInt("redis.db"),
 			CacheTTL: viper.GetInt("redis.cache_ttl"),
 		},
+
+		Http: HttpConfig{
+			Cors: CorsConfig{
+				AllowOrigins:     viper.GetStringSlice("http.cors.allow_origins"),
+				AllowMethods:     viper.GetStringSlice("http.cors.allow_methods"),
+				AllowHeaders:     viper.GetStringSlice("http.cors.allow_headers"),
+				ExposeHeaders:    viper.GetStringSlice("http.cors.expose_headers"),
+				AllowCredentials: viper.GetBool("http.cors.allow_credentials"),
+				MaxAge:           viper.GetInt("http.cors.max_age"),
+			},
+			MaxBodyMB:       viper.GetInt("http.max_body_mb"),
+			UploadMaxBodyMB: viper.GetInt("http.upload_max_body_mb"),
+			Gzip:            viper.GetBool("http.gzip"),
+			TrustedProxies:  viper.GetStringSlice("http.trusted_proxies"),
+			AccessLog: HttpAccessLogConfig{
+				Enabled:      viper.GetBool("http.access_log.enabled"),
+				SampleRate:   viper.GetFloat64("http.access_log.sample_rate"),
+				SlowMs:       viper.GetInt("http.access_log.slow_ms"),
+				LogBodies:    viper.GetBool("http.access_log.log_bodies"),
+				MaxBodyBytes: viper.GetInt("http.access_log.max_body_bytes"),
+				RedactFields: viper.GetStringSlice("http.access_log.redact_fields"),
+			},
+		},
+
+		Notify: NotifyConfig{
+			RateLimitSeconds:    viper.GetInt("notify.rate_limit_seconds"),
+			Routes:              viper.GetStringMapStringSlice("notify.routes"),
+			ScannerStallMinutes: viper.GetInt("notify.scanner_stall_minutes"),
+			Email: NotifyEmailConfig{
+				Enabled:  viper.GetBool("notify.email.enabled"),
+				Host:     viper.GetString("notify.email.host"),
+				Port:     viper.GetInt("notify.email.port"),
+				Username: viper.GetString("notify.email.username"),
+				Password: viper.GetString("notify.email.password"),
+				From:     viper.GetString("notify.email.from"),
+				To:       viper.GetStringSlice("notify.email.to"),
+			},
+			Telegram: NotifyTelegramConfig{
+				Enabled:  viper.GetBool("notify.telegram.enabled"),
+				BotToken: viper.GetString("notify.telegram.bot_token"),
+				ChatID:   viper.GetString("notify.telegram.chat_id"),
+			},
+		},
+
+		MetaID: MetaIDConfig{
+			Derivation: viper.GetString("metaid.derivation"),
+		},
+
+		Shutdown: ShutdownConfig{
+			TimeoutSecs: viper.GetInt("shutdown.timeout_secs"),
+		},
 	}
 
 	// Set default values
@@ -274,12 +1074,24 @@ func InitConfig() error {
 	if Cfg.Port == "" {
 		Cfg.Port = Cfg.IndexerPort
 	}
+	if Cfg.Shutdown.TimeoutSecs <= 0 {
+		Cfg.Shutdown.TimeoutSecs = 30
+	}
 	if Cfg.Storage.Type == "" {
 		Cfg.Storage.Type = "local"
 	}
+	if Cfg.Storage.PathTemplate.File == "" {
+		Cfg.Storage.PathTemplate.File = "indexer/t/{chain}/{year}/{month}/{pinId}{ext}"
+	}
+	if Cfg.Storage.PathTemplate.Chunk == "" {
+		Cfg.Storage.PathTemplate.Chunk = "indexer/t/chunk/{chain}/{year}/{month}/{pinId}"
+	}
 	if Cfg.Storage.Local.BasePath == "" {
 		Cfg.Storage.Local.BasePath = "./data/files"
 	}
+	if Cfg.Storage.Gateway.TimeoutSeconds <= 0 {
+		Cfg.Storage.Gateway.TimeoutSeconds = 30
+	}
 	if Cfg.Indexer.ScanInterval == 0 {
 		Cfg.Indexer.ScanInterval = 10
 	}
@@ -292,6 +1104,112 @@ func InitConfig() error {
 	if Cfg.Uploader.FeeRate == 0 {
 		Cfg.Uploader.FeeRate = 1
 	}
+	if Cfg.Uploader.DustLimit <= 0 {
+		Cfg.Uploader.DustLimit = 600
+	}
+	if Cfg.Uploader.MinChange <= 0 {
+		Cfg.Uploader.MinChange = Cfg.Uploader.DustLimit
+	}
+	if Cfg.Uploader.Faucet.Amount <= 0 {
+		Cfg.Uploader.Faucet.Amount = 1000000 // 0.01 coin
+	}
+	if Cfg.Uploader.Faucet.AddressCooldown <= 0 {
+		Cfg.Uploader.Faucet.AddressCooldown = 3600
+	}
+	if Cfg.Uploader.Faucet.MaxPerHour <= 0 {
+		Cfg.Uploader.Faucet.MaxPerHour = 20
+	}
+	if Cfg.Uploader.RateLimit.AddressPerMinute == 0 {
+		Cfg.Uploader.RateLimit.AddressPerMinute = 10
+	}
+	if Cfg.Uploader.RateLimit.AddressBurst <= 0 {
+		Cfg.Uploader.RateLimit.AddressBurst = 2 * Cfg.Uploader.RateLimit.AddressPerMinute
+	}
+	if Cfg.Uploader.RateLimit.IpPerMinute == 0 {
+		Cfg.Uploader.RateLimit.IpPerMinute = 30
+	}
+	if Cfg.Uploader.RateLimit.IpBurst <= 0 {
+		Cfg.Uploader.RateLimit.IpBurst = 2 * Cfg.Uploader.RateLimit.IpPerMinute
+	}
+	if len(Cfg.Uploader.UrlFetch.AllowedSchemes) == 0 {
+		Cfg.Uploader.UrlFetch.AllowedSchemes = []string{"https"}
+	}
+	if Cfg.Uploader.UrlFetch.TimeoutSeconds <= 0 {
+		Cfg.Uploader.UrlFetch.TimeoutSeconds = 60
+	}
+	if Cfg.Uploader.Schedule.MaxDelayHours <= 0 {
+		Cfg.Uploader.Schedule.MaxDelayHours = 720
+	}
+	if Cfg.Uploader.Schedule.IntervalSeconds <= 0 {
+		Cfg.Uploader.Schedule.IntervalSeconds = 30
+	}
+	if Cfg.Uploader.Schedule.FeeEstimateBlocks <= 0 {
+		Cfg.Uploader.Schedule.FeeEstimateBlocks = 2
+	}
+	if Cfg.Uploader.Batch.WindowSeconds <= 0 {
+		Cfg.Uploader.Batch.WindowSeconds = 5
+	}
+	if Cfg.Uploader.Batch.MaxFiles <= 0 {
+		Cfg.Uploader.Batch.MaxFiles = 20
+	}
+	if Cfg.Uploader.Batch.MaxFileBytes <= 0 {
+		Cfg.Uploader.Batch.MaxFileBytes = 10240
+	}
+	if Cfg.Uploader.TaskTTL.PendingHours <= 0 {
+		Cfg.Uploader.TaskTTL.PendingHours = 24
+	}
+	if Cfg.Uploader.TaskTTL.ProcessingHours <= 0 {
+		Cfg.Uploader.TaskTTL.ProcessingHours = 24
+	}
+	if Cfg.Uploader.Delegated.MaxFeePerUpload <= 0 {
+		Cfg.Uploader.Delegated.MaxFeePerUpload = 1000000 // 0.01 coin
+	}
+	if Cfg.Uploader.Idempotency.TtlHours <= 0 {
+		Cfg.Uploader.Idempotency.TtlHours = 24
+	}
+	if Cfg.Uploader.Idempotency.ProcessingMinutes <= 0 {
+		Cfg.Uploader.Idempotency.ProcessingMinutes = 30
+	}
+	switch Cfg.Uploader.Preflight.NodeCheck {
+	case "auto", "off":
+	case "":
+		Cfg.Uploader.Preflight.NodeCheck = "auto"
+	default:
+		fmt.Printf("⚠️  Unknown uploader.preflight.node_check %q, using auto\n", Cfg.Uploader.Preflight.NodeCheck)
+		Cfg.Uploader.Preflight.NodeCheck = "auto"
+	}
+	if Cfg.Uploader.Delta.TimeoutSeconds <= 0 {
+		Cfg.Uploader.Delta.TimeoutSeconds = 60
+	}
+	switch Cfg.Uploader.ChunkFunding.Strategy {
+	case "assistent", "hot_wallet":
+	case "":
+		Cfg.Uploader.ChunkFunding.Strategy = "assistent"
+	default:
+		fmt.Printf("⚠️  Unknown uploader.chunk_funding.strategy %q, using assistent\n", Cfg.Uploader.ChunkFunding.Strategy)
+		Cfg.Uploader.ChunkFunding.Strategy = "assistent"
+	}
+	if Cfg.Uploader.ChunkFunding.MaxPerUpload <= 0 {
+		Cfg.Uploader.ChunkFunding.MaxPerUpload = 1000000 // 0.01 coin
+	}
+	if Cfg.Uploader.ChunkFunding.LockMinutes <= 0 {
+		Cfg.Uploader.ChunkFunding.LockMinutes = 60
+	}
+	if Cfg.Uploader.UtxoLock.TTLMinutes <= 0 {
+		Cfg.Uploader.UtxoLock.TTLMinutes = 30
+	}
+	if !viper.IsSet("uploader.keep.max_files_per_owner") {
+		Cfg.Uploader.Keep.MaxFilesPerOwner = 100
+	}
+	if Cfg.Uploader.Keep.SignatureMaxAgeSecs <= 0 {
+		Cfg.Uploader.Keep.SignatureMaxAgeSecs = 600
+	}
+	if Cfg.Uploader.Gzip.MinSavingPercent <= 0 {
+		Cfg.Uploader.Gzip.MinSavingPercent = 10
+	}
+	if Cfg.Storage.Migration.Interval <= 0 {
+		Cfg.Storage.Migration.Interval = 300
+	}
 	if Cfg.Database.MaxOpenConns == 0 {
 		Cfg.Database.MaxOpenConns = 100
 	}
@@ -301,6 +1219,123 @@ func InitConfig() error {
 	if Cfg.Indexer.LargeBlockSizeMB <= 0 {
 		Cfg.Indexer.LargeBlockSizeMB = 50 // 50MB default
 	}
+	if Cfg.Notify.RateLimitSeconds <= 0 {
+		Cfg.Notify.RateLimitSeconds = 300
+	}
+	if Cfg.Notify.ScannerStallMinutes <= 0 {
+		Cfg.Notify.ScannerStallMinutes = 15
+	}
+	if Cfg.Notify.Email.Port <= 0 {
+		Cfg.Notify.Email.Port = 587
+	}
+	if Cfg.Indexer.Domains.CacheSeconds <= 0 {
+		Cfg.Indexer.Domains.CacheSeconds = 30
+	}
+	if Cfg.Indexer.Domains.Acme.CacheDir == "" {
+		Cfg.Indexer.Domains.Acme.CacheDir = "./data/acme"
+	}
+	if Cfg.Indexer.Domains.Acme.HttpsPort == "" {
+		Cfg.Indexer.Domains.Acme.HttpsPort = "443"
+	}
+	if Cfg.Indexer.Domains.Acme.HttpPort == "" {
+		Cfg.Indexer.Domains.Acme.HttpPort = "80"
+	}
+	switch Cfg.Indexer.ParserMode {
+	case "strict", "lenient":
+	case "":
+		Cfg.Indexer.ParserMode = "lenient"
+	default:
+		fmt.Printf("⚠️  Unknown indexer.parser_mode %q, using lenient\n", Cfg.Indexer.ParserMode)
+		Cfg.Indexer.ParserMode = "lenient"
+	}
+	if Cfg.Indexer.Mirror.TimeoutSeconds <= 0 {
+		Cfg.Indexer.Mirror.TimeoutSeconds = 30
+	}
+	if Cfg.Indexer.Mirror.MaxFileMB <= 0 {
+		Cfg.Indexer.Mirror.MaxFileMB = 100
+	}
+	if Cfg.Indexer.Pipeline.FetchWorkers <= 0 {
+		Cfg.Indexer.Pipeline.FetchWorkers = 4
+	}
+	if Cfg.Indexer.Pipeline.ParseWorkers <= 0 {
+		Cfg.Indexer.Pipeline.ParseWorkers = runtime.NumCPU()
+	}
+	if Cfg.Indexer.Pipeline.ResolveWorkers <= 0 {
+		Cfg.Indexer.Pipeline.ResolveWorkers = 4
+	}
+	if Cfg.Indexer.Pipeline.QueueSize <= 0 {
+		Cfg.Indexer.Pipeline.QueueSize = 256
+	}
+	if Cfg.Indexer.StorageRetry.IntervalSeconds <= 0 {
+		Cfg.Indexer.StorageRetry.IntervalSeconds = 30
+	}
+	if Cfg.Indexer.StorageRetry.MaxBackoffSeconds <= 0 {
+		Cfg.Indexer.StorageRetry.MaxBackoffSeconds = 1800
+	}
+	if Cfg.Indexer.CreatorRetry.IntervalSeconds <= 0 {
+		Cfg.Indexer.CreatorRetry.IntervalSeconds = 60
+	}
+	if Cfg.Indexer.CreatorRetry.MaxBackoffSeconds <= 0 {
+		Cfg.Indexer.CreatorRetry.MaxBackoffSeconds = 3600
+	}
+	if Cfg.Indexer.CreatorRetry.MaxAttempts <= 0 {
+		Cfg.Indexer.CreatorRetry.MaxAttempts = 48
+	}
+	if Cfg.Indexer.WatchOutbox.IntervalSeconds <= 0 {
+		Cfg.Indexer.WatchOutbox.IntervalSeconds = 10
+	}
+	if Cfg.Indexer.WatchOutbox.MaxBackoffSeconds <= 0 {
+		Cfg.Indexer.WatchOutbox.MaxBackoffSeconds = 1800
+	}
+	if Cfg.Indexer.WatchOutbox.MaxAttempts <= 0 {
+		Cfg.Indexer.WatchOutbox.MaxAttempts = 20
+	}
+	if Cfg.Indexer.EventStream.TopicPrefix == "" {
+		Cfg.Indexer.EventStream.TopicPrefix = "metafs"
+	}
+	if Cfg.Indexer.EventStream.Format == "" {
+		Cfg.Indexer.EventStream.Format = "json"
+	}
+	if Cfg.Indexer.EventStream.BatchSize <= 0 {
+		Cfg.Indexer.EventStream.BatchSize = 500
+	}
+	if Cfg.Indexer.EventStream.PollSeconds <= 0 {
+		Cfg.Indexer.EventStream.PollSeconds = 2
+	}
+	if Cfg.Indexer.EventStream.CursorFile == "" {
+		Cfg.Indexer.EventStream.CursorFile = "./data/event_stream.cursor"
+	}
+	if Cfg.Indexer.DiskWatchdog.MinFreeMB <= 0 {
+		Cfg.Indexer.DiskWatchdog.MinFreeMB = 1024
+	}
+	if Cfg.Indexer.DiskWatchdog.ResumeFreeMB < Cfg.Indexer.DiskWatchdog.MinFreeMB {
+		Cfg.Indexer.DiskWatchdog.ResumeFreeMB = 2 * Cfg.Indexer.DiskWatchdog.MinFreeMB
+	}
+	if Cfg.Indexer.DiskWatchdog.IntervalSeconds <= 0 {
+		Cfg.Indexer.DiskWatchdog.IntervalSeconds = 30
+	}
+	if Cfg.Indexer.WebDAV.CacheSeconds <= 0 {
+		Cfg.Indexer.WebDAV.CacheSeconds = 10
+	}
+	if err := viper.UnmarshalKey("indexer.rescan_schedule.jobs", &Cfg.Indexer.RescanSchedule.Jobs); err != nil {
+		return fmt.Errorf("failed to parse indexer.rescan_schedule.jobs: %w", err)
+	}
+	for i := range Cfg.Indexer.RescanSchedule.Jobs {
+		if Cfg.Indexer.RescanSchedule.Jobs[i].Blocks <= 0 {
+			Cfg.Indexer.RescanSchedule.Jobs[i].Blocks = 144
+		}
+	}
+	if err := viper.UnmarshalKey("http.access_log.routes", &Cfg.Http.AccessLog.Routes); err != nil {
+		return fmt.Errorf("failed to parse http.access_log.routes: %w", err)
+	}
+	if err := viper.UnmarshalKey("indexer.listeners", &Cfg.Indexer.Listeners); err != nil {
+		return fmt.Errorf("failed to parse indexer.listeners: %w", err)
+	}
+	Cfg.Indexer.Listeners = listenerDefaults(Cfg.Indexer.Listeners, Cfg.IndexerPort)
+	if err := viper.UnmarshalKey("uploader.listeners", &Cfg.Uploader.Listeners); err != nil {
+		return fmt.Errorf("failed to parse uploader.listeners: %w", err)
+	}
+	Cfg.Uploader.Listeners = listenerDefaults(Cfg.Uploader.Listeners, Cfg.UploaderPort)
 	if Cfg.Indexer.SwaggerBaseUrl == "" {
 		Cfg.Indexer.SwaggerBaseUrl = "localhost:" + Cfg.IndexerPort
 	}
@@ -314,11 +1349,11 @@ func InitConfig() error {
 
 		// Debug: print raw config value
 		rawChains := viper.Get("indexer.chains")
-		fmt.Printf("📋 Raw chains config type: %T, value: %+v\n", rawChains, rawChains)
+		fmt.Print(secrets.Redact(fmt.Sprintf("📋 Raw chains config type: %T, value: %+v\n", rawChains, rawChains)))
 
 		var chains []ChainInstanceConfig
 		if err := viper.UnmarshalKey("indexer.chains", &chains); err != nil {
-			fmt.Printf("❌ Warning: failed to parse indexer.chains: %v\n", err)
+			fmt.Print(secrets.Redact(fmt.Sprintf("❌ Warning: failed to parse indexer.chains: %v\n", err)))
 
 			// Try alternative parsing method
 			fmt.Println("🔄 Trying alternative parsing method...")
@@ -335,9 +1370,15 @@ func InitConfig() error {
 							StartHeight: getInt64FromMap(chainMap, "start_height"),
 							ZmqEnabled:  getBoolFromMap(chainMap, "zmq_enabled"),
 							ZmqAddress:  getStringFromMap(chainMap, "zmq_address"),
+							EsploraUrl:  getStringFromMap(chainMap, "esplora_url"),
+
+							ParserMode:     getStringFromMap(chainMap, "parser_mode"),
+							ParserFeatures: getBoolMapFromMap(chainMap, "parser_features"),
+
+							MinConfirmations: getInt64FromMap(chainMap, "min_confirmations"),
 						}
 						chains = append(chains, chain)
-						fmt.Printf("  ✅ Parsed chain %d: %s (RPC: %s)\n", i+1, chain.Name, chain.RpcUrl)
+						fmt.Print(secrets.Redact(fmt.Sprintf("  ✅ Parsed chain %d: %s (RPC: %s)\n", i+1, chain.Name, chain.RpcUrl)))
 					}
 				}
 			}
@@ -347,8 +1388,8 @@ func InitConfig() error {
 			Cfg.Indexer.Chains = chains
 			fmt.Printf("✅ Loaded %d chain configurations successfully\n", len(chains))
 			for i, chain := range chains {
-				fmt.Printf("  Chain %d: %s (RPC: %s, Start: %d, ZMQ: %v)\n",
-					i+1, chain.Name, chain.RpcUrl, chain.StartHeight, chain.ZmqEnabled)
+				fmt.Print(secrets.Redact(fmt.Sprintf("  Chain %d: %s (RPC: %s, Start: %d, ZMQ: %v)\n",
+					i+1, chain.Name, chain.RpcUrl, chain.StartHeight, chain.ZmqEnabled)))
 			}
 		} else {
 			fmt.Println("⚠️  No chains loaded, falling back to single-chain mode")
@@ -363,7 +1404,7 @@ func InitConfig() error {
 		fmt.Println("🔍 Loading uploader.chains configuration...")
 		var uploaderChains []UploaderChainConfig
 		if err := viper.UnmarshalKey("uploader.chains", &uploaderChains); err != nil {
-			fmt.Printf("❌ Warning: failed to parse uploader.chains: %v\n", err)
+			fmt.Print(secrets.Redact(fmt.Sprintf("❌ Warning: failed to parse uploader.chains: %v\n", err)))
 			// Try alternative parsing
 			chainsInterface := viper.Get("uploader.chains")
 			if chainsList, ok := chainsInterface.([]interface{}); ok {
@@ -379,10 +1420,13 @@ func InitConfig() error {
 						ChunkSize:      getInt64FromMap(m, "chunk_size"),
 						ChunkSizeBytes: getInt64FromMap(m, "chunk_size_bytes"),
 						FeeRate:        getInt64FromMap(m, "fee_rate"),
+
+						MaxSinglePayloadBytes: getInt64FromMap(m, "max_single_payload_bytes"),
+						MaxTxSize:             getInt64FromMap(m, "max_tx_size"),
 					}
 						if c.Name != "" && c.RpcUrl != "" {
 							uploaderChains = append(uploaderChains, c)
-							fmt.Printf("  ✅ Parsed uploader chain %d: %s (RPC: %s)\n", i+1, c.Name, c.RpcUrl)
+							fmt.Print(secrets.Redact(fmt.Sprintf("  ✅ Parsed uploader chain %d: %s (RPC: %s)\n", i+1, c.Name, c.RpcUrl)))
 						}
 					}
 				}
@@ -467,6 +1511,22 @@ func getBoolFromMap(m map[string]interface{}, key string) bool {
 	return false
 }
 
+// getBoolMapFromMap reads a nested map of booleans, e.g. parser_features;
+// nil when key is not set
+func getBoolMapFromMap(m map[string]interface{}, key string) map[string]bool {
+	nested, ok := m[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	out := make(map[string]bool, len(nested))
+	for name, val := range nested {
+		if b, ok := val.(bool); ok {
+			out[name] = b
+		}
+	}
+	return out
+}
+
 // GetUploaderChainConfig returns the uploader chain config for the given chain name
 func GetUploaderChainConfig(chain string) *UploaderChainConfig {
 	if chain == "" || Cfg == nil {
@@ -514,6 +1574,63 @@ func GetUploaderChainParam(chain string) (maxFileSize, chunkSize, feeRate int64)
 	return maxFileSize, chunkSize, feeRate
 }
 
+// GetUploaderChainPolicy returns the dust and change thresholds for the given
+// chain: the chain's dust_limit/min_change, else uploader.dust_limit/min_change,
+// else 600. MinChange is raised to DustLimit, since a smaller change output
+// would itself be dust.
+func GetUploaderChainPolicy(chain string) UploaderChainPolicy {
+	policy := UploaderChainPolicy{DustLimit: 600}
+	if Cfg != nil {
+		if Cfg.Uploader.DustLimit > 0 {
+			policy.DustLimit = Cfg.Uploader.DustLimit
+		}
+		policy.MinChange = Cfg.Uploader.MinChange
+		if c := GetUploaderChainConfig(chain); c != nil {
+			if c.DustLimit > 0 {
+				policy.DustLimit = c.DustLimit
+			}
+			if c.MinChange > 0 {
+				policy.MinChange = c.MinChange
+			}
+		}
+	}
+	if policy.MinChange < policy.DustLimit {
+		policy.MinChange = policy.DustLimit
+	}
+	return policy
+}
+
+// GetUploaderMaxSinglePayload returns the largest payload (bytes) a single PIN
+// may carry on the given chain: the chain's max_single_payload_bytes, else
+// uploader.max_single_payload_bytes, else the chain's chunk size (the payload
+// size chunk PINs are already relayed with).
+func GetUploaderMaxSinglePayload(chain string) int64 {
+	if c := GetUploaderChainConfig(chain); c != nil && c.MaxSinglePayloadBytes > 0 {
+		return c.MaxSinglePayloadBytes
+	}
+	if Cfg != nil && Cfg.Uploader.MaxSinglePayloadBytes > 0 {
+		return Cfg.Uploader.MaxSinglePayloadBytes
+	}
+	_, chunkSize, _ := GetUploaderChainParam(chain)
+	if chunkSize <= 0 {
+		chunkSize = 2000 * 1024
+	}
+	return chunkSize
+}
+
+// GetUploaderMaxTxSize returns the largest transaction (bytes) the node of
+// the given chain relays: the chain's max_tx_size, else the node default
+// (DOGE 100000, standard transaction size; MVC 10 MB)
+func GetUploaderMaxTxSize(chain string) int64 {
+	if c := GetUploaderChainConfig(chain); c != nil && c.MaxTxSize > 0 {
+		return c.MaxTxSize
+	}
+	if chain == "doge" {
+		return 100000
+	}
+	return 10 * 1024 * 1024
+}
+
 // GetUploaderChainNames returns the list of supported chain names
 func GetUploaderChainNames() []string {
 	if Cfg == nil {
@@ -0,0 +1,36 @@
+package conf
+
+import "strings"
+
+// listenerDefaults fills in the listener defaults; without listeners the API
+// is served on every IPv4 and IPv6 address on port.
+func listenerDefaults(listeners []ListenerConfig, port string) []ListenerConfig {
+	if len(listeners) == 0 {
+		listeners = []ListenerConfig{{Address: ":" + port}}
+	}
+	for i := range listeners {
+		l := &listeners[i]
+		l.Address = strings.TrimSpace(l.Address)
+		if l.Network == "" {
+			l.Network = "tcp"
+		}
+		if l.Routes == "" {
+			l.Routes = ListenerRoutesAll
+		}
+		if l.SocketMode == "" {
+			l.SocketMode = "0660"
+		}
+		if l.TLS.MinVersion == "" {
+			l.TLS.MinVersion = "1.2"
+		}
+		if l.TLS.ReloadSecs <= 0 {
+			l.TLS.ReloadSecs = 60
+		}
+	}
+	return listeners
+}
+
+// IsUnixListener reports whether address is a unix socket (unix:/path)
+func IsUnixListener(address string) bool {
+	return strings.HasPrefix(address, "unix:")
+}
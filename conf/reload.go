@@ -0,0 +1,34 @@
+package conf
+
+import (
+	"log"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// RuntimeConfig holds the settings that are safe to change at runtime
+// (currently the indexer protocol path allow/deny lists).
+type RuntimeConfig struct {
+	PathAllowlist []string
+	PathDenylist  []string
+}
+
+// WatchConfig watches the loaded config file and, on change, re-reads the
+// runtime settings and passes them to onChange. Cfg itself is never modified
+// after startup (other goroutines read it without locking), so onChange must
+// publish the new values through its own synchronized state, e.g.
+// metaid_protocols.SetPathFilter. Everything else still requires a restart.
+func WatchConfig(onChange func(RuntimeConfig)) {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		log.Printf("Config file changed: %s, reloading runtime settings", e.Name)
+		runtime := RuntimeConfig{
+			PathAllowlist: viper.GetStringSlice("indexer.path_allowlist"),
+			PathDenylist:  viper.GetStringSlice("indexer.path_denylist"),
+		}
+		if onChange != nil {
+			onChange(runtime)
+		}
+	})
+	viper.WatchConfig()
+}
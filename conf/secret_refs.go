@@ -0,0 +1,68 @@
+package conf
+
+import (
+	"fmt"
+	"log"
+
+	"meta-file-system/conf/secrets"
+
+	"github.com/spf13/viper"
+)
+
+// resolveSecrets replaces the secret references (${env:...}, ${file:...},
+// ${vault:...}, ${aws-sm:...}) in the loaded config with the secrets, before
+// any value is read, and masks the secrets in log output from then on. Config
+// values printed with fmt go through secrets.Redact.
+func resolveSecrets() error {
+	found := false
+	for _, key := range viper.AllKeys() {
+		value, changed, err := resolveSecretValue(viper.Get(key))
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if changed {
+			viper.Set(key, value)
+			found = true
+		}
+	}
+	if found {
+		log.SetOutput(secrets.RedactingWriter(log.Writer()))
+	}
+	return nil
+}
+
+// resolveSecretValue resolves the references in a string, or in the strings
+// of a list or map (e.g. indexer.chains); changed is false when there were none
+func resolveSecretValue(v interface{}) (interface{}, bool, error) {
+	switch v := v.(type) {
+	case string:
+		if !secrets.HasReference(v) {
+			return v, false, nil
+		}
+		resolved, err := secrets.Resolve(v)
+		return resolved, err == nil, err
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		changed := false
+		for i, elem := range v {
+			resolved, c, err := resolveSecretValue(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			out[i], changed = resolved, changed || c
+		}
+		return out, changed, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		changed := false
+		for k, elem := range v {
+			resolved, c, err := resolveSecretValue(elem)
+			if err != nil {
+				return nil, false, err
+			}
+			out[k], changed = resolved, changed || c
+		}
+		return out, changed, nil
+	}
+	return v, false, nil
+}
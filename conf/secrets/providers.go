@@ -0,0 +1,164 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// envProvider ${env:NAME}
+type envProvider struct{}
+
+func (envProvider) Secret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// fileProvider ${file:/path}
+type fileProvider struct{}
+
+func (fileProvider) Secret(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// splitField splits "name#field"; field is empty without '#'
+func splitField(ref string) (name, field string) {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// jsonField one string field of a JSON object
+func jsonField(data []byte, field string) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// vaultProvider ${vault:path#field}: reads path with the Vault HTTP API, so a
+// KV v2 secret is addressed as mount/data/name (secret/data/metafs#rpc_pass).
+// VAULT_ADDR, VAULT_TOKEN and optionally VAULT_NAMESPACE configure it.
+type vaultProvider struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+func newVaultProvider() (Provider, error) {
+	p := &vaultProvider{
+		addr:      strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		client:    http.DefaultClient,
+	}
+	if p.addr == "" || p.token == "" {
+		return nil, errors.New("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	return p, nil
+}
+
+func (p *vaultProvider) Secret(ctx context.Context, ref string) (string, error) {
+	path, field := splitField(ref)
+	if field == "" {
+		return "", errors.New("vault reference needs a field: path#field")
+	}
+	u, err := url.Parse(p.addr + "/v1/" + strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %s for %s", resp.Status, path)
+	}
+
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	// KV v2 nests the fields in data.data, KV v1 and other engines return them in data
+	var kv2 struct {
+		Data     json.RawMessage `json:"data"`
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if json.Unmarshal(secret.Data, &kv2) == nil && kv2.Data != nil && kv2.Metadata != nil {
+		return jsonField(kv2.Data, field)
+	}
+	return jsonField(secret.Data, field)
+}
+
+// awsProvider ${aws-sm:secret-id} or ${aws-sm:secret-id#field} for a field of
+// a JSON secret; credentials and region come from the default AWS chain
+// (AWS_REGION, AWS_ACCESS_KEY_ID ..., shared config, instance role)
+type awsProvider struct {
+	client *secretsmanager.Client
+}
+
+func newAWSProvider() (Provider, error) {
+	cfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &awsProvider{client: secretsmanager.NewFromConfig(cfg)}, nil
+}
+
+func (p *awsProvider) Secret(ctx context.Context, ref string) (string, error) {
+	id, field := splitField(ref)
+	out, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", err
+	}
+	value := aws.ToString(out.SecretString)
+	if out.SecretString == nil {
+		value = string(out.SecretBinary)
+	}
+	if field == "" {
+		return value, nil
+	}
+	return jsonField([]byte(value), field)
+}
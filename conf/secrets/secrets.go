@@ -0,0 +1,168 @@
+// Package secrets resolves secret references in configuration values, so
+// credentials (RPC passwords, storage keys, DSNs ...) need not be written into
+// the config file. A reference ${scheme:ref} may make up a whole value or be
+// embedded in one, e.g. "user:${env:DB_PASS}@tcp(127.0.0.1:3306)/metafs":
+//
+//	${env:NAME}                   environment variable NAME
+//	${file:/run/secrets/rpc}      content of a file (Docker / Kubernetes secrets), trailing newline removed
+//	${vault:secret/data/app#key}  field of a HashiCorp Vault KV secret (VAULT_ADDR, VAULT_TOKEN)
+//	${aws-sm:secret-id#key}       AWS Secrets Manager secret, or a field of a JSON secret (default AWS credentials)
+//
+// A provider is only set up when a value first references it. Resolved values
+// are remembered so Redact can mask them in log output.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider looks up secrets of one scheme
+type Provider interface {
+	// Secret returns the secret named by ref (the part after "scheme:")
+	Secret(ctx context.Context, ref string) (string, error)
+}
+
+// Factory creates a provider on the first reference to its scheme
+type Factory func() (Provider, error)
+
+// lookupTimeout bounds the lookup of one reference
+const lookupTimeout = 30 * time.Second
+
+var (
+	refPattern = regexp.MustCompile(`\$\{([a-z][a-z0-9-]*):([^}]+)\}`)
+
+	mu        sync.Mutex
+	factories = map[string]Factory{
+		"env":    func() (Provider, error) { return envProvider{}, nil },
+		"file":   func() (Provider, error) { return fileProvider{}, nil },
+		"vault":  newVaultProvider,
+		"aws-sm": newAWSProvider,
+	}
+	providers = make(map[string]Provider)
+	cache     = make(map[string]string) // "scheme:ref" -> value
+	resolved  []string                  // Values to redact, longest first
+)
+
+// Register adds or replaces the provider of a scheme
+func Register(scheme string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[scheme] = factory
+	delete(providers, scheme)
+}
+
+// HasReference reports whether s contains a secret reference
+func HasReference(s string) bool {
+	return refPattern.MatchString(s)
+}
+
+// Resolve replaces every secret reference in s with the secret's value. A
+// reference that cannot be resolved is an error; an empty secret is one too,
+// so a missing environment variable is not taken for an empty password.
+func Resolve(s string) (string, error) {
+	var firstErr error
+	out := refPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if firstErr != nil {
+			return match
+		}
+		m := refPattern.FindStringSubmatch(match)
+		value, err := lookup(m[1], m[2])
+		if err != nil {
+			firstErr = fmt.Errorf("secret %s: %w", match, err)
+			return match
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
+
+func lookup(scheme, ref string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := scheme + ":" + ref
+	if value, ok := cache[key]; ok {
+		return value, nil
+	}
+	provider, ok := providers[scheme]
+	if !ok {
+		factory, known := factories[scheme]
+		if !known {
+			return "", fmt.Errorf("unknown secret provider %q", scheme)
+		}
+		var err error
+		if provider, err = factory(); err != nil {
+			return "", fmt.Errorf("%s provider: %w", scheme, err)
+		}
+		providers[scheme] = provider
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	value, err := provider.Secret(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", fmt.Errorf("secret is empty")
+	}
+	cache[key] = value
+	remember(value)
+	return value, nil
+}
+
+// remember adds a resolved value to the redaction list; callers hold mu
+func remember(value string) {
+	// Very short values would mask unrelated text
+	if len(value) < 4 {
+		return
+	}
+	for _, v := range resolved {
+		if v == value {
+			return
+		}
+	}
+	resolved = append(resolved, value)
+	sort.Slice(resolved, func(i, j int) bool { return len(resolved[i]) > len(resolved[j]) })
+}
+
+// redacted replaces a secret value in redacted text
+const redacted = "[REDACTED]"
+
+// Redact masks every resolved secret value in s
+func Redact(s string) string {
+	mu.Lock()
+	values := resolved
+	mu.Unlock()
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, redacted)
+	}
+	return s
+}
+
+// RedactingWriter wraps w so resolved secret values never reach it, for the
+// standard logger: log.SetOutput(secrets.RedactingWriter(log.Writer()))
+func RedactingWriter(w io.Writer) io.Writer {
+	return redactingWriter{w: w}
+}
+
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
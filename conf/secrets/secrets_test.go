@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	t.Setenv("METAFS_TEST_DB_PASS", "s3cret-pass")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "rpc")
+	if err := os.WriteFile(file, []byte("rpc-password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"plain":                            "plain",
+		"${env:METAFS_TEST_DB_PASS}":       "s3cret-pass",
+		"${file:" + file + "}":             "rpc-password",
+		"u:${env:METAFS_TEST_DB_PASS}@tcp": "u:s3cret-pass@tcp",
+		"$HOME and ${not a ref}":           "$HOME and ${not a ref}",
+	}
+	for in, want := range tests {
+		got, err := Resolve(in)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+
+	t.Setenv("METAFS_TEST_EMPTY", "")
+	for _, in := range []string{"${env:METAFS_TEST_UNSET}", "${env:METAFS_TEST_EMPTY}", "${nope:x}", "${file:" + filepath.Join(dir, "missing") + "}"} {
+		if got, err := Resolve(in); err == nil {
+			t.Errorf("Resolve(%q) = %q, want an error", in, got)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	t.Setenv("METAFS_TEST_TOKEN", "token-to-hide")
+	if _, err := Resolve("${env:METAFS_TEST_TOKEN}"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := RedactingWriter(&buf)
+	msg := "connecting with token-to-hide\n"
+	if n, err := w.Write([]byte(msg)); err != nil || n != len(msg) {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if got := buf.String(); strings.Contains(got, "token-to-hide") || !strings.Contains(got, redacted) {
+		t.Errorf("logged %q", got)
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/metafs": // KV v2
+			w.Write([]byte(`{"data":{"data":{"rpc_pass":"from-kv2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/metafs": // KV v1
+			w.Write([]byte(`{"data":{"rpc_pass":"from-kv1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p := &vaultProvider{addr: srv.URL, token: "root", client: srv.Client()}
+	for ref, want := range map[string]string{"secret/data/metafs#rpc_pass": "from-kv2", "kv/metafs#rpc_pass": "from-kv1"} {
+		got, err := p.Secret(context.Background(), ref)
+		if err != nil || got != want {
+			t.Errorf("Secret(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	for _, ref := range []string{"secret/data/metafs#other", "secret/data/missing#rpc_pass", "secret/data/metafs"} {
+		if _, err := p.Secret(context.Background(), ref); err == nil {
+			t.Errorf("Secret(%q) succeeded", ref)
+		}
+	}
+}
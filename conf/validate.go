@@ -0,0 +1,433 @@
+package conf
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Services a configuration is validated for (Config.Validate)
+const (
+	ServiceIndexer  = "indexer"
+	ServiceUploader = "uploader"
+)
+
+// Storage backends of storage.type and storage.migration.target
+var storageTypes = []string{"local", "oss", "s3", "minio", "gateway"}
+
+// ValidationError every problem Config.Validate found, one line per setting
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d problem(s) in %s:\n  - %s", len(e.Problems), GetYaml(), strings.Join(e.Problems, "\n  - "))
+}
+
+// validator collects the problems of one validation run
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(key, format string, args ...interface{}) {
+	v.problems = append(v.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+// require reports key unless value is set; when is the feature needing it
+func (v *validator) require(key, value, when string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf(key, "is required %s", when)
+	}
+}
+
+// oneOf reports value unless it is one of allowed
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf(key, "unknown value %q, use one of %s", value, strings.Join(allowed, ", "))
+}
+
+// url reports value unless it is an absolute URL with one of schemes
+func (v *validator) url(key, value string, schemes ...string) {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || u.Host == "" {
+		v.addf(key, "%q is not a URL, expected e.g. %s://host:port", value, schemes[0])
+		return
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return
+		}
+	}
+	v.addf(key, "%q has scheme %q, use %s", value, u.Scheme, strings.Join(schemes, " or "))
+}
+
+// port reports value unless it is a TCP port number
+func (v *validator) port(key, value string) {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+		v.addf(key, "%q is not a port number (1-65535)", value)
+	}
+}
+
+// listeners reports the listeners of a service under key: addresses, route
+// sets, unix socket modes and TLS files
+func (v *validator) listeners(key string, listeners []ListenerConfig) {
+	seen := make(map[string]bool, len(listeners))
+	for i, l := range listeners {
+		lkey := fmt.Sprintf("%s[%d]", key, i)
+		if IsUnixListener(l.Address) {
+			if strings.TrimSpace(strings.TrimPrefix(l.Address, "unix:")) == "" {
+				v.addf(lkey+".address", "%q has no socket path, expected e.g. unix:/run/metafs/api.sock", l.Address)
+			}
+			if mode, err := strconv.ParseUint(l.SocketMode, 8, 32); err != nil || mode > 0o777 {
+				v.addf(lkey+".socket_mode", "%q is not an octal permission mode such as 0660", l.SocketMode)
+			}
+		} else if l.Address == "" {
+			v.require(lkey+".address", "", "for every listener")
+		} else if _, port, err := net.SplitHostPort(l.Address); err != nil {
+			v.addf(lkey+".address", "%q is not host:port (IPv6 hosts in brackets, e.g. [::1]:7281) or unix:/path", l.Address)
+		} else {
+			v.port(lkey+".address", port)
+			v.oneOf(lkey+".network", l.Network, "tcp", "tcp4", "tcp6")
+		}
+		if seen[l.Address] {
+			v.addf(lkey+".address", "%q is configured more than once", l.Address)
+		}
+		seen[l.Address] = true
+		v.oneOf(lkey+".routes", l.Routes, ListenerRoutesAll, ListenerRoutesPublic, ListenerRoutesAdmin)
+
+		tls := l.TLS
+		if (tls.CertFile == "") != (tls.KeyFile == "") {
+			v.addf(lkey+".tls", "cert_file and key_file must be set together")
+		}
+		if tls.ClientCAFile != "" && tls.CertFile == "" {
+			v.require(lkey+".tls.cert_file", "", "when tls.client_ca_file is set")
+		}
+		if tls.CertFile != "" {
+			v.oneOf(lkey+".tls.min_version", tls.MinVersion, "1.2", "1.3")
+		}
+	}
+}
+
+// Validate checks the settings service (ServiceIndexer or ServiceUploader)
+// uses after InitConfig filled in the defaults: required fields of every
+// enabled feature, URLs and ports, and options that exclude each other. All
+// problems are returned together as a *ValidationError.
+func (c *Config) Validate(service string) error {
+	v := &validator{}
+	switch service {
+	case ServiceIndexer:
+		v.port("indexer.port", c.IndexerPort)
+		v.listeners("indexer.listeners", c.Indexer.Listeners)
+		c.validateIndexerDatabase(v)
+		c.validateStorage(v)
+		c.validateIndexer(v)
+	case ServiceUploader:
+		v.port("uploader.port", c.UploaderPort)
+		v.listeners("uploader.listeners", c.Uploader.Listeners)
+		c.validateUploaderDatabase(v)
+		c.validateStorage(v)
+		if c.Storage.Type == "gateway" {
+			v.addf("storage.type", "gateway is watch-only (indexer); the uploader needs local, oss, s3 or minio")
+		}
+		c.validateUploader(v)
+	default:
+		return fmt.Errorf("unknown service %q", service)
+	}
+	c.validateShared(v)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (c *Config) validateIndexerDatabase(v *validator) {
+	switch c.Database.IndexerType {
+	case "", "mysql":
+		v.require("database.dsn", c.Database.Dsn, "for database.indexer_type mysql")
+	case "pebble":
+		v.require("database.data_dir", c.Database.DataDir, "for database.indexer_type pebble")
+	case "sqlite":
+		v.require("database.sqlite_path", c.Database.SqlitePath, "for database.indexer_type sqlite")
+	default:
+		v.oneOf("database.indexer_type", c.Database.IndexerType, "mysql", "pebble", "sqlite")
+	}
+}
+
+func (c *Config) validateUploaderDatabase(v *validator) {
+	switch c.Database.UploaderType {
+	case "", "mysql":
+		v.require("database.dsn", c.Database.Dsn, "for database.uploader_type mysql")
+	case "sqlite":
+		v.require("database.sqlite_path", c.Database.SqlitePath, "for database.uploader_type sqlite")
+	default:
+		v.oneOf("database.uploader_type", c.Database.UploaderType, "mysql", "sqlite")
+	}
+}
+
+func (c *Config) validateStorage(v *validator) {
+	v.oneOf("storage.type", c.Storage.Type, storageTypes...)
+	c.validateStorageBackend(v, c.Storage.Type, "storage.type")
+
+	migration := c.Storage.Migration
+	if !migration.Enabled {
+		return
+	}
+	switch {
+	case migration.Target == "":
+		v.require("storage.migration.target", migration.Target, "when storage.migration.enabled is set")
+	case migration.Target == c.Storage.Type:
+		v.addf("storage.migration.target", "is the same backend as storage.type (%s); nothing to migrate", migration.Target)
+	default:
+		v.oneOf("storage.migration.target", migration.Target, "local", "oss", "s3", "minio")
+		if migration.Target != "gateway" {
+			c.validateStorageBackend(v, migration.Target, "storage.migration.target")
+		}
+	}
+}
+
+// validateStorageBackend checks the section of storageType, selected by key
+func (c *Config) validateStorageBackend(v *validator, storageType, key string) {
+	when := "for " + key + " " + storageType
+	switch storageType {
+	case "local":
+		v.require("storage.local.base_path", c.Storage.Local.BasePath, when)
+	case "oss":
+		v.require("storage.oss.endpoint", c.Storage.OSS.Endpoint, when)
+		v.require("storage.oss.access_key", c.Storage.OSS.AccessKey, when)
+		v.require("storage.oss.secret_key", c.Storage.OSS.SecretKey, when)
+		v.require("storage.oss.bucket", c.Storage.OSS.Bucket, when)
+	case "s3":
+		v.require("storage.s3.region", c.Storage.S3.Region, when)
+		v.require("storage.s3.access_key", c.Storage.S3.AccessKey, when)
+		v.require("storage.s3.secret_key", c.Storage.S3.SecretKey, when)
+		v.require("storage.s3.bucket", c.Storage.S3.Bucket, when)
+		if c.Storage.S3.Endpoint != "" {
+			v.url("storage.s3.endpoint", c.Storage.S3.Endpoint, "https", "http")
+		}
+	case "minio":
+		v.require("storage.minio.endpoint", c.Storage.MinIO.Endpoint, when)
+		v.require("storage.minio.access_key", c.Storage.MinIO.AccessKey, when)
+		v.require("storage.minio.secret_key", c.Storage.MinIO.SecretKey, when)
+		v.require("storage.minio.bucket", c.Storage.MinIO.Bucket, when)
+	case "gateway":
+		if c.Storage.Gateway.Url == "" {
+			v.require("storage.gateway.url", "", when)
+		} else {
+			v.url("storage.gateway.url", c.Storage.Gateway.Url, "https", "http")
+		}
+	}
+}
+
+func (c *Config) validateIndexer(v *validator) {
+	idx := c.Indexer
+	if idx.Mirror.Enabled {
+		// A mirror scans nothing, so chain settings would be silently ignored
+		if idx.Mirror.Upstream == "" {
+			v.require("indexer.mirror.upstream", "", "when indexer.mirror.enabled is set")
+		} else {
+			v.url("indexer.mirror.upstream", idx.Mirror.Upstream, "https", "http")
+		}
+		if len(idx.Chains) > 0 {
+			v.addf("indexer.mirror.enabled", "a mirror does not scan chains; remove indexer.chains or disable the mirror")
+		}
+	} else if len(idx.Chains) > 0 {
+		seen := make(map[string]bool, len(idx.Chains))
+		for i, chain := range idx.Chains {
+			key := fmt.Sprintf("indexer.chains[%d]", i)
+			v.oneOf(key+".name", chain.Name, "btc", "mvc", "doge")
+			if seen[chain.Name] {
+				v.addf(key+".name", "chain %q is configured more than once", chain.Name)
+			}
+			seen[chain.Name] = true
+			c.validateChainRpc(v, key, chain.RpcUrl, chain.ZmqEnabled, chain.ZmqAddress, chain.EsploraUrl)
+			if chain.ParserMode != "" {
+				v.oneOf(key+".parser_mode", chain.ParserMode, "strict", "lenient")
+			}
+			if chain.MinConfirmations < 0 {
+				v.addf(key+".min_confirmations", "%d is negative, use 0 to inherit indexer.confirmations.min", chain.MinConfirmations)
+			}
+		}
+	} else {
+		// Single-chain mode scans chain.rpc_url
+		c.validateChainRpc(v, "chain", c.Chain.RpcUrl, idx.ZmqEnabled, idx.ZmqAddress, c.Chain.EsploraUrl)
+	}
+
+	if idx.Confirmations.Min < 0 {
+		v.addf("indexer.confirmations.min", "%d is negative", idx.Confirmations.Min)
+	}
+	for i, peer := range idx.Peers.Urls {
+		v.url(fmt.Sprintf("indexer.peers.urls[%d]", i), peer, "https", "http")
+	}
+
+	if acme := idx.Domains.Acme; acme.Enabled {
+		v.port("indexer.domains.acme.https_port", acme.HttpsPort)
+		v.port("indexer.domains.acme.http_port", acme.HttpPort)
+		if acme.HttpsPort == c.IndexerPort || acme.HttpPort == c.IndexerPort {
+			v.addf("indexer.domains.acme", "https_port and http_port must differ from indexer.port (%s)", c.IndexerPort)
+		}
+		for i, l := range idx.Listeners {
+			if IsUnixListener(l.Address) {
+				continue
+			}
+			if _, port, err := net.SplitHostPort(l.Address); err == nil && port != c.IndexerPort && (port == acme.HttpsPort || port == acme.HttpPort) {
+				v.addf(fmt.Sprintf("indexer.listeners[%d].address", i), "port %s is taken by indexer.domains.acme", port)
+			}
+		}
+	}
+
+	if stream := idx.EventStream; stream.Enabled {
+		v.oneOf("indexer.event_stream.broker", strings.ToLower(stream.Broker), "nats", "kafka")
+		v.oneOf("indexer.event_stream.format", stream.Format, "json", "protobuf")
+		if len(stream.URLs) == 0 {
+			v.require("indexer.event_stream.urls", "", "when indexer.event_stream.enabled is set")
+		}
+		for i, u := range stream.URLs {
+			key := fmt.Sprintf("indexer.event_stream.urls[%d]", i)
+			if strings.ToLower(stream.Broker) == "kafka" {
+				v.url(key, u, "http", "https")
+			} else {
+				v.url(key, u, "nats", "tls")
+			}
+		}
+	}
+
+	if idx.RescanSchedule.Enabled {
+		for i, job := range idx.RescanSchedule.Jobs {
+			key := fmt.Sprintf("indexer.rescan_schedule.jobs[%d]", i)
+			v.require(key+".cron", job.Cron, "for a rescan job")
+			v.oneOf(key+".chain", strings.ToLower(job.Chain), "btc", "mvc")
+		}
+	}
+}
+
+// validateChainRpc checks the node settings of one scanned chain under key
+func (c *Config) validateChainRpc(v *validator, key, rpcUrl string, zmqEnabled bool, zmqAddress, esploraUrl string) {
+	if rpcUrl == "" {
+		v.require(key+".rpc_url", "", "to scan the chain (or set indexer.mirror.enabled)")
+	} else {
+		v.url(key+".rpc_url", rpcUrl, "http", "https")
+	}
+	if zmqEnabled {
+		zmqKey := key + ".zmq_address"
+		if key == "chain" {
+			zmqKey = "indexer.zmq_address"
+		}
+		if zmqAddress == "" {
+			v.require(zmqKey, "", "when zmq_enabled is set")
+		} else {
+			v.url(zmqKey, zmqAddress, "tcp")
+		}
+	}
+	if esploraUrl != "" {
+		v.url(key+".esplora_url", esploraUrl, "https", "http")
+	}
+}
+
+func (c *Config) validateUploader(v *validator) {
+	up := c.Uploader
+	seen := make(map[string]bool, len(up.Chains))
+	for i, chain := range up.Chains {
+		key := fmt.Sprintf("uploader.chains[%d]", i)
+		v.require(key+".name", chain.Name, "for every uploader chain")
+		if seen[chain.Name] {
+			v.addf(key+".name", "chain %q is configured more than once", chain.Name)
+		}
+		seen[chain.Name] = true
+		if chain.RpcUrl == "" {
+			// Without uploader.chains the chain section's node is used
+			v.require(key+".rpc_url", "", "to broadcast uploads of "+chain.Name+" (or chain.rpc_url without uploader.chains)")
+		} else {
+			v.url(key+".rpc_url", chain.RpcUrl, "http", "https")
+		}
+		if chain.FallbackRpcUrl != "" {
+			v.url(key+".fallback_rpc_url", chain.FallbackRpcUrl, "http", "https")
+		}
+	}
+
+	// The faucet and the delegated hot wallet default to the mvc chain's node
+	if up.Faucet.Enabled && c.Net != "mainnet" {
+		c.validateWalletRpc(v, "uploader.faucet", up.Faucet.RpcUrl, seen["mvc"])
+	}
+	if up.Delegated.Enabled {
+		c.validateWalletRpc(v, "uploader.delegated", up.Delegated.RpcUrl, seen["mvc"])
+		if len(up.Delegated.ApiKeys) == 0 {
+			v.require("uploader.delegated.api_keys", "", "when uploader.delegated.enabled is set (no client could use it)")
+		}
+	}
+	if up.ChunkFunding.Strategy == "hot_wallet" {
+		v.require("uploader.chunk_funding.hot_wallet_key", up.ChunkFunding.HotWalletKey, "for uploader.chunk_funding.strategy hot_wallet")
+	}
+
+	if up.UrlFetch.Enabled {
+		for i, scheme := range up.UrlFetch.AllowedSchemes {
+			v.oneOf(fmt.Sprintf("uploader.url_fetch.allowed_schemes[%d]", i), strings.ToLower(scheme), "https", "http")
+		}
+	}
+	if up.Delta.IndexerUrl != "" {
+		v.url("uploader.delta.indexer_url", up.Delta.IndexerUrl, "https", "http")
+	}
+	if up.Keep.MaxFilesPerOwner < 0 {
+		v.addf("uploader.keep.max_files_per_owner", "%d is negative, use 0 for no limit", up.Keep.MaxFilesPerOwner)
+	}
+	if up.Keep.MaxMBPerOwner < 0 {
+		v.addf("uploader.keep.max_mb_per_owner", "%d is negative, use 0 for no limit", up.Keep.MaxMBPerOwner)
+	}
+}
+
+// validateWalletRpc checks the node of a wallet feature under key: its own
+// rpc_url, or the mvc uploader chain's
+func (c *Config) validateWalletRpc(v *validator, key, rpcUrl string, hasMvc bool) {
+	switch {
+	case rpcUrl != "":
+		v.url(key+".rpc_url", rpcUrl, "http", "https")
+	case !hasMvc:
+		v.require(key+".rpc_url", "", "when no mvc chain is in uploader.chains")
+	}
+}
+
+func (c *Config) validateShared(v *validator) {
+	if c.Redis.Enabled {
+		v.require("redis.host", c.Redis.Host, "when redis.enabled is set")
+		v.port("redis.port", strconv.Itoa(c.Redis.Port))
+	}
+
+	if email := c.Notify.Email; email.Enabled {
+		v.require("notify.email.host", email.Host, "when notify.email.enabled is set")
+		v.require("notify.email.from", email.From, "when notify.email.enabled is set")
+		if len(email.To) == 0 {
+			v.require("notify.email.to", "", "when notify.email.enabled is set")
+		}
+	}
+	if telegram := c.Notify.Telegram; telegram.Enabled {
+		v.require("notify.telegram.bot_token", telegram.BotToken, "when notify.telegram.enabled is set")
+		v.require("notify.telegram.chat_id", telegram.ChatID, "when notify.telegram.enabled is set")
+	}
+	for event, channels := range c.Notify.Routes {
+		for _, channel := range channels {
+			v.oneOf("notify.routes."+event, channel, "email", "telegram")
+		}
+	}
+
+	if rate := c.Http.AccessLog.SampleRate; rate < 0 || rate > 1 {
+		v.addf("http.access_log.sample_rate", "%v is not a share between 0 and 1", rate)
+	}
+	for i, proxy := range c.Http.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				v.addf(fmt.Sprintf("http.trusted_proxies[%d]", i), "%q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+	for i, origin := range c.Http.Cors.AllowOrigins {
+		if origin != "*" {
+			v.url(fmt.Sprintf("http.cors.allow_origins[%d]", i), origin, "https", "http")
+		}
+	}
+}
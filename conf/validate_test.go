@@ -0,0 +1,150 @@
+package conf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validConfig a configuration both services accept
+func validConfig() *Config {
+	return &Config{
+		Net:          "livenet",
+		IndexerPort:  "7281",
+		UploaderPort: "7282",
+		Database:     DatabaseConfig{IndexerType: "pebble", UploaderType: "mysql", Dsn: "user:pass@tcp(localhost:3306)/db", DataDir: "./data/pebble"},
+		Storage:      StorageConfig{Type: "local", Local: LocalStorageConfig{BasePath: "./data/files"}},
+		Indexer: IndexerConfig{
+			Chains: []ChainInstanceConfig{{Name: "mvc", RpcUrl: "http://127.0.0.1:9882", ZmqEnabled: true, ZmqAddress: "tcp://127.0.0.1:28332"}},
+		},
+		Uploader: UploaderConfig{
+			Chains:       []UploaderChainConfig{{Name: "mvc", RpcUrl: "http://127.0.0.1:9882"}},
+			ChunkFunding: UploadChunkFundingConfig{Strategy: "assistent"},
+		},
+	}
+}
+
+// problems the problem lines of err, failing unless it is a *ValidationError
+func problems(t *testing.T, err error) []string {
+	t.Helper()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want a *ValidationError", err)
+	}
+	return verr.Problems
+}
+
+func TestValidate(t *testing.T) {
+	for _, service := range []string{ServiceIndexer, ServiceUploader} {
+		if err := validConfig().Validate(service); err != nil {
+			t.Errorf("valid %s config: %v", service, err)
+		}
+	}
+	if err := validConfig().Validate("replay"); err == nil {
+		t.Error("unknown service accepted")
+	}
+
+	tests := []struct {
+		name    string
+		service string
+		edit    func(c *Config)
+		want    []string // Settings reported, in order
+	}{
+		{"storage type typo", ServiceIndexer, func(c *Config) { c.Storage.Type = "s4" }, []string{"storage.type"}},
+		{"oss without credentials", ServiceIndexer, func(c *Config) {
+			c.Storage.Type = "oss"
+			c.Storage.OSS = OSSStorageConfig{Endpoint: "oss-cn-hangzhou.aliyuncs.com", Bucket: "files"}
+		}, []string{"storage.oss.access_key", "storage.oss.secret_key"}},
+		{"migration to the same backend", ServiceIndexer, func(c *Config) {
+			c.Storage.Migration = StorageMigrationConfig{Enabled: true, Target: "local"}
+		}, []string{"storage.migration.target"}},
+		{"missing chain rpc url", ServiceIndexer, func(c *Config) {
+			c.Indexer.Chains = append(c.Indexer.Chains, ChainInstanceConfig{Name: "btc"})
+		}, []string{"indexer.chains[1].rpc_url"}},
+		{"duplicate chain and bad zmq address", ServiceIndexer, func(c *Config) {
+			c.Indexer.Chains = append(c.Indexer.Chains, ChainInstanceConfig{Name: "mvc", RpcUrl: "127.0.0.1:9882", ZmqEnabled: true, ZmqAddress: "127.0.0.1:28332"})
+		}, []string{"indexer.chains[1].name", "indexer.chains[1].rpc_url", "indexer.chains[1].zmq_address"}},
+		{"single chain mode", ServiceIndexer, func(c *Config) { c.Indexer.Chains = nil }, []string{"chain.rpc_url"}},
+		{"mirror with chains", ServiceIndexer, func(c *Config) {
+			c.Indexer.Mirror = IndexerMirrorConfig{Enabled: true, Upstream: "ftp://indexer.example.com"}
+		}, []string{"indexer.mirror.upstream", "indexer.mirror.enabled"}},
+		{"mirror", ServiceIndexer, func(c *Config) {
+			c.Indexer.Chains = nil
+			c.Indexer.Mirror = IndexerMirrorConfig{Enabled: true, Upstream: "https://indexer.example.com"}
+		}, nil},
+		{"event stream", ServiceIndexer, func(c *Config) {
+			c.Indexer.EventStream = IndexerEventStreamConfig{Enabled: true, Broker: "rabbitmq", Format: "json", URLs: []string{"nats://127.0.0.1:4222"}}
+		}, []string{"indexer.event_stream.broker"}},
+		{"bad port and database type", ServiceIndexer, func(c *Config) {
+			c.IndexerPort = "72810"
+			c.Database.IndexerType = "peble"
+		}, []string{"indexer.port", "database.indexer_type"}},
+		{"dual-stack, tls and admin socket listeners", ServiceIndexer, func(c *Config) {
+			c.Indexer.Listeners = listenerDefaults([]ListenerConfig{
+				{Address: "[::]:7281", Routes: ListenerRoutesPublic},
+				{Address: "127.0.0.1:8443", Network: "tcp4", TLS: ListenerTLSConfig{CertFile: "api.pem", KeyFile: "api.key", ClientCAFile: "ca.pem"}},
+				{Address: "unix:/run/metafs/admin.sock", Routes: ListenerRoutesAdmin},
+			}, c.IndexerPort)
+		}, nil},
+		{"bad listeners", ServiceIndexer, func(c *Config) {
+			c.Indexer.Listeners = listenerDefaults([]ListenerConfig{
+				{Address: "::1:7281"},
+				{Address: "[::1]:7282", Routes: "internal", Network: "udp"},
+				{Address: "unix:", SocketMode: "rw", TLS: ListenerTLSConfig{CertFile: "api.pem"}},
+				{Address: "[::1]:7282"},
+			}, c.IndexerPort)
+		}, []string{"indexer.listeners[0].address", "indexer.listeners[1].network", "indexer.listeners[1].routes",
+			"indexer.listeners[2].address", "indexer.listeners[2].socket_mode", "indexer.listeners[2].tls", "indexer.listeners[3].address"}},
+		{"gateway storage on the uploader", ServiceUploader, func(c *Config) {
+			c.Storage = StorageConfig{Type: "gateway", Gateway: GatewayStorageConfig{Url: "https://gateway.example.com"}}
+		}, []string{"storage.type"}},
+		{"uploader without dsn", ServiceUploader, func(c *Config) { c.Database.Dsn = "" }, []string{"database.dsn"}},
+		{"hot wallet without key", ServiceUploader, func(c *Config) { c.Uploader.ChunkFunding.Strategy = "hot_wallet" }, []string{"uploader.chunk_funding.hot_wallet_key"}},
+		{"negative keep quota", ServiceUploader, func(c *Config) { c.Uploader.Keep.MaxMBPerOwner = -1 }, []string{"uploader.keep.max_mb_per_owner"}},
+		{"delegated uploads without mvc node", ServiceUploader, func(c *Config) {
+			c.Uploader.Chains[0].Name = "doge"
+			c.Uploader.Delegated = UploadDelegatedConfig{Enabled: true, ApiKeys: map[string]string{"app": "key"}}
+		}, []string{"uploader.delegated.rpc_url"}},
+		{"notify channels", ServiceUploader, func(c *Config) {
+			c.Notify.Telegram = NotifyTelegramConfig{Enabled: true, BotToken: "token"}
+			c.Notify.Routes = map[string][]string{"storage_write_failed": {"sms"}}
+		}, []string{"notify.telegram.chat_id", "notify.routes.storage_write_failed"}},
+		{"trusted proxies", ServiceUploader, func(c *Config) {
+			c.Http.TrustedProxies = []string{"10.0.0.1", "172.16.0.0/12", "::1", "proxy.local"}
+		}, []string{"http.trusted_proxies[3]"}},
+	}
+	for _, tt := range tests {
+		c := validConfig()
+		tt.edit(c)
+		err := c.Validate(tt.service)
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		got := problems(t, err)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: problems %q, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i, key := range tt.want {
+			if !strings.HasPrefix(got[i], key+": ") {
+				t.Errorf("%s: problem %d = %q, want %s", tt.name, i, got[i], key)
+			}
+		}
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	c := validConfig()
+	c.Storage.Type = "s4"
+	c.Indexer.Chains[0].RpcUrl = ""
+	err := c.Validate(ServiceIndexer)
+	msg := err.Error()
+	if !strings.HasPrefix(msg, "2 problem(s) in ") ||
+		!strings.Contains(msg, "\n  - storage.type: unknown value \"s4\", use one of local, oss, s3, minio, gateway") ||
+		!strings.Contains(msg, "\n  - indexer.chains[0].rpc_url: is required to scan the chain") {
+		t.Errorf("message:\n%s", msg)
+	}
+}
@@ -0,0 +1,371 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"math/rand"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/controller/respond"
+	"meta-file-system/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults of conf.HttpAccessLogConfig
+const (
+	defaultAccessLogSlowMs       = 1000
+	defaultAccessLogMaxBodyBytes = 4096
+)
+
+// defaultRedactedFields body and query fields never logged: keys, signed
+// transactions and file content. Names match case-insensitively and without
+// '_' and '-'; every field ending in "hex" (preTxHex, mergeTxHex ...) is
+// redacted as well.
+var defaultRedactedFields = []string{
+	"priHex", "privateKey", "wif", "mnemonic", "seed", "password", "secret", "token", "apiKey",
+	"preTxRaw", "rawTx", "signedRawTx", "signedTx",
+	"content", "fileContent", "contentBase64",
+}
+
+// redactedValue replaces a redacted field value in the log
+const redactedValue = "[REDACTED]"
+
+// unmatchedRoute route label of requests no route matched
+const unmatchedRoute = "unmatched"
+
+// newEngine creates a gin engine with panic recovery and, unless the access
+// log replaces it, gin's request log. Forwarding headers are only honoured
+// from http.trusted_proxies, so c.ClientIP() cannot be set by the client.
+func newEngine() *gin.Engine {
+	var r *gin.Engine
+	if httpConfig().AccessLog.Enabled {
+		r = gin.New()
+		r.Use(gin.Recovery())
+	} else {
+		r = gin.Default()
+	}
+	if err := r.SetTrustedProxies(httpConfig().TrustedProxies); err != nil {
+		// Refused by Validate at startup
+		log.Printf("⚠️  Invalid http.trusted_proxies, trusting no proxy: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+	return r
+}
+
+// accessLogRoute settings of one route (conf.HttpAccessLogRouteConfig)
+type accessLogRoute struct {
+	sampleRate float64
+	logBodies  bool
+}
+
+// accessLogger logs requests to the structured logger and feeds the request
+// metrics
+type accessLogger struct {
+	enabled  bool
+	defaults accessLogRoute
+	routes   map[string]accessLogRoute // "METHOD route" or "route"
+	slow     time.Duration
+	maxBody  int
+	redact   map[string]bool // Normalized field names
+	logger   *slog.Logger
+	random   func() float64
+}
+
+func newAccessLogger(cfg conf.HttpAccessLogConfig, logger *slog.Logger) *accessLogger {
+	l := &accessLogger{
+		enabled:  cfg.Enabled,
+		defaults: accessLogRoute{sampleRate: cfg.SampleRate, logBodies: cfg.LogBodies},
+		routes:   make(map[string]accessLogRoute),
+		slow:     time.Duration(cfg.SlowMs) * time.Millisecond,
+		maxBody:  cfg.MaxBodyBytes,
+		redact:   make(map[string]bool),
+		logger:   logger,
+		random:   rand.Float64,
+	}
+	if l.defaults.sampleRate <= 0 {
+		l.defaults.sampleRate = 1
+	}
+	if l.slow <= 0 {
+		l.slow = defaultAccessLogSlowMs * time.Millisecond
+	}
+	if l.maxBody <= 0 {
+		l.maxBody = defaultAccessLogMaxBodyBytes
+	}
+	for _, field := range append(append([]string{}, defaultRedactedFields...), cfg.RedactFields...) {
+		l.redact[normalizeFieldName(field)] = true
+	}
+	for _, route := range cfg.Routes {
+		settings := l.defaults
+		if route.SampleRate != nil {
+			settings.sampleRate = *route.SampleRate
+		}
+		if route.LogBodies != nil {
+			settings.logBodies = *route.LogBodies
+		}
+		l.routes[strings.Join(strings.Fields(route.Route), " ")] = settings
+	}
+	return l
+}
+
+// accessLogMiddleware records every request in the HTTP metrics and, with
+// http.access_log.enabled, logs a sample of them: method, route, path,
+// status, response code, latency and sizes, plus the redacted bodies with
+// log_bodies. Server errors (HTTP 5xx or code 5xxxx) and slow requests are
+// logged whatever the sample rate.
+func accessLogMiddleware(cfg conf.HttpAccessLogConfig) gin.HandlerFunc {
+	return newAccessLogger(cfg, slog.Default()).handle
+}
+
+func (l *accessLogger) route(method, route string) accessLogRoute {
+	if settings, ok := l.routes[method+" "+route]; ok {
+		return settings
+	}
+	if settings, ok := l.routes[route]; ok {
+		return settings
+	}
+	return l.defaults
+}
+
+func (l *accessLogger) handle(c *gin.Context) {
+	start := time.Now()
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	settings := l.route(c.Request.Method, route)
+
+	var reqBody *cappedBuffer
+	var w *accessLogWriter
+	if l.enabled {
+		if settings.logBodies && c.Request.Body != nil && loggableContentType(c.GetHeader("Content-Type")) {
+			reqBody = &cappedBuffer{max: l.maxBody}
+			c.Request.Body = &teeReadCloser{ReadCloser: c.Request.Body, buf: reqBody}
+		}
+		// The response is kept for its code even when bodies are not logged
+		w = &accessLogWriter{ResponseWriter: c.Writer, body: cappedBuffer{max: l.maxBody}}
+		c.Writer = w
+	}
+
+	c.Next()
+
+	latency := time.Since(start)
+	status := c.Writer.Status()
+	metrics.ObserveHTTPRequest(c.Request.Method, route, status, latency)
+	if !l.enabled {
+		return
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	code, hasCode := responseCode(&w.body, contentType)
+	failed := status >= 500 || (hasCode && code >= 50000)
+	slow := latency >= l.slow
+	if !failed && !slow && !l.sampled(settings.sampleRate) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", c.Request.Method),
+		slog.String("route", route),
+		slog.String("path", l.redactedPath(c.Request.URL)),
+		slog.Int("status", status),
+	}
+	if hasCode {
+		attrs = append(attrs, slog.Int("code", code))
+	}
+	attrs = append(attrs,
+		slog.Float64("latency_ms", float64(latency.Microseconds())/1000),
+		slog.Int64("bytes_in", c.Request.ContentLength),
+		slog.Int("bytes_out", c.Writer.Size()),
+		slog.String("client_ip", c.ClientIP()),
+	)
+	if id := w.Header().Get(respond.HeaderNameRequestID); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if settings.logBodies {
+		if reqBody != nil {
+			attrs = append(attrs, slog.String("request_body", l.redactedBody(reqBody, c.GetHeader("Content-Type"))))
+		}
+		if w.body.total > 0 {
+			attrs = append(attrs, slog.String("response_body", l.redactedBody(&w.body, contentType)))
+		}
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case failed:
+		level = slog.LevelError
+	case slow:
+		level = slog.LevelWarn
+	}
+	l.logger.LogAttrs(c.Request.Context(), level, "http request", attrs...)
+}
+
+func (l *accessLogger) sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return rate > 0 && l.random() < rate
+}
+
+// sensitive reports whether a field's value must not be logged
+func (l *accessLogger) sensitive(field string) bool {
+	name := normalizeFieldName(field)
+	return l.redact[name] || strings.HasSuffix(name, "hex")
+}
+
+func normalizeFieldName(field string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(field))
+}
+
+// redactedPath the request path with sensitive query values redacted
+func (l *accessLogger) redactedPath(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return u.Path + "?[unparsable query]"
+	}
+	l.redactForm(query)
+	return u.Path + "?" + query.Encode()
+}
+
+// redactedBody a JSON or form body with sensitive fields redacted. Bodies
+// that are cut off or cannot be parsed are logged by size only, since their
+// sensitive fields cannot be found reliably.
+func (l *accessLogger) redactedBody(buf *cappedBuffer, contentType string) string {
+	if buf.total == 0 {
+		return ""
+	}
+	omitted := fmt.Sprintf("[%d bytes not logged]", buf.total)
+	if buf.truncated() {
+		return omitted
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.Contains(mediaType, "json"):
+		decoder := json.NewDecoder(bytes.NewReader(buf.data))
+		decoder.UseNumber()
+		var body interface{}
+		if err := decoder.Decode(&body); err != nil {
+			return omitted
+		}
+		redacted, err := json.Marshal(l.redactJSON(body))
+		if err != nil {
+			return omitted
+		}
+		return string(redacted)
+	case mediaType == "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(buf.data))
+		if err != nil {
+			return omitted
+		}
+		l.redactForm(form)
+		return form.Encode()
+	}
+	return omitted
+}
+
+func (l *accessLogger) redactJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if l.sensitive(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = l.redactJSON(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = l.redactJSON(value)
+		}
+	}
+	return v
+}
+
+func (l *accessLogger) redactForm(form url.Values) {
+	for key := range form {
+		if l.sensitive(key) {
+			form[key] = []string{redactedValue}
+		}
+	}
+}
+
+// loggableContentType reports whether bodies of contentType can be redacted
+// and logged
+func loggableContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return strings.Contains(mediaType, "json") || mediaType == "application/x-www-form-urlencoded"
+}
+
+// responseCode the code of a JSON response envelope
+func responseCode(buf *cappedBuffer, contentType string) (int, bool) {
+	if buf.total == 0 || buf.truncated() || !loggableContentType(contentType) {
+		return 0, false
+	}
+	var msg struct {
+		Code *int `json:"code"`
+	}
+	if err := json.Unmarshal(buf.data, &msg); err != nil || msg.Code == nil {
+		return 0, false
+	}
+	return *msg.Code, true
+}
+
+// cappedBuffer keeps the first max bytes written to it and counts the rest
+type cappedBuffer struct {
+	max   int
+	data  []byte
+	total int64
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += int64(len(p))
+	if room := b.max - len(b.data); room > 0 {
+		b.data = append(b.data, p[:min(len(p), room)]...)
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) truncated() bool {
+	return b.total > int64(len(b.data))
+}
+
+// teeReadCloser copies what the handler reads of the request body
+type teeReadCloser struct {
+	io.ReadCloser
+	buf *cappedBuffer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		t.buf.Write(p[:n])
+	}
+	return n, err
+}
+
+// accessLogWriter copies the start of the response body
+type accessLogWriter struct {
+	gin.ResponseWriter
+	body cappedBuffer
+}
+
+func (w *accessLogWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *accessLogWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/controller/respond"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	never := 0.0
+	l := newAccessLogger(conf.HttpAccessLogConfig{
+		Enabled:      true,
+		LogBodies:    true,
+		RedactFields: []string{"metaId"},
+		Routes:       []conf.HttpAccessLogRouteConfig{{Route: "GET /quiet", SampleRate: &never}},
+	}, slog.New(slog.NewJSONHandler(&out, nil)))
+
+	r := gin.New()
+	r.Use(l.handle)
+	r.POST("/upload", func(c *gin.Context) {
+		var req map[string]interface{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.Success(c, gin.H{"txId": "abc", "signedRawTx": "0100beef"})
+	})
+	r.GET("/quiet", func(c *gin.Context) { respond.Success(c, nil) })
+	r.GET("/broken", func(c *gin.Context) { respond.ServerError(c, "boom") })
+
+	body := `{"priHex":"deadbeef","preTxHex":"0a00","content":"aGVsbG8=","metaId":"m1","path":"/file","nested":[{"private_key":"k"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/upload?apiKey=secret-key&chain=mvc", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("log entry %q: %v", out.String(), err)
+	}
+	line := out.String()
+	for _, secret := range []string{"deadbeef", "0a00", "aGVsbG8=", "m1", `\"k\"`, "secret-key", "0100beef"} {
+		if strings.Contains(line, secret) {
+			t.Errorf("log entry contains %s: %s", secret, line)
+		}
+	}
+	if entry["route"] != "/upload" || entry["status"] != float64(200) || entry["code"] != float64(0) ||
+		!strings.Contains(entry["request_body"].(string), `"path":"/file"`) ||
+		!strings.Contains(entry["response_body"].(string), `"txId":"abc"`) ||
+		!strings.Contains(entry["path"].(string), "chain=mvc") {
+		t.Errorf("log entry %s", line)
+	}
+
+	// Sample rate 0 still logs server errors
+	out.Reset()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/quiet", nil))
+	if out.Len() != 0 {
+		t.Errorf("unsampled request logged: %s", out.String())
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/broken", nil))
+	if !strings.Contains(out.String(), `"level":"ERROR"`) {
+		t.Errorf("server error logged as %s", out.String())
+	}
+}
+
+func TestAccessLogOmitsTruncatedBodies(t *testing.T) {
+	l := newAccessLogger(conf.HttpAccessLogConfig{MaxBodyBytes: 8}, slog.Default())
+	buf := &cappedBuffer{max: l.maxBody}
+	buf.Write([]byte(`{"priHex":"deadbeef"}`))
+	if got := l.redactedBody(buf, "application/json"); got != "[21 bytes not logged]" {
+		t.Errorf("truncated body logged as %q", got)
+	}
+}
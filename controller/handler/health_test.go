@@ -22,6 +22,8 @@ func (fakeStorage) Save(string, []byte) error
 func (fakeStorage) Get(string) ([]byte, error)                                  { panic("unexpected") }
 func (fakeStorage) Delete(string) error                                         { panic("unexpected") }
 func (f fakeStorage) Exists(string) bool                                        { return f.exists }
+func (fakeStorage) Stat(string) (*storage.ObjectInfo, error)                    { panic("unexpected") }
+func (fakeStorage) List(string, func(storage.ObjectInfo) error) error           { panic("unexpected") }
 func (fakeStorage) InitiateMultipartUpload(string) (string, error)              { panic("unexpected") }
 func (fakeStorage) UploadPart(string, string, int, []byte) (string, error)      { panic("unexpected") }
 func (fakeStorage) CompleteMultipartUpload(string, string, []storage.PartInfo) error {
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/service/indexer_service"
+)
+
+// GetArchive download several files as one zip or tar.gz archive
+// @Summary      Download files as an archive
+// @Description  Streams a zip or tar.gz archive of the files selected by exactly one source: pin_ids (comma-separated or repeated; stored under their file names), manifest (a site manifest PIN; stored under their site paths) or metaid with path (a creator's directory as in the path tree; stored under their paths below it, latest versions only). Archives hold at most 1000 files. Files are read one at a time while the archive is written, so a storage error part way ends the download with a truncated archive
+// @Tags         Indexer File Query
+// @Produce      application/zip,application/gzip
+// @Param        format    query     string  false  "Archive format: zip or tar.gz"  default(zip)
+// @Param        pin_ids   query     string  false  "PIN IDs, comma-separated"
+// @Param        manifest  query     string  false  "Site manifest PIN ID"
+// @Param        metaid    query     string  false  "Creator MetaID or GlobalMetaID (with path)"
+// @Param        path      query     string  false  "Directory of metaid to archive, e.g. /file/photos"  default(/)
+// @Success      200       {file}    binary  "Archive stream"
+// @Failure      400       {object}  respond.Response
+// @Failure      404       {object}  respond.Response  "A file or the manifest is not indexed"
+// @Failure      500       {object}  respond.Response
+// @Router       /v1/files/archive [get]
+func (h *IndexerQueryHandler) GetArchive(c *gin.Context) {
+	format := c.DefaultQuery("format", indexer_service.ArchiveZip)
+	contentType, ok := indexer_service.ArchiveContentType(format)
+	if !ok {
+		respond.InvalidParam(c, "invalid format, must be zip or tar.gz")
+		return
+	}
+
+	var pinIDs []string
+	for _, value := range c.QueryArray("pin_ids") {
+		for _, pinID := range strings.Split(value, ",") {
+			if pinID = strings.TrimSpace(pinID); pinID != "" {
+				pinIDs = append(pinIDs, pinID)
+			}
+		}
+	}
+	manifest := strings.TrimSpace(c.Query("manifest"))
+	metaid := strings.TrimSpace(c.Query("metaid"))
+
+	sources := 0
+	for _, set := range []bool{len(pinIDs) > 0, manifest != "", metaid != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		respond.InvalidParam(c, "exactly one of pin_ids, manifest or metaid is required")
+		return
+	}
+
+	var entries []indexer_service.ArchiveEntry
+	var err error
+	name := "files"
+	switch {
+	case len(pinIDs) > 0:
+		entries, err = h.indexerFileService.ArchiveByPinIDs(pinIDs)
+	case manifest != "":
+		entries, err = h.indexerFileService.ArchiveByManifest(manifest)
+		name = manifest
+	default:
+		dir := c.DefaultQuery("path", "/")
+		entries, err = h.indexerFileService.ArchiveByPath(metaid, dir)
+		name = metaid
+		if dir := strings.Trim(dir, "/ "); dir != "" {
+			name += "-" + strings.ReplaceAll(dir, "/", "-")
+		}
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, indexer_service.ErrProvisionalContent):
+			respond.ContentError(c, err)
+		case errors.Is(err, indexer_service.ErrInvalidArchive), errors.Is(err, metaid_protocols.ErrInvalidSiteManifest):
+			respond.InvalidParam(c, err.Error())
+		case errors.Is(err, indexer_service.ErrArchiveFileNotFound), manifest != "":
+			// A manifest that cannot be loaded is not indexed (as for /site)
+			respond.NotFound(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	c.Status(http.StatusOK)
+	if err := h.indexerFileService.WriteArchive(c.Writer, format, entries); err != nil {
+		// Headers are sent: the client gets a truncated archive
+		log.Printf("Archive %s: %v", c.Request.URL.RawQuery, err)
+	}
+}
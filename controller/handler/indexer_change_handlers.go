@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/indexer_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetChanges list change log events after a sequence number
+// @Summary      Change feed
+// @Description  Ordered log of indexing actions (file_created, file_modified, file_revoked, file_confirmed, chunk_indexed, chunks_merged, user_name_updated, avatar_updated, user_bio_updated, chat_public_key_updated, profile_changed). Each event carries the written record. Poll with since = next_since to receive only new events
+// @Tags         Indexer Changes
+// @Produce      json
+// @Param        since  query     int  false  "Return events with seq greater than since"  default(0)
+// @Param        limit  query     int  false  "Page size (max 1000)"                        default(100)
+// @Success      200    {object}  respond.Response{data=respond.IndexerChangeListResponse}
+// @Failure      400    {object}  respond.Response
+// @Failure      500    {object}  respond.Response
+// @Router       /v1/changes [get]
+func (h *IndexerQueryHandler) GetChanges(c *gin.Context) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil {
+		respond.InvalidParam(c, "since must be a sequence number")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(indexer_service.DefaultChangeEvents)))
+
+	events, nextSince, hasMore, err := h.indexerFileService.ListChanges(since, limit)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidChangeCursor) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if events == nil {
+		events = []*model.IndexerChangeEvent{}
+	}
+	respond.Success(c, respond.IndexerChangeListResponse{Events: events, NextSince: nextSince, HasMore: hasMore})
+}
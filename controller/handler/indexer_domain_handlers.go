@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/service/indexer_service"
+)
+
+// DomainRouting serves requests whose Host header is a mapped custom domain
+// from the mapped site or MetaID, for every path; other hosts fall through to
+// the API routes. Content can change with a new manifest version or file, so
+// responses are revalidated (ETag) instead of cached as immutable.
+func (h *IndexerQueryHandler) DomainRouting() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mapping := indexer_service.LookupDomain(c.Request.Host)
+		if mapping == nil {
+			c.Next()
+			return
+		}
+		defer c.Abort()
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Header("Allow", "GET, HEAD")
+			c.Status(http.StatusMethodNotAllowed)
+			return
+		}
+		reqPath := c.Request.URL.Path
+		site, isDir, err := h.indexerFileService.GetDomainFile(mapping, reqPath)
+		if err != nil {
+			if errors.Is(err, metaid_protocols.ErrInvalidSiteManifest) {
+				c.String(http.StatusBadGateway, err.Error())
+				return
+			}
+			if errors.Is(err, indexer_service.ErrProvisionalContent) {
+				// Served once the file has enough confirmations
+				c.String(http.StatusServiceUnavailable, err.Error())
+				return
+			}
+			c.String(http.StatusNotFound, "404 page not found")
+			return
+		}
+		if isDir {
+			target := reqPath + "/"
+			if c.Request.URL.RawQuery != "" {
+				target += "?" + c.Request.URL.RawQuery
+			}
+			c.Redirect(http.StatusMovedPermanently, target)
+			return
+		}
+		writeSiteContent(c, site, "no-cache")
+	}
+}
+
+// SaveDomain map a custom domain to a site or MetaID
+// @Summary      Map custom domain
+// @Description  Map a domain (admin; requires indexer.admin_enabled) to a site manifest PIN (type=site; its latest version is served) or a MetaID / GlobalMetaID (type=metaid; its /file/* paths are served, /file/index.html at the root). Requests whose Host header is the domain are then served that content; point the domain's DNS at the indexer. An existing mapping of the domain is replaced
+// @Tags         Indexer Domains
+// @Accept       json
+// @Produce      json
+// @Param        request  body      respond.IndexerDomainRequest  true  "Domain mapping"
+// @Success      200      {object}  respond.Response{data=model.IndexerDomain}
+// @Failure      400      {object}  respond.Response
+// @Failure      500      {object}  respond.Response
+// @Router       /v1/admin/domains [post]
+func (h *IndexerQueryHandler) SaveDomain(c *gin.Context) {
+	var req respond.IndexerDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, fmt.Sprintf("invalid request parameters: %v", err))
+		return
+	}
+
+	mapping, err := h.indexerFileService.SaveDomain(req.Domain, req.Type, req.Target)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidDomain) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, mapping)
+}
+
+// ListDomains list custom domain mappings
+// @Summary      List custom domains
+// @Description  List all custom domain mappings ordered by domain (admin; requires indexer.admin_enabled)
+// @Tags         Indexer Domains
+// @Produce      json
+// @Success      200  {object}  respond.Response{data=respond.IndexerDomainListResponse}
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/admin/domains [get]
+func (h *IndexerQueryHandler) ListDomains(c *gin.Context) {
+	domains, err := h.indexerFileService.ListDomains()
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if domains == nil {
+		domains = []*model.IndexerDomain{}
+	}
+	respond.Success(c, respond.IndexerDomainListResponse{Domains: domains})
+}
+
+// DeleteDomain remove a custom domain mapping
+// @Summary      Remove custom domain
+// @Description  Remove the mapping of a domain (admin; requires indexer.admin_enabled); the host then falls through to the API again
+// @Tags         Indexer Domains
+// @Produce      json
+// @Param        domain  path      string  true  "Domain"
+// @Success      200     {object}  respond.Response
+// @Failure      400     {object}  respond.Response
+// @Failure      404     {object}  respond.Response
+// @Router       /v1/admin/domains/{domain} [delete]
+func (h *IndexerQueryHandler) DeleteDomain(c *gin.Context) {
+	if err := h.indexerFileService.DeleteDomain(strings.TrimSpace(c.Param("domain"))); err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidDomain) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		if errors.Is(err, database.ErrNotFound) {
+			respond.NotFound(c, "domain not found")
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, nil)
+}
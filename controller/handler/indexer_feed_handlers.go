@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"meta-file-system/conf"
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/indexer_service"
+)
+
+// sitemapCache holds the last rendered sitemap; it is re-rendered only when the
+// recent-files cache version changes (i.e. a new public file was indexed).
+var sitemapCache struct {
+	mu      sync.Mutex
+	version uint64
+	body    []byte
+}
+
+// feedFileLink returns the public link for a file: the configured explorer
+// template when set, else the indexer content URL.
+func feedFileLink(file *model.IndexerFile) string {
+	if tpl := conf.Cfg.Indexer.FeedLinkTemplate; tpl != "" {
+		return strings.ReplaceAll(tpl, "{pinId}", file.PinID)
+	}
+	return getIndexerBaseUrl() + "/api/v1/files/content/" + file.PinID
+}
+
+// feedMeta builds the channel information for a feed served at path
+func feedMeta(c *gin.Context, path string, filter indexer_service.FeedFilter) respond.FeedMeta {
+	title := "MetaID indexed files"
+	if filter.FileType != "" {
+		title += " - " + filter.FileType
+	}
+	if filter.Creator != "" {
+		title += " by " + filter.Creator
+	}
+	self := getIndexerBaseUrl() + path
+	if c.Request.URL.RawQuery != "" {
+		self += "?" + c.Request.URL.RawQuery
+	}
+	return respond.FeedMeta{
+		Title:       title,
+		Description: "Newly indexed public files",
+		SiteURL:     getIndexerBaseUrl() + "/",
+		SelfURL:     self,
+	}
+}
+
+// feedFiles reads the feed query parameters and loads the matching files
+func (h *IndexerQueryHandler) feedFiles(c *gin.Context) (indexer_service.FeedFilter, []*model.IndexerFile, bool) {
+	filter := indexer_service.FeedFilter{
+		Creator:  strings.TrimSpace(c.Query("creator")),
+		FileType: strings.TrimSpace(c.Query("file_type")),
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(indexer_service.DefaultFeedItems)))
+
+	files, err := h.indexerFileService.GetFeedFiles(filter, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return filter, nil, false
+	}
+	return filter, files, true
+}
+
+// GetRSSFeed get RSS feed of newly indexed public files
+// @Summary      RSS feed of new files
+// @Description  RSS 2.0 feed of the newest indexed public (unencrypted) files, optionally filtered by creator or file type
+// @Tags         Indexer Feed
+// @Produce      xml
+// @Param        creator    query  string  false  "Creator MetaID or GlobalMetaID"
+// @Param        file_type  query  string  false  "File type: image/video/audio/document/other"
+// @Param        size       query  int     false  "Number of items (max 100)"  default(50)
+// @Success      200        {string}  string  "RSS document"
+// @Failure      500        {object}  respond.Response
+// @Router       /v1/feed/rss [get]
+func (h *IndexerQueryHandler) GetRSSFeed(c *gin.Context) {
+	filter, files, ok := h.feedFiles(c)
+	if !ok {
+		return
+	}
+	body, err := respond.RenderRSSFeed(feedMeta(c, "/api/v1/feed/rss", filter), files, feedFileLink)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, respond.ContentTypeRSS, body)
+}
+
+// GetAtomFeed get Atom feed of newly indexed public files
+// @Summary      Atom feed of new files
+// @Description  Atom 1.0 feed of the newest indexed public (unencrypted) files, optionally filtered by creator or file type
+// @Tags         Indexer Feed
+// @Produce      xml
+// @Param        creator    query  string  false  "Creator MetaID or GlobalMetaID"
+// @Param        file_type  query  string  false  "File type: image/video/audio/document/other"
+// @Param        size       query  int     false  "Number of items (max 100)"  default(50)
+// @Success      200        {string}  string  "Atom document"
+// @Failure      500        {object}  respond.Response
+// @Router       /v1/feed/atom [get]
+func (h *IndexerQueryHandler) GetAtomFeed(c *gin.Context) {
+	filter, files, ok := h.feedFiles(c)
+	if !ok {
+		return
+	}
+	body, err := respond.RenderAtomFeed(feedMeta(c, "/api/v1/feed/atom", filter), files, feedFileLink)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	c.Data(http.StatusOK, respond.ContentTypeAtom, body)
+}
+
+// GetSitemap get sitemap of the newest indexed public files
+// @Summary      Sitemap
+// @Description  sitemap.xml listing the newest indexed public files (up to 1000) for explorer frontends; also served at /sitemap.xml
+// @Tags         Indexer Feed
+// @Produce      xml
+// @Success      200  {string}  string  "Sitemap document"
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/sitemap.xml [get]
+func (h *IndexerQueryHandler) GetSitemap(c *gin.Context) {
+	files, version, err := h.indexerFileService.GetSitemapFiles()
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	sitemapCache.mu.Lock()
+	defer sitemapCache.mu.Unlock()
+	if sitemapCache.body == nil || sitemapCache.version != version {
+		body, err := respond.RenderSitemap(files, feedFileLink)
+		if err != nil {
+			respond.ServerError(c, err.Error())
+			return
+		}
+		sitemapCache.body = body
+		sitemapCache.version = version
+	}
+	c.Data(http.StatusOK, respond.ContentTypeSitemap, sitemapCache.body)
+}
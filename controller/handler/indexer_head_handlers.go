@@ -7,6 +7,8 @@ import (
 
 	"meta-file-system/controller/respond"
 	"meta-file-system/model"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/indexer_service"
 )
 
 // HEAD counterparts for the file-content routes.
@@ -19,28 +21,61 @@ import (
 // 404 as "not indexed yet" even though the pin was servable.
 //
 // These handlers run the SAME metadata lookup the GET handlers use (so a
-// not-indexed pin still 404s the same way), then set the headers WITHOUT
+// not-indexed pin still 404s the same way, and a refused provisional file gets
+// the same provisional_content error), then set the headers WITHOUT
 // writing the body and WITHOUT downloading the file bytes from storage. For
 // the "accelerate" (OSS redirect) routes they return 200 with headers instead
 // of the 307 redirect a GET issues, so a HEAD probe answers "is it available?"
 // in a single round trip.
 
 // HeadFileContent is the HEAD counterpart of GetFileContent.
+// @Summary      Probe file content
+// @Description  Headers of GET /files/content/{pinId} without the body and without reading storage; not-indexed PINs get the GET handler's not-found response
+// @Tags         Indexer File Query
+// @Produce      octet-stream
+// @Param        pinId  path      string  true  "PIN ID"
+// @Success      200    {string}  string  "Headers only: Content-Type, Content-Disposition, Content-Length"
+// @Failure      404    {object}  respond.Response
+// @Router       /v1/files/content/{pinId} [head]
 func (h *IndexerQueryHandler) HeadFileContent(c *gin.Context) {
 	headFileContentByPin(c, h.indexerFileService.GetFileByPinID)
 }
 
 // HeadLatestFileContentByFirstPinID is the HEAD counterpart of GetLatestFileContentByFirstPinID.
+// @Summary      Probe latest file content
+// @Description  Headers of GET /files/content/latest/{firstPinId} (latest version) without the body; not-indexed PINs get the GET handler's not-found response
+// @Tags         Indexer File Query
+// @Produce      octet-stream
+// @Param        firstPinId  path      string  true  "First PIN ID"
+// @Success      200         {string}  string  "Headers only: Content-Type, Content-Disposition, Content-Length"
+// @Failure      404         {object}  respond.Response
+// @Router       /v1/files/content/latest/{firstPinId} [head]
 func (h *IndexerQueryHandler) HeadLatestFileContentByFirstPinID(c *gin.Context) {
 	headFileContentByFirstPin(c, "firstPinId", h.indexerFileService.GetLatestFileByFirstPinID)
 }
 
 // HeadFastFileContent is the HEAD counterpart of GetFastFileContent.
+// @Summary      Probe accelerated file content
+// @Description  Availability probe for GET /files/accelerate/content/{pinId}: answers 200 with the content headers instead of the 307 redirect; not-indexed PINs get the GET handler's not-found response
+// @Tags         Indexer File Query
+// @Produce      octet-stream
+// @Param        pinId  path      string  true  "PIN ID"
+// @Success      200    {string}  string  "Headers only: Content-Type, Content-Disposition, Content-Length"
+// @Failure      404    {object}  respond.Response
+// @Router       /v1/files/accelerate/content/{pinId} [head]
 func (h *IndexerQueryHandler) HeadFastFileContent(c *gin.Context) {
 	headFileContentByPin(c, h.indexerFileService.GetFileByPinID)
 }
 
 // HeadLatestFastFileContentByFirstPinID is the HEAD counterpart of GetLatestFastFileContentByFirstPinID.
+// @Summary      Probe latest accelerated file content
+// @Description  Availability probe for GET /files/accelerate/content/latest/{firstPinId}: answers 200 with the content headers of the latest version instead of the 307 redirect
+// @Tags         Indexer File Query
+// @Produce      octet-stream
+// @Param        firstPinId  path      string  true  "First PIN ID"
+// @Success      200         {string}  string  "Headers only: Content-Type, Content-Disposition, Content-Length"
+// @Failure      404         {object}  respond.Response
+// @Router       /v1/files/accelerate/content/latest/{firstPinId} [head]
 func (h *IndexerQueryHandler) HeadLatestFastFileContentByFirstPinID(c *gin.Context) {
 	headFileContentByFirstPin(c, "firstPinId", h.indexerFileService.GetLatestFileByFirstPinID)
 }
@@ -59,6 +94,10 @@ func headFileContentByPin(c *gin.Context, lookup func(string) (*model.IndexerFil
 		respond.NotFound(c, "file not found")
 		return
 	}
+	if err := indexer_service.CheckProvisionalContent(file); err != nil {
+		respond.ContentError(c, err)
+		return
+	}
 	writeHeadHeaders(c, file)
 	c.Status(200)
 }
@@ -76,6 +115,10 @@ func headFileContentByFirstPin(c *gin.Context, param string, lookup func(string)
 		respond.NotFound(c, "file not found")
 		return
 	}
+	if err := indexer_service.CheckProvisionalContent(file); err != nil {
+		respond.ContentError(c, err)
+		return
+	}
 	writeHeadHeaders(c, file)
 	c.Status(200)
 }
@@ -84,7 +127,7 @@ func headFileContentByFirstPin(c *gin.Context, param string, lookup func(string)
 // an accurate Content-Length from the indexed metadata (no body is written).
 func writeHeadHeaders(c *gin.Context, file *model.IndexerFile) {
 	if file.ContentType != "" {
-		c.Header("Content-Type", file.ContentType)
+		c.Header("Content-Type", contenttype.Header(file.ContentType))
 	}
 	if file.FileName != "" {
 		c.Header("Content-Disposition", "inline; filename=\""+file.FileName+"\"")
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strings"
+
+	"meta-file-system/controller/respond"
+	common_service "meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/metaid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeriveMetaID MetaID of an address
+// @Summary      Derive the MetaID of an address
+// @Description  MetaID and GlobalMetaID of an address as this deployment derives them (metaid.derivation): address_sha256 hashes the address, genesis_txid uses the txid of the first PIN indexed for the address. Under genesis_txid an address without indexed PINs has no MetaID yet (404)
+// @Tags         Indexer User Info
+// @Produce      json
+// @Param        address  query     string  true  "Native chain address (ID addresses are rejected)"
+// @Success      200      {object}  respond.Response{data=respond.MetaIDDeriveResponse}
+// @Failure      400      {object}  respond.Response
+// @Failure      404      {object}  respond.Response
+// @Router       /v1/metaid/derive [get]
+func (h *IndexerQueryHandler) DeriveMetaID(c *gin.Context) {
+	address := strings.TrimSpace(c.Query("address"))
+	if address == "" {
+		respond.InvalidParam(c, "address is required")
+		return
+	}
+
+	// MetaIDs are derived from native addresses; an ID address stands for one on each chain
+	if common_service.IsGlobalMetaId(address) {
+		respond.InvalidParam(c, "address must be a native chain address, not an ID address")
+		return
+	}
+
+	metaID := metaid.Derive(address)
+	if metaID == "" {
+		respond.NotFound(c, "no PIN indexed for address yet")
+		return
+	}
+	respond.Success(c, respond.MetaIDDeriveResponse{
+		Address:      address,
+		MetaId:       metaID,
+		GlobalMetaId: common_service.ConvertToGlobalMetaId(address),
+		Derivation:   string(metaid.CurrentDerivation()),
+	})
+}
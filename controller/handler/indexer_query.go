@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
@@ -11,6 +14,8 @@ import (
 	"meta-file-system/controller/respond"
 	"meta-file-system/model"
 	"meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid_protocols"
 	"meta-file-system/service/indexer_service"
 
 	"github.com/gin-gonic/gin"
@@ -49,6 +54,17 @@ func getIndexerBaseUrl() string {
 	return "https://" + strings.TrimSuffix(s, "/")
 }
 
+// queryFields parses the fields parameter (sparse fieldset) against the
+// allowed names; on an unknown name it responds and returns false
+func queryFields(c *gin.Context, allowed []string) (respond.FieldSet, bool) {
+	fields, err := respond.ParseFieldSet(c.Query("fields"), allowed)
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return nil, false
+	}
+	return fields, true
+}
+
 // GetLatestByFirstPinID get latest file information by first PIN ID
 // @Summary      Get latest file by first PIN ID
 // @Description  Query latest file details by first PIN ID
@@ -56,10 +72,15 @@ func getIndexerBaseUrl() string {
 // @Accept       json
 // @Produce      json
 // @Param        firstPinId  path      string  true  "First PIN ID"
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200         {object}  respond.Response{data=respond.IndexerFileResponse}
 // @Failure      404         {object}  respond.Response
-// @Router       /files/latest/{firstPinId} [get]
+// @Router       /v1/files/latest/{firstPinId} [get]
 func (h *IndexerQueryHandler) GetLatestByFirstPinID(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	firstPinID := c.Param("firstPinId")
 	if firstPinID == "" {
 		respond.InvalidParam(c, "firstPinId is required")
@@ -71,7 +92,7 @@ func (h *IndexerQueryHandler) GetLatestByFirstPinID(c *gin.Context) {
 		respond.NotFound(c, err.Error())
 		return
 	}
-	respond.Success(c, respond.ToIndexerFileResponse(file, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileResponse(file, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // GetByPinID get file information by PIN ID
@@ -81,10 +102,15 @@ func (h *IndexerQueryHandler) GetLatestByFirstPinID(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        pinId  path      string  true  "PIN ID"
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200    {object}  respond.Response{data=respond.IndexerFileResponse}
 // @Failure      404    {object}  respond.Response
-// @Router       /files/{pinId} [get]
+// @Router       /v1/files/{pinId} [get]
 func (h *IndexerQueryHandler) GetByPinID(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	pinID := c.Param("pinId")
 	if pinID == "" {
 		respond.InvalidParam(c, "pinId is required")
@@ -96,18 +122,56 @@ func (h *IndexerQueryHandler) GetByPinID(c *gin.Context) {
 		respond.NotFound(c, err.Error())
 		return
 	}
-	respond.Success(c, respond.ToIndexerFileResponse(file, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileResponse(file, h.indexerFileService, getIndexerBaseUrl()), fields))
+}
+
+// GetFilesByPinIDs get metadata for many files in one request
+// @Summary      Get files by PIN IDs (batch)
+// @Description  Query file details for up to 100 PIN IDs at once. Files are returned in request order; PIN IDs with no indexed file are listed in missing.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      json
+// @Param        request  body      respond.IndexerFileBatchRequest  true  "PIN IDs"
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200      {object}  respond.Response{data=respond.IndexerFileBatchResponse}
+// @Failure      400      {object}  respond.Response
+// @Router       /v1/files/batch [post]
+func (h *IndexerQueryHandler) GetFilesByPinIDs(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	var req respond.IndexerFileBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, fmt.Sprintf("invalid request parameters: %v", err))
+		return
+	}
+	if len(req.PinIDs) == 0 {
+		respond.InvalidParam(c, "pin_ids is required")
+		return
+	}
+	if len(req.PinIDs) > indexer_service.MaxBatchPinIDs {
+		respond.InvalidParam(c, fmt.Sprintf("at most %d pin_ids per request", indexer_service.MaxBatchPinIDs))
+		return
+	}
+
+	files, missing, err := h.indexerFileService.GetFilesByPinIDs(req.PinIDs)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileBatchResponse(files, missing, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // GetFileStatus report the indexing state of a pinId.
 // @Summary      Get file index status by PIN ID
-// @Description  Report whether a file pin is merged / pending (on chain but not indexed yet) / not_found
+// @Description  Report whether a file pin is merged / pending (on chain but not indexed yet) / rejected (content refused, with reason) / pending_storage (indexed, storage write queued for retry, with the storage error) / not_found
 // @Tags         Indexer File Query
 // @Accept       json
 // @Produce      json
 // @Param        pinId  path      string  true  "PIN ID"
 // @Success      200    {object}  respond.Response{data=indexer_service.FileStatus}
-// @Router       /files/status/{pinId} [get]
+// @Router       /v1/files/status/{pinId} [get]
 func (h *IndexerQueryHandler) GetFileStatus(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -122,6 +186,38 @@ func (h *IndexerQueryHandler) GetFileStatus(c *gin.Context) {
 	respond.Success(c, status)
 }
 
+// GetFileChunks list the chunks and merge status of a multi-chunk file
+// @Summary      List chunks of a multi-chunk file
+// @Description  List every chunk of the multi-chunk file indexed by pinId (the metafile/index PIN) in chunkList order: PIN ID, index, offset, size, sha256, whether the chunk is indexed and confirmed, and whether its stored hash matches. Also reports the merge status and whether the merged file exists in storage. Works while the merge is still waiting for chunks.
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        pinId  path      string  true  "Index PIN ID"
+// @Success      200    {object}  respond.Response{data=indexer_service.FileChunksResult}
+// @Failure      400    {object}  respond.Response  "Not a multi-chunk file"
+// @Failure      404    {object}  respond.Response  "Index not found"
+// @Failure      500    {object}  respond.Response
+// @Router       /v1/files/{pinId}/chunks [get]
+func (h *IndexerQueryHandler) GetFileChunks(c *gin.Context) {
+	pinID := c.Param("pinId")
+	if pinID == "" {
+		respond.InvalidParam(c, "pinId is required")
+		return
+	}
+	result, err := h.indexerFileService.GetFileChunks(pinID)
+	if err != nil {
+		switch {
+		case errors.Is(err, indexer_service.ErrIndexNotFound):
+			respond.NotFound(c, err.Error())
+		case errors.Is(err, indexer_service.ErrNotMultiChunkFile):
+			respond.InvalidParam(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+	respond.Success(c, result)
+}
+
 // GetPinInfoByPinID get PIN information by PIN ID from collectionPinInfo
 // @Summary      Get PIN info by PIN ID
 // @Description  Query PIN details from collectionPinInfo by PIN ID
@@ -131,7 +227,7 @@ func (h *IndexerQueryHandler) GetFileStatus(c *gin.Context) {
 // @Param        pinId  path      string  true  "PIN ID"
 // @Success      200    {object}  respond.Response{data=respond.IndexerPinInfoResponse}
 // @Failure      404    {object}  respond.Response
-// @Router       /pins/{pinId} [get]
+// @Router       /v1/pins/{pinId} [get]
 func (h *IndexerQueryHandler) GetPinInfoByPinID(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -154,13 +250,19 @@ func (h *IndexerQueryHandler) GetPinInfoByPinID(c *gin.Context) {
 // @Tags         Indexer File Query
 // @Accept       json
 // @Produce      json
-// @Param        address  path   string  true   "Creator address"
+// @Param        address  path   string  true   "Creator address, or an ID address for the creator's files on every chain"
 // @Param        cursor   query  int     false  "Cursor" default(0)
 // @Param        size     query  int     false  "Page size"             default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200      {object}  respond.Response{data=respond.IndexerFileListResponse}
 // @Failure      500      {object}  respond.Response
-// @Router       /files/creator/{address} [get]
+// @Router       /v1/files/creator/{address} [get]
 func (h *IndexerQueryHandler) GetByCreatorAddress(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	address := c.Param("address")
 	if address == "" {
 		respond.InvalidParam(c, "address is required")
@@ -175,13 +277,13 @@ func (h *IndexerQueryHandler) GetByCreatorAddress(c *gin.Context) {
 	size, _ := strconv.Atoi(sizeStr)
 
 	// Query file list
-	files, nextCursor, hasMore, err := h.indexerFileService.GetFilesByCreatorAddress(address, cursor, size)
+	files, nextCursor, hasMore, err := h.indexerFileService.GetFilesByCreatorAddress(address, cursor, size, includeUnconfirmed(c))
 	if err != nil {
 		respond.ServerError(c, err.Error())
 		return
 	}
 
-	respond.Success(c, respond.ToIndexerFileListResponse(files, nextCursor, hasMore, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileListResponse(files, nextCursor, hasMore, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // GetByCreatorMetaID get file list by creator MetaID or GlobalMetaID
@@ -193,10 +295,21 @@ func (h *IndexerQueryHandler) GetByCreatorAddress(c *gin.Context) {
 // @Param        metaidOrGlobalMetaId  path   string  true   "Creator MetaID or GlobalMetaID"
 // @Param        cursor                query  int     false  "Cursor" default(0)
 // @Param        size                  query  int     false  "Page size" default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        file_type             query  string  false  "File type: image/video/audio/document/other"
+// @Param        content_type          query  string  false  "Content type prefix, e.g. image/ or image/png"
+// @Param        path_prefix           query  string  false  "MetaID path prefix, matched on whole segments, e.g. /file/photos"
+// @Param        chain                 query  string  false  "Chain name: btc/mvc/doge"
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200                   {object}  respond.Response{data=respond.IndexerFileListResponse}
+// @Failure      400                   {object}  respond.Response
 // @Failure      500                   {object}  respond.Response
-// @Router       /files/metaid/{metaidOrGlobalMetaId} [get]
+// @Router       /v1/files/metaid/{metaidOrGlobalMetaId} [get]
 func (h *IndexerQueryHandler) GetByCreatorMetaID(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	metaidOrGlobalMetaId := c.Param("metaidOrGlobalMetaId")
 	if metaidOrGlobalMetaId == "" {
 		respond.InvalidParam(c, "metaidOrGlobalMetaId is required")
@@ -208,22 +321,32 @@ func (h *IndexerQueryHandler) GetByCreatorMetaID(c *gin.Context) {
 	cursor, _ := strconv.ParseInt(cursorStr, 10, 64)
 	size, _ := strconv.Atoi(sizeStr)
 
+	filter, err := indexer_service.NormalizeFileFilter(model.IndexerFileFilter{
+		FileType:          c.Query("file_type"),
+		ContentTypePrefix: c.Query("content_type"),
+		PathPrefix:        c.Query("path_prefix"),
+		ChainName:         c.Query("chain"),
+	})
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
 	var files []*model.IndexerFile
 	var nextCursor int64
 	var hasMore bool
-	var err error
 
 	if common_service.IsGlobalMetaId(metaidOrGlobalMetaId) {
-		files, nextCursor, hasMore, err = h.indexerFileService.GetFilesByCreatorGlobalMetaID(metaidOrGlobalMetaId, cursor, size)
+		files, nextCursor, hasMore, err = h.indexerFileService.GetFilesByCreatorGlobalMetaID(metaidOrGlobalMetaId, filter, cursor, size, includeUnconfirmed(c))
 	} else {
-		files, nextCursor, hasMore, err = h.indexerFileService.GetFilesByCreatorMetaID(metaidOrGlobalMetaId, cursor, size)
+		files, nextCursor, hasMore, err = h.indexerFileService.GetFilesByCreatorMetaID(metaidOrGlobalMetaId, filter, cursor, size, includeUnconfirmed(c))
 	}
 	if err != nil {
 		respond.ServerError(c, err.Error())
 		return
 	}
 
-	respond.Success(c, respond.ToIndexerFileListResponse(files, nextCursor, hasMore, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileListResponse(files, nextCursor, hasMore, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // ListFiles get file list with cursor pagination
@@ -234,10 +357,16 @@ func (h *IndexerQueryHandler) GetByCreatorMetaID(c *gin.Context) {
 // @Produce      json
 // @Param        cursor  query  int  false  "Cursor" default(0)
 // @Param        size    query  int  false  "Page size"             default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200     {object}  respond.Response{data=respond.IndexerFileListResponse}
 // @Failure      500     {object}  respond.Response
-// @Router       /files [get]
+// @Router       /v1/files [get]
 func (h *IndexerQueryHandler) ListFiles(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	// Get cursor and size parameters
 	cursorStr := c.DefaultQuery("cursor", "0")
 	sizeStr := c.DefaultQuery("size", "20")
@@ -246,13 +375,13 @@ func (h *IndexerQueryHandler) ListFiles(c *gin.Context) {
 	size, _ := strconv.Atoi(sizeStr)
 
 	// Query file list
-	files, nextCursor, hasMore, err := h.indexerFileService.ListFiles(cursor, size)
+	files, nextCursor, hasMore, err := h.indexerFileService.ListFiles(cursor, size, includeUnconfirmed(c))
 	if err != nil {
 		respond.ServerError(c, err.Error())
 		return
 	}
 
-	respond.Success(c, respond.ToIndexerFileListResponse(files, nextCursor, hasMore, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileListResponse(files, nextCursor, hasMore, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // normalizeExtension 归一化扩展名：小写、带前导点（与 DB 索引一致）
@@ -346,6 +475,51 @@ func mergeFilesByExtension(filesByExt [][]*model.IndexerFile, size int) ([]*mode
 	return out, nextTimestamp, hasMore
 }
 
+// extensionLister lists one extension's files from a key-based cursor (see ListFilesByExtension)
+type extensionLister func(extension, cursor string, size int) ([]*model.IndexerFile, string, bool, error)
+
+// listFilesByExtensions lists the files of one or more extensions from the
+// 16-digit timestamp cursor, merging the results of several extensions
+func listFilesByExtensions(list extensionLister, extensions []string, timestamp string, size int) ([]*model.IndexerFile, string, bool, error) {
+	if len(extensions) == 1 {
+		files, nextCursor, hasMore, err := list(extensions[0], timestamp, size)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return files, extractTimestamp16FromKey(nextCursor), hasMore, nil
+	}
+
+	fetchSize := size * len(extensions)
+	if fetchSize > 500 {
+		fetchSize = 500
+	}
+	var filesByExt [][]*model.IndexerFile
+	for _, ext := range extensions {
+		files, _, _, err := list(ext, timestamp, fetchSize)
+		if err != nil {
+			return nil, "", false, err
+		}
+		filesByExt = append(filesByExt, files)
+	}
+	files, nextTimestamp, hasMore := mergeFilesByExtension(filesByExt, size)
+	return files, nextTimestamp, hasMore, nil
+}
+
+// extensionPageSize page size of the extension listings (1-100, default 20)
+func extensionPageSize(c *gin.Context) int {
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if size < 1 || size > 100 {
+		size = 20
+	}
+	return size
+}
+
+// includeUnconfirmed reports whether a listing also returns mempool-only files
+// (includeUnconfirmed=true); by default only confirmed files are listed
+func includeUnconfirmed(c *gin.Context) bool {
+	return c.Query("includeUnconfirmed") == "true"
+}
+
 // GetFilesByExtension get file list by file extension (global), reverse time order; extension as query (array supported)
 // @Summary      Get files by extension
 // @Description  Query file list by file extension (e.g. .jpg, .png), reverse time order; extension can be repeated for multiple. Paginate with timestamp (16-digit).
@@ -355,51 +529,29 @@ func mergeFilesByExtension(filesByExt [][]*model.IndexerFile, size int) ([]*mode
 // @Param        extension  query  []string  true   "File extension(s), supports multi (extension=.jpg&extension=.png) and csv (extension=.jpg,.png)"
 // @Param        timestamp  query  string    false  "Next page: 16-digit timestamp from previous response next_timestamp"
 // @Param        size       query  int       false  "Page size" default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200        {object}  respond.Response{data=respond.IndexerFileListByExtensionResponse}
 // @Failure      500        {object}  respond.Response
-// @Router       /files/extension [get]
+// @Router       /v1/files/extension [get]
 func (h *IndexerQueryHandler) GetFilesByExtension(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	extensions := parseExtensionsQuery(c)
 	if len(extensions) == 0 {
 		respond.InvalidParam(c, "extension is required (query, supports: extension=.jpg&extension=.png or extension=.jpg,.png)")
 		return
 	}
-	timestamp := c.DefaultQuery("timestamp", "")
-	sizeStr := c.DefaultQuery("size", "20")
-	size, _ := strconv.Atoi(sizeStr)
-	if size < 1 || size > 100 {
-		size = 20
-	}
-
-	var files []*model.IndexerFile
-	var nextTimestamp string
-	var hasMore bool
-	if len(extensions) == 1 {
-		var nextCursor string
-		var err error
-		files, nextCursor, hasMore, err = h.indexerFileService.ListFilesByExtension(extensions[0], timestamp, size)
-		if err != nil {
-			respond.ServerError(c, err.Error())
-			return
-		}
-		nextTimestamp = extractTimestamp16FromKey(nextCursor)
-	} else {
-		fetchSize := size * len(extensions)
-		if fetchSize > 500 {
-			fetchSize = 500
-		}
-		var filesByExt [][]*model.IndexerFile
-		for _, ext := range extensions {
-			list, _, _, err := h.indexerFileService.ListFilesByExtension(ext, timestamp, fetchSize)
-			if err != nil {
-				respond.ServerError(c, err.Error())
-				return
-			}
-			filesByExt = append(filesByExt, list)
-		}
-		files, nextTimestamp, hasMore = mergeFilesByExtension(filesByExt, size)
+	files, nextTimestamp, hasMore, err := listFilesByExtensions(func(ext, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+		return h.indexerFileService.ListFilesByExtension(ext, cursor, size, includeUnconfirmed(c))
+	}, extensions, c.DefaultQuery("timestamp", ""), extensionPageSize(c))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
 	}
-	respond.Success(c, respond.ToIndexerFileListByExtensionResponse(files, nextTimestamp, hasMore, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileListByExtensionResponse(files, nextTimestamp, hasMore, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // GetFilesByGlobalMetaIDAndExtension get file list by globalMetaID and file extension; extension as query (array supported)
@@ -412,10 +564,16 @@ func (h *IndexerQueryHandler) GetFilesByExtension(c *gin.Context) {
 // @Param        extension            query    []string  true  "File extension(s), supports multi (extension=.jpg&extension=.png) and csv (extension=.jpg,.png)"
 // @Param        timestamp            query    string    false "Next page: 16-digit timestamp from previous response next_timestamp"
 // @Param        size                 query    int       false "Page size" default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200                  {object}  respond.Response{data=respond.IndexerFileListByExtensionResponse}
 // @Failure      500                  {object}  respond.Response
-// @Router       /files/metaid/{metaidOrGlobalMetaId}/extension [get]
+// @Router       /v1/files/metaid/{metaidOrGlobalMetaId}/extension [get]
 func (h *IndexerQueryHandler) GetFilesByGlobalMetaIDAndExtension(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	globalMetaID := c.Param("metaidOrGlobalMetaId")
 	if globalMetaID == "" {
 		respond.InvalidParam(c, "metaidOrGlobalMetaId is required")
@@ -426,42 +584,14 @@ func (h *IndexerQueryHandler) GetFilesByGlobalMetaIDAndExtension(c *gin.Context)
 		respond.InvalidParam(c, "extension is required (query, supports: extension=.jpg&extension=.png or extension=.jpg,.png)")
 		return
 	}
-	timestamp := c.DefaultQuery("timestamp", "")
-	sizeStr := c.DefaultQuery("size", "20")
-	size, _ := strconv.Atoi(sizeStr)
-	if size < 1 || size > 100 {
-		size = 20
-	}
-
-	var files []*model.IndexerFile
-	var nextTimestamp string
-	var hasMore bool
-	if len(extensions) == 1 {
-		var nextCursor string
-		var err error
-		files, nextCursor, hasMore, err = h.indexerFileService.ListFilesByGlobalMetaIDAndExtension(globalMetaID, extensions[0], timestamp, size)
-		if err != nil {
-			respond.ServerError(c, err.Error())
-			return
-		}
-		nextTimestamp = extractTimestamp16FromKey(nextCursor)
-	} else {
-		fetchSize := size * len(extensions)
-		if fetchSize > 500 {
-			fetchSize = 500
-		}
-		var filesByExt [][]*model.IndexerFile
-		for _, ext := range extensions {
-			list, _, _, err := h.indexerFileService.ListFilesByGlobalMetaIDAndExtension(globalMetaID, ext, timestamp, fetchSize)
-			if err != nil {
-				respond.ServerError(c, err.Error())
-				return
-			}
-			filesByExt = append(filesByExt, list)
-		}
-		files, nextTimestamp, hasMore = mergeFilesByExtension(filesByExt, size)
+	files, nextTimestamp, hasMore, err := listFilesByExtensions(func(ext, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+		return h.indexerFileService.ListFilesByGlobalMetaIDAndExtension(globalMetaID, ext, cursor, size, includeUnconfirmed(c))
+	}, extensions, c.DefaultQuery("timestamp", ""), extensionPageSize(c))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
 	}
-	respond.Success(c, respond.ToIndexerFileListByExtensionResponse(files, nextTimestamp, hasMore, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileListByExtensionResponse(files, nextTimestamp, hasMore, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // GetFilesByKeywordAndExtension get file list by keyword and file extension; extension as query (array supported)
@@ -474,10 +604,16 @@ func (h *IndexerQueryHandler) GetFilesByGlobalMetaIDAndExtension(c *gin.Context)
 // @Param        extension  query    []string  true   "File extension(s), supports multi (extension=.jpg&extension=.png) and csv (extension=.jpg,.png)"
 // @Param        timestamp  query    string    false  "Next page: 16-digit timestamp from previous response next_timestamp"
 // @Param        size       query    int       false  "Page size" default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
 // @Success      200        {object}  respond.Response{data=respond.IndexerFileListByExtensionResponse}
 // @Failure      500        {object}  respond.Response
-// @Router       /files/keyword/{keyword}/extension [get]
+// @Router       /v1/files/keyword/{keyword}/extension [get]
 func (h *IndexerQueryHandler) GetFilesByKeywordAndExtension(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
 	keyword := strings.TrimSpace(c.Param("keyword"))
 	if keyword == "" {
 		respond.InvalidParam(c, "keyword is required")
@@ -490,44 +626,14 @@ func (h *IndexerQueryHandler) GetFilesByKeywordAndExtension(c *gin.Context) {
 		return
 	}
 
-	timestamp := c.DefaultQuery("timestamp", "")
-	sizeStr := c.DefaultQuery("size", "20")
-	size, _ := strconv.Atoi(sizeStr)
-	if size < 1 || size > 100 {
-		size = 20
-	}
-
-	var files []*model.IndexerFile
-	var nextTimestamp string
-	var hasMore bool
-
-	if len(extensions) == 1 {
-		var nextCursor string
-		var err error
-		files, nextCursor, hasMore, err = h.indexerFileService.ListFilesByKeywordAndExtension(keyword, extensions[0], timestamp, size)
-		if err != nil {
-			respond.ServerError(c, err.Error())
-			return
-		}
-		nextTimestamp = extractTimestamp16FromKey(nextCursor)
-	} else {
-		fetchSize := size * len(extensions)
-		if fetchSize > 500 {
-			fetchSize = 500
-		}
-		var filesByExt [][]*model.IndexerFile
-		for _, ext := range extensions {
-			list, _, _, err := h.indexerFileService.ListFilesByKeywordAndExtension(keyword, ext, timestamp, fetchSize)
-			if err != nil {
-				respond.ServerError(c, err.Error())
-				return
-			}
-			filesByExt = append(filesByExt, list)
-		}
-		files, nextTimestamp, hasMore = mergeFilesByExtension(filesByExt, size)
+	files, nextTimestamp, hasMore, err := listFilesByExtensions(func(ext, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+		return h.indexerFileService.ListFilesByKeywordAndExtension(keyword, ext, cursor, size, includeUnconfirmed(c))
+	}, extensions, c.DefaultQuery("timestamp", ""), extensionPageSize(c))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
 	}
-
-	respond.Success(c, respond.ToIndexerFileListByExtensionResponse(files, nextTimestamp, hasMore, h.indexerFileService, getIndexerBaseUrl()))
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFileListByExtensionResponse(files, nextTimestamp, hasMore, h.indexerFileService, getIndexerBaseUrl()), fields))
 }
 
 // GetLatestFileContentByFirstPinID get latest file content by first PIN ID
@@ -539,7 +645,7 @@ func (h *IndexerQueryHandler) GetFilesByKeywordAndExtension(c *gin.Context) {
 // @Param        firstPinId  path      string  true  "First PIN ID"
 // @Success      200         {file}    binary
 // @Failure      404         {object}  respond.Response
-// @Router       /files/content/latest/{firstPinId} [get]
+// @Router       /v1/files/content/latest/{firstPinId} [get]
 func (h *IndexerQueryHandler) GetLatestFileContentByFirstPinID(c *gin.Context) {
 	firstPinID := c.Param("firstPinId")
 	if firstPinID == "" {
@@ -547,28 +653,25 @@ func (h *IndexerQueryHandler) GetLatestFileContentByFirstPinID(c *gin.Context) {
 		return
 	}
 
-	content, contentType, fileName, err := h.indexerFileService.GetLatestFileContentByFirstPinID(firstPinID)
+	content, err := h.indexerFileService.OpenLatestFileContent(firstPinID)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respond.ContentError(c, err)
 		return
 	}
-
-	// Set response headers
-	c.Header("Content-Type", contentType)
-	c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
-	c.Data(200, contentType, content)
+	writeFileContent(c, content)
 }
 
 // GetFileContent get file content by PIN ID
 // @Summary      Get file content
-// @Description  Get file content by PIN ID
+// @Description  Get file content by PIN ID. A blob missing on this gateway is fetched from its peers (indexer.peers), checked against the file hash and cached; requests with the X-Mfs-Peer-Fetch header are answered from local storage only.
 // @Tags         Indexer File Query
 // @Accept       json
 // @Produce      octet-stream
 // @Param        pinId  path      string  true  "PIN ID"
+// @Param        X-Mfs-Peer-Fetch  header  string  false  "Set by peer gateways: do not ask other peers"
 // @Success      200    {file}    binary
 // @Failure      404    {object}  respond.Response
-// @Router       /files/content/{pinId} [get]
+// @Router       /v1/files/content/{pinId} [get]
 func (h *IndexerQueryHandler) GetFileContent(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -576,27 +679,99 @@ func (h *IndexerQueryHandler) GetFileContent(c *gin.Context) {
 		return
 	}
 
-	content, contentType, fileName, err := h.indexerFileService.GetFileContent(pinID)
+	// Peers are answered from this gateway's storage only
+	open := h.indexerFileService.OpenFileContent
+	if c.GetHeader(indexer_service.PeerFetchHeader) != "" {
+		open = h.indexerFileService.OpenLocalFileContent
+	}
+	content, err := open(pinID)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respond.ContentError(c, err)
 		return
 	}
+	writeFileContent(c, content)
+}
 
-	// Set response headers
-	c.Header("Content-Type", contentType)
-	c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
-	c.Data(200, contentType, content)
+// writeFileContent sends file content inline; large multi-chunk files are
+// streamed chunk by chunk, so an error part way can only abort the response
+func writeFileContent(c *gin.Context, content *indexer_service.FileContent) {
+	c.Header("Content-Type", contenttype.Header(content.ContentType))
+	c.Header("Content-Disposition", "inline; filename=\""+content.FileName+"\"")
+	c.Header("Content-Length", strconv.FormatInt(content.Size, 10))
+	c.Status(200)
+	if _, err := content.WriteTo(c.Writer); err != nil {
+		log.Printf("Failed to write content of %s: %v", content.File.PinID, err)
+		c.Abort()
+	}
+}
+
+// ResolveMfs resolve an mfs:// URI
+// @Summary      Resolve mfs:// URI
+// @Description  Resolve a content-addressable mfs://{pinId} or mfs://{sha256} URI. A sha256 URI names content rather than a PIN and resolves to the earliest indexed PIN with that file sha256. mode=content (default) returns the bytes with an immutable Cache-Control and the sha256 as ETag, mode=redirect answers 307 to /files/content/{pinId}, mode=json returns the resolved file.
+// @Tags         Indexer File Query
+// @Accept       json
+// @Produce      octet-stream,json
+// @Param        uri   query     string  true   "mfs://{pinId} or mfs://{sha256}"
+// @Param        mode  query     string  false  "content, redirect or json"  default(content)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200   {object}  respond.Response{data=respond.MfsResolveResponse}  "mode=json; other modes return the file bytes"
+// @Success      307   {string}  string  "mode=redirect: redirect to the content URL"
+// @Failure      400   {object}  respond.Response
+// @Failure      404   {object}  respond.Response
+// @Router       /v1/resolve [get]
+func (h *IndexerQueryHandler) ResolveMfs(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	mode := c.DefaultQuery("mode", "content")
+	if mode != "content" && mode != "redirect" && mode != "json" {
+		respond.InvalidParam(c, "mode must be content, redirect or json")
+		return
+	}
+
+	uri, file, err := h.indexerFileService.ResolveMfsURI(c.Query("uri"))
+	if err != nil {
+		switch {
+		case errors.Is(err, metaid_protocols.ErrInvalidMfsURI):
+			respond.InvalidParam(c, err.Error())
+		case errors.Is(err, indexer_service.ErrMfsNotFound):
+			respond.NotFound(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	switch mode {
+	case "json":
+		respond.Success(c, respond.SelectFields(respond.ToMfsResolveResponse(uri, file, h.indexerFileService, getIndexerBaseUrl()), fields))
+	case "redirect":
+		c.Redirect(307, getIndexerBaseUrl()+"/api/v1/files/content/"+file.PinID)
+	default:
+		content, err := h.indexerFileService.OpenFileContent(file.PinID)
+		if err != nil {
+			respond.ContentError(c, err)
+			return
+		}
+		// PIN content never changes, so either URI form can be cached forever
+		if file.FileHash != "" {
+			c.Header("ETag", "\""+file.FileHash+"\"")
+		}
+		c.Header("Cache-Control", "public, max-age=31536000, immutable")
+		writeFileContent(c, content)
+	}
 }
 
 // GetSyncStatus get indexer sync status
 // @Summary      Get sync status
-// @Description  Get current sync status for all chains (current sync height and latest block height)
+// @Description  Get current sync status for all chains (current sync height, latest block height, and for pruned nodes the earliest block that can be rescanned, and why scanning is paused), with the PIN parser mode, experimental features and the PINs accepted, warned about and rejected per mode since startup
 // @Tags         Indexer Status
 // @Accept       json
 // @Produce      json
 // @Success      200  {object}  respond.Response{data=respond.IndexerMultiChainSyncStatusResponse}
 // @Failure      500  {object}  respond.Response
-// @Router       /status [get]
+// @Router       /v1/status [get]
 func (h *IndexerQueryHandler) GetSyncStatus(c *gin.Context) {
 	// Get all chain sync statuses
 	statuses, err := h.syncStatusService.GetAllSyncStatus()
@@ -623,7 +798,47 @@ func (h *IndexerQueryHandler) GetSyncStatus(c *gin.Context) {
 		}
 	}
 
-	respond.Success(c, respond.ToIndexerMultiChainSyncStatusResponse(statuses, latestHeights))
+	response := respond.ToIndexerMultiChainSyncStatusResponse(statuses, latestHeights)
+	for i := range response.Chains {
+		if info, ok := h.syncStatusService.GetPruneInfo(response.Chains[i].ChainName); ok && info.Pruned {
+			response.Chains[i].Pruned = true
+			response.Chains[i].EarliestBlockHeight = info.EarliestHeight()
+		}
+		response.Chains[i].Parser = toIndexerParserStatus(indexer_service.GetParserStats(response.Chains[i].ChainName))
+		if h.indexerService != nil {
+			response.Chains[i].Paused = h.indexerService.PauseReason(response.Chains[i].ChainName)
+		}
+	}
+	respond.Success(c, response)
+}
+
+// toIndexerParserStatus converts a chain's parser stats to the status response
+func toIndexerParserStatus(stats indexer_service.ParserStats) *respond.IndexerParserStatus {
+	status := &respond.IndexerParserStatus{
+		Mode:     stats.Mode,
+		Features: stats.Features,
+		Counts:   make(map[string]respond.IndexerParserCounts, len(stats.Counts)),
+	}
+	for mode, counts := range stats.Counts {
+		status.Counts[mode] = respond.IndexerParserCounts{
+			Accepted: counts.Accepted,
+			Warned:   counts.Warned,
+			Rejected: counts.Rejected,
+			Reasons:  counts.Reasons,
+		}
+	}
+	return status
+}
+
+// ListPeers list peer gateways
+// @Summary      List peer gateways
+// @Description  List the peer gateways this indexer asks for content it lacks (indexer.peers), configured ones first, with the outcome of the last requests. Other gateways read this list to discover peers.
+// @Tags         Indexer Status
+// @Produce      json
+// @Success      200  {object}  respond.Response{data=respond.IndexerPeerListResponse}
+// @Router       /v1/peers [get]
+func (h *IndexerQueryHandler) ListPeers(c *gin.Context) {
+	respond.Success(c, respond.IndexerPeerListResponse{Peers: h.indexerFileService.ListPeers()})
 }
 
 // GetStats get indexer statistics (supports per-chain breakdown)
@@ -634,7 +849,7 @@ func (h *IndexerQueryHandler) GetSyncStatus(c *gin.Context) {
 // @Produce      json
 // @Success      200  {object}  respond.Response{data=respond.IndexerStatsResponse}
 // @Failure      500  {object}  respond.Response
-// @Router       /stats [get]
+// @Router       /v1/stats [get]
 func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 	// Get total files count
 	filesCount, err := h.indexerFileService.GetFilesCount()
@@ -654,6 +869,138 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 	respond.Success(c, respond.ToIndexerStatsResponseWithChains(filesCount, chainStats))
 }
 
+// countPageSize reads the page size used to compute page counts (listing default 20)
+func countPageSize(c *gin.Context) int {
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil || size < 1 {
+		return 20
+	}
+	return size
+}
+
+// GetFilesCount get total (or per-chain) file count for paginating the file list
+// @Summary      Get file count
+// @Description  Total number of indexed files with per-chain counts, or the count of one chain, plus the page count for size. Served from maintained counters (no scan)
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        chain  query     string  false  "Chain name: btc/mvc/doge; omit for the total"
+// @Param        size   query     int     false  "Page size used to compute pages"  default(20)
+// @Success      200    {object}  respond.Response{data=respond.IndexerFileCountResponse}
+// @Failure      500    {object}  respond.Response
+// @Router       /v1/files/count [get]
+func (h *IndexerQueryHandler) GetFilesCount(c *gin.Context) {
+	size := countPageSize(c)
+
+	if chain := strings.TrimSpace(c.Query("chain")); chain != "" {
+		count, err := h.indexerFileService.GetFilesCountByChain(strings.ToLower(chain))
+		if err != nil {
+			respond.ServerError(c, err.Error())
+			return
+		}
+		respond.Success(c, respond.ToIndexerFileCountResponse(count, size, nil))
+		return
+	}
+
+	total, err := h.indexerFileService.GetFilesCount()
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	chains, err := h.indexerFileService.GetFilesCountByChains()
+	if err != nil {
+		chains = nil
+	}
+	respond.Success(c, respond.ToIndexerFileCountResponse(total, size, chains))
+}
+
+// GetCreatorFilesCount get file count of a creator for paginating its file list
+// @Summary      Get file count by creator MetaID or GlobalMetaID
+// @Description  Number of files of a creator (as listed by /files/metaid/{metaidOrGlobalMetaId} without filters) plus the page count for size. Served from maintained counters (no scan)
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        metaidOrGlobalMetaId  path      string  true   "Creator MetaID or GlobalMetaID"
+// @Param        size                  query     int     false  "Page size used to compute pages"  default(20)
+// @Success      200                   {object}  respond.Response{data=respond.IndexerFileCountResponse}
+// @Failure      400                   {object}  respond.Response
+// @Failure      500                   {object}  respond.Response
+// @Router       /v1/files/metaid/{metaidOrGlobalMetaId}/count [get]
+func (h *IndexerQueryHandler) GetCreatorFilesCount(c *gin.Context) {
+	metaidOrGlobalMetaId := c.Param("metaidOrGlobalMetaId")
+	if metaidOrGlobalMetaId == "" {
+		respond.InvalidParam(c, "metaidOrGlobalMetaId is required")
+		return
+	}
+
+	var count int64
+	var err error
+	if common_service.IsGlobalMetaId(metaidOrGlobalMetaId) {
+		count, err = h.indexerFileService.GetFilesCountByCreatorGlobalMetaID(metaidOrGlobalMetaId)
+	} else {
+		count, err = h.indexerFileService.GetFilesCountByCreatorMetaID(metaidOrGlobalMetaId)
+	}
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, respond.ToIndexerFileCountResponse(count, countPageSize(c), nil))
+}
+
+// GetDailyStats get per-day stats time series
+// @Summary      Get daily statistics
+// @Description  Get per-day counts of new files, new users and bytes indexed (with per-chain breakdown) for an inclusive UTC date range; days without activity are zero-filled
+// @Tags         Indexer Status
+// @Accept       json
+// @Produce      json
+// @Param        from  query  string  false  "Start day (YYYY-MM-DD, UTC); defaults to 30 days before to"
+// @Param        to    query  string  false  "End day (YYYY-MM-DD, UTC); defaults to today"
+// @Success      200   {object}  respond.Response{data=respond.IndexerDailyStatsResponse}
+// @Failure      400   {object}  respond.Response
+// @Failure      500   {object}  respond.Response
+// @Router       /v1/stats/daily [get]
+func (h *IndexerQueryHandler) GetDailyStats(c *gin.Context) {
+	from, to, err := indexer_service.ParseDailyStatsRange(c.Query("from"), c.Query("to"), time.Now())
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	stats, err := h.indexerFileService.GetDailyStats(from, to)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerDailyStatsResponse(from, to, stats))
+}
+
+// GetWeeklyStats get per-week stats time series
+// @Summary      Get weekly statistics
+// @Description  Get per-week (ISO week, Monday to Sunday, UTC) counts of new files, new users and bytes indexed (with per-chain breakdown); the range is widened to whole weeks and weeks without activity are zero-filled
+// @Tags         Indexer Status
+// @Accept       json
+// @Produce      json
+// @Param        from  query  string  false  "Start day (YYYY-MM-DD, UTC), moved back to its Monday; defaults to 12 weeks before to"
+// @Param        to    query  string  false  "End day (YYYY-MM-DD, UTC), moved forward to its Sunday; defaults to today"
+// @Success      200   {object}  respond.Response{data=respond.IndexerWeeklyStatsResponse}
+// @Failure      400   {object}  respond.Response
+// @Failure      500   {object}  respond.Response
+// @Router       /v1/stats/weekly [get]
+func (h *IndexerQueryHandler) GetWeeklyStats(c *gin.Context) {
+	from, to, err := indexer_service.ParseWeeklyStatsRange(c.Query("from"), c.Query("to"), time.Now())
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	stats, err := h.indexerFileService.GetDailyStats(from, to)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.ToIndexerWeeklyStatsResponse(from, to, stats))
+}
+
 // ============================================================
 // Old Avatar methods - DEPRECATED (commented out)
 // ============================================================
@@ -668,7 +1015,7 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 // // @Param        size    query  int  false  "Page size"               default(20)
 // // @Success      200     {object}  respond.Response{data=respond.IndexerAvatarListResponse}
 // // @Failure      500     {object}  respond.Response
-// // @Router       /avatars [get]
+// // @Router       /v1/avatars [get]
 // func (h *IndexerQueryHandler) ListAvatars(c *gin.Context) {
 // 	// Get cursor and size parameters
 // 	cursorStr := c.DefaultQuery("cursor", "0")
@@ -696,7 +1043,7 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 // // @Param        metaId  path  string  true  "MetaID"
 // // @Success      200     {object}  respond.Response{data=respond.IndexerAvatarResponse}
 // // @Failure      404     {object}  respond.Response
-// // @Router       /avatars/metaid/{metaId} [get]
+// // @Router       /v1/avatars/metaid/{metaId} [get]
 // func (h *IndexerQueryHandler) GetLatestAvatarByMetaID(c *gin.Context) {
 // 	metaID := c.Param("metaId")
 // 	if metaID == "" {
@@ -722,7 +1069,7 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 // // @Param        address  path  string  true  "Address"
 // // @Success      200      {object}  respond.Response{data=respond.IndexerAvatarResponse}
 // // @Failure      404      {object}  respond.Response
-// // @Router       /avatars/address/{address} [get]
+// // @Router       /v1/avatars/address/{address} [get]
 // func (h *IndexerQueryHandler) GetLatestAvatarByAddress(c *gin.Context) {
 // 	address := c.Param("address")
 // 	if address == "" {
@@ -748,7 +1095,7 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 // // @Param        pinId  path      string  true  "PIN ID"
 // // @Success      200    {file}    binary
 // // @Failure      404    {object}  respond.Response
-// // @Router       /avatars/content/{pinId} [get]
+// // @Router       /v1/avatars/content/{pinId} [get]
 // func (h *IndexerQueryHandler) GetAvatarContent(c *gin.Context) {
 // 	pinID := c.Param("pinId")
 // 	if pinID == "" {
@@ -779,10 +1126,15 @@ func (h *IndexerQueryHandler) GetStats(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        metaId  path  string  true  "MetaID"
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200     {object}  respond.Response{data=model.IndexerUserInfo}
 // @Failure      404     {object}  respond.Response
-// @Router       /users/metaid/{metaId} [get]
+// @Router       /v1/users/metaid/{metaId} [get]
 func (h *IndexerQueryHandler) GetUserInfoByMetaID(c *gin.Context) {
+	fields, ok := queryFields(c, respond.UserInfoFields)
+	if !ok {
+		return
+	}
 	metaID := c.Param("metaId")
 	if metaID == "" {
 		respond.InvalidParam(c, "metaId is required")
@@ -795,7 +1147,7 @@ func (h *IndexerQueryHandler) GetUserInfoByMetaID(c *gin.Context) {
 		return
 	}
 
-	respond.Success(c, userInfo)
+	respond.Success(c, respond.SelectFields(respond.WithIDAddress(userInfo), fields))
 }
 
 // GetUserInfoByAddress get user information by address
@@ -804,11 +1156,16 @@ func (h *IndexerQueryHandler) GetUserInfoByMetaID(c *gin.Context) {
 // @Tags         Indexer User Info
 // @Accept       json
 // @Produce      json
-// @Param        address  path  string  true  "Address"
+// @Param        address  path  string  true  "Address (native or ID address)"
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200      {object}  respond.Response{data=model.IndexerUserInfo}
 // @Failure      404      {object}  respond.Response
-// @Router       /users/address/{address} [get]
+// @Router       /v1/users/address/{address} [get]
 func (h *IndexerQueryHandler) GetUserInfoByAddress(c *gin.Context) {
+	fields, ok := queryFields(c, respond.UserInfoFields)
+	if !ok {
+		return
+	}
 	address := c.Param("address")
 	if address == "" {
 		respond.InvalidParam(c, "address is required")
@@ -821,7 +1178,7 @@ func (h *IndexerQueryHandler) GetUserInfoByAddress(c *gin.Context) {
 		return
 	}
 
-	respond.Success(c, userInfo)
+	respond.Success(c, respond.SelectFields(respond.WithIDAddress(userInfo), fields))
 }
 
 // GetMetaIDUserInfoByMetaID get MetaID format user info by MetaID
@@ -831,10 +1188,15 @@ func (h *IndexerQueryHandler) GetUserInfoByAddress(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        metaid  path  string  true  "MetaID"
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200     {object}  respond.Response{data=respond.MetaIDUserInfo}
 // @Failure      404     {object}  respond.Response
-// @Router       /info/metaid/{metaidOrGlobalMetaId} [get]
+// @Router       /v1/info/metaid/{metaidOrGlobalMetaId} [get]
 func (h *IndexerQueryHandler) GetMetaIDUserInfoByMetaID(c *gin.Context) {
+	fields, ok := queryFields(c, respond.MetaIDUserInfoFields)
+	if !ok {
+		return
+	}
 	metaID := c.Param("metaidOrGlobalMetaId")
 	if metaID == "" {
 		respond.InvalidParam(c, "metaid is required")
@@ -847,7 +1209,7 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByMetaID(c *gin.Context) {
 			respond.NotFound(c, err.Error())
 			return
 		}
-		respond.SuccessWithCode(c, 1, respond.ToMetaIDUserInfo(userInfo))
+		respond.SuccessWithCode(c, 1, respond.SelectFields(respond.ToMetaIDUserInfo(userInfo), fields))
 		return
 	}
 
@@ -858,7 +1220,7 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByMetaID(c *gin.Context) {
 	}
 
 	// Convert to MetaIDUserInfo format
-	respond.SuccessWithCode(c, 1, respond.ToMetaIDUserInfo(userInfo))
+	respond.SuccessWithCode(c, 1, respond.SelectFields(respond.ToMetaIDUserInfo(userInfo), fields))
 }
 
 // GetMetaIDUserInfoByAddress get MetaID format user info by address
@@ -867,11 +1229,16 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByMetaID(c *gin.Context) {
 // @Tags         Indexer User Info
 // @Accept       json
 // @Produce      json
-// @Param        address  path  string  true  "Address"
+// @Param        address  path  string  true  "Address (native or ID address)"
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200      {object}  respond.Response{data=respond.MetaIDUserInfo}
 // @Failure      404      {object}  respond.Response
-// @Router       /info/address/{address} [get]
+// @Router       /v1/info/address/{address} [get]
 func (h *IndexerQueryHandler) GetMetaIDUserInfoByAddress(c *gin.Context) {
+	fields, ok := queryFields(c, respond.MetaIDUserInfoFields)
+	if !ok {
+		return
+	}
 	address := c.Param("address")
 	if address == "" {
 		respond.InvalidParam(c, "address is required")
@@ -885,7 +1252,7 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByAddress(c *gin.Context) {
 	}
 
 	// Convert to MetaIDUserInfo format
-	respond.SuccessWithCode(c, 1, respond.ToMetaIDUserInfo(userInfo))
+	respond.SuccessWithCode(c, 1, respond.SelectFields(respond.ToMetaIDUserInfo(userInfo), fields))
 }
 
 // GetMetaIDUserInfoByGlobalMetaID get MetaID format user info by Global MetaID
@@ -895,10 +1262,15 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByAddress(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        globalMetaID  path  string  true  "Global MetaID"
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200      {object}  respond.Response{data=respond.MetaIDUserInfo}
 // @Failure      404      {object}  respond.Response
-// @Router       /info/globalmetaid/{globalMetaID} [get]
+// @Router       /v1/info/globalmetaid/{globalMetaID} [get]
 func (h *IndexerQueryHandler) GetMetaIDUserInfoByGlobalMetaID(c *gin.Context) {
+	fields, ok := queryFields(c, respond.MetaIDUserInfoFields)
+	if !ok {
+		return
+	}
 	globalMetaID := c.Param("globalMetaID")
 	if globalMetaID == "" {
 		respond.InvalidParam(c, "globalMetaID is required")
@@ -912,7 +1284,7 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByGlobalMetaID(c *gin.Context) {
 	}
 
 	// Convert to MetaIDUserInfo format
-	respond.SuccessWithCode(c, 1, respond.ToMetaIDUserInfo(userInfo))
+	respond.SuccessWithCode(c, 1, respond.SelectFields(respond.ToMetaIDUserInfo(userInfo), fields))
 }
 
 // SearchMetaIDUserInfo search MetaID format user info (fuzzy search)
@@ -924,10 +1296,15 @@ func (h *IndexerQueryHandler) GetMetaIDUserInfoByGlobalMetaID(c *gin.Context) {
 // @Param        keyword  query  string  true   "Search keyword (partial MetaID or Name)"
 // @Param        keytype  query  string  true   "Key type: metaid (fuzzy) or name (fuzzy)"
 // @Param        limit    query  int     false  "Result limit (default: 10, max: 100)"
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200      {object}  respond.Response{data=[]respond.MetaIDUserInfo}
 // @Failure      404      {object}  respond.Response
-// @Router       /info/search [get]
+// @Router       /v1/info/search [get]
 func (h *IndexerQueryHandler) SearchMetaIDUserInfo(c *gin.Context) {
+	fields, ok := queryFields(c, respond.MetaIDUserInfoFields)
+	if !ok {
+		return
+	}
 	keyword := c.Query("keyword")
 	keytype := c.Query("keytype")
 	limitStr := c.DefaultQuery("limit", "10")
@@ -959,7 +1336,7 @@ func (h *IndexerQueryHandler) SearchMetaIDUserInfo(c *gin.Context) {
 	}
 
 	// respond.Success(c, result)
-	c.JSON(200, result)
+	c.JSON(200, respond.SelectFields(result, fields))
 }
 
 // ListUserInfo get user info list with pagination
@@ -970,10 +1347,15 @@ func (h *IndexerQueryHandler) SearchMetaIDUserInfo(c *gin.Context) {
 // @Produce      json
 // @Param        cursor  query  int  false  "Cursor" default(0)
 // @Param        size    query  int  false  "Page size" default(20)
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
 // @Success      200     {object}  respond.Response{data=respond.UserInfoListResponse}
 // @Failure      500     {object}  respond.Response
-// @Router       /users [get]
+// @Router       /v1/users [get]
 func (h *IndexerQueryHandler) ListUserInfo(c *gin.Context) {
+	fields, ok := queryFields(c, respond.UserInfoFields)
+	if !ok {
+		return
+	}
 	// Get cursor and size parameters
 	cursorStr := c.DefaultQuery("cursor", "0")
 	sizeStr := c.DefaultQuery("size", "20")
@@ -988,7 +1370,7 @@ func (h *IndexerQueryHandler) ListUserInfo(c *gin.Context) {
 		return
 	}
 
-	respond.Success(c, respond.ToUserInfoListResponse(users, nextCursor, hasMore, total))
+	respond.Success(c, respond.SelectFields(respond.ToUserInfoListResponse(users, nextCursor, hasMore, total), fields))
 }
 
 // GetUserInfoHistory get user info history by MetaID or Address
@@ -1001,7 +1383,7 @@ func (h *IndexerQueryHandler) ListUserInfo(c *gin.Context) {
 // @Success      200  {object}  respond.Response{data=model.UserInfoHistory}
 // @Failure      404  {object}  respond.Response
 // @Failure      500  {object}  respond.Response
-// @Router       /users/history/{key} [get]
+// @Router       /v1/users/history/{key} [get]
 func (h *IndexerQueryHandler) GetUserInfoHistory(c *gin.Context) {
 	key := c.Param("key")
 	if key == "" {
@@ -1028,7 +1410,7 @@ func (h *IndexerQueryHandler) GetUserInfoHistory(c *gin.Context) {
 // @Success      200     {file}    binary  "Avatar content"
 // @Success      307     {string}  string  "Redirect to OSS URL"
 // @Failure      404     {object}  respond.Response
-// @Router       /users/metaid/{metaId}/avatar [get]
+// @Router       /v1/users/metaid/{metaId}/avatar [get]
 func (h *IndexerQueryHandler) GetAvatarContentByMetaID(c *gin.Context) {
 	metaID := c.Param("metaId")
 	if metaID == "" {
@@ -1057,10 +1439,10 @@ func (h *IndexerQueryHandler) GetAvatarContentByMetaID(c *gin.Context) {
 	}
 
 	// Set response headers
-	c.Header("Content-Type", contentType)
+	c.Header("Content-Type", contenttype.Header(contentType))
 	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", fileName))
 	c.Header("X-File-Type", fileType)
-	c.Data(200, contentType, content)
+	c.Data(200, contenttype.Header(contentType), content)
 }
 
 // GetAvatarContentByPinID get avatar content by avatar PIN ID
@@ -1072,7 +1454,7 @@ func (h *IndexerQueryHandler) GetAvatarContentByMetaID(c *gin.Context) {
 // @Param        pinId  path  string  true  "Avatar PIN ID"
 // @Success      200    {file}    binary  "Avatar content"
 // @Failure      404    {object}  respond.Response
-// @Router       /users/avatar/content/{pinId} [get]
+// @Router       /v1/users/avatar/content/{pinId} [get]
 func (h *IndexerQueryHandler) GetAvatarContentByPinID(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -1088,9 +1470,9 @@ func (h *IndexerQueryHandler) GetAvatarContentByPinID(c *gin.Context) {
 	}
 
 	// Set response headers
-	c.Header("Content-Type", contentType)
+	c.Header("Content-Type", contenttype.Header(contentType))
 	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", fileName))
-	c.Data(200, contentType, content)
+	c.Data(200, contenttype.Header(contentType), content)
 }
 
 // GetFastAvatarContentByPinID get accelerated avatar content redirect to OSS by avatar PIN ID
@@ -1104,7 +1486,7 @@ func (h *IndexerQueryHandler) GetAvatarContentByPinID(c *gin.Context) {
 // @Success      307         {string}  string  "Redirect to OSS URL"
 // @Failure      404         {object}  respond.Response
 // @Failure      500         {object}  respond.Response
-// @Router       /users/avatar/accelerate/{pinId} [get]
+// @Router       /v1/users/avatar/accelerate/{pinId} [get]
 func (h *IndexerQueryHandler) GetFastAvatarContentByPinID(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -1123,7 +1505,7 @@ func (h *IndexerQueryHandler) GetFastAvatarContentByPinID(c *gin.Context) {
 	}
 
 	// Set response headers
-	c.Header("Content-Type", contentType)
+	c.Header("Content-Type", contenttype.Header(contentType))
 	c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
 	c.Header("X-File-Type", fileType)
 
@@ -1141,7 +1523,7 @@ func (h *IndexerQueryHandler) GetFastAvatarContentByPinID(c *gin.Context) {
 // @Success      307    {string}  string  "Redirect to OSS URL with thumbnail processing"
 // @Failure      404    {object}  respond.Response
 // @Failure      500    {object}  respond.Response
-// @Router       /thumbnail/{pinId} [get]
+// @Router       /v1/thumbnail/{pinId} [get]
 func (h *IndexerQueryHandler) GetAvatarThumbnailByPinID(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -1157,7 +1539,7 @@ func (h *IndexerQueryHandler) GetAvatarThumbnailByPinID(c *gin.Context) {
 	}
 
 	// Set response headers
-	c.Header("Content-Type", contentType)
+	c.Header("Content-Type", contenttype.Header(contentType))
 	c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
 	c.Header("X-File-Type", fileType)
 
@@ -1176,7 +1558,7 @@ func (h *IndexerQueryHandler) GetAvatarThumbnailByPinID(c *gin.Context) {
 // @Success      307         {string}  string  "Redirect to OSS URL"
 // @Failure      404         {object}  respond.Response
 // @Failure      500         {object}  respond.Response
-// @Router       /files/accelerate/content/latest/{firstPinId} [get]
+// @Router       /v1/files/accelerate/content/latest/{firstPinId} [get]
 func (h *IndexerQueryHandler) GetLatestFastFileContentByFirstPinID(c *gin.Context) {
 	firstPinID := c.Param("firstPinId")
 	if firstPinID == "" {
@@ -1190,7 +1572,7 @@ func (h *IndexerQueryHandler) GetLatestFastFileContentByFirstPinID(c *gin.Contex
 	// Get OSS URL, ContentType, FileName, and FileType for latest file
 	ossURL, contentType, fileName, fileType, err := h.indexerFileService.GetLatestFastFileOSSURLByFirstPinID(firstPinID, processType)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respond.ContentError(c, err)
 		return
 	}
 
@@ -1199,7 +1581,7 @@ func (h *IndexerQueryHandler) GetLatestFastFileContentByFirstPinID(c *gin.Contex
 	shouldPreview := fileType == "image" || fileType == "video" || fileType == "audio" || fileType == "text"
 
 	// Set response headers
-	c.Header("Content-Type", contentType)
+	c.Header("Content-Type", contenttype.Header(contentType))
 	if shouldPreview {
 		c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
 	} else {
@@ -1221,7 +1603,7 @@ func (h *IndexerQueryHandler) GetLatestFastFileContentByFirstPinID(c *gin.Contex
 // @Success      307         {string}  string  "Redirect to OSS URL"
 // @Failure      404         {object}  respond.Response
 // @Failure      500         {object}  respond.Response
-// @Router       /files/accelerate/content/{pinId} [get]
+// @Router       /v1/files/accelerate/content/{pinId} [get]
 func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 	pinID := c.Param("pinId")
 	if pinID == "" {
@@ -1235,7 +1617,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 	// Get OSS URL, ContentType, FileName, and FileType
 	ossURL, contentType, fileName, fileType, err := h.indexerFileService.GetFastFileOSSURL(pinID, processType)
 	if err != nil {
-		respond.NotFound(c, err.Error())
+		respond.ContentError(c, err)
 		return
 	}
 
@@ -1244,7 +1626,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 	shouldPreview := fileType == "image" || fileType == "video" || fileType == "audio" || fileType == "text"
 
 	// Set response headers
-	c.Header("Content-Type", contentType)
+	c.Header("Content-Type", contenttype.Header(contentType))
 	if shouldPreview {
 		c.Header("Content-Disposition", "inline; filename=\""+fileName+"\"")
 	} else {
@@ -1266,7 +1648,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 // // @Success      307         {string}  string  "Redirect to OSS URL"
 // // @Failure      404         {object}  respond.Response
 // // @Failure      500         {object}  respond.Response
-// // @Router       /avatars/accelerate/content/{pinId} [get]
+// // @Router       /v1/avatars/accelerate/content/{pinId} [get]
 // func (h *IndexerQueryHandler) GetFastAvatarContent(c *gin.Context) {
 // 	pinID := c.Param("pinId")
 // 	if pinID == "" {
@@ -1299,7 +1681,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 // // @Success      307         {string}  string  "Redirect to OSS URL"
 // // @Failure      404         {object}  respond.Response
 // // @Failure      500         {object}  respond.Response
-// // @Router       /avatars/accelerate/metaid/{metaId} [get]
+// // @Router       /v1/avatars/accelerate/metaid/{metaId} [get]
 // func (h *IndexerQueryHandler) GetFastAvatarByMetaID(c *gin.Context) {
 // 	metaID := c.Param("metaId")
 // 	if metaID == "" {
@@ -1332,7 +1714,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 // // @Success      307         {string}  string  "Redirect to OSS URL"
 // // @Failure      404         {object}  respond.Response
 // // @Failure      500         {object}  respond.Response
-// // @Router       /avatars/accelerate/address/{address} [get]
+// // @Router       /v1/avatars/accelerate/address/{address} [get]
 // func (h *IndexerQueryHandler) GetFastAvatarByAddress(c *gin.Context) {
 // 	address := c.Param("address")
 // 	if address == "" {
@@ -1356,7 +1738,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 
 // RescanBlocks trigger asynchronous block rescan
 // @Summary      Rescan blocks
-// @Description  Trigger asynchronous rescan of blocks within specified height range for a specific chain
+// @Description  Trigger asynchronous rescan of blocks within specified height range for a specific chain. Fails with 400 when start_height is below the earliest block a pruned node still has (see earliest_block_height in /status).
 // @Tags         Indexer Admin
 // @Accept       json
 // @Produce      json
@@ -1364,7 +1746,7 @@ func (h *IndexerQueryHandler) GetFastFileContent(c *gin.Context) {
 // @Success      200      {object}  respond.Response{data=respond.RescanResponse}
 // @Failure      400      {object}  respond.Response
 // @Failure      500      {object}  respond.Response
-// @Router       /admin/rescan [post]
+// @Router       /v1/admin/rescan [post]
 func (h *IndexerQueryHandler) RescanBlocks(c *gin.Context) {
 	// Check if indexer service is available
 	if h.indexerService == nil {
@@ -1392,6 +1774,10 @@ func (h *IndexerQueryHandler) RescanBlocks(c *gin.Context) {
 	// Trigger async rescan
 	taskID, err := h.indexerService.RescanBlocksAsync(req.Chain, req.StartHeight, req.EndHeight)
 	if err != nil {
+		if errors.Is(err, indexer_service.ErrBlockPruned) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		respond.ServerError(c, fmt.Sprintf("failed to start rescan: %v", err))
 		return
 	}
@@ -1416,7 +1802,7 @@ func (h *IndexerQueryHandler) RescanBlocks(c *gin.Context) {
 // @Produce      json
 // @Success      200      {object}  respond.Response{data=respond.RescanStatusResponse}
 // @Failure      500      {object}  respond.Response
-// @Router       /admin/rescan/status [get]
+// @Router       /v1/admin/rescan/status [get]
 func (h *IndexerQueryHandler) GetRescanStatus(c *gin.Context) {
 	// Check if indexer service is available
 	if h.indexerService == nil {
@@ -1438,6 +1824,12 @@ func (h *IndexerQueryHandler) GetRescanStatus(c *gin.Context) {
 		ProcessedBlocks: task.ProcessedBlocks,
 		TotalBlocks:     task.TotalBlocks,
 		ErrorMessage:    task.ErrorMessage,
+		IndexedPins:     task.Counts.IndexedPins,
+		DuplicatePins:   task.Counts.DuplicatePins,
+		SkippedPins:     task.Counts.SkippedPins,
+		RejectedPins:    task.Counts.RejectedPins,
+		FailedPins:      task.Counts.FailedPins,
+		FailedBlocks:    task.Counts.FailedBlocks,
 	}
 
 	// Calculate progress, speed and time estimates
@@ -1463,6 +1855,300 @@ func (h *IndexerQueryHandler) GetRescanStatus(c *gin.Context) {
 	respond.Success(c, response)
 }
 
+// GetRescanReport download the per-PIN report of a rescan task
+// @Summary      Download rescan report
+// @Description  Downloads the per-block and per-PIN outcomes (indexed, skipped_duplicate, skipped, rejected, failed with reason) of the running rescan or one of the last 10 finished ones. The report is served as an attachment, as JSON or as CSV with one row per PIN and per failed block
+// @Tags         Indexer Admin
+// @Produce      json,text/csv
+// @Param        taskId  path      string  true   "Rescan task ID"
+// @Param        format  query     string  false  "Report format"  Enums(json, csv)  default(json)
+// @Success      200     {object}  indexer_service.RescanReport
+// @Failure      400     {object}  respond.Response
+// @Failure      404     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Router       /v1/admin/rescan/{taskId}/report [get]
+func (h *IndexerQueryHandler) GetRescanReport(c *gin.Context) {
+	if h.indexerService == nil {
+		respond.ServerError(c, "indexer service not available")
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		respond.InvalidParam(c, "format must be json or csv")
+		return
+	}
+
+	taskID := c.Param("taskId")
+	report, err := h.indexerService.GetRescanReport(taskID)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrRescanReportNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", taskID+"."+format))
+	if format == "csv" {
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", report.CSV())
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetPendingStorage list blobs awaiting storage
+// @Summary      List files awaiting storage
+// @Description  Lists, oldest first, the files and chunks indexed while the storage backend refused writes. Their records have status pending_storage and their content is served from the retry queue until the background retrier (indexer.storage_retry) stores it
+// @Tags         Indexer Admin
+// @Produce      json
+// @Success      200  {object}  respond.Response{data=respond.PendingStorageResponse}
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/admin/storage/pending [get]
+func (h *IndexerQueryHandler) GetPendingStorage(c *gin.Context) {
+	if h.indexerService == nil {
+		respond.ServerError(c, "indexer service not available")
+		return
+	}
+
+	writes, err := h.indexerService.ListPendingStorageWrites()
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	response := respond.PendingStorageResponse{
+		Total:  len(writes),
+		Writes: make([]respond.PendingStorageWriteResponse, 0, len(writes)),
+	}
+	for _, w := range writes {
+		response.Writes = append(response.Writes, respond.PendingStorageWriteResponse{
+			StoragePath: w.StoragePath,
+			PinID:       w.PinID,
+			ChainName:   w.ChainName,
+			Kind:        w.Kind,
+			Size:        w.Size,
+			Sha256:      w.Sha256,
+			Attempts:    w.Attempts,
+			LastError:   w.LastError,
+			NextRetryAt: w.NextRetryAt,
+			CreatedAt:   w.CreatedAt.Unix(),
+		})
+	}
+	respond.Success(c, response)
+}
+
+// GetPendingCreators list queued creator lookups
+// @Summary      List queued creator lookups
+// @Description  Lists, oldest first, the files whose creator address could not be looked up while indexing. They are served with the fallback address until the background retrier (indexer.creator_retry) resolves it; a lookup that fails max_attempts times is dropped and the file keeps the fallback address
+// @Tags         Indexer Admin
+// @Produce      json
+// @Success      200  {object}  respond.Response{data=respond.PendingCreatorResponse}
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/admin/creator/pending [get]
+func (h *IndexerQueryHandler) GetPendingCreators(c *gin.Context) {
+	if h.indexerService == nil {
+		respond.ServerError(c, "indexer service not available")
+		return
+	}
+
+	queued, err := h.indexerService.ListPendingCreatorResolutions()
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	response := respond.PendingCreatorResponse{
+		Total:   len(queued),
+		Lookups: make([]respond.PendingCreatorLookupResponse, 0, len(queued)),
+	}
+	for _, q := range queued {
+		response.Lookups = append(response.Lookups, respond.PendingCreatorLookupResponse{
+			PinID:           q.PinID,
+			ChainName:       q.ChainName,
+			FallbackAddress: q.FallbackAddress,
+			Attempts:        q.Attempts,
+			LastError:       q.LastError,
+			NextRetryAt:     q.NextRetryAt,
+			CreatedAt:       q.CreatedAt.Unix(),
+		})
+	}
+	respond.Success(c, response)
+}
+
+// PauseChain pause scanning of a chain
+// @Summary      Pause chain
+// @Description  Stops scanning new blocks and mempool transactions of a chain until it is resumed; the block being scanned is finished first. Rescans still run. Mempool transactions seen while paused are indexed with their blocks. The pause is kept in memory, so a restarted indexer scans again; /status reports paused chains
+// @Tags         Indexer Admin
+// @Produce      json
+// @Param        chain  path      string  true  "Chain name"  example(btc)
+// @Success      200    {object}  respond.Response{data=respond.ChainPauseResponse}
+// @Failure      400    {object}  respond.Response
+// @Failure      500    {object}  respond.Response
+// @Router       /v1/admin/chains/{chain}/pause [post]
+func (h *IndexerQueryHandler) PauseChain(c *gin.Context) {
+	h.setChainPaused(c, true)
+}
+
+// ResumeChain resume scanning of a paused chain
+// @Summary      Resume chain
+// @Description  Resumes scanning of a chain paused with /admin/chains/{chain}/pause. Resuming a chain that is not paused is a no-op; a pause for low disk space (indexer.disk_watchdog) is not lifted
+// @Tags         Indexer Admin
+// @Produce      json
+// @Param        chain  path      string  true  "Chain name"  example(btc)
+// @Success      200    {object}  respond.Response{data=respond.ChainPauseResponse}
+// @Failure      400    {object}  respond.Response
+// @Failure      500    {object}  respond.Response
+// @Router       /v1/admin/chains/{chain}/resume [post]
+func (h *IndexerQueryHandler) ResumeChain(c *gin.Context) {
+	h.setChainPaused(c, false)
+}
+
+// setChainPaused pauses or resumes the chain of the request and answers its
+// scanning state
+func (h *IndexerQueryHandler) setChainPaused(c *gin.Context, paused bool) {
+	if h.indexerService == nil {
+		respond.ServerError(c, "indexer service not available")
+		return
+	}
+
+	chain := strings.ToLower(c.Param("chain"))
+	var err error
+	if paused {
+		err = h.indexerService.PauseChain(chain)
+	} else {
+		err = h.indexerService.ResumeChain(chain)
+	}
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrUnknownChain) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	response := respond.ChainPauseResponse{Chain: chain}
+	if since, ok := h.indexerService.ChainPausedAt(chain); ok {
+		response.Paused = true
+		response.PausedAt = since.Unix()
+	}
+	respond.Success(c, response)
+}
+
+// GetStorageMigration get storage backend migration progress
+// @Summary      Get storage migration progress
+// @Description  Progress of copying indexed blobs to storage.migration.target. Records are flipped to the target storage type once their copy is hash-verified; when status is completed, set storage.type to the target and disable the migration
+// @Tags         Indexer Admin
+// @Produce      json
+// @Success      200  {object}  respond.Response{data=respond.StorageMigrationResponse}
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/admin/storage-migration [get]
+func (h *IndexerQueryHandler) GetStorageMigration(c *gin.Context) {
+	if h.indexerService == nil {
+		respond.ServerError(c, "indexer service not available")
+		return
+	}
+
+	progress, enabled := h.indexerService.GetStorageMigrationProgress()
+	if !enabled {
+		respond.Success(c, respond.StorageMigrationResponse{Enabled: false})
+		return
+	}
+
+	response := respond.StorageMigrationResponse{
+		Enabled:     true,
+		Source:      progress.Source,
+		Target:      progress.Target,
+		Status:      "waiting",
+		Passes:      progress.Passes,
+		Total:       progress.Total,
+		Migrated:    progress.Migrated,
+		Pending:     progress.Pending(),
+		Failed:      progress.Failed,
+		LastError:   progress.LastError,
+		StartedAt:   progress.StartedAt,
+		LastPassAt:  progress.LastPassAt,
+		CompletedAt: progress.CompletedAt,
+	}
+	if progress.Running {
+		response.Status = "running"
+	} else if progress.CompletedAt > 0 {
+		response.Status = "completed"
+	}
+	if progress.Total > 0 {
+		response.Progress = float64(progress.Migrated) / float64(progress.Total) * 100
+	} else if progress.Passes > 0 {
+		response.Progress = 100
+	}
+
+	respond.Success(c, response)
+}
+
+// GetCounters list maintained counters
+// @Summary      List counters
+// @Description  List the counters maintained per block (files, chunks, users; total, per chain and per creator), optionally filtered by name prefix
+// @Tags         Indexer Admin
+// @Produce      json
+// @Param        prefix  query     string  false  "Counter name prefix, e.g. files:chain:"
+// @Success      200     {object}  respond.Response{data=respond.CountersResponse}
+// @Failure      500     {object}  respond.Response
+// @Router       /v1/admin/counters [get]
+func (h *IndexerQueryHandler) GetCounters(c *gin.Context) {
+	counters, err := h.indexerFileService.ListCounters(c.Query("prefix"))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, respond.CountersResponse{Counters: counters})
+}
+
+// ReconcileCounters recount maintained counters
+// @Summary      Reconcile counters
+// @Description  Recount files, chunks and users from the indexes and report counters that drifted from the maintained values. With fix=true the recounted values are stored
+// @Tags         Indexer Admin
+// @Produce      json
+// @Param        fix  query     bool  false  "Store the recounted values"  default(false)
+// @Success      200  {object}  respond.Response{data=respond.CountersReconcileResponse}
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/admin/counters/reconcile [post]
+func (h *IndexerQueryHandler) ReconcileCounters(c *gin.Context) {
+	fix := c.Query("fix") == "true"
+	drifts, err := h.indexerFileService.ReconcileCounters(fix)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, respond.CountersReconcileResponse{Drifts: drifts, Fixed: fix && len(drifts) > 0})
+}
+
+// RepairLatestIndexes check and repair the latest_* collections
+// @Summary      Repair latest entries
+// @Description  Recompute the latest version of every file (latest_file_info, from all indexed PINs) and every user info (name, avatar, bio, chat public key; by MetaID and by GlobalMetaID, from their history) and report entries that differ, e.g. after blocks arrived out of order. The latest version has the latest timestamp; ties go to the higher block height, then the larger PIN ID. With fix=true the entries are rewritten; entries whose history is gone are only reported
+// @Tags         Indexer Admin
+// @Produce      json
+// @Param        fix  query     bool  false  "Rewrite the entries that drifted"  default(false)
+// @Success      200  {object}  respond.Response{data=respond.LatestRepairResponse}
+// @Failure      500  {object}  respond.Response
+// @Router       /v1/admin/latest/repair [post]
+func (h *IndexerQueryHandler) RepairLatestIndexes(c *gin.Context) {
+	fix := c.Query("fix") == "true"
+	drifts, err := h.indexerFileService.RepairLatestIndexes(fix)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	fixed := false
+	for _, drift := range drifts {
+		if fix && drift.Actual != "" {
+			fixed = true
+			break
+		}
+	}
+	respond.Success(c, respond.LatestRepairResponse{Drifts: drifts, Fixed: fixed})
+}
+
 // StopRescan stop the current rescan task
 // @Summary      Stop rescan
 // @Description  Stop the current rescan task
@@ -1472,7 +2158,7 @@ func (h *IndexerQueryHandler) GetRescanStatus(c *gin.Context) {
 // @Success      200      {object}  respond.Response{data=respond.RescanStopResponse}
 // @Failure      400      {object}  respond.Response
 // @Failure      500      {object}  respond.Response
-// @Router       /admin/rescan/stop [post]
+// @Router       /v1/admin/rescan/stop [post]
 func (h *IndexerQueryHandler) StopRescan(c *gin.Context) {
 	// Check if indexer service is available
 	if h.indexerService == nil {
@@ -2,10 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"meta-file-system/service/indexer_service"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -56,3 +60,42 @@ func TestGetFilesByKeywordAndExtensionValidation(t *testing.T) {
 		}
 	})
 }
+
+func TestGetFilesByPinIDsValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := make([]string, indexer_service.MaxBatchPinIDs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("pin%di0", i)
+	}
+	tooManyBody, _ := json.Marshal(map[string]any{"pin_ids": tooMany})
+
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"malformed body", `{"pin_ids":`},
+		{"missing pin_ids", `{}`},
+		{"empty pin_ids", `{"pin_ids":[]}`},
+		{"too many pin_ids", string(tooManyBody)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/files/batch", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler := &IndexerQueryHandler{}
+			handler.GetFilesByPinIDs(c)
+
+			var resp map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if code, ok := resp["code"].(float64); !ok || int(code) != 40000 {
+				t.Fatalf("code = %v, want 40000", resp["code"])
+			}
+		})
+	}
+}
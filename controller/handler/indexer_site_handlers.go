@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/service/indexer_service"
+)
+
+// ServeSite serves a static website described by a site manifest PIN.
+//
+// GET /site/{manifestPinId}/{path} resolves path against the manifest: "/"
+// and directories serve their index.html, unknown paths fall back to the
+// root index.html for single-page apps (spa) or to 404.html with status 404.
+// A directory requested without its trailing slash is redirected to it, so
+// relative links in the page resolve under the directory. Manifest and files
+// are immutable PINs and are served with an immutable Cache-Control.
+func (h *IndexerQueryHandler) ServeSite(c *gin.Context) {
+	manifestPinID := c.Param("manifestPinId")
+	reqPath := c.Param("path")
+
+	site, isDir, err := h.indexerFileService.GetSiteFile(manifestPinID, reqPath)
+	if err != nil {
+		if errors.Is(err, metaid_protocols.ErrInvalidSiteManifest) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		// Provisional file (code 42500), else unknown path
+		// (indexer_service.ErrSiteNotFound), manifest or file not indexed
+		respond.ContentError(c, err)
+		return
+	}
+	if isDir {
+		target := "/site/" + manifestPinID + reqPath + "/"
+		if c.Request.URL.RawQuery != "" {
+			target += "?" + c.Request.URL.RawQuery
+		}
+		c.Redirect(http.StatusMovedPermanently, target)
+		return
+	}
+
+	// The same path always maps to the same PIN
+	writeSiteContent(c, site, "public, max-age=31536000, immutable")
+}
+
+// writeSiteContent writes a resolved site file with cacheControl and an ETag;
+// 404.html is served with status 404
+func writeSiteContent(c *gin.Context, site *indexer_service.SiteContent, cacheControl string) {
+	status := http.StatusOK
+	if site.NotFound {
+		status = http.StatusNotFound
+	} else {
+		c.Header("Cache-Control", cacheControl)
+		if site.File.FileHash != "" {
+			etag := "\"" + site.File.FileHash + "\""
+			c.Header("ETag", etag)
+			if strings.Contains(c.GetHeader("If-None-Match"), etag) {
+				c.Status(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	c.Data(status, site.ContentType, site.Content)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/service/indexer_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPathTree list one directory of a creator's files by MetaID path
+// @Summary      Path tree of a creator's files
+// @Description  Virtual filesystem view of a creator's files built from their MetaID paths: the subdirectories of path with per-directory file counts and total sizes, and the files directly in path. A path ending in the file name places the file in its parent directory. Only the latest version of each file is shown and revoked files are left out; truncated is set when the subtree has more files than the view reads (counts are then partial)
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        metaidOrGlobalMetaId  path      string  true   "Creator MetaID or GlobalMetaID"
+// @Param        path                  query     string  false  "Directory to list, e.g. /file/photos"  default(/)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200                   {object}  respond.Response{data=respond.IndexerPathTreeResponse}
+// @Failure      400                   {object}  respond.Response
+// @Failure      500                   {object}  respond.Response
+// @Router       /v1/files/metaid/{metaidOrGlobalMetaId}/tree [get]
+func (h *IndexerQueryHandler) GetPathTree(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	metaidOrGlobalMetaId := c.Param("metaidOrGlobalMetaId")
+	if metaidOrGlobalMetaId == "" {
+		respond.InvalidParam(c, "metaidOrGlobalMetaId is required")
+		return
+	}
+
+	tree, err := h.indexerFileService.GetPathTree(metaidOrGlobalMetaId, c.DefaultQuery("path", "/"))
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidTreePath) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, respond.SelectFields(respond.ToIndexerPathTreeResponse(tree, h.indexerFileService, getIndexerBaseUrl()), fields))
+}
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service"
+	"meta-file-system/service/indexer_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v2 list handlers: the v1 listings answered with the shared page envelope
+// (respond.IndexerFilePage etc.) and an opaque string cursor.
+
+// numericCursor parses a numeric v2 cursor (an offset, ID, timestamp or seq
+// depending on the listing; empty = first page); answers 400 and returns
+// false when it is malformed
+func numericCursor(c *gin.Context) (int64, bool) {
+	raw := c.Query("cursor")
+	if raw == "" {
+		return 0, true
+	}
+	cursor, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || cursor < 0 {
+		respond.InvalidParam(c, "invalid cursor")
+		return 0, false
+	}
+	return cursor, true
+}
+
+// ListFilesV2 get file list (v2 page)
+// @Summary      Query file list (v2)
+// @Description  All indexed files, newest first, as a v2 page; the total file count is included with includeUnconfirmed=true
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        size    query     int     false  "Page size (max 100)"  default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200     {object}  respond.Response{data=respond.IndexerFilePage}
+// @Failure      400     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Router       /v2/files [get]
+func (h *IndexerQueryHandler) ListFilesV2(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	files, nextCursor, hasMore, err := h.indexerFileService.ListFiles(cursor, size, includeUnconfirmed(c))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	// The file count includes mempool files, so it is only reported when they are listed
+	var total *int64
+	if includeUnconfirmed(c) {
+		if count, err := h.indexerFileService.GetFilesCount(); err == nil {
+			total = &count
+		}
+	}
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFilePage(files, respond.PageCursor(nextCursor, hasMore), hasMore, total, h.indexerFileService, getIndexerBaseUrl()), fields))
+}
+
+// GetByCreatorAddressV2 get file list by creator address (v2 page)
+// @Summary      Get files by creator address (v2)
+// @Description  Files of a creator address, newest first, as a v2 page
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        address  path      string  true   "Creator address, or an ID address for the creator's files on every chain"
+// @Param        cursor   query     string  false  "nextCursor of the previous page"
+// @Param        size     query     int     false  "Page size (max 100)"  default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200      {object}  respond.Response{data=respond.IndexerFilePage}
+// @Failure      400      {object}  respond.Response
+// @Failure      500      {object}  respond.Response
+// @Router       /v2/files/creator/{address} [get]
+func (h *IndexerQueryHandler) GetByCreatorAddressV2(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	address := c.Param("address")
+	if address == "" {
+		respond.InvalidParam(c, "address is required")
+		return
+	}
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	files, nextCursor, hasMore, err := h.indexerFileService.GetFilesByCreatorAddress(address, cursor, size, includeUnconfirmed(c))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFilePage(files, respond.PageCursor(nextCursor, hasMore), hasMore, nil, h.indexerFileService, getIndexerBaseUrl()), fields))
+}
+
+// GetByCreatorMetaIDV2 get file list by creator MetaID or GlobalMetaID (v2 page)
+// @Summary      Get files by creator MetaID or GlobalMetaID (v2)
+// @Description  Files of a creator MetaID or GlobalMetaID, newest first, as a v2 page; same filters as the v1 route
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        metaidOrGlobalMetaId  path      string  true   "Creator MetaID or GlobalMetaID"
+// @Param        cursor                query     string  false  "nextCursor of the previous page"
+// @Param        size                  query     int     false  "Page size (max 100)"  default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        file_type             query     string  false  "File type: image/video/audio/document/other"
+// @Param        content_type          query     string  false  "Content type prefix, e.g. image/ or image/png"
+// @Param        path_prefix           query     string  false  "MetaID path prefix, matched on whole segments, e.g. /file/photos"
+// @Param        chain                 query     string  false  "Chain name: btc/mvc/doge"
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200                   {object}  respond.Response{data=respond.IndexerFilePage}
+// @Failure      400                   {object}  respond.Response
+// @Failure      500                   {object}  respond.Response
+// @Router       /v2/files/metaid/{metaidOrGlobalMetaId} [get]
+func (h *IndexerQueryHandler) GetByCreatorMetaIDV2(c *gin.Context) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	metaidOrGlobalMetaId := c.Param("metaidOrGlobalMetaId")
+	if metaidOrGlobalMetaId == "" {
+		respond.InvalidParam(c, "metaidOrGlobalMetaId is required")
+		return
+	}
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	filter, err := indexer_service.NormalizeFileFilter(model.IndexerFileFilter{
+		FileType:          c.Query("file_type"),
+		ContentTypePrefix: c.Query("content_type"),
+		PathPrefix:        c.Query("path_prefix"),
+		ChainName:         c.Query("chain"),
+	})
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	var files []*model.IndexerFile
+	var nextCursor int64
+	var hasMore bool
+	if common_service.IsGlobalMetaId(metaidOrGlobalMetaId) {
+		files, nextCursor, hasMore, err = h.indexerFileService.GetFilesByCreatorGlobalMetaID(metaidOrGlobalMetaId, filter, cursor, size, includeUnconfirmed(c))
+	} else {
+		files, nextCursor, hasMore, err = h.indexerFileService.GetFilesByCreatorMetaID(metaidOrGlobalMetaId, filter, cursor, size, includeUnconfirmed(c))
+	}
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFilePage(files, respond.PageCursor(nextCursor, hasMore), hasMore, nil, h.indexerFileService, getIndexerBaseUrl()), fields))
+}
+
+// GetFilesByExtensionV2 get file list by file extension (v2 page)
+// @Summary      Get files by extension (v2)
+// @Description  Files with the given extension(s), newest first, as a v2 page
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        extension  query     []string  true   "File extension(s), supports multi (extension=.jpg&extension=.png) and csv (extension=.jpg,.png)"
+// @Param        cursor     query     string    false  "nextCursor of the previous page"
+// @Param        size       query     int       false  "Page size (max 100)"  default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200        {object}  respond.Response{data=respond.IndexerFilePage}
+// @Failure      400        {object}  respond.Response
+// @Failure      500        {object}  respond.Response
+// @Router       /v2/files/extension [get]
+func (h *IndexerQueryHandler) GetFilesByExtensionV2(c *gin.Context) {
+	h.listFilesByExtensionsV2(c, func(ext, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+		return h.indexerFileService.ListFilesByExtension(ext, cursor, size, includeUnconfirmed(c))
+	})
+}
+
+// GetFilesByGlobalMetaIDAndExtensionV2 get file list by globalMetaID and file extension (v2 page)
+// @Summary      Get files by globalMetaID and extension (v2)
+// @Description  Files of a globalMetaID with the given extension(s), newest first, as a v2 page
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        metaidOrGlobalMetaId  path      string    true   "Global MetaID"
+// @Param        extension             query     []string  true   "File extension(s), supports multi (extension=.jpg&extension=.png) and csv (extension=.jpg,.png)"
+// @Param        cursor                query     string    false  "nextCursor of the previous page"
+// @Param        size                  query     int       false  "Page size (max 100)"  default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200                   {object}  respond.Response{data=respond.IndexerFilePage}
+// @Failure      400                   {object}  respond.Response
+// @Failure      500                   {object}  respond.Response
+// @Router       /v2/files/metaid/{metaidOrGlobalMetaId}/extension [get]
+func (h *IndexerQueryHandler) GetFilesByGlobalMetaIDAndExtensionV2(c *gin.Context) {
+	globalMetaID := c.Param("metaidOrGlobalMetaId")
+	if globalMetaID == "" {
+		respond.InvalidParam(c, "metaidOrGlobalMetaId is required")
+		return
+	}
+	h.listFilesByExtensionsV2(c, func(ext, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+		return h.indexerFileService.ListFilesByGlobalMetaIDAndExtension(globalMetaID, ext, cursor, size, includeUnconfirmed(c))
+	})
+}
+
+// GetFilesByKeywordAndExtensionV2 get file list by keyword and file extension (v2 page)
+// @Summary      Get files by keyword and extension (v2)
+// @Description  Files whose base name contains keyword, with the given extension(s), newest first, as a v2 page
+// @Tags         Indexer File Query
+// @Produce      json
+// @Param        keyword    path      string    true   "Keyword contained in file base name"
+// @Param        extension  query     []string  true   "File extension(s), supports multi (extension=.jpg&extension=.png) and csv (extension=.jpg,.png)"
+// @Param        cursor     query     string    false  "nextCursor of the previous page"
+// @Param        size       query     int       false  "Page size (max 100)"  default(20)
+// @Param        includeUnconfirmed  query  bool  false  "Also list mempool-only (unconfirmed) files"  default(false)
+// @Param        fields  query  string  false  "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url"
+// @Success      200        {object}  respond.Response{data=respond.IndexerFilePage}
+// @Failure      400        {object}  respond.Response
+// @Failure      500        {object}  respond.Response
+// @Router       /v2/files/keyword/{keyword}/extension [get]
+func (h *IndexerQueryHandler) GetFilesByKeywordAndExtensionV2(c *gin.Context) {
+	keyword := c.Param("keyword")
+	if keyword == "" {
+		respond.InvalidParam(c, "keyword is required")
+		return
+	}
+	h.listFilesByExtensionsV2(c, func(ext, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+		return h.indexerFileService.ListFilesByKeywordAndExtension(keyword, ext, cursor, size, includeUnconfirmed(c))
+	})
+}
+
+// listFilesByExtensionsV2 answers an extension listing as a v2 page; the
+// cursor is the v1 16-digit timestamp
+func (h *IndexerQueryHandler) listFilesByExtensionsV2(c *gin.Context, list extensionLister) {
+	fields, ok := queryFields(c, respond.IndexerFileFields)
+	if !ok {
+		return
+	}
+	extensions := parseExtensionsQuery(c)
+	if len(extensions) == 0 {
+		respond.InvalidParam(c, "extension is required (query, supports: extension=.jpg&extension=.png or extension=.jpg,.png)")
+		return
+	}
+	files, nextTimestamp, hasMore, err := listFilesByExtensions(list, extensions, c.Query("cursor"), extensionPageSize(c))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if !hasMore {
+		nextTimestamp = ""
+	}
+	respond.Success(c, respond.SelectFields(respond.ToIndexerFilePage(files, nextTimestamp, hasMore, nil, h.indexerFileService, getIndexerBaseUrl()), fields))
+}
+
+// ListUserInfoV2 get user info list (v2 page)
+// @Summary      Query user info list (v2)
+// @Description  Users ordered by latest activity, as a v2 page with the total user count
+// @Tags         Indexer User Info
+// @Produce      json
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        size    query     int     false  "Page size (max 100)"  default(20)
+// @Param        fields  query  string  false  "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar"
+// @Success      200     {object}  respond.Response{data=respond.UserInfoPage}
+// @Failure      400     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Router       /v2/users [get]
+func (h *IndexerQueryHandler) ListUserInfoV2(c *gin.Context) {
+	fields, ok := queryFields(c, respond.UserInfoFields)
+	if !ok {
+		return
+	}
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", "20"))
+
+	users, nextCursor, hasMore, total, err := h.indexerFileService.GetUserInfoList(cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if users == nil {
+		users = []*model.IndexerUserInfo{}
+	}
+
+	respond.Success(c, respond.SelectFields(respond.UserInfoPage{
+		Items:      respond.WithIDAddresses(users),
+		NextCursor: respond.PageCursor(nextCursor, hasMore),
+		HasMore:    hasMore,
+		Total:      &total,
+	}, fields))
+}
+
+// ListWatchEventsV2 list recorded events of a watched target (v2 page)
+// @Summary      List watch events (v2)
+// @Description  Events (PINs) of a watched address, MetaID or GlobalMetaID, oldest first, as a v2 page. Poll with cursor = nextCursor to receive only new events
+// @Tags         Indexer Watchlist
+// @Produce      json
+// @Param        target  query     string  true   "Address, MetaID or GlobalMetaID"
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        size    query     int     false  "Page size (max 100)"  default(20)
+// @Success      200     {object}  respond.Response{data=respond.IndexerWatchEventPage}
+// @Failure      400     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Router       /v2/watchlist/events [get]
+func (h *IndexerQueryHandler) ListWatchEventsV2(c *gin.Context) {
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(indexer_service.DefaultWatchEvents)))
+
+	events, nextCursor, hasMore, err := h.indexerFileService.ListWatchEvents(c.Query("target"), cursor, size)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidWatch) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if events == nil {
+		events = []*model.IndexerWatchEvent{}
+	}
+	respond.Success(c, respond.IndexerWatchEventPage{Items: events, NextCursor: respond.FeedCursor(nextCursor), HasMore: hasMore})
+}
+
+// GetChangesV2 list change log events after a cursor (v2 page)
+// @Summary      Change feed (v2)
+// @Description  Ordered log of indexing actions as a v2 page (see GET /v1/changes). Poll with cursor = nextCursor to receive only new events
+// @Tags         Indexer Changes
+// @Produce      json
+// @Param        cursor  query     string  false  "nextCursor of the previous page"
+// @Param        size    query     int     false  "Page size (max 1000)"  default(100)
+// @Success      200     {object}  respond.Response{data=respond.IndexerChangePage}
+// @Failure      400     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Router       /v2/changes [get]
+func (h *IndexerQueryHandler) GetChangesV2(c *gin.Context) {
+	since, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(indexer_service.DefaultChangeEvents)))
+
+	events, nextSince, hasMore, err := h.indexerFileService.ListChanges(since, size)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidChangeCursor) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if events == nil {
+		events = []*model.IndexerChangeEvent{}
+	}
+	respond.Success(c, respond.IndexerChangePage{Items: events, NextCursor: respond.FeedCursor(nextSince), HasMore: hasMore})
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/service/indexer_service"
+)
+
+// CreateWatch add an address or MetaID to the watchlist
+// @Summary      Add watchlist entry
+// @Description  Watch an address, MetaID or GlobalMetaID (admin; requires indexer.admin_enabled). Every PIN it creates is recorded as an event (when seen and again when confirmed), POSTed to webhook_url if set, and pushed to WebSocket subscribers. webhook_url must resolve to a public address; each client IP may hold up to 100 watches, out of 10000 in total
+// @Tags         Indexer Watchlist
+// @Accept       json
+// @Produce      json
+// @Param        request  body      respond.IndexerWatchRequest  true  "Watch request"
+// @Success      200      {object}  respond.Response{data=model.IndexerWatch}
+// @Failure      400      {object}  respond.Response
+// @Failure      500      {object}  respond.Response
+// @Router       /v1/admin/watchlist [post]
+func (h *IndexerQueryHandler) CreateWatch(c *gin.Context) {
+	var req respond.IndexerWatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, fmt.Sprintf("invalid request parameters: %v", err))
+		return
+	}
+
+	// The client IP is the connection address unless the request came
+	// through one of http.trusted_proxies, so it cannot be forged
+	watch, err := h.indexerFileService.CreateWatch(req.Target, req.WebhookURL, req.Label, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidWatch) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, watch)
+}
+
+// ListWatches list watchlist entries
+// @Summary      List watchlist
+// @Description  List watchlist entries, optionally only those of one target
+// @Tags         Indexer Watchlist
+// @Produce      json
+// @Param        target  query     string  false  "Address, MetaID or GlobalMetaID"
+// @Success      200     {object}  respond.Response{data=respond.IndexerWatchListResponse}
+// @Failure      500     {object}  respond.Response
+// @Router       /v1/watchlist [get]
+func (h *IndexerQueryHandler) ListWatches(c *gin.Context) {
+	watches, err := h.indexerFileService.ListWatches(c.Query("target"))
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if watches == nil {
+		watches = []*model.IndexerWatch{}
+	}
+	respond.Success(c, respond.IndexerWatchListResponse{Watches: watches})
+}
+
+// DeleteWatch remove a watchlist entry
+// @Summary      Remove watchlist entry
+// @Description  Remove a watchlist entry by ID (admin; requires indexer.admin_enabled); events already recorded are kept
+// @Tags         Indexer Watchlist
+// @Produce      json
+// @Param        id   path      int  true  "Watch ID"
+// @Success      200  {object}  respond.Response
+// @Failure      400  {object}  respond.Response
+// @Failure      404  {object}  respond.Response
+// @Router       /v1/admin/watchlist/{id} [delete]
+func (h *IndexerQueryHandler) DeleteWatch(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respond.InvalidParam(c, "id must be a positive integer")
+		return
+	}
+
+	if err := h.indexerFileService.DeleteWatch(id); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			respond.NotFound(c, "watch not found")
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, nil)
+}
+
+// ListWatchEvents list recorded events of a watched target
+// @Summary      List watch events
+// @Description  Events (PINs) of a watched address, MetaID or GlobalMetaID, oldest first. Poll with cursor = next_cursor to receive only new events
+// @Tags         Indexer Watchlist
+// @Produce      json
+// @Param        target  query     string  true   "Address, MetaID or GlobalMetaID"
+// @Param        cursor  query     int     false  "Return events with ID greater than cursor"  default(0)
+// @Param        size    query     int     false  "Page size (max 100)"                          default(20)
+// @Success      200     {object}  respond.Response{data=respond.IndexerWatchEventListResponse}
+// @Failure      400     {object}  respond.Response
+// @Failure      500     {object}  respond.Response
+// @Router       /v1/watchlist/events [get]
+func (h *IndexerQueryHandler) ListWatchEvents(c *gin.Context) {
+	cursor, _ := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	size, _ := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(indexer_service.DefaultWatchEvents)))
+
+	events, nextCursor, hasMore, err := h.indexerFileService.ListWatchEvents(c.Query("target"), cursor, size)
+	if err != nil {
+		if errors.Is(err, indexer_service.ErrInvalidWatch) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+	if events == nil {
+		events = []*model.IndexerWatchEvent{}
+	}
+	respond.Success(c, respond.IndexerWatchEventListResponse{Events: events, NextCursor: nextCursor, HasMore: hasMore})
+}
+
+// WatchEventsWebSocket stream events of targets over a WebSocket
+// @Summary      Watch events WebSocket
+// @Description  Upgrade to a WebSocket that receives a JSON model.IndexerWatchEvent for every new PIN of the given targets (comma-separated or repeated). Targets need not be on the watchlist; events of unwatched targets are streamed but not recorded
+// @Tags         Indexer Watchlist
+// @Param        target  query     []string  true  "Addresses, MetaIDs or GlobalMetaIDs"  collectionFormat(multi)
+// @Success      101     {object}  model.IndexerWatchEvent
+// @Failure      400     {object}  respond.Response
+// @Router       /v1/watchlist/ws [get]
+func (h *IndexerQueryHandler) WatchEventsWebSocket(c *gin.Context) {
+	sub, err := h.indexerFileService.SubscribeWatchEvents(c.QueryArray("target"))
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	defer h.indexerFileService.UnsubscribeWatchEvents(sub)
+
+	server := websocket.Server{
+		// Any origin is allowed, as for the rest of the API (see CORS config)
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+
+			// Clients do not send anything; reading only detects disconnects
+			closed := make(chan struct{})
+			go func() {
+				io.Copy(io.Discard, ws)
+				close(closed)
+			}()
+
+			for {
+				select {
+				case event, ok := <-sub.Events:
+					if !ok {
+						return
+					}
+					if err := websocket.JSON.Send(ws, event); err != nil {
+						return
+					}
+				case <-closed:
+					return
+				}
+			}
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
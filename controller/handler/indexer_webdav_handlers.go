@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/webdav"
+
+	"meta-file-system/service/indexer_service"
+)
+
+// webdavReadMethods methods served by the read-only WebDAV gateway
+var webdavReadMethods = []string{http.MethodOptions, http.MethodGet, http.MethodHead, "PROPFIND"}
+
+// WebDAVMethods every method routed to the WebDAV gateway; writes are
+// answered 405 so clients mount it read-only
+var WebDAVMethods = append(append([]string{}, webdavReadMethods...),
+	http.MethodPost, http.MethodPut, http.MethodDelete, "MKCOL", "COPY", "MOVE", "PROPPATCH", "LOCK", "UNLOCK")
+
+// WebDAV serves the read-only WebDAV gateway under indexer_service.WebDAVPrefix.
+//
+// /webdav/{metaidOrGlobalMetaId}/ is the root of a creator's files, arranged
+// by MetaID path as in GET /api/v1/files/metaid/{metaidOrGlobalMetaId}/tree,
+// so desktop systems can mount it as a network drive and copy files out.
+// Uploading needs the creator's signature, which WebDAV cannot carry, so the
+// gateway does not accept writes.
+func (h *IndexerQueryHandler) WebDAV() gin.HandlerFunc {
+	allow := strings.Join(webdavReadMethods, ", ")
+	dav := &webdav.Handler{
+		Prefix:     indexer_service.WebDAVPrefix,
+		FileSystem: indexer_service.NewWebDAVFileSystem(h.indexerFileService),
+		LockSystem: webdav.NewMemLS(),
+		Logger: func(r *http.Request, err error) {
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("WebDAV %s %s: %v", r.Method, r.URL.Path, err)
+			}
+		},
+	}
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodOptions:
+			// Advertise class 1 (no locking) and the read methods only
+			c.Header("Allow", allow)
+			c.Header("DAV", "1")
+			c.Header("MS-Author-Via", "DAV")
+			c.Status(http.StatusOK)
+		case http.MethodGet, http.MethodHead, "PROPFIND":
+			dav.ServeHTTP(c.Writer, c.Request)
+		default:
+			c.Header("Allow", allow)
+			c.String(http.StatusMethodNotAllowed, "the WebDAV gateway is read-only")
+		}
+	}
+}
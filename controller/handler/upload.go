@@ -5,16 +5,20 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"meta-file-system/common"
 	"meta-file-system/conf"
 	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
 	"meta-file-system/service/upload_service"
 	"meta-file-system/storage"
 
@@ -85,6 +89,15 @@ func maxMultipartBodyBytes() int64 {
 	return conf.Cfg.Uploader.MaxFileSize + uploadBodyOverheadBytes
 }
 
+// formBoolPtr returns the bool form field key, or nil when it is absent or invalid
+func formBoolPtr(c *gin.Context, key string) *bool {
+	v, err := strconv.ParseBool(c.PostForm(key))
+	if err != nil {
+		return nil
+	}
+	return &v
+}
+
 // maxJSONBodyBytes returns a safe upper bound for JSON/base64 requests.
 func maxJSONBodyBytes() int64 {
 	if conf.Cfg == nil || conf.Cfg.Uploader.MaxFileSize <= 0 {
@@ -134,6 +147,9 @@ type PreUploadResponseData struct {
 	Message   string `json:"message" example:"success" description:"Message"`
 	CalTxFee  int64  `json:"calTxFee" example:"1000" description:"Calculated transaction fee (satoshis)"`
 	CalTxSize int64  `json:"calTxSize" example:"500" description:"Calculated transaction size (bytes)"`
+
+	GzipCompressed bool  `json:"gzipCompressed,omitempty" example:"true" description:"True when the content was inscribed gzip-compressed"`
+	SavedFee       int64 `json:"savedFee,omitempty" example:"1200" description:"Fee saved by gzip compression (satoshis)"`
 }
 
 // PreUpload pre-upload file
@@ -142,23 +158,36 @@ type PreUploadResponseData struct {
 // @Tags         File Upload
 // @Accept       multipart/form-data
 // @Produce      json
-// @Param        file           formData  file    true   "File to upload"
-// @Param        path           formData  string  true   "File path"
-// @Param        operation      formData  string  false  "Operation type"        default(create)
-// @Param        contentType    formData  string  false  "Content type"
-// @Param        changeAddress  formData  string  false  "Change address"
-// @Param        metaId         formData  string  false  "MetaID"
-// @Param        address        formData  string  false  "Address"
-// @Param        feeRate        formData  int     false  "Fee rate"           default(1)
-// @Param        outputs        formData  string  false  "Output list json"
-// @Param        otherOutputs   formData  string  false  "Other output list json"
+// @Param        file             formData  file    true   "File to upload"
+// @Param        path             formData  string  true   "File path"
+// @Param        operation        formData  string  false  "Operation type"        default(create)
+// @Param        contentType      formData  string  false  "Content type"
+// @Param        changeAddress    formData  string  false  "Change address"
+// @Param        metaId           formData  string  false  "MetaID"
+// @Param        address          formData  string  false  "Address"
+// @Param        feeRate          formData  int     false  "Fee rate"           default(1)
+// @Param        outputs          formData  string  false  "Output list json"
+// @Param        otherOutputs     formData  string  false  "Other output list json"
+// @Param        gzip             formData  bool    false  "Gzip content before inscription when it saves at least uploader.gzip.min_saving_percent (default uploader.gzip.enabled)"
+// @Param        storageClass     formData  string  false  "Storage class hint: hot, cold or ephemeral (local records of ephemeral files may be pruned after uploader.ephemeral_retention_hours)"  default(hot)
+// @Param        sha256           formData  string  false  "SHA256 of the file (hex); a file received with another checksum is rejected before any transaction is built"
+// @Param        Idempotency-Key  header    string  false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
 // @Success      200  {object}  respond.Response{data=PreUploadResponseData}  "Pre-upload successful, return transaction and file info"
 // @Failure      400  {object}  respond.Response  "Parameter error"
+// @Failure      409  {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900)"
+// @Failure      413  {object}  respond.Response{data=respond.PayloadTooLargeData}  "Payload too large for a single PIN (code 41300)"
+// @Failure      422  {object}  respond.Response{data=respond.ChecksumMismatchData}  "File does not match the declared sha256 (code 42201)"
+// @Failure      429  {object}  respond.Response{data=respond.RateLimitedData}  "Upload rate limit exceeded (code 42900)"
 // @Failure      500  {object}  respond.Response  "Server error"
-// @Router       /files/pre-upload [post]
+// @Router       /v1/files/pre-upload [post]
 func (h *UploadHandler) PreUpload(c *gin.Context) {
 	limitRequestBody(c, maxMultipartBodyBytes())
 
+	if err := h.uploadService.CheckUploadRateLimit(strings.TrimSpace(c.PostForm("address")), c.ClientIP()); err != nil {
+		respond.UploadRateLimited(c, err)
+		return
+	}
+
 	// Read file content
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
@@ -242,6 +271,12 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 		}
 	}
 
+	storageClass := c.PostForm("storageClass")
+	if _, err := upload_service.ParseStorageClass(storageClass); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
 	// Build upload request
 	req := &upload_service.UploadRequest{
 		MetaId:        metaId,
@@ -255,11 +290,25 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 		Outputs:       outputs,
 		OtherOutputs:  otherOutputs,
 		FeeRate:       feeRate,
+		Gzip:          formBoolPtr(c, "gzip"),
+		StorageClass:  storageClass,
+		ContentSHA256: c.PostForm("sha256"),
 	}
 
 	// Upload file
 	resp, err := h.uploadService.PreUpload(req)
 	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrPayloadTooLarge):
+			respond.PayloadTooLarge(c, err)
+			return
+		case errors.Is(err, upload_service.ErrChecksumMismatch):
+			respond.ChecksumMismatch(c, err)
+			return
+		case errors.Is(err, upload_service.ErrInvalidChecksum):
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		respond.ServerError(c, err.Error())
 		return
 	}
@@ -284,13 +333,29 @@ func (h *UploadHandler) PreUpload(c *gin.Context) {
 // @Param        changeAddress    formData  string  false  "Change address (optional, defaults to address)"
 // @Param        feeRate          formData  int     false  "Fee rate (satoshis per byte, optional)"
 // @Param        totalInputAmount formData  int     false  "Total input amount in satoshis (optional, for automatic change calculation)"
+// @Param        dryRun           formData  bool    false  "Build and return the final transaction hex and PinID without broadcasting or saving anything"
+// @Param        gzip             formData  bool    false  "Gzip content before inscription when it saves at least uploader.gzip.min_saving_percent (default uploader.gzip.enabled)"
+// @Param        storageClass     formData  string  false  "Storage class hint: hot, cold or ephemeral (local records of ephemeral files may be pruned after uploader.ephemeral_retention_hours)"  default(hot)
+// @Param        chunkedFallback  formData  bool    false  "Upload through the chunked pipeline when the payload exceeds the chain's single-PIN limit (needs indexPreTxHex; preTxHex then funds the chunks)"
+// @Param        indexPreTxHex    formData  string  false  "Index pre-transaction hex used by chunkedFallback"
+// @Param        batch            formData  bool    false  "Consent to share one transaction with other small uploads of the same fee rate (uploader.batch): preTxHex must have one input signed SIGHASH_SINGLE|ANYONECANPAY and its one owner output; totalInputAmount is required. Waits until the batch is broadcast"
+// @Param        Idempotency-Key  header    string  false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
 // @Success      200  {object}  respond.Response{data=CommitUploadResponseData}  "Upload successful, return transaction ID and Pin ID"
 // @Failure      400  {object}  respond.Response  "Parameter error"
+// @Failure      409  {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)"
+// @Failure      413  {object}  respond.Response{data=respond.PayloadTooLargeData}  "Payload too large for a single PIN (code 41300)"
+// @Failure      422  {object}  respond.Response{data=respond.TxRejectedData}  "Built transaction rejected by node policy before broadcasting (code 42200)"
+// @Failure      429  {object}  respond.Response{data=respond.RateLimitedData}  "Upload rate limit exceeded (code 42900)"
 // @Failure      500  {object}  respond.Response  "Server error"
-// @Router       /files/direct-upload [post]
+// @Router       /v1/files/direct-upload [post]
 func (h *UploadHandler) DirectUpload(c *gin.Context) {
 	limitRequestBody(c, maxMultipartBodyBytes())
 
+	if err := h.uploadService.CheckUploadRateLimit(strings.TrimSpace(c.PostForm("address")), c.ClientIP()); err != nil {
+		respond.UploadRateLimited(c, err)
+		return
+	}
+
 	// Read file content
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
@@ -355,6 +420,16 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 		}
 	}
 
+	dryRun, _ := strconv.ParseBool(c.PostForm("dryRun"))
+	chunkedFallback, _ := strconv.ParseBool(c.PostForm("chunkedFallback"))
+	batch, _ := strconv.ParseBool(c.PostForm("batch"))
+
+	storageClass := c.PostForm("storageClass")
+	if _, err := upload_service.ParseStorageClass(storageClass); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
 	// Build direct upload request
 	req := &upload_service.DirectUploadRequest{
 		MetaId:           metaId,
@@ -369,11 +444,25 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 		ChangeAddress:    changeAddress,
 		FeeRate:          feeRate,
 		TotalInputAmount: totalInputAmount,
+		DryRun:           dryRun,
+		Gzip:             formBoolPtr(c, "gzip"),
+		StorageClass:     storageClass,
+		ChunkedFallback:  chunkedFallback,
+		IndexPreTxHex:    c.PostForm("indexPreTxHex"),
+		Batch:            batch,
 	}
 
 	// Upload file (one-step: build + broadcast)
 	resp, err := h.uploadService.DirectUpload(req)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrPayloadTooLarge) {
+			respond.PayloadTooLarge(c, err)
+			return
+		}
+		if errors.Is(err, upload_service.ErrUploadBatchDisabled) || errors.Is(err, upload_service.ErrUploadBatchRejected) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		// Broadcast failures carry a typed error -> structured code.
 		respond.BroadcastError(c, err)
 		return
@@ -382,6 +471,130 @@ func (h *UploadHandler) DirectUpload(c *gin.Context) {
 	respond.Success(c, resp)
 }
 
+// GetUploadBatchStatus returns the batching window configuration and open batches.
+// @Summary      Upload batching status
+// @Description  Batching configuration (uploader.batch) and the batches of this uploader instance still collecting direct uploads sent with batch=true, per fee rate
+// @Tags         File Upload
+// @Produce      json
+// @Success      200  {object}  respond.Response{data=upload_service.UploadBatchStatusResponse}
+// @Router       /v1/files/batch [get]
+func (h *UploadHandler) GetUploadBatchStatus(c *gin.Context) {
+	respond.Success(c, h.uploadService.UploadBatchStatus())
+}
+
+// GetUploadBatch lists the files of a batch transaction with their PinIDs.
+// @Summary      Get upload batch
+// @Description  Files inscribed by one batch transaction (the txId of a batched direct upload), in inscription order, with their PinIDs
+// @Tags         File Upload
+// @Produce      json
+// @Param        txId  path      string  true  "Batch transaction ID"
+// @Success      200   {object}  respond.Response{data=upload_service.UploadBatchResponse}
+// @Failure      404   {object}  respond.Response  "No uploaded files for this transaction"
+// @Failure      500   {object}  respond.Response  "Server error"
+// @Router       /v1/files/batch/{txId} [get]
+func (h *UploadHandler) GetUploadBatch(c *gin.Context) {
+	resp, err := h.uploadService.GetUploadBatch(c.Param("txId"))
+	if err != nil {
+		if errors.Is(err, upload_service.ErrUploadBatchNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// CreateProofRequest defines the payload for a proof-of-existence timestamp.
+type CreateProofRequest struct {
+	Hash             string `json:"hash" binding:"required" example:"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae" description:"SHA256 of the data to notarize (hex)"`
+	PreTxHex         string `json:"preTxHex" binding:"required" example:"0a000000..." description:"Pre-transaction hex (signed, with inputs and outputs), as for direct upload"`
+	MergeTxHex       string `json:"mergeTxHex" description:"Merge transaction hex (optional, broadcast before the proof transaction)"`
+	MetaId           string `json:"metaId" description:"MetaID"`
+	Address          string `json:"address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"Address (also used as change address if changeAddress is not provided)"`
+	ChangeAddress    string `json:"changeAddress" description:"Change address (optional, defaults to address)"`
+	FeeRate          int64  `json:"feeRate" example:"1" description:"Fee rate (satoshis per byte, optional)"`
+	TotalInputAmount int64  `json:"totalInputAmount" description:"Total input amount in satoshis (optional, for automatic change calculation)"`
+	DryRun           bool   `json:"dryRun" description:"Build and return the final transaction hex and PinID without broadcasting or saving anything"`
+}
+
+// CreateProof inscribes a SHA256 hash as a proof-of-existence timestamp.
+// @Summary      Create proof of existence
+// @Description  Inscribe just a SHA256 hash (no content) under /proof/sha256, with the same pre-tx flow as direct upload, and record it so GET /proofs/{hash} can return its verification data
+// @Tags         Proof
+// @Accept       json
+// @Produce      json
+// @Param        request          body      CreateProofRequest  true   "Proof request"
+// @Param        Idempotency-Key  header    string              false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
+// @Success      200      {object}  respond.Response{data=CommitUploadResponseData}  "Transaction ID and Pin ID of the proof"
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      409      {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)"
+// @Failure      429      {object}  respond.Response{data=respond.RateLimitedData}  "Upload rate limit exceeded (code 42900)"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/proofs [post]
+func (h *UploadHandler) CreateProof(c *gin.Context) {
+	var req CreateProofRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if err := h.uploadService.CheckUploadRateLimit(strings.TrimSpace(req.Address), c.ClientIP()); err != nil {
+		respond.UploadRateLimited(c, err)
+		return
+	}
+
+	resp, err := h.uploadService.CreateProof(&upload_service.ProofRequest{
+		Hash:             req.Hash,
+		MetaId:           req.MetaId,
+		Address:          req.Address,
+		ChangeAddress:    req.ChangeAddress,
+		PreTxHex:         req.PreTxHex,
+		MergeTxHex:       req.MergeTxHex,
+		FeeRate:          req.FeeRate,
+		TotalInputAmount: req.TotalInputAmount,
+		DryRun:           req.DryRun,
+	})
+	if err != nil {
+		if errors.Is(err, upload_service.ErrInvalidProofHash) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		respond.BroadcastError(c, err)
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// GetProof returns the verification data of a notarized hash.
+// @Summary      Verify proof of existence
+// @Description  Inscriptions of a hash made through POST /proofs, oldest first (at most 10). Each carries its raw transaction and, once mined, the block hash, height and time, the block merkle root, the transaction index and its merkle branch (sibling hashes from the leaf level up, hex in RPC byte order)
+// @Tags         Proof
+// @Produce      json
+// @Param        hash  path      string  true  "SHA256 (hex)"
+// @Success      200   {object}  respond.Response{data=upload_service.ProofResponse}
+// @Failure      400   {object}  respond.Response  "Invalid hash"
+// @Failure      404   {object}  respond.Response  "No proof recorded for this hash"
+// @Failure      500   {object}  respond.Response  "Server error"
+// @Router       /v1/proofs/{hash} [get]
+func (h *UploadHandler) GetProof(c *gin.Context) {
+	resp, err := h.uploadService.GetProof(c.Param("hash"))
+	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrInvalidProofHash):
+			respond.InvalidParam(c, err.Error())
+		case errors.Is(err, upload_service.ErrProofNotFound):
+			respond.NotFound(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
 // CommitUploadRequest commit upload request
 type CommitUploadRequest struct {
 	FileId      string `json:"fileId" binding:"required" example:"metaid_abc123" description:"File ID (from pre-upload response)"`
@@ -395,6 +608,15 @@ type CommitUploadResponseData struct {
 	TxId    string `json:"txId" example:"abc123..." description:"Transaction ID"`
 	PinId   string `json:"pinId" example:"abc123...i0" description:"Pin ID"`
 	Message string `json:"message" example:"success" description:"Message"`
+	TxHex   string `json:"txHex,omitempty" example:"0100000..." description:"Final transaction hex (direct upload dry run only)"`
+	DryRun  bool   `json:"dryRun,omitempty" example:"false" description:"True when nothing was broadcast or saved"`
+
+	GzipCompressed bool  `json:"gzipCompressed,omitempty" example:"true" description:"True when the content was inscribed gzip-compressed (direct upload only)"`
+	SavedFee       int64 `json:"savedFee,omitempty" example:"1200" description:"Fee saved by gzip compression (satoshis, direct upload only)"`
+
+	Chunked *upload_service.ChunkedUploadResponse `json:"chunked,omitempty" description:"Chunked upload result when a direct upload fell back to chunks (txId/pinId are then the index's)"`
+
+	BatchSize int `json:"batchSize,omitempty" example:"4" description:"Uploads sharing the transaction (batched direct upload only)"`
 }
 
 // CommitUpload commit upload: broadcast signed transaction
@@ -403,11 +625,14 @@ type CommitUploadResponseData struct {
 // @Tags         File Upload
 // @Accept       json
 // @Produce      json
-// @Param        request  body      CommitUploadRequest  true  "Commit upload request"
+// @Param        request          body      CommitUploadRequest  true   "Commit upload request"
+// @Param        Idempotency-Key  header    string               false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
 // @Success      200      {object}  respond.Response{data=CommitUploadResponseData}  "Upload successful, return transaction ID and Pin ID"
 // @Failure      400      {object}  respond.Response  "Parameter error or file not found"
+// @Failure      409      {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)"
+// @Failure      422      {object}  respond.Response{data=respond.TxRejectedData}  "Built transaction rejected by node policy before broadcasting (code 42200)"
 // @Failure      500      {object}  respond.Response  "Server error or broadcast failed"
-// @Router       /files/commit-upload [post]
+// @Router       /v1/files/commit-upload [post]
 func (h *UploadHandler) CommitUpload(c *gin.Context) {
 	var req CommitUploadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -418,7 +643,7 @@ func (h *UploadHandler) CommitUpload(c *gin.Context) {
 	// Commit upload
 	resp, err := h.uploadService.CommitUpload(req.FileId, req.SignedRawTx)
 	if err != nil {
-		respond.ServerError(c, err.Error())
+		respond.BroadcastError(c, err)
 		return
 	}
 
@@ -430,6 +655,8 @@ type ChainConfigItem struct {
 	MaxFileSize int64 `json:"maxFileSize" description:"Max file size in bytes"`
 	ChunkSize   int64 `json:"chunkSize" description:"Chunk size in bytes"`
 	FeeRate     int64 `json:"feeRate" description:"Fee rate (sat/byte or sat/KB)"`
+
+	MaxSinglePayloadBytes int64 `json:"maxSinglePayloadBytes" description:"Largest payload one PIN may carry (bytes); larger files need chunked upload"`
 }
 
 // ConfigResponse configuration response
@@ -437,6 +664,7 @@ type ConfigResponse struct {
 	MaxFileSize    int64                      `json:"maxFileSize" example:"10485760" description:"Max file size (bytes), min across chains for backward compat"`
 	SwaggerBaseUrl string                     `json:"swaggerBaseUrl" example:"localhost:7282" description:"Swagger API base URL"`
 	Chains         map[string]ChainConfigItem `json:"chains,omitempty" description:"Per-chain config (maxFileSize, chunkSize, feeRate)"`
+	FaucetEnabled  bool                       `json:"faucetEnabled" example:"false" description:"Whether the testnet faucet (POST /faucet) is available"`
 }
 
 // GetConfig get configuration information
@@ -446,13 +674,18 @@ type ConfigResponse struct {
 // @Accept       json
 // @Produce      json
 // @Success      200  {object}  respond.Response{data=ConfigResponse}
-// @Router       /config [get]
+// @Router       /v1/config [get]
 func (h *UploadHandler) GetConfig(c *gin.Context) {
 	chainsMap := make(map[string]ChainConfigItem)
 	minMaxFileSize := conf.Cfg.Uploader.MaxFileSize
 	for _, c := range conf.Cfg.Uploader.Chains {
 		maxFileSize, chunkSize, feeRate := conf.GetUploaderChainParam(c.Name)
-		chainsMap[c.Name] = ChainConfigItem{MaxFileSize: maxFileSize, ChunkSize: chunkSize, FeeRate: feeRate}
+		chainsMap[c.Name] = ChainConfigItem{
+			MaxFileSize:           maxFileSize,
+			ChunkSize:             chunkSize,
+			FeeRate:               feeRate,
+			MaxSinglePayloadBytes: conf.GetUploaderMaxSinglePayload(c.Name),
+		}
 		if maxFileSize > 0 && (minMaxFileSize == 0 || maxFileSize < minMaxFileSize) {
 			minMaxFileSize = maxFileSize
 		}
@@ -461,18 +694,22 @@ func (h *UploadHandler) GetConfig(c *gin.Context) {
 		MaxFileSize:    minMaxFileSize,
 		SwaggerBaseUrl: conf.Cfg.Uploader.SwaggerBaseUrl,
 		Chains:         chainsMap,
+		FaucetEnabled:  conf.FaucetAllowed(),
 	})
 }
 
 // EstimateChunkedUploadRequest estimate chunked upload request
 type EstimateChunkedUploadRequest struct {
-	FileName    string `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
-	Content     string `json:"content" description:"File content (base64 encoded string, optional if storageKey is provided)"`
-	StorageKey  string `json:"storageKey" description:"Storage key from multipart upload (optional, if provided, file will be read from storage)"`
-	Path        string `json:"path" binding:"required" example:"/file" description:"MetaID path (base path, will auto-add /file/_chunk and /file/index)"`
-	ContentType string `json:"contentType" example:"image/jpeg" description:"File content type"`
-	Chain       string `json:"chain" example:"mvc" description:"Blockchain: mvc or doge (default mvc)"`
-	FeeRate     int64  `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to chain config)"`
+	FileName    string                               `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
+	Content     string                               `json:"content" description:"File content (base64 encoded string, optional if storageKey is provided)"`
+	StorageKey  string                               `json:"storageKey" description:"Storage key from a multipart upload or POST /files/fetch-url (optional, if provided, file will be read from storage)"`
+	Path        string                               `json:"path" binding:"required" example:"/file" description:"MetaID path; chunks and index are inscribed under {base}/file/_chunk and {base}/file/index, base being the part before /file (host prefix kept, other bases need uploader.chunk_base_paths)"`
+	ContentType string                               `json:"contentType" example:"image/jpeg" description:"File content type"`
+	Chain       string                               `json:"chain" example:"mvc" description:"Blockchain: mvc or doge (default mvc)"`
+	FeeRate     int64                                `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to chain config)"`
+	Compression string                               `json:"compression" example:"none" description:"Compression of content declared in the v2 file index: none or gzip (default none; content must already be compressed)"`
+	Encryption  *metaid_protocols.MetaFileEncryption `json:"encryption" description:"Encryption envelope declared in the v2 file index (optional; content must already be encrypted, algorithm required)"`
+	DeltaBase   string                               `json:"deltaBasePinId" example:"4e3f...a1i0" description:"Inscribe only the binary delta of content against the indexed file of this PIN (optional, needs uploader.delta.indexer_url); same value as the upload that follows"`
 }
 
 // EstimateChunkedUpload estimate chunked upload fee
@@ -485,7 +722,7 @@ type EstimateChunkedUploadRequest struct {
 // @Success      200      {object}  respond.Response{data=upload_service.EstimateChunkedUploadResponse}  "Estimate successful"
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/estimate-chunked-upload [post]
+// @Router       /v1/files/estimate-chunked-upload [post]
 func (h *UploadHandler) EstimateChunkedUpload(c *gin.Context) {
 	limitRequestBody(c, maxJSONBodyBytes())
 
@@ -494,6 +731,10 @@ func (h *UploadHandler) EstimateChunkedUpload(c *gin.Context) {
 		respond.InvalidParam(c, err.Error())
 		return
 	}
+	if err := upload_service.ValidateMetaFileEncoding(req.Compression, req.Encryption); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
 
 	// Get content from storage or request body
 	var content []byte
@@ -523,17 +764,24 @@ func (h *UploadHandler) EstimateChunkedUpload(c *gin.Context) {
 		chain = "mvc"
 	}
 	serviceReq := &upload_service.EstimateChunkedUploadRequest{
-		FileName:    req.FileName,
-		Content:     content,
-		Path:        req.Path,
-		ContentType: req.ContentType,
-		Chain:       chain,
-		FeeRate:     req.FeeRate,
+		FileName:       req.FileName,
+		Content:        content,
+		Path:           req.Path,
+		ContentType:    req.ContentType,
+		Chain:          chain,
+		FeeRate:        req.FeeRate,
+		Compression:    req.Compression,
+		Encryption:     req.Encryption,
+		DeltaBasePinId: req.DeltaBase,
 	}
 
 	// Estimate fee
 	resp, err := h.uploadService.EstimateChunkedUpload(serviceReq)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrUnsupportedBasePath) || errors.Is(err, upload_service.ErrInvalidDeltaUpload) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		respond.ServerError(c, err.Error())
 		return
 	}
@@ -541,21 +789,70 @@ func (h *UploadHandler) EstimateChunkedUpload(c *gin.Context) {
 	respond.Success(c, resp)
 }
 
+// GetUploadCost compare direct and chunked upload cost by file size
+// @Summary      Upload cost calculator
+// @Description  Estimate the cost of a direct (single OP_RETURN transaction) upload versus a chunked upload for a file of the given size on each supported chain at the configured fee rates, with a recommended mode and break-even guidance. No file content is needed.
+// @Tags         File Upload
+// @Produce      json
+// @Param        fileSize     query     int     true   "File size in bytes"
+// @Param        contentType  query     string  false  "File content type (default application/octet-stream)"
+// @Param        fileName     query     string  false  "File name (included in the chunked index)"
+// @Param        path         query     string  false  "Base MetaID path (default /file)"
+// @Param        chain        query     string  false  "Blockchain: mvc or doge (default every configured chain)"
+// @Param        feeRate      query     int     false  "Fee rate override (default chain config)"
+// @Success      200          {object}  respond.Response{data=upload_service.UploadCostResponse}  "Estimate successful"
+// @Failure      400          {object}  respond.Response  "Parameter error"
+// @Router       /v1/files/upload-cost [get]
+func (h *UploadHandler) GetUploadCost(c *gin.Context) {
+	fileSize, err := strconv.ParseInt(c.Query("fileSize"), 10, 64)
+	if err != nil || fileSize <= 0 {
+		respond.InvalidParam(c, "invalid fileSize")
+		return
+	}
+	feeRate, err := strconv.ParseInt(c.DefaultQuery("feeRate", "0"), 10, 64)
+	if err != nil || feeRate < 0 {
+		respond.InvalidParam(c, "invalid feeRate")
+		return
+	}
+
+	resp, err := h.uploadService.EstimateUploadCost(&upload_service.UploadCostRequest{
+		FileSize:    fileSize,
+		FileName:    c.Query("fileName"),
+		Path:        c.Query("path"),
+		ContentType: c.Query("contentType"),
+		Chain:       c.Query("chain"),
+		FeeRate:     feeRate,
+	})
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
 // ChunkedUploadRequest chunked upload request
 type ChunkedUploadRequest struct {
-	MetaId        string `json:"metaId" binding:"required" example:"metaid_abc123" description:"MetaID"`
-	Address       string `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address"`
-	FileName      string `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
-	Content       string `json:"content" description:"File content (base64 encoded string, optional if storageKey is provided)"`
-	StorageKey    string `json:"storageKey" description:"Storage key from multipart upload (optional, if provided, file will be read from storage)"`
-	Path          string `json:"path" binding:"required" example:"/file" description:"MetaID path (base path, will auto-add /file/_chunk and /file/index)"`
-	Operation     string `json:"operation" example:"create" description:"Operation type (create/update)"`
-	ContentType   string `json:"contentType" example:"image/jpeg" description:"File content type"`
-	ChunkPreTxHex string `json:"chunkPreTxHex" binding:"required" example:"0100000..." description:"Pre-built chunk funding transaction (with inputs, signNull)"`
-	IndexPreTxHex string `json:"indexPreTxHex" binding:"required" example:"0100000..." description:"Pre-built index transaction (with inputs, signNull)"`
-	MergeTxHex    string `json:"mergeTxHex" example:"0100000..." description:"Merge transaction hex (creates two UTXOs, broadcasted first if IsBroadcast is true)"`
-	FeeRate       int64  `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to config)"`
-	IsBroadcast   bool   `json:"isBroadcast" example:"false" description:"Whether to broadcast transactions automatically"`
+	MetaId        string                               `json:"metaId" binding:"required" example:"metaid_abc123" description:"MetaID"`
+	Address       string                               `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address"`
+	FileName      string                               `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
+	Content       string                               `json:"content" description:"File content (base64 encoded string, optional if storageKey is provided)"`
+	StorageKey    string                               `json:"storageKey" description:"Storage key from a multipart upload or POST /files/fetch-url (optional, if provided, file will be read from storage)"`
+	Path          string                               `json:"path" binding:"required" example:"/file" description:"MetaID path; chunks and index are inscribed under {base}/file/_chunk and {base}/file/index, base being the part before /file (host prefix kept, other bases need uploader.chunk_base_paths)"`
+	Operation     string                               `json:"operation" example:"create" description:"Operation type (create/update)"`
+	ContentType   string                               `json:"contentType" example:"image/jpeg" description:"File content type"`
+	ChunkPreTxHex string                               `json:"chunkPreTxHex" example:"0100000..." description:"Pre-built chunk funding transaction (with inputs, signNull); required unless the chunks are funded by the hot wallet"`
+	IndexPreTxHex string                               `json:"indexPreTxHex" binding:"required" example:"0100000..." description:"Pre-built index transaction (with inputs, signNull)"`
+	MergeTxHex    string                               `json:"mergeTxHex" example:"0100000..." description:"Merge transaction hex (creates two UTXOs, broadcasted first if IsBroadcast is true)"`
+	FeeRate       int64                                `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to config)"`
+	IsBroadcast   bool                                 `json:"isBroadcast" example:"false" description:"Whether to broadcast transactions automatically"`
+	DryRun        bool                                 `json:"dryRun" example:"false" description:"Build and return all transaction hexes and PinIDs without broadcasting or saving anything (overrides isBroadcast)"`
+	StorageClass  string                               `json:"storageClass" example:"hot" description:"Storage class hint: hot, cold or ephemeral (default hot; local records of ephemeral files may be pruned)"`
+	Compression   string                               `json:"compression" example:"none" description:"Compression of content declared in the v2 file index: none or gzip (default none; content must already be compressed)"`
+	Encryption    *metaid_protocols.MetaFileEncryption `json:"encryption" description:"Encryption envelope declared in the v2 file index (optional; content must already be encrypted, algorithm required)"`
+	DeltaBase     string                               `json:"deltaBasePinId" example:"4e3f...a1i0" description:"Inscribe only the binary delta of content against the indexed file of this PIN, typically the previous version (optional, needs uploader.delta.indexer_url; not with compression gzip or encryption)"`
+	SHA256        string                               `json:"sha256" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" description:"SHA256 of the file content (hex, optional); content received with another checksum is rejected before any transaction is built (code 42201)"`
+	Funding       string                               `json:"fundingStrategy" example:"hot_wallet" description:"Chunk funding: assistent (chunkPreTxHex funds a per-user assistant key) or hot_wallet (the operator hot wallet funds the chunks; mvc only, needs isBroadcast or dryRun). Optional, defaults to uploader.chunk_funding.strategy; another value needs uploader.chunk_funding.allow_request_strategy"`
 }
 
 // ChunkedUpload chunked file upload
@@ -564,11 +861,14 @@ type ChunkedUploadRequest struct {
 // @Tags         File Upload
 // @Accept       json
 // @Produce      json
-// @Param        request  body      ChunkedUploadRequest  true  "Chunked upload request"
+// @Param        request          body      ChunkedUploadRequest  true   "Chunked upload request"
+// @Param        Idempotency-Key  header    string                false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
 // @Success      200      {object}  respond.Response{data=upload_service.ChunkedUploadResponse}  "Upload successful"
 // @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      409      {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)"
+// @Failure      422      {object}  respond.Response{data=respond.TxRejectedData}  "Built transaction rejected by node policy before broadcasting (code 42200), or content not matching the declared sha256 (code 42201, data respond.ChecksumMismatchData)"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/chunked-upload [post]
+// @Router       /v1/files/chunked-upload [post]
 func (h *UploadHandler) ChunkedUpload(c *gin.Context) {
 	limitRequestBody(c, maxJSONBodyBytes())
 
@@ -577,6 +877,14 @@ func (h *UploadHandler) ChunkedUpload(c *gin.Context) {
 		respond.InvalidParam(c, err.Error())
 		return
 	}
+	if _, err := upload_service.ParseStorageClass(req.StorageClass); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if err := upload_service.ValidateMetaFileEncoding(req.Compression, req.Encryption); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
 
 	// Get content from storage or request body
 	var content []byte
@@ -602,23 +910,36 @@ func (h *UploadHandler) ChunkedUpload(c *gin.Context) {
 
 	// Convert to service request
 	serviceReq := &upload_service.ChunkedUploadRequest{
-		MetaId:        req.MetaId,
-		Address:       req.Address,
-		FileName:      req.FileName,
-		Content:       content,
-		Path:          req.Path,
-		Operation:     req.Operation,
-		ContentType:   req.ContentType,
-		ChunkPreTxHex: req.ChunkPreTxHex,
-		IndexPreTxHex: req.IndexPreTxHex,
-		MergeTxHex:    req.MergeTxHex,
-		FeeRate:       req.FeeRate,
-		IsBroadcast:   req.IsBroadcast,
+		MetaId:         req.MetaId,
+		Address:        req.Address,
+		FileName:       req.FileName,
+		Content:        content,
+		Path:           req.Path,
+		Operation:      req.Operation,
+		ContentType:    req.ContentType,
+		ChunkPreTxHex:  req.ChunkPreTxHex,
+		IndexPreTxHex:  req.IndexPreTxHex,
+		MergeTxHex:     req.MergeTxHex,
+		FeeRate:        req.FeeRate,
+		IsBroadcast:    req.IsBroadcast,
+		DryRun:         req.DryRun,
+		StorageClass:   req.StorageClass,
+		Compression:    req.Compression,
+		Encryption:     req.Encryption,
+		DeltaBasePinId: req.DeltaBase,
+		ContentSHA256:  req.SHA256,
+
+		FundingStrategy: req.Funding,
 	}
 
 	// Upload file
 	resp, err := h.uploadService.ChunkedUpload(serviceReq)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrUnsupportedBasePath) || errors.Is(err, upload_service.ErrInvalidDeltaUpload) ||
+			errors.Is(err, upload_service.ErrInvalidChecksum) || upload_service.IsChunkFundingError(err) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
 		// Broadcast failures carry a typed error -> structured code.
 		respond.BroadcastError(c, err)
 		return
@@ -629,32 +950,45 @@ func (h *UploadHandler) ChunkedUpload(c *gin.Context) {
 
 // ChunkedUploadForTaskRequest defines the payload for creating an async chunked upload task.
 type ChunkedUploadForTaskRequest struct {
-	MetaId        string `json:"metaId" binding:"required" example:"metaid_abc123" description:"MetaID"`
-	Address       string `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address"`
-	FileName      string `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
-	Content       string `json:"content" description:"Base64 encoded file content (optional if storageKey is provided)"`
-	StorageKey    string `json:"storageKey" description:"Storage key from multipart upload (optional, if provided, file will be read from storage)"`
-	Path          string `json:"path" binding:"required" example:"/file" description:"Base MetaID path (will append /file/_chunk and /file/index)"`
-	Operation     string `json:"operation" example:"create" description:"Operation type (create/update)"`
-	ContentType   string `json:"contentType" example:"image/jpeg" description:"MIME type"`
-	Chain         string `json:"chain" example:"mvc" description:"Blockchain: mvc or doge (default mvc)"`
-	ChunkPreTxHex string `json:"chunkPreTxHex" binding:"required" example:"0100000..." description:"Pre-built chunk transaction (contains inputs, signNull)"`
-	IndexPreTxHex string `json:"indexPreTxHex" example:"0100000..." description:"Pre-built index transaction (required for mvc, optional for doge - index funded by chunk change)"`
-	MergeTxHex    string `json:"mergeTxHex" example:"0100000..." description:"Merge transaction hex (optional, broadcast first)"`
-	FeeRate       int64  `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to config)"`
+	MetaId        string                               `json:"metaId" binding:"required" example:"metaid_abc123" description:"MetaID"`
+	Address       string                               `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address"`
+	FileName      string                               `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
+	Content       string                               `json:"content" description:"Base64 encoded file content (optional if storageKey is provided)"`
+	StorageKey    string                               `json:"storageKey" description:"Storage key from a multipart upload or POST /files/fetch-url (optional, if provided, file will be read from storage)"`
+	Path          string                               `json:"path" binding:"required" example:"/file" description:"MetaID path; chunks and index are inscribed under {base}/file/_chunk and {base}/file/index, base being the part before /file (host prefix kept, other bases need uploader.chunk_base_paths)"`
+	Operation     string                               `json:"operation" example:"create" description:"Operation type (create/update)"`
+	ContentType   string                               `json:"contentType" example:"image/jpeg" description:"MIME type"`
+	Chain         string                               `json:"chain" example:"mvc" description:"Blockchain: mvc or doge (default mvc)"`
+	ChunkPreTxHex string                               `json:"chunkPreTxHex" example:"0100000..." description:"Pre-built chunk transaction (contains inputs, signNull); required unless the chunks are funded by the hot wallet"`
+	IndexPreTxHex string                               `json:"indexPreTxHex" example:"0100000..." description:"Pre-built index transaction (required for mvc, optional for doge - index funded by chunk change)"`
+	MergeTxHex    string                               `json:"mergeTxHex" example:"0100000..." description:"Merge transaction hex (optional, broadcast first)"`
+	FeeRate       int64                                `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to config)"`
+	StorageClass  string                               `json:"storageClass" example:"hot" description:"Storage class hint: hot, cold or ephemeral (default hot; local records of ephemeral files may be pruned)"`
+	Compression   string                               `json:"compression" example:"none" description:"Compression of content declared in the v2 file index: none or gzip (default none; content must already be compressed)"`
+	Encryption    *metaid_protocols.MetaFileEncryption `json:"encryption" description:"Encryption envelope declared in the v2 file index (optional; content must already be encrypted, algorithm required)"`
+	DeltaBase     string                               `json:"deltaBasePinId" example:"4e3f...a1i0" description:"Inscribe only the binary delta of content against the indexed file of this PIN, typically the previous version (optional, needs uploader.delta.indexer_url; not with compression gzip or encryption)"`
+	BroadcastAt   int64                                `json:"broadcastAt" example:"1767225600" description:"Unix seconds: build the transactions now but broadcast at this time (optional, within uploader.schedule.max_delay_hours)"`
+	TargetFeeRate int64                                `json:"targetFeeRate" example:"1" description:"Broadcast as soon as the network fee rate is at or below this, no later than broadcastAt (optional, at most feeRate)"`
+	SHA256        string                               `json:"sha256" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" description:"SHA256 of the file content (hex, optional); content received with another checksum is rejected before the task is created (code 42201)"`
+	Funding       string                               `json:"fundingStrategy" example:"hot_wallet" description:"Chunk funding: assistent (chunkPreTxHex funds a per-user assistant key) or hot_wallet (the operator hot wallet funds the chunks; mvc only, not with broadcastAt or targetFeeRate). Optional, defaults to uploader.chunk_funding.strategy; another value needs uploader.chunk_funding.allow_request_strategy"`
 }
 
 // ChunkedUploadForTask creates an async chunked upload task.
 // @Summary      Async chunked upload (create task)
-// @Description  Create an async chunked upload task and return the task ID so the client can poll for progress
+// @Description  Create an async chunked upload task and return the task ID so the client can poll for progress.
+// @Description  With broadcastAt and/or targetFeeRate the transactions are built at once and the task waits in status scheduled until the time arrives or the network fee rate drops to the target; the fee rate is re-checked before broadcasting.
 // @Tags         File Upload
 // @Accept       json
 // @Produce      json
-// @Param        request  body      ChunkedUploadForTaskRequest  true  "Async chunked upload request"
+// @Param        request          body      ChunkedUploadForTaskRequest  true   "Async chunked upload request"
+// @Param        Idempotency-Key  header    string                       false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
 // @Success      200      {object}  respond.Response{data=respond.ChunkedUploadTaskResponse}
 // @Failure      400      {object}  respond.Response  "Invalid parameter"
+// @Failure      409      {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)"
+// @Failure      422      {object}  respond.Response{data=respond.ChecksumMismatchData}  "Content does not match the declared sha256 (code 42201)"
+// @Failure      429      {object}  respond.Response{data=respond.RateLimitedData}  "Upload rate limit exceeded (code 42900)"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/chunked-upload-task [post]
+// @Router       /v1/files/chunked-upload-task [post]
 func (h *UploadHandler) ChunkedUploadForTask(c *gin.Context) {
 	limitRequestBody(c, maxJSONBodyBytes())
 
@@ -663,6 +997,18 @@ func (h *UploadHandler) ChunkedUploadForTask(c *gin.Context) {
 		respond.InvalidParam(c, err.Error())
 		return
 	}
+	if err := h.uploadService.CheckUploadRateLimit(req.Address, c.ClientIP()); err != nil {
+		respond.UploadRateLimited(c, err)
+		return
+	}
+	if _, err := upload_service.ParseStorageClass(req.StorageClass); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if err := upload_service.ValidateMetaFileEncoding(req.Compression, req.Encryption); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
 
 	// Get content from storage or request body
 	var content []byte
@@ -705,24 +1051,50 @@ func (h *UploadHandler) ChunkedUploadForTask(c *gin.Context) {
 
 	// Convert to service request
 	serviceReq := &upload_service.ChunkedUploadRequest{
-		MetaId:        req.MetaId,
-		Address:       req.Address,
-		FileName:      req.FileName,
-		Content:       content,
-		Path:          req.Path,
-		Operation:     req.Operation,
-		ContentType:   req.ContentType,
-		Chain:         chain,
-		ChunkPreTxHex: req.ChunkPreTxHex,
-		IndexPreTxHex: req.IndexPreTxHex,
-		MergeTxHex:    req.MergeTxHex,
-		FeeRate:       req.FeeRate,
-		IsBroadcast:   false, // handled asynchronously by background worker
+		MetaId:         req.MetaId,
+		Address:        req.Address,
+		FileName:       req.FileName,
+		Content:        content,
+		Path:           req.Path,
+		Operation:      req.Operation,
+		ContentType:    req.ContentType,
+		Chain:          chain,
+		ChunkPreTxHex:  req.ChunkPreTxHex,
+		IndexPreTxHex:  req.IndexPreTxHex,
+		MergeTxHex:     req.MergeTxHex,
+		FeeRate:        req.FeeRate,
+		StorageClass:   req.StorageClass,
+		Compression:    req.Compression,
+		Encryption:     req.Encryption,
+		DeltaBasePinId: req.DeltaBase,
+		TargetFeeRate:  req.TargetFeeRate,
+		ContentSHA256:  req.SHA256,
+		IsBroadcast:    false, // handled asynchronously by background worker
+
+		FundingStrategy: req.Funding,
+	}
+	if req.BroadcastAt > 0 {
+		broadcastAt := time.Unix(req.BroadcastAt, 0)
+		serviceReq.BroadcastAt = &broadcastAt
 	}
 
 	// Create async task
 	resp, err := h.uploadService.ChunkedUploadForTask(serviceReq)
 	if err != nil {
+		if errors.Is(err, upload_service.ErrUnsupportedBasePath) || errors.Is(err, upload_service.ErrInvalidUploadSchedule) ||
+			errors.Is(err, upload_service.ErrInvalidDeltaUpload) || errors.Is(err, upload_service.ErrInvalidChecksum) ||
+			upload_service.IsChunkFundingError(err) {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+		if errors.Is(err, upload_service.ErrChecksumMismatch) {
+			respond.ChecksumMismatch(c, err)
+			return
+		}
+		if errors.Is(err, upload_service.ErrUtxoLocked) {
+			respond.UtxoLocked(c, err)
+			return
+		}
 		respond.ServerError(c, err.Error())
 		return
 	}
@@ -734,6 +1106,58 @@ func (h *UploadHandler) ChunkedUploadForTask(c *gin.Context) {
 	})
 }
 
+// FetchURLRequest defines the payload for fetching upload content from a URL.
+type FetchURLRequest struct {
+	Url      string `json:"url" binding:"required" example:"https://example.com/logo.png" description:"Source URL (scheme must be in uploader.url_fetch.allowed_schemes, https by default)"`
+	Address  string `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address (rate limited like an upload)"`
+	FileName string `json:"fileName" example:"logo.png" description:"File name (optional, defaults to the last segment of the URL path)"`
+	Chain    string `json:"chain" example:"mvc" description:"Chain the file will be uploaded to; its max_file_size bounds the download unless uploader.url_fetch.max_size_mb is set (default mvc)"`
+}
+
+// FetchFromURL downloads upload content from a URL into storage.
+// @Summary      Fetch upload content from a URL
+// @Description  Download the file at url on the server (allowlisted schemes, no private network addresses, content type and size limits from uploader.url_fetch) into storage and return its storage key, size, content type, MD5 and SHA256. Pass storageKey to estimate-chunked-upload and then chunked-upload or chunked-upload-task instead of content, so existing web assets go on-chain without the client downloading them. Only available when uploader.url_fetch.enabled is set.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        request  body      FetchURLRequest  true  "Fetch request"
+// @Success      200      {object}  respond.Response{data=upload_service.UrlFetchResponse}
+// @Failure      400      {object}  respond.Response  "Invalid parameter, URL or content not allowed, or the source failed"
+// @Failure      404      {object}  respond.Response  "URL fetch is disabled"
+// @Failure      429      {object}  respond.Response{data=respond.RateLimitedData}  "Upload rate limit exceeded (code 42900)"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/files/fetch-url [post]
+func (h *UploadHandler) FetchFromURL(c *gin.Context) {
+	var req FetchURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if err := h.uploadService.CheckUploadRateLimit(strings.TrimSpace(req.Address), c.ClientIP()); err != nil {
+		respond.UploadRateLimited(c, err)
+		return
+	}
+
+	resp, err := h.uploadService.FetchFromURL(c.Request.Context(), &upload_service.UrlFetchRequest{
+		SourceURL: req.Url,
+		FileName:  req.FileName,
+		Chain:     req.Chain,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrUrlFetchDisabled):
+			respond.NotFound(c, err.Error())
+		case errors.Is(err, upload_service.ErrUrlFetchRejected), errors.Is(err, upload_service.ErrUrlFetchFailed):
+			respond.InvalidParam(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
 // GetTaskProgress returns detailed task info by task ID.
 // @Summary      Query task progress
 // @Description  Get async upload task progress and status by task ID
@@ -745,7 +1169,7 @@ func (h *UploadHandler) ChunkedUploadForTask(c *gin.Context) {
 // @Failure      400     {object}  respond.Response  "Invalid parameter"
 // @Failure      404     {object}  respond.Response  "Task not found"
 // @Failure      500     {object}  respond.Response  "Server error"
-// @Router       /files/task/{taskId} [get]
+// @Router       /v1/files/task/{taskId} [get]
 func (h *UploadHandler) GetTaskProgress(c *gin.Context) {
 	taskId := c.Param("taskId")
 	if taskId == "" {
@@ -762,6 +1186,47 @@ func (h *UploadHandler) GetTaskProgress(c *gin.Context) {
 	respond.Success(c, task)
 }
 
+// CancelUploadTaskRequest identifies the owner of the task to cancel.
+type CancelUploadTaskRequest struct {
+	Address string `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"Uploader address of the task"`
+}
+
+// CancelUploadTask cancels an async task before anything is broadcast.
+// @Summary      Cancel upload task
+// @Description  Cancel an async chunked upload task of the address while none of its transactions has been broadcast (status pending or scheduled). Its built transactions are dropped.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        taskId   path      string                   true  "Task ID"
+// @Param        request  body      CancelUploadTaskRequest  true  "Task owner"
+// @Success      200      {object}  respond.Response{data=respond.UploadTask}
+// @Failure      400      {object}  respond.Response  "Invalid parameter, or the task is already broadcasting or finished"
+// @Failure      404      {object}  respond.Response  "Task not found"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/files/task/{taskId}/cancel [post]
+func (h *UploadHandler) CancelUploadTask(c *gin.Context) {
+	var req CancelUploadTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	task, err := h.uploadService.CancelUploadTask(c.Param("taskId"), req.Address)
+	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrUploadTaskNotFound):
+			respond.NotFound(c, err.Error())
+		case errors.Is(err, upload_service.ErrUploadTaskNotCancellable):
+			respond.InvalidParam(c, err.Error())
+		default:
+			respond.ServerError(c, err.Error())
+		}
+		return
+	}
+
+	respond.Success(c, respond.ToUploadTask(task))
+}
+
 // InitiateMultipartUploadRequest request for initiating multipart upload
 type InitiateMultipartUploadRequest struct {
 	FileName string `json:"fileName" binding:"required"`
@@ -780,7 +1245,7 @@ type InitiateMultipartUploadRequest struct {
 // @Success      200      {object}  respond.Response{data=upload_service.InitiateMultipartUploadResponse}
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/multipart/initiate [post]
+// @Router       /v1/files/multipart/initiate [post]
 func (h *UploadHandler) InitiateMultipartUpload(c *gin.Context) {
 	var req InitiateMultipartUploadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -822,7 +1287,7 @@ type UploadPartRequest struct {
 // @Success      200      {object}  respond.Response{data=upload_service.UploadPartResponse}
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/multipart/upload-part [post]
+// @Router       /v1/files/multipart/upload-part [post]
 func (h *UploadHandler) UploadPart(c *gin.Context) {
 	limitRequestBody(c, maxJSONBodyBytes())
 
@@ -876,7 +1341,7 @@ type CompleteMultipartUploadRequest struct {
 // @Success      200      {object}  respond.Response{data=upload_service.CompleteMultipartUploadResponse}
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/multipart/complete [post]
+// @Router       /v1/files/multipart/complete [post]
 func (h *UploadHandler) CompleteMultipartUpload(c *gin.Context) {
 	var req CompleteMultipartUploadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -925,7 +1390,7 @@ type ListPartsRequest struct {
 // @Success      200      {object}  respond.Response{data=upload_service.ListPartsResponse}
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/multipart/list-parts [post]
+// @Router       /v1/files/multipart/list-parts [post]
 func (h *UploadHandler) ListParts(c *gin.Context) {
 	var req ListPartsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -958,7 +1423,7 @@ type AbortMultipartUploadRequest struct {
 // @Success      200      {object}  respond.Response  "Abort successful"
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/multipart/abort [post]
+// @Router       /v1/files/multipart/abort [post]
 func (h *UploadHandler) AbortMultipartUpload(c *gin.Context) {
 	var req AbortMultipartUploadRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -987,7 +1452,7 @@ func (h *UploadHandler) AbortMultipartUpload(c *gin.Context) {
 // @Success      200      {object}  respond.Response{data=respond.UploadTaskListResponse}
 // @Failure      400      {object}  respond.Response  "Parameter error"
 // @Failure      500      {object}  respond.Response  "Server error"
-// @Router       /files/tasks [get]
+// @Router       /v1/files/tasks [get]
 func (h *UploadHandler) ListUploadTasks(c *gin.Context) {
 	address := c.Query("address")
 	if strings.TrimSpace(address) == "" {
@@ -1022,3 +1487,268 @@ func (h *UploadHandler) ListUploadTasks(c *gin.Context) {
 		HasMore:    resp.HasMore,
 	})
 }
+
+// GetUploadTaskStats task outcome counts and the expired rate
+// @Summary      Upload task statistics
+// @Description  Counts async upload tasks finished in the last hours by outcome (completed, failed, cancelled, expired) and the expired rate expired / (completed + expired), plus the tasks still pending, processing or scheduled. Tasks that broadcast nothing within uploader.task_ttl are expired by the cleanup processor.
+// @Tags         File Upload
+// @Produce      json
+// @Param        hours  query     int  false  "Window in hours (at most 720)"  default(24)
+// @Success      200    {object}  respond.Response{data=upload_service.TaskStats}
+// @Failure      400    {object}  respond.Response  "Parameter error"
+// @Failure      500    {object}  respond.Response  "Server error"
+// @Router       /v1/files/tasks/stats [get]
+func (h *UploadHandler) GetUploadTaskStats(c *gin.Context) {
+	hours, err := strconv.Atoi(c.DefaultQuery("hours", "24"))
+	if err != nil || hours <= 0 {
+		respond.InvalidParam(c, "invalid hours")
+		return
+	}
+
+	stats, err := h.uploadService.GetTaskStats(hours)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, stats)
+}
+
+// ListUploadedFiles list a user's uploaded files with cursor pagination
+// @Summary      List uploaded files
+// @Description  Upload history of a MetaID and/or address, newest first, with cursor pagination and an optional status filter. When the indexer shares the uploader's MySQL database each file carries the indexer state of its PIN.
+// @Tags         File Upload
+// @Produce      json
+// @Param        address  query     string  false  "Uploader address (address and/or metaId is required)"
+// @Param        metaId   query     string  false  "MetaID (address and/or metaId is required)"
+// @Param        status   query     string  false  "Upload status: pending, success or failed"
+// @Param        cursor   query     int     false  "Cursor (last file ID)"  default(0)
+// @Param        size     query     int     false  "Page size"              default(20)
+// @Success      200      {object}  respond.Response{data=respond.UploadedFileListResponse}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/files/uploads [get]
+func (h *UploadHandler) ListUploadedFiles(c *gin.Context) {
+	address := strings.TrimSpace(c.Query("address"))
+	metaId := strings.TrimSpace(c.Query("metaId"))
+	if address == "" && metaId == "" {
+		respond.InvalidParam(c, "address or metaId is required")
+		return
+	}
+
+	status := model.Status(c.Query("status"))
+	switch status {
+	case "", model.StatusPending, model.StatusSuccess, model.StatusFailed:
+	default:
+		respond.InvalidParam(c, "invalid status, must be pending, success or failed")
+		return
+	}
+
+	cursor, err := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		respond.InvalidParam(c, "invalid cursor")
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil {
+		respond.InvalidParam(c, "invalid size")
+		return
+	}
+
+	resp, err := h.uploadService.ListUploadedFiles(&upload_service.UploadedFileListRequest{
+		MetaId:  metaId,
+		Address: address,
+		Status:  status,
+		Cursor:  cursor,
+		Size:    size,
+	})
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.UploadedFileListResponse{
+		Files:      respond.ToUploadedFileList(resp.Files, getIndexerBaseUrl()),
+		NextCursor: resp.NextCursor,
+		HasMore:    resp.HasMore,
+	})
+}
+
+// GetUploadedFile get an uploaded file with its chunks
+// @Summary      Get uploaded file
+// @Description  Details of an uploaded file by file ID, including the chunks of a chunked upload and, when the indexer shares the uploader's MySQL database, the indexer state of its PIN.
+// @Tags         File Upload
+// @Produce      json
+// @Param        fileId  path      string  true  "File ID (metaId_fileHash)"
+// @Success      200     {object}  respond.Response{data=respond.UploadedFile}
+// @Failure      400     {object}  respond.Response  "Parameter error"
+// @Failure      404     {object}  respond.Response  "File not found"
+// @Failure      500     {object}  respond.Response  "Server error"
+// @Router       /v1/files/uploads/{fileId} [get]
+func (h *UploadHandler) GetUploadedFile(c *gin.Context) {
+	fileId := strings.TrimSpace(c.Param("fileId"))
+	if fileId == "" {
+		respond.InvalidParam(c, "fileId is required")
+		return
+	}
+
+	file, err := h.uploadService.GetUploadedFile(fileId)
+	if err != nil {
+		if errors.Is(err, upload_service.ErrUploadedFileNotFound) {
+			respond.NotFound(c, err.Error())
+			return
+		}
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.ToUploadedFile(file, getIndexerBaseUrl()))
+}
+
+// FaucetRequest testnet faucet request
+type FaucetRequest struct {
+	Address   string `json:"address" example:"mhZz3V4V6JqGfKXsVgzq3wUUzFhNRJ6X3M" description:"Testnet address to fund (optional, a fresh address and key are generated when empty)"`
+	MetaId    string `json:"metaId" example:"metaid_abc123" description:"MetaID (used with assistant)"`
+	Assistant bool   `json:"assistant" example:"false" description:"Fund the chunked upload assistant address of address instead of address itself"`
+}
+
+// Faucet fund a testnet address from the faucet
+// @Summary      Testnet faucet
+// @Description  Send testnet MVC coins to an address, to the chunked upload assistant address of an address, or to a freshly generated address whose private key is returned, so the upload flows can be tried end to end. Only available when uploader.faucet.enabled is set outside mainnet; each address and client IP is subject to a cooldown and the faucet to an hourly cap (code 42900 when exceeded). Callers without a client IP (a unix socket listener without http.trusted_proxies) get code 40300.
+// @Tags         Faucet
+// @Accept       json
+// @Produce      json
+// @Param        request  body      FaucetRequest  false  "Faucet request"
+// @Success      200      {object}  respond.Response{data=upload_service.FaucetResponse}  "Funded"
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      403      {object}  respond.Response  "Client IP unknown"
+// @Failure      404      {object}  respond.Response  "Faucet disabled"
+// @Failure      429      {object}  respond.Response  "Rate limited"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/faucet [post]
+func (h *UploadHandler) Faucet(c *gin.Context) {
+	var req FaucetRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respond.InvalidParam(c, err.Error())
+			return
+		}
+	}
+
+	resp, err := h.uploadService.FundFromFaucet(&upload_service.FaucetRequest{
+		Address:   strings.TrimSpace(req.Address),
+		MetaId:    req.MetaId,
+		Assistant: req.Assistant,
+		ClientIP:  c.ClientIP(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrFaucetDisabled):
+			respond.NotFound(c, err.Error())
+		case errors.Is(err, upload_service.ErrFaucetRateLimited):
+			respond.Error(c, respond.CodeRateLimited, err.Error())
+		case errors.Is(err, upload_service.ErrFaucetClientUnknown):
+			respond.Error(c, respond.CodeForbidden, err.Error())
+		case errors.Is(err, upload_service.ErrFaucetPayout):
+			respond.BroadcastError(c, err)
+		default:
+			respond.InvalidParam(c, err.Error())
+		}
+		return
+	}
+
+	respond.Success(c, resp)
+}
+
+// RecoverUploads resume interrupted synchronous chunked uploads
+// @Summary      Recover in-flight uploads
+// @Description  Resume chunked uploads sent with isBroadcast=true whose broadcast was cut off, e.g. by a restart. Every upload keeps a checkpoint of its transactions and broadcast progress; pending checkpoints not updated for stalledAfter seconds are broadcast from where they stopped (already-known transactions count as done), and their file is marked success or failed. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        stalledAfter  query     int  false  "Only uploads not updated for this many seconds (0 = all pending)"  default(120)
+// @Param        limit         query     int  false  "Uploads per run (max 1000)"                                        default(100)
+// @Success      200           {object}  respond.Response{data=upload_service.RecoverUploadsResponse}
+// @Failure      400           {object}  respond.Response  "Parameter error"
+// @Failure      500           {object}  respond.Response  "Server error"
+// @Router       /v1/admin/uploads/recover [post]
+func (h *UploadHandler) RecoverUploads(c *gin.Context) {
+	stalledAfter, err := strconv.Atoi(c.DefaultQuery("stalledAfter", strconv.Itoa(int(upload_service.DefaultRecoverStalledAfter/time.Second))))
+	if err != nil || stalledAfter < 0 {
+		respond.InvalidParam(c, "invalid stalledAfter")
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(upload_service.DefaultRecoverLimit)))
+	if err != nil {
+		respond.InvalidParam(c, "invalid limit")
+		return
+	}
+
+	resp, err := h.uploadService.RecoverInFlightUploads(time.Duration(stalledAfter)*time.Second, limit)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// GetAssistentDashboard balances and fees of the assistent addresses
+// @Summary      Assistent address dashboard
+// @Description  Value flowing through the uploader's assistent (intermediate) keys, from the ledger of outputs the uploader broadcast to them: totals per chain and per user, largest balance first. balance is what sits in unspent outputs; feesPaid is what users paid in that is no longer unspent (network fees plus PIN dust). Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        chain   query     string  false  "Chain (mvc or doge; default all)"
+// @Param        cursor  query     int     false  "Offset of the user page"       default(0)
+// @Param        size    query     int     false  "Users per page (max 100)"      default(20)
+// @Success      200     {object}  respond.Response{data=upload_service.AssistentDashboard}
+// @Failure      400     {object}  respond.Response  "Parameter error"
+// @Failure      500     {object}  respond.Response  "Server error"
+// @Router       /v1/admin/assistants [get]
+func (h *UploadHandler) GetAssistentDashboard(c *gin.Context) {
+	chain := strings.ToLower(strings.TrimSpace(c.Query("chain")))
+	if chain != "" && chain != "mvc" && chain != "doge" {
+		respond.InvalidParam(c, "chain must be mvc or doge")
+		return
+	}
+	cursor, err := strconv.Atoi(c.DefaultQuery("cursor", "0"))
+	if err != nil || cursor < 0 {
+		respond.InvalidParam(c, "invalid cursor")
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil {
+		respond.InvalidParam(c, "invalid size")
+		return
+	}
+
+	dashboard, err := h.uploadService.GetAssistentDashboard(chain, cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, dashboard)
+}
+
+// GetAssistentDetail balance and unspent outputs of one assistent address
+// @Summary      Assistent address detail
+// @Description  Ledger totals and unspent (pending) outputs of the assistent address of a user, looked up by user address or by assistent address. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        address  path      string  true  "User address or assistent address"
+// @Success      200      {object}  respond.Response{data=upload_service.AssistentDetail}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/assistants/{address} [get]
+func (h *UploadHandler) GetAssistentDetail(c *gin.Context) {
+	address := strings.TrimSpace(c.Param("address"))
+	if address == "" {
+		respond.InvalidParam(c, "address is required")
+		return
+	}
+
+	detail, err := h.uploadService.GetAssistentDetail(address)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, detail)
+}
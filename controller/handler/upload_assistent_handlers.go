@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/model/dao"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RotateAssistentRequest rotate assistent key request
+type RotateAssistentRequest struct {
+	Chain   string `json:"chain" example:"mvc" description:"Blockchain: mvc (default) or doge"`
+	SweepTo string `json:"sweepTo" example:"assistent" description:"Where the retired address's unspent outputs go: assistent (the new assistent address, default) or user (the user's own address)"`
+	Reason  string `json:"reason" example:"key exposed in server logs" description:"Why the key is rotated, kept in the audit trail"`
+	FeeRate int64  `json:"feeRate" example:"1" description:"Sweep fee rate (sat/byte), 0 = chain default"`
+	Force   bool   `json:"force" example:"false" description:"Rotate even while upload tasks of the user may still broadcast transactions signed by the old key"`
+}
+
+// RotateAssistent replace the assistent key of a user
+// @Summary      Rotate assistent key
+// @Description  Generate a new assistent key for the user, retire the current one and sweep the unspent outputs the ledger holds for the retired address to the new assistent address or back to the user. Refused while upload tasks of the user are pending, processing or scheduled unless force is set. The rotation is recorded before the sweep; a failed sweep is reported in sweep_status/sweep_error and can be retried with /admin/assistants/rotations/{id}/sweep. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Accept       json
+// @Produce      json
+// @Param        address  path      string                  true  "User address"
+// @Param        request  body      RotateAssistentRequest  true  "Rotate request"
+// @Success      200      {object}  respond.Response{data=model.AssistentRotation}
+// @Failure      400      {object}  respond.Response  "Parameter error or upload tasks still active"
+// @Failure      404      {object}  respond.Response  "No assistent for this address"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/assistants/{address}/rotate [post]
+func (h *UploadHandler) RotateAssistent(c *gin.Context) {
+	var req RotateAssistentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	rotation, err := h.uploadService.RotateFileAssistent(&upload_service.RotateAssistentRequest{
+		Address:     strings.TrimSpace(c.Param("address")),
+		Chain:       strings.TrimSpace(req.Chain),
+		SweepTo:     strings.TrimSpace(req.SweepTo),
+		Reason:      req.Reason,
+		FeeRate:     req.FeeRate,
+		Force:       req.Force,
+		RequestedBy: c.ClientIP(),
+	})
+	switch {
+	case err == nil:
+		respond.Success(c, rotation)
+	case errors.Is(err, upload_service.ErrAssistentNotFound):
+		respond.NotFound(c, err.Error())
+	case errors.Is(err, upload_service.ErrInvalidRotation),
+		errors.Is(err, upload_service.ErrAssistentInUse),
+		errors.Is(err, dao.ErrAssistentChanged):
+		respond.InvalidParam(c, err.Error())
+	default:
+		respond.ServerError(c, err.Error())
+	}
+}
+
+// SweepRetiredAssistent retry the sweep of a rotation
+// @Summary      Sweep retired assistent address
+// @Description  Sweep the unspent outputs the ledger still holds for the address retired by a rotation, e.g. after its sweep failed. The outcome is recorded on the rotation. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        id       path      int  true   "Rotation ID"
+// @Param        feeRate  query     int  false  "Sweep fee rate (sat/byte), 0 = chain default"  default(0)
+// @Success      200      {object}  respond.Response{data=model.AssistentRotation}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      404      {object}  respond.Response  "Rotation not found"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/assistants/rotations/{id}/sweep [post]
+func (h *UploadHandler) SweepRetiredAssistent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respond.InvalidParam(c, "invalid rotation id")
+		return
+	}
+	feeRate, err := strconv.ParseInt(c.DefaultQuery("feeRate", "0"), 10, 64)
+	if err != nil || feeRate < 0 {
+		respond.InvalidParam(c, "invalid feeRate")
+		return
+	}
+
+	rotation, err := h.uploadService.SweepRetiredAssistent(id, feeRate)
+	if errors.Is(err, upload_service.ErrRotationNotFound) {
+		respond.NotFound(c, err.Error())
+		return
+	}
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, rotation)
+}
+
+// ListAssistentRotations key rotations of a user
+// @Summary      List assistent key rotations
+// @Description  Audit trail of the assistent key rotations of a user address (or the rotation that retired an assistent address), newest first. Private keys are never included. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        address  path      string  true   "User address or retired assistent address"
+// @Param        cursor   query     int     false  "Cursor (last rotation ID)"  default(0)
+// @Param        size     query     int     false  "Page size (max 100)"        default(20)
+// @Success      200      {object}  respond.Response{data=upload_service.AssistentRotationList}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/assistants/{address}/rotations [get]
+func (h *UploadHandler) ListAssistentRotations(c *gin.Context) {
+	address := strings.TrimSpace(c.Param("address"))
+	if address == "" {
+		respond.InvalidParam(c, "address is required")
+		return
+	}
+	cursor, err := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil || cursor < 0 {
+		respond.InvalidParam(c, "invalid cursor")
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil {
+		respond.InvalidParam(c, "invalid size")
+		return
+	}
+
+	list, err := h.uploadService.ListAssistentRotations(address, cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, list)
+}
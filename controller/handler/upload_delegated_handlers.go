@@ -0,0 +1,296 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderNameApiKey request header carrying a delegated upload API key
+const HeaderNameApiKey = "X-Api-Key"
+
+// DelegatedUploadRequest a chunked upload funded by the operator hot wallet
+type DelegatedUploadRequest struct {
+	MetaId       string                               `json:"metaId" binding:"required" example:"metaid_abc123" description:"MetaID"`
+	Address      string                               `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address, owner of the file and invoiced for the upload"`
+	FileName     string                               `json:"fileName" binding:"required" example:"example.jpg" description:"File name"`
+	Content      string                               `json:"content" description:"Base64 encoded file content (optional if storageKey is provided)"`
+	StorageKey   string                               `json:"storageKey" description:"Storage key from a multipart upload or POST /files/fetch-url (optional, if provided, file will be read from storage)"`
+	Path         string                               `json:"path" binding:"required" example:"/file" description:"MetaID path; chunks and index are inscribed under {base}/file/_chunk and {base}/file/index"`
+	Operation    string                               `json:"operation" example:"create" description:"Operation type (create/update)"`
+	ContentType  string                               `json:"contentType" example:"image/jpeg" description:"MIME type"`
+	FeeRate      int64                                `json:"feeRate" example:"1" description:"Fee rate (optional, defaults to config)"`
+	StorageClass string                               `json:"storageClass" example:"hot" description:"Storage class hint: hot, cold or ephemeral (default hot)"`
+	Compression  string                               `json:"compression" example:"none" description:"Compression of content declared in the v2 file index: none or gzip (default none; content must already be compressed)"`
+	Encryption   *metaid_protocols.MetaFileEncryption `json:"encryption" description:"Encryption envelope declared in the v2 file index (optional; content must already be encrypted)"`
+	DeltaBase    string                               `json:"deltaBasePinId" example:"4e3f...a1i0" description:"Inscribe only the binary delta of content against the indexed file of this PIN (optional, needs uploader.delta.indexer_url; not with compression gzip or encryption)"`
+	SHA256       string                               `json:"sha256" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" description:"SHA256 of the file content (hex, optional); content received with another checksum is rejected before the hot wallet pays anything (code 42201)"`
+}
+
+// DelegatedSettleRequest settles the unsettled charges of a user
+type DelegatedSettleRequest struct {
+	Address   string  `json:"address" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"User address"`
+	Client    string  `json:"client" example:"my-app" description:"Only charges requested by this client (optional)"`
+	ChargeIds []int64 `json:"chargeIds" description:"Only these charges (optional, default all unsettled charges of the user)"`
+	Reference string  `json:"reference" binding:"required" example:"payment-2026-10-001" description:"Payment reference: txid, invoice number, ..."`
+}
+
+// delegatedClient resolves the request's API key to a delegated upload
+// client, answering the request itself when it cannot
+func delegatedClient(c *gin.Context) (string, bool) {
+	client, err := upload_service.DelegatedClient(c.GetHeader(HeaderNameApiKey))
+	switch {
+	case errors.Is(err, upload_service.ErrDelegatedDisabled):
+		respond.NotFound(c, err.Error())
+		return "", false
+	case err != nil:
+		respond.Error(c, respond.CodeUnauthorized, err.Error())
+		return "", false
+	}
+	return client, true
+}
+
+// parseChargeQuery reads the status, cursor and size query parameters of the charge listings
+func parseChargeQuery(c *gin.Context) (string, int64, int, bool) {
+	status := c.Query("status")
+	if status != "" && status != model.ChargeUnsettled && status != model.ChargeSettled {
+		respond.InvalidParam(c, "status must be unsettled or settled")
+		return "", 0, 0, false
+	}
+	cursor, err := strconv.ParseInt(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil || cursor < 0 {
+		respond.InvalidParam(c, "invalid cursor")
+		return "", 0, 0, false
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil {
+		respond.InvalidParam(c, "invalid size")
+		return "", 0, 0, false
+	}
+	return status, cursor, size, true
+}
+
+// DelegatedUpload chunked upload funded by the operator hot wallet
+// @Summary      Delegated (server-funded) chunked upload
+// @Description  Chunked MVC upload without client-built pre-transactions: the operator hot wallet pays the estimated chunk and index funding to the user's assistant address, the uploader builds, signs and broadcasts every transaction, and the cost is recorded as an unsettled charge of the user (see /admin/delegated/invoices). The index PIN is signed by the user's assistant address and its first output pays the user. Requires uploader.delegated.enabled and an API key from uploader.delegated.api_keys in the X-Api-Key header (code 40100 otherwise); uploads estimated above uploader.delegated.max_fee_per_upload are refused.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        X-Api-Key        header    string                  true   "Delegated upload API key"
+// @Param        request          body      DelegatedUploadRequest  true   "Delegated upload request"
+// @Param        Idempotency-Key  header    string                  false  "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours"
+// @Success      200        {object}  respond.Response{data=upload_service.DelegatedUploadResponse}  "Uploaded"
+// @Failure      400        {object}  respond.Response  "Parameter error or fee limit exceeded"
+// @Failure      401        {object}  respond.Response  "Missing or unknown API key"
+// @Failure      404        {object}  respond.Response  "Delegated uploads disabled"
+// @Failure      409        {object}  respond.Response  "Idempotency-Key used with another request, or its first request still running (code 40900)"
+// @Failure      422        {object}  respond.Response{data=respond.TxRejectedData}  "Built transaction rejected by node policy before broadcasting (code 42200), or content not matching the declared sha256 (code 42201, data respond.ChecksumMismatchData)"
+// @Failure      500        {object}  respond.Response  "Server error"
+// @Router       /v1/files/delegated-upload [post]
+func (h *UploadHandler) DelegatedUpload(c *gin.Context) {
+	client, ok := delegatedClient(c)
+	if !ok {
+		return
+	}
+	limitRequestBody(c, maxJSONBodyBytes())
+
+	var req DelegatedUploadRequest
+	if err := bindJSONWithOptionalGzip(c, &req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if _, err := upload_service.ParseStorageClass(req.StorageClass); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if err := upload_service.ValidateMetaFileEncoding(req.Compression, req.Encryption); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	var content []byte
+	var err error
+	if req.StorageKey != "" {
+		content, err = h.uploadService.GetFileFromStorage(req.StorageKey)
+		if err != nil {
+			respond.InvalidParam(c, "failed to read file from storage: "+err.Error())
+			return
+		}
+	} else if req.Content != "" {
+		content, err = base64.StdEncoding.DecodeString(req.Content)
+		if err != nil {
+			respond.InvalidParam(c, "invalid base64 content: "+err.Error())
+			return
+		}
+	} else {
+		respond.InvalidParam(c, "either content or storageKey must be provided")
+		return
+	}
+
+	resp, err := h.uploadService.DelegatedUpload(client, &upload_service.ChunkedUploadRequest{
+		MetaId:         req.MetaId,
+		Address:        strings.TrimSpace(req.Address),
+		FileName:       req.FileName,
+		Content:        content,
+		Path:           req.Path,
+		Operation:      req.Operation,
+		ContentType:    req.ContentType,
+		FeeRate:        req.FeeRate,
+		StorageClass:   req.StorageClass,
+		Compression:    req.Compression,
+		Encryption:     req.Encryption,
+		DeltaBasePinId: req.DeltaBase,
+		ContentSHA256:  req.SHA256,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, upload_service.ErrDelegatedFunding):
+			respond.BroadcastError(c, err)
+		case errors.Is(err, upload_service.ErrDelegatedFeeLimit), errors.Is(err, upload_service.ErrUnsupportedBasePath),
+			errors.Is(err, upload_service.ErrInvalidDeltaUpload), errors.Is(err, upload_service.ErrInvalidChecksum):
+			respond.InvalidParam(c, err.Error())
+		default:
+			respond.BroadcastError(c, err)
+		}
+		return
+	}
+	respond.Success(c, resp)
+}
+
+// ListDelegatedClientCharges charges of the uploads an API client requested
+// @Summary      List my delegated upload charges
+// @Description  Charges recorded for the delegated uploads requested with the caller's API key (X-Api-Key), newest first, so the client can invoice its users.
+// @Tags         File Upload
+// @Produce      json
+// @Param        X-Api-Key  header    string  true   "Delegated upload API key"
+// @Param        address    query     string  false  "Only charges of this user address"
+// @Param        status     query     string  false  "unsettled or settled (default both)"
+// @Param        cursor     query     int     false  "Cursor (last charge ID)"  default(0)
+// @Param        size       query     int     false  "Page size (max 100)"      default(20)
+// @Success      200        {object}  respond.Response{data=upload_service.DelegatedChargeList}
+// @Failure      400        {object}  respond.Response  "Parameter error"
+// @Failure      401        {object}  respond.Response  "Missing or unknown API key"
+// @Failure      500        {object}  respond.Response  "Server error"
+// @Router       /v1/files/delegated/charges [get]
+func (h *UploadHandler) ListDelegatedClientCharges(c *gin.Context) {
+	client, ok := delegatedClient(c)
+	if !ok {
+		return
+	}
+	status, cursor, size, ok := parseChargeQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := dao.DelegatedChargeFilter{Client: client, Address: strings.TrimSpace(c.Query("address")), Status: status}
+	list, err := h.uploadService.ListDelegatedCharges(filter, cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, list)
+}
+
+// ListDelegatedCharges charges of delegated uploads
+// @Summary      List delegated upload charges
+// @Description  Charges of delegated (hot wallet funded) uploads, newest first, optionally for one client, user and settlement status. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        client   query     string  false  "API client name"
+// @Param        address  query     string  false  "User address"
+// @Param        status   query     string  false  "unsettled or settled (default both)"
+// @Param        cursor   query     int     false  "Cursor (last charge ID)"  default(0)
+// @Param        size     query     int     false  "Page size (max 100)"      default(20)
+// @Success      200      {object}  respond.Response{data=upload_service.DelegatedChargeList}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/delegated/charges [get]
+func (h *UploadHandler) ListDelegatedCharges(c *gin.Context) {
+	status, cursor, size, ok := parseChargeQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := dao.DelegatedChargeFilter{
+		Client:  strings.TrimSpace(c.Query("client")),
+		Address: strings.TrimSpace(c.Query("address")),
+		Status:  status,
+	}
+	list, err := h.uploadService.ListDelegatedCharges(filter, cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, list)
+}
+
+// ListDelegatedInvoices what each user owes for delegated uploads
+// @Summary      Delegated upload invoices
+// @Description  Charges summed per API client and user, largest amount first: by default what is still unsettled, with status=settled what was paid. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Param        client  query     string  false  "API client name"
+// @Param        status  query     string  false  "unsettled or settled"  default(unsettled)
+// @Param        cursor  query     int     false  "Offset of the page"    default(0)
+// @Param        size    query     int     false  "Page size (max 100)"   default(20)
+// @Success      200     {object}  respond.Response{data=upload_service.DelegatedInvoiceList}
+// @Failure      400     {object}  respond.Response  "Parameter error"
+// @Failure      500     {object}  respond.Response  "Server error"
+// @Router       /v1/admin/delegated/invoices [get]
+func (h *UploadHandler) ListDelegatedInvoices(c *gin.Context) {
+	status, cursor, size, ok := parseChargeQuery(c)
+	if !ok {
+		return
+	}
+
+	list, err := h.uploadService.ListDelegatedInvoices(strings.TrimSpace(c.Query("client")), status, int(cursor), size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+	respond.Success(c, list)
+}
+
+// SettleDelegatedCharges mark delegated upload charges paid
+// @Summary      Settle delegated upload charges
+// @Description  Mark the unsettled charges of a user settled with a payment reference: all of them, those of one client, or the listed chargeIds. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      DelegatedSettleRequest  true  "Settle request"
+// @Success      200      {object}  respond.Response{data=upload_service.DelegatedSettleResponse}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/delegated/settle [post]
+func (h *UploadHandler) SettleDelegatedCharges(c *gin.Context) {
+	var req DelegatedSettleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	if len(req.ChargeIds) > 1000 {
+		respond.InvalidParam(c, "at most 1000 chargeIds per request")
+		return
+	}
+
+	resp, err := h.uploadService.SettleDelegatedCharges(&upload_service.DelegatedSettleRequest{
+		Address:   strings.TrimSpace(req.Address),
+		Client:    strings.TrimSpace(req.Client),
+		ChargeIds: req.ChargeIds,
+		Reference: strings.TrimSpace(req.Reference),
+	})
+	if err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+	respond.Success(c, resp)
+}
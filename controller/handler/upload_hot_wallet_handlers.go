@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HotWalletDepositRequest record a deposit to the chunk funding hot wallet
+type HotWalletDepositRequest struct {
+	TxId string `json:"txId" binding:"required" example:"4e3f0c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f" description:"Transaction paying the hot wallet address"`
+}
+
+// GetHotWalletStatus chunk funding hot wallet status
+// @Summary      Chunk funding hot wallet
+// @Description  Chunk funding strategy of the uploader, the hot wallet address (uploader.chunk_funding.hot_wallet_key) and its ledger totals per status: available, locked (by an upload not broadcast yet), pending (change not broadcast yet) and spent. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Produce      json
+// @Success      200      {object}  respond.Response{data=upload_service.HotWalletStatus}
+// @Failure      404      {object}  respond.Response  "No hot wallet configured"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/hot-wallet [get]
+func (h *UploadHandler) GetHotWalletStatus(c *gin.Context) {
+	status, err := h.uploadService.GetHotWalletStatus()
+	switch {
+	case err == nil:
+		respond.Success(c, status)
+	case errors.Is(err, upload_service.ErrHotWalletNotConfigured):
+		respond.NotFound(c, err.Error())
+	default:
+		respond.ServerError(c, err.Error())
+	}
+}
+
+// RecordHotWalletDeposit add a deposit to the hot wallet ledger
+// @Summary      Record hot wallet deposit
+// @Description  Add the outputs of an MVC transaction paying the hot wallet address to its spendable outputs. Chunks are only funded from outputs in the ledger, so every deposit must be recorded. Transactions spending hot wallet outputs are refused; recording a deposit again changes nothing. Only registered when uploader.admin_enabled is set.
+// @Tags         Uploader Admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      HotWalletDepositRequest  true  "Deposit transaction"
+// @Success      200      {object}  respond.Response{data=upload_service.HotWalletDeposit}
+// @Failure      400      {object}  respond.Response  "Parameter error, or the transaction pays nothing to the hot wallet"
+// @Failure      404      {object}  respond.Response  "No hot wallet configured"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/admin/hot-wallet/deposits [post]
+func (h *UploadHandler) RecordHotWalletDeposit(c *gin.Context) {
+	var req HotWalletDepositRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	deposit, err := h.uploadService.RecordHotWalletDeposit(req.TxId)
+	switch {
+	case err == nil:
+		respond.Success(c, deposit)
+	case errors.Is(err, upload_service.ErrHotWalletNotConfigured):
+		respond.NotFound(c, err.Error())
+	case errors.Is(err, upload_service.ErrInvalidDeposit):
+		respond.InvalidParam(c, err.Error())
+	default:
+		respond.ServerError(c, err.Error())
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeepFileRequest signed keep or unkeep request
+type KeepFileRequest struct {
+	PinId     string `json:"pinId" binding:"required" example:"4e3f0c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2fi0" description:"PIN ID of the uploaded file"`
+	PublicKey string `json:"publicKey" binding:"required" example:"02b4632d08485ff1df2db55b9dafd23347d1c47a457072a1e87be26896549a8737" description:"Hex public key of the file's uploader address"`
+	Timestamp int64  `json:"timestamp" binding:"required" example:"1767225600" description:"Unix seconds the request was signed at (within uploader.keep.signature_max_age_secs of the server time, and after the file's previous request)"`
+	Signature string `json:"signature" binding:"required" example:"3045022100..." description:"Hex DER ECDSA signature of SHA256(\"meta-file-system keep <pinId> <timestamp>\"), or unkeep for unkeep requests"`
+}
+
+func (r *KeepFileRequest) toService() *upload_service.KeepRequest {
+	return &upload_service.KeepRequest{
+		PinId:     r.PinId,
+		PublicKey: r.PublicKey,
+		Timestamp: r.Timestamp,
+		Signature: r.Signature,
+	}
+}
+
+// KeepFile keep an uploaded file
+// @Summary      Keep file
+// @Description  Ask the uploader to keep the local records of an uploaded file: kept files are never pruned, whatever their storage class, until unkept. The request is signed by the key of the file's uploader address (P2PKH). Each address may keep uploader.keep.max_files_per_owner files and uploader.keep.max_mb_per_owner MB. Keeping a kept file changes nothing. Requires uploader.keep.enabled.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        request  body      KeepFileRequest  true  "Signed keep request"
+// @Success      200      {object}  respond.Response{data=upload_service.KeepStatus}
+// @Failure      400      {object}  respond.Response  "Parameter error, keeping disabled, or the upload did not succeed"
+// @Failure      403      {object}  respond.Response  "40300: the signature does not prove ownership, is too old or was already used"
+// @Failure      404      {object}  respond.Response  "No uploaded file with this PIN ID"
+// @Failure      429      {object}  respond.Response  "42901: keeping the file would exceed the owner's quota"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/files/keep [post]
+func (h *UploadHandler) KeepFile(c *gin.Context) {
+	var req KeepFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	status, err := h.uploadService.KeepFile(req.toService())
+	if err != nil {
+		keepError(c, err)
+		return
+	}
+	respond.Success(c, status)
+}
+
+// UnkeepFile release a kept file
+// @Summary      Unkeep file
+// @Description  Release a kept file: its storage class applies again and it no longer counts against the owner's quota. Signed like keep requests with the action unkeep. Requires uploader.keep.enabled.
+// @Tags         File Upload
+// @Accept       json
+// @Produce      json
+// @Param        request  body      KeepFileRequest  true  "Signed unkeep request"
+// @Success      200      {object}  respond.Response{data=upload_service.KeepStatus}
+// @Failure      400      {object}  respond.Response  "Parameter error or keeping disabled"
+// @Failure      403      {object}  respond.Response  "40300: the signature does not prove ownership, is too old or was already used"
+// @Failure      404      {object}  respond.Response  "The file is not kept"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/files/unkeep [post]
+func (h *UploadHandler) UnkeepFile(c *gin.Context) {
+	var req KeepFileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond.InvalidParam(c, err.Error())
+		return
+	}
+
+	status, err := h.uploadService.UnkeepFile(req.toService())
+	if err != nil {
+		keepError(c, err)
+		return
+	}
+	respond.Success(c, status)
+}
+
+// ListKeptFiles files an address keeps
+// @Summary      List kept files
+// @Description  Files an address keeps, most recently kept first (at most 1000), with its usage and limits
+// @Tags         File Upload
+// @Produce      json
+// @Param        address  query     string  true  "Uploader address"
+// @Success      200      {object}  respond.Response{data=upload_service.KeptFiles}
+// @Failure      400      {object}  respond.Response  "Missing address"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v1/files/keep [get]
+func (h *UploadHandler) ListKeptFiles(c *gin.Context) {
+	kept, err := h.uploadService.ListKeptFiles(c.Query("address"))
+	if err != nil {
+		keepError(c, err)
+		return
+	}
+	respond.Success(c, kept)
+}
+
+// keepError maps keep and unkeep errors to responses
+func keepError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, upload_service.ErrKeepDisabled), errors.Is(err, upload_service.ErrInvalidKeepRequest):
+		respond.InvalidParam(c, err.Error())
+	case errors.Is(err, upload_service.ErrKeepForbidden):
+		respond.Error(c, respond.CodeForbidden, err.Error())
+	case errors.Is(err, upload_service.ErrUploadedFileNotFound), errors.Is(err, upload_service.ErrFileNotKept):
+		respond.NotFound(c, err.Error())
+	case errors.Is(err, upload_service.ErrKeepQuotaExceeded):
+		respond.KeepQuotaExceeded(c, err)
+	default:
+		respond.ServerError(c, err.Error())
+	}
+}
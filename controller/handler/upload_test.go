@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/service/upload_service"
+)
+
+func TestListUploadedFilesValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing owner", "/api/v1/files/uploads"},
+		{"blank owner", "/api/v1/files/uploads?address=%20&metaId=%20"},
+		{"invalid status", "/api/v1/files/uploads?address=1abc&status=rejected"},
+		{"invalid cursor", "/api/v1/files/uploads?metaId=abc&cursor=x"},
+		{"invalid size", "/api/v1/files/uploads?metaId=abc&size=x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tt.query, nil)
+
+			// A nil service is never reached when the parameters are invalid
+			handler := &UploadHandler{}
+			handler.ListUploadedFiles(c)
+
+			var resp map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if code, ok := resp["code"].(float64); !ok || int(code) != 40000 {
+				t.Fatalf("code = %v, want 40000", resp["code"])
+			}
+		})
+	}
+}
+
+func TestGetUploadedFileRequiresFileID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "fileId", Value: "  "}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/files/uploads/%20%20", nil)
+
+	handler := &UploadHandler{}
+	handler.GetUploadedFile(c)
+
+	var resp map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if code, ok := resp["code"].(float64); !ok || int(code) != 40000 {
+		t.Fatalf("code = %v, want 40000", resp["code"])
+	}
+}
+
+// setTestUploaderDB opens a SQLite uploader database for the test
+func setTestUploaderDB(t *testing.T) {
+	t.Helper()
+	prevCfg, prevDB := conf.Cfg, database.UploaderDB
+	conf.Cfg = &conf.Config{}
+	conf.Cfg.Database.UploaderType = string(database.DBTypeSQLite)
+	conf.Cfg.Database.SqlitePath = filepath.Join(t.TempDir(), "uploader.db")
+	if err := database.InitUploaderDB(); err != nil {
+		t.Fatalf("InitUploaderDB: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.CloseUploaderDB()
+		conf.Cfg, database.UploaderDB = prevCfg, prevDB
+	})
+}
+
+func TestGetUploadTaskStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	setTestUploaderDB(t)
+	handler := NewUploadHandler(upload_service.NewUploadService(nil))
+
+	getStats := func(t *testing.T) map[string]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/files/tasks/stats?hours=24", nil)
+		handler.GetUploadTaskStats(c)
+
+		var resp struct {
+			Code int            `json:"code"`
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", w.Body.String(), err)
+		}
+		if resp.Code != 0 {
+			t.Fatalf("code = %d, body %s", resp.Code, w.Body.String())
+		}
+		return resp.Data
+	}
+
+	// No finished tasks: the rate is 0, not NaN (which would not encode)
+	t.Run("no finished tasks", func(t *testing.T) {
+		data := getStats(t)
+		if data["expiredRate"] != 0.0 || data["completed"] != 0.0 || data["expired"] != 0.0 {
+			t.Errorf("data = %v, want zero counts and expiredRate 0", data)
+		}
+	})
+
+	finished := time.Now().Add(-time.Hour)
+	taskDAO := dao.NewFileUploaderTaskDAO()
+	for i, status := range []model.Status{model.StatusSuccess, model.StatusSuccess, model.StatusSuccess, model.TaskStatusExpired, model.StatusFailed} {
+		if err := taskDAO.Create(&model.FileUploaderTask{TaskId: string(rune('a' + i)), Status: status, FinishedAt: &finished}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Failed tasks are not part of the rate: expired / (completed + expired)
+	t.Run("expired rate", func(t *testing.T) {
+		data := getStats(t)
+		if data["completed"] != 3.0 || data["expired"] != 1.0 || data["failed"] != 1.0 || data["expiredRate"] != 0.25 {
+			t.Errorf("data = %v, want 3 completed, 1 expired, 1 failed, expiredRate 0.25", data)
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListUploadTasksV2 list upload tasks by address (v2 page)
+// @Summary      List upload tasks (v2)
+// @Description  Chunked upload tasks of an address, newest first, as a v2 page
+// @Tags         File Upload
+// @Produce      json
+// @Param        address  query     string  true   "User address"
+// @Param        cursor   query     string  false  "nextCursor of the previous page"
+// @Param        size     query     int     false  "Page size (max 100)"  default(20)
+// @Success      200      {object}  respond.Response{data=respond.UploadTaskPage}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v2/files/tasks [get]
+func (h *UploadHandler) ListUploadTasksV2(c *gin.Context) {
+	address := c.Query("address")
+	if strings.TrimSpace(address) == "" {
+		respond.InvalidParam(c, "address is required")
+		return
+	}
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil {
+		respond.InvalidParam(c, "invalid size")
+		return
+	}
+
+	resp, err := h.uploadService.ListTasksByAddress(address, cursor, size)
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.UploadTaskPage{
+		Items:      respond.ToUploadTaskList(resp.Tasks),
+		NextCursor: respond.PageCursor(resp.NextCursor, resp.HasMore),
+		HasMore:    resp.HasMore,
+	})
+}
+
+// ListUploadedFilesV2 list a user's uploaded files (v2 page)
+// @Summary      List uploaded files (v2)
+// @Description  Upload history of a MetaID and/or address, newest first, as a v2 page; same filters as the v1 route
+// @Tags         File Upload
+// @Produce      json
+// @Param        address  query     string  false  "Uploader address (address and/or metaId is required)"
+// @Param        metaId   query     string  false  "MetaID (address and/or metaId is required)"
+// @Param        status   query     string  false  "Upload status: pending, success or failed"
+// @Param        cursor   query     string  false  "nextCursor of the previous page"
+// @Param        size     query     int     false  "Page size (max 100)"  default(20)
+// @Success      200      {object}  respond.Response{data=respond.UploadedFilePage}
+// @Failure      400      {object}  respond.Response  "Parameter error"
+// @Failure      500      {object}  respond.Response  "Server error"
+// @Router       /v2/files/uploads [get]
+func (h *UploadHandler) ListUploadedFilesV2(c *gin.Context) {
+	address := strings.TrimSpace(c.Query("address"))
+	metaId := strings.TrimSpace(c.Query("metaId"))
+	if address == "" && metaId == "" {
+		respond.InvalidParam(c, "address or metaId is required")
+		return
+	}
+
+	status := model.Status(c.Query("status"))
+	switch status {
+	case "", model.StatusPending, model.StatusSuccess, model.StatusFailed:
+	default:
+		respond.InvalidParam(c, "invalid status, must be pending, success or failed")
+		return
+	}
+
+	cursor, ok := numericCursor(c)
+	if !ok {
+		return
+	}
+	size, err := strconv.Atoi(c.DefaultQuery("size", "20"))
+	if err != nil {
+		respond.InvalidParam(c, "invalid size")
+		return
+	}
+
+	resp, err := h.uploadService.ListUploadedFiles(&upload_service.UploadedFileListRequest{
+		MetaId:  metaId,
+		Address: address,
+		Status:  status,
+		Cursor:  cursor,
+		Size:    size,
+	})
+	if err != nil {
+		respond.ServerError(c, err.Error())
+		return
+	}
+
+	respond.Success(c, respond.UploadedFilePage{
+		Items:      respond.ToUploadedFileList(resp.Files, getIndexerBaseUrl()),
+		NextCursor: respond.PageCursor(resp.NextCursor, resp.HasMore),
+		HasMore:    resp.HasMore,
+	})
+}
@@ -1,14 +1,18 @@
 package controller
 
 import (
+	"net/http"
+
 	"meta-file-system/conf"
 	"meta-file-system/controller/handler"
 	"meta-file-system/controller/respond"
+	"meta-file-system/database"
 	indexerDocs "meta-file-system/docs/indexer"
+	"meta-file-system/metrics"
 	"meta-file-system/service/indexer_service"
 	"meta-file-system/storage"
+	"meta-file-system/web/explorer"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -20,21 +24,23 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 	indexerDocs.SwaggerInfoindexer.Host = conf.Cfg.Indexer.SwaggerBaseUrl
 
 	// Create Gin engine
-	r := gin.Default()
-
-	// Add CORS middleware
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Allow all origins, can be configured to specific domains
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
-		AllowCredentials: false,     // Avoid wildcard-origin credentials, keep behavior consistent across browsers
-		MaxAge:           12 * 3600, // 12 hours
-	}))
+	r := newEngine()
+
+	// Add CORS and response compression middleware (http config)
+	r.Use(corsMiddleware(httpConfig().Cors))
+	if httpConfig().Gzip {
+		r.Use(gzipMiddleware())
+	}
+
+	// Log requests (http.access_log) and record the request metrics
+	r.Use(accessLogMiddleware(httpConfig().AccessLog))
 
 	// Add timing middleware
 	r.Use(respond.TimingMiddleware())
 
+	// Reject oversized request bodies (http.max_body_mb)
+	r.Use(bodyLimitMiddleware(maxBodyBytes()))
+
 	// Create indexer file service instance
 	indexerFileService := indexer_service.NewIndexerFileService(stor)
 
@@ -58,6 +64,10 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 		indexerQueryHandler.SetIndexerService(indexerService)
 	}
 
+	// Serve mapped custom domains (Host header) from their site or MetaID;
+	// registered before the routes so it runs for every path
+	r.Use(indexerQueryHandler.DomainRouting())
+
 	// API v1 route group
 	v1 := r.Group("/api/v1")
 	{
@@ -71,8 +81,16 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 		// Gin radix-tree conflict with the parameterized route below).
 		files.GET("/status/:pinId", indexerQueryHandler.GetFileStatus)
 
+		// Get metadata for many files by PIN IDs
+		files.POST("/batch", indexerQueryHandler.GetFilesByPinIDs)
+
+		// Get total / per-chain file count (registered before /:pinId, like /status)
+		files.GET("/count", indexerQueryHandler.GetFilesCount)
+
 		// Get file by PIN ID
 		files.GET("/:pinId", indexerQueryHandler.GetByPinID)
+		// List chunks and merge status of a multi-chunk file by index PIN ID
+		files.GET("/:pinId/chunks", indexerQueryHandler.GetFileChunks)
 
 			// Get file content by PIN ID
 			files.GET("/content/:pinId", indexerQueryHandler.GetFileContent)
@@ -100,6 +118,12 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 
 			// Get files by creator MetaID
 			files.GET("/metaid/:metaidOrGlobalMetaId", indexerQueryHandler.GetByCreatorMetaID)
+			// Get file count by creator MetaID or GlobalMetaID
+			files.GET("/metaid/:metaidOrGlobalMetaId/count", indexerQueryHandler.GetCreatorFilesCount)
+			// Get a directory of the creator's files by MetaID path
+			files.GET("/metaid/:metaidOrGlobalMetaId/tree", indexerQueryHandler.GetPathTree)
+			// Download files by PIN IDs, site manifest or creator directory as zip / tar.gz
+			files.GET("/archive", indexerQueryHandler.GetArchive)
 			// Get files by file extension (global), reverse time order; extension as query (array supported)
 			files.GET("/extension", indexerQueryHandler.GetFilesByExtension)
 			// Get files by globalMetaID and file extension; extension as query (array supported)
@@ -145,8 +169,39 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 
 		// Statistics route
 		v1.GET("/stats", indexerQueryHandler.GetStats)
+		v1.GET("/stats/daily", indexerQueryHandler.GetDailyStats)
+		v1.GET("/stats/weekly", indexerQueryHandler.GetWeeklyStats)
 
-		// Info routes (MetaID format, same as /api/info for Swagger basePath /api/v1)
+		// Peer gateways asked for missing content (read by peers to discover each other)
+		v1.GET("/peers", indexerQueryHandler.ListPeers)
+
+		// Feed and sitemap routes
+		feed := v1.Group("/feed")
+		{
+			feed.GET("/rss", indexerQueryHandler.GetRSSFeed)
+			feed.GET("/atom", indexerQueryHandler.GetAtomFeed)
+		}
+		v1.GET("/sitemap.xml", indexerQueryHandler.GetSitemap)
+
+		// Change feed route (ordered log of indexing actions)
+		v1.GET("/changes", indexerQueryHandler.GetChanges)
+
+		// Resolve content-addressable mfs://{pinId} and mfs://{sha256} URIs
+		v1.GET("/resolve", indexerQueryHandler.ResolveMfs)
+
+		// MetaID of an address under metaid.derivation
+		v1.GET("/metaid/derive", indexerQueryHandler.DeriveMetaID)
+
+		// Watchlist routes (notify on new PINs of watched addresses / MetaIDs);
+		// adding and removing watches is an admin operation
+		watchlist := v1.Group("/watchlist")
+		{
+			watchlist.GET("", indexerQueryHandler.ListWatches)
+			watchlist.GET("/events", indexerQueryHandler.ListWatchEvents)
+			watchlist.GET("/ws", indexerQueryHandler.WatchEventsWebSocket)
+		}
+
+		// Info routes (MetaID format, same as /api/info, documented in Swagger)
 		infoV1 := v1.Group("/info")
 		{
 			infoV1.GET("/metaid/:metaidOrGlobalMetaId", indexerQueryHandler.GetMetaIDUserInfoByMetaID)
@@ -170,10 +225,57 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 
 				// Stop rescan
 				admin.POST("/rescan/stop", indexerQueryHandler.StopRescan)
+
+				// Download the per-PIN report of a rescan task
+				admin.GET("/rescan/:taskId/report", indexerQueryHandler.GetRescanReport)
+
+				// Maintained counters and reconciliation
+				admin.GET("/counters", indexerQueryHandler.GetCounters)
+				admin.POST("/counters/reconcile", indexerQueryHandler.ReconcileCounters)
+
+				// Check / repair latest_* entries against history
+				admin.POST("/latest/repair", indexerQueryHandler.RepairLatestIndexes)
+
+				// Storage backend migration progress
+				admin.GET("/storage-migration", indexerQueryHandler.GetStorageMigration)
+
+				// Files and chunks whose storage write is queued for retry
+				admin.GET("/storage/pending", indexerQueryHandler.GetPendingStorage)
+
+				// Files whose creator address lookup is queued for retry
+				admin.GET("/creator/pending", indexerQueryHandler.GetPendingCreators)
+
+				// Pause / resume scanning of a chain
+				admin.POST("/chains/:chain/pause", indexerQueryHandler.PauseChain)
+				admin.POST("/chains/:chain/resume", indexerQueryHandler.ResumeChain)
+
+				// Watchlist management
+				admin.POST("/watchlist", indexerQueryHandler.CreateWatch)
+				admin.DELETE("/watchlist/:id", indexerQueryHandler.DeleteWatch)
+
+				// Custom domain mappings (Host-header routing to sites / MetaIDs)
+				admin.GET("/domains", indexerQueryHandler.ListDomains)
+				admin.POST("/domains", indexerQueryHandler.SaveDomain)
+				admin.DELETE("/domains/:domain", indexerQueryHandler.DeleteDomain)
 			}
 		}
 	}
 
+	// API v2 list routes: same listings as v1 in the page envelope
+	// {items, nextCursor, hasMore, total?}
+	v2 := r.Group("/api/v2")
+	{
+		v2.GET("/files", indexerQueryHandler.ListFilesV2)
+		v2.GET("/files/creator/:address", indexerQueryHandler.GetByCreatorAddressV2)
+		v2.GET("/files/metaid/:metaidOrGlobalMetaId", indexerQueryHandler.GetByCreatorMetaIDV2)
+		v2.GET("/files/extension", indexerQueryHandler.GetFilesByExtensionV2)
+		v2.GET("/files/metaid/:metaidOrGlobalMetaId/extension", indexerQueryHandler.GetFilesByGlobalMetaIDAndExtensionV2)
+		v2.GET("/files/keyword/:keyword/extension", indexerQueryHandler.GetFilesByKeywordAndExtensionV2)
+		v2.GET("/users", indexerQueryHandler.ListUserInfoV2)
+		v2.GET("/watchlist/events", indexerQueryHandler.ListWatchEventsV2)
+		v2.GET("/changes", indexerQueryHandler.GetChangesV2)
+	}
+
 	api := r.Group("/api")
 	{
 		// MetaID compatible info routes
@@ -197,6 +299,22 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 	r.GET("/content/:pinId", indexerQueryHandler.GetAvatarContentByPinID)
 	r.GET("/thumbnail/:pinId", indexerQueryHandler.GetAvatarThumbnailByPinID)
 
+	// Sitemap at the conventional root location
+	r.GET("/sitemap.xml", indexerQueryHandler.GetSitemap)
+
+	// Static site hosting from a site manifest PIN (index.html, SPA fallback)
+	r.GET("/site/:manifestPinId/*path", indexerQueryHandler.ServeSite)
+	r.HEAD("/site/:manifestPinId/*path", indexerQueryHandler.ServeSite)
+
+	// Read-only WebDAV gateway over the creators' path trees
+	if conf.Cfg.Indexer.WebDAV.Enabled {
+		webdavHandler := indexerQueryHandler.WebDAV()
+		for _, method := range handler.WebDAVMethods {
+			r.Handle(method, indexer_service.WebDAVPrefix, webdavHandler)
+			r.Handle(method, indexer_service.WebDAVPrefix+"/*path", webdavHandler)
+		}
+	}
+
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -205,13 +323,23 @@ func SetupIndexerRouter(stor storage.Storage, indexerService *indexer_service.In
 		})
 	})
 
+	// Prometheus metrics, with per-collection storage engine metrics on Pebble
+	pebbleMetrics, _ := database.DB.(metrics.PebbleSource)
+	r.GET("/metrics", gin.WrapH(metrics.Handler(pebbleMetrics)))
+
 	// Swagger documentation
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler,
 		ginSwagger.InstanceName("indexer")))
 
+	// Built-in explorer UI (embedded) at /, its assets under /explorer
+	explorerFS := http.FS(explorer.FS())
+	r.GET("/", func(c *gin.Context) {
+		c.FileFromFS("/", explorerFS)
+	})
+	r.StaticFS("/explorer", explorerFS)
+
 	// Static files and web pages
 	r.Static("/static", "./web/static")
-	r.StaticFile("/", "./web/indexer.html")
 	r.StaticFile("/indexer.html", "./web/indexer.html")
 	r.StaticFile("/indexer.js", "./web/indexer.js")
 
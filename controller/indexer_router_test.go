@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"meta-file-system/conf"
@@ -54,3 +55,75 @@ func TestSetupIndexerRouterRegistersAdminRescanRoutesWhenEnabled(t *testing.T) {
 		t.Fatalf("GET /api/v1/admin/rescan/status status = %d, want route registered", w.Code)
 	}
 }
+
+func TestSetupIndexerRouterRegistersWatchlistWritesOnlyUnderAdmin(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+
+	gin.SetMode(gin.TestMode)
+	for _, adminEnabled := range []bool{false, true} {
+		conf.Cfg = &conf.Config{
+			Indexer: conf.IndexerConfig{
+				AdminEnabled:   adminEnabled,
+				SwaggerBaseUrl: "localhost:7281",
+			},
+		}
+
+		routes := make(map[string]bool)
+		for _, route := range SetupIndexerRouter(nil, nil).Routes() {
+			routes[route.Method+" "+route.Path] = true
+		}
+
+		if routes["POST /api/v1/watchlist"] || routes["DELETE /api/v1/watchlist/:id"] {
+			t.Errorf("admin=%v: watchlist writes registered outside the admin group", adminEnabled)
+		}
+		if !routes["GET /api/v1/watchlist"] {
+			t.Errorf("admin=%v: GET /api/v1/watchlist not registered", adminEnabled)
+		}
+		if routes["POST /api/v1/admin/watchlist"] != adminEnabled || routes["DELETE /api/v1/admin/watchlist/:id"] != adminEnabled {
+			t.Errorf("admin=%v: admin watchlist routes registered = %v", adminEnabled, routes["POST /api/v1/admin/watchlist"])
+		}
+	}
+}
+
+func TestSetupIndexerRouterRegistersChangeFeed(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+
+	gin.SetMode(gin.TestMode)
+	conf.Cfg = &conf.Config{Indexer: conf.IndexerConfig{SwaggerBaseUrl: "localhost:7281"}}
+
+	for _, route := range SetupIndexerRouter(nil, nil).Routes() {
+		if route.Method == "GET" && route.Path == "/api/v1/changes" {
+			return
+		}
+	}
+	t.Error("GET /api/v1/changes not registered")
+}
+
+func TestSetupIndexerRouterServesEmbeddedExplorer(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+
+	gin.SetMode(gin.TestMode)
+	conf.Cfg = &conf.Config{
+		Indexer: conf.IndexerConfig{
+			SwaggerBaseUrl: "localhost:7281",
+		},
+	}
+
+	router := SetupIndexerRouter(nil, nil)
+	for _, path := range []string{"/", "/explorer/explorer.js", "/explorer/explorer.css"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Body.Len() == 0 {
+			t.Errorf("GET %s status = %d, body %d bytes; want embedded asset", path, w.Code, w.Body.Len())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(w.Body.String(), "/explorer/explorer.js") {
+		t.Errorf("GET / did not serve the explorer page")
+	}
+}
@@ -0,0 +1,369 @@
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/controller/handler"
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults of conf.HttpConfig
+const (
+	defaultMaxBodyMB  = 10
+	defaultCorsMaxAge = 12 * 3600 // 12 hours
+)
+
+var (
+	defaultCorsMethods       = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"}
+	defaultCorsHeaders       = []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With", headerNameIdempotencyKey}
+	defaultCorsExposeHeaders = []string{"Content-Length", "Content-Type", "Retry-After", respond.HeaderNameRequestID, headerNameIdempotentReplayed}
+)
+
+// httpConfig returns the HTTP middleware configuration (zero when unloaded)
+func httpConfig() conf.HttpConfig {
+	if conf.Cfg == nil {
+		return conf.HttpConfig{}
+	}
+	return conf.Cfg.Http
+}
+
+// corsMiddleware allows browser dApps on the configured origins (any origin
+// by default) to call the API. Credentials are only allowed for explicit
+// origins, since browsers refuse them with a wildcard origin.
+func corsMiddleware(cfg conf.CorsConfig) gin.HandlerFunc {
+	origins := cfg.AllowOrigins
+	anyOrigin := len(origins) == 0
+	for _, origin := range origins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+	}
+	if anyOrigin {
+		origins = []string{"*"}
+	}
+	credentials := cfg.AllowCredentials && !anyOrigin
+	if cfg.AllowCredentials && anyOrigin {
+		log.Printf("http.cors.allow_credentials ignored: it needs explicit http.cors.allow_origins")
+	}
+
+	maxAge := cfg.MaxAge
+	if maxAge <= 0 {
+		maxAge = defaultCorsMaxAge
+	}
+	return cors.New(cors.Config{
+		AllowOrigins:     origins,
+		AllowMethods:     orDefault(cfg.AllowMethods, defaultCorsMethods),
+		AllowHeaders:     orDefault(cfg.AllowHeaders, defaultCorsHeaders),
+		ExposeHeaders:    orDefault(cfg.ExposeHeaders, defaultCorsExposeHeaders),
+		AllowCredentials: credentials,
+		MaxAge:           time.Duration(maxAge) * time.Second,
+	})
+}
+
+func orDefault(values, def []string) []string {
+	if len(values) == 0 {
+		return def
+	}
+	return values
+}
+
+// maxBodyBytes returns the request body limit of routes other than uploads
+func maxBodyBytes() int64 {
+	mb := httpConfig().MaxBodyMB
+	if mb <= 0 {
+		mb = defaultMaxBodyMB
+	}
+	return int64(mb) * 1024 * 1024
+}
+
+// uploadMaxBodyBytes returns the request body limit of uploader upload
+// routes; 0 leaves them to the per-route limits their handlers derive from
+// uploader.max_file_size
+func uploadMaxBodyBytes() int64 {
+	return int64(httpConfig().UploadMaxBodyMB) * 1024 * 1024
+}
+
+// bodyLimitMiddleware rejects request bodies over maxBytes with code 41300.
+// Declared sizes are refused up front; chunked bodies fail when read past
+// the limit. 0 = no limit.
+func bodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			respond.Error(c, respond.CodePayloadTooLarge, fmt.Sprintf("request body too large: %d bytes, max %d", c.Request.ContentLength, maxBytes))
+			c.Abort()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// gzipMiddleware compresses JSON, XML and text responses for clients that
+// accept gzip. File downloads (Content-Disposition), already encoded bodies
+// and WebSocket upgrades are passed through untouched.
+func gzipMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") || c.GetHeader("Upgrade") != "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer w.close()
+		c.Next()
+	}
+}
+
+// gzipResponseWriter decides on the first body write whether to compress,
+// once the handler has set its headers
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Disposition") != "" || !compressibleType(h.Get("Content-Type")) {
+		return
+	}
+	switch w.Status() {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		return
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	w.gz = gzipWriterPool.Get().(*gzip.Writer)
+	w.gz.Reset(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.gz == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.gz.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	if err := w.gz.Close(); err != nil {
+		log.Printf("Failed to finish gzip response: %v", err)
+	}
+	w.gz.Reset(nil)
+	gzipWriterPool.Put(w.gz)
+	w.gz = nil
+}
+
+// compressibleType reports whether a response of contentType is worth gzipping
+func compressibleType(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	return strings.HasPrefix(ct, "text/") ||
+		strings.Contains(ct, "json") ||
+		strings.Contains(ct, "xml") ||
+		strings.Contains(ct, "javascript")
+}
+
+// Idempotency-Key request header and the response header marking a replay
+const (
+	headerNameIdempotencyKey     = "Idempotency-Key"
+	headerNameIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// idempotencyStore keeps Idempotency-Key records (upload_service.UploadService)
+type idempotencyStore interface {
+	BeginIdempotentRequest(scope, key, fingerprint string) (*model.UploadIdempotency, error)
+	FinishIdempotentRequest(record *model.UploadIdempotency, response []byte) error
+	AbandonIdempotentRequest(record *model.UploadIdempotency) error
+}
+
+// idempotencyMiddleware makes upload routes safe to retry. A request with an
+// Idempotency-Key header runs once per route; retries with the same key and
+// the same request get the stored response back (Idempotent-Replayed: true)
+// instead of uploading or broadcasting again. The key used with a different
+// request, or while its first request still runs, gets code 40900. Server
+// errors and rate limits are not stored, so retrying them runs the request
+// again. Requests without the header are not affected.
+func idempotencyMiddleware(store idempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(headerNameIdempotencyKey))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > upload_service.MaxIdempotencyKeyLength {
+			respond.InvalidParam(c, fmt.Sprintf("%s too long: max %d characters", headerNameIdempotencyKey, upload_service.MaxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		fingerprint, err := requestFingerprint(c.Request, idempotentBodyBytes())
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				respond.Error(c, respond.CodePayloadTooLarge, fmt.Sprintf("request body too large: max %d bytes", tooLarge.Limit))
+			} else {
+				respond.InvalidParam(c, "failed to read request body: "+err.Error())
+			}
+			c.Abort()
+			return
+		}
+
+		record, err := store.BeginIdempotentRequest(c.Request.Method+" "+c.FullPath(), key, fingerprint)
+		switch {
+		case errors.Is(err, upload_service.ErrIdempotencyMismatch), errors.Is(err, upload_service.ErrIdempotencyInProgress):
+			respond.Error(c, respond.CodeIdempotencyConflict, err.Error())
+			c.Abort()
+			return
+		case err != nil:
+			respond.ServerError(c, err.Error())
+			c.Abort()
+			return
+		case record.Status == model.IdempotencyCompleted:
+			c.Header(headerNameIdempotentReplayed, "true")
+			c.Data(http.StatusOK, "application/json; charset=utf-8", record.Response)
+			c.Abort()
+			return
+		}
+
+		w := &capturingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		finished := false
+		defer func() {
+			// The handler panicked: release the key for the retry
+			if !finished {
+				if err := store.AbandonIdempotentRequest(record); err != nil {
+					log.Printf("Idempotency-Key %q: %v", key, err)
+				}
+			}
+		}()
+		c.Next()
+		finished = true
+
+		if !replayableResponse(w.body.Bytes()) {
+			err = store.AbandonIdempotentRequest(record)
+		} else {
+			err = store.FinishIdempotentRequest(record, w.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("Idempotency-Key %q: %v", key, err)
+		}
+	}
+}
+
+// idempotentBodyBytes bounds the request body idempotencyMiddleware reads:
+// http.upload_max_body_mb, else the largest upload body the handlers accept
+// (base64 JSON of uploader.max_file_size); 0 = no limit
+func idempotentBodyBytes() int64 {
+	if limit := uploadMaxBodyBytes(); limit > 0 {
+		return limit
+	}
+	if conf.Cfg == nil || conf.Cfg.Uploader.MaxFileSize <= 0 {
+		return 0
+	}
+	return conf.Cfg.Uploader.MaxFileSize*2 + 2*1024*1024
+}
+
+// requestFingerprint hashes what makes two requests the same: method, path,
+// query, API key and body. The body is read (up to maxBytes) and put back
+// for the handler. Multipart boundaries are left out, since clients pick a
+// new one when they resend a form.
+func requestFingerprint(req *http.Request, maxBytes int64) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		reader := req.Body
+		if maxBytes > 0 {
+			reader = http.MaxBytesReader(nil, req.Body, maxBytes)
+		}
+		var err error
+		if body, err = io.ReadAll(reader); err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	hashed := body
+	if mediaType, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil &&
+		strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		hashed = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n%s\n", req.Method, req.URL.Path, req.URL.RawQuery, req.Header.Get(handler.HeaderNameApiKey))
+	h.Write(hashed)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replayableResponse reports whether a response is final for its request and
+// can be sent again to retries: successes and client errors other than rate
+// limits. Server errors (5xxxx) may pass on a retry.
+func replayableResponse(body []byte) bool {
+	var msg struct {
+		Code *int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil || msg.Code == nil {
+		return false
+	}
+	code := *msg.Code
+	return code != respond.CodeRateLimited && code < 50000
+}
+
+// capturingResponseWriter keeps a copy of the response body it writes
+type capturingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,285 @@
+package controller
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/controller/respond"
+	"meta-file-system/model"
+	"meta-file-system/service/upload_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(bodyLimitMiddleware(10))
+	r.POST("/echo", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respond.Error(c, respond.CodePayloadTooLarge, err.Error())
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("small")))
+	if w.Body.String() != "small" {
+		t.Fatalf("small body: got %q", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("far too large body")))
+	var msg respond.Message
+	if err := json.Unmarshal(w.Body.Bytes(), &msg); err != nil || msg.Code != respond.CodePayloadTooLarge {
+		t.Fatalf("large body: got %q (%v), want code %d", w.Body.String(), err, respond.CodePayloadTooLarge)
+	}
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gzipMiddleware())
+	r.GET("/json", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"hello": "world"}) })
+	r.GET("/file", func(c *gin.Context) {
+		c.Header("Content-Disposition", `inline; filename="a.json"`)
+		c.Data(http.StatusOK, "application/json", []byte(`{"a":1}`))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("JSON response not gzipped, headers %v", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != `{"hello":"world"}` {
+		t.Errorf("decompressed body = %q", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/file", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != `{"a":1}` {
+		t.Errorf("file download was compressed: headers %v", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/json", nil))
+	if w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("response gzipped without Accept-Encoding")
+	}
+}
+
+func TestCorsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	preflight := func(cfg conf.CorsConfig, origin string) http.Header {
+		r := gin.New()
+		r.Use(corsMiddleware(cfg))
+		r.GET("/x", func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodOptions, "/x", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Header()
+	}
+
+	h := preflight(conf.CorsConfig{}, "https://dapp.example.com")
+	if h.Get("Access-Control-Allow-Origin") != "*" || h.Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("default CORS headers %v", h)
+	}
+
+	cfg := conf.CorsConfig{AllowOrigins: []string{"https://dapp.example.com"}, AllowCredentials: true}
+	h = preflight(cfg, "https://dapp.example.com")
+	if h.Get("Access-Control-Allow-Origin") != "https://dapp.example.com" || h.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("explicit origin CORS headers %v", h)
+	}
+	h = preflight(cfg, "https://evil.example.com")
+	if h.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("disallowed origin got CORS headers %v", h)
+	}
+
+	h = preflight(conf.CorsConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}, "https://dapp.example.com")
+	if h.Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("credentials allowed with a wildcard origin: %v", h)
+	}
+}
+
+// memoryIdempotencyStore the key handling of UploadService without expiry
+type memoryIdempotencyStore struct {
+	records map[string]*model.UploadIdempotency
+}
+
+func (m *memoryIdempotencyStore) BeginIdempotentRequest(scope, key, fingerprint string) (*model.UploadIdempotency, error) {
+	if record, ok := m.records[scope+" "+key]; ok {
+		switch {
+		case record.Fingerprint != fingerprint:
+			return nil, upload_service.ErrIdempotencyMismatch
+		case record.Status != model.IdempotencyCompleted:
+			return nil, upload_service.ErrIdempotencyInProgress
+		}
+		return record, nil
+	}
+	record := &model.UploadIdempotency{Scope: scope, Key: key, Fingerprint: fingerprint, Status: model.IdempotencyProcessing}
+	m.records[scope+" "+key] = record
+	return record, nil
+}
+
+func (m *memoryIdempotencyStore) FinishIdempotentRequest(record *model.UploadIdempotency, response []byte) error {
+	record.Status, record.Response = model.IdempotencyCompleted, response
+	return nil
+}
+
+func (m *memoryIdempotencyStore) AbandonIdempotentRequest(record *model.UploadIdempotency) error {
+	delete(m.records, record.Scope+" "+record.Key)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &memoryIdempotencyStore{records: make(map[string]*model.UploadIdempotency)}
+	runs := 0
+	r := gin.New()
+	r.POST("/upload", idempotencyMiddleware(store), func(c *gin.Context) {
+		runs++
+		body, _ := io.ReadAll(c.Request.Body)
+		if string(body) == "fail" {
+			respond.Error(c, respond.CodeBroadcastTimeout, "broadcast timed out")
+			return
+		}
+		respond.Success(c, gin.H{"run": runs, "body": string(body)})
+	})
+
+	post := func(key, body string) (*httptest.ResponseRecorder, respond.Message) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(headerNameIdempotencyKey, key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var msg respond.Message
+		if err := json.Unmarshal(w.Body.Bytes(), &msg); err != nil {
+			t.Fatalf("response %q: %v", w.Body.String(), err)
+		}
+		return w, msg
+	}
+
+	first, _ := post("k1", "file")
+	retry, _ := post("k1", "file")
+	if runs != 1 || retry.Body.String() != first.Body.String() || retry.Header().Get(headerNameIdempotentReplayed) != "true" {
+		t.Fatalf("retry ran the handler again (runs %d) or changed the response: %q vs %q", runs, retry.Body.String(), first.Body.String())
+	}
+	if first.Header().Get(headerNameIdempotentReplayed) != "" {
+		t.Errorf("first response marked as replayed")
+	}
+
+	if _, msg := post("k1", "other file"); msg.Code != respond.CodeIdempotencyConflict || msg.ErrorCode != respond.ErrorCodeIdempotencyConflict {
+		t.Errorf("reused key: code %d (%s), want %d", msg.Code, msg.ErrorCode, respond.CodeIdempotencyConflict)
+	}
+
+	// Server errors are not stored: the retry runs again
+	post("k2", "fail")
+	post("k2", "fail")
+	if runs != 3 {
+		t.Errorf("runs = %d after retrying a server error, want 3", runs)
+	}
+
+	post("", "file")
+	post("", "file")
+	if runs != 5 {
+		t.Errorf("runs = %d without Idempotency-Key, want 5", runs)
+	}
+}
+
+func TestRequestFingerprint(t *testing.T) {
+	form := func(boundary string) *http.Request {
+		body := "--" + boundary + "\r\nContent-Disposition: form-data; name=\"path\"\r\n\r\n/file\r\n--" + boundary + "--\r\n"
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		return req
+	}
+
+	req := form("aaaa")
+	a, err := requestFingerprint(req, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(req.Body); !strings.Contains(string(body), "/file") {
+		t.Errorf("body not restored for the handler: %q", body)
+	}
+	if b, _ := requestFingerprint(form("bbbb"), 0); a != b {
+		t.Errorf("resent form with a new boundary has another fingerprint")
+	}
+
+	other := form("aaaa")
+	other.URL.RawQuery = "chain=doge"
+	if b, _ := requestFingerprint(other, 0); a == b {
+		t.Errorf("different query, same fingerprint")
+	}
+	if _, err := requestFingerprint(form("aaaa"), 16); err == nil {
+		t.Errorf("body over the limit was read")
+	}
+}
+
+func TestClientIPTrustsConfiguredProxiesOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prev := conf.Cfg
+	t.Cleanup(func() { conf.Cfg = prev })
+	conf.Cfg = &conf.Config{}
+	conf.Cfg.Uploader.RateLimit = conf.UploadRateLimitConfig{Enabled: true, AddressPerMinute: -1, IpPerMinute: 1, IpBurst: 1}
+
+	// upload attempts from one connection address, each with another forwarded address
+	uploads := func(r *gin.Engine) (allowed int) {
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+			req.RemoteAddr = "203.0.113.7:40000"
+			req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+			req.Header.Set("X-Real-IP", fmt.Sprintf("198.51.100.%d", i))
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code == http.StatusOK {
+				allowed++
+			}
+		}
+		return allowed
+	}
+	newRouter := func() *gin.Engine {
+		s := &upload_service.UploadService{}
+		r := newEngine()
+		r.POST("/upload", func(c *gin.Context) {
+			if err := s.CheckUploadRateLimit("", c.ClientIP()); err != nil {
+				c.String(http.StatusTooManyRequests, err.Error())
+				return
+			}
+			c.String(http.StatusOK, c.ClientIP())
+		})
+		return r
+	}
+
+	// No trusted proxy: the forwarded addresses are ignored, one bucket
+	if allowed := uploads(newRouter()); allowed != 1 {
+		t.Errorf("untrusted forwarding headers: %d uploads allowed, want 1", allowed)
+	}
+
+	// Behind a trusted proxy every forwarded client has its own bucket
+	conf.Cfg.Http.TrustedProxies = []string{"203.0.113.0/24"}
+	if allowed := uploads(newRouter()); allowed != 3 {
+		t.Errorf("trusted proxy: %d uploads allowed, want 3", allowed)
+	}
+}
@@ -6,15 +6,19 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"meta-file-system/node"
+	"meta-file-system/service/upload_service"
 )
 
 // BroadcastError maps a classified node broadcast error onto a structured
 // response and writes it. It is the single place handlers call when a
 // broadcast step fails, so the code/slug mapping stays consistent:
 //
-//   - node.ErrUpstreamNodeUnreachable -> 50301 / upstream_node_unreachable
-//   - node.ErrBroadcastTimeout        -> 50401 / mvc_broadcast_timeout
-//   - anything else                   -> generic 50000 (ServerError)
+//   - node.ErrUpstreamNodeUnreachable    -> 50301 / upstream_node_unreachable
+//   - node.ErrBroadcastTimeout           -> 50401 / mvc_broadcast_timeout
+//   - upload_service.ErrTxRejected       -> 42200 / tx_rejected (TxRejected)
+//   - upload_service.ErrChecksumMismatch -> 42201 / checksum_mismatch (ChecksumMismatch)
+//   - upload_service.ErrUtxoLocked       -> 40901 / utxo_locked (UtxoLocked)
+//   - anything else                      -> generic 50000 (ServerError)
 //
 // HTTP stays 200 (existing convention; the real outcome is in `code`), and
 // the response carries requestId via Message so callers can correlate. The
@@ -29,6 +33,12 @@ func BroadcastError(c *gin.Context, err error) {
 		Error(c, CodeUpstreamNodeUnreachable, err.Error())
 	case errors.Is(err, node.ErrBroadcastTimeout):
 		Error(c, CodeBroadcastTimeout, err.Error())
+	case errors.Is(err, upload_service.ErrTxRejected):
+		TxRejected(c, err)
+	case errors.Is(err, upload_service.ErrChecksumMismatch):
+		ChecksumMismatch(c, err)
+	case errors.Is(err, upload_service.ErrUtxoLocked):
+		UtxoLocked(c, err)
 	default:
 		ServerError(c, err.Error())
 	}
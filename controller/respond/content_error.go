@@ -0,0 +1,36 @@
+package respond
+
+import (
+	"errors"
+
+	"meta-file-system/service/indexer_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProvisionalContentData data of a provisional_content error
+type ProvisionalContentData struct {
+	PinID            string `json:"pin_id" example:"abc123...i0"`
+	ChainName        string `json:"chain_name" example:"btc"`
+	BlockHeight      int64  `json:"block_height" example:"870000"` // 0 in mempool
+	Confirmations    int64  `json:"confirmations" example:"1"`
+	MinConfirmations int64  `json:"min_confirmations" example:"3"`
+}
+
+// ContentError answers a failed content lookup: provisional files (see
+// indexer.confirmations.refuse_provisional) get code 42500 with their
+// confirmation state, everything else the not-found response
+func ContentError(c *gin.Context, err error) {
+	var provisional *indexer_service.ProvisionalContentError
+	if !errors.As(err, &provisional) {
+		NotFound(c, err.Error())
+		return
+	}
+	ErrorWithData(c, CodeProvisionalContent, err.Error(), &ProvisionalContentData{
+		PinID:            provisional.PinID,
+		ChainName:        provisional.ChainName,
+		BlockHeight:      provisional.BlockHeight,
+		Confirmations:    provisional.Confirmations,
+		MinConfirmations: provisional.MinConfirmations,
+	})
+}
@@ -0,0 +1,242 @@
+package respond
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/contenttype"
+)
+
+// Content types for feed and sitemap responses
+const (
+	ContentTypeRSS     = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom    = "application/atom+xml; charset=utf-8"
+	ContentTypeSitemap = "application/xml; charset=utf-8"
+)
+
+// FeedMeta channel-level information shared by the RSS and Atom feeds
+type FeedMeta struct {
+	Title       string
+	Description string
+	SiteURL     string // Explorer/indexer home link
+	SelfURL     string // Absolute URL of the feed itself
+}
+
+// FileLinker returns the public link for a file (explorer page or content URL)
+type FileLinker func(file *model.IndexerFile) string
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string       `xml:"title"`
+	Link        string       `xml:"link"`
+	GUID        rssGUID      `xml:"guid"`
+	Description string       `xml:"description"`
+	Category    string       `xml:"category,omitempty"`
+	PubDate     string       `xml:"pubDate"`
+	Enclosure   rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length string `xml:"length,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Updated   string        `xml:"updated"`
+	Published string        `xml:"published"`
+	Links     []atomLink    `xml:"link"`
+	Author    *atomAuthor   `xml:"author,omitempty"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Summary   string        `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// fileTitle returns the display title of a file: its name, else its path, else its PIN ID
+func fileTitle(file *model.IndexerFile) string {
+	if file.FileName != "" {
+		return file.FileName
+	}
+	if file.Path != "" {
+		return file.Path
+	}
+	return file.PinID
+}
+
+// fileSummary returns a one-line description of a file for feed entries
+func fileSummary(file *model.IndexerFile) string {
+	return fmt.Sprintf("%s (%s, %d bytes) on %s", fileTitle(file), contenttype.Normalize(file.ContentType), file.FileSize, file.ChainName)
+}
+
+// fileTime returns the file timestamp (ms) as time.Time, or the zero time when unknown
+func fileTime(file *model.IndexerFile) time.Time {
+	if file.Timestamp <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(file.Timestamp).UTC()
+}
+
+// feedUpdated returns the newest file time, or now for an empty feed
+func feedUpdated(files []*model.IndexerFile) time.Time {
+	if len(files) > 0 && files[0].Timestamp > 0 {
+		return fileTime(files[0])
+	}
+	return time.Now().UTC()
+}
+
+func marshalXML(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// RenderRSSFeed render files (newest first) as an RSS 2.0 document
+func RenderRSSFeed(meta FeedMeta, files []*model.IndexerFile, link FileLinker) ([]byte, error) {
+	items := make([]rssItem, 0, len(files))
+	for _, file := range files {
+		href := link(file)
+		items = append(items, rssItem{
+			Title:       fileTitle(file),
+			Link:        href,
+			GUID:        rssGUID{Value: file.PinID},
+			Description: fileSummary(file),
+			Category:    file.FileType,
+			PubDate:     fileTime(file).Format(time.RFC1123Z),
+			Enclosure: rssEnclosure{
+				URL:    href,
+				Length: strconv.FormatInt(file.FileSize, 10),
+				Type:   contenttype.Header(file.ContentType),
+			},
+		})
+	}
+
+	return marshalXML(rssDocument{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         meta.Title,
+			Link:          meta.SiteURL,
+			Description:   meta.Description,
+			AtomLink:      atomLink{Href: meta.SelfURL, Rel: "self", Type: "application/rss+xml"},
+			LastBuildDate: feedUpdated(files).Format(time.RFC1123Z),
+			Items:         items,
+		},
+	})
+}
+
+// RenderAtomFeed render files (newest first) as an Atom 1.0 document
+func RenderAtomFeed(meta FeedMeta, files []*model.IndexerFile, link FileLinker) ([]byte, error) {
+	entries := make([]atomEntry, 0, len(files))
+	for _, file := range files {
+		href := link(file)
+		published := fileTime(file).Format(time.RFC3339)
+		entry := atomEntry{
+			ID:        "urn:metaid:pin:" + file.PinID,
+			Title:     fileTitle(file),
+			Updated:   published,
+			Published: published,
+			Links: []atomLink{
+				{Href: href, Rel: "alternate"},
+				{Href: href, Rel: "enclosure", Type: contenttype.Header(file.ContentType), Length: strconv.FormatInt(file.FileSize, 10)},
+			},
+			Summary: fileSummary(file),
+		}
+		if file.CreatorMetaId != "" {
+			entry.Author = &atomAuthor{Name: file.CreatorMetaId}
+		}
+		if file.FileType != "" {
+			entry.Category = &atomCategory{Term: file.FileType}
+		}
+		entries = append(entries, entry)
+	}
+
+	return marshalXML(atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		ID:      meta.SelfURL,
+		Title:   meta.Title,
+		Updated: feedUpdated(files).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: meta.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: meta.SiteURL, Rel: "alternate"},
+		},
+		Entries: entries,
+	})
+}
+
+// RenderSitemap render files as a sitemaps.org urlset
+func RenderSitemap(files []*model.IndexerFile, link FileLinker) ([]byte, error) {
+	urls := make([]sitemapURL, 0, len(files))
+	for _, file := range files {
+		entry := sitemapURL{Loc: link(file)}
+		if file.Timestamp > 0 {
+			entry.LastMod = fileTime(file).Format(time.RFC3339)
+		}
+		urls = append(urls, entry)
+	}
+	return marshalXML(sitemapURLSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  urls,
+	})
+}
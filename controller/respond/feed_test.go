@@ -0,0 +1,104 @@
+package respond
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func testFeedFiles() []*model.IndexerFile {
+	return []*model.IndexerFile{
+		{PinID: "abci0", FileName: "a<b>.png", ContentType: "image/png", FileType: "image", FileSize: 12, ChainName: "mvc", CreatorMetaId: "meta1", Timestamp: 1735732800000},
+		{PinID: "defi0", Path: "/file/doc.pdf", ContentType: "application/pdf", FileSize: 7, ChainName: "btc", Timestamp: 1735646400000},
+	}
+}
+
+func testLink(file *model.IndexerFile) string {
+	return "https://explorer.example.com/pin/" + file.PinID
+}
+
+func TestRenderRSSFeed(t *testing.T) {
+	meta := FeedMeta{Title: "t", Description: "d", SiteURL: "https://x/", SelfURL: "https://x/api/v1/feed/rss"}
+	body, err := RenderRSSFeed(meta, testFeedFiles(), testLink)
+	if err != nil {
+		t.Fatalf("RenderRSSFeed: %v", err)
+	}
+
+	var doc struct {
+		Channel struct {
+			Items []struct {
+				Title     string `xml:"title"`
+				GUID      string `xml:"guid"`
+				PubDate   string `xml:"pubDate"`
+				Enclosure struct {
+					URL    string `xml:"url,attr"`
+					Length string `xml:"length,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("invalid RSS XML: %v\n%s", err, body)
+	}
+	items := doc.Channel.Items
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if items[0].Title != "a<b>.png" || items[1].Title != "/file/doc.pdf" {
+		t.Errorf("titles = %q, %q", items[0].Title, items[1].Title)
+	}
+	if items[0].GUID != "abci0" || items[0].Enclosure.URL != testLink(testFeedFiles()[0]) || items[0].Enclosure.Length != "12" {
+		t.Errorf("item[0] = %+v", items[0])
+	}
+	if items[0].PubDate != "Wed, 01 Jan 2025 12:00:00 +0000" {
+		t.Errorf("pubDate = %q", items[0].PubDate)
+	}
+	if !strings.Contains(string(body), `<atom:link href="https://x/api/v1/feed/rss" rel="self"`) {
+		t.Errorf("missing atom self link:\n%s", body)
+	}
+}
+
+func TestRenderAtomFeedAndSitemap(t *testing.T) {
+	meta := FeedMeta{Title: "t", SiteURL: "https://x/", SelfURL: "https://x/api/v1/feed/atom"}
+	body, err := RenderAtomFeed(meta, testFeedFiles(), testLink)
+	if err != nil {
+		t.Fatalf("RenderAtomFeed: %v", err)
+	}
+	var feed struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			ID     string `xml:"id"`
+			Author struct {
+				Name string `xml:"name"`
+			} `xml:"author"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		t.Fatalf("invalid Atom XML: %v\n%s", err, body)
+	}
+	if len(feed.Entries) != 2 || feed.Entries[0].ID != "urn:metaid:pin:abci0" || feed.Entries[0].Author.Name != "meta1" {
+		t.Errorf("entries = %+v", feed.Entries)
+	}
+	if feed.Updated != "2025-01-01T12:00:00Z" {
+		t.Errorf("updated = %q, want newest file time", feed.Updated)
+	}
+
+	body, err = RenderSitemap(testFeedFiles(), testLink)
+	if err != nil {
+		t.Fatalf("RenderSitemap: %v", err)
+	}
+	var urlset struct {
+		URLs []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(body, &urlset); err != nil {
+		t.Fatalf("invalid sitemap XML: %v\n%s", err, body)
+	}
+	if len(urlset.URLs) != 2 || urlset.URLs[1].Loc != "https://explorer.example.com/pin/defi0" || urlset.URLs[1].LastMod != "2024-12-31T12:00:00Z" {
+		t.Errorf("urls = %+v", urlset.URLs)
+	}
+}
@@ -0,0 +1,219 @@
+package respond
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"meta-file-system/model"
+)
+
+// Sparse fieldsets: file and user endpoints take fields=name1,name2 to return
+// only those fields of every file or user in the response (JSON names, as
+// documented for the response type), which keeps feed payloads small. The
+// envelope and pagination fields are always returned.
+
+// Fields a fields selection may name, per resource type
+var (
+	IndexerFileFields    = jsonFieldNames(reflect.TypeOf(IndexerFileResponse{}))
+	UserInfoFields       = jsonFieldNames(reflect.TypeOf(model.IndexerUserInfo{}))
+	MetaIDUserInfoFields = jsonFieldNames(reflect.TypeOf(MetaIDUserInfo{}))
+)
+
+// FieldSet the fields selected by a request; nil selects every field
+type FieldSet map[string]bool
+
+// ParseFieldSet parses a comma-separated fields parameter, checking every name
+// against allowed. An empty parameter returns a nil set.
+func ParseFieldSet(raw string, allowed []string) (FieldSet, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	known := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		known[name] = true
+	}
+	fs := make(FieldSet)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q in fields; allowed: %s", name, strings.Join(allowed, ","))
+		}
+		fs[name] = true
+	}
+	if len(fs) == 0 {
+		return nil, nil
+	}
+	return fs, nil
+}
+
+// SelectFields returns data reduced to the fields in fs when it is marshaled.
+// The resources reduced are the elements of data's files, items or users
+// arrays and its file object; data itself when it has none of them, or each
+// element when data is an array.
+func SelectFields(data interface{}, fs FieldSet) interface{} {
+	if fs == nil {
+		return data
+	}
+	return fieldSelection{data: data, fields: fs}
+}
+
+// resourceKeys members of a response holding the resources of a fields selection
+var resourceKeys = map[string]bool{"files": true, "items": true, "users": true, "file": true}
+
+type fieldSelection struct {
+	data   interface{}
+	fields FieldSet
+}
+
+func (s fieldSelection) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(s.data)
+	if err != nil {
+		return nil, err
+	}
+	switch firstByte(raw) {
+	case '[':
+		return s.selectEach(raw)
+	case '{':
+	default:
+		return raw, nil
+	}
+
+	container := false
+	if err := eachMember(raw, func(key string, _ json.RawMessage) (json.RawMessage, bool, error) {
+		container = container || resourceKeys[key]
+		return nil, false, nil
+	}); err != nil {
+		return nil, err
+	}
+	if !container {
+		return s.selectObject(raw)
+	}
+
+	var buf bytes.Buffer
+	err = writeObject(&buf, raw, func(key string, value json.RawMessage) (json.RawMessage, bool, error) {
+		if !resourceKeys[key] {
+			return value, true, nil
+		}
+		if firstByte(value) == '[' {
+			selected, err := s.selectEach(value)
+			return selected, true, err
+		}
+		selected, err := s.selectObject(value)
+		return selected, true, err
+	})
+	return buf.Bytes(), err
+}
+
+// selectEach reduces every object in a JSON array
+func (s fieldSelection) selectEach(raw json.RawMessage) ([]byte, error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil {
+		return nil, err
+	}
+	if elems == nil {
+		return raw, nil
+	}
+	for i, elem := range elems {
+		selected, err := s.selectObject(elem)
+		if err != nil {
+			return nil, err
+		}
+		elems[i] = selected
+	}
+	return json.Marshal(elems)
+}
+
+// selectObject keeps the selected members of a JSON object, in their order
+func (s fieldSelection) selectObject(raw json.RawMessage) ([]byte, error) {
+	if firstByte(raw) != '{' {
+		return raw, nil
+	}
+	var buf bytes.Buffer
+	err := writeObject(&buf, raw, func(key string, value json.RawMessage) (json.RawMessage, bool, error) {
+		return value, s.fields[key], nil
+	})
+	return buf.Bytes(), err
+}
+
+// memberFunc maps one member of a JSON object; keep false drops it
+type memberFunc func(key string, value json.RawMessage) (mapped json.RawMessage, keep bool, err error)
+
+// writeObject writes the JSON object raw to buf with every member passed through fn
+func writeObject(buf *bytes.Buffer, raw json.RawMessage, fn memberFunc) error {
+	buf.WriteByte('{')
+	first := true
+	err := eachMember(raw, func(key string, value json.RawMessage) (json.RawMessage, bool, error) {
+		mapped, keep, err := fn(key, value)
+		if err != nil || !keep {
+			return nil, false, err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(mapped)
+		return nil, false, nil
+	})
+	buf.WriteByte('}')
+	return err
+}
+
+// eachMember calls fn for every member of the JSON object raw, in order
+func eachMember(raw json.RawMessage, fn memberFunc) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if _, _, err := fn(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func firstByte(raw []byte) byte {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return 0
+	}
+	return raw[0]
+}
+
+// jsonFieldNames JSON names of a struct's exported fields, in declaration order
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,71 @@
+package respond
+
+import (
+	"encoding/json"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestParseFieldSet(t *testing.T) {
+	fs, err := ParseFieldSet(" pin_id, file_name ,,", IndexerFileFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 2 || !fs["pin_id"] || !fs["file_name"] {
+		t.Errorf("fields = %v, want pin_id and file_name", fs)
+	}
+
+	for _, raw := range []string{"", " , "} {
+		if fs, err := ParseFieldSet(raw, IndexerFileFields); fs != nil || err != nil {
+			t.Errorf("ParseFieldSet(%q) = %v, %v, want every field", raw, fs, err)
+		}
+	}
+	if _, err := ParseFieldSet("pin_id,data", IndexerFileFields); err == nil {
+		t.Error("unknown field accepted")
+	}
+	if _, err := ParseFieldSet("pin_id", UserInfoFields); err == nil {
+		t.Error("file field accepted for users")
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	fs, _ := ParseFieldSet("pin_id,file_name", IndexerFileFields)
+	files := []IndexerFileResponse{{PinID: "a", FileName: "a.png", FileSize: 1}, {PinID: "b", FileName: "b.png"}}
+
+	tests := []struct {
+		name string
+		data interface{}
+		want string
+	}{
+		{"single", files[0], `{"pin_id":"a","file_name":"a.png"}`},
+		{"array", files, `[{"pin_id":"a","file_name":"a.png"},{"pin_id":"b","file_name":"b.png"}]`},
+		{"list", IndexerFileListResponse{Files: files, NextCursor: 2, HasMore: true},
+			`{"files":[{"pin_id":"a","file_name":"a.png"},{"pin_id":"b","file_name":"b.png"}],"next_cursor":2,"has_more":true}`},
+		{"page", IndexerFilePage{Items: files[:1], NextCursor: "1", HasMore: true},
+			`{"items":[{"pin_id":"a","file_name":"a.png"}],"nextCursor":"1","hasMore":true}`},
+		{"resolve", MfsResolveResponse{Uri: "mfs://a", Kind: "pin", File: files[0]},
+			`{"uri":"mfs://a","kind":"pin","file":{"pin_id":"a","file_name":"a.png"}}`},
+		{"empty page", IndexerFilePage{Items: []IndexerFileResponse{}}, `{"items":[],"nextCursor":"","hasMore":false}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(SelectFields(tt.data, fs))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, data, tt.want)
+		}
+	}
+
+	users, _ := ParseFieldSet("globalMetaId,name", UserInfoFields)
+	data, _ := json.Marshal(SelectFields(UserInfoListResponse{Users: []*model.IndexerUserInfo{{GlobalMetaId: "id1", Name: "n", Address: "addr"}}, Total: 1}, users))
+	if want := `{"users":[{"globalMetaId":"id1","name":"n"}],"next_cursor":0,"has_more":false,"total":1}`; string(data) != want {
+		t.Errorf("users = %s, want %s", data, want)
+	}
+
+	// No selection marshals the data unchanged
+	if got, ok := SelectFields(files[0], nil).(IndexerFileResponse); !ok || got.PinID != "a" {
+		t.Errorf("nil fields changed the data: %v", got)
+	}
+}
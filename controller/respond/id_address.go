@@ -0,0 +1,53 @@
+package respond
+
+import (
+	"sync/atomic"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/idaddress"
+)
+
+// idAddressRendering adds the chain-independent ID address next to native
+// addresses in file, avatar and user responses (indexer.render_id_address)
+var idAddressRendering atomic.Bool
+
+// SetIDAddressRendering turns ID address fields in responses on or off
+func SetIDAddressRendering(enabled bool) {
+	idAddressRendering.Store(enabled)
+}
+
+// IDAddressOf returns the ID address of a native address, or "" when rendering
+// is off or the address type has no ID form
+func IDAddressOf(address string) string {
+	if !idAddressRendering.Load() || address == "" {
+		return ""
+	}
+	idAddr, err := idaddress.ConvertFromBitcoin(address)
+	if err != nil {
+		return ""
+	}
+	return idAddr
+}
+
+// WithIDAddress returns user info with IdAddress filled in. The user info is
+// copied because it may be shared with the user info cache.
+func WithIDAddress(userInfo *model.IndexerUserInfo) *model.IndexerUserInfo {
+	if userInfo == nil || !idAddressRendering.Load() {
+		return userInfo
+	}
+	copied := *userInfo
+	copied.IdAddress = IDAddressOf(userInfo.Address)
+	return &copied
+}
+
+// WithIDAddresses is WithIDAddress for a list of users
+func WithIDAddresses(users []*model.IndexerUserInfo) []*model.IndexerUserInfo {
+	if !idAddressRendering.Load() {
+		return users
+	}
+	out := make([]*model.IndexerUserInfo, len(users))
+	for i, user := range users {
+		out[i] = WithIDAddress(user)
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package respond
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/idaddress"
+)
+
+func TestIDAddressRendering(t *testing.T) {
+	const address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	want, err := idaddress.ConvertFromBitcoin(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.IndexerUserInfo{Address: address}
+
+	if IDAddressOf(address) != "" || WithIDAddress(user).IdAddress != "" {
+		t.Fatal("ID address rendered while rendering is off")
+	}
+
+	SetIDAddressRendering(true)
+	t.Cleanup(func() { SetIDAddressRendering(false) })
+	if got := IDAddressOf(address); got != want {
+		t.Errorf("IDAddressOf = %q, want %q", got, want)
+	}
+	if IDAddressOf("not-an-address") != "" {
+		t.Error("unconvertible address rendered an ID address")
+	}
+	rendered := WithIDAddresses([]*model.IndexerUserInfo{user})
+	if rendered[0].IdAddress != want || user.IdAddress != "" {
+		t.Errorf("rendered = %q, original = %q; the original must stay untouched", rendered[0].IdAddress, user.IdAddress)
+	}
+}
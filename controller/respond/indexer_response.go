@@ -2,11 +2,15 @@ package respond
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
 	"meta-file-system/model"
 	common_service "meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/service/indexer_service"
 )
 
 // IndexerFileResponse file information response structure
@@ -26,15 +30,24 @@ type IndexerFileResponse struct {
 	FileHash      string `json:"file_hash" example:"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`
 	// StorageType    string    `json:"storage_type" example:"oss"`
 	StoragePath          string          `json:"storage_path" example:"indexer/mvc/pinid123i0.jpg"`
+	StorageReceipt       string          `json:"storage_receipt,omitempty" example:"rcpt-7f3a"` // Receipt of the external storage gateway (storage.type gateway)
 	ChainName            string          `json:"chain_name" example:"mvc"`
 	BlockHeight          int64           `json:"block_height" example:"12345"`
+	Confirmed            bool            `json:"confirmed" example:"true"` // In a block; false for mempool-only PINs, which a reorg or double spend may still drop
 	Timestamp            int64           `json:"timestamp" example:"1699999999"`
+	FirstSeenAt          int64           `json:"first_seen_at" example:"1699999999000"` // First time the PIN was observed (ms)
+	ConfirmedAt          int64           `json:"confirmed_at" example:"1699999999000"`  // Confirming block time (ms), 0 while unconfirmed
+	Confirmations        int64           `json:"confirmations" example:"6"`             // Blocks indexed from the file's block on, 0 in mempool
+	Provisional          bool            `json:"provisional" example:"false"`           // Fewer confirmations than the chain requires (indexer.confirmations.min); content may be refused until then
 	CreatorMetaId        string          `json:"creator_meta_id" example:"abc123def456..."`
 	CreatorAddress       string          `json:"creator_address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
 	CreatorGlobalMetaId  string          `json:"creator_global_meta_id" example:"idaddress..."`
+	CreatorIdAddress     string          `json:"creator_id_address,omitempty" example:"idq1..."` // ID address of creator_address (indexer.render_id_address)
+	ResolutionPending    bool            `json:"resolution_pending,omitempty" example:"false"`   // Creator is the fallback address until the creator input lookup is retried
 	UserInfo             *MetaIDUserInfo `json:"user_info,omitempty"`
 	OwnerMetaId          string          `json:"owner_meta_id" example:"abc123def456..."`
 	OwnerAddress         string          `json:"owner_address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
+	OwnerIdAddress       string          `json:"owner_id_address,omitempty" example:"idq1..."`                                                      // ID address of owner_address (indexer.render_id_address)
 	ContentUrl           string          `json:"content_url,omitempty" example:"https://example.com/api/v1/content/abc123i0"`                       // 预览链接 baseUrl + /api/v1/content/:pinId
 	AccelerateContentUrl string          `json:"accelerate_content_url,omitempty" example:"https://example.com/api/v1/accelerate/content/abc123i0"` // 下载/加速链接 baseUrl + /api/v1/accelerate/content/:pinId
 	// Status         string    `json:"status" example:"success"`
@@ -49,6 +62,7 @@ type IndexerAvatarResponse struct {
 	TxID          string    `json:"tx_id" example:"xyz789"`
 	MetaId        string    `json:"meta_id" example:"abc123def456..."`
 	Address       string    `json:"address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
+	IdAddress     string    `json:"id_address,omitempty" example:"idq1..."` // ID address of address (indexer.render_id_address)
 	Avatar        string    `json:"avatar" example:"indexer/avatar/mvc/xyz789/xyz789i0.jpg"`
 	ContentType   string    `json:"content_type" example:"image/jpeg"`
 	FileSize      int64     `json:"file_size" example:"102400"`
@@ -58,11 +72,56 @@ type IndexerAvatarResponse struct {
 	FileType      string    `json:"file_type" example:"image"`
 	ChainName     string    `json:"chain_name" example:"mvc"`
 	BlockHeight   int64     `json:"block_height" example:"12345"`
+	Confirmed     bool      `json:"confirmed" example:"true"` // In a block; false for mempool-only PINs
 	Timestamp     int64     `json:"timestamp" example:"1699999999"`
 	CreatedAt     time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
 	UpdatedAt     time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
 
+// IndexerFileBatchRequest request structure for batch file metadata lookup
+type IndexerFileBatchRequest struct {
+	PinIDs []string `json:"pin_ids" binding:"required" example:"abc123def456i0,abc123def789i0"`
+}
+
+// IndexerFileBatchResponse batch file metadata response; missing lists PIN IDs with no indexed file
+type IndexerFileBatchResponse struct {
+	Files   []IndexerFileResponse `json:"files"`
+	Missing []string              `json:"missing" example:"abc123def789i0"`
+}
+
+// MfsResolveResponse file named by an mfs:// URI
+type MfsResolveResponse struct {
+	Uri  string              `json:"uri" example:"mfs://2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"` // Canonical (lower-case) URI
+	Kind string              `json:"kind" example:"sha256"`                                                                // pin or sha256
+	File IndexerFileResponse `json:"file"`                                                                                 // Resolved file (earliest PIN with the content for sha256 URIs)
+}
+
+// MetaIDDeriveResponse identifiers of an address under this deployment's MetaID derivation
+type MetaIDDeriveResponse struct {
+	Address      string `json:"address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
+	MetaId       string `json:"metaId" example:"a1b2c3d4e5f6..."`
+	GlobalMetaId string `json:"globalMetaId" example:"idq1..."`      // Empty for addresses that have no IDAddress form
+	Derivation   string `json:"derivation" example:"address_sha256"` // address_sha256 or genesis_txid
+}
+
+// IndexerPathTreeDirectory subdirectory of a path tree; counts cover the files below it at any depth
+type IndexerPathTreeDirectory struct {
+	Name      string `json:"name" example:"2024"`
+	Path      string `json:"path" example:"/file/photos/2024"`
+	FileCount int64  `json:"file_count" example:"12"`
+	TotalSize int64  `json:"total_size" example:"3145728"` // Bytes
+}
+
+// IndexerPathTreeResponse a user's files under one MetaID path, as a directory listing
+type IndexerPathTreeResponse struct {
+	Path        string                     `json:"path" example:"/file/photos"`
+	FileCount   int64                      `json:"file_count" example:"15"`      // Files below path at any depth
+	TotalSize   int64                      `json:"total_size" example:"4194304"` // Bytes below path at any depth
+	Truncated   bool                       `json:"truncated" example:"false"`    // Too many files below path; counts are partial
+	Directories []IndexerPathTreeDirectory `json:"directories"`
+	Files       []IndexerFileResponse      `json:"files"` // Files directly in path, by name
+}
+
 // RescanRequest request structure for block rescan
 type RescanRequest struct {
 	Chain       string `json:"chain" binding:"required" example:"mvc"`
@@ -95,6 +154,12 @@ type RescanStatusResponse struct {
 	ElapsedTime       int64   `json:"elapsed_time" example:"4050"`        // milliseconds
 	EstimatedTimeLeft int64   `json:"estimated_time_left" example:"4100"` // milliseconds
 	ErrorMessage      string  `json:"error_message,omitempty" example:""`
+	IndexedPins       int64   `json:"indexed_pins" example:"120"`   // PINs (re)indexed
+	DuplicatePins     int64   `json:"duplicate_pins" example:"900"` // PINs already indexed
+	SkippedPins       int64   `json:"skipped_pins" example:"30"`    // PINs outside the indexed paths
+	RejectedPins      int64   `json:"rejected_pins" example:"0"`    // PINs quarantined by the parser policy
+	FailedPins        int64   `json:"failed_pins" example:"2"`      // PINs that failed to index; see the task report
+	FailedBlocks      int64   `json:"failed_blocks" example:"0"`    // Blocks that could not be scanned
 }
 
 // RescanStopResponse response structure for stop rescan
@@ -104,6 +169,86 @@ type RescanStopResponse struct {
 	Status  string `json:"status" example:"cancelled"`
 }
 
+// CountersResponse maintained counters (files, chunks, users; total and per chain)
+type CountersResponse struct {
+	Counters map[string]int64 `json:"counters"` // e.g. files, files:chain:mvc, chunks, users:chain:btc
+}
+
+// CountersReconcileResponse result of recounting the maintained counters
+type CountersReconcileResponse struct {
+	Drifts []*model.CounterDrift `json:"drifts"` // Counters whose stored value differs from the recount
+	Fixed  bool                  `json:"fixed"`  // Whether the recounted values were stored
+}
+
+// LatestRepairResponse result of checking the latest_* collections against history
+type LatestRepairResponse struct {
+	Drifts []*model.LatestDrift `json:"drifts"` // Entries that are not the latest version (or are missing)
+	Fixed  bool                 `json:"fixed"`  // Whether the entries were rewritten
+}
+
+// StorageMigrationResponse progress of the storage backend migration (storage.migration)
+type StorageMigrationResponse struct {
+	Enabled     bool    `json:"enabled" example:"true"`
+	Source      string  `json:"source,omitempty" example:"local"`
+	Target      string  `json:"target,omitempty" example:"oss"`
+	Status      string  `json:"status,omitempty" example:"running"` // running, waiting (between passes), completed
+	Passes      int     `json:"passes" example:"3"`
+	Total       int64   `json:"total" example:"12000"`    // File and chunk records with a blob
+	Migrated    int64   `json:"migrated" example:"11990"` // Records confirmed on the target
+	Pending     int64   `json:"pending" example:"10"`
+	Failed      int64   `json:"failed" example:"2"`       // Failed in the last pass, retried on the next
+	Progress    float64 `json:"progress" example:"99.92"` // percentage
+	LastError   string  `json:"last_error,omitempty" example:""`
+	StartedAt   int64   `json:"started_at,omitempty" example:"1699999999"`
+	LastPassAt  int64   `json:"last_pass_at,omitempty" example:"1700000299"`
+	CompletedAt int64   `json:"completed_at,omitempty" example:"0"`
+}
+
+// PendingStorageWriteResponse a blob waiting for the storage backend
+type PendingStorageWriteResponse struct {
+	StoragePath string `json:"storage_path" example:"indexer/mvc/abc123def456i0.png"`
+	PinID       string `json:"pin_id" example:"abc123def456i0"`
+	ChainName   string `json:"chain_name" example:"mvc"`
+	Kind        string `json:"kind" example:"file"` // file or chunk
+	Size        int64  `json:"size" example:"1024"`
+	Sha256      string `json:"sha256" example:"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`
+	Attempts    int    `json:"attempts" example:"3"`
+	LastError   string `json:"last_error" example:"failed to upload file to OSS: connection refused"`
+	NextRetryAt int64  `json:"next_retry_at" example:"1700000120"`
+	CreatedAt   int64  `json:"created_at" example:"1699999999"`
+}
+
+// PendingStorageResponse files and chunks awaiting storage
+type PendingStorageResponse struct {
+	Total  int                           `json:"total" example:"1"`
+	Writes []PendingStorageWriteResponse `json:"writes"`
+}
+
+// PendingCreatorLookupResponse a file whose creator address lookup is queued
+// for retry; until it succeeds the file has the fallback address
+type PendingCreatorLookupResponse struct {
+	PinID           string `json:"pin_id" example:"abc123def456i0"`
+	ChainName       string `json:"chain_name" example:"btc"`
+	FallbackAddress string `json:"fallback_address" example:"bc1q..."`
+	Attempts        int    `json:"attempts" example:"2"`
+	LastError       string `json:"last_error" example:"failed to get transaction: connection refused"`
+	NextRetryAt     int64  `json:"next_retry_at" example:"1700000120"`
+	CreatedAt       int64  `json:"created_at" example:"1699999999"`
+}
+
+// PendingCreatorResponse queued creator address lookups
+type PendingCreatorResponse struct {
+	Total   int                            `json:"total" example:"1"`
+	Lookups []PendingCreatorLookupResponse `json:"lookups"`
+}
+
+// ChainPauseResponse scanning state of a chain after a pause or resume
+type ChainPauseResponse struct {
+	Chain    string `json:"chain" example:"btc"`
+	Paused   bool   `json:"paused" example:"true"`
+	PausedAt int64  `json:"paused_at,omitempty" example:"1699999999"` // Unix seconds
+}
+
 // IndexerPinInfoResponse PIN information response structure
 type IndexerPinInfoResponse struct {
 	PinID       string `json:"pin_id" example:"abc123def456i0"`
@@ -114,17 +259,38 @@ type IndexerPinInfoResponse struct {
 	ContentType string `json:"content_type" example:"text/plain"`
 	ChainName   string `json:"chain_name" example:"mvc"`
 	BlockHeight int64  `json:"block_height" example:"12345"`
+	Confirmed   bool   `json:"confirmed" example:"true"` // In a block; false for mempool-only PINs
 	Timestamp   int64  `json:"timestamp" example:"1699999999"`
 }
 
 // IndexerSyncStatusResponse sync status response structure
 type IndexerSyncStatusResponse struct {
 	// ID                int64     `json:"id" example:"1"`
-	ChainName         string    `json:"chain_name" example:"mvc"`
-	CurrentSyncHeight int64     `json:"current_sync_height" example:"12345"`
-	LatestBlockHeight int64     `json:"latest_block_height" example:"12350"`
-	CreatedAt         time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt         time.Time `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	ChainName           string               `json:"chain_name" example:"mvc"`
+	CurrentSyncHeight   int64                `json:"current_sync_height" example:"12345"`
+	LatestBlockHeight   int64                `json:"latest_block_height" example:"12350"`
+	Pruned              bool                 `json:"pruned" example:"false"`            // Node runs pruned
+	EarliestBlockHeight int64                `json:"earliest_block_height" example:"0"` // Lowest height that can be rescanned (0 = all)
+	Parser              *IndexerParserStatus `json:"parser,omitempty"`                  // PIN parser mode, features and counts
+	CreatedAt           time.Time            `json:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt           time.Time            `json:"updated_at" example:"2024-01-01T00:00:00Z"`
+	Paused              string               `json:"paused,omitempty" example:"operator"` // Why scanning waits: operator or disk_space
+}
+
+// IndexerParserStatus parser policy of a chain (indexer.parser_mode,
+// indexer.parser_features) and the PINs of blocks it handled since startup
+type IndexerParserStatus struct {
+	Mode     string                         `json:"mode" example:"strict"` // strict or lenient
+	Features map[string]bool                `json:"features"`              // Experimental protocol toggles in effect
+	Counts   map[string]IndexerParserCounts `json:"counts"`                // By mode (strict, lenient)
+}
+
+// IndexerParserCounts PINs handled in one parser mode
+type IndexerParserCounts struct {
+	Accepted int64            `json:"accepted" example:"1200"` // Indexed without problems
+	Warned   int64            `json:"warned" example:"3"`      // Indexed despite problems (lenient)
+	Rejected int64            `json:"rejected" example:"5"`    // Not indexed
+	Reasons  map[string]int64 `json:"reasons"`                 // Problems found, by kind
 }
 
 // IndexerFileListResponse file list response structure
@@ -154,6 +320,98 @@ type IndexerStatsResponse struct {
 	ChainStats map[string]int64 `json:"chain_stats,omitempty"` // Per-chain file counts
 }
 
+// IndexerFileCountResponse file count response structure; pages = ceil(total / page_size)
+type IndexerFileCountResponse struct {
+	Total    int64            `json:"total" example:"12345"`
+	PageSize int              `json:"page_size" example:"20"`
+	Pages    int64            `json:"pages" example:"618"`
+	Chains   map[string]int64 `json:"chains,omitempty"` // Per-chain counts (global count only)
+}
+
+// IndexerDailyStatCounts counters for one day (and optionally one chain)
+type IndexerDailyStatCounts struct {
+	NewFiles int64 `json:"new_files" example:"120"`
+	NewUsers int64 `json:"new_users" example:"15"`
+	Bytes    int64 `json:"bytes" example:"10485760"`
+}
+
+// IndexerDailyStatItem one day of the daily stats series
+type IndexerDailyStatItem struct {
+	Date     string                            `json:"date" example:"2025-01-01"` // UTC day
+	NewFiles int64                             `json:"new_files" example:"120"`
+	NewUsers int64                             `json:"new_users" example:"15"`
+	Bytes    int64                             `json:"bytes" example:"10485760"`
+	Chains   map[string]IndexerDailyStatCounts `json:"chains"` // Per-chain breakdown
+}
+
+// IndexerDailyStatsResponse daily stats time series response structure
+type IndexerDailyStatsResponse struct {
+	From string                 `json:"from" example:"2025-01-01"`
+	To   string                 `json:"to" example:"2025-01-30"`
+	Days []IndexerDailyStatItem `json:"days"` // One entry per day in [from, to], zero-filled
+}
+
+// IndexerWatchRequest request structure for adding a watchlist entry
+type IndexerWatchRequest struct {
+	Target     string `json:"target" binding:"required" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"` // Address, MetaID or GlobalMetaID
+	WebhookURL string `json:"webhook_url" example:"https://wallet.example.com/hooks/metaid"`          // Optional; events are POSTed here
+	Label      string `json:"label" example:"my wallet"`
+}
+
+// IndexerWatchListResponse watchlist response structure
+type IndexerWatchListResponse struct {
+	Watches []*model.IndexerWatch `json:"watches"`
+}
+
+// IndexerDomainRequest request structure for mapping a custom domain
+type IndexerDomainRequest struct {
+	Domain string `json:"domain" binding:"required" example:"mysite.example.com"`                                                 // Host name whose DNS points at the indexer
+	Type   string `json:"type" binding:"required" example:"site"`                                                                 // site or metaid
+	Target string `json:"target" binding:"required" example:"4f2a9c1b8e7d6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1fi0"` // Site manifest PIN ID (site) or MetaID / GlobalMetaID (metaid)
+}
+
+// IndexerDomainListResponse custom domain list response structure
+type IndexerDomainListResponse struct {
+	Domains []*model.IndexerDomain `json:"domains"`
+}
+
+// IndexerPeerListResponse peer gateway list response structure
+type IndexerPeerListResponse struct {
+	Peers []indexer_service.PeerStatus `json:"peers"`
+}
+
+// IndexerWatchEventListResponse watch event list response structure (oldest first; cursor = last event ID)
+type IndexerWatchEventListResponse struct {
+	Events     []*model.IndexerWatchEvent `json:"events"`
+	NextCursor int64                      `json:"next_cursor" example:"100"`
+	HasMore    bool                       `json:"has_more" example:"false"`
+}
+
+// IndexerChangeListResponse change feed page (ascending seq; resume with since = next_since)
+type IndexerChangeListResponse struct {
+	Events    []*model.IndexerChangeEvent `json:"events"`
+	NextSince int64                       `json:"next_since" example:"1200"`
+	HasMore   bool                        `json:"has_more" example:"false"`
+}
+
+// IndexerWeeklyStatItem one ISO week (Monday to Sunday, UTC) of the weekly stats series
+type IndexerWeeklyStatItem struct {
+	Week      string                            `json:"week" example:"2025-W01"`         // ISO week
+	StartDate string                            `json:"start_date" example:"2024-12-30"` // Monday
+	EndDate   string                            `json:"end_date" example:"2025-01-05"`   // Sunday
+	NewFiles  int64                             `json:"new_files" example:"840"`
+	NewUsers  int64                             `json:"new_users" example:"105"`
+	Bytes     int64                             `json:"bytes" example:"73400320"`
+	Chains    map[string]IndexerDailyStatCounts `json:"chains"` // Per-chain breakdown
+}
+
+// IndexerWeeklyStatsResponse weekly stats time series response structure
+type IndexerWeeklyStatsResponse struct {
+	From  string                  `json:"from" example:"2024-12-30"`
+	To    string                  `json:"to" example:"2025-03-23"`
+	Weeks []IndexerWeeklyStatItem `json:"weeks"` // One entry per week in [from, to], zero-filled
+}
+
 // UserInfoListResponse user info list response structure
 type UserInfoListResponse struct {
 	Users      []*model.IndexerUserInfo `json:"users"`
@@ -182,7 +440,7 @@ func ToIndexerFileResponse(file *model.IndexerFile, resolver UserInfoResolver, b
 		Path:                file.Path,
 		Operation:           file.Operation,
 		Encryption:          file.Encryption,
-		ContentType:         file.ContentType,
+		ContentType:         contenttype.Normalize(file.ContentType),
 		FileType:            file.FileType,
 		FileExtension:       file.FileExtension,
 		FileName:            file.FileName,
@@ -190,15 +448,31 @@ func ToIndexerFileResponse(file *model.IndexerFile, resolver UserInfoResolver, b
 		FileMd5:             file.FileMd5,
 		FileHash:            file.FileHash,
 		StoragePath:         file.StoragePath,
+		StorageReceipt:      file.StorageReceipt,
 		ChainName:           file.ChainName,
 		BlockHeight:         file.BlockHeight,
+		Confirmed:           file.BlockHeight > 0,
 		Timestamp:           file.Timestamp,
+		FirstSeenAt:         file.FirstSeenAt,
+		ConfirmedAt:         file.ConfirmedAt,
 		CreatorMetaId:       file.CreatorMetaId,
 		CreatorAddress:      file.CreatorAddress,
 		CreatorGlobalMetaId: creatorGlobalMetaId,
+		CreatorIdAddress:    IDAddressOf(file.CreatorAddress),
+		ResolutionPending:   file.ResolutionPending,
 		OwnerMetaId:         file.OwnerMetaId,
 		OwnerAddress:        file.OwnerAddress,
+		OwnerIdAddress:      IDAddressOf(file.OwnerAddress),
+	}
+	// Rows indexed before first-seen/confirmed tracking only have Timestamp
+	if resp.FirstSeenAt == 0 {
+		resp.FirstSeenAt = file.Timestamp
+	}
+	if resp.ConfirmedAt == 0 && file.BlockHeight > 0 {
+		resp.ConfirmedAt = file.Timestamp
 	}
+	confirmation := indexer_service.ConfirmationOf(file.ChainName, file.BlockHeight)
+	resp.Confirmations, resp.Provisional = confirmation.Confirmations, confirmation.Provisional
 	if baseUrl != "" && file.PinID != "" {
 		base := strings.TrimSuffix(baseUrl, "/")
 		resp.ContentUrl = base + "/api/v1/files/content/" + file.PinID
@@ -225,6 +499,51 @@ func ToIndexerFileListResponse(files []*model.IndexerFile, nextCursor int64, has
 	}
 }
 
+// ToIndexerFileBatchResponse convert batch lookup result to response; resolver and baseUrl optional.
+func ToIndexerFileBatchResponse(files []*model.IndexerFile, missing []string, resolver UserInfoResolver, baseUrl string) IndexerFileBatchResponse {
+	fileResponses := make([]IndexerFileResponse, 0, len(files))
+	for _, file := range files {
+		fileResponses = append(fileResponses, ToIndexerFileResponse(file, resolver, baseUrl))
+	}
+	return IndexerFileBatchResponse{
+		Files:   fileResponses,
+		Missing: missing,
+	}
+}
+
+// ToMfsResolveResponse convert a resolved mfs:// URI to response; resolver and baseUrl optional.
+func ToMfsResolveResponse(uri *metaid_protocols.MfsURI, file *model.IndexerFile, resolver UserInfoResolver, baseUrl string) MfsResolveResponse {
+	return MfsResolveResponse{
+		Uri:  uri.String(),
+		Kind: uri.Kind,
+		File: ToIndexerFileResponse(file, resolver, baseUrl),
+	}
+}
+
+// ToIndexerPathTreeResponse convert a path tree to response; resolver and baseUrl optional.
+func ToIndexerPathTreeResponse(tree *indexer_service.PathTree, resolver UserInfoResolver, baseUrl string) IndexerPathTreeResponse {
+	resp := IndexerPathTreeResponse{
+		Path:        tree.Path,
+		FileCount:   tree.FileCount,
+		TotalSize:   tree.TotalSize,
+		Truncated:   tree.Truncated,
+		Directories: make([]IndexerPathTreeDirectory, 0, len(tree.Directories)),
+		Files:       make([]IndexerFileResponse, 0, len(tree.Files)),
+	}
+	for _, dir := range tree.Directories {
+		resp.Directories = append(resp.Directories, IndexerPathTreeDirectory{
+			Name:      dir.Name,
+			Path:      dir.Path,
+			FileCount: dir.FileCount,
+			TotalSize: dir.TotalSize,
+		})
+	}
+	for _, file := range tree.Files {
+		resp.Files = append(resp.Files, ToIndexerFileResponse(file, resolver, baseUrl))
+	}
+	return resp
+}
+
 // ToIndexerFileListByExtensionResponse convert file list to extension response (nextTimestamp = 16-digit timestamp for next page); resolver and baseUrl optional.
 func ToIndexerFileListByExtensionResponse(files []*model.IndexerFile, nextTimestamp string, hasMore bool, resolver UserInfoResolver, baseUrl string) IndexerFileListByExtensionResponse {
 	var fileResponses []IndexerFileResponse
@@ -249,8 +568,9 @@ func ToIndexerAvatarResponse(avatar *model.IndexerUserAvatar) IndexerAvatarRespo
 		TxID:          avatar.TxID,
 		MetaId:        avatar.MetaId,
 		Address:       avatar.Address,
+		IdAddress:     IDAddressOf(avatar.Address),
 		Avatar:        avatar.Avatar,
-		ContentType:   avatar.ContentType,
+		ContentType:   contenttype.Normalize(avatar.ContentType),
 		FileSize:      avatar.FileSize,
 		FileMd5:       avatar.FileMd5,
 		FileHash:      avatar.FileHash,
@@ -258,6 +578,7 @@ func ToIndexerAvatarResponse(avatar *model.IndexerUserAvatar) IndexerAvatarRespo
 		FileType:      avatar.FileType,
 		ChainName:     avatar.ChainName,
 		BlockHeight:   avatar.BlockHeight,
+		Confirmed:     avatar.BlockHeight > 0,
 		Timestamp:     avatar.Timestamp,
 		CreatedAt:     avatar.CreatedAt,
 		UpdatedAt:     avatar.UpdatedAt,
@@ -303,9 +624,10 @@ func ToIndexerPinInfoResponse(pinInfo *model.IndexerPinInfo) IndexerPinInfoRespo
 		FirstPath:   pinInfo.FirstPath,
 		Path:        pinInfo.Path,
 		Operation:   pinInfo.Operation,
-		ContentType: pinInfo.ContentType,
+		ContentType: contenttype.Normalize(pinInfo.ContentType),
 		ChainName:   pinInfo.ChainName,
 		BlockHeight: pinInfo.BlockHeight,
+		Confirmed:   pinInfo.BlockHeight > 0,
 		Timestamp:   pinInfo.Timestamp,
 	}
 }
@@ -325,6 +647,91 @@ func ToIndexerStatsResponseWithChains(totalFiles int64, chainStats map[string]in
 	}
 }
 
+// ToIndexerFileCountResponse build a file count response with the page count for pageSize
+func ToIndexerFileCountResponse(total int64, pageSize int, chains map[string]int64) IndexerFileCountResponse {
+	pages := int64(0)
+	if pageSize > 0 {
+		pages = (total + int64(pageSize) - 1) / int64(pageSize)
+	}
+	return IndexerFileCountResponse{
+		Total:    total,
+		PageSize: pageSize,
+		Pages:    pages,
+		Chains:   chains,
+	}
+}
+
+// ToIndexerDailyStatsResponse convert per-chain daily stats to a zero-filled daily series for [from, to]
+func ToIndexerDailyStatsResponse(from, to time.Time, stats []*model.IndexerDailyStat) IndexerDailyStatsResponse {
+	days := make([]IndexerDailyStatItem, 0, int(to.Sub(from).Hours()/24)+1)
+	index := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(model.DailyStatDateLayout)
+		index[date] = len(days)
+		days = append(days, IndexerDailyStatItem{Date: date, Chains: map[string]IndexerDailyStatCounts{}})
+	}
+
+	for _, stat := range stats {
+		i, ok := index[stat.Date]
+		if !ok {
+			continue
+		}
+		item := &days[i]
+		item.NewFiles += stat.NewFiles
+		item.NewUsers += stat.NewUsers
+		item.Bytes += stat.Bytes
+		if stat.ChainName != "" {
+			counts := item.Chains[stat.ChainName]
+			counts.NewFiles += stat.NewFiles
+			counts.NewUsers += stat.NewUsers
+			counts.Bytes += stat.Bytes
+			item.Chains[stat.ChainName] = counts
+		}
+	}
+
+	return IndexerDailyStatsResponse{
+		From: from.Format(model.DailyStatDateLayout),
+		To:   to.Format(model.DailyStatDateLayout),
+		Days: days,
+	}
+}
+
+// ToIndexerWeeklyStatsResponse convert per-chain daily stats to a zero-filled weekly series;
+// from must be a Monday and to a Sunday (see indexer_service.ParseWeeklyStatsRange)
+func ToIndexerWeeklyStatsResponse(from, to time.Time, stats []*model.IndexerDailyStat) IndexerWeeklyStatsResponse {
+	daily := ToIndexerDailyStatsResponse(from, to, stats)
+	weeks := make([]IndexerWeeklyStatItem, 0, len(daily.Days)/7+1)
+	for i, day := range daily.Days {
+		if i%7 == 0 {
+			monday := from.AddDate(0, 0, i)
+			year, week := monday.ISOWeek()
+			weeks = append(weeks, IndexerWeeklyStatItem{
+				Week:      fmt.Sprintf("%d-W%02d", year, week),
+				StartDate: day.Date,
+				EndDate:   monday.AddDate(0, 0, 6).Format(model.DailyStatDateLayout),
+				Chains:    map[string]IndexerDailyStatCounts{},
+			})
+		}
+		item := &weeks[len(weeks)-1]
+		item.NewFiles += day.NewFiles
+		item.NewUsers += day.NewUsers
+		item.Bytes += day.Bytes
+		for chain, c := range day.Chains {
+			counts := item.Chains[chain]
+			counts.NewFiles += c.NewFiles
+			counts.NewUsers += c.NewUsers
+			counts.Bytes += c.Bytes
+			item.Chains[chain] = counts
+		}
+	}
+
+	return IndexerWeeklyStatsResponse{
+		From:  daily.From,
+		To:    daily.To,
+		Weeks: weeks,
+	}
+}
+
 // IndexerMultiChainSyncStatusResponse multi-chain sync status response
 type IndexerMultiChainSyncStatusResponse struct {
 	Chains []IndexerSyncStatusResponse `json:"chains"`
@@ -348,7 +755,7 @@ func ToIndexerMultiChainSyncStatusResponse(statuses []*model.IndexerSyncStatus,
 // ToUserInfoListResponse convert to user info list response
 func ToUserInfoListResponse(users []*model.IndexerUserInfo, nextCursor int64, hasMore bool, total int64) UserInfoListResponse {
 	return UserInfoListResponse{
-		Users:      users,
+		Users:      WithIDAddresses(users),
 		NextCursor: nextCursor,
 		HasMore:    hasMore,
 		Total:      total,
@@ -357,31 +764,45 @@ func ToUserInfoListResponse(users []*model.IndexerUserInfo, nextCursor int64, ha
 
 // MetaIDUserInfo MetaID user info response (compatible with external API format)
 type MetaIDUserInfo struct {
-	GlobalMetaId string          `json:"globalMetaId" example:"idaddress..."`
-	Metaid       string          `json:"metaid" example:"abc123def456..."`
-	Name         string          `json:"name" example:"John Doe"`
-	NameId       string          `json:"nameId" example:"abc123def456i0"`
-	Address      string          `json:"address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
-	Avatar       string          `json:"avatar" example:"https://oss.example.com/avatar.jpg"`
-	AvatarId     string          `json:"avatarId" example:"xyz789i0"`
-	Bio          json.RawMessage `json:"bio"`
-	Chatpubkey   string          `json:"chatpubkey" example:"02abc123..."`
-	ChatpubkeyId string          `json:"chatpubkeyId" example:"def456i0"`
+	GlobalMetaId      string          `json:"globalMetaId" example:"idaddress..."`
+	Metaid            string          `json:"metaid" example:"abc123def456..."`
+	Name              string          `json:"name" example:"John Doe"`
+	NameId            string          `json:"nameId" example:"abc123def456i0"`
+	Address           string          `json:"address" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"`
+	IdAddress         string          `json:"idAddress,omitempty" example:"idq1..."` // ID address of address (indexer.render_id_address)
+	Avatar            string          `json:"avatar" example:"https://oss.example.com/avatar.jpg"`
+	AvatarId          string          `json:"avatarId" example:"xyz789i0"`
+	AvatarInvalid     bool            `json:"avatarInvalid,omitempty" example:"false"` // Latest avatar is not a usable image; avatar is empty and clients should use a default
+	Bio               json.RawMessage `json:"bio"`
+	Chatpubkey        string          `json:"chatpubkey" example:"02abc123..."`
+	ChatpubkeyId      string          `json:"chatpubkeyId" example:"def456i0"`
+	ChatpubkeyInvalid bool            `json:"chatpubkeyInvalid,omitempty" example:"false"` // Latest chat public key is malformed; chatpubkey is empty and must not be used for E2E chat
 }
 
 // ToMetaIDUserInfo convert IndexerUserInfo to MetaIDUserInfo
 func ToMetaIDUserInfo(userInfo *model.IndexerUserInfo) *MetaIDUserInfo {
-	return &MetaIDUserInfo{
+	info := &MetaIDUserInfo{
 		GlobalMetaId: userInfo.GlobalMetaId,
 		Metaid:       userInfo.MetaId,
 		Name:         userInfo.Name,
 		NameId:       userInfo.NamePinId,
 		Address:      userInfo.Address,
+		IdAddress:    IDAddressOf(userInfo.Address),
 		// Avatar:       userInfo.Avatar,
-		Avatar:       "/content/" + userInfo.AvatarPinId,
-		AvatarId:     userInfo.AvatarPinId,
-		Bio:          userInfo.Bio,
-		Chatpubkey:   userInfo.ChatPublicKey,
-		ChatpubkeyId: userInfo.ChatPublicKeyPinId,
+		Avatar:            "/content/" + userInfo.AvatarPinId,
+		AvatarId:          userInfo.AvatarPinId,
+		AvatarInvalid:     userInfo.AvatarInvalid,
+		Bio:               userInfo.Bio,
+		Chatpubkey:        userInfo.ChatPublicKey,
+		ChatpubkeyId:      userInfo.ChatPublicKeyPinId,
+		ChatpubkeyInvalid: userInfo.ChatPublicKeyInvalid,
+	}
+	// The content of an invalid avatar is not served and an invalid key is never handed out
+	if userInfo.AvatarInvalid {
+		info.Avatar = ""
+	}
+	if userInfo.ChatPublicKeyInvalid {
+		info.Chatpubkey = ""
 	}
+	return info
 }
@@ -0,0 +1,52 @@
+package respond
+
+import (
+	"testing"
+	"time"
+
+	"meta-file-system/model"
+)
+
+func TestToIndexerWeeklyStatsResponse(t *testing.T) {
+	from := time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC) // Monday of 2025-W01
+	to := time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)    // Sunday of 2025-W02
+	stats := []*model.IndexerDailyStat{
+		{Date: "2024-12-30", ChainName: "mvc", NewFiles: 2, Bytes: 20},
+		{Date: "2025-01-05", ChainName: "btc", NewFiles: 1, NewUsers: 1, Bytes: 5},
+		{Date: "2025-01-13", ChainName: "mvc", NewFiles: 9}, // Outside the range
+	}
+
+	got := ToIndexerWeeklyStatsResponse(from, to, stats)
+	if got.From != "2024-12-30" || got.To != "2025-01-12" || len(got.Weeks) != 2 {
+		t.Fatalf("got %s..%s with %d weeks, want 2024-12-30..2025-01-12 with 2", got.From, got.To, len(got.Weeks))
+	}
+	w1, w2 := got.Weeks[0], got.Weeks[1]
+	if w1.Week != "2025-W01" || w1.StartDate != "2024-12-30" || w1.EndDate != "2025-01-05" {
+		t.Errorf("week 1 = %s %s..%s", w1.Week, w1.StartDate, w1.EndDate)
+	}
+	if w1.NewFiles != 3 || w1.NewUsers != 1 || w1.Bytes != 25 || w1.Chains["mvc"].NewFiles != 2 || w1.Chains["btc"].Bytes != 5 {
+		t.Errorf("week 1 counts = %+v", w1)
+	}
+	if w2.Week != "2025-W02" || w2.NewFiles != 0 || len(w2.Chains) != 0 {
+		t.Errorf("week 2 = %+v, want empty 2025-W02", w2)
+	}
+}
+
+func TestToMetaIDUserInfo_InvalidAvatar(t *testing.T) {
+	info := ToMetaIDUserInfo(&model.IndexerUserInfo{MetaId: "meta1", AvatarPinId: "avatari0"})
+	if info.Avatar != "/content/avatari0" || info.AvatarInvalid {
+		t.Errorf("valid avatar: Avatar=%q AvatarInvalid=%v", info.Avatar, info.AvatarInvalid)
+	}
+
+	info = ToMetaIDUserInfo(&model.IndexerUserInfo{MetaId: "meta1", AvatarPinId: "avatari0", AvatarInvalid: true})
+	if info.Avatar != "" || !info.AvatarInvalid || info.AvatarId != "avatari0" {
+		t.Errorf("invalid avatar: Avatar=%q AvatarId=%q AvatarInvalid=%v, want empty avatar, PIN kept, flag set", info.Avatar, info.AvatarId, info.AvatarInvalid)
+	}
+}
+
+func TestToMetaIDUserInfo_InvalidChatPublicKey(t *testing.T) {
+	info := ToMetaIDUserInfo(&model.IndexerUserInfo{MetaId: "meta1", ChatPublicKey: "not-a-key", ChatPublicKeyPinId: "keyi0", ChatPublicKeyInvalid: true})
+	if info.Chatpubkey != "" || !info.ChatpubkeyInvalid || info.ChatpubkeyId != "keyi0" {
+		t.Errorf("invalid key: Chatpubkey=%q ChatpubkeyId=%q ChatpubkeyInvalid=%v, want empty key, PIN kept, flag set", info.Chatpubkey, info.ChatpubkeyId, info.ChatpubkeyInvalid)
+	}
+}
@@ -20,7 +20,7 @@ type Message struct {
 // Response response structure (for Swagger)
 // @Description Unified API response structure
 type Response struct {
-	Code           int         `json:"code" example:"0" description:"Response code: 0=success, 40000=param error, 40400=not found, 50000=server error, 50301=upstream node unreachable, 50401=broadcast timeout"`
+	Code           int         `json:"code" example:"0" description:"Response code: 0=success, 40000=param error, 40100=unauthorized, 40300=forbidden, 40400=not found, 40900=idempotency conflict, 41300=payload too large, 42200=transaction rejected, 42900=rate limited, 42901=keep quota exceeded, 50000=server error, 50301=upstream node unreachable, 50401=broadcast timeout"`
 	Message        string      `json:"message" example:"success" description:"Response message"`
 	ProcessingTime int64       `json:"processingTime" example:"123" description:"Request processing time (milliseconds)"`
 	RequestId      string      `json:"requestId,omitempty" example:"9b1c..." description:"Per-request id echoed for tracing"`
@@ -35,6 +35,43 @@ const (
 	CodeNotFound     = 40400 // Resource not found
 	CodeServerError  = 50000 // Server error
 
+	// CodePayloadTooLarge a single-PIN upload exceeds the chain's payload limit
+	CodePayloadTooLarge = 41300 // errorCode: payload_too_large
+
+	// CodeTxRejected a built upload transaction failed the node policy
+	// checks run before broadcasting; nothing of the upload was broadcast
+	CodeTxRejected = 42200 // errorCode: tx_rejected
+
+	// CodeChecksumMismatch the uploaded content does not match the SHA256
+	// the client declared; nothing was built or broadcast
+	CodeChecksumMismatch = 42201 // errorCode: checksum_mismatch
+
+	// CodeProvisionalContent the file has fewer confirmations than its chain
+	// requires and indexer.confirmations.refuse_provisional is set
+	CodeProvisionalContent = 42500 // errorCode: provisional_content
+
+	// CodeRateLimited the caller hit a rate limit (e.g. the testnet faucet)
+	CodeRateLimited = 42900 // errorCode: rate_limited
+
+	// CodeUnauthorized the route needs an API key the caller did not present
+	CodeUnauthorized = 40100 // errorCode: unauthorized
+
+	// CodeForbidden the request's signature does not prove it comes from the
+	// owner of what it changes
+	CodeForbidden = 40300 // errorCode: forbidden
+
+	// CodeIdempotencyConflict the Idempotency-Key was used with another
+	// request, or its first request is still running
+	CodeIdempotencyConflict = 40900 // errorCode: idempotency_conflict
+
+	// CodeUtxoLocked an input of the upload is reserved by another upload
+	// in flight; nothing was built or broadcast
+	CodeUtxoLocked = 40901 // errorCode: utxo_locked
+
+	// CodeKeepQuotaExceeded keeping the file would take its owner over the
+	// uploader.keep limits
+	CodeKeepQuotaExceeded = 42901 // errorCode: keep_quota_exceeded
+
 	// Classified broadcast failure codes. Carried in the `code` field with a
 	// matching machine-readable slug in `errorCode`, so callers (e.g. OAC)
 	// can distinguish a dead node from a generic server error without parsing
@@ -47,6 +84,16 @@ const (
 const (
 	ErrorCodeUpstreamNodeUnreachable = "upstream_node_unreachable"
 	ErrorCodeBroadcastTimeout        = "mvc_broadcast_timeout"
+	ErrorCodeRateLimited             = "rate_limited"
+	ErrorCodePayloadTooLarge         = "payload_too_large"
+	ErrorCodeUnauthorized            = "unauthorized"
+	ErrorCodeIdempotencyConflict     = "idempotency_conflict"
+	ErrorCodeUtxoLocked              = "utxo_locked"
+	ErrorCodeTxRejected              = "tx_rejected"
+	ErrorCodeChecksumMismatch        = "checksum_mismatch"
+	ErrorCodeProvisionalContent      = "provisional_content"
+	ErrorCodeForbidden               = "forbidden"
+	ErrorCodeKeepQuotaExceeded       = "keep_quota_exceeded"
 )
 
 // Success message constants
@@ -131,6 +178,26 @@ func errorCodeForCode(code int) string {
 		return ErrorCodeUpstreamNodeUnreachable
 	case CodeBroadcastTimeout:
 		return ErrorCodeBroadcastTimeout
+	case CodeRateLimited:
+		return ErrorCodeRateLimited
+	case CodePayloadTooLarge:
+		return ErrorCodePayloadTooLarge
+	case CodeUnauthorized:
+		return ErrorCodeUnauthorized
+	case CodeIdempotencyConflict:
+		return ErrorCodeIdempotencyConflict
+	case CodeUtxoLocked:
+		return ErrorCodeUtxoLocked
+	case CodeTxRejected:
+		return ErrorCodeTxRejected
+	case CodeChecksumMismatch:
+		return ErrorCodeChecksumMismatch
+	case CodeProvisionalContent:
+		return ErrorCodeProvisionalContent
+	case CodeForbidden:
+		return ErrorCodeForbidden
+	case CodeKeepQuotaExceeded:
+		return ErrorCodeKeepQuotaExceeded
 	}
 	return ""
 }
@@ -3,13 +3,18 @@ package respond
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"meta-file-system/node"
+	"meta-file-system/service/indexer_service"
+	"meta-file-system/service/upload_service"
 )
 
 func init() { gin.SetMode(gin.TestMode) }
@@ -110,3 +115,129 @@ func TestBroadcastError_GenericFallback(t *testing.T) {
 		t.Errorf("errorCode = %q, want empty for generic errors", m.ErrorCode)
 	}
 }
+
+func TestPayloadTooLarge_CarriesSizes(t *testing.T) {
+	c, w := newCtx()
+	RequestIDMiddleware()(c)
+
+	err := fmt.Errorf("direct upload: %w", &upload_service.PayloadTooLargeError{Chain: "mvc", Size: 5000, Max: 4000})
+	PayloadTooLarge(c, err)
+
+	var m struct {
+		Code      int                 `json:"code"`
+		ErrorCode string              `json:"errorCode"`
+		Data      PayloadTooLargeData `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if m.Code != CodePayloadTooLarge || m.ErrorCode != ErrorCodePayloadTooLarge {
+		t.Errorf("code = %d/%q, want %d/%q", m.Code, m.ErrorCode, CodePayloadTooLarge, ErrorCodePayloadTooLarge)
+	}
+	want := PayloadTooLargeData{Chain: "mvc", PayloadSize: 5000, MaxPayloadSize: 4000, Recommended: "chunked"}
+	if m.Data != want {
+		t.Errorf("data = %+v, want %+v", m.Data, want)
+	}
+}
+
+func TestBroadcastError_TxRejected(t *testing.T) {
+	c, w := newCtx()
+	RequestIDMiddleware()(c)
+
+	err := fmt.Errorf("chunked upload: %w", &upload_service.TxRejectedError{
+		Chain: "mvc", Tx: "chunk 2", TxID: "abc", Reason: "tx-size", Source: upload_service.TxRejectedByLocalCheck,
+	})
+	BroadcastError(c, err)
+
+	var m struct {
+		Code      int            `json:"code"`
+		ErrorCode string         `json:"errorCode"`
+		Data      TxRejectedData `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if m.Code != CodeTxRejected || m.ErrorCode != ErrorCodeTxRejected {
+		t.Errorf("code = %d/%q, want %d/%q", m.Code, m.ErrorCode, CodeTxRejected, ErrorCodeTxRejected)
+	}
+	want := TxRejectedData{Chain: "mvc", Tx: "chunk 2", TxId: "abc", Reason: "tx-size", Source: "local"}
+	if m.Data != want {
+		t.Errorf("data = %+v, want %+v", m.Data, want)
+	}
+}
+
+func TestBroadcastError_ChecksumMismatch(t *testing.T) {
+	c, w := newCtx()
+
+	BroadcastError(c, &upload_service.ChecksumMismatchError{Declared: "aa", Actual: "bb", Size: 10})
+
+	var m struct {
+		Code      int                  `json:"code"`
+		ErrorCode string               `json:"errorCode"`
+		Data      ChecksumMismatchData `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if m.Code != CodeChecksumMismatch || m.ErrorCode != ErrorCodeChecksumMismatch {
+		t.Errorf("code = %d/%q, want %d/%q", m.Code, m.ErrorCode, CodeChecksumMismatch, ErrorCodeChecksumMismatch)
+	}
+	want := ChecksumMismatchData{Algorithm: "sha256", Declared: "aa", Actual: "bb", ReceivedSize: 10}
+	if m.Data != want {
+		t.Errorf("data = %+v, want %+v", m.Data, want)
+	}
+}
+
+func TestBroadcastError_UtxoLocked(t *testing.T) {
+	c, w := newCtx()
+
+	BroadcastError(c, fmt.Errorf("wrapped: %w", &upload_service.UtxoLockedError{
+		Chain: "mvc", TxID: "aa", Vout: 2, ExpiresAt: time.Now().Add(time.Minute),
+	}))
+
+	var m struct {
+		Code      int            `json:"code"`
+		ErrorCode string         `json:"errorCode"`
+		Data      UtxoLockedData `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if m.Code != CodeUtxoLocked || m.ErrorCode != ErrorCodeUtxoLocked {
+		t.Errorf("code = %d/%q, want %d/%q", m.Code, m.ErrorCode, CodeUtxoLocked, ErrorCodeUtxoLocked)
+	}
+	if m.Data.TxId != "aa" || m.Data.Vout != 2 || m.Data.RetryAfter < 59 || m.Data.RetryAfter > 61 {
+		t.Errorf("data = %+v", m.Data)
+	}
+	if got := w.Header().Get("Retry-After"); got != strconv.Itoa(m.Data.RetryAfter) {
+		t.Errorf("Retry-After = %q, want %d", got, m.Data.RetryAfter)
+	}
+}
+
+func TestContentError(t *testing.T) {
+	c, w := newCtx()
+	ContentError(c, fmt.Errorf("open: %w", &indexer_service.ProvisionalContentError{
+		PinID: "abci0", ChainName: "btc", BlockHeight: 870000, Confirmations: 1, MinConfirmations: 3,
+	}))
+
+	var m struct {
+		Code      int                    `json:"code"`
+		ErrorCode string                 `json:"errorCode"`
+		Data      ProvisionalContentData `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if m.Code != CodeProvisionalContent || m.ErrorCode != ErrorCodeProvisionalContent {
+		t.Errorf("code = %d/%q, want %d/%q", m.Code, m.ErrorCode, CodeProvisionalContent, ErrorCodeProvisionalContent)
+	}
+	if m.Data.PinID != "abci0" || m.Data.Confirmations != 1 || m.Data.MinConfirmations != 3 {
+		t.Errorf("data = %+v", m.Data)
+	}
+
+	c, w = newCtx()
+	ContentError(c, errors.New("file not found"))
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil || m.Code != CodeNotFound {
+		t.Errorf("other errors: code = %d, %v; want %d", m.Code, err, CodeNotFound)
+	}
+}
@@ -0,0 +1,86 @@
+package respond
+
+import (
+	"strconv"
+
+	"meta-file-system/model"
+)
+
+// v2 list responses share one page envelope: the items, an opaque cursor for
+// the next page and whether another page follows; total is only set where the
+// count is maintained. Pass nextCursor back as cursor while hasMore is true;
+// feeds (watch events, changes) also return it on the last page so clients
+// can poll for new items.
+
+// IndexerFilePage v2 page of indexed files
+type IndexerFilePage struct {
+	Items      []IndexerFileResponse `json:"items"`
+	NextCursor string                `json:"nextCursor" example:"20"` // Opaque; empty when there is no next page
+	HasMore    bool                  `json:"hasMore" example:"true"`
+	Total      *int64                `json:"total,omitempty" example:"12345"` // Matching records, when counted
+}
+
+// UserInfoPage v2 page of user info
+type UserInfoPage struct {
+	Items      []*model.IndexerUserInfo `json:"items"`
+	NextCursor string                   `json:"nextCursor" example:"1699123456"` // Opaque; empty when there is no next page
+	HasMore    bool                     `json:"hasMore" example:"true"`
+	Total      *int64                   `json:"total,omitempty" example:"1000"` // Total number of users
+}
+
+// IndexerWatchEventPage v2 page of watch events (oldest first)
+type IndexerWatchEventPage struct {
+	Items      []*model.IndexerWatchEvent `json:"items"`
+	NextCursor string                     `json:"nextCursor" example:"100"` // Opaque; poll with it for new events
+	HasMore    bool                       `json:"hasMore" example:"false"`
+}
+
+// IndexerChangePage v2 page of the change feed (ascending seq)
+type IndexerChangePage struct {
+	Items      []*model.IndexerChangeEvent `json:"items"`
+	NextCursor string                      `json:"nextCursor" example:"1200"` // Opaque; poll with it for new events
+	HasMore    bool                        `json:"hasMore" example:"false"`
+}
+
+// UploadTaskPage v2 page of upload tasks (newest first)
+type UploadTaskPage struct {
+	Items      []*UploadTask `json:"items"`
+	NextCursor string        `json:"nextCursor" example:"123"` // Opaque; empty when there is no next page
+	HasMore    bool          `json:"hasMore" example:"true"`
+}
+
+// UploadedFilePage v2 page of uploaded files (newest first)
+type UploadedFilePage struct {
+	Items      []*UploadedFile `json:"items"`
+	NextCursor string          `json:"nextCursor" example:"123"` // Opaque; empty when there is no next page
+	HasMore    bool            `json:"hasMore" example:"true"`
+}
+
+// PageCursor formats a numeric resume position as a v2 cursor; the cursor is
+// empty when no page follows
+func PageCursor(next int64, hasMore bool) string {
+	if !hasMore {
+		return ""
+	}
+	return strconv.FormatInt(next, 10)
+}
+
+// FeedCursor formats the resume position of a feed, which clients keep
+// polling after the last page
+func FeedCursor(next int64) string {
+	return strconv.FormatInt(next, 10)
+}
+
+// ToIndexerFilePage convert files to a v2 page; total optional, resolver and baseUrl as for ToIndexerFileResponse.
+func ToIndexerFilePage(files []*model.IndexerFile, nextCursor string, hasMore bool, total *int64, resolver UserInfoResolver, baseUrl string) IndexerFilePage {
+	items := make([]IndexerFileResponse, 0, len(files))
+	for _, file := range files {
+		items = append(items, ToIndexerFileResponse(file, resolver, baseUrl))
+	}
+	return IndexerFilePage{
+		Items:      items,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+		Total:      total,
+	}
+}
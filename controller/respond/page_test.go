@@ -0,0 +1,35 @@
+package respond
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPageCursor(t *testing.T) {
+	if got := PageCursor(40, true); got != "40" {
+		t.Errorf("PageCursor(40, true) = %q, want 40", got)
+	}
+	if got := PageCursor(40, false); got != "" {
+		t.Errorf("PageCursor(40, false) = %q, want empty", got)
+	}
+	if got := FeedCursor(0); got != "0" {
+		t.Errorf("FeedCursor(0) = %q, want 0", got)
+	}
+}
+
+func TestIndexerFilePageJSON(t *testing.T) {
+	// An empty page has an empty items array and no total
+	data, err := json.Marshal(ToIndexerFilePage(nil, "", false, nil, nil, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"items":[],"nextCursor":"","hasMore":false}`; got != want {
+		t.Errorf("empty page = %s, want %s", got, want)
+	}
+
+	total := int64(7)
+	data, _ = json.Marshal(ToIndexerFilePage(nil, "20", true, &total, nil, ""))
+	if got, want := string(data), `{"items":[],"nextCursor":"20","hasMore":true,"total":7}`; got != want {
+		t.Errorf("page with total = %s, want %s", got, want)
+	}
+}
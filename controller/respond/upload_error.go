@@ -0,0 +1,150 @@
+package respond
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"meta-file-system/service/upload_service"
+)
+
+// PayloadTooLargeData data of a payload_too_large response
+type PayloadTooLargeData struct {
+	Chain          string `json:"chain" example:"mvc" description:"Blockchain"`
+	PayloadSize    int64  `json:"payloadSize" example:"5242880" description:"Payload size in bytes (after gzip)"`
+	MaxPayloadSize int64  `json:"maxPayloadSize" example:"2097152" description:"Largest single-PIN payload in bytes"`
+	Recommended    string `json:"recommended" example:"chunked" description:"Upload mode to use instead"`
+}
+
+// PayloadTooLarge writes a 41300 / payload_too_large response for an
+// upload_service.ErrPayloadTooLarge error, with the sizes in data so clients
+// can switch to chunked upload without parsing the message.
+func PayloadTooLarge(c *gin.Context, err error) {
+	data := PayloadTooLargeData{Recommended: upload_service.UploadModeChunked}
+	var tooLarge *upload_service.PayloadTooLargeError
+	if errors.As(err, &tooLarge) {
+		data.Chain = tooLarge.Chain
+		data.PayloadSize = tooLarge.Size
+		data.MaxPayloadSize = tooLarge.Max
+	}
+	ErrorWithData(c, CodePayloadTooLarge, err.Error(), data)
+}
+
+// RateLimitedData data of an upload rate_limited response
+type RateLimitedData struct {
+	Scope      string `json:"scope" example:"address" description:"Limit that was hit: address or ip"`
+	Key        string `json:"key" example:"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" description:"Limited address or client IP"`
+	PerMinute  int    `json:"perMinute" example:"10" description:"Sustained uploads per minute allowed for the key"`
+	Burst      int    `json:"burst" example:"20" description:"Uploads an idle key may send at once"`
+	RetryAfter int    `json:"retryAfter" example:"6" description:"Seconds until the next upload is accepted (also in the Retry-After header)"`
+}
+
+// UploadRateLimited writes a 42900 / rate_limited response for an
+// upload_service.ErrUploadRateLimited error, with the limit in data and the
+// wait in a Retry-After header.
+func UploadRateLimited(c *gin.Context, err error) {
+	var data *RateLimitedData
+	var limited *upload_service.UploadRateLimitError
+	if errors.As(err, &limited) {
+		data = &RateLimitedData{
+			Scope:      limited.Scope,
+			Key:        limited.Key,
+			PerMinute:  limited.PerMinute,
+			Burst:      limited.Burst,
+			RetryAfter: limited.RetryAfterSeconds(),
+		}
+		c.Header("Retry-After", strconv.Itoa(data.RetryAfter))
+	}
+	ErrorWithData(c, CodeRateLimited, err.Error(), data)
+}
+
+// TxRejectedData data of a tx_rejected response
+type TxRejectedData struct {
+	Chain  string `json:"chain" example:"mvc" description:"Blockchain"`
+	Tx     string `json:"tx" example:"chunk 3" description:"Rejected transaction of the upload: merge, funding, chunk N, index, upload or batch"`
+	TxId   string `json:"txId" example:"b3c0...e1" description:"Rejected transaction ID"`
+	Reason string `json:"reason" example:"tx-size" description:"Node reject reason, e.g. tx-size, dust, min relay fee not met, bad-txns-in-belowout"`
+	Source string `json:"source" example:"local" description:"Check that rejected it: local (uploader policy checks) or node (testmempoolaccept)"`
+}
+
+// TxRejected writes a 42200 / tx_rejected response for an
+// upload_service.ErrTxRejected error, with the reject reason in data so
+// clients can fix the transaction (e.g. raise the fee) and retry.
+func TxRejected(c *gin.Context, err error) {
+	var data *TxRejectedData
+	var rejected *upload_service.TxRejectedError
+	if errors.As(err, &rejected) {
+		data = &TxRejectedData{
+			Chain:  rejected.Chain,
+			Tx:     rejected.Tx,
+			TxId:   rejected.TxID,
+			Reason: rejected.Reason,
+			Source: rejected.Source,
+		}
+	}
+	ErrorWithData(c, CodeTxRejected, err.Error(), data)
+}
+
+// ChecksumMismatchData data of a checksum_mismatch response
+type ChecksumMismatchData struct {
+	Algorithm    string `json:"algorithm" example:"sha256" description:"Checksum algorithm"`
+	Declared     string `json:"declared" example:"2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" description:"Checksum sent by the client"`
+	Actual       string `json:"actual" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" description:"Checksum of the content the server received"`
+	ReceivedSize int64  `json:"receivedSize" example:"1048576" description:"Bytes the server received"`
+}
+
+// ChecksumMismatch writes a 42201 / checksum_mismatch response for an
+// upload_service.ErrChecksumMismatch error, with both checksums and the size
+// received in data so clients can tell a truncated upload from a wrong file.
+func ChecksumMismatch(c *gin.Context, err error) {
+	var data *ChecksumMismatchData
+	var mismatch *upload_service.ChecksumMismatchError
+	if errors.As(err, &mismatch) {
+		data = &ChecksumMismatchData{
+			Algorithm:    "sha256",
+			Declared:     mismatch.Declared,
+			Actual:       mismatch.Actual,
+			ReceivedSize: mismatch.Size,
+		}
+	}
+	ErrorWithData(c, CodeChecksumMismatch, err.Error(), data)
+}
+
+// UtxoLockedData data of a utxo_locked response
+type UtxoLockedData struct {
+	Chain      string `json:"chain" example:"mvc" description:"Blockchain"`
+	TxId       string `json:"txId" example:"4e3f0c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f" description:"Transaction of the reserved output"`
+	Vout       uint32 `json:"vout" example:"1" description:"Index of the reserved output"`
+	RetryAfter int    `json:"retryAfter" example:"1800" description:"Seconds until the reservation expires at the latest (also in the Retry-After header)"`
+}
+
+// UtxoLocked writes a 40901 / utxo_locked response for an
+// upload_service.ErrUtxoLocked error, with the reserved output in data and
+// the longest wait in a Retry-After header.
+func UtxoLocked(c *gin.Context, err error) {
+	var data *UtxoLockedData
+	var locked *upload_service.UtxoLockedError
+	if errors.As(err, &locked) {
+		data = &UtxoLockedData{
+			Chain:      locked.Chain,
+			TxId:       locked.TxID,
+			Vout:       locked.Vout,
+			RetryAfter: locked.RetryAfterSeconds(),
+		}
+		c.Header("Retry-After", strconv.Itoa(data.RetryAfter))
+	}
+	ErrorWithData(c, CodeUtxoLocked, err.Error(), data)
+}
+
+// KeepQuotaExceeded writes a 42901 / keep_quota_exceeded response for an
+// upload_service.ErrKeepQuotaExceeded error, with the owner's kept files and
+// limits in data.
+func KeepQuotaExceeded(c *gin.Context, err error) {
+	var data *upload_service.KeepUsage
+	var exceeded *upload_service.KeepQuotaError
+	if errors.As(err, &exceeded) {
+		data = &exceeded.Usage
+	}
+	ErrorWithData(c, CodeKeepQuotaExceeded, err.Error(), data)
+}
@@ -38,6 +38,9 @@ type UploadTask struct {
 	ChunkTxIds      []string   `json:"chunkTxIds"`
 	IndexTxId       string     `json:"indexTxId"`
 	ErrorMessage    string     `json:"errorMessage"`
+	BroadcastAt     *time.Time `json:"broadcastAt"`   // Scheduled broadcast time (null = broadcast at once)
+	TargetFeeRate   int64      `json:"targetFeeRate"` // Broadcast early once the network fee rate drops to this (0 = off)
+	ReleasedAt      *time.Time `json:"releasedAt"`    // When a scheduled task was released for broadcast
 	CreatedAt       time.Time  `json:"createdAt"`
 	UpdatedAt       time.Time  `json:"updatedAt"`
 	StartedAt       *time.Time `json:"startedAt"`
@@ -91,6 +94,9 @@ func ToUploadTask(task *model.FileUploaderTask) *UploadTask {
 		ChunkTxIds:      chunkTxIds,
 		IndexTxId:       task.IndexTxId,
 		ErrorMessage:    task.ErrorMessage,
+		BroadcastAt:     task.BroadcastAt,
+		TargetFeeRate:   task.TargetFeeRate,
+		ReleasedAt:      task.ReleasedAt,
 		CreatedAt:       task.CreatedAt,
 		UpdatedAt:       task.UpdatedAt,
 		StartedAt:       task.StartedAt,
@@ -0,0 +1,147 @@
+package respond
+
+import (
+	"time"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/upload_service"
+)
+
+// UploadedFile public view of a file in a user's upload history.
+type UploadedFile struct {
+	FileId           string               `json:"fileId"`
+	FileName         string               `json:"fileName"`
+	FileHash         string               `json:"fileHash"`
+	FileMd5          string               `json:"fileMd5"`
+	FileSize         int64                `json:"fileSize"`
+	FileType         string               `json:"fileType"`
+	FileContentType  string               `json:"fileContentType"`
+	ChunkType        string               `json:"chunkType" example:"multi" description:"single or multi (chunked)"`
+	IsGzipCompressed bool                 `json:"isGzipCompressed"`
+	StorageClass     string               `json:"storageClass" example:"hot" description:"Storage class hint: hot, cold or ephemeral"`
+	Kept             bool                 `json:"kept" description:"Whether the owner keeps the file: never pruned, whatever its storage class"`
+	KeptAt           *time.Time           `json:"keptAt,omitempty"`
+	MetaId           string               `json:"metaId"`
+	Address          string               `json:"address"`
+	TxId             string               `json:"txId"`
+	PinId            string               `json:"pinId"`
+	Path             string               `json:"path"`
+	Operation        string               `json:"operation"`
+	Status           string               `json:"status" example:"success" description:"pending, success or failed"`
+	BlockHeight      int64                `json:"blockHeight"`
+	CreatedAt        time.Time            `json:"createdAt"`
+	UpdatedAt        time.Time            `json:"updatedAt"`
+	ContentUrl       string               `json:"contentUrl,omitempty" description:"Indexer content URL of the PIN (when indexer.swagger_base_url is set)"`
+	Indexer          *UploadedFileIndexer `json:"indexer,omitempty" description:"Indexer state of the PIN; absent when the indexer data is not reachable from the uploader"`
+	Chunks           []*UploadedFileChunk `json:"chunks,omitempty" description:"Chunks of a chunked upload (detail only)"`
+}
+
+// UploadedFileIndexer indexer state of an uploaded file's PIN.
+type UploadedFileIndexer struct {
+	Indexed      bool   `json:"indexed" description:"Whether the indexer has the PIN"`
+	FirstPinId   string `json:"firstPinId,omitempty"`
+	ChainName    string `json:"chainName,omitempty"`
+	BlockHeight  int64  `json:"blockHeight,omitempty"`
+	Confirmed    bool   `json:"confirmed" description:"Whether the indexed PIN is in a block (false while mempool-only)"`
+	Timestamp    int64  `json:"timestamp,omitempty"`
+	Status       string `json:"status,omitempty" description:"Indexer status: success, failed or rejected"`
+	StatusReason string `json:"statusReason,omitempty"`
+}
+
+// UploadedFileChunk public view of a chunk of an uploaded file.
+type UploadedFileChunk struct {
+	ChunkIndex  int64  `json:"chunkIndex"`
+	ChunkHash   string `json:"chunkHash"`
+	ChunkSize   int64  `json:"chunkSize"`
+	TxId        string `json:"txId"`
+	PinId       string `json:"pinId"`
+	Path        string `json:"path"`
+	Status      string `json:"status"`
+	BlockHeight int64  `json:"blockHeight"`
+}
+
+// UploadedFileListResponse describes a paginated upload history.
+type UploadedFileListResponse struct {
+	Files      []*UploadedFile `json:"files"`
+	NextCursor int64           `json:"nextCursor" example:"123" description:"Cursor for the next page"`
+	HasMore    bool            `json:"hasMore" example:"true" description:"Whether there are more records"`
+}
+
+// ToUploadedFile converts an uploaded file into a public response struct.
+// baseUrl optional, when set fills contentUrl.
+func ToUploadedFile(uploaded *upload_service.UploadedFile, baseUrl string) *UploadedFile {
+	if uploaded == nil || uploaded.File == nil {
+		return nil
+	}
+	f := uploaded.File
+	resp := &UploadedFile{
+		FileId:           f.FileId,
+		FileName:         f.FileName,
+		FileHash:         f.FileHash,
+		FileMd5:          f.FileMd5,
+		FileSize:         f.FileSize,
+		FileType:         f.FileType,
+		FileContentType:  contenttype.Normalize(f.FileContentType),
+		ChunkType:        string(f.ChunkType),
+		IsGzipCompressed: f.IsGzipCompressed,
+		StorageClass:     string(f.StorageClass),
+		MetaId:           f.MetaId,
+		Address:          f.Address,
+		TxId:             f.TxID,
+		PinId:            f.PinId,
+		Path:             f.Path,
+		Operation:        f.Operation,
+		Status:           string(f.Status),
+		BlockHeight:      f.BlockHeight,
+		CreatedAt:        f.CreatedAt,
+		UpdatedAt:        f.UpdatedAt,
+	}
+	if resp.StorageClass == "" {
+		resp.StorageClass = string(model.StorageClassHot) // Recorded before storage classes existed
+	}
+	if r := uploaded.Retention; r != nil {
+		keptAt := r.KeptAt
+		resp.Kept, resp.KeptAt = true, &keptAt
+	}
+	if baseUrl != "" && f.PinId != "" {
+		resp.ContentUrl = baseUrl + "/api/v1/files/content/" + f.PinId
+	}
+	if uploaded.IndexerLinked {
+		resp.Indexer = &UploadedFileIndexer{}
+		if idx := uploaded.Indexed; idx != nil {
+			resp.Indexer = &UploadedFileIndexer{
+				Indexed:      true,
+				FirstPinId:   idx.FirstPinID,
+				ChainName:    idx.ChainName,
+				BlockHeight:  idx.BlockHeight,
+				Confirmed:    idx.BlockHeight > 0,
+				Timestamp:    idx.Timestamp,
+				Status:       string(idx.Status),
+				StatusReason: idx.StatusReason,
+			}
+		}
+	}
+	for _, chunk := range uploaded.Chunks {
+		resp.Chunks = append(resp.Chunks, &UploadedFileChunk{
+			ChunkIndex:  chunk.ChunkIndex,
+			ChunkHash:   chunk.ChunkHash,
+			ChunkSize:   chunk.ChunkSize,
+			TxId:        chunk.TxID,
+			PinId:       chunk.PinId,
+			Path:        chunk.Path,
+			Status:      string(chunk.Status),
+			BlockHeight: chunk.BlockHeight,
+		})
+	}
+	return resp
+}
+
+// ToUploadedFileList converts uploaded files to response structs.
+func ToUploadedFileList(files []*upload_service.UploadedFile, baseUrl string) []*UploadedFile {
+	result := make([]*UploadedFile, 0, len(files))
+	for _, f := range files {
+		result = append(result, ToUploadedFile(f, baseUrl))
+	}
+	return result
+}
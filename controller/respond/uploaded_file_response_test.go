@@ -0,0 +1,58 @@
+package respond
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/service/upload_service"
+)
+
+func TestToUploadedFileIndexerLinkage(t *testing.T) {
+	file := &model.File{FileId: "meta1_hash", PinId: "abci0", ChunkType: model.ChunkTypeMulti, Status: model.StatusSuccess}
+
+	t.Run("not linked", func(t *testing.T) {
+		resp := ToUploadedFile(&upload_service.UploadedFile{File: file}, "")
+		if resp.Indexer != nil {
+			t.Errorf("Indexer = %+v, want nil when the indexer data is not reachable", resp.Indexer)
+		}
+		if resp.ContentUrl != "" {
+			t.Errorf("ContentUrl = %q, want empty without base URL", resp.ContentUrl)
+		}
+	})
+
+	t.Run("linked, not indexed yet", func(t *testing.T) {
+		resp := ToUploadedFile(&upload_service.UploadedFile{File: file, IndexerLinked: true}, "https://idx.example.com")
+		if resp.Indexer == nil || resp.Indexer.Indexed {
+			t.Fatalf("Indexer = %+v, want indexed=false", resp.Indexer)
+		}
+		if want := "https://idx.example.com/api/v1/files/content/abci0"; resp.ContentUrl != want {
+			t.Errorf("ContentUrl = %q, want %q", resp.ContentUrl, want)
+		}
+	})
+
+	t.Run("linked and indexed with chunks", func(t *testing.T) {
+		resp := ToUploadedFile(&upload_service.UploadedFile{
+			File:          file,
+			Chunks:        []*model.FileChunk{{ChunkIndex: 0, PinId: "c0i0"}, {ChunkIndex: 1, PinId: "c1i0"}},
+			IndexerLinked: true,
+			Indexed:       &model.IndexerFile{PinID: "abci0", FirstPinID: "abci0", ChainName: "mvc", BlockHeight: 120, Status: model.StatusSuccess},
+		}, "")
+		if resp.Indexer == nil || !resp.Indexer.Indexed || resp.Indexer.BlockHeight != 120 || !resp.Indexer.Confirmed || resp.Indexer.Status != "success" {
+			t.Fatalf("Indexer = %+v, want confirmed at 120 with status success", resp.Indexer)
+		}
+		if len(resp.Chunks) != 2 || resp.Chunks[1].PinId != "c1i0" {
+			t.Errorf("Chunks = %+v, want both chunks in order", resp.Chunks)
+		}
+	})
+}
+
+func TestToUploadedFileStorageClass(t *testing.T) {
+	resp := ToUploadedFile(&upload_service.UploadedFile{File: &model.File{FileId: "f1", StorageClass: model.StorageClassEphemeral}}, "")
+	if resp.StorageClass != "ephemeral" {
+		t.Errorf("StorageClass = %q, want ephemeral", resp.StorageClass)
+	}
+	resp = ToUploadedFile(&upload_service.UploadedFile{File: &model.File{FileId: "f2"}}, "")
+	if resp.StorageClass != "hot" {
+		t.Errorf("StorageClass = %q, want hot for files recorded without a class", resp.StorageClass)
+	}
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+	indexerDocs "meta-file-system/docs/indexer"
+	uploaderDocs "meta-file-system/docs/uploader"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ginParamPattern = regexp.MustCompile(`[:*](\w+)`)
+
+// assertRoutesDocumented fails for every versioned (/api/v1, /api/v2) route
+// of r missing from the swagger spec doc (paths are relative to basePath /api)
+func assertRoutesDocumented(t *testing.T, r *gin.Engine, doc string) {
+	t.Helper()
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal([]byte(doc), &spec); err != nil {
+		t.Fatalf("parse swagger spec: %v", err)
+	}
+	for _, route := range r.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/v1/") && !strings.HasPrefix(route.Path, "/api/v2/") {
+			continue // pages, health checks, legacy /api/info and swagger itself
+		}
+		path := strings.TrimPrefix(route.Path, "/api")
+		path = ginParamPattern.ReplaceAllString(path, "{$1}")
+		if _, ok := spec.Paths[path][strings.ToLower(route.Method)]; !ok {
+			t.Errorf("%s /api%s is not in the swagger spec", route.Method, path)
+		}
+	}
+}
+
+func TestIndexerRoutesAreInSwaggerSpec(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+
+	gin.SetMode(gin.TestMode)
+	conf.Cfg = &conf.Config{
+		Indexer: conf.IndexerConfig{
+			AdminEnabled:   true,
+			SwaggerBaseUrl: "localhost:7281",
+		},
+	}
+
+	assertRoutesDocumented(t, SetupIndexerRouter(nil, nil), indexerDocs.SwaggerInfoindexer.ReadDoc())
+}
+
+func TestUploaderRoutesAreInSwaggerSpec(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+
+	gin.SetMode(gin.TestMode)
+	conf.Cfg = &conf.Config{
+		Uploader: conf.UploaderConfig{
+			AdminEnabled:   true,
+			SwaggerBaseUrl: "localhost:7282",
+		},
+	}
+
+	r, _ := SetupUploadRouter(nil)
+	assertRoutesDocumented(t, r, uploaderDocs.SwaggerInfouploader.ReadDoc())
+}
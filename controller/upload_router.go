@@ -5,10 +5,10 @@ import (
 	"meta-file-system/controller/handler"
 	"meta-file-system/controller/respond"
 	uploaderDocs "meta-file-system/docs/uploader"
+	"meta-file-system/metrics"
 	"meta-file-system/service/upload_service"
 	"meta-file-system/storage"
 
-	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -20,17 +20,16 @@ func SetupUploadRouter(stor storage.Storage) (*gin.Engine, *upload_service.Uploa
 	uploaderDocs.SwaggerInfouploader.Host = conf.Cfg.Uploader.SwaggerBaseUrl
 
 	// Create Gin engine
-	r := gin.Default()
-
-	// Add CORS middleware
-	r.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"*"}, // Allow all origins, can be configured to specific domains
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization", "Accept", "Cache-Control", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Content-Type"},
-		AllowCredentials: false, // Avoid wildcard-origin credentials, keep behavior consistent across browsers
-		MaxAge:           12 * 3600, // 12 hours
-	}))
+	r := newEngine()
+
+	// Add CORS and response compression middleware (http config)
+	r.Use(corsMiddleware(httpConfig().Cors))
+	if httpConfig().Gzip {
+		r.Use(gzipMiddleware())
+	}
+
+	// Log requests (http.access_log) and record the request metrics
+	r.Use(accessLogMiddleware(httpConfig().AccessLog))
 
 	// Add timing + request-id middleware
 	r.Use(respond.TimingMiddleware())
@@ -48,28 +47,78 @@ func SetupUploadRouter(stor storage.Storage) (*gin.Engine, *upload_service.Uploa
 	r.StaticFile("/app.js", "./web/app.js")
 	r.Static("/static", "./web")
 
-	// API v1 route group
+	// API v1 route group. Upload routes carry file content and get their own
+	// body limit (http.upload_max_body_mb); every other route http.max_body_mb.
 	v1 := r.Group("/api/v1")
+	uploads := v1.Group("/files", bodyLimitMiddleware(uploadMaxBodyBytes()))
+	api := v1.Group("", bodyLimitMiddleware(maxBodyBytes()))
+	// Routes that create records or broadcast replay their first response to
+	// retries sent with the same Idempotency-Key
+	idempotent := idempotencyMiddleware(uploadService)
 	{
 		// File upload
-		v1.POST("/files/pre-upload", uploadHandler.PreUpload)
-		v1.POST("/files/commit-upload", uploadHandler.CommitUpload)
-		v1.POST("/files/direct-upload", uploadHandler.DirectUpload)                    // One-step upload (recommended)
-		v1.POST("/files/estimate-chunked-upload", uploadHandler.EstimateChunkedUpload) // Estimate chunked upload fee
-		v1.POST("/files/chunked-upload", uploadHandler.ChunkedUpload)                  // Chunked file upload
-		v1.POST("/files/chunked-upload-task", uploadHandler.ChunkedUploadForTask) // Async chunked file upload (create task, chain: mvc/doge)
-		v1.GET("/files/task/:taskId", uploadHandler.GetTaskProgress)              // Get task progress
-		v1.GET("/files/tasks", uploadHandler.ListUploadTasks)                          // List tasks by address
+		uploads.POST("/pre-upload", idempotent, uploadHandler.PreUpload)
+		uploads.POST("/commit-upload", idempotent, uploadHandler.CommitUpload)
+		uploads.POST("/direct-upload", idempotent, uploadHandler.DirectUpload)               // One-step upload (recommended)
+		uploads.POST("/estimate-chunked-upload", uploadHandler.EstimateChunkedUpload)        // Estimate chunked upload fee
+		api.GET("/files/upload-cost", uploadHandler.GetUploadCost)                           // Compare direct vs chunked upload cost by file size
+		api.GET("/files/batch", uploadHandler.GetUploadBatchStatus)                          // Batching window config and open batches
+		api.GET("/files/batch/:txId", uploadHandler.GetUploadBatch)                          // Files and PinIDs of a batch transaction
+		uploads.POST("/chunked-upload", idempotent, uploadHandler.ChunkedUpload)             // Chunked file upload
+		uploads.POST("/chunked-upload-task", idempotent, uploadHandler.ChunkedUploadForTask) // Async chunked file upload (create task, chain: mvc/doge)
+		uploads.POST("/delegated-upload", idempotent, uploadHandler.DelegatedUpload)         // Chunked upload funded by the operator hot wallet (X-Api-Key)
+		api.GET("/files/delegated/charges", uploadHandler.ListDelegatedClientCharges)        // Charges of the caller's delegated uploads (X-Api-Key)
+		api.POST("/files/fetch-url", uploadHandler.FetchFromURL)                             // Fetch content from a URL into storage (storageKey for chunked upload)
+		api.GET("/files/task/:taskId", uploadHandler.GetTaskProgress)                        // Get task progress
+		api.POST("/files/task/:taskId/cancel", uploadHandler.CancelUploadTask)               // Cancel a task before broadcast
+		api.GET("/files/tasks", uploadHandler.ListUploadTasks)                               // List tasks by address
+		api.GET("/files/tasks/stats", uploadHandler.GetUploadTaskStats)                      // Completed/failed/expired task counts and expired rate
+		api.GET("/files/uploads", uploadHandler.ListUploadedFiles)                           // Upload history by address/MetaID
+		api.GET("/files/uploads/:fileId", uploadHandler.GetUploadedFile)                     // Uploaded file detail with chunks
+		api.POST("/files/keep", uploadHandler.KeepFile)                                      // Owner-signed request to never prune a file
+		api.POST("/files/unkeep", uploadHandler.UnkeepFile)                                  // Owner-signed release of a kept file
+		api.GET("/files/keep", uploadHandler.ListKeptFiles)                                  // Files an address keeps, with its quota
 
 		// Multipart upload (for large files with resume support)
-		v1.POST("/files/multipart/initiate", uploadHandler.InitiateMultipartUpload) // Initiate multipart upload
-		v1.POST("/files/multipart/upload-part", uploadHandler.UploadPart)           // Upload a part
-		v1.POST("/files/multipart/complete", uploadHandler.CompleteMultipartUpload) // Complete multipart upload
-		v1.POST("/files/multipart/list-parts", uploadHandler.ListParts)             // List uploaded parts (for resume)
-		v1.POST("/files/multipart/abort", uploadHandler.AbortMultipartUpload)       // Abort multipart upload
+		uploads.POST("/multipart/initiate", uploadHandler.InitiateMultipartUpload) // Initiate multipart upload
+		uploads.POST("/multipart/upload-part", uploadHandler.UploadPart)           // Upload a part
+		uploads.POST("/multipart/complete", uploadHandler.CompleteMultipartUpload) // Complete multipart upload
+		uploads.POST("/multipart/list-parts", uploadHandler.ListParts)             // List uploaded parts (for resume)
+		uploads.POST("/multipart/abort", uploadHandler.AbortMultipartUpload)       // Abort multipart upload
+
+		// Proof of existence (SHA256 timestamps)
+		api.POST("/proofs", idempotent, uploadHandler.CreateProof) // Inscribe a hash under /proof/sha256
+		api.GET("/proofs/:hash", uploadHandler.GetProof)           // Transaction, block and merkle proof of a hash
 
 		// Configuration
-		v1.GET("/config", uploadHandler.GetConfig)
+		api.GET("/config", uploadHandler.GetConfig)
+
+		// Testnet faucet (uploader.faucet.enabled, never on mainnet)
+		api.POST("/faucet", uploadHandler.Faucet)
+
+		// Admin (uploader.admin_enabled)
+		if conf.Cfg.Uploader.AdminEnabled {
+			admin := api.Group("/admin")
+			admin.POST("/uploads/recover", uploadHandler.RecoverUploads)                       // Resume interrupted synchronous uploads
+			admin.GET("/assistants", uploadHandler.GetAssistentDashboard)                      // Assistent balances and fees per chain and user
+			admin.GET("/assistants/:address", uploadHandler.GetAssistentDetail)                // Assistent balance and unspent outputs of a user
+			admin.POST("/assistants/:address/rotate", uploadHandler.RotateAssistent)           // Replace a user's assistent key and sweep the old address
+			admin.GET("/assistants/:address/rotations", uploadHandler.ListAssistentRotations)  // Assistent key rotation audit trail
+			admin.POST("/assistants/rotations/:id/sweep", uploadHandler.SweepRetiredAssistent) // Retry the sweep of a retired assistent address
+			admin.GET("/delegated/charges", uploadHandler.ListDelegatedCharges)                // Delegated upload charges
+			admin.GET("/delegated/invoices", uploadHandler.ListDelegatedInvoices)              // Delegated upload totals owed per client and user
+			admin.POST("/delegated/settle", uploadHandler.SettleDelegatedCharges)              // Mark delegated upload charges paid
+			admin.GET("/hot-wallet", uploadHandler.GetHotWalletStatus)                         // Chunk funding hot wallet address and ledger totals
+			admin.POST("/hot-wallet/deposits", uploadHandler.RecordHotWalletDeposit)           // Add a deposit to the hot wallet's spendable outputs
+		}
+	}
+
+	// API v2 list routes: same listings as v1 in the page envelope
+	// {items, nextCursor, hasMore}
+	v2 := r.Group("/api/v2", bodyLimitMiddleware(maxBodyBytes()))
+	{
+		v2.GET("/files/tasks", uploadHandler.ListUploadTasksV2)
+		v2.GET("/files/uploads", uploadHandler.ListUploadedFilesV2)
 	}
 
 	// Health check
@@ -84,6 +133,9 @@ func SetupUploadRouter(stor storage.Storage) (*gin.Engine, *upload_service.Uploa
 	// (config / database / storage / multipart / merge_broadcast).
 	r.GET("/health/deep", handler.DeepHealthCheck(stor))
 
+	// Prometheus metrics (runtime and HTTP requests)
+	r.GET("/metrics", gin.WrapH(metrics.Handler(nil)))
+
 	// Ignore Chrome DevTools requests
 	r.GET("/.well-known/*any", func(c *gin.Context) {
 		c.Status(204) // No Content
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"sort"
+	"sync"
+
+	"meta-file-system/model"
+)
+
+// counterBuffer accumulates counter deltas between flushes so that the
+// counters of a block are persisted in one write (the per-block unit of work)
+type counterBuffer struct {
+	mu      sync.Mutex
+	pending map[string]int64
+}
+
+// add buffers delta for counter name; zero deltas and empty names are ignored
+func (b *counterBuffer) add(name string, delta int64) {
+	if name == "" || delta == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending == nil {
+		b.pending = make(map[string]int64)
+	}
+	b.pending[name] += delta
+}
+
+// addChain buffers delta for counter name and, when chainName is set, its per-chain counter
+func (b *counterBuffer) addChain(name, chainName string, delta int64) {
+	b.add(name, delta)
+	if chainName != "" {
+		b.add(model.ChainCounter(name, chainName), delta)
+	}
+}
+
+// get returns the buffered delta of counter name
+func (b *counterBuffer) get(name string) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending[name]
+}
+
+// take removes and returns all buffered deltas
+func (b *counterBuffer) take() map[string]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pending := b.pending
+	b.pending = nil
+	return pending
+}
+
+// restore puts deltas back after a failed flush so they are retried next time
+func (b *counterBuffer) restore(deltas map[string]int64) {
+	for name, delta := range deltas {
+		b.add(name, delta)
+	}
+}
+
+// snapshot returns a copy of the buffered deltas
+func (b *counterBuffer) snapshot() map[string]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make(map[string]int64, len(b.pending))
+	for name, delta := range b.pending {
+		out[name] = delta
+	}
+	return out
+}
+
+// counterDrifts compares stored against recounted values; counters missing on
+// either side count as 0. Results are ordered by name.
+func counterDrifts(stored, actual map[string]int64) []*model.CounterDrift {
+	var drifts []*model.CounterDrift
+	for name, value := range actual {
+		if stored[name] != value {
+			drifts = append(drifts, &model.CounterDrift{Name: name, Stored: stored[name], Actual: value})
+		}
+	}
+	for name, value := range stored {
+		if _, ok := actual[name]; !ok && value != 0 {
+			drifts = append(drifts, &model.CounterDrift{Name: name, Stored: value})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Name < drifts[j].Name })
+	return drifts
+}
+
+// isNewFilePin reports whether file is the first PIN of its file; modifies
+// share the first PIN of the file they change and are not new files
+func isNewFilePin(file *model.IndexerFile) bool {
+	return file.FirstPinID == "" || file.FirstPinID == file.PinID
+}
+
+// newFileDailyStat returns the daily stats change for a file PIN whose
+// successful state changed by delta (+1 when it first becomes a successful
+// file), or nil when nothing is counted: modifies, rewrites of an unchanged
+// PIN and PINs without a timestamp
+func newFileDailyStat(file *model.IndexerFile, delta int64) *model.IndexerDailyStat {
+	if delta == 0 || file.Timestamp <= 0 || !isNewFilePin(file) {
+		return nil
+	}
+	return &model.IndexerDailyStat{
+		Date:      model.DailyStatDate(file.Timestamp),
+		ChainName: file.ChainName,
+		NewFiles:  delta,
+		Bytes:     delta * file.FileSize,
+	}
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+// benchAdapters the adapters benchmarked: Pebble, and the SQL adapter shared
+// by MySQL and SQLite, run on SQLite so no server is needed
+var benchAdapters = []struct {
+	name string
+	open func(b *testing.B) Database
+}{
+	{"pebble", func(b *testing.B) Database { quietLog(b); return newTestPebble(b) }},
+	{"sqlite", func(b *testing.B) Database { quietLog(b); return newTestSQLite(b) }},
+}
+
+// quietLog discards the standard logger until b ends, so log lines do not
+// break up the benchmark results
+func quietLog(b *testing.B) {
+	out := log.Writer()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(out) })
+}
+
+// benchCreators number of creators the benchmark files are spread over
+const benchCreators = 10
+
+func benchHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// benchIndexerFile the i-th file of a chain as the indexer stores it: a
+// single-PIN image of one of benchCreators creators, one per block
+func benchIndexerFile(i int) *model.IndexerFile {
+	txID := benchHash(fmt.Sprintf("tx%d", i))
+	pinID := txID + "i0"
+	path := fmt.Sprintf("/file/photos/%06d.png", i)
+	creator := fmt.Sprintf("1BenchCreator%02dAddressxxxxxxxxxx", i%benchCreators)
+	timestamp := 1700000000000 + int64(i)*600000
+	return &model.IndexerFile{
+		FirstPinID:     pinID,
+		FirstPath:      path,
+		PinID:          pinID,
+		TxID:           txID,
+		Path:           path,
+		Operation:      "create",
+		ParentPath:     "/file/photos",
+		Encryption:     "0",
+		Version:        "1.0.0",
+		ContentType:    "image/png",
+		ChunkType:      model.ChunkTypeSingle,
+		FileType:       "image",
+		FileExtension:  ".png",
+		FileName:       fmt.Sprintf("%06d.png", i),
+		FileSize:       int64(20000 + i%5000),
+		FileMd5:        benchHash(pinID)[:32],
+		FileHash:       benchHash(path),
+		StorageType:    "local",
+		StoragePath:    "indexer/mvc/" + txID + "/" + pinID + ".png",
+		ChainName:      "mvc",
+		BlockHeight:    int64(100000 + i),
+		Timestamp:      timestamp,
+		FirstSeenAt:    timestamp,
+		ConfirmedAt:    timestamp,
+		CreatorMetaId:  benchHash(creator),
+		CreatorAddress: creator,
+		OwnerAddress:   creator,
+		OwnerMetaId:    benchHash(creator),
+		Status:         model.StatusSuccess,
+	}
+}
+
+func BenchmarkCreateIndexerFile(b *testing.B) {
+	for _, adapter := range benchAdapters {
+		b.Run(adapter.name, func(b *testing.B) {
+			db := adapter.open(b)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := db.CreateIndexerFile(benchIndexerFile(i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkListIndexerFiles reads pages of 20 files out of 5000: the first
+// and the 100th page of all files, and the first page of one creator's files
+func BenchmarkListIndexerFiles(b *testing.B) {
+	const files, size = 5000, 20
+	for _, adapter := range benchAdapters {
+		db := adapter.open(b)
+		for i := 0; i < files; i++ {
+			if err := db.CreateIndexerFile(benchIndexerFile(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+		creatorMetaID := benchIndexerFile(0).CreatorMetaId
+
+		// The cursor of the 100th page, followed from the first one
+		var deepCursor int64
+		for page := 1; page < 100; page++ {
+			var err error
+			if _, deepCursor, _, err = db.ListIndexerFilesWithCursor(deepCursor, size); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		cases := []struct {
+			name string
+			list func() ([]*model.IndexerFile, error)
+		}{
+			{"first-page", func() ([]*model.IndexerFile, error) {
+				page, _, _, err := db.ListIndexerFilesWithCursor(0, size)
+				return page, err
+			}},
+			{"deep-page", func() ([]*model.IndexerFile, error) {
+				page, _, _, err := db.ListIndexerFilesWithCursor(deepCursor, size)
+				return page, err
+			}},
+			{"creator-first-page", func() ([]*model.IndexerFile, error) {
+				page, _, _, err := db.GetIndexerFilesByCreatorMetaIDWithCursor(creatorMetaID, model.IndexerFileFilter{}, 0, size)
+				return page, err
+			}},
+		}
+		for _, tc := range cases {
+			b.Run(adapter.name+"/"+tc.name, func(b *testing.B) {
+				if page, err := tc.list(); err != nil || len(page) != size {
+					b.Fatalf("listed %d files (%v), want %d", len(page), err, size)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := tc.list(); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
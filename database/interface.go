@@ -9,17 +9,35 @@ type Database interface {
 	// IndexerFile operations
 	CreateIndexerFile(file *model.IndexerFile) error
 	GetIndexerFileByPinID(pinID string) (*model.IndexerFile, error)
+	// GetIndexerFilesByPinIDs returns the files found for pinIDs (any order); missing IDs are skipped
+	GetIndexerFilesByPinIDs(pinIDs []string) ([]*model.IndexerFile, error)
 	UpdateIndexerFile(file *model.IndexerFile) error
-	ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, error)
-	GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, error)
-	GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error)
-	GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error)
+	// UpdateIndexerFileFields rewrites the stored record in place without re-creating
+	// index keys or touching counters; fields that keys are built from must be unchanged
+	UpdateIndexerFileFields(file *model.IndexerFile) error
+	// ReassignIndexerFileCreator stores file after its creator fields were
+	// corrected: the creator index keys of previous (the stored record) move
+	// to the new creator, together with the creator file counters
+	ReassignIndexerFileCreator(file, previous *model.IndexerFile) error
+	// Offset-cursor file listings, newest first (timestamp, then PIN ID): cursor
+	// is the number of records to skip; returns the page, the cursor of the next
+	// page and whether another page follows
+	ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error)
+	GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error)
+	GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error)
+	GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error)
 	GetIndexerFilesByExtensionWithCursor(extension string, cursor string, size int) ([]*model.IndexerFile, string, error)
 	GetIndexerFilesByGlobalMetaIDAndExtensionWithCursor(globalMetaID string, extension string, cursor string, size int) ([]*model.IndexerFile, string, error)
 	GetIndexerFilesByKeywordAndExtensionWithCursor(keyword string, extension string, cursor string, size int) ([]*model.IndexerFile, string, error)
+	// File counts: successful file PINs (total) and successful files by first PIN
+	// (per chain / creator), read from the maintained counters
 	GetIndexerFilesCount() (int64, error)
 	GetIndexerFilesCountByChain(chainName string) (int64, error)
+	GetIndexerFilesCountByCreatorMetaID(metaID string) (int64, error)
+	GetIndexerFilesCountByCreatorGlobalMetaID(globalMetaID string) (int64, error)
 	GetLatestFileInfoByFirstPinID(firstPinID string) (*model.IndexerFile, error)
+	// GetIndexerFilesByFileHash returns the successful file PINs with content sha256 fileHash
+	GetIndexerFilesByFileHash(fileHash string) ([]*model.IndexerFile, error)
 	AddFileInfoHistory(history *model.FileInfoHistory, firstPinID string) error
 	GetFileInfoHistory(firstPinID string) ([]model.FileInfoHistory, error)
 
@@ -29,6 +47,11 @@ type Database interface {
 	// Migrate helpers: iterate latest file info; write file to global_meta + extension indexes only
 	IterateLatestFileInfo(fn func(*model.IndexerFile) error) error
 	WriteFileToExtensionAndGlobalMetaIndexes(file *model.IndexerFile) error
+	WriteFileToCreatorFilterIndex(file *model.IndexerFile) error
+	RebuildFileHashIndex() error
+	// Storage layout migration: iterate every file / chunk PIN record (any status)
+	IterateIndexerFiles(fn func(*model.IndexerFile) error) error
+	IterateIndexerFileChunks(fn func(*model.IndexerFileChunk) error) error
 
 	// IndexerUserAvatar operations
 	CreateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error
@@ -42,6 +65,7 @@ type Database interface {
 	CreateIndexerFileChunk(chunk *model.IndexerFileChunk) error
 	GetIndexerFileChunkByPinID(pinID string) (*model.IndexerFileChunk, error)
 	GetIndexerFileChunksByParentPinID(parentPinID string) ([]*model.IndexerFileChunk, error)
+	ListIndexerFileChunksBySha256(sha256 string) ([]*model.IndexerFileChunk, error)
 	UpdateIndexerFileChunk(chunk *model.IndexerFileChunk) error
 
 	// IndexerSyncStatus operations
@@ -105,6 +129,21 @@ type Database interface {
 	ListPendingIndexFilesByChain(chainName string) ([]*model.PendingIndexFile, error)
 	DeletePendingIndexFile(pinID string) error
 
+	// PendingStorageWrite operations (indexer-only; Pebble impl, MySQL stub)
+	CreatePendingStorageWrite(w *model.PendingStorageWrite) error
+	GetPendingStorageWrite(storagePath string) (*model.PendingStorageWrite, error)
+	ListPendingStorageWrites() ([]*model.PendingStorageWrite, error)
+	DeletePendingStorageWrite(storagePath string) error
+
+	// PendingCreatorResolution operations (indexer-only; Pebble impl, MySQL stub)
+	CreatePendingCreatorResolution(r *model.PendingCreatorResolution) error
+	ListPendingCreatorResolutions() ([]*model.PendingCreatorResolution, error)
+	DeletePendingCreatorResolution(pinID string) error
+
+	// MetaID genesis operations (metaid.derivation genesis_txid; Pebble impl, MySQL stub)
+	GetMetaIDGenesis(address string) (string, error)
+	RecordMetaIDGenesis(address, pinID string) error
+
 	// MetaIdAddress operations
 	SaveMetaIdAddress(metaID, address string) error
 	GetAddressByMetaID(metaID string) (string, error)
@@ -115,10 +154,58 @@ type Database interface {
 	GetGlobalMetaIdAddress(globalMetaId string) (*model.GlobalMetaIdAddress, error)
 
 	// MetaIdTimestamp operations
-	SaveMetaIdTimestamp(metaID string, timestamp int64) error
+	// SaveMetaIdTimestamp keeps the earliest timestamp per MetaID and counts the
+	// MetaID as a new user of chainName on that day in the daily stats
+	SaveMetaIdTimestamp(metaID, chainName string, timestamp int64) error
 	ListMetaIdsByTimestamp(cursor int64, size int) ([]model.MetaIdTimestamp, int64, bool, error)
 	GetMetaIDCount() (int64, error)
 
+	// Counter operations (files, chunks, users, per chain; see model.Counter*).
+	// Writes buffer counter deltas; FlushCounters persists them in one write and
+	// is called once per block. Reads include buffered deltas.
+	GetCounter(name string) (int64, error)
+	ListCounters(prefix string) (map[string]int64, error)
+	FlushCounters() error
+	// ReconcileCounters recounts every counter from the indexes and returns the
+	// counters that drifted; with fix set the recounted values are stored
+	ReconcileCounters(fix bool) ([]*model.CounterDrift, error)
+	// RepairLatestIndexes recomputes the latest_* collections (latest file per
+	// FirstPinID, latest user infos) from history and returns the entries that
+	// are not the latest version; with fix set they are rewritten
+	RepairLatestIndexes(fix bool) ([]*model.LatestDrift, error)
+
+	// DailyStat operations
+	IncrDailyStat(delta *model.IndexerDailyStat) error
+	ListDailyStats(fromDate, toDate string) ([]*model.IndexerDailyStat, error)
+	RebuildDailyStats() error
+
+	// Watchlist operations
+	CreateWatch(watch *model.IndexerWatch) error
+	DeleteWatch(id int64) error
+	ListWatches() ([]*model.IndexerWatch, error)
+	// AddWatchEvent saves event, assigning an increasing ID, together with its
+	// outbox entries (each given an ID and the saved event) in one atomic write
+	AddWatchEvent(event *model.IndexerWatchEvent, outbox ...*model.IndexerOutboxEvent) error
+	// ListWatchEvents lists events of target with ID > afterID in ascending ID order
+	ListWatchEvents(target string, afterID int64, size int) ([]*model.IndexerWatchEvent, error)
+
+	// Watch event outbox operations (entries are added by AddWatchEvent)
+	// ListOutboxEvents lists entries with ID > afterID in ascending ID order
+	ListOutboxEvents(afterID int64, size int) ([]*model.IndexerOutboxEvent, error)
+	UpdateOutboxEvent(entry *model.IndexerOutboxEvent) error
+	DeleteOutboxEvent(id int64) error
+
+	// Custom domain operations (SaveDomain inserts or replaces by domain)
+	SaveDomain(domain *model.IndexerDomain) error
+	GetDomain(domain string) (*model.IndexerDomain, error)
+	DeleteDomain(domain string) error
+	ListDomains() ([]*model.IndexerDomain, error)
+
+	// Change log operations (append-only; Seq is assigned here and increases with every event)
+	AppendChangeEvent(event *model.IndexerChangeEvent) error
+	// ListChangeEvents lists events with Seq > sinceSeq in ascending Seq order
+	ListChangeEvents(sinceSeq int64, size int) ([]*model.IndexerChangeEvent, error)
+
 	// General operations
 	Close() error
 }
@@ -129,6 +216,7 @@ type DBType string
 const (
 	DBTypeMySQL  DBType = "mysql"
 	DBTypePebble DBType = "pebble"
+	DBTypeSQLite DBType = "sqlite"
 )
 
 // Global database instance
@@ -148,6 +236,9 @@ func InitDatabase(dbType DBType, config interface{}) error {
 	case DBTypePebble:
 		DB, err = NewPebbleDatabase(config)
 		currentDBType = DBTypePebble
+	case DBTypeSQLite:
+		DB, err = NewSQLiteDatabase(config)
+		currentDBType = DBTypeSQLite
 	default:
 		return ErrUnsupportedDBType
 	}
@@ -155,9 +246,9 @@ func InitDatabase(dbType DBType, config interface{}) error {
 	return err
 }
 
-// GetGormDB get GORM database instance (only for MySQL)
+// GetGormDB get GORM database instance (only for MySQL and SQLite)
 func GetGormDB() interface{} {
-	if currentDBType == DBTypeMySQL {
+	if currentDBType == DBTypeMySQL || currentDBType == DBTypeSQLite {
 		if mysqlDB, ok := DB.(*MySQLDatabase); ok {
 			return mysqlDB.GetGormDB()
 		}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"sort"
+
+	"meta-file-system/model"
+)
+
+// latestOrder the fields that decide which version of a file or user info is
+// the latest one
+type latestOrder struct {
+	PinID       string
+	BlockHeight int64
+	Timestamp   int64
+}
+
+func latestOrderOf(timestamp, blockHeight int64, pinID string) latestOrder {
+	return latestOrder{PinID: pinID, BlockHeight: blockHeight, Timestamp: timestamp}
+}
+
+// laterPIN reports whether version a supersedes b: the later timestamp wins,
+// ties go to the higher block height and then the larger PIN ID. The order
+// does not depend on the order PINs are indexed in, so blocks of one chain
+// arriving after another chain's later blocks give the same latest entries.
+func laterPIN(a, b latestOrder) bool {
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp > b.Timestamp
+	}
+	if a.BlockHeight != b.BlockHeight {
+		return a.BlockHeight > b.BlockHeight
+	}
+	return a.PinID > b.PinID
+}
+
+// userInfoOrder decodes the ordering fields of a user info entry (name,
+// avatar, bio, chat public key)
+type userInfoOrder struct {
+	PinID       string `json:"pinId"`
+	BlockHeight int64  `json:"blockHeight"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+func (o userInfoOrder) order() latestOrder {
+	return latestOrderOf(o.Timestamp, o.BlockHeight, o.PinID)
+}
+
+// sortLatestDrifts orders drifts by collection and key
+func sortLatestDrifts(drifts []*model.LatestDrift) {
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Collection != drifts[j].Collection {
+			return drifts[i].Collection < drifts[j].Collection
+		}
+		return drifts[i].Key < drifts[j].Key
+	})
+}
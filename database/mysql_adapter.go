@@ -1,10 +1,12 @@
 package database
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"meta-file-system/model"
@@ -14,9 +16,15 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// MySQLDatabase MySQL database implementation
+// MySQLDatabase MySQL database implementation. It also serves SQLite (see
+// NewSQLiteDatabase); the few statements that differ check sqlite.
 type MySQLDatabase struct {
-	db *gorm.DB
+	db     *gorm.DB
+	sqlite bool
+
+	counterDeltas counterBuffer // counter deltas not yet flushed to tb_indexer_counter
+
+	changeMu sync.Mutex // serializes change log inserts so Seq becomes visible in order
 }
 
 // MySQLConfig MySQL configuration
@@ -60,7 +68,14 @@ func NewMySQLDatabase(config interface{}) (Database, error) {
 // IndexerFile operations
 
 func (m *MySQLDatabase) CreateIndexerFile(file *model.IndexerFile) error {
-	return m.db.Create(file).Error
+	if err := m.db.Create(file).Error; err != nil {
+		return err
+	}
+	if countedRow(file.Status, file.State) {
+		m.counterDeltas.addChain(model.CounterFiles, file.ChainName, 1)
+		m.addDailyFileStat(file, 1)
+	}
+	return nil
 }
 
 func (m *MySQLDatabase) GetIndexerFileByPinID(pinID string) (*model.IndexerFile, error) {
@@ -72,84 +87,116 @@ func (m *MySQLDatabase) GetIndexerFileByPinID(pinID string) (*model.IndexerFile,
 	return &file, err
 }
 
-func (m *MySQLDatabase) UpdateIndexerFile(file *model.IndexerFile) error {
-	return m.db.Save(file).Error
-}
-
-func (m *MySQLDatabase) ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, error) {
+func (m *MySQLDatabase) GetIndexerFilesByPinIDs(pinIDs []string) ([]*model.IndexerFile, error) {
 	var files []*model.IndexerFile
-	query := m.db.Where("status = ?", model.StatusSuccess)
-
-	if cursor > 0 {
-		query = query.Where("id < ?", cursor)
+	if len(pinIDs) == 0 {
+		return files, nil
 	}
+	err := m.db.Where("pin_id IN ?", pinIDs).Find(&files).Error
+	return files, err
+}
 
-	err := query.Order("id DESC").Limit(size).Find(&files).Error
-	if err != nil {
-		return nil, 0, err
+func (m *MySQLDatabase) UpdateIndexerFile(file *model.IndexerFile) error {
+	var old model.IndexerFile
+	found := file.ID > 0 && m.db.Select("status", "state", "chain_name").Where("id = ?", file.ID).First(&old).Error == nil
+	if err := m.db.Save(file).Error; err != nil {
+		return err
+	}
+	var delta int64
+	if found && countedRow(old.Status, old.State) {
+		m.counterDeltas.addChain(model.CounterFiles, old.ChainName, -1)
+		delta--
+	}
+	if countedRow(file.Status, file.State) {
+		m.counterDeltas.addChain(model.CounterFiles, file.ChainName, 1)
+		delta++
 	}
+	m.addDailyFileStat(file, delta)
+	return nil
+}
 
-	// Calculate nextCursor: cursor + number of records returned
-	nextCursor := cursor + int64(len(files))
-	return files, nextCursor, nil
+// addDailyFileStat counts file in the daily stats when its successful state changed by delta
+func (m *MySQLDatabase) addDailyFileStat(file *model.IndexerFile, delta int64) {
+	if stat := newFileDailyStat(file, delta); stat != nil {
+		if err := m.IncrDailyStat(stat); err != nil {
+			log.Printf("Failed to update daily file stats for PIN %s: %v", file.PinID, err)
+		}
+	}
 }
 
-func (m *MySQLDatabase) GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	var files []*model.IndexerFile
-	query := m.db.Where("creator_address = ? AND status = ?", address, model.StatusSuccess)
+func (m *MySQLDatabase) UpdateIndexerFileFields(file *model.IndexerFile) error {
+	// Indexes are maintained by MySQL; status is unchanged so counters are too
+	return m.db.Save(file).Error
+}
 
-	if cursor > 0 {
-		query = query.Where("id < ?", cursor)
+// pageFilesByOffset runs query with the offset cursor of the Pebble adapter:
+// newest first (timestamp, then PIN ID), skipping cursor records. One extra
+// record is read to tell whether another page follows.
+func pageFilesByOffset(query *gorm.DB, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	if cursor < 0 {
+		cursor = 0
 	}
-
-	err := query.Order("id DESC").Limit(size).Find(&files).Error
+	var files []*model.IndexerFile
+	err := query.Order("timestamp DESC, pin_id DESC").Offset(int(cursor)).Limit(size + 1).Find(&files).Error
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, false, err
+	}
+	hasMore := len(files) > size
+	if hasMore {
+		files = files[:size]
 	}
+	return files, cursor + int64(len(files)), hasMore, nil
+}
 
-	// Calculate nextCursor: cursor + number of records returned
-	nextCursor := cursor + int64(len(files))
-	return files, nextCursor, nil
+func (m *MySQLDatabase) ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	return pageFilesByOffset(m.db.Where("status = ?", model.StatusSuccess), cursor, size)
 }
 
-func (m *MySQLDatabase) GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	var files []*model.IndexerFile
-	query := m.db.Where("creator_meta_id = ? AND status = ?", metaID, model.StatusSuccess)
+func (m *MySQLDatabase) GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	return pageFilesByOffset(m.db.Where("creator_address = ? AND status = ?", address, model.StatusSuccess), cursor, size)
+}
 
-	if cursor > 0 {
-		query = query.Where("id < ?", cursor)
-	}
+// escapeLike escapes LIKE wildcards so s is matched literally (paths contain
+// "_"). The escape character is '!' because SQLite has no default one and
+// MySQL and SQLite quote a backslash differently.
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
 
-	err := query.Order("id DESC").Limit(size).Find(&files).Error
-	if err != nil {
-		return nil, 0, err
+// applyFileFilter adds WHERE clauses for the set fields of filter
+func applyFileFilter(query *gorm.DB, filter model.IndexerFileFilter) *gorm.DB {
+	if filter.FileType != "" {
+		query = query.Where("file_type = ?", filter.FileType)
+	}
+	if filter.ChainName != "" {
+		query = query.Where("chain_name = ?", filter.ChainName)
+	}
+	if filter.ContentTypePrefix != "" {
+		query = query.Where("content_type LIKE ? ESCAPE '!'", escapeLike(filter.ContentTypePrefix)+"%")
 	}
+	if filter.PathPrefix != "" {
+		prefix := strings.TrimSuffix(filter.PathPrefix, "/")
+		query = query.Where("(path = ? OR path LIKE ? ESCAPE '!')", prefix, escapeLike(prefix)+"/%")
+	}
+	return query
+}
 
-	// Calculate nextCursor: cursor + number of records returned
-	nextCursor := cursor + int64(len(files))
-	return files, nextCursor, nil
+func (m *MySQLDatabase) GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	query := m.db.Where("creator_meta_id = ? AND status = ?", metaID, model.StatusSuccess)
+	return pageFilesByOffset(applyFileFilter(query, filter), cursor, size)
 }
 
-func (m *MySQLDatabase) GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
+func (m *MySQLDatabase) GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
 	addrMap, err := m.GetGlobalMetaIdAddress(globalMetaID)
 	if err != nil || addrMap == nil || len(addrMap.Items) == 0 {
-		return nil, 0, nil
+		return nil, 0, false, nil
 	}
 	addrs := make([]string, 0, len(addrMap.Items))
 	for _, it := range addrMap.Items {
 		addrs = append(addrs, it.Address)
 	}
 	query := m.db.Where("creator_address IN ? AND status = ?", addrs, model.StatusSuccess)
-	if cursor > 0 {
-		query = query.Where("id < ?", cursor)
-	}
-	var files []*model.IndexerFile
-	err = query.Order("id DESC").Limit(size).Find(&files).Error
-	if err != nil {
-		return nil, 0, err
-	}
-	nextCursor := cursor + int64(len(files))
-	return files, nextCursor, nil
+	return pageFilesByOffset(applyFileFilter(query, filter), cursor, size)
 }
 
 func (m *MySQLDatabase) GetIndexerFilesByExtensionWithCursor(extension string, cursor string, size int) ([]*model.IndexerFile, string, error) {
@@ -298,17 +345,33 @@ func (m *MySQLDatabase) GetIndexerFilesByKeywordAndExtensionWithCursor(keyword s
 }
 
 func (m *MySQLDatabase) GetIndexerFilesCount() (int64, error) {
+	return m.GetCounter(model.CounterFiles)
+}
+
+func (m *MySQLDatabase) GetIndexerFilesCountByChain(chainName string) (int64, error) {
+	return m.GetCounter(model.ChainCounter(model.CounterFiles, chainName))
+}
+
+func (m *MySQLDatabase) GetIndexerFilesCountByCreatorMetaID(metaID string) (int64, error) {
 	var count int64
 	err := m.db.Model(&model.IndexerFile{}).
-		Where("status = ? AND state = 0", model.StatusSuccess).
+		Where("creator_meta_id = ? AND status = ? AND state = 0", metaID, model.StatusSuccess).
 		Count(&count).Error
 	return count, err
 }
 
-func (m *MySQLDatabase) GetIndexerFilesCountByChain(chainName string) (int64, error) {
+func (m *MySQLDatabase) GetIndexerFilesCountByCreatorGlobalMetaID(globalMetaID string) (int64, error) {
+	addrMap, err := m.GetGlobalMetaIdAddress(globalMetaID)
+	if err != nil || addrMap == nil || len(addrMap.Items) == 0 {
+		return 0, nil
+	}
+	addrs := make([]string, 0, len(addrMap.Items))
+	for _, it := range addrMap.Items {
+		addrs = append(addrs, it.Address)
+	}
 	var count int64
-	err := m.db.Model(&model.IndexerFile{}).
-		Where("chain_name = ? AND status = ? AND state = 0", chainName, model.StatusSuccess).
+	err = m.db.Model(&model.IndexerFile{}).
+		Where("creator_address IN ? AND status = ? AND state = 0", addrs, model.StatusSuccess).
 		Count(&count).Error
 	return count, err
 }
@@ -369,7 +432,13 @@ func (m *MySQLDatabase) ListIndexerUserAvatarsWithCursor(cursor int64, size int)
 // IndexerFileChunk operations
 
 func (m *MySQLDatabase) CreateIndexerFileChunk(chunk *model.IndexerFileChunk) error {
-	return m.db.Create(chunk).Error
+	if err := m.db.Create(chunk).Error; err != nil {
+		return err
+	}
+	if countedRow(chunk.Status, chunk.State) {
+		m.counterDeltas.addChain(model.CounterChunks, chunk.ChainName, 1)
+	}
+	return nil
 }
 
 func (m *MySQLDatabase) GetIndexerFileChunkByPinID(pinID string) (*model.IndexerFileChunk, error) {
@@ -389,8 +458,27 @@ func (m *MySQLDatabase) GetIndexerFileChunksByParentPinID(parentPinID string) ([
 	return chunks, err
 }
 
+func (m *MySQLDatabase) ListIndexerFileChunksBySha256(sha256 string) ([]*model.IndexerFileChunk, error) {
+	var chunks []*model.IndexerFileChunk
+	err := m.db.Where("chunk_sha256 = ? AND status = ?", sha256, model.StatusSuccess).
+		Order("id ASC").
+		Find(&chunks).Error
+	return chunks, err
+}
+
 func (m *MySQLDatabase) UpdateIndexerFileChunk(chunk *model.IndexerFileChunk) error {
-	return m.db.Save(chunk).Error
+	var old model.IndexerFileChunk
+	found := chunk.ID > 0 && m.db.Select("status", "state", "chain_name").Where("id = ?", chunk.ID).First(&old).Error == nil
+	if err := m.db.Save(chunk).Error; err != nil {
+		return err
+	}
+	if found && countedRow(old.Status, old.State) {
+		m.counterDeltas.addChain(model.CounterChunks, old.ChainName, -1)
+	}
+	if countedRow(chunk.Status, chunk.State) {
+		m.counterDeltas.addChain(model.CounterChunks, chunk.ChainName, 1)
+	}
+	return nil
 }
 
 // IndexerSyncStatus operations
@@ -455,10 +543,56 @@ func (m *MySQLDatabase) IterateLatestFileInfo(fn func(*model.IndexerFile) error)
 	return nil
 }
 
+// mysqlIterateBatchSize rows loaded per query by the Iterate* helpers
+const mysqlIterateBatchSize = 500
+
+func (m *MySQLDatabase) IterateIndexerFiles(fn func(*model.IndexerFile) error) error {
+	var files []*model.IndexerFile
+	return m.db.Order("id ASC").FindInBatches(&files, mysqlIterateBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, file := range files {
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+func (m *MySQLDatabase) IterateIndexerFileChunks(fn func(*model.IndexerFileChunk) error) error {
+	var chunks []*model.IndexerFileChunk
+	return m.db.Order("id ASC").FindInBatches(&chunks, mysqlIterateBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, chunk := range chunks {
+			if err := fn(chunk); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
 func (m *MySQLDatabase) WriteFileToExtensionAndGlobalMetaIndexes(file *model.IndexerFile) error {
 	return nil
 }
 
+func (m *MySQLDatabase) WriteFileToCreatorFilterIndex(file *model.IndexerFile) error {
+	return nil
+}
+
+func (m *MySQLDatabase) RebuildFileHashIndex() error {
+	return nil
+}
+
+func (m *MySQLDatabase) GetIndexerFilesByFileHash(fileHash string) ([]*model.IndexerFile, error) {
+	if fileHash == "" {
+		return nil, nil
+	}
+	var files []*model.IndexerFile
+	err := m.db.Where("file_hash = ? AND status = ?", fileHash, model.StatusSuccess).
+		Order("id ASC").
+		Find(&files).Error
+	return files, err
+}
+
 // UserInfo operations - not implemented for MySQL yet
 func (m *MySQLDatabase) CreateOrUpdateLatestUserNameInfo(info *model.UserNameInfo, metaID string) error {
 	return ErrNotImplemented
@@ -626,6 +760,52 @@ func (m *MySQLDatabase) DeletePendingIndexFile(pinID string) error {
 	return ErrNotImplemented
 }
 
+// ReassignIndexerFileCreator saves the corrected row; MySQL indexes the creator
+// columns itself
+func (m *MySQLDatabase) ReassignIndexerFileCreator(file, previous *model.IndexerFile) error {
+	return m.UpdateIndexerFile(file)
+}
+
+// PendingStorageWrite operations - indexer-only store; not implemented for MySQL
+func (m *MySQLDatabase) CreatePendingStorageWrite(w *model.PendingStorageWrite) error {
+	return ErrNotImplemented
+}
+
+func (m *MySQLDatabase) GetPendingStorageWrite(storagePath string) (*model.PendingStorageWrite, error) {
+	return nil, ErrNotImplemented
+}
+
+func (m *MySQLDatabase) ListPendingStorageWrites() ([]*model.PendingStorageWrite, error) {
+	return nil, ErrNotImplemented
+}
+
+func (m *MySQLDatabase) DeletePendingStorageWrite(storagePath string) error {
+	return ErrNotImplemented
+}
+
+// PendingCreatorResolution operations - indexer-only store; not implemented for MySQL
+func (m *MySQLDatabase) CreatePendingCreatorResolution(r *model.PendingCreatorResolution) error {
+	return ErrNotImplemented
+}
+
+func (m *MySQLDatabase) ListPendingCreatorResolutions() ([]*model.PendingCreatorResolution, error) {
+	return nil, ErrNotImplemented
+}
+
+func (m *MySQLDatabase) DeletePendingCreatorResolution(pinID string) error {
+	return ErrNotImplemented
+}
+
+// MetaID genesis operations - indexer-only store (metaid.derivation
+// genesis_txid needs Pebble); not implemented for MySQL
+func (m *MySQLDatabase) GetMetaIDGenesis(address string) (string, error) {
+	return "", ErrNotImplemented
+}
+
+func (m *MySQLDatabase) RecordMetaIDGenesis(address, pinID string) error {
+	return ErrNotImplemented
+}
+
 // MetaIdAddress operations - not implemented for MySQL yet
 func (m *MySQLDatabase) SaveMetaIdAddress(metaID, address string) error {
 	return ErrNotImplemented
@@ -648,7 +828,7 @@ func (m *MySQLDatabase) GetGlobalMetaIdAddress(globalMetaId string) (*model.Glob
 }
 
 // MetaIdTimestamp operations - not implemented for MySQL yet
-func (m *MySQLDatabase) SaveMetaIdTimestamp(metaID string, timestamp int64) error {
+func (m *MySQLDatabase) SaveMetaIdTimestamp(metaID, chainName string, timestamp int64) error {
 	return ErrNotImplemented
 }
 
@@ -660,8 +840,330 @@ func (m *MySQLDatabase) GetMetaIDCount() (int64, error) {
 	return 0, ErrNotImplemented
 }
 
+// DailyStat operations
+
+// IncrDailyStat adds the counters in delta to the (date, chain_name) row, creating it if needed
+func (m *MySQLDatabase) IncrDailyStat(delta *model.IndexerDailyStat) error {
+	if delta == nil || delta.Date == "" {
+		return fmt.Errorf("daily stat date cannot be empty")
+	}
+	upsert := "ON DUPLICATE KEY UPDATE new_files = new_files + VALUES(new_files), new_users = new_users + VALUES(new_users), " +
+		"bytes = bytes + VALUES(bytes), updated_at = VALUES(updated_at)"
+	if m.sqlite {
+		upsert = "ON CONFLICT (date, chain_name) DO UPDATE SET new_files = new_files + excluded.new_files, " +
+			"new_users = new_users + excluded.new_users, bytes = bytes + excluded.bytes, updated_at = excluded.updated_at"
+	}
+	return m.db.Exec(
+		"INSERT INTO tb_indexer_daily_stat (date, chain_name, new_files, new_users, bytes, updated_at) VALUES (?, ?, ?, ?, ?, ?) "+upsert,
+		delta.Date, delta.ChainName, delta.NewFiles, delta.NewUsers, delta.Bytes, time.Now(),
+	).Error
+}
+
+func (m *MySQLDatabase) ListDailyStats(fromDate, toDate string) ([]*model.IndexerDailyStat, error) {
+	var stats []*model.IndexerDailyStat
+	err := m.db.Where("date >= ? AND date <= ?", fromDate, toDate).
+		Order("date ASC, chain_name ASC").
+		Find(&stats).Error
+	return stats, err
+}
+
+// RebuildDailyStats recomputes tb_indexer_daily_stat from the successful first
+// PINs in tb_indexer_file, bucketed by UTC day. MetaID first-seen times are not
+// stored in MySQL, so new_users stays 0.
+func (m *MySQLDatabase) RebuildDailyStats() error {
+	day := "DATE(CONVERT_TZ(FROM_UNIXTIME(timestamp DIV 1000), @@session.time_zone, '+00:00'))"
+	if m.sqlite {
+		day = "date(timestamp / 1000, 'unixepoch')"
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM tb_indexer_daily_stat").Error; err != nil {
+			return err
+		}
+		return tx.Exec(
+			"INSERT INTO tb_indexer_daily_stat (date, chain_name, new_files, new_users, bytes, updated_at) "+
+				"SELECT "+day+" AS day, chain_name, "+
+				"COUNT(*), 0, COALESCE(SUM(file_size), 0), ? FROM tb_indexer_file "+
+				"WHERE status = ? AND state = 0 AND timestamp > 0 AND (first_pin_id = '' OR first_pin_id = pin_id) "+
+				"GROUP BY day, chain_name",
+			time.Now(), model.StatusSuccess,
+		).Error
+	})
+}
+
+// Counter operations
+
+// countedRow reports whether a file/chunk row counts: successful and not deleted
+func countedRow(status model.Status, state int64) bool {
+	return status == model.StatusSuccess && state == 0
+}
+
+// GetCounter returns the stored value of a counter plus its unflushed delta
+func (m *MySQLDatabase) GetCounter(name string) (int64, error) {
+	var counter model.IndexerCounter
+	err := m.db.Where("name = ?", name).First(&counter).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return 0, err
+	}
+	return max(counter.Value+m.counterDeltas.get(name), 0), nil
+}
+
+// ListCounters returns all counters whose name starts with prefix, including unflushed deltas
+func (m *MySQLDatabase) ListCounters(prefix string) (map[string]int64, error) {
+	var rows []*model.IndexerCounter
+	if err := m.db.Where("name LIKE ?", prefix+"%").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	counters := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counters[row.Name] = row.Value
+	}
+	for name, delta := range m.counterDeltas.snapshot() {
+		if strings.HasPrefix(name, prefix) {
+			counters[name] = max(counters[name]+delta, 0)
+		}
+	}
+	return counters, nil
+}
+
+// FlushCounters adds the buffered counter deltas to tb_indexer_counter in one transaction
+func (m *MySQLDatabase) FlushCounters() error {
+	deltas := m.counterDeltas.take()
+	if len(deltas) == 0 {
+		return nil
+	}
+	upsert := "INSERT INTO tb_indexer_counter (name, value, updated_at) VALUES (?, GREATEST(?, 0), ?) " +
+		"ON DUPLICATE KEY UPDATE value = GREATEST(value + ?, 0), updated_at = VALUES(updated_at)"
+	if m.sqlite {
+		// SQLite's scalar max() is MySQL's GREATEST()
+		upsert = "INSERT INTO tb_indexer_counter (name, value, updated_at) VALUES (?, max(?, 0), ?) " +
+			"ON CONFLICT (name) DO UPDATE SET value = max(value + ?, 0), updated_at = excluded.updated_at"
+	}
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for name, delta := range deltas {
+			if delta == 0 {
+				continue
+			}
+			if err := tx.Exec(
+				upsert,
+				name, delta, now, delta,
+			).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		m.counterDeltas.restore(deltas)
+	}
+	return err
+}
+
+// RepairLatestIndexes the latest_* collections are Pebble-only; not
+// implemented for MySQL
+func (m *MySQLDatabase) RepairLatestIndexes(fix bool) ([]*model.LatestDrift, error) {
+	return nil, ErrNotImplemented
+}
+
+// ReconcileCounters recounts files and chunks (total and per chain) from their
+// tables and compares them with tb_indexer_counter. Users are not tracked for
+// MySQL (no MetaID timestamp table).
+func (m *MySQLDatabase) ReconcileCounters(fix bool) ([]*model.CounterDrift, error) {
+	if err := m.FlushCounters(); err != nil {
+		return nil, err
+	}
+
+	actual := make(map[string]int64)
+	recount := func(table interface{}, name string) error {
+		var rows []struct {
+			ChainName string
+			Count     int64
+		}
+		err := m.db.Model(table).
+			Select("chain_name, COUNT(*) AS count").
+			Where("status = ? AND state = 0", model.StatusSuccess).
+			Group("chain_name").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			actual[name] += row.Count
+			if row.ChainName != "" {
+				actual[model.ChainCounter(name, row.ChainName)] = row.Count
+			}
+		}
+		return nil
+	}
+	if err := recount(&model.IndexerFile{}, model.CounterFiles); err != nil {
+		return nil, err
+	}
+	if err := recount(&model.IndexerFileChunk{}, model.CounterChunks); err != nil {
+		return nil, err
+	}
+
+	stored, err := m.ListCounters("")
+	if err != nil {
+		return nil, err
+	}
+	drifts := counterDrifts(stored, actual)
+	if !fix || len(drifts) == 0 {
+		return drifts, nil
+	}
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		for _, drift := range drifts {
+			if err := tx.Save(&model.IndexerCounter{Name: drift.Name, Value: drift.Actual}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return drifts, err
+}
+
+// Watchlist operations
+
+func (m *MySQLDatabase) CreateWatch(watch *model.IndexerWatch) error {
+	if watch.Target == "" {
+		return fmt.Errorf("watch target cannot be empty")
+	}
+	return m.db.Create(watch).Error
+}
+
+func (m *MySQLDatabase) DeleteWatch(id int64) error {
+	result := m.db.Delete(&model.IndexerWatch{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MySQLDatabase) ListWatches() ([]*model.IndexerWatch, error) {
+	var watches []*model.IndexerWatch
+	err := m.db.Order("id ASC").Find(&watches).Error
+	return watches, err
+}
+
+func (m *MySQLDatabase) AddWatchEvent(event *model.IndexerWatchEvent, outbox ...*model.IndexerOutboxEvent) error {
+	if event.Target == "" {
+		return fmt.Errorf("watch event target cannot be empty")
+	}
+	if len(outbox) == 0 {
+		return m.db.Create(event).Error
+	}
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		for _, entry := range outbox {
+			entry.Event = data
+		}
+		return tx.Create(outbox).Error
+	})
+}
+
+func (m *MySQLDatabase) ListWatchEvents(target string, afterID int64, size int) ([]*model.IndexerWatchEvent, error) {
+	var events []*model.IndexerWatchEvent
+	err := m.db.Where("target = ? AND id > ?", target, afterID).
+		Order("id ASC").
+		Limit(size).
+		Find(&events).Error
+	return events, err
+}
+
+func (m *MySQLDatabase) ListOutboxEvents(afterID int64, size int) ([]*model.IndexerOutboxEvent, error) {
+	var entries []*model.IndexerOutboxEvent
+	err := m.db.Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(size).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (m *MySQLDatabase) UpdateOutboxEvent(entry *model.IndexerOutboxEvent) error {
+	if entry.ID == 0 {
+		return fmt.Errorf("outbox entry ID cannot be empty")
+	}
+	return m.db.Save(entry).Error
+}
+
+func (m *MySQLDatabase) DeleteOutboxEvent(id int64) error {
+	return m.db.Delete(&model.IndexerOutboxEvent{}, id).Error
+}
+
+// Custom domain operations
+
+func (m *MySQLDatabase) SaveDomain(domain *model.IndexerDomain) error {
+	if domain.Domain == "" {
+		return fmt.Errorf("domain cannot be empty")
+	}
+	if existing, err := m.GetDomain(domain.Domain); err == nil {
+		domain.CreatedAt = existing.CreatedAt
+	}
+	return m.db.Save(domain).Error
+}
+
+func (m *MySQLDatabase) GetDomain(domain string) (*model.IndexerDomain, error) {
+	var mapping model.IndexerDomain
+	err := m.db.Where("domain = ?", domain).First(&mapping).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (m *MySQLDatabase) DeleteDomain(domain string) error {
+	result := m.db.Where("domain = ?", domain).Delete(&model.IndexerDomain{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (m *MySQLDatabase) ListDomains() ([]*model.IndexerDomain, error) {
+	var domains []*model.IndexerDomain
+	err := m.db.Order("domain ASC").Find(&domains).Error
+	return domains, err
+}
+
+func (m *MySQLDatabase) AppendChangeEvent(event *model.IndexerChangeEvent) error {
+	if event.Action == "" {
+		return fmt.Errorf("change event action cannot be empty")
+	}
+	event.Seq = 0
+	m.changeMu.Lock()
+	defer m.changeMu.Unlock()
+	return m.db.Create(event).Error
+}
+
+func (m *MySQLDatabase) ListChangeEvents(sinceSeq int64, size int) ([]*model.IndexerChangeEvent, error) {
+	var events []*model.IndexerChangeEvent
+	err := m.db.Where("seq > ?", sinceSeq).
+		Order("seq ASC").
+		Limit(size).
+		Find(&events).Error
+	return events, err
+}
+
 // Close close database connection
 func (m *MySQLDatabase) Close() error {
+	if err := m.FlushCounters(); err != nil {
+		log.Printf("Failed to flush counters: %v", err)
+	}
 	sqlDB, err := m.db.DB()
 	if err != nil {
 		return err
@@ -6,10 +6,10 @@ import (
 	"log"
 	"math/rand"
 	"os"
-	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -21,16 +21,49 @@ import (
 
 // PebbleDatabase PebbleDB database implementation with multiple collections
 type PebbleDatabase struct {
-	collections map[string]*pebble.DB // Map of collection name to PebbleDB instance
+	collections map[string]*pebbleCollection // Map of collection name to collection
+	stores      map[string]*pebble.DB        // Pebble instances holding the collections, by name
+	sharedCache bool                         // Instances share one block cache (PebbleConfig.CacheSize)
 
 	fileIDCounter   atomic.Int64
 	avatarIDCounter atomic.Int64
 	statusIDCounter atomic.Int64
+	watchIDCounter  atomic.Int64
+	eventIDCounter  atomic.Int64
+	outboxIDCounter atomic.Int64
+
+	changeMu  sync.Mutex // assigns change log sequence numbers in write order
+	changeSeq int64
+
+	dailyStatsMu sync.Mutex // serializes read-modify-write of daily_stats
+
+	genesisMu sync.Mutex // serializes first-write-wins of metaid_genesis
+
+	statCountersMu sync.Mutex    // serializes flush / reconcile of stat_counters
+	counterDeltas  counterBuffer // counter deltas not yet flushed
 }
 
-// PebbleConfig PebbleDB configuration
+// PebbleConfig PebbleDB configuration. The tuning options apply to every
+// Pebble instance: the single store, or each collection of a data directory
+// still in the legacy layout; 0 keeps Pebble's default.
 type PebbleConfig struct {
-	DataDir string
+	DataDir                  string
+	CacheSize                int64  // Block cache shared by all instances (bytes); 0 = one default cache per instance
+	MemTableSize             uint64 // Memtable size of each instance (bytes)
+	MaxConcurrentCompactions int    // Concurrent compactions per instance
+}
+
+// options the pebble.Options of one instance; cache is the shared block
+// cache, nil for Pebble's default
+func (c *PebbleConfig) options(cache *pebble.Cache) *pebble.Options {
+	opts := &pebble.Options{
+		Cache:        cache,
+		MemTableSize: c.MemTableSize,
+	}
+	if n := c.MaxConcurrentCompactions; n > 0 {
+		opts.MaxConcurrentCompactions = func() int { return n }
+	}
+	return opts
 }
 
 // Collection names and their key-value formats
@@ -40,8 +73,9 @@ const (
 	collectionFilePinID                          = "file_pin"                                // key: {pin_id}, value: JSON(IndexerFile) - PinID 到 ID 的映射
 	collectionFileAddress                        = "file_addr"                               // key: {address}:{first_pin_id}, value: JSON(IndexerFile) - 按地址索引
 	collectionFileMetaID                         = "file_meta"                               // key: {meta_id}:{first_pin_id}, value: JSON(IndexerFile) - 按 MetaID 索引
+	collectionFileMetaIDTypeChain                = "file_meta_type_chain"                    // key: {meta_id}:{file_type}:{chain_name}:{first_pin_id}, value: JSON(IndexerFile) - 按 MetaID + 文件类型 + 链过滤
 	collectionFileGlobalMetaID                   = "file_global_meta"                        // key: {global_meta_id}:{first_pin_id}, value: JSON(IndexerFile) - 按 GlobalMetaID 索引
-	collectionFileHash                           = "file_hash"                               // key: {hash}:{pin_id}, value: JSON(IndexerFile) - 按 Hash 索引
+	collectionFileHash                           = "file_hash"                               // key: {file_hash}:{pin_id}, value: JSON(IndexerFile) - 按内容 SHA256 索引（mfs://{sha256}）
 	collectionFileInfoHistory                    = "file_info_history"                       // key: {first_pin_id}, value: JSON(List[{pin_id, path, operation, content_type, chain_name, block_height, timestamp}]) - 按地址索引
 	collectionFileExtensionTimestamp             = "file_extension_timestamp"                // key: {extension}:{timestamp_16}, value: JSON(IndexerFile)
 	collectionGlobalMetaIDFileExtensionTimestamp = "global_meta_id_file_extension_timestamp" // key: {global_meta_id}:{extension}:{timestamp_16}, value: JSON(IndexerFile)
@@ -60,6 +94,7 @@ const (
 	// FileChunk collections
 	collectionFileChunkPinID       = "file_chunk_pin"    // key: {pin_id}, value: JSON(IndexerFileChunk) - PinID 到 chunk 的映射
 	collectionFileChunkParentPinID = "file_chunk_parent" // key: {parent_pin_id}:{chunk_index}, value: JSON(IndexerFileChunk) - 按父 PIN ID 索引
+	collectionFileChunkSha256      = "file_chunk_sha256" // key: {sha256}:{pin_id}, value: JSON(IndexerFileChunk) - 按内容 Hash 索引（重复上链的分片）
 
 	// UserInfo collections
 	collectionMetaIdAddress               = "meta_id_address"                  // key: {meta_id} Or {address}, value: JSON({meta_id, address}) - 按 MetaID 或地址索引
@@ -90,10 +125,34 @@ const (
 	// PendingIndexFile collections (deferred multi-chunk index merges)
 	collectionPendingIndexFile = "pending_index_file" // key: {index_pin_id}, value: JSON(PendingIndexFile) - chunk-miss 重试记录
 
+	// PendingStorageWrite collections (storage writes queued for retry)
+	collectionPendingStorageWrite = "pending_storage_write" // key: {storage_path}, value: JSON(PendingStorageWrite) - 存储写入重试队列
+
+	// PendingCreatorResolution collections (creator address lookups queued for retry)
+	collectionPendingCreatorResolution = "pending_creator_resolution" // key: {pin_id}, value: JSON(PendingCreatorResolution) - 创建者地址解析重试队列
+
+	// MetaID genesis collections (metaid.derivation genesis_txid)
+	collectionMetaIDGenesis = "metaid_genesis" // key: {address}, value: {genesis pin_id} - 地址的首个 PIN
+
 	// System collections
 	collectionSyncStatus = "sync_status" // key: {chain_name}, value: JSON(IndexerSyncStatus) - 同步状态
 	collectionCounters   = "counters"    // key: file/avatar/status, value: {max_id} - ID 计数器
 
+	// Stats collections
+	collectionDailyStats   = "daily_stats"   // key: {date}:{chain_name}, value: JSON(IndexerDailyStat) - 按天按链的增量统计
+	collectionStatCounters = "stat_counters" // key: {counter name}（files / chunks:chain:{chain_name} / files:meta:{meta_id} ...）, value: {count} - 按块维护的计数器
+
+	// Watchlist collections
+	collectionWatchlist   = "watchlist"    // key: {id:020d}, value: JSON(IndexerWatch) - 关注的地址/MetaID
+	collectionWatchEvents = "watch_events" // key: {target}:{id:020d}, value: JSON(IndexerWatchEvent) - 关注对象的新 PIN 事件
+	// key: ;outbox:{id:020d}, value: JSON(IndexerOutboxEvent) - 待投递的事件（与事件同一批次写入；target 不含 ';'，不会冲突）
+
+	// Custom domain collections
+	collectionDomains = "domains" // key: {domain}, value: JSON(IndexerDomain) - 自定义域名映射
+
+	// Change log collections
+	collectionChangeEvents = "change_events" // key: {seq:020d}, value: JSON(IndexerChangeEvent) - 追加写入的索引变更日志
+
 	collectionVersion = "version" // key: version, value: {version} - 版本号
 )
 
@@ -102,11 +161,73 @@ const (
 	keyFileCounter   = "file"
 	keyAvatarCounter = "avatar"
 	keyStatusCounter = "status"
+	keyWatchCounter  = "watch"
+	keyEventCounter  = "watch_event"
+	keyOutboxCounter = "outbox_event"
+	keyChangeCounter = "change_event"
 )
 
 // Schema version key (in collectionVersion)
 const keySchemaVersion = "schema_version"
 
+// pebbleCollectionNames every collection of the indexer database
+var pebbleCollectionNames = []string{
+	collectionLatestFileInfo,
+	collectionFilePinID,
+	collectionFileAddress,
+	collectionFileMetaID,
+	collectionFileMetaIDTypeChain,
+	collectionFileHash,
+	collectionFileInfoHistory,
+	collectionChainFileInfo,
+	collectionFileGlobalMetaID,
+	collectionFileExtensionTimestamp,
+	collectionGlobalMetaIDFileExtensionTimestamp,
+	collectionAvatarPinID,
+	collectionAvatarMetaID,
+	collectionAvatarMetaIDTimestamp,
+	collectionAvatarAddr,
+	collectionAvatarHash,
+	collectionLasestAvatarMetaID,
+	collectionFileChunkPinID,
+	collectionFileChunkParentPinID,
+	collectionFileChunkSha256,
+	collectionMetaIdAddress,
+	collectionGlobalMetaIdAddress,
+	collectionMetaIdTimestamp,
+	collectionLatestUserNameInfo,
+	collectionLatestUserAvatarInfo,
+	collectionLatestUserBioInfo,
+	collectionLatestUserChatPublicKeyInfo,
+	collectionUserNameInfoHistory,
+	collectionUserAvatarInfoHistory,
+	collectionUserBioInfoHistory,
+	collectionUserChatPublicKeyHistory,
+	collectionUserAvatarInfo,
+	collectionLatestUserNameInfoByGlobalMetaId,
+	collectionUserNameInfoHistoryByGlobalMetaId,
+	collectionLatestUserAvatarInfoByGlobalMetaId,
+	collectionUserAvatarInfoHistoryByGlobalMetaId,
+	collectionLatestUserBioInfoByGlobalMetaId,
+	collectionUserBioInfoHistoryByGlobalMetaId,
+	collectionLatestUserChatPublicKeyInfoByGlobalMetaId,
+	collectionUserChatPublicKeyHistoryByGlobalMetaId,
+	collectionPinInfo,
+	collectionPendingIndexFile,
+	collectionPendingStorageWrite,
+	collectionPendingCreatorResolution,
+	collectionMetaIDGenesis,
+	collectionSyncStatus,
+	collectionCounters,
+	collectionDailyStats,
+	collectionStatCounters,
+	collectionWatchlist,
+	collectionWatchEvents,
+	collectionDomains,
+	collectionChangeEvents,
+	collectionVersion,
+}
+
 // NewPebbleDatabase create PebbleDB database instance with multiple collections
 func NewPebbleDatabase(config interface{}) (Database, error) {
 	cfg, ok := config.(*PebbleConfig)
@@ -121,77 +242,24 @@ func NewPebbleDatabase(config interface{}) (Database, error) {
 
 	log.Printf("PebbleDB data directory: %s", cfg.DataDir)
 
-	// List of all collections
-	collectionNames := []string{
-		collectionLatestFileInfo,
-		collectionFilePinID,
-		collectionFileAddress,
-		collectionFileMetaID,
-		collectionFileHash,
-		collectionFileInfoHistory,
-		collectionChainFileInfo,
-		collectionFileGlobalMetaID,
-		collectionFileExtensionTimestamp,
-		collectionGlobalMetaIDFileExtensionTimestamp,
-		collectionAvatarPinID,
-		collectionAvatarMetaID,
-		collectionAvatarMetaIDTimestamp,
-		collectionAvatarAddr,
-		collectionAvatarHash,
-		collectionLasestAvatarMetaID,
-		collectionFileChunkPinID,
-		collectionFileChunkParentPinID,
-		collectionMetaIdAddress,
-		collectionGlobalMetaIdAddress,
-		collectionMetaIdTimestamp,
-		collectionLatestUserNameInfo,
-		collectionLatestUserAvatarInfo,
-		collectionLatestUserBioInfo,
-		collectionLatestUserChatPublicKeyInfo,
-		collectionUserNameInfoHistory,
-		collectionUserAvatarInfoHistory,
-		collectionUserBioInfoHistory,
-		collectionUserChatPublicKeyHistory,
-		collectionUserAvatarInfo,
-		collectionLatestUserNameInfoByGlobalMetaId,
-		collectionUserNameInfoHistoryByGlobalMetaId,
-		collectionLatestUserAvatarInfoByGlobalMetaId,
-		collectionUserAvatarInfoHistoryByGlobalMetaId,
-		collectionLatestUserBioInfoByGlobalMetaId,
-		collectionUserBioInfoHistoryByGlobalMetaId,
-		collectionLatestUserChatPublicKeyInfoByGlobalMetaId,
-		collectionUserChatPublicKeyHistoryByGlobalMetaId,
-		collectionPinInfo,
-		collectionPendingIndexFile,
-		collectionSyncStatus,
-		collectionCounters,
-		collectionVersion,
-	}
-
-	// Open PebbleDB for each collection
-	collections := make(map[string]*pebble.DB)
-	for _, name := range collectionNames {
-		// Create collection path: dataDir/collectionName
-		collectionPath := filepath.Join(cfg.DataDir, "indexer_db", name)
-
-		log.Printf("Opening collection: %s at %s", name, collectionPath)
-
-		// PebbleDB will create the directory automatically, but we ensure parent exists
-		// No need to create the collection directory manually
-		db, err := pebble.Open(collectionPath, &pebble.Options{})
-		if err != nil {
-			// Close previously opened databases
-			for _, openedDB := range collections {
-				openedDB.Close()
-			}
-			return nil, fmt.Errorf("failed to open collection %s at %s: %w", name, collectionPath, err)
-		}
-		collections[name] = db
-		log.Printf("Collection %s opened successfully", name)
+	// One block cache for all collections when configured; every Open takes
+	// its own reference, released when the instance is closed
+	var cache *pebble.Cache
+	if cfg.CacheSize > 0 {
+		cache = pebble.NewCache(cfg.CacheSize)
+		defer cache.Unref()
+		log.Printf("PebbleDB shared block cache: %d MB", cfg.CacheSize>>20)
+	}
+
+	collections, stores, err := openPebbleStores(cfg, cache)
+	if err != nil {
+		return nil, err
 	}
 
 	pdb := &PebbleDatabase{
 		collections: collections,
+		stores:      stores,
+		sharedCache: cache != nil,
 	}
 
 	// Load counters
@@ -199,7 +267,7 @@ func NewPebbleDatabase(config interface{}) (Database, error) {
 		return nil, fmt.Errorf("failed to load counters: %w", err)
 	}
 
-	log.Printf("PebbleDB database connected successfully with %d collections", len(collections))
+	log.Printf("PebbleDB database connected successfully with %d collections in %d instance(s)", len(collections), len(stores))
 	return pdb, nil
 }
 
@@ -228,6 +296,29 @@ func (p *PebbleDatabase) loadCounters() error {
 		closer.Close()
 	}
 
+	// Load watchlist counters
+	if val, closer, err := counterDB.Get([]byte(keyWatchCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.watchIDCounter.Store(count)
+		closer.Close()
+	}
+	if val, closer, err := counterDB.Get([]byte(keyEventCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.eventIDCounter.Store(count)
+		closer.Close()
+	}
+	if val, closer, err := counterDB.Get([]byte(keyOutboxCounter)); err == nil {
+		count, _ := strconv.ParseInt(string(val), 10, 64)
+		p.outboxIDCounter.Store(count)
+		closer.Close()
+	}
+
+	// Load change log sequence
+	if val, closer, err := counterDB.Get([]byte(keyChangeCounter)); err == nil {
+		p.changeSeq, _ = strconv.ParseInt(string(val), 10, 64)
+		closer.Close()
+	}
+
 	return nil
 }
 
@@ -253,10 +344,11 @@ func makeTimestamp16(ts int64) string {
 
 // IndexerFile operations
 
-// paginateFilesByTimestampDesc sorts files by timestamp desc (fallback PinID) then slices by cursor+size.
-func paginateFilesByTimestampDesc(files []*model.IndexerFile, cursor int64, size int) ([]*model.IndexerFile, int64) {
+// paginateFilesByTimestampDesc sorts files by timestamp desc (fallback PinID) then slices by cursor+size;
+// hasMore reports whether files remain after the page.
+func paginateFilesByTimestampDesc(files []*model.IndexerFile, cursor int64, size int) ([]*model.IndexerFile, int64, bool) {
 	if len(files) == 0 || size <= 0 {
-		return nil, cursor
+		return nil, cursor, false
 	}
 
 	if cursor < 0 {
@@ -272,7 +364,7 @@ func paginateFilesByTimestampDesc(files []*model.IndexerFile, cursor int64, size
 
 	start := int(cursor)
 	if start >= len(files) {
-		return nil, cursor
+		return nil, cursor, false
 	}
 
 	end := start + size
@@ -282,7 +374,7 @@ func paginateFilesByTimestampDesc(files []*model.IndexerFile, cursor int64, size
 
 	paged := files[start:end]
 	nextCursor := cursor + int64(len(paged))
-	return paged, nextCursor
+	return paged, nextCursor, end < len(files)
 }
 
 func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
@@ -292,6 +384,26 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 		return err
 	}
 
+	firstPinID := file.FirstPinID
+	if firstPinID == "" {
+		firstPinID = file.PinID // Fallback to PinID if FirstPinID is not set
+	}
+
+	// Work out how this write changes the successful-file counters before any
+	// index is overwritten
+	var counts fileCountDelta
+	if counts.total, err = successDelta(p.collections[collectionFilePinID], []byte(file.PinID), file.Status); err != nil {
+		return err
+	}
+	if counts.metaID, err = successDelta(p.collections[collectionFileMetaID], []byte(file.CreatorMetaId+":"+firstPinID), file.Status); err != nil {
+		return err
+	}
+	if file.CreatorGlobalMetaId != "" {
+		if counts.globalMetaID, err = successDelta(p.collections[collectionFileGlobalMetaID], []byte(file.CreatorGlobalMetaId+":"+firstPinID), file.Status); err != nil {
+			return err
+		}
+	}
+
 	// Store in PinID collection (primary index)
 	// key: pin_id, value: JSON(IndexerFile)
 	if err := p.collections[collectionFilePinID].Set([]byte(file.PinID), data, pebble.Sync); err != nil {
@@ -321,8 +433,8 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 				return err
 			}
 
-			// Update if new file has a later timestamp
-			if file.Timestamp > existingFile.Timestamp {
+			// Update if new file is the later version (see laterPIN), or it is the same PIN being updated (e.g. confirmed)
+			if file.PinID == existingFile.PinID || laterPIN(latestOrderOf(file.Timestamp, file.BlockHeight, file.PinID), latestOrderOf(existingFile.Timestamp, existingFile.BlockHeight, existingFile.PinID)) {
 				shouldUpdate = true
 			}
 		}
@@ -336,10 +448,6 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 
 	// Store in Address index collection
 	// key: address:first_pin_id, value: JSON(IndexerFile)
-	firstPinID := file.FirstPinID
-	if firstPinID == "" {
-		firstPinID = file.PinID // Fallback to PinID if FirstPinID is not set
-	}
 	addressKey := file.CreatorAddress + ":" + firstPinID
 	if err := p.collections[collectionFileAddress].Set([]byte(addressKey), data, pebble.Sync); err != nil {
 		return err
@@ -348,6 +456,9 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 	// Store in MetaID index collection
 	// key: meta_id:first_pin_id, value: JSON(IndexerFile)
 	metaIDKey := file.CreatorMetaId + ":" + firstPinID
+	if err := p.writeFileToCreatorFilterIndex(file, data); err != nil {
+		return err
+	}
 	if err := p.collections[collectionFileMetaID].Set([]byte(metaIDKey), data, pebble.Sync); err != nil {
 		return err
 	}
@@ -358,9 +469,8 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 	}
 
 	// Store in Hash index collection
-	// key: hash:pin_id, value: JSON(IndexerFile)
-	hashKey := file.FileMd5 + ":" + file.PinID
-	if err := p.collections[collectionFileHash].Set([]byte(hashKey), data, pebble.Sync); err != nil {
+	// key: file_hash:pin_id, value: JSON(IndexerFile)
+	if err := p.writeFileToHashIndex(file, data); err != nil {
 		return err
 	}
 
@@ -377,6 +487,7 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 		}
 
 		shouldUpdateChain := false
+		existingSuccess := false
 		if err == pebble.ErrNotFound {
 			// No existing file, this is the first one
 			shouldUpdateChain = true
@@ -388,19 +499,32 @@ func (p *PebbleDatabase) CreateIndexerFile(file *model.IndexerFile) error {
 				return err
 			}
 
-			// Update if new file has a later timestamp
-			if file.Timestamp > existingChainFile.Timestamp {
+			// Update if new file has a later timestamp, or it is the same PIN being updated (e.g. failed -> success)
+			if file.Timestamp > existingChainFile.Timestamp || file.PinID == existingChainFile.PinID {
 				shouldUpdateChain = true
 			}
+			existingSuccess = existingChainFile.Status == model.StatusSuccess
 		}
 
 		if shouldUpdateChain {
 			if err := chainFileDB.Set([]byte(chainFileKey), data, pebble.Sync); err != nil {
 				return err
 			}
+			if file.Status == model.StatusSuccess {
+				counts.chain++
+			}
+			if existingSuccess {
+				counts.chain--
+			}
 		}
 	}
 
+	p.bufferFileCountDelta(file, counts)
+	if stat := newFileDailyStat(file, counts.total); stat != nil {
+		if err := p.IncrDailyStat(stat); err != nil {
+			log.Printf("Failed to update daily file stats for PIN %s: %v", file.PinID, err)
+		}
+	}
 	return nil
 }
 
@@ -431,6 +555,52 @@ func (p *PebbleDatabase) writeFileToGlobalMetaAndExtensionIndexes(file *model.In
 	return nil
 }
 
+// creatorFilterKey builds the file_meta_type_chain key: {meta_id}:{file_type}:{chain_name}:{first_pin_id}
+func creatorFilterKey(file *model.IndexerFile, firstPinID string) string {
+	return file.CreatorMetaId + ":" + strings.ToLower(file.FileType) + ":" + strings.ToLower(file.ChainName) + ":" + firstPinID
+}
+
+// writeFileToCreatorFilterIndex writes the MetaID + file type + chain index. The previous
+// version under {meta_id}:{first_pin_id} is read first so a modify that changes the file
+// type does not leave a stale key behind. Must run before file_meta is overwritten.
+func (p *PebbleDatabase) writeFileToCreatorFilterIndex(file *model.IndexerFile, data []byte) error {
+	firstPinID := file.FirstPinID
+	if firstPinID == "" {
+		firstPinID = file.PinID
+	}
+	if file.CreatorMetaId == "" || firstPinID == "" {
+		return nil
+	}
+	db := p.collections[collectionFileMetaIDTypeChain]
+	newKey := creatorFilterKey(file, firstPinID)
+
+	prevData, closer, err := p.collections[collectionFileMetaID].Get([]byte(file.CreatorMetaId + ":" + firstPinID))
+	if err == nil {
+		var prev model.IndexerFile
+		if jsonErr := json.Unmarshal(prevData, &prev); jsonErr == nil {
+			if oldKey := creatorFilterKey(&prev, firstPinID); oldKey != newKey {
+				if err := db.Delete([]byte(oldKey), pebble.Sync); err != nil {
+					closer.Close()
+					return err
+				}
+			}
+		}
+		closer.Close()
+	} else if err != pebble.ErrNotFound {
+		return err
+	}
+
+	return db.Set([]byte(newKey), data, pebble.Sync)
+}
+
+func (p *PebbleDatabase) WriteFileToCreatorFilterIndex(file *model.IndexerFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	return p.writeFileToCreatorFilterIndex(file, data)
+}
+
 func (p *PebbleDatabase) GetIndexerSchemaVersion() (int, error) {
 	db := p.collections[collectionVersion]
 	val, closer, err := db.Get([]byte(keySchemaVersion))
@@ -469,6 +639,42 @@ func (p *PebbleDatabase) IterateLatestFileInfo(fn func(*model.IndexerFile) error
 	return nil
 }
 
+func (p *PebbleDatabase) IterateIndexerFiles(fn func(*model.IndexerFile) error) error {
+	iter, err := p.collections[collectionFilePinID].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+		if err := fn(&file); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+func (p *PebbleDatabase) IterateIndexerFileChunks(fn func(*model.IndexerFileChunk) error) error {
+	iter, err := p.collections[collectionFileChunkPinID].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		var chunk model.IndexerFileChunk
+		if err := json.Unmarshal(iter.Value(), &chunk); err != nil {
+			continue
+		}
+		if err := fn(&chunk); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
 func (p *PebbleDatabase) WriteFileToExtensionAndGlobalMetaIndexes(file *model.IndexerFile) error {
 	data, err := json.Marshal(file)
 	if err != nil {
@@ -496,134 +702,510 @@ func (p *PebbleDatabase) GetIndexerFileByPinID(pinID string) (*model.IndexerFile
 	return &file, nil
 }
 
-func (p *PebbleDatabase) UpdateIndexerFile(file *model.IndexerFile) error {
-	// Simply recreate (overwrite)
-	return p.CreateIndexerFile(file)
-}
-
-func (p *PebbleDatabase) ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	filePinDB := p.collections[collectionFilePinID]
+// GetIndexerFilesByPinIDs looks up many PinIDs with one iterator: keys are
+// visited in sorted order with SeekGE instead of one Get per ID.
+func (p *PebbleDatabase) GetIndexerFilesByPinIDs(pinIDs []string) ([]*model.IndexerFile, error) {
+	var files []*model.IndexerFile
+	if len(pinIDs) == 0 {
+		return files, nil
+	}
+	keys := append([]string(nil), pinIDs...)
+	sort.Strings(keys)
 
-	// Create iterator for PinID collection
-	iter, err := filePinDB.NewIter(nil)
+	iter, err := p.collections[collectionFilePinID].NewIter(nil)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer iter.Close()
 
-	var files []*model.IndexerFile
-	for iter.First(); iter.Valid(); iter.Next() {
+	for i, key := range keys {
+		if i > 0 && key == keys[i-1] {
+			continue
+		}
+		if !iter.SeekGE([]byte(key)) || string(iter.Key()) != key {
+			continue
+		}
 		var file model.IndexerFile
 		if err := json.Unmarshal(iter.Value(), &file); err != nil {
 			continue
 		}
-
-		if file.Status == model.StatusSuccess {
-			fileCopy := file
-			files = append(files, &fileCopy)
-		}
+		files = append(files, &file)
 	}
-
-	sorted, nextCursor := paginateFilesByTimestampDesc(files, cursor, size)
-	return sorted, nextCursor, nil
+	return files, iter.Error()
 }
 
-func (p *PebbleDatabase) GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	addressDB := p.collections[collectionFileAddress]
-	prefix := address + ":"
+func (p *PebbleDatabase) UpdateIndexerFile(file *model.IndexerFile) error {
+	// Simply recreate (overwrite)
+	return p.CreateIndexerFile(file)
+}
 
-	// Create iterator with prefix
-	// key format: address:pin_id
-	iter, err := addressDB.NewIter(&pebble.IterOptions{
-		LowerBound: []byte(prefix),
-		UpperBound: []byte(prefix + "~"),
-	})
+// UpdateIndexerFileFields rewrites every stored copy of file under its existing
+// keys. Unlike UpdateIndexerFile it never adds index keys (the extension indexes
+// use random-suffix timestamp keys, so a re-create leaves duplicates behind) and
+// never changes counters. Copies held by another PIN (e.g. a newer latest-file
+// record) are left alone.
+func (p *PebbleDatabase) UpdateIndexerFileFields(file *model.IndexerFile) error {
+	if _, err := p.GetIndexerFileByPinID(file.PinID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(file)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
-	defer iter.Close()
 
-	var files []*model.IndexerFile
-	for iter.First(); iter.Valid(); iter.Next() {
-		var file model.IndexerFile
-		if err := json.Unmarshal(iter.Value(), &file); err != nil {
-			continue
+	firstPinID := file.FirstPinID
+	if firstPinID == "" {
+		firstPinID = file.PinID
+	}
+	keyed := []struct {
+		collection string
+		key        string
+	}{
+		{collectionFilePinID, file.PinID},
+		{collectionLatestFileInfo, firstPinID},
+		{collectionFileAddress, file.CreatorAddress + ":" + firstPinID},
+		{collectionFileMetaID, file.CreatorMetaId + ":" + firstPinID},
+		{collectionFileMetaIDTypeChain, creatorFilterKey(file, firstPinID)},
+		{collectionFileGlobalMetaID, file.CreatorGlobalMetaId + ":" + firstPinID},
+		{collectionFileHash, file.FileHash + ":" + file.PinID},
+		{collectionChainFileInfo, file.ChainName + ":" + firstPinID},
+	}
+	for _, k := range keyed {
+		if err := p.rewriteFileCopy(p.collections[k.collection], []byte(k.key), file.PinID, data); err != nil {
+			return err
 		}
+	}
 
-		if file.Status == model.StatusSuccess {
-			fileCopy := file
-			files = append(files, &fileCopy)
+	// Timestamp keys carry a random suffix: rewrite the entries for this PIN
+	// among those sharing its second
+	tsPrefix := normalizeFileExtension(file.FileExtension) + ":" + fmt.Sprintf("%010d", file.Timestamp)
+	if err := p.rewriteFileCopiesWithPrefix(p.collections[collectionFileExtensionTimestamp], tsPrefix, file.PinID, data); err != nil {
+		return err
+	}
+	if file.CreatorGlobalMetaId != "" {
+		prefix := file.CreatorGlobalMetaId + ":" + tsPrefix
+		if err := p.rewriteFileCopiesWithPrefix(p.collections[collectionGlobalMetaIDFileExtensionTimestamp], prefix, file.PinID, data); err != nil {
+			return err
 		}
 	}
-
-	sorted, nextCursor := paginateFilesByTimestampDesc(files, cursor, size)
-	return sorted, nextCursor, nil
+	return nil
 }
 
-func (p *PebbleDatabase) GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	metaIDDB := p.collections[collectionFileMetaID]
-	prefix := metaID + ":"
+// ReassignIndexerFileCreator moves file from the creator indexes of previous
+// (file_address, file_meta, file_meta_type_chain, file_global_meta and the
+// GlobalMetaID extension index) to the keys of its corrected creator, then
+// rewrites its other copies in place. A creator key is only moved while it
+// holds this PIN, so a newer version indexed under the old creator keeps its
+// keys. The MetaID / GlobalMetaID file counters follow the moved keys.
+func (p *PebbleDatabase) ReassignIndexerFileCreator(file, previous *model.IndexerFile) error {
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	firstPinID := file.FirstPinID
+	if firstPinID == "" {
+		firstPinID = file.PinID
+	}
+	creatorKey := func(creator string) string {
+		if creator == "" {
+			return ""
+		}
+		return creator + ":" + firstPinID
+	}
+	filterKey := func(f *model.IndexerFile) string {
+		if f.CreatorMetaId == "" {
+			return ""
+		}
+		return creatorFilterKey(f, firstPinID)
+	}
 
-	// Create iterator with prefix
-	// key format: meta_id:pin_id
-	iter, err := metaIDDB.NewIter(&pebble.IterOptions{
-		LowerBound: []byte(prefix),
-		UpperBound: []byte(prefix + "~"),
-	})
+	if _, _, err := p.moveFileCopy(p.collections[collectionFileAddress], creatorKey(previous.CreatorAddress), creatorKey(file.CreatorAddress), file, data); err != nil {
+		return err
+	}
+	if _, _, err := p.moveFileCopy(p.collections[collectionFileMetaIDTypeChain], filterKey(previous), filterKey(file), file, data); err != nil {
+		return err
+	}
+	var removed, added fileCountDelta
+	dropped, delta, err := p.moveFileCopy(p.collections[collectionFileMetaID], creatorKey(previous.CreatorMetaId), creatorKey(file.CreatorMetaId), file, data)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
-	defer iter.Close()
+	if dropped && previous.Status == model.StatusSuccess {
+		removed.metaID = -1
+	}
+	added.metaID = delta
+	dropped, delta, err = p.moveFileCopy(p.collections[collectionFileGlobalMetaID], creatorKey(previous.CreatorGlobalMetaId), creatorKey(file.CreatorGlobalMetaId), file, data)
+	if err != nil {
+		return err
+	}
+	if dropped && previous.Status == model.StatusSuccess {
+		removed.globalMetaID = -1
+	}
+	added.globalMetaID = delta
 
-	var files []*model.IndexerFile
-	for iter.First(); iter.Valid(); iter.Next() {
-		var file model.IndexerFile
-		if err := json.Unmarshal(iter.Value(), &file); err != nil {
-			continue
+	// Every version has its own GlobalMetaID extension key (random suffix)
+	extDB := p.collections[collectionGlobalMetaIDFileExtensionTimestamp]
+	extNorm := normalizeFileExtension(file.FileExtension)
+	if previous.CreatorGlobalMetaId != "" {
+		prefix := previous.CreatorGlobalMetaId + ":" + extNorm + ":" + fmt.Sprintf("%010d", file.Timestamp)
+		if err := p.deleteFileCopiesWithPrefix(extDB, prefix, file.PinID); err != nil {
+			return err
 		}
-
-		if file.Status == model.StatusSuccess {
-			fileCopy := file
-			files = append(files, &fileCopy)
+	}
+	if file.CreatorGlobalMetaId != "" {
+		key := file.CreatorGlobalMetaId + ":" + extNorm + ":" + makeTimestamp16(file.Timestamp)
+		if err := extDB.Set([]byte(key), data, pebble.Sync); err != nil {
+			return err
 		}
 	}
 
-	sorted, nextCursor := paginateFilesByTimestampDesc(files, cursor, size)
-	return sorted, nextCursor, nil
+	if err := p.UpdateIndexerFileFields(file); err != nil {
+		return err
+	}
+	p.bufferFileCountDelta(previous, removed)
+	p.bufferFileCountDelta(file, added)
+	return nil
 }
 
-func (p *PebbleDatabase) GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	db := p.collections[collectionFileGlobalMetaID]
-	prefix := globalMetaID + ":"
-	iter, err := db.NewIter(&pebble.IterOptions{
-		LowerBound: []byte(prefix),
-		UpperBound: []byte(prefix + "~"),
-	})
+// moveFileCopy deletes oldKey when it holds file's record, then stores data
+// under newKey unless newKey holds a newer version. An empty key is skipped.
+// dropped reports whether oldKey was deleted, added how newKey changed the
+// number of successful records in db.
+func (p *PebbleDatabase) moveFileCopy(db *pebbleCollection, oldKey, newKey string, file *model.IndexerFile, data []byte) (dropped bool, added int64, err error) {
+	if oldKey != "" {
+		stored, err := storedFileCopy(db, []byte(oldKey))
+		if err != nil {
+			return false, 0, err
+		}
+		if stored == nil || stored.PinID != file.PinID {
+			// Not indexed here under this PIN: nothing to move
+			return false, 0, nil
+		}
+		if err := db.Delete([]byte(oldKey), pebble.Sync); err != nil {
+			return false, 0, err
+		}
+		dropped = true
+	}
+	if newKey == "" {
+		return dropped, 0, nil
+	}
+	stored, err := storedFileCopy(db, []byte(newKey))
 	if err != nil {
-		return nil, 0, err
+		return dropped, 0, err
 	}
-	defer iter.Close()
+	if stored != nil && stored.PinID != file.PinID && stored.Timestamp > file.Timestamp {
+		return dropped, 0, nil
+	}
+	if added, err = successDelta(db, []byte(newKey), file.Status); err != nil {
+		return dropped, 0, err
+	}
+	return dropped, added, db.Set([]byte(newKey), data, pebble.Sync)
+}
 
-	var files []*model.IndexerFile
-	for iter.First(); iter.Valid(); iter.Next() {
-		var file model.IndexerFile
-		if err := json.Unmarshal(iter.Value(), &file); err != nil {
-			continue
-		}
-		if file.Status == model.StatusSuccess {
-			fileCopy := file
-			files = append(files, &fileCopy)
-		}
+// storedFileCopy decodes the file record under key; nil when there is none
+func storedFileCopy(db *pebbleCollection, key []byte) (*model.IndexerFile, error) {
+	data, closer, err := db.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	var stored model.IndexerFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, nil
 	}
-	sorted, nextCursor := paginateFilesByTimestampDesc(files, cursor, size)
-	return sorted, nextCursor, nil
+	return &stored, nil
 }
 
-// iterateExtensionKeys 在给定范围内倒序迭代（从新到旧），收集最多 size 条；返回 nextCursor 为本页最后一条的 key（空表示没有更多）
-func (p *PebbleDatabase) iterateExtensionKeys(db *pebble.DB, lowerBound, upperBound []byte, size int, onlySuccess bool) ([]*model.IndexerFile, string, error) {
+// deleteFileCopiesWithPrefix deletes every key under prefix holding pinID's record
+func (p *PebbleDatabase) deleteFileCopiesWithPrefix(db *pebbleCollection, prefix, pinID string) error {
 	iter, err := db.NewIter(&pebble.IterOptions{
-		LowerBound: lowerBound,
-		UpperBound: upperBound,
+		LowerBound: []byte(prefix),
+		UpperBound: append([]byte(prefix), 0xFF),
+	})
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for iter.First(); iter.Valid(); iter.Next() {
+		var stored model.IndexerFile
+		if json.Unmarshal(iter.Value(), &stored) == nil && stored.PinID == pinID {
+			keys = append(keys, append([]byte(nil), iter.Key()...))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		iter.Close()
+		return err
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := db.Delete(key, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteFileCopy overwrites key with data if it currently holds pinID's record
+func (p *PebbleDatabase) rewriteFileCopy(db *pebbleCollection, key []byte, pinID string, data []byte) error {
+	existing, closer, err := db.Get(key)
+	if err == pebble.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var stored model.IndexerFile
+	jsonErr := json.Unmarshal(existing, &stored)
+	closer.Close()
+	if jsonErr != nil || stored.PinID != pinID {
+		return nil
+	}
+	return db.Set(key, data, pebble.Sync)
+}
+
+// writeFileToHashIndex stores file under its content sha256; files without a hash are skipped
+func (p *PebbleDatabase) writeFileToHashIndex(file *model.IndexerFile, data []byte) error {
+	if file.FileHash == "" {
+		return nil
+	}
+	return p.collections[collectionFileHash].Set([]byte(file.FileHash+":"+file.PinID), data, pebble.Sync)
+}
+
+// GetIndexerFilesByFileHash returns the successful file PINs whose content sha256 is fileHash
+func (p *PebbleDatabase) GetIndexerFilesByFileHash(fileHash string) ([]*model.IndexerFile, error) {
+	if fileHash == "" {
+		return nil, nil
+	}
+	prefix := []byte(fileHash + ":")
+	iter, err := p.collections[collectionFileHash].NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix, 0xFF),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var files []*model.IndexerFile
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+		if file.Status != model.StatusSuccess {
+			continue
+		}
+		files = append(files, &file)
+	}
+	return files, iter.Error()
+}
+
+// RebuildFileHashIndex rewrites file_hash from file_pin. Older versions keyed it
+// by MD5; those keys are dropped.
+func (p *PebbleDatabase) RebuildFileHashIndex() error {
+	db := p.collections[collectionFileHash]
+	clearIter, err := db.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	batch := db.NewBatch()
+	for clearIter.First(); clearIter.Valid(); clearIter.Next() {
+		if err := batch.Delete(append([]byte(nil), clearIter.Key()...), nil); err != nil {
+			clearIter.Close()
+			batch.Close()
+			return err
+		}
+	}
+	clearIter.Close()
+	if err := batch.Commit(pebble.Sync); err != nil {
+		batch.Close()
+		return err
+	}
+	batch.Close()
+
+	fileIter, err := p.collections[collectionFilePinID].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer fileIter.Close()
+	for fileIter.First(); fileIter.Valid(); fileIter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(fileIter.Value(), &file); err != nil {
+			continue
+		}
+		if err := p.writeFileToHashIndex(&file, fileIter.Value()); err != nil {
+			return err
+		}
+	}
+	return fileIter.Error()
+}
+
+// rewriteFileCopiesWithPrefix overwrites every key under prefix holding pinID's record
+func (p *PebbleDatabase) rewriteFileCopiesWithPrefix(db *pebbleCollection, prefix, pinID string, data []byte) error {
+	iter, err := db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(prefix),
+		UpperBound: append([]byte(prefix), 0xFF),
+	})
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for iter.First(); iter.Valid(); iter.Next() {
+		var stored model.IndexerFile
+		if json.Unmarshal(iter.Value(), &stored) == nil && stored.PinID == pinID {
+			keys = append(keys, append([]byte(nil), iter.Key()...))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		iter.Close()
+		return err
+	}
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := db.Set(key, data, pebble.Sync); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PebbleDatabase) ListIndexerFilesWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	filePinDB := p.collections[collectionFilePinID]
+
+	// Create iterator for PinID collection
+	iter, err := filePinDB.NewIter(nil)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer iter.Close()
+
+	var files []*model.IndexerFile
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+
+		if file.Status == model.StatusSuccess {
+			fileCopy := file
+			files = append(files, &fileCopy)
+		}
+	}
+
+	sorted, nextCursor, hasMore := paginateFilesByTimestampDesc(files, cursor, size)
+	return sorted, nextCursor, hasMore, nil
+}
+
+func (p *PebbleDatabase) GetIndexerFilesByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	addressDB := p.collections[collectionFileAddress]
+	prefix := address + ":"
+
+	// Create iterator with prefix
+	// key format: address:pin_id
+	iter, err := addressDB.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(prefix),
+		UpperBound: []byte(prefix + "~"),
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer iter.Close()
+
+	var files []*model.IndexerFile
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+
+		if file.Status == model.StatusSuccess {
+			fileCopy := file
+			files = append(files, &fileCopy)
+		}
+	}
+
+	sorted, nextCursor, hasMore := paginateFilesByTimestampDesc(files, cursor, size)
+	return sorted, nextCursor, hasMore, nil
+}
+
+func (p *PebbleDatabase) GetIndexerFilesByCreatorMetaIDWithCursor(metaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	// key format: meta_id:pin_id, or meta_id:file_type:chain_name:first_pin_id when
+	// filtering by file type (narrows the scan to that type / type+chain)
+	db := p.collections[collectionFileMetaID]
+	prefix := metaID + ":"
+	if filter.FileType != "" {
+		db = p.collections[collectionFileMetaIDTypeChain]
+		prefix += strings.ToLower(filter.FileType) + ":"
+		if filter.ChainName != "" {
+			prefix += strings.ToLower(filter.ChainName) + ":"
+		}
+	}
+
+	iter, err := db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(prefix),
+		UpperBound: []byte(prefix + "~"),
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer iter.Close()
+
+	var files []*model.IndexerFile
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+
+		if file.Status == model.StatusSuccess && filter.Matches(&file) {
+			fileCopy := file
+			files = append(files, &fileCopy)
+		}
+	}
+
+	sorted, nextCursor, hasMore := paginateFilesByTimestampDesc(files, cursor, size)
+	return sorted, nextCursor, hasMore, nil
+}
+
+func (p *PebbleDatabase) GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	db := p.collections[collectionFileGlobalMetaID]
+	prefix := globalMetaID + ":"
+	iter, err := db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(prefix),
+		UpperBound: []byte(prefix + "~"),
+	})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer iter.Close()
+
+	var files []*model.IndexerFile
+	for iter.First(); iter.Valid(); iter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(iter.Value(), &file); err != nil {
+			continue
+		}
+		if file.Status == model.StatusSuccess && filter.Matches(&file) {
+			fileCopy := file
+			files = append(files, &fileCopy)
+		}
+	}
+	sorted, nextCursor, hasMore := paginateFilesByTimestampDesc(files, cursor, size)
+	return sorted, nextCursor, hasMore, nil
+}
+
+// iterateExtensionKeys 在给定范围内倒序迭代（从新到旧），收集最多 size 条；返回 nextCursor 为本页最后一条的 key（空表示没有更多）
+func (p *PebbleDatabase) iterateExtensionKeys(db *pebbleCollection, lowerBound, upperBound []byte, size int, onlySuccess bool) ([]*model.IndexerFile, string, error) {
+	iter, err := db.NewIter(&pebble.IterOptions{
+		LowerBound: lowerBound,
+		UpperBound: upperBound,
 	})
 	if err != nil {
 		return nil, "", err
@@ -757,72 +1339,477 @@ func (p *PebbleDatabase) GetIndexerFilesByKeywordAndExtensionWithCursor(keyword
 	return out[:size], nextCursor, nil
 }
 
+// GetIndexerFilesCount returns the number of successful file PINs from the maintained counter
 func (p *PebbleDatabase) GetIndexerFilesCount() (int64, error) {
-	var count int64
+	return p.GetCounter(model.CounterFiles)
+}
 
-	filePinDB := p.collections[collectionFilePinID]
+// GetIndexerFilesCountByChain returns the number of successful files (by first PIN) of a chain
+func (p *PebbleDatabase) GetIndexerFilesCountByChain(chainName string) (int64, error) {
+	return p.GetCounter(model.ChainCounter(model.CounterFiles, chainName))
+}
 
-	// Iterate through all files and count
-	iter, err := filePinDB.NewIter(nil)
+// GetIndexerFilesCountByCreatorMetaID returns the number of successful files (by first PIN) of a creator MetaID
+func (p *PebbleDatabase) GetIndexerFilesCountByCreatorMetaID(metaID string) (int64, error) {
+	return p.GetCounter(model.CreatorMetaIDCounter(metaID))
+}
+
+// GetIndexerFilesCountByCreatorGlobalMetaID returns the number of successful files (by first PIN) of a creator GlobalMetaID
+func (p *PebbleDatabase) GetIndexerFilesCountByCreatorGlobalMetaID(globalMetaID string) (int64, error) {
+	return p.GetCounter(model.CreatorGlobalMetaIDCounter(globalMetaID))
+}
+
+// fileCountDelta changes to the successful-file counters caused by one file write
+type fileCountDelta struct {
+	total, chain, metaID, globalMetaID int64
+}
+
+// successDelta returns how writing a record with status under key changes the
+// number of successful records stored in db: +1, -1 or 0
+func successDelta(db *pebbleCollection, key []byte, status model.Status) (int64, error) {
+	var delta int64
+	if status == model.StatusSuccess {
+		delta = 1
+	}
+	data, closer, err := db.Get(key)
+	if err == pebble.ErrNotFound {
+		return delta, nil
+	}
 	if err != nil {
 		return 0, err
 	}
-	defer iter.Close()
-
-	for iter.First(); iter.Valid(); iter.Next() {
-		var file model.IndexerFile
-		if err := json.Unmarshal(iter.Value(), &file); err != nil {
-			continue
-		}
+	defer closer.Close()
 
-		// Only count successful files
-		if file.Status == model.StatusSuccess {
-			count++
-		}
+	var existing struct {
+		Status model.Status `json:"status"`
 	}
-
-	return count, nil
+	if err := json.Unmarshal(data, &existing); err == nil && existing.Status == model.StatusSuccess {
+		delta--
+	}
+	return delta, nil
 }
 
-func (p *PebbleDatabase) GetIndexerFilesCountByChain(chainName string) (int64, error) {
-	var count int64
+// bufferFileCountDelta buffers delta for the total, chain and creator file counters of file
+func (p *PebbleDatabase) bufferFileCountDelta(file *model.IndexerFile, delta fileCountDelta) {
+	p.counterDeltas.add(model.CounterFiles, delta.total)
+	if file.ChainName != "" {
+		p.counterDeltas.add(model.ChainCounter(model.CounterFiles, file.ChainName), delta.chain)
+	}
+	if file.CreatorMetaId != "" {
+		p.counterDeltas.add(model.CreatorMetaIDCounter(file.CreatorMetaId), delta.metaID)
+	}
+	if file.CreatorGlobalMetaId != "" {
+		p.counterDeltas.add(model.CreatorGlobalMetaIDCounter(file.CreatorGlobalMetaId), delta.globalMetaID)
+	}
+}
 
-	chainFileDB := p.collections[collectionChainFileInfo]
-	prefix := chainName + ":"
+// Counter operations
 
-	// Create iterator with prefix filter
-	iter, err := chainFileDB.NewIter(&pebble.IterOptions{
-		LowerBound: []byte(prefix),
-		UpperBound: []byte(prefix + "~"),
-	})
+func (p *PebbleDatabase) getStoredCounter(name string) (int64, error) {
+	data, closer, err := p.collections[collectionStatCounters].Get([]byte(name))
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
 	if err != nil {
 		return 0, err
 	}
-	defer iter.Close()
-
-	for iter.First(); iter.Valid(); iter.Next() {
-		var file model.IndexerFile
-		if err := json.Unmarshal(iter.Value(), &file); err != nil {
-			continue
-		}
+	defer closer.Close()
+	return strconv.ParseInt(string(data), 10, 64)
+}
 
-		// Only count successful files
-		if file.Status == model.StatusSuccess {
-			count++
-		}
+// GetCounter returns the stored value of a counter plus its unflushed delta
+func (p *PebbleDatabase) GetCounter(name string) (int64, error) {
+	stored, err := p.getStoredCounter(name)
+	if err != nil {
+		return 0, err
 	}
-
-	return count, nil
+	return max(stored+p.counterDeltas.get(name), 0), nil
 }
 
-// IndexerUserAvatar operations
-
-func (p *PebbleDatabase) CreateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error {
-	data, err := json.Marshal(avatar)
+// ListCounters returns all counters whose name starts with prefix, including unflushed deltas
+func (p *PebbleDatabase) ListCounters(prefix string) (map[string]int64, error) {
+	counters, err := p.storedCounters(prefix)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
+	for name, delta := range p.counterDeltas.snapshot() {
+		if strings.HasPrefix(name, prefix) {
+			counters[name] = max(counters[name]+delta, 0)
+		}
+	}
+	return counters, nil
+}
+
+func (p *PebbleDatabase) storedCounters(prefix string) (map[string]int64, error) {
+	opts := &pebble.IterOptions{}
+	if prefix != "" {
+		opts.LowerBound = []byte(prefix)
+		opts.UpperBound = append([]byte(prefix), 0xFF)
+	}
+	iter, err := p.collections[collectionStatCounters].NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	counters := make(map[string]int64)
+	for iter.First(); iter.Valid(); iter.Next() {
+		value, err := strconv.ParseInt(string(iter.Value()), 10, 64)
+		if err != nil {
+			continue
+		}
+		counters[string(iter.Key())] = value
+	}
+	return counters, nil
+}
+
+// FlushCounters persists the buffered counter deltas in a single batch
+func (p *PebbleDatabase) FlushCounters() error {
+	p.statCountersMu.Lock()
+	defer p.statCountersMu.Unlock()
+
+	deltas := p.counterDeltas.take()
+	if len(deltas) == 0 {
+		return nil
+	}
+	if err := p.applyCounterDeltas(deltas); err != nil {
+		p.counterDeltas.restore(deltas)
+		return err
+	}
+	return nil
+}
+
+func (p *PebbleDatabase) applyCounterDeltas(deltas map[string]int64) error {
+	batch := p.collections[collectionStatCounters].NewBatch()
+	defer batch.Close()
+	for name, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		value, err := p.getStoredCounter(name)
+		if err != nil {
+			return err
+		}
+		value = max(value+delta, 0)
+		if err := batch.Set([]byte(name), []byte(strconv.FormatInt(value, 10)), nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// ReconcileCounters recounts files (file_pin, chain_file_info, creator indexes),
+// chunks (file_chunk_pin) and users (meta_id_timestamp) and compares them with
+// the maintained counters. Buffered deltas are flushed first.
+func (p *PebbleDatabase) ReconcileCounters(fix bool) ([]*model.CounterDrift, error) {
+	if err := p.FlushCounters(); err != nil {
+		return nil, err
+	}
+
+	actual, err := p.recountCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	p.statCountersMu.Lock()
+	defer p.statCountersMu.Unlock()
+
+	stored, err := p.storedCounters("")
+	if err != nil {
+		return nil, err
+	}
+	drifts := counterDrifts(stored, actual)
+	if !fix || len(drifts) == 0 {
+		return drifts, nil
+	}
+
+	batch := p.collections[collectionStatCounters].NewBatch()
+	defer batch.Close()
+	for _, drift := range drifts {
+		if drift.Actual == 0 {
+			err = batch.Delete([]byte(drift.Name), nil)
+		} else {
+			err = batch.Set([]byte(drift.Name), []byte(strconv.FormatInt(drift.Actual, 10)), nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return drifts, batch.Commit(pebble.Sync)
+}
+
+// latestUserInfoCollections pairs each latest_* user info collection with
+// the history it is derived from; both are keyed alike
+var latestUserInfoCollections = []struct {
+	latest, history string
+	metaIDKeyed     bool // Keyed by MetaID/address (cached user info), not GlobalMetaID
+}{
+	{collectionLatestUserNameInfo, collectionUserNameInfoHistory, true},
+	{collectionLatestUserAvatarInfo, collectionUserAvatarInfoHistory, true},
+	{collectionLatestUserBioInfo, collectionUserBioInfoHistory, true},
+	{collectionLatestUserChatPublicKeyInfo, collectionUserChatPublicKeyHistory, true},
+	{collectionLatestUserNameInfoByGlobalMetaId, collectionUserNameInfoHistoryByGlobalMetaId, false},
+	{collectionLatestUserAvatarInfoByGlobalMetaId, collectionUserAvatarInfoHistoryByGlobalMetaId, false},
+	{collectionLatestUserBioInfoByGlobalMetaId, collectionUserBioInfoHistoryByGlobalMetaId, false},
+	{collectionLatestUserChatPublicKeyInfoByGlobalMetaId, collectionUserChatPublicKeyHistoryByGlobalMetaId, false},
+}
+
+// RepairLatestIndexes recomputes the latest_* collections from history
+// (latest_file_info from file_pin, user infos from their history
+// collections) and returns the entries that are not the latest version; with
+// fix set they are rewritten. Entries whose history is gone are reported and
+// kept.
+func (p *PebbleDatabase) RepairLatestIndexes(fix bool) ([]*model.LatestDrift, error) {
+	drifts, err := p.repairLatestFileInfo(fix)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range latestUserInfoCollections {
+		userDrifts, err := p.repairLatestUserInfo(c.latest, c.history, fix)
+		if err != nil {
+			return nil, err
+		}
+		for _, drift := range userDrifts {
+			if fix && c.metaIDKeyed && drift.Actual != "" {
+				go p.updateUserInfoCache(drift.Key)
+			}
+		}
+		drifts = append(drifts, userDrifts...)
+	}
+	sortLatestDrifts(drifts)
+	return drifts, nil
+}
+
+// repairLatestFileInfo checks latest_file_info against the versions of each
+// file in file_pin
+func (p *PebbleDatabase) repairLatestFileInfo(fix bool) ([]*model.LatestDrift, error) {
+	// Latest version of every file, by FirstPinID
+	actual := make(map[string]latestOrder)
+	err := p.IterateIndexerFiles(func(file *model.IndexerFile) error {
+		if file.FirstPinID == "" {
+			return nil
+		}
+		version := latestOrderOf(file.Timestamp, file.BlockHeight, file.PinID)
+		if best, ok := actual[file.FirstPinID]; !ok || laterPIN(version, best) {
+			actual[file.FirstPinID] = version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []*model.LatestDrift
+	err = p.IterateLatestFileInfo(func(stored *model.IndexerFile) error {
+		best, ok := actual[stored.FirstPinID]
+		delete(actual, stored.FirstPinID)
+		if !ok {
+			drifts = append(drifts, &model.LatestDrift{Collection: collectionLatestFileInfo, Key: stored.FirstPinID, Stored: stored.PinID})
+		} else if stored.PinID != best.PinID {
+			drifts = append(drifts, &model.LatestDrift{Collection: collectionLatestFileInfo, Key: stored.FirstPinID, Stored: stored.PinID, Actual: best.PinID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for firstPinID, best := range actual {
+		drifts = append(drifts, &model.LatestDrift{Collection: collectionLatestFileInfo, Key: firstPinID, Actual: best.PinID})
+	}
+	if !fix {
+		return drifts, nil
+	}
+
+	latestDB := p.collections[collectionLatestFileInfo]
+	for _, drift := range drifts {
+		if drift.Actual == "" {
+			continue
+		}
+		data, closer, err := p.collections[collectionFilePinID].Get([]byte(drift.Actual))
+		if err != nil {
+			if err == pebble.ErrNotFound {
+				continue // Removed since the scan
+			}
+			return nil, err
+		}
+		err = latestDB.Set([]byte(drift.Key), data, pebble.Sync)
+		closer.Close()
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Repaired latest_file_info %s: %s -> %s", drift.Key, drift.Stored, drift.Actual)
+	}
+	return drifts, nil
+}
+
+// repairLatestUserInfo checks one latest_* user info collection against its
+// history collection
+func (p *PebbleDatabase) repairLatestUserInfo(latest, history string, fix bool) ([]*model.LatestDrift, error) {
+	// Latest history entry of every key
+	type version struct {
+		order latestOrder
+		data  []byte
+	}
+	actual := make(map[string]version)
+	iter, err := p.collections[history].NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	for iter.First(); iter.Valid(); iter.Next() {
+		var entries []json.RawMessage
+		if err := json.Unmarshal(iter.Value(), &entries); err != nil {
+			continue
+		}
+		var best *version
+		for _, entry := range entries {
+			var o userInfoOrder
+			if err := json.Unmarshal(entry, &o); err != nil {
+				continue
+			}
+			if best == nil || laterPIN(o.order(), best.order) {
+				best = &version{order: o.order(), data: entry}
+			}
+		}
+		if best != nil {
+			actual[string(iter.Key())] = version{order: best.order, data: append([]byte(nil), best.data...)}
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	var drifts []*model.LatestDrift
+	seen := make(map[string]bool, len(actual))
+	iter, err = p.collections[latest].NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := string(iter.Key())
+		var stored userInfoOrder
+		if err := json.Unmarshal(iter.Value(), &stored); err != nil {
+			continue
+		}
+		seen[key] = true
+		best, ok := actual[key]
+		if !ok {
+			drifts = append(drifts, &model.LatestDrift{Collection: latest, Key: key, Stored: stored.PinID})
+		} else if stored.PinID != best.order.PinID {
+			drifts = append(drifts, &model.LatestDrift{Collection: latest, Key: key, Stored: stored.PinID, Actual: best.order.PinID})
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	for key, best := range actual {
+		if !seen[key] {
+			drifts = append(drifts, &model.LatestDrift{Collection: latest, Key: key, Actual: best.order.PinID})
+		}
+	}
+	if !fix {
+		return drifts, nil
+	}
+
+	for _, drift := range drifts {
+		if drift.Actual == "" {
+			continue
+		}
+		if err := p.collections[latest].Set([]byte(drift.Key), actual[drift.Key].data, pebble.Sync); err != nil {
+			return nil, err
+		}
+		log.Printf("Repaired %s %s: %s -> %s", latest, drift.Key, drift.Stored, drift.Actual)
+	}
+	return drifts, nil
+}
+
+// recountCounters computes every counter by scanning the indexes
+func (p *PebbleDatabase) recountCounters() (map[string]int64, error) {
+	counts := make(map[string]int64)
+	countSuccess := func(collection string, namesFor func(key []byte, value []byte) []string) error {
+		iter, err := p.collections[collection].NewIter(nil)
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+		for iter.First(); iter.Valid(); iter.Next() {
+			var record struct {
+				Status model.Status `json:"status"`
+			}
+			if err := json.Unmarshal(iter.Value(), &record); err != nil || record.Status != model.StatusSuccess {
+				continue
+			}
+			for _, name := range namesFor(iter.Key(), iter.Value()) {
+				counts[name]++
+			}
+		}
+		return nil
+	}
+	// Chain and creator index keys are {chain_name|creator}:{first_pin_id}
+	byKeyPrefix := func(counterName func(string) string) func([]byte, []byte) []string {
+		return func(key, _ []byte) []string {
+			prefix, _, _ := strings.Cut(string(key), ":")
+			if prefix == "" {
+				return nil
+			}
+			return []string{counterName(prefix)}
+		}
+	}
+	withChain := func(name string) func([]byte, []byte) []string {
+		return func(_, value []byte) []string {
+			var record struct {
+				ChainName string `json:"chain_name"`
+			}
+			names := []string{name}
+			if json.Unmarshal(value, &record) == nil && record.ChainName != "" {
+				names = append(names, model.ChainCounter(name, record.ChainName))
+			}
+			return names
+		}
+	}
+
+	if err := countSuccess(collectionFilePinID, func([]byte, []byte) []string { return []string{model.CounterFiles} }); err != nil {
+		return nil, err
+	}
+	if err := countSuccess(collectionChainFileInfo, byKeyPrefix(func(chain string) string { return model.ChainCounter(model.CounterFiles, chain) })); err != nil {
+		return nil, err
+	}
+	if err := countSuccess(collectionFileMetaID, byKeyPrefix(model.CreatorMetaIDCounter)); err != nil {
+		return nil, err
+	}
+	if err := countSuccess(collectionFileGlobalMetaID, byKeyPrefix(model.CreatorGlobalMetaIDCounter)); err != nil {
+		return nil, err
+	}
+	if err := countSuccess(collectionFileChunkPinID, withChain(model.CounterChunks)); err != nil {
+		return nil, err
+	}
+
+	// Users: one meta_id_timestamp entry per MetaID (no status)
+	iter, err := p.collections[collectionMetaIdTimestamp].NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		var entry model.MetaIdTimestamp
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			continue
+		}
+		counts[model.CounterUsers]++
+		if entry.ChainName != "" {
+			counts[model.ChainCounter(model.CounterUsers, entry.ChainName)]++
+		}
+	}
+	return counts, nil
+}
+
+// IndexerUserAvatar operations
+
+func (p *PebbleDatabase) CreateIndexerUserAvatar(avatar *model.IndexerUserAvatar) error {
+	data, err := json.Marshal(avatar)
+	if err != nil {
+		return err
+	}
+
 	blockHeightKey := strconv.FormatInt(avatar.BlockHeight, 10)
 	timestampKey := strconv.FormatInt(avatar.Timestamp, 10)
 
@@ -1045,10 +2032,16 @@ func (p *PebbleDatabase) CreateIndexerFileChunk(chunk *model.IndexerFileChunk) e
 		return err
 	}
 
+	delta, err := successDelta(p.collections[collectionFileChunkPinID], []byte(chunk.PinID), chunk.Status)
+	if err != nil {
+		return err
+	}
+
 	// Store in PinID collection (primary index)
 	if err := p.collections[collectionFileChunkPinID].Set([]byte(chunk.PinID), data, pebble.Sync); err != nil {
 		return err
 	}
+	p.counterDeltas.addChain(model.CounterChunks, chunk.ChainName, delta)
 
 	// Store in ParentPinID collection if parent is set
 	if chunk.ParentPinID != "" {
@@ -1058,6 +2051,15 @@ func (p *PebbleDatabase) CreateIndexerFileChunk(chunk *model.IndexerFileChunk) e
 		}
 	}
 
+	// Store in Sha256 collection so retried (re-inscribed) chunks can be
+	// resolved by content hash
+	if chunk.ChunkSha256 != "" {
+		hashKey := chunk.ChunkSha256 + ":" + chunk.PinID
+		if err := p.collections[collectionFileChunkSha256].Set([]byte(hashKey), data, pebble.Sync); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -1110,6 +2112,32 @@ func (p *PebbleDatabase) GetIndexerFileChunksByParentPinID(parentPinID string) (
 	return chunks, nil
 }
 
+func (p *PebbleDatabase) ListIndexerFileChunksBySha256(sha256 string) ([]*model.IndexerFileChunk, error) {
+	prefix := []byte(sha256 + ":")
+	iter, err := p.collections[collectionFileChunkSha256].NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: append(prefix, 0xFF),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	// Only successful chunks can stand in for a referenced chunk
+	var chunks []*model.IndexerFileChunk
+	for iter.First(); iter.Valid(); iter.Next() {
+		var chunk model.IndexerFileChunk
+		if err := json.Unmarshal(iter.Value(), &chunk); err != nil {
+			continue
+		}
+		if chunk.Status != model.StatusSuccess {
+			continue
+		}
+		chunks = append(chunks, &chunk)
+	}
+	return chunks, nil
+}
+
 func (p *PebbleDatabase) UpdateIndexerFileChunk(chunk *model.IndexerFileChunk) error {
 	// Simply recreate (overwrite)
 	return p.CreateIndexerFileChunk(chunk)
@@ -1221,8 +2249,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserNameInfo(info *model.UserNameIn
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -1297,7 +2325,7 @@ func (p *PebbleDatabase) AddUserNameInfoHistory(info *model.UserNameInfo, metaID
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -1357,8 +2385,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserNameInfoByGlobalMetaId(info *mo
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -1430,7 +2458,7 @@ func (p *PebbleDatabase) AddUserNameInfoHistoryByGlobalMetaId(info *model.UserNa
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -1490,8 +2518,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserAvatarInfo(info *model.UserAvat
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -1654,7 +2682,7 @@ func (p *PebbleDatabase) AddUserAvatarInfoHistory(info *model.UserAvatarInfo, me
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -1714,8 +2742,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserAvatarInfoByGlobalMetaId(info *
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -1787,7 +2815,7 @@ func (p *PebbleDatabase) AddUserAvatarInfoHistoryByGlobalMetaId(info *model.User
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -1847,8 +2875,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserBioInfo(info *model.UserBioInfo
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -1923,7 +2951,7 @@ func (p *PebbleDatabase) AddUserBioInfoHistory(info *model.UserBioInfo, metaID s
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -1983,8 +3011,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserBioInfoByGlobalMetaId(info *mod
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -2056,7 +3084,7 @@ func (p *PebbleDatabase) AddUserBioInfoHistoryByGlobalMetaId(info *model.UserBio
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -2116,8 +3144,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserChatPublicKeyInfo(info *model.U
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -2192,7 +3220,7 @@ func (p *PebbleDatabase) AddUserChatPublicKeyHistory(info *model.UserChatPublicK
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -2252,8 +3280,8 @@ func (p *PebbleDatabase) CreateOrUpdateLatestUserChatPublicKeyInfoByGlobalMetaId
 			return err
 		}
 
-		// Update if new info has a later timestamp
-		if info.Timestamp > existingInfo.Timestamp {
+		// Update if new info is the later version (see laterPIN)
+		if laterPIN(latestOrderOf(info.Timestamp, info.BlockHeight, info.PinID), latestOrderOf(existingInfo.Timestamp, existingInfo.BlockHeight, existingInfo.PinID)) {
 			shouldUpdate = true
 		}
 	}
@@ -2325,7 +3353,7 @@ func (p *PebbleDatabase) AddUserChatPublicKeyHistoryByGlobalMetaId(info *model.U
 
 	// Sort by timestamp desc
 	sort.Slice(history, func(i, j int) bool {
-		return history[i].Timestamp > history[j].Timestamp
+		return laterPIN(latestOrderOf(history[i].Timestamp, history[i].BlockHeight, history[i].PinID), latestOrderOf(history[j].Timestamp, history[j].BlockHeight, history[j].PinID))
 	})
 
 	// Save history
@@ -2471,7 +3499,7 @@ func (p *PebbleDatabase) AddFileInfoHistory(history *model.FileInfoHistory, firs
 
 	// Sort by timestamp desc
 	sort.Slice(historyList, func(i, j int) bool {
-		return historyList[i].Timestamp > historyList[j].Timestamp
+		return laterPIN(latestOrderOf(historyList[i].Timestamp, historyList[i].BlockHeight, historyList[i].PinID), latestOrderOf(historyList[j].Timestamp, historyList[j].BlockHeight, historyList[j].PinID))
 	})
 
 	// Save history
@@ -2479,182 +3507,672 @@ func (p *PebbleDatabase) AddFileInfoHistory(history *model.FileInfoHistory, firs
 	if err != nil {
 		return err
 	}
-
-	return db.Set([]byte(key), data, pebble.Sync)
+
+	return db.Set([]byte(key), data, pebble.Sync)
+}
+
+// GetFileInfoHistory get file info history by first PIN ID
+func (p *PebbleDatabase) GetFileInfoHistory(firstPinID string) ([]model.FileInfoHistory, error) {
+	db := p.collections[collectionFileInfoHistory]
+
+	// key: first_pin_id
+	key := firstPinID
+
+	data, closer, err := db.Get([]byte(key))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+
+	var historyList []model.FileInfoHistory
+	if err := json.Unmarshal(data, &historyList); err != nil {
+		return nil, err
+	}
+
+	return historyList, nil
+}
+
+// MetaIdTimestamp operations
+
+// SaveMetaIdTimestamp save MetaID with timestamp (only keeps earliest timestamp per MetaID)
+func (p *PebbleDatabase) SaveMetaIdTimestamp(metaID, chainName string, timestamp int64) error {
+	if metaID == "" || timestamp <= 0 {
+		return fmt.Errorf("metaID and timestamp must be valid")
+	}
+
+	db := p.collections[collectionMetaIdTimestamp]
+
+	// Check if MetaID already has a timestamp recorded
+	// We need to scan for existing entries with this MetaID
+	prefix := []byte(fmt.Sprintf("%d:", 0))
+	upperBound := []byte(fmt.Sprintf("%d:", 9999999999999)) // Max timestamp
+
+	iter, err := db.NewIter(&pebble.IterOptions{
+		LowerBound: prefix,
+		UpperBound: upperBound,
+	})
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+
+	// Look for existing entry with this MetaID
+	var existingTimestamp int64 = 0
+	var existingChainName string
+	var existingKey []byte
+	for iter.First(); iter.Valid(); iter.Next() {
+		var entry model.MetaIdTimestamp
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			continue
+		}
+		if entry.MetaId == metaID {
+			existingTimestamp = entry.Timestamp
+			existingChainName = entry.ChainName
+			existingKey = append([]byte(nil), iter.Key()...) // Copy key
+			break
+		}
+	}
+
+	// If MetaID already exists with an earlier timestamp, skip
+	if existingTimestamp > 0 && existingTimestamp <= timestamp {
+		log.Printf("MetaID %s already has earlier or same timestamp: %d (new: %d), skipping", metaID, existingTimestamp, timestamp)
+		return nil
+	}
+
+	// If we have an existing entry with a later timestamp, delete it
+	if existingTimestamp > 0 && existingTimestamp > timestamp {
+		if err := db.Delete(existingKey, pebble.Sync); err != nil {
+			log.Printf("Failed to delete old MetaID timestamp entry: %v", err)
+		}
+		log.Printf("Deleted old MetaID timestamp entry: MetaID=%s, OldTimestamp=%d", metaID, existingTimestamp)
+
+		// Still one user, counted on the chain of the earlier entry
+		p.counterDeltas.addChain(model.CounterUsers, existingChainName, -1)
+
+		// The user now counts on the earlier day instead
+		if err := p.IncrDailyStat(&model.IndexerDailyStat{
+			Date:      model.DailyStatDate(existingTimestamp),
+			ChainName: existingChainName,
+			NewUsers:  -1,
+		}); err != nil {
+			log.Printf("Failed to update daily user stats: %v", err)
+		}
+	}
+
+	// Save new entry
+	entry := &model.MetaIdTimestamp{
+		MetaId:    metaID,
+		ChainName: chainName,
+		Timestamp: timestamp,
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// key: timestamp:meta_id
+	key := fmt.Sprintf("%d:%s", timestamp, metaID)
+	if err := db.Set([]byte(key), data, pebble.Sync); err != nil {
+		return err
+	}
+
+	p.counterDeltas.addChain(model.CounterUsers, chainName, 1)
+
+	if err := p.IncrDailyStat(&model.IndexerDailyStat{
+		Date:      model.DailyStatDate(timestamp),
+		ChainName: chainName,
+		NewUsers:  1,
+	}); err != nil {
+		log.Printf("Failed to update daily user stats: %v", err)
+	}
+
+	log.Printf("MetaID timestamp saved: MetaID=%s, Timestamp=%d", metaID, timestamp)
+	return nil
+}
+
+// ListMetaIdsByTimestamp list MetaIDs ordered by timestamp (descending) with cursor pagination
+// cursor: last timestamp (0 for first page)
+// size: page size
+// Returns: list of MetaIdTimestamp, nextCursor (timestamp), hasMore, error
+func (p *PebbleDatabase) ListMetaIdsByTimestamp(cursor int64, size int) ([]model.MetaIdTimestamp, int64, bool, error) {
+	if size <= 0 {
+		size = 20
+	}
+
+	db := p.collections[collectionMetaIdTimestamp]
+
+	// Create iterator
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	defer iter.Close()
+
+	var results []model.MetaIdTimestamp
+	count := 0
+
+	// If cursor is provided, seek to cursor position
+	if cursor > 0 {
+		// Seek to the cursor position (timestamp)
+		seekKey := []byte(fmt.Sprintf("%d:", cursor))
+		iter.SeekLT(seekKey) // Seek to less than cursor (for descending order)
+	} else {
+		// Start from the last (highest timestamp)
+		iter.Last()
+	}
+
+	// Iterate in reverse order (descending timestamp)
+	for iter.Valid() && count < size {
+		var entry model.MetaIdTimestamp
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			iter.Prev()
+			continue
+		}
+
+		results = append(results, entry)
+		count++
+		iter.Prev()
+	}
+
+	// Determine next cursor and hasMore
+	var nextCursor int64 = 0
+	hasMore := false
+
+	if len(results) > 0 {
+		// Next cursor is the timestamp of the last result
+		nextCursor = results[len(results)-1].Timestamp
+		// Check if there are more records
+		hasMore = iter.Valid()
+	}
+
+	return results, nextCursor, hasMore, nil
+}
+
+// GetMetaIDCount get total count of unique MetaIDs (users) from the maintained counter
+func (p *PebbleDatabase) GetMetaIDCount() (int64, error) {
+	return p.GetCounter(model.CounterUsers)
+}
+
+// DailyStat operations
+
+// dailyStatKey builds the daily_stats key: {date}:{chain_name}
+func dailyStatKey(date, chainName string) []byte {
+	return []byte(date + ":" + chainName)
+}
+
+// IncrDailyStat adds the counters in delta to the {date}:{chain_name} bucket
+func (p *PebbleDatabase) IncrDailyStat(delta *model.IndexerDailyStat) error {
+	if delta == nil || delta.Date == "" {
+		return fmt.Errorf("daily stat date cannot be empty")
+	}
+
+	p.dailyStatsMu.Lock()
+	defer p.dailyStatsMu.Unlock()
+
+	db := p.collections[collectionDailyStats]
+	key := dailyStatKey(delta.Date, delta.ChainName)
+
+	stat := model.IndexerDailyStat{Date: delta.Date, ChainName: delta.ChainName}
+	data, closer, err := db.Get(key)
+	if err == nil {
+		unmarshalErr := json.Unmarshal(data, &stat)
+		closer.Close()
+		if unmarshalErr != nil {
+			return unmarshalErr
+		}
+	} else if err != pebble.ErrNotFound {
+		return err
+	}
+
+	stat.NewFiles += delta.NewFiles
+	stat.NewUsers += delta.NewUsers
+	stat.Bytes += delta.Bytes
+	stat.UpdatedAt = time.Now()
+
+	value, err := json.Marshal(&stat)
+	if err != nil {
+		return err
+	}
+	return db.Set(key, value, pebble.Sync)
+}
+
+// ListDailyStats list per-chain daily stats with fromDate <= date <= toDate (YYYY-MM-DD), ordered by date
+func (p *PebbleDatabase) ListDailyStats(fromDate, toDate string) ([]*model.IndexerDailyStat, error) {
+	db := p.collections[collectionDailyStats]
+
+	// ';' sorts right after ':', so the upper bound covers every chain of toDate
+	iter, err := db.NewIter(&pebble.IterOptions{
+		LowerBound: []byte(fromDate + ":"),
+		UpperBound: []byte(toDate + ";"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var stats []*model.IndexerDailyStat
+	for iter.First(); iter.Valid(); iter.Next() {
+		var stat model.IndexerDailyStat
+		if err := json.Unmarshal(iter.Value(), &stat); err != nil {
+			continue
+		}
+		stats = append(stats, &stat)
+	}
+	return stats, nil
+}
+
+// RebuildDailyStats recomputes daily_stats from file_pin (successful first PINs) and meta_id_timestamp
+func (p *PebbleDatabase) RebuildDailyStats() error {
+	totals := make(map[string]*model.IndexerDailyStat)
+	bucket := func(timestamp int64, chainName string) *model.IndexerDailyStat {
+		date := model.DailyStatDate(timestamp)
+		key := date + ":" + chainName
+		stat, ok := totals[key]
+		if !ok {
+			stat = &model.IndexerDailyStat{Date: date, ChainName: chainName}
+			totals[key] = stat
+		}
+		return stat
+	}
+
+	fileIter, err := p.collections[collectionFilePinID].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	for fileIter.First(); fileIter.Valid(); fileIter.Next() {
+		var file model.IndexerFile
+		if err := json.Unmarshal(fileIter.Value(), &file); err != nil {
+			continue
+		}
+		if file.Status != model.StatusSuccess || file.Timestamp <= 0 || !isNewFilePin(&file) {
+			continue
+		}
+		stat := bucket(file.Timestamp, file.ChainName)
+		stat.NewFiles++
+		stat.Bytes += file.FileSize
+	}
+	fileIter.Close()
+
+	userIter, err := p.collections[collectionMetaIdTimestamp].NewIter(nil)
+	if err != nil {
+		return err
+	}
+	for userIter.First(); userIter.Valid(); userIter.Next() {
+		var entry model.MetaIdTimestamp
+		if err := json.Unmarshal(userIter.Value(), &entry); err != nil {
+			continue
+		}
+		if entry.Timestamp <= 0 {
+			continue
+		}
+		bucket(entry.Timestamp, entry.ChainName).NewUsers++
+	}
+	userIter.Close()
+
+	p.dailyStatsMu.Lock()
+	defer p.dailyStatsMu.Unlock()
+
+	db := p.collections[collectionDailyStats]
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	// Drop existing buckets before writing the recomputed ones
+	clearIter, err := db.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	for clearIter.First(); clearIter.Valid(); clearIter.Next() {
+		if err := batch.Delete(append([]byte(nil), clearIter.Key()...), nil); err != nil {
+			clearIter.Close()
+			return err
+		}
+	}
+	clearIter.Close()
+
+	now := time.Now()
+	for _, stat := range totals {
+		stat.UpdatedAt = now
+		value, err := json.Marshal(stat)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(dailyStatKey(stat.Date, stat.ChainName), value, nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// Watchlist operations
+
+// watchKey builds the watchlist key: zero-padded ID so keys iterate in ID order
+func watchKey(id int64) []byte {
+	return []byte(fmt.Sprintf("%020d", id))
+}
+
+// watchEventKey builds the watch_events key: {target}:{id:020d}
+func watchEventKey(target string, id int64) []byte {
+	return []byte(fmt.Sprintf("%s:%020d", target, id))
+}
+
+// Outbox entries share the watch_events collection so they are written in the
+// same batch as their event; targets cannot contain ';'
+const outboxKeyPrefix = ";outbox:"
+
+// outboxKey builds the outbox key: ;outbox:{id:020d}
+func outboxKey(id int64) []byte {
+	return []byte(fmt.Sprintf("%s%020d", outboxKeyPrefix, id))
+}
+
+// CreateWatch saves a watchlist entry, assigning its ID
+func (p *PebbleDatabase) CreateWatch(watch *model.IndexerWatch) error {
+	if watch.Target == "" {
+		return fmt.Errorf("watch target cannot be empty")
+	}
+	if watch.ID == 0 {
+		watch.ID = p.watchIDCounter.Add(1)
+		p.collections[collectionCounters].Set(
+			[]byte(keyWatchCounter),
+			[]byte(strconv.FormatInt(watch.ID, 10)),
+			pebble.Sync,
+		)
+	}
+	if watch.CreatedAt.IsZero() {
+		watch.CreatedAt = time.Now()
+	}
+
+	data, err := json.Marshal(watch)
+	if err != nil {
+		return err
+	}
+	return p.collections[collectionWatchlist].Set(watchKey(watch.ID), data, pebble.Sync)
 }
 
-// GetFileInfoHistory get file info history by first PIN ID
-func (p *PebbleDatabase) GetFileInfoHistory(firstPinID string) ([]model.FileInfoHistory, error) {
-	db := p.collections[collectionFileInfoHistory]
-
-	// key: first_pin_id
-	key := firstPinID
-
-	data, closer, err := db.Get([]byte(key))
+// DeleteWatch removes a watchlist entry; recorded events are kept
+func (p *PebbleDatabase) DeleteWatch(id int64) error {
+	db := p.collections[collectionWatchlist]
+	key := watchKey(id)
+	_, closer, err := db.Get(key)
+	if err == pebble.ErrNotFound {
+		return ErrNotFound
+	}
 	if err != nil {
-		if err == pebble.ErrNotFound {
-			return nil, ErrNotFound
-		}
-		return nil, err
+		return err
 	}
-	defer closer.Close()
+	closer.Close()
+	return db.Delete(key, pebble.Sync)
+}
 
-	var historyList []model.FileInfoHistory
-	if err := json.Unmarshal(data, &historyList); err != nil {
+// ListWatches lists all watchlist entries ordered by ID
+func (p *PebbleDatabase) ListWatches() ([]*model.IndexerWatch, error) {
+	iter, err := p.collections[collectionWatchlist].NewIter(nil)
+	if err != nil {
 		return nil, err
 	}
+	defer iter.Close()
 
-	return historyList, nil
+	var watches []*model.IndexerWatch
+	for iter.First(); iter.Valid(); iter.Next() {
+		var watch model.IndexerWatch
+		if err := json.Unmarshal(iter.Value(), &watch); err != nil {
+			continue
+		}
+		watches = append(watches, &watch)
+	}
+	return watches, nil
 }
 
-// MetaIdTimestamp operations
-
-// SaveMetaIdTimestamp save MetaID with timestamp (only keeps earliest timestamp per MetaID)
-func (p *PebbleDatabase) SaveMetaIdTimestamp(metaID string, timestamp int64) error {
-	if metaID == "" || timestamp <= 0 {
-		return fmt.Errorf("metaID and timestamp must be valid")
+// AddWatchEvent saves an event under its target, assigning an increasing ID,
+// and its outbox entries in the same batch
+func (p *PebbleDatabase) AddWatchEvent(event *model.IndexerWatchEvent, outbox ...*model.IndexerOutboxEvent) error {
+	if event.Target == "" {
+		return fmt.Errorf("watch event target cannot be empty")
+	}
+	if event.ID == 0 {
+		event.ID = p.eventIDCounter.Add(1)
+		p.collections[collectionCounters].Set(
+			[]byte(keyEventCounter),
+			[]byte(strconv.FormatInt(event.ID, 10)),
+			pebble.Sync,
+		)
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
 	}
 
-	db := p.collections[collectionMetaIdTimestamp]
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(outbox) == 0 {
+		return p.collections[collectionWatchEvents].Set(watchEventKey(event.Target, event.ID), data, pebble.Sync)
+	}
 
-	// Check if MetaID already has a timestamp recorded
-	// We need to scan for existing entries with this MetaID
-	prefix := []byte(fmt.Sprintf("%d:", 0))
-	upperBound := []byte(fmt.Sprintf("%d:", 9999999999999)) // Max timestamp
+	batch := p.collections[collectionWatchEvents].NewBatch()
+	defer batch.Close()
+	if err := batch.Set(watchEventKey(event.Target, event.ID), data, nil); err != nil {
+		return err
+	}
+	for _, entry := range outbox {
+		entry.ID = p.outboxIDCounter.Add(1)
+		entry.Event = data
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = event.CreatedAt
+		}
+		entryData, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(outboxKey(entry.ID), entryData, nil); err != nil {
+			return err
+		}
+	}
+	// The counter only needs to stay ahead of the IDs written
+	p.collections[collectionCounters].Set(
+		[]byte(keyOutboxCounter),
+		[]byte(strconv.FormatInt(p.outboxIDCounter.Load(), 10)),
+		pebble.Sync,
+	)
+	return batch.Commit(pebble.Sync)
+}
 
-	iter, err := db.NewIter(&pebble.IterOptions{
-		LowerBound: prefix,
-		UpperBound: upperBound,
+// ListWatchEvents lists events of target with ID > afterID in ascending ID order
+func (p *PebbleDatabase) ListWatchEvents(target string, afterID int64, size int) ([]*model.IndexerWatchEvent, error) {
+	// ';' sorts right after ':', so the upper bound covers every event of target
+	iter, err := p.collections[collectionWatchEvents].NewIter(&pebble.IterOptions{
+		LowerBound: watchEventKey(target, afterID+1),
+		UpperBound: []byte(target + ";"),
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer iter.Close()
 
-	// Look for existing entry with this MetaID
-	var existingTimestamp int64 = 0
-	var existingKey []byte
-	for iter.First(); iter.Valid(); iter.Next() {
-		var entry model.MetaIdTimestamp
-		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+	var events []*model.IndexerWatchEvent
+	for iter.First(); iter.Valid() && len(events) < size; iter.Next() {
+		var event model.IndexerWatchEvent
+		if err := json.Unmarshal(iter.Value(), &event); err != nil {
 			continue
 		}
-		if entry.MetaId == metaID {
-			existingTimestamp = entry.Timestamp
-			existingKey = append([]byte(nil), iter.Key()...) // Copy key
-			break
-		}
+		events = append(events, &event)
 	}
+	return events, nil
+}
 
-	// If MetaID already exists with an earlier timestamp, skip
-	if existingTimestamp > 0 && existingTimestamp <= timestamp {
-		log.Printf("MetaID %s already has earlier or same timestamp: %d (new: %d), skipping", metaID, existingTimestamp, timestamp)
-		return nil
+// ListOutboxEvents lists outbox entries with ID > afterID in ascending ID order
+func (p *PebbleDatabase) ListOutboxEvents(afterID int64, size int) ([]*model.IndexerOutboxEvent, error) {
+	iter, err := p.collections[collectionWatchEvents].NewIter(&pebble.IterOptions{
+		LowerBound: outboxKey(afterID + 1),
+		UpperBound: []byte(";outbox;"),
+	})
+	if err != nil {
+		return nil, err
 	}
+	defer iter.Close()
 
-	// If we have an existing entry with a later timestamp, delete it
-	if existingTimestamp > 0 && existingTimestamp > timestamp {
-		if err := db.Delete(existingKey, pebble.Sync); err != nil {
-			log.Printf("Failed to delete old MetaID timestamp entry: %v", err)
+	var entries []*model.IndexerOutboxEvent
+	for iter.First(); iter.Valid() && len(entries) < size; iter.Next() {
+		var entry model.IndexerOutboxEvent
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			continue
 		}
-		log.Printf("Deleted old MetaID timestamp entry: MetaID=%s, OldTimestamp=%d", metaID, existingTimestamp)
+		entries = append(entries, &entry)
 	}
+	return entries, nil
+}
 
-	// Save new entry
-	entry := &model.MetaIdTimestamp{
-		MetaId:    metaID,
-		Timestamp: timestamp,
+// UpdateOutboxEvent saves the delivery state of an outbox entry
+func (p *PebbleDatabase) UpdateOutboxEvent(entry *model.IndexerOutboxEvent) error {
+	if entry.ID == 0 {
+		return fmt.Errorf("outbox entry ID cannot be empty")
 	}
-
 	data, err := json.Marshal(entry)
 	if err != nil {
 		return err
 	}
+	return p.collections[collectionWatchEvents].Set(outboxKey(entry.ID), data, pebble.Sync)
+}
+
+// DeleteOutboxEvent removes a delivered outbox entry
+func (p *PebbleDatabase) DeleteOutboxEvent(id int64) error {
+	return p.collections[collectionWatchEvents].Delete(outboxKey(id), pebble.Sync)
+}
+
+// Custom domain operations
+
+// SaveDomain inserts or replaces the mapping of domain.Domain
+func (p *PebbleDatabase) SaveDomain(domain *model.IndexerDomain) error {
+	if domain.Domain == "" {
+		return fmt.Errorf("domain cannot be empty")
+	}
+	now := time.Now()
+	if existing, err := p.GetDomain(domain.Domain); err == nil {
+		domain.CreatedAt = existing.CreatedAt
+	} else if domain.CreatedAt.IsZero() {
+		domain.CreatedAt = now
+	}
+	domain.UpdatedAt = now
 
-	// key: timestamp:meta_id
-	key := fmt.Sprintf("%d:%s", timestamp, metaID)
-	if err := db.Set([]byte(key), data, pebble.Sync); err != nil {
+	data, err := json.Marshal(domain)
+	if err != nil {
 		return err
 	}
-
-	log.Printf("MetaID timestamp saved: MetaID=%s, Timestamp=%d", metaID, timestamp)
-	return nil
+	return p.collections[collectionDomains].Set([]byte(domain.Domain), data, pebble.Sync)
 }
 
-// ListMetaIdsByTimestamp list MetaIDs ordered by timestamp (descending) with cursor pagination
-// cursor: last timestamp (0 for first page)
-// size: page size
-// Returns: list of MetaIdTimestamp, nextCursor (timestamp), hasMore, error
-func (p *PebbleDatabase) ListMetaIdsByTimestamp(cursor int64, size int) ([]model.MetaIdTimestamp, int64, bool, error) {
-	if size <= 0 {
-		size = 20
+// GetDomain gets the mapping of domain; returns ErrNotFound if there is none
+func (p *PebbleDatabase) GetDomain(domain string) (*model.IndexerDomain, error) {
+	value, closer, err := p.collections[collectionDomains].Get([]byte(domain))
+	if err == pebble.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
+	defer closer.Close()
 
-	db := p.collections[collectionMetaIdTimestamp]
+	var mapping model.IndexerDomain
+	if err := json.Unmarshal(value, &mapping); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
 
-	// Create iterator
-	iter, err := db.NewIter(nil)
+// DeleteDomain removes the mapping of domain
+func (p *PebbleDatabase) DeleteDomain(domain string) error {
+	db := p.collections[collectionDomains]
+	key := []byte(domain)
+	_, closer, err := db.Get(key)
+	if err == pebble.ErrNotFound {
+		return ErrNotFound
+	}
 	if err != nil {
-		return nil, 0, false, err
+		return err
 	}
-	defer iter.Close()
-
-	var results []model.MetaIdTimestamp
-	count := 0
+	closer.Close()
+	return db.Delete(key, pebble.Sync)
+}
 
-	// If cursor is provided, seek to cursor position
-	if cursor > 0 {
-		// Seek to the cursor position (timestamp)
-		seekKey := []byte(fmt.Sprintf("%d:", cursor))
-		iter.SeekLT(seekKey) // Seek to less than cursor (for descending order)
-	} else {
-		// Start from the last (highest timestamp)
-		iter.Last()
+// ListDomains lists all domain mappings ordered by domain
+func (p *PebbleDatabase) ListDomains() ([]*model.IndexerDomain, error) {
+	iter, err := p.collections[collectionDomains].NewIter(nil)
+	if err != nil {
+		return nil, err
 	}
+	defer iter.Close()
 
-	// Iterate in reverse order (descending timestamp)
-	for iter.Valid() && count < size {
-		var entry model.MetaIdTimestamp
-		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
-			iter.Prev()
+	var domains []*model.IndexerDomain
+	for iter.First(); iter.Valid(); iter.Next() {
+		var mapping model.IndexerDomain
+		if err := json.Unmarshal(iter.Value(), &mapping); err != nil {
 			continue
 		}
+		domains = append(domains, &mapping)
+	}
+	return domains, nil
+}
 
-		results = append(results, entry)
-		count++
-		iter.Prev()
+// AppendChangeEvent appends event to the change log, assigning the next Seq.
+// Sequence numbers are handed out under a lock together with the write, so an
+// event is never visible after one with a higher Seq.
+func (p *PebbleDatabase) AppendChangeEvent(event *model.IndexerChangeEvent) error {
+	if event.Action == "" {
+		return fmt.Errorf("change event action cannot be empty")
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
 	}
 
-	// Determine next cursor and hasMore
-	var nextCursor int64 = 0
-	hasMore := false
+	p.changeMu.Lock()
+	defer p.changeMu.Unlock()
 
-	if len(results) > 0 {
-		// Next cursor is the timestamp of the last result
-		nextCursor = results[len(results)-1].Timestamp
-		// Check if there are more records
-		hasMore = iter.Valid()
+	event.Seq = p.changeSeq + 1
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
 	}
 
-	return results, nextCursor, hasMore, nil
+	if err := p.collections[collectionChangeEvents].Set(changeEventKey(event.Seq), data, pebble.Sync); err != nil {
+		return err
+	}
+	if err := p.collections[collectionCounters].Set(
+		[]byte(keyChangeCounter),
+		[]byte(strconv.FormatInt(event.Seq, 10)),
+		pebble.Sync,
+	); err != nil {
+		return err
+	}
+	p.changeSeq = event.Seq
+	return nil
 }
 
-// GetMetaIDCount get total count of unique MetaIDs (users)
-func (p *PebbleDatabase) GetMetaIDCount() (int64, error) {
-	db := p.collections[collectionMetaIdTimestamp]
-
-	iter, err := db.NewIter(nil)
+// ListChangeEvents lists events with Seq > sinceSeq in ascending Seq order
+func (p *PebbleDatabase) ListChangeEvents(sinceSeq int64, size int) ([]*model.IndexerChangeEvent, error) {
+	iter, err := p.collections[collectionChangeEvents].NewIter(&pebble.IterOptions{
+		LowerBound: changeEventKey(sinceSeq + 1),
+	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer iter.Close()
 
-	var count int64 = 0
-	for iter.First(); iter.Valid(); iter.Next() {
-		count++
+	var events []*model.IndexerChangeEvent
+	for iter.First(); iter.Valid() && len(events) < size; iter.Next() {
+		var event model.IndexerChangeEvent
+		if err := json.Unmarshal(iter.Value(), &event); err != nil {
+			continue
+		}
+		events = append(events, &event)
 	}
+	return events, nil
+}
 
-	return count, nil
+func changeEventKey(seq int64) []byte {
+	return []byte(fmt.Sprintf("%020d", seq))
 }
 
 // MetaIdAddress operations
@@ -3018,6 +4536,135 @@ func (p *PebbleDatabase) DeletePendingIndexFile(pinID string) error {
 	return nil
 }
 
+// CreatePendingStorageWrite queues a failed storage write, keyed by storage
+// path. It overwrites any existing record for the same path (a retry updates
+// Attempts/NextRetryAt this way).
+func (p *PebbleDatabase) CreatePendingStorageWrite(w *model.PendingStorageWrite) error {
+	data, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	db := p.collections[collectionPendingStorageWrite]
+	return db.Set([]byte(w.StoragePath), data, pebble.Sync)
+}
+
+// GetPendingStorageWrite returns the queued write for a storage path, or
+// ErrNotFound when none exists.
+func (p *PebbleDatabase) GetPendingStorageWrite(storagePath string) (*model.PendingStorageWrite, error) {
+	db := p.collections[collectionPendingStorageWrite]
+	data, closer, err := db.Get([]byte(storagePath))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer closer.Close()
+
+	var w model.PendingStorageWrite
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListPendingStorageWrites returns all queued storage writes in storage path
+// order. The queue only grows during a storage outage and drains once the
+// backend is back, so a full scan is fine.
+func (p *PebbleDatabase) ListPendingStorageWrites() ([]*model.PendingStorageWrite, error) {
+	db := p.collections[collectionPendingStorageWrite]
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*model.PendingStorageWrite
+	for iter.First(); iter.Valid(); iter.Next() {
+		var w model.PendingStorageWrite
+		if err := json.Unmarshal(iter.Value(), &w); err != nil {
+			continue
+		}
+		out = append(out, &w)
+	}
+	return out, nil
+}
+
+// DeletePendingStorageWrite removes a queued write (called once it is
+// stored). Missing records are not an error.
+func (p *PebbleDatabase) DeletePendingStorageWrite(storagePath string) error {
+	db := p.collections[collectionPendingStorageWrite]
+	return db.Delete([]byte(storagePath), pebble.Sync)
+}
+
+// CreatePendingCreatorResolution queues a failed creator address lookup, keyed
+// by PIN ID. It overwrites any existing record for the PIN (a retry updates
+// Attempts/NextRetryAt this way).
+func (p *PebbleDatabase) CreatePendingCreatorResolution(r *model.PendingCreatorResolution) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	db := p.collections[collectionPendingCreatorResolution]
+	return db.Set([]byte(r.PinID), data, pebble.Sync)
+}
+
+// ListPendingCreatorResolutions returns all queued lookups in PIN ID order.
+// The queue only fills while the node is unreachable, so a full scan is fine.
+func (p *PebbleDatabase) ListPendingCreatorResolutions() ([]*model.PendingCreatorResolution, error) {
+	db := p.collections[collectionPendingCreatorResolution]
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var out []*model.PendingCreatorResolution
+	for iter.First(); iter.Valid(); iter.Next() {
+		var r model.PendingCreatorResolution
+		if err := json.Unmarshal(iter.Value(), &r); err != nil {
+			continue
+		}
+		out = append(out, &r)
+	}
+	return out, nil
+}
+
+// DeletePendingCreatorResolution removes a queued lookup. Missing records are
+// not an error.
+func (p *PebbleDatabase) DeletePendingCreatorResolution(pinID string) error {
+	db := p.collections[collectionPendingCreatorResolution]
+	return db.Delete([]byte(pinID), pebble.Sync)
+}
+
+// GetMetaIDGenesis returns the genesis PIN ID recorded for address, "" if none
+func (p *PebbleDatabase) GetMetaIDGenesis(address string) (string, error) {
+	db := p.collections[collectionMetaIDGenesis]
+	data, closer, err := db.Get([]byte(address))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	defer closer.Close()
+	return string(data), nil
+}
+
+// RecordMetaIDGenesis records pinID as the genesis PIN of address unless one
+// is already recorded
+func (p *PebbleDatabase) RecordMetaIDGenesis(address, pinID string) error {
+	p.genesisMu.Lock()
+	defer p.genesisMu.Unlock()
+
+	existing, err := p.GetMetaIDGenesis(address)
+	if err != nil || existing != "" {
+		return err
+	}
+	db := p.collections[collectionMetaIDGenesis]
+	return db.Set([]byte(address), []byte(pinID), pebble.Sync)
+}
+
 func (p *PebbleDatabase) buildUserInfoCachePayload(metaID string) (*model.IndexerUserInfo, *model.UserNameInfo) {
 	// Get latest user name
 	nameInfo, _ := p.GetLatestUserNameInfo(metaID)
@@ -3135,9 +4782,13 @@ func (p *PebbleDatabase) updateUserInfoCache(metaID string) {
 // Close close all database connections
 func (p *PebbleDatabase) Close() error {
 	var lastErr error
-	for name, db := range p.collections {
+	if err := p.FlushCounters(); err != nil {
+		log.Printf("Failed to flush counters: %v", err)
+		lastErr = err
+	}
+	for name, db := range p.stores {
 		if err := db.Close(); err != nil {
-			log.Printf("Failed to close collection %s: %v", name, err)
+			log.Printf("Failed to close PebbleDB %s: %v", name, err)
 			lastErr = err
 		}
 	}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPebbleChangeLog(t *testing.T) {
+	dir := t.TempDir()
+	dbi, err := NewPebbleDatabase(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("NewPebbleDatabase: %v", err)
+	}
+	pdb := dbi.(*PebbleDatabase)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			event := &model.IndexerChangeEvent{
+				Action: model.ChangeFileCreated,
+				PinID:  string(rune('a'+i)) + "i0",
+				Record: json.RawMessage(`{"pin_id":"x"}`),
+			}
+			if err := pdb.AppendChangeEvent(event); err != nil {
+				t.Errorf("AppendChangeEvent: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := pdb.AppendChangeEvent(&model.IndexerChangeEvent{}); err == nil {
+		t.Error("AppendChangeEvent without action: want error")
+	}
+
+	events, err := pdb.ListChangeEvents(0, 100)
+	if err != nil {
+		t.Fatalf("ListChangeEvents: %v", err)
+	}
+	if len(events) != 20 {
+		t.Fatalf("len = %d, want 20", len(events))
+	}
+	for i, event := range events {
+		if event.Seq != int64(i+1) {
+			t.Fatalf("events[%d].Seq = %d, want %d", i, event.Seq, i+1)
+		}
+	}
+	if string(events[0].Record) != `{"pin_id":"x"}` {
+		t.Errorf("record = %s", events[0].Record)
+	}
+
+	if page, _ := pdb.ListChangeEvents(15, 3); len(page) != 3 || page[0].Seq != 16 || page[2].Seq != 18 {
+		t.Errorf("page after 15 = %+v", page)
+	}
+	if page, _ := pdb.ListChangeEvents(20, 10); len(page) != 0 {
+		t.Errorf("page after last = %d events, want 0", len(page))
+	}
+
+	// Sequence numbers continue after a restart
+	if err := pdb.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	dbi, err = NewPebbleDatabase(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer dbi.Close()
+	event := &model.IndexerChangeEvent{Action: model.ChangeAvatarUpdated, PinID: "zi0"}
+	if err := dbi.AppendChangeEvent(event); err != nil {
+		t.Fatalf("AppendChangeEvent after reopen: %v", err)
+	}
+	if event.Seq != 21 {
+		t.Errorf("Seq after reopen = %d, want 21", event.Seq)
+	}
+}
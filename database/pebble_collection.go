@@ -0,0 +1,318 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// Directories of the two Pebble layouts under data_dir
+const (
+	pebbleStoreDir  = "indexer_kv" // One Pebble instance, a key prefix per collection
+	pebbleLegacyDir = "indexer_db" // One Pebble instance per collection (before the consolidation)
+)
+
+// pebbleCollection one collection of the indexer database. Every collection
+// lives in the single Pebble instance under its own key prefix; in the legacy
+// layout it has an instance of its own and the prefix is empty. Keys passed in
+// and returned are those of the collection, without the prefix.
+type pebbleCollection struct {
+	db     *pebble.DB
+	prefix []byte
+}
+
+// collectionPrefix the key prefix of a collection in the single instance: its
+// name and a 0 byte, so no collection's keys are a prefix of another's
+func collectionPrefix(name string) []byte {
+	return append([]byte(name), 0)
+}
+
+func (c *pebbleCollection) key(key []byte) []byte {
+	if len(c.prefix) == 0 {
+		return key
+	}
+	k := make([]byte, 0, len(c.prefix)+len(key))
+	return append(append(k, c.prefix...), key...)
+}
+
+// bounds the key range of the whole collection
+func (c *pebbleCollection) bounds() (lower, upper []byte) {
+	if len(c.prefix) == 0 {
+		return nil, nil
+	}
+	upper = append([]byte(nil), c.prefix...)
+	upper[len(upper)-1]++
+	return c.prefix, upper
+}
+
+func (c *pebbleCollection) Get(key []byte) ([]byte, io.Closer, error) {
+	return c.db.Get(c.key(key))
+}
+
+func (c *pebbleCollection) Set(key, value []byte, opts *pebble.WriteOptions) error {
+	return c.db.Set(c.key(key), value, opts)
+}
+
+func (c *pebbleCollection) Delete(key []byte, opts *pebble.WriteOptions) error {
+	return c.db.Delete(c.key(key), opts)
+}
+
+// NewIter iterates the collection; bounds in o are collection keys and a nil
+// bound stops at the edge of the collection
+func (c *pebbleCollection) NewIter(o *pebble.IterOptions) (*pebbleCollectionIter, error) {
+	opts := &pebble.IterOptions{}
+	if o != nil {
+		*opts = *o
+	}
+	if len(c.prefix) > 0 {
+		lower, upper := c.bounds()
+		if opts.LowerBound != nil {
+			lower = c.key(opts.LowerBound)
+		}
+		if opts.UpperBound != nil {
+			upper = c.key(opts.UpperBound)
+		}
+		opts.LowerBound, opts.UpperBound = lower, upper
+	}
+	iter, err := c.db.NewIter(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleCollectionIter{iter: iter, c: c}, nil
+}
+
+// NewBatch a batch of writes to the collection, applied atomically on Commit
+func (c *pebbleCollection) NewBatch() *pebbleCollectionBatch {
+	return &pebbleCollectionBatch{batch: c.db.NewBatch(), c: c}
+}
+
+// pebbleCollectionIter iterator over the keys of one collection
+type pebbleCollectionIter struct {
+	iter *pebble.Iterator
+	c    *pebbleCollection
+}
+
+func (it *pebbleCollectionIter) First() bool { return it.iter.First() }
+func (it *pebbleCollectionIter) Last() bool  { return it.iter.Last() }
+func (it *pebbleCollectionIter) Next() bool  { return it.iter.Next() }
+func (it *pebbleCollectionIter) Prev() bool  { return it.iter.Prev() }
+func (it *pebbleCollectionIter) Valid() bool { return it.iter.Valid() }
+
+func (it *pebbleCollectionIter) SeekGE(key []byte) bool { return it.iter.SeekGE(it.c.key(key)) }
+func (it *pebbleCollectionIter) SeekLT(key []byte) bool { return it.iter.SeekLT(it.c.key(key)) }
+
+// Key the current key without the collection prefix; like pebble's, only valid
+// until the iterator moves
+func (it *pebbleCollectionIter) Key() []byte { return it.iter.Key()[len(it.c.prefix):] }
+
+func (it *pebbleCollectionIter) Value() []byte { return it.iter.Value() }
+func (it *pebbleCollectionIter) Error() error  { return it.iter.Error() }
+func (it *pebbleCollectionIter) Close() error  { return it.iter.Close() }
+
+// pebbleCollectionBatch batch of writes to one collection
+type pebbleCollectionBatch struct {
+	batch *pebble.Batch
+	c     *pebbleCollection
+}
+
+func (b *pebbleCollectionBatch) Set(key, value []byte, opts *pebble.WriteOptions) error {
+	return b.batch.Set(b.c.key(key), value, opts)
+}
+
+func (b *pebbleCollectionBatch) Delete(key []byte, opts *pebble.WriteOptions) error {
+	return b.batch.Delete(b.c.key(key), opts)
+}
+
+func (b *pebbleCollectionBatch) Commit(opts *pebble.WriteOptions) error { return b.batch.Commit(opts) }
+func (b *pebbleCollectionBatch) Close() error                           { return b.batch.Close() }
+
+// openPebbleStores opens the collections and returns them with the Pebble
+// instances holding them, by name. A data directory still in the legacy
+// layout is opened as it is, one instance per collection, until it is
+// migrated with MigratePebbleLayout.
+func openPebbleStores(cfg *PebbleConfig, cache *pebble.Cache) (map[string]*pebbleCollection, map[string]*pebble.DB, error) {
+	collections := make(map[string]*pebbleCollection)
+	stores := make(map[string]*pebble.DB)
+
+	if !isPebbleLegacyLayout(cfg.DataDir) {
+		storePath := filepath.Join(cfg.DataDir, pebbleStoreDir)
+		log.Printf("Opening PebbleDB at %s", storePath)
+		db, err := pebble.Open(storePath, cfg.options(cache))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open PebbleDB at %s: %w", storePath, err)
+		}
+		stores[pebbleStoreDir] = db
+		for _, name := range pebbleCollectionNames {
+			collections[name] = &pebbleCollection{db: db, prefix: collectionPrefix(name)}
+		}
+		return collections, stores, nil
+	}
+
+	log.Printf("⚠️  PebbleDB data directory uses the legacy layout (one instance per collection); run the indexer with -migrate-pebble-layout to consolidate it")
+	for _, name := range pebbleCollectionNames {
+		// Create collection path: dataDir/indexer_db/collectionName
+		collectionPath := filepath.Join(cfg.DataDir, pebbleLegacyDir, name)
+
+		log.Printf("Opening collection: %s at %s", name, collectionPath)
+
+		db, err := pebble.Open(collectionPath, cfg.options(cache))
+		if err != nil {
+			// Close previously opened databases
+			for _, openedDB := range stores {
+				openedDB.Close()
+			}
+			return nil, nil, fmt.Errorf("failed to open collection %s at %s: %w", name, collectionPath, err)
+		}
+		stores[name] = db
+		collections[name] = &pebbleCollection{db: db}
+	}
+	return collections, stores, nil
+}
+
+// isPebbleLegacyLayout reports whether dataDir holds per-collection instances
+// and no consolidated store yet
+func isPebbleLegacyLayout(dataDir string) bool {
+	if _, err := os.Stat(filepath.Join(dataDir, pebbleStoreDir)); err == nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dataDir, pebbleLegacyDir))
+	return err == nil && info.IsDir()
+}
+
+// PebbleMigrationResult outcome of MigratePebbleLayout
+type PebbleMigrationResult struct {
+	Collections int   // Collections copied
+	Keys        int64 // Keys copied
+	LegacyDir   string
+}
+
+// migrateBatchBytes flush threshold of the migration's write batches
+const migrateBatchBytes = 16 << 20
+
+// MigratePebbleLayout copies every collection of the legacy layout (one Pebble
+// instance per collection under data_dir/indexer_db) into the single instance
+// under data_dir/indexer_kv, then renames indexer_db to indexer_db.legacy so
+// it can be restored or deleted. Run it with the indexer stopped. The copy is
+// written to indexer_kv.migrating and only renamed into place once every
+// collection is copied and its key count verified, so an interrupted
+// migration leaves the legacy layout in use and simply starts over.
+func MigratePebbleLayout(cfg *PebbleConfig) (*PebbleMigrationResult, error) {
+	legacyDir := filepath.Join(cfg.DataDir, pebbleLegacyDir)
+	storeDir := filepath.Join(cfg.DataDir, pebbleStoreDir)
+	if _, err := os.Stat(storeDir); err == nil {
+		return nil, fmt.Errorf("%s already exists: the data directory is already migrated", storeDir)
+	}
+	if !isPebbleLegacyLayout(cfg.DataDir) {
+		return nil, fmt.Errorf("no legacy collections found at %s", legacyDir)
+	}
+
+	tmpDir := storeDir + ".migrating"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return nil, fmt.Errorf("failed to remove unfinished migration %s: %w", tmpDir, err)
+	}
+	dst, err := pebble.Open(tmpDir, cfg.options(nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", tmpDir, err)
+	}
+
+	result := &PebbleMigrationResult{LegacyDir: legacyDir + ".legacy"}
+	known := make(map[string]bool)
+	for _, name := range pebbleCollectionNames {
+		known[name] = true
+		srcPath := filepath.Join(legacyDir, name)
+		if _, err := os.Stat(srcPath); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		n, err := copyLegacyCollection(srcPath, &pebbleCollection{db: dst, prefix: collectionPrefix(name)})
+		if err != nil {
+			dst.Close()
+			return nil, fmt.Errorf("collection %s: %w", name, err)
+		}
+		log.Printf("Migrated collection %s: %d keys", name, n)
+		result.Collections++
+		result.Keys += n
+	}
+	if entries, err := os.ReadDir(legacyDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() && !known[e.Name()] {
+				log.Printf("⚠️  Skipped %s: not a collection of this version", filepath.Join(legacyDir, e.Name()))
+			}
+		}
+	}
+
+	if err := dst.Flush(); err != nil {
+		dst.Close()
+		return nil, fmt.Errorf("failed to flush %s: %w", tmpDir, err)
+	}
+	if err := dst.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close %s: %w", tmpDir, err)
+	}
+	if err := os.Rename(tmpDir, storeDir); err != nil {
+		return nil, fmt.Errorf("failed to move %s into place: %w", tmpDir, err)
+	}
+	if err := os.Rename(legacyDir, result.LegacyDir); err != nil {
+		return nil, fmt.Errorf("migrated to %s but failed to rename %s (rename or delete it by hand): %w", storeDir, legacyDir, err)
+	}
+	return result, nil
+}
+
+// copyLegacyCollection copies every key of the instance at srcPath into dst
+// and checks dst then holds as many keys
+func copyLegacyCollection(srcPath string, dst *pebbleCollection) (int64, error) {
+	src, err := pebble.Open(srcPath, &pebble.Options{ReadOnly: true})
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	iter, err := src.NewIter(nil)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	var copied int64
+	batch := dst.NewBatch()
+	defer func() { batch.Close() }()
+	for iter.First(); iter.Valid(); iter.Next() {
+		if err := batch.Set(iter.Key(), iter.Value(), nil); err != nil {
+			return 0, err
+		}
+		copied++
+		if batch.batch.Len() >= migrateBatchBytes {
+			if err := batch.Commit(pebble.NoSync); err != nil {
+				return 0, err
+			}
+			batch.Close()
+			batch = dst.NewBatch()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return 0, err
+	}
+	if err := batch.Commit(pebble.NoSync); err != nil {
+		return 0, err
+	}
+
+	dstIter, err := dst.NewIter(nil)
+	if err != nil {
+		return 0, err
+	}
+	defer dstIter.Close()
+	var stored int64
+	for dstIter.First(); dstIter.Valid(); dstIter.Next() {
+		stored++
+	}
+	if err := dstIter.Error(); err != nil {
+		return 0, err
+	}
+	if stored != copied {
+		return 0, fmt.Errorf("copied %d keys but %d are stored", copied, stored)
+	}
+	return copied, nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+)
+
+func TestPebbleCollection_KeysStayInTheirCollection(t *testing.T) {
+	db, err := pebble.Open(t.TempDir(), &pebble.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// file_pinid is a prefix of file_pinid_x's name: their keys must not mix
+	a := &pebbleCollection{db: db, prefix: collectionPrefix("file_pinid")}
+	b := &pebbleCollection{db: db, prefix: collectionPrefix("file_pinid_x")}
+	for _, k := range []string{"a", "b", "c"} {
+		if err := a.Set([]byte(k), []byte("A"+k), nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Set([]byte(k), []byte("B"+k), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	batch := a.NewBatch()
+	batch.Delete([]byte("b"), nil)
+	batch.Set([]byte("d"), []byte("Ad"), nil)
+	if err := batch.Commit(pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+	batch.Close()
+
+	keys := func(c *pebbleCollection, o *pebble.IterOptions) (keys []string) {
+		iter, err := c.NewIter(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer iter.Close()
+		for iter.First(); iter.Valid(); iter.Next() {
+			keys = append(keys, string(iter.Key())+"="+string(iter.Value()))
+		}
+		return keys
+	}
+	if got := keys(a, nil); len(got) != 3 || got[0] != "a=Aa" || got[1] != "c=Ac" || got[2] != "d=Ad" {
+		t.Errorf("collection a: %v", got)
+	}
+	if got := keys(b, nil); len(got) != 3 || got[1] != "b=Bb" {
+		t.Errorf("collection b: %v", got)
+	}
+	if got := keys(a, &pebble.IterOptions{LowerBound: []byte("b"), UpperBound: []byte("d")}); len(got) != 1 || got[0] != "c=Ac" {
+		t.Errorf("collection a in [b, d): %v", got)
+	}
+
+	iter, err := b.NewIter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	if !iter.Last() || string(iter.Key()) != "c" {
+		t.Errorf("Last of b: %q", iter.Key())
+	}
+	if !iter.SeekLT([]byte("b")) || string(iter.Key()) != "a" {
+		t.Errorf("SeekLT(b) in b: %q", iter.Key())
+	}
+	if iter.SeekGE([]byte("e")) {
+		t.Errorf("SeekGE(e) in b ran into another collection: %q", iter.Key())
+	}
+
+	if _, _, err := b.Get([]byte("d")); err != pebble.ErrNotFound {
+		t.Errorf("Get of a key of another collection: %v", err)
+	}
+}
+
+func TestMigratePebbleLayout(t *testing.T) {
+	dir := t.TempDir()
+	for name, keys := range map[string][]string{
+		collectionDomains:  {"example.com", "files.example.com"},
+		collectionCounters: {keyFileCounter},
+	} {
+		db, err := pebble.Open(filepath.Join(dir, pebbleLegacyDir, name), &pebble.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range keys {
+			if err := db.Set([]byte(k), []byte("42"), pebble.Sync); err != nil {
+				t.Fatal(err)
+			}
+		}
+		db.Close()
+	}
+
+	// The legacy layout still opens as it is
+	dbi, err := NewPebbleDatabase(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("open legacy layout: %v", err)
+	}
+	if p := dbi.(*PebbleDatabase); len(p.stores) != len(pebbleCollectionNames) || p.fileIDCounter.Load() != 42 {
+		t.Fatalf("legacy layout: %d stores, file counter %d", len(p.stores), p.fileIDCounter.Load())
+	}
+	dbi.Close()
+
+	result, err := MigratePebbleLayout(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("MigratePebbleLayout: %v", err)
+	}
+	// Every collection was opened (and so created) above; only two hold keys
+	if result.Collections != len(pebbleCollectionNames) || result.Keys != 3 {
+		t.Errorf("result = %+v, want %d collections and 3 keys", result, len(pebbleCollectionNames))
+	}
+	if _, err := os.Stat(filepath.Join(dir, pebbleLegacyDir)); !os.IsNotExist(err) {
+		t.Errorf("legacy directory still in place: %v", err)
+	}
+	if _, err := os.Stat(result.LegacyDir); err != nil {
+		t.Errorf("legacy directory not kept as %s: %v", result.LegacyDir, err)
+	}
+
+	dbi, err = NewPebbleDatabase(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("open migrated layout: %v", err)
+	}
+	defer dbi.Close()
+	p := dbi.(*PebbleDatabase)
+	if len(p.stores) != 1 || p.fileIDCounter.Load() != 42 {
+		t.Fatalf("migrated layout: %d stores, file counter %d", len(p.stores), p.fileIDCounter.Load())
+	}
+	for _, k := range []string{"example.com", "files.example.com"} {
+		val, closer, err := p.collections[collectionDomains].Get([]byte(k))
+		if err != nil {
+			t.Fatalf("Get %s after migration: %v", k, err)
+		}
+		if string(val) != "42" {
+			t.Errorf("%s = %q after migration", k, val)
+		}
+		closer.Close()
+	}
+
+	if _, err := MigratePebbleLayout(&PebbleConfig{DataDir: dir}); err == nil {
+		t.Error("second migration succeeded")
+	}
+}
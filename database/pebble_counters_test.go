@@ -0,0 +1,201 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+
+	"meta-file-system/model"
+)
+
+// seedCounters indexes files, chunks and users covering status transitions,
+// modify PINs and a MetaID first seen again with an earlier timestamp
+func seedCounters(t *testing.T, pdb *PebbleDatabase) {
+	t.Helper()
+	files := []*model.IndexerFile{
+		// Created failed, then indexed successfully: counted once
+		{PinID: "a1i0", FirstPinID: "a1i0", ChainName: "mvc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusFailed, Timestamp: 1},
+		{PinID: "a1i0", FirstPinID: "a1i0", ChainName: "mvc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusSuccess, Timestamp: 1},
+		// Modify of a1i0 shares its first PIN: one more PIN, same file for chain/creator
+		{PinID: "a2i0", FirstPinID: "a1i0", ChainName: "mvc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusSuccess, Timestamp: 2},
+		{PinID: "b1i0", FirstPinID: "b1i0", ChainName: "btc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusSuccess, Timestamp: 3},
+		{PinID: "c1i0", FirstPinID: "c1i0", ChainName: "mvc", CreatorMetaId: "meta2", Status: model.StatusSuccess, Timestamp: 4},
+		// Never successful: not counted
+		{PinID: "d1i0", FirstPinID: "d1i0", ChainName: "mvc", CreatorMetaId: "meta2", Status: model.StatusFailed, Timestamp: 5},
+	}
+	for _, f := range files {
+		if err := pdb.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+
+	chunks := []*model.IndexerFileChunk{
+		{PinID: "k1i0", ChainName: "mvc", Status: model.StatusSuccess},
+		{PinID: "k1i0", ChainName: "mvc", ParentPinID: "a1i0", Status: model.StatusSuccess}, // Update after merge
+		{PinID: "k2i0", ChainName: "doge", Status: model.StatusSuccess},
+		{PinID: "k3i0", ChainName: "doge", Status: model.StatusFailed},
+	}
+	for _, c := range chunks {
+		if err := pdb.CreateIndexerFileChunk(c); err != nil {
+			t.Fatalf("CreateIndexerFileChunk(%s): %v", c.PinID, err)
+		}
+	}
+
+	users := []struct {
+		metaID, chain string
+		timestamp     int64
+	}{
+		{"meta1", "mvc", 100},
+		{"meta1", "mvc", 200}, // Later: ignored
+		{"meta2", "mvc", 300},
+		{"meta2", "btc", 150}, // Earlier on another chain: moves to btc
+	}
+	for _, u := range users {
+		if err := pdb.SaveMetaIdTimestamp(u.metaID, u.chain, u.timestamp); err != nil {
+			t.Fatalf("SaveMetaIdTimestamp(%s): %v", u.metaID, err)
+		}
+	}
+}
+
+func wantSeededCounters() map[string]int64 {
+	return map[string]int64{
+		model.CounterFiles: 4,
+		model.ChainCounter(model.CounterFiles, "mvc"):   2,
+		model.ChainCounter(model.CounterFiles, "btc"):   1,
+		model.CreatorMetaIDCounter("meta1"):             2,
+		model.CreatorMetaIDCounter("meta2"):             1,
+		model.CreatorGlobalMetaIDCounter("idq1"):        2,
+		model.CounterChunks:                             2,
+		model.ChainCounter(model.CounterChunks, "mvc"):  1,
+		model.ChainCounter(model.CounterChunks, "doge"): 1,
+		model.CounterUsers:                              2,
+		model.ChainCounter(model.CounterUsers, "mvc"):   1,
+		model.ChainCounter(model.CounterUsers, "btc"):   1,
+		model.ChainCounter(model.CounterUsers, "doge"):  0,
+		model.ChainCounter(model.CounterFiles, "doge"):  0,
+		model.CreatorMetaIDCounter("unknown"):           0,
+		model.CreatorGlobalMetaIDCounter("idq-unknown"): 0,
+	}
+}
+
+func checkCounters(t *testing.T, stage string, pdb *PebbleDatabase) {
+	t.Helper()
+	for name, want := range wantSeededCounters() {
+		got, err := pdb.GetCounter(name)
+		if err != nil {
+			t.Fatalf("%s GetCounter(%s): %v", stage, name, err)
+		}
+		if got != want {
+			t.Errorf("%s %s = %d, want %d", stage, name, got, want)
+		}
+	}
+}
+
+func TestCounters_BufferedFlushedAndReopened(t *testing.T) {
+	dir := t.TempDir()
+	dbi, err := NewPebbleDatabase(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("NewPebbleDatabase: %v", err)
+	}
+	pdb := dbi.(*PebbleDatabase)
+	seedCounters(t, pdb)
+
+	// Reads include deltas that are not flushed yet
+	checkCounters(t, "buffered", pdb)
+	if stored, _ := pdb.getStoredCounter(model.CounterFiles); stored != 0 {
+		t.Errorf("files stored before flush = %d, want 0", stored)
+	}
+
+	if err := pdb.FlushCounters(); err != nil {
+		t.Fatalf("FlushCounters: %v", err)
+	}
+	checkCounters(t, "flushed", pdb)
+	if stored, _ := pdb.getStoredCounter(model.CounterFiles); stored != 4 {
+		t.Errorf("files stored after flush = %d, want 4", stored)
+	}
+
+	// The existing file and count readers are served from the counters
+	if n, _ := pdb.GetIndexerFilesCountByChain("mvc"); n != 2 {
+		t.Errorf("GetIndexerFilesCountByChain(mvc) = %d, want 2", n)
+	}
+	if n, _ := pdb.GetMetaIDCount(); n != 2 {
+		t.Errorf("GetMetaIDCount = %d, want 2", n)
+	}
+	chains, err := pdb.ListCounters(model.CounterChunks + ":chain:")
+	if err != nil || len(chains) != 2 || chains["chunks:chain:doge"] != 1 {
+		t.Errorf("ListCounters(chunks:chain:) = %v (%v)", chains, err)
+	}
+
+	// Deltas buffered at Close are flushed, not lost
+	if err := pdb.CreateIndexerFile(&model.IndexerFile{PinID: "e1i0", FirstPinID: "e1i0", ChainName: "btc", Status: model.StatusSuccess, Timestamp: 6}); err != nil {
+		t.Fatalf("CreateIndexerFile(e1i0): %v", err)
+	}
+	if err := dbi.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	dbi, err = NewPebbleDatabase(&PebbleConfig{DataDir: dir})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer dbi.Close()
+	if n, _ := dbi.GetCounter(model.ChainCounter(model.CounterFiles, "btc")); n != 2 {
+		t.Errorf("files:chain:btc after reopen = %d, want 2", n)
+	}
+}
+
+func TestCounters_Reconcile(t *testing.T) {
+	pdb := newTestPebble(t)
+	seedCounters(t, pdb)
+
+	drifts, err := pdb.ReconcileCounters(false)
+	if err != nil {
+		t.Fatalf("ReconcileCounters: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("maintained counters drifted from recount: %+v", drifts)
+	}
+
+	// Simulate lost and stale updates
+	db := pdb.collections[collectionStatCounters]
+	if err := db.Set([]byte(model.CounterChunks), []byte("7"), pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete([]byte(model.ChainCounter(model.CounterUsers, "btc")), pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Set([]byte(model.CreatorMetaIDCounter("ghost")), []byte("3"), pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err = pdb.ReconcileCounters(false)
+	if err != nil {
+		t.Fatalf("ReconcileCounters: %v", err)
+	}
+	want := []model.CounterDrift{
+		{Name: model.CounterChunks, Stored: 7, Actual: 2},
+		{Name: model.CreatorMetaIDCounter("ghost"), Stored: 3, Actual: 0},
+		{Name: model.ChainCounter(model.CounterUsers, "btc"), Stored: 0, Actual: 1},
+	}
+	if len(drifts) != len(want) {
+		t.Fatalf("drifts = %+v, want %+v", drifts, want)
+	}
+	for i := range want {
+		if *drifts[i] != want[i] {
+			t.Errorf("drift[%d] = %+v, want %+v", i, *drifts[i], want[i])
+		}
+	}
+	if n, _ := pdb.GetCounter(model.CounterChunks); n != 7 {
+		t.Errorf("check-only reconcile changed chunks to %d", n)
+	}
+
+	if _, err := pdb.ReconcileCounters(true); err != nil {
+		t.Fatalf("ReconcileCounters(fix): %v", err)
+	}
+	checkCounters(t, "fixed", pdb)
+	if n, _ := pdb.GetCounter(model.CreatorMetaIDCounter("ghost")); n != 0 {
+		t.Errorf("ghost counter = %d after fix, want 0", n)
+	}
+	if drifts, _ := pdb.ReconcileCounters(false); len(drifts) != 0 {
+		t.Errorf("drifts after fix: %+v", drifts)
+	}
+}
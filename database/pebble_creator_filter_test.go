@@ -0,0 +1,75 @@
+package database
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPebbleCreatorFileFilters(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	seed := []*model.IndexerFile{
+		{PinID: "img1i0", Path: "/file/photos/a.png", ContentType: "image/png", FileType: "image", ChainName: "mvc", Timestamp: 4},
+		{PinID: "img2i0", Path: "/file/photos2/b.jpg", ContentType: "image/jpeg", FileType: "image", ChainName: "btc", Timestamp: 3},
+		{PinID: "doc1i0", Path: "/file/docs/c.pdf", ContentType: "application/pdf", FileType: "document", ChainName: "mvc", Timestamp: 2},
+		{PinID: "img3i0", Path: "/file/photos/sub/d.png", ContentType: "image/png", FileType: "image", ChainName: "mvc", Timestamp: 1},
+	}
+	for _, f := range seed {
+		f.FirstPinID = f.PinID
+		f.CreatorMetaId = "creator1"
+		f.Status = model.StatusSuccess
+		if err := pdb.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		filter model.IndexerFileFilter
+		want   []string
+	}{
+		{"no filter", model.IndexerFileFilter{}, []string{"img1i0", "img2i0", "doc1i0", "img3i0"}},
+		{"file type", model.IndexerFileFilter{FileType: "image"}, []string{"img1i0", "img2i0", "img3i0"}},
+		{"file type and chain", model.IndexerFileFilter{FileType: "image", ChainName: "btc"}, []string{"img2i0"}},
+		{"chain only", model.IndexerFileFilter{ChainName: "mvc"}, []string{"img1i0", "doc1i0", "img3i0"}},
+		{"content type prefix", model.IndexerFileFilter{ContentTypePrefix: "image/png"}, []string{"img1i0", "img3i0"}},
+		{"path prefix on segments", model.IndexerFileFilter{PathPrefix: "/file/photos"}, []string{"img1i0", "img3i0"}},
+		{"combined", model.IndexerFileFilter{FileType: "image", PathPrefix: "/FILE/photos/sub"}, []string{"img3i0"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, _, _, err := pdb.GetIndexerFilesByCreatorMetaIDWithCursor("creator1", tc.filter, 0, 20)
+			if err != nil {
+				t.Fatalf("GetIndexerFilesByCreatorMetaIDWithCursor: %v", err)
+			}
+			if len(files) != len(tc.want) {
+				t.Fatalf("got %d files, want %v", len(files), tc.want)
+			}
+			for i, f := range files {
+				if f.PinID != tc.want[i] {
+					t.Errorf("files[%d] = %s, want %s", i, f.PinID, tc.want[i])
+				}
+			}
+		})
+	}
+
+	// A modify that changes the file type must drop the old type index entry
+	modified := *seed[0]
+	modified.PinID = "img1mod1i0"
+	modified.FileType = "document"
+	modified.ContentType = "application/pdf"
+	modified.Timestamp = 5
+	if err := pdb.CreateIndexerFile(&modified); err != nil {
+		t.Fatalf("CreateIndexerFile(modified): %v", err)
+	}
+	files, _, _, err := pdb.GetIndexerFilesByCreatorMetaIDWithCursor("creator1", model.IndexerFileFilter{FileType: "image"}, 0, 20)
+	if err != nil {
+		t.Fatalf("GetIndexerFilesByCreatorMetaIDWithCursor: %v", err)
+	}
+	for _, f := range files {
+		if f.FirstPinID == "img1i0" {
+			t.Fatalf("stale image entry for modified file: %+v", f)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPebbleReassignIndexerFileCreator(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	previous := &model.IndexerFile{
+		PinID: "f1i0", FirstPinID: "f1i0", Path: "/file/a.png", FileType: "image", FileExtension: ".png",
+		ChainName: "mvc", Timestamp: 1700000000000, Status: model.StatusSuccess, ResolutionPending: true,
+		CreatorAddress: "fallback", CreatorMetaId: "metaFallback", CreatorGlobalMetaId: "idqFallback",
+	}
+	if err := pdb.CreateIndexerFile(previous); err != nil {
+		t.Fatalf("CreateIndexerFile: %v", err)
+	}
+
+	corrected := *previous
+	corrected.ResolutionPending = false
+	corrected.CreatorAddress = "real"
+	corrected.CreatorMetaId = "metaReal"
+	corrected.CreatorGlobalMetaId = "idqReal"
+	if err := pdb.ReassignIndexerFileCreator(&corrected, previous); err != nil {
+		t.Fatalf("ReassignIndexerFileCreator: %v", err)
+	}
+
+	listings := []struct {
+		name string
+		list func() ([]*model.IndexerFile, error)
+		want int
+	}{
+		{"fallback address", func() ([]*model.IndexerFile, error) {
+			files, _, _, err := pdb.GetIndexerFilesByCreatorAddressWithCursor("fallback", 0, 10)
+			return files, err
+		}, 0},
+		{"real address", func() ([]*model.IndexerFile, error) {
+			files, _, _, err := pdb.GetIndexerFilesByCreatorAddressWithCursor("real", 0, 10)
+			return files, err
+		}, 1},
+		{"fallback metaid", func() ([]*model.IndexerFile, error) {
+			files, _, _, err := pdb.GetIndexerFilesByCreatorMetaIDWithCursor("metaFallback", model.IndexerFileFilter{}, 0, 10)
+			return files, err
+		}, 0},
+		{"real metaid by type", func() ([]*model.IndexerFile, error) {
+			files, _, _, err := pdb.GetIndexerFilesByCreatorMetaIDWithCursor("metaReal", model.IndexerFileFilter{FileType: "image"}, 0, 10)
+			return files, err
+		}, 1},
+		{"fallback global metaid", func() ([]*model.IndexerFile, error) {
+			files, _, _, err := pdb.GetIndexerFilesByCreatorGlobalMetaIDWithCursor("idqFallback", model.IndexerFileFilter{}, 0, 10)
+			return files, err
+		}, 0},
+		{"real global metaid and extension", func() ([]*model.IndexerFile, error) {
+			files, _, err := pdb.GetIndexerFilesByGlobalMetaIDAndExtensionWithCursor("idqReal", ".png", "", 10)
+			return files, err
+		}, 1},
+		{"fallback global metaid and extension", func() ([]*model.IndexerFile, error) {
+			files, _, err := pdb.GetIndexerFilesByGlobalMetaIDAndExtensionWithCursor("idqFallback", ".png", "", 10)
+			return files, err
+		}, 0},
+	}
+	for _, l := range listings {
+		files, err := l.list()
+		if err != nil {
+			t.Fatalf("%s: %v", l.name, err)
+		}
+		if len(files) != l.want {
+			t.Errorf("%s: %d files, want %d", l.name, len(files), l.want)
+		}
+		for _, f := range files {
+			if f.CreatorAddress != "real" || f.ResolutionPending {
+				t.Errorf("%s: stale copy %+v", l.name, f)
+			}
+		}
+	}
+
+	stored, err := pdb.GetIndexerFileByPinID("f1i0")
+	if err != nil || stored.CreatorMetaId != "metaReal" {
+		t.Fatalf("GetIndexerFileByPinID = %+v, %v", stored, err)
+	}
+	counts := map[string]int64{"metaFallback": 0, "metaReal": 1}
+	for metaID, want := range counts {
+		if got, _ := pdb.GetIndexerFilesCountByCreatorMetaID(metaID); got != want {
+			t.Errorf("count of %s = %d, want %d", metaID, got, want)
+		}
+	}
+	if got, _ := pdb.GetIndexerFilesCountByCreatorGlobalMetaID("idqReal"); got != 1 {
+		t.Errorf("count of idqReal = %d, want 1", got)
+	}
+	if got, _ := pdb.GetIndexerFilesCountByCreatorGlobalMetaID("idqFallback"); got != 0 {
+		t.Errorf("count of idqFallback = %d, want 0", got)
+	}
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+// ms for 2025-01-01T12:00:00Z plus whole days
+func dayMs(day int) int64 {
+	return 1735732800000 + int64(day)*24*3600*1000
+}
+
+func dailyStatsByKey(t *testing.T, pdb *PebbleDatabase, from, to string) map[string]model.IndexerDailyStat {
+	t.Helper()
+	stats, err := pdb.ListDailyStats(from, to)
+	if err != nil {
+		t.Fatalf("ListDailyStats: %v", err)
+	}
+	out := make(map[string]model.IndexerDailyStat, len(stats))
+	for _, s := range stats {
+		out[s.Date+":"+s.ChainName] = *s
+	}
+	return out
+}
+
+func TestPebbleDailyStatsIncrementAndRange(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	deltas := []*model.IndexerDailyStat{
+		{Date: "2025-01-01", ChainName: "mvc", NewFiles: 1, Bytes: 100},
+		{Date: "2025-01-01", ChainName: "mvc", NewFiles: 1, Bytes: 50},
+		{Date: "2025-01-01", ChainName: "btc", NewFiles: 1, Bytes: 10},
+		{Date: "2025-01-02", ChainName: "mvc", NewUsers: 1},
+		{Date: "2025-01-03", ChainName: "doge", NewFiles: 1, Bytes: 7},
+	}
+	for _, d := range deltas {
+		if err := pdb.IncrDailyStat(d); err != nil {
+			t.Fatalf("IncrDailyStat: %v", err)
+		}
+	}
+	if err := pdb.IncrDailyStat(&model.IndexerDailyStat{ChainName: "mvc"}); err == nil {
+		t.Fatal("IncrDailyStat without date: want error")
+	}
+
+	got := dailyStatsByKey(t, pdb, "2025-01-01", "2025-01-02")
+	if len(got) != 3 {
+		t.Fatalf("got %d buckets, want 3: %+v", len(got), got)
+	}
+	if s := got["2025-01-01:mvc"]; s.NewFiles != 2 || s.Bytes != 150 {
+		t.Errorf("2025-01-01:mvc = %+v, want 2 files / 150 bytes", s)
+	}
+	if s := got["2025-01-02:mvc"]; s.NewUsers != 1 {
+		t.Errorf("2025-01-02:mvc = %+v, want 1 user", s)
+	}
+	if _, ok := got["2025-01-03:doge"]; ok {
+		t.Error("2025-01-03 must be outside the range")
+	}
+}
+
+func TestPebbleDailyStatsUsersMoveToEarliestDay(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	if err := pdb.SaveMetaIdTimestamp("user1", "mvc", dayMs(2)); err != nil {
+		t.Fatalf("SaveMetaIdTimestamp: %v", err)
+	}
+	// Later sighting is ignored, earlier sighting moves the user
+	if err := pdb.SaveMetaIdTimestamp("user1", "mvc", dayMs(3)); err != nil {
+		t.Fatalf("SaveMetaIdTimestamp: %v", err)
+	}
+	if err := pdb.SaveMetaIdTimestamp("user1", "btc", dayMs(0)); err != nil {
+		t.Fatalf("SaveMetaIdTimestamp: %v", err)
+	}
+
+	got := dailyStatsByKey(t, pdb, "2025-01-01", "2025-01-31")
+	if s := got["2025-01-01:btc"]; s.NewUsers != 1 {
+		t.Errorf("2025-01-01:btc = %+v, want 1 user", s)
+	}
+	if s := got["2025-01-03:mvc"]; s.NewUsers != 0 {
+		t.Errorf("2025-01-03:mvc = %+v, want 0 users after move", s)
+	}
+	if s := got["2025-01-04:mvc"]; s.NewUsers != 0 {
+		t.Errorf("2025-01-04:mvc = %+v, want later sighting ignored", s)
+	}
+}
+
+func TestPebbleRebuildDailyStats(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	files := []*model.IndexerFile{
+		{PinID: "a1i0", ChainName: "mvc", FileSize: 100, Timestamp: dayMs(0), Status: model.StatusSuccess},
+		{PinID: "a2i0", ChainName: "mvc", FileSize: 20, Timestamp: dayMs(0), Status: model.StatusSuccess},
+		{PinID: "b1i0", ChainName: "doge", FileSize: 5, Timestamp: dayMs(1), Status: model.StatusSuccess},
+		{PinID: "f1i0", ChainName: "mvc", FileSize: 999, Timestamp: dayMs(0), Status: model.StatusFailed},
+	}
+	for _, f := range files {
+		f.FirstPinID = f.PinID
+		if err := pdb.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+	if err := pdb.SaveMetaIdTimestamp("user1", "doge", dayMs(1)); err != nil {
+		t.Fatalf("SaveMetaIdTimestamp: %v", err)
+	}
+	// Stale bucket that the rebuild must drop
+	if err := pdb.IncrDailyStat(&model.IndexerDailyStat{Date: "2025-01-05", ChainName: "btc", NewFiles: 9}); err != nil {
+		t.Fatalf("IncrDailyStat: %v", err)
+	}
+
+	if err := pdb.RebuildDailyStats(); err != nil {
+		t.Fatalf("RebuildDailyStats: %v", err)
+	}
+
+	got := dailyStatsByKey(t, pdb, "2025-01-01", "2025-01-31")
+	want := map[string]model.IndexerDailyStat{
+		"2025-01-01:mvc":  {NewFiles: 2, Bytes: 120},
+		"2025-01-02:doge": {NewFiles: 1, NewUsers: 1, Bytes: 5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d buckets, want %d: %+v", len(got), len(want), got)
+	}
+	for key, w := range want {
+		g := got[key]
+		if g.NewFiles != w.NewFiles || g.NewUsers != w.NewUsers || g.Bytes != w.Bytes {
+			t.Errorf("%s = %+v, want %+v", key, g, w)
+		}
+	}
+}
+
+func TestPebbleDailyStatsCountFirstSuccessOnly(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	create := func(f model.IndexerFile) {
+		t.Helper()
+		if err := pdb.CreateIndexerFile(&f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+	file := model.IndexerFile{FirstPinID: "a1i0", PinID: "a1i0", ChainName: "mvc", FileSize: 100, Timestamp: dayMs(0), Status: model.StatusSuccess}
+	create(file)
+	// Rescan of the same PIN
+	create(file)
+	// Modify of the same file on a later day
+	create(model.IndexerFile{FirstPinID: "a1i0", PinID: "a2i0", ChainName: "mvc", FileSize: 300, Timestamp: dayMs(1), Status: model.StatusSuccess})
+	// Failed first, successful on retry: counted once
+	retried := model.IndexerFile{FirstPinID: "b1i0", PinID: "b1i0", ChainName: "mvc", FileSize: 7, Timestamp: dayMs(0), Status: model.StatusFailed}
+	create(retried)
+	retried.Status = model.StatusSuccess
+	create(retried)
+	create(retried)
+
+	got := dailyStatsByKey(t, pdb, "2025-01-01", "2025-01-31")
+	if s := got["2025-01-01:mvc"]; s.NewFiles != 2 || s.Bytes != 107 {
+		t.Errorf("2025-01-01:mvc = %+v, want 2 files / 107 bytes", s)
+	}
+	if s := got["2025-01-02:mvc"]; s.NewFiles != 0 || s.Bytes != 0 {
+		t.Errorf("2025-01-02:mvc = %+v, modify must not count as a new file", s)
+	}
+}
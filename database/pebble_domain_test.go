@@ -0,0 +1,55 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPebbleDomainCRUD(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	for _, d := range []*model.IndexerDomain{
+		{Domain: "b.example.com", TargetType: model.DomainTargetSite, Target: "pin1"},
+		{Domain: "a.example.com", TargetType: model.DomainTargetMetaID, Target: "meta1"},
+	} {
+		if err := pdb.SaveDomain(d); err != nil {
+			t.Fatalf("SaveDomain: %v", err)
+		}
+	}
+	if err := pdb.SaveDomain(&model.IndexerDomain{}); err == nil {
+		t.Error("SaveDomain with empty domain: want error")
+	}
+
+	first, err := pdb.GetDomain("b.example.com")
+	if err != nil || first.Target != "pin1" {
+		t.Fatalf("GetDomain = %+v, %v", first, err)
+	}
+	// Saving again replaces the target and keeps the creation time
+	if err := pdb.SaveDomain(&model.IndexerDomain{Domain: "b.example.com", TargetType: model.DomainTargetSite, Target: "pin2"}); err != nil {
+		t.Fatalf("SaveDomain replace: %v", err)
+	}
+	got, err := pdb.GetDomain("b.example.com")
+	if err != nil || got.Target != "pin2" || !got.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("after replace = %+v, %v; created_at %v", got, err, first.CreatedAt)
+	}
+
+	domains, err := pdb.ListDomains()
+	if err != nil {
+		t.Fatalf("ListDomains: %v", err)
+	}
+	if len(domains) != 2 || domains[0].Domain != "a.example.com" || domains[1].Domain != "b.example.com" {
+		t.Fatalf("domains = %+v", domains)
+	}
+
+	if err := pdb.DeleteDomain("a.example.com"); err != nil {
+		t.Fatalf("DeleteDomain: %v", err)
+	}
+	if err := pdb.DeleteDomain("a.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteDomain missing = %v, want ErrNotFound", err)
+	}
+	if _, err := pdb.GetDomain("a.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetDomain deleted = %v, want ErrNotFound", err)
+	}
+}
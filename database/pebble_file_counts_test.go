@@ -0,0 +1,60 @@
+package database
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestFileCounts_MaintainedAndRebuilt(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	files := []*model.IndexerFile{
+		// Created failed, then indexed successfully: counted once
+		{PinID: "a1i0", FirstPinID: "a1i0", ChainName: "mvc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusFailed, Timestamp: 1},
+		{PinID: "a1i0", FirstPinID: "a1i0", ChainName: "mvc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusSuccess, Timestamp: 1},
+		// Modify of a1i0 shares its first PIN: one more PIN, same file for chain/creator
+		{PinID: "a2i0", FirstPinID: "a1i0", ChainName: "mvc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusSuccess, Timestamp: 2},
+		{PinID: "b1i0", FirstPinID: "b1i0", ChainName: "btc", CreatorMetaId: "meta1", CreatorGlobalMetaId: "idq1", Status: model.StatusSuccess, Timestamp: 3},
+		{PinID: "c1i0", FirstPinID: "c1i0", ChainName: "mvc", CreatorMetaId: "meta2", Status: model.StatusSuccess, Timestamp: 4},
+		// Never successful: not counted
+		{PinID: "d1i0", FirstPinID: "d1i0", ChainName: "mvc", CreatorMetaId: "meta2", Status: model.StatusFailed, Timestamp: 5},
+	}
+	for _, f := range files {
+		if err := pdb.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+
+	cases := []struct {
+		name  string
+		count func() (int64, error)
+		want  int64
+	}{
+		{"total", pdb.GetIndexerFilesCount, 4},
+		{"chain mvc", func() (int64, error) { return pdb.GetIndexerFilesCountByChain("mvc") }, 2},
+		{"chain btc", func() (int64, error) { return pdb.GetIndexerFilesCountByChain("btc") }, 1},
+		{"chain doge", func() (int64, error) { return pdb.GetIndexerFilesCountByChain("doge") }, 0},
+		{"metaid meta1", func() (int64, error) { return pdb.GetIndexerFilesCountByCreatorMetaID("meta1") }, 2},
+		{"metaid meta2", func() (int64, error) { return pdb.GetIndexerFilesCountByCreatorMetaID("meta2") }, 1},
+		{"global metaid", func() (int64, error) { return pdb.GetIndexerFilesCountByCreatorGlobalMetaID("idq1") }, 2},
+	}
+	check := func(stage string) {
+		t.Helper()
+		for _, tc := range cases {
+			got, err := tc.count()
+			if err != nil {
+				t.Fatalf("%s %s: %v", stage, tc.name, err)
+			}
+			if got != tc.want {
+				t.Errorf("%s %s = %d, want %d", stage, tc.name, got, tc.want)
+			}
+		}
+	}
+
+	check("maintained")
+	if _, err := pdb.ReconcileCounters(true); err != nil {
+		t.Fatalf("ReconcileCounters: %v", err)
+	}
+	check("reconciled")
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestGetIndexerFilesByFileHash(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	hashA := "aa00000000000000000000000000000000000000000000000000000000000000"
+	hashB := "bb00000000000000000000000000000000000000000000000000000000000000"
+	for _, f := range []*model.IndexerFile{
+		{PinID: "a1i0", FirstPinID: "a1i0", ChainName: "mvc", FileMd5: "md5a", FileHash: hashA, Status: model.StatusSuccess, Timestamp: 2},
+		{PinID: "a2i0", FirstPinID: "a2i0", ChainName: "btc", FileMd5: "md5a", FileHash: hashA, Status: model.StatusSuccess, Timestamp: 1},
+		{PinID: "a3i0", FirstPinID: "a3i0", ChainName: "mvc", FileHash: hashA, Status: model.StatusRejected, Timestamp: 3},
+		{PinID: "b1i0", FirstPinID: "b1i0", ChainName: "mvc", FileHash: hashB, Status: model.StatusSuccess, Timestamp: 4},
+		{PinID: "c1i0", FirstPinID: "c1i0", ChainName: "mvc", Status: model.StatusSuccess, Timestamp: 5},
+	} {
+		if err := pdb.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+	// An entry written by the old MD5-keyed index
+	if err := pdb.collections[collectionFileHash].Set([]byte("md5a:a1i0"), []byte(`{"pin_id":"a1i0","status":"success"}`), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(stage string) {
+		t.Helper()
+		for hash, want := range map[string][]string{hashA: {"a1i0", "a2i0"}, hashB: {"b1i0"}} {
+			files, err := pdb.GetIndexerFilesByFileHash(hash)
+			if err != nil {
+				t.Fatalf("%s GetIndexerFilesByFileHash(%q): %v", stage, hash, err)
+			}
+			var got []string
+			for _, f := range files {
+				got = append(got, f.PinID)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s GetIndexerFilesByFileHash(%q) = %v, want %v", stage, hash, got, want)
+			}
+		}
+	}
+
+	check("maintained")
+	if err := pdb.RebuildFileHashIndex(); err != nil {
+		t.Fatalf("RebuildFileHashIndex: %v", err)
+	}
+	check("rebuilt")
+	if files, err := pdb.GetIndexerFilesByFileHash("md5a"); err != nil || len(files) != 0 {
+		t.Errorf("MD5-keyed entry survived the rebuild: %v, %v", files, err)
+	}
+}
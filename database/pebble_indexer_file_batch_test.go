@@ -0,0 +1,43 @@
+package database
+
+import (
+	"sort"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPebbleGetIndexerFilesByPinIDs(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	for _, pinID := range []string{"aaa1i0", "bbb2i0", "ccc3i0", "ccc3i01"} {
+		file := &model.IndexerFile{
+			FirstPinID: pinID,
+			PinID:      pinID,
+			Path:       "/file/" + pinID,
+			ChainName:  "mvc",
+			Status:     model.StatusSuccess,
+		}
+		if err := pdb.CreateIndexerFile(file); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", pinID, err)
+		}
+	}
+
+	// Unsorted, duplicated, with a missing ID and a prefix of an existing key
+	files, err := pdb.GetIndexerFilesByPinIDs([]string{"ccc3i0", "zzz9i0", "aaa1i0", "ccc3i0", "bbb"})
+	if err != nil {
+		t.Fatalf("GetIndexerFilesByPinIDs: %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.PinID)
+	}
+	sort.Strings(got)
+	if len(got) != 2 || got[0] != "aaa1i0" || got[1] != "ccc3i0" {
+		t.Fatalf("got %v, want [aaa1i0 ccc3i0]", got)
+	}
+
+	if files, err := pdb.GetIndexerFilesByPinIDs(nil); err != nil || len(files) != 0 {
+		t.Fatalf("empty input: files=%v err=%v", files, err)
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+
+	"meta-file-system/model"
+)
+
+func TestLatestFileInfoTieIsOrderIndependent(t *testing.T) {
+	v1 := &model.IndexerFile{PinID: "aaai0", FirstPinID: "aaai0", Path: "/file/a", BlockHeight: 10, Timestamp: 1000, Status: model.StatusSuccess}
+	v2 := &model.IndexerFile{PinID: "bbbi0", FirstPinID: "aaai0", Path: "/file/a", BlockHeight: 11, Timestamp: 1000, Status: model.StatusSuccess}
+
+	for _, order := range [][]*model.IndexerFile{{v1, v2}, {v2, v1}} {
+		pdb := newTestPebble(t)
+		for _, f := range order {
+			copied := *f
+			if err := pdb.CreateIndexerFile(&copied); err != nil {
+				t.Fatalf("CreateIndexerFile: %v", err)
+			}
+		}
+		latest, err := pdb.GetLatestFileInfoByFirstPinID("aaai0")
+		if err != nil || latest.PinID != "bbbi0" {
+			t.Errorf("latest after %s,%s = %+v, %v; want bbbi0", order[0].PinID, order[1].PinID, latest, err)
+		}
+	}
+}
+
+func TestRepairLatestFileInfo(t *testing.T) {
+	pdb := newTestPebble(t)
+	for _, f := range []*model.IndexerFile{
+		{PinID: "aaai0", FirstPinID: "aaai0", Path: "/file/a", BlockHeight: 10, Timestamp: 1000, Status: model.StatusSuccess},
+		{PinID: "bbbi0", FirstPinID: "aaai0", Path: "/file/a", BlockHeight: 20, Timestamp: 2000, Status: model.StatusSuccess},
+	} {
+		if err := pdb.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile: %v", err)
+		}
+	}
+
+	// An out-of-order write left the older version as latest
+	stale, _ := json.Marshal(&model.IndexerFile{PinID: "aaai0", FirstPinID: "aaai0", Timestamp: 1000})
+	if err := pdb.collections[collectionLatestFileInfo].Set([]byte("aaai0"), stale, pebble.Sync); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err := pdb.RepairLatestIndexes(false)
+	if err != nil {
+		t.Fatalf("RepairLatestIndexes: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].Collection != collectionLatestFileInfo || drifts[0].Stored != "aaai0" || drifts[0].Actual != "bbbi0" {
+		t.Fatalf("drifts = %+v", drifts)
+	}
+	if latest, _ := pdb.GetLatestFileInfoByFirstPinID("aaai0"); latest.PinID != "aaai0" {
+		t.Fatal("check-only run rewrote the entry")
+	}
+
+	if _, err := pdb.RepairLatestIndexes(true); err != nil {
+		t.Fatalf("RepairLatestIndexes(fix): %v", err)
+	}
+	if latest, _ := pdb.GetLatestFileInfoByFirstPinID("aaai0"); latest.PinID != "bbbi0" || latest.Path != "/file/a" {
+		t.Errorf("latest after repair = %+v", latest)
+	}
+	if drifts, _ := pdb.RepairLatestIndexes(false); len(drifts) != 0 {
+		t.Errorf("drifts after repair = %+v", drifts)
+	}
+}
+
+func TestRepairLatestUserInfo(t *testing.T) {
+	pdb := newTestPebble(t)
+	older := &model.UserNameInfo{Name: "old", PinID: "n1i0", BlockHeight: 10, Timestamp: 1000}
+	newer := &model.UserNameInfo{Name: "new", PinID: "n2i0", BlockHeight: 20, Timestamp: 2000}
+	for _, info := range []*model.UserNameInfo{older, newer} {
+		if err := pdb.AddUserNameInfoHistoryByGlobalMetaId(info, "idq1user"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pdb.CreateOrUpdateLatestUserNameInfoByGlobalMetaId(older, "idq1user"); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err := pdb.RepairLatestIndexes(true)
+	if err != nil {
+		t.Fatalf("RepairLatestIndexes: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].Collection != collectionLatestUserNameInfoByGlobalMetaId || drifts[0].Key != "idq1user" || drifts[0].Actual != "n2i0" {
+		t.Fatalf("drifts = %+v", drifts)
+	}
+	if latest, err := pdb.GetLatestUserNameInfoByGlobalMetaId("idq1user"); err != nil || latest.Name != "new" {
+		t.Errorf("latest after repair = %+v, %v", latest, err)
+	}
+}
@@ -0,0 +1,19 @@
+package database
+
+import "testing"
+
+func TestPebbleMetaIDGenesisFirstWriteWins(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	if got, err := pdb.GetMetaIDGenesis("1Addr"); err != nil || got != "" {
+		t.Fatalf("GetMetaIDGenesis before record = %q, %v", got, err)
+	}
+	for _, pinID := range []string{"aa11i0", "bb22i0"} {
+		if err := pdb.RecordMetaIDGenesis("1Addr", pinID); err != nil {
+			t.Fatalf("RecordMetaIDGenesis(%s): %v", pinID, err)
+		}
+	}
+	if got, err := pdb.GetMetaIDGenesis("1Addr"); err != nil || got != "aa11i0" {
+		t.Errorf("GetMetaIDGenesis = %q, %v; want aa11i0", got, err)
+	}
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"log"
+	"sort"
+)
+
+// PebbleMetrics storage engine metrics of the Pebble database, for capacity
+// tuning (see database.pebble in the config)
+type PebbleMetrics struct {
+	Stores      []PebbleStoreMetrics      // Pebble instances, sorted by name
+	Collections []PebbleCollectionMetrics // Sorted by name
+	BlockCache  PebbleCacheMetrics        // Shared cache, or the sum of the per-instance caches
+}
+
+// PebbleStoreMetrics metrics of one Pebble instance: the single store
+// (indexer_kv), or one collection in the legacy layout
+type PebbleStoreMetrics struct {
+	Store                 string
+	Levels                []PebbleLevelMetrics // L0 .. L6
+	ReadAmp               int                  // Sublevels a point read may have to consult
+	CompactionDebtBytes   uint64               // Estimated bytes to compact for the LSM to be in shape
+	CompactionsInProgress int64
+	Compactions           int64 // Completed since open
+	Flushes               int64 // Memtable flushes since open
+	MemTableBytes         uint64
+	WALBytes              uint64
+	DiskUsageBytes        uint64 // sstables, WAL and obsolete files not yet deleted
+}
+
+// PebbleCollectionMetrics size of one collection
+type PebbleCollectionMetrics struct {
+	Collection     string
+	DiskUsageBytes uint64 // Estimated from the sstables overlapping the collection's keys
+}
+
+// PebbleLevelMetrics one level of an instance's LSM tree
+type PebbleLevelMetrics struct {
+	Level     int
+	Files     int64
+	SizeBytes int64
+	Score     float64 // Compaction score; a level above 1 is due for compaction
+	Sublevels int32   // Read amplification of the level (L0 may have several)
+}
+
+// PebbleCacheMetrics block cache usage
+type PebbleCacheMetrics struct {
+	SizeBytes int64
+	Blocks    int64
+	Hits      int64
+	Misses    int64
+}
+
+// PebbleMetrics returns the current metrics of every instance and collection
+func (p *PebbleDatabase) PebbleMetrics() PebbleMetrics {
+	var result PebbleMetrics
+	for i, name := range sortedKeys(p.stores) {
+		m := p.stores[name].Metrics()
+		s := PebbleStoreMetrics{
+			Store:                 name,
+			ReadAmp:               m.ReadAmp(),
+			CompactionDebtBytes:   m.Compact.EstimatedDebt,
+			CompactionsInProgress: m.Compact.NumInProgress,
+			Compactions:           m.Compact.Count,
+			Flushes:               m.Flush.Count,
+			MemTableBytes:         m.MemTable.Size,
+			WALBytes:              m.WAL.Size,
+			DiskUsageBytes:        m.DiskSpaceUsage(),
+		}
+		for level, l := range m.Levels {
+			s.Levels = append(s.Levels, PebbleLevelMetrics{
+				Level:     level,
+				Files:     l.NumFiles,
+				SizeBytes: l.Size,
+				Score:     l.Score,
+				Sublevels: l.Sublevels,
+			})
+		}
+		result.Stores = append(result.Stores, s)
+
+		// A shared cache reports the same numbers from every instance
+		if !p.sharedCache || i == 0 {
+			result.BlockCache.SizeBytes += m.BlockCache.Size
+			result.BlockCache.Blocks += m.BlockCache.Count
+			result.BlockCache.Hits += m.BlockCache.Hits
+			result.BlockCache.Misses += m.BlockCache.Misses
+		}
+	}
+
+	storeSize := make(map[string]uint64)
+	for _, s := range result.Stores {
+		storeSize[s.Store] = s.DiskUsageBytes
+	}
+	for _, name := range sortedKeys(p.collections) {
+		c := p.collections[name]
+		if len(c.prefix) == 0 {
+			// Legacy layout: the collection is the whole instance
+			result.Collections = append(result.Collections, PebbleCollectionMetrics{Collection: name, DiskUsageBytes: storeSize[name]})
+			continue
+		}
+		lower, upper := c.bounds()
+		size, err := c.db.EstimateDiskUsage(lower, upper)
+		if err != nil {
+			log.Printf("Failed to estimate disk usage of collection %s: %v", name, err)
+			continue
+		}
+		result.Collections = append(result.Collections, PebbleCollectionMetrics{Collection: name, DiskUsageBytes: size})
+	}
+	return result
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
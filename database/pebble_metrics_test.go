@@ -0,0 +1,49 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+)
+
+func TestPebbleMetrics_SharedCache(t *testing.T) {
+	dbi, err := NewPebbleDatabase(&PebbleConfig{DataDir: t.TempDir(), CacheSize: 16 << 20, MemTableSize: 8 << 20, MaxConcurrentCompactions: 2})
+	if err != nil {
+		t.Fatalf("NewPebbleDatabase: %v", err)
+	}
+	p := dbi.(*PebbleDatabase)
+	defer p.Close()
+
+	db := p.collections[collectionDomains]
+	for i := 0; i < 10; i++ {
+		if err := db.Set([]byte(fmt.Sprintf("k%d", i)), []byte("v"), pebble.Sync); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.db.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	m := p.PebbleMetrics()
+	if len(m.Stores) != 1 || m.Stores[0].Store != pebbleStoreDir {
+		t.Fatalf("stores in metrics: %+v, want the single %s", m.Stores, pebbleStoreDir)
+	}
+	if s := m.Stores[0]; len(s.Levels) != 7 || s.Levels[0].Files == 0 || s.DiskUsageBytes == 0 {
+		t.Errorf("store after a flush: %+v", s)
+	}
+	if len(m.Collections) != len(p.collections) {
+		t.Fatalf("%d collections in metrics, want %d", len(m.Collections), len(p.collections))
+	}
+	for i, c := range m.Collections {
+		if i > 0 && m.Collections[i-1].Collection >= c.Collection {
+			t.Errorf("collections not sorted: %s before %s", m.Collections[i-1].Collection, c.Collection)
+		}
+		if c.Collection == collectionDomains && c.DiskUsageBytes == 0 {
+			t.Errorf("%s after a flush: %+v", c.Collection, c)
+		}
+	}
+	if !p.sharedCache {
+		t.Error("instances do not share the configured block cache")
+	}
+}
@@ -0,0 +1,43 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPaginateFilesByTimestampDesc(t *testing.T) {
+	newFiles := func() []*model.IndexerFile {
+		var files []*model.IndexerFile
+		for i := 1; i <= 5; i++ {
+			files = append(files, &model.IndexerFile{PinID: fmt.Sprintf("pin%di0", i), Timestamp: int64(i)})
+		}
+		return files
+	}
+
+	cases := []struct {
+		cursor   int64
+		size     int
+		wantPins []string
+		wantNext int64
+		wantMore bool
+	}{
+		{0, 2, []string{"pin5i0", "pin4i0"}, 2, true},
+		{2, 2, []string{"pin3i0", "pin2i0"}, 4, true},
+		{4, 2, []string{"pin1i0"}, 5, false},
+		{0, 5, []string{"pin5i0", "pin4i0", "pin3i0", "pin2i0", "pin1i0"}, 5, false}, // A full last page has no more
+		{5, 2, nil, 5, false},
+	}
+	for _, tc := range cases {
+		page, next, hasMore := paginateFilesByTimestampDesc(newFiles(), tc.cursor, tc.size)
+		var pins []string
+		for _, f := range page {
+			pins = append(pins, f.PinID)
+		}
+		if fmt.Sprint(pins) != fmt.Sprint(tc.wantPins) || next != tc.wantNext || hasMore != tc.wantMore {
+			t.Errorf("cursor %d size %d: got %v next %d more %v, want %v next %d more %v",
+				tc.cursor, tc.size, pins, next, hasMore, tc.wantPins, tc.wantNext, tc.wantMore)
+		}
+	}
+}
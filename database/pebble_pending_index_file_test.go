@@ -6,7 +6,7 @@ import (
 	"meta-file-system/model"
 )
 
-func newTestPebble(t *testing.T) *PebbleDatabase {
+func newTestPebble(t testing.TB) *PebbleDatabase {
 	t.Helper()
 	dbi, err := NewPebbleDatabase(&PebbleConfig{DataDir: t.TempDir()})
 	if err != nil {
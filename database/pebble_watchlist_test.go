@@ -0,0 +1,130 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestPebbleWatchlistCRUD(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	for _, target := range []string{"addr1", "meta1", "addr1"} {
+		if err := pdb.CreateWatch(&model.IndexerWatch{Target: target}); err != nil {
+			t.Fatalf("CreateWatch: %v", err)
+		}
+	}
+	if err := pdb.CreateWatch(&model.IndexerWatch{}); err == nil {
+		t.Error("CreateWatch with empty target: want error")
+	}
+
+	watches, err := pdb.ListWatches()
+	if err != nil {
+		t.Fatalf("ListWatches: %v", err)
+	}
+	if len(watches) != 3 || watches[0].ID != 1 || watches[2].ID != 3 || watches[1].Target != "meta1" {
+		t.Fatalf("watches = %+v", watches)
+	}
+
+	if err := pdb.DeleteWatch(2); err != nil {
+		t.Fatalf("DeleteWatch: %v", err)
+	}
+	if err := pdb.DeleteWatch(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteWatch missing = %v, want ErrNotFound", err)
+	}
+	if watches, _ = pdb.ListWatches(); len(watches) != 2 {
+		t.Errorf("after delete len = %d, want 2", len(watches))
+	}
+}
+
+func TestPebbleWatchEventsByTargetAndCursor(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	// "addr1x" shares a prefix with "addr1" and must not leak into its listing
+	for i, target := range []string{"addr1", "addr1x", "addr1", "addr2", "addr1"} {
+		if err := pdb.AddWatchEvent(&model.IndexerWatchEvent{Target: target, PinID: string(rune('a'+i)) + "i0"}); err != nil {
+			t.Fatalf("AddWatchEvent: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name    string
+		target  string
+		afterID int64
+		size    int
+		want    []int64
+	}{
+		{"all", "addr1", 0, 10, []int64{1, 3, 5}},
+		{"after cursor", "addr1", 3, 10, []int64{5}},
+		{"page size", "addr1", 0, 2, []int64{1, 3}},
+		{"prefix target", "addr1x", 0, 10, []int64{2}},
+		{"unknown", "addr3", 0, 10, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := pdb.ListWatchEvents(tc.target, tc.afterID, tc.size)
+			if err != nil {
+				t.Fatalf("ListWatchEvents: %v", err)
+			}
+			var ids []int64
+			for _, e := range events {
+				if e.Target != tc.target {
+					t.Errorf("event %d target = %s, want %s", e.ID, e.Target, tc.target)
+				}
+				ids = append(ids, e.ID)
+			}
+			if len(ids) != len(tc.want) {
+				t.Fatalf("ids = %v, want %v", ids, tc.want)
+			}
+			for i := range ids {
+				if ids[i] != tc.want[i] {
+					t.Fatalf("ids = %v, want %v", ids, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPebbleOutboxWrittenWithEvent(t *testing.T) {
+	pdb := newTestPebble(t)
+
+	event := &model.IndexerWatchEvent{Target: "meta1", PinID: "a1i0"}
+	hook := &model.IndexerOutboxEvent{Channel: model.OutboxWebhook, WatchID: 7, URL: "https://example.com/hook"}
+	push := &model.IndexerOutboxEvent{Channel: model.OutboxBroadcast}
+	if err := pdb.AddWatchEvent(event, hook, push); err != nil {
+		t.Fatalf("AddWatchEvent: %v", err)
+	}
+	// Outbox keys must not show up in event listings
+	if events, _ := pdb.ListWatchEvents("meta1", 0, 10); len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+
+	entries, err := pdb.ListOutboxEvents(0, 10)
+	if err != nil {
+		t.Fatalf("ListOutboxEvents: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != hook.ID || entries[1].Channel != model.OutboxBroadcast {
+		t.Fatalf("entries = %+v", entries)
+	}
+	var saved model.IndexerWatchEvent
+	if err := json.Unmarshal(entries[0].Event, &saved); err != nil || saved.ID != event.ID || saved.PinID != "a1i0" {
+		t.Errorf("entry event = %s (%v), want saved event", entries[0].Event, err)
+	}
+
+	entries[0].Attempts, entries[0].NextAttemptAt = 1, 100
+	if err := pdb.UpdateOutboxEvent(entries[0]); err != nil {
+		t.Fatalf("UpdateOutboxEvent: %v", err)
+	}
+	if err := pdb.DeleteOutboxEvent(push.ID); err != nil {
+		t.Fatalf("DeleteOutboxEvent: %v", err)
+	}
+	entries, _ = pdb.ListOutboxEvents(0, 10)
+	if len(entries) != 1 || entries[0].Attempts != 1 || entries[0].NextAttemptAt != 100 {
+		t.Errorf("entries after update/delete = %+v", entries)
+	}
+	if entries, _ = pdb.ListOutboxEvents(hook.ID, 10); len(entries) != 0 {
+		t.Errorf("entries after cursor = %d, want 0", len(entries))
+	}
+}
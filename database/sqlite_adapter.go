@@ -0,0 +1,85 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"meta-file-system/model"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultSQLitePath is the database file used when database.sqlite_path is empty
+const DefaultSQLitePath = "./data/meta-file-system.db"
+
+// SQLiteConfig SQLite configuration
+type SQLiteConfig struct {
+	Path string // Database file; created with its directory if missing
+}
+
+// openSQLite opens (creating if needed) a SQLite database file. WAL lets
+// readers run alongside the writer, and the busy timeout lets the indexer and
+// uploader share one file. A single connection serializes writes in-process.
+func openSQLite(path string, logLevel logger.LogLevel) (*gorm.DB, error) {
+	if path == "" {
+		path = DefaultSQLitePath
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create SQLite directory: %w", err)
+		}
+	}
+
+	dsn := path + "?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logLevel),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite %s: %w", path, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// NewSQLiteDatabase create SQLite database instance. It shares the MySQL
+// implementation; the tables of sql/indexer.sql are created on open.
+func NewSQLiteDatabase(config interface{}) (Database, error) {
+	cfg, ok := config.(*SQLiteConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid SQLite config type")
+	}
+
+	db, err := openSQLite(cfg.Path, logger.Error)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(
+		&model.IndexerFile{},
+		&model.IndexerFileChunk{},
+		&model.IndexerUserAvatar{},
+		&model.IndexerSyncStatus{},
+		&model.IndexerDailyStat{},
+		&model.IndexerCounter{},
+		&model.IndexerWatch{},
+		&model.IndexerWatchEvent{},
+		&model.IndexerOutboxEvent{},
+		&model.IndexerChangeEvent{},
+		&model.IndexerDomain{},
+	); err != nil {
+		return nil, fmt.Errorf("failed to create SQLite indexer tables: %w", err)
+	}
+
+	log.Printf("SQLite database opened successfully: %s", cfg.Path)
+
+	return &MySQLDatabase{db: db, sqlite: true}, nil
+}
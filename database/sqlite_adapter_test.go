@@ -0,0 +1,98 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func newTestSQLite(t testing.TB) *MySQLDatabase {
+	t.Helper()
+	db, err := NewSQLiteDatabase(&SQLiteConfig{Path: filepath.Join(t.TempDir(), "indexer.db")})
+	if err != nil {
+		t.Fatalf("NewSQLiteDatabase: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db.(*MySQLDatabase)
+}
+
+func TestSQLitePathFilterMatchesLiterally(t *testing.T) {
+	db := newTestSQLite(t)
+	for _, f := range []*model.IndexerFile{
+		{PinID: "a1i0", Path: "/file/my_dir/a.png", CreatorMetaId: "meta1", Status: model.StatusSuccess, Timestamp: 1},
+		{PinID: "b1i0", Path: "/file/myXdir/b.png", CreatorMetaId: "meta1", Status: model.StatusSuccess, Timestamp: 2},
+	} {
+		if err := db.CreateIndexerFile(f); err != nil {
+			t.Fatalf("CreateIndexerFile(%s): %v", f.PinID, err)
+		}
+	}
+
+	files, _, _, err := db.GetIndexerFilesByCreatorMetaIDWithCursor("meta1", model.IndexerFileFilter{PathPrefix: "/file/my_dir"}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].PinID != "a1i0" {
+		t.Errorf("files under /file/my_dir = %d, want only a1i0", len(files))
+	}
+}
+
+func TestSQLiteCountersAndDailyStats(t *testing.T) {
+	db := newTestSQLite(t)
+	if err := db.CreateIndexerFile(&model.IndexerFile{PinID: "a1i0", ChainName: "mvc", FileSize: 10, Status: model.StatusSuccess, Timestamp: 1700000000000}); err != nil {
+		t.Fatal(err)
+	}
+	// Flushed twice so the second flush updates the existing rows
+	for i := 0; i < 2; i++ {
+		db.counterDeltas.addChain(model.CounterChunks, "mvc", 1)
+		if err := db.FlushCounters(); err != nil {
+			t.Fatalf("FlushCounters: %v", err)
+		}
+	}
+	if n, _ := db.GetCounter(model.CounterChunks); n != 2 {
+		t.Errorf("chunks = %d, want 2", n)
+	}
+	if n, _ := db.GetCounter(model.CounterFiles); n != 1 {
+		t.Errorf("files = %d, want 1", n)
+	}
+
+	if err := db.IncrDailyStat(&model.IndexerDailyStat{Date: "2023-11-14", ChainName: "mvc", NewFiles: 1, Bytes: 10}); err != nil {
+		t.Fatalf("IncrDailyStat: %v", err)
+	}
+	stats, err := db.ListDailyStats("2023-11-14", "2023-11-14")
+	if err != nil || len(stats) != 1 || stats[0].NewFiles != 2 || stats[0].Bytes != 20 {
+		t.Fatalf("stats = %+v, %v; want 2 files, 20 bytes", stats, err)
+	}
+
+	if err := db.RebuildDailyStats(); err != nil {
+		t.Fatalf("RebuildDailyStats: %v", err)
+	}
+	stats, _ = db.ListDailyStats("2023-11-14", "2023-11-14")
+	if len(stats) != 1 || stats[0].NewFiles != 1 || stats[0].Bytes != 10 {
+		t.Errorf("rebuilt stats = %+v; want 1 file, 10 bytes", stats)
+	}
+}
+
+func TestSQLiteOutboxWrittenWithEvent(t *testing.T) {
+	db := newTestSQLite(t)
+	event := &model.IndexerWatchEvent{Target: "meta1", PinID: "a1i0"}
+	hook := &model.IndexerOutboxEvent{Channel: model.OutboxWebhook, WatchID: 7, URL: "https://example.com/hook"}
+	if err := db.AddWatchEvent(event, hook); err != nil {
+		t.Fatalf("AddWatchEvent: %v", err)
+	}
+
+	entries, err := db.ListOutboxEvents(0, 10)
+	if err != nil || len(entries) != 1 || entries[0].ID != hook.ID {
+		t.Fatalf("entries = %+v, %v", entries, err)
+	}
+	if !strings.Contains(string(entries[0].Event), `"pin_id":"a1i0"`) {
+		t.Errorf("entry event = %s", entries[0].Event)
+	}
+	if err := db.DeleteOutboxEvent(hook.ID); err != nil {
+		t.Fatalf("DeleteOutboxEvent: %v", err)
+	}
+	if entries, _ = db.ListOutboxEvents(0, 10); len(entries) != 0 {
+		t.Errorf("entries after delete = %d", len(entries))
+	}
+}
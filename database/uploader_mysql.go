@@ -13,13 +13,27 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-// UploaderDB global GORM database instance for Uploader service (always MySQL)
+// UploaderDB global GORM database instance for Uploader service (MySQL or SQLite)
 var UploaderDB *gorm.DB
 
-// InitUploaderDB initialize Uploader database (always MySQL)
+// InitUploaderDB initialize Uploader database (MySQL, or SQLite with
+// database.uploader_type sqlite)
 func InitUploaderDB() error {
 	var err error
 
+	if DBType(conf.Cfg.Database.UploaderType) == DBTypeSQLite {
+		UploaderDB, err = openSQLite(conf.Cfg.Database.SqlitePath, logger.Error)
+		if err != nil {
+			return err
+		}
+		// No sql/uploader.sql for SQLite: create the tables here
+		if err := AutoMigrate(); err != nil {
+			return fmt.Errorf("failed to create SQLite uploader tables: %w", err)
+		}
+		log.Println("Uploader database (SQLite) opened successfully")
+		return nil
+	}
+
 	// Build DSN
 	dsn := conf.Cfg.Database.Dsn
 	if dsn == "" {
@@ -56,8 +70,18 @@ func AutoMigrate() error {
 		&model.File{},
 		&model.FileChunk{},
 		&model.Assistant{},
+		&model.FileAssistent{},
 		&model.MultipartUpload{},
 		&model.FileUploaderTask{},
+		&model.UploadCheckpoint{},
+		&model.Proof{},
+		&model.AssistentUtxo{},
+		&model.DelegatedCharge{},
+		&model.UploadIdempotency{},
+		&model.AssistentRotation{},
+		&model.HotWalletUtxo{},
+		&model.UtxoLock{},
+		&model.FileRetention{},
 	)
 }
 
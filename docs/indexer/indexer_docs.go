@@ -24,28 +24,24 @@ const docTemplateindexer = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/rescan": {
+        "/v1/admin/chains/{chain}/pause": {
             "post": {
-                "description": "Trigger asynchronous rescan of blocks within specified height range for a specific chain",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stops scanning new blocks and mempool transactions of a chain until it is resumed; the block being scanned is finished first. Rescans still run. Mempool transactions seen while paused are indexed with their blocks. The pause is kept in memory, so a restarted indexer scans again; /status reports paused chains",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Indexer Admin"
                 ],
-                "summary": "Rescan blocks",
+                "summary": "Pause chain",
                 "parameters": [
                     {
-                        "description": "Rescan request parameters",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanRequest"
-                        }
+                        "type": "string",
+                        "example": "btc",
+                        "description": "Chain name",
+                        "name": "chain",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -60,7 +56,7 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.ChainPauseResponse"
                                         }
                                     }
                                 }
@@ -82,19 +78,26 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/admin/rescan/status": {
-            "get": {
-                "description": "Get current rescan task status",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/chains/{chain}/resume": {
+            "post": {
+                "description": "Resumes scanning of a chain paused with /admin/chains/{chain}/pause. Resuming a chain that is not paused is a no-op; a pause for low disk space (indexer.disk_watchdog) is not lifted",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Indexer Admin"
                 ],
-                "summary": "Get rescan status",
+                "summary": "Resume chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "btc",
+                        "description": "Chain name",
+                        "name": "chain",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -107,13 +110,19 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanStatusResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.ChainPauseResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -123,19 +132,24 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/admin/rescan/stop": {
-            "post": {
-                "description": "Stop the current rescan task",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/counters": {
+            "get": {
+                "description": "List the counters maintained per block (files, chunks, users; total, per chain and per creator), optionally filtered by name prefix",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Indexer Admin"
                 ],
-                "summary": "Stop rescan",
+                "summary": "List counters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Counter name prefix, e.g. files:chain:",
+                        "name": "prefix",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -148,19 +162,13 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanStopResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.CountersResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -170,32 +178,22 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files": {
-            "get": {
-                "description": "Query file list with cursor pagination",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/counters/reconcile": {
+            "post": {
+                "description": "Recount files, chunks and users from the indexes and report counters that drifted from the maintained values. With fix=true the recounted values are stored",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Query file list",
+                "summary": "Reconcile counters",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Cursor",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Store the recounted values",
+                        "name": "fix",
                         "in": "query"
                     }
                 ],
@@ -211,7 +209,7 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.CountersReconcileResponse"
                                         }
                                     }
                                 }
@@ -227,44 +225,33 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/accelerate/content/latest/{firstPinId}": {
+        "/v1/admin/creator/pending": {
             "get": {
-                "description": "Redirect to OSS URL for latest file content by first PIN ID, supports preview/thumbnail/video processing",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists, oldest first, the files whose creator address could not be looked up while indexing. They are served with the fallback address until the background retrier (indexer.creator_retry) resolves it; a lookup that fails max_attempts times is dropped and the file keeps the fallback address",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get latest accelerated file content (redirect to OSS)",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "First PIN ID",
-                        "name": "firstPinId",
-                        "in": "path"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Process type: preview (640px for image), thumbnail (235px for image), video (first frame for video), empty for original",
-                        "name": "process",
-                        "in": "query"
-                    }
+                    "Indexer Admin"
                 ],
+                "summary": "List queued creator lookups",
                 "responses": {
-                    "307": {
-                        "description": "Redirect to OSS URL",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.PendingCreatorResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "500": {
@@ -276,9 +263,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/accelerate/content/{pinId}": {
-            "get": {
-                "description": "Redirect to OSS URL for file content by PIN ID, supports preview/thumbnail/video processing",
+        "/v1/admin/domains": {
+            "post": {
+                "description": "Map a domain (admin; requires indexer.admin_enabled) to a site manifest PIN (type=site; its latest version is served) or a MetaID / GlobalMetaID (type=metaid; its /file/* paths are served, /file/index.html at the root). Requests whose Host header is the domain are then served that content; point the domain's DNS at the indexer. An existing mapping of the domain is replaced",
                 "consumes": [
                     "application/json"
                 ],
@@ -286,32 +273,41 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Domains"
                 ],
-                "summary": "Get accelerated file content (redirect to OSS)",
+                "summary": "Map custom domain",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
-                        "in": "path"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Process type: preview (640px for image), thumbnail (235px for image), video (first frame for video), empty for original",
-                        "name": "process",
-                        "in": "query"
+                        "description": "Domain mapping",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerDomainRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "307": {
-                        "description": "Redirect to OSS URL",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.IndexerDomain"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -323,39 +319,37 @@ const docTemplateindexer = `{
                         }
                     }
                 }
-            }
-        },
-        "/files/content/latest/{firstPinId}": {
+            },
             "get": {
-                "description": "Get latest file content by first PIN ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List all custom domain mappings ordered by domain (admin; requires indexer.admin_enabled)",
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get latest file content",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "First PIN ID",
-                        "name": "firstPinId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Indexer Domains"
                 ],
+                "summary": "List custom domains",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerDomainListResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -363,24 +357,21 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/content/{pinId}": {
-            "get": {
-                "description": "Get file content by PIN ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/domains/{domain}": {
+            "delete": {
+                "description": "Remove the mapping of a domain (admin; requires indexer.admin_enabled); the host then falls through to the API again",
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Domains"
                 ],
-                "summary": "Get file content",
+                "summary": "Remove custom domain",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
+                        "description": "Domain",
+                        "name": "domain",
                         "in": "path",
                         "required": true
                     }
@@ -389,7 +380,13 @@ const docTemplateindexer = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "404": {
@@ -401,39 +398,22 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/creator/{address}": {
-            "get": {
-                "description": "Query file list by creator address with cursor pagination",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/latest/repair": {
+            "post": {
+                "description": "Recompute the latest version of every file (latest_file_info, from all indexed PINs) and every user info (name, avatar, bio, chat public key; by MetaID and by GlobalMetaID, from their history) and report entries that differ, e.g. after blocks arrived out of order. The latest version has the latest timestamp; ties go to the higher block height, then the larger PIN ID. With fix=true the entries are rewritten; entries whose history is gone are only reported",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Get files by creator address",
+                "summary": "Repair latest entries",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Creator address",
-                        "name": "address",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Cursor",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Rewrite the entries that drifted",
+                        "name": "fix",
                         "in": "query"
                     }
                 ],
@@ -449,7 +429,7 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.LatestRepairResponse"
                                         }
                                     }
                                 }
@@ -465,9 +445,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/extension": {
-            "get": {
-                "description": "Query file list by file extension (e.g. .jpg, .png), reverse time order; extension can be repeated for multiple. Paginate with timestamp (16-digit).",
+        "/v1/admin/rescan": {
+            "post": {
+                "description": "Trigger asynchronous rescan of blocks within specified height range for a specific chain. Fails with 400 when start_height is below the earliest block a pruned node still has (see earliest_block_height in /status).",
                 "consumes": [
                     "application/json"
                 ],
@@ -475,33 +455,18 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Get files by extension",
+                "summary": "Rescan blocks",
                 "parameters": [
                     {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
-                        "name": "extension",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Next page: 16-digit timestamp from previous response next_timestamp",
-                        "name": "timestamp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
+                        "description": "Rescan request parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -516,13 +481,19 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListByExtensionResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -532,9 +503,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/keyword/{keyword}/extension": {
+        "/v1/admin/rescan/status": {
             "get": {
-                "description": "Query file list whose file base name contains keyword in file extension(s), reverse time order; extension can be repeated. Paginate with timestamp (16-digit).",
+                "description": "Get current rescan task status",
                 "consumes": [
                     "application/json"
                 ],
@@ -542,42 +513,9 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get files by keyword and extension",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Keyword contained in file base name",
-                        "name": "keyword",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
-                        "name": "extension",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Next page: 16-digit timestamp from previous response next_timestamp",
-                        "name": "timestamp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
-                    }
+                    "Indexer Admin"
                 ],
+                "summary": "Get rescan status",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -590,7 +528,7 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListByExtensionResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanStatusResponse"
                                         }
                                     }
                                 }
@@ -606,9 +544,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/latest/{firstPinId}": {
-            "get": {
-                "description": "Query latest file details by first PIN ID",
+        "/v1/admin/rescan/stop": {
+            "post": {
+                "description": "Stop the current rescan task",
                 "consumes": [
                     "application/json"
                 ],
@@ -616,18 +554,9 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get latest file by first PIN ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "First PIN ID",
-                        "name": "firstPinId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Indexer Admin"
                 ],
+                "summary": "Stop rescan",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -640,15 +569,21 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RescanStopResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -656,39 +591,34 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/metaid/{metaidOrGlobalMetaId}": {
+        "/v1/admin/rescan/{taskId}/report": {
             "get": {
-                "description": "Query file list by creator MetaID or GlobalMetaID with cursor pagination (param is metaId or globalMetaId)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Downloads the per-block and per-PIN outcomes (indexed, skipped_duplicate, skipped, rejected, failed with reason) of the running rescan or one of the last 10 finished ones. The report is served as an attachment, as JSON or as CSV with one row per PIN and per failed block",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "text/csv"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Admin"
                 ],
-                "summary": "Get files by creator MetaID or GlobalMetaID",
+                "summary": "Download rescan report",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Creator MetaID or GlobalMetaID",
-                        "name": "metaidOrGlobalMetaId",
+                        "description": "Rescan task ID",
+                        "name": "taskId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Cursor",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
+                        "type": "string",
+                        "default": "json",
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "description": "Report format",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
@@ -696,19 +626,19 @@ const docTemplateindexer = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/meta-file-system_service_indexer_service.RescanReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "500": {
@@ -720,52 +650,16 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/metaid/{metaidOrGlobalMetaId}/extension": {
+        "/v1/admin/storage-migration": {
             "get": {
-                "description": "Query file list by globalMetaID and file extension(s), reverse time order; extension can be repeated. Paginate with timestamp (16-digit).",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Progress of copying indexed blobs to storage.migration.target. Records are flipped to the target storage type once their copy is hash-verified; when status is completed, set storage.type to the target and disable the migration",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get files by globalMetaID and extension",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Global MetaID (path segment shared with metaid route)",
-                        "name": "metaidOrGlobalMetaId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "array",
-                        "items": {
-                            "type": "string"
-                        },
-                        "collectionFormat": "csv",
-                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
-                        "name": "extension",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Next page: 16-digit timestamp from previous response next_timestamp",
-                        "name": "timestamp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
-                    }
+                    "Indexer Admin"
                 ],
+                "summary": "Get storage migration progress",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -778,7 +672,7 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListByExtensionResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.StorageMigrationResponse"
                                         }
                                     }
                                 }
@@ -794,28 +688,16 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/files/status/{pinId}": {
+        "/v1/admin/storage/pending": {
             "get": {
-                "description": "Report whether a file pin is merged / pending (on chain but not indexed yet) / not_found",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists, oldest first, the files and chunks indexed while the storage backend refused writes. Their records have status pending_storage and their content is served from the retry queue until the background retrier (indexer.storage_retry) stores it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
-                ],
-                "summary": "Get file index status by PIN ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Indexer Admin"
                 ],
+                "summary": "List files awaiting storage",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -828,19 +710,25 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_indexer_service.FileStatus"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.PendingStorageResponse"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
                     }
                 }
             }
         },
-        "/files/{pinId}": {
-            "get": {
-                "description": "Query file details by PIN ID",
+        "/v1/admin/watchlist": {
+            "post": {
+                "description": "Watch an address, MetaID or GlobalMetaID (admin; requires indexer.admin_enabled). Every PIN it creates is recorded as an event (when seen and again when confirmed), POSTed to webhook_url if set, and pushed to WebSocket subscribers. webhook_url must resolve to a public address; each client IP may hold up to 100 watches, out of 10000 in total",
                 "consumes": [
                     "application/json"
                 ],
@@ -848,16 +736,18 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer File Query"
+                    "Indexer Watchlist"
                 ],
-                "summary": "Get file by PIN ID",
+                "summary": "Add watchlist entry",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
-                        "in": "path",
-                        "required": true
+                        "description": "Watch request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerWatchRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -872,15 +762,21 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                                            "$ref": "#/definitions/model.IndexerWatch"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -888,24 +784,21 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/info/address/{address}": {
-            "get": {
-                "description": "Query user information in MetaID format by address",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/watchlist/{id}": {
+            "delete": {
+                "description": "Remove a watchlist entry by ID (admin; requires indexer.admin_enabled); events already recorded are kept",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer Watchlist"
                 ],
-                "summary": "Get MetaID user info by address",
+                "summary": "Remove watchlist entry",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Address",
-                        "name": "address",
+                        "type": "integer",
+                        "description": "Watch ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -914,19 +807,13 @@ const docTemplateindexer = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "404": {
@@ -938,26 +825,30 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/info/globalmetaid/{globalMetaID}": {
+        "/v1/changes": {
             "get": {
-                "description": "Query user information in MetaID format by address",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Ordered log of indexing actions (file_created, file_modified, file_revoked, file_confirmed, chunk_indexed, chunks_merged, user_name_updated, avatar_updated, user_bio_updated, chat_public_key_updated, profile_changed). Each event carries the written record. Poll with since = next_since to receive only new events",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer Changes"
                 ],
-                "summary": "Get MetaID user info by Global MetaID",
+                "summary": "Change feed",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Global MetaID",
-                        "name": "globalMetaID",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Return events with seq greater than since",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size (max 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -972,15 +863,21 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerChangeListResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -988,49 +885,46 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/info/metaid/{metaidOrGlobalMetaId}": {
+        "/v1/feed/atom": {
             "get": {
-                "description": "Query user information in MetaID format by MetaID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Atom 1.0 feed of the newest indexed public (unencrypted) files, optionally filtered by creator or file type",
                 "produces": [
-                    "application/json"
+                    "application/xml"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer Feed"
                 ],
-                "summary": "Get MetaID user info by MetaID",
+                "summary": "Atom feed of new files",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "MetaID",
-                        "name": "metaid",
-                        "in": "path",
-                        "required": true
+                        "description": "Creator MetaID or GlobalMetaID",
+                        "name": "creator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File type: image/video/audio/document/other",
+                        "name": "file_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Number of items (max 100)",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Atom document",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1038,65 +932,46 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/info/search": {
+        "/v1/feed/rss": {
             "get": {
-                "description": "Fuzzy search user information by keyword and keytype (metaid or name)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "RSS 2.0 feed of the newest indexed public (unencrypted) files, optionally filtered by creator or file type",
                 "produces": [
-                    "application/json"
+                    "application/xml"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer Feed"
                 ],
-                "summary": "Search MetaID user info (fuzzy)",
+                "summary": "RSS feed of new files",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search keyword (partial MetaID or Name)",
-                        "name": "keyword",
-                        "in": "query",
-                        "required": true
+                        "description": "Creator MetaID or GlobalMetaID",
+                        "name": "creator",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Key type: metaid (fuzzy) or name (fuzzy)",
-                        "name": "keytype",
-                        "in": "query",
-                        "required": true
+                        "description": "File type: image/video/audio/document/other",
+                        "name": "file_type",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Result limit (default: 10, max: 100)",
-                        "name": "limit",
+                        "default": 50,
+                        "description": "Number of items (max 100)",
+                        "name": "size",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "RSS document",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
-                                            }
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1104,9 +979,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/pins/{pinId}": {
+        "/v1/files": {
             "get": {
-                "description": "Query PIN details from collectionPinInfo by PIN ID",
+                "description": "Query file list with cursor pagination",
                 "consumes": [
                     "application/json"
                 ],
@@ -1114,16 +989,36 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer PIN Query"
+                    "Indexer File Query"
                 ],
-                "summary": "Get PIN info by PIN ID",
+                "summary": "Query file list",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "PIN ID",
-                        "name": "pinId",
-                        "in": "path",
-                        "required": true
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1138,15 +1033,15 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerPinInfoResponse"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1154,9 +1049,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/stats": {
+        "/v1/files/accelerate/content/latest/{firstPinId}": {
             "get": {
-                "description": "Get indexer statistics (total files count and per-chain breakdown)",
+                "description": "Redirect to OSS URL for latest file content by first PIN ID, supports preview/thumbnail/video processing",
                 "consumes": [
                     "application/json"
                 ],
@@ -1164,26 +1059,34 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer Status"
+                    "Indexer File Query"
+                ],
+                "summary": "Get latest accelerated file content (redirect to OSS)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First PIN ID",
+                        "name": "firstPinId",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Process type: preview (640px for image), thumbnail (235px for image), video (first frame for video), empty for original",
+                        "name": "process",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get statistics",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "307": {
+                        "description": "Redirect to OSS URL",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerStatsResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "500": {
@@ -1193,42 +1096,34 @@ const docTemplateindexer = `{
                         }
                     }
                 }
-            }
-        },
-        "/status": {
-            "get": {
-                "description": "Get current sync status for all chains (current sync height and latest block height)",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "head": {
+                "description": "Availability probe for GET /files/accelerate/content/latest/{firstPinId}: answers 200 with the content headers of the latest version instead of the 307 redirect",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Indexer Status"
+                    "Indexer File Query"
+                ],
+                "summary": "Probe latest accelerated file content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First PIN ID",
+                        "name": "firstPinId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get sync status",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Headers only: Content-Type, Content-Disposition, Content-Length",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerMultiChainSyncStatusResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1236,9 +1131,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/thumbnail/{pinId}": {
+        "/v1/files/accelerate/content/{pinId}": {
             "get": {
-                "description": "Redirect to OSS URL for avatar thumbnail (128x128) by avatar PIN ID using OSS built-in thumbnail processing",
+                "description": "Redirect to OSS URL for file content by PIN ID, supports preview/thumbnail/video processing",
                 "consumes": [
                     "application/json"
                 ],
@@ -1246,21 +1141,26 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get avatar thumbnail (redirect to OSS)",
+                "summary": "Get accelerated file content (redirect to OSS)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Avatar PIN ID",
+                        "description": "PIN ID",
                         "name": "pinId",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "307": {
-                        "description": "Redirect to OSS URL with thumbnail processing",
+                        "in": "path"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Process type: preview (640px for image), thumbnail (235px for image), video (first frame for video), empty for original",
+                        "name": "process",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Redirect to OSS URL",
                         "schema": {
                             "type": "string"
                         }
@@ -1278,54 +1178,103 @@ const docTemplateindexer = `{
                         }
                     }
                 }
+            },
+            "head": {
+                "description": "Availability probe for GET /files/accelerate/content/{pinId}: answers 200 with the content headers instead of the 307 redirect; not-indexed PINs get the GET handler's not-found response",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Probe accelerated file content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Headers only: Content-Type, Content-Disposition, Content-Length",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
             }
         },
-        "/users": {
+        "/v1/files/archive": {
             "get": {
-                "description": "Query user info list with cursor pagination",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Streams a zip or tar.gz archive of the files selected by exactly one source: pin_ids (comma-separated or repeated; stored under their file names), manifest (a site manifest PIN; stored under their site paths) or metaid with path (a creator's directory as in the path tree; stored under their paths below it, latest versions only). Archives hold at most 1000 files. Files are read one at a time while the archive is written, so a storage error part way ends the download with a truncated archive",
                 "produces": [
-                    "application/json"
+                    "application/zip",
+                    "application/gzip"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Query user info list",
+                "summary": "Download files as an archive",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Cursor",
-                        "name": "cursor",
+                        "type": "string",
+                        "default": "zip",
+                        "description": "Archive format: zip or tar.gz",
+                        "name": "format",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
+                        "type": "string",
+                        "description": "PIN IDs, comma-separated",
+                        "name": "pin_ids",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Site manifest PIN ID",
+                        "name": "manifest",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Creator MetaID or GlobalMetaID (with path)",
+                        "name": "metaid",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "/",
+                        "description": "Directory of metaid to archive, e.g. /file/photos",
+                        "name": "path",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Archive stream",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.UserInfoListResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "A file or the manifest is not indexed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "500": {
@@ -1337,9 +1286,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/users/address/{address}": {
-            "get": {
-                "description": "Query user information (name, avatar, chat public key) by address",
+        "/v1/files/batch": {
+            "post": {
+                "description": "Query file details for up to 100 PIN IDs at once. Files are returned in request order; PIN IDs with no indexed file are listed in missing.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1347,16 +1296,24 @@ const docTemplateindexer = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get user info by address",
+                "summary": "Get files by PIN IDs (batch)",
                 "parameters": [
+                    {
+                        "description": "PIN IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileBatchRequest"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "path",
-                        "required": true
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1371,15 +1328,15 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.IndexerUserInfo"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileBatchResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1387,39 +1344,33 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/users/avatar/accelerate/{pinId}": {
+        "/v1/files/content/latest/{firstPinId}": {
             "get": {
-                "description": "Redirect to OSS URL for avatar content by avatar PIN ID, supports preview/thumbnail processing",
+                "description": "Get latest file content by first PIN ID",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get accelerated avatar content (redirect to OSS)",
+                "summary": "Get latest file content",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Avatar PIN ID",
-                        "name": "pinId",
+                        "description": "First PIN ID",
+                        "name": "firstPinId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Process type: preview (640px), thumbnail (128px), empty for original",
-                        "name": "process",
-                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "307": {
-                        "description": "Redirect to OSS URL",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "file"
                         }
                     },
                     "404": {
@@ -1427,9 +1378,36 @@ const docTemplateindexer = `{
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
+                    }
+                }
+            },
+            "head": {
+                "description": "Headers of GET /files/content/latest/{firstPinId} (latest version) without the body; not-indexed PINs get the GET handler's not-found response",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Probe latest file content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First PIN ID",
+                        "name": "firstPinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Headers only: Content-Type, Content-Disposition, Content-Length",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1437,9 +1415,9 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/users/avatar/content/{pinId}": {
+        "/v1/files/content/{pinId}": {
             "get": {
-                "description": "Get specific avatar version content by avatar PIN ID",
+                "description": "Get file content by PIN ID. A blob missing on this gateway is fetched from its peers (indexer.peers), checked against the file hash and cached; requests with the X-Mfs-Peer-Fetch header are answered from local storage only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1447,21 +1425,27 @@ const docTemplateindexer = `{
                     "application/octet-stream"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get avatar content by PIN ID",
+                "summary": "Get file content",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Avatar PIN ID",
+                        "description": "PIN ID",
                         "name": "pinId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set by peer gateways: do not ask other peers",
+                        "name": "X-Mfs-Peer-Fetch",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Avatar content",
+                        "description": "OK",
                         "schema": {
                             "type": "file"
                         }
@@ -1473,47 +1457,30 @@ const docTemplateindexer = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/history/{key}": {
-            "get": {
-                "description": "Get all user info history (name, avatar, chat public key) by MetaID or Address",
-                "consumes": [
-                    "application/json"
-                ],
+            },
+            "head": {
+                "description": "Headers of GET /files/content/{pinId} without the body and without reading storage; not-indexed PINs get the GET handler's not-found response",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get user info history",
+                "summary": "Probe file content",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "MetaID or Address",
-                        "name": "key",
+                        "description": "PIN ID",
+                        "name": "pinId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Headers only: Content-Type, Content-Disposition, Content-Length",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/model.UserInfoHistory"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "string"
                         }
                     },
                     "404": {
@@ -1521,36 +1488,33 @@ const docTemplateindexer = `{
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                        }
                     }
                 }
             }
         },
-        "/users/metaid/{metaId}": {
+        "/v1/files/count": {
             "get": {
-                "description": "Query user information (name, avatar, chat public key) by MetaID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Total number of indexed files with per-chain counts, or the count of one chain, plus the page count for size. Served from maintained counters (no scan)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get user info by MetaID",
+                "summary": "Get file count",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "MetaID",
-                        "name": "metaId",
-                        "in": "path",
-                        "required": true
+                        "description": "Chain name: btc/mvc/doge; omit for the total",
+                        "name": "chain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size used to compute pages",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1565,15 +1529,15 @@ const docTemplateindexer = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.IndexerUserInfo"
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileCountResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -1581,39 +1545,295 @@ const docTemplateindexer = `{
                 }
             }
         },
-        "/users/metaid/{metaId}/avatar": {
+        "/v1/files/creator/{address}": {
             "get": {
-                "description": "Get avatar content by user MetaID, returns content from storage or redirects to OSS",
+                "description": "Query file list by creator address with cursor pagination",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/octet-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "Indexer User Info"
+                    "Indexer File Query"
                 ],
-                "summary": "Get avatar content by MetaID",
+                "summary": "Get files by creator address",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User MetaID",
-                        "name": "metaId",
+                        "description": "Creator address, or an ID address for the creator's files on every chain",
+                        "name": "address",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
                     "200": {
-                        "description": "Avatar content",
+                        "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "307": {
-                        "description": "Redirect to OSS URL",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/extension": {
+            "get": {
+                "description": "Query file list by file extension (e.g. .jpg, .png), reverse time order; extension can be repeated for multiple. Paginate with timestamp (16-digit).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by extension",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
+                        "name": "extension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Next page: 16-digit timestamp from previous response next_timestamp",
+                        "name": "timestamp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListByExtensionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/keyword/{keyword}/extension": {
+            "get": {
+                "description": "Query file list whose file base name contains keyword in file extension(s), reverse time order; extension can be repeated. Paginate with timestamp (16-digit).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by keyword and extension",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Keyword contained in file base name",
+                        "name": "keyword",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
                             "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
+                        "name": "extension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Next page: 16-digit timestamp from previous response next_timestamp",
+                        "name": "timestamp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListByExtensionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/latest/{firstPinId}": {
+            "get": {
+                "description": "Query latest file details by first PIN ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get latest file by first PIN ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First PIN ID",
+                        "name": "firstPinId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "404": {
@@ -1624,490 +1844,4151 @@ const docTemplateindexer = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "meta-file-system_controller_respond.IndexerFileListByExtensionResponse": {
+        },
+        "/v1/files/metaid/{metaidOrGlobalMetaId}": {
+            "get": {
+                "description": "Query file list by creator MetaID or GlobalMetaID with cursor pagination (param is metaId or globalMetaId)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by creator MetaID or GlobalMetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator MetaID or GlobalMetaID",
+                        "name": "metaidOrGlobalMetaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File type: image/video/audio/document/other",
+                        "name": "file_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type prefix, e.g. image/ or image/png",
+                        "name": "content_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID path prefix, matched on whole segments, e.g. /file/photos",
+                        "name": "path_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain name: btc/mvc/doge",
+                        "name": "chain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/metaid/{metaidOrGlobalMetaId}/count": {
+            "get": {
+                "description": "Number of files of a creator (as listed by /files/metaid/{metaidOrGlobalMetaId} without filters) plus the page count for size. Served from maintained counters (no scan)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get file count by creator MetaID or GlobalMetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator MetaID or GlobalMetaID",
+                        "name": "metaidOrGlobalMetaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size used to compute pages",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileCountResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/metaid/{metaidOrGlobalMetaId}/extension": {
+            "get": {
+                "description": "Query file list by globalMetaID and file extension(s), reverse time order; extension can be repeated. Paginate with timestamp (16-digit).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by globalMetaID and extension",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Global MetaID (path segment shared with metaid route)",
+                        "name": "metaidOrGlobalMetaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
+                        "name": "extension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Next page: 16-digit timestamp from previous response next_timestamp",
+                        "name": "timestamp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileListByExtensionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/metaid/{metaidOrGlobalMetaId}/tree": {
+            "get": {
+                "description": "Virtual filesystem view of a creator's files built from their MetaID paths: the subdirectories of path with per-directory file counts and total sizes, and the files directly in path. A path ending in the file name places the file in its parent directory. Only the latest version of each file is shown and revoked files are left out; truncated is set when the subtree has more files than the view reads (counts are then partial)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Path tree of a creator's files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator MetaID or GlobalMetaID",
+                        "name": "metaidOrGlobalMetaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "/",
+                        "description": "Directory to list, e.g. /file/photos",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerPathTreeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/status/{pinId}": {
+            "get": {
+                "description": "Report whether a file pin is merged / pending (on chain but not indexed yet) / rejected (content refused, with reason) / pending_storage (indexed, storage write queued for retry, with the storage error) / not_found",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get file index status by PIN ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_indexer_service.FileStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/{pinId}": {
+            "get": {
+                "description": "Query file details by PIN ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get file by PIN ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/{pinId}/chunks": {
+            "get": {
+                "description": "List every chunk of the multi-chunk file indexed by pinId (the metafile/index PIN) in chunkList order: PIN ID, index, offset, size, sha256, whether the chunk is indexed and confirmed, and whether its stored hash matches. Also reports the merge status and whether the merged file exists in storage. Works while the merge is still waiting for chunks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "List chunks of a multi-chunk file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Index PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_indexer_service.FileChunksResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Not a multi-chunk file",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Index not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/info/address/{address}": {
+            "get": {
+                "description": "Query user information in MetaID format by address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get MetaID user info by address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address (native or ID address)",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/info/globalmetaid/{globalMetaID}": {
+            "get": {
+                "description": "Query user information in MetaID format by address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get MetaID user info by Global MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Global MetaID",
+                        "name": "globalMetaID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/info/metaid/{metaidOrGlobalMetaId}": {
+            "get": {
+                "description": "Query user information in MetaID format by MetaID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get MetaID user info by MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/info/search": {
+            "get": {
+                "description": "Fuzzy search user information by keyword and keytype (metaid or name)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Search MetaID user info (fuzzy)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search keyword (partial MetaID or Name)",
+                        "name": "keyword",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Key type: metaid (fuzzy) or name (fuzzy)",
+                        "name": "keytype",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Result limit (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/metaid/derive": {
+            "get": {
+                "description": "MetaID and GlobalMetaID of an address as this deployment derives them (metaid.derivation): address_sha256 hashes the address, genesis_txid uses the txid of the first PIN indexed for the address. Under genesis_txid an address without indexed PINs has no MetaID yet (404)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Derive the MetaID of an address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Native chain address (ID addresses are rejected)",
+                        "name": "address",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDDeriveResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/peers": {
+            "get": {
+                "description": "List the peer gateways this indexer asks for content it lacks (indexer.peers), configured ones first, with the outcome of the last requests. Other gateways read this list to discover peers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "List peer gateways",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerPeerListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/pins/{pinId}": {
+            "get": {
+                "description": "Query PIN details from collectionPinInfo by PIN ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer PIN Query"
+                ],
+                "summary": "Get PIN info by PIN ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerPinInfoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/resolve": {
+            "get": {
+                "description": "Resolve a content-addressable mfs://{pinId} or mfs://{sha256} URI. A sha256 URI names content rather than a PIN and resolves to the earliest indexed PIN with that file sha256. mode=content (default) returns the bytes with an immutable Cache-Control and the sha256 as ETag, mode=redirect answers 307 to /files/content/{pinId}, mode=json returns the resolved file.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream",
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Resolve mfs:// URI",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "mfs://{pinId} or mfs://{sha256}",
+                        "name": "uri",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "content",
+                        "description": "content, redirect or json",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "mode=json; other modes return the file bytes",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.MfsResolveResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "307": {
+                        "description": "mode=redirect: redirect to the content URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/sitemap.xml": {
+            "get": {
+                "description": "sitemap.xml listing the newest indexed public files (up to 1000) for explorer frontends; also served at /sitemap.xml",
+                "produces": [
+                    "application/xml"
+                ],
+                "tags": [
+                    "Indexer Feed"
+                ],
+                "summary": "Sitemap",
+                "responses": {
+                    "200": {
+                        "description": "Sitemap document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/stats": {
+            "get": {
+                "description": "Get indexer statistics (total files count and per-chain breakdown)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "Get statistics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/stats/daily": {
+            "get": {
+                "description": "Get per-day counts of new files, new users and bytes indexed (with per-chain breakdown) for an inclusive UTC date range; days without activity are zero-filled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "Get daily statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start day (YYYY-MM-DD, UTC); defaults to 30 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End day (YYYY-MM-DD, UTC); defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerDailyStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/stats/weekly": {
+            "get": {
+                "description": "Get per-week (ISO week, Monday to Sunday, UTC) counts of new files, new users and bytes indexed (with per-chain breakdown); the range is widened to whole weeks and weeks without activity are zero-filled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "Get weekly statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start day (YYYY-MM-DD, UTC), moved back to its Monday; defaults to 12 weeks before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End day (YYYY-MM-DD, UTC), moved forward to its Sunday; defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerWeeklyStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/status": {
+            "get": {
+                "description": "Get current sync status for all chains (current sync height, latest block height, and for pruned nodes the earliest block that can be rescanned, and why scanning is paused), with the PIN parser mode, experimental features and the PINs accepted, warned about and rejected per mode since startup",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Status"
+                ],
+                "summary": "Get sync status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerMultiChainSyncStatusResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/thumbnail/{pinId}": {
+            "get": {
+                "description": "Redirect to OSS URL for avatar thumbnail (128x128) by avatar PIN ID using OSS built-in thumbnail processing",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get avatar thumbnail (redirect to OSS)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Avatar PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Redirect to OSS URL with thumbnail processing",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users": {
+            "get": {
+                "description": "Query user info list with cursor pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Query user info list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UserInfoListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/address/{address}": {
+            "get": {
+                "description": "Query user information (name, avatar, chat public key) by address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get user info by address",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address (native or ID address)",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.IndexerUserInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/avatar/accelerate/{pinId}": {
+            "get": {
+                "description": "Redirect to OSS URL for avatar content by avatar PIN ID, supports preview/thumbnail processing",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get accelerated avatar content (redirect to OSS)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Avatar PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Process type: preview (640px), thumbnail (128px), empty for original",
+                        "name": "process",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Redirect to OSS URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/avatar/content/{pinId}": {
+            "get": {
+                "description": "Get specific avatar version content by avatar PIN ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get avatar content by PIN ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Avatar PIN ID",
+                        "name": "pinId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Avatar content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/history/{key}": {
+            "get": {
+                "description": "Get all user info history (name, avatar, chat public key) by MetaID or Address",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get user info history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MetaID or Address",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.UserInfoHistory"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/metaid/{metaId}": {
+            "get": {
+                "description": "Query user information (name, avatar, chat public key) by MetaID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get user info by MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.IndexerUserInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/metaid/{metaId}/avatar": {
+            "get": {
+                "description": "Get avatar content by user MetaID, returns content from storage or redirects to OSS",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Get avatar content by MetaID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User MetaID",
+                        "name": "metaId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Avatar content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "307": {
+                        "description": "Redirect to OSS URL",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist": {
+            "get": {
+                "description": "List watchlist entries, optionally only those of one target",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Watchlist"
+                ],
+                "summary": "List watchlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address, MetaID or GlobalMetaID",
+                        "name": "target",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerWatchListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/events": {
+            "get": {
+                "description": "Events (PINs) of a watched address, MetaID or GlobalMetaID, oldest first. Poll with cursor = next_cursor to receive only new events",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Watchlist"
+                ],
+                "summary": "List watch events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address, MetaID or GlobalMetaID",
+                        "name": "target",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Return events with ID greater than cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerWatchEventListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/watchlist/ws": {
+            "get": {
+                "description": "Upgrade to a WebSocket that receives a JSON model.IndexerWatchEvent for every new PIN of the given targets (comma-separated or repeated). Targets need not be on the watchlist; events of unwatched targets are streamed but not recorded",
+                "tags": [
+                    "Indexer Watchlist"
+                ],
+                "summary": "Watch events WebSocket",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Addresses, MetaIDs or GlobalMetaIDs",
+                        "name": "target",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/model.IndexerWatchEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/changes": {
+            "get": {
+                "description": "Ordered log of indexing actions as a v2 page (see GET /v1/changes). Poll with cursor = nextCursor to receive only new events",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Changes"
+                ],
+                "summary": "Change feed (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size (max 1000)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerChangePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files": {
+            "get": {
+                "description": "All indexed files, newest first, as a v2 page; the total file count is included with includeUnconfirmed=true",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Query file list (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/creator/{address}": {
+            "get": {
+                "description": "Files of a creator address, newest first, as a v2 page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by creator address (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator address, or an ID address for the creator's files on every chain",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/extension": {
+            "get": {
+                "description": "Files with the given extension(s), newest first, as a v2 page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by extension (v2)",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
+                        "name": "extension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/keyword/{keyword}/extension": {
+            "get": {
+                "description": "Files whose base name contains keyword, with the given extension(s), newest first, as a v2 page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by keyword and extension (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Keyword contained in file base name",
+                        "name": "keyword",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
+                        "name": "extension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/metaid/{metaidOrGlobalMetaId}": {
+            "get": {
+                "description": "Files of a creator MetaID or GlobalMetaID, newest first, as a v2 page; same filters as the v1 route",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by creator MetaID or GlobalMetaID (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Creator MetaID or GlobalMetaID",
+                        "name": "metaidOrGlobalMetaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File type: image/video/audio/document/other",
+                        "name": "file_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type prefix, e.g. image/ or image/png",
+                        "name": "content_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID path prefix, matched on whole segments, e.g. /file/photos",
+                        "name": "path_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain name: btc/mvc/doge",
+                        "name": "chain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/metaid/{metaidOrGlobalMetaId}/extension": {
+            "get": {
+                "description": "Files of a globalMetaID with the given extension(s), newest first, as a v2 page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer File Query"
+                ],
+                "summary": "Get files by globalMetaID and extension (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Global MetaID",
+                        "name": "metaidOrGlobalMetaId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "File extension(s), supports multi (extension=.jpg\u0026extension=.png) and csv (extension=.jpg,.png)",
+                        "name": "extension",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Also list mempool-only (unconfirmed) files",
+                        "name": "includeUnconfirmed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each file to return (sparse fieldset), e.g. pin_id,file_name,content_url",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/users": {
+            "get": {
+                "description": "Users ordered by latest activity, as a v2 page with the total user count",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer User Info"
+                ],
+                "summary": "Query user info list (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated fields of each user to return (sparse fieldset), e.g. globalMetaId,name,avatar",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UserInfoPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/watchlist/events": {
+            "get": {
+                "description": "Events (PINs) of a watched address, MetaID or GlobalMetaID, oldest first, as a v2 page. Poll with cursor = nextCursor to receive only new events",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Indexer Watchlist"
+                ],
+                "summary": "List watch events (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address, MetaID or GlobalMetaID",
+                        "name": "target",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerWatchEventPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "meta-file-system_controller_respond.ChainPauseResponse": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "btc"
+                },
+                "paused": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "paused_at": {
+                    "description": "Unix seconds",
+                    "type": "integer",
+                    "example": 1699999999
+                }
+            }
+        },
+        "meta-file-system_controller_respond.CountersReconcileResponse": {
+            "type": "object",
+            "properties": {
+                "drifts": {
+                    "description": "Counters whose stored value differs from the recount",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CounterDrift"
+                    }
+                },
+                "fixed": {
+                    "description": "Whether the recounted values were stored",
+                    "type": "boolean"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.CountersResponse": {
+            "type": "object",
+            "properties": {
+                "counters": {
+                    "description": "e.g. files, files:chain:mvc, chunks, users:chain:btc",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerChangeListResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerChangeEvent"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "next_since": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerChangePage": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerChangeEvent"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Opaque; poll with it for new events",
+                    "type": "string",
+                    "example": "1200"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerDailyStatCounts": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 10485760
+                },
+                "new_files": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "new_users": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerDailyStatItem": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 10485760
+                },
+                "chains": {
+                    "description": "Per-chain breakdown",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerDailyStatCounts"
+                    }
+                },
+                "date": {
+                    "description": "UTC day",
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "new_files": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "new_users": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerDailyStatsResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "One entry per day in [from, to], zero-filled",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerDailyStatItem"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-01-30"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerDomainListResponse": {
+            "type": "object",
+            "properties": {
+                "domains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerDomain"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerDomainRequest": {
+            "type": "object",
+            "properties": {
+                "domain": {
+                    "description": "Host name whose DNS points at the indexer",
+                    "type": "string",
+                    "example": "mysite.example.com"
+                },
+                "target": {
+                    "description": "Site manifest PIN ID (site) or MetaID / GlobalMetaID (metaid)",
+                    "type": "string",
+                    "example": "4f2a9c1b8e7d6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1fi0"
+                },
+                "type": {
+                    "description": "site or metaid",
+                    "type": "string",
+                    "example": "site"
+                }
+            },
+            "required": [
+                "domain",
+                "target",
+                "type"
+            ]
+        },
+        "meta-file-system_controller_respond.IndexerFileBatchRequest": {
+            "type": "object",
+            "required": [
+                "pin_ids"
+            ],
+            "properties": {
+                "pin_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abc123def456i0",
+                        "abc123def789i0"
+                    ]
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerFileBatchResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                    }
+                },
+                "missing": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abc123def789i0"
+                    ]
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerFileCountResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "description": "Per-chain counts (global count only)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "pages": {
+                    "type": "integer",
+                    "example": 618
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 12345
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerFileListByExtensionResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "next_timestamp": {
+                    "type": "string",
+                    "example": "1699123456082917"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerFileListResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "next_cursor": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerFilePage": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Opaque; empty when there is no next page",
+                    "type": "string",
+                    "example": "20"
+                },
+                "total": {
+                    "description": "Matching records, when counted",
+                    "type": "integer",
+                    "example": 12345
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerFileResponse": {
+            "type": "object",
+            "properties": {
+                "accelerate_content_url": {
+                    "description": "下载/加速链接 baseUrl + /api/v1/accelerate/content/:pinId",
+                    "type": "string",
+                    "example": "https://example.com/api/v1/accelerate/content/abc123i0"
+                },
+                "block_height": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "chain_name": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "confirmations": {
+                    "description": "Blocks indexed from the file's block on, 0 in mempool",
+                    "type": "integer",
+                    "example": 6
+                },
+                "confirmed": {
+                    "description": "In a block; false for mempool-only PINs, which a reorg or double spend may still drop",
+                    "type": "boolean",
+                    "example": true
+                },
+                "confirmed_at": {
+                    "description": "Confirming block time (ms), 0 while unconfirmed",
+                    "type": "integer",
+                    "example": 1699999999000
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "content_url": {
+                    "description": "预览链接 baseUrl + /api/v1/content/:pinId",
+                    "type": "string",
+                    "example": "https://example.com/api/v1/content/abc123i0"
+                },
+                "creator_address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "creator_global_meta_id": {
+                    "type": "string",
+                    "example": "idaddress..."
+                },
+                "creator_id_address": {
+                    "description": "ID address of creator_address (indexer.render_id_address)",
+                    "type": "string",
+                    "example": "idq1..."
+                },
+                "creator_meta_id": {
+                    "type": "string",
+                    "example": "abc123def456..."
+                },
+                "encryption": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "file_extension": {
+                    "type": "string",
+                    "example": ".jpg"
+                },
+                "file_hash": {
+                    "type": "string",
+                    "example": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+                },
+                "file_md5": {
+                    "type": "string",
+                    "example": "d41d8cd98f00b204e9800998ecf8427e"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "test.jpg"
+                },
+                "file_size": {
+                    "type": "integer",
+                    "example": 102400
+                },
+                "file_type": {
+                    "type": "string",
+                    "example": "image"
+                },
+                "first_seen_at": {
+                    "description": "First time the PIN was observed (ms)",
+                    "type": "integer",
+                    "example": 1699999999000
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "owner_address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "owner_id_address": {
+                    "description": "ID address of owner_address (indexer.render_id_address)",
+                    "type": "string",
+                    "example": "idq1..."
+                },
+                "owner_meta_id": {
+                    "type": "string",
+                    "example": "abc123def456..."
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file/test.jpg"
+                },
+                "pin_id": {
+                    "description": "ID             int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
+                    "type": "string",
+                    "example": "abc123def456i0"
+                },
+                "provisional": {
+                    "description": "Fewer confirmations than the chain requires (indexer.confirmations.min); content may be refused until then",
+                    "type": "boolean",
+                    "example": false
+                },
+                "resolution_pending": {
+                    "description": "Creator is the fallback address until the creator input lookup is retried",
+                    "type": "boolean",
+                    "example": false
+                },
+                "storage_path": {
+                    "description": "StorageType    string    ` + "`" + `json:\"storage_type\" example:\"oss\"` + "`" + `",
+                    "type": "string",
+                    "example": "indexer/mvc/pinid123i0.jpg"
+                },
+                "storage_receipt": {
+                    "description": "Receipt of the external storage gateway (storage.type gateway)",
+                    "type": "string",
+                    "example": "rcpt-7f3a"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "tx_id": {
+                    "type": "string",
+                    "example": "abc123def456789"
+                },
+                "user_info": {
+                    "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerMultiChainSyncStatusResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerSyncStatusResponse"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerParserCounts": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "Indexed without problems",
+                    "type": "integer",
+                    "example": 1200
+                },
+                "reasons": {
+                    "description": "Problems found, by kind",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "rejected": {
+                    "description": "Not indexed",
+                    "type": "integer",
+                    "example": 5
+                },
+                "warned": {
+                    "description": "Indexed despite problems (lenient)",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerParserStatus": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "description": "By mode (strict, lenient)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerParserCounts"
+                    }
+                },
+                "features": {
+                    "description": "Experimental protocol toggles in effect",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "mode": {
+                    "description": "strict or lenient",
+                    "type": "string",
+                    "example": "strict"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerPathTreeDirectory": {
+            "type": "object",
+            "properties": {
+                "file_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "2024"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file/photos/2024"
+                },
+                "total_size": {
+                    "description": "Bytes",
+                    "type": "integer",
+                    "example": 3145728
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerPathTreeResponse": {
+            "type": "object",
+            "properties": {
+                "directories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerPathTreeDirectory"
+                    }
+                },
+                "file_count": {
+                    "description": "Files below path at any depth",
+                    "type": "integer",
+                    "example": 15
+                },
+                "files": {
+                    "description": "Files directly in path, by name",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                    }
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file/photos"
+                },
+                "total_size": {
+                    "description": "Bytes below path at any depth",
+                    "type": "integer",
+                    "example": 4194304
+                },
+                "truncated": {
+                    "description": "Too many files below path; counts are partial",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerPeerListResponse": {
+            "type": "object",
+            "properties": {
+                "peers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_indexer_service.PeerStatus"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerPinInfoResponse": {
+            "type": "object",
+            "properties": {
+                "block_height": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "chain_name": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "text/plain"
+                },
+                "first_path": {
+                    "type": "string",
+                    "example": "/protocols/simplebuzz/info/name"
+                },
+                "first_pin_id": {
+                    "type": "string",
+                    "example": "xyz789i0"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "modify"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "@xyz789i0"
+                },
+                "pin_id": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1699999999
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerStatsResponse": {
+            "type": "object",
+            "properties": {
+                "chain_stats": {
+                    "description": "Per-chain file counts",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "total_files": {
+                    "type": "integer",
+                    "example": 12345
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerSyncStatusResponse": {
+            "type": "object",
+            "properties": {
+                "chain_name": {
+                    "description": "ID                int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "current_sync_height": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "earliest_block_height": {
+                    "description": "Lowest height that can be rescanned (0 = all)",
+                    "type": "integer",
+                    "example": 0
+                },
+                "latest_block_height": {
+                    "type": "integer",
+                    "example": 12350
+                },
+                "parser": {
+                    "description": "PIN parser mode, features and counts",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerParserStatus"
+                        }
+                    ]
+                },
+                "paused": {
+                    "description": "Why scanning waits: operator or disk_space",
+                    "type": "string",
+                    "example": "operator"
+                },
+                "pruned": {
+                    "description": "Node runs pruned",
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerWatchEventListResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerWatchEvent"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "next_cursor": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerWatchEventPage": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerWatchEvent"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Opaque; poll with it for new events",
+                    "type": "string",
+                    "example": "100"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerWatchListResponse": {
+            "type": "object",
+            "properties": {
+                "watches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerWatch"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerWatchRequest": {
+            "type": "object",
+            "required": [
+                "target"
+            ],
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "example": "my wallet"
+                },
+                "target": {
+                    "description": "Address, MetaID or GlobalMetaID",
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "webhook_url": {
+                    "description": "Optional; events are POSTed here",
+                    "type": "string",
+                    "example": "https://wallet.example.com/hooks/metaid"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerWeeklyStatItem": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer",
+                    "example": 73400320
+                },
+                "chains": {
+                    "description": "Per-chain breakdown",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerDailyStatCounts"
+                    }
+                },
+                "end_date": {
+                    "description": "Sunday",
+                    "type": "string",
+                    "example": "2025-01-05"
+                },
+                "new_files": {
+                    "type": "integer",
+                    "example": 840
+                },
+                "new_users": {
+                    "type": "integer",
+                    "example": 105
+                },
+                "start_date": {
+                    "description": "Monday",
+                    "type": "string",
+                    "example": "2024-12-30"
+                },
+                "week": {
+                    "description": "ISO week",
+                    "type": "string",
+                    "example": "2025-W01"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.IndexerWeeklyStatsResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2024-12-30"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-03-23"
+                },
+                "weeks": {
+                    "description": "One entry per week in [from, to], zero-filled",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerWeeklyStatItem"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.LatestRepairResponse": {
+            "type": "object",
+            "properties": {
+                "drifts": {
+                    "description": "Entries that are not the latest version (or are missing)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.LatestDrift"
+                    }
+                },
+                "fixed": {
+                    "description": "Whether the entries were rewritten",
+                    "type": "boolean"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.MetaIDDeriveResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "derivation": {
+                    "description": "address_sha256 or genesis_txid",
+                    "type": "string",
+                    "example": "address_sha256"
+                },
+                "globalMetaId": {
+                    "description": "Empty for addresses that have no IDAddress form",
+                    "type": "string",
+                    "example": "idq1..."
+                },
+                "metaId": {
+                    "type": "string",
+                    "example": "a1b2c3d4e5f6..."
+                }
+            }
+        },
+        "meta-file-system_controller_respond.MetaIDUserInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "avatar": {
+                    "type": "string",
+                    "example": "https://oss.example.com/avatar.jpg"
+                },
+                "avatarId": {
+                    "type": "string",
+                    "example": "xyz789i0"
+                },
+                "avatarInvalid": {
+                    "description": "Latest avatar is not a usable image; avatar is empty and clients should use a default",
+                    "type": "boolean",
+                    "example": false
+                },
+                "bio": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "chatpubkey": {
+                    "type": "string",
+                    "example": "02abc123..."
+                },
+                "chatpubkeyId": {
+                    "type": "string",
+                    "example": "def456i0"
+                },
+                "chatpubkeyInvalid": {
+                    "description": "Latest chat public key is malformed; chatpubkey is empty and must not be used for E2E chat",
+                    "type": "boolean",
+                    "example": false
+                },
+                "globalMetaId": {
+                    "type": "string",
+                    "example": "idaddress..."
+                },
+                "idAddress": {
+                    "description": "ID address of address (indexer.render_id_address)",
+                    "type": "string",
+                    "example": "idq1..."
+                },
+                "metaid": {
+                    "type": "string",
+                    "example": "abc123def456..."
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "nameId": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.MfsResolveResponse": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "description": "Resolved file (earliest PIN with the content for sha256 URIs)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
+                        }
+                    ]
+                },
+                "kind": {
+                    "description": "pin or sha256",
+                    "type": "string",
+                    "example": "sha256"
+                },
+                "uri": {
+                    "description": "Canonical (lower-case) URI",
+                    "type": "string",
+                    "example": "mfs://2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.PendingCreatorLookupResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "chain_name": {
+                    "type": "string",
+                    "example": "btc"
+                },
+                "created_at": {
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "fallback_address": {
+                    "type": "string",
+                    "example": "bc1q..."
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "failed to get transaction: connection refused"
+                },
+                "next_retry_at": {
+                    "type": "integer",
+                    "example": 1700000120
+                },
+                "pin_id": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.PendingCreatorResponse": {
+            "type": "object",
+            "properties": {
+                "lookups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.PendingCreatorLookupResponse"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "meta-file-system_controller_respond.PendingStorageResponse": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "writes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.PendingStorageWriteResponse"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.PendingStorageWriteResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "chain_name": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "created_at": {
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "kind": {
+                    "description": "file or chunk",
+                    "type": "string",
+                    "example": "file"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "failed to upload file to OSS: connection refused"
+                },
+                "next_retry_at": {
+                    "type": "integer",
+                    "example": 1700000120
+                },
+                "pin_id": {
+                    "type": "string",
+                    "example": "abc123def456i0"
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 1024
+                },
+                "storage_path": {
+                    "type": "string",
+                    "example": "indexer/mvc/abc123def456i0.png"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.RescanRequest": {
             "type": "object",
+            "required": [
+                "chain",
+                "end_height",
+                "start_height"
+            ],
             "properties": {
-                "files": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
-                    }
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
                 },
-                "has_more": {
-                    "type": "boolean",
-                    "example": true
+                "end_height": {
+                    "type": "integer",
+                    "example": 100100
                 },
-                "next_timestamp": {
-                    "type": "string",
-                    "example": "1699123456082917"
+                "start_height": {
+                    "type": "integer",
+                    "example": 100000
                 }
             }
         },
-        "meta-file-system_controller_respond.IndexerFileListResponse": {
+        "meta-file-system_controller_respond.RescanResponse": {
             "type": "object",
             "properties": {
-                "files": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerFileResponse"
-                    }
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
                 },
-                "has_more": {
-                    "type": "boolean",
-                    "example": true
+                "end_height": {
+                    "type": "integer",
+                    "example": 100100
                 },
-                "next_cursor": {
+                "message": {
+                    "type": "string",
+                    "example": "Block rescan task started successfully"
+                },
+                "start_height": {
                     "type": "integer",
-                    "example": 100
+                    "example": 100000
+                },
+                "task_id": {
+                    "type": "string",
+                    "example": "rescan_mvc_100000_100100_1699999999"
                 }
             }
         },
-        "meta-file-system_controller_respond.IndexerFileResponse": {
+        "meta-file-system_controller_respond.RescanStatusResponse": {
             "type": "object",
             "properties": {
-                "accelerate_content_url": {
-                    "description": "下载/加速链接 baseUrl + /api/v1/accelerate/content/:pinId",
+                "chain": {
                     "type": "string",
-                    "example": "https://example.com/api/v1/accelerate/content/abc123i0"
+                    "example": "mvc"
                 },
-                "block_height": {
+                "current_height": {
                     "type": "integer",
-                    "example": 12345
+                    "example": 100050
                 },
-                "chain_name": {
-                    "type": "string",
-                    "example": "mvc"
+                "duplicate_pins": {
+                    "description": "PINs already indexed",
+                    "type": "integer",
+                    "example": 900
                 },
-                "content_type": {
-                    "type": "string",
-                    "example": "image/jpeg"
+                "elapsed_time": {
+                    "description": "milliseconds",
+                    "type": "integer",
+                    "example": 4050
                 },
-                "content_url": {
-                    "description": "预览链接 baseUrl + /api/v1/content/:pinId",
-                    "type": "string",
-                    "example": "https://example.com/api/v1/content/abc123i0"
+                "end_height": {
+                    "type": "integer",
+                    "example": 100100
                 },
-                "creator_address": {
+                "error_message": {
                     "type": "string",
-                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                    "example": ""
                 },
-                "creator_global_meta_id": {
+                "estimated_time_left": {
+                    "description": "milliseconds",
+                    "type": "integer",
+                    "example": 4100
+                },
+                "failed_blocks": {
+                    "description": "Blocks that could not be scanned",
+                    "type": "integer",
+                    "example": 0
+                },
+                "failed_pins": {
+                    "description": "PINs that failed to index; see the task report",
+                    "type": "integer",
+                    "example": 2
+                },
+                "indexed_pins": {
+                    "description": "PINs (re)indexed",
+                    "type": "integer",
+                    "example": 120
+                },
+                "processed_blocks": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "progress": {
+                    "description": "percentage",
+                    "type": "number",
+                    "example": 49.5
+                },
+                "rejected_pins": {
+                    "description": "PINs quarantined by the parser policy",
+                    "type": "integer",
+                    "example": 0
+                },
+                "skipped_pins": {
+                    "description": "PINs outside the indexed paths",
+                    "type": "integer",
+                    "example": 30
+                },
+                "speed": {
+                    "description": "blocks per second",
+                    "type": "number",
+                    "example": 12.34
+                },
+                "start_height": {
+                    "type": "integer",
+                    "example": 100000
+                },
+                "start_time": {
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "status": {
+                    "description": "idle, running, completed, cancelled, failed",
                     "type": "string",
-                    "example": "idaddress..."
+                    "example": "running"
                 },
-                "creator_meta_id": {
+                "task_id": {
                     "type": "string",
-                    "example": "abc123def456..."
+                    "example": "rescan_mvc_100000_100100_1699999999"
                 },
-                "encryption": {
+                "total_blocks": {
+                    "type": "integer",
+                    "example": 101
+                }
+            }
+        },
+        "meta-file-system_controller_respond.RescanStopResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string",
-                    "example": "0"
+                    "example": "Rescan task stopped successfully"
                 },
-                "file_extension": {
+                "status": {
                     "type": "string",
-                    "example": ".jpg"
+                    "example": "cancelled"
                 },
-                "file_hash": {
+                "task_id": {
                     "type": "string",
-                    "example": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+                    "example": "rescan_mvc_100000_100100_1699999999"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.Response": {
+            "description": "Unified API response structure",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "file_md5": {
+                "data": {},
+                "errorCode": {
                     "type": "string",
-                    "example": "d41d8cd98f00b204e9800998ecf8427e"
+                    "example": "upstream_node_unreachable"
                 },
-                "file_name": {
+                "message": {
                     "type": "string",
-                    "example": "test.jpg"
+                    "example": "success"
                 },
-                "file_size": {
+                "processingTime": {
                     "type": "integer",
-                    "example": 102400
+                    "example": 123
                 },
-                "file_type": {
+                "requestId": {
                     "type": "string",
-                    "example": "image"
+                    "example": "9b1c..."
+                }
+            }
+        },
+        "meta-file-system_controller_respond.StorageMigrationResponse": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "operation": {
-                    "type": "string",
-                    "example": "create"
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
                 },
-                "owner_address": {
-                    "type": "string",
-                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                "failed": {
+                    "type": "integer",
+                    "example": 2,
+                    "description": "Failed in the last pass, retried on the next"
                 },
-                "owner_meta_id": {
+                "last_error": {
                     "type": "string",
-                    "example": "abc123def456..."
+                    "example": ""
                 },
-                "path": {
+                "last_pass_at": {
+                    "type": "integer",
+                    "example": 1700000299
+                },
+                "migrated": {
+                    "type": "integer",
+                    "example": 11990,
+                    "description": "Records confirmed on the target"
+                },
+                "passes": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "progress": {
+                    "type": "number",
+                    "example": 99.92,
+                    "description": "percentage"
+                },
+                "source": {
                     "type": "string",
-                    "example": "/file/test.jpg"
+                    "example": "local"
                 },
-                "pin_id": {
-                    "description": "ID             int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
+                "started_at": {
+                    "type": "integer",
+                    "example": 1699999999
+                },
+                "status": {
                     "type": "string",
-                    "example": "abc123def456i0"
+                    "example": "running",
+                    "description": "running, waiting (between passes), completed"
                 },
-                "storage_path": {
-                    "description": "StorageType    string    ` + "`" + `json:\"storage_type\" example:\"oss\"` + "`" + `",
+                "target": {
                     "type": "string",
-                    "example": "indexer/mvc/pinid123i0.jpg"
+                    "example": "oss"
                 },
-                "timestamp": {
+                "total": {
+                    "type": "integer",
+                    "example": 12000,
+                    "description": "File and chunk records with a blob"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UserInfoListResponse": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "next_cursor": {
                     "type": "integer",
-                    "example": 1699999999
+                    "example": 100
                 },
-                "tx_id": {
-                    "type": "string",
-                    "example": "abc123def456789"
+                "total": {
+                    "description": "Total number of users",
+                    "type": "integer",
+                    "example": 1000
                 },
-                "user_info": {
-                    "$ref": "#/definitions/meta-file-system_controller_respond.MetaIDUserInfo"
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IndexerUserInfo"
+                    }
                 }
             }
         },
-        "meta-file-system_controller_respond.IndexerMultiChainSyncStatusResponse": {
+        "meta-file-system_controller_respond.UserInfoPage": {
             "type": "object",
             "properties": {
-                "chains": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/meta-file-system_controller_respond.IndexerSyncStatusResponse"
+                        "$ref": "#/definitions/model.IndexerUserInfo"
                     }
+                },
+                "nextCursor": {
+                    "description": "Opaque; empty when there is no next page",
+                    "type": "string",
+                    "example": "1699123456"
+                },
+                "total": {
+                    "description": "Total number of users",
+                    "type": "integer",
+                    "example": 1000
                 }
             }
         },
-        "meta-file-system_controller_respond.IndexerPinInfoResponse": {
+        "meta-file-system_service_indexer_service.FileChunkInfo": {
             "type": "object",
             "properties": {
-                "block_height": {
-                    "type": "integer",
-                    "example": 12345
+                "blockHeight": {
+                    "description": "Block of the chunk PIN (0 = mempool)",
+                    "type": "integer"
                 },
-                "chain_name": {
-                    "type": "string",
-                    "example": "mvc"
+                "chainName": {
+                    "description": "Chain of the chunk PIN",
+                    "type": "string"
                 },
-                "content_type": {
-                    "type": "string",
-                    "example": "text/plain"
+                "confirmed": {
+                    "description": "Chunk PIN is in a block",
+                    "type": "boolean"
                 },
-                "first_path": {
-                    "type": "string",
-                    "example": "/protocols/simplebuzz/info/name"
+                "hashMatch": {
+                    "description": "Stored chunk sha256 matches the index",
+                    "type": "boolean"
                 },
-                "first_pin_id": {
-                    "type": "string",
-                    "example": "xyz789i0"
+                "hashMismatched": {
+                    "description": "The last merge attempt refused this chunk",
+                    "type": "boolean"
                 },
-                "operation": {
-                    "type": "string",
-                    "example": "modify"
+                "index": {
+                    "description": "Position in chunkList",
+                    "type": "integer"
                 },
-                "path": {
-                    "type": "string",
-                    "example": "@xyz789i0"
+                "offset": {
+                    "description": "Byte offset in the file",
+                    "type": "integer"
                 },
-                "pin_id": {
-                    "type": "string",
-                    "example": "abc123def456i0"
+                "parentPinId": {
+                    "description": "Index the stored chunk is attached to",
+                    "type": "string"
                 },
-                "timestamp": {
-                    "type": "integer",
-                    "example": 1699999999
+                "pinId": {
+                    "description": "Chunk PIN ID listed in the index",
+                    "type": "string"
+                },
+                "resolvedPinId": {
+                    "description": "Indexed PIN used instead (same sha256), when the listed one is not indexed",
+                    "type": "string"
+                },
+                "sha256": {
+                    "description": "Chunk sha256 listed in the index",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Chunk size listed in (or derived from) the index",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "indexed, missing or rejected",
+                    "type": "string"
+                },
+                "statusReason": {
+                    "description": "Why the chunk was rejected",
+                    "type": "string"
+                },
+                "storedSize": {
+                    "description": "Size of the stored chunk",
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_controller_respond.IndexerStatsResponse": {
+        "meta-file-system_service_indexer_service.FileChunksResult": {
             "type": "object",
             "properties": {
-                "chain_stats": {
-                    "description": "Per-chain file counts",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer",
-                        "format": "int64"
+                "chainName": {
+                    "description": "Chain of the index PIN",
+                    "type": "string"
+                },
+                "chunkNumber": {
+                    "description": "Number of chunks",
+                    "type": "integer"
+                },
+                "chunkSize": {
+                    "description": "Chunk size from the index",
+                    "type": "integer"
+                },
+                "chunks": {
+                    "description": "Chunks in chunkList order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_indexer_service.FileChunkInfo"
                     }
                 },
-                "total_files": {
-                    "type": "integer",
-                    "example": 12345
+                "chunksIndexed": {
+                    "description": "Chunks with status indexed",
+                    "type": "integer"
+                },
+                "fileName": {
+                    "description": "File name from the index",
+                    "type": "string"
+                },
+                "fileSize": {
+                    "description": "File size from the index",
+                    "type": "integer"
+                },
+                "indexPinId": {
+                    "description": "Index PIN ID",
+                    "type": "string"
+                },
+                "mergeError": {
+                    "description": "Last merge failure or rejection reason",
+                    "type": "string"
+                },
+                "mergeStatus": {
+                    "description": "merged, pending or rejected (FileStatus values)",
+                    "type": "string"
+                },
+                "merged": {
+                    "description": "The merged file exists in storage",
+                    "type": "boolean"
+                },
+                "version": {
+                    "description": "Index schema version",
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_controller_respond.IndexerSyncStatusResponse": {
+        "meta-file-system_service_indexer_service.FileStatus": {
             "type": "object",
             "properties": {
-                "chain_name": {
-                    "description": "ID                int64     ` + "`" + `json:\"id\" example:\"1\"` + "`" + `",
-                    "type": "string",
-                    "example": "mvc"
+                "blockHeight": {
+                    "type": "integer"
                 },
-                "created_at": {
-                    "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                "chainName": {
+                    "type": "string"
                 },
-                "current_sync_height": {
-                    "type": "integer",
-                    "example": 12345
+                "fileName": {
+                    "type": "string"
                 },
-                "latest_block_height": {
-                    "type": "integer",
-                    "example": 12350
+                "fileSize": {
+                    "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
                 }
             }
         },
-        "meta-file-system_controller_respond.MetaIDUserInfo": {
+        "meta-file-system_service_indexer_service.PeerStatus": {
             "type": "object",
             "properties": {
-                "address": {
-                    "type": "string",
-                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
-                },
-                "avatar": {
-                    "type": "string",
-                    "example": "https://oss.example.com/avatar.jpg"
-                },
-                "avatarId": {
-                    "type": "string",
-                    "example": "xyz789i0"
-                },
-                "bio": {
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                },
-                "chatpubkey": {
-                    "type": "string",
-                    "example": "02abc123..."
+                "available": {
+                    "description": "Not skipped after a recent failure",
+                    "type": "boolean"
                 },
-                "chatpubkeyId": {
-                    "type": "string",
-                    "example": "def456i0"
+                "discovered": {
+                    "description": "Listed by another peer rather than configured",
+                    "type": "boolean"
                 },
-                "globalMetaId": {
-                    "type": "string",
-                    "example": "idaddress..."
+                "last_error": {
+                    "type": "string"
                 },
-                "metaid": {
-                    "type": "string",
-                    "example": "abc123def456..."
+                "last_error_at": {
+                    "description": "Last failed request (ms)",
+                    "type": "integer"
                 },
-                "name": {
-                    "type": "string",
-                    "example": "John Doe"
+                "last_success_at": {
+                    "description": "Last answered request (ms)",
+                    "type": "integer"
                 },
-                "nameId": {
-                    "type": "string",
-                    "example": "abc123def456i0"
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "meta-file-system_controller_respond.RescanRequest": {
+        "meta-file-system_service_indexer_service.RescanBlockResult": {
             "type": "object",
-            "required": [
-                "chain",
-                "end_height",
-                "start_height"
-            ],
             "properties": {
-                "chain": {
-                    "type": "string",
-                    "example": "mvc"
+                "error": {
+                    "type": "string"
                 },
-                "end_height": {
-                    "type": "integer",
-                    "example": 100100
+                "failed": {
+                    "type": "integer"
                 },
-                "start_height": {
-                    "type": "integer",
-                    "example": 100000
+                "height": {
+                    "type": "integer"
+                },
+                "indexed": {
+                    "type": "integer"
+                },
+                "pins": {
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_controller_respond.RescanResponse": {
+        "meta-file-system_service_indexer_service.RescanCounts": {
             "type": "object",
             "properties": {
-                "chain": {
-                    "type": "string",
-                    "example": "mvc"
+                "duplicate_pins": {
+                    "type": "integer"
                 },
-                "end_height": {
-                    "type": "integer",
-                    "example": 100100
+                "failed_blocks": {
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "Block rescan task started successfully"
+                "failed_pins": {
+                    "type": "integer"
                 },
-                "start_height": {
-                    "type": "integer",
-                    "example": 100000
+                "indexed_pins": {
+                    "type": "integer"
                 },
-                "task_id": {
-                    "type": "string",
-                    "example": "rescan_mvc_100000_100100_1699999999"
+                "rejected_pins": {
+                    "type": "integer"
+                },
+                "skipped_pins": {
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_controller_respond.RescanStatusResponse": {
+        "meta-file-system_service_indexer_service.RescanPinResult": {
             "type": "object",
             "properties": {
-                "chain": {
-                    "type": "string",
-                    "example": "mvc"
+                "height": {
+                    "type": "integer"
                 },
-                "current_height": {
-                    "type": "integer",
-                    "example": 100050
+                "outcome": {
+                    "type": "string"
                 },
-                "elapsed_time": {
-                    "description": "milliseconds",
-                    "type": "integer",
-                    "example": 4050
+                "path": {
+                    "type": "string"
                 },
-                "end_height": {
-                    "type": "integer",
-                    "example": 100100
+                "pin_id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_service_indexer_service.RescanReport": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_indexer_service.RescanBlockResult"
+                    }
                 },
-                "error_message": {
-                    "type": "string",
-                    "example": ""
+                "chain": {
+                    "type": "string"
                 },
-                "estimated_time_left": {
-                    "description": "milliseconds",
-                    "type": "integer",
-                    "example": 4100
+                "counts": {
+                    "$ref": "#/definitions/meta-file-system_service_indexer_service.RescanCounts"
                 },
-                "processed_blocks": {
-                    "type": "integer",
-                    "example": 50
+                "end_height": {
+                    "type": "integer"
                 },
-                "progress": {
-                    "description": "percentage",
-                    "type": "number",
-                    "example": 49.5
+                "generated_at": {
+                    "type": "integer"
                 },
-                "speed": {
-                    "description": "blocks per second",
-                    "type": "number",
-                    "example": 12.34
+                "pins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_indexer_service.RescanPinResult"
+                    }
                 },
                 "start_height": {
-                    "type": "integer",
-                    "example": 100000
-                },
-                "start_time": {
-                    "type": "integer",
-                    "example": 1699999999
-                },
-                "status": {
-                    "description": "idle, running, completed, cancelled, failed",
-                    "type": "string",
-                    "example": "running"
+                    "type": "integer"
                 },
                 "task_id": {
-                    "type": "string",
-                    "example": "rescan_mvc_100000_100100_1699999999"
+                    "type": "string"
                 },
-                "total_blocks": {
-                    "type": "integer",
-                    "example": 101
+                "truncated": {
+                    "description": "PIN entries beyond the cap were dropped",
+                    "type": "boolean"
                 }
             }
         },
-        "meta-file-system_controller_respond.RescanStopResponse": {
+        "model.CounterDrift": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "Rescan task stopped successfully"
+                "actual": {
+                    "description": "Value recounted from the indexes",
+                    "type": "integer"
                 },
-                "status": {
-                    "type": "string",
-                    "example": "cancelled"
+                "name": {
+                    "type": "string"
                 },
-                "task_id": {
-                    "type": "string",
-                    "example": "rescan_mvc_100000_100100_1699999999"
+                "stored": {
+                    "description": "Maintained value",
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_controller_respond.Response": {
-            "description": "Unified API response structure",
+        "model.IndexerChangeEvent": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 0
+                "action": {
+                    "type": "string"
                 },
-                "data": {},
-                "errorCode": {
-                    "type": "string",
-                    "example": "upstream_node_unreachable"
+                "block_height": {
+                    "description": "0 while in mempool",
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "success"
+                "chain_name": {
+                    "type": "string"
                 },
-                "processingTime": {
-                    "type": "integer",
-                    "example": 123
+                "created_at": {
+                    "type": "string"
                 },
-                "requestId": {
-                    "type": "string",
-                    "example": "9b1c..."
-                }
-            }
-        },
-        "meta-file-system_controller_respond.UserInfoListResponse": {
-            "type": "object",
-            "properties": {
-                "has_more": {
-                    "type": "boolean",
-                    "example": true
+                "first_pin_id": {
+                    "description": "File the PIN belongs to (modify/revoke/merge)",
+                    "type": "string"
                 },
-                "next_cursor": {
-                    "type": "integer",
-                    "example": 100
+                "meta_id": {
+                    "description": "Creator MetaID",
+                    "type": "string"
                 },
-                "total": {
-                    "description": "Total number of users",
-                    "type": "integer",
-                    "example": 1000
+                "pin_id": {
+                    "type": "string"
                 },
-                "users": {
+                "record": {
+                    "description": "JSON of the written record (IndexerFile, IndexerFileChunk, user info)",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.IndexerUserInfo"
+                        "type": "integer"
                     }
+                },
+                "seq": {
+                    "type": "integer"
+                },
+                "timestamp": {
+                    "description": "PIN timestamp (ms)",
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_service_indexer_service.FileStatus": {
+        "model.IndexerDomain": {
             "type": "object",
             "properties": {
-                "blockHeight": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "chainName": {
+                "domain": {
+                    "description": "Lower-cased host name, e.g. mysite.example.com",
                     "type": "string"
                 },
-                "fileName": {
+                "target": {
+                    "description": "Site manifest PIN ID or MetaID",
                     "type": "string"
                 },
-                "fileSize": {
-                    "type": "integer"
+                "target_type": {
+                    "description": "site or metaid",
+                    "type": "string"
                 },
-                "status": {
+                "updated_at": {
                     "type": "string"
                 }
             }
@@ -2123,6 +6004,10 @@ const docTemplateindexer = `{
                     "description": "头像路径",
                     "type": "string"
                 },
+                "avatarInvalid": {
+                    "description": "最新头像无效（客户端应使用默认头像）",
+                    "type": "boolean"
+                },
                 "avatarPinId": {
                     "description": "头像 PIN ID",
                     "type": "string"
@@ -2150,14 +6035,26 @@ const docTemplateindexer = `{
                     "description": "聊天公钥",
                     "type": "string"
                 },
+                "chatPublicKeyInvalid": {
+                    "description": "最新聊天公钥无效（不返回公钥）",
+                    "type": "boolean"
+                },
                 "chatPublicKeyPinId": {
                     "description": "聊天公钥 PIN ID",
                     "type": "string"
                 },
+                "chatPublicKeyType": {
+                    "description": "聊天公钥类型：secp256k1 / x25519",
+                    "type": "string"
+                },
                 "globalMetaId": {
                     "description": "全局 MetaID",
                     "type": "string"
                 },
+                "idAddress": {
+                    "description": "地址的 ID 地址形式（仅在响应中填充，indexer.render_id_address）",
+                    "type": "string"
+                },
                 "metaId": {
                     "description": "用户 MetaID",
                     "type": "string"
@@ -2176,6 +6073,157 @@ const docTemplateindexer = `{
                 }
             }
         },
+        "model.IndexerWatch": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "description": "IP of the client that created it (per-client limit)",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "label": {
+                    "description": "Free-form client label",
+                    "type": "string"
+                },
+                "target": {
+                    "description": "Address, MetaID or GlobalMetaID",
+                    "type": "string"
+                },
+                "webhook_url": {
+                    "description": "Optional; events are POSTed here as JSON",
+                    "type": "string"
+                }
+            }
+        },
+        "model.IndexerWatchEvent": {
+            "type": "object",
+            "properties": {
+                "block_height": {
+                    "description": "0 while in mempool",
+                    "type": "integer"
+                },
+                "chain_name": {
+                    "type": "string"
+                },
+                "confirmed": {
+                    "type": "boolean"
+                },
+                "content_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "creator_address": {
+                    "type": "string"
+                },
+                "creator_global_meta_id": {
+                    "type": "string"
+                },
+                "creator_meta_id": {
+                    "type": "string"
+                },
+                "event": {
+                    "description": "pin (empty on events recorded before kinds) / profile_changed",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "pin_id": {
+                    "type": "string"
+                },
+                "profile": {
+                    "description": "profile_changed events",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ProfileChange"
+                        }
+                    ]
+                },
+                "target": {
+                    "description": "Watched identity that matched",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "PIN timestamp (ms)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.LatestDrift": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "description": "PIN ID of the latest version; empty when no version is left (the entry is kept)",
+                    "type": "string"
+                },
+                "collection": {
+                    "description": "e.g. latest_file_info, latest_user_name_info_by_global_meta_id",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "FirstPinID, MetaID/address or GlobalMetaID",
+                    "type": "string"
+                },
+                "stored": {
+                    "description": "PIN ID of the stored entry; empty when it is missing",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ProfileChange": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "block_height": {
+                    "description": "0 while in mempool",
+                    "type": "integer"
+                },
+                "chain_name": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "global_meta_id": {
+                    "type": "string"
+                },
+                "meta_id": {
+                    "type": "string"
+                },
+                "pin_id": {
+                    "description": "PIN that made the change",
+                    "type": "string"
+                },
+                "previous": {
+                    "type": "string"
+                },
+                "previous_pin_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "PIN timestamp (ms)",
+                    "type": "integer"
+                }
+            }
+        },
         "model.UserAvatarInfo": {
             "type": "object",
             "properties": {
@@ -2199,6 +6247,10 @@ const docTemplateindexer = `{
                     "description": "Content type (e.g., image/jpeg)",
                     "type": "string"
                 },
+                "downscaled": {
+                    "description": "Stored content was downscaled from the on-chain image",
+                    "type": "boolean"
+                },
                 "fileExtension": {
                     "description": "File extension, e.g. .jpg, .png, .mp4, .mp3, .doc, .pdf, etc.",
                     "type": "string"
@@ -2227,6 +6279,18 @@ const docTemplateindexer = `{
                     "description": "第一个 PIN ID",
                     "type": "string"
                 },
+                "height": {
+                    "description": "Image height in pixels (after downscaling)",
+                    "type": "integer"
+                },
+                "invalid": {
+                    "description": "Not a usable image; content is not served and clients should fall back",
+                    "type": "boolean"
+                },
+                "invalidReason": {
+                    "description": "Why the avatar was marked invalid",
+                    "type": "string"
+                },
                 "pinId": {
                     "description": "PIN ID",
                     "type": "string"
@@ -2234,6 +6298,10 @@ const docTemplateindexer = `{
                 "timestamp": {
                     "description": "时间戳",
                     "type": "integer"
+                },
+                "width": {
+                    "description": "Image width in pixels (after downscaling)",
+                    "type": "integer"
                 }
             }
         },
@@ -2296,6 +6364,22 @@ const docTemplateindexer = `{
                     "description": "第一个 PIN ID",
                     "type": "string"
                 },
+                "invalid": {
+                    "description": "公钥格式无效，E2E 客户端不应使用",
+                    "type": "boolean"
+                },
+                "invalidReason": {
+                    "description": "无效原因",
+                    "type": "string"
+                },
+                "keyEncoding": {
+                    "description": "公钥编码：hex / base64",
+                    "type": "string"
+                },
+                "keyType": {
+                    "description": "公钥类型：secp256k1 / x25519",
+                    "type": "string"
+                },
                 "pinId": {
                     "description": "PIN ID",
                     "type": "string"
@@ -2379,7 +6463,7 @@ const docTemplateindexer = `{
 var SwaggerInfoindexer = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:7281",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{"https", "http"},
 	Title:            "Meta File System Indexer API",
 	Description:      "Meta File System Indexer Service API, provides file query and download functionality",
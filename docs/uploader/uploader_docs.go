@@ -24,19 +24,38 @@ const docTemplateuploader = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/config": {
+        "/v1/admin/assistants": {
             "get": {
-                "description": "Get upload service configuration information, including max file size, swagger base URL, and per-chain config",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Value flowing through the uploader's assistent (intermediate) keys, from the ledger of outputs the uploader broadcast to them: totals per chain and per user, largest balance first. balance is what sits in unspent outputs; feesPaid is what users paid in that is no longer unspent (network fees plus PIN dust). Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Configuration"
+                    "Uploader Admin"
+                ],
+                "summary": "Assistent address dashboard",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain (mvc or doge; default all)",
+                        "name": "chain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset of the user page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Users per page (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -49,43 +68,57 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.ConfigResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.AssistentDashboard"
                                         }
                                     }
                                 }
                             ]
                         }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
                     }
                 }
             }
         },
-        "/files/chunked-upload": {
+        "/v1/admin/assistants/rotations/{id}/sweep": {
             "post": {
-                "description": "Upload large file by splitting it into chunks, build transactions for chunks and index, optionally broadcast all transactions in order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Sweep the unspent outputs the ledger still holds for the address retired by a rotation, e.g. after its sweep failed. The outcome is recorded on the rotation. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Chunked file upload",
+                "summary": "Sweep retired assistent address",
                 "parameters": [
                     {
-                        "description": "Chunked upload request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller_handler.ChunkedUploadRequest"
-                        }
+                        "type": "integer",
+                        "description": "Rotation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Sweep fee rate (sat/byte), 0 = chain default",
+                        "name": "feeRate",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Upload successful",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -95,7 +128,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_upload_service.ChunkedUploadResponse"
+                                            "$ref": "#/definitions/model.AssistentRotation"
                                         }
                                     }
                                 }
@@ -108,6 +141,12 @@ const docTemplateuploader = `{
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
+                    "404": {
+                        "description": "Rotation not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
                     "500": {
                         "description": "Server error",
                         "schema": {
@@ -117,28 +156,23 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/chunked-upload-task": {
-            "post": {
-                "description": "Create an async chunked upload task and return the task ID so the client can poll for progress",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/assistants/{address}": {
+            "get": {
+                "description": "Ledger totals and unspent (pending) outputs of the assistent address of a user, looked up by user address or by assistent address. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Async chunked upload (create task)",
+                "summary": "Assistent address detail",
                 "parameters": [
                     {
-                        "description": "Async chunked upload request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller_handler.ChunkedUploadForTaskRequest"
-                        }
+                        "type": "string",
+                        "description": "User address or assistent address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -153,7 +187,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.ChunkedUploadTaskResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.AssistentDetail"
                                         }
                                     }
                                 }
@@ -161,7 +195,7 @@ const docTemplateuploader = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid parameter",
+                        "description": "Parameter error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -175,9 +209,9 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/commit-upload": {
+        "/v1/admin/assistants/{address}/rotate": {
             "post": {
-                "description": "Submit signed transaction for broadcast",
+                "description": "Generate a new assistent key for the user, retire the current one and sweep the unspent outputs the ledger holds for the retired address to the new assistent address or back to the user. Refused while upload tasks of the user are pending, processing or scheduled unless force is set. The rotation is recorded before the sweep; a failed sweep is reported in sweep_status/sweep_error and can be retried with /admin/assistants/rotations/{id}/sweep. Only registered when uploader.admin_enabled is set.",
                 "consumes": [
                     "application/json"
                 ],
@@ -185,23 +219,30 @@ const docTemplateuploader = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Commit upload",
+                "summary": "Rotate assistent key",
                 "parameters": [
                     {
-                        "description": "Commit upload request",
+                        "type": "string",
+                        "description": "User address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rotate request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller_handler.CommitUploadRequest"
+                            "$ref": "#/definitions/controller_handler.RotateAssistentRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -211,7 +252,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                            "$ref": "#/definitions/model.AssistentRotation"
                                         }
                                     }
                                 }
@@ -219,13 +260,19 @@ const docTemplateuploader = `{
                         }
                     },
                     "400": {
-                        "description": "Parameter error or file not found",
+                        "description": "Parameter error or upload tasks still active",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No assistent for this address",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "500": {
-                        "description": "Server error or broadcast failed",
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -233,94 +280,42 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/direct-upload": {
-            "post": {
-                "description": "Upload file and add MetaID OP_RETURN output to existing PreTxHex, then broadcast immediately. This is a one-step upload process that combines building and broadcasting. Supports UTXO merge transaction for SIGHASH_SINGLE compatibility.",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/v1/admin/assistants/{address}/rotations": {
+            "get": {
+                "description": "Audit trail of the assistent key rotations of a user address (or the rotation that retired an assistent address), newest first. Private keys are never included. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Direct upload file (one-step)",
+                "summary": "List assistent key rotations",
                 "parameters": [
-                    {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "File path",
-                        "name": "path",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Pre-transaction hex (signed, with inputs and outputs)",
-                        "name": "preTxHex",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Merge transaction hex (optional, broadcasted before main transaction)",
-                        "name": "mergeTxHex",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "default": "create",
-                        "description": "Operation type",
-                        "name": "operation",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content type",
-                        "name": "contentType",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "MetaID",
-                        "name": "metaId",
-                        "in": "formData"
-                    },
                     {
                         "type": "string",
-                        "description": "Address (also used as change address if changeAddress is not provided)",
+                        "description": "User address or retired assistent address",
                         "name": "address",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Change address (optional, defaults to address)",
-                        "name": "changeAddress",
-                        "in": "formData"
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "Fee rate (satoshis per byte, optional)",
-                        "name": "feeRate",
-                        "in": "formData"
+                        "default": 0,
+                        "description": "Cursor (last rotation ID)",
+                        "name": "cursor",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Total input amount in satoshis (optional, for automatic change calculation)",
-                        "name": "totalInputAmount",
-                        "in": "formData"
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -330,7 +325,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.AssistentRotationList"
                                         }
                                     }
                                 }
@@ -352,33 +347,53 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/estimate-chunked-upload": {
-            "post": {
-                "description": "Estimate the fee required for chunked file upload, including chunk count and fees for ChunkPreTxHex and IndexPreTxHex",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/delegated/charges": {
+            "get": {
+                "description": "Charges of delegated (hot wallet funded) uploads, newest first, optionally for one client, user and settlement status. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Estimate chunked upload fee",
+                "summary": "List delegated upload charges",
                 "parameters": [
                     {
-                        "description": "Estimate chunked upload request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller_handler.EstimateChunkedUploadRequest"
-                        }
+                        "type": "string",
+                        "description": "API client name",
+                        "name": "client",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "unsettled or settled (default both)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last charge ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Estimate successful",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -388,7 +403,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_upload_service.EstimateChunkedUploadResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.DelegatedChargeList"
                                         }
                                     }
                                 }
@@ -410,37 +425,64 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/multipart/abort": {
-            "post": {
-                "description": "Abort a multipart upload session and clean up resources",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/delegated/invoices": {
+            "get": {
+                "description": "Charges summed per API client and user, largest amount first: by default what is still unsettled, with status=settled what was paid. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Abort multipart upload",
+                "summary": "Delegated upload invoices",
                 "parameters": [
                     {
-                        "description": "Abort multipart upload request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller_handler.AbortMultipartUploadRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Abort successful",
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                        }
-                    },
+                        "type": "string",
+                        "description": "API client name",
+                        "name": "client",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "unsettled",
+                        "description": "unsettled or settled",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset of the page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.DelegatedInvoiceList"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
                     "400": {
                         "description": "Parameter error",
                         "schema": {
@@ -456,9 +498,9 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/multipart/complete": {
+        "/v1/admin/delegated/settle": {
             "post": {
-                "description": "Complete a multipart upload session and merge all parts into a single file",
+                "description": "Mark the unsettled charges of a user settled with a payment reference: all of them, those of one client, or the listed chargeIds. Only registered when uploader.admin_enabled is set.",
                 "consumes": [
                     "application/json"
                 ],
@@ -466,17 +508,17 @@ const docTemplateuploader = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Complete multipart upload",
+                "summary": "Settle delegated upload charges",
                 "parameters": [
                     {
-                        "description": "Complete multipart upload request",
+                        "description": "Settle request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller_handler.CompleteMultipartUploadRequest"
+                            "$ref": "#/definitions/controller_handler.DelegatedSettleRequest"
                         }
                     }
                 ],
@@ -492,7 +534,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_upload_service.CompleteMultipartUploadResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.DelegatedSettleResponse"
                                         }
                                     }
                                 }
@@ -514,30 +556,16 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/multipart/initiate": {
-            "post": {
-                "description": "Start a multipart upload session for large file uploads with resume support",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/hot-wallet": {
+            "get": {
+                "description": "Chunk funding strategy of the uploader, the hot wallet address (uploader.chunk_funding.hot_wallet_key) and its ledger totals per status: available, locked (by an upload not broadcast yet), pending (change not broadcast yet) and spent. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
-                ],
-                "summary": "Initiate multipart upload",
-                "parameters": [
-                    {
-                        "description": "Initiate multipart upload request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller_handler.InitiateMultipartUploadRequest"
-                        }
-                    }
+                    "Uploader Admin"
                 ],
+                "summary": "Chunk funding hot wallet",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -550,15 +578,15 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_upload_service.InitiateMultipartUploadResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.HotWalletStatus"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "Parameter error",
+                    "404": {
+                        "description": "No hot wallet configured",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -572,9 +600,9 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/multipart/list-parts": {
+        "/v1/admin/hot-wallet/deposits": {
             "post": {
-                "description": "List all uploaded parts in a multipart upload session for resume support",
+                "description": "Add the outputs of an MVC transaction paying the hot wallet address to its spendable outputs. Chunks are only funded from outputs in the ledger, so every deposit must be recorded. Transactions spending hot wallet outputs are refused; recording a deposit again changes nothing. Only registered when uploader.admin_enabled is set.",
                 "consumes": [
                     "application/json"
                 ],
@@ -582,17 +610,17 @@ const docTemplateuploader = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "List uploaded parts",
+                "summary": "Record hot wallet deposit",
                 "parameters": [
                     {
-                        "description": "List parts request",
+                        "description": "Deposit transaction",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controller_handler.ListPartsRequest"
+                            "$ref": "#/definitions/controller_handler.HotWalletDepositRequest"
                         }
                     }
                 ],
@@ -608,7 +636,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_upload_service.ListPartsResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.HotWalletDeposit"
                                         }
                                     }
                                 }
@@ -616,7 +644,13 @@ const docTemplateuploader = `{
                         }
                     },
                     "400": {
-                        "description": "Parameter error",
+                        "description": "Parameter error, or the transaction pays nothing to the hot wallet",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No hot wallet configured",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -630,28 +664,30 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/multipart/upload-part": {
+        "/v1/admin/uploads/recover": {
             "post": {
-                "description": "Upload a single part of the file in a multipart upload session",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Resume chunked uploads sent with isBroadcast=true whose broadcast was cut off, e.g. by a restart. Every upload keeps a checkpoint of its transactions and broadcast progress; pending checkpoints not updated for stalledAfter seconds are broadcast from where they stopped (already-known transactions count as done), and their file is marked success or failed. Only registered when uploader.admin_enabled is set.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Uploader Admin"
                 ],
-                "summary": "Upload part",
+                "summary": "Recover in-flight uploads",
                 "parameters": [
                     {
-                        "description": "Upload part request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controller_handler.UploadPartRequest"
-                        }
+                        "type": "integer",
+                        "default": 120,
+                        "description": "Only uploads not updated for this many seconds (0 = all pending)",
+                        "name": "stalledAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Uploads per run (max 1000)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -666,7 +702,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_service_upload_service.UploadPartResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.RecoverUploadsResponse"
                                         }
                                     }
                                 }
@@ -688,88 +724,22 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/pre-upload": {
-            "post": {
-                "description": "Upload file and generate unsigned transaction, return transaction for client signing",
+        "/v1/config": {
+            "get": {
+                "description": "Get upload service configuration information, including max file size, swagger base URL, and per-chain config",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
-                ],
-                "summary": "Pre-upload file",
-                "parameters": [
-                    {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "File path",
-                        "name": "path",
-                        "in": "formData",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "default": "create",
-                        "description": "Operation type",
-                        "name": "operation",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Content type",
-                        "name": "contentType",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Change address",
-                        "name": "changeAddress",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "MetaID",
-                        "name": "metaId",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Address",
-                        "name": "address",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Fee rate",
-                        "name": "feeRate",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Output list json",
-                        "name": "outputs",
-                        "in": "formData"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Other output list json",
-                        "name": "otherOutputs",
-                        "in": "formData"
-                    }
+                    "Configuration"
                 ],
+                "summary": "Get configuration",
                 "responses": {
                     "200": {
-                        "description": "Pre-upload successful, return transaction and file info",
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -779,31 +749,19 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/controller_handler.PreUploadResponseData"
+                                            "$ref": "#/definitions/controller_handler.ConfigResponse"
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "400": {
-                        "description": "Parameter error",
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Server error",
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                        }
                     }
                 }
             }
         },
-        "/files/task/{taskId}": {
-            "get": {
-                "description": "Get async upload task progress and status by task ID",
+        "/v1/faucet": {
+            "post": {
+                "description": "Send testnet MVC coins to an address, to the chunked upload assistant address of an address, or to a freshly generated address whose private key is returned, so the upload flows can be tried end to end. Only available when uploader.faucet.enabled is set outside mainnet; each address and client IP is subject to a cooldown and the faucet to an hourly cap (code 42900 when exceeded). Callers without a client IP (a unix socket listener without http.trusted_proxies) get code 40300.",
                 "consumes": [
                     "application/json"
                 ],
@@ -811,21 +769,22 @@ const docTemplateuploader = `{
                     "application/json"
                 ],
                 "tags": [
-                    "File Upload"
+                    "Faucet"
                 ],
-                "summary": "Query task progress",
+                "summary": "Testnet faucet",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Task ID",
-                        "name": "taskId",
-                        "in": "path",
-                        "required": true
+                        "description": "Faucet request",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.FaucetRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Funded",
                         "schema": {
                             "allOf": [
                                 {
@@ -835,7 +794,7 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadTaskDetailResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.FaucetResponse"
                                         }
                                     }
                                 }
@@ -843,13 +802,25 @@ const docTemplateuploader = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid parameter",
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Client IP unknown",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     },
                     "404": {
-                        "description": "Task not found",
+                        "description": "Faucet disabled",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
@@ -863,42 +834,16 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "/files/tasks": {
+        "/v1/files/batch": {
             "get": {
-                "description": "List chunked upload tasks for a given address with cursor-based pagination",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Batching configuration (uploader.batch) and the batches of this uploader instance still collecting direct uploads sent with batch=true, per fee rate",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "File Upload"
                 ],
-                "summary": "List upload tasks",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User address",
-                        "name": "address",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Cursor (last task ID)",
-                        "name": "cursor",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 20,
-                        "description": "Page size",
-                        "name": "size",
-                        "in": "query"
-                    }
-                ],
+                "summary": "Upload batching status",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -911,252 +856,4397 @@ const docTemplateuploader = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadTaskListResponse"
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.UploadBatchStatusResponse"
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "400": {
-                        "description": "Parameter error",
-                        "schema": {
-                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Server error",
+                    }
+                }
+            }
+        },
+        "/v1/files/batch/{txId}": {
+            "get": {
+                "description": "Files inscribed by one batch transaction (the txId of a batched direct upload), in inscription order, with their PinIDs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get upload batch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Batch transaction ID",
+                        "name": "txId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.UploadBatchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "No uploaded files for this transaction",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/chunked-upload": {
+            "post": {
+                "description": "Upload large file by splitting it into chunks, build transactions for chunks and index, optionally broadcast all transactions in order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Chunked file upload",
+                "parameters": [
+                    {
+                        "description": "Chunked upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.ChunkedUploadRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.ChunkedUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Built transaction rejected by node policy before broadcasting (code 42200), or content not matching the declared sha256 (code 42201, data respond.ChecksumMismatchData)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.TxRejectedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/chunked-upload-task": {
+            "post": {
+                "description": "Create an async chunked upload task and return the task ID so the client can poll for progress.\nWith broadcastAt and/or targetFeeRate the transactions are built at once and the task waits in status scheduled until the time arrives or the network fee rate drops to the target; the fee rate is re-checked before broadcasting.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Async chunked upload (create task)",
+                "parameters": [
+                    {
+                        "description": "Async chunked upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.ChunkedUploadForTaskRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.ChunkedUploadTaskResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameter",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Content does not match the declared sha256 (code 42201)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.ChecksumMismatchData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Upload rate limit exceeded (code 42900)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RateLimitedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
                         "schema": {
                             "$ref": "#/definitions/meta-file-system_controller_respond.Response"
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "controller_handler.AbortMultipartUploadRequest": {
-            "type": "object",
-            "required": [
-                "key",
-                "uploadId"
-            ],
-            "properties": {
-                "key": {
+                }
+            }
+        },
+        "/v1/files/commit-upload": {
+            "post": {
+                "description": "Submit signed transaction for broadcast",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Commit upload",
+                "parameters": [
+                    {
+                        "description": "Commit upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CommitUploadRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error or file not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Built transaction rejected by node policy before broadcasting (code 42200)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.TxRejectedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error or broadcast failed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/delegated-upload": {
+            "post": {
+                "description": "Chunked MVC upload without client-built pre-transactions: the operator hot wallet pays the estimated chunk and index funding to the user's assistant address, the uploader builds, signs and broadcasts every transaction, and the cost is recorded as an unsettled charge of the user (see /admin/delegated/invoices). The index PIN is signed by the user's assistant address and its first output pays the user. Requires uploader.delegated.enabled and an API key from uploader.delegated.api_keys in the X-Api-Key header (code 40100 otherwise); uploads estimated above uploader.delegated.max_fee_per_upload are refused.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Delegated (server-funded) chunked upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delegated upload API key",
+                        "name": "X-Api-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Delegated upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.DelegatedUploadRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Uploaded",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.DelegatedUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error or fee limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or unknown API key",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Delegated uploads disabled",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Built transaction rejected by node policy before broadcasting (code 42200), or content not matching the declared sha256 (code 42201, data respond.ChecksumMismatchData)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.TxRejectedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/delegated/charges": {
+            "get": {
+                "description": "Charges recorded for the delegated uploads requested with the caller's API key (X-Api-Key), newest first, so the client can invoice its users.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List my delegated upload charges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delegated upload API key",
+                        "name": "X-Api-Key",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only charges of this user address",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "unsettled or settled (default both)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last charge ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.DelegatedChargeList"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or unknown API key",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/direct-upload": {
+            "post": {
+                "description": "Upload file and add MetaID OP_RETURN output to existing PreTxHex, then broadcast immediately. This is a one-step upload process that combines building and broadcasting. Supports UTXO merge transaction for SIGHASH_SINGLE compatibility.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Direct upload file (one-step)",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pre-transaction hex (signed, with inputs and outputs)",
+                        "name": "preTxHex",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Merge transaction hex (optional, broadcasted before main transaction)",
+                        "name": "mergeTxHex",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type",
+                        "name": "contentType",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address (also used as change address if changeAddress is not provided)",
+                        "name": "address",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Change address (optional, defaults to address)",
+                        "name": "changeAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fee rate (satoshis per byte, optional)",
+                        "name": "feeRate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Total input amount in satoshis (optional, for automatic change calculation)",
+                        "name": "totalInputAmount",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Build and return the final transaction hex and PinID without broadcasting or saving anything",
+                        "name": "dryRun",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Gzip content before inscription when it saves at least uploader.gzip.min_saving_percent (default uploader.gzip.enabled)",
+                        "name": "gzip",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "hot",
+                        "description": "Storage class hint: hot, cold or ephemeral (local records of ephemeral files may be pruned after uploader.ephemeral_retention_hours)",
+                        "name": "storageClass",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Upload through the chunked pipeline when the payload exceeds the chain's single-PIN limit (needs indexPreTxHex; preTxHex then funds the chunks)",
+                        "name": "chunkedFallback",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Index pre-transaction hex used by chunkedFallback",
+                        "name": "indexPreTxHex",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Consent to share one transaction with other small uploads of the same fee rate (uploader.batch): preTxHex must have one input signed SIGHASH_SINGLE|ANYONECANPAY and its one owner output; totalInputAmount is required. Waits until the batch is broadcast",
+                        "name": "batch",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload successful, return transaction ID and Pin ID",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Payload too large for a single PIN (code 41300)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.PayloadTooLargeData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Built transaction rejected by node policy before broadcasting (code 42200)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.TxRejectedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Upload rate limit exceeded (code 42900)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RateLimitedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/estimate-chunked-upload": {
+            "post": {
+                "description": "Estimate the fee required for chunked file upload, including chunk count and fees for ChunkPreTxHex and IndexPreTxHex",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Estimate chunked upload fee",
+                "parameters": [
+                    {
+                        "description": "Estimate chunked upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.EstimateChunkedUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Estimate successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.EstimateChunkedUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/fetch-url": {
+            "post": {
+                "description": "Download the file at url on the server (allowlisted schemes, no private network addresses, content type and size limits from uploader.url_fetch) into storage and return its storage key, size, content type, MD5 and SHA256. Pass storageKey to estimate-chunked-upload and then chunked-upload or chunked-upload-task instead of content, so existing web assets go on-chain without the client downloading them. Only available when uploader.url_fetch.enabled is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Fetch upload content from a URL",
+                "parameters": [
+                    {
+                        "description": "Fetch request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.FetchURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.UrlFetchResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameter, URL or content not allowed, or the source failed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "URL fetch is disabled",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Upload rate limit exceeded (code 42900)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RateLimitedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/keep": {
+            "get": {
+                "description": "Files an address keeps, most recently kept first (at most 1000), with its usage and limits",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List kept files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Uploader address",
+                        "name": "address",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.KeptFiles"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Missing address",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Ask the uploader to keep the local records of an uploaded file: kept files are never pruned, whatever their storage class, until unkept. The request is signed by the key of the file's uploader address (P2PKH). Each address may keep uploader.keep.max_files_per_owner files and uploader.keep.max_mb_per_owner MB. Keeping a kept file changes nothing. Requires uploader.keep.enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Keep file",
+                "parameters": [
+                    {
+                        "description": "Signed keep request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.KeepFileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.KeepStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error, keeping disabled, or the upload did not succeed",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "40300: the signature does not prove ownership, is too old or was already used",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No uploaded file with this PIN ID",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "42901: keeping the file would exceed the owner's quota",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/multipart/abort": {
+            "post": {
+                "description": "Abort a multipart upload session and clean up resources",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Abort multipart upload",
+                "parameters": [
+                    {
+                        "description": "Abort multipart upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.AbortMultipartUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Abort successful",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/multipart/complete": {
+            "post": {
+                "description": "Complete a multipart upload session and merge all parts into a single file",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Complete multipart upload",
+                "parameters": [
+                    {
+                        "description": "Complete multipart upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CompleteMultipartUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.CompleteMultipartUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/multipart/initiate": {
+            "post": {
+                "description": "Start a multipart upload session for large file uploads with resume support",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Initiate multipart upload",
+                "parameters": [
+                    {
+                        "description": "Initiate multipart upload request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.InitiateMultipartUploadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.InitiateMultipartUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/multipart/list-parts": {
+            "post": {
+                "description": "List all uploaded parts in a multipart upload session for resume support",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List uploaded parts",
+                "parameters": [
+                    {
+                        "description": "List parts request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.ListPartsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.ListPartsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/multipart/upload-part": {
+            "post": {
+                "description": "Upload a single part of the file in a multipart upload session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Upload part",
+                "parameters": [
+                    {
+                        "description": "Upload part request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.UploadPartRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.UploadPartResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/pre-upload": {
+            "post": {
+                "description": "Upload file and generate unsigned transaction, return transaction for client signing",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Pre-upload file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "path",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "create",
+                        "description": "Operation type",
+                        "name": "operation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content type",
+                        "name": "contentType",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Change address",
+                        "name": "changeAddress",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID",
+                        "name": "metaId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Address",
+                        "name": "address",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Fee rate",
+                        "name": "feeRate",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output list json",
+                        "name": "outputs",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Other output list json",
+                        "name": "otherOutputs",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Gzip content before inscription when it saves at least uploader.gzip.min_saving_percent (default uploader.gzip.enabled)",
+                        "name": "gzip",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "default": "hot",
+                        "description": "Storage class hint: hot, cold or ephemeral (local records of ephemeral files may be pruned after uploader.ephemeral_retention_hours)",
+                        "name": "storageClass",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "SHA256 of the file (hex); a file received with another checksum is rejected before any transaction is built",
+                        "name": "sha256",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pre-upload successful, return transaction and file info",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.PreUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "413": {
+                        "description": "Payload too large for a single PIN (code 41300)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.PayloadTooLargeData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "File does not match the declared sha256 (code 42201)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.ChecksumMismatchData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Upload rate limit exceeded (code 42900)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RateLimitedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/task/{taskId}": {
+            "get": {
+                "description": "Get async upload task progress and status by task ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Query task progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadTaskDetailResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameter",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/task/{taskId}/cancel": {
+            "post": {
+                "description": "Cancel an async chunked upload task of the address while none of its transactions has been broadcast (status pending or scheduled). Its built transactions are dropped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Cancel upload task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Task owner",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CancelUploadTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadTask"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameter, or the task is already broadcasting or finished",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/tasks": {
+            "get": {
+                "description": "List chunked upload tasks for a given address with cursor-based pagination",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List upload tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User address",
+                        "name": "address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last task ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadTaskListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/tasks/stats": {
+            "get": {
+                "description": "Counts async upload tasks finished in the last hours by outcome (completed, failed, cancelled, expired) and the expired rate expired / (completed + expired), plus the tasks still pending, processing or scheduled. Tasks that broadcast nothing within uploader.task_ttl are expired by the cleanup processor.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Upload task statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 24,
+                        "description": "Window in hours (at most 720)",
+                        "name": "hours",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.TaskStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/unkeep": {
+            "post": {
+                "description": "Release a kept file: its storage class applies again and it no longer counts against the owner's quota. Signed like keep requests with the action unkeep. Requires uploader.keep.enabled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Unkeep file",
+                "parameters": [
+                    {
+                        "description": "Signed unkeep request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.KeepFileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.KeepStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error or keeping disabled",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "40300: the signature does not prove ownership, is too old or was already used",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "The file is not kept",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/upload-cost": {
+            "get": {
+                "description": "Estimate the cost of a direct (single OP_RETURN transaction) upload versus a chunked upload for a file of the given size on each supported chain at the configured fee rates, with a recommended mode and break-even guidance. No file content is needed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Upload cost calculator",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "File size in bytes",
+                        "name": "fileSize",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "File content type (default application/octet-stream)",
+                        "name": "contentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "File name (included in the chunked index)",
+                        "name": "fileName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base MetaID path (default /file)",
+                        "name": "path",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Blockchain: mvc or doge (default every configured chain)",
+                        "name": "chain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fee rate override (default chain config)",
+                        "name": "feeRate",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Estimate successful",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.UploadCostResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/uploads": {
+            "get": {
+                "description": "Upload history of a MetaID and/or address, newest first, with cursor pagination and an optional status filter. When the indexer shares the uploader's MySQL database each file carries the indexer state of its PIN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List uploaded files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Uploader address (address and/or metaId is required)",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID (address and/or metaId is required)",
+                        "name": "metaId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload status: pending, success or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Cursor (last file ID)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFileListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/files/uploads/{fileId}": {
+            "get": {
+                "description": "Details of an uploaded file by file ID, including the chunks of a chunked upload and, when the indexer shares the uploader's MySQL database, the indexer state of its PIN.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "Get uploaded file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID (metaId_fileHash)",
+                        "name": "fileId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/proofs": {
+            "post": {
+                "description": "Inscribe just a SHA256 hash (no content) under /proof/sha256, with the same pre-tx flow as direct upload, and record it so GET /proofs/{hash} can return its verification data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proof"
+                ],
+                "summary": "Create proof of existence",
+                "parameters": [
+                    {
+                        "description": "Proof request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controller_handler.CreateProofRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries sent with the same key and request get the first response back (Idempotent-Replayed: true) instead of running again; keys are kept uploader.idempotency.ttl_hours",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction ID and Pin ID of the proof",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/controller_handler.CommitUploadResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key used with another request, or its first request still running (code 40900); an input reserved by another upload in flight (code 40901)",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "429": {
+                        "description": "Upload rate limit exceeded (code 42900)",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.RateLimitedData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/proofs/{hash}": {
+            "get": {
+                "description": "Inscriptions of a hash made through POST /proofs, oldest first (at most 10). Each carries its raw transaction and, once mined, the block hash, height and time, the block merkle root, the transaction index and its merkle branch (sibling hashes from the leaf level up, hex in RPC byte order)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Proof"
+                ],
+                "summary": "Verify proof of existence",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "SHA256 (hex)",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_service_upload_service.ProofResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid hash",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "No proof recorded for this hash",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/tasks": {
+            "get": {
+                "description": "Chunked upload tasks of an address, newest first, as a v2 page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List upload tasks (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User address",
+                        "name": "address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadTaskPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v2/files/uploads": {
+            "get": {
+                "description": "Upload history of a MetaID and/or address, newest first, as a v2 page; same filters as the v1 route",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "File Upload"
+                ],
+                "summary": "List uploaded files (v2)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Uploader address (address and/or metaId is required)",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "MetaID (address and/or metaId is required)",
+                        "name": "metaId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload status: pending, success or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (max 100)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFilePage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Server error",
+                        "schema": {
+                            "$ref": "#/definitions/meta-file-system_controller_respond.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controller_handler.AbortMultipartUploadRequest": {
+            "type": "object",
+            "required": [
+                "key",
+                "uploadId"
+            ],
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "uploadId": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller_handler.CancelUploadTaskRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                }
+            },
+            "required": [
+                "address"
+            ]
+        },
+        "controller_handler.ChainConfigItem": {
+            "type": "object",
+            "properties": {
+                "chunkSize": {
+                    "type": "integer"
+                },
+                "feeRate": {
+                    "type": "integer"
+                },
+                "maxFileSize": {
+                    "type": "integer"
+                },
+                "maxSinglePayloadBytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controller_handler.ChunkedUploadForTaskRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "broadcastAt": {
+                    "type": "integer",
+                    "example": 1767225600
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "chunkPreTxHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "compression": {
+                    "type": "string",
+                    "example": "none"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentType": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "deltaBasePinId": {
+                    "type": "string",
+                    "example": "4e3f...a1i0"
+                },
+                "encryption": {
+                    "$ref": "#/definitions/meta-file-system_service_common_service_metaid_protocols.MetaFileEncryption"
+                },
+                "feeRate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "fileName": {
+                    "type": "string",
+                    "example": "example.jpg"
+                },
+                "fundingStrategy": {
+                    "type": "string",
+                    "example": "hot_wallet"
+                },
+                "indexPreTxHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "mergeTxHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "metaId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file"
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+                },
+                "storageClass": {
+                    "type": "string",
+                    "example": "hot"
+                },
+                "storageKey": {
+                    "type": "string"
+                },
+                "targetFeeRate": {
+                    "type": "integer",
+                    "example": 1
+                }
+            },
+            "required": [
+                "address",
+                "fileName",
+                "metaId",
+                "path"
+            ]
+        },
+        "controller_handler.ChunkedUploadRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "chunkPreTxHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "compression": {
+                    "type": "string",
+                    "example": "none"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentType": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "deltaBasePinId": {
+                    "type": "string",
+                    "example": "4e3f...a1i0"
+                },
+                "dryRun": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "encryption": {
+                    "$ref": "#/definitions/meta-file-system_service_common_service_metaid_protocols.MetaFileEncryption"
+                },
+                "feeRate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "fileName": {
+                    "type": "string",
+                    "example": "example.jpg"
+                },
+                "fundingStrategy": {
+                    "type": "string",
+                    "example": "hot_wallet"
+                },
+                "indexPreTxHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "isBroadcast": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "mergeTxHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "metaId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file"
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+                },
+                "storageClass": {
+                    "type": "string",
+                    "example": "hot"
+                },
+                "storageKey": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "address",
+                "fileName",
+                "indexPreTxHex",
+                "metaId",
+                "path"
+            ]
+        },
+        "controller_handler.CommitUploadRequest": {
+            "type": "object",
+            "required": [
+                "fileId",
+                "signedRawTx"
+            ],
+            "properties": {
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "signedRawTx": {
+                    "type": "string",
+                    "example": "0100000..."
+                }
+            }
+        },
+        "controller_handler.CommitUploadResponseData": {
+            "type": "object",
+            "properties": {
+                "batchSize": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "chunked": {
+                    "$ref": "#/definitions/meta-file-system_service_upload_service.ChunkedUploadResponse"
+                },
+                "dryRun": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "gzipCompressed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "pinId": {
+                    "type": "string",
+                    "example": "abc123...i0"
+                },
+                "savedFee": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "txHex": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "txId": {
+                    "type": "string",
+                    "example": "abc123..."
+                }
+            }
+        },
+        "controller_handler.CompleteMultipartUploadRequest": {
+            "type": "object",
+            "required": [
+                "key",
+                "parts",
+                "uploadId"
+            ],
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "parts": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "etag": {
+                                "type": "string"
+                            },
+                            "partNumber": {
+                                "type": "integer"
+                            },
+                            "size": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                },
+                "uploadId": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller_handler.ConfigResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/controller_handler.ChainConfigItem"
+                    }
+                },
+                "maxFileSize": {
+                    "type": "integer",
+                    "example": 10485760
+                },
+                "swaggerBaseUrl": {
+                    "type": "string",
+                    "example": "localhost:7282"
+                },
+                "faucetEnabled": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "controller_handler.CreateProofRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "changeAddress": {
+                    "type": "string"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "feeRate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "hash": {
+                    "type": "string",
+                    "example": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+                },
+                "mergeTxHex": {
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "preTxHex": {
+                    "type": "string",
+                    "example": "0a000000..."
+                },
+                "totalInputAmount": {
+                    "type": "integer"
+                }
+            },
+            "required": [
+                "hash",
+                "preTxHex"
+            ]
+        },
+        "controller_handler.DelegatedSettleRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "chargeIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "client": {
+                    "type": "string",
+                    "example": "my-app"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "payment-2026-10-001"
+                }
+            },
+            "required": [
+                "address",
+                "reference"
+            ]
+        },
+        "controller_handler.DelegatedUploadRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "compression": {
+                    "type": "string",
+                    "example": "none"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentType": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "deltaBasePinId": {
+                    "type": "string",
+                    "example": "4e3f...a1i0"
+                },
+                "encryption": {
+                    "$ref": "#/definitions/meta-file-system_service_common_service_metaid_protocols.MetaFileEncryption"
+                },
+                "feeRate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "fileName": {
+                    "type": "string",
+                    "example": "example.jpg"
+                },
+                "metaId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file"
+                },
+                "sha256": {
+                    "type": "string",
+                    "example": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+                },
+                "storageClass": {
+                    "type": "string",
+                    "example": "hot"
+                },
+                "storageKey": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "address",
+                "fileName",
+                "metaId",
+                "path"
+            ]
+        },
+        "controller_handler.EstimateChunkedUploadRequest": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "compression": {
+                    "type": "string",
+                    "example": "none"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentType": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "deltaBasePinId": {
+                    "type": "string",
+                    "example": "4e3f...a1i0"
+                },
+                "encryption": {
+                    "$ref": "#/definitions/meta-file-system_service_common_service_metaid_protocols.MetaFileEncryption"
+                },
+                "feeRate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "fileName": {
+                    "type": "string",
+                    "example": "example.jpg"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/file"
+                },
+                "storageKey": {
+                    "type": "string"
+                }
+            },
+            "required": [
+                "fileName",
+                "path"
+            ]
+        },
+        "controller_handler.FaucetRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "mhZz3V4V6JqGfKXsVgzq3wUUzFhNRJ6X3M"
+                },
+                "assistant": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "metaId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                }
+            }
+        },
+        "controller_handler.FetchURLRequest": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "fileName": {
+                    "type": "string",
+                    "example": "logo.png"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/logo.png"
+                }
+            },
+            "required": [
+                "address",
+                "url"
+            ]
+        },
+        "controller_handler.HotWalletDepositRequest": {
+            "type": "object",
+            "properties": {
+                "txId": {
+                    "type": "string",
+                    "example": "4e3f0c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+                }
+            },
+            "required": [
+                "txId"
+            ]
+        },
+        "controller_handler.InitiateMultipartUploadRequest": {
+            "type": "object",
+            "required": [
+                "fileName",
+                "fileSize"
+            ],
+            "properties": {
+                "address": {
+                    "description": "Optional, user address",
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "fileSize": {
+                    "type": "integer"
+                },
+                "metaId": {
+                    "description": "Optional, for file organization",
+                    "type": "string"
+                }
+            }
+        },
+        "controller_handler.KeepFileRequest": {
+            "type": "object",
+            "required": [
+                "pinId",
+                "publicKey",
+                "signature",
+                "timestamp"
+            ],
+            "properties": {
+                "pinId": {
+                    "type": "string",
+                    "example": "4e3f0c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2fi0"
+                },
+                "publicKey": {
+                    "type": "string",
+                    "example": "02b4632d08485ff1df2db55b9dafd23347d1c47a457072a1e87be26896549a8737"
+                },
+                "signature": {
+                    "type": "string",
+                    "example": "3045022100..."
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1767225600
+                }
+            }
+        },
+        "controller_handler.ListPartsRequest": {
+            "type": "object",
+            "required": [
+                "key",
+                "uploadId"
+            ],
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "uploadId": {
+                    "type": "string"
+                }
+            }
+        },
+        "controller_handler.PreUploadResponseData": {
+            "type": "object",
+            "properties": {
+                "calTxFee": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "calTxSize": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "fileId": {
+                    "type": "string",
+                    "example": "metaid_abc123"
+                },
+                "fileMd5": {
+                    "type": "string",
+                    "example": "5d41402abc4b2a76b9719d911017c592"
+                },
+                "filehash": {
+                    "type": "string",
+                    "example": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+                },
+                "gzipCompressed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "pinId": {
+                    "type": "string",
+                    "example": "abc123...i0"
+                },
+                "preTxRaw": {
+                    "type": "string",
+                    "example": "0100000..."
+                },
+                "savedFee": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "txId": {
+                    "type": "string",
+                    "example": "abc123..."
+                }
+            }
+        },
+        "controller_handler.RotateAssistentRequest": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "feeRate": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "force": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "key exposed in server logs"
+                },
+                "sweepTo": {
+                    "type": "string",
+                    "example": "assistent"
+                }
+            }
+        },
+        "controller_handler.UploadPartRequest": {
+            "type": "object",
+            "required": [
+                "content",
+                "key",
+                "partNumber",
+                "uploadId"
+            ],
+            "properties": {
+                "content": {
+                    "description": "Base64 encoded part data",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "Storage key from initiate",
+                    "type": "string"
+                },
+                "partNumber": {
+                    "type": "integer"
+                },
+                "uploadId": {
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.ChecksumMismatchData": {
+            "type": "object",
+            "properties": {
+                "actual": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "algorithm": {
+                    "type": "string",
+                    "example": "sha256"
+                },
+                "declared": {
+                    "type": "string",
+                    "example": "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+                },
+                "receivedSize": {
+                    "type": "integer",
+                    "example": 1048576
+                }
+            }
+        },
+        "meta-file-system_controller_respond.ChunkedUploadTaskResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "task created"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "taskId": {
+                    "type": "string",
+                    "example": "task_123"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.PayloadTooLargeData": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "maxPayloadSize": {
+                    "type": "integer",
+                    "example": 2097152
+                },
+                "payloadSize": {
+                    "type": "integer",
+                    "example": 5242880
+                },
+                "recommended": {
+                    "type": "string",
+                    "example": "chunked"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.RateLimitedData": {
+            "type": "object",
+            "properties": {
+                "burst": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "key": {
+                    "type": "string",
+                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                },
+                "perMinute": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "retryAfter": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "address"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.Response": {
+            "description": "Unified API response structure",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {},
+                "errorCode": {
+                    "type": "string",
+                    "example": "upstream_node_unreachable"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "processingTime": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "9b1c..."
+                }
+            }
+        },
+        "meta-file-system_controller_respond.TxRejectedData": {
+            "type": "object",
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "tx-size"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "local"
+                },
+                "tx": {
+                    "type": "string",
+                    "example": "chunk 3"
+                },
+                "txId": {
+                    "type": "string",
+                    "example": "b3c0...e1"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadTask": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "broadcastAt": {
+                    "description": "Scheduled broadcast time (null = broadcast at once)",
+                    "type": "string"
+                },
+                "chunkFundingTx": {
+                    "type": "string"
+                },
+                "chunkTxIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "contentType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currentStep": {
+                    "type": "string"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "fileHash": {
+                    "type": "string"
+                },
+                "fileId": {
+                    "type": "string"
+                },
+                "fileMd5": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "fileSize": {
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "indexTxId": {
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "processedChunks": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "type": "integer"
+                },
+                "releasedAt": {
+                    "description": "When a scheduled task was released for broadcast",
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "targetFeeRate": {
+                    "description": "Broadcast early once the network fee rate drops to this (0 = off)",
+                    "type": "integer"
+                },
+                "taskId": {
+                    "type": "string"
+                },
+                "totalChunks": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadTaskDetailResponse": {
+            "type": "object",
+            "properties": {
+                "task": {
+                    "$ref": "#/definitions/meta-file-system_controller_respond.UploadTask"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadTaskListResponse": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "nextCursor": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.UploadTask"
+                    }
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadTaskPage": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.UploadTask"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Opaque; empty when there is no next page",
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadedFile": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "blockHeight": {
+                    "type": "integer"
+                },
+                "chunkType": {
+                    "type": "string",
+                    "example": "multi"
+                },
+                "chunks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFileChunk"
+                    }
+                },
+                "contentUrl": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "fileContentType": {
+                    "type": "string"
+                },
+                "fileHash": {
+                    "type": "string"
+                },
+                "fileId": {
+                    "type": "string"
+                },
+                "fileMd5": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "fileSize": {
+                    "type": "integer"
+                },
+                "fileType": {
+                    "type": "string"
+                },
+                "indexer": {
+                    "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFileIndexer"
+                },
+                "isGzipCompressed": {
+                    "type": "boolean"
+                },
+                "kept": {
+                    "type": "boolean"
+                },
+                "keptAt": {
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "operation": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "pinId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                },
+                "storageClass": {
+                    "type": "string",
+                    "example": "hot"
+                },
+                "txId": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadedFileChunk": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "type": "integer"
+                },
+                "chunkHash": {
+                    "type": "string"
+                },
+                "chunkIndex": {
+                    "type": "integer"
+                },
+                "chunkSize": {
+                    "type": "integer"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "pinId": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "txId": {
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadedFileIndexer": {
+            "type": "object",
+            "properties": {
+                "blockHeight": {
+                    "type": "integer"
+                },
+                "chainName": {
+                    "type": "string"
+                },
+                "confirmed": {
+                    "type": "boolean"
+                },
+                "firstPinId": {
+                    "type": "string"
+                },
+                "indexed": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusReason": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadedFileListResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFile"
+                    }
+                },
+                "hasMore": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "nextCursor": {
+                    "type": "integer",
+                    "example": 123
+                }
+            }
+        },
+        "meta-file-system_controller_respond.UploadedFilePage": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_controller_respond.UploadedFile"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Opaque; empty when there is no next page",
+                    "type": "string",
+                    "example": "123"
+                }
+            }
+        },
+        "meta-file-system_service_common_service_metaid_protocols.MetaFileDelta": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "description": "MetaFileDeltaMfsV1",
+                    "type": "string"
+                },
+                "basePinId": {
+                    "description": "PIN whose indexed content the delta applies to (may itself be a delta)",
+                    "type": "string"
+                },
+                "baseSha256": {
+                    "description": "sha256 of the base content",
+                    "type": "string"
+                },
+                "targetSha256": {
+                    "description": "sha256 of the rebuilt file",
+                    "type": "string"
+                },
+                "targetSize": {
+                    "description": "Rebuilt file size in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_common_service_metaid_protocols.MetaFileEncryption": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "description": "e.g. aes-256-gcm, aes-256-ctr",
+                    "type": "string"
+                },
+                "encryptedKey": {
+                    "description": "Content key wrapped for the recipient (base64)",
+                    "type": "string"
+                },
+                "iv": {
+                    "description": "IV / nonce (base64 or hex, algorithm specific)",
+                    "type": "string"
+                },
+                "keyId": {
+                    "description": "Identifier of the key (or recipient) the content key belongs to",
+                    "type": "string"
+                },
+                "plainSha256": {
+                    "description": "sha256 of the plaintext",
+                    "type": "string"
+                },
+                "plainSize": {
+                    "description": "Plaintext size in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.AssistentBalance": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "User address (per-user rows)",
+                    "type": "string"
+                },
+                "assistentAddress": {
+                    "description": "Assistent address (per-user rows)",
+                    "type": "string"
+                },
+                "balance": {
+                    "description": "Satoshis in unspent outputs",
+                    "type": "integer",
+                    "example": 30000
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "mvc"
+                },
+                "feesPaid": {
+                    "description": "received - balance",
+                    "type": "integer",
+                    "example": 2470000
+                },
+                "metaId": {
+                    "description": "User MetaID (per-user rows)",
+                    "type": "string"
+                },
+                "outputs": {
+                    "description": "Outputs paid to assistent addresses",
+                    "type": "integer",
+                    "example": 120
+                },
+                "pendingOutputs": {
+                    "description": "Unspent outputs",
+                    "type": "integer",
+                    "example": 2
+                },
+                "received": {
+                    "description": "Satoshis paid in by users",
+                    "type": "integer",
+                    "example": 2500000
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.AssistentDashboard": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "Offset of the next page of users",
+                    "type": "integer"
+                },
+                "totals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.AssistentBalance"
+                    }
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.AssistentBalance"
+                    }
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.AssistentDetail": {
+            "type": "object",
+            "properties": {
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.AssistentBalance"
+                    }
+                },
+                "pendingOutputs": {
+                    "description": "Oldest first, at most 200",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AssistentUtxo"
+                    }
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.AssistentRotationList": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "Pass as cursor for the next page",
+                    "type": "integer"
+                },
+                "rotations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AssistentRotation"
+                    }
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.ChainUploadCost": {
+            "type": "object",
+            "properties": {
+                "breakEvenFileSize": {
+                    "description": "Smallest size (in chunk-size steps) from which chunked costs no more than direct; 0 if never within the direct limit",
+                    "type": "integer"
+                },
+                "chain": {
+                    "description": "Blockchain",
+                    "type": "string"
+                },
+                "chunked": {
+                    "description": "Chunked upload estimate",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-file-system_service_upload_service.ChunkedUploadCost"
+                        }
+                    ]
+                },
+                "direct": {
+                    "description": "Direct upload estimate",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-file-system_service_upload_service.DirectUploadCost"
+                        }
+                    ]
+                },
+                "directMaxFileSize": {
+                    "description": "Direct upload size limit in bytes (0 = unlimited)",
+                    "type": "integer"
+                },
+                "dustLimit": {
+                    "description": "Smallest output/funding value used (satoshis)",
+                    "type": "integer"
+                },
+                "feeRateUnit": {
+                    "description": "sat/byte (mvc) or sat/KB (doge)",
+                    "type": "string"
+                },
+                "guidance": {
+                    "description": "Human-readable recommendation",
+                    "type": "string"
+                },
+                "maxFileSize": {
+                    "description": "Chunked upload size limit in bytes",
+                    "type": "integer"
+                },
+                "minChange": {
+                    "description": "Change below this is left to the fee (satoshis)",
+                    "type": "integer"
+                },
+                "recommended": {
+                    "description": "Cheapest supported mode: direct or chunked (empty if none)",
+                    "type": "string"
+                },
+                "savings": {
+                    "description": "Satoshis saved by the recommended mode over the other one (0 if only one is supported)",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.ChunkedUploadCost": {
+            "type": "object",
+            "properties": {
+                "chunkNumber": {
+                    "description": "Number of chunks",
+                    "type": "integer"
+                },
+                "chunkPreTxFee": {
+                    "description": "Funding required for chunk transactions",
+                    "type": "integer"
+                },
+                "chunkSize": {
+                    "description": "Chunk size in bytes",
+                    "type": "integer"
+                },
+                "feeRate": {
+                    "description": "Fee rate used",
+                    "type": "integer"
+                },
+                "indexPreTxFee": {
+                    "description": "Funding required for the index transaction",
+                    "type": "integer"
+                },
+                "perChunkFee": {
+                    "description": "Average fee per chunk",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Why chunked upload is not possible",
+                    "type": "string"
+                },
+                "supported": {
+                    "description": "Whether chunked upload is possible for this file on this chain",
+                    "type": "boolean"
+                },
+                "totalFee": {
+                    "description": "Estimated total fee in satoshis",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.ChunkedUploadResponse": {
+            "type": "object",
+            "properties": {
+                "chunkFundingTx": {
+                    "description": "Funding transaction for chunk outputs",
+                    "type": "string"
+                },
+                "chunkNumber": {
+                    "description": "Number of chunks",
+                    "type": "integer"
+                },
+                "chunkPinIds": {
+                    "description": "Chunk PinIDs (dry run only)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunkRevealTxIds": {
+                    "description": "DOGE: reveal tx id per chunk for index",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunkTxIds": {
+                    "description": "Chunk transaction IDs (flat, for broadcast)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunkTxs": {
+                    "description": "Chunk transaction hex list (ordered)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "dryRun": {
+                    "description": "Nothing was broadcast or saved",
+                    "type": "boolean"
+                },
+                "fileHash": {
+                    "description": "File SHA256 hash",
+                    "type": "string"
+                },
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "fileMd5": {
+                    "description": "File MD5 hash",
+                    "type": "string"
+                },
+                "indexPinId": {
+                    "description": "Index PinID (dry run only)",
+                    "type": "string"
+                },
+                "indexTx": {
+                    "description": "Index transaction hex (MVC single tx)",
+                    "type": "string"
+                },
+                "indexTxId": {
+                    "description": "Index transaction ID",
+                    "type": "string"
+                },
+                "indexTxs": {
+                    "description": "DOGE: index txs [commitHex, revealHex]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "description": "Additional message",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status string",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.CompleteMultipartUploadResponse": {
+            "type": "object",
+            "properties": {
+                "fileSize": {
+                    "description": "Total file size",
+                    "type": "integer"
+                },
+                "key": {
+                    "description": "Storage key",
+                    "type": "string"
+                },
+                "uploadId": {
+                    "description": "Upload ID",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.DelegatedChargeList": {
+            "type": "object",
+            "properties": {
+                "charges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DelegatedCharge"
+                    }
+                },
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "Pass as cursor for the next page",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.DelegatedInvoice": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "Satoshis",
+                    "type": "integer",
+                    "example": 840000
+                },
+                "client": {
+                    "type": "string",
+                    "example": "my-app"
+                },
+                "firstAt": {
+                    "description": "Oldest charge",
+                    "type": "string"
+                },
+                "lastAt": {
+                    "description": "Newest charge",
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "uploads": {
+                    "description": "Charges",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.DelegatedInvoiceList": {
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "invoices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.DelegatedInvoice"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Offset of the next page",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Charges included",
+                    "type": "string",
+                    "example": "unsettled"
+                },
+                "total": {
+                    "description": "Satoshis on this page",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.DelegatedSettleResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Satoshis settled",
+                    "type": "integer",
+                    "example": 210000
+                },
+                "charges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.DelegatedCharge"
+                    }
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "settled": {
+                    "description": "Charges settled",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.DelegatedUploadResponse": {
+            "type": "object",
+            "properties": {
+                "chargeId": {
+                    "description": "Charge recorded for the upload",
+                    "type": "integer",
+                    "example": 42
+                },
+                "charged": {
+                    "description": "Satoshis sent by the hot wallet, invoiced to the user",
+                    "type": "integer",
+                    "example": 125000
+                },
+                "chunkFundingTx": {
+                    "description": "Funding transaction for chunk outputs",
+                    "type": "string"
+                },
+                "chunkNumber": {
+                    "description": "Number of chunks",
+                    "type": "integer"
+                },
+                "chunkPinIds": {
+                    "description": "Chunk PinIDs (dry run only)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunkRevealTxIds": {
+                    "description": "DOGE: reveal tx id per chunk for index",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunkTxIds": {
+                    "description": "Chunk transaction IDs (flat, for broadcast)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunkTxs": {
+                    "description": "Chunk transaction hex list (ordered)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "dryRun": {
+                    "description": "Nothing was broadcast or saved",
+                    "type": "boolean"
+                },
+                "fileHash": {
+                    "description": "File SHA256 hash",
+                    "type": "string"
+                },
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "fileMd5": {
+                    "description": "File MD5 hash",
+                    "type": "string"
+                },
+                "indexPinId": {
+                    "description": "Index PinID (dry run only)",
+                    "type": "string"
+                },
+                "indexTx": {
+                    "description": "Index transaction hex (MVC single tx)",
+                    "type": "string"
+                },
+                "indexTxId": {
+                    "description": "Index transaction ID",
+                    "type": "string"
+                },
+                "indexTxs": {
+                    "description": "DOGE: index txs [commitHex, revealHex]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "message": {
+                    "description": "Additional message",
                     "type": "string"
                 },
-                "uploadId": {
+                "status": {
+                    "description": "Status string",
                     "type": "string"
                 }
             }
         },
-        "controller_handler.ChainConfigItem": {
+        "meta-file-system_service_upload_service.DirectUploadCost": {
             "type": "object",
             "properties": {
-                "chunkSize": {
+                "feeRate": {
+                    "description": "Fee rate used",
                     "type": "integer"
                 },
-                "feeRate": {
+                "reason": {
+                    "description": "Why direct upload is not possible",
+                    "type": "string"
+                },
+                "supported": {
+                    "description": "Whether direct upload is possible for this file on this chain",
+                    "type": "boolean"
+                },
+                "totalFee": {
+                    "description": "Estimated total fee in satoshis",
                     "type": "integer"
                 },
-                "maxFileSize": {
+                "txSize": {
+                    "description": "Estimated transaction size in bytes",
                     "type": "integer"
                 }
             }
         },
-        "controller_handler.ChunkedUploadForTaskRequest": {
+        "meta-file-system_service_upload_service.EstimateChunkedUploadResponse": {
             "type": "object",
-            "required": [
-                "address",
-                "chunkPreTxHex",
-                "fileName",
-                "metaId",
-                "path"
-            ],
             "properties": {
-                "address": {
-                    "type": "string",
-                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
-                },
                 "chain": {
-                    "type": "string",
-                    "example": "mvc"
-                },
-                "chunkPreTxHex": {
-                    "type": "string",
-                    "example": "0100000..."
-                },
-                "content": {
+                    "description": "Blockchain used for estimate (mvc, doge, etc.)",
                     "type": "string"
                 },
-                "contentType": {
-                    "type": "string",
-                    "example": "image/jpeg"
+                "chunkFees": {
+                    "description": "Fee per chunk",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 },
-                "feeRate": {
-                    "type": "integer",
-                    "example": 1
+                "chunkNumber": {
+                    "description": "Number of chunks",
+                    "type": "integer"
                 },
-                "fileName": {
-                    "type": "string",
-                    "example": "example.jpg"
+                "chunkPerTxFee": {
+                    "description": "Fee required per chunk transaction",
+                    "type": "integer"
                 },
-                "indexPreTxHex": {
-                    "type": "string",
-                    "example": "0100000..."
+                "chunkPreTxFee": {
+                    "description": "Total funding required for chunk transactions",
+                    "type": "integer"
                 },
-                "mergeTxHex": {
-                    "type": "string",
-                    "example": "0100000..."
+                "chunkSize": {
+                    "description": "Chunk size in bytes",
+                    "type": "integer"
                 },
-                "metaId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                "delta": {
+                    "description": "Delta uploads: the base and the rebuilt file",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-file-system_service_common_service_metaid_protocols.MetaFileDelta"
+                        }
+                    ]
                 },
-                "operation": {
-                    "type": "string",
-                    "example": "create"
+                "deltaSize": {
+                    "description": "Delta uploads: bytes inscribed instead of the file",
+                    "type": "integer"
                 },
-                "path": {
-                    "type": "string",
-                    "example": "/file"
+                "dustLimit": {
+                    "description": "Smallest output/funding value used (satoshis)",
+                    "type": "integer"
                 },
-                "storageKey": {
+                "indexPreTxFee": {
+                    "description": "Funding required for the index transaction",
+                    "type": "integer"
+                },
+                "message": {
+                    "description": "Additional message",
                     "type": "string"
+                },
+                "minChange": {
+                    "description": "Change below this is left to the fee (satoshis)",
+                    "type": "integer"
+                },
+                "perChunkFee": {
+                    "description": "Average fee per chunk",
+                    "type": "integer"
+                },
+                "totalFee": {
+                    "description": "Total fee (ChunkPreTxFee + IndexPreTxFee)",
+                    "type": "integer"
                 }
             }
         },
-        "controller_handler.ChunkedUploadRequest": {
+        "meta-file-system_service_upload_service.FaucetResponse": {
             "type": "object",
-            "required": [
-                "address",
-                "chunkPreTxHex",
-                "fileName",
-                "indexPreTxHex",
-                "metaId",
-                "path"
-            ],
             "properties": {
                 "address": {
-                    "type": "string",
-                    "example": "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+                    "description": "Funded address",
+                    "type": "string"
                 },
-                "chunkPreTxHex": {
-                    "type": "string",
-                    "example": "0100000..."
+                "amount": {
+                    "description": "Satoshis sent",
+                    "type": "integer"
                 },
-                "content": {
+                "assistant": {
+                    "description": "Whether Address is the user's assistant address",
+                    "type": "boolean"
+                },
+                "chain": {
+                    "description": "Funded chain (mvc)",
                     "type": "string"
                 },
-                "contentType": {
+                "privateKeyHex": {
+                    "description": "Private key of a freshly generated address (testnet only)",
+                    "type": "string"
+                },
+                "privateKeyWif": {
+                    "description": "WIF of a freshly generated address (testnet only)",
+                    "type": "string"
+                },
+                "txId": {
+                    "description": "Funding transaction ID",
+                    "type": "string"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.HotWalletBalance": {
+            "type": "object",
+            "properties": {
+                "outputs": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "description": "available, locked (by an upload not broadcast yet), pending (change not broadcast yet) or spent",
                     "type": "string",
-                    "example": "image/jpeg"
+                    "example": "available"
                 },
-                "feeRate": {
+                "value": {
+                    "description": "Satoshis",
+                    "type": "integer",
+                    "example": 4200000
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.HotWalletDeposit": {
+            "type": "object",
+            "properties": {
+                "outputs": {
+                    "description": "Outputs paying the hot wallet",
                     "type": "integer",
                     "example": 1
                 },
-                "fileName": {
-                    "type": "string",
-                    "example": "example.jpg"
+                "txId": {
+                    "type": "string"
                 },
-                "indexPreTxHex": {
-                    "type": "string",
-                    "example": "0100000..."
+                "value": {
+                    "description": "Satoshis",
+                    "type": "integer",
+                    "example": 5000000
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.HotWalletStatus": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "Hot wallet address; send deposits here",
+                    "type": "string"
                 },
-                "isBroadcast": {
-                    "type": "boolean",
-                    "example": false
+                "allowRequestStrategy": {
+                    "description": "Requests may pick the strategy",
+                    "type": "boolean"
                 },
-                "mergeTxHex": {
-                    "type": "string",
-                    "example": "0100000..."
+                "balance": {
+                    "description": "Satoshis not spent: available, locked and pending",
+                    "type": "integer",
+                    "example": 4200000
                 },
-                "metaId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.HotWalletBalance"
+                    }
                 },
-                "operation": {
-                    "type": "string",
-                    "example": "create"
+                "maxPerUpload": {
+                    "type": "integer",
+                    "example": 1000000
                 },
-                "path": {
+                "strategy": {
+                    "description": "Deployment strategy (uploader.chunk_funding.strategy)",
                     "type": "string",
-                    "example": "/file"
-                },
-                "storageKey": {
-                    "type": "string"
+                    "example": "hot_wallet"
                 }
             }
         },
-        "controller_handler.CommitUploadRequest": {
+        "meta-file-system_service_upload_service.InitiateMultipartUploadResponse": {
             "type": "object",
-            "required": [
-                "fileId",
-                "signedRawTx"
-            ],
             "properties": {
-                "fileId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                "key": {
+                    "description": "Storage key",
+                    "type": "string"
                 },
-                "signedRawTx": {
-                    "type": "string",
-                    "example": "0100000..."
+                "uploadId": {
+                    "description": "Upload ID for subsequent operations",
+                    "type": "string"
                 }
             }
         },
-        "controller_handler.CommitUploadResponseData": {
+        "meta-file-system_service_upload_service.KeepStatus": {
             "type": "object",
             "properties": {
                 "fileId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                    "type": "string"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "success"
+                "kept": {
+                    "description": "The file is kept: never pruned",
+                    "type": "boolean"
+                },
+                "keptAt": {
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Uploader address of the file",
+                    "type": "string"
                 },
                 "pinId": {
-                    "type": "string",
-                    "example": "abc123...i0"
+                    "type": "string"
                 },
-                "status": {
-                    "type": "string",
-                    "example": "success"
+                "releasedAt": {
+                    "type": "string"
+                },
+                "usage": {
+                    "description": "Owner's kept files and limits after the request",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/meta-file-system_service_upload_service.KeepUsage"
+                        }
+                    ]
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.KeepUsage": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "description": "Total size of the files kept",
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "files": {
+                    "description": "Files kept",
+                    "type": "integer",
+                    "example": 3
+                },
+                "maxBytes": {
+                    "description": "Total size the owner may keep (uploader.keep.max_mb_per_owner)",
+                    "type": "integer",
+                    "example": 0
                 },
-                "txId": {
-                    "type": "string",
-                    "example": "abc123..."
+                "maxFiles": {
+                    "description": "Files the owner may keep (uploader.keep.max_files_per_owner)",
+                    "type": "integer",
+                    "example": 100
                 }
             }
         },
-        "controller_handler.CompleteMultipartUploadRequest": {
+        "meta-file-system_service_upload_service.KeptFiles": {
             "type": "object",
-            "required": [
-                "key",
-                "parts",
-                "uploadId"
-            ],
             "properties": {
-                "key": {
+                "files": {
+                    "description": "Most recently kept first, at most 1000",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.FileRetention"
+                    }
+                },
+                "owner": {
                     "type": "string"
                 },
+                "usage": {
+                    "$ref": "#/definitions/meta-file-system_service_upload_service.KeepUsage"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.ListPartsResponse": {
+            "type": "object",
+            "properties": {
                 "parts": {
                     "type": "array",
                     "items": {
-                        "type": "object",
-                        "properties": {
-                            "etag": {
-                                "type": "string"
-                            },
-                            "partNumber": {
-                                "type": "integer"
-                            },
-                            "size": {
-                                "type": "integer"
-                            }
-                        }
+                        "$ref": "#/definitions/storage.PartInfo"
                     }
                 },
                 "uploadId": {
@@ -1164,493 +5254,593 @@ const docTemplateuploader = `{
                 }
             }
         },
-        "controller_handler.ConfigResponse": {
+        "meta-file-system_service_upload_service.PendingUploadBatch": {
             "type": "object",
             "properties": {
-                "chains": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/controller_handler.ChainConfigItem"
-                    }
+                "closesAt": {
+                    "description": "When the batch is broadcast unless it fills up first",
+                    "type": "string"
                 },
-                "maxFileSize": {
-                    "type": "integer",
-                    "example": 10485760
+                "feeRate": {
+                    "description": "Fee rate shared by the batch (sat/byte)",
+                    "type": "integer"
                 },
-                "swaggerBaseUrl": {
-                    "type": "string",
-                    "example": "localhost:7282"
+                "files": {
+                    "description": "Uploads collected so far",
+                    "type": "integer"
                 }
             }
         },
-        "controller_handler.EstimateChunkedUploadRequest": {
+        "meta-file-system_service_upload_service.ProofResponse": {
             "type": "object",
-            "required": [
-                "fileName",
-                "path"
-            ],
             "properties": {
-                "chain": {
-                    "type": "string",
-                    "example": "mvc"
-                },
-                "content": {
+                "hash": {
+                    "description": "Notarized SHA256",
                     "type": "string"
                 },
-                "contentType": {
-                    "type": "string",
-                    "example": "image/jpeg"
-                },
-                "feeRate": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "fileName": {
-                    "type": "string",
-                    "example": "example.jpg"
-                },
-                "path": {
-                    "type": "string",
-                    "example": "/file"
-                },
-                "storageKey": {
-                    "type": "string"
+                "proofs": {
+                    "description": "Inscriptions of the hash, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.ProofVerification"
+                    }
                 }
             }
         },
-        "controller_handler.InitiateMultipartUploadRequest": {
+        "meta-file-system_service_upload_service.ProofVerification": {
             "type": "object",
-            "required": [
-                "fileName",
-                "fileSize"
-            ],
             "properties": {
                 "address": {
-                    "description": "Optional, user address",
+                    "description": "Address of the notarizer",
                     "type": "string"
                 },
-                "fileName": {
+                "blockHash": {
+                    "description": "Block containing the transaction",
                     "type": "string"
                 },
-                "fileSize": {
+                "blockHeight": {
+                    "description": "Height of that block",
+                    "type": "integer"
+                },
+                "blockTime": {
+                    "description": "Block timestamp (unix seconds)",
                     "type": "integer"
                 },
+                "confirmations": {
+                    "description": "Confirmations when queried",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "When the uploader broadcast it",
+                    "type": "string"
+                },
+                "merkleProof": {
+                    "description": "Sibling hashes from the leaf level up (see docs)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "merkleRoot": {
+                    "description": "Merkle root of the block header",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "Why the status is unavailable",
+                    "type": "string"
+                },
                 "metaId": {
-                    "description": "Optional, for file organization",
+                    "description": "MetaID of the notarizer",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "Pin ID",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "confirmed, unconfirmed or unavailable",
+                    "type": "string"
+                },
+                "txHex": {
+                    "description": "Raw transaction (its OP_RETURN carries the hash)",
                     "type": "string"
+                },
+                "txId": {
+                    "description": "Inscription transaction ID",
+                    "type": "string"
+                },
+                "txIndex": {
+                    "description": "Position of the transaction in the block",
+                    "type": "integer"
                 }
             }
         },
-        "controller_handler.ListPartsRequest": {
+        "meta-file-system_service_upload_service.RecoverUploadsResponse": {
             "type": "object",
-            "required": [
-                "key",
-                "uploadId"
-            ],
             "properties": {
-                "key": {
+                "failed": {
+                    "description": "Uploads marked failed",
+                    "type": "integer"
+                },
+                "succeeded": {
+                    "description": "Uploads whose index transaction is now broadcast",
+                    "type": "integer"
+                },
+                "uploads": {
+                    "description": "Uploads picked up, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.RecoveredUpload"
+                    }
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.RecoveredUpload": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Broadcast error (failed)",
                     "type": "string"
                 },
-                "uploadId": {
+                "fileId": {
+                    "description": "File ID",
+                    "type": "string"
+                },
+                "fromStage": {
+                    "description": "Stage the upload was resumed from",
+                    "type": "string"
+                },
+                "indexTxId": {
+                    "description": "Index transaction ID (success)",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "success, failed, or pending when its progress could not be saved",
                     "type": "string"
                 }
             }
         },
-        "controller_handler.PreUploadResponseData": {
+        "meta-file-system_service_upload_service.TaskStats": {
             "type": "object",
             "properties": {
-                "calTxFee": {
+                "cancelled": {
+                    "description": "Cancelled before broadcast",
                     "type": "integer",
-                    "example": 1000
+                    "example": 1
                 },
-                "calTxSize": {
+                "completed": {
+                    "description": "Finished with success",
                     "type": "integer",
-                    "example": 500
-                },
-                "fileId": {
-                    "type": "string",
-                    "example": "metaid_abc123"
+                    "example": 95
                 },
-                "fileMd5": {
-                    "type": "string",
-                    "example": "5d41402abc4b2a76b9719d911017c592"
+                "expired": {
+                    "description": "Expired by uploader.task_ttl",
+                    "type": "integer",
+                    "example": 5
                 },
-                "filehash": {
-                    "type": "string",
-                    "example": "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+                "expiredRate": {
+                    "description": "expired / (completed + expired); 0 when both are 0",
+                    "type": "number",
+                    "example": 0.05
                 },
-                "message": {
-                    "type": "string",
-                    "example": "success"
+                "failed": {
+                    "description": "Finished with an error",
+                    "type": "integer",
+                    "example": 2
                 },
-                "pinId": {
-                    "type": "string",
-                    "example": "abc123...i0"
+                "hours": {
+                    "description": "Window: tasks finished in the last hours",
+                    "type": "integer",
+                    "example": 24
                 },
-                "preTxRaw": {
-                    "type": "string",
-                    "example": "0100000..."
+                "pending": {
+                    "description": "Open now: waiting for the task processor",
+                    "type": "integer",
+                    "example": 3
                 },
-                "status": {
-                    "type": "string",
-                    "example": "pending"
+                "processing": {
+                    "description": "Open now: being built or broadcast",
+                    "type": "integer",
+                    "example": 1
                 },
-                "txId": {
-                    "type": "string",
-                    "example": "abc123..."
+                "scheduled": {
+                    "description": "Open now: broadcast held (broadcastAt / targetFeeRate)",
+                    "type": "integer",
+                    "example": 4
                 }
             }
         },
-        "controller_handler.UploadPartRequest": {
+        "meta-file-system_service_upload_service.UploadBatchFile": {
             "type": "object",
-            "required": [
-                "content",
-                "key",
-                "partNumber",
-                "uploadId"
-            ],
             "properties": {
-                "content": {
-                    "description": "Base64 encoded part data",
+                "address": {
+                    "description": "Uploader address",
                     "type": "string"
                 },
-                "key": {
-                    "description": "Storage key from initiate",
+                "fileId": {
+                    "description": "File ID",
                     "type": "string"
                 },
-                "partNumber": {
-                    "type": "integer"
+                "fileName": {
+                    "description": "File name",
+                    "type": "string"
                 },
-                "uploadId": {
+                "pinId": {
+                    "description": "PinID of the file's inscription",
                     "type": "string"
                 }
             }
         },
-        "meta-file-system_controller_respond.ChunkedUploadTaskResponse": {
+        "meta-file-system_service_upload_service.UploadBatchResponse": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string",
-                    "example": "task created"
-                },
-                "status": {
-                    "type": "string",
-                    "example": "pending"
+                "files": {
+                    "description": "Files in inscription order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.UploadBatchFile"
+                    }
                 },
-                "taskId": {
-                    "type": "string",
-                    "example": "task_123"
+                "txId": {
+                    "description": "Batch transaction ID",
+                    "type": "string"
                 }
             }
         },
-        "meta-file-system_controller_respond.Response": {
-            "description": "Unified API response structure",
+        "meta-file-system_service_upload_service.UploadBatchStatusResponse": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 0
+                "enabled": {
+                    "description": "uploader.batch.enabled",
+                    "type": "boolean"
                 },
-                "data": {},
-                "errorCode": {
-                    "type": "string",
-                    "example": "upstream_node_unreachable"
+                "maxFileBytes": {
+                    "description": "Largest payload accepted into a batch",
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string",
-                    "example": "success"
+                "maxFiles": {
+                    "description": "Uploads per batch",
+                    "type": "integer"
+                },
+                "pending": {
+                    "description": "Open batches of this uploader instance",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.PendingUploadBatch"
+                    }
+                },
+                "windowSeconds": {
+                    "description": "Collect time after a batch's first upload",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.UploadCostResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "description": "Per-chain comparison",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/meta-file-system_service_upload_service.ChainUploadCost"
+                    }
+                },
+                "contentType": {
+                    "description": "MIME type used for the estimate",
+                    "type": "string"
                 },
-                "processingTime": {
-                    "type": "integer",
-                    "example": 123
+                "fileSize": {
+                    "description": "File size in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "meta-file-system_service_upload_service.UploadPartResponse": {
+            "type": "object",
+            "properties": {
+                "etag": {
+                    "description": "ETag for this part",
+                    "type": "string"
                 },
-                "requestId": {
-                    "type": "string",
-                    "example": "9b1c..."
+                "partNumber": {
+                    "description": "Part number",
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_controller_respond.UploadTask": {
+        "meta-file-system_service_upload_service.UrlFetchResponse": {
             "type": "object",
             "properties": {
-                "address": {
+                "contentType": {
+                    "description": "Content type from the response, sniffed when missing or generic",
                     "type": "string"
                 },
-                "chunkFundingTx": {
+                "fileHash": {
+                    "description": "Hex SHA256 of the content",
                     "type": "string"
                 },
-                "chunkTxIds": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "fileMd5": {
+                    "description": "Hex MD5 of the content",
+                    "type": "string"
                 },
-                "contentType": {
+                "fileName": {
+                    "description": "File name to upload under",
                     "type": "string"
                 },
-                "createdAt": {
+                "fileSize": {
+                    "description": "Size in bytes",
+                    "type": "integer"
+                },
+                "finalUrl": {
+                    "description": "URL the content was served from, after redirects",
                     "type": "string"
                 },
-                "currentStep": {
+                "sourceUrl": {
+                    "description": "Requested URL",
                     "type": "string"
                 },
-                "errorMessage": {
+                "storageKey": {
+                    "description": "Pass as storageKey to estimate-chunked-upload, chunked-upload or chunked-upload-task",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AssistentRotation": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "User address",
                     "type": "string"
                 },
-                "fileHash": {
+                "chain": {
+                    "description": "mvc/doge",
                     "type": "string"
                 },
-                "fileId": {
+                "created_at": {
                     "type": "string"
                 },
-                "fileMd5": {
+                "id": {
+                    "type": "integer"
+                },
+                "meta_id": {
+                    "description": "User MetaID",
                     "type": "string"
                 },
-                "fileName": {
+                "new_assistent_address": {
+                    "description": "New assistant address",
                     "type": "string"
                 },
-                "fileSize": {
+                "new_assistent_id": {
+                    "description": "tb_file_assistent row replacing it",
                     "type": "integer"
                 },
-                "finishedAt": {
+                "old_assistent_address": {
+                    "description": "Retired assistant address",
                     "type": "string"
                 },
-                "indexTxId": {
-                    "type": "string"
+                "old_assistent_id": {
+                    "description": "Retired tb_file_assistent row",
+                    "type": "integer"
                 },
-                "metaId": {
+                "reason": {
+                    "description": "Why the key was rotated",
                     "type": "string"
                 },
-                "operation": {
+                "requested_by": {
+                    "description": "Client IP of the admin request",
                     "type": "string"
                 },
-                "path": {
+                "sweep_address": {
+                    "description": "Address the outputs were swept to",
                     "type": "string"
                 },
-                "processedChunks": {
-                    "type": "integer"
+                "sweep_error": {
+                    "description": "Why the latest sweep failed",
+                    "type": "string"
                 },
-                "progress": {
+                "sweep_fee": {
+                    "description": "Satoshis paid in sweep fees",
                     "type": "integer"
                 },
-                "stage": {
+                "sweep_status": {
+                    "description": "none/success/failed",
                     "type": "string"
                 },
-                "startedAt": {
+                "sweep_to": {
+                    "description": "assistent (new assistant address) or user",
                     "type": "string"
                 },
-                "status": {
+                "sweep_tx_id": {
+                    "description": "Latest sweep transaction",
                     "type": "string"
                 },
-                "taskId": {
-                    "type": "string"
+                "swept_outputs": {
+                    "description": "Outputs of the old address swept so far",
+                    "type": "integer"
                 },
-                "totalChunks": {
+                "swept_value": {
+                    "description": "Satoshis received by SweepAddress",
                     "type": "integer"
                 },
-                "updatedAt": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "meta-file-system_controller_respond.UploadTaskDetailResponse": {
-            "type": "object",
-            "properties": {
-                "task": {
-                    "$ref": "#/definitions/meta-file-system_controller_respond.UploadTask"
-                }
-            }
-        },
-        "meta-file-system_controller_respond.UploadTaskListResponse": {
+        "model.AssistentUtxo": {
             "type": "object",
             "properties": {
-                "hasMore": {
-                    "type": "boolean",
-                    "example": true
+                "address": {
+                    "description": "User address",
+                    "type": "string"
                 },
-                "nextCursor": {
-                    "type": "integer",
-                    "example": 123
+                "assistent_address": {
+                    "description": "Assistant address holding the output",
+                    "type": "string"
                 },
-                "tasks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/meta-file-system_controller_respond.UploadTask"
-                    }
+                "chain": {
+                    "description": "mvc/doge",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_change": {
+                    "description": "Created by a transaction spending assistant outputs (not funded by the user)",
+                    "type": "boolean"
+                },
+                "meta_id": {
+                    "description": "User MetaID",
+                    "type": "string"
+                },
+                "spent_at": {
+                    "type": "string"
+                },
+                "spent_tx_id": {
+                    "description": "Transaction spending the output, empty while unspent",
+                    "type": "string"
+                },
+                "tx_id": {
+                    "description": "Transaction creating the output",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Satoshis",
+                    "type": "integer"
+                },
+                "vout": {
+                    "description": "Output index",
+                    "type": "integer"
                 }
             }
         },
-        "meta-file-system_service_upload_service.ChunkedUploadResponse": {
+        "model.DelegatedCharge": {
             "type": "object",
             "properties": {
-                "chunkFundingTx": {
-                    "description": "Funding transaction for chunk outputs",
+                "address": {
+                    "description": "User address, invoiced",
                     "type": "string"
                 },
-                "chunkNumber": {
-                    "description": "Number of chunks",
+                "amount": {
+                    "description": "Satoshis sent by the hot wallet (node wallet fees not included)",
                     "type": "integer"
                 },
-                "chunkRevealTxIds": {
-                    "description": "DOGE: reveal tx id per chunk for index",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "chain": {
+                    "description": "mvc",
+                    "type": "string"
                 },
-                "chunkTxIds": {
-                    "description": "Chunk transaction IDs (flat, for broadcast)",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "chunk_funding_tx_id": {
+                    "description": "Hot wallet payment funding the chunk transactions",
+                    "type": "string"
                 },
-                "chunkTxs": {
-                    "description": "Chunk transaction hex list (ordered)",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "client": {
+                    "description": "API client (uploader.delegated.api_keys name) that requested the upload",
+                    "type": "string"
                 },
-                "fileHash": {
-                    "description": "File SHA256 hash",
+                "created_at": {
                     "type": "string"
                 },
-                "fileId": {
-                    "description": "File ID",
+                "file_id": {
+                    "description": "Uploaded file",
                     "type": "string"
                 },
-                "fileMd5": {
-                    "description": "File MD5 hash",
+                "file_size": {
+                    "description": "Bytes uploaded",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index_funding_tx_id": {
+                    "description": "Hot wallet payment funding the index transaction",
                     "type": "string"
                 },
-                "indexTx": {
-                    "description": "Index transaction hex (MVC single tx)",
+                "index_tx_id": {
+                    "description": "Index transaction of the upload",
                     "type": "string"
                 },
-                "indexTxId": {
-                    "description": "Index transaction ID",
+                "message": {
+                    "description": "Upload outcome message",
                     "type": "string"
                 },
-                "indexTxs": {
-                    "description": "DOGE: index txs [commitHex, revealHex]",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "meta_id": {
+                    "description": "User MetaID",
+                    "type": "string"
                 },
-                "message": {
-                    "description": "Additional message",
+                "settled_at": {
+                    "type": "string"
+                },
+                "settlement_ref": {
+                    "description": "Payment reference given when settling (txid, invoice number, ...)",
                     "type": "string"
                 },
                 "status": {
-                    "description": "Status string",
+                    "description": "unsettled/settled",
                     "type": "string"
-                }
-            }
-        },
-        "meta-file-system_service_upload_service.CompleteMultipartUploadResponse": {
-            "type": "object",
-            "properties": {
-                "fileSize": {
-                    "description": "Total file size",
-                    "type": "integer"
                 },
-                "key": {
-                    "description": "Storage key",
+                "updated_at": {
                     "type": "string"
                 },
-                "uploadId": {
-                    "description": "Upload ID",
+                "upload_status": {
+                    "description": "Upload outcome: success/pending/failed",
                     "type": "string"
                 }
             }
         },
-        "meta-file-system_service_upload_service.EstimateChunkedUploadResponse": {
+        "model.FileRetention": {
             "type": "object",
             "properties": {
-                "chain": {
-                    "description": "Blockchain used for estimate (mvc, doge, etc.)",
+                "created_at": {
                     "type": "string"
                 },
-                "chunkFees": {
-                    "description": "Fee per chunk",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                },
-                "chunkNumber": {
-                    "description": "Number of chunks",
-                    "type": "integer"
+                "file_id": {
+                    "description": "Uploaded file record",
+                    "type": "string"
                 },
-                "chunkPerTxFee": {
-                    "description": "Fee required per chunk transaction",
+                "file_size": {
+                    "description": "Counted against the owner's quota",
                     "type": "integer"
                 },
-                "chunkPreTxFee": {
-                    "description": "Total funding required for chunk transactions",
+                "id": {
                     "type": "integer"
                 },
-                "chunkSize": {
-                    "description": "Chunk size in bytes",
-                    "type": "integer"
+                "kept_at": {
+                    "description": "When the file was (last) kept",
+                    "type": "string"
                 },
-                "indexPreTxFee": {
-                    "description": "Funding required for the index transaction",
-                    "type": "integer"
+                "owner": {
+                    "description": "Uploader address of the file",
+                    "type": "string"
                 },
-                "message": {
-                    "description": "Additional message",
+                "pin_id": {
+                    "description": "Kept PIN",
                     "type": "string"
                 },
-                "perChunkFee": {
-                    "description": "Average fee per chunk",
-                    "type": "integer"
+                "public_key": {
+                    "description": "Key that signed the latest request",
+                    "type": "string"
                 },
-                "totalFee": {
-                    "description": "Total fee (ChunkPreTxFee + IndexPreTxFee)",
-                    "type": "integer"
-                }
-            }
-        },
-        "meta-file-system_service_upload_service.InitiateMultipartUploadResponse": {
-            "type": "object",
-            "properties": {
-                "key": {
-                    "description": "Storage key",
+                "released_at": {
+                    "description": "When it was unkept",
                     "type": "string"
                 },
-                "uploadId": {
-                    "description": "Upload ID for subsequent operations",
+                "signature": {
+                    "description": "Latest request signature (DER, hex)",
                     "type": "string"
-                }
-            }
-        },
-        "meta-file-system_service_upload_service.ListPartsResponse": {
-            "type": "object",
-            "properties": {
-                "parts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/storage.PartInfo"
-                    }
                 },
-                "uploadId": {
+                "signed_at": {
+                    "description": "Timestamp of the latest request; older requests are refused",
                     "type": "string"
-                }
-            }
-        },
-        "meta-file-system_service_upload_service.UploadPartResponse": {
-            "type": "object",
-            "properties": {
-                "etag": {
-                    "description": "ETag for this part",
+                },
+                "status": {
+                    "description": "active/released",
                     "type": "string"
                 },
-                "partNumber": {
-                    "description": "Part number",
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
@@ -1675,7 +5865,7 @@ const docTemplateuploader = `{
 var SwaggerInfouploader = &swag.Spec{
 	Version:          "1.0",
 	Host:             "localhost:7282",
-	BasePath:         "/api/v1",
+	BasePath:         "/api",
 	Schemes:          []string{"https", "http"},
 	Title:            "Meta File System Uploader API",
 	Description:      "Meta File System Upload Service API, provides file upload functionality",
@@ -0,0 +1,78 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"meta-file-system/model"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type encoder func(event *model.IndexerChangeEvent) ([]byte, error)
+
+func encoderFor(format string) (encoder, error) {
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		return encodeJSON, nil
+	case FormatProtobuf:
+		return encodeProtobuf, nil
+	default:
+		return nil, fmt.Errorf("event stream: unsupported format %q (want %s or %s)", format, FormatJSON, FormatProtobuf)
+	}
+}
+
+// encodeJSON encodes event as served by /api/v1/changes
+func encodeJSON(event *model.IndexerChangeEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+// Field numbers of metafs.stream.v1.IndexerEvent (indexer_event.proto)
+const (
+	fieldSeq         protowire.Number = 1
+	fieldAction      protowire.Number = 2
+	fieldChainName   protowire.Number = 3
+	fieldPinID       protowire.Number = 4
+	fieldFirstPinID  protowire.Number = 5
+	fieldMetaID      protowire.Number = 6
+	fieldBlockHeight protowire.Number = 7
+	fieldTimestamp   protowire.Number = 8
+	fieldRecord      protowire.Number = 9
+	fieldCreatedAt   protowire.Number = 10
+)
+
+// encodeProtobuf encodes event as a metafs.stream.v1.IndexerEvent. Like proto3
+// encoders, zero values are left out.
+func encodeProtobuf(event *model.IndexerChangeEvent) ([]byte, error) {
+	var b []byte
+	appendInt := func(num protowire.Number, v int64) {
+		if v != 0 {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(v))
+		}
+	}
+	appendString := func(num protowire.Number, v string) {
+		if v != "" {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendString(b, v)
+		}
+	}
+
+	appendInt(fieldSeq, event.Seq)
+	appendString(fieldAction, string(event.Action))
+	appendString(fieldChainName, event.ChainName)
+	appendString(fieldPinID, event.PinID)
+	appendString(fieldFirstPinID, event.FirstPinID)
+	appendString(fieldMetaID, event.MetaId)
+	appendInt(fieldBlockHeight, event.BlockHeight)
+	appendInt(fieldTimestamp, event.Timestamp)
+	if len(event.Record) > 0 {
+		b = protowire.AppendTag(b, fieldRecord, protowire.BytesType)
+		b = protowire.AppendBytes(b, event.Record)
+	}
+	if !event.CreatedAt.IsZero() {
+		appendInt(fieldCreatedAt, event.CreatedAt.UnixMilli())
+	}
+	return b, nil
+}
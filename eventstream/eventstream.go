@@ -0,0 +1,99 @@
+// Package eventstream publishes indexing events (the entries of the indexer
+// change log: new files, profile updates, chunk merges ...) to a message bus,
+// so data pipelines can consume them without polling the HTTP API.
+//
+// Two brokers are supported without extra client libraries: NATS, spoken
+// natively over TCP, and Kafka through a Kafka REST Proxy (v2 API). Events are
+// published to {topic_prefix}.{action} keyed by the file they belong to, as
+// JSON or as the protobuf message of indexer_event.proto.
+package eventstream
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"meta-file-system/model"
+)
+
+// Brokers
+const (
+	BrokerNATS  = "nats"
+	BrokerKafka = "kafka"
+)
+
+// Serialization formats
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+)
+
+// DefaultTopicPrefix prefix of subjects / topics when none is configured
+const DefaultTopicPrefix = "metafs"
+
+// Message one event ready to publish
+type Message struct {
+	Topic string // {prefix}.{action}
+	Key   string // Partition key: the file (first PIN) or PIN the event is about
+	ID    string // Change log Seq, for deduplication by the broker or consumers
+	Value []byte // Encoded event
+}
+
+// Publisher publishes messages to a broker. Publish returns once the broker
+// accepted every message; on error none of them may be assumed delivered.
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+// Config publisher settings (indexer.event_stream)
+type Config struct {
+	Broker      string   // nats | kafka
+	URLs        []string // NATS servers (nats://[user:pass@]host:port, tls:// for TLS) or Kafka REST Proxy base URLs
+	TopicPrefix string
+	Format      string // json | protobuf
+}
+
+// New creates the publisher of cfg.Broker
+func New(cfg Config) (Publisher, error) {
+	if len(cfg.URLs) == 0 {
+		return nil, fmt.Errorf("event stream: no broker URLs configured")
+	}
+	if _, err := encoderFor(cfg.Format); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(cfg.Broker) {
+	case BrokerNATS:
+		return newNATSPublisher(cfg.URLs), nil
+	case BrokerKafka:
+		return newKafkaPublisher(cfg.URLs), nil
+	default:
+		return nil, fmt.Errorf("event stream: unsupported broker %q (want %s or %s)", cfg.Broker, BrokerNATS, BrokerKafka)
+	}
+}
+
+// Topic subject / topic of action under prefix
+func Topic(prefix string, action model.ChangeAction) string {
+	if prefix == "" {
+		prefix = DefaultTopicPrefix
+	}
+	return prefix + "." + string(action)
+}
+
+// NewMessage encodes event in format as a message of its action's topic
+func NewMessage(event *model.IndexerChangeEvent, prefix, format string) (Message, error) {
+	encode, err := encoderFor(format)
+	if err != nil {
+		return Message{}, err
+	}
+	value, err := encode(event)
+	if err != nil {
+		return Message{}, err
+	}
+	key := event.FirstPinID
+	if key == "" {
+		key = event.PinID
+	}
+	return Message{Topic: Topic(prefix, event.Action), Key: key, ID: strconv.FormatInt(event.Seq, 10), Value: value}, nil
+}
@@ -0,0 +1,205 @@
+package eventstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"meta-file-system/model"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func testEvent() *model.IndexerChangeEvent {
+	return &model.IndexerChangeEvent{
+		Seq:         42,
+		Action:      model.ChangeChunksMerged,
+		ChainName:   "mvc",
+		PinID:       "idx1i0",
+		FirstPinID:  "first1i0",
+		MetaId:      "meta1",
+		BlockHeight: 100,
+		Timestamp:   1700000000000,
+		Record:      json.RawMessage(`{"pin_id":"idx1i0"}`),
+		CreatedAt:   time.UnixMilli(1700000000123),
+	}
+}
+
+func TestNewMessageProtobuf(t *testing.T) {
+	msg, err := NewMessage(testEvent(), "", FormatProtobuf)
+	if err != nil {
+		t.Fatalf("NewMessage: %v", err)
+	}
+	if msg.Topic != "metafs.chunks_merged" || msg.Key != "first1i0" || msg.ID != "42" {
+		t.Errorf("message = %+v", msg)
+	}
+
+	fields := map[protowire.Number]interface{}{}
+	for b := msg.Value; len(b) > 0; {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("bad tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			fields[num], b = int64(v), b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			fields[num], b = string(v), b[n:]
+		default:
+			t.Fatalf("field %d has wire type %d", num, typ)
+		}
+	}
+	want := map[protowire.Number]interface{}{
+		fieldSeq: int64(42), fieldAction: "chunks_merged", fieldChainName: "mvc", fieldPinID: "idx1i0",
+		fieldFirstPinID: "first1i0", fieldMetaID: "meta1", fieldBlockHeight: int64(100), fieldTimestamp: int64(1700000000000),
+		fieldRecord: `{"pin_id":"idx1i0"}`, fieldCreatedAt: int64(1700000000123),
+	}
+	for num, v := range want {
+		if fields[num] != v {
+			t.Errorf("field %d = %v, want %v", num, fields[num], v)
+		}
+	}
+}
+
+func TestNewMessageRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewMessage(testEvent(), "x", "avro"); err == nil {
+		t.Error("want error for unknown format")
+	}
+	if _, err := New(Config{Broker: "rabbitmq", URLs: []string{"amqp://x"}}); err == nil {
+		t.Error("want error for unknown broker")
+	}
+}
+
+// fakeNATS accepts one client, answers its PINGs and collects published payloads by subject
+func fakeNATS(t *testing.T, headers bool) (string, chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	got := make(chan string, 16)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte(`INFO {"server_id":"test","headers":` + strconv.FormatBool(headers) + "}\r\n"))
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			parts := strings.Fields(line)
+			switch {
+			case len(parts) == 0:
+			case parts[0] == "PING":
+				conn.Write([]byte("PONG\r\n"))
+			case parts[0] == "PUB" || parts[0] == "HPUB":
+				size, _ := strconv.Atoi(parts[len(parts)-1])
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				got <- parts[1] + " " + string(payload[:size])
+			}
+		}
+	}()
+	return "nats://" + ln.Addr().String(), got
+}
+
+func TestNATSPublish(t *testing.T) {
+	for _, headers := range []bool{false, true} {
+		addr, got := fakeNATS(t, headers)
+		pub, err := New(Config{Broker: BrokerNATS, URLs: []string{addr}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs := []Message{{Topic: "metafs.file_created", ID: "1", Value: []byte("a")}, {Topic: "metafs.avatar_updated", ID: "2", Value: []byte("bc")}}
+		if err := pub.Publish(context.Background(), msgs); err != nil {
+			t.Fatalf("Publish (headers %v): %v", headers, err)
+		}
+		pub.Close()
+
+		want := []string{"metafs.file_created a", "metafs.avatar_updated bc"}
+		if headers {
+			want = []string{"metafs.file_created NATS/1.0\r\nNats-Msg-Id: 1\r\n\r\na", "metafs.avatar_updated NATS/1.0\r\nNats-Msg-Id: 2\r\n\r\nbc"}
+		}
+		for _, w := range want {
+			if g := <-got; g != w {
+				t.Errorf("published %q, want %q", g, w)
+			}
+		}
+	}
+}
+
+func TestNATSPublishUnreachable(t *testing.T) {
+	pub, _ := New(Config{Broker: BrokerNATS, URLs: []string{"nats://127.0.0.1:1"}})
+	if err := pub.Publish(context.Background(), []Message{{Topic: "t", Value: []byte("x")}}); err == nil {
+		t.Error("want error for unreachable server")
+	}
+}
+
+func TestKafkaPublish(t *testing.T) {
+	type request struct {
+		topic   string
+		records []kafkaRecord
+	}
+	got := make(chan request, 4)
+	failing := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/vnd.kafka.binary.v2+json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		var body struct {
+			Records []kafkaRecord `json:"records"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if failing {
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":null,"error_code":50003,"error":"leader not available"}]}`))
+			return
+		}
+		got <- request{strings.TrimPrefix(r.URL.Path, "/topics/"), body.Records}
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`))
+	}))
+	defer srv.Close()
+
+	pub, err := New(Config{Broker: BrokerKafka, URLs: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs := []Message{
+		{Topic: "metafs.file_created", Key: "pin1", Value: []byte("a")},
+		{Topic: "metafs.file_created", Key: "pin2", Value: []byte("b")},
+	}
+	if err := pub.Publish(context.Background(), msgs); err == nil {
+		t.Fatal("want error for a record the proxy failed")
+	}
+
+	failing = false
+	if err := pub.Publish(context.Background(), msgs); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	req := <-got
+	if req.topic != "metafs.file_created" || len(req.records) != 2 {
+		t.Fatalf("request = %+v", req)
+	}
+	key, _ := base64.StdEncoding.DecodeString(req.records[1].Key)
+	value, _ := base64.StdEncoding.DecodeString(req.records[1].Value)
+	if string(key) != "pin2" || string(value) != "b" {
+		t.Errorf("record = %q/%q, want pin2/b", key, value)
+	}
+}
@@ -0,0 +1,115 @@
+package eventstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const kafkaTimeout = 30 * time.Second
+
+// kafkaPublisher produces to Kafka through a Kafka REST Proxy (v2 API,
+// POST /topics/{topic}). Values are sent as binary, so JSON and protobuf
+// events are stored byte for byte. Proxies are tried in order.
+type kafkaPublisher struct {
+	urls   []string
+	client *http.Client
+}
+
+func newKafkaPublisher(urls []string) *kafkaPublisher {
+	return &kafkaPublisher{urls: urls, client: &http.Client{Timeout: kafkaTimeout}}
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key,omitempty"` // base64
+	Value string `json:"value"`         // base64
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int     `json:"partition"`
+		Offset    int64   `json:"offset"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msgs []Message) error {
+	// One request per topic, records in their original order
+	var topics []string
+	records := make(map[string][]kafkaRecord)
+	for _, m := range msgs {
+		if _, ok := records[m.Topic]; !ok {
+			topics = append(topics, m.Topic)
+		}
+		rec := kafkaRecord{Value: base64.StdEncoding.EncodeToString(m.Value)}
+		if m.Key != "" {
+			rec.Key = base64.StdEncoding.EncodeToString([]byte(m.Key))
+		}
+		records[m.Topic] = append(records[m.Topic], rec)
+	}
+
+	for _, topic := range topics {
+		body, err := json.Marshal(map[string][]kafkaRecord{"records": records[topic]})
+		if err != nil {
+			return err
+		}
+		var lastErr error
+		for _, base := range p.urls {
+			if lastErr = p.produce(ctx, base, topic, body); lastErr == nil {
+				break
+			}
+		}
+		if lastErr != nil {
+			return lastErr
+		}
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) produce(ctx context.Context, base, topic string, body []byte) error {
+	endpoint := strings.TrimRight(base, "/") + "/topics/" + url.PathEscape(topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.binary.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka: produce to %s: %w", topic, err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka: produce to %s: status %d: %s", topic, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result kafkaProduceResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("kafka: produce to %s: invalid response: %w", topic, err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil || offset.Error != nil {
+			msg := ""
+			if offset.Error != nil {
+				msg = *offset.Error
+			}
+			return fmt.Errorf("kafka: produce to %s partition %d failed: %s", topic, offset.Partition, msg)
+		}
+	}
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,216 @@
+package eventstream
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const natsTimeout = 10 * time.Second
+
+// natsPublisher publishes over the NATS client protocol. Messages are sent as
+// PUB (HPUB with a Nats-Msg-Id header when the server supports headers, so a
+// JetStream stream drops redelivered events) and confirmed with a PING/PONG
+// round trip. The connection is opened on first use and reopened after an
+// error, trying the servers in order.
+type natsPublisher struct {
+	urls []string
+
+	mu      sync.Mutex
+	conn    net.Conn
+	r       *bufio.Reader
+	headers bool // Server supports HPUB
+}
+
+func newNATSPublisher(urls []string) *natsPublisher {
+	return &natsPublisher{urls: urls}
+}
+
+// natsInfo the fields of the server INFO used here
+type natsInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	Headers     bool `json:"headers"`
+}
+
+// natsConnect the CONNECT options sent to the server
+type natsConnect struct {
+	Verbose     bool   `json:"verbose"`
+	Pedantic    bool   `json:"pedantic"`
+	TLSRequired bool   `json:"tls_required"`
+	Name        string `json:"name"`
+	Lang        string `json:"lang"`
+	Version     string `json:"version"`
+	Protocol    int    `json:"protocol"`
+	Headers     bool   `json:"headers"`
+	User        string `json:"user,omitempty"`
+	Pass        string `json:"pass,omitempty"`
+	AuthToken   string `json:"auth_token,omitempty"`
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, msgs []Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.publish(ctx, msgs); err != nil {
+		p.closeLocked()
+		return err
+	}
+	return nil
+}
+
+func (p *natsPublisher) publish(ctx context.Context, msgs []Message) error {
+	p.conn.SetDeadline(deadline(ctx))
+	w := bufio.NewWriter(p.conn)
+	for _, m := range msgs {
+		if p.headers && m.ID != "" {
+			hdr := "NATS/1.0\r\nNats-Msg-Id: " + m.ID + "\r\n\r\n"
+			fmt.Fprintf(w, "HPUB %s %d %d\r\n%s", m.Topic, len(hdr), len(hdr)+len(m.Value), hdr)
+		} else {
+			fmt.Fprintf(w, "PUB %s %d\r\n", m.Topic, len(m.Value))
+		}
+		w.Write(m.Value)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("PING\r\n")
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("nats: publish: %w", err)
+	}
+	return p.awaitPong()
+}
+
+// connect opens a connection to the first server that accepts it
+func (p *natsPublisher) connect(ctx context.Context) error {
+	var lastErr error
+	for _, raw := range p.urls {
+		if err := p.dial(ctx, raw); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return lastErr
+}
+
+func (p *natsPublisher) dial(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("nats: invalid server URL %q", raw)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	dialer := &net.Dialer{Timeout: natsTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return fmt.Errorf("nats: connect %s: %w", host, err)
+	}
+	conn.SetDeadline(deadline(ctx))
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("nats: %s sent no INFO: %v", host, err)
+	}
+	var info natsInfo
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line[len("INFO "):])), &info); err != nil {
+		conn.Close()
+		return fmt.Errorf("nats: invalid INFO from %s: %w", host, err)
+	}
+
+	useTLS := u.Scheme == "tls" || info.TLSRequired
+	if useTLS {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return fmt.Errorf("nats: TLS handshake with %s: %w", host, err)
+		}
+		conn, r = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	opts := natsConnect{
+		TLSRequired: useTLS,
+		Name:        "meta-file-system-indexer",
+		Lang:        "go",
+		Version:     "1.0.0",
+		Protocol:    1,
+		Headers:     info.Headers,
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts.User, opts.Pass = u.User.Username(), pass
+		} else {
+			opts.AuthToken = u.User.Username()
+		}
+	}
+	data, _ := json.Marshal(opts)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", data); err != nil {
+		conn.Close()
+		return fmt.Errorf("nats: CONNECT to %s: %w", host, err)
+	}
+
+	p.conn, p.r, p.headers = conn, r, info.Headers
+	if err := p.awaitPong(); err != nil {
+		p.closeLocked()
+		return err
+	}
+	return nil
+}
+
+// awaitPong reads until the server answers the last PING, replying to its own
+// PINGs; -ERR (bad credentials, invalid subject ...) fails
+func (p *natsPublisher) awaitPong() error {
+	for {
+		line, err := p.r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("nats: read: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("nats: write: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *natsPublisher) closeLocked() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.r = nil, nil
+	}
+}
+
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked()
+	return nil
+}
+
+// deadline of ctx, or natsTimeout from now when it has none
+func deadline(ctx context.Context) time.Time {
+	if d, ok := ctx.Deadline(); ok {
+		return d
+	}
+	return time.Now().Add(natsTimeout)
+}
@@ -0,0 +1,159 @@
+package indexer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Block archive layout: the magic "MFSBLKA1", the chain name (one length byte
+// and the name), then one record per block: height (uint64 little-endian),
+// size (uint32 little-endian) and the serialized block. Heights ascend
+// without gaps.
+const blockArchiveMagic = "MFSBLKA1"
+
+// BlockArchive reads a block archive written by BlockArchiveWriter
+type BlockArchive struct {
+	f       *os.File
+	chain   string
+	first   int64
+	offsets []int64 // Of each block's data, index = height - first
+	sizes   []uint32
+}
+
+// OpenBlockArchive indexes the block archive at path
+func OpenBlockArchive(path string) (*BlockArchive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	a := &BlockArchive{f: f}
+	if err := a.index(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+func (a *BlockArchive) index() error {
+	r := bufio.NewReader(a.f)
+	magic := make([]byte, len(blockArchiveMagic)+1)
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic[:len(blockArchiveMagic)]) != blockArchiveMagic {
+		return errors.New("not a block archive")
+	}
+	chain := make([]byte, magic[len(blockArchiveMagic)])
+	if _, err := io.ReadFull(r, chain); err != nil {
+		return fmt.Errorf("truncated archive header: %w", err)
+	}
+	a.chain = string(chain)
+
+	offset := int64(len(magic) + len(chain))
+	record := make([]byte, 12)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("truncated record at offset %d", offset)
+		}
+		height := int64(binary.LittleEndian.Uint64(record[:8]))
+		size := binary.LittleEndian.Uint32(record[8:])
+		if len(a.offsets) == 0 {
+			a.first = height
+		} else if height != a.first+int64(len(a.offsets)) {
+			return fmt.Errorf("block %d follows block %d", height, a.first+int64(len(a.offsets))-1)
+		}
+		if _, err := r.Discard(int(size)); err != nil {
+			return fmt.Errorf("truncated block %d", height)
+		}
+		a.offsets = append(a.offsets, offset+int64(len(record)))
+		a.sizes = append(a.sizes, size)
+		offset += int64(len(record)) + int64(size)
+	}
+}
+
+// Chain returns the chain the archive's blocks belong to
+func (a *BlockArchive) Chain() string {
+	return a.chain
+}
+
+// Heights implements BlockSource; last < first for an empty archive
+func (a *BlockArchive) Heights() (int64, int64) {
+	return a.first, a.first + int64(len(a.offsets)) - 1
+}
+
+// RawBlock implements BlockSource
+func (a *BlockArchive) RawBlock(height int64) ([]byte, error) {
+	i := height - a.first
+	if i < 0 || i >= int64(len(a.offsets)) {
+		first, last := a.Heights()
+		return nil, fmt.Errorf("block %d is not in the archive (%d to %d)", height, first, last)
+	}
+	raw := make([]byte, a.sizes[i])
+	if _, err := a.f.ReadAt(raw, a.offsets[i]); err != nil {
+		return nil, fmt.Errorf("block %d: %w", height, err)
+	}
+	return raw, nil
+}
+
+// Close implements BlockSource
+func (a *BlockArchive) Close() error {
+	return a.f.Close()
+}
+
+// BlockArchiveWriter writes a block archive
+type BlockArchiveWriter struct {
+	f    *os.File
+	w    *bufio.Writer
+	next int64 // Height expected next; -1 before the first block
+}
+
+// CreateBlockArchive creates (or truncates) the block archive at path for the
+// blocks of chain
+func CreateBlockArchive(path, chain string) (*BlockArchiveWriter, error) {
+	if len(chain) == 0 || len(chain) > 255 {
+		return nil, fmt.Errorf("invalid chain name %q", chain)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &BlockArchiveWriter{f: f, w: bufio.NewWriterSize(f, 1<<20), next: -1}
+	w.w.WriteString(blockArchiveMagic)
+	w.w.WriteByte(byte(len(chain)))
+	w.w.WriteString(chain)
+	return w, nil
+}
+
+// Append writes the block at height, which must follow the previous one
+func (w *BlockArchiveWriter) Append(height int64, raw []byte) error {
+	if w.next >= 0 && height != w.next {
+		return fmt.Errorf("block %d appended after block %d", height, w.next-1)
+	}
+	if int64(len(raw)) > int64(^uint32(0)) {
+		return fmt.Errorf("block %d is too large for the archive: %d bytes", height, len(raw))
+	}
+	var record [12]byte
+	binary.LittleEndian.PutUint64(record[:8], uint64(height))
+	binary.LittleEndian.PutUint32(record[8:], uint32(len(raw)))
+	if _, err := w.w.Write(record[:]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(raw); err != nil {
+		return err
+	}
+	w.next = height + 1
+	return nil
+}
+
+// Close flushes and closes the archive
+func (w *BlockArchiveWriter) Close() error {
+	err := w.w.Flush()
+	if closeErr := w.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
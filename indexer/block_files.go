@@ -0,0 +1,234 @@
+package indexer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// BlockSource reads raw blocks by height from disk, so the chain can be
+// replayed without the node's RPC (cmd/replay)
+type BlockSource interface {
+	// Heights returns the first and last height available
+	Heights() (first, last int64)
+	// RawBlock returns the serialized block at height
+	RawBlock(height int64) ([]byte, error)
+	Close() error
+}
+
+// blockHeaderSize serialized size of a block header
+const blockHeaderSize = 80
+
+// blockLocation where a block's data is stored in the block files
+type blockLocation struct {
+	file   int
+	offset int64
+	size   uint32
+}
+
+// BlockFiles reads blocks from the block files of a bitcoind or mvcd data
+// directory (blocks/blk*.dat). The files hold blocks in the order the node
+// received them, so OpenBlockFiles links them by their previous block hash
+// from the genesis block to find the height of each block of the best chain.
+// Files obfuscated with the key in xor.dat (Bitcoin Core 28 and later) are
+// read as well. The node may keep running, but blocks it writes after
+// OpenBlockFiles are not seen.
+type BlockFiles struct {
+	files  []string
+	xorKey []byte
+	chain  []blockLocation // Index = height
+
+	open    *os.File // Block file read last
+	openIdx int
+}
+
+// OpenBlockFiles indexes the block files in dir. Block files of a pruned node
+// lack the early blocks, so heights cannot be derived: export the blocks to a
+// block archive instead.
+func OpenBlockFiles(dir string) (*BlockFiles, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "blk*.dat"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no blk*.dat files in %s", dir)
+	}
+	sort.Strings(files)
+
+	b := &BlockFiles{files: files, openIdx: -1}
+	if key, err := os.ReadFile(filepath.Join(dir, "xor.dat")); err == nil {
+		if len(key) > 0 && !bytes.Equal(key, make([]byte, len(key))) {
+			b.xorKey = key
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	type entry struct {
+		prev [32]byte
+		loc  blockLocation
+	}
+	blocks := make(map[[32]byte]entry)
+	var magic []byte
+	for i := range files {
+		err := b.scanFile(i, func(header []byte, loc blockLocation, fileMagic []byte) error {
+			if magic == nil {
+				magic = append([]byte(nil), fileMagic...)
+			} else if !bytes.Equal(magic, fileMagic) {
+				return fmt.Errorf("%s: block at offset %d has network magic %x, expected %x", files[i], loc.offset, fileMagic, magic)
+			}
+			var prev [32]byte
+			copy(prev[:], header[4:36])
+			blocks[blockHash(header)] = entry{prev: prev, loc: loc}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Link the blocks to their parents from the genesis block (all-zero
+	// previous hash) and keep the longest branch
+	children := make(map[[32]byte][][32]byte)
+	var genesis [][32]byte
+	for hash, e := range blocks {
+		if e.prev == ([32]byte{}) {
+			genesis = append(genesis, hash)
+			continue
+		}
+		children[e.prev] = append(children[e.prev], hash)
+	}
+	if len(genesis) != 1 {
+		return nil, fmt.Errorf("found %d genesis blocks in %s, expected 1 (block files of a pruned node cannot be replayed)", len(genesis), dir)
+	}
+	heights := map[[32]byte]int64{genesis[0]: 0}
+	tip, tipHeight := genesis[0], int64(0)
+	queue := [][32]byte{genesis[0]}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		for _, child := range children[hash] {
+			height := heights[hash] + 1
+			heights[child] = height
+			if height > tipHeight {
+				tip, tipHeight = child, height
+			}
+			queue = append(queue, child)
+		}
+	}
+
+	b.chain = make([]blockLocation, tipHeight+1)
+	for hash, height := tip, tipHeight; height >= 0; height-- {
+		e := blocks[hash]
+		b.chain[height] = e.loc
+		hash = e.prev
+	}
+	return b, nil
+}
+
+// blockHash the double SHA-256 of a block header, in internal byte order
+func blockHash(header []byte) [32]byte {
+	first := sha256.Sum256(header)
+	return sha256.Sum256(first[:])
+}
+
+// scanFile calls fn with the header of each block in file i. Zeroed space
+// preallocated by the node ends a file, and so does a block the node is still
+// writing.
+func (b *BlockFiles) scanFile(i int, fn func(header []byte, loc blockLocation, magic []byte) error) error {
+	f, err := os.Open(b.files[i])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, 8+blockHeaderSize)
+	for offset := int64(0); offset+int64(len(prefix)) <= info.Size(); {
+		if err := b.readAt(f, prefix, offset); err != nil {
+			return fmt.Errorf("%s: %w", b.files[i], err)
+		}
+		magic := prefix[:4]
+		if bytes.Equal(magic, []byte{0, 0, 0, 0}) {
+			return nil
+		}
+		size := binary.LittleEndian.Uint32(prefix[4:8])
+		if size < blockHeaderSize {
+			return fmt.Errorf("%s: invalid block size %d at offset %d", b.files[i], size, offset)
+		}
+		end := offset + 8 + int64(size)
+		if end > info.Size() {
+			return nil
+		}
+		if err := fn(prefix[8:], blockLocation{file: i, offset: offset + 8, size: size}, magic); err != nil {
+			return err
+		}
+		offset = end
+	}
+	return nil
+}
+
+// readAt reads len(p) bytes at offset of f, undoing the xor.dat obfuscation
+func (b *BlockFiles) readAt(f *os.File, p []byte, offset int64) error {
+	if _, err := f.ReadAt(p, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if len(b.xorKey) > 0 {
+		n := int64(len(b.xorKey))
+		for i := range p {
+			p[i] ^= b.xorKey[(offset+int64(i))%n]
+		}
+	}
+	return nil
+}
+
+// Heights implements BlockSource
+func (b *BlockFiles) Heights() (int64, int64) {
+	return 0, int64(len(b.chain)) - 1
+}
+
+// RawBlock implements BlockSource
+func (b *BlockFiles) RawBlock(height int64) ([]byte, error) {
+	if height < 0 || height >= int64(len(b.chain)) {
+		return nil, fmt.Errorf("block %d is not in the block files (tip %d)", height, len(b.chain)-1)
+	}
+	loc := b.chain[height]
+	if b.openIdx != loc.file {
+		if b.open != nil {
+			b.open.Close()
+			b.open = nil
+		}
+		f, err := os.Open(b.files[loc.file])
+		if err != nil {
+			return nil, err
+		}
+		b.open, b.openIdx = f, loc.file
+	}
+	raw := make([]byte, loc.size)
+	if err := b.readAt(b.open, raw, loc.offset); err != nil {
+		return nil, fmt.Errorf("block %d: %w", height, err)
+	}
+	return raw, nil
+}
+
+// Close implements BlockSource
+func (b *BlockFiles) Close() error {
+	if b.open == nil {
+		return nil
+	}
+	err := b.open.Close()
+	b.open, b.openIdx = nil, -1
+	return err
+}
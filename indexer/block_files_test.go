@@ -0,0 +1,128 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+// testBlock a BTC block on top of prev with one coinbase-like transaction
+func testBlock(t *testing.T, prev chainhash.Hash, nonce uint32) (*btcwire.MsgBlock, []byte) {
+	t.Helper()
+	tx := btcwire.NewMsgTx(1)
+	tx.AddTxIn(btcwire.NewTxIn(&btcwire.OutPoint{Index: 0xffffffff}, []byte{byte(nonce)}, nil))
+	tx.AddTxOut(btcwire.NewTxOut(50, []byte{0x51}))
+	block := btcwire.NewMsgBlock(btcwire.NewBlockHeader(1, &prev, &chainhash.Hash{}, 0x1d00ffff, nonce))
+	block.Header.Timestamp = time.Unix(1700000000+int64(nonce), 0)
+	block.AddTransaction(tx)
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return block, buf.Bytes()
+}
+
+// writeBlockFile writes blocks as a node does, obfuscated with key, followed
+// by preallocated zeroes
+func writeBlockFile(t *testing.T, path string, key []byte, blocks ...[]byte) {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, raw := range blocks {
+		buf.Write([]byte{0xf9, 0xbe, 0xb4, 0xd9})
+		binary.Write(&buf, binary.LittleEndian, uint32(len(raw)))
+		buf.Write(raw)
+	}
+	buf.Write(make([]byte, 64))
+	data := buf.Bytes()
+	for i := range data {
+		data[i] ^= key[i%len(key)]
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlockFiles(t *testing.T) {
+	genesis, rawGenesis := testBlock(t, chainhash.Hash{}, 0)
+	b1, raw1 := testBlock(t, genesis.BlockHash(), 1)
+	_, rawStale := testBlock(t, genesis.BlockHash(), 99)
+	b2, raw2 := testBlock(t, b1.BlockHash(), 2)
+	_, raw3 := testBlock(t, b2.BlockHash(), 3)
+
+	dir := t.TempDir()
+	key := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	if err := os.WriteFile(filepath.Join(dir, "xor.dat"), key, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Received out of order, with a stale block
+	writeBlockFile(t, filepath.Join(dir, "blk00000.dat"), key, rawGenesis, raw2, rawStale)
+	writeBlockFile(t, filepath.Join(dir, "blk00001.dat"), key, raw1, raw3)
+
+	files, err := OpenBlockFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer files.Close()
+	if first, last := files.Heights(); first != 0 || last != 3 {
+		t.Fatalf("Heights() = %d, %d; want 0, 3", first, last)
+	}
+	for height, want := range [][]byte{rawGenesis, raw1, raw2, raw3} {
+		got, err := files.RawBlock(int64(height))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("RawBlock(%d) differs (%v)", height, err)
+		}
+	}
+	block, txs, err := DecodeBlock(ChainTypeBTC, raw2)
+	if err != nil || txs != 1 || block.(*btcwire.MsgBlock).BlockHash() != b2.BlockHash() {
+		t.Errorf("DecodeBlock = %v, %d, %v", block, txs, err)
+	}
+
+	// Without the genesis block the heights are unknown
+	os.Remove(filepath.Join(dir, "blk00000.dat"))
+	if _, err := OpenBlockFiles(dir); err == nil {
+		t.Error("block files without genesis opened")
+	}
+}
+
+func TestBlockArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "btc.blocks")
+	w, err := CreateBlockArchive(path, "btc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := [][]byte{[]byte("block 100"), []byte("block 101"), {}}
+	for i, raw := range blocks {
+		if err := w.Append(100+int64(i), raw); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Append(200, []byte("gap")); err == nil {
+		t.Error("appended a block after a gap")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := OpenBlockArchive(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	if first, last := a.Heights(); a.Chain() != "btc" || first != 100 || last != 102 {
+		t.Fatalf("archive of %s %d to %d", a.Chain(), first, last)
+	}
+	for i, want := range blocks {
+		if got, err := a.RawBlock(100 + int64(i)); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("RawBlock(%d) = %q, %v", 100+i, got, err)
+		}
+	}
+	if _, err := a.RawBlock(103); err == nil {
+		t.Error("read a block past the archive")
+	}
+}
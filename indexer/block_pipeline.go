@@ -0,0 +1,234 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+
+	"github.com/bitcoinsv/bsvd/wire"
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+// PipelineConfig concurrency of the block pipeline stages. Fields <= 0 use
+// the defaults of DefaultPipelineConfig.
+type PipelineConfig struct {
+	FetchWorkers   int // Parallel tx fetches of a *LazyBlock
+	ParseWorkers   int // Parallel ParseAllPINs calls
+	ResolveWorkers int // Parallel creator address lookups
+	QueueSize      int // Transactions in flight between fetch and persist
+}
+
+// DefaultPipelineConfig stage settings used for unset PipelineConfig fields
+func DefaultPipelineConfig() PipelineConfig {
+	return PipelineConfig{FetchWorkers: 4, ParseWorkers: runtime.NumCPU(), ResolveWorkers: 4, QueueSize: 256}
+}
+
+func (c PipelineConfig) withDefaults() PipelineConfig {
+	d := DefaultPipelineConfig()
+	if c.FetchWorkers <= 0 {
+		c.FetchWorkers = d.FetchWorkers
+	}
+	if c.ParseWorkers <= 0 {
+		c.ParseWorkers = d.ParseWorkers
+	}
+	if c.ResolveWorkers <= 0 {
+		c.ResolveWorkers = d.ResolveWorkers
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = d.QueueSize
+	}
+	return c
+}
+
+// BlockPipeline indexes the transactions of one block in stages:
+//
+//	fetch -> parse -> resolve addresses -> persist
+//
+// Fetch (lazy blocks only), parse and resolve run on worker pools connected
+// by bounded channels, so a slow stage holds back the ones before it instead
+// of buffering the block. Persist runs on the calling goroutine in block
+// order, so a PIN always sees the PINs before it (e.g. a modify sees its
+// create). Post-processing (counters, sync height, pending merges) is left
+// to the caller once Run returns.
+type BlockPipeline struct {
+	chainType ChainType
+	cfg       PipelineConfig
+	resolve   func(data *MetaIDData) // Optional: fills in the creator address of a PIN
+	parse     func(tx interface{}, chainType ChainType) (*MetaIDDataTx, error)
+}
+
+// NewBlockPipeline create a block pipeline for chainType. resolve may be
+// nil; it is called for each PIN with a CreatorInputLocation and may be
+// called from several goroutines.
+func NewBlockPipeline(chainType ChainType, cfg PipelineConfig, resolve func(data *MetaIDData)) *BlockPipeline {
+	return &BlockPipeline{chainType: chainType, cfg: cfg.withDefaults(), resolve: resolve, parse: parseTxPINs}
+}
+
+// PipelineResult what a block run went through the pipeline
+type PipelineResult struct {
+	Txs       int // Transactions in the block
+	MetaIDTxs int // Transactions carrying PINs
+	Pins      int // PINs found
+	Persisted int // MetaID transactions persisted without error
+}
+
+// pipelineItem one transaction on its way through the stages; done is
+// closed when it is ready to persist
+type pipelineItem struct {
+	txid       string
+	tx         interface{}
+	metaDataTx *MetaIDDataTx
+	done       chan struct{}
+}
+
+// Run sends the transactions of block through the pipeline. block is a
+// *LazyBlock (fetch is then required), *btcwire.MsgBlock or *wire.MsgBlock.
+// Transactions that cannot be fetched are logged and skipped; persist
+// errors are logged and the block goes on, as a single bad transaction
+// must not stall the chain.
+func (p *BlockPipeline) Run(block interface{}, fetch func(txid string) (interface{}, error), persist func(tx interface{}, metaDataTx *MetaIDDataTx) error) (PipelineResult, error) {
+	var (
+		txids []string
+		txs   []interface{}
+	)
+	switch b := block.(type) {
+	case *LazyBlock:
+		if fetch == nil {
+			return PipelineResult{}, errors.New("lazy block without a tx fetcher")
+		}
+		txids = b.TxIDs
+	case *btcwire.MsgBlock:
+		if p.chainType != ChainTypeBTC && p.chainType != ChainTypeDOGE {
+			return PipelineResult{}, fmt.Errorf("invalid %s block type", p.chainType)
+		}
+		for _, tx := range b.Transactions {
+			txs = append(txs, tx)
+		}
+	case *wire.MsgBlock:
+		if p.chainType != ChainTypeMVC {
+			return PipelineResult{}, fmt.Errorf("invalid %s block type", p.chainType)
+		}
+		for _, tx := range b.Transactions {
+			txs = append(txs, tx)
+		}
+	default:
+		return PipelineResult{}, fmt.Errorf("invalid %s block type %T", p.chainType, block)
+	}
+
+	result := PipelineResult{Txs: len(txids) + len(txs)}
+	order := make(chan *pipelineItem, p.cfg.QueueSize)
+	fetchCh := make(chan *pipelineItem, p.cfg.QueueSize)
+	parseCh := make(chan *pipelineItem, p.cfg.QueueSize)
+	resolveCh := make(chan *pipelineItem, p.cfg.QueueSize)
+
+	// Source: queue every transaction in block order. order bounds the
+	// transactions in flight; the persist loop below drains it.
+	go func() {
+		defer close(fetchCh)
+		defer close(order)
+		for _, txid := range txids {
+			item := &pipelineItem{txid: txid, done: make(chan struct{})}
+			order <- item
+			fetchCh <- item
+		}
+		for _, tx := range txs {
+			item := &pipelineItem{tx: tx, done: make(chan struct{})}
+			order <- item
+			fetchCh <- item
+		}
+	}()
+
+	fetchWorkers := p.cfg.FetchWorkers
+	if txids == nil {
+		fetchWorkers = 1 // Transactions already in memory
+	}
+	runStage(fetchWorkers, fetchCh, parseCh, func(item *pipelineItem) bool {
+		if item.tx != nil {
+			return true
+		}
+		tx, err := fetch(item.txid)
+		if err != nil {
+			log.Printf("[%s] Failed to fetch tx %s: %v", p.chainType, item.txid, err)
+			return false
+		}
+		item.tx = tx
+		return true
+	})
+	runStage(p.cfg.ParseWorkers, parseCh, resolveCh, func(item *pipelineItem) bool {
+		// Not a MetaID transaction, or one the decoder cannot read: skip
+		metaDataTx, err := p.parse(item.tx, p.chainType)
+		if err != nil {
+			log.Printf("[%s] %v", p.chainType, err)
+		}
+		item.metaDataTx = metaDataTx
+		return metaDataTx != nil
+	})
+	runStage(p.cfg.ResolveWorkers, resolveCh, nil, func(item *pipelineItem) bool {
+		if p.resolve != nil {
+			for _, data := range item.metaDataTx.MetaIDData {
+				if data.CreatorInputLocation != "" {
+					p.resolve(data)
+				}
+			}
+		}
+		return true
+	})
+
+	// Persist in block order
+	for item := range order {
+		<-item.done
+		if item.metaDataTx == nil {
+			continue
+		}
+		result.MetaIDTxs++
+		result.Pins += len(item.metaDataTx.MetaIDData)
+		if err := persist(item.tx, item.metaDataTx); err != nil {
+			log.Printf("[%s] Failed to handle transaction %s: %v", p.chainType, item.metaDataTx.TxID, err)
+			continue
+		}
+		result.Persisted++
+	}
+	return result, nil
+}
+
+// runStage runs fn on workers goroutines for every item of in. Items fn
+// keeps go to out; the others, and all items of the last stage (out nil),
+// are marked done. out is closed once in is drained.
+func runStage(workers int, in <-chan *pipelineItem, out chan<- *pipelineItem, fn func(item *pipelineItem) bool) {
+	if workers <= 0 {
+		workers = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range in {
+				if fn(item) && out != nil {
+					out <- item
+				} else {
+					close(item.done)
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		if out != nil {
+			close(out)
+		}
+	}()
+}
+
+// pipelineParsers parsers of the parse workers; the script decoders are
+// not documented as safe for concurrent use
+var pipelineParsers = sync.Pool{New: func() interface{} { return NewMetaIDParser("") }}
+
+// parseTxPINs parse all PIN data of tx with a pooled parser
+func parseTxPINs(tx interface{}, chainType ChainType) (*MetaIDDataTx, error) {
+	parser := pipelineParsers.Get().(*MetaIDParser)
+	defer pipelineParsers.Put(parser)
+	return parser.ParseAllPINs(tx, chainType)
+}
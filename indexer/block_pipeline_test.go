@@ -0,0 +1,93 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBlockPipelineKeepsBlockOrder(t *testing.T) {
+	const txCount = 200
+	block := &LazyBlock{}
+	for i := 0; i < txCount; i++ {
+		block.TxIDs = append(block.TxIDs, fmt.Sprintf("tx%03d", i))
+	}
+
+	var resolving, maxResolving int32
+	pipeline := NewBlockPipeline(ChainTypeMVC, PipelineConfig{FetchWorkers: 8, ParseWorkers: 3, ResolveWorkers: 5, QueueSize: 16},
+		func(data *MetaIDData) {
+			n := atomic.AddInt32(&resolving, 1)
+			for {
+				max := atomic.LoadInt32(&maxResolving)
+				if n <= max || atomic.CompareAndSwapInt32(&maxResolving, max, n) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+			data.CreatorAddress = "resolved-" + data.PinID
+			atomic.AddInt32(&resolving, -1)
+		})
+	// Every third tx carries a PIN; tx013 cannot be fetched
+	pipeline.parse = func(tx interface{}, chainType ChainType) (*MetaIDDataTx, error) {
+		txid := tx.(string)
+		var n int
+		fmt.Sscanf(txid, "tx%d", &n)
+		if n%3 != 0 {
+			return nil, nil
+		}
+		return &MetaIDDataTx{TxID: txid, MetaIDData: []*MetaIDData{{PinID: txid + "i0", CreatorInputLocation: txid + ":0"}}}, nil
+	}
+	fetch := func(txid string) (interface{}, error) {
+		time.Sleep(time.Duration(rand.Intn(300)) * time.Microsecond)
+		if txid == "tx012" {
+			return nil, errors.New("not found")
+		}
+		return txid, nil
+	}
+
+	var persisted []string
+	result, err := pipeline.Run(block, fetch, func(tx interface{}, metaDataTx *MetaIDDataTx) error {
+		pin := metaDataTx.MetaIDData[0]
+		if pin.CreatorAddress != "resolved-"+pin.PinID {
+			t.Errorf("%s persisted before its address was resolved", pin.PinID)
+		}
+		persisted = append(persisted, metaDataTx.TxID)
+		if metaDataTx.TxID == "tx099" {
+			return errors.New("write failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := 0
+	for _, txid := range persisted {
+		for want == 12 || want%3 != 0 {
+			want++
+		}
+		if txid != fmt.Sprintf("tx%03d", want) {
+			t.Fatalf("persist order %v, want block order", persisted)
+		}
+		want++
+	}
+	if result.Txs != txCount || result.MetaIDTxs != 66 || result.Pins != 66 || result.Persisted != 65 {
+		t.Errorf("result = %+v, want 200 txs, 66 MetaID txs and PINs, 65 persisted", result)
+	}
+	if maxResolving > 5 {
+		t.Errorf("%d concurrent resolves, want at most 5", maxResolving)
+	}
+}
+
+func TestBlockPipelineRejectsWrongBlock(t *testing.T) {
+	pipeline := NewBlockPipeline(ChainTypeBTC, PipelineConfig{}, nil)
+	if _, err := pipeline.Run(&LazyBlock{TxIDs: []string{"a"}}, nil, nil); err == nil {
+		t.Error("lazy block without a fetcher accepted")
+	}
+	if _, err := pipeline.Run("block", nil, nil); err == nil {
+		t.Error("unknown block type accepted")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"meta-file-system/tool"
@@ -32,10 +33,21 @@ type BlockScanner struct {
 	interval                 time.Duration
 	chainType                ChainType // Chain type: btc, mvc, or doge
 	progressBar              *progressbar.ProgressBar
-	zmqClient                *ZMQClient    // ZMQ client for real-time transaction monitoring
-	zmqEnabled               bool          // Whether ZMQ is enabled
-	parser                   *MetaIDParser // Shared parser to avoid repeated allocation
-	largeBlockThresholdBytes int64         // Block size in bytes above which to use lazy loading; 0 = default
+	zmqClient                *ZMQClient             // ZMQ client for real-time transaction monitoring
+	zmqEnabled               bool                   // Whether ZMQ is enabled
+	parser                   *MetaIDParser          // Shared parser to avoid repeated allocation
+	largeBlockThresholdBytes int64                  // Block size in bytes above which to use lazy loading; 0 = default
+	pipelineConfig           PipelineConfig         // Stage settings of ScanBlock
+	resolveCreator           func(data *MetaIDData) // Resolve stage of ScanBlock; nil = addresses resolved later
+	paused                   func() bool            // Scanning waits while it reports true; nil = never paused
+
+	pruneMu    sync.RWMutex
+	pruneInfo  PruneInfo // Last DetectPruning answer
+	esploraURL string    // Esplora API for blocks the node has pruned (btc only)
+
+	stopChan chan struct{} // Closed by Stop; Start returns after the current block
+	stopOnce sync.Once
+	running  sync.WaitGroup // Start's loop
 }
 
 // NewBlockScanner create block scanner (default MVC)
@@ -47,6 +59,7 @@ func NewBlockScanner(rpcURL, rpcUser, rpcPassword string, startHeight int64, int
 		startHeight: startHeight,
 		interval:    time.Duration(interval) * time.Second,
 		chainType:   ChainTypeMVC,
+		stopChan:    make(chan struct{}),
 	}
 }
 
@@ -61,9 +74,15 @@ func NewBlockScannerWithChain(rpcURL, rpcUser, rpcPassword string, startHeight i
 		chainType:   chainType,
 		zmqEnabled:  false,
 		parser:      NewMetaIDParser(""), // Create shared parser once
+		stopChan:    make(chan struct{}),
 	}
 }
 
+// ChainType returns the chain the scanner reads
+func (s *BlockScanner) ChainType() ChainType {
+	return s.chainType
+}
+
 // EnableZMQ enable ZMQ real-time transaction monitoring
 func (s *BlockScanner) EnableZMQ(zmqAddress string) {
 	s.zmqClient = NewZMQClient(zmqAddress, s.chainType)
@@ -88,6 +107,25 @@ func (s *BlockScanner) SetLargeBlockThreshold(bytes int64) {
 	s.largeBlockThresholdBytes = bytes
 }
 
+// SetPipeline sets the stage settings of ScanBlock and the function its
+// resolve stage fills in PIN creator addresses with (nil = none)
+func (s *BlockScanner) SetPipeline(cfg PipelineConfig, resolveCreator func(data *MetaIDData)) {
+	s.pipelineConfig = cfg
+	s.resolveCreator = resolveCreator
+}
+
+// SetPauseCheck sets the function checked before each block is scanned; while
+// it reports true the scanner waits instead (e.g. the storage disk is full).
+// Call before Start.
+func (s *BlockScanner) SetPauseCheck(paused func() bool) {
+	s.paused = paused
+}
+
+// Paused reports whether scanning is currently paused by the pause check
+func (s *BlockScanner) Paused() bool {
+	return s.paused != nil && s.paused()
+}
+
 // RPCRequest RPC request structure
 type RPCRequest struct {
 	Jsonrpc string        `json:"jsonrpc"`
@@ -611,6 +649,13 @@ func (s *BlockScanner) ScanMempool(handler func(tx interface{}, metaDataTx *Meta
 // Returns interface{} which can be *wire.MsgBlock (MVC), *btcwire.MsgBlock (BTC/DOGE), or *LazyBlock.
 // For large blocks (size > largeBlockThresholdBytes), returns LazyBlock to avoid loading full block into memory.
 func (s *BlockScanner) GetBlockMsg(height int64) (interface{}, int, error) {
+	if block, ok, err := s.prunedBlockFromEsplora(height); ok {
+		if err != nil {
+			return nil, 0, err
+		}
+		return block, len(block.Transactions), nil
+	}
+
 	blockhash, err := s.GetBlockhash(height)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get block hash: %w", err)
@@ -635,10 +680,7 @@ func (s *BlockScanner) GetBlockMsg(height int64) (interface{}, int, error) {
 	}
 
 	if useLazy {
-		timestampMs := verbose.Time * 1000
-		if verbose.Time > 1e12 {
-			timestampMs = verbose.Time
-		}
+		timestampMs := NormalizeTimestamp(s.chainType, verbose.Time)
 		lazy := &LazyBlock{
 			TxIDs:     verbose.Tx,
 			Timestamp: timestampMs,
@@ -665,23 +707,33 @@ func (s *BlockScanner) GetBlockMsg(height int64) (interface{}, int, error) {
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to decode block hex: %w", err)
 	}
+	return DecodeBlock(s.chainType, blockBytes)
+}
 
-	if s.chainType == ChainTypeBTC {
+// DecodeBlock deserializes a raw BTC (*btcwire.MsgBlock) or MVC
+// (*wire.MsgBlock) block and returns it with its transaction count. DOGE
+// blocks carry AuxPoW headers and are read through getDOGEBlockByRPC instead.
+func DecodeBlock(chainType ChainType, raw []byte) (interface{}, int, error) {
+	switch chainType {
+	case ChainTypeBTC:
 		var msgBlock btcwire.MsgBlock
-		if err := msgBlock.Deserialize(bytes.NewReader(blockBytes)); err != nil {
+		if err := msgBlock.Deserialize(bytes.NewReader(raw)); err != nil {
 			return nil, 0, fmt.Errorf("failed to deserialize BTC block: %w", err)
 		}
 		return &msgBlock, len(msgBlock.Transactions), nil
+	case ChainTypeDOGE:
+		return nil, 0, errors.New("raw DOGE blocks cannot be decoded")
 	}
 	var msgBlock wire.MsgBlock
-	if err := msgBlock.Deserialize(bytes.NewReader(blockBytes)); err != nil {
+	if err := msgBlock.Deserialize(bytes.NewReader(raw)); err != nil {
 		return nil, 0, fmt.Errorf("failed to deserialize MVC block: %w", err)
 	}
 	return &msgBlock, len(msgBlock.Transactions), nil
 }
 
-// ScanBlock scan specified block
-// handler accepts interface{} for tx to support both BTC and MVC
+// ScanBlock scan specified block through the block pipeline
+// handler accepts interface{} for tx to support both BTC and MVC; it is the
+// persist stage and is called in block order
 // Returns the number of processed MetaID transactions
 func (s *BlockScanner) ScanBlock(height int64, handler func(tx interface{}, metaDataTx *MetaIDDataTx, height, timestamp int64) error) (int, error) {
 	// Get block message with all transactions (or LazyBlock for large blocks)
@@ -690,107 +742,29 @@ func (s *BlockScanner) ScanBlock(height int64, handler func(tx interface{}, meta
 		return 0, fmt.Errorf("failed to get block message: %w", err)
 	}
 
-	processedCount := 0
-	metaidPinCount := 0
-
-	// Large block path: iterate txids and fetch each tx on demand
-	if lazy, ok := msgBlockInterface.(*LazyBlock); ok {
-		for _, txid := range lazy.TxIDs {
-			tx, err := s.GetAndDeserializeTx(txid)
-			if err != nil {
-				log.Printf("[%s] Failed to get tx %s in block %d: %v", s.chainType, txid, height, err)
-				continue
-			}
-			metaDataTx, err := s.parser.ParseAllPINs(tx, s.chainType)
-			if err != nil || metaDataTx == nil {
-				continue
-			}
-			metaidPinCount += len(metaDataTx.MetaIDData)
-			if err := handler(tx, metaDataTx, height, lazy.Timestamp); err != nil {
-				log.Printf("[%s] Failed to handle transaction %s: %v", s.chainType, metaDataTx.TxID, err)
-			} else {
-				processedCount++
-			}
-		}
-		log.Printf("Scanned block at height %d (lazy), transaction count: %d (chain: %s), MetaID PIN count: %d", height, txCount, s.chainType, metaidPinCount)
-		return processedCount, nil
+	var timestamp int64
+	lazyNote := ""
+	switch block := msgBlockInterface.(type) {
+	case *LazyBlock:
+		// Large block path: the fetch stage loads each tx on demand
+		timestamp = block.Timestamp
+		lazyNote = " (lazy)"
+	case *btcwire.MsgBlock:
+		timestamp = BlockHeaderTimestamp(s.chainType, block.Header.Timestamp)
+	case *wire.MsgBlock:
+		timestamp = BlockHeaderTimestamp(ChainTypeMVC, block.Header.Timestamp)
 	}
 
-	// Process transactions based on chain type (full block in memory)
-	if s.chainType == ChainTypeBTC || s.chainType == ChainTypeDOGE {
-		// BTC/DOGE block
-		btcBlock, ok := msgBlockInterface.(*btcwire.MsgBlock)
-		if !ok {
-			chainName := "BTC"
-			if s.chainType == ChainTypeDOGE {
-				chainName = "DOGE"
-			}
-			return 0, fmt.Errorf("invalid %s block type", chainName)
-		}
-		timestamp := btcBlock.Header.Timestamp.UnixMilli()
-
-		// Traverse transactions
-		for _, tx := range btcBlock.Transactions {
-			// Parse MetaID data using shared parser
-			metaDataTx, err := s.parser.ParseAllPINs(tx, s.chainType)
-			if err != nil {
-				// not MetaID transaction, skip
-				continue
-			}
-			if metaDataTx == nil {
-				// not MetaID transaction, skip
-				continue
-			}
-			metaidPinCount += len(metaDataTx.MetaIDData)
-
-			// Call handler
-			chainName := "BTC"
-			if s.chainType == ChainTypeDOGE {
-				chainName = "DOGE"
-			}
-			if err := handler(tx, metaDataTx, height, timestamp); err != nil {
-				log.Printf("Failed to handle %s transaction %s: %v", chainName, metaDataTx.TxID, err)
-			} else {
-				processedCount++
-			}
-		}
-	} else {
-		// MVC block
-		mvcBlock, ok := msgBlockInterface.(*wire.MsgBlock)
-		if !ok {
-			return 0, errors.New("invalid MVC block type")
-		}
-		timestamp := mvcBlock.Header.Timestamp.UnixMilli()
-
-		// Traverse transactions
-		for _, tx := range mvcBlock.Transactions {
-			// Parse MetaID data using shared parser
-			metaDataTx, err := s.parser.ParseAllPINs(tx, ChainTypeMVC)
-			if err != nil {
-				// not MetaID transaction, skip
-				continue
-			}
-			if metaDataTx == nil {
-				// not MetaID transaction, skip
-				continue
-			}
-			// has
-			// for _, pin := range metaDataTx.MetaIDData {
-			// 	if
-			// }
-
-			// Call handler
-			if err := handler(tx, metaDataTx, height, timestamp); err != nil {
-				log.Printf("Failed to handle MVC transaction %s: %v", metaDataTx.TxID, err)
-			} else {
-				processedCount++
-			}
-			metaidPinCount += len(metaDataTx.MetaIDData)
-		}
+	pipeline := NewBlockPipeline(s.chainType, s.pipelineConfig, s.resolveCreator)
+	result, err := pipeline.Run(msgBlockInterface, s.GetAndDeserializeTx, func(tx interface{}, metaDataTx *MetaIDDataTx) error {
+		return handler(tx, metaDataTx, height, timestamp)
+	})
+	if err != nil {
+		return 0, err
 	}
-	log.Printf("Scanned block at height %d, transaction count: %d (chain: %s), MetaID PIN count: %d", height, txCount, s.chainType, metaidPinCount)
+	log.Printf("Scanned block at height %d%s, transaction count: %d (chain: %s), MetaID PIN count: %d", height, lazyNote, txCount, s.chainType, result.Pins)
 
-	return processedCount, nil
+	return result.Persisted, nil
 }
 
 // Start start scanner
@@ -800,17 +774,20 @@ func (s *BlockScanner) Start(
 	handler func(tx interface{}, metaDataTx *MetaIDDataTx, height, timestamp int64) error,
 	onBlockComplete func(height int64) error,
 ) {
+	s.running.Add(1)
+	defer s.running.Done()
+
 	currentHeight := s.startHeight
 	log.Printf("Block scanner started from height %d (chain: %s)", currentHeight, s.chainType)
 
 	zmqStarted := false // Track if ZMQ has been started
 
-	for {
+	for !s.stopping() {
 		// get latest block height
 		latestHeight, err := s.GetBlockCount()
 		if err != nil {
 			log.Printf("Failed to get block count: %v", err)
-			time.Sleep(s.interval)
+			s.wait()
 			continue
 		}
 
@@ -836,10 +813,21 @@ func (s *BlockScanner) Start(
 			log.Printf("Starting to scan %d blocks (from %d to %d)", blocksToScan, currentHeight, latestHeight)
 
 			for currentHeight <= latestHeight {
+				if s.stopping() {
+					s.progressBar.Finish()
+					s.progressBar = nil
+					log.Printf("\nBlock scanner stopped before block %d (chain: %s)", currentHeight, s.chainType)
+					return
+				}
+				if s.Paused() {
+					s.wait()
+					continue
+				}
+
 				_, err := s.ScanBlock(currentHeight, handler)
 				if err != nil {
 					log.Printf("\nFailed to scan block %d: %v", currentHeight, err)
-					time.Sleep(s.interval)
+					s.wait()
 					continue
 				}
 
@@ -867,7 +855,7 @@ func (s *BlockScanner) Start(
 				// Set ZMQ transaction handler (without height parameter for mempool txs)
 				s.zmqClient.SetTransactionHandler(func(tx interface{}, metaDataTx *MetaIDDataTx) error {
 					// Call the same handler but with height = 0 (mempool transaction)
-					return handler(tx, metaDataTx, 0, time.Now().UnixMilli())
+					return handler(tx, metaDataTx, 0, FirstSeenTimestamp())
 				})
 
 				// Start ZMQ client
@@ -889,7 +877,7 @@ func (s *BlockScanner) Start(
 
 					// Set ZMQ transaction handler
 					s.zmqClient.SetTransactionHandler(func(tx interface{}, metaDataTx *MetaIDDataTx) error {
-						return handler(tx, metaDataTx, 0, time.Now().UnixMilli())
+						return handler(tx, metaDataTx, 0, FirstSeenTimestamp())
 					})
 
 					// Start ZMQ client
@@ -906,14 +894,20 @@ func (s *BlockScanner) Start(
 		}
 
 		// wait for next scan
-		time.Sleep(s.interval)
+		s.wait()
 	}
+	log.Printf("Block scanner stopped at height %d (chain: %s)", currentHeight, s.chainType)
 }
 
-// Stop stop scanner and ZMQ client
+// Stop stop scanner and ZMQ client. Start returns after the block it is
+// scanning; Wait waits for it.
 func (s *BlockScanner) Stop() {
 	log.Println("Stopping block scanner...")
 
+	if s.stopChan != nil {
+		s.stopOnce.Do(func() { close(s.stopChan) })
+	}
+
 	// Stop ZMQ client if running
 	if s.zmqClient != nil {
 		s.zmqClient.Stop()
@@ -922,6 +916,33 @@ func (s *BlockScanner) Stop() {
 	log.Println("Block scanner stopped")
 }
 
+// Wait waits until Start returned after Stop; returns at once when Start
+// is not running
+func (s *BlockScanner) Wait() {
+	s.running.Wait()
+}
+
+// stopping reports whether Stop was called
+func (s *BlockScanner) stopping() bool {
+	if s.stopChan == nil {
+		return false
+	}
+	select {
+	case <-s.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait sleeps one scan interval, returning early on Stop
+func (s *BlockScanner) wait() {
+	select {
+	case <-s.stopChan:
+	case <-time.After(s.interval):
+	}
+}
+
 // rpcCall execute RPC call
 func (s *BlockScanner) rpcCall(request RPCRequest) (*RPCResponse, error) {
 	// set authentication header
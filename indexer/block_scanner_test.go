@@ -0,0 +1,34 @@
+package indexer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBlockScannerStop(t *testing.T) {
+	node := httptest.NewServer(http.NotFoundHandler())
+	node.Close() // Every block count request fails, so Start waits a scan interval
+
+	scanner := NewBlockScannerWithChain(node.URL, "user", "pass", 1, 3600, ChainTypeMVC)
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		scanner.Start(func(interface{}, *MetaIDDataTx, int64, int64) error { return nil }, nil)
+	}()
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		scanner.Stop()
+		scanner.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start still running after Stop")
+	}
+}
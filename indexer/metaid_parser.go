@@ -51,10 +51,15 @@ type MetaIDData struct {
 	CreatorInputLocation      string // Creator input location txId:vin
 	CreatorInputTxVinLocation string // Creator input transaction vin location PreTxId:vin
 	CreatorAddress            string // Creator address
+	CreatorResolutionPending  bool   // Creator input lookup failed: CreatorAddress is the fallback address
 	OwnerAddress              string // Owner address
 	ChainName                 string // Chain name: btc, mvc, doge
 }
 
+// ErrNoBlockScanner is returned by FindCreatorAddressFromCreatorInputLocation
+// when the parser has no node to fetch transactions from
+var ErrNoBlockScanner = errors.New("blockScanner not set, cannot fetch transaction from node")
+
 // MetaIDParser MetaID protocol parser
 type MetaIDParser struct {
 	btcParser    decoder.ChainParser
@@ -242,24 +247,35 @@ func (p *MetaIDParser) ParseAllPINs(tx interface{}, chainType ChainType) (*MetaI
 
 	if chainType == ChainTypeBTC {
 		// Try BTC parser first
-		pins, err = p.btcParser.ParseTransaction(txBytes, &chaincfg.MainNetParams)
+		pins, err = decodePins(func() ([]*decoder.Pin, error) {
+			return p.btcParser.ParseTransaction(txBytes, &chaincfg.MainNetParams)
+		})
 		if err == nil && len(pins) > 0 {
 			chainName = "btc"
 		}
 	} else if chainType == ChainTypeDOGE {
 		// Try DOGE parser first
-		pins, err = p.dogeParser.ParseTransaction(txBytes, &chaincfg.MainNetParams)
+		pins, err = decodePins(func() ([]*decoder.Pin, error) {
+			return p.dogeParser.ParseTransaction(txBytes, &chaincfg.MainNetParams)
+		})
 		if err == nil && len(pins) > 0 {
 			chainName = "doge"
 		}
 	} else {
 		// Try MVC parser first
-		pins, err = p.mvcParser.ParseTransaction(txBytes, nil)
+		pins, err = decodePins(func() ([]*decoder.Pin, error) {
+			return p.mvcParser.ParseTransaction(txBytes, nil)
+		})
 		if err == nil && len(pins) > 0 {
 			chainName = "mvc"
 		}
 	}
 
+	// A decoder panic is reported; other decode errors mean no MetaID data
+	if errors.Is(err, errDecoderPanic) {
+		return nil, fmt.Errorf("tx %s: %w", txID, err)
+	}
+
 	// Check if any PIN data was found
 	if err != nil || len(pins) == 0 {
 		return nil, nil
@@ -268,6 +284,9 @@ func (p *MetaIDParser) ParseAllPINs(tx interface{}, chainType ChainType) (*MetaI
 	// Convert all PINs to MetaIDData (address already extracted above)
 	var results []*MetaIDData
 	for _, pin := range pins {
+		if pin == nil {
+			continue
+		}
 		data := &MetaIDData{
 			PinID:                     pin.Id,
 			Operation:                 pin.Operation,
@@ -290,6 +309,10 @@ func (p *MetaIDParser) ParseAllPINs(tx interface{}, chainType ChainType) (*MetaI
 		results = append(results, data)
 	}
 
+	if len(results) == 0 {
+		return nil, nil
+	}
+
 	return &MetaIDDataTx{
 		TxID:       txID,
 		ChainName:  chainName,
@@ -297,6 +320,20 @@ func (p *MetaIDParser) ParseAllPINs(tx interface{}, chainType ChainType) (*MetaI
 	}, nil
 }
 
+// errDecoderPanic marks a script decoder that panicked on a transaction
+var errDecoderPanic = errors.New("script decoder panicked")
+
+// decodePins runs a script decoder, turning a panic on hostile scripts into
+// an error so one transaction cannot stop the scanner
+func decodePins(parse func() ([]*decoder.Pin, error)) (pins []*decoder.Pin, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pins, err = nil, fmt.Errorf("%w: %v", errDecoderPanic, r)
+		}
+	}()
+	return parse()
+}
+
 // extractBTCAddress extract address from BTC transaction first input
 func extractBTCCreatorAddress(tx *btcwire.MsgTx) string {
 	// In Bitcoin, the address is typically extracted from the first input's previous output
@@ -341,7 +378,7 @@ func TxToHex(tx *wire.MsgTx) (string, error) {
 // For BTC/DOGE: uses creatorInputTxVinLocation format "txid:vin", traces back two levels to find the address
 func (p *MetaIDParser) FindCreatorAddressFromCreatorInputLocation(creatorInputLocation string, creatorInputTxVinLocation string, chainType ChainType) (string, error) {
 	if p.blockScanner == nil {
-		return "", errors.New("blockScanner not set, cannot fetch transaction from node")
+		return "", ErrNoBlockScanner
 	}
 
 	// MVC chain: use creatorInputLocation directly
@@ -0,0 +1,125 @@
+package indexer
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/bitcoinsv/bsvd/wire"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+// benchContent file content of the benchmark PINs: a 10 KiB image-sized body
+// that does not compress
+func benchContent() []byte {
+	content := make([]byte, 10<<10)
+	rand.New(rand.NewSource(1)).Read(content)
+	return content
+}
+
+// benchPushes the fields of a metaid create PIN, with the body split into
+// pushes of at most max bytes
+func benchPushes(content []byte, max int) [][]byte {
+	pushes := [][]byte{[]byte("metaid"), []byte("create"), []byte("/file/bench.png"), []byte("0"), []byte("1.0.0"), []byte("image/png")}
+	for len(content) > 0 {
+		n := min(len(content), max)
+		pushes = append(pushes, content[:n])
+		content = content[n:]
+	}
+	return pushes
+}
+
+func appendPush(script, data []byte) []byte {
+	switch n := len(data); {
+	case n < 0x4c:
+		script = append(script, byte(n))
+	case n <= 0xff:
+		script = append(script, 0x4c, byte(n))
+	case n <= 0xffff:
+		script = append(script, 0x4d, byte(n), byte(n>>8))
+	default:
+		script = append(script, 0x4e, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(script, data...)
+}
+
+// benchSigScript a P2PKH unlocking script: signature and compressed pubkey
+func benchSigScript() []byte {
+	script := appendPush(nil, bytes.Repeat([]byte{0x30}, 71))
+	return appendPush(script, append([]byte{0x02}, bytes.Repeat([]byte{0x11}, 32)...))
+}
+
+func benchP2PKH() []byte {
+	return append(append([]byte{0x76, 0xa9, 0x14}, bytes.Repeat([]byte{0x22}, 20)...), 0x88, 0xac)
+}
+
+// benchMVCTx an MVC transaction with two inputs and change; with content it
+// carries a PIN in an OP_FALSE OP_RETURN output
+func benchMVCTx(content []byte) *wire.MsgTx {
+	tx := wire.NewMsgTx(10)
+	for i := 0; i < 2; i++ {
+		outPoint := wire.OutPoint{Index: uint32(i)}
+		outPoint.Hash[0] = byte(i + 1)
+		tx.AddTxIn(wire.NewTxIn(&outPoint, benchSigScript()))
+	}
+	if content != nil {
+		script := []byte{0x00, 0x6a}
+		for _, push := range benchPushes(content, len(content)) {
+			script = appendPush(script, push)
+		}
+		tx.AddTxOut(wire.NewTxOut(1, benchP2PKH()))
+		tx.AddTxOut(wire.NewTxOut(0, script))
+	}
+	tx.AddTxOut(wire.NewTxOut(100000, benchP2PKH()))
+	return tx
+}
+
+// benchBTCTx a BTC taproot reveal transaction carrying a PIN in its witness
+func benchBTCTx(content []byte) *btcwire.MsgTx {
+	script := appendPush(nil, bytes.Repeat([]byte{0x33}, 32))
+	script = append(script, 0xac, 0x00, 0x63) // OP_CHECKSIG OP_FALSE OP_IF
+	for _, push := range benchPushes(content, 520) {
+		script = appendPush(script, push)
+	}
+	script = append(script, 0x68) // OP_ENDIF
+
+	tx := btcwire.NewMsgTx(2)
+	in := btcwire.NewTxIn(btcwire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil)
+	in.Witness = btcwire.TxWitness{bytes.Repeat([]byte{0x44}, 64), script, append([]byte{0xc0}, bytes.Repeat([]byte{0x55}, 32)...)}
+	tx.AddTxIn(in)
+	tx.AddTxOut(btcwire.NewTxOut(546, append([]byte{0x51, 0x20}, bytes.Repeat([]byte{0x66}, 32)...)))
+	return tx
+}
+
+func BenchmarkParseAllPINs(b *testing.B) {
+	content := benchContent()
+	cases := []struct {
+		name      string
+		tx        interface{}
+		chainType ChainType
+		pins      int
+	}{
+		{"mvc-payment", benchMVCTx(nil), ChainTypeMVC, 0},
+		{"mvc-file", benchMVCTx(content), ChainTypeMVC, 1},
+		{"btc-file", benchBTCTx(content), ChainTypeBTC, 1},
+	}
+	parser := NewMetaIDParser("")
+	for _, tc := range cases {
+		b.Run(tc.name, func(b *testing.B) {
+			metaDataTx, _ := parser.ParseAllPINs(tc.tx, tc.chainType)
+			pins := 0
+			if metaDataTx != nil {
+				pins = len(metaDataTx.MetaIDData)
+			}
+			if pins != tc.pins {
+				b.Fatalf("parsed %d PINs, want %d", pins, tc.pins)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				parser.ParseAllPINs(tc.tx, tc.chainType)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package indexer
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/bitcoinsv/bsvd/wire"
+	btcwire "github.com/btcsuite/btcd/wire"
+	"github.com/metaid-developers/metaid-script-decoder/decoder"
+)
+
+func TestDecodePinsRecoversPanic(t *testing.T) {
+	pins, err := decodePins(func() ([]*decoder.Pin, error) { panic("index out of range") })
+	if pins != nil || !errors.Is(err, errDecoderPanic) {
+		t.Errorf("decodePins = %v, %v, want errDecoderPanic", pins, err)
+	}
+}
+
+// FuzzParseAllPINs feeds arbitrary transactions to every chain's decoder:
+// parsing must never panic and must not return empty or nil PINs
+func FuzzParseAllPINs(f *testing.F) {
+	// A transaction with an OP_FALSE OP_RETURN output carrying a metaid envelope
+	script := []byte{0x00, 0x6a}
+	for _, push := range []string{"metaid", "create", "/file/a.txt", "0", "1.0.0", "text/plain", "hello"} {
+		script = append(append(script, byte(len(push))), push...)
+	}
+	seedTx := btcwire.NewMsgTx(1)
+	seedTx.AddTxIn(&btcwire.TxIn{Sequence: 0xffffffff})
+	seedTx.AddTxOut(&btcwire.TxOut{PkScript: script})
+	var seed bytes.Buffer
+	_ = seedTx.Serialize(&seed)
+	f.Add(seed.Bytes())
+	f.Add([]byte{})
+	f.Add(bytes.Repeat([]byte{0x6a}, 64))
+
+	parser := NewMetaIDParser("")
+	f.Fuzz(func(t *testing.T, raw []byte) {
+		var mvcTx wire.MsgTx
+		if mvcTx.Deserialize(bytes.NewReader(raw)) == nil {
+			checkParsedPINs(t, parser, &mvcTx, ChainTypeMVC)
+		}
+		var btcTx btcwire.MsgTx
+		if btcTx.Deserialize(bytes.NewReader(raw)) == nil {
+			checkParsedPINs(t, parser, &btcTx, ChainTypeBTC)
+			checkParsedPINs(t, parser, &btcTx, ChainTypeDOGE)
+		}
+	})
+}
+
+func checkParsedPINs(t *testing.T, parser *MetaIDParser, tx interface{}, chainType ChainType) {
+	t.Helper()
+	metaDataTx, err := parser.ParseAllPINs(tx, chainType)
+	if err != nil || metaDataTx == nil {
+		return
+	}
+	if len(metaDataTx.MetaIDData) == 0 {
+		t.Fatalf("%s: empty MetaIDDataTx returned", chainType)
+	}
+	for i, data := range metaDataTx.MetaIDData {
+		if data == nil || data.ChainName != string(chainType) {
+			t.Fatalf("%s: PIN %d = %+v", chainType, i, data)
+		}
+	}
+}
@@ -224,6 +224,12 @@ func (c *MultiChainCoordinator) scanBlocksForChain(chainName string, scanner *Bl
 						c.chainCurrentHeight[chainName] = currentHeight
 						c.stateTrackingMu.Unlock()
 
+						// Paused (see BlockScanner.SetPauseCheck): fetch nothing until resumed
+						if scanner.Paused() {
+							time.Sleep(scanner.interval)
+							continue
+						}
+
 						// **PER-CHAIN QUOTA CHECK**: Prevent one chain from monopolizing the queue
 						c.chainSlotUsageMu.Lock()
 						chainUsage := c.chainSlotUsage[chainName]
@@ -298,11 +304,11 @@ func (c *MultiChainCoordinator) scanBlocksForChain(chainName string, scanner *Bl
 							timestamp = lazy.Timestamp
 						} else if scanner.chainType == ChainTypeBTC || scanner.chainType == ChainTypeDOGE {
 							if btcBlock, ok := msgBlock.(*btcwire.MsgBlock); ok {
-								timestamp = btcBlock.Header.Timestamp.UnixMilli()
+								timestamp = BlockHeaderTimestamp(scanner.chainType, btcBlock.Header.Timestamp)
 							}
 						} else {
 							if mvcBlock, ok := msgBlock.(*wire.MsgBlock); ok {
-								timestamp = mvcBlock.Header.Timestamp.UnixMilli()
+								timestamp = BlockHeaderTimestamp(scanner.chainType, mvcBlock.Header.Timestamp)
 							}
 						}
 
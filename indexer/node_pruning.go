@@ -0,0 +1,163 @@
+package indexer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+// ErrBlockPruned is returned (wrapped) for blocks below a pruned node's prune
+// height when no Esplora fallback is configured
+var ErrBlockPruned = errors.New("block pruned by node")
+
+// esploraTimeout bounds each Esplora request (raw blocks can be a few MB)
+const esploraTimeout = 60 * time.Second
+
+// PruneInfo block availability of the scanner's node
+type PruneInfo struct {
+	Checked     bool  // getblockchaininfo answered at least once
+	Pruned      bool  // Node runs with -prune
+	PruneHeight int64 // Earliest block the node still serves (0 when not pruned)
+	Esplora     bool  // Older blocks are read from the Esplora fallback
+}
+
+// EarliestHeight returns the lowest height that can be scanned: the prune
+// height of a pruned node without fallback, else 0
+func (p PruneInfo) EarliestHeight() int64 {
+	if !p.Pruned || p.Esplora {
+		return 0
+	}
+	return p.PruneHeight
+}
+
+// SetEsploraFallback sets the Esplora API (e.g. https://blockstream.info/api)
+// used for blocks a pruned node no longer has. BTC only.
+func (s *BlockScanner) SetEsploraFallback(url string) {
+	url = strings.TrimRight(strings.TrimSpace(url), "/")
+	if url == "" {
+		return
+	}
+	if s.chainType != ChainTypeBTC {
+		log.Printf("[%s] Esplora fallback is only supported for btc, ignoring %s", s.chainType, url)
+		return
+	}
+	s.pruneMu.Lock()
+	s.esploraURL = url
+	s.pruneMu.Unlock()
+	log.Printf("[%s] Esplora fallback for pruned blocks: %s", s.chainType, url)
+}
+
+// DetectPruning asks the node (getblockchaininfo) whether it is pruned and
+// from which height it still has blocks, and remembers the answer
+func (s *BlockScanner) DetectPruning() (PruneInfo, error) {
+	request := RPCRequest{
+		Jsonrpc: "1.0",
+		ID:      "getblockchaininfo",
+		Method:  "getblockchaininfo",
+		Params:  []interface{}{},
+	}
+
+	response, err := s.rpcCall(request)
+	if err != nil {
+		return s.PruneInfo(), err
+	}
+	if response.Error != nil {
+		return s.PruneInfo(), fmt.Errorf("rpc error: %s", response.Error.Message)
+	}
+	result, ok := response.Result.(map[string]interface{})
+	if !ok {
+		return s.PruneInfo(), errors.New("invalid blockchain info response")
+	}
+
+	pruned, _ := result["pruned"].(bool)
+	var pruneHeight int64
+	if pruned {
+		if h, ok := result["pruneheight"].(float64); ok {
+			pruneHeight = int64(h)
+		}
+	}
+
+	s.pruneMu.Lock()
+	s.pruneInfo = PruneInfo{Checked: true, Pruned: pruned, PruneHeight: pruneHeight}
+	s.pruneMu.Unlock()
+
+	info := s.PruneInfo()
+	if info.Pruned {
+		if info.Esplora {
+			log.Printf("[%s] Node is pruned, blocks below %d are read from Esplora", s.chainType, info.PruneHeight)
+		} else {
+			log.Printf("[%s] WARNING: node is pruned, blocks below %d cannot be scanned or rescanned", s.chainType, info.PruneHeight)
+		}
+	}
+	return info, nil
+}
+
+// PruneInfo returns the block availability found by the last DetectPruning
+func (s *BlockScanner) PruneInfo() PruneInfo {
+	s.pruneMu.RLock()
+	defer s.pruneMu.RUnlock()
+	info := s.pruneInfo
+	info.Esplora = info.Pruned && s.esploraURL != ""
+	return info
+}
+
+// CheckHeightAvailable returns an ErrBlockPruned error when height is below
+// the node's prune height and cannot be read from a fallback either
+func (s *BlockScanner) CheckHeightAvailable(height int64) error {
+	if earliest := s.PruneInfo().EarliestHeight(); height < earliest {
+		return fmt.Errorf("%w: %s node only has blocks from height %d, requested %d", ErrBlockPruned, s.chainType, earliest, height)
+	}
+	return nil
+}
+
+// prunedBlockFromEsplora returns the block at height from the Esplora
+// fallback when the node has pruned it; ok is false when the node should be
+// asked as usual
+func (s *BlockScanner) prunedBlockFromEsplora(height int64) (block *btcwire.MsgBlock, ok bool, err error) {
+	info := s.PruneInfo()
+	if !info.Pruned || height >= info.PruneHeight {
+		return nil, false, nil
+	}
+	if !info.Esplora {
+		return nil, true, s.CheckHeightAvailable(height)
+	}
+
+	s.pruneMu.RLock()
+	baseURL := s.esploraURL
+	s.pruneMu.RUnlock()
+
+	client := &http.Client{Timeout: esploraTimeout}
+	hash, err := esploraGet(client, fmt.Sprintf("%s/block-height/%d", baseURL, height))
+	if err != nil {
+		return nil, true, fmt.Errorf("esplora block hash at %d: %w", height, err)
+	}
+	raw, err := esploraGet(client, fmt.Sprintf("%s/block/%s/raw", baseURL, strings.TrimSpace(string(hash))))
+	if err != nil {
+		return nil, true, fmt.Errorf("esplora block at %d: %w", height, err)
+	}
+
+	var msgBlock btcwire.MsgBlock
+	if err := msgBlock.Deserialize(bytes.NewReader(raw)); err != nil {
+		return nil, true, fmt.Errorf("failed to deserialize esplora block at %d: %w", height, err)
+	}
+	return &msgBlock, true, nil
+}
+
+func esploraGet(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
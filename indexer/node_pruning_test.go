@@ -0,0 +1,133 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+// newPrunedNode serves getblockchaininfo for a node pruned below pruneHeight
+func newPrunedNode(t *testing.T, pruned bool, pruneHeight int64) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req RPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode rpc request: %v", err)
+		}
+		result := map[string]interface{}{"chain": "main", "blocks": 900000, "pruned": pruned}
+		if pruned {
+			result["pruneheight"] = pruneHeight
+		}
+		if req.Method != "getblockchaininfo" {
+			t.Errorf("unexpected rpc method %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result, "error": nil, "id": req.ID})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestDetectPruning(t *testing.T) {
+	srv := newPrunedNode(t, true, 850000)
+	s := NewBlockScannerWithChain(srv.URL, "u", "p", 0, 10, ChainTypeBTC)
+
+	info, err := s.DetectPruning()
+	if err != nil {
+		t.Fatalf("DetectPruning: %v", err)
+	}
+	if !info.Checked || !info.Pruned || info.PruneHeight != 850000 || info.EarliestHeight() != 850000 {
+		t.Fatalf("unexpected prune info %+v", info)
+	}
+
+	if err := s.CheckHeightAvailable(849999); !errors.Is(err, ErrBlockPruned) {
+		t.Errorf("CheckHeightAvailable(849999) = %v, want ErrBlockPruned", err)
+	}
+	if err := s.CheckHeightAvailable(850000); err != nil {
+		t.Errorf("CheckHeightAvailable(850000) = %v", err)
+	}
+	if _, _, err := s.GetBlockMsg(1); !errors.Is(err, ErrBlockPruned) {
+		t.Errorf("GetBlockMsg below prune height = %v, want ErrBlockPruned", err)
+	}
+}
+
+func TestDetectPruningUnprunedNode(t *testing.T) {
+	srv := newPrunedNode(t, false, 0)
+	s := NewBlockScannerWithChain(srv.URL, "u", "p", 0, 10, ChainTypeBTC)
+
+	info, err := s.DetectPruning()
+	if err != nil {
+		t.Fatalf("DetectPruning: %v", err)
+	}
+	if info.Pruned || info.EarliestHeight() != 0 {
+		t.Fatalf("unexpected prune info %+v", info)
+	}
+	if err := s.CheckHeightAvailable(1); err != nil {
+		t.Errorf("CheckHeightAvailable(1) = %v", err)
+	}
+}
+
+func TestPrunedBlockFromEsplora(t *testing.T) {
+	block := btcwire.NewMsgBlock(&btcwire.BlockHeader{Version: 1})
+	tx := btcwire.NewMsgTx(1)
+	tx.AddTxIn(btcwire.NewTxIn(&btcwire.OutPoint{Index: 0xffffffff}, []byte{0x01}, nil))
+	tx.AddTxOut(btcwire.NewTxOut(5000000000, []byte{0x51}))
+	block.AddTransaction(tx)
+	var raw bytes.Buffer
+	if err := block.Serialize(&raw); err != nil {
+		t.Fatal(err)
+	}
+	hash := block.BlockHash().String()
+
+	esplora := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/block-height/100":
+			fmt.Fprint(w, hash)
+		case "/block/" + hash + "/raw":
+			w.Write(raw.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer esplora.Close()
+
+	srv := newPrunedNode(t, true, 850000)
+	s := NewBlockScannerWithChain(srv.URL, "u", "p", 0, 10, ChainTypeBTC)
+	s.SetEsploraFallback(esplora.URL + "/")
+	info, err := s.DetectPruning()
+	if err != nil {
+		t.Fatalf("DetectPruning: %v", err)
+	}
+	if !info.Esplora || info.EarliestHeight() != 0 {
+		t.Fatalf("unexpected prune info %+v", info)
+	}
+	if err := s.CheckHeightAvailable(100); err != nil {
+		t.Errorf("CheckHeightAvailable(100) with fallback = %v", err)
+	}
+
+	msg, txCount, err := s.GetBlockMsg(100)
+	if err != nil {
+		t.Fatalf("GetBlockMsg(100): %v", err)
+	}
+	got, ok := msg.(*btcwire.MsgBlock)
+	if !ok || txCount != 1 || got.BlockHash().String() != hash {
+		t.Fatalf("GetBlockMsg(100) = %T (%d txs), want block %s", msg, txCount, hash)
+	}
+
+	if _, _, err := s.GetBlockMsg(101); err == nil {
+		t.Error("GetBlockMsg(101) succeeded although Esplora has no such block")
+	}
+}
+
+func TestEsploraFallbackIgnoredForMVC(t *testing.T) {
+	s := NewBlockScannerWithChain("http://127.0.0.1:0", "u", "p", 0, 10, ChainTypeMVC)
+	s.SetEsploraFallback("https://blockstream.info/api")
+	if s.esploraURL != "" {
+		t.Errorf("esploraURL = %q, want empty for mvc", s.esploraURL)
+	}
+}
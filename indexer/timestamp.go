@@ -0,0 +1,63 @@
+package indexer
+
+import "time"
+
+// Earliest plausible block time per chain, in Unix seconds. Anything that
+// normalizes to before this is treated as garbage rather than a real time.
+const (
+	btcGenesisTime  int64 = 1231006505 // 2009-01-03, BTC genesis block
+	dogeGenesisTime int64 = 1386325540 // 2013-12-06, DOGE genesis block
+)
+
+// maxTimestampSpan bounds how far after genesis a timestamp may fall before a
+// smaller unit is tried (roughly two centuries, in seconds).
+const maxTimestampSpan int64 = 200 * 365 * 24 * 3600
+
+// chainGenesisTime returns the lower bound for block timestamps on chainType.
+// MVC shares BTC's header format and clock; unknown chains use BTC's bound.
+func chainGenesisTime(chainType ChainType) int64 {
+	if chainType == ChainTypeDOGE {
+		return dogeGenesisTime
+	}
+	return btcGenesisTime
+}
+
+// NormalizeTimestamp converts a timestamp of unknown unit (seconds,
+// milliseconds, microseconds or nanoseconds, as returned by the various RPC
+// and wire paths) into Unix milliseconds. The unit is the first one that puts
+// the value within [chain genesis, genesis+200y]; 0 is returned when no unit
+// fits, so callers can fall back to a first-seen time.
+func NormalizeTimestamp(chainType ChainType, ts int64) int64 {
+	if ts <= 0 {
+		return 0
+	}
+	low := chainGenesisTime(chainType)
+	high := low + maxTimestampSpan
+	for _, u := range timestampUnits {
+		if seconds := ts / u.perSecond; seconds >= low && seconds <= high {
+			return u.toMillis(ts)
+		}
+	}
+	return 0
+}
+
+var timestampUnits = []struct {
+	perSecond int64
+	toMillis  func(int64) int64
+}{
+	{1, func(ts int64) int64 { return ts * 1000 }},             // seconds
+	{1000, func(ts int64) int64 { return ts }},                 // milliseconds
+	{1000000, func(ts int64) int64 { return ts / 1000 }},       // microseconds
+	{1000000000, func(ts int64) int64 { return ts / 1000000 }}, // nanoseconds
+}
+
+// BlockHeaderTimestamp returns a block header time in Unix milliseconds.
+func BlockHeaderTimestamp(chainType ChainType, t time.Time) int64 {
+	return NormalizeTimestamp(chainType, t.Unix())
+}
+
+// FirstSeenTimestamp returns the time a transaction is observed outside a
+// block (ZMQ/mempool), in Unix milliseconds.
+func FirstSeenTimestamp() int64 {
+	return time.Now().UnixMilli()
+}
@@ -0,0 +1,41 @@
+package indexer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeTimestamp(t *testing.T) {
+	cases := []struct {
+		name  string
+		chain ChainType
+		ts    int64
+		want  int64
+	}{
+		{"seconds", ChainTypeBTC, 1700000000, 1700000000000},
+		{"milliseconds", ChainTypeMVC, 1700000000123, 1700000000123},
+		{"microseconds", ChainTypeBTC, 1700000000123456, 1700000000123},
+		{"nanoseconds", ChainTypeDOGE, 1700000000123456789, 1700000000123},
+		{"nine-digit seconds are not valid block times", ChainTypeBTC, 999999999, 0},
+		{"btc genesis", ChainTypeBTC, 1231006505, 1231006505000},
+		{"before doge genesis", ChainTypeDOGE, 1300000000, 0},
+		{"doge genesis", ChainTypeDOGE, 1386325540, 1386325540000},
+		{"zero", ChainTypeMVC, 0, 0},
+		{"negative", ChainTypeMVC, -1, 0},
+		{"unknown chain uses btc bound", ChainType("other"), 1231006505, 1231006505000},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NormalizeTimestamp(tc.chain, tc.ts); got != tc.want {
+				t.Errorf("NormalizeTimestamp(%s, %d) = %d, want %d", tc.chain, tc.ts, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBlockHeaderTimestamp(t *testing.T) {
+	header := time.Unix(1700000000, 0)
+	if got := BlockHeaderTimestamp(ChainTypeMVC, header); got != header.UnixMilli() {
+		t.Fatalf("BlockHeaderTimestamp = %d, want %d", got, header.UnixMilli())
+	}
+}
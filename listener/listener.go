@@ -0,0 +1,220 @@
+// Package listener serves the HTTP API of a service on its configured
+// listeners (indexer.listeners / uploader.listeners): TCP addresses on IPv4,
+// IPv6 or both, and unix sockets, in plain HTTP or with TLS. Each listener
+// serves every route, only the public routes, or only the admin routes, so
+// the admin API can be kept on a loopback port or a socket while the public
+// API faces the internet.
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"meta-file-system/conf"
+)
+
+// AdminPrefix path prefix of the admin routes of both services
+const AdminPrefix = "/api/v1/admin"
+
+// Group the listeners of a service
+type Group struct {
+	name    string
+	servers []*server
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// server one listener and the HTTP server on it
+type server struct {
+	cfg   conf.ListenerConfig
+	ln    net.Listener
+	srv   *http.Server
+	certs *certReloader // nil without TLS
+}
+
+// Start binds every listener of a service and serves handler on them. It
+// returns before serving anything when one of them cannot be bound or its
+// certificate cannot be loaded, closing the others.
+func Start(name string, listeners []conf.ListenerConfig, handler http.Handler) (*Group, error) {
+	g := &Group{name: name, stop: make(chan struct{})}
+	for _, cfg := range listeners {
+		s, err := newServer(cfg, handler)
+		if err != nil {
+			for _, started := range g.servers {
+				started.ln.Close()
+			}
+			return nil, fmt.Errorf("listener %s: %w", cfg.Address, err)
+		}
+		g.servers = append(g.servers, s)
+	}
+
+	for _, s := range g.servers {
+		log.Printf("%s starting on %s (%s, routes: %s)...", name, s.cfg.Address, s.scheme(), s.cfg.Routes)
+		go s.serve()
+		if s.certs != nil {
+			g.wg.Add(1)
+			go func(certs *certReloader) {
+				defer g.wg.Done()
+				certs.watch(g.stop)
+			}(s.certs)
+		}
+	}
+	return g, nil
+}
+
+func newServer(cfg conf.ListenerConfig, handler http.Handler) (*server, error) {
+	s := &server{
+		cfg: cfg,
+		srv: &http.Server{Handler: FilterRoutes(cfg.Routes, handler)},
+	}
+	if cfg.TLS.CertFile != "" {
+		tlsConfig, certs, err := newTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		s.srv.TLSConfig = tlsConfig
+		s.certs = certs
+	}
+
+	ln, err := listen(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.ln = ln
+	return s, nil
+}
+
+// listen binds a TCP address or a unix socket. A stale socket file left by
+// an unclean exit is replaced; any other file at the path is an error.
+func listen(cfg conf.ListenerConfig) (net.Listener, error) {
+	if !conf.IsUnixListener(cfg.Address) {
+		return net.Listen(cfg.Network, cfg.Address)
+	}
+
+	socketPath := strings.TrimPrefix(cfg.Address, "unix:")
+	mode, err := strconv.ParseUint(cfg.SocketMode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket_mode %q: %w", cfg.SocketMode, err)
+	}
+	if info, err := os.Lstat(socketPath); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", socketPath)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, os.FileMode(mode)); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+func (s *server) scheme() string {
+	if s.srv.TLSConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func (s *server) serve() {
+	var err error
+	if s.srv.TLSConfig != nil {
+		err = s.srv.ServeTLS(s.ln, "", "")
+	} else {
+		err = s.srv.Serve(s.ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Failed to serve %s: %v", s.cfg.Address, err)
+	}
+}
+
+// ReloadCertificates reloads the certificate and key of every TLS listener
+// from disk, keeping the current ones when the files cannot be loaded
+func (g *Group) ReloadCertificates() {
+	for _, s := range g.servers {
+		if s.certs == nil {
+			continue
+		}
+		if err := s.certs.reload(); err != nil {
+			log.Printf("⚠️  %s: keeping the current certificate of %s: %v", g.name, s.cfg.Address, err)
+		} else {
+			log.Printf("%s: reloaded the certificate of %s", g.name, s.cfg.Address)
+		}
+	}
+}
+
+// Shutdown stops accepting connections on every listener and waits, until
+// ctx is done, for the requests in flight. Returns ctx's error when requests
+// were still running.
+func (g *Group) Shutdown(ctx context.Context) error {
+	close(g.stop)
+	var wg sync.WaitGroup
+	errs := make([]error, len(g.servers))
+	for i, s := range g.servers {
+		wg.Add(1)
+		go func(i int, s *server) {
+			defer wg.Done()
+			if err := s.srv.Shutdown(ctx); err != nil {
+				log.Printf("%s on %s forced to shutdown: %v", g.name, s.cfg.Address, err)
+				errs[i] = err
+			}
+		}(i, s)
+	}
+	wg.Wait()
+	g.wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Addrs the bound address of every listener, in configuration order
+func (g *Group) Addrs() []net.Addr {
+	addrs := make([]net.Addr, len(g.servers))
+	for i, s := range g.servers {
+		addrs[i] = s.ln.Addr()
+	}
+	return addrs
+}
+
+// FilterRoutes restricts handler to the routes of a listener: public
+// listeners answer 404 for the admin routes, admin listeners for every route
+// except the admin routes, the sync status, /metrics and /health*.
+func FilterRoutes(routes string, handler http.Handler) http.Handler {
+	var allowed func(p string) bool
+	switch routes {
+	case conf.ListenerRoutesPublic:
+		allowed = func(p string) bool { return !isAdminPath(p) }
+	case conf.ListenerRoutesAdmin:
+		allowed = func(p string) bool {
+			return isAdminPath(p) || p == "/api/v1/status" || p == "/metrics" ||
+				p == "/health" || strings.HasPrefix(p, "/health/")
+		}
+	default:
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed(path.Clean("/" + r.URL.Path)) {
+			http.NotFound(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func isAdminPath(p string) bool {
+	return p == AdminPrefix || strings.HasPrefix(p, AdminPrefix+"/")
+}
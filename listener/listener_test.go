@@ -0,0 +1,219 @@
+package listener
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	io.WriteString(w, "ok")
+})
+
+func TestFilterRoutes(t *testing.T) {
+	tests := []struct {
+		routes string
+		path   string
+		want   int
+	}{
+		{conf.ListenerRoutesAll, "/api/v1/admin/rescan", http.StatusOK},
+		{conf.ListenerRoutesAll, "/api/v1/files/abc", http.StatusOK},
+		{conf.ListenerRoutesPublic, "/api/v1/files/abc", http.StatusOK},
+		{conf.ListenerRoutesPublic, "/api/v1/admin/rescan", http.StatusNotFound},
+		{conf.ListenerRoutesPublic, "/api/v1/admin", http.StatusNotFound},
+		{conf.ListenerRoutesPublic, "/api/v1/files/../admin/rescan", http.StatusNotFound},
+		{conf.ListenerRoutesPublic, "/api/v1/administrators", http.StatusOK},
+		{conf.ListenerRoutesAdmin, "/api/v1/admin/rescan", http.StatusOK},
+		{conf.ListenerRoutesAdmin, "/api/v1/status", http.StatusOK},
+		{conf.ListenerRoutesAdmin, "/metrics", http.StatusOK},
+		{conf.ListenerRoutesAdmin, "/health/deep", http.StatusOK},
+		{conf.ListenerRoutesAdmin, "/api/v1/files/abc", http.StatusNotFound},
+		{conf.ListenerRoutesAdmin, "/", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.URL.Path = tt.path // Keeps dot segments
+		FilterRoutes(tt.routes, okHandler).ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d", tt.routes, tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestStartTCPAndUnixListeners(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+
+	// A socket left behind by an unclean exit is replaced
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	g, err := Start("test", []conf.ListenerConfig{
+		{Address: "127.0.0.1:0", Network: "tcp", Routes: conf.ListenerRoutesPublic},
+		{Address: "unix:" + socket, Routes: conf.ListenerRoutesAdmin, SocketMode: "0600"},
+	}, okHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Shutdown(context.Background())
+
+	public := &http.Client{}
+	admin := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	publicURL := "http://" + g.Addrs()[0].String()
+	checkStatus(t, public, publicURL+"/api/v1/files/abc", http.StatusOK)
+	checkStatus(t, public, publicURL+"/api/v1/admin/rescan/status", http.StatusNotFound)
+	checkStatus(t, admin, "http://admin/api/v1/admin/rescan/status", http.StatusOK)
+	checkStatus(t, admin, "http://admin/api/v1/files/abc", http.StatusNotFound)
+
+	info, err := os.Stat(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("socket mode %o, want 600", perm)
+	}
+}
+
+func TestStartRefusesNonSocketFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "api.sock")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Start("test", []conf.ListenerConfig{
+		{Address: "127.0.0.1:0", Network: "tcp", Routes: conf.ListenerRoutesAll},
+		{Address: "unix:" + file, Routes: conf.ListenerRoutesAll, SocketMode: "0660"},
+	}, okHandler)
+	if err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Fatalf("err = %v, want not a socket", err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "data" {
+		t.Error("the file was replaced")
+	}
+}
+
+func TestTLSCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "api.pem")
+	keyFile := filepath.Join(dir, "api.key")
+	writeCert(t, certFile, keyFile, 1, time.Now().Add(-time.Hour))
+
+	g, err := Start("test", []conf.ListenerConfig{{
+		Address: "127.0.0.1:0",
+		Network: "tcp",
+		Routes:  conf.ListenerRoutesAll,
+		TLS:     conf.ListenerTLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.2", ReloadSecs: 60},
+	}}, okHandler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Shutdown(context.Background())
+	addr := g.Addrs()[0].String()
+
+	if serial := servedSerial(t, addr); serial != 1 {
+		t.Fatalf("serial %d, want 1", serial)
+	}
+
+	// A broken key pair keeps the current certificate
+	if err := os.WriteFile(keyFile, []byte("broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	g.ReloadCertificates()
+	if serial := servedSerial(t, addr); serial != 1 {
+		t.Fatalf("serial after a failed reload %d, want 1", serial)
+	}
+
+	// Renewed certificates are picked up when the files change, or on SIGHUP
+	writeCert(t, certFile, keyFile, 2, time.Now())
+	if reloaded, err := g.servers[0].certs.reloadIfChanged(); err != nil || !reloaded {
+		t.Fatalf("reloadIfChanged = %v, %v", reloaded, err)
+	}
+	if serial := servedSerial(t, addr); serial != 2 {
+		t.Fatalf("serial after renewal %d, want 2", serial)
+	}
+	if reloaded, err := g.servers[0].certs.reloadIfChanged(); err != nil || reloaded {
+		t.Errorf("unchanged files: reloadIfChanged = %v, %v", reloaded, err)
+	}
+}
+
+func checkStatus(t *testing.T, client *http.Client, url string, want int) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != want {
+		t.Errorf("GET %s: status %d, want %d", url, resp.StatusCode, want)
+	}
+}
+
+// servedSerial the serial number of the certificate served on addr
+func servedSerial(t *testing.T, addr string) int64 {
+	t.Helper()
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+}
+
+// writeCert writes a self-signed certificate and its key, modified at modTime
+func writeCert(t *testing.T, certFile, keyFile string, serial int64, modTime time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
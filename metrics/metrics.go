@@ -0,0 +1,135 @@
+// Package metrics serves the Prometheus metrics of the services (GET
+// /metrics): Go runtime and process metrics, HTTP requests by route and,
+// with the Pebble indexer database, the storage engine metrics of every
+// Pebble instance (LSM levels, compaction debt, read amplification ...) and
+// the size of every collection, for capacity tuning.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"meta-file-system/database"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefix of every metric of the service
+const Namespace = "metafs"
+
+// PebbleSource reports Pebble metrics (*database.PebbleDatabase)
+type PebbleSource interface {
+	PebbleMetrics() database.PebbleMetrics
+}
+
+// Handler serves the metrics in the Prometheus text format; pebble may be nil
+func Handler(pebble PebbleSource) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+	)
+	if pebble != nil {
+		registry.MustRegister(newPebbleCollector(pebble))
+	}
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// pebbleCollector reads the Pebble metrics on every scrape
+type pebbleCollector struct {
+	source PebbleSource
+
+	levelFiles            *prometheus.Desc
+	levelSize             *prometheus.Desc
+	levelScore            *prometheus.Desc
+	levelSublevels        *prometheus.Desc
+	readAmp               *prometheus.Desc
+	compactionDebt        *prometheus.Desc
+	compactionsInProgress *prometheus.Desc
+	compactions           *prometheus.Desc
+	flushes               *prometheus.Desc
+	memTable              *prometheus.Desc
+	wal                   *prometheus.Desc
+	diskUsage             *prometheus.Desc
+	collectionDiskUsage   *prometheus.Desc
+	cacheSize             *prometheus.Desc
+	cacheBlocks           *prometheus.Desc
+	cacheHits             *prometheus.Desc
+	cacheMisses           *prometheus.Desc
+}
+
+func newPebbleCollector(source PebbleSource) *pebbleCollector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(Namespace, "pebble", name), help, labels, nil)
+	}
+	return &pebbleCollector{
+		source:                source,
+		levelFiles:            desc("level_files", "sstables in an LSM level of a Pebble instance", "store", "level"),
+		levelSize:             desc("level_size_bytes", "Size of an LSM level of a Pebble instance", "store", "level"),
+		levelScore:            desc("level_score", "Compaction score of an LSM level; above 1 the level is due for compaction", "store", "level"),
+		levelSublevels:        desc("level_sublevels", "Sublevels of an LSM level (its read amplification)", "store", "level"),
+		readAmp:               desc("read_amplification", "Sublevels a point read of a Pebble instance may consult", "store"),
+		compactionDebt:        desc("compaction_debt_bytes", "Estimated bytes to compact before the instance's LSM is in shape", "store"),
+		compactionsInProgress: desc("compactions_in_progress", "Compactions of a Pebble instance running now", "store"),
+		compactions:           desc("compactions_total", "Compactions of a Pebble instance completed since the indexer started", "store"),
+		flushes:               desc("flushes_total", "Memtable flushes of a Pebble instance since the indexer started", "store"),
+		memTable:              desc("memtable_size_bytes", "Memtable memory of a Pebble instance", "store"),
+		wal:                   desc("wal_size_bytes", "Write-ahead log size of a Pebble instance", "store"),
+		diskUsage:             desc("disk_usage_bytes", "Disk space used by a Pebble instance", "store"),
+		collectionDiskUsage:   desc("collection_disk_usage_bytes", "Estimated disk space used by a collection", "collection"),
+		cacheSize:             desc("block_cache_size_bytes", "Block cache memory in use"),
+		cacheBlocks:           desc("block_cache_blocks", "Blocks held in the block cache"),
+		cacheHits:             desc("block_cache_hits_total", "Block cache hits"),
+		cacheMisses:           desc("block_cache_misses_total", "Block cache misses"),
+	}
+}
+
+func (c *pebbleCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.levelFiles, c.levelSize, c.levelScore, c.levelSublevels, c.readAmp, c.compactionDebt,
+		c.compactionsInProgress, c.compactions, c.flushes, c.memTable, c.wal, c.diskUsage,
+		c.collectionDiskUsage, c.cacheSize, c.cacheBlocks, c.cacheHits, c.cacheMisses,
+	} {
+		ch <- d
+	}
+}
+
+func (c *pebbleCollector) Collect(ch chan<- prometheus.Metric) {
+	m := c.source.PebbleMetrics()
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...)
+	}
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...)
+	}
+
+	for _, store := range m.Stores {
+		name := store.Store
+		for _, l := range store.Levels {
+			level := strconv.Itoa(l.Level)
+			gauge(c.levelFiles, float64(l.Files), name, level)
+			gauge(c.levelSize, float64(l.SizeBytes), name, level)
+			gauge(c.levelScore, l.Score, name, level)
+			gauge(c.levelSublevels, float64(l.Sublevels), name, level)
+		}
+		gauge(c.readAmp, float64(store.ReadAmp), name)
+		gauge(c.compactionDebt, float64(store.CompactionDebtBytes), name)
+		gauge(c.compactionsInProgress, float64(store.CompactionsInProgress), name)
+		counter(c.compactions, float64(store.Compactions), name)
+		counter(c.flushes, float64(store.Flushes), name)
+		gauge(c.memTable, float64(store.MemTableBytes), name)
+		gauge(c.wal, float64(store.WALBytes), name)
+		gauge(c.diskUsage, float64(store.DiskUsageBytes), name)
+	}
+	for _, coll := range m.Collections {
+		gauge(c.collectionDiskUsage, float64(coll.DiskUsageBytes), coll.Collection)
+	}
+	gauge(c.cacheSize, float64(m.BlockCache.SizeBytes))
+	gauge(c.cacheBlocks, float64(m.BlockCache.Blocks))
+	counter(c.cacheHits, float64(m.BlockCache.Hits))
+	counter(c.cacheMisses, float64(m.BlockCache.Misses))
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"meta-file-system/database"
+)
+
+type fakePebble struct{}
+
+func (fakePebble) PebbleMetrics() database.PebbleMetrics {
+	return database.PebbleMetrics{
+		Stores: []database.PebbleStoreMetrics{{
+			Store:               "indexer_kv",
+			Levels:              []database.PebbleLevelMetrics{{Level: 0, Files: 3, SizeBytes: 4096, Score: 1.5, Sublevels: 2}},
+			ReadAmp:             2,
+			CompactionDebtBytes: 123,
+			Compactions:         7,
+		}},
+		Collections: []database.PebbleCollectionMetrics{{Collection: "file_pinid", DiskUsageBytes: 2048}},
+		BlockCache:  database.PebbleCacheMetrics{Hits: 10, Misses: 5},
+	}
+}
+
+func TestHandlerServesPebbleMetrics(t *testing.T) {
+	ObserveHTTPRequest("GET", "/api/v1/files/:pinId", 200, 30*time.Millisecond)
+	rec := httptest.NewRecorder()
+	Handler(fakePebble{}).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`metafs_pebble_level_files{level="0",store="indexer_kv"} 3`,
+		`metafs_pebble_level_score{level="0",store="indexer_kv"} 1.5`,
+		`metafs_pebble_read_amplification{store="indexer_kv"} 2`,
+		`metafs_pebble_compaction_debt_bytes{store="indexer_kv"} 123`,
+		`metafs_pebble_compactions_total{store="indexer_kv"} 7`,
+		`metafs_pebble_collection_disk_usage_bytes{collection="file_pinid"} 2048`,
+		`metafs_pebble_block_cache_hits_total 10`,
+		`metafs_http_requests_total{method="GET",route="/api/v1/files/:pinId",status="200"} 1`,
+		`metafs_http_request_duration_seconds_bucket{method="GET",route="/api/v1/files/:pinId",le="0.05"} 1`,
+		`go_goroutines `,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output lacks %q", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	Handler(nil).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "metafs_pebble_") {
+		t.Error("pebble metrics served without a pebble source")
+	}
+}
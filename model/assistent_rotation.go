@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Sweep outcome of an assistent rotation
+const (
+	AssistentSweepNone    = "none"    // The old assistent address held no unspent outputs in the ledger
+	AssistentSweepSuccess = "success" // Its outputs were swept in SweepTxId
+	AssistentSweepFailed  = "failed"  // The sweep failed (SweepError); it can be retried
+)
+
+// AssistentRotation one replacement of a user's assistant (托管) key: which
+// key was retired for which one, why and by whom, and where the value left
+// on the old assistant address was swept. The rows are the audit trail of
+// assistant key rotations, e.g. after a key was suspected leaked.
+type AssistentRotation struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Chain               string `gorm:"type:varchar(20)" json:"chain"`                        // mvc/doge
+	MetaId              string `gorm:"type:varchar(255)" json:"meta_id"`                     // User MetaID
+	Address             string `gorm:"index;type:varchar(100)" json:"address"`               // User address
+	OldAssistentId      int64  `json:"old_assistent_id"`                                     // Retired tb_file_assistent row
+	OldAssistentAddress string `gorm:"index;type:varchar(100)" json:"old_assistent_address"` // Retired assistant address
+	NewAssistentId      int64  `json:"new_assistent_id"`                                     // tb_file_assistent row replacing it
+	NewAssistentAddress string `gorm:"type:varchar(100)" json:"new_assistent_address"`       // New assistant address
+	Reason              string `gorm:"type:varchar(500)" json:"reason"`                      // Why the key was rotated
+	RequestedBy         string `gorm:"type:varchar(100)" json:"requested_by"`                // Client IP of the admin request
+
+	SweepTo      string `gorm:"type:varchar(20)" json:"sweep_to"`       // assistent (new assistant address) or user
+	SweepAddress string `gorm:"type:varchar(100)" json:"sweep_address"` // Address the outputs were swept to
+	SweepStatus  string `gorm:"type:varchar(20)" json:"sweep_status"`   // none/success/failed
+	SweepTxId    string `gorm:"type:varchar(64)" json:"sweep_tx_id"`    // Latest sweep transaction
+	SweptOutputs int    `json:"swept_outputs"`                          // Outputs of the old address swept so far
+	SweptValue   int64  `json:"swept_value"`                            // Satoshis received by SweepAddress
+	SweepFee     int64  `json:"sweep_fee"`                              // Satoshis paid in sweep fees
+	SweepError   string `gorm:"type:text" json:"sweep_error,omitempty"` // Why the latest sweep failed
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (AssistentRotation) TableName() string {
+	return "tb_assistent_rotation"
+}
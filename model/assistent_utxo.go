@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// AssistentUtxo an output paying a user's assistant (托管) address, recorded
+// when the uploader broadcasts the transaction that creates it and marked
+// spent when it broadcasts the transaction spending it. The rows are the
+// uploader's ledger of value held by its intermediate keys: what is still
+// unspent is the assistant balance, and what was received from the user but
+// is no longer unspent went to fees (and PIN dust outputs).
+type AssistentUtxo struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Chain            string `gorm:"index;type:varchar(20)" json:"chain"`                              // mvc/doge
+	MetaId           string `gorm:"type:varchar(255)" json:"meta_id"`                                 // User MetaID
+	Address          string `gorm:"index;type:varchar(100)" json:"address"`                           // User address
+	AssistentAddress string `gorm:"index;type:varchar(100)" json:"assistent_address"`                 // Assistant address holding the output
+	TxId             string `gorm:"uniqueIndex:idx_assistent_outpoint;type:varchar(64)" json:"tx_id"` // Transaction creating the output
+	Vout             uint32 `gorm:"uniqueIndex:idx_assistent_outpoint" json:"vout"`                   // Output index
+	Value            int64  `json:"value"`                                                            // Satoshis
+	IsChange         bool   `gorm:"type:tinyint(1);default:0" json:"is_change"`                       // Created by a transaction spending assistant outputs (not funded by the user)
+
+	SpentTxId string     `gorm:"index;type:varchar(64);default:''" json:"spent_tx_id"` // Transaction spending the output, empty while unspent
+	SpentAt   *time.Time `gorm:"type:timestamp" json:"spent_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName sets custom table name
+func (AssistentUtxo) TableName() string {
+	return "tb_assistent_utxo"
+}
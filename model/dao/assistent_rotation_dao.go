@@ -0,0 +1,44 @@
+package dao
+
+import (
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// AssistentRotationDAO data access layer for assistent key rotations.
+type AssistentRotationDAO struct{}
+
+// NewAssistentRotationDAO creates a new DAO instance.
+func NewAssistentRotationDAO() *AssistentRotationDAO {
+	return &AssistentRotationDAO{}
+}
+
+// GetByID returns a rotation, nil when there is none.
+func (dao *AssistentRotationDAO) GetByID(id int64) (*model.AssistentRotation, error) {
+	var rotations []*model.AssistentRotation
+	if err := database.UploaderDB.Where("id = ?", id).Limit(1).Find(&rotations).Error; err != nil {
+		return nil, err
+	}
+	if len(rotations) == 0 {
+		return nil, nil
+	}
+	return rotations[0], nil
+}
+
+// Update saves a rotation (sweep outcome).
+func (dao *AssistentRotationDAO) Update(rotation *model.AssistentRotation) error {
+	return database.UploaderDB.Save(rotation).Error
+}
+
+// ListByAddress returns the rotations of a user address, or of the rotation
+// that retired an assistent address, newest first, with IDs below cursor
+// (0 = from the newest).
+func (dao *AssistentRotationDAO) ListByAddress(address string, cursor int64, size int) ([]*model.AssistentRotation, error) {
+	var rotations []*model.AssistentRotation
+	query := database.UploaderDB.Where("address = ? OR old_assistent_address = ?", address, address)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	err := query.Order("id DESC").Limit(size).Find(&rotations).Error
+	return rotations, err
+}
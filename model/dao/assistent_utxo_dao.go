@@ -0,0 +1,105 @@
+package dao
+
+import (
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// AssistentUtxoDAO data access layer for the assistent output ledger.
+type AssistentUtxoDAO struct{}
+
+// NewAssistentUtxoDAO creates a new DAO instance.
+func NewAssistentUtxoDAO() *AssistentUtxoDAO {
+	return &AssistentUtxoDAO{}
+}
+
+// AssistentTotals ledger totals of one group of outputs (a chain, or a user
+// on a chain). Received counts outputs funded by the user, not change.
+type AssistentTotals struct {
+	Chain            string
+	MetaId           string
+	Address          string
+	AssistentAddress string
+	Outputs          int64 // Outputs recorded
+	Received         int64 // Satoshis paid in by users
+	Balance          int64 // Satoshis in unspent outputs
+	PendingOutputs   int64 // Unspent outputs
+}
+
+const assistentTotalsSelect = "COUNT(*) AS outputs, " +
+	"COALESCE(SUM(CASE WHEN is_change THEN 0 ELSE value END), 0) AS received, " +
+	"COALESCE(SUM(CASE WHEN spent_tx_id = '' THEN value ELSE 0 END), 0) AS balance, " +
+	"COALESCE(SUM(CASE WHEN spent_tx_id = '' THEN 1 ELSE 0 END), 0) AS pending_outputs"
+
+// Record saves an output, or keeps the existing one when the same
+// transaction is broadcast again.
+func (dao *AssistentUtxoDAO) Record(utxo *model.AssistentUtxo) error {
+	return database.UploaderDB.
+		Where("tx_id = ? AND vout = ?", utxo.TxId, utxo.Vout).
+		FirstOrCreate(utxo).Error
+}
+
+// MarkSpent marks an unspent output as spent by spentTxID. Returns the
+// output, or nil when it is not in the ledger or already spent.
+func (dao *AssistentUtxoDAO) MarkSpent(txID string, vout uint32, spentTxID string, spentAt time.Time) (*model.AssistentUtxo, error) {
+	var utxo model.AssistentUtxo
+	err := database.UploaderDB.
+		Where("tx_id = ? AND vout = ? AND spent_tx_id = ''", txID, vout).
+		Limit(1).
+		Find(&utxo).Error
+	if err != nil || utxo.ID == 0 {
+		return nil, err
+	}
+	result := database.UploaderDB.Model(&model.AssistentUtxo{}).
+		Where("id = ? AND spent_tx_id = ''", utxo.ID).
+		Updates(map[string]interface{}{"spent_tx_id": spentTxID, "spent_at": spentAt})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &utxo, nil
+}
+
+// TotalsByChain returns the ledger totals of each chain (one chain when chain is set).
+func (dao *AssistentUtxoDAO) TotalsByChain(chain string) ([]*AssistentTotals, error) {
+	var totals []*AssistentTotals
+	query := database.UploaderDB.Model(&model.AssistentUtxo{}).
+		Select("chain, " + assistentTotalsSelect)
+	if chain != "" {
+		query = query.Where("chain = ?", chain)
+	}
+	err := query.Group("chain").Order("chain ASC").Scan(&totals).Error
+	return totals, err
+}
+
+// TotalsByUser returns the ledger totals of each user and assistent address,
+// largest balance first, optionally for one chain and/or one user address.
+func (dao *AssistentUtxoDAO) TotalsByUser(chain, address string, offset, limit int) ([]*AssistentTotals, error) {
+	var totals []*AssistentTotals
+	query := database.UploaderDB.Model(&model.AssistentUtxo{}).
+		Select("chain, address, assistent_address, MAX(meta_id) AS meta_id, " + assistentTotalsSelect)
+	if chain != "" {
+		query = query.Where("chain = ?", chain)
+	}
+	if address != "" {
+		query = query.Where("address = ? OR assistent_address = ?", address, address)
+	}
+	err := query.Group("chain, address, assistent_address").
+		Order("balance DESC, address ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// ListUnspent returns the unspent outputs of a user or assistent address, oldest first.
+func (dao *AssistentUtxoDAO) ListUnspent(address string, limit int) ([]*model.AssistentUtxo, error) {
+	var utxos []*model.AssistentUtxo
+	err := database.UploaderDB.
+		Where("(address = ? OR assistent_address = ?) AND spent_tx_id = ''", address, address).
+		Order("id ASC").
+		Limit(limit).
+		Find(&utxos).Error
+	return utxos, err
+}
@@ -0,0 +1,108 @@
+package dao
+
+import (
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+
+	"gorm.io/gorm"
+)
+
+// DelegatedChargeDAO data access layer for delegated upload charges.
+type DelegatedChargeDAO struct{}
+
+// NewDelegatedChargeDAO creates a new DAO instance.
+func NewDelegatedChargeDAO() *DelegatedChargeDAO {
+	return &DelegatedChargeDAO{}
+}
+
+// DelegatedChargeFilter narrows charge queries; empty fields match all.
+type DelegatedChargeFilter struct {
+	Client  string
+	Address string
+	Status  string
+}
+
+// DelegatedInvoiceTotals charges of one user requested by one client
+type DelegatedInvoiceTotals struct {
+	Client  string
+	Address string
+	MetaId  string
+	Uploads int64 // Charges
+	Amount  int64 // Satoshis
+	FirstAt time.Time
+	LastAt  time.Time
+}
+
+// apply adds the filter's conditions to query
+func (f DelegatedChargeFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Client != "" {
+		query = query.Where("client = ?", f.Client)
+	}
+	if f.Address != "" {
+		query = query.Where("address = ?", f.Address)
+	}
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	return query
+}
+
+// Create saves a charge.
+func (dao *DelegatedChargeDAO) Create(charge *model.DelegatedCharge) error {
+	return database.UploaderDB.Create(charge).Error
+}
+
+// List returns matching charges, newest first, with IDs below cursor (0 = from the newest).
+func (dao *DelegatedChargeDAO) List(filter DelegatedChargeFilter, cursor int64, size int) ([]*model.DelegatedCharge, error) {
+	var charges []*model.DelegatedCharge
+	query := filter.apply(database.UploaderDB.Model(&model.DelegatedCharge{}))
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	err := query.Order("id DESC").Limit(size).Find(&charges).Error
+	return charges, err
+}
+
+// Invoices returns the charge totals of each client and user, largest amount first.
+func (dao *DelegatedChargeDAO) Invoices(filter DelegatedChargeFilter, offset, limit int) ([]*DelegatedInvoiceTotals, error) {
+	var totals []*DelegatedInvoiceTotals
+	err := filter.apply(database.UploaderDB.Model(&model.DelegatedCharge{})).
+		Select("client, address, MAX(meta_id) AS meta_id, COUNT(*) AS uploads, " +
+			"COALESCE(SUM(amount), 0) AS amount, MIN(created_at) AS first_at, MAX(created_at) AS last_at").
+		Group("client, address").
+		Order("amount DESC, address ASC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// Settle marks the unsettled charges of a user settled with ref, all of them
+// or only ids when given, optionally only those requested by client. Returns
+// the charges settled.
+func (dao *DelegatedChargeDAO) Settle(filter DelegatedChargeFilter, ids []int64, ref string, at time.Time) ([]*model.DelegatedCharge, error) {
+	filter.Status = model.ChargeUnsettled
+	var charges []*model.DelegatedCharge
+	err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		query := filter.apply(tx.Model(&model.DelegatedCharge{}))
+		if len(ids) > 0 {
+			query = query.Where("id IN ?", ids)
+		}
+		if err := query.Find(&charges).Error; err != nil || len(charges) == 0 {
+			return err
+		}
+		settled := make([]int64, 0, len(charges))
+		for _, charge := range charges {
+			settled = append(settled, charge.ID)
+			charge.Status = model.ChargeSettled
+			charge.SettlementRef = ref
+			charge.SettledAt = &at
+		}
+		return tx.Model(&model.DelegatedCharge{}).
+			Where("id IN ? AND status = ?", settled, model.ChargeUnsettled).
+			Updates(map[string]interface{}{"status": model.ChargeSettled, "settlement_ref": ref, "settled_at": at}).Error
+	})
+	return charges, err
+}
@@ -1,12 +1,18 @@
 package dao
 
 import (
+	"errors"
+	"time"
+
 	"meta-file-system/database"
 	"meta-file-system/model"
 
 	"gorm.io/gorm"
 )
 
+// ErrAssistentChanged the assistent was retired or replaced by another request
+var ErrAssistentChanged = errors.New("assistent was rotated by another request")
+
 // FileAssistentDAO file assistent data access object
 type FileAssistentDAO struct{}
 
@@ -54,3 +60,44 @@ func (dao *FileAssistentDAO) GetByAddress(address string) (*model.FileAssistent,
 func (dao *FileAssistentDAO) Update(assistent *model.FileAssistent) error {
 	return database.UploaderDB.Save(assistent).Error
 }
+
+// Rotate replaces old by next in one transaction: next is created, old is
+// retired and rotation is recorded with both IDs. Fails with
+// ErrAssistentChanged when old is no longer the active assistent.
+func (dao *FileAssistentDAO) Rotate(old, next *model.FileAssistent, rotation *model.AssistentRotation, at time.Time) error {
+	return database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.FileAssistent{}).
+			Where("id = ? AND status = ?", old.ID, model.StatusSuccess).
+			Updates(map[string]interface{}{"status": model.AssistentStatusRetired, "retired_at": at})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAssistentChanged
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		rotation.OldAssistentId = old.ID
+		rotation.NewAssistentId = next.ID
+		if err := tx.Create(rotation).Error; err != nil {
+			return err
+		}
+		old.Status = model.AssistentStatusRetired
+		old.RetiredAt = &at
+		return nil
+	})
+}
+
+// GetByID get assistent by ID, retired ones included
+func (dao *FileAssistentDAO) GetByID(id int64) (*model.FileAssistent, error) {
+	var assistent model.FileAssistent
+	err := database.UploaderDB.Where("id = ?", id).First(&assistent).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &assistent, nil
+}
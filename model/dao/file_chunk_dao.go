@@ -51,3 +51,8 @@ func (dao *FileChunkDAO) GetByFileHash(fileHash string) ([]*model.FileChunk, err
 func (dao *FileChunkDAO) Update(chunk *model.FileChunk) error {
 	return database.UploaderDB.Save(chunk).Error
 }
+
+// DeleteByFileHash delete all chunks of a file
+func (dao *FileChunkDAO) DeleteByFileHash(fileHash string) error {
+	return database.UploaderDB.Where("file_hash = ?", fileHash).Delete(&model.FileChunk{}).Error
+}
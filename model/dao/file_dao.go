@@ -1,11 +1,13 @@
 package dao
 
 import (
+	"time"
+
 	"meta-file-system/database"
 	"meta-file-system/model"
 )
 
-// FileDAO file data access object (for Uploader service, always uses MySQL)
+// FileDAO file data access object (for Uploader service, UploaderDB: MySQL or SQLite)
 type FileDAO struct{}
 
 // NewFileDAO create file DAO instance
@@ -15,7 +17,7 @@ func NewFileDAO() *FileDAO {
 
 // Create create file record
 func (dao *FileDAO) Create(file *model.File) error {
-	// Uploader always uses MySQL (UploaderDB)
+	// Uploader uses UploaderDB (MySQL or SQLite)
 	return database.UploaderDB.Create(file).Error
 }
 
@@ -39,6 +41,23 @@ func (dao *FileDAO) GetByTxID(txID string) (*model.File, error) {
 	return &file, nil
 }
 
+// ListByTxID list files inscribed by one transaction (several for a batched upload)
+func (dao *FileDAO) ListByTxID(txID string) ([]*model.File, error) {
+	var files []*model.File
+	err := database.UploaderDB.Where("tx_id = ?", txID).Order("id ASC").Find(&files).Error
+	return files, err
+}
+
+// GetByPinID get file by PIN ID
+func (dao *FileDAO) GetByPinID(pinID string) (*model.File, error) {
+	var file model.File
+	err := database.UploaderDB.Where("pin_id = ?", pinID).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
 // GetByPath get file by path
 func (dao *FileDAO) GetByPath(path string) (*model.File, error) {
 	var file model.File
@@ -89,6 +108,30 @@ func (dao *FileDAO) Count() (int64, error) {
 	return count, err
 }
 
+// CountByFileHash count files with the given content hash
+func (dao *FileDAO) CountByFileHash(fileHash string) (int64, error) {
+	var count int64
+	err := database.UploaderDB.Model(&model.File{}).Where("file_hash = ?", fileHash).Count(&count).Error
+	return count, err
+}
+
+// ListFinishedByStorageClass returns files of a storage class that succeeded
+// or failed before beforeTime, oldest first, except the files their owner
+// keeps (FileRetention). Raw content and transaction columns are not loaded.
+func (dao *FileDAO) ListFinishedByStorageClass(class model.StorageClass, beforeTime time.Time, limit int) ([]*model.File, error) {
+	kept := database.UploaderDB.Model(&model.FileRetention{}).
+		Select("pin_id").
+		Where("status = ?", model.FileRetentionActive)
+	var files []*model.File
+	err := database.UploaderDB.Omit("content_hex", "pre_tx_raw", "tx_raw").
+		Where("storage_class = ? AND status IN ? AND updated_at < ?", class, []model.Status{model.StatusSuccess, model.StatusFailed}, beforeTime).
+		Where("pin_id NOT IN (?)", kept).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&files).Error
+	return files, err
+}
+
 // GetMaxBlockHeight get max block height
 func (dao *FileDAO) GetMaxBlockHeight() (int64, error) {
 	var maxHeight int64
@@ -105,3 +148,78 @@ func (dao *FileDAO) GetByID(id int64) (*model.File, error) {
 	}
 	return &file, nil
 }
+
+// FileListFilter selects a user's uploaded files. At least one of MetaId and
+// Address is set; both must match when both are.
+type FileListFilter struct {
+	MetaId  string
+	Address string
+	Status  model.Status // Empty = any status
+}
+
+// ListByOwnerWithCursor returns the files of a user with cursor pagination (id desc).
+// cursor: last file ID from previous page (0 for first page); hasMore reports
+// whether another page follows. Raw content and transaction columns are not loaded.
+func (dao *FileDAO) ListByOwnerWithCursor(filter FileListFilter, cursor int64, size int) ([]*model.File, int64, bool, error) {
+	if size <= 0 || size > 100 {
+		size = 20
+	}
+
+	query := database.UploaderDB.Omit("content_hex", "pre_tx_raw", "tx_raw")
+	if filter.MetaId != "" {
+		query = query.Where("meta_id = ?", filter.MetaId)
+	}
+	if filter.Address != "" {
+		query = query.Where("address = ?", filter.Address)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+
+	// One extra row tells whether another page follows
+	var files []*model.File
+	if err := query.Order("id DESC").Limit(size + 1).Find(&files).Error; err != nil {
+		return nil, 0, false, err
+	}
+	hasMore := len(files) > size
+	if hasMore {
+		files = files[:size]
+	}
+
+	var nextCursor int64
+	if len(files) > 0 {
+		nextCursor = files[len(files)-1].ID
+	}
+	return files, nextCursor, hasMore, nil
+}
+
+// HasIndexerTables reports whether the indexer's file table lives in the
+// uploader database (indexer on MySQL with the same DSN, or on SQLite with the
+// same file)
+func (dao *FileDAO) HasIndexerTables() bool {
+	return database.UploaderDB.Migrator().HasTable(&model.IndexerFile{})
+}
+
+// GetIndexerFilesByPinIDs returns the indexer records of pinIDs keyed by PIN ID.
+// Only valid when HasIndexerTables is true.
+func (dao *FileDAO) GetIndexerFilesByPinIDs(pinIDs []string) (map[string]*model.IndexerFile, error) {
+	result := make(map[string]*model.IndexerFile, len(pinIDs))
+	if len(pinIDs) == 0 {
+		return result, nil
+	}
+	var files []*model.IndexerFile
+	err := database.UploaderDB.
+		Select("pin_id", "first_pin_id", "chain_name", "block_height", "timestamp", "status", "status_reason").
+		Where("pin_id IN ?", pinIDs).
+		Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		result[f.PinID] = f
+	}
+	return result, nil
+}
@@ -0,0 +1,84 @@
+package dao
+
+import (
+	"fmt"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// FileRetentionDAO data access layer for owner-signed file retentions.
+type FileRetentionDAO struct{}
+
+// NewFileRetentionDAO creates a new DAO instance.
+func NewFileRetentionDAO() *FileRetentionDAO {
+	return &FileRetentionDAO{}
+}
+
+// GetByPinID returns the retention of a PIN, active or released; nil when
+// the PIN was never kept.
+func (dao *FileRetentionDAO) GetByPinID(pinID string) (*model.FileRetention, error) {
+	var retentions []*model.FileRetention
+	err := database.UploaderDB.Where("pin_id = ?", pinID).Limit(1).Find(&retentions).Error
+	if err != nil || len(retentions) == 0 {
+		return nil, err
+	}
+	return retentions[0], nil
+}
+
+// Save creates the retention, or updates it when it has an ID.
+func (dao *FileRetentionDAO) Save(retention *model.FileRetention) error {
+	if retention == nil {
+		return fmt.Errorf("retention is nil")
+	}
+	if retention.ID == 0 {
+		return database.UploaderDB.Create(retention).Error
+	}
+	return database.UploaderDB.Model(&model.FileRetention{}).
+		Where("id = ?", retention.ID).
+		Select("*").
+		Updates(retention).Error
+}
+
+// ActiveUsage returns how many files owner keeps and their total size.
+func (dao *FileRetentionDAO) ActiveUsage(owner string) (int64, int64, error) {
+	var usage struct {
+		Files int64
+		Bytes int64
+	}
+	err := database.UploaderDB.Model(&model.FileRetention{}).
+		Select("COUNT(*) AS files, COALESCE(SUM(file_size), 0) AS bytes").
+		Where("owner = ? AND status = ?", owner, model.FileRetentionActive).
+		Scan(&usage).Error
+	return usage.Files, usage.Bytes, err
+}
+
+// ListActiveByOwner returns the files owner keeps, most recently kept first.
+func (dao *FileRetentionDAO) ListActiveByOwner(owner string, limit int) ([]*model.FileRetention, error) {
+	var retentions []*model.FileRetention
+	err := database.UploaderDB.
+		Where("owner = ? AND status = ?", owner, model.FileRetentionActive).
+		Order("kept_at DESC").
+		Limit(limit).
+		Find(&retentions).Error
+	return retentions, err
+}
+
+// GetActiveByPinIDs returns the active retentions of pinIDs keyed by PIN ID.
+func (dao *FileRetentionDAO) GetActiveByPinIDs(pinIDs []string) (map[string]*model.FileRetention, error) {
+	result := make(map[string]*model.FileRetention, len(pinIDs))
+	if len(pinIDs) == 0 {
+		return result, nil
+	}
+	var retentions []*model.FileRetention
+	err := database.UploaderDB.
+		Where("pin_id IN ? AND status = ?", pinIDs, model.FileRetentionActive).
+		Find(&retentions).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range retentions {
+		result[r.PinId] = r
+	}
+	return result, nil
+}
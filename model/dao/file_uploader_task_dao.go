@@ -63,6 +63,125 @@ func (dao *FileUploaderTaskDAO) GetPendingTasks(limit int) ([]*model.FileUploade
 	return tasks, err
 }
 
+// ClaimForProcessing marks a pending (or stalled processing) task as
+// processing; false when it has meanwhile been cancelled or released elsewhere.
+func (dao *FileUploaderTaskDAO) ClaimForProcessing(task *model.FileUploaderTask) (bool, error) {
+	result := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Where("id = ? AND status IN ?", task.ID, []model.Status{model.StatusPending, "processing"}).
+		Updates(map[string]interface{}{
+			"status":       task.Status,
+			"started_at":   task.StartedAt,
+			"current_step": task.CurrentStep,
+			"progress":     task.Progress,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetScheduledTasks returns tasks holding their broadcast, earliest broadcastAt first.
+func (dao *FileUploaderTaskDAO) GetScheduledTasks(limit int) ([]*model.FileUploaderTask, error) {
+	var tasks []*model.FileUploaderTask
+	err := database.UploaderDB.Where("status = ?", model.TaskStatusScheduled).
+		Order("broadcast_at ASC").
+		Limit(limit).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ReleaseScheduledTask moves a scheduled task back to pending so the task
+// processor broadcasts it; false when it is no longer scheduled.
+func (dao *FileUploaderTaskDAO) ReleaseScheduledTask(id int64, releasedAt time.Time, step string) (bool, error) {
+	result := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Where("id = ? AND status = ?", id, model.TaskStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":       model.StatusPending,
+			"released_at":  releasedAt,
+			"current_step": step,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// FailScheduledTask fails a scheduled task and drops its built transactions;
+// false when it is no longer scheduled.
+func (dao *FileUploaderTaskDAO) FailScheduledTask(id int64, finishedAt time.Time, message string) (bool, error) {
+	result := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Where("id = ? AND status = ?", id, model.TaskStatusScheduled).
+		Updates(taskEndUpdates(model.StatusFailed, finishedAt, message))
+	return result.RowsAffected > 0, result.Error
+}
+
+// Cancel cancels a task of address that has not broadcast anything yet
+// (pending or scheduled, stage created/prepared); false when there is no such task.
+func (dao *FileUploaderTaskDAO) Cancel(taskID, address string, finishedAt time.Time) (bool, error) {
+	result := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Where("task_id = ? AND address = ?", taskID, address).
+		Where("status IN ?", []model.Status{model.StatusPending, model.TaskStatusScheduled}).
+		Where("stage IN ?", []model.TaskStage{"", model.TaskStageCreated, model.TaskStagePrepared}).
+		Updates(taskEndUpdates(model.TaskStatusCancelled, finishedAt, "Cancelled before broadcast"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// ExpireStale expires tasks in status not updated since updatedBefore that
+// have not broadcast anything yet (stage created/prepared), dropping their
+// payload. Returns the number of tasks expired.
+func (dao *FileUploaderTaskDAO) ExpireStale(status model.Status, updatedBefore, finishedAt time.Time, message string) (int64, error) {
+	result := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Where("status = ? AND updated_at < ?", status, updatedBefore).
+		Where("stage IN ?", []model.TaskStage{"", model.TaskStageCreated, model.TaskStagePrepared}).
+		Updates(taskEndUpdates(model.TaskStatusExpired, finishedAt, message))
+	return result.RowsAffected, result.Error
+}
+
+// CountFinishedSince counts tasks by final status among those finished at or after since
+func (dao *FileUploaderTaskDAO) CountFinishedSince(since time.Time) (map[model.Status]int64, error) {
+	var rows []struct {
+		Status model.Status
+		Count  int64
+	}
+	err := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Select("status, COUNT(*) AS count").
+		Where("finished_at >= ?", since).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[model.Status]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// taskEndUpdates ends a task that never broadcast, dropping its payload like
+// a finished task
+func taskEndUpdates(status model.Status, finishedAt time.Time, message string) map[string]interface{} {
+	updates := map[string]interface{}{
+		"status":           status,
+		"current_step":     message,
+		"finished_at":      finishedAt,
+		"chunk_pre_tx_hex": "",
+		"index_pre_tx_hex": "",
+		"merge_tx_hex":     "",
+		"chunk_funding_tx": "",
+		"content_base64":   "",
+		"chunk_tx_hexes":   "",
+	}
+	if status == model.StatusFailed {
+		updates["error_message"] = message
+	}
+	return updates
+}
+
+// CountActiveByAddress counts the tasks of a user address that may still
+// broadcast: pending, processing or scheduled.
+func (dao *FileUploaderTaskDAO) CountActiveByAddress(address string) (int64, error) {
+	var count int64
+	err := database.UploaderDB.Model(&model.FileUploaderTask{}).
+		Where("address = ? AND status IN ?", address, []model.Status{model.StatusPending, "processing", model.TaskStatusScheduled}).
+		Count(&count).Error
+	return count, err
+}
+
 // GetProcessingTasks returns processing tasks ordered by creation time ascending.
 func (dao *FileUploaderTaskDAO) GetProcessingTasks(limit int) ([]*model.FileUploaderTask, error) {
 	var tasks []*model.FileUploaderTask
@@ -107,8 +226,9 @@ func (dao *FileUploaderTaskDAO) Count(status string) (int64, error) {
 }
 
 // ListByAddressWithCursor returns tasks by address with cursor pagination (id desc).
-// cursor: last task ID from previous page (0 for first page).
-func (dao *FileUploaderTaskDAO) ListByAddressWithCursor(address string, cursor int64, size int) ([]*model.FileUploaderTask, int64, error) {
+// cursor: last task ID from previous page (0 for first page); hasMore reports
+// whether another page follows.
+func (dao *FileUploaderTaskDAO) ListByAddressWithCursor(address string, cursor int64, size int) ([]*model.FileUploaderTask, int64, bool, error) {
 	if size <= 0 || size > 100 {
 		size = 20
 	}
@@ -119,8 +239,13 @@ func (dao *FileUploaderTaskDAO) ListByAddressWithCursor(address string, cursor i
 		query = query.Where("id < ?", cursor)
 	}
 
-	if err := query.Order("id DESC").Limit(size).Find(&tasks).Error; err != nil {
-		return nil, 0, err
+	// One extra row tells whether another page follows
+	if err := query.Order("id DESC").Limit(size + 1).Find(&tasks).Error; err != nil {
+		return nil, 0, false, err
+	}
+	hasMore := len(tasks) > size
+	if hasMore {
+		tasks = tasks[:size]
 	}
 
 	var nextCursor int64
@@ -128,5 +253,5 @@ func (dao *FileUploaderTaskDAO) ListByAddressWithCursor(address string, cursor i
 		nextCursor = tasks[len(tasks)-1].ID
 	}
 
-	return tasks, nextCursor, nil
+	return tasks, nextCursor, hasMore, nil
 }
@@ -0,0 +1,127 @@
+package dao
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// setTestUploaderDB opens a SQLite uploader database for the test
+func setTestUploaderDB(t *testing.T) {
+	t.Helper()
+	prevCfg, prevDB := conf.Cfg, database.UploaderDB
+	conf.Cfg = &conf.Config{}
+	conf.Cfg.Database.UploaderType = string(database.DBTypeSQLite)
+	conf.Cfg.Database.SqlitePath = filepath.Join(t.TempDir(), "uploader.db")
+	if err := database.InitUploaderDB(); err != nil {
+		t.Fatalf("InitUploaderDB: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.CloseUploaderDB()
+		conf.Cfg, database.UploaderDB = prevCfg, prevDB
+	})
+}
+
+func createTestTask(t *testing.T, dao *FileUploaderTaskDAO, task *model.FileUploaderTask) {
+	t.Helper()
+	if err := dao.Create(task); err != nil {
+		t.Fatalf("Create(%s): %v", task.TaskId, err)
+	}
+}
+
+func TestFileUploaderTaskDAO_ExpireStale(t *testing.T) {
+	setTestUploaderDB(t)
+	dao := NewFileUploaderTaskDAO()
+	now := time.Now()
+	old, recent := now.Add(-48*time.Hour), now.Add(-time.Hour)
+
+	for _, task := range []*model.FileUploaderTask{
+		{TaskId: "stale", Status: model.StatusPending, Stage: model.TaskStageCreated, ContentBase64: "AAAA", UpdatedAt: old},
+		{TaskId: "stale-prepared", Status: model.StatusPending, Stage: model.TaskStagePrepared, UpdatedAt: old},
+		{TaskId: "recent", Status: model.StatusPending, Stage: model.TaskStageCreated, UpdatedAt: recent},
+		{TaskId: "broadcasting", Status: model.StatusPending, Stage: model.TaskStageChunkBroadcast, UpdatedAt: old},
+		{TaskId: "old-processing", Status: "processing", Stage: model.TaskStageCreated, UpdatedAt: old},
+		{TaskId: "done", Status: model.StatusSuccess, Stage: model.TaskStageCompleted, UpdatedAt: old},
+	} {
+		createTestTask(t, dao, task)
+	}
+
+	n, err := dao.ExpireStale(model.StatusPending, now.Add(-24*time.Hour), now, "Expired: pending for 24h without broadcasting")
+	if err != nil {
+		t.Fatalf("ExpireStale: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expired %d tasks, want 2", n)
+	}
+
+	want := map[string]model.Status{
+		"stale":          model.TaskStatusExpired,
+		"stale-prepared": model.TaskStatusExpired,
+		"recent":         model.StatusPending,
+		"broadcasting":   model.StatusPending,
+		"old-processing": "processing",
+		"done":           model.StatusSuccess,
+	}
+	for taskID, status := range want {
+		task, err := dao.GetByTaskID(taskID)
+		if err != nil {
+			t.Fatalf("GetByTaskID(%s): %v", taskID, err)
+		}
+		if task.Status != status {
+			t.Errorf("%s: status = %s, want %s", taskID, task.Status, status)
+		}
+		if status != model.TaskStatusExpired {
+			continue
+		}
+		if task.FinishedAt == nil || task.ContentBase64 != "" {
+			t.Errorf("%s: finished_at = %v, content %q; want set and dropped", taskID, task.FinishedAt, task.ContentBase64)
+		}
+	}
+}
+
+func TestFileUploaderTaskDAO_CountFinishedSince(t *testing.T) {
+	setTestUploaderDB(t)
+	dao := NewFileUploaderTaskDAO()
+	now := time.Now()
+	inWindow, before := now.Add(-time.Hour), now.Add(-48*time.Hour)
+
+	tasks := []struct {
+		status     model.Status
+		finishedAt *time.Time
+	}{
+		{model.StatusSuccess, &inWindow},
+		{model.StatusSuccess, &inWindow},
+		{model.StatusSuccess, &before},
+		{model.StatusFailed, &inWindow},
+		{model.TaskStatusExpired, &inWindow},
+		{model.TaskStatusExpired, &before},
+		{model.StatusPending, nil},
+	}
+	for i, task := range tasks {
+		createTestTask(t, dao, &model.FileUploaderTask{TaskId: string(rune('a' + i)), Status: task.status, FinishedAt: task.finishedAt})
+	}
+
+	counts, err := dao.CountFinishedSince(now.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatalf("CountFinishedSince: %v", err)
+	}
+	want := map[model.Status]int64{model.StatusSuccess: 2, model.StatusFailed: 1, model.TaskStatusExpired: 1}
+	if len(counts) != len(want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	for status, n := range want {
+		if counts[status] != n {
+			t.Errorf("%s: %d, want %d", status, counts[status], n)
+		}
+	}
+
+	// Nothing finished in the window
+	counts, err = dao.CountFinishedSince(now.Add(time.Minute))
+	if err != nil || len(counts) != 0 {
+		t.Errorf("empty window: counts = %v, err = %v", counts, err)
+	}
+}
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// HotWalletUtxoDAO data access layer for the outputs of the chunk funding hot wallet.
+type HotWalletUtxoDAO struct{}
+
+// NewHotWalletUtxoDAO creates a new DAO instance.
+func NewHotWalletUtxoDAO() *HotWalletUtxoDAO {
+	return &HotWalletUtxoDAO{}
+}
+
+// HotWalletTotals outputs and satoshis of the hot wallet in one status
+type HotWalletTotals struct {
+	Status  string
+	Outputs int64
+	Value   int64
+}
+
+// Record saves an output, or keeps the existing one when it is recorded again.
+func (dao *HotWalletUtxoDAO) Record(utxo *model.HotWalletUtxo) error {
+	return database.UploaderDB.
+		Where("tx_id = ? AND vout = ?", utxo.TxId, utxo.Vout).
+		FirstOrCreate(utxo).Error
+}
+
+// spendable outputs that are available, or locked before staleBefore
+func spendable(db *gorm.DB, staleBefore time.Time) *gorm.DB {
+	return db.Where("status = ? OR (status = ? AND locked_at < ?)",
+		model.HotWalletUtxoAvailable, model.HotWalletUtxoLocked, staleBefore)
+}
+
+// ListSpendable returns the spendable outputs of the hot wallet address,
+// largest first.
+func (dao *HotWalletUtxoDAO) ListSpendable(chain, address string, staleBefore time.Time, limit int) ([]*model.HotWalletUtxo, error) {
+	var utxos []*model.HotWalletUtxo
+	err := spendable(database.UploaderDB.Where("chain = ? AND address = ?", chain, address), staleBefore).
+		Order("value DESC, id ASC").
+		Limit(limit).
+		Find(&utxos).Error
+	return utxos, err
+}
+
+// Lock locks all of the outputs under lockID, or none of them when one is no
+// longer spendable (taken by another uploader process). Returns whether they
+// were locked.
+func (dao *HotWalletUtxoDAO) Lock(ids []int64, lockID string, staleBefore, now time.Time) (bool, error) {
+	locked := false
+	err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			result := spendable(tx.Model(&model.HotWalletUtxo{}).Where("id = ?", id), staleBefore).
+				Updates(map[string]interface{}{"status": model.HotWalletUtxoLocked, "lock_id": lockID, "locked_at": now})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		locked = true
+		return nil
+	})
+	if err == gorm.ErrRecordNotFound {
+		return false, nil
+	}
+	return locked, err
+}
+
+// Release frees the outputs locked under lockID and drops the pending change
+// of the funding transaction lockID. Returns the outputs freed.
+func (dao *HotWalletUtxoDAO) Release(lockID string) (int64, error) {
+	var released int64
+	err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.HotWalletUtxo{}).
+			Where("status = ? AND lock_id = ?", model.HotWalletUtxoLocked, lockID).
+			Updates(map[string]interface{}{"status": model.HotWalletUtxoAvailable, "lock_id": "", "locked_at": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		released = result.RowsAffected
+		return tx.Where("status = ? AND tx_id = ?", model.HotWalletUtxoPending, lockID).
+			Delete(&model.HotWalletUtxo{}).Error
+	})
+	return released, err
+}
+
+// Exists reports whether the output is in the ledger
+func (dao *HotWalletUtxoDAO) Exists(txID string, vout uint32) (bool, error) {
+	var count int64
+	err := database.UploaderDB.Model(&model.HotWalletUtxo{}).
+		Where("tx_id = ? AND vout = ?", txID, vout).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MarkSpent marks an unspent output as spent by spentTxID. Returns whether
+// the output is in the ledger and was not spent yet.
+func (dao *HotWalletUtxoDAO) MarkSpent(txID string, vout uint32, spentTxID string, spentAt time.Time) (bool, error) {
+	result := database.UploaderDB.Model(&model.HotWalletUtxo{}).
+		Where("tx_id = ? AND vout = ? AND status <> ?", txID, vout, model.HotWalletUtxoSpent).
+		Updates(map[string]interface{}{"status": model.HotWalletUtxoSpent, "spent_tx_id": spentTxID, "spent_at": spentAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Confirm makes the pending change of a broadcast transaction available
+func (dao *HotWalletUtxoDAO) Confirm(txID string) error {
+	return database.UploaderDB.Model(&model.HotWalletUtxo{}).
+		Where("tx_id = ? AND status = ?", txID, model.HotWalletUtxoPending).
+		Update("status", model.HotWalletUtxoAvailable).Error
+}
+
+// TotalsByStatus returns the outputs and satoshis of the hot wallet address per status.
+func (dao *HotWalletUtxoDAO) TotalsByStatus(chain, address string) ([]*HotWalletTotals, error) {
+	var totals []*HotWalletTotals
+	err := database.UploaderDB.Model(&model.HotWalletUtxo{}).
+		Select("status, COUNT(*) AS outputs, COALESCE(SUM(value), 0) AS value").
+		Where("chain = ? AND address = ?", chain, address).
+		Group("status").
+		Order("status ASC").
+		Scan(&totals).Error
+	return totals, err
+}
@@ -36,7 +36,17 @@ func (dao *IndexerFileChunkDAO) GetByParentPinID(parentPinID string) ([]*model.I
 	return dao.db.GetIndexerFileChunksByParentPinID(parentPinID)
 }
 
+// ListBySha256 get the successfully indexed chunks whose content hash is sha256
+func (dao *IndexerFileChunkDAO) ListBySha256(sha256 string) ([]*model.IndexerFileChunk, error) {
+	return dao.db.ListIndexerFileChunksBySha256(sha256)
+}
+
 // Update update chunk record
 func (dao *IndexerFileChunkDAO) Update(chunk *model.IndexerFileChunk) error {
 	return dao.db.UpdateIndexerFileChunk(chunk)
 }
+
+// Iterate calls fn for every chunk PIN record, whatever its status
+func (dao *IndexerFileChunkDAO) Iterate(fn func(*model.IndexerFileChunk) error) error {
+	return dao.db.IterateIndexerFileChunks(fn)
+}
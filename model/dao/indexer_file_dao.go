@@ -31,38 +31,65 @@ func (dao *IndexerFileDAO) GetByPinID(pinID string) (*model.IndexerFile, error)
 	return file, err
 }
 
+// GetByPinIDs get files for many PIN IDs in one query; missing IDs are skipped
+func (dao *IndexerFileDAO) GetByPinIDs(pinIDs []string) ([]*model.IndexerFile, error) {
+	return dao.db.GetIndexerFilesByPinIDs(pinIDs)
+}
+
 // Update update file record
 func (dao *IndexerFileDAO) Update(file *model.IndexerFile) error {
 	return dao.db.UpdateIndexerFile(file)
 }
 
+// UpdateFields rewrites the file record in place; only for changes that leave
+// its status and indexed fields (creator, extension, timestamp, hash) untouched
+func (dao *IndexerFileDAO) UpdateFields(file *model.IndexerFile) error {
+	return dao.db.UpdateIndexerFileFields(file)
+}
+
+// ReassignCreator stores file after its creator fields were corrected, moving
+// it from the creator indexes of previous (the record as stored)
+func (dao *IndexerFileDAO) ReassignCreator(file, previous *model.IndexerFile) error {
+	return dao.db.ReassignIndexerFileCreator(file, previous)
+}
+
+// Iterate calls fn for every file PIN record, whatever its status
+func (dao *IndexerFileDAO) Iterate(fn func(*model.IndexerFile) error) error {
+	return dao.db.IterateIndexerFiles(fn)
+}
+
+// IterateLatest calls fn for the latest version of every file (by first PIN)
+func (dao *IndexerFileDAO) IterateLatest(fn func(*model.IndexerFile) error) error {
+	return dao.db.IterateLatestFileInfo(fn)
+}
+
 // ListWithCursor get file list with cursor pagination
 // cursor: number of records to skip (0 for first page)
 // size: page size
-// Returns: files, nextCursor, error
-func (dao *IndexerFileDAO) ListWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, error) {
+// Returns: files, nextCursor, hasMore, error
+func (dao *IndexerFileDAO) ListWithCursor(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
 	return dao.db.ListIndexerFilesWithCursor(cursor, size)
 }
 
 // GetByCreatorAddressWithCursor get file list by creator address with cursor pagination
 // cursor: number of records to skip (0 for first page)
 // size: page size
-// Returns: files, nextCursor, error
-func (dao *IndexerFileDAO) GetByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
+// Returns: files, nextCursor, hasMore, error
+func (dao *IndexerFileDAO) GetByCreatorAddressWithCursor(address string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
 	return dao.db.GetIndexerFilesByCreatorAddressWithCursor(address, cursor, size)
 }
 
 // GetByCreatorMetaIDWithCursor get file list by creator MetaID with cursor pagination
 // cursor: number of records to skip (0 for first page)
 // size: page size
-// Returns: files, nextCursor, error
-func (dao *IndexerFileDAO) GetByCreatorMetaIDWithCursor(metaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	return dao.db.GetIndexerFilesByCreatorMetaIDWithCursor(metaID, cursor, size)
+// Returns: files, nextCursor, hasMore, error
+func (dao *IndexerFileDAO) GetByCreatorMetaIDWithCursor(metaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	return dao.db.GetIndexerFilesByCreatorMetaIDWithCursor(metaID, filter, cursor, size)
 }
 
 // GetByCreatorGlobalMetaIDWithCursor get file list by creator GlobalMetaID with cursor pagination
-func (dao *IndexerFileDAO) GetByCreatorGlobalMetaIDWithCursor(globalMetaID string, cursor int64, size int) ([]*model.IndexerFile, int64, error) {
-	return dao.db.GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID, cursor, size)
+func (dao *IndexerFileDAO) GetByCreatorGlobalMetaIDWithCursor(globalMetaID string, filter model.IndexerFileFilter, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	return dao.db.GetIndexerFilesByCreatorGlobalMetaIDWithCursor(globalMetaID, filter, cursor, size)
 }
 
 // GetByExtensionWithCursor get file list by file extension with key-based cursor (reverse time order)
@@ -85,6 +112,11 @@ func (dao *IndexerFileDAO) GetFilesCount() (int64, error) {
 	return dao.db.GetIndexerFilesCount()
 }
 
+// GetByFileHash get the successful file PINs whose content sha256 is fileHash
+func (dao *IndexerFileDAO) GetByFileHash(fileHash string) ([]*model.IndexerFile, error) {
+	return dao.db.GetIndexerFilesByFileHash(fileHash)
+}
+
 // GetLatestFileInfoByFirstPinID get latest file info by first PIN ID
 func (dao *IndexerFileDAO) GetLatestFileInfoByFirstPinID(firstPinID string) (*model.IndexerFile, error) {
 	return dao.db.GetLatestFileInfoByFirstPinID(firstPinID)
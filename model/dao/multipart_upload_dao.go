@@ -103,6 +103,18 @@ func (dao *MultipartUploadDAO) DeleteExpiredUploads(beforeTime time.Time) (int64
 	return result.RowsAffected, result.Error
 }
 
+// CountLiveByKey counts uploads other than excludeID that still use the
+// storage key (not aborted or expired); keys are date + file name, so uploads
+// of the same name on one day share a blob
+func (dao *MultipartUploadDAO) CountLiveByKey(key string, excludeID int64) (int64, error) {
+	var count int64
+	err := database.UploaderDB.Model(&model.MultipartUpload{}).
+		Where("`key` = ? AND id <> ? AND status NOT IN ?", key, excludeID,
+			[]model.MultipartUploadStatus{model.MultipartUploadStatusAborted, model.MultipartUploadStatusExpired}).
+		Count(&count).Error
+	return count, err
+}
+
 // CountByStatus returns count of uploads by status
 func (dao *MultipartUploadDAO) CountByStatus(status model.MultipartUploadStatus) (int64, error) {
 	var count int64
@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// PendingCreatorResolutionDAO data access object for creator address lookups
+// queued for retry.
+type PendingCreatorResolutionDAO struct {
+	db database.Database
+}
+
+// NewPendingCreatorResolutionDAO create pending creator resolution DAO instance.
+func NewPendingCreatorResolutionDAO() *PendingCreatorResolutionDAO {
+	return &PendingCreatorResolutionDAO{
+		db: database.DB,
+	}
+}
+
+// Save persists a queued lookup (overwrites on conflict).
+func (dao *PendingCreatorResolutionDAO) Save(r *model.PendingCreatorResolution) error {
+	return dao.db.CreatePendingCreatorResolution(r)
+}
+
+// List returns all queued lookups.
+func (dao *PendingCreatorResolutionDAO) List() ([]*model.PendingCreatorResolution, error) {
+	return dao.db.ListPendingCreatorResolutions()
+}
+
+// Delete removes a queued lookup (after the file was corrected).
+func (dao *PendingCreatorResolutionDAO) Delete(pinID string) error {
+	return dao.db.DeletePendingCreatorResolution(pinID)
+}
@@ -0,0 +1,43 @@
+package dao
+
+import (
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// PendingStorageWriteDAO data access object for storage writes queued for retry.
+type PendingStorageWriteDAO struct {
+	db database.Database
+}
+
+// NewPendingStorageWriteDAO create pending storage write DAO instance.
+func NewPendingStorageWriteDAO() *PendingStorageWriteDAO {
+	return &PendingStorageWriteDAO{
+		db: database.DB,
+	}
+}
+
+// Save persists a queued write (overwrites on conflict).
+func (dao *PendingStorageWriteDAO) Save(w *model.PendingStorageWrite) error {
+	return dao.db.CreatePendingStorageWrite(w)
+}
+
+// GetByStoragePath returns the queued write for a storage path, or (nil, nil)
+// when nothing is queued for it.
+func (dao *PendingStorageWriteDAO) GetByStoragePath(storagePath string) (*model.PendingStorageWrite, error) {
+	w, err := dao.db.GetPendingStorageWrite(storagePath)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	return w, err
+}
+
+// List returns all queued writes.
+func (dao *PendingStorageWriteDAO) List() ([]*model.PendingStorageWrite, error) {
+	return dao.db.ListPendingStorageWrites()
+}
+
+// Delete removes a queued write (after it was stored).
+func (dao *PendingStorageWriteDAO) Delete(storagePath string) error {
+	return dao.db.DeletePendingStorageWrite(storagePath)
+}
@@ -0,0 +1,50 @@
+package dao
+
+import (
+	"fmt"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// ProofDAO data access layer for proof-of-existence timestamps.
+type ProofDAO struct{}
+
+// NewProofDAO creates a new DAO instance.
+func NewProofDAO() *ProofDAO {
+	return &ProofDAO{}
+}
+
+// Save creates the proof of a PIN, or keeps the existing one when the same
+// inscription is recorded again.
+func (dao *ProofDAO) Save(proof *model.Proof) error {
+	var existing model.Proof
+	err := database.UploaderDB.Where("pin_id = ?", proof.PinId).First(&existing).Error
+	if err == nil {
+		*proof = existing
+		return nil
+	}
+	return database.UploaderDB.Create(proof).Error
+}
+
+// Update persists proof changes.
+func (dao *ProofDAO) Update(proof *model.Proof) error {
+	if proof == nil {
+		return fmt.Errorf("proof is nil")
+	}
+	return database.UploaderDB.Model(&model.Proof{}).
+		Where("id = ?", proof.ID).
+		Select("*").
+		Updates(proof).Error
+}
+
+// ListByHash returns the proofs of a hash, oldest first.
+func (dao *ProofDAO) ListByHash(hash string, limit int) ([]*model.Proof, error) {
+	var proofs []*model.Proof
+	err := database.UploaderDB.
+		Where("hash = ?", hash).
+		Order("id ASC").
+		Limit(limit).
+		Find(&proofs).Error
+	return proofs, err
+}
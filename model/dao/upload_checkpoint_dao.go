@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"fmt"
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// UploadCheckpointDAO data access layer for synchronous upload checkpoints.
+type UploadCheckpointDAO struct{}
+
+// NewUploadCheckpointDAO creates a new DAO instance.
+func NewUploadCheckpointDAO() *UploadCheckpointDAO {
+	return &UploadCheckpointDAO{}
+}
+
+// Save creates the checkpoint of a file or replaces the one left by an
+// earlier attempt at the same file.
+func (dao *UploadCheckpointDAO) Save(cp *model.UploadCheckpoint) error {
+	var existing model.UploadCheckpoint
+	err := database.UploaderDB.Where("file_id = ?", cp.FileId).First(&existing).Error
+	if err == nil {
+		cp.ID = existing.ID
+		cp.CreatedAt = existing.CreatedAt
+		return dao.Update(cp)
+	}
+	return database.UploaderDB.Create(cp).Error
+}
+
+// Update persists checkpoint changes.
+func (dao *UploadCheckpointDAO) Update(cp *model.UploadCheckpoint) error {
+	if cp == nil {
+		return fmt.Errorf("checkpoint is nil")
+	}
+	return database.UploaderDB.Model(&model.UploadCheckpoint{}).
+		Where("id = ?", cp.ID).
+		Select("*").
+		Updates(cp).Error
+}
+
+// GetByFileID fetches the checkpoint of a file.
+func (dao *UploadCheckpointDAO) GetByFileID(fileID string) (*model.UploadCheckpoint, error) {
+	var cp model.UploadCheckpoint
+	err := database.UploaderDB.Where("file_id = ?", fileID).First(&cp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// GetStalledPending returns pending checkpoints not updated since
+// updatedBefore, oldest first.
+func (dao *UploadCheckpointDAO) GetStalledPending(updatedBefore time.Time, limit int) ([]*model.UploadCheckpoint, error) {
+	var cps []*model.UploadCheckpoint
+	err := database.UploaderDB.
+		Where("status = ? AND updated_at < ?", model.StatusPending, updatedBefore).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&cps).Error
+	return cps, err
+}
@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// UploadIdempotencyDAO data access layer for upload idempotency keys.
+type UploadIdempotencyDAO struct{}
+
+// NewUploadIdempotencyDAO creates a new DAO instance.
+func NewUploadIdempotencyDAO() *UploadIdempotencyDAO {
+	return &UploadIdempotencyDAO{}
+}
+
+// Create saves a key; fails when the key is already used on the scope.
+func (dao *UploadIdempotencyDAO) Create(record *model.UploadIdempotency) error {
+	return database.UploaderDB.Create(record).Error
+}
+
+// Get returns the key of scope.
+func (dao *UploadIdempotencyDAO) Get(scope, key string) (*model.UploadIdempotency, error) {
+	var record model.UploadIdempotency
+	err := database.UploaderDB.Where("scope = ? AND idem_key = ?", scope, key).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// TakeOver restarts an expired or abandoned key for a new request, unless
+// another request took it over since it was read (updated_at changed).
+// Returns whether this request now holds the key.
+func (dao *UploadIdempotencyDAO) TakeOver(old *model.UploadIdempotency, fingerprint string, expiresAt time.Time) (bool, error) {
+	result := database.UploaderDB.Model(&model.UploadIdempotency{}).
+		Where("id = ? AND updated_at = ?", old.ID, old.UpdatedAt).
+		Updates(map[string]interface{}{
+			"fingerprint": fingerprint,
+			"status":      model.IdempotencyProcessing,
+			"response":    nil,
+			"expires_at":  expiresAt,
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Complete stores the response of the request holding the key.
+func (dao *UploadIdempotencyDAO) Complete(id int64, response []byte) error {
+	return database.UploaderDB.Model(&model.UploadIdempotency{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":   model.IdempotencyCompleted,
+			"response": response,
+		}).Error
+}
+
+// Delete releases a key.
+func (dao *UploadIdempotencyDAO) Delete(id int64) error {
+	return database.UploaderDB.Delete(&model.UploadIdempotency{}, id).Error
+}
+
+// DeleteExpired removes keys that expired before now; returns the number deleted.
+func (dao *UploadIdempotencyDAO) DeleteExpired(now time.Time) (int64, error) {
+	result := database.UploaderDB.Where("expires_at < ?", now).Delete(&model.UploadIdempotency{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// UtxoLockDAO data access layer for the outputs reserved by uploads in flight.
+type UtxoLockDAO struct{}
+
+// NewUtxoLockDAO creates a new DAO instance.
+func NewUtxoLockDAO() *UtxoLockDAO {
+	return &UtxoLockDAO{}
+}
+
+// errUtxoLockTaken rolls back Acquire when an outpoint is held by another owner
+var errUtxoLockTaken = errors.New("utxo lock taken")
+
+// Acquire reserves all of the outpoints for owner until expiresAt, or none of
+// them when one is held by another owner; returns that lock. Locks of other
+// owners that expired before now are taken over; locks owner already holds
+// are extended. Acquire never waits for a lock, so callers locking in any
+// order cannot deadlock; outpoints are still locked in the order given, which
+// callers sort, to keep conflicts between concurrent callers short.
+func (dao *UtxoLockDAO) Acquire(outpoints []model.UtxoLock, owner string, expiresAt, now time.Time) (*model.UtxoLock, error) {
+	var conflict *model.UtxoLock
+	err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		for _, op := range outpoints {
+			if err := tx.Where("chain = ? AND tx_id = ? AND vout = ? AND owner <> ? AND expires_at < ?",
+				op.Chain, op.TxId, op.Vout, owner, now).Delete(&model.UtxoLock{}).Error; err != nil {
+				return err
+			}
+
+			var existing []*model.UtxoLock
+			if err := tx.Where("chain = ? AND tx_id = ? AND vout = ?", op.Chain, op.TxId, op.Vout).
+				Limit(1).Find(&existing).Error; err != nil {
+				return err
+			}
+			switch {
+			case len(existing) == 0:
+				lock := model.UtxoLock{Chain: op.Chain, TxId: op.TxId, Vout: op.Vout, Owner: owner, ExpiresAt: expiresAt}
+				if err := tx.Create(&lock).Error; err != nil {
+					return err
+				}
+			case existing[0].Owner != owner:
+				conflict = existing[0]
+				return errUtxoLockTaken
+			default:
+				if err := tx.Model(existing[0]).Update("expires_at", expiresAt).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errUtxoLockTaken) {
+		return conflict, nil
+	}
+	if err != nil {
+		// An insert may lose against a concurrent Acquire on the unique
+		// outpoint index; report that lock rather than the constraint error
+		for _, op := range outpoints {
+			var existing []*model.UtxoLock
+			if database.UploaderDB.Where("chain = ? AND tx_id = ? AND vout = ? AND owner <> ?",
+				op.Chain, op.TxId, op.Vout, owner).Limit(1).Find(&existing).Error == nil && len(existing) > 0 {
+				return existing[0], nil
+			}
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// Release removes every lock of owner.
+func (dao *UtxoLockDAO) Release(owner string) error {
+	return database.UploaderDB.Where("owner = ?", owner).Delete(&model.UtxoLock{}).Error
+}
+
+// DeleteExpired removes locks that expired before now; returns the number deleted.
+func (dao *UtxoLockDAO) DeleteExpired(now time.Time) (int64, error) {
+	result := database.UploaderDB.Where("expires_at < ?", now).Delete(&model.UtxoLock{})
+	return result.RowsAffected, result.Error
+}
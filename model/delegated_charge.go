@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Settlement state of a delegated upload charge
+const (
+	ChargeUnsettled = "unsettled" // Invoiced, not yet paid
+	ChargeSettled   = "settled"   // Paid; SettlementRef says how
+)
+
+// DelegatedCharge the cost of one delegated upload (uploader.delegated): the
+// satoshis the operator hot wallet sent to the user's assistent address to
+// fund the chunk and index transactions, owed by the user until settled.
+type DelegatedCharge struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Client       string `gorm:"index;type:varchar(100)" json:"client"`  // API client (uploader.delegated.api_keys name) that requested the upload
+	FileId       string `gorm:"index;type:varchar(255)" json:"file_id"` // Uploaded file
+	MetaId       string `gorm:"type:varchar(255)" json:"meta_id"`       // User MetaID
+	Address      string `gorm:"index;type:varchar(100)" json:"address"` // User address, invoiced
+	Chain        string `gorm:"type:varchar(20)" json:"chain"`          // mvc
+	Status       string `gorm:"index;type:varchar(20)" json:"status"`   // unsettled/settled
+	UploadStatus Status `gorm:"type:varchar(20)" json:"upload_status"`  // Upload outcome: success/pending/failed
+	Message      string `gorm:"type:varchar(500)" json:"message"`       // Upload outcome message
+
+	ChunkFundingTxId string `gorm:"type:varchar(64)" json:"chunk_funding_tx_id"` // Hot wallet payment funding the chunk transactions
+	IndexFundingTxId string `gorm:"type:varchar(64)" json:"index_funding_tx_id"` // Hot wallet payment funding the index transaction
+	IndexTxId        string `gorm:"type:varchar(64)" json:"index_tx_id"`         // Index transaction of the upload
+	FileSize         int64  `json:"file_size"`                                   // Bytes uploaded
+	Amount           int64  `json:"amount"`                                      // Satoshis sent by the hot wallet (node wallet fees not included)
+
+	SettlementRef string     `gorm:"type:varchar(255)" json:"settlement_ref,omitempty"` // Payment reference given when settling (txid, invoice number, ...)
+	SettledAt     *time.Time `gorm:"type:timestamp" json:"settled_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (DelegatedCharge) TableName() string {
+	return "tb_delegated_charge"
+}
@@ -15,6 +15,23 @@ const (
 	StatusPending Status = "pending"
 	StatusSuccess Status = "success"
 	StatusFailed  Status = "failed"
+
+	// StatusRejected content refused by the indexer (e.g. a gzip bomb); not stored
+	StatusRejected Status = "rejected"
+
+	// StatusPendingStorage indexed, but the storage write failed; the content
+	// waits in the PendingStorageWrite queue until the retrier stores it
+	StatusPendingStorage Status = "pending_storage"
+)
+
+// StorageClass retention hint chosen at upload; the on-chain data is permanent
+// whatever the class, it only tells the operator what may be dropped locally
+type StorageClass string
+
+const (
+	StorageClassHot       StorageClass = "hot"       // Default: kept
+	StorageClassCold      StorageClass = "cold"      // Rarely read: kept, may go to cheaper storage
+	StorageClassEphemeral StorageClass = "ephemeral" // Throwaway: local records may be pruned
 )
 
 // File file metadata model
@@ -31,22 +48,26 @@ type File struct {
 	FileContentType string    `gorm:"type:varchar(100)" json:"file_content_type"` // File content type
 	ChunkType       ChunkType `gorm:"type:varchar(20)" json:"chunk_type"`         // single/multi
 
+	IsGzipCompressed bool `gorm:"type:tinyint(1);default:0" json:"is_gzip_compressed"` // Content was inscribed gzip compressed
+
+	StorageClass StorageClass `gorm:"index;type:varchar(20);default:'hot'" json:"storage_class"` // hot/cold/ephemeral
+
 	ContentHex string `gorm:"type:text" json:"content_hex"` // Content hexadecimal
 
-	MetaId  string `gorm:"type:varchar(255)" json:"meta_id"` // MetaID
-	Address string `gorm:"type:varchar(255)" json:"address"` //
+	MetaId  string `gorm:"index;type:varchar(255)" json:"meta_id"` // MetaID
+	Address string `gorm:"index;type:varchar(255)" json:"address"` // Uploader address
 
-	TxID        string `gorm:"uniqueIndex;type:varchar(64);not null" json:"tx_id"` // On-chain transaction ID
-	PinId       string `gorm:"index;type:varchar(255);not null" json:"pin_id"`     // Pin ID
-	Path        string `gorm:"index;type:varchar(255);not null" json:"path"`       // MetaID path
-	ContentType string `gorm:"type:varchar(100)" json:"content_type"`              // Content type   - metafile/index
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss
-	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"`              // Storage path
-	Operation   string `gorm:"type:varchar(20)" json:"operation"`                  // create/modify/revoke
+	TxID        string `gorm:"index;type:varchar(64);not null" json:"tx_id"`   // On-chain transaction ID (shared by batched uploads)
+	PinId       string `gorm:"index;type:varchar(255);not null" json:"pin_id"` // Pin ID
+	Path        string `gorm:"index;type:varchar(255);not null" json:"path"`   // MetaID path
+	ContentType string `gorm:"type:varchar(100)" json:"content_type"`          // Content type   - metafile/index
+	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`           // local/oss
+	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"`          // Storage path
+	Operation   string `gorm:"type:varchar(20)" json:"operation"`              // create/modify/revoke
 
-	PreTxRaw string `gorm:"type:text" json:"pre_tx_raw"`    // Pre-transaction raw data
-	TxRaw    string `gorm:"type:text" json:"tx_raw"`        // Transaction raw data
-	Status   Status `gorm:"type:varchar(20)" json:"status"` // pending/success/failed
+	PreTxRaw string `gorm:"type:text" json:"pre_tx_raw"`          // Pre-transaction raw data
+	TxRaw    string `gorm:"type:text" json:"tx_raw"`              // Transaction raw data
+	Status   Status `gorm:"index;type:varchar(20)" json:"status"` // pending/success/failed
 
 	BlockHeight int64     `gorm:"index" json:"block_height"`        // Block height
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"` // Creation time
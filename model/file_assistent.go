@@ -2,6 +2,10 @@ package model
 
 import "time"
 
+// AssistentStatusRetired an assistent replaced by a key rotation; the row is
+// kept for the audit trail and for sweeping outputs still paid to it
+const AssistentStatusRetired Status = "retired"
+
 // FileAssistent 文件托管助手模型（用于存储托管地址私钥，帮助用户异步上链分片）
 type FileAssistent struct {
 	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -15,7 +19,8 @@ type FileAssistent struct {
 	AssistentPriHex  string `gorm:"type:text;not null" json:"assistent_pri_hex"`               // 托管地址私钥（hex格式）
 
 	// 状态字段
-	Status Status `gorm:"type:varchar(20);default:'success'" json:"status"` // success/failed
+	Status    Status     `gorm:"type:varchar(20);default:'success'" json:"status"` // success/failed/retired
+	RetiredAt *time.Time `gorm:"type:timestamp" json:"retired_at"`                 // 密钥轮换停用时间
 
 	// 时间戳
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"` // 创建时间
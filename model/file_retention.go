@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Status of a file retention
+const (
+	FileRetentionActive   = "active"   // The file is kept
+	FileRetentionReleased = "released" // The owner unkept it; its storage class applies again
+)
+
+// FileRetention an owner's request to keep the local records of an uploaded
+// file, proven with a signature by the key of the file's uploader address.
+// Kept files are never pruned, whatever their storage class. One row per
+// PIN: keeping a released file again reactivates it.
+type FileRetention struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	PinId    string `gorm:"uniqueIndex;type:varchar(80)" json:"pin_id"`                    // Kept PIN
+	FileId   string `gorm:"type:varchar(255)" json:"file_id"`                              // Uploaded file record
+	Owner    string `gorm:"index:idx_file_retention_owner;type:varchar(100)" json:"owner"` // Uploader address of the file
+	FileSize int64  `json:"file_size"`                                                     // Counted against the owner's quota
+
+	Status string `gorm:"index:idx_file_retention_owner;type:varchar(20)" json:"status"` // active/released
+
+	PublicKey string    `gorm:"type:varchar(130)" json:"public_key"` // Key that signed the latest request
+	Signature string    `gorm:"type:varchar(150)" json:"signature"`  // Latest request signature (DER, hex)
+	SignedAt  time.Time `json:"signed_at"`                           // Timestamp of the latest request; older requests are refused
+
+	KeptAt     time.Time  `json:"kept_at"`               // When the file was (last) kept
+	ReleasedAt *time.Time `json:"released_at,omitempty"` // When it was unkept
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (FileRetention) TableName() string {
+	return "tb_file_retention"
+}
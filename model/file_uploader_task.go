@@ -15,6 +15,13 @@ const (
 	TaskStageCompleted        TaskStage = "completed"         // Finished
 )
 
+// Task-only statuses, besides pending/processing/success/failed
+const (
+	TaskStatusScheduled Status = "scheduled" // Transactions built, broadcast held until BroadcastAt or TargetFeeRate
+	TaskStatusCancelled Status = "cancelled" // Cancelled by the uploader before anything was broadcast
+	TaskStatusExpired   Status = "expired"   // Broadcast nothing within uploader.task_ttl; payload dropped
+)
+
 // FileUploaderTask represents an async chunk upload task
 type FileUploaderTask struct {
 	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -34,8 +41,14 @@ type FileUploaderTask struct {
 	Operation     string `gorm:"type:varchar(20)" json:"operation"`     // create/update
 	ContentBase64 string `gorm:"type:longtext" json:"content_base64"`   // File content (base64)
 
+	StorageClass StorageClass `gorm:"type:varchar(20);default:'hot'" json:"storage_class"` // hot/cold/ephemeral, copied to the file
+	Compression  string       `gorm:"type:varchar(20)" json:"compression"`                 // Compression declared in the index (none/gzip)
+	Encryption   string       `gorm:"type:text" json:"encryption"`                         // Encryption envelope declared in the index (JSON, empty if none)
+	Delta        string       `gorm:"type:text" json:"delta"`                              // Delta envelope of a delta upload (JSON, empty if none); the content is the delta
+
 	// Chain (mvc/doge)
-	Chain string `gorm:"type:varchar(20);default:'mvc'" json:"chain"` // Blockchain (mvc/doge)
+	Chain           string `gorm:"type:varchar(20);default:'mvc'" json:"chain"` // Blockchain (mvc/doge)
+	FundingStrategy string `gorm:"type:varchar(20)" json:"funding_strategy"`    // Chunk funding: assistent/hot_wallet (empty = uploader.chunk_funding.strategy)
 
 	// Transaction info
 	ChunkPreTxHex string `gorm:"type:text" json:"chunk_pre_tx_hex"` // Pre-built chunk tx
@@ -43,8 +56,13 @@ type FileUploaderTask struct {
 	MergeTxHex    string `gorm:"type:text" json:"merge_tx_hex"`     // Merge tx hex
 	FeeRate       int64  `json:"fee_rate"`                          // Fee rate
 
+	// Delayed broadcast (either set = build now, hold the broadcast)
+	BroadcastAt   *time.Time `gorm:"type:timestamp;index" json:"broadcast_at"` // Broadcast no later than this time
+	TargetFeeRate int64      `json:"target_fee_rate"`                          // Broadcast earlier once the network fee rate is at or below this
+	ReleasedAt    *time.Time `gorm:"type:timestamp" json:"released_at"`        // When the scheduler released the held broadcast
+
 	// Task status & progress
-	Status          Status    `gorm:"type:varchar(20);default:'pending'" json:"status"` // pending/processing/success/failed
+	Status          Status    `gorm:"type:varchar(20);default:'pending'" json:"status"` // pending/processing/scheduled/success/failed/cancelled/expired
 	Progress        int       `gorm:"type:int;default:0" json:"progress"`               // Percent (0-100)
 	TotalChunks     int       `gorm:"type:int;default:0" json:"total_chunks"`           // Total chunks
 	ProcessedChunks int       `gorm:"type:int;default:0" json:"processed_chunks"`       // Processed chunks
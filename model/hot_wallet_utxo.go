@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Hot wallet output states
+const (
+	HotWalletUtxoAvailable = "available" // Spendable by the next upload
+	HotWalletUtxoLocked    = "locked"    // Selected by an upload whose funding transaction is not broadcast yet
+	HotWalletUtxoPending   = "pending"   // Change of a funding transaction not broadcast yet
+	HotWalletUtxoSpent     = "spent"     // Spent by a broadcast transaction
+)
+
+// HotWalletUtxo an output of the operator hot wallet that funds chunk
+// transactions (uploader.chunk_funding.strategy hot_wallet). Deposits are
+// recorded through the admin route, change when the uploader builds a funding
+// transaction. An upload locks the outputs it spends under the txid of its
+// funding transaction; broadcasting that transaction marks them spent and
+// its change available, a failed upload releases them.
+type HotWalletUtxo struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Chain    string `gorm:"index;type:varchar(20)" json:"chain"`                               // mvc
+	Address  string `gorm:"index;type:varchar(100)" json:"address"`                            // Hot wallet address holding the output
+	TxId     string `gorm:"uniqueIndex:idx_hot_wallet_outpoint;type:varchar(64)" json:"tx_id"` // Transaction creating the output
+	Vout     uint32 `gorm:"uniqueIndex:idx_hot_wallet_outpoint" json:"vout"`                   // Output index
+	Value    int64  `json:"value"`                                                             // Satoshis
+	IsChange bool   `gorm:"type:tinyint(1);default:0" json:"is_change"`                        // Change of a funding transaction (not a deposit)
+
+	Status    string     `gorm:"index;type:varchar(20)" json:"status"`             // available/locked/pending/spent
+	LockId    string     `gorm:"index;type:varchar(64);default:''" json:"lock_id"` // Funding txid holding the lock
+	LockedAt  *time.Time `gorm:"type:timestamp" json:"locked_at"`                  // Locked at; stale after uploader.chunk_funding.lock_minutes
+	SpentTxId string     `gorm:"type:varchar(64);default:''" json:"spent_tx_id"`   // Transaction spending the output
+	SpentAt   *time.Time `gorm:"type:timestamp" json:"spent_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (HotWalletUtxo) TableName() string {
+	return "tb_hot_wallet_utxo"
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChangeAction kind of indexing action recorded in the change log
+type ChangeAction string
+
+const (
+	ChangeFileCreated          ChangeAction = "file_created"            // File PIN indexed (create)
+	ChangeFileModified         ChangeAction = "file_modified"           // File PIN indexed (modify)
+	ChangeFileRevoked          ChangeAction = "file_revoked"            // Revoke PIN applied to a file
+	ChangeFileConfirmed        ChangeAction = "file_confirmed"          // Mempool file confirmed in a block
+	ChangeFileCreatorCorrected ChangeAction = "file_creator_corrected"  // Creator of a file indexed with the fallback address looked up again
+	ChangeChunkIndexed         ChangeAction = "chunk_indexed"           // Chunk PIN indexed
+	ChangeChunksMerged         ChangeAction = "chunks_merged"           // Index PIN merged its chunks into a file
+	ChangeUserNameUpdated      ChangeAction = "user_name_updated"       // /info/name
+	ChangeAvatarUpdated        ChangeAction = "avatar_updated"          // /info/avatar
+	ChangeUserBioUpdated       ChangeAction = "user_bio_updated"        // /info/bio
+	ChangeChatPublicKeyUpdated ChangeAction = "chat_public_key_updated" // /info/chatpubkey
+	ChangeProfileChanged       ChangeAction = "profile_changed"         // Latest name, avatar or chat public key changed (Record is a ProfileChange)
+)
+
+// IndexerChangeEvent one entry of the append-only change log. Seq increases with
+// every event, so consumers can resume from the last Seq they saw; Record is
+// the record as written, so the indexed state can be rebuilt by replay.
+type IndexerChangeEvent struct {
+	Seq         int64           `gorm:"primaryKey;autoIncrement" json:"seq"`
+	Action      ChangeAction    `gorm:"type:varchar(40);not null" json:"action"`
+	ChainName   string          `gorm:"type:varchar(20)" json:"chain_name"`
+	PinID       string          `gorm:"type:varchar(255)" json:"pin_id"`
+	FirstPinID  string          `gorm:"type:varchar(255)" json:"first_pin_id,omitempty"` // File the PIN belongs to (modify/revoke/merge)
+	MetaId      string          `gorm:"type:varchar(64)" json:"meta_id,omitempty"`       // Creator MetaID
+	BlockHeight int64           `json:"block_height"`                                    // 0 while in mempool
+	Timestamp   int64           `json:"timestamp"`                                       // PIN timestamp (ms)
+	Record      json.RawMessage `gorm:"type:mediumtext" json:"record,omitempty"`         // JSON of the written record (IndexerFile, IndexerFileChunk, user info, ProfileChange)
+	CreatedAt   time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specify table name
+func (IndexerChangeEvent) TableName() string {
+	return "tb_indexer_change_event"
+}
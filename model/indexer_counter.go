@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Counter names maintained by the indexer. Per-chain and per-creator
+// counters are derived with ChainCounter / CreatorMetaIDCounter /
+// CreatorGlobalMetaIDCounter.
+const (
+	CounterFiles  = "files"  // Successful file PINs
+	CounterChunks = "chunks" // Successful chunk PINs
+	CounterUsers  = "users"  // Unique MetaIDs
+)
+
+// ChainCounter returns the per-chain counter name of a counter, e.g. files:chain:mvc
+func ChainCounter(name, chainName string) string {
+	return name + ":chain:" + chainName
+}
+
+// CreatorMetaIDCounter returns the files counter name of a creator MetaID
+func CreatorMetaIDCounter(metaID string) string {
+	return CounterFiles + ":meta:" + metaID
+}
+
+// CreatorGlobalMetaIDCounter returns the files counter name of a creator GlobalMetaID
+func CreatorGlobalMetaIDCounter(globalMetaID string) string {
+	return CounterFiles + ":gmeta:" + globalMetaID
+}
+
+// IndexerCounter a named counter maintained incrementally by the indexer
+// (deltas are flushed once per block)
+type IndexerCounter struct {
+	Name  string `gorm:"primaryKey;type:varchar(255)" json:"name"` // Counter name, e.g. files or chunks:chain:mvc
+	Value int64  `gorm:"not null;default:0" json:"value"`          // Current value
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (IndexerCounter) TableName() string {
+	return "tb_indexer_counter"
+}
+
+// CounterDrift a counter whose stored value differs from a recount of the indexes
+type CounterDrift struct {
+	Name   string `json:"name"`
+	Stored int64  `json:"stored"` // Maintained value
+	Actual int64  `json:"actual"` // Value recounted from the indexes
+}
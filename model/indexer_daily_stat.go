@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// DailyStatDateLayout is the UTC calendar-day key used by IndexerDailyStat
+const DailyStatDateLayout = "2006-01-02"
+
+// IndexerDailyStat per-day, per-chain counters maintained incrementally as
+// files and users are indexed. Date is the UTC day of the PIN timestamp.
+type IndexerDailyStat struct {
+	Date      string `gorm:"primaryKey;type:varchar(10)" json:"date"`       // YYYY-MM-DD (UTC)
+	ChainName string `gorm:"primaryKey;type:varchar(20)" json:"chain_name"` // btc/mvc/doge
+	NewFiles  int64  `gorm:"not null;default:0" json:"new_files"`           // New files (first PINs that became successful)
+	NewUsers  int64  `gorm:"not null;default:0" json:"new_users"`           // MetaIDs first seen on this day
+	Bytes     int64  `gorm:"not null;default:0" json:"bytes"`               // Sum of the sizes of those new files
+
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"` // Update time
+}
+
+// TableName specify table name
+func (IndexerDailyStat) TableName() string {
+	return "tb_indexer_daily_stat"
+}
+
+// DailyStatDate returns the UTC day key for a timestamp in Unix milliseconds
+func DailyStatDate(timestampMs int64) string {
+	return time.UnixMilli(timestampMs).UTC().Format(DailyStatDateLayout)
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Domain mapping target types
+const (
+	DomainTargetSite   = "site"   // Target is a site manifest PIN ID (its latest version is served)
+	DomainTargetMetaID = "metaid" // Target is a MetaID whose /file/* paths are served
+)
+
+// IndexerDomain maps a custom domain to on-chain content served by the indexer
+type IndexerDomain struct {
+	Domain     string    `gorm:"primaryKey;type:varchar(255)" json:"domain"`   // Lower-cased host name, e.g. mysite.example.com
+	TargetType string    `gorm:"type:varchar(20);not null" json:"target_type"` // site or metaid
+	Target     string    `gorm:"type:varchar(255);not null" json:"target"`     // Site manifest PIN ID or MetaID
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specify table name
+func (IndexerDomain) TableName() string {
+	return "tb_indexer_domain"
+}
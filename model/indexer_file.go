@@ -27,17 +27,21 @@ type IndexerFile struct {
 	FileName         string `gorm:"type:varchar(255)" json:"file_name"`                  // File name (extracted from path)
 	FileSize         int64  `json:"file_size"`                                           // File size
 	FileMd5          string `gorm:"type:varchar(64)" json:"file_md5"`                    // File MD5
-	FileHash         string `gorm:"type:varchar(64)" json:"file_hash"`                   // File Hash SHA256
+	FileHash         string `gorm:"index;type:varchar(64)" json:"file_hash"`             // File Hash SHA256
 	IsGzipCompressed bool   `gorm:"type:tinyint(1);default:0" json:"is_gzip_compressed"` // Whether the original content was gzip compressed
 
 	// Storage related fields
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`  // local/oss
-	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"` // Storage path
+	StorageType    string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss/chunks
+	StoragePath    string `gorm:"type:varchar(500)" json:"storage_path"`              // Storage path
+	StorageLayout  int    `gorm:"type:int;default:0" json:"storage_layout,omitempty"` // Path layout version (storage.Layout), 0 = 1
+	StorageReceipt string `gorm:"type:varchar(255)" json:"storage_receipt,omitempty"` // Receipt of the external storage gateway (storage.type gateway)
 
 	// Blockchain related fields
 	ChainName           string `gorm:"type:varchar(20);not null" json:"chain_name"`    // btc/mvc
 	BlockHeight         int64  `gorm:"index" json:"block_height"`                      // Block height
-	Timestamp           int64  `gorm:"index" json:"timestamp"`                         // Timestamp (seconds since epoch)
+	Timestamp           int64  `gorm:"index" json:"timestamp"`                         // Timestamp (milliseconds since epoch): block time, or first-seen time for mempool PINs
+	FirstSeenAt         int64  `json:"first_seen_at"`                                  // First time the PIN was observed (ms): mempool arrival via ZMQ, else block time
+	ConfirmedAt         int64  `json:"confirmed_at"`                                   // Time of the confirming block (ms), 0 while unconfirmed
 	CreatorMetaId       string `gorm:"index;type:varchar(64)" json:"creator_meta_id"`  // Creator MetaID (SHA256 hash)
 	CreatorAddress      string `gorm:"index;type:varchar(100)" json:"creator_address"` // Creator address
 	CreatorGlobalMetaId string `gorm:"-" json:"creator_global_meta_id,omitempty"`      // GlobalMetaID (IDAddress), for extension index; set by indexer, not stored in MySQL
@@ -45,7 +49,9 @@ type IndexerFile struct {
 	OwnerMetaId         string `gorm:"index;type:varchar(64)" json:"owner_meta_id"`    // Owner MetaID (SHA256 hash)
 
 	// Status fields
-	Status Status `gorm:"type:varchar(20);default:'success'" json:"status"` // success/failed
+	Status            Status `gorm:"type:varchar(20);default:'success'" json:"status"`              // success/failed/rejected/pending_storage
+	StatusReason      string `gorm:"type:varchar(255)" json:"status_reason,omitempty"`              // Why the content was rejected
+	ResolutionPending bool   `gorm:"type:tinyint(1);default:0" json:"resolution_pending,omitempty"` // Creator fields hold the fallback address until the creator input lookup is retried (PendingCreatorResolution)
 
 	// Timestamps
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`    // Creation time
@@ -53,6 +59,11 @@ type IndexerFile struct {
 	State     int64     `gorm:"type:int(11);default:0" json:"state"` // State 0:EXIST,2:DELETED
 }
 
+// StorageTypeChunks StorageType of a multi-chunk file too large to merge
+// (indexer.max_merged_file_mb): it has no StoragePath, its content is read
+// from its chunks
+const StorageTypeChunks = "chunks"
+
 // TableName specify table name
 func (IndexerFile) TableName() string {
 	return "tb_indexer_file"
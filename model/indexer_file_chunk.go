@@ -18,20 +18,24 @@ type IndexerFileChunk struct {
 	ChunkIndex       int    `gorm:"type:int" json:"chunk_index"`                         // Chunk index (0-based)
 	ChunkSize        int64  `json:"chunk_size"`                                          // Chunk size
 	ChunkMd5         string `gorm:"type:varchar(64)" json:"chunk_md5"`                   // Chunk MD5
+	ChunkSha256      string `gorm:"index;type:varchar(64)" json:"chunk_sha256"`          // Chunk SHA256 (matches index chunkList sha256)
 	ParentFirstPinID string `gorm:"index;type:varchar(255)" json:"parent_first_pin_id"`  // Parent file First PIN ID
 	ParentPinID      string `gorm:"index;type:varchar(255)" json:"parent_pin_id"`        // Parent file PIN ID
 	IsGzipCompressed bool   `gorm:"type:tinyint(1);default:0" json:"is_gzip_compressed"` // Whether the original content was gzip compressed
 
 	// Storage related fields
-	StorageType string `gorm:"type:varchar(20)" json:"storage_type"`  // local/oss
-	StoragePath string `gorm:"type:varchar(500)" json:"storage_path"` // Storage path
+	StorageType    string `gorm:"type:varchar(20)" json:"storage_type"`               // local/oss
+	StoragePath    string `gorm:"type:varchar(500)" json:"storage_path"`              // Storage path
+	StorageLayout  int    `gorm:"type:int;default:0" json:"storage_layout,omitempty"` // Path layout version (storage.Layout), 0 = 1
+	StorageReceipt string `gorm:"type:varchar(255)" json:"storage_receipt,omitempty"` // Receipt of the external storage gateway (storage.type gateway)
 
 	// Blockchain related fields
 	ChainName   string `gorm:"type:varchar(20);not null" json:"chain_name"` // btc/mvc
 	BlockHeight int64  `gorm:"index" json:"block_height"`                   // Block height
 
 	// Status fields
-	Status Status `gorm:"type:varchar(20);default:'success'" json:"status"` // success/failed
+	Status       Status `gorm:"type:varchar(20);default:'success'" json:"status"` // success/failed/rejected/pending_storage
+	StatusReason string `gorm:"type:varchar(255)" json:"status_reason,omitempty"` // Why the content was rejected
 
 	// Timestamps
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`    // Creation time
@@ -0,0 +1,40 @@
+package model
+
+import "strings"
+
+// IndexerFileFilter optional filters for creator-scoped file listings; empty fields match everything
+type IndexerFileFilter struct {
+	FileType          string // Exact file type: image/video/audio/document/other
+	ContentTypePrefix string // Content type prefix, e.g. "image/" or "image/png"
+	PathPrefix        string // Normalized MetaID path prefix matched on whole segments, e.g. "/file/photos"
+	ChainName         string // btc/mvc/doge
+}
+
+// IsEmpty reports whether no filter is set
+func (f IndexerFileFilter) IsEmpty() bool {
+	return f.FileType == "" && f.ContentTypePrefix == "" && f.PathPrefix == "" && f.ChainName == ""
+}
+
+// Matches reports whether file passes every set filter (case-insensitive)
+func (f IndexerFileFilter) Matches(file *IndexerFile) bool {
+	if file == nil {
+		return false
+	}
+	if f.FileType != "" && !strings.EqualFold(file.FileType, f.FileType) {
+		return false
+	}
+	if f.ChainName != "" && !strings.EqualFold(file.ChainName, f.ChainName) {
+		return false
+	}
+	if f.ContentTypePrefix != "" && !strings.HasPrefix(strings.ToLower(file.ContentType), strings.ToLower(f.ContentTypePrefix)) {
+		return false
+	}
+	if f.PathPrefix != "" {
+		path := strings.ToLower(file.Path)
+		prefix := strings.ToLower(strings.TrimSuffix(f.PathPrefix, "/"))
+		if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
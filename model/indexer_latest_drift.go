@@ -0,0 +1,10 @@
+package model
+
+// LatestDrift a latest_* entry that is not the latest version in the history
+// it is derived from
+type LatestDrift struct {
+	Collection string `json:"collection"` // e.g. latest_file_info, latest_user_name_info_by_global_meta_id
+	Key        string `json:"key"`        // FirstPinID, MetaID/address or GlobalMetaID
+	Stored     string `json:"stored"`     // PIN ID of the stored entry; empty when it is missing
+	Actual     string `json:"actual"`     // PIN ID of the latest version; empty when no version is left (the entry is kept)
+}
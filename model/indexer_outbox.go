@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxChannel where an outbox entry is published
+type OutboxChannel string
+
+const (
+	OutboxWebhook   OutboxChannel = "webhook"   // POSTed to the webhook URL of a watch
+	OutboxBroadcast OutboxChannel = "broadcast" // Pushed to the live (WebSocket) subscribers of the target
+)
+
+// IndexerOutboxEvent a pending publication of a watch event. Entries are
+// written in the same write as the event itself, so a recorded event is never
+// left unpublished; the outbox dispatcher deletes an entry once it is
+// delivered (at least once) or has run out of attempts.
+type IndexerOutboxEvent struct {
+	ID            int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Channel       OutboxChannel   `gorm:"type:varchar(20);not null" json:"channel"`
+	WatchID       int64           `json:"watch_id,omitempty"`                       // Webhook: the watch that matched
+	Label         string          `gorm:"type:varchar(255)" json:"label,omitempty"` // Webhook: the watch's label
+	URL           string          `gorm:"type:varchar(1024)" json:"url,omitempty"`  // Webhook: target URL
+	Event         json.RawMessage `gorm:"type:mediumtext" json:"event"`             // JSON of the IndexerWatchEvent, set when written
+	Attempts      int             `json:"attempts"`                                 // Failed deliveries so far
+	LastError     string          `gorm:"type:varchar(1024)" json:"last_error,omitempty"`
+	NextAttemptAt int64           `json:"next_attempt_at"` // Unix seconds; 0 = due now
+	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specify table name
+func (IndexerOutboxEvent) TableName() string {
+	return "tb_indexer_outbox_event"
+}
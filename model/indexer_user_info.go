@@ -4,20 +4,24 @@ import "encoding/json"
 
 // IndexerUserInfo 用户信息模型
 type IndexerUserInfo struct {
-	GlobalMetaId       string          `json:"globalMetaId"`       // 全局 MetaID
-	MetaId             string          `json:"metaId"`             // 用户 MetaID
-	Address            string          `json:"address"`            // 用户地址
-	Name               string          `json:"name"`               // 用户名称
-	NamePinId          string          `json:"namePinId"`          // 用户名称 PIN ID
-	Avatar             string          `json:"avatar"`             // 头像路径
-	AvatarPinId        string          `json:"avatarPinId"`        // 头像 PIN ID
-	Bio                json.RawMessage `json:"bio"`                // 用户简介（JSON）
-	BioPinId           string          `json:"bioPinId"`           // 用户简介 PIN ID
-	ChatPublicKey      string          `json:"chatPublicKey"`      // 聊天公钥
-	ChatPublicKeyPinId string          `json:"chatPublicKeyPinId"` // 聊天公钥 PIN ID
-	ChainName          string          `json:"chainName"`          // 链名称
-	BlockHeight        int64           `json:"blockHeight"`        // 区块高度
-	Timestamp          int64           `json:"timestamp"`          // 时间戳
+	GlobalMetaId         string          `json:"globalMetaId"`                   // 全局 MetaID
+	MetaId               string          `json:"metaId"`                         // 用户 MetaID
+	Address              string          `json:"address"`                        // 用户地址
+	IdAddress            string          `json:"idAddress,omitempty"`            // 地址的 ID 地址形式（仅在响应中填充，indexer.render_id_address）
+	Name                 string          `json:"name"`                           // 用户名称
+	NamePinId            string          `json:"namePinId"`                      // 用户名称 PIN ID
+	Avatar               string          `json:"avatar"`                         // 头像路径
+	AvatarPinId          string          `json:"avatarPinId"`                    // 头像 PIN ID
+	AvatarInvalid        bool            `json:"avatarInvalid,omitempty"`        // 最新头像无效（客户端应使用默认头像）
+	Bio                  json.RawMessage `json:"bio"`                            // 用户简介（JSON）
+	BioPinId             string          `json:"bioPinId"`                       // 用户简介 PIN ID
+	ChatPublicKey        string          `json:"chatPublicKey"`                  // 聊天公钥
+	ChatPublicKeyPinId   string          `json:"chatPublicKeyPinId"`             // 聊天公钥 PIN ID
+	ChatPublicKeyType    string          `json:"chatPublicKeyType,omitempty"`    // 聊天公钥类型：secp256k1 / x25519
+	ChatPublicKeyInvalid bool            `json:"chatPublicKeyInvalid,omitempty"` // 最新聊天公钥无效（不返回公钥）
+	ChainName            string          `json:"chainName"`                      // 链名称
+	BlockHeight          int64           `json:"blockHeight"`                    // 区块高度
+	Timestamp            int64           `json:"timestamp"`                      // 时间戳
 }
 
 // UserNameInfo 用户名称信息
@@ -48,6 +52,12 @@ type UserAvatarInfo struct {
 	FileHash      string `json:"fileHash"`      // File Hash SHA256
 	FileExtension string `json:"fileExtension"` // File extension, e.g. .jpg, .png, .mp4, .mp3, .doc, .pdf, etc.
 	FileType      string `json:"fileType"`      // File type (image/video/audio/document/other)
+
+	Width         int    `json:"width,omitempty"`         // Image width in pixels (after downscaling)
+	Height        int    `json:"height,omitempty"`        // Image height in pixels (after downscaling)
+	Downscaled    bool   `json:"downscaled,omitempty"`    // Stored content was downscaled from the on-chain image
+	Invalid       bool   `json:"invalid,omitempty"`       // Not a usable image; content is not served and clients should fall back
+	InvalidReason string `json:"invalidReason,omitempty"` // Why the avatar was marked invalid
 }
 
 // UserBioInfo 用户简介信息
@@ -70,6 +80,11 @@ type UserChatPublicKeyInfo struct {
 	ChainName     string `json:"chainName"`     // 链名称
 	BlockHeight   int64  `json:"blockHeight"`   // 区块高度
 	Timestamp     int64  `json:"timestamp"`     // 时间戳
+
+	KeyType       string `json:"keyType,omitempty"`       // 公钥类型：secp256k1 / x25519
+	KeyEncoding   string `json:"keyEncoding,omitempty"`   // 公钥编码：hex / base64
+	Invalid       bool   `json:"invalid,omitempty"`       // 公钥格式无效，E2E 客户端不应使用
+	InvalidReason string `json:"invalidReason,omitempty"` // 无效原因
 }
 
 // UserInfoHistory 用户信息历史记录（包含三种历史记录）
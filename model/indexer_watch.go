@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// IndexerWatch a watchlist entry: notify when the indexer sees a PIN created by Target
+type IndexerWatch struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Target     string    `gorm:"index;type:varchar(255);not null" json:"target"` // Address, MetaID or GlobalMetaID
+	WebhookURL string    `gorm:"type:varchar(1024)" json:"webhook_url"`          // Optional; events are POSTed here as JSON
+	Label      string    `gorm:"type:varchar(255)" json:"label"`                 // Free-form client label
+	Client     string    `gorm:"index;type:varchar(64)" json:"client,omitempty"` // IP of the client that created it (per-client limit)
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specify table name
+func (IndexerWatch) TableName() string {
+	return "tb_indexer_watch"
+}
+
+// Watch event kinds
+const (
+	WatchEventPin            = "pin"             // Any PIN of the watched identity
+	WatchEventProfileChanged = "profile_changed" // Name, avatar or chat public key changed (Profile is set)
+)
+
+// IndexerWatchEvent a PIN from a watched identity, recorded once when first seen
+// (mempool or block) and again when confirmed in a block. Profile changes are
+// recorded once more as a profile_changed event carrying the diff.
+type IndexerWatchEvent struct {
+	ID                  int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Event               string         `gorm:"type:varchar(20)" json:"event,omitempty"`                                 // pin (empty on events recorded before kinds) / profile_changed
+	Target              string         `gorm:"index:idx_target_id,priority:1;type:varchar(255);not null" json:"target"` // Watched identity that matched
+	PinID               string         `gorm:"type:varchar(255);not null" json:"pin_id"`
+	Path                string         `gorm:"type:varchar(1024)" json:"path"`
+	Operation           string         `gorm:"type:varchar(20)" json:"operation"`
+	ContentType         string         `gorm:"type:varchar(255)" json:"content_type"`
+	ChainName           string         `gorm:"type:varchar(20)" json:"chain_name"`
+	BlockHeight         int64          `json:"block_height"` // 0 while in mempool
+	Confirmed           bool           `json:"confirmed"`
+	Timestamp           int64          `json:"timestamp"` // PIN timestamp (ms)
+	CreatorAddress      string         `gorm:"type:varchar(100)" json:"creator_address"`
+	CreatorMetaId       string         `gorm:"type:varchar(64)" json:"creator_meta_id"`
+	CreatorGlobalMetaId string         `gorm:"type:varchar(255)" json:"creator_global_meta_id"`
+	Profile             *ProfileChange `gorm:"serializer:json;type:text" json:"profile,omitempty"` // profile_changed events
+	CreatedAt           time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specify table name
+func (IndexerWatchEvent) TableName() string {
+	return "tb_indexer_watch_event"
+}
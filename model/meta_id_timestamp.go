@@ -2,6 +2,7 @@ package model
 
 // MetaIdTimestamp MetaID 和时间戳的映射
 type MetaIdTimestamp struct {
-	MetaId    string `json:"metaId"`    // MetaID
-	Timestamp int64  `json:"timestamp"` // 时间戳（记录最早的时间戳）
+	MetaId    string `json:"metaId"`              // MetaID
+	ChainName string `json:"chainName,omitempty"` // 最早时间戳所在的链
+	Timestamp int64  `json:"timestamp"`           // 时间戳（记录最早的时间戳）
 }
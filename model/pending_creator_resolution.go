@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// PendingCreatorResolution a file PIN whose creator address could not be
+// looked up while indexing (e.g. a node hiccup fetching the creator input
+// transaction). The file is committed with the fallback address and
+// ResolutionPending set; IndexerService's creator retrier looks the address
+// up again, corrects the file's creator fields and indexes, and deletes this
+// record.
+//
+// Keyed by PinID. Like PendingStorageWrite it lives in PebbleDB.
+type PendingCreatorResolution struct {
+	PinID                     string    `gorm:"uniqueIndex;type:varchar(255)" json:"pin_id"` // file pin ID (key)
+	ChainName                 string    `gorm:"index;type:varchar(20)" json:"chain_name"`    // btc/mvc/doge
+	CreatorInputLocation      string    `json:"creator_input_location"`                      // txid:vout (MVC)
+	CreatorInputTxVinLocation string    `json:"creator_input_tx_vin_location"`               // txid:vin (BTC/DOGE)
+	FallbackAddress           string    `json:"fallback_address"`                            // address the file was indexed with
+	Attempts                  int       `json:"attempts"`                                    // failed lookups, including the first
+	LastError                 string    `json:"last_error,omitempty"`                        // last lookup failure
+	NextRetryAt               int64     `json:"next_retry_at"`                               // unix seconds of the next attempt
+	CreatedAt                 time.Time `json:"created_at"`
+}
+
+// TableName specify table name (MySQL; indexer uses Pebble in production).
+func (PendingCreatorResolution) TableName() string {
+	return "tb_pending_creator_resolution"
+}
@@ -14,17 +14,28 @@ import "time"
 // MetaData holds the marshalled indexer.MetaIDData (creator/owner/etc.) and
 // IndexJSON the marshalled metafile index (sha256/fileSize/chunkList), both as
 // raw JSON strings so package model does not import package indexer.
+//
+// MismatchChunkPinID/MismatchChunkIndex record the chunk that failed sha256
+// verification against the index chunkList on the last merge attempt (empty
+// when the merge is only waiting for missing chunks). MismatchAttempts counts
+// those failed attempts; once it reaches the retry limit Status is set to
+// StatusRejected and the record is kept for inspection but no longer retried.
 type PendingIndexFile struct {
-	PinID       string `gorm:"uniqueIndex;type:varchar(255)" json:"pin_id"` // index pin ID (key)
-	FirstPinID  string `gorm:"index;type:varchar(255)" json:"first_pin_id"`  // first pin ID for the file
-	FirstPath   string `json:"first_path"`                                   // first pin path
-	TxID        string `gorm:"index;type:varchar(64)" json:"tx_id"`          // transaction id of the index pin
-	ChainName   string `gorm:"index;type:varchar(20)" json:"chain_name"`     // btc/mvc/doge
-	BlockHeight int64  `gorm:"index" json:"block_height"`                    // block the index pin landed in
-	Timestamp   int64  `json:"timestamp"`                                    // index pin timestamp
-	MetaData    string `json:"meta_data"`                                    // marshalled indexer.MetaIDData
-	IndexJSON   string `json:"index_json"`                                   // marshalled metafile index (chunkList)
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	PinID              string    `gorm:"uniqueIndex;type:varchar(255)" json:"pin_id"` // index pin ID (key)
+	FirstPinID         string    `gorm:"index;type:varchar(255)" json:"first_pin_id"` // first pin ID for the file
+	FirstPath          string    `json:"first_path"`                                  // first pin path
+	TxID               string    `gorm:"index;type:varchar(64)" json:"tx_id"`         // transaction id of the index pin
+	ChainName          string    `gorm:"index;type:varchar(20)" json:"chain_name"`    // btc/mvc/doge
+	BlockHeight        int64     `gorm:"index" json:"block_height"`                   // block the index pin landed in
+	Timestamp          int64     `json:"timestamp"`                                   // index pin timestamp
+	MetaData           string    `json:"meta_data"`                                   // marshalled indexer.MetaIDData
+	IndexJSON          string    `json:"index_json"`                                  // marshalled metafile index (chunkList)
+	MismatchChunkPinID string    `json:"mismatch_chunk_pin_id,omitempty"`             // chunk that failed hash verification
+	MismatchChunkIndex int       `json:"mismatch_chunk_index,omitempty"`              // its position in chunkList
+	MismatchAttempts   int       `json:"mismatch_attempts,omitempty"`                 // merge attempts refused by a hash mismatch
+	Status             Status    `json:"status,omitempty"`                            // empty while retried, rejected once given up
+	LastError          string    `json:"last_error,omitempty"`                        // last merge failure reason
+	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
 
 // TableName specify table name (MySQL; indexer uses Pebble in production).
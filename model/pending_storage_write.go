@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// PendingStorageWrite a blob the indexer could not write to the storage
+// backend (e.g. an OSS outage or a full disk). The PIN's metadata is already
+// committed with StatusPendingStorage; the content is kept here until
+// IndexerService's storage retrier writes it to StoragePath, flips the file
+// or chunk record to StatusSuccess and deletes this record.
+//
+// Keyed by StoragePath. Like PendingIndexFile it lives in PebbleDB, so queued
+// writes survive restarts independently of the failing storage backend.
+type PendingStorageWrite struct {
+	StoragePath string    `gorm:"uniqueIndex;type:varchar(255)" json:"storage_path"` // storage key (key)
+	PinID       string    `gorm:"index;type:varchar(255)" json:"pin_id"`             // file, chunk or index pin ID
+	ChainName   string    `gorm:"index;type:varchar(20)" json:"chain_name"`          // btc/mvc/doge
+	Kind        string    `gorm:"type:varchar(10)" json:"kind"`                      // file/chunk: which record to update
+	Size        int64     `json:"size"`                                              // len(Content)
+	Sha256      string    `json:"sha256"`                                            // sha256 of Content
+	Content     []byte    `json:"content,omitempty"`                                 // the blob to write
+	Attempts    int       `json:"attempts"`                                          // failed writes, including the first
+	LastError   string    `json:"last_error,omitempty"`                              // last write failure
+	NextRetryAt int64     `json:"next_retry_at"`                                     // unix seconds of the next attempt
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Kinds of PendingStorageWrite
+const (
+	PendingStorageKindFile  = "file"
+	PendingStorageKindChunk = "chunk"
+)
+
+// TableName specify table name (MySQL; indexer uses Pebble in production).
+func (PendingStorageWrite) TableName() string {
+	return "tb_pending_storage_write"
+}
@@ -0,0 +1,27 @@
+package model
+
+// ProfileField user profile field a ProfileChange is about
+type ProfileField string
+
+const (
+	ProfileFieldName          ProfileField = "name"            // /info/name
+	ProfileFieldAvatar        ProfileField = "avatar"          // /info/avatar (values are avatar URLs)
+	ProfileFieldChatPublicKey ProfileField = "chat_public_key" // /info/chatpubkey
+)
+
+// ProfileChange a change of a user's latest name, avatar or chat public key,
+// with the values before and after the PIN that made it. Previous and
+// PreviousPinId are empty when the user had no value yet.
+type ProfileChange struct {
+	Field         ProfileField `json:"field"`
+	Previous      string       `json:"previous"`
+	Current       string       `json:"current"`
+	PreviousPinId string       `json:"previous_pin_id,omitempty"`
+	PinId         string       `json:"pin_id"` // PIN that made the change
+	MetaId        string       `json:"meta_id"`
+	Address       string       `json:"address"`
+	GlobalMetaId  string       `json:"global_meta_id,omitempty"`
+	ChainName     string       `json:"chain_name"`
+	BlockHeight   int64        `json:"block_height"` // 0 while in mempool
+	Timestamp     int64        `json:"timestamp"`    // PIN timestamp (ms)
+}
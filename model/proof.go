@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Proof is a proof-of-existence timestamp: a SHA256 hash inscribed on its own
+// under /proof/sha256 (no file content). The block fields cache where the
+// transaction was found confirmed and its merkle branch; they are recomputed
+// when the node reports the transaction in another block.
+type Proof struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Hash    string `gorm:"index;type:varchar(64)" json:"hash"`         // Notarized SHA256 (lower-case hex)
+	TxID    string `gorm:"index;type:varchar(64)" json:"tx_id"`        // Inscription transaction ID
+	PinId   string `gorm:"uniqueIndex;type:varchar(80)" json:"pin_id"` // Pin ID
+	MetaId  string `gorm:"type:varchar(255)" json:"meta_id"`           // MetaID of the notarizer
+	Address string `gorm:"index;type:varchar(100)" json:"address"`     // Address of the notarizer
+
+	BlockHash   string `gorm:"type:varchar(64)" json:"block_hash"` // Block the transaction was confirmed in
+	BlockHeight int64  `gorm:"type:bigint;default:0" json:"block_height"`
+	MerkleRoot  string `gorm:"type:varchar(64)" json:"merkle_root"`
+	TxIndex     int    `gorm:"type:int;default:0" json:"tx_index"` // Position of the transaction in the block
+	MerklePath  string `gorm:"type:longtext" json:"-"`             // Merkle branch (JSON array of hashes, leaf level first)
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (Proof) TableName() string {
+	return "tb_proof"
+}
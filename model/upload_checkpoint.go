@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// UploadCheckpoint records the broadcast progress of a synchronous chunked
+// upload (ChunkedUpload with isBroadcast), so an upload whose process died
+// mid-broadcast can be resumed after a restart. Async tasks keep the same
+// artifacts on FileUploaderTask instead.
+//
+// It is saved with every transaction hex before the first broadcast; Stage and
+// ProcessedChunks move forward after each broadcast. Status stays pending
+// until the index transaction is broadcast (success) or a broadcast fails
+// (failed); the transaction hexes are cleared once it leaves pending.
+type UploadCheckpoint struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	FileId   string `gorm:"uniqueIndex;type:varchar(255)" json:"file_id"` // File ID (metaid_filehash)
+	FileHash string `gorm:"type:varchar(255)" json:"file_hash"`           // File SHA256 hash (chunk rows are keyed by it)
+	Chain    string `gorm:"type:varchar(20);default:'mvc'" json:"chain"`  // Blockchain
+
+	Stage           TaskStage `gorm:"type:varchar(50);default:'prepared'" json:"stage"` // Last stage reached (TaskStage*)
+	Status          Status    `gorm:"index;type:varchar(20);default:'pending'" json:"status"`
+	TotalChunks     int       `gorm:"type:int;default:0" json:"total_chunks"`     // Chunk transaction count
+	ProcessedChunks int       `gorm:"type:int;default:0" json:"processed_chunks"` // Chunk transactions broadcast so far
+	Attempts        int       `gorm:"type:int;default:0" json:"attempts"`         // Recovery runs that picked it up
+
+	// Transactions, in broadcast order
+	MergeTxHex     string `gorm:"type:text" json:"-"`                  // Optional merge tx
+	ChunkFundingTx string `gorm:"type:text" json:"-"`                  // Chunk funding tx
+	ChunkTxHexes   string `gorm:"type:longtext" json:"-"`              // Chunk txs (JSON array)
+	ChunkTxIds     string `gorm:"type:text" json:"chunk_tx_ids"`       // Chunk tx IDs (JSON array)
+	IndexTxHex     string `gorm:"type:text" json:"-"`                  // Index tx
+	IndexTxId      string `gorm:"type:varchar(64)" json:"index_tx_id"` // Index tx ID
+
+	ErrorMessage string `gorm:"type:text" json:"error_message"` // Why the broadcast failed
+
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime;index" json:"updated_at"`
+	FinishedAt *time.Time `gorm:"type:timestamp" json:"finished_at"`
+}
+
+// TableName sets custom table name
+func (UploadCheckpoint) TableName() string {
+	return "tb_upload_checkpoint"
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// State of an idempotency key
+const (
+	IdempotencyProcessing = "processing" // The first request is still running
+	IdempotencyCompleted  = "completed"  // Response stored; retries get it back
+)
+
+// UploadIdempotency an Idempotency-Key sent to an upload route: the request
+// it was first used with and, once that request finished, its response, so
+// a retried request is answered without uploading or broadcasting again.
+// Keys are dropped after uploader.idempotency.ttl_hours.
+type UploadIdempotency struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Scope       string `gorm:"uniqueIndex:uk_scope_key;type:varchar(100)" json:"scope"`               // Route (method and path template) the key was used on
+	Key         string `gorm:"uniqueIndex:uk_scope_key;column:idem_key;type:varchar(255)" json:"key"` // Idempotency-Key header
+	Fingerprint string `gorm:"type:varchar(64)" json:"fingerprint"`                                   // SHA256 of the first request; a retry must match it
+	Status      string `gorm:"index;type:varchar(20)" json:"status"`                                  // processing/completed
+	Response    []byte `gorm:"type:longblob" json:"-"`                                                // Response body of the first request
+
+	ExpiresAt time.Time `gorm:"index;type:timestamp" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (UploadIdempotency) TableName() string {
+	return "tb_upload_idempotency"
+}
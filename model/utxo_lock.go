@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// UtxoLock an output reserved by an upload in flight: the inputs of the
+// transactions it built, from the client's pre-transactions or an assistent
+// address. Other uploads may not spend the output until its owner releases
+// the lock or the lock expires.
+type UtxoLock struct {
+	ID int64 `gorm:"primaryKey;autoIncrement" json:"id"`
+
+	Chain string `gorm:"uniqueIndex:idx_utxo_lock_outpoint;type:varchar(20)" json:"chain"` // mvc/doge
+	TxId  string `gorm:"uniqueIndex:idx_utxo_lock_outpoint;type:varchar(64)" json:"tx_id"` // Transaction creating the output
+	Vout  uint32 `gorm:"uniqueIndex:idx_utxo_lock_outpoint" json:"vout"`                   // Output index
+
+	Owner     string    `gorm:"index;type:varchar(128)" json:"owner"` // task:{taskId}, upload:{id} or sweep:{rotationId}
+	ExpiresAt time.Time `gorm:"index" json:"expires_at"`              // The output is free again after this
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName sets custom table name
+func (UtxoLock) TableName() string {
+	return "tb_utxo_lock"
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"meta-file-system/conf"
+	"meta-file-system/notify"
 )
 
 // Resilient broadcast knobs.
@@ -35,6 +36,8 @@ var (
 //  3. The returned error is classified (ErrUpstreamNodeUnreachable /
 //     ErrBroadcastTimeout / raw RPC error) so handlers can map it to a
 //     structured code.
+//  4. When no node could be reached at all a broadcast_unavailable alert is
+//     sent (notify; rate limited).
 func BroadcastTxResilient(chain, txHex string) (string, error) {
 	// Primary: retry transient failures only.
 	var lastErr error
@@ -73,6 +76,9 @@ func BroadcastTxResilient(chain, txHex string) (string, error) {
 	if lastErr == nil {
 		lastErr = fmt.Errorf("broadcast failed with no error")
 	}
+	if isTransientBroadcastError(lastErr) {
+		notify.Alertf(notify.EventBroadcastUnavailable, "%s: no broadcast node reachable (primary retried, fallback tried if configured): %v", chain, lastErr)
+	}
 	return "", lastErr
 }
 
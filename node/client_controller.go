@@ -123,6 +123,21 @@ func (c *ClientController) BroadcastTxBatchWithOptions(net string, options ...Tx
 	return NewSendRawTransactionsResult(result), nil
 }
 
+// TestMempoolAccept asks the node whether it would accept the transactions
+// into its mempool, without broadcasting them (testmempoolaccept)
+func (c *ClientController) TestMempoolAccept(net string, txHexStrs ...string) ([]MempoolAcceptResult, error) {
+	request := []interface{}{
+		txHexStrs,
+	}
+
+	result, err := c.ClientMap[net].Call("testmempoolaccept", request)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMempoolAcceptResults(result), nil
+}
+
 func (c *ClientController) GetBlockhash(net string, height uint64) (string, error) {
 
 	request := []interface{}{
@@ -265,3 +280,24 @@ func (c *ClientController) GetMempool(net string) ([]string, error) {
 
 	return txIds, nil
 }
+
+// EstimateFeePerKB returns the node's fee estimate in coins per kilobyte for
+// confirmation within blocks (estimatesmartfee, falling back to estimatefee
+// on nodes without it).
+func (c *ClientController) EstimateFeePerKB(net string, blocks int) (float64, error) {
+	result, err := c.ClientMap[net].Call("estimatesmartfee", []interface{}{blocks})
+	if err == nil {
+		if rate := result.Get("feerate").Float(); rate > 0 {
+			return rate, nil
+		}
+	}
+
+	result, err = c.ClientMap[net].Call("estimatefee", []interface{}{blocks})
+	if err != nil {
+		return 0, err
+	}
+	if rate := result.Float(); rate > 0 {
+		return rate, nil
+	}
+	return 0, errors.New("node has no fee estimate")
+}
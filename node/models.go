@@ -343,6 +343,26 @@ func newTxVoutByCore(json *gjson.Result) *Vout {
 	return &obj
 }
 
+// MempoolAcceptResult one transaction of a testmempoolaccept RPC call return result
+type MempoolAcceptResult struct {
+	TxID         string `json:"txid"`          // Transaction ID
+	Allowed      bool   `json:"allowed"`       // Whether the node would accept the transaction
+	RejectReason string `json:"reject-reason"` // Why it would not (e.g. min relay fee not met, dust, tx-size)
+}
+
+// NewMempoolAcceptResults parses a testmempoolaccept result
+func NewMempoolAcceptResults(json *gjson.Result) []MempoolAcceptResult {
+	var results []MempoolAcceptResult
+	for _, r := range json.Array() {
+		results = append(results, MempoolAcceptResult{
+			TxID:         r.Get("txid").String(),
+			Allowed:      r.Get("allowed").Bool(),
+			RejectReason: r.Get("reject-reason").String(),
+		})
+	}
+	return results
+}
+
 // SendRawTransactionsResult represents sendrawtransactions RPC call return result
 type SendRawTransactionsResult struct {
 	Known       []string                 `json:"known"`       // Known transaction IDs
@@ -258,3 +258,9 @@ func IsDuplicateBroadcastError(err error) bool {
 	return strings.Contains(msg, "already") || strings.Contains(msg, "known") ||
 		strings.Contains(msg, "exists") || strings.Contains(msg, "spent")
 }
+
+// IsMethodNotFoundError reports whether err is the JSON-RPC "method not
+// found" error (-32601) of a node that lacks the called RPC
+func IsMethodNotFoundError(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "[-32601]")
+}
@@ -31,7 +31,50 @@ func BroadcastTxBatchWithOptions(chain string, options ...TxOption) (*SendRawTra
 	return client.BroadcastTxBatchWithOptions(chain, options...)
 }
 
+// TestMempoolAccept checks transactions against the node's mempool policy
+// without broadcasting them. Nodes without testmempoolaccept fail with an
+// error IsMethodNotFoundError recognizes.
+func TestMempoolAccept(chain string, txHexStrs ...string) ([]MempoolAcceptResult, error) {
+	client := NewClientController(chain)
+	return client.TestMempoolAccept(chain, txHexStrs...)
+}
+
+// GetBlock returns a block with its transaction IDs (getblock verbosity 1).
+func GetBlock(chain, hash string) (*Block, error) {
+	client := NewClientController(chain)
+	return client.GetBlock(chain, hash, 1)
+}
+
 func GetMempool(chain string) ([]string, error) {
 	client := NewClientController(chain)
 	return client.GetMempool(chain)
 }
+
+// EstimateFeePerKB returns the node's fee estimate in coins per kilobyte for
+// confirmation within blocks.
+func EstimateFeePerKB(chain string, blocks int) (float64, error) {
+	client := NewClientController(chain)
+	return client.EstimateFeePerKB(chain, blocks)
+}
+
+// SendToAddress pays amount satoshis from the wallet of the node at url to
+// address (sendtoaddress). Used by the testnet faucet and delegated uploads.
+func SendToAddress(url, user, pass, address string, amount int64) (string, error) {
+	cli := NewClientNode(url, BasicAuth(user, pass), false)
+	result, err := cli.Call("sendtoaddress", []interface{}{address, float64(amount) / 1e8})
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// GetRawTransactionFrom returns the raw hex of txId from the node at url,
+// e.g. a payment its wallet just sent
+func GetRawTransactionFrom(url, user, pass, txId string) (string, error) {
+	cli := NewClientNode(url, BasicAuth(user, pass), false)
+	result, err := cli.Call("getrawtransaction", []interface{}{txId, 0})
+	if err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+// EmailNotifier sends alerts by SMTP (STARTTLS when the server offers it)
+type EmailNotifier struct {
+	cfg conf.NotifyEmailConfig
+}
+
+// NewEmailNotifier creates an SMTP email notifier
+func NewEmailNotifier(cfg conf.NotifyEmailConfig) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+// Name channel name
+func (n *EmailNotifier) Name() string {
+	return ChannelEmail
+}
+
+// Send mails the alert to every recipient. ctx is only checked before
+// sending; net/smtp does not support cancellation.
+func (n *EmailNotifier) Send(ctx context.Context, alert Alert) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(n.cfg.To) == 0 {
+		return fmt.Errorf("no recipients configured")
+	}
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)
+	}
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	return smtp.SendMail(addr, auth, n.cfg.From, n.cfg.To, n.message(alert))
+}
+
+// message builds the RFC 5322 message of alert
+func (n *EmailNotifier) message(alert Alert) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + n.cfg.From + "\r\n")
+	b.WriteString("To: " + strings.Join(n.cfg.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + alert.Subject() + "\r\n")
+	b.WriteString("Date: " + alert.Time.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(alert.Text(), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,230 @@
+// Package notify delivers operational alerts (scanner stalled, broadcast
+// nodes unreachable, storage write failures, rescan completed, disk space
+// low) to the channels configured under notify: SMTP email and a Telegram bot.
+//
+// Each event type is routed to its own channels (notify.routes) and rate
+// limited: after an alert is sent, further alerts of the same type within
+// notify.rate_limit_seconds are only counted and reported with the next one,
+// so a flood of failures produces one message per window.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+// Event types
+const (
+	EventScannerStalled       = "scanner_stalled"       // Indexer sync height stopped advancing while behind the node
+	EventBroadcastUnavailable = "broadcast_unavailable" // Every broadcast node (primary retries and fallback) unreachable
+	EventStorageWriteFailed   = "storage_write_failed"  // A blob could not be written to storage
+	EventRescanCompleted      = "rescan_completed"      // An admin rescan finished (or was cancelled)
+	EventDiskSpaceLow         = "disk_space_low"        // A storage / database volume is nearly full; indexer scanning paused
+	EventDiskSpaceRecovered   = "disk_space_recovered"  // Free space is back; indexer scanning resumed
+)
+
+// Channel names used in notify.routes
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+)
+
+const (
+	defaultRateLimit = 5 * time.Minute
+	sendTimeout      = 15 * time.Second
+	queueSize        = 256
+)
+
+// Alert a notification of one event
+type Alert struct {
+	Service    string    // indexer/uploader
+	Event      string    // Event type (Event*)
+	Message    string    // What happened
+	Time       time.Time // When it happened
+	Suppressed int       // Alerts of the same type dropped by the rate limit since the previous one
+}
+
+// Subject one-line summary of the alert
+func (a Alert) Subject() string {
+	return fmt.Sprintf("[%s] %s", a.Service, a.Event)
+}
+
+// Text full alert text
+func (a Alert) Text() string {
+	text := fmt.Sprintf("%s\n%s\n%s", a.Subject(), a.Message, a.Time.UTC().Format(time.RFC3339))
+	if a.Suppressed > 0 {
+		text += fmt.Sprintf("\n(%d more %s alerts suppressed by the rate limit)", a.Suppressed, a.Event)
+	}
+	return text
+}
+
+// Notifier a channel alerts are delivered to
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, alert Alert) error
+}
+
+// Dispatcher routes alerts to notifiers and rate limits them per event type
+type Dispatcher struct {
+	service   string
+	notifiers map[string]Notifier
+	routes    map[string][]string // Event type -> channel names; unlisted events go to every notifier
+	interval  time.Duration
+
+	mu         sync.Mutex
+	lastSent   map[string]time.Time
+	suppressed map[string]int
+	now        func() time.Time
+
+	queue chan Alert
+}
+
+// NewDispatcher creates a dispatcher; interval <= 0 uses the default rate limit
+func NewDispatcher(service string, notifiers []Notifier, routes map[string][]string, interval time.Duration) *Dispatcher {
+	if interval <= 0 {
+		interval = defaultRateLimit
+	}
+	d := &Dispatcher{
+		service:    service,
+		notifiers:  make(map[string]Notifier, len(notifiers)),
+		routes:     make(map[string][]string, len(routes)),
+		interval:   interval,
+		lastSent:   make(map[string]time.Time),
+		suppressed: make(map[string]int),
+		now:        time.Now,
+	}
+	for _, n := range notifiers {
+		d.notifiers[n.Name()] = n
+	}
+	for event, channels := range routes {
+		d.routes[strings.ToLower(event)] = channels
+	}
+	return d
+}
+
+// admit applies the rate limit: returns the alert to send (with the count of
+// alerts suppressed since the last one) or false when it is suppressed
+func (d *Dispatcher) admit(event, message string) (Alert, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.now()
+	if last, ok := d.lastSent[event]; ok && now.Sub(last) < d.interval {
+		d.suppressed[event]++
+		return Alert{}, false
+	}
+	alert := Alert{Service: d.service, Event: event, Message: message, Time: now, Suppressed: d.suppressed[event]}
+	d.lastSent[event] = now
+	delete(d.suppressed, event)
+	return alert, true
+}
+
+// targets returns the notifiers an event is routed to
+func (d *Dispatcher) targets(event string) []Notifier {
+	channels, routed := d.routes[event]
+	if !routed {
+		channels = make([]string, 0, len(d.notifiers))
+		for name := range d.notifiers {
+			channels = append(channels, name)
+		}
+		sort.Strings(channels)
+	}
+	targets := make([]Notifier, 0, len(channels))
+	for _, name := range channels {
+		if n, ok := d.notifiers[strings.ToLower(name)]; ok {
+			targets = append(targets, n)
+		}
+	}
+	return targets
+}
+
+// deliver sends alert to every notifier its event is routed to
+func (d *Dispatcher) deliver(alert Alert) {
+	for _, n := range d.targets(alert.Event) {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		if err := n.Send(ctx, alert); err != nil {
+			log.Printf("Failed to send %s alert via %s: %v", alert.Event, n.Name(), err)
+		}
+		cancel()
+	}
+}
+
+// start delivers queued alerts in the background
+func (d *Dispatcher) start() {
+	d.queue = make(chan Alert, queueSize)
+	go func() {
+		for alert := range d.queue {
+			d.deliver(alert)
+		}
+	}()
+}
+
+// Notify queues an alert of event unless the rate limit suppresses it. It
+// never blocks: alerts are dropped when the queue is full.
+func (d *Dispatcher) Notify(event, message string) {
+	if len(d.targets(event)) == 0 {
+		return
+	}
+	alert, ok := d.admit(event, message)
+	if !ok {
+		return
+	}
+	select {
+	case d.queue <- alert:
+	default:
+		log.Printf("Alert queue full, dropping %s alert: %s", event, message)
+	}
+}
+
+var (
+	defaultMu         sync.RWMutex
+	defaultDispatcher *Dispatcher
+)
+
+// Init sets up the channels of conf.Cfg.Notify for service (indexer or
+// uploader). Without any enabled channel alerts are discarded.
+func Init(service string) {
+	cfg := conf.Cfg.Notify
+	var notifiers []Notifier
+	if cfg.Email.Enabled {
+		notifiers = append(notifiers, NewEmailNotifier(cfg.Email))
+	}
+	if cfg.Telegram.Enabled {
+		notifiers = append(notifiers, NewTelegramNotifier(cfg.Telegram))
+	}
+	if len(notifiers) == 0 {
+		return
+	}
+
+	d := NewDispatcher(service, notifiers, cfg.Routes, time.Duration(cfg.RateLimitSeconds)*time.Second)
+	d.start()
+	defaultMu.Lock()
+	defaultDispatcher = d
+	defaultMu.Unlock()
+	log.Printf("Alert notifications enabled: %d channel(s)", len(notifiers))
+}
+
+// Enabled reports whether any alert channel is set up
+func Enabled() bool {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultDispatcher != nil
+}
+
+// Alertf sends an alert of event through the channels set up by Init; a no-op
+// when none is enabled
+func Alertf(event, format string, args ...interface{}) {
+	defaultMu.RLock()
+	d := defaultDispatcher
+	defaultMu.RUnlock()
+	if d == nil {
+		return
+	}
+	d.Notify(event, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+type recordingNotifier struct {
+	name   string
+	alerts []Alert
+}
+
+func (n *recordingNotifier) Name() string { return n.name }
+
+func (n *recordingNotifier) Send(_ context.Context, alert Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestDispatcherRoutes(t *testing.T) {
+	email := &recordingNotifier{name: ChannelEmail}
+	telegram := &recordingNotifier{name: ChannelTelegram}
+	d := NewDispatcher("indexer", []Notifier{email, telegram}, map[string][]string{
+		EventStorageWriteFailed: {"telegram"},
+		EventRescanCompleted:    {},
+	}, time.Minute)
+
+	for _, event := range []string{EventScannerStalled, EventStorageWriteFailed, EventRescanCompleted} {
+		if alert, ok := d.admit(event, "msg"); ok {
+			d.deliver(alert)
+		}
+	}
+
+	if len(email.alerts) != 1 || email.alerts[0].Event != EventScannerStalled {
+		t.Errorf("email alerts = %+v, want only the unrouted %s", email.alerts, EventScannerStalled)
+	}
+	if len(telegram.alerts) != 2 || telegram.alerts[1].Event != EventStorageWriteFailed {
+		t.Errorf("telegram alerts = %+v", telegram.alerts)
+	}
+	if len(d.targets(EventRescanCompleted)) != 0 {
+		t.Error("event routed to [] is not muted")
+	}
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	now := time.Now()
+	d := NewDispatcher("uploader", []Notifier{&recordingNotifier{name: ChannelEmail}}, nil, 5*time.Minute)
+	d.now = func() time.Time { return now }
+
+	if _, ok := d.admit(EventBroadcastUnavailable, "first"); !ok {
+		t.Fatal("first alert suppressed")
+	}
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Minute)
+		if _, ok := d.admit(EventBroadcastUnavailable, "flood"); ok {
+			t.Fatalf("alert %d within the window was sent", i)
+		}
+	}
+	if _, ok := d.admit(EventStorageWriteFailed, "other type"); !ok {
+		t.Error("rate limit shared across event types")
+	}
+
+	now = now.Add(2 * time.Minute)
+	alert, ok := d.admit(EventBroadcastUnavailable, "after window")
+	if !ok || alert.Suppressed != 3 {
+		t.Fatalf("alert after window = %+v, %v; want 3 suppressed", alert, ok)
+	}
+	if !strings.Contains(alert.Text(), "3 more broadcast_unavailable alerts suppressed") {
+		t.Errorf("text = %q", alert.Text())
+	}
+	if alert, _ := d.admit(EventBroadcastUnavailable, "next"); alert.Suppressed != 0 {
+		t.Errorf("suppressed count not reset: %d", alert.Suppressed)
+	}
+}
+
+func TestTelegramNotifierSend(t *testing.T) {
+	var got map[string]string
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&got)
+		if got["chat_id"] == "bad" {
+			http.Error(w, `{"ok":false,"description":"chat not found"}`, http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	prev := telegramAPI
+	telegramAPI = srv.URL
+	t.Cleanup(func() { telegramAPI = prev })
+
+	n := NewTelegramNotifier(conf.NotifyTelegramConfig{Enabled: true, BotToken: "123:abc", ChatID: "-100"})
+	alert := Alert{Service: "indexer", Event: EventScannerStalled, Message: "mvc stalled", Time: time.Now()}
+	if err := n.Send(context.Background(), alert); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if path != "/bot123:abc/sendMessage" || got["chat_id"] != "-100" || !strings.Contains(got["text"], "[indexer] scanner_stalled\nmvc stalled") {
+		t.Errorf("path %q, body %v", path, got)
+	}
+
+	n = NewTelegramNotifier(conf.NotifyTelegramConfig{Enabled: true, BotToken: "123:abc", ChatID: "bad"})
+	if err := n.Send(context.Background(), alert); err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("err = %v, want the API error", err)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	n := NewEmailNotifier(conf.NotifyEmailConfig{From: "alerts@example.com", To: []string{"a@example.com", "b@example.com"}})
+	msg := string(n.message(Alert{Service: "uploader", Event: EventStorageWriteFailed, Message: "disk full", Time: time.Now()}))
+	for _, want := range []string{
+		"From: alerts@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: [uploader] storage_write_failed\r\n",
+		"\r\n\r\n[uploader] storage_write_failed\r\ndisk full\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message missing %q:\n%s", want, msg)
+		}
+	}
+}
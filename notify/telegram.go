@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"meta-file-system/conf"
+)
+
+// telegramAPI Bot API base URL; replaced in tests
+var telegramAPI = "https://api.telegram.org"
+
+// TelegramNotifier sends alerts as messages of a Telegram bot to one chat
+type TelegramNotifier struct {
+	cfg    conf.NotifyTelegramConfig
+	client *http.Client
+}
+
+// NewTelegramNotifier creates a Telegram bot notifier
+func NewTelegramNotifier(cfg conf.NotifyTelegramConfig) *TelegramNotifier {
+	return &TelegramNotifier{cfg: cfg, client: &http.Client{Timeout: sendTimeout}}
+}
+
+// Name channel name
+func (n *TelegramNotifier) Name() string {
+	return ChannelTelegram
+}
+
+// Send posts the alert with the Bot API sendMessage method
+func (n *TelegramNotifier) Send(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(map[string]string{"chat_id": n.cfg.ChatID, "text": alert.Text()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramAPI+"/bot"+n.cfg.BotToken+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The request URL carries the bot token; keep it out of logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telegram responded %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Package contenttype parses the content types PINs are declared with. The
+// uploader and the indexer both go through it, so an upload is inscribed,
+// stored and served with the same type whatever casing and spacing the
+// client sent.
+//
+// A declaration is a MIME type whose parameters may include the MetaID
+// ";binary" flag (the body is raw bytes rather than text). The flag belongs
+// to the inscription only; stored fields and HTTP headers carry the MIME
+// type without it:
+//
+//	"Image/JPEG ;binary"       inscribed "image/jpeg;binary", stored "image/jpeg"
+//	"text/plain; charset=UTF8" inscribed "text/plain;charset=utf-8", stored "text/plain; charset=utf-8"
+package contenttype
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// Default type of content declared without one
+	Default = "application/octet-stream"
+	// BinaryFlag MetaID parameter marking a raw byte body
+	BinaryFlag = "binary"
+)
+
+// ContentType a parsed declaration
+type ContentType struct {
+	MediaType string            // type/subtype in lower case; "" when none was declared
+	Charset   string            // charset parameter in lower case; "" when none
+	Binary    bool              // Declared with ";binary"
+	Params    map[string]string // Other key=value parameters, keys in lower case
+}
+
+// charsetAliases spellings of charsets mapped to their registered names
+var charsetAliases = map[string]string{
+	"utf8":   "utf-8",
+	"latin1": "iso-8859-1",
+}
+
+// Parse parses declared leniently: parameters without a value other than
+// binary are dropped, quoted values are unquoted, and a declaration that is
+// only parameters has no media type
+func Parse(declared string) ContentType {
+	parts := strings.Split(declared, ";")
+	t := ContentType{MediaType: strings.ToLower(strings.TrimSpace(parts[0]))}
+	for _, part := range parts[1:] {
+		key, value, hasValue := strings.Cut(part, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch {
+		case key == BinaryFlag && !hasValue:
+			t.Binary = true
+		case key == "" || !hasValue || value == "":
+		case key == "charset":
+			t.Charset = strings.ToLower(value)
+			if alias, ok := charsetAliases[t.Charset]; ok {
+				t.Charset = alias
+			}
+		default:
+			if t.Params == nil {
+				t.Params = make(map[string]string)
+			}
+			t.Params[key] = value
+		}
+	}
+	return t
+}
+
+// String the stored and served form: the media type with its charset and
+// other parameters, without the binary flag; "" without a media type
+func (t ContentType) String() string {
+	return t.format("; ", false)
+}
+
+// Declaration the inscribed form: parameters joined without spaces and the
+// binary flag last; Default without a media type
+func (t ContentType) Declaration() string {
+	if t.MediaType == "" {
+		t.MediaType = Default
+	}
+	return t.format(";", t.Binary)
+}
+
+func (t ContentType) format(sep string, binary bool) string {
+	if t.MediaType == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(t.MediaType)
+	if t.Charset != "" {
+		b.WriteString(sep + "charset=" + t.Charset)
+	}
+	keys := make([]string, 0, len(t.Params))
+	for key := range t.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b.WriteString(sep + key + "=" + t.Params[key])
+	}
+	if binary {
+		b.WriteString(";" + BinaryFlag)
+	}
+	return b.String()
+}
+
+// Normalize the stored form of declared (ContentType.String)
+func Normalize(declared string) string {
+	return Parse(declared).String()
+}
+
+// MediaType the type/subtype of declared in lower case, without parameters
+func MediaType(declared string) string {
+	return Parse(declared).MediaType
+}
+
+// Declaration the inscribed form of declared (ContentType.Declaration);
+// Default when it is empty
+func Declaration(declared string) string {
+	return Parse(declared).Declaration()
+}
+
+// Header the Content-Type header of content stored with contentType, which
+// records indexed before normalization may still declare with ";binary";
+// Default when it is empty
+func Header(contentType string) string {
+	if normalized := Normalize(contentType); normalized != "" {
+		return normalized
+	}
+	return Default
+}
@@ -0,0 +1,48 @@
+package contenttype
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	got := Parse(` Image/JPEG ; Binary ; name="a b.jpg"; flag`)
+	if got.MediaType != "image/jpeg" || !got.Binary || got.Charset != "" || len(got.Params) != 1 || got.Params["name"] != "a b.jpg" {
+		t.Errorf("Parse = %+v", got)
+	}
+	if got := Parse("text/plain; charset=UTF8"); got.Charset != "utf-8" || got.Binary {
+		t.Errorf("charset alias: %+v", got)
+	}
+	// binary=... is an ordinary parameter, not the flag
+	if got := Parse("application/json;binary=1"); got.Binary || got.Params["binary"] != "1" {
+		t.Errorf("binary with a value: %+v", got)
+	}
+	if got := Parse(";binary"); got.MediaType != "" || !got.Binary {
+		t.Errorf("flag only: %+v", got)
+	}
+}
+
+func TestForms(t *testing.T) {
+	tests := []struct {
+		declared, stored, inscribed, header string
+	}{
+		{"image/jpeg;binary", "image/jpeg", "image/jpeg;binary", "image/jpeg"},
+		{"Image/PNG ; binary", "image/png", "image/png;binary", "image/png"},
+		{"text/plain; charset=UTF-8", "text/plain; charset=utf-8", "text/plain;charset=utf-8", "text/plain; charset=utf-8"},
+		{"text/html;x=2;charset=utf8;a=1", "text/html; charset=utf-8; a=1; x=2", "text/html;charset=utf-8;a=1;x=2", "text/html; charset=utf-8; a=1; x=2"},
+		{"metafile/chunk;binary", "metafile/chunk", "metafile/chunk;binary", "metafile/chunk"},
+		{"", "", Default, Default},
+		{";binary", "", Default + ";binary", Default},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.declared); got != tt.stored {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.declared, got, tt.stored)
+		}
+		if got := Declaration(tt.declared); got != tt.inscribed {
+			t.Errorf("Declaration(%q) = %q, want %q", tt.declared, got, tt.inscribed)
+		}
+		if got := Header(tt.declared); got != tt.header {
+			t.Errorf("Header(%q) = %q, want %q", tt.declared, got, tt.header)
+		}
+	}
+	if got := MediaType("Video/MP4; codecs=avc1"); got != "video/mp4" {
+		t.Errorf("MediaType = %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * * * *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) accepted", expr)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	utc := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		expr, from, want string
+	}{
+		{"0 3 * * *", "2026-10-16 02:59", "2026-10-16 03:00"},
+		{"0 3 * * *", "2026-10-16 03:00", "2026-10-17 03:00"},
+		{"@daily", "2026-12-31 12:00", "2027-01-01 00:00"},
+		{"@hourly", "2026-10-16 02:30", "2026-10-16 03:00"},
+		{"*/15 * * * *", "2026-10-16 02:31", "2026-10-16 02:45"},
+		{"30 1-5/2 * * *", "2026-10-16 02:00", "2026-10-16 03:30"},
+		{"0 0 * * sun", "2026-10-16 00:00", "2026-10-18 00:00"}, // Friday -> Sunday
+		{"0 0 * * 7", "2026-10-16 00:00", "2026-10-18 00:00"},
+		{"0 0 1,15 * *", "2026-10-16 00:00", "2026-11-01 00:00"},
+		{"0 0 13 * fri", "2026-10-10 00:00", "2026-10-13 00:00"}, // either day field
+		{"0 12 29 feb *", "2026-03-01 00:00", "2028-02-29 12:00"},
+		{"0 0 1 jan-mar *", "2026-04-01 00:00", "2027-01-01 00:00"},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(utc(tt.from)); !got.Equal(utc(tt.want)) {
+			t.Errorf("%q after %s = %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+
+	never, _ := Parse("0 0 30 2 *")
+	if got := never.Next(utc("2026-01-01 00:00")); !got.IsZero() {
+		t.Errorf("30 February fired at %s", got)
+	}
+}
@@ -0,0 +1,126 @@
+// Package metaid derives the MetaID of an address. Every service computes
+// MetaIDs through this package so the indexer, the uploader and the APIs
+// agree on the identifiers of one deployment.
+package metaid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// Derivation how a MetaID is derived from an address
+type Derivation string
+
+const (
+	// AddressSHA256 hex SHA256 of the address string (MetaID v2, the default)
+	AddressSHA256 Derivation = "address_sha256"
+	// GenesisTxID txid of the address's genesis PIN, i.e. the first PIN the
+	// indexer recorded for the address (MetaID v1 root transaction)
+	GenesisTxID Derivation = "genesis_txid"
+)
+
+// ParseDerivation parses a derivation name; empty selects AddressSHA256
+func ParseDerivation(name string) (Derivation, error) {
+	switch d := Derivation(strings.ToLower(strings.TrimSpace(name))); d {
+	case "":
+		return AddressSHA256, nil
+	case AddressSHA256, GenesisTxID:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown MetaID derivation %q (want %s or %s)", name, AddressSHA256, GenesisTxID)
+	}
+}
+
+// GenesisStore keeps the genesis PIN of each address for GenesisTxID
+type GenesisStore interface {
+	// GetMetaIDGenesis returns the genesis PIN ID recorded for address, "" if none
+	GetMetaIDGenesis(address string) (string, error)
+	// RecordMetaIDGenesis records pinID as the genesis of address unless one
+	// is already recorded
+	RecordMetaIDGenesis(address, pinID string) error
+}
+
+type deriver struct {
+	derivation Derivation
+	genesis    GenesisStore
+}
+
+var current atomic.Pointer[deriver]
+
+func init() {
+	current.Store(&deriver{derivation: AddressSHA256})
+}
+
+// SetDerivation selects the derivation used by Derive, e.g. from
+// metaid.derivation. genesis is only used by GenesisTxID; without one
+// GenesisTxID derives nothing (the uploader keeps client-supplied MetaIDs).
+func SetDerivation(name string, genesis GenesisStore) error {
+	d, err := ParseDerivation(name)
+	if err != nil {
+		return err
+	}
+	current.Store(&deriver{derivation: d, genesis: genesis})
+	log.Printf("MetaID derivation: %s", d)
+	return nil
+}
+
+// CurrentDerivation returns the derivation in effect
+func CurrentDerivation() Derivation {
+	return current.Load().derivation
+}
+
+// Derive returns the MetaID of address under the current derivation; "" for
+// an empty address or, with GenesisTxID, an address without a genesis PIN
+func Derive(address string) string {
+	if address == "" {
+		return ""
+	}
+	d := current.Load()
+	if d.derivation != GenesisTxID {
+		return FromAddress(address)
+	}
+	if d.genesis == nil {
+		return ""
+	}
+	pinID, err := d.genesis.GetMetaIDGenesis(address)
+	if err != nil {
+		log.Printf("Failed to get MetaID genesis of %s: %v", address, err)
+		return ""
+	}
+	return FromGenesisPin(pinID)
+}
+
+// Observe records pinID, a PIN created by address, as the address's genesis
+// when GenesisTxID is in effect and none is recorded yet. The indexer calls
+// it for every PIN in chain order, so the first PIN of an address wins.
+func Observe(address, pinID string) {
+	d := current.Load()
+	if d.derivation != GenesisTxID || d.genesis == nil || address == "" || pinID == "" {
+		return
+	}
+	if err := d.genesis.RecordMetaIDGenesis(address, pinID); err != nil {
+		log.Printf("Failed to record MetaID genesis of %s: %v", address, err)
+	}
+}
+
+// FromAddress MetaID of address under AddressSHA256
+func FromAddress(address string) string {
+	if address == "" {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(address))
+	return hex.EncodeToString(hash[:])
+}
+
+// FromGenesisPin MetaID under GenesisTxID: the txid of the genesis PIN ID
+// ({txid}i{vout})
+func FromGenesisPin(pinID string) string {
+	if i := strings.LastIndexByte(pinID, 'i'); i > 0 {
+		return pinID[:i]
+	}
+	return pinID
+}
@@ -0,0 +1,83 @@
+package metaid
+
+import "testing"
+
+type memGenesis map[string]string
+
+func (m memGenesis) GetMetaIDGenesis(address string) (string, error) {
+	return m[address], nil
+}
+
+func (m memGenesis) RecordMetaIDGenesis(address, pinID string) error {
+	if _, ok := m[address]; !ok {
+		m[address] = pinID
+	}
+	return nil
+}
+
+func TestParseDerivation(t *testing.T) {
+	for name, want := range map[string]Derivation{
+		"":               AddressSHA256,
+		"address_sha256": AddressSHA256,
+		" Genesis_TxID ": GenesisTxID,
+	} {
+		got, err := ParseDerivation(name)
+		if err != nil || got != want {
+			t.Errorf("ParseDerivation(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseDerivation("pubkey"); err == nil {
+		t.Error("unknown derivation accepted")
+	}
+}
+
+func TestDeriveAddressSHA256(t *testing.T) {
+	t.Cleanup(func() { SetDerivation("", nil) })
+	if err := SetDerivation("address_sha256", nil); err != nil {
+		t.Fatal(err)
+	}
+	// sha256("abc")
+	if got := Derive("abc"); got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("Derive = %s", got)
+	}
+	if Derive("") != "" {
+		t.Error("empty address derived a MetaID")
+	}
+}
+
+func TestDeriveGenesisTxID(t *testing.T) {
+	t.Cleanup(func() { SetDerivation("", nil) })
+	store := memGenesis{}
+	if err := SetDerivation("genesis_txid", store); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := Derive("1Addr"); got != "" {
+		t.Errorf("MetaID without genesis = %q, want empty", got)
+	}
+	Observe("1Addr", "aa11i0")
+	Observe("1Addr", "bb22i1") // later PINs do not replace the genesis
+	if got := Derive("1Addr"); got != "aa11" {
+		t.Errorf("Derive = %q, want aa11", got)
+	}
+
+	// Without a store (uploader) nothing is derived or recorded
+	if err := SetDerivation("genesis_txid", nil); err != nil {
+		t.Fatal(err)
+	}
+	Observe("1Other", "cc33i0")
+	if got := Derive("1Addr"); got != "" {
+		t.Errorf("Derive without store = %q, want empty", got)
+	}
+}
+
+func TestObserveIgnoredForAddressSHA256(t *testing.T) {
+	store := memGenesis{}
+	if err := SetDerivation("address_sha256", store); err != nil {
+		t.Fatal(err)
+	}
+	Observe("1Addr", "aa11i0")
+	if len(store) != 0 {
+		t.Errorf("genesis recorded under address_sha256: %v", store)
+	}
+}
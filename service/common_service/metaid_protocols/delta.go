@@ -0,0 +1,194 @@
+package metaid_protocols
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// MetaFileDeltaMfsV1 binary delta format of a v2 index delta envelope
+const MetaFileDeltaMfsV1 = "mfs-delta-v1"
+
+// ErrInvalidDelta is returned (wrapped) by ApplyDelta for a delta that
+// cannot be applied to the base it is given
+var ErrInvalidDelta = errors.New("invalid file delta")
+
+// mfs-delta-v1 layout: "MFSD", version byte 1, uvarint target size, then
+// operations until the end: opDeltaCopy uvarint offset, uvarint length
+// (bytes of the base) or opDeltaAdd uvarint length, literal bytes.
+const (
+	deltaMagic   = "MFSD"
+	deltaVersion = 1
+
+	opDeltaCopy = 0x01
+	opDeltaAdd  = 0x02
+
+	// deltaBlockSize bytes of the base indexed per block; shorter matches
+	// are written as literals
+	deltaBlockSize = 64
+	deltaHashBase  = 16777619
+)
+
+// IsDelta reports whether the chunks carry a delta against an earlier file
+func (idx *MetaFileIndex) IsDelta() bool {
+	return idx.Delta != nil && idx.Delta.Algorithm != ""
+}
+
+// ValidateMetaFileDelta checks a delta envelope: a known algorithm, the base
+// PIN and both hashes, and no encryption alongside it
+func ValidateMetaFileDelta(delta *MetaFileDelta, encryption *MetaFileEncryption) error {
+	if delta == nil {
+		return nil
+	}
+	if delta.Algorithm != MetaFileDeltaMfsV1 {
+		return fmt.Errorf("unsupported delta algorithm %q (allowed: %s)", delta.Algorithm, MetaFileDeltaMfsV1)
+	}
+	if delta.BasePinId == "" || delta.BaseSha256 == "" || delta.TargetSha256 == "" {
+		return fmt.Errorf("delta envelope needs basePinId, baseSha256 and targetSha256")
+	}
+	if delta.TargetSize <= 0 {
+		return fmt.Errorf("delta envelope without targetSize")
+	}
+	if encryption != nil && encryption.Algorithm != "" {
+		return fmt.Errorf("a delta cannot be encrypted")
+	}
+	return nil
+}
+
+// EncodeDelta returns an mfs-delta-v1 delta that rebuilds target from base:
+// runs of target found in base (at least deltaBlockSize bytes) are copied,
+// everything else is carried as literals
+func EncodeDelta(base, target []byte) []byte {
+	var out bytes.Buffer
+	out.WriteString(deltaMagic)
+	out.WriteByte(deltaVersion)
+	writeUvarint(&out, uint64(len(target)))
+
+	blocks := make(map[uint32]int, len(base)/deltaBlockSize)
+	for off := 0; off+deltaBlockSize <= len(base); off += deltaBlockSize {
+		h := deltaHash(base[off : off+deltaBlockSize])
+		if _, ok := blocks[h]; !ok {
+			blocks[h] = off
+		}
+	}
+
+	// pow = deltaHashBase^(deltaBlockSize-1), to roll the oldest byte out
+	pow := uint32(1)
+	for i := 1; i < deltaBlockSize; i++ {
+		pow *= deltaHashBase
+	}
+
+	literal := 0 // Start of the bytes not written yet
+	i := 0
+	var h uint32
+	rolled := false
+	for len(blocks) > 0 && i+deltaBlockSize <= len(target) {
+		if !rolled {
+			h = deltaHash(target[i : i+deltaBlockSize])
+			rolled = true
+		}
+		if off, ok := blocks[h]; ok && bytes.Equal(base[off:off+deltaBlockSize], target[i:i+deltaBlockSize]) {
+			start, baseStart := i, off
+			for start > literal && baseStart > 0 && target[start-1] == base[baseStart-1] {
+				start--
+				baseStart--
+			}
+			end, baseEnd := i+deltaBlockSize, off+deltaBlockSize
+			for end < len(target) && baseEnd < len(base) && target[end] == base[baseEnd] {
+				end++
+				baseEnd++
+			}
+			writeDeltaAdd(&out, target[literal:start])
+			out.WriteByte(opDeltaCopy)
+			writeUvarint(&out, uint64(baseStart))
+			writeUvarint(&out, uint64(end-start))
+			literal, i, rolled = end, end, false
+			continue
+		}
+		if i+deltaBlockSize < len(target) {
+			h = (h-uint32(target[i])*pow)*deltaHashBase + uint32(target[i+deltaBlockSize])
+		}
+		i++
+	}
+	writeDeltaAdd(&out, target[literal:])
+	return out.Bytes()
+}
+
+// ApplyDelta rebuilds the target of an mfs-delta-v1 delta from base. A
+// target larger than maxSize bytes is refused before it is built.
+func ApplyDelta(base, delta []byte, maxSize int64) ([]byte, error) {
+	if len(delta) < len(deltaMagic)+1 || string(delta[:len(deltaMagic)]) != deltaMagic {
+		return nil, fmt.Errorf("%w: not an %s delta", ErrInvalidDelta, MetaFileDeltaMfsV1)
+	}
+	if v := delta[len(deltaMagic)]; v != deltaVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidDelta, v)
+	}
+	r := bytes.NewReader(delta[len(deltaMagic)+1:])
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: truncated header", ErrInvalidDelta)
+	}
+	if size > uint64(maxSize) {
+		return nil, fmt.Errorf("%w: target of %d bytes exceeds %d bytes", ErrInvalidDelta, size, maxSize)
+	}
+
+	target := make([]byte, 0, size)
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+		switch op {
+		case opDeltaCopy:
+			off, err1 := binary.ReadUvarint(r)
+			n, err2 := binary.ReadUvarint(r)
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("%w: truncated copy", ErrInvalidDelta)
+			}
+			if off > uint64(len(base)) || n > uint64(len(base))-off {
+				return nil, fmt.Errorf("%w: copy of %d bytes at %d is outside the %d byte base", ErrInvalidDelta, n, off, len(base))
+			}
+			if n > size-uint64(len(target)) {
+				return nil, fmt.Errorf("%w: operations exceed the target size %d", ErrInvalidDelta, size)
+			}
+			target = append(target, base[off:off+n]...)
+		case opDeltaAdd:
+			n, err := binary.ReadUvarint(r)
+			if err != nil || n > uint64(r.Len()) {
+				return nil, fmt.Errorf("%w: truncated literal", ErrInvalidDelta)
+			}
+			if n > size-uint64(len(target)) {
+				return nil, fmt.Errorf("%w: operations exceed the target size %d", ErrInvalidDelta, size)
+			}
+			start := len(target)
+			target = append(target, make([]byte, n)...)
+			_, _ = r.Read(target[start:])
+		default:
+			return nil, fmt.Errorf("%w: unknown operation 0x%02x", ErrInvalidDelta, op)
+		}
+	}
+	if uint64(len(target)) != size {
+		return nil, fmt.Errorf("%w: rebuilt %d bytes, header says %d", ErrInvalidDelta, len(target), size)
+	}
+	return target, nil
+}
+
+func deltaHash(block []byte) uint32 {
+	var h uint32
+	for _, b := range block {
+		h = h*deltaHashBase + uint32(b)
+	}
+	return h
+}
+
+func writeDeltaAdd(out *bytes.Buffer, literal []byte) {
+	if len(literal) == 0 {
+		return
+	}
+	out.WriteByte(opDeltaAdd)
+	writeUvarint(out, uint64(len(literal)))
+	out.Write(literal)
+}
+
+func writeUvarint(out *bytes.Buffer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	out.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
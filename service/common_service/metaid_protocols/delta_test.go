@@ -0,0 +1,96 @@
+package metaid_protocols
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestDeltaRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	base := make([]byte, 200000)
+	rng.Read(base)
+
+	edited := append([]byte{}, base[:50000]...)
+	edited = append(edited, []byte("inserted paragraph")...)
+	edited = append(edited, base[50000:120000]...)
+	edited = append(edited, base[130000:]...) // 10000 bytes removed
+	edited[150000] ^= 0xff                    // one byte changed
+
+	appended := append(append([]byte{}, base...), []byte("tail")...)
+
+	cases := []struct {
+		name     string
+		base     []byte
+		target   []byte
+		maxDelta int // 0: no size expectation
+	}{
+		{"edited", base, edited, 1000},
+		{"appended", base, appended, 100},
+		{"identical", base, base, 50},
+		{"empty base", nil, []byte("hello"), 0},
+		{"empty target", base, nil, 0},
+		{"short base", []byte("abc"), []byte("abcdef"), 0},
+	}
+	for _, tc := range cases {
+		delta := EncodeDelta(tc.base, tc.target)
+		if tc.maxDelta > 0 && len(delta) > tc.maxDelta {
+			t.Errorf("%s: delta is %d bytes, want at most %d", tc.name, len(delta), tc.maxDelta)
+		}
+		got, err := ApplyDelta(tc.base, delta, int64(len(tc.target)))
+		if err != nil {
+			t.Fatalf("%s: ApplyDelta: %v", tc.name, err)
+		}
+		if !bytes.Equal(got, tc.target) {
+			t.Errorf("%s: rebuilt content differs from the target", tc.name)
+		}
+	}
+}
+
+func TestApplyDeltaRejects(t *testing.T) {
+	base := bytes.Repeat([]byte("0123456789abcdef"), 16)
+	target := append(append([]byte{}, base...), base...)
+	delta := EncodeDelta(base, target)
+
+	cases := []struct {
+		name  string
+		base  []byte
+		delta []byte
+		max   int64
+	}{
+		{"not a delta", base, []byte("hello world"), 1 << 20},
+		{"too large", base, delta, int64(len(target) - 1)},
+		{"truncated", base, delta[:len(delta)-1], 1 << 20},
+		{"short base", base[:100], delta, 1 << 20},
+		{"unknown op", base, append([]byte("MFSD\x01\x01"), 0x7f), 1 << 20},
+	}
+	for _, tc := range cases {
+		if _, err := ApplyDelta(tc.base, tc.delta, tc.max); !errors.Is(err, ErrInvalidDelta) {
+			t.Errorf("%s: err = %v, want ErrInvalidDelta", tc.name, err)
+		}
+	}
+}
+
+func TestValidateMetaFileDelta(t *testing.T) {
+	ok := &MetaFileDelta{Algorithm: MetaFileDeltaMfsV1, BasePinId: "p1i0", BaseSha256: "aa", TargetSha256: "bb", TargetSize: 10}
+	if err := ValidateMetaFileDelta(ok, nil); err != nil {
+		t.Errorf("valid envelope: %v", err)
+	}
+	if err := ValidateMetaFileDelta(nil, nil); err != nil {
+		t.Errorf("no envelope: %v", err)
+	}
+	bad := []*MetaFileDelta{
+		{Algorithm: "bsdiff", BasePinId: "p1i0", BaseSha256: "aa", TargetSha256: "bb", TargetSize: 10},
+		{Algorithm: MetaFileDeltaMfsV1, BaseSha256: "aa", TargetSha256: "bb", TargetSize: 10},
+		{Algorithm: MetaFileDeltaMfsV1, BasePinId: "p1i0", BaseSha256: "aa", TargetSha256: "bb"},
+	}
+	for i, d := range bad {
+		if err := ValidateMetaFileDelta(d, nil); err == nil {
+			t.Errorf("envelope %d accepted", i)
+		}
+	}
+	if err := ValidateMetaFileDelta(ok, &MetaFileEncryption{Algorithm: "aes-256-gcm"}); err == nil {
+		t.Error("encrypted delta accepted")
+	}
+}
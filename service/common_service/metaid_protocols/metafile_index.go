@@ -0,0 +1,110 @@
+package metaid_protocols
+
+import (
+	"fmt"
+)
+
+// MetaFileIndex schema versions
+const (
+	MetaFileIndexV1 = 1 // sha256 and pinId per chunk; the index has no version field
+	MetaFileIndexV2 = 2 // adds chunk offset/length, compression and encryption
+)
+
+// Compression of the file content described by a v2 index
+const (
+	MetaFileCompressionNone = "none"
+	MetaFileCompressionGzip = "gzip" // Whole file gzipped before chunking
+)
+
+// IndexVersion returns the schema version of the index (1 without a version field)
+func (idx *MetaFileIndex) IndexVersion() int {
+	if idx.Version <= 0 {
+		return MetaFileIndexV1
+	}
+	return idx.Version
+}
+
+// IsEncrypted reports whether the chunks carry ciphertext
+func (idx *MetaFileIndex) IsEncrypted() bool {
+	return idx.Encryption != nil && idx.Encryption.Algorithm != ""
+}
+
+// IsGzipCompressed reports whether the index declares the file gzip compressed
+func (idx *MetaFileIndex) IsGzipCompressed() bool {
+	return idx.Compression == MetaFileCompressionGzip
+}
+
+// ChunkRange returns the byte range of chunk i within the file: as listed in a
+// v2 index, else derived from chunkSize and fileSize (v1 uploaders always
+// split at chunkSize).
+func (idx *MetaFileIndex) ChunkRange(i int) (offset, length int64) {
+	if i >= 0 && i < len(idx.ChunkList) && idx.ChunkList[i].Length > 0 {
+		return idx.ChunkList[i].Offset, idx.ChunkList[i].Length
+	}
+	offset = int64(i) * idx.ChunkSize
+	length = idx.ChunkSize
+	if offset+length > idx.FileSize {
+		length = idx.FileSize - offset
+	}
+	if length < 0 {
+		length = 0
+	}
+	return offset, length
+}
+
+// Validate checks the v2 fields of the index: chunkNumber matches chunkList,
+// chunk ranges follow each other from offset 0 and add up to fileSize, the
+// compression is known, an encryption envelope names its algorithm and a
+// delta envelope is complete. A v1 index has none of these fields and always
+// passes.
+func (idx *MetaFileIndex) Validate() error {
+	version := idx.IndexVersion()
+	if version == MetaFileIndexV1 {
+		return nil
+	}
+	if version > MetaFileIndexV2 {
+		return fmt.Errorf("unsupported metafile index version %d", version)
+	}
+	if idx.ChunkNumber != len(idx.ChunkList) {
+		return fmt.Errorf("chunkNumber %d does not match %d chunkList entries", idx.ChunkNumber, len(idx.ChunkList))
+	}
+	var next int64
+	for i, chunk := range idx.ChunkList {
+		if chunk.Length <= 0 {
+			return fmt.Errorf("chunk %d has no length", i)
+		}
+		if chunk.Offset != next {
+			return fmt.Errorf("chunk %d starts at offset %d, want %d", i, chunk.Offset, next)
+		}
+		next += chunk.Length
+	}
+	if next != idx.FileSize {
+		return fmt.Errorf("chunk lengths add up to %d bytes, fileSize is %d", next, idx.FileSize)
+	}
+	if err := ValidateMetaFileCompression(idx.Compression); err != nil {
+		return err
+	}
+	if idx.Encryption != nil && idx.Encryption.Algorithm == "" {
+		return fmt.Errorf("encryption envelope without algorithm")
+	}
+	return ValidateMetaFileDelta(idx.Delta, idx.Encryption)
+}
+
+// ValidateMetaFileCompression rejects compression values a v2 index cannot carry
+func ValidateMetaFileCompression(compression string) error {
+	switch compression {
+	case "", MetaFileCompressionNone, MetaFileCompressionGzip:
+		return nil
+	}
+	return fmt.Errorf("unsupported compression %q (allowed: %s, %s)", compression, MetaFileCompressionNone, MetaFileCompressionGzip)
+}
+
+// AppendMetaFileChunk appends a chunk of length bytes to a v2 chunkList,
+// placed right after the previous entry
+func AppendMetaFileChunk(list []MetaFileChunk, sha256, pinId string, length int64) []MetaFileChunk {
+	var offset int64
+	if n := len(list); n > 0 {
+		offset = list[n-1].Offset + list[n-1].Length
+	}
+	return append(list, MetaFileChunk{Sha256: sha256, PinId: pinId, Offset: offset, Length: length})
+}
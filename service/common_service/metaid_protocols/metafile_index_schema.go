@@ -0,0 +1,147 @@
+package metaid_protocols
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Size caps of a metafile/index PIN. A 100 MB file in 1200-byte DOGE chunks
+// has ~87000 chunkList entries, about 15 MB of JSON.
+const (
+	MaxMetaFileIndexBytes  = 16 << 20
+	MaxMetaFileIndexChunks = 200000
+
+	maxMetaFileIndexInt = 1 << 53 // Largest integer a JSON number carries exactly
+)
+
+// ErrInvalidMetaFileIndex is returned (wrapped) by ParseMetaFileIndex for
+// index JSON that does not follow the schema
+var ErrInvalidMetaFileIndex = errors.New("invalid metafile index")
+
+// metaFileIndexInts integer fields of the index and of its nested objects;
+// writers may encode them as floats (e.g. 1.048576e+06)
+var (
+	metaFileIndexInts      = []string{"version", "fileSize", "chunkNumber", "chunkSize"}
+	metaFileIndexStrings   = []string{"sha256", "dataType", "name", "compression"}
+	metaFileChunkInts      = []string{"offset", "length"}
+	metaFileEncryptionInts = []string{"plainSize"}
+	metaFileDeltaInts      = []string{"targetSize"}
+)
+
+// ParseMetaFileIndex decodes the JSON of a metafile/index PIN after checking
+// its schema: at most MaxMetaFileIndexBytes, an object whose numbers are
+// non-negative integers (integral floats are accepted), whose text fields
+// are strings, and whose chunkList holds 1 to MaxMetaFileIndexChunks objects
+// naming a sha256 or a pinId. The v2 rules are checked by Validate.
+func ParseMetaFileIndex(content []byte) (*MetaFileIndex, error) {
+	if len(content) > MaxMetaFileIndexBytes {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d allowed", ErrInvalidMetaFileIndex, len(content), MaxMetaFileIndexBytes)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetaFileIndex, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: data after the index object", ErrInvalidMetaFileIndex)
+	}
+	index, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: not a JSON object", ErrInvalidMetaFileIndex)
+	}
+
+	if err := coerceIndexInts(index, "", metaFileIndexInts); err != nil {
+		return nil, err
+	}
+	for _, field := range metaFileIndexStrings {
+		if v, ok := index[field]; ok && v != nil {
+			if _, ok := v.(string); !ok {
+				return nil, fmt.Errorf("%w: %s must be a string", ErrInvalidMetaFileIndex, field)
+			}
+		}
+	}
+	for field, ints := range map[string][]string{"encryption": metaFileEncryptionInts, "delta": metaFileDeltaInts} {
+		switch v := index[field].(type) {
+		case nil:
+		case map[string]interface{}:
+			if err := coerceIndexInts(v, field+".", ints); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s must be an object", ErrInvalidMetaFileIndex, field)
+		}
+	}
+
+	chunkList, ok := index["chunkList"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: chunkList must be an array", ErrInvalidMetaFileIndex)
+	}
+	if len(chunkList) == 0 || len(chunkList) > MaxMetaFileIndexChunks {
+		return nil, fmt.Errorf("%w: chunkList has %d entries, want 1 to %d", ErrInvalidMetaFileIndex, len(chunkList), MaxMetaFileIndexChunks)
+	}
+	for i, entry := range chunkList {
+		chunk, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: chunk %d is not an object", ErrInvalidMetaFileIndex, i)
+		}
+		if err := coerceIndexInts(chunk, fmt.Sprintf("chunk %d ", i), metaFileChunkInts); err != nil {
+			return nil, err
+		}
+		named := false
+		for _, field := range []string{"sha256", "pinId"} {
+			switch v := chunk[field].(type) {
+			case nil:
+			case string:
+				named = named || v != ""
+			default:
+				return nil, fmt.Errorf("%w: chunk %d %s must be a string", ErrInvalidMetaFileIndex, i, field)
+			}
+		}
+		if !named {
+			return nil, fmt.Errorf("%w: chunk %d has neither sha256 nor pinId", ErrInvalidMetaFileIndex, i)
+		}
+	}
+
+	// Re-encode the checked values and decode them into the struct
+	corrected, err := json.Marshal(index)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetaFileIndex, err)
+	}
+	var metaFileIndex MetaFileIndex
+	if err := json.Unmarshal(corrected, &metaFileIndex); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetaFileIndex, err)
+	}
+	return &metaFileIndex, nil
+}
+
+// coerceIndexInts replaces the integer fields of obj by int64 values,
+// refusing fractions, negative numbers, values beyond 2^53 and non-numbers
+func coerceIndexInts(obj map[string]interface{}, prefix string, fields []string) error {
+	for _, field := range fields {
+		v, ok := obj[field]
+		if !ok || v == nil {
+			continue
+		}
+		number, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%w: %s%s must be a number", ErrInvalidMetaFileIndex, prefix, field)
+		}
+		if n, err := number.Int64(); err == nil {
+			if n < 0 || n > maxMetaFileIndexInt {
+				return fmt.Errorf("%w: %s%s %d out of range", ErrInvalidMetaFileIndex, prefix, field, n)
+			}
+			obj[field] = n
+			continue
+		}
+		f, err := number.Float64()
+		if err != nil || f != math.Trunc(f) || f < 0 || f > maxMetaFileIndexInt {
+			return fmt.Errorf("%w: %s%s %s is not a non-negative integer", ErrInvalidMetaFileIndex, prefix, field, number)
+		}
+		obj[field] = int64(f)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package metaid_protocols
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseMetaFileIndex(t *testing.T) {
+	idx, err := ParseMetaFileIndex([]byte(`{"sha256":"aa","fileSize":1.048576e+06,"chunkNumber":1,"chunkSize":1048576.0,"chunkList":[{"sha256":"c1","pinId":"p1i0","length":2e0}],"delta":null}`))
+	if err != nil {
+		t.Fatalf("ParseMetaFileIndex = %v", err)
+	}
+	if idx.FileSize != 1048576 || idx.ChunkSize != 1048576 || idx.ChunkList[0].Length != 2 || idx.Delta != nil {
+		t.Errorf("parsed index = %+v", idx)
+	}
+	if _, err := ParseMetaFileIndex([]byte(v1IndexJSON)); err != nil {
+		t.Errorf("ParseMetaFileIndex(v1) = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"not JSON", `{`},
+		{"not an object", `[1]`},
+		{"trailing data", `{"chunkList":[{"pinId":"p"}]} {}`},
+		{"fractional size", `{"fileSize":1.5,"chunkList":[{"pinId":"p"}]}`},
+		{"negative size", `{"fileSize":-1,"chunkList":[{"pinId":"p"}]}`},
+		{"huge size", `{"fileSize":1e300,"chunkList":[{"pinId":"p"}]}`},
+		{"size as string", `{"fileSize":"10","chunkList":[{"pinId":"p"}]}`},
+		{"name as number", `{"name":7,"chunkList":[{"pinId":"p"}]}`},
+		{"encryption as string", `{"encryption":"aes","chunkList":[{"pinId":"p"}]}`},
+		{"no chunkList", `{"fileSize":1}`},
+		{"empty chunkList", `{"chunkList":[]}`},
+		{"chunk not an object", `{"chunkList":["p"]}`},
+		{"unnamed chunk", `{"chunkList":[{"sha256":"","length":1}]}`},
+		{"chunk pinId as number", `{"chunkList":[{"pinId":1}]}`},
+		{"negative chunk offset", `{"chunkList":[{"pinId":"p","offset":-2}]}`},
+		{"too large", `{"name":"` + strings.Repeat("a", MaxMetaFileIndexBytes) + `","chunkList":[{"pinId":"p"}]}`},
+		{"too many chunks", `{"chunkList":[` + strings.Repeat(`{"pinId":"p"},`, MaxMetaFileIndexChunks) + `{"pinId":"p"}]}`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseMetaFileIndex([]byte(tt.content)); !errors.Is(err, ErrInvalidMetaFileIndex) {
+			t.Errorf("%s: err = %v, want ErrInvalidMetaFileIndex", tt.name, err)
+		}
+	}
+}
+
+func FuzzParseMetaFileIndex(f *testing.F) {
+	f.Add([]byte(v1IndexJSON))
+	f.Add([]byte(`{"version":2,"sha256":"aa","fileSize":10,"chunkNumber":1,"chunkSize":10,"compression":"gzip","chunkList":[{"sha256":"bb","pinId":"p","offset":0,"length":10}]}`))
+	f.Add([]byte(`{"fileSize":1e308,"chunkList":[{"pinId":"p","offset":-0.0}],"encryption":{"plainSize":9.0}}`))
+	f.Add([]byte(`{"chunkList":[null,{}]}`))
+	f.Fuzz(func(t *testing.T, content []byte) {
+		idx, err := ParseMetaFileIndex(content)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidMetaFileIndex) {
+				t.Fatalf("error not wrapping ErrInvalidMetaFileIndex: %v", err)
+			}
+			return
+		}
+		if idx.FileSize < 0 || idx.ChunkSize < 0 || len(idx.ChunkList) == 0 || len(idx.ChunkList) > MaxMetaFileIndexChunks {
+			t.Fatalf("accepted index out of schema: %+v", idx)
+		}
+		for i, chunk := range idx.ChunkList {
+			if chunk.Offset < 0 || chunk.Length < 0 || (chunk.Sha256 == "" && chunk.PinId == "") {
+				t.Fatalf("accepted chunk %d out of schema: %+v", i, chunk)
+			}
+		}
+		// Validate and the chunk ranges must not panic on any accepted index
+		_ = idx.Validate()
+		for i := range idx.ChunkList {
+			idx.ChunkRange(i)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package metaid_protocols
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const v1IndexJSON = `{"sha256":"aa","fileSize":5,"chunkNumber":3,"chunkSize":2,"dataType":"text/plain","name":"a.txt","chunkList":[{"sha256":"c1","pinId":"p1i0"},{"sha256":"c2","pinId":"p2i0"},{"sha256":"c3","pinId":"p3i0"}]}`
+
+func TestMetaFileIndex_V1(t *testing.T) {
+	var idx MetaFileIndex
+	if err := json.Unmarshal([]byte(v1IndexJSON), &idx); err != nil {
+		t.Fatal(err)
+	}
+	if idx.IndexVersion() != MetaFileIndexV1 || idx.IsEncrypted() || idx.IsGzipCompressed() {
+		t.Errorf("v1 index: version %d encrypted %v gzip %v", idx.IndexVersion(), idx.IsEncrypted(), idx.IsGzipCompressed())
+	}
+	if err := idx.Validate(); err != nil {
+		t.Errorf("Validate(v1) = %v", err)
+	}
+	wantRanges := [][2]int64{{0, 2}, {2, 2}, {4, 1}}
+	for i, want := range wantRanges {
+		if off, length := idx.ChunkRange(i); off != want[0] || length != want[1] {
+			t.Errorf("ChunkRange(%d) = %d,%d, want %d,%d", i, off, length, want[0], want[1])
+		}
+	}
+
+	// A v1 index re-encodes without any v2 field
+	data, err := json.Marshal(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != v1IndexJSON {
+		t.Errorf("re-encoded v1 index = %s", data)
+	}
+}
+
+func TestMetaFileIndex_V2RoundTrip(t *testing.T) {
+	var chunks []MetaFileChunk
+	chunks = AppendMetaFileChunk(chunks, "c1", "p1i0", 3)
+	chunks = AppendMetaFileChunk(chunks, "c2", "p2i0", 2)
+	idx := MetaFileIndex{
+		Version:     MetaFileIndexV2,
+		Sha256:      "aa",
+		FileSize:    5,
+		ChunkNumber: 2,
+		ChunkSize:   3,
+		Compression: MetaFileCompressionGzip,
+		Encryption:  &MetaFileEncryption{Algorithm: "aes-256-gcm", KeyId: "k1", Iv: "00", PlainSize: 9},
+		ChunkList:   chunks,
+	}
+	if err := idx.Validate(); err != nil {
+		t.Fatalf("Validate = %v", err)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"version":2`, `"compression":"gzip"`, `"algorithm":"aes-256-gcm"`, `{"sha256":"c2","pinId":"p2i0","offset":3,"length":2}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("encoded index %s lacks %s", data, want)
+		}
+	}
+
+	var decoded MetaFileIndex
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.IndexVersion() != MetaFileIndexV2 || !decoded.IsEncrypted() || !decoded.IsGzipCompressed() {
+		t.Errorf("decoded v2 index: %+v", decoded)
+	}
+	if off, length := decoded.ChunkRange(1); off != 3 || length != 2 {
+		t.Errorf("ChunkRange(1) = %d,%d, want 3,2", off, length)
+	}
+}
+
+func TestMetaFileIndex_Validate(t *testing.T) {
+	valid := func() MetaFileIndex {
+		var chunks []MetaFileChunk
+		chunks = AppendMetaFileChunk(chunks, "c1", "p1i0", 2)
+		chunks = AppendMetaFileChunk(chunks, "c2", "p2i0", 2)
+		return MetaFileIndex{Version: MetaFileIndexV2, FileSize: 4, ChunkNumber: 2, ChunkSize: 2, ChunkList: chunks}
+	}
+	cases := []struct {
+		name   string
+		mutate func(*MetaFileIndex)
+	}{
+		{"future version", func(idx *MetaFileIndex) { idx.Version = 3 }},
+		{"chunk count", func(idx *MetaFileIndex) { idx.ChunkNumber = 3 }},
+		{"missing length", func(idx *MetaFileIndex) { idx.ChunkList[1].Length = 0 }},
+		{"gap", func(idx *MetaFileIndex) { idx.ChunkList[1].Offset = 3 }},
+		{"file size", func(idx *MetaFileIndex) { idx.FileSize = 5 }},
+		{"compression", func(idx *MetaFileIndex) { idx.Compression = "zstd" }},
+		{"encryption algorithm", func(idx *MetaFileIndex) { idx.Encryption = &MetaFileEncryption{KeyId: "k1"} }},
+	}
+	base := valid()
+	if err := base.Validate(); err != nil {
+		t.Fatalf("Validate(valid) = %v", err)
+	}
+	for _, tc := range cases {
+		idx := valid()
+		tc.mutate(&idx)
+		if err := idx.Validate(); err == nil {
+			t.Errorf("%s: Validate = nil, want error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package metaid_protocols
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// MfsScheme is the scheme of content-addressable MetaFS URIs
+const MfsScheme = "mfs"
+
+// Kinds of MfsURI
+const (
+	MfsKindPin    = "pin"    // mfs://{pinId}: one PIN
+	MfsKindSha256 = "sha256" // mfs://{sha256}: any PIN whose file content has this sha256
+)
+
+var ErrInvalidMfsURI = errors.New("invalid mfs uri: want mfs://{pinId} or mfs://{sha256}")
+
+var sha256Pattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// MfsURI is a parsed mfs:// URI. It names content independently of the
+// gateway serving it:
+//
+//	mfs://{pinId}    e.g. mfs://6b86…4b0i0
+//	mfs://{sha256}   e.g. mfs://2c26…7ae (lower-case hex)
+type MfsURI struct {
+	Kind   string // MfsKindPin or MfsKindSha256
+	PinID  string // lower-cased PIN ID (Kind pin)
+	Sha256 string // lower-cased content sha256 (Kind sha256)
+}
+
+// ParseMfsURI parses raw as mfs://{pinId} or mfs://{sha256}. The scheme and
+// hex are case-insensitive; a trailing slash is ignored.
+func ParseMfsURI(raw string) (*MfsURI, error) {
+	raw = strings.TrimSpace(raw)
+	i := strings.Index(raw, "://")
+	if i < 0 || !strings.EqualFold(raw[:i], MfsScheme) {
+		return nil, ErrInvalidMfsURI
+	}
+	id := strings.ToLower(strings.TrimSuffix(raw[i+3:], "/"))
+	switch {
+	case pinIDPattern.MatchString(id):
+		return &MfsURI{Kind: MfsKindPin, PinID: id}, nil
+	case sha256Pattern.MatchString(id):
+		return &MfsURI{Kind: MfsKindSha256, Sha256: id}, nil
+	}
+	return nil, ErrInvalidMfsURI
+}
+
+// ID returns the PIN ID or sha256 the URI names
+func (u *MfsURI) ID() string {
+	if u.Kind == MfsKindSha256 {
+		return u.Sha256
+	}
+	return u.PinID
+}
+
+// String returns the canonical (lower-case) form of the URI
+func (u *MfsURI) String() string {
+	return MfsScheme + "://" + u.ID()
+}
+
+// MfsPinURI returns mfs://{pinId}
+func MfsPinURI(pinID string) string {
+	return (&MfsURI{Kind: MfsKindPin, PinID: strings.ToLower(pinID)}).String()
+}
+
+// MfsSha256URI returns mfs://{sha256} for a hex content hash
+func MfsSha256URI(sha256 string) string {
+	return (&MfsURI{Kind: MfsKindSha256, Sha256: strings.ToLower(sha256)}).String()
+}
@@ -0,0 +1,53 @@
+package metaid_protocols
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseMfsURI(t *testing.T) {
+	txid := strings.Repeat("ab", 32)
+	tests := []struct {
+		raw     string
+		kind    string
+		id      string
+		wantErr bool
+	}{
+		{"mfs://" + txid + "i0", MfsKindPin, txid + "i0", false},
+		{"MFS://" + strings.ToUpper(txid) + "i12/", MfsKindPin, txid + "i12", false},
+		{" mfs://" + txid + " ", MfsKindSha256, txid, false},
+		{"mfs://" + txid[:63], "", "", true},
+		{"mfs://" + txid + "i", "", "", true},
+		{"mfs://" + txid + "/file.png", "", "", true},
+		{"metafile://" + txid + "i0", "", "", true},
+		{txid + "i0", "", "", true},
+		{"", "", "", true},
+	}
+	for _, tt := range tests {
+		u, err := ParseMfsURI(tt.raw)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidMfsURI) {
+				t.Errorf("ParseMfsURI(%q) = %+v, %v; want ErrInvalidMfsURI", tt.raw, u, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMfsURI(%q): %v", tt.raw, err)
+			continue
+		}
+		if u.Kind != tt.kind || u.ID() != tt.id {
+			t.Errorf("ParseMfsURI(%q) = %+v, want %s %s", tt.raw, u, tt.kind, tt.id)
+		}
+		if u.String() != "mfs://"+tt.id {
+			t.Errorf("String() = %q", u.String())
+		}
+	}
+
+	if got := MfsPinURI(strings.ToUpper(txid) + "i1"); got != "mfs://"+txid+"i1" {
+		t.Errorf("MfsPinURI = %q", got)
+	}
+	if u, err := ParseMfsURI(MfsSha256URI(txid)); err != nil || u.Sha256 != txid {
+		t.Errorf("MfsSha256URI round trip = %+v, %v", u, err)
+	}
+}
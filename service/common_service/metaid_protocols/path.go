@@ -0,0 +1,197 @@
+package metaid_protocols
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxPathLength matches the width of the path columns (varchar(500)).
+const MaxPathLength = 500
+
+var (
+	ErrEmptyPath      = errors.New("metaid path is empty")
+	ErrPathTooLong    = fmt.Errorf("metaid path exceeds %d characters", MaxPathLength)
+	ErrInvalidHost    = errors.New("metaid path has an invalid host prefix")
+	ErrInvalidRef     = errors.New("metaid path has an invalid @pinId reference")
+	ErrInvalidSegment = errors.New("metaid path has an invalid segment")
+)
+
+var (
+	hostPattern    = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]*[a-z0-9])?$`)
+	pinIDPattern   = regexp.MustCompile(`^[0-9a-f]{64}i[0-9]+$`)
+	segmentPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// MetaIDPath is a parsed MetaID path. Supported forms:
+//
+//	/info/name               plain path
+//	host:/file/_chunk        path with host prefix
+//	@{pinId}                 reference to another PIN (modify/revoke)
+//	host:@{pinId}            reference with host prefix
+//
+// Path keeps the caller's case (it is what gets inscribed); Normalized is the
+// lower-cased form used for matching.
+type MetaIDPath struct {
+	Host       string   // lower-cased host prefix, "" when absent
+	RefPinID   string   // referenced pinId for @ paths, "" otherwise
+	Path       string   // cleaned path: single slashes, leading slash, no trailing slash
+	Normalized string   // lower-cased Path
+	Segments   []string // lower-cased path segments
+}
+
+// ParseMetaIDPath validates raw against the MetaID path grammar and returns it
+// normalized (slashes collapsed, leading slash added, host and reference
+// lower-cased).
+func ParseMetaIDPath(raw string) (*MetaIDPath, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, ErrEmptyPath
+	}
+	if len(raw) > MaxPathLength {
+		return nil, ErrPathTooLong
+	}
+
+	p := &MetaIDPath{}
+	rest := raw
+
+	// Host prefix: everything before the first ':' when it precedes any '/' or '@'.
+	if i := strings.Index(rest, ":"); i >= 0 && !strings.ContainsAny(rest[:i], "/@") {
+		host := strings.ToLower(rest[:i])
+		if !hostPattern.MatchString(host) {
+			return nil, ErrInvalidHost
+		}
+		p.Host = host
+		rest = rest[i+1:]
+	}
+
+	if strings.HasPrefix(rest, "@") {
+		ref := strings.ToLower(rest[1:])
+		if !pinIDPattern.MatchString(ref) {
+			return nil, ErrInvalidRef
+		}
+		p.RefPinID = ref
+		return p, nil
+	}
+
+	var segments []string
+	for _, seg := range strings.Split(strings.ReplaceAll(rest, "\\", "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if seg == "." || seg == ".." || !segmentPattern.MatchString(seg) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSegment, seg)
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		return nil, ErrEmptyPath
+	}
+
+	p.Path = "/" + strings.Join(segments, "/")
+	p.Normalized = strings.ToLower(p.Path)
+	p.Segments = strings.Split(strings.TrimPrefix(p.Normalized, "/"), "/")
+	return p, nil
+}
+
+// IsReference reports whether the path is an @pinId reference.
+func (p *MetaIDPath) IsReference() bool {
+	return p.RefPinID != ""
+}
+
+// String renders the path back to its canonical on-chain form.
+func (p *MetaIDPath) String() string {
+	body := p.Path
+	if p.IsReference() {
+		body = "@" + p.RefPinID
+	}
+	if p.Host != "" {
+		return p.Host + ":" + body
+	}
+	return body
+}
+
+// HasPrefix reports whether the path starts with prefix on segment
+// boundaries, case-insensitively: "/file/_chunk" has prefix "/file" but
+// "/files" does not.
+func (p *MetaIDPath) HasPrefix(prefix string) bool {
+	want := splitSegments(prefix)
+	if len(want) == 0 || len(want) > len(p.Segments) {
+		return false
+	}
+	for i, seg := range want {
+		if p.Segments[i] != seg {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsSegments reports whether sub appears as a run of whole segments
+// anywhere in the path, case-insensitively: "/app/file/_chunk" contains
+// "/file/_chunk".
+func (p *MetaIDPath) ContainsSegments(sub string) bool {
+	want := splitSegments(sub)
+	if len(want) == 0 {
+		return false
+	}
+	for start := 0; start+len(want) <= len(p.Segments); start++ {
+		match := true
+		for i, seg := range want {
+			if p.Segments[start+i] != seg {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPinID reports whether id is a lower-case PIN ID ({txid}i{vout})
+func IsPinID(id string) bool {
+	return pinIDPattern.MatchString(id)
+}
+
+// PathHasPrefix parses raw and reports HasPrefix; invalid paths and
+// references never match.
+func PathHasPrefix(raw, prefix string) bool {
+	p, err := ParseMetaIDPath(raw)
+	if err != nil || p.IsReference() {
+		return false
+	}
+	return p.HasPrefix(prefix)
+}
+
+// PathContainsSegments parses raw and reports ContainsSegments; invalid paths
+// and references never match.
+func PathContainsSegments(raw, sub string) bool {
+	p, err := ParseMetaIDPath(raw)
+	if err != nil || p.IsReference() {
+		return false
+	}
+	return p.ContainsSegments(sub)
+}
+
+// NormalizeUploadPath validates a path supplied to the uploader and returns
+// its canonical form (case preserved) for inscription.
+func NormalizeUploadPath(raw string) (string, error) {
+	p, err := ParseMetaIDPath(raw)
+	if err != nil {
+		return "", err
+	}
+	return p.String(), nil
+}
+
+func splitSegments(path string) []string {
+	var out []string
+	for _, seg := range strings.Split(strings.ToLower(path), "/") {
+		if seg != "" {
+			out = append(out, seg)
+		}
+	}
+	return out
+}
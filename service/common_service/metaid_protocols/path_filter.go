@@ -0,0 +1,128 @@
+package metaid_protocols
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"sync/atomic"
+)
+
+// PathFilter decides which protocol paths the indexer stores. A path is
+// indexed when it matches at least one allow pattern and no deny pattern.
+//
+// Patterns are MetaID paths whose segments may use wildcards: "*" matches
+// exactly one segment (path.Match globs such as "img*" also work within a
+// segment) and "**" matches zero or more segments, e.g. allow /file/** and
+// /info/**, deny /protocols/garbage/**.
+type PathFilter struct {
+	allow [][]string
+	deny  [][]string
+}
+
+// NewPathFilter compiles allow/deny patterns. An empty allow list falls back
+// to the built-in ProtocolList (each entry and everything below it).
+func NewPathFilter(allow, deny []string) (*PathFilter, error) {
+	if len(allow) == 0 {
+		for _, protocol := range ProtocolList {
+			allow = append(allow, protocol+"/**")
+		}
+	}
+
+	f := &PathFilter{}
+	for _, pattern := range allow {
+		segs, err := compilePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.allow = append(f.allow, segs)
+	}
+	for _, pattern := range deny {
+		segs, err := compilePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		f.deny = append(f.deny, segs)
+	}
+	return f, nil
+}
+
+// Allows reports whether path passes the filter. Invalid paths and @pinId
+// references are never allowed.
+func (f *PathFilter) Allows(raw string) bool {
+	parsed, err := ParseMetaIDPath(raw)
+	if err != nil || parsed.IsReference() {
+		return false
+	}
+	for _, pattern := range f.deny {
+		if matchSegments(pattern, parsed.Segments) {
+			return false
+		}
+	}
+	for _, pattern := range f.allow {
+		if matchSegments(pattern, parsed.Segments) {
+			return true
+		}
+	}
+	return false
+}
+
+var currentPathFilter atomic.Pointer[PathFilter]
+
+// SetPathFilter replaces the filter used by IsProtocolPath. It is safe to call
+// while blocks are being indexed (e.g. from a config reload); on a bad
+// pattern the previous filter stays in effect.
+func SetPathFilter(allow, deny []string) error {
+	f, err := NewPathFilter(allow, deny)
+	if err != nil {
+		return err
+	}
+	currentPathFilter.Store(f)
+	log.Printf("Protocol path filter updated: allow=%v deny=%v", allow, deny)
+	return nil
+}
+
+func activePathFilter() *PathFilter {
+	if f := currentPathFilter.Load(); f != nil {
+		return f
+	}
+	f, _ := NewPathFilter(nil, nil)
+	currentPathFilter.CompareAndSwap(nil, f)
+	return currentPathFilter.Load()
+}
+
+func compilePathPattern(pattern string) ([]string, error) {
+	segs := splitSegments(pattern)
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("empty path pattern %q", pattern)
+	}
+	for _, seg := range segs {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+	}
+	return segs, nil
+}
+
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
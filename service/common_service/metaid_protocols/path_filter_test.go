@@ -0,0 +1,55 @@
+package metaid_protocols
+
+import "testing"
+
+func TestPathFilterAllows(t *testing.T) {
+	cases := []struct {
+		name        string
+		allow, deny []string
+		path        string
+		want        bool
+	}{
+		{"default allows file", nil, nil, "/file/a.png", true},
+		{"default allows info name", nil, nil, "/info/name", true},
+		{"default rejects other", nil, nil, "/protocols/simplebuzz", false},
+		{"double star any depth", []string{"/file/**"}, nil, "/file/a/b/c", true},
+		{"double star zero segments", []string{"/file/**"}, nil, "/file", true},
+		{"single star one segment", []string{"/info/*"}, nil, "/info/name", true},
+		{"single star not deeper", []string{"/info/*"}, nil, "/info/name/x", false},
+		{"glob within segment", []string{"/file/img*"}, nil, "/file/img01", true},
+		{"case insensitive", []string{"/file/**"}, nil, "/FILE/x", true},
+		{"deny wins", []string{"/**"}, []string{"/protocols/garbage/**"}, "/protocols/garbage/x", false},
+		{"deny leaves siblings", []string{"/**"}, []string{"/protocols/garbage/**"}, "/protocols/buzz", true},
+		{"deny over default", nil, []string{"/info/bio"}, "/info/bio", false},
+		{"reference never allowed", []string{"/**"}, nil, "@" + testPinID, false},
+		{"invalid path never allowed", []string{"/**"}, nil, "/file/../x", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := NewPathFilter(tc.allow, tc.deny)
+			if err != nil {
+				t.Fatalf("NewPathFilter: %v", err)
+			}
+			if got := f.Allows(tc.path); got != tc.want {
+				t.Errorf("Allows(%q) = %v, want %v", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetPathFilter(t *testing.T) {
+	t.Cleanup(func() { _ = SetPathFilter(nil, nil) })
+
+	if err := SetPathFilter([]string{"/file/**"}, nil); err != nil {
+		t.Fatalf("SetPathFilter: %v", err)
+	}
+	if IsProtocolPath("/info/name") {
+		t.Error("/info/name should be filtered out")
+	}
+	if err := SetPathFilter([]string{"/file/[a"}, nil); err == nil {
+		t.Fatal("SetPathFilter should reject a malformed pattern")
+	}
+	if !IsProtocolPath("/file/a.png") || IsProtocolPath("/info/name") {
+		t.Error("previous filter should stay in effect after a bad update")
+	}
+}
@@ -0,0 +1,166 @@
+package metaid_protocols
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testPinID = "b5a1f1e3c8d6a0e2f4b6c8d0e2f4a6b8c0d2e4f6a8b0c2d4e6f8a0b2c4d6e8f0i0"
+
+func TestParseMetaIDPath(t *testing.T) {
+	cases := []struct {
+		raw        string
+		wantErr    error
+		host       string
+		ref        string
+		path       string
+		normalized string
+		canonical  string
+	}{
+		{raw: "/file", path: "/file", normalized: "/file", canonical: "/file"},
+		{raw: "/info/chatPublicKey", path: "/info/chatPublicKey", normalized: "/info/chatpublickey", canonical: "/info/chatPublicKey"},
+		{raw: "file/index", path: "/file/index", normalized: "/file/index", canonical: "/file/index"},
+		{raw: "//file///_chunk/", path: "/file/_chunk", normalized: "/file/_chunk", canonical: "/file/_chunk"},
+		{raw: `\file\a.png`, path: "/file/a.png", normalized: "/file/a.png", canonical: "/file/a.png"},
+		{raw: "  /info/name  ", path: "/info/name", normalized: "/info/name", canonical: "/info/name"},
+		{raw: "MetaID.io:/File/Index", host: "metaid.io", path: "/File/Index", normalized: "/file/index", canonical: "metaid.io:/File/Index"},
+		{raw: "@" + testPinID, ref: testPinID, canonical: "@" + testPinID},
+		{raw: "@" + strings.ToUpper(testPinID[:64]) + "i0", ref: testPinID, canonical: "@" + testPinID},
+		{raw: "host:@" + testPinID, host: "host", ref: testPinID, canonical: "host:@" + testPinID},
+
+		{raw: "", wantErr: ErrEmptyPath},
+		{raw: "   ", wantErr: ErrEmptyPath},
+		{raw: "/", wantErr: ErrEmptyPath},
+		{raw: "///", wantErr: ErrEmptyPath},
+		{raw: "/" + strings.Repeat("a", MaxPathLength), wantErr: ErrPathTooLong},
+		{raw: "-bad-:/file", wantErr: ErrInvalidHost},
+		{raw: ":/file", wantErr: ErrInvalidHost},
+		{raw: "ho st:/file", wantErr: ErrInvalidHost},
+		{raw: "@notapin", wantErr: ErrInvalidRef},
+		{raw: "@" + testPinID[:64], wantErr: ErrInvalidRef},
+		{raw: "host:@", wantErr: ErrInvalidRef},
+		{raw: "/file/../etc", wantErr: ErrInvalidSegment},
+		{raw: "/file/./a", wantErr: ErrInvalidSegment},
+		{raw: "/file/a b", wantErr: ErrInvalidSegment},
+		{raw: "/file/a:b", wantErr: ErrInvalidSegment},
+		{raw: "/file?x=1", wantErr: ErrInvalidSegment},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.raw, func(t *testing.T) {
+			p, err := ParseMetaIDPath(tc.raw)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Host != tc.host {
+				t.Errorf("Host = %q, want %q", p.Host, tc.host)
+			}
+			if p.RefPinID != tc.ref {
+				t.Errorf("RefPinID = %q, want %q", p.RefPinID, tc.ref)
+			}
+			if p.IsReference() != (tc.ref != "") {
+				t.Errorf("IsReference = %v", p.IsReference())
+			}
+			if p.Path != tc.path {
+				t.Errorf("Path = %q, want %q", p.Path, tc.path)
+			}
+			if p.Normalized != tc.normalized {
+				t.Errorf("Normalized = %q, want %q", p.Normalized, tc.normalized)
+			}
+			if p.String() != tc.canonical {
+				t.Errorf("String() = %q, want %q", p.String(), tc.canonical)
+			}
+		})
+	}
+}
+
+func TestPathHasPrefix(t *testing.T) {
+	cases := []struct {
+		raw, prefix string
+		want        bool
+	}{
+		{"/file", "/file", true},
+		{"/file/a.png", "/file", true},
+		{"/FILE/a.png", "/file", true},
+		{"host:/file/a.png", "/file", true},
+		{"/files/a.png", "/file", false},
+		{"/filex", "/file", false},
+		{"/protocols/file", "/file", false},
+		{"/info/name", "/info/name", true},
+		{"/info/nameX", "/info/name", false},
+		{"/info/name/x", "/info/name", true},
+		{"/info", "/info/name", false},
+		{"/file", "", false},
+		{"@" + testPinID, "/file", false},
+		{"/file/../x", "/file", false},
+	}
+	for _, tc := range cases {
+		if got := PathHasPrefix(tc.raw, tc.prefix); got != tc.want {
+			t.Errorf("PathHasPrefix(%q, %q) = %v, want %v", tc.raw, tc.prefix, got, tc.want)
+		}
+	}
+}
+
+func TestPathContainsSegments(t *testing.T) {
+	cases := []struct {
+		raw, sub string
+		want     bool
+	}{
+		{"/file/_chunk", "/file/_chunk", true},
+		{"/file/file/_chunk", "/file/_chunk", true},
+		{"/app/File/_Chunk/1", "/file/_chunk", true},
+		{"/file/_chunkx", "/file/_chunk", false},
+		{"/myfile/_chunk", "/file/_chunk", false},
+		{"/file", "/file/_chunk", false},
+		{"/file/index", "/file/index", true},
+		{"/file/indexes", "/file/index", false},
+		{"/file", "", false},
+	}
+	for _, tc := range cases {
+		if got := PathContainsSegments(tc.raw, tc.sub); got != tc.want {
+			t.Errorf("PathContainsSegments(%q, %q) = %v, want %v", tc.raw, tc.sub, got, tc.want)
+		}
+	}
+}
+
+func TestIsProtocolPath(t *testing.T) {
+	cases := []struct {
+		path string
+		want bool
+	}{
+		{"/file", true},
+		{"/file/a.png", true},
+		{"/File/_chunk", true},
+		{"/info/name", true},
+		{"/info/avatar", true},
+		{"/info/bio", true},
+		{"/info/chatpubkey", true},
+		{"/filex", false},
+		{"/info/namex", false},
+		{"/protocols/simplebuzz", false},
+		{"", false},
+		{"@" + testPinID, false},
+	}
+	for _, tc := range cases {
+		if got := IsProtocolPath(tc.path); got != tc.want {
+			t.Errorf("IsProtocolPath(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeUploadPath(t *testing.T) {
+	got, err := NormalizeUploadPath("/file//photos/")
+	if err != nil || got != "/file/photos" {
+		t.Fatalf("NormalizeUploadPath = (%q, %v), want (/file/photos, nil)", got, err)
+	}
+	if _, err := NormalizeUploadPath("/file/../x"); err == nil {
+		t.Fatal("NormalizeUploadPath should reject traversal segments")
+	}
+}
@@ -9,20 +9,33 @@ import (
 *
 
 	{
+		"version": 2,//v2 only; absent in v1
 		"sha256": "",//总文件的hash
 		"fileSize": 202400,
 		"chunkNumber": 2,
 		"chunkSize": 102400,
 		"dataType": "text/plain",
 		"name": "",
+		"compression": "none",//v2: none or gzip (whole file, before chunking)
+		"encryption": {//v2, optional: file was encrypted before chunking
+			"algorithm": "aes-256-gcm",
+			"keyId": "",
+			"encryptedKey": "",
+			"iv": "",
+			"plainSha256": "",
+			"plainSize": 0
+		},
 		"chunkList": [
 			{
 				"sha256": "",//分片的hash
 				"pinId": "",//分片的pinId
+				"length": 102400//v2: 分片长度 (offset 0 omitted)
 			},
 			{
 				"sha256": "",//分片的hash
 				"pinId": "",//分片的pinId
+				"offset": 102400,//v2: 分片在文件中的偏移
+				"length": 100000
 			}
 		]
 	}
@@ -30,16 +43,48 @@ import (
 *
 */
 type MetaFileIndex struct {
-	Sha256      string `json:"sha256"`
-	FileSize    int64  `json:"fileSize"`
-	ChunkNumber int    `json:"chunkNumber"`
-	ChunkSize   int64  `json:"chunkSize"`
-	DataType    string `json:"dataType"`
-	Name        string `json:"name"`
-	ChunkList   []struct {
-		Sha256 string `json:"sha256"`
-		PinId  string `json:"pinId"`
-	} `json:"chunkList"`
+	Version     int                 `json:"version,omitempty"` // MetaFileIndexV2; absent (0) means v1
+	Sha256      string              `json:"sha256"`
+	FileSize    int64               `json:"fileSize"`
+	ChunkNumber int                 `json:"chunkNumber"`
+	ChunkSize   int64               `json:"chunkSize"`
+	DataType    string              `json:"dataType"`
+	Name        string              `json:"name"`
+	Compression string              `json:"compression,omitempty"` // v2: MetaFileCompression*
+	Encryption  *MetaFileEncryption `json:"encryption,omitempty"`  // v2: set when the chunks carry ciphertext
+	Delta       *MetaFileDelta      `json:"delta,omitempty"`       // v2: set when the chunks carry a delta against an earlier file
+	ChunkList   []MetaFileChunk     `json:"chunkList"`
+}
+
+// MetaFileChunk one chunkList entry of a MetaFileIndex
+type MetaFileChunk struct {
+	Sha256 string `json:"sha256"`
+	PinId  string `json:"pinId"`
+	Offset int64  `json:"offset,omitempty"` // v2: byte offset of the chunk in the file
+	Length int64  `json:"length,omitempty"` // v2: chunk length in bytes
+}
+
+// MetaFileEncryption envelope of an encrypted chunked file. The uploader only
+// records it; sha256, fileSize and the chunk ranges describe the ciphertext.
+type MetaFileEncryption struct {
+	Algorithm    string `json:"algorithm"`              // e.g. aes-256-gcm, aes-256-ctr
+	KeyId        string `json:"keyId,omitempty"`        // Identifier of the key (or recipient) the content key belongs to
+	EncryptedKey string `json:"encryptedKey,omitempty"` // Content key wrapped for the recipient (base64)
+	Iv           string `json:"iv,omitempty"`           // IV / nonce (base64 or hex, algorithm specific)
+	PlainSha256  string `json:"plainSha256,omitempty"`  // sha256 of the plaintext
+	PlainSize    int64  `json:"plainSize,omitempty"`    // Plaintext size in bytes
+}
+
+// MetaFileDelta delta envelope of a chunked file: the chunks carry a binary
+// delta against the content of an earlier PIN instead of the file itself.
+// sha256, fileSize and the chunk ranges describe the delta; the rebuilt file
+// is described here.
+type MetaFileDelta struct {
+	Algorithm    string `json:"algorithm"`    // MetaFileDeltaMfsV1
+	BasePinId    string `json:"basePinId"`    // PIN whose indexed content the delta applies to (may itself be a delta)
+	BaseSha256   string `json:"baseSha256"`   // sha256 of the base content
+	TargetSha256 string `json:"targetSha256"` // sha256 of the rebuilt file
+	TargetSize   int64  `json:"targetSize"`   // Rebuilt file size in bytes
 }
 
 // /file
@@ -71,24 +116,10 @@ var (
 	}
 )
 
-// IsProtocolPath checks if the given path is in the ProtocolList
+// IsProtocolPath checks if the given path should be indexed. By default that
+// is ProtocolList (matched case-insensitively on whole segments, so
+// "/file/chunk/123" matches "/file/chunk" but "/filex" does not match
+// "/file"); operators can narrow or widen it with SetPathFilter.
 func IsProtocolPath(path string) bool {
-	if path == "" {
-		return false
-	}
-
-	// Normalize path to lowercase for case-insensitive comparison
-	normalizedPath := strings.ToLower(path)
-
-	for _, protocol := range ProtocolList {
-		if normalizedPath == protocol {
-			return true
-		}
-		// Also check if path starts with the protocol (e.g., "/file/chunk/123" matches "/file/chunk")
-		if strings.HasPrefix(normalizedPath, protocol+"/") || strings.HasPrefix(normalizedPath, protocol) {
-			return true
-		}
-	}
-
-	return false
+	return activePathFilter().Allows(path)
 }
@@ -0,0 +1,137 @@
+package metaid_protocols
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SiteManifestV1 is the only site manifest version
+const SiteManifestV1 = 1
+
+// DefaultSiteIndex is the document served for the site root and for directories
+const DefaultSiteIndex = "index.html"
+
+// siteNotFoundPage is served with status 404 for unknown paths when present
+const siteNotFoundPage = "404.html"
+
+var ErrInvalidSiteManifest = errors.New("invalid site manifest")
+
+// SiteManifest maps the paths of a static website to the PINs holding each
+// file. It is an ordinary JSON file inscribed after the site's files:
+//
+//	{
+//	  "version": 1,
+//	  "index": "index.html",
+//	  "spa": true,
+//	  "files": {
+//	    "index.html": "{pinId}",
+//	    "assets/app.js": "{pinId}"
+//	  }
+//	}
+//
+// File paths are relative to the site root ("/" prefixes are ignored).
+type SiteManifest struct {
+	Version int               `json:"version"`         // SiteManifestV1
+	Index   string            `json:"index,omitempty"` // Directory index document (default index.html)
+	SPA     bool              `json:"spa,omitempty"`   // Serve the root index for unknown paths (single-page apps)
+	Files   map[string]string `json:"files"`           // Site path -> PIN ID
+}
+
+// SiteFile is the manifest entry a request path resolved to
+type SiteFile struct {
+	Path     string // Manifest path of the file
+	PinID    string // PIN holding its content
+	NotFound bool   // 404.html served for an unknown path (respond 404)
+	Fallback bool   // Root index served for an unknown path (SPA fallback)
+}
+
+// ParseSiteManifest decodes and validates a site manifest, normalizing its
+// paths and PIN IDs.
+func ParseSiteManifest(data []byte) (*SiteManifest, error) {
+	var raw SiteManifest
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSiteManifest, err)
+	}
+	if raw.Version != SiteManifestV1 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSiteManifest, raw.Version)
+	}
+	if len(raw.Files) == 0 {
+		return nil, fmt.Errorf("%w: no files", ErrInvalidSiteManifest)
+	}
+
+	m := &SiteManifest{Version: raw.Version, SPA: raw.SPA, Files: make(map[string]string, len(raw.Files))}
+	for p, pinID := range raw.Files {
+		clean, ok := cleanSitePath(p)
+		if !ok || clean == "" {
+			return nil, fmt.Errorf("%w: invalid file path %q", ErrInvalidSiteManifest, p)
+		}
+		pinID = strings.ToLower(strings.TrimSpace(pinID))
+		if !pinIDPattern.MatchString(pinID) {
+			return nil, fmt.Errorf("%w: invalid pinId for %q", ErrInvalidSiteManifest, p)
+		}
+		m.Files[clean] = pinID
+	}
+
+	m.Index = DefaultSiteIndex
+	if raw.Index != "" {
+		index, ok := cleanSitePath(raw.Index)
+		if !ok || index == "" || strings.Contains(index, "/") {
+			return nil, fmt.Errorf("%w: index must be a file name", ErrInvalidSiteManifest)
+		}
+		m.Index = index
+	}
+	if m.SPA {
+		if _, ok := m.Files[m.Index]; !ok {
+			return nil, fmt.Errorf("%w: spa needs %s at the site root", ErrInvalidSiteManifest, m.Index)
+		}
+	}
+	return m, nil
+}
+
+// cleanSitePath returns p relative to the site root with "." segments and
+// duplicate slashes removed; ok is false when p climbs out of the root.
+func cleanSitePath(p string) (string, bool) {
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return "", false
+		}
+	}
+	clean := strings.TrimPrefix(path.Clean("/"+p), "/")
+	return clean, true
+}
+
+// Resolve maps a request path to a manifest file:
+//
+//   - an exact file path
+//   - a directory ("" for the root) to its index document
+//   - with spa set, any other path to the root index (Fallback)
+//   - otherwise 404.html when the site has one (NotFound)
+//
+// isDir reports that the path names a directory whose index exists, so a
+// request without the trailing slash should be redirected to it. ok is false
+// when nothing matches.
+func (m *SiteManifest) Resolve(reqPath string) (file SiteFile, isDir bool, ok bool) {
+	clean, valid := cleanSitePath(reqPath)
+	if valid {
+		if pinID, found := m.Files[clean]; found && clean != "" {
+			return SiteFile{Path: clean, PinID: pinID}, false, true
+		}
+		index := m.Index
+		if clean != "" {
+			index = clean + "/" + m.Index
+		}
+		if pinID, found := m.Files[index]; found {
+			return SiteFile{Path: index, PinID: pinID}, clean != "", true
+		}
+	}
+	if m.SPA {
+		return SiteFile{Path: m.Index, PinID: m.Files[m.Index], Fallback: true}, false, true
+	}
+	if pinID, found := m.Files[siteNotFoundPage]; found {
+		return SiteFile{Path: siteNotFoundPage, PinID: pinID, NotFound: true}, false, true
+	}
+	return SiteFile{}, false, false
+}
@@ -0,0 +1,87 @@
+package metaid_protocols
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	sitePinA = strings.Repeat("a", 64) + "i0"
+	sitePinB = strings.Repeat("b", 64) + "i0"
+	sitePinC = strings.Repeat("c", 64) + "i1"
+	sitePinD = strings.Repeat("d", 64) + "i0"
+)
+
+func TestParseSiteManifest(t *testing.T) {
+	m, err := ParseSiteManifest([]byte(`{"version":1,"files":{"/index.html":"` + strings.ToUpper(sitePinA) + `","docs//guide/./index.html":"` + sitePinB + `"}}`))
+	if err != nil {
+		t.Fatalf("ParseSiteManifest: %v", err)
+	}
+	if m.Index != DefaultSiteIndex || m.Files["index.html"] != sitePinA || m.Files["docs/guide/index.html"] != sitePinB {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	for name, data := range map[string]string{
+		"not json":       `files`,
+		"version":        `{"version":2,"files":{"index.html":"` + sitePinA + `"}}`,
+		"no files":       `{"version":1,"files":{}}`,
+		"escaping path":  `{"version":1,"files":{"../etc/passwd":"` + sitePinA + `"}}`,
+		"root path":      `{"version":1,"files":{"/":"` + sitePinA + `"}}`,
+		"bad pinId":      `{"version":1,"files":{"index.html":"abc"}}`,
+		"nested index":   `{"version":1,"index":"a/b.html","files":{"a/b.html":"` + sitePinA + `"}}`,
+		"spa, no index":  `{"version":1,"spa":true,"files":{"app.js":"` + sitePinA + `"}}`,
+		"spa, own index": `{"version":1,"spa":true,"index":"main.html","files":{"index.html":"` + sitePinA + `"}}`,
+	} {
+		if _, err := ParseSiteManifest([]byte(data)); !errors.Is(err, ErrInvalidSiteManifest) {
+			t.Errorf("%s: err = %v, want ErrInvalidSiteManifest", name, err)
+		}
+	}
+}
+
+func TestSiteManifestResolve(t *testing.T) {
+	site := &SiteManifest{Version: 1, Index: DefaultSiteIndex, Files: map[string]string{
+		"index.html":      sitePinA,
+		"assets/app.js":   sitePinB,
+		"docs/index.html": sitePinC,
+	}}
+
+	tests := []struct {
+		path  string
+		pin   string
+		isDir bool
+		ok    bool
+	}{
+		{"", sitePinA, false, true},
+		{"/", sitePinA, false, true},
+		{"index.html", sitePinA, false, true},
+		{"/assets/app.js", sitePinB, false, true},
+		{"assets//./app.js", sitePinB, false, true},
+		{"docs/", sitePinC, true, true},
+		{"docs", sitePinC, true, true},
+		{"assets", "", false, false},
+		{"missing.css", "", false, false},
+		{"../index.html", "", false, false},
+	}
+	for _, tt := range tests {
+		file, isDir, ok := site.Resolve(tt.path)
+		if ok != tt.ok || file.PinID != tt.pin || isDir != tt.isDir {
+			t.Errorf("Resolve(%q) = %+v, %v, %v; want pin %q, isDir %v, ok %v", tt.path, file, isDir, ok, tt.pin, tt.isDir, tt.ok)
+		}
+	}
+
+	site.Files["404.html"] = sitePinD
+	if file, _, ok := site.Resolve("missing.css"); !ok || !file.NotFound || file.PinID != sitePinD {
+		t.Errorf("unknown path with 404.html = %+v, %v", file, ok)
+	}
+
+	site.SPA = true
+	for _, p := range []string{"dashboard/settings", "../x"} {
+		if file, _, ok := site.Resolve(p); !ok || !file.Fallback || file.PinID != sitePinA {
+			t.Errorf("spa fallback for %q = %+v, %v", p, file, ok)
+		}
+	}
+	if file, _, _ := site.Resolve("assets/app.js"); file.Fallback {
+		t.Error("existing file served as fallback")
+	}
+}
@@ -0,0 +1,280 @@
+package indexer_service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// Archive formats of WriteArchive
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+)
+
+// MaxArchiveFiles files one archive may hold
+const MaxArchiveFiles = 1000
+
+var (
+	// ErrInvalidArchive is returned (wrapped) for archive requests that cannot be served
+	ErrInvalidArchive = errors.New("invalid archive request")
+	// ErrArchiveFileNotFound is returned (wrapped) when a requested file is not indexed
+	ErrArchiveFileNotFound = errors.New("archive file not found")
+)
+
+// ArchiveEntry a file of an archive and the name it is stored under
+type ArchiveEntry struct {
+	Name string
+	File *model.IndexerFile
+}
+
+// ArchiveContentType the Content-Type of an archive format; ok is false for
+// unknown formats
+func ArchiveContentType(format string) (string, bool) {
+	switch format {
+	case ArchiveZip:
+		return "application/zip", true
+	case ArchiveTarGz:
+		return "application/gzip", true
+	}
+	return "", false
+}
+
+// ArchiveByPinIDs lists files by PIN ID, stored under their file names
+func (s *IndexerFileService) ArchiveByPinIDs(pinIDs []string) ([]ArchiveEntry, error) {
+	files, err := s.archiveFiles(pinIDs)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ArchiveEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, ArchiveEntry{Name: pathTreeFileName(file), File: file})
+	}
+	return checkArchiveEntries(uniqueArchiveNames(entries))
+}
+
+// ArchiveByManifest lists the files of a site manifest, stored under their
+// site paths
+func (s *IndexerFileService) ArchiveByManifest(manifestPinID string) ([]ArchiveEntry, error) {
+	manifest, err := s.GetSiteManifest(manifestPinID)
+	if err != nil {
+		return nil, err
+	}
+	pinIDs := make([]string, 0, len(manifest.Files))
+	for _, pinID := range manifest.Files {
+		pinIDs = append(pinIDs, pinID)
+	}
+	files, err := s.archiveFiles(pinIDs)
+	if err != nil {
+		return nil, err
+	}
+	byPinID := make(map[string]*model.IndexerFile, len(files))
+	for _, file := range files {
+		byPinID[file.PinID] = file
+	}
+
+	entries := make([]ArchiveEntry, 0, len(manifest.Files))
+	for sitePath, pinID := range manifest.Files {
+		entries = append(entries, ArchiveEntry{Name: sitePath, File: byPinID[pinID]})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return checkArchiveEntries(entries)
+}
+
+// ArchiveByPath lists the files of a creator under the MetaID directory dir,
+// stored under their path below dir as in GetPathTree
+func (s *IndexerFileService) ArchiveByPath(metaidOrGlobalMetaId, dir string) ([]ArchiveEntry, error) {
+	base, err := parseTreePath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	files, hasMore, err := s.creatorFilesUnder(metaidOrGlobalMetaId, base)
+	if err != nil {
+		return nil, err
+	}
+	if hasMore {
+		return nil, fmt.Errorf("%w: more than %d files under %s, archive a subdirectory", ErrInvalidArchive, MaxPathTreeFiles, dir)
+	}
+	entries := archivePathEntries(base, latestFiles(files))
+	if len(entries) > MaxArchiveFiles {
+		return nil, fmt.Errorf("%w: %d files, at most %d", ErrInvalidArchive, len(entries), MaxArchiveFiles)
+	}
+	return checkArchiveEntries(entries)
+}
+
+// checkArchiveEntries refuses an archive holding a provisional file under
+// indexer.confirmations.refuse_provisional, before any of it is written
+func checkArchiveEntries(entries []ArchiveEntry) ([]ArchiveEntry, error) {
+	for _, entry := range entries {
+		if err := CheckProvisionalContent(entry.File); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// archivePathEntries names files by their directory below base and file name
+func archivePathEntries(base *metaid_protocols.MetaIDPath, files []*model.IndexerFile) []ArchiveEntry {
+	depth := 0
+	if base != nil {
+		depth = len(base.Segments)
+	}
+	entries := make([]ArchiveEntry, 0, len(files))
+	for _, file := range files {
+		dirSegments, dirNames, ok := fileDirectory(file)
+		if !ok || len(dirSegments) < depth {
+			continue
+		}
+		name := path.Join(append(append([]string{}, dirNames[depth:]...), pathTreeFileName(file))...)
+		entries = append(entries, ArchiveEntry{Name: name, File: file})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return uniqueArchiveNames(entries)
+}
+
+// archiveFiles looks up the indexed files of pinIDs; every one must exist
+func (s *IndexerFileService) archiveFiles(pinIDs []string) ([]*model.IndexerFile, error) {
+	unique := make([]string, 0, len(pinIDs))
+	seen := make(map[string]struct{}, len(pinIDs))
+	for _, pinID := range pinIDs {
+		pinID = strings.TrimSpace(pinID)
+		if _, ok := seen[pinID]; pinID == "" || ok {
+			continue
+		}
+		seen[pinID] = struct{}{}
+		unique = append(unique, pinID)
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: no files", ErrInvalidArchive)
+	}
+	if len(unique) > MaxArchiveFiles {
+		return nil, fmt.Errorf("%w: %d files, at most %d", ErrInvalidArchive, len(unique), MaxArchiveFiles)
+	}
+
+	files := make([]*model.IndexerFile, 0, len(unique))
+	for start := 0; start < len(unique); start += MaxBatchPinIDs {
+		end := start + MaxBatchPinIDs
+		if end > len(unique) {
+			end = len(unique)
+		}
+		found, missing, err := s.GetFilesByPinIDs(unique[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrArchiveFileNotFound, strings.Join(missing, ", "))
+		}
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// uniqueArchiveNames renames the later of entries sharing a name to
+// "{pinId}_{name}" in the same directory
+func uniqueArchiveNames(entries []ArchiveEntry) []ArchiveEntry {
+	taken := make(map[string]struct{}, len(entries))
+	for i := range entries {
+		if _, ok := taken[entries[i].Name]; ok {
+			dir, name := path.Split(entries[i].Name)
+			entries[i].Name = dir + entries[i].File.PinID + "_" + name
+		}
+		taken[entries[i].Name] = struct{}{}
+	}
+	return entries
+}
+
+// WriteArchive streams entries to w as a zip or tar.gz archive. Contents are
+// read one file at a time, so only the file being written is held in memory.
+// An error part way leaves w with a truncated archive.
+func (s *IndexerFileService) WriteArchive(w io.Writer, format string, entries []ArchiveEntry) error {
+	read := func(file *model.IndexerFile) ([]byte, error) {
+		return s.readFile(file)
+	}
+	return writeArchive(w, format, entries, read)
+}
+
+func writeArchive(w io.Writer, format string, entries []ArchiveEntry, read func(*model.IndexerFile) ([]byte, error)) error {
+	switch format {
+	case ArchiveZip:
+		zw := zip.NewWriter(w)
+		for _, entry := range entries {
+			content, err := read(entry.File)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+			}
+			header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate, Modified: archiveModTime(entry.File)}
+			if precompressed(entry.File.ContentType) {
+				header.Method = zip.Store
+			}
+			fw, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			if _, err := fw.Write(content); err != nil {
+				return err
+			}
+		}
+		return zw.Close()
+
+	case ArchiveTarGz:
+		gz := gzip.NewWriter(w)
+		tw := tar.NewWriter(gz)
+		for _, entry := range entries {
+			content, err := read(entry.File)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+			}
+			header := &tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     entry.Name,
+				Mode:     0644,
+				Size:     int64(len(content)),
+				ModTime:  archiveModTime(entry.File),
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := tw.Write(content); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		return gz.Close()
+	}
+	return fmt.Errorf("%w: unknown format %q", ErrInvalidArchive, format)
+}
+
+func archiveModTime(file *model.IndexerFile) time.Time {
+	if file.Timestamp <= 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(file.Timestamp)
+}
+
+// precompressed reports content types deflate would not shrink
+func precompressed(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg"),
+		strings.HasPrefix(contentType, "video/"),
+		strings.HasPrefix(contentType, "audio/"):
+		return true
+	}
+	switch strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]) {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/x-7z-compressed", "application/x-rar-compressed", "application/zstd":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package indexer_service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+func TestArchivePathEntries(t *testing.T) {
+	base, err := metaid_protocols.ParseMetaIDPath("/file/photos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []*model.IndexerFile{
+		treeFile("p4", "p4", "/file/photos/2024/cat.png", "cat.png", 30),
+		treeFile("p3", "p3", "/file/photos/dog.png", "dog.png", 20),
+		treeFile("p2", "p2", "/file/photos", "dog.png", 10),
+		treeFile("p1", "p1", "/file/notes.txt", "notes.txt", 5),
+	}
+	entries := archivePathEntries(base, files)
+
+	want := []string{"2024/cat.png", "dog.png", "p2_dog.png"}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %v", entries, want)
+	}
+	for i, name := range want {
+		if entries[i].Name != name {
+			t.Errorf("entries[%d] = %q, want %q", i, entries[i].Name, name)
+		}
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	entries := []ArchiveEntry{
+		{Name: "a.txt", File: &model.IndexerFile{PinID: "p1", ContentType: "text/plain", Timestamp: 1700000000000}},
+		{Name: "dir/b.png", File: &model.IndexerFile{PinID: "p2", ContentType: "image/png"}},
+	}
+	content := map[string]string{"p1": "hello", "p2": "png bytes"}
+	read := func(file *model.IndexerFile) ([]byte, error) { return []byte(content[file.PinID]), nil }
+
+	var buf bytes.Buffer
+	if err := writeArchive(&buf, ArchiveZip, entries, read); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 2 {
+		t.Fatalf("zip holds %d files, want 2", len(zr.File))
+	}
+	if zr.File[1].Method != zip.Store {
+		t.Errorf("image stored with method %d, want store", zr.File[1].Method)
+	}
+	for i, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name != entries[i].Name || string(got) != content[entries[i].File.PinID] {
+			t.Errorf("zip file %d = %s %q", i, f.Name, got)
+		}
+	}
+
+	buf.Reset()
+	if err := writeArchive(&buf, ArchiveTarGz, entries, read); err != nil {
+		t.Fatalf("tar.gz: %v", err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for i := range entries {
+		header, err := tr.Next()
+		if err != nil {
+			t.Fatalf("tar entry %d: %v", i, err)
+		}
+		got, _ := io.ReadAll(tr)
+		if header.Name != entries[i].Name || string(got) != content[entries[i].File.PinID] {
+			t.Errorf("tar file %d = %s %q", i, header.Name, got)
+		}
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Errorf("tar has extra entries: %v", err)
+	}
+
+	if err := writeArchive(io.Discard, "rar", entries, read); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestArchiveRefusesProvisionalFiles(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	seedProvisionalPolicy(t)
+	confirmed := seedContentFile(t, s, stor, "confirmedi0", "old.txt", 100, "old")
+	provisional := seedContentFile(t, s, stor, "provisionali0", "new.txt", 199, "new")
+	fileService := NewIndexerFileService(stor)
+
+	if _, err := fileService.ArchiveByPinIDs([]string{confirmed.PinID, provisional.PinID}); !errors.Is(err, ErrProvisionalContent) {
+		t.Fatalf("archive with a provisional file: err = %v, want ErrProvisionalContent", err)
+	}
+
+	entries, err := fileService.ArchiveByPinIDs([]string{confirmed.PinID})
+	if err != nil {
+		t.Fatalf("archive of a confirmed file: %v", err)
+	}
+	if err := fileService.WriteArchive(io.Discard, ArchiveZip, entries); err != nil {
+		t.Errorf("WriteArchive of a confirmed file: %v", err)
+	}
+
+	// Entries listed before the policy applied are refused while writing
+	entries = append(entries, ArchiveEntry{Name: "new.txt", File: provisional})
+	if err := fileService.WriteArchive(io.Discard, ArchiveZip, entries); !errors.Is(err, ErrProvisionalContent) {
+		t.Errorf("WriteArchive with a provisional file: err = %v, want ErrProvisionalContent", err)
+	}
+}
@@ -0,0 +1,212 @@
+package indexer_service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // register GIF for image.Decode/DecodeConfig
+	"image/jpeg"
+	"image/png"
+
+	"meta-file-system/conf"
+)
+
+// Avatar limits used when indexer.avatar_* is not configured
+const (
+	DefaultAvatarMaxSizeKB    = 2048
+	DefaultAvatarMaxDimension = 2048
+
+	// maxAvatarDecodePixels caps the images that are fully decoded (to verify or
+	// downscale them) so a tiny compressed payload cannot force a huge allocation;
+	// larger ones are rejected from their header alone
+	maxAvatarDecodePixels = 16 * 1000 * 1000
+)
+
+// AvatarLimits size and dimension limits applied to avatar PINs at index time
+type AvatarLimits struct {
+	MaxBytes     int64 // Max stored avatar size in bytes
+	MaxDimension int   // Max width/height in pixels
+	Downscale    bool  // Downscale oversized JPEG/PNG avatars instead of rejecting them
+}
+
+// avatarLimits returns the configured avatar limits, falling back to the defaults
+func avatarLimits() AvatarLimits {
+	limits := AvatarLimits{
+		MaxBytes:     DefaultAvatarMaxSizeKB * 1024,
+		MaxDimension: DefaultAvatarMaxDimension,
+	}
+	if conf.Cfg == nil {
+		return limits
+	}
+	if conf.Cfg.Indexer.AvatarMaxSizeKB > 0 {
+		limits.MaxBytes = int64(conf.Cfg.Indexer.AvatarMaxSizeKB) * 1024
+	}
+	if conf.Cfg.Indexer.AvatarMaxDimension > 0 {
+		limits.MaxDimension = conf.Cfg.Indexer.AvatarMaxDimension
+	}
+	limits.Downscale = conf.Cfg.Indexer.AvatarDownscale
+	return limits
+}
+
+// AvatarCheck result of validating an avatar PIN
+type AvatarCheck struct {
+	Content     []byte // Content to store (downscaled when Downscaled is set)
+	ContentType string // Detected image content type, e.g. image/png
+	Width       int
+	Height      int
+	Downscaled  bool
+	Invalid     bool   // Content is not a usable avatar; clients should fall back to a default
+	Reason      string // Why the avatar is invalid
+}
+
+func invalidAvatar(content []byte, reason string, args ...interface{}) AvatarCheck {
+	return AvatarCheck{Content: content, Invalid: true, Reason: fmt.Sprintf(reason, args...)}
+}
+
+// validateAvatar checks that content is a decodable JPEG/PNG/GIF/WebP image within
+// limits. Oversized JPEG/PNG avatars are downscaled when limits.Downscale is set;
+// anything else that fails is returned with Invalid set rather than an error, so
+// the PIN is still recorded.
+func validateAvatar(content []byte, limits AvatarLimits) AvatarCheck {
+	if len(content) == 0 {
+		return invalidAvatar(content, "empty content")
+	}
+
+	width, height, format, err := avatarImageConfig(content)
+	if err != nil {
+		return invalidAvatar(content, "not a decodable image: %v", err)
+	}
+	check := AvatarCheck{
+		Content:     content,
+		ContentType: "image/" + format,
+		Width:       width,
+		Height:      height,
+	}
+
+	// Reject from the header what will not be accepted anyway, before decoding
+	oversized := width > limits.MaxDimension || height > limits.MaxDimension
+	if oversized && (!limits.Downscale || (format != "jpeg" && format != "png")) {
+		return invalidAvatar(content, "dimensions %dx%d exceed max %d", width, height, limits.MaxDimension)
+	}
+	if width*height > maxAvatarDecodePixels {
+		return invalidAvatar(content, "dimensions %dx%d too large to decode", width, height)
+	}
+
+	// Header-only checks are not enough for formats we can fully decode
+	var img image.Image
+	if format != "webp" {
+		if img, _, err = image.Decode(bytes.NewReader(content)); err != nil {
+			return invalidAvatar(content, "corrupt %s image: %v", format, err)
+		}
+	}
+
+	if oversized {
+		scaled, w, h, err := encodeAvatar(boxDownscale(img, limits.MaxDimension), format)
+		if err != nil {
+			return invalidAvatar(content, "failed to downscale: %v", err)
+		}
+		check.Content, check.Width, check.Height, check.Downscaled = scaled, w, h, true
+	}
+
+	if int64(len(check.Content)) > limits.MaxBytes {
+		return invalidAvatar(content, "size %d bytes exceeds max %d", len(check.Content), limits.MaxBytes)
+	}
+	return check
+}
+
+// avatarImageConfig returns the dimensions and format of an image from its header
+func avatarImageConfig(content []byte) (int, int, string, error) {
+	if w, h, ok := webpDimensions(content); ok {
+		return w, h, "webp", nil
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return 0, 0, "", fmt.Errorf("invalid dimensions %dx%d", cfg.Width, cfg.Height)
+	}
+	return cfg.Width, cfg.Height, format, nil
+}
+
+// webpDimensions reads the canvas size from a WebP header (VP8, VP8L or VP8X);
+// the standard library has no WebP decoder.
+func webpDimensions(b []byte) (int, int, bool) {
+	if len(b) < 30 || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	switch string(b[12:16]) {
+	case "VP8 ":
+		// Key frame start code, then 14-bit width/height
+		if b[23] != 0x9d || b[24] != 0x01 || b[25] != 0x2a {
+			return 0, 0, false
+		}
+		w := int(binary.LittleEndian.Uint16(b[26:28]) & 0x3fff)
+		h := int(binary.LittleEndian.Uint16(b[28:30]) & 0x3fff)
+		return w, h, w > 0 && h > 0
+	case "VP8L":
+		if b[20] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(b[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8X":
+		w := int(uint32(b[24])|uint32(b[25])<<8|uint32(b[26])<<16) + 1
+		h := int(uint32(b[27])|uint32(b[28])<<8|uint32(b[29])<<16) + 1
+		return w, h, true
+	}
+	return 0, 0, false
+}
+
+// encodeAvatar re-encodes a downscaled avatar in its original JPEG/PNG format
+func encodeAvatar(dst image.Image, format string) ([]byte, int, int, error) {
+	var err error
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 90})
+	case "png":
+		err = png.Encode(&buf, dst)
+	default:
+		err = fmt.Errorf("cannot re-encode %s", format)
+	}
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), dst.Bounds().Dx(), dst.Bounds().Dy(), nil
+}
+
+// boxDownscale averages source pixels into a maxDimension-bounded image
+func boxDownscale(src image.Image, maxDimension int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	longest := max(w, h)
+	if longest <= maxDimension {
+		return src
+	}
+	nw := max(1, w*maxDimension/longest)
+	nh := max(1, h*maxDimension/longest)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0, y1 := b.Min.Y+y*h/nh, b.Min.Y+(y+1)*h/nh
+		for x := 0; x < nw; x++ {
+			x0, x1 := b.Min.X+x*w/nw, b.Min.X+(x+1)*w/nw
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,116 @@
+package indexer_service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func testJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// testPNGHeader returns a PNG whose IHDR claims w x h but that has no image data
+func testPNGHeader(t *testing.T, w, h int) []byte {
+	t.Helper()
+	b := testPNG(t, 1, 1)[:33] // Signature + IHDR chunk
+	binary.BigEndian.PutUint32(b[16:20], uint32(w))
+	binary.BigEndian.PutUint32(b[20:24], uint32(h))
+	binary.BigEndian.PutUint32(b[29:33], crc32.ChecksumIEEE(b[12:29]))
+	return b
+}
+
+// testWebPVP8X builds a minimal extended WebP header with the given canvas size
+func testWebPVP8X(w, h int) []byte {
+	b := make([]byte, 30)
+	copy(b[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(b[4:8], 22)
+	copy(b[8:12], "WEBP")
+	copy(b[12:16], "VP8X")
+	binary.LittleEndian.PutUint32(b[16:20], 10)
+	w, h = w-1, h-1
+	b[24], b[25], b[26] = byte(w), byte(w>>8), byte(w>>16)
+	b[27], b[28], b[29] = byte(h), byte(h>>8), byte(h>>16)
+	return b
+}
+
+func TestValidateAvatar(t *testing.T) {
+	limits := AvatarLimits{MaxBytes: 64 * 1024, MaxDimension: 64}
+	pngTruncated := testPNG(t, 32, 32)
+	pngTruncated = pngTruncated[:len(pngTruncated)-20]
+
+	cases := []struct {
+		name        string
+		content     []byte
+		limits      AvatarLimits
+		wantInvalid bool
+		wantType    string // Content type, or a substring of the reason when invalid
+		wantW       int
+		wantH       int
+		wantScaled  bool
+	}{
+		{"png ok", testPNG(t, 32, 16), limits, false, "image/png", 32, 16, false},
+		{"jpeg ok", testJPEG(t, 40, 40), limits, false, "image/jpeg", 40, 40, false},
+		{"webp ok", testWebPVP8X(48, 48), limits, false, "image/webp", 48, 48, false},
+		{"empty", nil, limits, true, "", 0, 0, false},
+		{"not an image", []byte("hello, I am a text avatar"), limits, true, "", 0, 0, false},
+		{"truncated png", pngTruncated, limits, true, "", 0, 0, false},
+		{"too large no downscale", testPNG(t, 128, 32), limits, true, "", 0, 0, false},
+		{"webp too large", testWebPVP8X(4000, 10), AvatarLimits{MaxBytes: 1024, MaxDimension: 64, Downscale: true}, true, "", 0, 0, false},
+		{"png downscaled", testPNG(t, 128, 32), AvatarLimits{MaxBytes: 64 * 1024, MaxDimension: 64, Downscale: true}, false, "image/png", 64, 16, true},
+		{"jpeg downscaled", testJPEG(t, 50, 200), AvatarLimits{MaxBytes: 64 * 1024, MaxDimension: 64, Downscale: true}, false, "image/jpeg", 16, 64, true},
+		{"huge png no downscale", testPNGHeader(t, 6000, 6000), limits, true, "exceed max", 0, 0, false},
+		{"huge png downscale", testPNGHeader(t, 6000, 6000), AvatarLimits{MaxBytes: 64 * 1024, MaxDimension: 64, Downscale: true}, true, "too large to decode", 0, 0, false},
+		{"bytes over limit", testPNG(t, 32, 32), AvatarLimits{MaxBytes: 100, MaxDimension: 64}, true, "", 0, 0, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := validateAvatar(tc.content, tc.limits)
+			if got.Invalid != tc.wantInvalid {
+				t.Fatalf("Invalid = %v (%s), want %v", got.Invalid, got.Reason, tc.wantInvalid)
+			}
+			if tc.wantInvalid {
+				if got.Reason == "" || !strings.Contains(got.Reason, tc.wantType) {
+					t.Errorf("Reason = %q, want it to mention %q", got.Reason, tc.wantType)
+				}
+				return
+			}
+			if got.ContentType != tc.wantType || got.Width != tc.wantW || got.Height != tc.wantH || got.Downscaled != tc.wantScaled {
+				t.Errorf("got %s %dx%d downscaled=%v, want %s %dx%d downscaled=%v",
+					got.ContentType, got.Width, got.Height, got.Downscaled, tc.wantType, tc.wantW, tc.wantH, tc.wantScaled)
+			}
+			if tc.wantScaled {
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(got.Content))
+				if err != nil || cfg.Width != tc.wantW || cfg.Height != tc.wantH {
+					t.Errorf("downscaled content = %dx%d (%v), want %dx%d", cfg.Width, cfg.Height, err, tc.wantW, tc.wantH)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package indexer_service
+
+import (
+	"errors"
+	"log"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// blockPipelineConfig the indexer.pipeline stage settings
+func blockPipelineConfig() indexer.PipelineConfig {
+	cfg := conf.Cfg.Indexer.Pipeline
+	return indexer.PipelineConfig{
+		FetchWorkers:   cfg.FetchWorkers,
+		ParseWorkers:   cfg.ParseWorkers,
+		ResolveWorkers: cfg.ResolveWorkers,
+		QueueSize:      cfg.QueueSize,
+	}
+}
+
+// creatorResolver the resolve stage of the block pipeline for chainType: it
+// looks up the address a PIN's creator input spends and stores it as the
+// creator address, so the persist stage (handleTransaction) does not wait on
+// the node. A failed lookup keeps the fallback address and marks the data
+// CreatorResolutionPending, with the input locations kept for the creator
+// retrier; the process functions then skip the lookup. Chunks and creates
+// outside the indexed protocols are left alone; they never use the creator
+// address.
+func (s *IndexerService) creatorResolver(chainType indexer.ChainType) func(data *indexer.MetaIDData) {
+	return func(data *indexer.MetaIDData) {
+		if isChunkPath(data.Path) || (data.Operation == "create" && !metaid_protocols.IsProtocolPath(data.Path)) {
+			return
+		}
+		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(data.CreatorInputLocation, data.CreatorInputTxVinLocation, chainType)
+		if err != nil {
+			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
+				data.CreatorInputLocation, err)
+			if !errors.Is(err, indexer.ErrNoBlockScanner) {
+				data.CreatorResolutionPending = true
+				return
+			}
+		} else {
+			data.CreatorAddress = realAddress
+		}
+		data.CreatorInputLocation = ""
+		data.CreatorInputTxVinLocation = ""
+	}
+}
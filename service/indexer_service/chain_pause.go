@@ -0,0 +1,125 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reasons scanning of a chain is paused (PauseReason)
+const (
+	PauseReasonOperator  = "operator"   // POST /admin/chains/{chain}/pause
+	PauseReasonDiskSpace = "disk_space" // indexer.disk_watchdog
+)
+
+// ErrUnknownChain the chain is not indexed by this indexer
+var ErrUnknownChain = errors.New("chain is not indexed")
+
+// chainPauses chains whose scanning an operator paused, with the time of the
+// pause. Pauses are kept in memory: a restarted indexer scans every chain.
+type chainPauses struct {
+	mu    sync.RWMutex
+	since map[string]time.Time
+}
+
+func (p *chainPauses) paused(chainName string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.since[chainName]
+	return ok
+}
+
+// pausedAt the time chainName was paused; ok is false when it is not
+func (p *chainPauses) pausedAt(chainName string) (since time.Time, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	since, ok = p.since[chainName]
+	return since, ok
+}
+
+// set pauses or resumes chainName and reports whether that changed anything
+func (p *chainPauses) set(chainName string, paused bool, now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.since[chainName]; ok == paused {
+		return false
+	}
+	if !paused {
+		delete(p.since, chainName)
+		return true
+	}
+	if p.since == nil {
+		p.since = make(map[string]time.Time)
+	}
+	p.since[chainName] = now
+	return true
+}
+
+// scanningPaused reports whether new blocks and mempool transactions of
+// chainName wait: while disk space is low or an operator paused the chain
+func (s *IndexerService) scanningPaused(chainName string) bool {
+	if chainName == "" {
+		chainName = string(s.chainType)
+	}
+	return s.diskWatchdog.paused() || s.chainPauses.paused(chainName)
+}
+
+// PauseReason why scanning of chainName is paused: PauseReasonOperator,
+// PauseReasonDiskSpace or "" when it is not
+func (s *IndexerService) PauseReason(chainName string) string {
+	if s.chainPauses.paused(chainName) {
+		return PauseReasonOperator
+	}
+	if s.diskWatchdog.paused() {
+		return PauseReasonDiskSpace
+	}
+	return ""
+}
+
+// ChainPausedAt the time an operator paused chainName; ok is false when it is
+// not paused by an operator
+func (s *IndexerService) ChainPausedAt(chainName string) (since time.Time, ok bool) {
+	return s.chainPauses.pausedAt(chainName)
+}
+
+// PauseChain stops scanning new blocks and mempool transactions of chainName
+// until ResumeChain. The block being scanned is finished first; rescans are
+// not paused. Pausing a paused chain is a no-op.
+func (s *IndexerService) PauseChain(chainName string) error {
+	if err := s.checkIndexedChain(chainName); err != nil {
+		return err
+	}
+	if s.chainPauses.set(chainName, true, time.Now()) {
+		log.Printf("[%s] ⏸️  Scanning paused by operator", chainName)
+	}
+	return nil
+}
+
+// ResumeChain resumes scanning of chainName after PauseChain. Mempool
+// transactions seen while paused are indexed with their blocks.
+func (s *IndexerService) ResumeChain(chainName string) error {
+	if err := s.checkIndexedChain(chainName); err != nil {
+		return err
+	}
+	if s.chainPauses.set(chainName, false, time.Now()) {
+		log.Printf("[%s] ▶️  Scanning resumed by operator", chainName)
+	}
+	return nil
+}
+
+// checkIndexedChain returns ErrUnknownChain unless chainName has a scanner
+func (s *IndexerService) checkIndexedChain(chainName string) error {
+	scanners := s.watchedScanners()
+	if _, ok := scanners[chainName]; ok {
+		return nil
+	}
+	chains := make([]string, 0, len(scanners))
+	for name := range scanners {
+		chains = append(chains, name)
+	}
+	sort.Strings(chains)
+	return fmt.Errorf("%w: %s (indexed: %v)", ErrUnknownChain, chainName, chains)
+}
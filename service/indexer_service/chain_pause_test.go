@@ -0,0 +1,42 @@
+package indexer_service
+
+import (
+	"errors"
+	"testing"
+
+	"meta-file-system/indexer"
+)
+
+func TestPauseChain(t *testing.T) {
+	s := &IndexerService{chainType: indexer.ChainTypeMVC, scanner: &indexer.BlockScanner{}}
+
+	if err := s.PauseChain("btc"); !errors.Is(err, ErrUnknownChain) {
+		t.Fatalf("PauseChain(btc) = %v, want ErrUnknownChain", err)
+	}
+	if err := s.PauseChain("mvc"); err != nil {
+		t.Fatal(err)
+	}
+	since, ok := s.ChainPausedAt("mvc")
+	if !ok || !s.scanningPaused("mvc") || !s.scanningPaused("") || s.PauseReason("mvc") != PauseReasonOperator {
+		t.Fatalf("mvc not paused: ok=%v reason=%q", ok, s.PauseReason("mvc"))
+	}
+	if err := s.PauseChain("mvc"); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.ChainPausedAt("mvc"); !again.Equal(since) {
+		t.Errorf("pausing again moved the pause time from %v to %v", since, again)
+	}
+
+	if err := s.ResumeChain("mvc"); err != nil {
+		t.Fatal(err)
+	}
+	if s.scanningPaused("mvc") || s.PauseReason("mvc") != "" {
+		t.Errorf("mvc still paused after resume: %q", s.PauseReason("mvc"))
+	}
+
+	s.diskWatchdog = newDiskWatchdog(nil, 0, 0)
+	s.diskWatchdog.low.Store(true)
+	if !s.scanningPaused("mvc") || s.PauseReason("mvc") != PauseReasonDiskSpace {
+		t.Errorf("disk watchdog pause not reported: %q", s.PauseReason("mvc"))
+	}
+}
@@ -0,0 +1,107 @@
+package indexer_service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+)
+
+// Change feed page sizes
+const (
+	DefaultChangeEvents = 100
+	MaxChangeEvents     = 1000
+)
+
+// ErrInvalidChangeCursor is returned for a negative since cursor
+var ErrInvalidChangeCursor = errors.New("since must be a non-negative sequence number")
+
+// ListChanges lists change log events with Seq > since in ascending Seq order.
+// nextSince is the Seq of the last event returned (since when there is none),
+// so consumers resume with since = nextSince.
+func (s *IndexerFileService) ListChanges(since int64, limit int) ([]*model.IndexerChangeEvent, int64, bool, error) {
+	if since < 0 {
+		return nil, 0, false, ErrInvalidChangeCursor
+	}
+	if limit < 1 || limit > MaxChangeEvents {
+		limit = DefaultChangeEvents
+	}
+
+	events, err := database.DB.ListChangeEvents(since, limit+1)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+	nextSince := since
+	if len(events) > 0 {
+		nextSince = events[len(events)-1].Seq
+	}
+	return events, nextSince, hasMore, nil
+}
+
+// recordChange appends an indexing action to the change log, with record (the
+// record as written) as its payload. Failures are only logged: the change log
+// never blocks indexing.
+func recordChange(event *model.IndexerChangeEvent, record interface{}) {
+	if database.DB == nil {
+		return
+	}
+	if record != nil {
+		data, err := json.Marshal(record)
+		if err != nil {
+			log.Printf("Failed to encode %s change for PIN %s: %v", event.Action, event.PinID, err)
+			return
+		}
+		event.Record = data
+	}
+	if err := database.DB.AppendChangeEvent(event); err != nil {
+		log.Printf("Failed to record %s change for PIN %s: %v", event.Action, event.PinID, err)
+	}
+}
+
+// recordFileChange records a file write; action defaults to the file's operation
+func recordFileChange(action model.ChangeAction, file *model.IndexerFile) {
+	if action == "" {
+		action = fileChangeAction(file.Operation)
+	}
+	recordChange(&model.IndexerChangeEvent{
+		Action:      action,
+		ChainName:   file.ChainName,
+		PinID:       file.PinID,
+		FirstPinID:  file.FirstPinID,
+		MetaId:      file.CreatorMetaId,
+		BlockHeight: file.BlockHeight,
+		Timestamp:   file.Timestamp,
+	}, file)
+}
+
+// fileChangeAction maps a file PIN operation to its change log action
+func fileChangeAction(operation string) model.ChangeAction {
+	switch operation {
+	case "modify":
+		return model.ChangeFileModified
+	case "revoke":
+		return model.ChangeFileRevoked
+	default:
+		return model.ChangeFileCreated
+	}
+}
+
+// recordUserInfoChange records a write of the latest user info of metaID
+func recordUserInfoChange(action model.ChangeAction, metaData *indexer.MetaIDData, firstPinID, metaID string, height, timestamp int64, info interface{}) {
+	recordChange(&model.IndexerChangeEvent{
+		Action:      action,
+		ChainName:   metaData.ChainName,
+		PinID:       metaData.PinID,
+		FirstPinID:  firstPinID,
+		MetaId:      metaID,
+		BlockHeight: height,
+		Timestamp:   timestamp,
+	}, info)
+}
@@ -0,0 +1,50 @@
+package indexer_service
+
+import (
+	"errors"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestRecordFileChangeAndListChanges(t *testing.T) {
+	setTestPebble(t)
+
+	for _, op := range []string{"create", "modify", "revoke"} {
+		recordFileChange("", &model.IndexerFile{PinID: op + "i0", FirstPinID: "createi0", Operation: op, ChainName: "mvc"})
+	}
+	recordFileChange(model.ChangeFileConfirmed, &model.IndexerFile{PinID: "createi0", BlockHeight: 10})
+
+	s := &IndexerFileService{}
+	events, nextSince, hasMore, err := s.ListChanges(0, 3)
+	if err != nil {
+		t.Fatalf("ListChanges: %v", err)
+	}
+	want := []model.ChangeAction{model.ChangeFileCreated, model.ChangeFileModified, model.ChangeFileRevoked}
+	if len(events) != len(want) || nextSince != 3 || !hasMore {
+		t.Fatalf("page 1 = %d events, next %d, hasMore %v", len(events), nextSince, hasMore)
+	}
+	for i, action := range want {
+		if events[i].Action != action || events[i].Seq != int64(i+1) {
+			t.Errorf("events[%d] = %s/%d, want %s/%d", i, events[i].Action, events[i].Seq, action, i+1)
+		}
+	}
+	if len(events[1].Record) == 0 {
+		t.Error("file change without record")
+	}
+
+	events, nextSince, hasMore, err = s.ListChanges(nextSince, 3)
+	if err != nil {
+		t.Fatalf("ListChanges: %v", err)
+	}
+	if len(events) != 1 || events[0].Action != model.ChangeFileConfirmed || events[0].BlockHeight != 10 || nextSince != 4 || hasMore {
+		t.Fatalf("page 2 = %+v, next %d, hasMore %v", events, nextSince, hasMore)
+	}
+
+	if events, nextSince, _, _ = s.ListChanges(4, 3); len(events) != 0 || nextSince != 4 {
+		t.Errorf("after last = %d events, next %d", len(events), nextSince)
+	}
+	if _, _, _, err := s.ListChanges(-1, 3); !errors.Is(err, ErrInvalidChangeCursor) {
+		t.Errorf("negative since = %v, want ErrInvalidChangeCursor", err)
+	}
+}
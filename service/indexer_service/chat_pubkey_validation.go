@@ -0,0 +1,82 @@
+package indexer_service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// Chat public key types and encodings recorded on UserChatPublicKeyInfo
+const (
+	ChatKeyTypeSecp256k1 = "secp256k1"
+	ChatKeyTypeX25519    = "x25519"
+
+	ChatKeyEncodingHex    = "hex"
+	ChatKeyEncodingBase64 = "base64"
+)
+
+// ChatPublicKeyCheck result of validating a chat public key PIN
+type ChatPublicKeyCheck struct {
+	PublicKey string // Trimmed key text as published
+	KeyType   string // secp256k1 / x25519
+	Encoding  string // hex / base64
+	Invalid   bool   // Not a usable key; downstream E2E clients must not use it
+	Reason    string // Why the key is invalid
+}
+
+// validateChatPublicKey checks that content is a hex or base64 encoded
+// secp256k1 public key (33-byte compressed, or 65-byte uncompressed as
+// published by older clients) or a 32-byte X25519 key. Garbage is returned
+// with Invalid set rather than an error, so the PIN is still recorded.
+func validateChatPublicKey(content []byte) ChatPublicKeyCheck {
+	text := strings.TrimSpace(string(content))
+	check := ChatPublicKeyCheck{PublicKey: text}
+	if text == "" {
+		check.Invalid, check.Reason = true, "empty content"
+		return check
+	}
+
+	raw, encoding, err := decodeChatPublicKey(text)
+	if err != nil {
+		check.Invalid, check.Reason = true, err.Error()
+		return check
+	}
+	check.Encoding = encoding
+
+	switch len(raw) {
+	case 33, 65:
+		if _, err := btcec.ParsePubKey(raw); err != nil {
+			check.Invalid, check.Reason = true, fmt.Sprintf("invalid secp256k1 public key: %v", err)
+			return check
+		}
+		check.KeyType = ChatKeyTypeSecp256k1
+	case 32:
+		if bytes.Equal(raw, make([]byte, 32)) {
+			check.Invalid, check.Reason = true, "invalid x25519 public key: all zero"
+			return check
+		}
+		check.KeyType = ChatKeyTypeX25519
+	default:
+		check.Invalid, check.Reason = true, fmt.Sprintf("unexpected public key length %d bytes", len(raw))
+	}
+	return check
+}
+
+// decodeChatPublicKey decodes hex (optionally 0x-prefixed) or base64 (standard
+// or URL alphabet, padded or not) key text
+func decodeChatPublicKey(text string) ([]byte, string, error) {
+	hexText := strings.TrimPrefix(strings.TrimPrefix(text, "0x"), "0X")
+	if raw, err := hex.DecodeString(hexText); err == nil {
+		return raw, ChatKeyEncodingHex, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if raw, err := enc.DecodeString(text); err == nil {
+			return raw, ChatKeyEncodingBase64, nil
+		}
+	}
+	return nil, "", fmt.Errorf("public key is neither hex nor base64")
+}
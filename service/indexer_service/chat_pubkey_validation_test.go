@@ -0,0 +1,64 @@
+package indexer_service
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+func TestValidateChatPublicKey(t *testing.T) {
+	priv, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("NewPrivateKey: %v", err)
+	}
+	compressed := priv.PubKey().SerializeCompressed()
+	uncompressed := priv.PubKey().SerializeUncompressed()
+	x25519 := make([]byte, 32)
+	x25519[0] = 9
+
+	offCurve := append([]byte(nil), compressed...)
+	offCurve[0] = 0x05
+
+	cases := []struct {
+		name         string
+		content      string
+		wantInvalid  bool
+		wantType     string
+		wantEncoding string
+	}{
+		{"compressed hex", hex.EncodeToString(compressed), false, ChatKeyTypeSecp256k1, ChatKeyEncodingHex},
+		{"compressed hex padded", "  0x" + hex.EncodeToString(compressed) + "\n", false, ChatKeyTypeSecp256k1, ChatKeyEncodingHex},
+		{"uncompressed hex", strings.ToUpper(hex.EncodeToString(uncompressed)), false, ChatKeyTypeSecp256k1, ChatKeyEncodingHex},
+		{"compressed base64", base64.StdEncoding.EncodeToString(compressed), false, ChatKeyTypeSecp256k1, ChatKeyEncodingBase64},
+		{"x25519 base64", base64.StdEncoding.EncodeToString(x25519), false, ChatKeyTypeX25519, ChatKeyEncodingBase64},
+		{"x25519 hex", hex.EncodeToString(x25519), false, ChatKeyTypeX25519, ChatKeyEncodingHex},
+		{"empty", "   ", true, "", ""},
+		{"garbage", "hello world!", true, "", ""},
+		{"bad prefix", hex.EncodeToString(offCurve), true, "", ""},
+		{"wrong length", hex.EncodeToString(compressed[:20]), true, "", ""},
+		{"zero x25519", hex.EncodeToString(make([]byte, 32)), true, "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := validateChatPublicKey([]byte(tc.content))
+			if got.Invalid != tc.wantInvalid {
+				t.Fatalf("Invalid = %v (%s), want %v", got.Invalid, got.Reason, tc.wantInvalid)
+			}
+			if tc.wantInvalid {
+				if got.Reason == "" {
+					t.Error("invalid key must have a reason")
+				}
+				return
+			}
+			if got.KeyType != tc.wantType || got.Encoding != tc.wantEncoding {
+				t.Errorf("got %s/%s, want %s/%s", got.KeyType, got.Encoding, tc.wantType, tc.wantEncoding)
+			}
+			if got.PublicKey != strings.TrimSpace(tc.content) {
+				t.Errorf("PublicKey = %q, want trimmed content", got.PublicKey)
+			}
+		})
+	}
+}
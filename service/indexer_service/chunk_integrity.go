@@ -0,0 +1,208 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ChunkHashMismatchError is returned by the merge path when a stored chunk
+// does not match the sha256 listed for it in the index chunkList, even after
+// re-fetching the chunk from the chain. The merge is refused.
+type ChunkHashMismatchError struct {
+	ChunkIndex int
+	PinID      string
+	Expected   string
+	Got        string
+}
+
+func (e *ChunkHashMismatchError) Error() string {
+	return fmt.Sprintf("chunk %d (PIN=%s) sha256 mismatch: expected %s, got %s",
+		e.ChunkIndex, e.PinID, e.Expected, e.Got)
+}
+
+// resolveIndexChunk finds the indexed chunk referenced by an index chunkList
+// entry. It looks up the referenced PinID first; when that PIN was never
+// indexed (e.g. the user retried the chunk upload and the index points at a
+// different inscription of the same data) it falls back to an indexed chunk
+// with the same content sha256 that is not attached to a file yet, already
+// belongs to this index, or belongs to a file of the same creator. Chunks of
+// other creators' files are never used. Returns nil when none is available.
+func (s *IndexerService) resolveIndexChunk(pinID, sha256, indexPinID, creatorMetaID string) *model.IndexerFileChunk {
+	chunk, err := s.indexerFileChunkDAO.GetByPinID(pinID)
+	if err == nil && chunk != nil {
+		return chunk
+	}
+	if sha256 == "" {
+		return nil
+	}
+
+	candidates, err := s.indexerFileChunkDAO.ListBySha256(strings.ToLower(sha256))
+	if err != nil {
+		log.Printf("Failed to look up chunks by SHA256=%s: %v", sha256, err)
+		return nil
+	}
+	var sameCreator *model.IndexerFileChunk
+	for _, c := range candidates {
+		if c.ParentPinID == "" || c.ParentPinID == indexPinID {
+			log.Printf("Chunk PIN=%s not indexed, resolved by SHA256=%s to PIN=%s", pinID, sha256, c.PinID)
+			return c
+		}
+		if sameCreator == nil && creatorMetaID != "" {
+			if parent, err := s.indexerFileDAO.GetByPinID(c.ParentPinID); err == nil && parent != nil && parent.CreatorMetaId == creatorMetaID {
+				sameCreator = c
+			}
+		}
+	}
+	if sameCreator != nil {
+		log.Printf("Chunk PIN=%s not indexed, resolved by SHA256=%s to PIN=%s of the creator's file %s",
+			pinID, sha256, sameCreator.PinID, sameCreator.ParentPinID)
+	}
+	return sameCreator
+}
+
+// claimIndexChunks attaches the chunks of an index to it, in chunkList order.
+// Chunks already attached to another file keep their parent: they are shared
+// (read-only) by this index.
+func (s *IndexerService) claimIndexChunks(indexPinID string, chunks []*model.IndexerFileChunk) {
+	for i, chunk := range chunks {
+		if chunk.ParentPinID != "" {
+			continue
+		}
+		chunk.ParentPinID = indexPinID
+		chunk.ChunkIndex = i // Set chunk index based on order in chunkList
+		if err := s.indexerFileChunkDAO.Update(chunk); err != nil {
+			log.Printf("Failed to update chunk parent PIN ID: %v", err)
+		}
+	}
+}
+
+// loadVerifiedChunks loads every chunk of an index from storage, in chunkList
+// order, and verifies each against its sha256 from the index (see
+// loadVerifiedChunk).
+func (s *IndexerService) loadVerifiedChunks(metaFileIndex *metaid_protocols.MetaFileIndex, chunks []*model.IndexerFileChunk) ([][]byte, error) {
+	contents := make([][]byte, 0, len(chunks))
+	for i, chunk := range chunks {
+		chunkContent, err := s.loadVerifiedChunk(metaFileIndex, i, chunk)
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, chunkContent)
+	}
+	return contents, nil
+}
+
+// loadVerifiedChunk loads chunk i of an index from storage and verifies it
+// against its sha256 from the index. A mismatching chunk is re-fetched from
+// the chain once; if the chain copy matches it replaces the stored bytes,
+// otherwise a *ChunkHashMismatchError is returned. Entries without a sha256
+// in the index are not verified.
+func (s *IndexerService) loadVerifiedChunk(metaFileIndex *metaid_protocols.MetaFileIndex, i int, chunk *model.IndexerFileChunk) ([]byte, error) {
+	chunkContent, err := getBlob(s.storage, s.pendingStorageDAO, chunk.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunk from storage: %w", err)
+	}
+
+	expected := ""
+	if i < len(metaFileIndex.ChunkList) {
+		expected = strings.ToLower(metaFileIndex.ChunkList[i].Sha256)
+	}
+	if expected == "" {
+		return chunkContent, nil
+	}
+
+	got := calculateSHA256(chunkContent)
+	if got == expected {
+		return chunkContent, nil
+	}
+	log.Printf("Chunk hash mismatch: index=%d, PIN=%s, expected=%s, got=%s. Re-fetching from chain...",
+		i, chunk.PinID, expected, got)
+
+	refetched, err := s.refetchChunkContent(chunk)
+	if err != nil {
+		log.Printf("Failed to re-fetch chunk PIN=%s: %v", chunk.PinID, err)
+		return nil, &ChunkHashMismatchError{ChunkIndex: i, PinID: chunk.PinID, Expected: expected, Got: got}
+	}
+	if refetchedHash := calculateSHA256(refetched); refetchedHash != expected {
+		return nil, &ChunkHashMismatchError{ChunkIndex: i, PinID: chunk.PinID, Expected: expected, Got: refetchedHash}
+	}
+
+	// Chain copy is good: repair the stored chunk so later reads agree.
+	if err := s.storage.Save(chunk.StoragePath, refetched); err != nil {
+		log.Printf("Failed to repair stored chunk PIN=%s: %v", chunk.PinID, err)
+	}
+	chunk.ChunkSize = int64(len(refetched))
+	chunk.ChunkMd5 = calculateMD5(refetched)
+	chunk.ChunkSha256 = expected
+	if err := s.indexerFileChunkDAO.Update(chunk); err != nil {
+		log.Printf("Failed to update repaired chunk record PIN=%s: %v", chunk.PinID, err)
+	}
+	log.Printf("Chunk repaired from chain: index=%d, PIN=%s", i, chunk.PinID)
+	return refetched, nil
+}
+
+// refetchChunkContent fetches the chunk's transaction from the node again and
+// returns the (gunzipped, if needed) content of the chunk PIN, the same bytes
+// processChunkContent would have stored.
+func (s *IndexerService) refetchChunkContent(chunk *model.IndexerFileChunk) ([]byte, error) {
+	if chunk.TxID == "" {
+		return nil, fmt.Errorf("chunk has no txid")
+	}
+	if s.parser == nil {
+		return nil, fmt.Errorf("parser not available")
+	}
+	scanner := s.scannerForChain(chunk.ChainName)
+	if scanner == nil {
+		return nil, fmt.Errorf("no scanner for chain %s", chunk.ChainName)
+	}
+
+	tx, err := scanner.GetAndDeserializeTx(chunk.TxID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tx %s: %w", chunk.TxID, err)
+	}
+	metaDataTx, err := s.parser.ParseAllPINs(tx, indexer.ChainType(strings.ToLower(chunk.ChainName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tx %s: %w", chunk.TxID, err)
+	}
+	if metaDataTx == nil {
+		return nil, fmt.Errorf("no PINs found in tx %s", chunk.TxID)
+	}
+
+	for _, metaData := range metaDataTx.MetaIDData {
+		if metaData.PinID != chunk.PinID {
+			continue
+		}
+		content := metaData.Content
+		if isGzipCompressed(content) {
+			decompressed, err := decompressGzip(content, decompressLimits())
+			if errors.Is(err, ErrDecompressLimit) {
+				return nil, err
+			}
+			if err == nil {
+				content = decompressed
+			}
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf("PIN %s not found in tx %s", chunk.PinID, chunk.TxID)
+}
+
+// scannerForChain returns the block scanner serving chainName, or nil when
+// none is configured (e.g. single-chain mode for a different chain).
+func (s *IndexerService) scannerForChain(chainName string) *indexer.BlockScanner {
+	if s.isMultiChain {
+		if s.coordinator == nil {
+			return nil
+		}
+		return s.coordinator.GetScanner(strings.ToLower(chainName))
+	}
+	if s.scanner != nil && strings.EqualFold(string(s.chainType), chainName) {
+		return s.scanner
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestConfirmMempoolFile_UpdatesHeightAndConfirmedAt(t *testing.T) {
+	s, _ := newMergeTestService(t)
+
+	const firstSeen, blockTime = int64(1700000000000), int64(1700000600000)
+	file := &model.IndexerFile{
+		FirstPinID:  "mempoolpin1i0",
+		PinID:       "mempoolpin1i0",
+		Path:        "/file/a.txt",
+		ChainName:   "mvc",
+		Timestamp:   firstSeen,
+		FirstSeenAt: firstSeen,
+		Status:      model.StatusSuccess,
+	}
+	if err := s.indexerFileDAO.Create(file); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Still in mempool: nothing to confirm
+	if confirmed, err := s.confirmMempoolFile(file.PinID, 0, firstSeen); confirmed || err != nil {
+		t.Fatalf("height 0: confirmed=%v err=%v", confirmed, err)
+	}
+
+	confirmed, err := s.confirmMempoolFile(file.PinID, 123, blockTime)
+	if err != nil || !confirmed {
+		t.Fatalf("confirm: confirmed=%v err=%v", confirmed, err)
+	}
+	got, err := s.indexerFileDAO.GetByPinID(file.PinID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if got.BlockHeight != 123 || got.ConfirmedAt != blockTime {
+		t.Errorf("BlockHeight=%d ConfirmedAt=%d, want 123/%d", got.BlockHeight, got.ConfirmedAt, blockTime)
+	}
+	if got.FirstSeenAt != firstSeen || got.Timestamp != firstSeen {
+		t.Errorf("FirstSeenAt=%d Timestamp=%d, want both %d", got.FirstSeenAt, got.Timestamp, firstSeen)
+	}
+
+	// Already confirmed (e.g. rescan): fall through to normal processing
+	if confirmed, _ := s.confirmMempoolFile(file.PinID, 124, blockTime); confirmed {
+		t.Error("already confirmed file should not be confirmed again")
+	}
+}
+
+func TestConfirmMempoolFile_UnknownPin(t *testing.T) {
+	s, _ := newMergeTestService(t)
+	if confirmed, err := s.confirmMempoolFile("unknownpini0", 100, 1700000000000); confirmed || err != nil {
+		t.Fatalf("confirmed=%v err=%v, want false/nil", confirmed, err)
+	}
+}
+
+func TestConfirmMempoolFile_RewritesExtensionIndexInPlace(t *testing.T) {
+	s, _ := newMergeTestService(t)
+
+	file := &model.IndexerFile{
+		FirstPinID:          "mempoolpin2i0",
+		PinID:               "mempoolpin2i0",
+		Path:                "/file/b.png",
+		ChainName:           "mvc",
+		FileExtension:       ".png",
+		CreatorGlobalMetaId: "idq1creator",
+		Timestamp:           1700000000000,
+		Status:              model.StatusSuccess,
+	}
+	if err := s.indexerFileDAO.Create(file); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if confirmed, err := s.confirmMempoolFile(file.PinID, 200, 1700000600000); err != nil || !confirmed {
+		t.Fatalf("confirm: confirmed=%v err=%v", confirmed, err)
+	}
+
+	byExt, _, err := s.indexerFileDAO.GetByExtensionWithCursor(".png", "", 10)
+	if err != nil {
+		t.Fatalf("GetByExtensionWithCursor: %v", err)
+	}
+	byGlobal, _, err := s.indexerFileDAO.GetByGlobalMetaIDAndExtensionWithCursor("idq1creator", ".png", "", 10)
+	if err != nil {
+		t.Fatalf("GetByGlobalMetaIDAndExtensionWithCursor: %v", err)
+	}
+	for name, files := range map[string][]*model.IndexerFile{"extension": byExt, "globalMetaID+extension": byGlobal} {
+		if len(files) != 1 {
+			t.Errorf("%s index: %d entries, want 1", name, len(files))
+			continue
+		}
+		if files[0].BlockHeight != 200 {
+			t.Errorf("%s index: BlockHeight=%d, want 200", name, files[0].BlockHeight)
+		}
+	}
+	if latest, err := s.indexerFileDAO.GetLatestFileInfoByFirstPinID(file.FirstPinID); err != nil || latest == nil || latest.BlockHeight != 200 {
+		t.Errorf("latest file info not updated: %+v err=%v", latest, err)
+	}
+}
@@ -0,0 +1,138 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// chainTipTTL how long the indexed height of a chain is reused for
+// confirmation counts
+const chainTipTTL = 5 * time.Second
+
+// ErrProvisionalContent is returned (as a *ProvisionalContentError) for content
+// of a provisional file while indexer.confirmations.refuse_provisional is set
+var ErrProvisionalContent = errors.New("file is provisional")
+
+// ProvisionalContentError content refused because its file has fewer
+// confirmations than its chain requires
+type ProvisionalContentError struct {
+	PinID            string
+	ChainName        string
+	BlockHeight      int64
+	Confirmations    int64
+	MinConfirmations int64
+}
+
+func (e *ProvisionalContentError) Error() string {
+	return fmt.Sprintf("file %s is provisional: %d of %d confirmations on %s", e.PinID, e.Confirmations, e.MinConfirmations, e.ChainName)
+}
+
+// Is makes errors.Is(err, ErrProvisionalContent) match
+func (e *ProvisionalContentError) Is(target error) bool {
+	return target == ErrProvisionalContent
+}
+
+// FileConfirmation confirmation state of a PIN: Confirmations counts its block
+// and the indexed blocks after it (0 in mempool); it is provisional until
+// Confirmations reaches MinConfirmations
+type FileConfirmation struct {
+	Confirmations    int64
+	MinConfirmations int64
+	Provisional      bool
+}
+
+// MinConfirmations the confirmations a PIN of chainName needs: the chain's
+// indexer.chains entry, else indexer.confirmations.min, at least 1
+func MinConfirmations(chainName string) int64 {
+	if conf.Cfg == nil {
+		return 1
+	}
+	min := conf.Cfg.Indexer.Confirmations.Min
+	for _, chain := range conf.Cfg.Indexer.Chains {
+		if chain.Name == chainName && chain.MinConfirmations > 0 {
+			min = chain.MinConfirmations
+		}
+	}
+	if min < 1 {
+		min = 1
+	}
+	return min
+}
+
+// ConfirmationOf the confirmation state of a PIN of chainName at blockHeight,
+// counted against the height the indexer has reached on the chain. Promotion
+// needs no write: a file stops being provisional once the chain is indexed far
+// enough past it.
+func ConfirmationOf(chainName string, blockHeight int64) FileConfirmation {
+	state := FileConfirmation{MinConfirmations: MinConfirmations(chainName)}
+	if blockHeight <= 0 {
+		state.Provisional = true
+		return state
+	}
+	tip := chainTips.height(chainName)
+	if tip < blockHeight {
+		// The block is indexed but the sync height not saved yet
+		tip = blockHeight
+	}
+	state.Confirmations = tip - blockHeight + 1
+	state.Provisional = state.Confirmations < state.MinConfirmations
+	return state
+}
+
+// CheckProvisionalContent refuses the content of a provisional file when
+// indexer.confirmations.refuse_provisional is set
+func CheckProvisionalContent(file *model.IndexerFile) error {
+	if conf.Cfg == nil || !conf.Cfg.Indexer.Confirmations.RefuseProvisional {
+		return nil
+	}
+	state := ConfirmationOf(file.ChainName, file.BlockHeight)
+	if !state.Provisional {
+		return nil
+	}
+	return &ProvisionalContentError{
+		PinID:            file.PinID,
+		ChainName:        file.ChainName,
+		BlockHeight:      file.BlockHeight,
+		Confirmations:    state.Confirmations,
+		MinConfirmations: state.MinConfirmations,
+	}
+}
+
+// chainTipCache the indexed height per chain, read from the sync status
+type chainTipCache struct {
+	mu      sync.Mutex
+	heights map[string]int64
+	readAt  map[string]time.Time
+}
+
+var chainTips = &chainTipCache{heights: make(map[string]int64), readAt: make(map[string]time.Time)}
+
+// height the indexed height of chainName; 0 when unknown
+func (c *chainTipCache) height(chainName string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.readAt[chainName]) < chainTipTTL {
+		return c.heights[chainName]
+	}
+	if database.DB == nil {
+		return 0
+	}
+	status, err := database.DB.GetIndexerSyncStatusByChainName(chainName)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		log.Printf("Failed to read sync height of %s for confirmations: %v", chainName, err)
+		return c.heights[chainName]
+	}
+	var height int64
+	if status != nil {
+		height = status.CurrentSyncHeight
+	}
+	c.heights[chainName], c.readAt[chainName] = height, time.Now()
+	return height
+}
@@ -0,0 +1,121 @@
+package indexer_service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/storage"
+)
+
+// setChainTip makes the indexed height of chainName height for the test
+func setChainTip(t *testing.T, chainName string, height int64) {
+	t.Helper()
+	chainTips.set(chainName, height)
+	t.Cleanup(func() { chainTips.set(chainName, 0) })
+}
+
+func (c *chainTipCache) set(chainName string, height int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height == 0 {
+		delete(c.heights, chainName)
+		delete(c.readAt, chainName)
+		return
+	}
+	c.heights[chainName] = height
+	c.readAt[chainName] = time.Now()
+}
+
+func TestConfirmationOf(t *testing.T) {
+	setTestConfig(t)
+	conf.Cfg.Indexer.Confirmations.Min = 2
+	conf.Cfg.Indexer.Chains = []conf.ChainInstanceConfig{{Name: "btc", MinConfirmations: 6}, {Name: "doge"}}
+	setChainTip(t, "btc", 100)
+	setChainTip(t, "doge", 100)
+
+	cases := []struct {
+		chain         string
+		height        int64
+		confirmations int64
+		min           int64
+		provisional   bool
+	}{
+		{"btc", 0, 0, 6, true},    // Mempool
+		{"btc", 96, 5, 6, true},   // One block short
+		{"btc", 95, 6, 6, false},  // Promoted at the threshold
+		{"doge", 100, 1, 2, true}, // Chain without an override uses indexer.confirmations.min
+		{"doge", 99, 2, 2, false},
+		{"btc", 120, 1, 6, true}, // Indexed past the saved sync height
+	}
+	for _, tc := range cases {
+		got := ConfirmationOf(tc.chain, tc.height)
+		if got.Confirmations != tc.confirmations || got.MinConfirmations != tc.min || got.Provisional != tc.provisional {
+			t.Errorf("ConfirmationOf(%s, %d) = %+v, want %d/%d provisional=%v", tc.chain, tc.height, got, tc.confirmations, tc.min, tc.provisional)
+		}
+	}
+
+	conf.Cfg.Indexer.Confirmations.Min = 0
+	if got := MinConfirmations("mvc"); got != 1 {
+		t.Errorf("default MinConfirmations = %d, want 1", got)
+	}
+}
+
+func TestCheckProvisionalContent(t *testing.T) {
+	setTestConfig(t)
+	conf.Cfg.Indexer.Confirmations.Min = 3
+	setChainTip(t, "mvc", 200)
+	file := &model.IndexerFile{PinID: "newpini0", ChainName: "mvc", BlockHeight: 199}
+
+	if err := CheckProvisionalContent(file); err != nil {
+		t.Fatalf("refused without refuse_provisional: %v", err)
+	}
+	conf.Cfg.Indexer.Confirmations.RefuseProvisional = true
+	err := CheckProvisionalContent(file)
+	var provisional *ProvisionalContentError
+	if !errors.Is(err, ErrProvisionalContent) || !errors.As(err, &provisional) || provisional.Confirmations != 2 || provisional.MinConfirmations != 3 {
+		t.Fatalf("err = %v, want provisional with 2 of 3 confirmations", err)
+	}
+	file.BlockHeight = 198
+	if err := CheckProvisionalContent(file); err != nil {
+		t.Errorf("file at the threshold refused: %v", err)
+	}
+}
+
+// seedProvisionalPolicy refuses the content of mvc files with fewer than 3
+// confirmations, with the chain indexed up to height 200
+func seedProvisionalPolicy(t *testing.T) {
+	t.Helper()
+	conf.Cfg.Indexer.Confirmations.Min = 3
+	conf.Cfg.Indexer.Confirmations.RefuseProvisional = true
+	setChainTip(t, "mvc", 200)
+}
+
+// seedContentFile indexes an mvc file at blockHeight with its content stored
+func seedContentFile(t *testing.T, s *IndexerService, stor storage.Storage, pinID, fileName string, blockHeight int64, content string) *model.IndexerFile {
+	t.Helper()
+	file := &model.IndexerFile{
+		FirstPinID:  pinID,
+		PinID:       pinID,
+		Path:        "/file/" + fileName,
+		ChunkType:   model.ChunkTypeSingle,
+		FileName:    fileName,
+		FileSize:    int64(len(content)),
+		FileHash:    calculateSHA256([]byte(content)),
+		ContentType: "text/plain",
+		StorageType: "local",
+		StoragePath: "indexer/mvc/" + pinID,
+		ChainName:   "mvc",
+		BlockHeight: blockHeight,
+		Status:      model.StatusSuccess,
+	}
+	if err := stor.Save(file.StoragePath, []byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.indexerFileDAO.Create(file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
@@ -0,0 +1,86 @@
+package indexer_service
+
+import (
+	"strings"
+
+	"meta-file-system/model"
+)
+
+// confirmedFetchRounds bounds how many underlying pages one confirmed-only page
+// may read, so a long run of mempool files cannot turn a request into a scan
+const confirmedFetchRounds = 10
+
+// offsetFetch reads one page of an offset-cursor listing
+type offsetFetch func(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error)
+
+// keyFetch reads one page of a key-cursor listing; next is empty on the last page
+type keyFetch func(cursor string, size int) ([]*model.IndexerFile, string, error)
+
+// appendConfirmed appends the files that are in a block (mempool-only files have
+// block height 0 and may still be dropped by a reorg or double spend)
+func appendConfirmed(dst, files []*model.IndexerFile) []*model.IndexerFile {
+	for _, file := range files {
+		if file.BlockHeight > 0 {
+			dst = append(dst, file)
+		}
+	}
+	return dst
+}
+
+// listFilesByOffset reads one page of an offset-cursor listing, leaving out
+// mempool-only files unless includeUnconfirmed is set
+func listFilesByOffset(fetch offsetFetch, cursor int64, size int, includeUnconfirmed bool) ([]*model.IndexerFile, int64, bool, error) {
+	if includeUnconfirmed {
+		return fetch(cursor, size)
+	}
+	return listConfirmedByOffset(fetch, cursor, size)
+}
+
+// listFilesByKey is listFilesByOffset for key-cursor listings
+func listFilesByKey(fetch keyFetch, cursor string, size int, includeUnconfirmed bool) ([]*model.IndexerFile, string, error) {
+	if includeUnconfirmed {
+		return fetch(cursor, size)
+	}
+	return listConfirmedByKey(fetch, cursor, size)
+}
+
+// listConfirmedByOffset fills a page with confirmed files, reading further
+// pages of the listing until size files are collected or it ends. Each read
+// asks only for the files still missing, so next cursor stays exact.
+func listConfirmedByOffset(fetch offsetFetch, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+	var out []*model.IndexerFile
+	for round := 0; round < confirmedFetchRounds; round++ {
+		files, next, hasMore, err := fetch(cursor, size-len(out))
+		if err != nil {
+			return nil, 0, false, err
+		}
+		out = appendConfirmed(out, files)
+		cursor = next
+		if !hasMore || len(out) >= size {
+			return out, next, hasMore, nil
+		}
+	}
+	return out, cursor, true, nil
+}
+
+// listConfirmedByKey is listConfirmedByOffset for key-cursor listings. The
+// storage returns the full index key as next cursor while accepting its
+// trailing 16-digit timestamp, so the key is trimmed between reads.
+func listConfirmedByKey(fetch keyFetch, cursor string, size int) ([]*model.IndexerFile, string, error) {
+	var out []*model.IndexerFile
+	for round := 0; round < confirmedFetchRounds; round++ {
+		files, next, err := fetch(cursor, size-len(out))
+		if err != nil {
+			return nil, "", err
+		}
+		out = appendConfirmed(out, files)
+		if next == "" || len(out) >= size {
+			return out, next, nil
+		}
+		cursor = next
+		if i := strings.LastIndex(next, ":"); i >= 0 {
+			cursor = next[i+1:]
+		}
+	}
+	return out, cursor, nil
+}
@@ -0,0 +1,74 @@
+package indexer_service
+
+import (
+	"fmt"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+// listingOf builds files newest first; heights of 0 are mempool-only files
+func listingOf(heights ...int64) []*model.IndexerFile {
+	files := make([]*model.IndexerFile, len(heights))
+	for i, h := range heights {
+		files[i] = &model.IndexerFile{PinID: fmt.Sprintf("p%di0", i), BlockHeight: h}
+	}
+	return files
+}
+
+func pinIDs(files []*model.IndexerFile) []string {
+	ids := make([]string, len(files))
+	for i, f := range files {
+		ids[i] = f.PinID
+	}
+	return ids
+}
+
+func TestListConfirmedByOffsetFillsPage(t *testing.T) {
+	all := listingOf(0, 0, 10, 0, 9, 8, 7)
+	fetch := func(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+		end := cursor + int64(size)
+		if end > int64(len(all)) {
+			end = int64(len(all))
+		}
+		return all[cursor:end], end, end < int64(len(all)), nil
+	}
+
+	files, next, hasMore, err := listFilesByOffset(fetch, 0, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pinIDs(files)); got != "[p2i0 p4i0 p5i0]" || next != 6 || !hasMore {
+		t.Errorf("page 1 = %s next=%d hasMore=%v", got, next, hasMore)
+	}
+	files, _, hasMore, _ = listFilesByOffset(fetch, next, 3, false)
+	if got := fmt.Sprint(pinIDs(files)); got != "[p6i0]" || hasMore {
+		t.Errorf("page 2 = %s hasMore=%v", got, hasMore)
+	}
+
+	if files, _, _, _ := listFilesByOffset(fetch, 0, 3, true); len(files) != 3 || files[0].PinID != "p0i0" {
+		t.Errorf("includeUnconfirmed page = %v", pinIDs(files))
+	}
+}
+
+func TestListConfirmedByKeyTrimsCursor(t *testing.T) {
+	var cursors []string
+	fetch := func(cursor string, size int) ([]*model.IndexerFile, string, error) {
+		cursors = append(cursors, cursor)
+		if cursor == "" {
+			return listingOf(0, 5), ".png:0000001700000000", nil
+		}
+		return listingOf(4), "", nil
+	}
+
+	files, next, err := listFilesByKey(fetch, "", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || next != "" {
+		t.Errorf("files = %v next = %q", pinIDs(files), next)
+	}
+	if fmt.Sprint(cursors) != "[ 0000001700000000]" {
+		t.Errorf("cursors = %q", cursors)
+	}
+}
@@ -0,0 +1,42 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+)
+
+func TestIndexedContentTypeNormalized(t *testing.T) {
+	s, _ := newMergeTestService(t)
+	s.txDeliveries = newTxDeliveries()
+	metaDataTx := &indexer.MetaIDDataTx{
+		TxID:      "ctypetx",
+		ChainName: "mvc",
+		MetaIDData: []*indexer.MetaIDData{{
+			PinID:          "ctypetxi0",
+			TxID:           "ctypetx",
+			Operation:      "create",
+			Path:           "/file/notes",
+			ContentType:    "Text/Plain ; Charset=UTF8 ;binary",
+			Content:        []byte("declared with mixed casing"),
+			ChainName:      "mvc",
+			CreatorAddress: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+		}},
+	}
+	if err := s.handleTransaction(nil, metaDataTx, 100, 1700000000000); err != nil {
+		t.Fatalf("handleTransaction: %v", err)
+	}
+
+	file, err := s.indexerFileDAO.GetByPinID("ctypetxi0")
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if file.ContentType != "text/plain; charset=utf-8" || file.FileType != "text" || file.FileExtension != ".txt" {
+		t.Errorf("indexed file content type %q, type %q, extension %q", file.ContentType, file.FileType, file.FileExtension)
+	}
+	pinInfo, err := database.DB.GetPinInfoByPinID("ctypetxi0")
+	if err != nil || pinInfo.ContentType != "text/plain; charset=utf-8" {
+		t.Errorf("PIN info content type = %+v, %v", pinInfo, err)
+	}
+}
@@ -0,0 +1,192 @@
+package indexer_service
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	common_service "meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/metaid"
+)
+
+// resolveCreatorAddress returns the address of the creator of metaData: the
+// address spent by its creator input, looked up on the node unless the resolve
+// stage of the block pipeline already did. When the lookup fails the fallback
+// address is returned and CreatorResolutionPending is set.
+func (s *IndexerService) resolveCreatorAddress(metaData *indexer.MetaIDData) string {
+	if metaData.CreatorResolutionPending || metaData.CreatorInputLocation == "" {
+		return metaData.CreatorAddress
+	}
+	realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
+	if err != nil {
+		log.Printf("Failed to get creator address from location %s: %v, using fallback address",
+			metaData.CreatorInputLocation, err)
+		// Without a node connection a retry cannot do better
+		metaData.CreatorResolutionPending = !errors.Is(err, indexer.ErrNoBlockScanner)
+		return metaData.CreatorAddress
+	}
+	log.Printf("Found real creator address: %s (from location: %s)", realAddress, metaData.CreatorInputLocation)
+	return realAddress
+}
+
+// fileCreatorAddress resolves the creator of a file PIN like
+// resolveCreatorAddress. A failed lookup is queued for the creator retrier,
+// and the file record is saved with ResolutionPending
+// (metaData.CreatorResolutionPending) until it is corrected.
+func (s *IndexerService) fileCreatorAddress(metaData *indexer.MetaIDData) string {
+	address := s.resolveCreatorAddress(metaData)
+	if metaData.CreatorResolutionPending {
+		s.queueCreatorResolution(metaData)
+	}
+	return address
+}
+
+// queueCreatorResolution queues the creator lookup of metaData. A lookup that
+// cannot be queued clears CreatorResolutionPending: the file keeps the
+// fallback address for good, as before.
+func (s *IndexerService) queueCreatorResolution(metaData *indexer.MetaIDData) {
+	chainName := metaData.ChainName
+	if chainName == "" {
+		chainName = string(s.chainType)
+	}
+	now := time.Now()
+	pending := &model.PendingCreatorResolution{
+		PinID:                     metaData.PinID,
+		ChainName:                 chainName,
+		CreatorInputLocation:      metaData.CreatorInputLocation,
+		CreatorInputTxVinLocation: metaData.CreatorInputTxVinLocation,
+		FallbackAddress:           metaData.CreatorAddress,
+		Attempts:                  1,
+		NextRetryAt:               now.Add(creatorRetryBackoff(1)).Unix(),
+		CreatedAt:                 now,
+	}
+	if s.pendingCreatorDAO == nil {
+		metaData.CreatorResolutionPending = false
+		return
+	}
+	if err := s.pendingCreatorDAO.Save(pending); err != nil {
+		log.Printf("Failed to queue creator lookup of PIN %s: %v", metaData.PinID, err)
+		metaData.CreatorResolutionPending = false
+	}
+}
+
+// creatorRetryBackoff delay before attempt+1 of a queued lookup: the retry
+// interval doubled per failed attempt, capped at max_backoff_seconds
+func creatorRetryBackoff(attempts int) time.Duration {
+	cfg := conf.Cfg.Indexer.CreatorRetry
+	return doublingBackoff(time.Duration(cfg.IntervalSeconds)*time.Second, time.Duration(cfg.MaxBackoffSeconds)*time.Second, attempts, time.Minute)
+}
+
+// retryCreatorResolutions retries queued creator lookups every retry interval
+// until stop is closed
+func (s *IndexerService) retryCreatorResolutions(stop <-chan struct{}) {
+	interval := time.Duration(conf.Cfg.Indexer.CreatorRetry.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if corrected := s.flushPendingCreatorResolutions(time.Now()); corrected > 0 {
+			log.Printf("[CreatorRetry] %d queued creator lookups resolved", corrected)
+		}
+	}
+}
+
+// flushPendingCreatorResolutions looks up the queued creators that are due at
+// now and returns how many were resolved. A resolved file gets its creator
+// fields (and indexes) corrected and its queue record deleted; a failed
+// lookup is rescheduled with a longer backoff until max_attempts, after which
+// the file keeps the fallback address.
+func (s *IndexerService) flushPendingCreatorResolutions(now time.Time) int {
+	queued, err := s.pendingCreatorDAO.List()
+	if err != nil {
+		log.Printf("[CreatorRetry] Failed to list queued lookups: %v", err)
+		return 0
+	}
+
+	maxAttempts := conf.Cfg.Indexer.CreatorRetry.MaxAttempts
+	resolved := 0
+	for _, pending := range queued {
+		if pending.NextRetryAt > now.Unix() {
+			continue
+		}
+		address, err := s.parser.FindCreatorAddressFromCreatorInputLocation(pending.CreatorInputLocation, pending.CreatorInputTxVinLocation, indexer.ChainType(pending.ChainName))
+		if err != nil {
+			pending.Attempts++
+			pending.LastError = err.Error()
+			if maxAttempts > 0 && pending.Attempts >= maxAttempts {
+				log.Printf("[CreatorRetry] Giving up on PIN %s after %d lookups, keeping fallback address %s: %v",
+					pending.PinID, pending.Attempts, pending.FallbackAddress, err)
+				if err := s.pendingCreatorDAO.Delete(pending.PinID); err != nil {
+					log.Printf("[CreatorRetry] Failed to delete queued lookup %s: %v", pending.PinID, err)
+				}
+				continue
+			}
+			pending.NextRetryAt = now.Add(creatorRetryBackoff(pending.Attempts)).Unix()
+			if err := s.pendingCreatorDAO.Save(pending); err != nil {
+				log.Printf("[CreatorRetry] Failed to reschedule %s: %v", pending.PinID, err)
+			}
+			continue
+		}
+		if err := s.correctFileCreator(pending.PinID, address); err != nil {
+			// Keep the queue record so the next pass corrects the file again
+			log.Printf("[CreatorRetry] Resolved PIN %s to %s but failed to update the file: %v", pending.PinID, address, err)
+			continue
+		}
+		if err := s.pendingCreatorDAO.Delete(pending.PinID); err != nil {
+			log.Printf("[CreatorRetry] Failed to delete queued lookup %s: %v", pending.PinID, err)
+		}
+		resolved++
+	}
+	return resolved
+}
+
+// correctFileCreator stores address as the creator of the file PIN pinID and
+// clears ResolutionPending. Files that are not indexed (e.g. an index PIN
+// still waiting for chunks) or no longer pending are left alone.
+func (s *IndexerService) correctFileCreator(pinID, address string) error {
+	file, err := s.indexerFileDAO.GetByPinID(pinID)
+	if err != nil || file == nil || !file.ResolutionPending {
+		return err
+	}
+
+	previous := *file
+	file.ResolutionPending = false
+	if address == file.CreatorAddress {
+		return s.indexerFileDAO.UpdateFields(file)
+	}
+	file.CreatorAddress = address
+	metaid.Observe(address, pinID) // genesis_txid: the PIN skipped the real creator when indexed
+	file.CreatorMetaId = metaid.Derive(address)
+	file.CreatorGlobalMetaId = common_service.ConvertToGlobalMetaId(address)
+	if err := s.indexerFileDAO.ReassignCreator(file, &previous); err != nil {
+		return err
+	}
+	log.Printf("[CreatorRetry] Corrected creator of PIN %s: %s -> %s", pinID, previous.CreatorAddress, address)
+	recordFileChange(model.ChangeFileCreatorCorrected, file)
+	return nil
+}
+
+// ListPendingCreatorResolutions returns the queued creator lookups, oldest
+// first
+func (s *IndexerService) ListPendingCreatorResolutions() ([]*model.PendingCreatorResolution, error) {
+	queued, err := s.pendingCreatorDAO.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].CreatedAt.Before(queued[j].CreatedAt)
+	})
+	return queued, nil
+}
@@ -0,0 +1,82 @@
+package indexer_service
+
+import (
+	"testing"
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid"
+)
+
+func TestCreatorResolution_QueuedAndCorrected(t *testing.T) {
+	s, _ := newMergeTestService(t)
+	s.parser = indexer.NewMetaIDParser("") // No node: lookups fail
+	const (
+		pinID           = "creatorpin1i0"
+		fallbackAddress = "1BoatSLRHtKNngkdXEeobR76b53LETtpyT"
+		realAddress     = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	)
+
+	// As left by the resolve stage after a failed lookup
+	metaData := &indexer.MetaIDData{
+		PinID:                    pinID,
+		TxID:                     "creatorpin1",
+		Operation:                "create",
+		Path:                     "/file/note.txt",
+		ContentType:              "text/plain",
+		Content:                  []byte("indexed during a node hiccup"),
+		ChainName:                "mvc",
+		CreatorAddress:           fallbackAddress,
+		CreatorInputLocation:     "creatorinput:0",
+		CreatorResolutionPending: true,
+	}
+	if err := s.processFileContent(metaData, pinID, metaData.Path, 100, 1700000000000); err != nil {
+		t.Fatalf("processFileContent: %v", err)
+	}
+
+	file, err := s.indexerFileDAO.GetByPinID(pinID)
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if !file.ResolutionPending || file.CreatorAddress != fallbackAddress {
+		t.Fatalf("ResolutionPending=%v CreatorAddress=%s, want pending with the fallback address", file.ResolutionPending, file.CreatorAddress)
+	}
+	queued, err := s.pendingCreatorDAO.List()
+	if err != nil || len(queued) != 1 || queued[0].PinID != pinID || queued[0].CreatorInputLocation != "creatorinput:0" {
+		t.Fatalf("queued lookups = %+v, %v, want the file's lookup", queued, err)
+	}
+
+	// A failed retry is rescheduled
+	if resolved := s.flushPendingCreatorResolutions(time.Now().Add(time.Hour)); resolved != 0 {
+		t.Fatalf("resolved %d lookups without a node", resolved)
+	}
+	queued, _ = s.pendingCreatorDAO.List()
+	if len(queued) != 1 || queued[0].Attempts != 2 || queued[0].LastError == "" {
+		t.Fatalf("queued lookups after a failed retry = %+v, want attempts 2 with the error", queued)
+	}
+
+	// A successful lookup moves the file to the real creator
+	if err := s.correctFileCreator(pinID, realAddress); err != nil {
+		t.Fatalf("correctFileCreator: %v", err)
+	}
+	file, _ = s.indexerFileDAO.GetByPinID(pinID)
+	if file.ResolutionPending || file.CreatorAddress != realAddress || file.CreatorMetaId != metaid.Derive(realAddress) {
+		t.Fatalf("corrected file = %+v", file)
+	}
+	oldFiles, _, _, err := s.indexerFileDAO.GetByCreatorMetaIDWithCursor(metaid.Derive(fallbackAddress), model.IndexerFileFilter{}, 0, 10)
+	if err != nil || len(oldFiles) != 0 {
+		t.Errorf("files of the fallback MetaID = %d, %v, want none", len(oldFiles), err)
+	}
+	newFiles, _, _, err := s.indexerFileDAO.GetByCreatorMetaIDWithCursor(metaid.Derive(realAddress), model.IndexerFileFilter{}, 0, 10)
+	if err != nil || len(newFiles) != 1 || newFiles[0].PinID != pinID {
+		t.Errorf("files of the real MetaID = %+v, %v, want the corrected file", newFiles, err)
+	}
+	if n, _ := database.DB.GetIndexerFilesCountByCreatorMetaID(metaid.Derive(fallbackAddress)); n != 0 {
+		t.Errorf("fallback MetaID file count = %d, want 0", n)
+	}
+	if n, _ := database.DB.GetIndexerFilesCountByCreatorMetaID(metaid.Derive(realAddress)); n != 1 {
+		t.Errorf("real MetaID file count = %d, want 1", n)
+	}
+}
@@ -0,0 +1,170 @@
+package indexer_service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/metaid"
+)
+
+// Gzip limits used when indexer.gzip_* is not configured
+const (
+	DefaultGzipMaxOutputMB = 100
+	DefaultGzipMaxRatio    = 100
+
+	// gzipRatioFloor output size below which the ratio limit is not applied,
+	// so small, highly repetitive files (e.g. JSON) are not rejected
+	gzipRatioFloor = 1 << 20
+)
+
+// ErrDecompressLimit is returned (wrapped) by decompressGzip when the output
+// would exceed DecompressLimits; the content must be rejected, not stored
+var ErrDecompressLimit = errors.New("decompression limit exceeded")
+
+// DecompressLimits bounds the inflated size of gzip content from the chain
+type DecompressLimits struct {
+	MaxBytes int64 // Max decompressed size in bytes
+	MaxRatio int64 // Max decompressed/compressed size ratio, enforced above gzipRatioFloor; 0 = no ratio limit
+}
+
+// decompressLimits returns the configured gzip limits, falling back to the defaults
+func decompressLimits() DecompressLimits {
+	limits := DecompressLimits{
+		MaxBytes: DefaultGzipMaxOutputMB * 1024 * 1024,
+		MaxRatio: DefaultGzipMaxRatio,
+	}
+	if conf.Cfg == nil {
+		return limits
+	}
+	if conf.Cfg.Indexer.GzipMaxOutputMB > 0 {
+		limits.MaxBytes = int64(conf.Cfg.Indexer.GzipMaxOutputMB) * 1024 * 1024
+	}
+	if conf.Cfg.Indexer.GzipMaxRatio > 0 {
+		limits.MaxRatio = int64(conf.Cfg.Indexer.GzipMaxRatio)
+	}
+	return limits
+}
+
+// maxOutput returns the largest output allowed for compressedLen input bytes
+// and whether that bound comes from the ratio limit
+func (l DecompressLimits) maxOutput(compressedLen int) (int64, bool) {
+	if l.MaxRatio <= 0 {
+		return l.MaxBytes, false
+	}
+	byRatio := int64(compressedLen) * l.MaxRatio
+	if byRatio < gzipRatioFloor {
+		byRatio = gzipRatioFloor
+	}
+	if byRatio < l.MaxBytes {
+		return byRatio, true
+	}
+	return l.MaxBytes, false
+}
+
+// decompressGzip decompresses gzip content, reading at most one byte past the
+// allowed output so a small bomb never inflates in memory. Exceeding limits
+// returns an error wrapping ErrDecompressLimit whose message is the reason.
+func decompressGzip(content []byte, limits DecompressLimits) ([]byte, error) {
+	gzReader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzReader.Close()
+
+	maxOutput, byRatio := limits.maxOutput(len(content))
+	decompressed, err := io.ReadAll(io.LimitReader(gzReader, maxOutput+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress gzip content: %w", err)
+	}
+	if int64(len(decompressed)) > maxOutput {
+		if byRatio {
+			return nil, fmt.Errorf("%w: compression ratio exceeds %d:1 (%d compressed bytes)", ErrDecompressLimit, limits.MaxRatio, len(content))
+		}
+		return nil, fmt.Errorf("%w: decompressed size exceeds %d bytes", ErrDecompressLimit, limits.MaxBytes)
+	}
+
+	return decompressed, nil
+}
+
+// saveRejectedFile records a file PIN whose content was refused, with the
+// reason in StatusReason. Nothing is written to storage; size and hashes are
+// those of the on-chain content. Rejected files are excluded from listings and
+// content, and GetFileStatus reports the reason.
+func (s *IndexerService) saveRejectedFile(metaData *indexer.MetaIDData, chunkType model.ChunkType, firstPinID, firstPath, creatorAddress string, height, timestamp int64, reason string) error {
+	if firstPinID == "" {
+		firstPinID = metaData.PinID
+	}
+	indexerFile := &model.IndexerFile{
+		FirstPinID:          firstPinID,
+		FirstPath:           firstPath,
+		PinID:               metaData.PinID,
+		TxID:                metaData.TxID,
+		Vout:                metaData.Vout,
+		Path:                metaData.Path,
+		Operation:           metaData.Operation,
+		ParentPath:          metaData.ParentPath,
+		Encryption:          metaData.Encryption,
+		Version:             metaData.Version,
+		ContentType:         metaData.ContentType,
+		ChunkType:           chunkType,
+		FileName:            extractFileName(metaData.Path),
+		FileSize:            int64(len(metaData.Content)),
+		FileMd5:             calculateMD5(metaData.Content),
+		FileHash:            calculateSHA256(metaData.Content),
+		IsGzipCompressed:    isGzipCompressed(metaData.Content),
+		ChainName:           metaData.ChainName,
+		BlockHeight:         height,
+		Timestamp:           timestamp,
+		FirstSeenAt:         timestamp,
+		ConfirmedAt:         confirmedAt(height, timestamp),
+		CreatorMetaId:       metaid.Derive(creatorAddress),
+		CreatorAddress:      creatorAddress,
+		ResolutionPending:   metaData.CreatorResolutionPending,
+		CreatorGlobalMetaId: common_service.ConvertToGlobalMetaId(creatorAddress),
+		OwnerAddress:        metaData.OwnerAddress,
+		OwnerMetaId:         metaid.Derive(metaData.OwnerAddress),
+		Status:              model.StatusRejected,
+		StatusReason:        reason,
+	}
+	if err := s.indexerFileDAO.Create(indexerFile); err != nil {
+		return fmt.Errorf("failed to save rejected file to database: %w", err)
+	}
+
+	log.Printf("File rejected: PIN=%s, Path=%s, reason: %s", metaData.PinID, metaData.Path, reason)
+	return nil
+}
+
+// saveRejectedChunk records a chunk PIN whose content was refused. Nothing is
+// written to storage; an index referencing the chunk is rejected on merge.
+func (s *IndexerService) saveRejectedChunk(metaData *indexer.MetaIDData, height int64, reason string) error {
+	indexerFileChunk := &model.IndexerFileChunk{
+		PinID:            metaData.PinID,
+		TxID:             metaData.TxID,
+		Vout:             metaData.Vout,
+		Path:             metaData.Path,
+		Operation:        metaData.Operation,
+		ContentType:      metaData.ContentType,
+		ChunkSize:        int64(len(metaData.Content)),
+		ChunkMd5:         calculateMD5(metaData.Content),
+		ChunkSha256:      calculateSHA256(metaData.Content),
+		IsGzipCompressed: isGzipCompressed(metaData.Content),
+		ChainName:        metaData.ChainName,
+		BlockHeight:      height,
+		Status:           model.StatusRejected,
+		StatusReason:     reason,
+	}
+	if err := s.indexerFileChunkDAO.Create(indexerFileChunk); err != nil {
+		return fmt.Errorf("failed to save rejected chunk to database: %w", err)
+	}
+
+	log.Printf("Chunk rejected: PIN=%s, Path=%s, reason: %s", metaData.PinID, metaData.Path, reason)
+	return nil
+}
@@ -0,0 +1,165 @@
+package indexer_service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompressGzip_Limits(t *testing.T) {
+	text := []byte(strings.Repeat("hello, metaid\n", 100))
+	zeros := make([]byte, 8<<20) // ~8 KB compressed, ratio ~1000:1
+
+	tests := []struct {
+		name       string
+		content    []byte
+		limits     DecompressLimits
+		wantReason string // empty = no error
+	}{
+		{"small file", text, DecompressLimits{MaxBytes: 1 << 20, MaxRatio: 100}, ""},
+		{"repetitive below ratio floor", zeros[:gzipRatioFloor], DecompressLimits{MaxBytes: 100 << 20, MaxRatio: 2}, ""},
+		{"bomb over ratio", zeros, DecompressLimits{MaxBytes: 100 << 20, MaxRatio: 100}, "compression ratio exceeds 100:1"},
+		{"over max size", zeros, DecompressLimits{MaxBytes: 1 << 20, MaxRatio: 0}, "decompressed size exceeds 1048576 bytes"},
+		{"exactly max size", zeros[:1<<20], DecompressLimits{MaxBytes: 1 << 20, MaxRatio: 0}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decompressGzip(gzipBytes(t, tt.content), tt.limits)
+			if tt.wantReason != "" {
+				if !errors.Is(err, ErrDecompressLimit) {
+					t.Fatalf("err = %v, want ErrDecompressLimit", err)
+				}
+				if !strings.Contains(err.Error(), tt.wantReason) {
+					t.Errorf("err = %q, want reason %q", err, tt.wantReason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decompressGzip: %v", err)
+			}
+			if !bytes.Equal(got, tt.content) {
+				t.Errorf("got %d bytes, want %d", len(got), len(tt.content))
+			}
+		})
+	}
+
+	if _, err := decompressGzip([]byte{0x1f, 0x8b, 0x00}, decompressLimits()); err == nil || errors.Is(err, ErrDecompressLimit) {
+		t.Errorf("corrupt gzip: err = %v, want a non-limit error", err)
+	}
+}
+
+func TestProcessFileContent_RejectsGzipBomb(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	const pinID = "bombpin1i0"
+
+	metaData := &indexer.MetaIDData{
+		PinID:          pinID,
+		TxID:           "bombpin1",
+		Operation:      "create",
+		Path:           "/file/bomb.txt",
+		ContentType:    "text/plain",
+		Content:        gzipBytes(t, make([]byte, 8<<20)),
+		ChainName:      "mvc",
+		CreatorAddress: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+	}
+	if err := s.processFileContent(metaData, pinID, metaData.Path, 100, 1700000000000); err != nil {
+		t.Fatalf("processFileContent: %v", err)
+	}
+
+	file, err := s.indexerFileDAO.GetByPinID(pinID)
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if file.Status != model.StatusRejected || !strings.Contains(file.StatusReason, "compression ratio") {
+		t.Errorf("Status=%q StatusReason=%q, want rejected with ratio reason", file.Status, file.StatusReason)
+	}
+	if file.StoragePath != "" || file.FileSize != int64(len(metaData.Content)) {
+		t.Errorf("StoragePath=%q FileSize=%d, want no storage and the on-chain size", file.StoragePath, file.FileSize)
+	}
+	if stor.Exists("indexer/mvc/" + pinID + ".txt") {
+		t.Error("rejected content was written to storage")
+	}
+
+	fileService := NewIndexerFileService(stor)
+	status, err := fileService.GetFileStatus(pinID)
+	if err != nil {
+		t.Fatalf("GetFileStatus: %v", err)
+	}
+	if status.Status != FileStatusRejected || status.Reason != file.StatusReason {
+		t.Errorf("status = %+v, want rejected with reason", status)
+	}
+	if _, _, _, err := fileService.GetFileContent(pinID); err == nil {
+		t.Error("GetFileContent served a rejected file")
+	}
+}
+
+func TestProcessIndexContent_RejectedChunkRejectsFile(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	const indexPinID = "bombidx1i0"
+	chunkList := seedChunks(t, s, stor, indexPinID, []string{"AAA"})
+
+	const bombPinID = "bombchunk1i0"
+	chunk := &indexer.MetaIDData{
+		PinID:       bombPinID,
+		TxID:        "bombchunk1",
+		Operation:   "create",
+		Path:        "/file/_chunk",
+		ContentType: "metafile/chunk",
+		Content:     gzipBytes(t, make([]byte, 8<<20)),
+		ChainName:   "mvc",
+	}
+	if err := s.processChunkContent(chunk, bombPinID, 100, 1700000000000); err != nil {
+		t.Fatalf("processChunkContent: %v", err)
+	}
+	stored, err := s.indexerFileChunkDAO.GetByPinID(bombPinID)
+	if err != nil || stored == nil {
+		t.Fatalf("GetByPinID chunk: %v", err)
+	}
+	if stored.Status != model.StatusRejected || stored.StatusReason == "" {
+		t.Fatalf("chunk Status=%q StatusReason=%q, want rejected with reason", stored.Status, stored.StatusReason)
+	}
+
+	chunkList += `,{"pinId":"` + bombPinID + `","sha256":"` + stored.ChunkSha256 + `"}`
+	index := &indexer.MetaIDData{
+		PinID:          indexPinID,
+		TxID:           "bombidx1",
+		Operation:      "create",
+		Path:           "/file/big.bin",
+		ContentType:    "metafile/index",
+		Content:        []byte(`{"sha256":"x","fileSize":3,"chunkNumber":2,"chunkList":[` + chunkList + `],"dataType":"application/octet-stream","name":"big.bin"}`),
+		ChainName:      "mvc",
+		CreatorAddress: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+	}
+	if err := s.processIndexContent(index, indexPinID, index.Path, 100, 1700000000000); err != nil {
+		t.Fatalf("processIndexContent: %v", err)
+	}
+
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID index: %v", err)
+	}
+	if file.Status != model.StatusRejected || !strings.Contains(file.StatusReason, bombPinID) {
+		t.Errorf("Status=%q StatusReason=%q, want rejected naming the chunk", file.Status, file.StatusReason)
+	}
+	if pending, _ := s.pendingIndexFileDAO.GetByPinID(indexPinID); pending != nil {
+		t.Error("rejected index should not be left pending")
+	}
+}
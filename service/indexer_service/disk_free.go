@@ -0,0 +1,14 @@
+//go:build !windows
+
+package indexer_service
+
+import "syscall"
+
+// diskFreeSpace bytes available to the indexer process on the volume of path
+func diskFreeSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
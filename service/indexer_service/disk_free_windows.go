@@ -0,0 +1,18 @@
+//go:build windows
+
+package indexer_service
+
+import "golang.org/x/sys/windows"
+
+// diskFreeSpace bytes available to the indexer process on the volume of path
+func diskFreeSpace(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
@@ -0,0 +1,141 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/notify"
+	"meta-file-system/storage"
+)
+
+// diskWatchdog pauses scanning while a watched volume has less than minFree
+// bytes available, and lifts the pause once every volume has resumeFree again
+// (indexer.disk_watchdog). Between the two thresholds the state is kept, so a
+// volume hovering around minFree does not flip it on every check.
+type diskWatchdog struct {
+	paths      []string
+	minFree    uint64
+	resumeFree uint64
+	freeSpace  func(path string) (uint64, error)
+
+	low    atomic.Bool
+	failed map[string]bool // Paths whose last check failed, so errors are logged once
+}
+
+func newDiskWatchdog(paths []string, minFree, resumeFree uint64) *diskWatchdog {
+	return &diskWatchdog{
+		paths:      paths,
+		minFree:    minFree,
+		resumeFree: resumeFree,
+		freeSpace:  diskFreeSpace,
+		failed:     make(map[string]bool),
+	}
+}
+
+// paused reports whether scanning is paused for low disk space
+func (w *diskWatchdog) paused() bool {
+	return w != nil && w.low.Load()
+}
+
+// check measures every watched path and updates the pause. changed is true
+// when the pause was just set or lifted; detail names the volumes below
+// minFree when it was just set.
+// A path that cannot be measured does not pause scanning, nor lift a pause.
+func (w *diskWatchdog) check() (changed bool, detail string) {
+	low := w.low.Load()
+	threshold := w.minFree
+	if low {
+		threshold = w.resumeFree
+	}
+
+	var short []string
+	unmeasured := false
+	for _, path := range w.paths {
+		free, err := w.freeSpace(path)
+		if err != nil {
+			if !w.failed[path] {
+				log.Printf("[DiskWatchdog] Failed to read free space of %s: %v", path, err)
+				w.failed[path] = true
+			}
+			unmeasured = true
+			continue
+		}
+		w.failed[path] = false
+		if free < threshold {
+			short = append(short, fmt.Sprintf("%s: %d MB free", path, free/(1024*1024)))
+		}
+	}
+
+	if low == (len(short) > 0) || (low && unmeasured) {
+		return false, ""
+	}
+	w.low.Store(!low)
+	if low {
+		return true, ""
+	}
+	return true, strings.Join(short, ", ")
+}
+
+// watchDiskSpace checks the watched volumes every interval_seconds until stop
+// is closed, pausing and resuming scanning and sending an alert on each change
+func (s *IndexerService) watchDiskSpace(stop <-chan struct{}) {
+	cfg := conf.Cfg.Indexer.DiskWatchdog
+	w := s.diskWatchdog
+	log.Printf("[DiskWatchdog] Watching %s: scanning pauses below %d MB free, resumes at %d MB",
+		strings.Join(w.paths, ", "), cfg.MinFreeMB, cfg.ResumeFreeMB)
+
+	ticker := time.NewTicker(time.Duration(cfg.IntervalSeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		if changed, detail := w.check(); changed {
+			if w.paused() {
+				log.Printf("[DiskWatchdog] ⏸️  Scanning paused, disk space low (%s)", detail)
+				notify.Alertf(notify.EventDiskSpaceLow, "Indexer scanning paused: less than %d MB free (%s)", cfg.MinFreeMB, detail)
+			} else {
+				log.Printf("[DiskWatchdog] ▶️  Scanning resumed, every watched volume has %d MB free", cfg.ResumeFreeMB)
+				notify.Alertf(notify.EventDiskSpaceRecovered, "Indexer scanning resumed: every watched volume has %d MB free again", cfg.ResumeFreeMB)
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// diskWatchdogPaths the directories whose volumes are watched: local storage,
+// the indexer database (Pebble directory or SQLite file) and any extra paths
+func diskWatchdogPaths() []string {
+	var paths []string
+	if storage.RecordType(conf.Cfg.Storage.Type) == "local" {
+		paths = append(paths, conf.Cfg.Storage.Local.BasePath)
+	}
+	switch database.DBType(conf.Cfg.Database.IndexerType) {
+	case database.DBTypePebble:
+		paths = append(paths, conf.Cfg.Database.DataDir)
+	case database.DBTypeSQLite:
+		paths = append(paths, filepath.Dir(conf.Cfg.Database.SqlitePath))
+	}
+	paths = append(paths, conf.Cfg.Indexer.DiskWatchdog.Paths...)
+
+	seen := make(map[string]bool)
+	unique := paths[:0]
+	for _, path := range paths {
+		if path == "" || seen[filepath.Clean(path)] {
+			continue
+		}
+		seen[filepath.Clean(path)] = true
+		unique = append(unique, filepath.Clean(path))
+	}
+	sort.Strings(unique)
+	return unique
+}
@@ -0,0 +1,53 @@
+package indexer_service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiskWatchdogCheck(t *testing.T) {
+	const mb = 1024 * 1024
+	free := map[string]uint64{"/data/files": 5000 * mb, "/data/pebble": 5000 * mb}
+	w := newDiskWatchdog([]string{"/data/files", "/data/pebble"}, 1000*mb, 2000*mb)
+	w.freeSpace = func(path string) (uint64, error) {
+		if path == "/broken" {
+			return 0, errors.New("no such volume")
+		}
+		return free[path], nil
+	}
+
+	steps := []struct {
+		files, pebble uint64 // MB free
+		changed       bool
+		paused        bool
+	}{
+		{5000, 5000, false, false},
+		{1500, 5000, false, false}, // between the thresholds: still scanning
+		{1500, 900, true, true},    // pebble volume below min_free
+		{1500, 1200, false, true},  // above min_free but below resume_free: stays paused
+		{2500, 1999, false, true},
+		{2500, 2000, true, false}, // every volume at resume_free
+		{999, 2000, true, true},
+	}
+	for i, step := range steps {
+		free["/data/files"], free["/data/pebble"] = step.files*mb, step.pebble*mb
+		changed, detail := w.check()
+		if changed != step.changed || w.paused() != step.paused {
+			t.Errorf("step %d (%d/%d MB): changed=%v paused=%v, want %v/%v", i, step.files, step.pebble, changed, w.paused(), step.changed, step.paused)
+		}
+		if changed && step.paused && detail == "" {
+			t.Errorf("step %d: pause without detail", i)
+		}
+	}
+
+	// Volumes that cannot be measured do not change the state
+	w.paths = []string{"/broken"}
+	if changed, _ := w.check(); changed || !w.paused() {
+		t.Errorf("unmeasurable volume: changed=%v paused=%v, want no change", changed, w.paused())
+	}
+
+	var off *diskWatchdog
+	if off.paused() {
+		t.Error("nil watchdog must not pause")
+	}
+}
@@ -0,0 +1,263 @@
+package indexer_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service"
+)
+
+// ErrInvalidDomain is returned (wrapped) for domain mappings that fail validation
+var ErrInvalidDomain = errors.New("invalid domain mapping")
+
+// metaIDSiteRoot is the MetaID path a "metaid" domain serves: /about.html on
+// the domain is the creator's latest /file/about.html
+const metaIDSiteRoot = "/file"
+
+// metaIDSiteLookup how many files under a path are searched for an exact match
+const metaIDSiteLookup = 100
+
+var (
+	domainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+	metaIDPattern      = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// domainRegistry caches all domain mappings; it is reloaded once the cache
+// expires (indexer.domains.cache_seconds) and right after admin changes
+type domainRegistry struct {
+	mu       sync.RWMutex
+	mappings map[string]*model.IndexerDomain
+	loadedAt time.Time
+}
+
+var domainMappings = &domainRegistry{}
+
+// lookup returns the mapping of domain, nil when it is not mapped
+func (r *domainRegistry) lookup(domain string) *model.IndexerDomain {
+	ttl := time.Duration(conf.Cfg.Indexer.Domains.CacheSeconds) * time.Second
+	r.mu.RLock()
+	mapping, fresh := r.mappings[domain], time.Since(r.loadedAt) < ttl
+	r.mu.RUnlock()
+	if fresh {
+		return mapping
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.loadedAt) >= ttl {
+		if err := r.reloadLocked(); err != nil {
+			// Keep serving the previous mappings; retry after the next TTL
+			log.Printf("Failed to load domain mappings: %v", err)
+			r.loadedAt = time.Now()
+		}
+	}
+	return r.mappings[domain]
+}
+
+func (r *domainRegistry) reloadLocked() error {
+	if database.DB == nil {
+		return errors.New("database not initialized")
+	}
+	domains, err := database.DB.ListDomains()
+	if err != nil {
+		return err
+	}
+	mappings := make(map[string]*model.IndexerDomain, len(domains))
+	for _, d := range domains {
+		mappings[d.Domain] = d
+	}
+	r.mappings = mappings
+	r.loadedAt = time.Now()
+	return nil
+}
+
+// invalidate forces the next lookup to reload the mappings
+func (r *domainRegistry) invalidate() {
+	r.mu.Lock()
+	r.loadedAt = time.Time{}
+	r.mu.Unlock()
+}
+
+// NormalizeDomain lower-cases host and strips its port and trailing dot.
+// The result must be a DNS name with at least two labels; IP addresses are
+// rejected.
+func NormalizeDomain(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(host, ".")
+	if host == "" {
+		return "", fmt.Errorf("%w: domain is required", ErrInvalidDomain)
+	}
+	if len(host) > 253 || net.ParseIP(host) != nil || !strings.Contains(host, ".") {
+		return "", fmt.Errorf("%w: %q is not a domain name", ErrInvalidDomain, host)
+	}
+	for _, label := range strings.Split(host, ".") {
+		if len(label) > 63 || !domainLabelPattern.MatchString(label) {
+			return "", fmt.Errorf("%w: %q is not a domain name", ErrInvalidDomain, host)
+		}
+	}
+	return host, nil
+}
+
+// LookupDomain returns the mapping of the request Host header, nil when the
+// host is not a mapped domain
+func LookupDomain(host string) *model.IndexerDomain {
+	domain, err := NormalizeDomain(host)
+	if err != nil {
+		return nil
+	}
+	return domainMappings.lookup(domain)
+}
+
+// DomainHostPolicy allows TLS certificates only for mapped domains (used as
+// the ACME host policy, so arbitrary Host headers cannot trigger issuance)
+func DomainHostPolicy(_ context.Context, host string) error {
+	if LookupDomain(host) == nil {
+		return fmt.Errorf("domain %s is not mapped", host)
+	}
+	return nil
+}
+
+// SaveDomain maps domain to a site manifest PIN (targetType "site") or a
+// MetaID / GlobalMetaID (targetType "metaid"), replacing an existing mapping.
+// A site manifest must already be indexed.
+func (s *IndexerFileService) SaveDomain(domain, targetType, target string) (*model.IndexerDomain, error) {
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	targetType = strings.ToLower(strings.TrimSpace(targetType))
+	target = strings.TrimSpace(target)
+	switch targetType {
+	case model.DomainTargetSite:
+		target = strings.ToLower(target)
+		if _, err := s.GetSiteManifest(target); err != nil {
+			return nil, fmt.Errorf("%w: target is not a site manifest: %v", ErrInvalidDomain, err)
+		}
+	case model.DomainTargetMetaID:
+		if !common_service.IsGlobalMetaId(target) {
+			target = strings.ToLower(target)
+			if !metaIDPattern.MatchString(target) {
+				return nil, fmt.Errorf("%w: target must be a MetaID or GlobalMetaID", ErrInvalidDomain)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: type must be %s or %s", ErrInvalidDomain, model.DomainTargetSite, model.DomainTargetMetaID)
+	}
+
+	mapping := &model.IndexerDomain{Domain: domain, TargetType: targetType, Target: target}
+	if err := database.DB.SaveDomain(mapping); err != nil {
+		return nil, err
+	}
+	domainMappings.invalidate()
+	return mapping, nil
+}
+
+// DeleteDomain removes the mapping of domain; returns database.ErrNotFound if there is none
+func (s *IndexerFileService) DeleteDomain(domain string) error {
+	domain, err := NormalizeDomain(domain)
+	if err != nil {
+		return err
+	}
+	if err := database.DB.DeleteDomain(domain); err != nil {
+		return err
+	}
+	domainMappings.invalidate()
+	return nil
+}
+
+// ListDomains lists all domain mappings ordered by domain
+func (s *IndexerFileService) ListDomains() ([]*model.IndexerDomain, error) {
+	return database.DB.ListDomains()
+}
+
+// GetDomainFile resolves reqPath on a mapped domain. A site follows the
+// latest version of its manifest; a MetaID serves its /file/* paths the same
+// way a site does (index.html for directories, 404.html for unknown paths).
+// isDir reports a directory requested without its trailing slash.
+func (s *IndexerFileService) GetDomainFile(mapping *model.IndexerDomain, reqPath string) (*SiteContent, bool, error) {
+	if mapping.TargetType == model.DomainTargetMetaID {
+		return s.getMetaIDSiteFile(mapping.Target, reqPath)
+	}
+	manifestPinID := mapping.Target
+	if latest, err := s.GetLatestFileByFirstPinID(manifestPinID); err == nil && latest != nil && latest.PinID != "" {
+		manifestPinID = latest.PinID
+	}
+	return s.GetSiteFile(manifestPinID, reqPath)
+}
+
+// getMetaIDSiteFile serves reqPath from the latest files of metaID under /file
+func (s *IndexerFileService) getMetaIDSiteFile(metaID, reqPath string) (*SiteContent, bool, error) {
+	clean := path.Clean("/" + reqPath)
+	if clean != "/" && !strings.HasSuffix(reqPath, "/") {
+		if file, err := s.findMetaIDFile(metaID, metaIDSiteRoot+clean); err == nil {
+			return s.metaIDSiteContent(file, clean, false)
+		} else if !errors.Is(err, ErrSiteNotFound) {
+			return nil, false, err
+		}
+	}
+
+	index := strings.TrimSuffix(clean, "/") + "/index.html"
+	file, err := s.findMetaIDFile(metaID, metaIDSiteRoot+index)
+	if err == nil {
+		if clean != "/" && !strings.HasSuffix(reqPath, "/") {
+			return &SiteContent{}, true, nil
+		}
+		return s.metaIDSiteContent(file, index, false)
+	}
+	if !errors.Is(err, ErrSiteNotFound) {
+		return nil, false, err
+	}
+
+	if file, err := s.findMetaIDFile(metaID, metaIDSiteRoot+"/404.html"); err == nil {
+		return s.metaIDSiteContent(file, "/404.html", true)
+	}
+	return nil, false, fmt.Errorf("%w: %s", ErrSiteNotFound, reqPath)
+}
+
+// findMetaIDFile returns the newest live file of metaID at exactly filePath
+func (s *IndexerFileService) findMetaIDFile(metaID, filePath string) (*model.IndexerFile, error) {
+	filter := model.IndexerFileFilter{PathPrefix: filePath}
+	var files []*model.IndexerFile
+	var err error
+	if common_service.IsGlobalMetaId(metaID) {
+		files, _, _, err = s.GetFilesByCreatorGlobalMetaID(metaID, filter, 0, metaIDSiteLookup, true)
+	} else {
+		files, _, _, err = s.GetFilesByCreatorMetaID(metaID, filter, 0, metaIDSiteLookup, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if !strings.EqualFold(file.Path, filePath) || file.Operation == "revoke" || file.Status == model.StatusRejected {
+			continue
+		}
+		return file, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrSiteNotFound, filePath)
+}
+
+func (s *IndexerFileService) metaIDSiteContent(file *model.IndexerFile, sitePath string, notFound bool) (*SiteContent, bool, error) {
+	content, err := s.readFile(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get site file content: %w", err)
+	}
+	site := &SiteContent{File: file, Content: content, ContentType: siteContentType(sitePath, file.ContentType)}
+	site.Path = strings.TrimPrefix(sitePath, "/")
+	site.PinID = file.PinID
+	site.NotFound = notFound
+	return site, false, nil
+}
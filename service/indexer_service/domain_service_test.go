@@ -0,0 +1,26 @@
+package indexer_service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	valid := map[string]string{
+		"MySite.Example.com":      "mysite.example.com",
+		"mysite.example.com:8443": "mysite.example.com",
+		"mysite.example.com.":     "mysite.example.com",
+		" xn--bcher-kva.example ": "xn--bcher-kva.example",
+	}
+	for host, want := range valid {
+		if got, err := NormalizeDomain(host); err != nil || got != want {
+			t.Errorf("NormalizeDomain(%q) = %q, %v; want %q", host, got, err, want)
+		}
+	}
+
+	for _, host := range []string{"", "localhost", "127.0.0.1", "[::1]:80", "-bad.example.com", "my_site.example.com", "a..example.com"} {
+		if _, err := NormalizeDomain(host); !errors.Is(err, ErrInvalidDomain) {
+			t.Errorf("NormalizeDomain(%q) err = %v, want ErrInvalidDomain", host, err)
+		}
+	}
+}
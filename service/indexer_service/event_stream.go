@@ -0,0 +1,193 @@
+package indexer_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/eventstream"
+	"meta-file-system/model"
+)
+
+// Longest wait for the broker to accept one batch
+const eventStreamPublishTimeout = time.Minute
+
+// changeSource is the part of the database the event stream reads
+type changeSource interface {
+	ListChangeEvents(sinceSeq int64, size int) ([]*model.IndexerChangeEvent, error)
+}
+
+// eventStream tails the change log and publishes it to a message bus. The
+// Seq of the last published event is kept in a cursor file, written only
+// after the broker accepted the batch, so events are published at least once
+// across restarts and failures.
+type eventStream struct {
+	pub        eventstream.Publisher
+	source     changeSource
+	prefix     string
+	format     string
+	actions    map[model.ChangeAction]bool // nil = all
+	batchSize  int
+	cursorFile string
+	cursor     int64
+}
+
+func newEventStream(pub eventstream.Publisher, source changeSource, cfg conf.IndexerEventStreamConfig) (*eventStream, error) {
+	e := &eventStream{
+		pub:        pub,
+		source:     source,
+		prefix:     cfg.TopicPrefix,
+		format:     cfg.Format,
+		batchSize:  cfg.BatchSize,
+		cursorFile: cfg.CursorFile,
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = 500
+	}
+	for _, action := range cfg.Actions {
+		if action = strings.TrimSpace(action); action == "" {
+			continue
+		}
+		if e.actions == nil {
+			e.actions = make(map[model.ChangeAction]bool)
+		}
+		e.actions[model.ChangeAction(action)] = true
+	}
+
+	cursor, err := readStreamCursor(e.cursorFile)
+	if err != nil {
+		return nil, err
+	}
+	e.cursor = cursor
+	return e, nil
+}
+
+// publishNext publishes the change log events after the cursor, up to one
+// batch, and advances the cursor. Returns how many events were read; on error
+// the cursor stays, so the same events are published again.
+func (e *eventStream) publishNext() (int, error) {
+	events, err := e.source.ListChangeEvents(e.cursor, e.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("list change events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	msgs := make([]eventstream.Message, 0, len(events))
+	for _, event := range events {
+		if e.actions != nil && !e.actions[event.Action] {
+			continue
+		}
+		msg, err := eventstream.NewMessage(event, e.prefix, e.format)
+		if err != nil {
+			log.Printf("[EventStream] Skipping change %d: %v", event.Seq, err)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), eventStreamPublishTimeout)
+		err := e.pub.Publish(ctx, msgs)
+		cancel()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	e.cursor = events[len(events)-1].Seq
+	if err := writeStreamCursor(e.cursorFile, e.cursor); err != nil {
+		// Published; a restart before the next write publishes the batch again
+		log.Printf("[EventStream] Failed to save cursor %d: %v", e.cursor, err)
+	}
+	return len(events), nil
+}
+
+// readStreamCursor reads the Seq saved in path; 0 (the start of the change
+// log) when there is none
+func readStreamCursor(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	cursor, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || cursor < 0 {
+		return 0, fmt.Errorf("invalid event stream cursor in %s: %q", path, strings.TrimSpace(string(data)))
+	}
+	return cursor, nil
+}
+
+// writeStreamCursor replaces the Seq saved in path
+func writeStreamCursor(path string, cursor int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(cursor, 10)+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// publishEventStream publishes the change log to the configured message bus
+// (indexer.event_stream) until stop is closed. Full batches are followed at
+// once; otherwise, and after a failure, it waits poll_seconds.
+func (s *IndexerService) publishEventStream(stop <-chan struct{}) {
+	cfg := conf.Cfg.Indexer.EventStream
+	pub, err := eventstream.New(eventstream.Config{
+		Broker:      cfg.Broker,
+		URLs:        cfg.URLs,
+		TopicPrefix: cfg.TopicPrefix,
+		Format:      cfg.Format,
+	})
+	if err != nil {
+		log.Printf("[EventStream] Not started: %v", err)
+		return
+	}
+	defer pub.Close()
+
+	stream, err := newEventStream(pub, database.DB, cfg)
+	if err != nil {
+		log.Printf("[EventStream] Not started: %v", err)
+		return
+	}
+	log.Printf("[EventStream] Publishing change log to %s (%s, %s) from seq %d", cfg.Broker, cfg.TopicPrefix, cfg.Format, stream.cursor)
+
+	poll := time.Duration(cfg.PollSeconds) * time.Second
+	if poll <= 0 {
+		poll = 2 * time.Second
+	}
+	failing := false
+	for {
+		n, err := stream.publishNext()
+		if err != nil {
+			if !failing {
+				log.Printf("[EventStream] Publish failed, retrying from seq %d: %v", stream.cursor, err)
+			}
+			failing = true
+		} else if failing {
+			log.Printf("[EventStream] Publishing again")
+			failing = false
+		}
+
+		wait := poll
+		if err == nil && n >= stream.batchSize {
+			wait = 0
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package indexer_service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/eventstream"
+	"meta-file-system/model"
+)
+
+type fakeChangeLog []*model.IndexerChangeEvent
+
+func (f fakeChangeLog) ListChangeEvents(sinceSeq int64, size int) ([]*model.IndexerChangeEvent, error) {
+	var events []*model.IndexerChangeEvent
+	for _, event := range f {
+		if event.Seq > sinceSeq && len(events) < size {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+type fakePublisher struct {
+	err  error
+	sent []eventstream.Message
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msgs []eventstream.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.sent = append(p.sent, msgs...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error { return nil }
+
+func TestEventStreamPublishesAndResumes(t *testing.T) {
+	changes := fakeChangeLog{
+		{Seq: 1, Action: model.ChangeFileCreated, PinID: "a1i0"},
+		{Seq: 2, Action: model.ChangeChunkIndexed, PinID: "c1i0"},
+		{Seq: 3, Action: model.ChangeAvatarUpdated, PinID: "v1i0"},
+	}
+	cfg := conf.IndexerEventStreamConfig{
+		TopicPrefix: "mfs",
+		Format:      eventstream.FormatJSON,
+		Actions:     []string{"file_created", "avatar_updated"},
+		BatchSize:   2,
+		CursorFile:  filepath.Join(t.TempDir(), "stream", "cursor"),
+	}
+	pub := &fakePublisher{err: errors.New("broker down")}
+	stream, err := newEventStream(pub, changes, cfg)
+	if err != nil {
+		t.Fatalf("newEventStream: %v", err)
+	}
+
+	if _, err := stream.publishNext(); err == nil || stream.cursor != 0 {
+		t.Fatalf("failed publish: err %v, cursor %d; want error and cursor kept", err, stream.cursor)
+	}
+	pub.err = nil
+	if n, err := stream.publishNext(); err != nil || n != 2 || stream.cursor != 2 {
+		t.Fatalf("publishNext = %d, %v, cursor %d", n, err, stream.cursor)
+	}
+	if len(pub.sent) != 1 || pub.sent[0].Topic != "mfs.file_created" {
+		t.Errorf("sent = %+v, want only file_created (chunk_indexed filtered)", pub.sent)
+	}
+
+	// A restart resumes after the saved cursor
+	stream, err = newEventStream(pub, changes, cfg)
+	if err != nil || stream.cursor != 2 {
+		t.Fatalf("reopened cursor = %d, %v; want 2", stream.cursor, err)
+	}
+	if n, _ := stream.publishNext(); n != 1 || len(pub.sent) != 2 || pub.sent[1].Topic != "mfs.avatar_updated" {
+		t.Errorf("after restart read %d, sent %+v", n, pub.sent)
+	}
+	if n, _ := stream.publishNext(); n != 0 {
+		t.Errorf("caught up stream read %d events", n)
+	}
+}
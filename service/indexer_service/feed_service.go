@@ -0,0 +1,173 @@
+package indexer_service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"meta-file-system/model"
+	common_service "meta-file-system/service/common_service"
+)
+
+// Feed and sitemap limits
+const (
+	FeedCacheSize    = 1000 // Most recent public files kept in memory for feeds and the sitemap
+	DefaultFeedItems = 50
+	MaxFeedItems     = 100
+)
+
+// FeedFilter optional filters for the file feeds; empty fields match everything
+type FeedFilter struct {
+	Creator  string // MetaID or GlobalMetaID of the creator
+	FileType string // image/video/audio/document/other
+}
+
+// IsPublicFile reports whether a file may appear in public feeds and the sitemap
+// (successfully indexed and not encrypted)
+func IsPublicFile(file *model.IndexerFile) bool {
+	return file != nil && file.Status == model.StatusSuccess && (file.Encryption == "" || file.Encryption == "0")
+}
+
+// fileFeed keeps the newest public files (newest first). It is seeded from the
+// database on first read and then updated by the indexer as files are created,
+// so feeds and the sitemap never rescan the file collections.
+type fileFeed struct {
+	mu      sync.RWMutex
+	loaded  bool
+	files   []*model.IndexerFile
+	version uint64 // Bumped on every change; lets callers cache rendered output
+}
+
+var recentFiles = &fileFeed{}
+
+// add inserts a newly indexed file, keeping newest-first order and the cache bound
+func (f *fileFeed) add(file *model.IndexerFile) {
+	if !IsPublicFile(file) {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.files = mergeFeedFiles(f.files, []*model.IndexerFile{file})
+	f.version++
+}
+
+// snapshot returns the cached files and version, seeding the cache with load on first use
+func (f *fileFeed) snapshot(load func() ([]*model.IndexerFile, error)) ([]*model.IndexerFile, uint64, error) {
+	f.mu.RLock()
+	if f.loaded {
+		files, version := f.files, f.version
+		f.mu.RUnlock()
+		return files, version, nil
+	}
+	f.mu.RUnlock()
+
+	seed, err := load()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.loaded {
+		public := make([]*model.IndexerFile, 0, len(seed))
+		for _, file := range seed {
+			if IsPublicFile(file) {
+				public = append(public, file)
+			}
+		}
+		// Files added by the indexer while seeding are kept
+		f.files = mergeFeedFiles(f.files, public)
+		f.loaded = true
+		f.version++
+	}
+	return f.files, f.version, nil
+}
+
+// mergeFeedFiles returns a new newest-first slice of existing plus added, deduplicated
+// by PIN ID and trimmed to FeedCacheSize. existing is never modified, so snapshots
+// handed out earlier stay valid.
+func mergeFeedFiles(existing, added []*model.IndexerFile) []*model.IndexerFile {
+	seen := make(map[string]bool, len(existing)+len(added))
+	merged := make([]*model.IndexerFile, 0, len(existing)+len(added))
+	for _, list := range [][]*model.IndexerFile{added, existing} {
+		for _, file := range list {
+			if seen[file.PinID] {
+				continue
+			}
+			seen[file.PinID] = true
+			merged = append(merged, file)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp > merged[j].Timestamp
+	})
+	if len(merged) > FeedCacheSize {
+		merged = merged[:FeedCacheSize]
+	}
+	return merged
+}
+
+// publishIndexedFile makes a newly indexed file visible to feeds and the sitemap
+func publishIndexedFile(file *model.IndexerFile) {
+	recentFiles.add(file)
+}
+
+// recentPublicFiles returns the cached newest public files and the cache version
+func (s *IndexerFileService) recentPublicFiles() ([]*model.IndexerFile, uint64, error) {
+	return recentFiles.snapshot(func() ([]*model.IndexerFile, error) {
+		files, _, _, err := s.indexerFileDAO.ListWithCursor(0, FeedCacheSize)
+		return files, err
+	})
+}
+
+// GetFeedFiles get the newest public files for the RSS/Atom feeds, newest first.
+// Creator-filtered feeds read the creator index; unfiltered feeds use the
+// in-memory cache maintained by the indexer.
+func (s *IndexerFileService) GetFeedFiles(filter FeedFilter, limit int) ([]*model.IndexerFile, error) {
+	if limit < 1 || limit > MaxFeedItems {
+		limit = DefaultFeedItems
+	}
+	fileFilter, err := NormalizeFileFilter(model.IndexerFileFilter{FileType: filter.FileType})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*model.IndexerFile
+	if filter.Creator != "" {
+		// Over-fetch so encrypted files can be dropped without a short page
+		if common_service.IsGlobalMetaId(filter.Creator) {
+			candidates, _, _, err = s.GetFilesByCreatorGlobalMetaID(filter.Creator, fileFilter, 0, MaxFeedItems, true)
+		} else {
+			candidates, _, _, err = s.GetFilesByCreatorMetaID(filter.Creator, fileFilter, 0, MaxFeedItems, true)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get creator files: %w", err)
+		}
+	} else {
+		candidates, _, err = s.recentPublicFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get recent files: %w", err)
+		}
+	}
+
+	files := make([]*model.IndexerFile, 0, limit)
+	for _, file := range candidates {
+		if len(files) == limit {
+			break
+		}
+		if IsPublicFile(file) && fileFilter.Matches(file) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// GetSitemapFiles get the newest public files (up to FeedCacheSize) for the sitemap,
+// with a version that changes whenever a new public file is indexed
+func (s *IndexerFileService) GetSitemapFiles() ([]*model.IndexerFile, uint64, error) {
+	files, version, err := s.recentPublicFiles()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get recent files: %w", err)
+	}
+	return files, version, nil
+}
@@ -0,0 +1,108 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func feedFile(pinID string, ts int64, encryption string) *model.IndexerFile {
+	return &model.IndexerFile{PinID: pinID, Timestamp: ts, Encryption: encryption, Status: model.StatusSuccess}
+}
+
+func feedPinIDs(files []*model.IndexerFile) []string {
+	ids := make([]string, 0, len(files))
+	for _, f := range files {
+		ids = append(ids, f.PinID)
+	}
+	return ids
+}
+
+func TestFileFeedSeedAndIncrementalAdd(t *testing.T) {
+	feed := &fileFeed{}
+
+	// Added before the first read: kept alongside the seeded files
+	feed.add(feedFile("early", 25, "0"))
+	feed.add(feedFile("secret", 40, "ecies"))
+
+	loads := 0
+	load := func() ([]*model.IndexerFile, error) {
+		loads++
+		return []*model.IndexerFile{
+			feedFile("b", 20, ""),
+			feedFile("a", 10, ""),
+			feedFile("early", 25, "0"),
+			{PinID: "failed", Timestamp: 30, Status: model.StatusFailed},
+		}, nil
+	}
+
+	files, v1, err := feed.snapshot(load)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if got, want := feedPinIDs(files), []string{"early", "b", "a"}; !equalStrings(got, want) {
+		t.Fatalf("seeded feed = %v, want %v", got, want)
+	}
+
+	feed.add(feedFile("c", 30, ""))
+	files, v2, err := feed.snapshot(load)
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if loads != 1 {
+		t.Errorf("load called %d times, want 1", loads)
+	}
+	if v2 == v1 {
+		t.Error("version must change when a file is added")
+	}
+	if got, want := feedPinIDs(files), []string{"c", "early", "b", "a"}; !equalStrings(got, want) {
+		t.Errorf("feed after add = %v, want %v", got, want)
+	}
+}
+
+func TestFileFeedSeedError(t *testing.T) {
+	feed := &fileFeed{}
+	if _, _, err := feed.snapshot(func() ([]*model.IndexerFile, error) {
+		return nil, errors.New("db down")
+	}); err == nil {
+		t.Fatal("snapshot: want error from loader")
+	}
+	// A later successful load still seeds the cache
+	files, _, err := feed.snapshot(func() ([]*model.IndexerFile, error) {
+		return []*model.IndexerFile{feedFile("a", 1, "")}, nil
+	})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("snapshot after error = %v, %v; want 1 file", files, err)
+	}
+}
+
+func TestMergeFeedFilesBound(t *testing.T) {
+	var existing []*model.IndexerFile
+	for i := 0; i < FeedCacheSize; i++ {
+		existing = append(existing, feedFile(fmt.Sprintf("pin%d", i), int64(FeedCacheSize-i), ""))
+	}
+	merged := mergeFeedFiles(existing, []*model.IndexerFile{feedFile("newest", int64(FeedCacheSize+1), "")})
+	if len(merged) != FeedCacheSize {
+		t.Fatalf("len = %d, want %d", len(merged), FeedCacheSize)
+	}
+	if merged[0].PinID != "newest" {
+		t.Errorf("first = %s, want newest", merged[0].PinID)
+	}
+	if len(existing) != FeedCacheSize || existing[0].PinID == "newest" {
+		t.Error("existing slice must not be modified")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package indexer_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrNotMultiChunkFile is returned for a PIN that is not a multi-chunk index
+var ErrNotMultiChunkFile = errors.New("not a multi-chunk file")
+
+// ErrIndexNotFound is returned for an index PIN this indexer has not seen
+var ErrIndexNotFound = errors.New("index not found")
+
+// Chunk status strings of FileChunkInfo
+const (
+	ChunkStatusIndexed  = "indexed"  // Chunk PIN indexed and stored
+	ChunkStatusMissing  = "missing"  // Chunk PIN not indexed (yet)
+	ChunkStatusRejected = "rejected" // Chunk content refused (e.g. a gzip bomb)
+)
+
+// FileChunkInfo one chunkList entry of a multi-chunk file and what the
+// indexer has for it
+type FileChunkInfo struct {
+	Index          int    `json:"index"`                    // Position in chunkList
+	PinID          string `json:"pinId"`                    // Chunk PIN ID listed in the index
+	ResolvedPinID  string `json:"resolvedPinId,omitempty"`  // Indexed PIN used instead (same sha256), when the listed one is not indexed
+	Offset         int64  `json:"offset"`                   // Byte offset in the file
+	Size           int64  `json:"size"`                     // Chunk size listed in (or derived from) the index
+	Sha256         string `json:"sha256"`                   // Chunk sha256 listed in the index
+	Status         string `json:"status"`                   // indexed, missing or rejected
+	StatusReason   string `json:"statusReason,omitempty"`   // Why the chunk was rejected
+	StoredSize     int64  `json:"storedSize,omitempty"`     // Size of the stored chunk
+	HashMatch      bool   `json:"hashMatch"`                // Stored chunk sha256 matches the index
+	HashMismatched bool   `json:"hashMismatched,omitempty"` // The last merge attempt refused this chunk
+	ParentPinID    string `json:"parentPinId,omitempty"`    // Index the stored chunk is attached to
+	ChainName      string `json:"chainName,omitempty"`      // Chain of the chunk PIN
+	BlockHeight    int64  `json:"blockHeight,omitempty"`    // Block of the chunk PIN (0 = mempool)
+	Confirmed      bool   `json:"confirmed"`                // Chunk PIN is in a block
+}
+
+// FileChunksResult chunk listing and merge status of a multi-chunk file
+type FileChunksResult struct {
+	IndexPinID    string          `json:"indexPinId"`           // Index PIN ID
+	ChainName     string          `json:"chainName"`            // Chain of the index PIN
+	Version       int             `json:"version"`              // Index schema version
+	FileName      string          `json:"fileName"`             // File name from the index
+	FileSize      int64           `json:"fileSize"`             // File size from the index
+	ChunkSize     int64           `json:"chunkSize"`            // Chunk size from the index
+	ChunkNumber   int             `json:"chunkNumber"`          // Number of chunks
+	ChunksIndexed int             `json:"chunksIndexed"`        // Chunks with status indexed
+	MergeStatus   string          `json:"mergeStatus"`          // merged, pending or rejected (FileStatus values)
+	Merged        bool            `json:"merged"`               // The merged file exists in storage
+	MergeError    string          `json:"mergeError,omitempty"` // Last merge failure or rejection reason
+	Chunks        []FileChunkInfo `json:"chunks"`               // Chunks in chunkList order
+}
+
+// GetFileChunks lists the chunks of the multi-chunk file indexed by
+// indexPinID with their indexing state, and whether the merged file exists,
+// so clients can show assembly progress and find missing chunks. Works for
+// merged files as well as for indexes still waiting for chunks.
+func (s *IndexerFileService) GetFileChunks(indexPinID string) (*FileChunksResult, error) {
+	if indexPinID == "" {
+		return nil, errors.New("pinID is empty")
+	}
+
+	var (
+		indexJSON string
+		result    = &FileChunksResult{IndexPinID: indexPinID}
+		mismatch  string
+	)
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	if file != nil {
+		if file.ChunkType != model.ChunkTypeMulti {
+			return nil, ErrNotMultiChunkFile
+		}
+		indexJSON = file.Data
+		result.ChainName = file.ChainName
+		result.MergeStatus = FileStatusMerged
+		if file.Status == model.StatusRejected {
+			result.MergeStatus = FileStatusRejected
+			result.MergeError = file.StatusReason
+		} else {
+			result.Merged = file.StoragePath != "" && s.storage.Exists(file.StoragePath)
+		}
+	} else {
+		pending, err := s.pendingIndexFileDAO.GetByPinID(indexPinID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pending index: %w", err)
+		}
+		if pending == nil {
+			return nil, ErrIndexNotFound
+		}
+		indexJSON = pending.IndexJSON
+		result.ChainName = pending.ChainName
+		result.MergeStatus = FileStatusPending
+		if pending.Status == model.StatusRejected {
+			result.MergeStatus = FileStatusRejected
+		}
+		result.MergeError = pending.LastError
+		mismatch = pending.MismatchChunkPinID
+	}
+
+	var metaFileIndex metaid_protocols.MetaFileIndex
+	if err := json.Unmarshal([]byte(indexJSON), &metaFileIndex); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	result.Version = metaFileIndex.IndexVersion()
+	result.FileName = metaFileIndex.Name
+	result.FileSize = metaFileIndex.FileSize
+	result.ChunkSize = metaFileIndex.ChunkSize
+	result.ChunkNumber = metaFileIndex.ChunkNumber
+	result.Chunks = buildFileChunks(&metaFileIndex, func(pinID, sha256 string) *model.IndexerFileChunk {
+		return s.lookupIndexChunk(pinID, sha256, indexPinID)
+	})
+	for i := range result.Chunks {
+		chunk := &result.Chunks[i]
+		if chunk.Status == ChunkStatusIndexed {
+			result.ChunksIndexed++
+		}
+		if mismatch != "" && (chunk.PinID == mismatch || chunk.ResolvedPinID == mismatch) {
+			chunk.HashMismatched = true
+		}
+	}
+	return result, nil
+}
+
+// buildFileChunks describes every chunkList entry of an index, with lookup
+// returning the indexed chunk for an entry (nil when there is none)
+func buildFileChunks(metaFileIndex *metaid_protocols.MetaFileIndex, lookup func(pinID, sha256 string) *model.IndexerFileChunk) []FileChunkInfo {
+	chunks := make([]FileChunkInfo, 0, len(metaFileIndex.ChunkList))
+	for i, entry := range metaFileIndex.ChunkList {
+		offset, size := metaFileIndex.ChunkRange(i)
+		info := FileChunkInfo{
+			Index:  i,
+			PinID:  entry.PinId,
+			Offset: offset,
+			Size:   size,
+			Sha256: entry.Sha256,
+			Status: ChunkStatusMissing,
+		}
+		if chunk := lookup(entry.PinId, entry.Sha256); chunk != nil {
+			info.Status = ChunkStatusIndexed
+			if chunk.Status == model.StatusRejected {
+				info.Status = ChunkStatusRejected
+				info.StatusReason = chunk.StatusReason
+			}
+			if chunk.PinID != entry.PinId {
+				info.ResolvedPinID = chunk.PinID
+			}
+			info.StoredSize = chunk.ChunkSize
+			info.HashMatch = chunk.ChunkSha256 != "" && strings.EqualFold(chunk.ChunkSha256, entry.Sha256)
+			info.ChainName = chunk.ChainName
+			info.BlockHeight = chunk.BlockHeight
+			info.Confirmed = chunk.BlockHeight > 0
+			info.ParentPinID = chunk.ParentPinID
+		}
+		chunks = append(chunks, info)
+	}
+	return chunks
+}
+
+// lookupIndexChunk finds the indexed chunk of a chunkList entry without
+// changing anything: the listed PIN, else a chunk with the same sha256 that is
+// attached to this index or to no file yet (the one a merge would use).
+func (s *IndexerFileService) lookupIndexChunk(pinID, sha256, indexPinID string) *model.IndexerFileChunk {
+	if chunk, err := s.indexerFileChunkDAO.GetByPinID(pinID); err == nil && chunk != nil {
+		return chunk
+	}
+	if sha256 == "" {
+		return nil
+	}
+	candidates, err := s.indexerFileChunkDAO.ListBySha256(strings.ToLower(sha256))
+	if err != nil {
+		return nil
+	}
+	var unattached *model.IndexerFileChunk
+	for _, c := range candidates {
+		if c.ParentPinID == indexPinID {
+			return c
+		}
+		if c.ParentPinID == "" && unattached == nil {
+			unattached = c
+		}
+	}
+	return unattached
+}
@@ -0,0 +1,42 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+func TestBuildFileChunks(t *testing.T) {
+	idx := &metaid_protocols.MetaFileIndex{
+		FileSize:    250,
+		ChunkSize:   100,
+		ChunkNumber: 3,
+		ChunkList: []metaid_protocols.MetaFileChunk{
+			{Sha256: "aa", PinId: "c0i0"},
+			{Sha256: "bb", PinId: "c1i0"},
+			{Sha256: "cc", PinId: "c2i0"},
+		},
+	}
+	indexed := map[string]*model.IndexerFileChunk{
+		"aa": {PinID: "c0i0", ChunkSha256: "AA", ChunkSize: 100, BlockHeight: 10, ChainName: "mvc"},
+		"bb": {PinID: "r1i0", ChunkSha256: "bb", ChunkSize: 100, Status: model.StatusRejected, StatusReason: "too large"},
+	}
+	chunks := buildFileChunks(idx, func(pinID, sha256 string) *model.IndexerFileChunk { return indexed[sha256] })
+
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	c0 := chunks[0]
+	if c0.Status != ChunkStatusIndexed || !c0.HashMatch || !c0.Confirmed || c0.ResolvedPinID != "" || c0.Size != 100 {
+		t.Errorf("chunk 0 = %+v", c0)
+	}
+	c1 := chunks[1]
+	if c1.Status != ChunkStatusRejected || c1.StatusReason != "too large" || c1.ResolvedPinID != "r1i0" || c1.Confirmed || c1.Offset != 100 {
+		t.Errorf("chunk 1 = %+v", c1)
+	}
+	c2 := chunks[2]
+	if c2.Status != ChunkStatusMissing || c2.HashMatch || c2.Offset != 200 || c2.Size != 50 {
+		t.Errorf("chunk 2 = %+v", c2)
+	}
+}
@@ -0,0 +1,64 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrDeltaBaseNotIndexed is returned by applyFileDelta while the base PIN of a
+// delta has not been indexed (or could not be read); the merge is retried on
+// later blocks like one waiting for chunks
+var ErrDeltaBaseNotIndexed = errors.New("delta base file not indexed yet")
+
+// applyFileDelta rebuilds the file of a delta index from the indexed content
+// of its base PIN. Errors wrapping metaid_protocols.ErrInvalidDelta mean the
+// delta can never apply (wrong base, corrupt delta) and the file is rejected.
+// The rebuilt file may be at most the base plus the delta, or the gzip output
+// limit if that is larger, so a small delta cannot expand without bound.
+func (s *IndexerService) applyFileDelta(delta *metaid_protocols.MetaFileDelta, patch []byte) ([]byte, error) {
+	if err := metaid_protocols.ValidateMetaFileDelta(delta, nil); err != nil {
+		return nil, fmt.Errorf("%w: %v", metaid_protocols.ErrInvalidDelta, err)
+	}
+	base, err := s.indexerFileDAO.GetByPinID(delta.BasePinId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDeltaBaseNotIndexed, delta.BasePinId, err)
+	}
+	if base == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDeltaBaseNotIndexed, delta.BasePinId)
+	}
+	if base.Status == model.StatusRejected {
+		return nil, fmt.Errorf("%w: base %s was rejected: %s", metaid_protocols.ErrInvalidDelta, delta.BasePinId, base.StatusReason)
+	}
+	if base.StorageType == model.StorageTypeChunks {
+		return nil, fmt.Errorf("%w: base %s is too large to be merged", metaid_protocols.ErrInvalidDelta, delta.BasePinId)
+	}
+	baseContent, err := getBlob(s.storage, s.pendingStorageDAO, base.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDeltaBaseNotIndexed, delta.BasePinId, err)
+	}
+	if hash := calculateSHA256(baseContent); hash != delta.BaseSha256 {
+		return nil, fmt.Errorf("%w: base %s content has sha256 %s, the delta was made against %s", metaid_protocols.ErrInvalidDelta, delta.BasePinId, hash, delta.BaseSha256)
+	}
+
+	maxSize := decompressLimits().MaxBytes
+	if n := int64(len(baseContent) + len(patch)); n > maxSize {
+		maxSize = n
+	}
+	if delta.TargetSize > maxSize {
+		return nil, fmt.Errorf("%w: target of %d bytes exceeds %d bytes", metaid_protocols.ErrInvalidDelta, delta.TargetSize, maxSize)
+	}
+	rebuilt, err := metaid_protocols.ApplyDelta(baseContent, patch, delta.TargetSize)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(rebuilt)) != delta.TargetSize {
+		return nil, fmt.Errorf("%w: rebuilt %d bytes, targetSize is %d", metaid_protocols.ErrInvalidDelta, len(rebuilt), delta.TargetSize)
+	}
+	if hash := calculateSHA256(rebuilt); hash != delta.TargetSha256 {
+		return nil, fmt.Errorf("%w: rebuilt file has sha256 %s, targetSha256 is %s", metaid_protocols.ErrInvalidDelta, hash, delta.TargetSha256)
+	}
+	return rebuilt, nil
+}
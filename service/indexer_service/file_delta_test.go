@@ -0,0 +1,109 @@
+package indexer_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/storage"
+)
+
+// seedDeltaIndex creates a pending index whose single chunk is the delta from
+// base to target, declared against basePinID and baseSha256
+func seedDeltaIndex(t *testing.T, s *IndexerService, stor *storage.LocalStorage, indexPinID, basePinID, baseSha256 string, base, target []byte) {
+	t.Helper()
+	patch := metaid_protocols.EncodeDelta(base, target)
+	chunkList := seedChunks(t, s, stor, indexPinID, []string{string(patch)})
+
+	delta, err := json.Marshal(&metaid_protocols.MetaFileDelta{
+		Algorithm:    metaid_protocols.MetaFileDeltaMfsV1,
+		BasePinId:    basePinID,
+		BaseSha256:   baseSha256,
+		TargetSha256: calculateSHA256(target),
+		TargetSize:   int64(len(target)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metaData := `{"pinID":"` + indexPinID + `","chainName":"mvc","operation":"modify","creatorAddress":"1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}`
+	indexJSON := `{"version":2,"sha256":"` + calculateSHA256(patch) + `","fileSize":` + strconv.Itoa(len(patch)) +
+		`,"chunkNumber":1,"chunkList":[` + chunkList + `],"dataType":"text/plain","name":"f.txt","delta":` + string(delta) + `}`
+	if err := s.pendingIndexFileDAO.Create(&model.PendingIndexFile{
+		PinID: indexPinID, FirstPinID: basePinID, ChainName: "mvc",
+		MetaData: metaData, IndexJSON: indexJSON,
+	}); err != nil {
+		t.Fatalf("seed pending: %v", err)
+	}
+}
+
+// seedBaseFile indexes content as the file of pinID
+func seedBaseFile(t *testing.T, s *IndexerService, stor *storage.LocalStorage, pinID string, content []byte) {
+	t.Helper()
+	storagePath := "indexer/mvc/" + pinID
+	if err := stor.Save(storagePath, content); err != nil {
+		t.Fatalf("seed base storage: %v", err)
+	}
+	if err := s.indexerFileDAO.Create(&model.IndexerFile{
+		PinID: pinID, FirstPinID: pinID, ChainName: "mvc", StoragePath: storagePath,
+		FileSize: int64(len(content)), FileHash: calculateSHA256(content), Status: model.StatusSuccess,
+	}); err != nil {
+		t.Fatalf("seed base file: %v", err)
+	}
+}
+
+func TestRetryPendingIndexMerges_AppliesDelta(t *testing.T) {
+	s, stor := newMergeTestService(t)
+
+	base := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog. "), 100)
+	target := append(append([]byte{}, base[:2000]...), []byte("a new sentence. ")...)
+	target = append(target, base[2000:]...)
+
+	const basePinID, indexPinID = "basepin-1i0", "deltapin-1i0"
+	seedDeltaIndex(t, s, stor, indexPinID, basePinID, calculateSHA256(base), base, target)
+
+	// The base is not indexed yet: the merge waits for it
+	s.retryPendingIndexMerges("mvc")
+	if file, _ := s.indexerFileDAO.GetByPinID(indexPinID); file != nil {
+		t.Fatalf("delta merged without its base: %+v", file)
+	}
+	if got, _ := s.pendingIndexFileDAO.GetByPinID(indexPinID); got == nil {
+		t.Fatal("pending delta index dropped while its base is missing")
+	}
+
+	seedBaseFile(t, s, stor, basePinID, base)
+	s.retryPendingIndexMerges("mvc")
+
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil || file == nil {
+		t.Fatalf("delta not merged: %v", err)
+	}
+	if file.Status != model.StatusSuccess || file.FileSize != int64(len(target)) || file.FileHash != calculateSHA256(target) {
+		t.Errorf("merged delta file: status %s size %d hash %s", file.Status, file.FileSize, file.FileHash)
+	}
+	content, err := stor.Get(file.StoragePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, target) {
+		t.Error("stored content is not the rebuilt file")
+	}
+}
+
+func TestRetryPendingIndexMerges_RejectsDeltaOfOtherBase(t *testing.T) {
+	s, stor := newMergeTestService(t)
+
+	base := bytes.Repeat([]byte("0123456789"), 100)
+	const basePinID, indexPinID = "basepin-2i0", "deltapin-2i0"
+	seedBaseFile(t, s, stor, basePinID, base)
+	seedDeltaIndex(t, s, stor, indexPinID, basePinID, calculateSHA256([]byte("another base")), base, append(base, 'x'))
+
+	s.retryPendingIndexMerges("mvc")
+
+	file, _ := s.indexerFileDAO.GetByPinID(indexPinID)
+	if file == nil || file.Status != model.StatusRejected {
+		t.Fatalf("delta against the wrong base not rejected: %+v", file)
+	}
+}
@@ -0,0 +1,257 @@
+package indexer_service
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/storage"
+)
+
+// Large file limits used when indexer.max_merged_file_mb / stream_threshold_mb
+// are not configured
+const (
+	DefaultMaxMergedFileMB   = 1024
+	DefaultStreamThresholdMB = 64
+)
+
+// ErrFileStreamOnly is returned (wrapped) when the whole content of a file
+// kept as chunks only (model.StorageTypeChunks) is requested; it can only be
+// streamed with OpenFileContent
+var ErrFileStreamOnly = errors.New("file is only available as a stream")
+
+// maxMergedFileBytes the largest multi-chunk file merged into one stored blob
+func maxMergedFileBytes() int64 {
+	if conf.Cfg != nil && conf.Cfg.Indexer.MaxMergedFileMB > 0 {
+		return int64(conf.Cfg.Indexer.MaxMergedFileMB) * 1024 * 1024
+	}
+	return DefaultMaxMergedFileMB * 1024 * 1024
+}
+
+// streamThresholdBytes size above which multi-chunk files are served from
+// their chunks instead of being read into memory whole
+func streamThresholdBytes() int64 {
+	if conf.Cfg != nil && conf.Cfg.Indexer.StreamThresholdMB > 0 {
+		return int64(conf.Cfg.Indexer.StreamThresholdMB) * 1024 * 1024
+	}
+	return DefaultStreamThresholdMB * 1024 * 1024
+}
+
+// readFileBlob reads the stored content of file; files kept as chunks only
+// are never read whole
+func readFileBlob(st storage.Storage, queue *dao.PendingStorageWriteDAO, file *model.IndexerFile) ([]byte, error) {
+	if file.StorageType == model.StorageTypeChunks {
+		return nil, fmt.Errorf("%w: %s is %d bytes, kept as chunks", ErrFileStreamOnly, file.PinID, file.FileSize)
+	}
+	return getBlob(st, queue, file.StoragePath)
+}
+
+// saveChunkedIndex indexes a multi-chunk file larger than maxMergedFileBytes
+// without merging it: the chunks are verified one at a time and stay the only
+// stored copy, and the record has StorageType "chunks" and no StoragePath.
+// Whole-file gzip and delta indexes need the merged file and are rejected.
+func (s *IndexerService) saveChunkedIndex(
+	metaData *indexer.MetaIDData,
+	metaFileIndex *metaid_protocols.MetaFileIndex,
+	creatorAddress string,
+	chunks []*model.IndexerFileChunk,
+	firstPinID, firstPath string,
+	height, timestamp int64,
+	allChunksCompressed bool,
+) error {
+	if metaFileIndex.IsDelta() || (metaFileIndex.IsGzipCompressed() && !metaFileIndex.IsEncrypted()) {
+		reason := fmt.Sprintf("file over %d MB cannot be merged, and a gzip or delta index needs the merged file", maxMergedFileBytes()>>20)
+		return s.saveRejectedFile(metaData, model.ChunkTypeMulti, firstPinID, firstPath, creatorAddress, height, timestamp, reason)
+	}
+
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	realContentType := metaFileIndex.DataType
+	var size int64
+	for i, chunk := range chunks {
+		chunkContent, err := s.loadVerifiedChunk(metaFileIndex, i, chunk)
+		if err != nil {
+			return err
+		}
+		if i == 0 && !metaFileIndex.IsEncrypted() {
+			realContentType = detectRealContentType(chunkContent, metaFileIndex.DataType)
+		}
+		md5Hash.Write(chunkContent)
+		sha256Hash.Write(chunkContent)
+		size += int64(len(chunkContent))
+	}
+
+	fileHash := hex.EncodeToString(sha256Hash.Sum(nil))
+	if fileHash != metaFileIndex.Sha256 {
+		log.Printf("Warning: Chunked file hash mismatch. Expected: %s, Got: %s", metaFileIndex.Sha256, fileHash)
+	}
+	if size != metaFileIndex.FileSize {
+		log.Printf("Warning: Chunked file size mismatch. Expected: %d, Got: %d", metaFileIndex.FileSize, size)
+	}
+
+	indexerFile, err := newMergedIndexFile(metaData, metaFileIndex, creatorAddress, firstPinID, firstPath, height, timestamp)
+	if err != nil {
+		return err
+	}
+	indexerFile.FileExtension = mergedFileExtension(realContentType, metaFileIndex.Name)
+	indexerFile.FileType = detectFileType(realContentType)
+	indexerFile.FileSize = size
+	indexerFile.FileMd5 = hex.EncodeToString(md5Hash.Sum(nil))
+	indexerFile.FileHash = fileHash
+	indexerFile.IsGzipCompressed = allChunksCompressed
+	indexerFile.StorageType = model.StorageTypeChunks
+
+	log.Printf("File over %d MB kept as %d chunks: index PIN=%s (size: %d bytes)", maxMergedFileBytes()>>20, len(chunks), metaData.PinID, size)
+	return s.createMergedIndexFile(metaData, indexerFile)
+}
+
+// FileContent the content of a file to serve: the stored blob, or for a
+// large multi-chunk file its chunks, read one at a time by WriteTo
+type FileContent struct {
+	File        *model.IndexerFile
+	Size        int64
+	ContentType string
+	FileName    string
+
+	data   []byte
+	chunks []string // Storage paths of the chunks, in file order
+	read   func(storagePath string) ([]byte, error)
+}
+
+// Streamed reports whether the content is read from the chunks
+func (c *FileContent) Streamed() bool {
+	return c.chunks != nil
+}
+
+// WriteTo writes the content to w; a streamed file holds one chunk in memory
+// at a time. A chunk that cannot be read part way leaves w truncated.
+func (c *FileContent) WriteTo(w io.Writer) (int64, error) {
+	if c.chunks == nil {
+		n, err := w.Write(c.data)
+		return int64(n), err
+	}
+	var written int64
+	for i, path := range c.chunks {
+		chunkContent, err := c.read(path)
+		if err != nil {
+			return written, fmt.Errorf("failed to read chunk %d of %s: %w", i, c.File.PinID, err)
+		}
+		n, err := w.Write(chunkContent)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// OpenFileContent opens the content of the file of pinID. Multi-chunk files
+// kept as chunks, or larger than the stream threshold
+// (indexer.stream_threshold_mb), are streamed from their chunks; when the
+// chunks of a merged file are not all available its blob is read instead. A
+// missing blob is fetched from the peers (indexer.peers) when configured.
+func (s *IndexerFileService) OpenFileContent(pinID string) (*FileContent, error) {
+	return s.openFileContent(pinID, true)
+}
+
+// OpenLocalFileContent opens the content of the file of pinID like
+// OpenFileContent, without asking peers for a missing blob (used to answer
+// peers, see PeerFetchHeader)
+func (s *IndexerFileService) OpenLocalFileContent(pinID string) (*FileContent, error) {
+	return s.openFileContent(pinID, false)
+}
+
+func (s *IndexerFileService) openFileContent(pinID string, usePeers bool) (*FileContent, error) {
+	file, err := s.GetFileByPinID(pinID)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, errors.New("file not found")
+	}
+	if err := CheckProvisionalContent(file); err != nil {
+		return nil, err
+	}
+
+	content := &FileContent{
+		File:        file,
+		ContentType: file.ContentType,
+		FileName:    file.FileName,
+		read: func(storagePath string) ([]byte, error) {
+			return getBlob(s.storage, s.pendingStorageDAO, storagePath)
+		},
+	}
+	if file.ChunkType == model.ChunkTypeMulti && (file.StorageType == model.StorageTypeChunks || file.FileSize > streamThresholdBytes()) {
+		chunks, err := s.fileChunkPaths(file)
+		if err == nil {
+			content.chunks = chunks
+			content.Size = file.FileSize
+			return content, nil
+		}
+		if file.StorageType == model.StorageTypeChunks {
+			return nil, fmt.Errorf("failed to get file content: %w", err)
+		}
+		log.Printf("Streaming file %s from chunks failed, reading the merged file: %v", file.PinID, err)
+	}
+
+	read := s.readFile
+	if !usePeers {
+		read = func(file *model.IndexerFile) ([]byte, error) {
+			return readFileBlob(s.storage, s.pendingStorageDAO, file)
+		}
+	}
+	data, err := read(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file content: %w", err)
+	}
+	content.data = data
+	content.Size = int64(len(data))
+	return content, nil
+}
+
+// OpenLatestFileContent opens the content of the latest version of firstPinID
+func (s *IndexerFileService) OpenLatestFileContent(firstPinID string) (*FileContent, error) {
+	file, err := s.GetLatestFileByFirstPinID(firstPinID)
+	if err != nil {
+		return nil, err
+	}
+	return s.OpenFileContent(file.PinID)
+}
+
+// fileChunkPaths the storage paths of the chunks of a multi-chunk file, in
+// order. Fails when a chunk is not indexed, when the chunks do not add up to
+// the file size, or when the stored file is not the concatenated chunks
+// (whole-file gzip and delta indexes).
+func (s *IndexerFileService) fileChunkPaths(file *model.IndexerFile) ([]string, error) {
+	var metaFileIndex metaid_protocols.MetaFileIndex
+	if err := json.Unmarshal([]byte(file.Data), &metaFileIndex); err != nil {
+		return nil, fmt.Errorf("invalid file index: %w", err)
+	}
+	if metaFileIndex.IsDelta() || (metaFileIndex.IsGzipCompressed() && !metaFileIndex.IsEncrypted()) {
+		return nil, errors.New("file is not stored as its chunks")
+	}
+
+	paths := make([]string, 0, len(metaFileIndex.ChunkList))
+	var size int64
+	for i, entry := range metaFileIndex.ChunkList {
+		chunk := s.lookupIndexChunk(entry.PinId, entry.Sha256, file.PinID)
+		if chunk == nil || chunk.StoragePath == "" {
+			return nil, fmt.Errorf("chunk %d (PIN=%s) is not indexed", i, entry.PinId)
+		}
+		paths = append(paths, chunk.StoragePath)
+		size += chunk.ChunkSize
+	}
+	if size != file.FileSize {
+		return nil, fmt.Errorf("chunks hold %d bytes, file has %d", size, file.FileSize)
+	}
+	return paths, nil
+}
@@ -0,0 +1,147 @@
+package indexer_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/storage"
+)
+
+// seedLargeIndex seeds three 400 KiB chunks (1.2 MiB, over a 1 MB limit) and
+// returns the file content and its index
+func seedLargeIndex(t *testing.T, s *IndexerService, stor *storage.LocalStorage, indexPinID string) ([]byte, *metaid_protocols.MetaFileIndex) {
+	t.Helper()
+	const chunkSize = 400 << 10
+	content := make([]byte, 3*chunkSize)
+	rand.New(rand.NewSource(1)).Read(content)
+	var chunks []string
+	for i := 0; i < 3; i++ {
+		chunks = append(chunks, string(content[i*chunkSize:(i+1)*chunkSize]))
+	}
+	chunkList := seedChunks(t, s, stor, indexPinID, chunks)
+
+	index := &metaid_protocols.MetaFileIndex{}
+	indexJSON := `{"chunkNumber":3,"chunkSize":409600,"dataType":"application/octet-stream","name":"big.bin","chunkList":[` + chunkList + `]}`
+	if err := json.Unmarshal([]byte(indexJSON), index); err != nil {
+		t.Fatal(err)
+	}
+	index.Sha256 = calculateSHA256(content)
+	index.FileSize = int64(len(content))
+	return content, index
+}
+
+func mergeLargeIndex(t *testing.T, s *IndexerService, indexPinID string, index *metaid_protocols.MetaFileIndex) {
+	t.Helper()
+	chunks := make([]*model.IndexerFileChunk, 0, len(index.ChunkList))
+	for _, entry := range index.ChunkList {
+		chunk, err := s.indexerFileChunkDAO.GetByPinID(entry.PinId)
+		if err != nil || chunk == nil {
+			t.Fatalf("chunk %s: %v", entry.PinId, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	metaData := &indexer.MetaIDData{PinID: indexPinID, Path: "/file/big.bin", Operation: "create", ChainName: "mvc"}
+	if err := s.mergeAndSaveIndex(metaData, index, "1BoatSLRHtKNngkdXEeobR76b53LETtpyT", chunks, "", "/file/big.bin", 100, 1700000000000); err != nil {
+		t.Fatalf("mergeAndSaveIndex: %v", err)
+	}
+}
+
+func TestMergeAndSaveIndex_KeepsFileOverMaxAsChunks(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	conf.Cfg.Indexer.MaxMergedFileMB = 1
+	const indexPinID = "bigidx1i0"
+	content, index := seedLargeIndex(t, s, stor, indexPinID)
+	mergeLargeIndex(t, s, indexPinID, index)
+
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if file.Status != model.StatusSuccess || file.StorageType != model.StorageTypeChunks || file.StoragePath != "" {
+		t.Fatalf("file = %s %s %q, want a success record kept as chunks", file.Status, file.StorageType, file.StoragePath)
+	}
+	if file.FileSize != int64(len(content)) || file.FileHash != index.Sha256 || file.FileMd5 != calculateMD5(content) {
+		t.Errorf("FileSize=%d FileHash=%s FileMd5=%s, want the hashes of the whole file", file.FileSize, file.FileHash, file.FileMd5)
+	}
+
+	fileService := NewIndexerFileService(stor)
+	if _, _, _, err := fileService.GetFileContent(indexPinID); !errors.Is(err, ErrFileStreamOnly) {
+		t.Errorf("GetFileContent err = %v, want ErrFileStreamOnly", err)
+	}
+	fileContent, err := fileService.OpenFileContent(indexPinID)
+	if err != nil {
+		t.Fatalf("OpenFileContent: %v", err)
+	}
+	var buf bytes.Buffer
+	if n, err := fileContent.WriteTo(&buf); err != nil || !fileContent.Streamed() || n != fileContent.Size {
+		t.Fatalf("WriteTo = %d, %v (streamed %v), want %d bytes streamed", n, err, fileContent.Streamed(), fileContent.Size)
+	}
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Error("streamed content differs from the file")
+	}
+}
+
+func TestMergeAndSaveIndex_RejectsGzipIndexOverMax(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	conf.Cfg.Indexer.MaxMergedFileMB = 1
+	const indexPinID = "bigidx2i0"
+	_, index := seedLargeIndex(t, s, stor, indexPinID)
+	index.Version = metaid_protocols.MetaFileIndexV2
+	index.Compression = metaid_protocols.MetaFileCompressionGzip
+	mergeLargeIndex(t, s, indexPinID, index)
+
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if file.Status != model.StatusRejected {
+		t.Errorf("Status = %s, want rejected", file.Status)
+	}
+}
+
+func TestOpenFileContent_StreamsMergedFileOverThreshold(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	conf.Cfg.Indexer.StreamThresholdMB = 1
+	const indexPinID = "bigidx3i0"
+	content, index := seedLargeIndex(t, s, stor, indexPinID)
+	mergeLargeIndex(t, s, indexPinID, index)
+
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil || file == nil || file.StoragePath == "" {
+		t.Fatalf("file = %+v (%v), want a merged file", file, err)
+	}
+	fileService := NewIndexerFileService(stor)
+	fileContent, err := fileService.OpenFileContent(indexPinID)
+	if err != nil {
+		t.Fatalf("OpenFileContent: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := fileContent.WriteTo(&buf); err != nil || !fileContent.Streamed() || !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("WriteTo err = %v, streamed %v, want the file streamed from its chunks", err, fileContent.Streamed())
+	}
+
+	// With a chunk unavailable the merged file is read instead
+	chunk, err := s.indexerFileChunkDAO.GetByPinID(index.ChunkList[0].PinId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk.StoragePath = ""
+	if err := s.indexerFileChunkDAO.Update(chunk); err != nil {
+		t.Fatal(err)
+	}
+	fileContent, err = fileService.OpenFileContent(indexPinID)
+	if err != nil {
+		t.Fatalf("OpenFileContent without chunks: %v", err)
+	}
+	buf.Reset()
+	if _, err := fileContent.WriteTo(&buf); err != nil || fileContent.Streamed() || !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("WriteTo err = %v, streamed %v, want the merged file", err, fileContent.Streamed())
+	}
+}
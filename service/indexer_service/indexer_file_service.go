@@ -1,19 +1,20 @@
 package indexer_service
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"time"
 
 	"meta-file-system/conf"
 	"meta-file-system/database"
 	"meta-file-system/model"
 	"meta-file-system/model/dao"
 	common_service "meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/metaid_protocols"
 	"meta-file-system/storage"
 
 	"gorm.io/gorm"
@@ -30,18 +31,29 @@ const (
 // IndexerFileService indexer file service
 type IndexerFileService struct {
 	indexerFileDAO       *dao.IndexerFileDAO
+	indexerFileChunkDAO  *dao.IndexerFileChunkDAO
 	indexerUserAvatarDAO *dao.IndexerUserAvatarDAO
 	pendingIndexFileDAO  *dao.PendingIndexFileDAO
+	pendingStorageDAO    *dao.PendingStorageWriteDAO
 	storage              storage.Storage
+	mirror               *upstreamMirror // Upstream fetched on a miss (indexer.mirror); nil when off
+	peers                *peerGateways   // Asked for missing blobs (indexer.peers); nil when none
+
+	siteMu sync.Mutex
+	sites  map[string]*metaid_protocols.SiteManifest // Parsed site manifests by PIN ID
 }
 
 // NewIndexerFileService create indexer file service instance
 func NewIndexerFileService(storage storage.Storage) *IndexerFileService {
 	return &IndexerFileService{
 		indexerFileDAO:       dao.NewIndexerFileDAO(),
+		indexerFileChunkDAO:  dao.NewIndexerFileChunkDAO(),
 		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
 		pendingIndexFileDAO:  dao.NewPendingIndexFileDAO(),
+		pendingStorageDAO:    dao.NewPendingStorageWriteDAO(),
 		storage:              storage,
+		mirror:               newUpstreamMirror(conf.Cfg.Indexer.Mirror),
+		peers:                newPeerGateways(conf.Cfg.Indexer.Peers),
 	}
 }
 
@@ -76,7 +88,8 @@ func (s *IndexerFileService) GetLatestFileByFirstPinID(firstPinID string) (*mode
 	return file, nil
 }
 
-// GetFileByPinID get file information by PIN ID
+// GetFileByPinID get file information by PIN ID. In mirror mode a PIN not
+// found locally is fetched from the upstream indexer and cached.
 func (s *IndexerFileService) GetFileByPinID(pinID string) (*model.IndexerFile, error) {
 	file, err := s.indexerFileDAO.GetByPinID(pinID)
 	if err != nil {
@@ -85,17 +98,78 @@ func (s *IndexerFileService) GetFileByPinID(pinID string) (*model.IndexerFile, e
 		}
 		return nil, fmt.Errorf("failed to get file: %w", err)
 	}
+	if file == nil && s.mirror != nil {
+		if file, err = s.mirrorFile(pinID); err != nil {
+			log.Printf("[Mirror] Failed to fetch %s from upstream: %v", pinID, err)
+			return nil, errors.New("file not found")
+		}
+	}
 	if file == nil {
 		return nil, errors.New("file not found")
 	}
+	if file.Status == model.StatusRejected {
+		return nil, fmt.Errorf("file rejected: %s", file.StatusReason)
+	}
 	return file, nil
 }
 
+// MaxBatchPinIDs limits how many PIN IDs GetFilesByPinIDs accepts per call.
+const MaxBatchPinIDs = 100
+
+// GetFilesByPinIDs get files for many PIN IDs in one lookup. Duplicate IDs are
+// collapsed; files come back in request order and IDs without a file are
+// returned in missing instead of failing the whole call.
+func (s *IndexerFileService) GetFilesByPinIDs(pinIDs []string) ([]*model.IndexerFile, []string, error) {
+	seen := make(map[string]bool, len(pinIDs))
+	unique := make([]string, 0, len(pinIDs))
+	for _, pinID := range pinIDs {
+		pinID = strings.TrimSpace(pinID)
+		if pinID == "" || seen[pinID] {
+			continue
+		}
+		seen[pinID] = true
+		unique = append(unique, pinID)
+	}
+	if len(unique) == 0 {
+		return []*model.IndexerFile{}, []string{}, nil
+	}
+	if len(unique) > MaxBatchPinIDs {
+		return nil, nil, fmt.Errorf("too many pin_ids: %d (max %d)", len(unique), MaxBatchPinIDs)
+	}
+
+	found, err := s.indexerFileDAO.GetByPinIDs(unique)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get files: %w", err)
+	}
+	byPinID := make(map[string]*model.IndexerFile, len(found))
+	for _, file := range found {
+		if file.Status == model.StatusSuccess {
+			byPinID[file.PinID] = file
+		}
+	}
+
+	files := make([]*model.IndexerFile, 0, len(byPinID))
+	missing := []string{}
+	for _, pinID := range unique {
+		if file, ok := byPinID[pinID]; ok {
+			files = append(files, file)
+		} else {
+			missing = append(missing, pinID)
+		}
+	}
+	return files, missing, nil
+}
+
 // FileStatus result of GetFileStatus. Status is one of:
 //   - "merged":     the IndexerFile record exists (file is servable via content).
 //   - "pending":    the pin was seen on chain (IndexerPinInfo) but not merged
 //                   yet — either waiting for a block / scan, or a deferred
 //                   multi-chunk merge (PendingIndexFile present).
+//   - "rejected":   the pin was indexed but its content refused (e.g. a gzip
+//                   bomb); Reason says why and the content is not served.
+//   - "pending_storage": the pin was indexed but the storage write failed; the
+//                   content is queued for retry (and still served from the
+//                   queue) and Reason holds the storage error.
 //   - "not_found":  the pin was never seen by this indexer.
 type FileStatus struct {
 	Status      string `json:"status"`
@@ -103,13 +177,17 @@ type FileStatus struct {
 	BlockHeight int64  `json:"blockHeight,omitempty"`
 	FileSize    int64  `json:"fileSize,omitempty"`
 	FileName    string `json:"fileName,omitempty"`
+	Reason      string `json:"reason,omitempty"`
 }
 
 // File status string constants returned by GetFileStatus.
 const (
 	FileStatusMerged   = "merged"
 	FileStatusPending  = "pending"
+	FileStatusRejected = "rejected"
 	FileStatusNotFound = "not_found"
+
+	FileStatusPendingStorage = "pending_storage"
 )
 
 // GetFileStatus reports the indexing state of a pinId without fetching file
@@ -121,8 +199,27 @@ func (s *IndexerFileService) GetFileStatus(pinID string) (*FileStatus, error) {
 		return nil, errors.New("pinID is empty")
 	}
 
-	// 1) Merged? -> IndexerFile exists.
+	// 1) Merged (or rejected)? -> IndexerFile exists.
 	if file, err := s.indexerFileDAO.GetByPinID(pinID); err == nil && file != nil {
+		if file.Status == model.StatusRejected {
+			return &FileStatus{
+				Status:      FileStatusRejected,
+				ChainName:   file.ChainName,
+				BlockHeight: file.BlockHeight,
+				FileName:    file.FileName,
+				Reason:      file.StatusReason,
+			}, nil
+		}
+		if file.Status == model.StatusPendingStorage {
+			return &FileStatus{
+				Status:      FileStatusPendingStorage,
+				ChainName:   file.ChainName,
+				BlockHeight: file.BlockHeight,
+				FileSize:    file.FileSize,
+				FileName:    file.FileName,
+				Reason:      file.StatusReason,
+			}, nil
+		}
 		return &FileStatus{
 			Status:      FileStatusMerged,
 			ChainName:   file.ChainName,
@@ -154,84 +251,110 @@ func (s *IndexerFileService) GetFileStatus(pinID string) (*FileStatus, error) {
 }
 
 // GetFilesByCreatorAddress get file list by creator address with cursor pagination
+// address: native chain address, or an ID address for the creator's files on every chain
 // cursor: number of records to skip (0 for first page)
 // size: page size
+// includeUnconfirmed: also list mempool-only files (default: confirmed files only)
 // Returns: files, next_cursor, has_more, error
-func (s *IndexerFileService) GetFilesByCreatorAddress(address string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+func (s *IndexerFileService) GetFilesByCreatorAddress(address string, cursor int64, size int, includeUnconfirmed bool) ([]*model.IndexerFile, int64, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
 
-	files, nextCursor, err := s.indexerFileDAO.GetByCreatorAddressWithCursor(address, cursor, size)
+	if common_service.IsGlobalMetaId(address) {
+		return s.GetFilesByCreatorGlobalMetaID(address, model.IndexerFileFilter{}, cursor, size, includeUnconfirmed)
+	}
+
+	files, nextCursor, hasMore, err := listFilesByOffset(func(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+		return s.indexerFileDAO.GetByCreatorAddressWithCursor(address, cursor, size)
+	}, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, 0, false, fmt.Errorf("failed to get files by creator address: %w", err)
 	}
 
-	// Determine has_more: if we got exactly size records, there might be more
-	hasMore := len(files) == size
-
 	return files, nextCursor, hasMore, nil
 }
 
+// NormalizeFileFilter validates a listing filter and canonicalizes its path prefix
+// (host and duplicate slashes stripped) so it matches stored paths.
+func NormalizeFileFilter(filter model.IndexerFileFilter) (model.IndexerFileFilter, error) {
+	filter.FileType = strings.ToLower(strings.TrimSpace(filter.FileType))
+	filter.ChainName = strings.ToLower(strings.TrimSpace(filter.ChainName))
+	filter.ContentTypePrefix = strings.ToLower(strings.TrimSpace(filter.ContentTypePrefix))
+	if raw := strings.TrimSpace(filter.PathPrefix); raw != "" {
+		parsed, err := metaid_protocols.ParseMetaIDPath(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid path prefix: %w", err)
+		}
+		if parsed.IsReference() {
+			return filter, errors.New("invalid path prefix: @pinId references are not paths")
+		}
+		filter.PathPrefix = parsed.Path
+	}
+	return filter, nil
+}
+
 // GetFilesByCreatorMetaID get file list by creator MetaID with cursor pagination
+// filter: optional file type / content type prefix / path prefix / chain filters
 // cursor: number of records to skip (0 for first page)
 // size: page size
+// includeUnconfirmed: also list mempool-only files (default: confirmed files only)
 // Returns: files, next_cursor, has_more, error
-func (s *IndexerFileService) GetFilesByCreatorMetaID(metaID string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+func (s *IndexerFileService) GetFilesByCreatorMetaID(metaID string, filter model.IndexerFileFilter, cursor int64, size int, includeUnconfirmed bool) ([]*model.IndexerFile, int64, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
 
-	files, nextCursor, err := s.indexerFileDAO.GetByCreatorMetaIDWithCursor(metaID, cursor, size)
+	files, nextCursor, hasMore, err := listFilesByOffset(func(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+		return s.indexerFileDAO.GetByCreatorMetaIDWithCursor(metaID, filter, cursor, size)
+	}, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, 0, false, fmt.Errorf("failed to get files by creator MetaID: %w", err)
 	}
 
-	// Determine has_more: if we got exactly size records, there might be more
-	hasMore := len(files) == size
-
 	return files, nextCursor, hasMore, nil
 }
 
 // GetFilesByCreatorGlobalMetaID get file list by creator GlobalMetaID with cursor pagination
-func (s *IndexerFileService) GetFilesByCreatorGlobalMetaID(globalMetaID string, cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+func (s *IndexerFileService) GetFilesByCreatorGlobalMetaID(globalMetaID string, filter model.IndexerFileFilter, cursor int64, size int, includeUnconfirmed bool) ([]*model.IndexerFile, int64, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
-	files, nextCursor, err := s.indexerFileDAO.GetByCreatorGlobalMetaIDWithCursor(globalMetaID, cursor, size)
+	files, nextCursor, hasMore, err := listFilesByOffset(func(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+		return s.indexerFileDAO.GetByCreatorGlobalMetaIDWithCursor(globalMetaID, filter, cursor, size)
+	}, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, 0, false, fmt.Errorf("failed to get files by creator GlobalMetaID: %w", err)
 	}
-	hasMore := len(files) == size
 	return files, nextCursor, hasMore, nil
 }
 
 // ListFiles get file list with cursor pagination
 // cursor: number of records to skip (0 for first page)
 // size: page size
+// includeUnconfirmed: also list mempool-only files (default: confirmed files only)
 // Returns: files, next_cursor, has_more, error
-func (s *IndexerFileService) ListFiles(cursor int64, size int) ([]*model.IndexerFile, int64, bool, error) {
+func (s *IndexerFileService) ListFiles(cursor int64, size int, includeUnconfirmed bool) ([]*model.IndexerFile, int64, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
 
-	files, nextCursor, err := s.indexerFileDAO.ListWithCursor(cursor, size)
+	files, nextCursor, hasMore, err := listFilesByOffset(s.indexerFileDAO.ListWithCursor, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, 0, false, fmt.Errorf("failed to list files: %w", err)
 	}
 
-	// Determine has_more: if we got exactly size records, there might be more
-	hasMore := len(files) == size
-
 	return files, nextCursor, hasMore, nil
 }
 
 // ListFilesByExtension get file list by file extension (global), reverse time order, key-based cursor pagination
-func (s *IndexerFileService) ListFilesByExtension(extension string, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+func (s *IndexerFileService) ListFilesByExtension(extension string, cursor string, size int, includeUnconfirmed bool) ([]*model.IndexerFile, string, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
-	files, nextCursor, err := s.indexerFileDAO.GetByExtensionWithCursor(extension, cursor, size)
+	files, nextCursor, err := listFilesByKey(func(cursor string, size int) ([]*model.IndexerFile, string, error) {
+		return s.indexerFileDAO.GetByExtensionWithCursor(extension, cursor, size)
+	}, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, "", false, fmt.Errorf("failed to list files by extension: %w", err)
 	}
@@ -240,11 +363,13 @@ func (s *IndexerFileService) ListFilesByExtension(extension string, cursor strin
 }
 
 // ListFilesByGlobalMetaIDAndExtension get file list by globalMetaID and file extension, reverse time order, key-based cursor pagination
-func (s *IndexerFileService) ListFilesByGlobalMetaIDAndExtension(globalMetaID string, extension string, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+func (s *IndexerFileService) ListFilesByGlobalMetaIDAndExtension(globalMetaID string, extension string, cursor string, size int, includeUnconfirmed bool) ([]*model.IndexerFile, string, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
-	files, nextCursor, err := s.indexerFileDAO.GetByGlobalMetaIDAndExtensionWithCursor(globalMetaID, extension, cursor, size)
+	files, nextCursor, err := listFilesByKey(func(cursor string, size int) ([]*model.IndexerFile, string, error) {
+		return s.indexerFileDAO.GetByGlobalMetaIDAndExtensionWithCursor(globalMetaID, extension, cursor, size)
+	}, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, "", false, fmt.Errorf("failed to list files by globalMetaID and extension: %w", err)
 	}
@@ -253,11 +378,13 @@ func (s *IndexerFileService) ListFilesByGlobalMetaIDAndExtension(globalMetaID st
 }
 
 // ListFilesByKeywordAndExtension get file list by keyword and file extension, reverse time order, key-based cursor pagination
-func (s *IndexerFileService) ListFilesByKeywordAndExtension(keyword string, extension string, cursor string, size int) ([]*model.IndexerFile, string, bool, error) {
+func (s *IndexerFileService) ListFilesByKeywordAndExtension(keyword string, extension string, cursor string, size int, includeUnconfirmed bool) ([]*model.IndexerFile, string, bool, error) {
 	if size < 1 || size > 100 {
 		size = 20
 	}
-	files, nextCursor, err := s.indexerFileDAO.GetByKeywordAndExtensionWithCursor(keyword, extension, cursor, size)
+	files, nextCursor, err := listFilesByKey(func(cursor string, size int) ([]*model.IndexerFile, string, error) {
+		return s.indexerFileDAO.GetByKeywordAndExtensionWithCursor(keyword, extension, cursor, size)
+	}, cursor, size, includeUnconfirmed)
 	if err != nil {
 		return nil, "", false, fmt.Errorf("failed to list files by keyword and extension: %w", err)
 	}
@@ -287,9 +414,12 @@ func (s *IndexerFileService) GetFileContent(pinID string) ([]byte, string, strin
 	if file == nil {
 		return nil, "", "", errors.New("file not found")
 	}
+	if err := CheckProvisionalContent(file); err != nil {
+		return nil, "", "", err
+	}
 
 	// Read file content from storage layer
-	content, err := s.storage.Get(file.StoragePath)
+	content, err := s.readFile(file)
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to get file content: %w", err)
 	}
@@ -302,6 +432,53 @@ func (s *IndexerFileService) GetFilesCount() (int64, error) {
 	return s.indexerFileDAO.GetFilesCount()
 }
 
+// GetFilesCountByChain get file count of one chain
+func (s *IndexerFileService) GetFilesCountByChain(chainName string) (int64, error) {
+	return database.DB.GetIndexerFilesCountByChain(chainName)
+}
+
+// GetFilesCountByCreatorMetaID get file count of a creator MetaID
+func (s *IndexerFileService) GetFilesCountByCreatorMetaID(metaID string) (int64, error) {
+	return database.DB.GetIndexerFilesCountByCreatorMetaID(metaID)
+}
+
+// GetFilesCountByCreatorGlobalMetaID get file count of a creator GlobalMetaID
+func (s *IndexerFileService) GetFilesCountByCreatorGlobalMetaID(globalMetaID string) (int64, error) {
+	return database.DB.GetIndexerFilesCountByCreatorGlobalMetaID(globalMetaID)
+}
+
+// ListCounters list the maintained counters whose name starts with prefix
+func (s *IndexerFileService) ListCounters(prefix string) (map[string]int64, error) {
+	return database.DB.ListCounters(prefix)
+}
+
+// ReconcileCounters recount the maintained counters and return the drifted
+// ones; with fix set the recounted values are stored
+func (s *IndexerFileService) ReconcileCounters(fix bool) ([]*model.CounterDrift, error) {
+	drifts, err := database.DB.ReconcileCounters(fix)
+	if err != nil {
+		return nil, err
+	}
+	if drifts == nil {
+		drifts = []*model.CounterDrift{}
+	}
+	return drifts, nil
+}
+
+// RepairLatestIndexes check the latest_* collections against history and
+// return the entries that are not the latest version; with fix set they are
+// rewritten
+func (s *IndexerFileService) RepairLatestIndexes(fix bool) ([]*model.LatestDrift, error) {
+	drifts, err := database.DB.RepairLatestIndexes(fix)
+	if err != nil {
+		return nil, err
+	}
+	if drifts == nil {
+		drifts = []*model.LatestDrift{}
+	}
+	return drifts, nil
+}
+
 // GetFilesCountByChains get file count for each chain
 func (s *IndexerFileService) GetFilesCountByChains() (map[string]int64, error) {
 	// Get all sync statuses to know which chains exist
@@ -324,6 +501,83 @@ func (s *IndexerFileService) GetFilesCountByChains() (map[string]int64, error) {
 	return chainStats, nil
 }
 
+// Daily stats range limits (days, inclusive of both ends)
+const (
+	DefaultDailyStatsDays = 30
+	MaxDailyStatsDays     = 366
+)
+
+// Weekly stats range limits (ISO weeks, Monday to Sunday)
+const (
+	DefaultWeeklyStatsWeeks = 12
+	MaxWeeklyStatsWeeks     = 53
+)
+
+// ParseDailyStatsRange parses an inclusive from/to day range (YYYY-MM-DD, UTC).
+// An empty to defaults to today and an empty from to DefaultDailyStatsDays
+// ending at to; ranges longer than MaxDailyStatsDays are rejected.
+func ParseDailyStatsRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start, end, err := parseStatsRange(from, to, now, DefaultDailyStatsDays)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > MaxDailyStatsDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range too large: %d days (max %d)", days, MaxDailyStatsDays)
+	}
+	return start, end, nil
+}
+
+// ParseWeeklyStatsRange parses from/to like ParseDailyStatsRange and widens the
+// range to whole ISO weeks: from moves back to its Monday and to forward to its
+// Sunday. An empty from defaults to DefaultWeeklyStatsWeeks ending at to;
+// ranges longer than MaxWeeklyStatsWeeks are rejected.
+func ParseWeeklyStatsRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start, end, err := parseStatsRange(from, to, now, DefaultWeeklyStatsWeeks*7)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	end = end.AddDate(0, 0, (7-int(end.Weekday()))%7)
+	if weeks := (int(end.Sub(start).Hours()/24) + 1) / 7; weeks > MaxWeeklyStatsWeeks {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range too large: %d weeks (max %d)", weeks, MaxWeeklyStatsWeeks)
+	}
+	return start, end, nil
+}
+
+// parseStatsRange parses an inclusive from/to day range, defaulting to to today
+// and from to defaultDays ending at to
+func parseStatsRange(from, to string, now time.Time, defaultDays int) (time.Time, time.Time, error) {
+	end := now.UTC().Truncate(24 * time.Hour)
+	if to = strings.TrimSpace(to); to != "" {
+		t, err := time.Parse(model.DailyStatDateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to date %q, expected YYYY-MM-DD", to)
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, -(defaultDays - 1))
+	if from = strings.TrimSpace(from); from != "" {
+		t, err := time.Parse(model.DailyStatDateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from date %q, expected YYYY-MM-DD", from)
+		}
+		start = t
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, errors.New("from date must not be after to date")
+	}
+	return start, end, nil
+}
+
+// GetDailyStats get per-chain daily stats for the inclusive day range [from, to]
+func (s *IndexerFileService) GetDailyStats(from, to time.Time) ([]*model.IndexerDailyStat, error) {
+	stats, err := database.DB.ListDailyStats(from.Format(model.DailyStatDateLayout), to.Format(model.DailyStatDateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily stats: %w", err)
+	}
+	return stats, nil
+}
+
 // ============================================================
 // Old Avatar methods - DEPRECATED (commented out)
 // Use new UserInfo methods instead
@@ -504,6 +758,7 @@ func (s *IndexerFileService) GetUserInfoByGlobalMetaID(globalMetaId, metaid stri
 	if avatarInfo != nil {
 		userInfo.Avatar = avatarInfo.AvatarUrl
 		userInfo.AvatarPinId = avatarInfo.PinID
+		userInfo.AvatarInvalid = avatarInfo.Invalid
 		// Use avatar's timestamp if it's later
 		if avatarInfo.Timestamp < userInfo.Timestamp {
 			userInfo.Timestamp = avatarInfo.Timestamp
@@ -524,7 +779,13 @@ func (s *IndexerFileService) GetUserInfoByGlobalMetaID(globalMetaId, metaid stri
 	}
 
 	if chatPubKeyInfo != nil {
-		userInfo.ChatPublicKey = chatPubKeyInfo.ChatPublicKey
+		// Never hand a malformed key to E2E chat clients; flag it instead
+		if chatPubKeyInfo.Invalid {
+			userInfo.ChatPublicKeyInvalid = true
+		} else {
+			userInfo.ChatPublicKey = chatPubKeyInfo.ChatPublicKey
+			userInfo.ChatPublicKeyType = chatPubKeyInfo.KeyType
+		}
 		userInfo.ChatPublicKeyPinId = chatPubKeyInfo.PinID
 		// Use chat public key's timestamp if it's later
 		if chatPubKeyInfo.Timestamp < userInfo.Timestamp {
@@ -663,7 +924,11 @@ func (s *IndexerFileService) GetUserInfoByMetaID(metaID string) (*model.IndexerU
 // GetUserInfoByAddress get user information by address
 func (s *IndexerFileService) GetUserInfoByAddress(address string) (*model.IndexerUserInfo, error) {
 
-	globalMetaId := common_service.ConvertToGlobalMetaId(address)
+	// An ID address is already the GlobalMetaID shared by the user's addresses on every chain
+	globalMetaId := address
+	if !common_service.IsGlobalMetaId(address) {
+		globalMetaId = common_service.ConvertToGlobalMetaId(address)
+	}
 
 	// Try to get from cache first
 	// cacheKey := "user:address:" + address
@@ -678,7 +943,7 @@ func (s *IndexerFileService) GetUserInfoByAddress(address string) (*model.Indexe
 
 	// Cache miss, query from databases
 	// Calculate MetaID from address (SHA256)
-	// metaID := calculateMetaIDFromAddress(address)
+	// metaID := metaid.Derive(address)
 
 	// userInfo, err := s.GetUserInfoByMetaID(metaID)
 	userInfo, err := s.GetUserInfoByGlobalMetaID(globalMetaId, "")
@@ -902,15 +1167,6 @@ func (s *IndexerFileService) rebuildUserNameCache() error {
 	return nil
 }
 
-// calculateMetaIDFromAddress calculate MetaID from address (SHA256 hash)
-func calculateMetaIDFromAddress(address string) string {
-	if address == "" {
-		return ""
-	}
-	hash := sha256.Sum256([]byte(address))
-	return hex.EncodeToString(hash[:])
-}
-
 // GetLatestFastFileOSSURLByFirstPinID get latest OSS URL for fast file content redirect by first PIN ID
 // processType: "preview" for image preview (640), "thumbnail" for thumbnail (235), "video" for video first frame, "" for original
 // Returns: OSS URL, ContentType, FileName, FileType, error
@@ -938,6 +1194,9 @@ func (s *IndexerFileService) GetFastFileOSSURL(pinID string, processType string)
 	if file == nil {
 		return "", "", "", "", errors.New("file not found")
 	}
+	if err := CheckProvisionalContent(file); err != nil {
+		return "", "", "", "", err
+	}
 
 	// Check if storage type is OSS
 	if file.StorageType != "oss" {
@@ -1132,6 +1391,12 @@ func (s *IndexerFileService) GetUserInfoList(cursor int64, size int) ([]*model.I
 	return users, nextCursor, hasMore, total, nil
 }
 
+// invalidAvatarError reports an avatar marked invalid at index time; handlers
+// answer 404 so clients fall back to a default avatar
+func invalidAvatarError(avatarInfo *model.UserAvatarInfo) error {
+	return fmt.Errorf("avatar is invalid: %s", avatarInfo.InvalidReason)
+}
+
 // GetAvatarOSSURLByMetaID get avatar OSS URL or content by MetaID
 // Returns: (ossURL, contentType, fileName, fileType, isOSS, error)
 func (s *IndexerFileService) GetAvatarOSSURLByMetaID(metaID string) (string, string, string, string, bool, error) {
@@ -1143,6 +1408,9 @@ func (s *IndexerFileService) GetAvatarOSSURLByMetaID(metaID string) (string, str
 		}
 		return "", "", "", "", false, fmt.Errorf("failed to get avatar info: %w", err)
 	}
+	if avatarInfo.Invalid {
+		return "", "", "", "", false, invalidAvatarError(avatarInfo)
+	}
 
 	// Check if avatar has OSS URL
 	if avatarInfo.AvatarUrl == "" {
@@ -1173,6 +1441,9 @@ func (s *IndexerFileService) GetAvatarContentByMetaID(metaID string) ([]byte, st
 		}
 		return nil, "", "", fmt.Errorf("failed to get avatar info: %w", err)
 	}
+	if avatarInfo.Invalid {
+		return nil, "", "", invalidAvatarError(avatarInfo)
+	}
 
 	// Read avatar content from storage
 	content, err := s.storage.Get(avatarInfo.Avatar)
@@ -1205,6 +1476,9 @@ func (s *IndexerFileService) GetAvatarContentByPinID(avatarPinID string) ([]byte
 		}
 		return nil, "", "", fmt.Errorf("failed to get avatar info: %w", err)
 	}
+	if avatarInfo.Invalid {
+		return nil, "", "", invalidAvatarError(avatarInfo)
+	}
 
 	// Read avatar content from storage
 	content, err := s.storage.Get(avatarInfo.Avatar)
@@ -1238,6 +1512,9 @@ func (s *IndexerFileService) GetFastAvatarOSSURLByPinID(avatarPinID string, proc
 		}
 		return "", "", "", "", fmt.Errorf("failed to get avatar info: %w", err)
 	}
+	if avatarInfo.Invalid {
+		return "", "", "", "", invalidAvatarError(avatarInfo)
+	}
 
 	// Check if avatar has OSS URL
 	if avatarInfo.AvatarUrl == "" {
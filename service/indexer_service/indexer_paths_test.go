@@ -0,0 +1,45 @@
+package indexer_service
+
+import "testing"
+
+func TestFilePathMatchers(t *testing.T) {
+	tests := []struct {
+		path                 string
+		file, chunk, indexed bool
+	}{
+		{path: "/file", file: true},
+		{path: "/file/photo.jpg", file: true},
+		{path: "/protocols/app/file", file: true},
+		{path: "/protocols/app/file/photo.jpg", file: true},
+		{path: "/file/_chunk", chunk: true},
+		{path: "/app/file/_chunk", chunk: true},
+		{path: "/file/chunk", chunk: true},
+		{path: "/file/index", indexed: true},
+		{path: "/app/file/index", indexed: true},
+		{path: "/files/photo.jpg"},
+		{path: "/profile"},
+		{path: "/file/a b"},
+	}
+	for _, tt := range tests {
+		if got := isFilePath(tt.path); got != tt.file {
+			t.Errorf("isFilePath(%q) = %v, want %v", tt.path, got, tt.file)
+		}
+		if got := isChunkPath(tt.path); got != tt.chunk {
+			t.Errorf("isChunkPath(%q) = %v, want %v", tt.path, got, tt.chunk)
+		}
+		if got := isIndexPath(tt.path); got != tt.indexed {
+			t.Errorf("isIndexPath(%q) = %v, want %v", tt.path, got, tt.indexed)
+		}
+	}
+}
+
+func TestIsIndexablePathRejectsInvalidPaths(t *testing.T) {
+	for _, path := range []string{"", "/", "/file/../etc", "/file/a b"} {
+		if isIndexablePath("pin", path) {
+			t.Errorf("isIndexablePath(%q) = true, want false", path)
+		}
+	}
+	if !isIndexablePath("pin", "/file/photo.jpg") {
+		t.Error("isIndexablePath(/file/photo.jpg) = false, want true")
+	}
+}
@@ -1,21 +1,19 @@
 package indexer_service
 
 import (
-	"bytes"
-	"compress/gzip"
 	"context"
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"meta-file-system/conf"
@@ -23,12 +21,12 @@ import (
 	"meta-file-system/indexer"
 	"meta-file-system/model"
 	"meta-file-system/model/dao"
+	"meta-file-system/notify"
 	"meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid"
 	"meta-file-system/service/common_service/metaid_protocols"
 	"meta-file-system/storage"
-
-	"github.com/bitcoinsv/bsvd/wire"
-	btcwire "github.com/btcsuite/btcd/wire"
 )
 
 // RescanTaskStatus represents the status of a rescan task
@@ -42,6 +40,17 @@ const (
 	RescanStatusFailed    RescanTaskStatus = "failed"
 )
 
+// ErrBlockPruned is returned when a rescan starts below the earliest block the
+// chain's (pruned) node can still serve
+var ErrBlockPruned = indexer.ErrBlockPruned
+
+// ErrRescanRunning is returned when a rescan is requested while another runs
+var ErrRescanRunning = errors.New("another rescan task is already running")
+
+// ErrShuttingDown is returned when a rescan is requested while the service
+// shuts down
+var ErrShuttingDown = errors.New("the indexer is shutting down")
+
 // RescanTask represents a rescan task
 type RescanTask struct {
 	TaskID          string
@@ -54,7 +63,10 @@ type RescanTask struct {
 	TotalBlocks     int64
 	StartTime       time.Time
 	ErrorMessage    string
+	Counts          RescanCounts
 	CancelFunc      context.CancelFunc
+	report          *rescanReport
+	interrupted     bool // Cancelled by a service shutdown
 	mu              sync.RWMutex
 }
 
@@ -65,6 +77,8 @@ type IndexerService struct {
 	indexerFileDAO       *dao.IndexerFileDAO
 	indexerFileChunkDAO  *dao.IndexerFileChunkDAO
 	pendingIndexFileDAO  *dao.PendingIndexFileDAO
+	pendingStorageDAO    *dao.PendingStorageWriteDAO
+	pendingCreatorDAO    *dao.PendingCreatorResolutionDAO
 	indexerUserAvatarDAO *dao.IndexerUserAvatarDAO
 	syncStatusDAO        *dao.IndexerSyncStatusDAO
 	storage              storage.Storage
@@ -78,6 +92,45 @@ type IndexerService struct {
 	// Rescan task management
 	currentRescanTask *RescanTask
 	rescanMu          sync.Mutex
+	rescanReports     map[string]*rescanReport // per-PIN results of recent rescans
+	rescanReportOrder []string
+
+	// ZMQ and block deliveries of the same transaction, by txid
+	txDeliveries *txDeliveries
+
+	// Storage backend migration (storage.migration), nil when disabled
+	storageMigration *StorageMigrationJob
+
+	// Closed on Stop to end the scanner watchdog, nil when alerts are off
+	watchdogStop chan struct{}
+
+	// Closed on Stop to end the storage write retrier
+	storageRetryStop chan struct{}
+
+	// Closed on Stop to end the creator lookup retrier
+	creatorRetryStop chan struct{}
+
+	// Closed on Stop to end the watch event outbox dispatcher
+	watchOutboxStop chan struct{}
+
+	// Closed on Stop to end the event stream publisher, nil when it is off
+	eventStreamStop chan struct{}
+
+	// Pauses scanning while disk space is low (indexer.disk_watchdog), nil when off
+	diskWatchdog     *diskWatchdog
+	diskWatchdogStop chan struct{}
+
+	// Closed on Stop to end the rescan scheduler, nil when it is off
+	rescanScheduleStop chan struct{}
+
+	// Chains an operator paused (POST /admin/chains/{chain}/pause)
+	chainPauses chainPauses
+
+	// Set when Shutdown begins; new rescans are refused from then on
+	stopping atomic.Bool
+
+	rescans    sync.WaitGroup // Running rescan goroutines
+	background sync.WaitGroup // Background jobs started by Start
 }
 
 // NewIndexerService create indexer service instance
@@ -125,6 +178,7 @@ func NewIndexerServiceWithChain(storage storage.Storage, chainType indexer.Chain
 	}
 
 	log.Printf("Indexer service will start from block height: %d (chain: %s)", startHeight, chainType)
+	logParserPolicy(chainName)
 
 	// Create block scanner with chain type
 	scanner := indexer.NewBlockScannerWithChain(
@@ -139,6 +193,12 @@ func NewIndexerServiceWithChain(storage storage.Storage, chainType indexer.Chain
 		scanner.SetLargeBlockThreshold(int64(conf.Cfg.Indexer.LargeBlockSizeMB) * 1024 * 1024)
 	}
 
+	// Detect a pruned node so rescans below its prune height fail clearly
+	scanner.SetEsploraFallback(conf.Cfg.Chain.EsploraUrl)
+	if _, err := scanner.DetectPruning(); err != nil {
+		log.Printf("Failed to detect node pruning: %v", err)
+	}
+
 	// Enable ZMQ if configured
 	if conf.Cfg.Indexer.ZmqEnabled && conf.Cfg.Indexer.ZmqAddress != "" {
 		scanner.EnableZMQ(conf.Cfg.Indexer.ZmqAddress)
@@ -157,13 +217,19 @@ func NewIndexerServiceWithChain(storage storage.Storage, chainType indexer.Chain
 		indexerFileDAO:       dao.NewIndexerFileDAO(),
 		indexerFileChunkDAO:  dao.NewIndexerFileChunkDAO(),
 		pendingIndexFileDAO:  dao.NewPendingIndexFileDAO(),
+		pendingStorageDAO:    dao.NewPendingStorageWriteDAO(),
+		pendingCreatorDAO:    dao.NewPendingCreatorResolutionDAO(),
 		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
 		syncStatusDAO:        dao.NewIndexerSyncStatusDAO(),
 		storage:              storage,
 		chainType:            chainType,
 		parser:               parser,
+		storageMigration:     newStorageMigrationJobFor(storage),
+		txDeliveries:         newTxDeliveries(),
 	}
 
+	scanner.SetPipeline(blockPipelineConfig(), service.creatorResolver(chainType))
+
 	// Initialize sync status in database
 	if err := service.initializeSyncStatus(startHeight); err != nil {
 		log.Printf("Failed to initialize sync status: %v", err)
@@ -189,12 +255,16 @@ func NewMultiChainIndexerService(storage storage.Storage, chainConfigs []conf.Ch
 		indexerFileDAO:       dao.NewIndexerFileDAO(),
 		indexerFileChunkDAO:  dao.NewIndexerFileChunkDAO(),
 		pendingIndexFileDAO:  dao.NewPendingIndexFileDAO(),
+		pendingStorageDAO:    dao.NewPendingStorageWriteDAO(),
+		pendingCreatorDAO:    dao.NewPendingCreatorResolutionDAO(),
 		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
 		syncStatusDAO:        dao.NewIndexerSyncStatusDAO(),
 		storage:              storage,
 		coordinator:          coordinator,
 		isMultiChain:         true,
 		parser:               indexer.NewMetaIDParser(""),
+		storageMigration:     newStorageMigrationJobFor(storage),
+		txDeliveries:         newTxDeliveries(),
 	}
 
 	// Create scanner for each chain
@@ -227,6 +297,7 @@ func (s *IndexerService) addChainScanner(chainConfig conf.ChainInstanceConfig) e
 	}
 
 	chainName := string(chainType)
+	logParserPolicy(chainName)
 	syncStatusDAO := dao.NewIndexerSyncStatusDAO()
 
 	// Get current sync height from database
@@ -262,6 +333,12 @@ func (s *IndexerService) addChainScanner(chainConfig conf.ChainInstanceConfig) e
 		scanner.SetLargeBlockThreshold(int64(conf.Cfg.Indexer.LargeBlockSizeMB) * 1024 * 1024)
 	}
 
+	// Detect a pruned node so rescans below its prune height fail clearly
+	scanner.SetEsploraFallback(chainConfig.EsploraUrl)
+	if _, err := scanner.DetectPruning(); err != nil {
+		log.Printf("[%s] Failed to detect node pruning: %v", chainName, err)
+	}
+
 	// Enable ZMQ if configured
 	if chainConfig.ZmqEnabled && chainConfig.ZmqAddress != "" {
 		scanner.EnableZMQ(chainConfig.ZmqAddress)
@@ -271,11 +348,14 @@ func (s *IndexerService) addChainScanner(chainConfig conf.ChainInstanceConfig) e
 		scanner.SetZMQTransactionHandler(func(tx interface{}, metaDataTx *indexer.MetaIDDataTx) error {
 			// Call the same handler but with height = 0 (mempool transaction)
 			// and current timestamp for ZMQ transactions
-			return s.handleTransaction(tx, metaDataTx, 0, time.Now().UnixMilli())
+			return s.handleTransaction(tx, metaDataTx, 0, indexer.FirstSeenTimestamp())
 		})
 		log.Printf("[%s] ZMQ transaction handler configured", chainName)
 	}
 
+	// Rescans of this chain run through the same pipeline as the coordinator
+	scanner.SetPipeline(blockPipelineConfig(), s.creatorResolver(chainType))
+
 	// Add to coordinator
 	if err := s.coordinator.AddChain(chainName, scanner); err != nil {
 		return err
@@ -337,88 +417,29 @@ func (s *IndexerService) handleBlockEvent(event *indexer.BlockEvent) error {
 		return fmt.Errorf("unsupported chain type: %s", event.ChainName)
 	}
 
-	parser := indexer.NewMetaIDParser("")
-
-	// Large block path: Block is *LazyBlock, fetch each tx by txid via TxFetcher
-	if lazy, ok := event.Block.(*indexer.LazyBlock); ok {
-		timestamp := event.Timestamp
-		if len(strconv.FormatInt(timestamp, 10)) != 13 {
-			timestamp = timestamp * 1000
-		}
-		if event.TxFetcher == nil {
-			return fmt.Errorf("LazyBlock event missing TxFetcher")
-		}
-		for _, txid := range lazy.TxIDs {
-			tx, err := event.TxFetcher(txid)
-			if err != nil {
-				log.Printf("[%s] Failed to fetch tx %s in block %d: %v", event.ChainName, txid, event.Height, err)
-				continue
-			}
-			metaDataTx, err := parser.ParseAllPINs(tx, chainType)
-			if err != nil || metaDataTx == nil {
-				continue
-			}
-			if err := s.handleTransaction(tx, metaDataTx, event.Height, timestamp); err != nil {
-				log.Printf("[%s] Failed to handle transaction %s: %v", event.ChainName, metaDataTx.TxID, err)
-			}
-		}
-		if err := s.syncStatusDAO.UpdateCurrentSyncHeight(event.ChainName, event.Height); err != nil {
-			return fmt.Errorf("failed to update sync height: %w", err)
-		}
-		return nil
-	}
-
-	// Process transactions based on chain type (full block in memory)
-	if chainType == indexer.ChainTypeBTC || chainType == indexer.ChainTypeDOGE {
-		btcBlock, ok := event.Block.(*btcwire.MsgBlock)
-		if !ok {
-			chainName := "BTC"
-			if chainType == indexer.ChainTypeDOGE {
-				chainName = "DOGE"
-			}
-			return fmt.Errorf("invalid %s block type", chainName)
-		}
-		//判断event.Timestamp的位数是否13位，不是的话，则认为是10位，则需要乘以1000
-		if len(strconv.FormatInt(event.Timestamp, 10)) != 13 {
-			event.Timestamp = event.Timestamp * 1000
-		}
-
-		// Process each transaction
-		for _, tx := range btcBlock.Transactions {
-			metaDataTx, err := parser.ParseAllPINs(tx, chainType)
-			if err != nil || metaDataTx == nil {
-				continue
-			}
-
-			// Handle the transaction
-			if err := s.handleTransaction(tx, metaDataTx, event.Height, event.Timestamp); err != nil {
-				log.Printf("[%s] Failed to handle transaction %s: %v", event.ChainName, metaDataTx.TxID, err)
-			}
-		}
+	// Block times arrive in seconds or milliseconds depending on the path
+	// (RPC verbose block vs wire header); store milliseconds throughout.
+	if ts := indexer.NormalizeTimestamp(chainType, event.Timestamp); ts > 0 {
+		event.Timestamp = ts
 	} else {
-		mvcBlock, ok := event.Block.(*wire.MsgBlock)
-		if !ok {
-			return fmt.Errorf("invalid MVC block type")
-		}
-
-		//判断event.Timestamp的位数是否13位，不是的话，则认为是10位，则需要乘以1000
-		if len(strconv.FormatInt(event.Timestamp, 10)) != 13 {
-			event.Timestamp = event.Timestamp * 1000
-		}
-
-		// Process each transaction
-		for _, tx := range mvcBlock.Transactions {
-			metaDataTx, err := parser.ParseAllPINs(tx, chainType)
-			if err != nil || metaDataTx == nil {
-				continue
-			}
+		log.Printf("[%s] Block %d has unrecognized timestamp %d, using first-seen time", event.ChainName, event.Height, event.Timestamp)
+		event.Timestamp = indexer.FirstSeenTimestamp()
+	}
 
-			// Handle the transaction
-			if err := s.handleTransaction(tx, metaDataTx, event.Height, event.Timestamp); err != nil {
-				log.Printf("[%s] Failed to handle transaction %s: %v", event.ChainName, metaDataTx.TxID, err)
-			}
-		}
+	// Fetch (lazy blocks), parse and resolve run concurrently; handleTransaction
+	// persists the PINs in block order
+	if _, ok := event.Block.(*indexer.LazyBlock); ok && event.TxFetcher == nil {
+		return fmt.Errorf("LazyBlock event missing TxFetcher")
 	}
+	pipeline := indexer.NewBlockPipeline(chainType, blockPipelineConfig(), s.creatorResolver(chainType))
+	if _, err := pipeline.Run(event.Block, event.TxFetcher, func(tx interface{}, metaDataTx *indexer.MetaIDDataTx) error {
+		return s.handleTransaction(tx, metaDataTx, event.Height, event.Timestamp)
+	}); err != nil {
+		return err
+	}
+
+	// Post-process the block
+	s.flushCounters(event.ChainName, event.Height)
 
 	// Update sync status
 	if err := s.syncStatusDAO.UpdateCurrentSyncHeight(event.ChainName, event.Height); err != nil {
@@ -463,6 +484,60 @@ func (s *IndexerService) initializeSyncStatus(startHeight int64) error {
 func (s *IndexerService) Start() {
 	log.Println("Indexer service starting...")
 
+	// Copy existing blobs to the migration target while new ones are dual-written
+	if s.storageMigration != nil {
+		s.storageMigration.Start()
+	}
+
+	// Alert when a chain's sync height stops advancing (notify.scanner_stall_minutes)
+	if notify.Enabled() {
+		s.watchdogStop = make(chan struct{})
+		s.runBackground(s.watchScanner, s.watchdogStop)
+	}
+
+	// Store blobs whose storage write failed (indexer.storage_retry)
+	s.storageRetryStop = make(chan struct{})
+	s.runBackground(s.retryStorageWrites, s.storageRetryStop)
+
+	// Correct files indexed with a fallback creator address (indexer.creator_retry)
+	s.creatorRetryStop = make(chan struct{})
+	s.runBackground(s.retryCreatorResolutions, s.creatorRetryStop)
+
+	// Publish recorded watch events to webhooks and subscribers (indexer.watch_outbox)
+	s.watchOutboxStop = make(chan struct{})
+	s.runBackground(s.dispatchWatchOutbox, s.watchOutboxStop)
+
+	// Publish the change log to NATS / Kafka (indexer.event_stream)
+	if conf.Cfg.Indexer.EventStream.Enabled {
+		s.eventStreamStop = make(chan struct{})
+		s.runBackground(s.publishEventStream, s.eventStreamStop)
+	}
+
+	// Pause scanning while the storage / database volumes are nearly full (indexer.disk_watchdog)
+	if cfg := conf.Cfg.Indexer.DiskWatchdog; cfg.Enabled {
+		if paths := diskWatchdogPaths(); len(paths) > 0 {
+			s.diskWatchdog = newDiskWatchdog(paths, uint64(cfg.MinFreeMB)*1024*1024, uint64(cfg.ResumeFreeMB)*1024*1024)
+			s.diskWatchdogStop = make(chan struct{})
+			s.runBackground(s.watchDiskSpace, s.diskWatchdogStop)
+		}
+	}
+
+	// Scanning waits while the disk watchdog or an operator pauses the chain
+	for chainName, scanner := range s.watchedScanners() {
+		chainName := chainName
+		scanner.SetPauseCheck(func() bool { return s.scanningPaused(chainName) })
+	}
+
+	// Recurring rescans of the latest blocks (indexer.rescan_schedule)
+	if cfg := conf.Cfg.Indexer.RescanSchedule; cfg.Enabled && len(cfg.Jobs) > 0 {
+		jobs, err := newRescanJobs(cfg.Jobs, time.Now())
+		if err != nil {
+			log.Fatalf("Invalid rescan schedule: %v", err)
+		}
+		s.rescanScheduleStop = make(chan struct{})
+		s.runBackground(func(stop <-chan struct{}) { s.runRescanSchedule(jobs, stop) }, s.rescanScheduleStop)
+	}
+
 	if s.isMultiChain {
 		// Multi-chain mode
 		log.Println("Starting in multi-chain mode...")
@@ -495,6 +570,31 @@ func (s *IndexerService) IsMultiChain() bool {
 func (s *IndexerService) Stop() {
 	log.Println("Stopping indexer service...")
 
+	if s.storageMigration != nil {
+		s.storageMigration.Stop()
+	}
+	if s.watchdogStop != nil {
+		close(s.watchdogStop)
+	}
+	if s.storageRetryStop != nil {
+		close(s.storageRetryStop)
+	}
+	if s.creatorRetryStop != nil {
+		close(s.creatorRetryStop)
+	}
+	if s.watchOutboxStop != nil {
+		close(s.watchOutboxStop)
+	}
+	if s.eventStreamStop != nil {
+		close(s.eventStreamStop)
+	}
+	if s.diskWatchdogStop != nil {
+		close(s.diskWatchdogStop)
+	}
+	if s.rescanScheduleStop != nil {
+		close(s.rescanScheduleStop)
+	}
+
 	if s.isMultiChain && s.coordinator != nil {
 		s.coordinator.Stop()
 	} else if s.scanner != nil {
@@ -504,10 +604,21 @@ func (s *IndexerService) Stop() {
 	log.Println("Indexer service stopped")
 }
 
+// GetStorageMigrationProgress returns the storage backend migration progress,
+// or false when storage.migration is not enabled
+func (s *IndexerService) GetStorageMigrationProgress() (StorageMigrationProgress, bool) {
+	if s.storageMigration == nil {
+		return StorageMigrationProgress{}, false
+	}
+	return s.storageMigration.Progress(), true
+}
+
 // onBlockComplete called after each block is successfully scanned
 func (s *IndexerService) onBlockComplete(height int64) error {
 	chainName := string(s.chainType)
 
+	s.flushCounters(chainName, height)
+
 	// Update current sync height
 	if err := s.syncStatusDAO.UpdateCurrentSyncHeight(chainName, height); err != nil {
 		return fmt.Errorf("failed to update sync height: %w", err)
@@ -521,13 +632,48 @@ func (s *IndexerService) onBlockComplete(height int64) error {
 	return nil
 }
 
+// flushCounters persists the counter deltas of a block (one write per block).
+// On failure the deltas stay buffered and are retried with the next block.
+func (s *IndexerService) flushCounters(chainName string, height int64) {
+	if database.DB == nil {
+		return
+	}
+	if err := database.DB.FlushCounters(); err != nil {
+		log.Printf("[%s] Failed to flush counters at block %d: %v", chainName, height, err)
+	}
+}
+
 // handleTransaction handle transaction
 // tx is interface{} to support both BTC (*btcwire.MsgTx) and MVC (*wire.MsgTx) transactions
 func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.MetaIDDataTx, height, timestamp int64) error {
+	return s.handleTransactionReport(tx, metaDataTx, height, timestamp, nil)
+}
+
+// handleTransactionReport is handleTransaction recording each PIN's outcome in
+// report (nil outside rescans)
+func (s *IndexerService) handleTransactionReport(tx interface{}, metaDataTx *indexer.MetaIDDataTx, height, timestamp int64, report *rescanReport) error {
 	if metaDataTx == nil || len(metaDataTx.MetaIDData) == 0 {
 		return nil
 	}
 
+	// Deliveries of a transaction from ZMQ and from its block are handled one at
+	// a time. A mempool delivery of a transaction already handled is dropped:
+	// ZMQ repeats transactions, and also publishes them when their block
+	// arrives. A block delivery after the mempool one confirms the indexed
+	// records in place (see the already-indexed branches below).
+	// Mempool transactions are dropped while scanning is paused (low disk
+	// space, or the chain paused by an operator); they are indexed with their
+	// block once scanning resumes
+	if height == 0 && s.scanningPaused(metaDataTx.ChainName) {
+		return nil
+	}
+
+	prev, release := s.txDeliveries.begin(metaDataTx.ChainName, metaDataTx.TxID, height > 0)
+	defer release()
+	if height == 0 && prev != txUnseen {
+		return nil
+	}
+
 	// txID := metaDataTx.TxID
 	// chainNameFromTx := metaDataTx.ChainName
 	// pinId := metaDataTx.MetaIDData[0].PinID
@@ -535,11 +681,25 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 	// log.Printf("Found MetaID pinId: %s,  transaction: %s at height %d (chain: %s), PIN count: %d",
 	// 	pinId, txID, height, chainNameFromTx, len(metaDataTx.MetaIDData))
 
+	// Malformed PINs are rejected or indexed best-effort per the chain's parser mode
+	policy := parserPolicyFor(metaDataTx.ChainName)
+
 	// Process each PIN in the transaction
 	for _, metaData := range metaDataTx.MetaIDData {
+		// Everything indexed from the PIN records its content type without
+		// the ";binary" flag, in lower case (contenttype.Normalize)
+		metaData.ContentType = contenttype.Normalize(metaData.ContentType)
+
+		// Under genesis_txid the first PIN of an address (any path) fixes its
+		// MetaID; a fallback creator address is not recorded
+		if !metaData.CreatorResolutionPending {
+			metaid.Observe(metaData.CreatorAddress, metaData.PinID)
+		}
+
 		// Track firstPinID for modify operations
 		var firstPinID string
 		var firstPath string
+		var pinInfo *model.IndexerPinInfo
 
 		// Handle based on operation type
 		if metaData.Operation == "create" {
@@ -547,11 +707,12 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			firstPinID = metaData.PinID // For create, firstPinID = PinID
 			firstPath = metaData.Path   // For create, firstPath = Path
 
-			if !metaid_protocols.IsProtocolPath(firstPath) {
+			if !isIndexablePath(metaData.PinID, firstPath) {
+				report.pin(height, metaData, PinOutcomeSkipped, "path not indexed")
 				continue
 			}
 
-			pinInfo := &model.IndexerPinInfo{
+			pinInfo = &model.IndexerPinInfo{
 				PinID:       metaData.PinID,
 				FirstPinID:  firstPinID,
 				FirstPath:   firstPath,
@@ -562,14 +723,12 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 				BlockHeight: height,
 				Timestamp:   timestamp,
 			}
-			if err := database.DB.CreateOrUpdatePinInfo(pinInfo); err != nil {
-				log.Printf("Failed to save PIN info for %s: %v", metaData.PinID, err)
-			}
 		} else if metaData.Operation == "modify" || metaData.Operation == "revoke" {
 			// Modify/Revoke operation: resolve path and firstPinID if it's a reference (@pinId or host:@pinId)
 			resolvedPath, resolvedFirstPinID, resolvedFirstPath, isValidOperation := s.resolvePathAndFirstPinID(metaData.Path)
 			if !isValidOperation {
 				log.Printf("Invalid operation: %s, path: %s", metaData.Operation, metaData.Path)
+				report.pin(height, metaData, PinOutcomeFailed, "unresolvable "+metaData.Operation+" reference")
 				continue
 			}
 			if resolvedPath != metaData.Path {
@@ -586,12 +745,13 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 				log.Printf("Warning: %s operation without @pinId reference, using PinID as firstPinID: %s", metaData.Operation, firstPinID)
 			}
 
-			if !metaid_protocols.IsProtocolPath(firstPath) {
+			if !isIndexablePath(metaData.PinID, firstPath) {
+				report.pin(height, metaData, PinOutcomeSkipped, "path not indexed")
 				continue
 			}
 
 			// Save PIN info for modify/revoke operations
-			pinInfo := &model.IndexerPinInfo{
+			pinInfo = &model.IndexerPinInfo{
 				PinID:       metaData.PinID,
 				FirstPinID:  firstPinID,
 				FirstPath:   firstPath,
@@ -602,21 +762,37 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 				BlockHeight: height,
 				Timestamp:   timestamp,
 			}
-			if err := database.DB.CreateOrUpdatePinInfo(pinInfo); err != nil {
-				log.Printf("Failed to save PIN info for %s: %v", metaData.PinID, err)
-			}
 		} else {
 			// For other operations, use PinID as firstPinID
 			firstPinID = metaData.PinID
 			firstPath = metaData.Path
 		}
 
+		// Only PINs in blocks are counted, so mempool sightings are not counted twice.
+		// A rejected PIN writes nothing but its quarantine record.
+		if ok, reason := policy.admit(metaData, firstPath, height > 0); !ok {
+			if height > 0 {
+				s.quarantinePIN(metaData, firstPinID, firstPath, height, timestamp, reason)
+			}
+			report.pin(height, metaData, PinOutcomeRejected, reason)
+			continue
+		}
+		if pinInfo != nil {
+			if err := database.DB.CreateOrUpdatePinInfo(pinInfo); err != nil {
+				log.Printf("Failed to save PIN info for %s: %v", metaData.PinID, err)
+			}
+		}
+
 		// Store firstPinID in metadata for use in processing functions
 		// We'll pass it through a context or store it temporarily
 		// For now, we'll use a simple approach by modifying the processing functions
 		// Check if this is a chunk or index PIN (for large file splitting)
 		log.Printf("Processing PIN: %s (path: %s, operation: %s, content type: %s)",
 			metaData.PinID, metaData.Path, metaData.Operation, metaData.ContentType)
+
+		// Notify watchlist entries / live subscribers of the creator
+		s.notifyWatchers(metaData, height, timestamp)
+
 		if isChunkPath(metaData.Path) && isChunkContentType(metaData.ContentType) {
 			log.Printf("Processing chunk PIN: %s (path: %s, operation: %s)",
 				metaData.PinID, metaData.Path, metaData.Operation)
@@ -625,14 +801,24 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			existingChunk, err := s.indexerFileChunkDAO.GetByPinID(metaData.PinID)
 			if err == nil && existingChunk != nil {
 				log.Printf("Chunk PIN already indexed: %s", metaData.PinID)
+				// Record the confirming block of a chunk indexed from the mempool
+				if existingChunk.BlockHeight == 0 && height > 0 {
+					existingChunk.BlockHeight = height
+					if err := s.indexerFileChunkDAO.Update(existingChunk); err != nil {
+						log.Printf("Failed to confirm mempool chunk %s: %v", metaData.PinID, err)
+					}
+				}
+				report.pin(height, metaData, PinOutcomeDuplicate, "")
 				continue
 			}
 
 			// Process chunk content
 			if err := s.processChunkContent(metaData, firstPinID, height, timestamp); err != nil {
 				log.Printf("Failed to process chunk content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else if isIndexPath(firstPath) && isIndexContentType(metaData.ContentType) {
 			log.Printf("Processing index PIN: %s (firstPath: %s, path: %s, operation: %s)",
 				metaData.PinID, firstPath, metaData.Path, metaData.Operation)
@@ -641,14 +827,20 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			existingFile, err := s.indexerFileDAO.GetByPinID(metaData.PinID)
 			if err == nil && existingFile != nil {
 				log.Printf("Index PIN already indexed: %s", metaData.PinID)
+				if _, err := s.confirmMempoolFile(metaData.PinID, height, timestamp); err != nil {
+					log.Printf("%v", err)
+				}
+				report.pin(height, metaData, PinOutcomeDuplicate, "")
 				continue
 			}
 
 			// Process index content
 			if err := s.processIndexContent(metaData, firstPinID, firstPath, height, timestamp); err != nil {
 				log.Printf("Failed to process index content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else if isFilePath(firstPath) {
 			// Check if this is a file PIN
 			log.Printf("Processing file PIN: %s (firstPath: %s, path: %s, operation: %s)",
@@ -658,12 +850,18 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			existingFile, err := s.indexerFileDAO.GetByPinID(metaData.PinID)
 			if err == nil && existingFile != nil {
 				log.Printf("File PIN already indexed: %s", metaData.PinID)
+				report.pin(height, metaData, PinOutcomeDuplicate, "")
 
-				// Update file content height
-				if existingFile.BlockHeight < height && height > 0 {
+				// Record the confirming block of a file indexed from the mempool;
+				// a file re-mined at another height only gets the new height
+				if confirmed, err := s.confirmMempoolFile(metaData.PinID, height, timestamp); err != nil {
+					log.Printf("%v", err)
+				} else if !confirmed && existingFile.BlockHeight < height && height > 0 {
 					existingFile.BlockHeight = height
 					if err := s.indexerFileDAO.Update(existingFile); err != nil {
 						log.Printf("Failed to update file content height: %v", err)
+					} else {
+						recordFileChange(model.ChangeFileConfirmed, existingFile)
 					}
 				}
 
@@ -673,9 +871,11 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			// Process file content
 			if err := s.processFileContent(metaData, firstPinID, firstPath, height, timestamp); err != nil {
 				log.Printf("Failed to process file content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				// Continue processing other PINs even if one fails
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else if isUserNamePath(firstPath) {
 			// Check if this is a user name PIN
 			log.Printf("Processing user name PIN: %s (firstPath: %s, path: %s, operation: %s)",
@@ -684,8 +884,10 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			// Process user name content
 			if err := s.processUserNameContent(metaData, firstPinID, firstPath, height, timestamp); err != nil {
 				log.Printf("Failed to process user name content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else if isUserAvatarInfoPath(firstPath) {
 			// Check if this is a user avatar info PIN (different from avatar file)
 			log.Printf("Processing user avatar info PIN: %s (firstPath: %s, path: %s, operation: %s)",
@@ -694,8 +896,10 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			// Process user avatar info content
 			if err := s.processUserAvatarInfoContent(metaData, firstPinID, firstPath, height, timestamp); err != nil {
 				log.Printf("Failed to process user avatar info content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else if isUserBioPath(firstPath) {
 			// Check if this is a user bio PIN
 			log.Printf("Processing user bio PIN: %s (firstPath: %s, path: %s, operation: %s)",
@@ -704,8 +908,10 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			// Process user bio content
 			if err := s.processUserBioContent(metaData, firstPinID, firstPath, height, timestamp); err != nil {
 				log.Printf("Failed to process user bio content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else if isUserChatPublicKeyPath(firstPath) {
 			// Check if this is a user chat public key PIN
 			log.Printf("Processing user chat public key PIN: %s (firstPath: %s, path: %s, operation: %s)",
@@ -714,87 +920,86 @@ func (s *IndexerService) handleTransaction(tx interface{}, metaDataTx *indexer.M
 			// Process user chat public key content
 			if err := s.processUserChatPublicKeyContent(metaData, firstPinID, firstPath, height, timestamp); err != nil {
 				log.Printf("Failed to process user chat public key content for PIN %s: %v", metaData.PinID, err)
+				report.pin(height, metaData, PinOutcomeFailed, err.Error())
 				continue
 			}
+			report.pin(height, metaData, PinOutcomeIndexed, "")
 		} else {
 			// log.Printf("Skipping PIN: %s (path: %s)", metaData.PinID, metaData.Path)
+			report.pin(height, metaData, PinOutcomeSkipped, "no handler for path")
 		}
 	}
 
 	return nil
 }
 
+// isIndexablePath reports whether a PIN's path passes the protocol path
+// filter. Paths that are not valid MetaID paths are logged, so PINs dropped
+// for their path can be traced.
+func isIndexablePath(pinID, path string) bool {
+	if _, err := metaid_protocols.ParseMetaIDPath(path); err != nil {
+		if !errors.Is(err, metaid_protocols.ErrEmptyPath) {
+			log.Printf("Skipping PIN %s: invalid path %q: %v", pinID, path, err)
+		}
+		return false
+	}
+	return metaid_protocols.IsProtocolPath(path)
+}
+
 // isFilePath check if path is a file path
 func isFilePath(path string) bool {
-	// Check if path starts with /file or contains /file
-	// But exclude chunk and index paths
+	// Paths with a /file segment, also under a base path (e.g. /app/file),
+	// but exclude chunk and index paths
 	if isChunkPath(path) || isIndexPath(path) {
 		return false
 	}
-	return strings.HasPrefix(path, "/file") || strings.Contains(path, "/file")
+	return metaid_protocols.PathContainsSegments(path, "/file")
 }
 
-// isAvatarPath check if path is an avatar path (avatar file, not info)
-// func isAvatarPath(path string) bool {
-// 	// Check if path starts with /info/avatar or contains /info/avatar
-// 	// But exclude /info/avatar info path (which is for avatar info text)
-// 	if isUserAvatarInfoPath(path) {
-// 		return false
-// 	}
-// 	return strings.HasPrefix(path, "/info/avatar") || strings.Contains(path, "/info/avatar")
-// }
-
 // isUserNamePath check if path is a user name path
 func isUserNamePath(path string) bool {
-	// Check if path starts with /info/name or contains /info/name
-	return strings.HasPrefix(path, "/info/name") || strings.Contains(path, "/info/name")
+	return metaid_protocols.PathHasPrefix(path, "/info/"+metaid_protocols.MonitorMetaIdInfoNameContentType)
 }
 
 // isUserAvatarInfoPath check if path is a user avatar info path (text info, not file)
 func isUserAvatarInfoPath(path string) bool {
-	// This is for avatar info text, not avatar file
-	// Usually path like /info/avatar with text content
-	return strings.HasPrefix(path, "/info/avatar") || strings.Contains(path, "/info/avatar")
+	return metaid_protocols.PathHasPrefix(path, "/info/"+metaid_protocols.MonitorMetaIdInfoAvatarContentType)
 }
 
 // isUserBioPath check if path is a user bio path
 func isUserBioPath(path string) bool {
-	// Check if path starts with /info/bio or contains /info/bio
-	return strings.HasPrefix(strings.ToLower(path), "/info/bio") || strings.Contains(strings.ToLower(path), "/info/bio")
+	return metaid_protocols.PathHasPrefix(path, "/info/"+metaid_protocols.MonitorMetaIdInfoBioContentType)
 }
 
 // isUserChatPublicKeyPath check if path is a user chat public key path
 func isUserChatPublicKeyPath(path string) bool {
-	// Check if path starts with /info/chatpubkey or contains /info/chatpubkey
-	return strings.HasPrefix(strings.ToLower(path), "/info/chatpubkey") || strings.Contains(strings.ToLower(path), "/info/chatpubkey") ||
-		strings.HasPrefix(strings.ToLower(path), strings.ToLower("/info/chatPublicKey")) || strings.Contains(strings.ToLower(path), strings.ToLower("/info/chatPublicKey"))
+	// Both /info/chatpubkey and the legacy /info/chatPublicKey spelling
+	return metaid_protocols.PathHasPrefix(path, "/info/"+metaid_protocols.MonitorMetaIdInfoChatPublicKeyContentType) ||
+		metaid_protocols.PathHasPrefix(path, "/info/chatPublicKey")
 }
 
 // isChunkPath check if path is a chunk path
 func isChunkPath(path string) bool {
-	// Check if path contains /file/_chunk
-	return strings.Contains(path, "/file/_chunk") || strings.Contains(path, "/file/"+metaid_protocols.MonitorFileChunk) ||
-		strings.Contains(path, "/file/chunk") || strings.Contains(path, "/file/"+metaid_protocols.MonitorFileChunkOld)
+	// Chunk paths may carry a base path in front, e.g. /app/file/_chunk
+	return metaid_protocols.PathContainsSegments(path, "/file/"+metaid_protocols.MonitorFileChunk) ||
+		metaid_protocols.PathContainsSegments(path, "/file/"+metaid_protocols.MonitorFileChunkOld)
 }
 
 // isIndexPath check if path is an index path
 func isIndexPath(path string) bool {
-	// Check if path contains /file/index
-	return strings.Contains(path, "/file/index") || strings.Contains(path, "/file/"+metaid_protocols.MonitorFileIndex)
+	return metaid_protocols.PathContainsSegments(path, "/file/"+metaid_protocols.MonitorFileIndex)
 }
 
 // isChunkContentType check if content type is metafile/chunk
 func isChunkContentType(contentType string) bool {
 	// Check if content type is metafile/chunk (with or without parameters)
-	normalized := strings.ToLower(strings.TrimSpace(contentType))
-	return strings.HasPrefix(normalized, "metafile/chunk")
+	return contenttype.MediaType(contentType) == "metafile/chunk"
 }
 
 // isIndexContentType check if content type is metafile/index
 func isIndexContentType(contentType string) bool {
 	// Check if content type is metafile/index (with or without parameters)
-	normalized := strings.ToLower(strings.TrimSpace(contentType))
-	return strings.HasPrefix(normalized, "metafile/index")
+	return contenttype.MediaType(contentType) == "metafile/index"
 }
 
 // isGzipCompressed check if content is gzip compressed
@@ -806,25 +1011,11 @@ func isGzipCompressed(content []byte) bool {
 	return content[0] == 0x1f && content[1] == 0x8b
 }
 
-// decompressGzip decompress gzip compressed content
-func decompressGzip(content []byte) ([]byte, error) {
-	reader := bytes.NewReader(content)
-	gzReader, err := gzip.NewReader(reader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-	}
-	defer gzReader.Close()
-
-	decompressed, err := io.ReadAll(gzReader)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decompress gzip content: %w", err)
-	}
-
-	return decompressed, nil
-}
-
 // processFileContent process and save file content (unified for create and modify)
 func (s *IndexerService) processFileContent(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
+	if confirmed, err := s.confirmMempoolFile(metaData.PinID, height, timestamp); confirmed || err != nil {
+		return err
+	}
 	if metaData.Operation == "create" {
 		return s.processFileContentCreate(metaData, firstPinID, firstPath, height, timestamp)
 	} else if metaData.Operation == "modify" {
@@ -834,27 +1025,49 @@ func (s *IndexerService) processFileContent(metaData *indexer.MetaIDData, firstP
 	return s.processFileContentCreate(metaData, firstPinID, firstPath, height, timestamp)
 }
 
+// confirmMempoolFile records the confirming block for a file that was first
+// indexed from the mempool (ZMQ, height 0). It returns true when pinID was such
+// a file and has been updated, so the caller can skip re-indexing the content.
+// Timestamp keeps the first-seen time so list ordering and index keys stay stable.
+func (s *IndexerService) confirmMempoolFile(pinID string, height, timestamp int64) (bool, error) {
+	if height <= 0 {
+		return false, nil
+	}
+	existing, err := s.indexerFileDAO.GetByPinID(pinID)
+	if err != nil || existing == nil || existing.BlockHeight > 0 {
+		return false, nil
+	}
+
+	if existing.FirstSeenAt == 0 {
+		existing.FirstSeenAt = existing.Timestamp
+	}
+	existing.BlockHeight = height
+	existing.ConfirmedAt = timestamp
+	if err := s.indexerFileDAO.UpdateFields(existing); err != nil {
+		return false, fmt.Errorf("failed to confirm mempool file %s: %w", pinID, err)
+	}
+	recordFileChange(model.ChangeFileConfirmed, existing)
+
+	log.Printf("File confirmed: PIN=%s, height=%d, firstSeenAt=%d, confirmedAt=%d",
+		pinID, height, existing.FirstSeenAt, timestamp)
+	return true, nil
+}
+
 // processFileContentCreate process and save file content for create operation
 func (s *IndexerService) processFileContentCreate(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
-	// Get real creator address from CreatorInputLocation if available
-	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
-		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
-		if err != nil {
-			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
-				metaData.CreatorInputLocation, err)
-		} else {
-			creatorAddress = realAddress
-			log.Printf("Found real creator address: %s (from location: %s)", realAddress, metaData.CreatorInputLocation)
-		}
-	}
+	// Get real creator address from CreatorInputLocation if available; a failed
+	// lookup is queued and the file saved with ResolutionPending
+	creatorAddress := s.fileCreatorAddress(metaData)
 
 	// Check if content is gzip compressed and decompress if needed
 	fileContent := metaData.Content
 	isCompressed := isGzipCompressed(metaData.Content)
 	if isCompressed {
 		log.Printf("Detected gzip compressed content for PIN: %s, decompressing...", metaData.PinID)
-		decompressed, err := decompressGzip(metaData.Content)
+		decompressed, err := decompressGzip(metaData.Content, decompressLimits())
+		if errors.Is(err, ErrDecompressLimit) {
+			return s.saveRejectedFile(metaData, model.ChunkTypeSingle, firstPinID, firstPath, creatorAddress, height, timestamp, err.Error())
+		}
 		if err != nil {
 			log.Printf("Failed to decompress gzip content for PIN %s: %v, using original content", metaData.PinID, err)
 			// Continue with original content if decompression fails
@@ -881,27 +1094,34 @@ func (s *IndexerService) processFileContentCreate(metaData *indexer.MetaIDData,
 	// Detect file type from real content type
 	fileType := detectFileType(realContentType)
 
-	// Determine storage path: indexer/{chain}/{txid}/{pinid}{extension}
+	// Calculate Creator MetaID (per metaid.derivation)
+	creatorMetaID := metaid.Derive(creatorAddress)
+
+	// Determine storage path from the configured layout (storage.Layout)
 	// Use pinID as filename to ensure uniqueness, with file extension
-	storagePath := fmt.Sprintf("indexer/%s/%s%s",
-		metaData.ChainName,
-		metaData.PinID,
-		fileExtension)
+	layout := storage.CurrentLayout()
+	storagePath := layout.FilePath(storage.PathVars{
+		ChainName: metaData.ChainName,
+		PinID:     metaData.PinID,
+		Extension: fileExtension,
+		MetaID:    creatorMetaID,
+		Timestamp: timestamp,
+	})
 
 	// Save file to storage (save decompressed content if available)
-	storageType := "local"
-	if conf.Cfg.Storage.Type == "oss" {
-		storageType = "oss"
-	}
+	storageType := storage.RecordType(conf.Cfg.Storage.Type)
 
-	if err := s.storage.Save(storagePath, fileContent); err != nil {
+	storageReceipt, pendingStorage, err := s.saveBlob(model.PendingStorageKindFile, metaData.PinID, metaData.ChainName, storagePath, fileContent)
+	if err != nil {
 		return fmt.Errorf("failed to save file to storage: %w", err)
 	}
+	status := model.StatusSuccess
+	if pendingStorage != "" {
+		status = model.StatusPendingStorage
+	}
 
 	log.Printf("File saved to storage: %s (size: %d bytes, compressed: %v)", storagePath, len(fileContent), isCompressed)
 
-	// Calculate Creator MetaID (SHA256 of address)
-	creatorMetaID := calculateMetaID(creatorAddress)
 	globalMetaId := common_service.ConvertToGlobalMetaId(creatorAddress)
 
 	// Create database record
@@ -927,15 +1147,21 @@ func (s *IndexerService) processFileContentCreate(metaData *indexer.MetaIDData,
 		IsGzipCompressed:    isCompressed,
 		StorageType:         storageType,
 		StoragePath:         storagePath,
+		StorageLayout:       layout.Version(),
+		StorageReceipt:      storageReceipt,
 		ChainName:           metaData.ChainName,
 		BlockHeight:         height,
 		Timestamp:           timestamp,
+		FirstSeenAt:         timestamp,
+		ConfirmedAt:         confirmedAt(height, timestamp),
 		CreatorMetaId:       creatorMetaID,
 		CreatorAddress:      creatorAddress, // Use real creator address
+		ResolutionPending:   metaData.CreatorResolutionPending,
 		CreatorGlobalMetaId: globalMetaId,
 		OwnerAddress:        metaData.OwnerAddress,
-		OwnerMetaId:         calculateMetaID(metaData.OwnerAddress),
-		Status:              model.StatusSuccess,
+		OwnerMetaId:         metaid.Derive(metaData.OwnerAddress),
+		Status:              status,
+		StatusReason:        pendingStorage,
 		State:               0,
 	}
 
@@ -943,6 +1169,10 @@ func (s *IndexerService) processFileContentCreate(metaData *indexer.MetaIDData,
 	if err := s.indexerFileDAO.Create(indexerFile); err != nil {
 		return fmt.Errorf("failed to save file to database: %w", err)
 	}
+	if status == model.StatusSuccess {
+		publishIndexedFile(indexerFile)
+	}
+	recordFileChange("", indexerFile)
 
 	// Add to file info history
 	fileHistory := &model.FileInfoHistory{
@@ -969,19 +1199,16 @@ func (s *IndexerService) processFileContentCreate(metaData *indexer.MetaIDData,
 // processFileContentModify process and save file content for modify operation
 func (s *IndexerService) processFileContentModify(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
 	// Get real creator address
-	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
-		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
-		if err == nil {
-			creatorAddress = realAddress
-		}
-	}
+	creatorAddress := s.fileCreatorAddress(metaData)
 
 	// Process file content
 	fileContent := metaData.Content
 	isCompressed := isGzipCompressed(metaData.Content)
 	if isCompressed {
-		decompressed, err := decompressGzip(metaData.Content)
+		decompressed, err := decompressGzip(metaData.Content, decompressLimits())
+		if errors.Is(err, ErrDecompressLimit) {
+			return s.saveRejectedFile(metaData, model.ChunkTypeSingle, firstPinID, firstPath, creatorAddress, height, timestamp, err.Error())
+		}
 		if err == nil {
 			fileContent = decompressed
 		}
@@ -993,22 +1220,28 @@ func (s *IndexerService) processFileContentModify(metaData *indexer.MetaIDData,
 	fileMd5 := calculateMD5(fileContent)
 	fileHash := calculateSHA256(fileContent)
 	fileType := detectFileType(realContentType)
+	creatorMetaID := metaid.Derive(creatorAddress)
 
-	storagePath := fmt.Sprintf("indexer/%s/%s%s",
-		metaData.ChainName,
-		metaData.PinID,
-		fileExtension)
+	layout := storage.CurrentLayout()
+	storagePath := layout.FilePath(storage.PathVars{
+		ChainName: metaData.ChainName,
+		PinID:     metaData.PinID,
+		Extension: fileExtension,
+		MetaID:    creatorMetaID,
+		Timestamp: timestamp,
+	})
 
-	storageType := "local"
-	if conf.Cfg.Storage.Type == "oss" {
-		storageType = "oss"
-	}
+	storageType := storage.RecordType(conf.Cfg.Storage.Type)
 
-	if err := s.storage.Save(storagePath, fileContent); err != nil {
+	storageReceipt, pendingStorage, err := s.saveBlob(model.PendingStorageKindFile, metaData.PinID, metaData.ChainName, storagePath, fileContent)
+	if err != nil {
 		return fmt.Errorf("failed to save file to storage: %w", err)
 	}
+	status := model.StatusSuccess
+	if pendingStorage != "" {
+		status = model.StatusPendingStorage
+	}
 
-	creatorMetaID := calculateMetaID(creatorAddress)
 	globalMetaId := common_service.ConvertToGlobalMetaId(creatorAddress)
 
 	// Use firstPinID from parameter (resolved from @pinId reference)
@@ -1040,20 +1273,30 @@ func (s *IndexerService) processFileContentModify(metaData *indexer.MetaIDData,
 		IsGzipCompressed:    isCompressed,
 		StorageType:         storageType,
 		StoragePath:         storagePath,
+		StorageLayout:       layout.Version(),
+		StorageReceipt:      storageReceipt,
 		ChainName:           metaData.ChainName,
 		BlockHeight:         height,
 		Timestamp:           timestamp,
+		FirstSeenAt:         timestamp,
+		ConfirmedAt:         confirmedAt(height, timestamp),
 		CreatorMetaId:       creatorMetaID,
 		CreatorAddress:      creatorAddress,
+		ResolutionPending:   metaData.CreatorResolutionPending,
 		OwnerAddress:        metaData.OwnerAddress,
-		OwnerMetaId:         calculateMetaID(metaData.OwnerAddress),
-		Status:              model.StatusSuccess,
+		OwnerMetaId:         metaid.Derive(metaData.OwnerAddress),
+		Status:              status,
+		StatusReason:        pendingStorage,
 		State:               0,
 	}
 
 	if err := s.indexerFileDAO.Create(indexerFile); err != nil {
 		return fmt.Errorf("failed to save file to database: %w", err)
 	}
+	if status == model.StatusSuccess {
+		publishIndexedFile(indexerFile)
+	}
+	recordFileChange("", indexerFile)
 
 	// Add to file info history
 	fileHistory := &model.FileInfoHistory{
@@ -1081,7 +1324,7 @@ func (s *IndexerService) processFileContentModify(metaData *indexer.MetaIDData,
 func (s *IndexerService) processUserNameContent(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
 	// Get real creator address from CreatorInputLocation if available
 	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
+	if metaData.CreatorInputLocation != "" && !metaData.CreatorResolutionPending {
 		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
 		if err != nil {
 			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
@@ -1092,8 +1335,8 @@ func (s *IndexerService) processUserNameContent(metaData *indexer.MetaIDData, fi
 		}
 	}
 
-	// Calculate Creator MetaID (SHA256 of address)
-	creatorMetaID := calculateMetaID(creatorAddress)
+	// Calculate Creator MetaID (per metaid.derivation)
+	creatorMetaID := metaid.Derive(creatorAddress)
 
 	// Save MetaID-Address mapping for bidirectional lookup
 	if err := database.DB.SaveMetaIdAddress(creatorMetaID, creatorAddress); err != nil {
@@ -1109,7 +1352,7 @@ func (s *IndexerService) processUserNameContent(metaData *indexer.MetaIDData, fi
 	}
 
 	// Save MetaID-Timestamp mapping (only earliest timestamp)
-	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, timestamp); err != nil {
+	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, metaData.ChainName, timestamp); err != nil {
 		log.Printf("Failed to save MetaID-Timestamp mapping: %v", err)
 	}
 
@@ -1127,10 +1370,15 @@ func (s *IndexerService) processUserNameContent(metaData *indexer.MetaIDData, fi
 		Timestamp:   timestamp,
 	}
 
-	// Save to database - latest info
+	// Save to database - latest info; the previous value is read first to report the change
+	prevProfile, prevOK := profileBefore(model.ProfileFieldName, creatorMetaID, metaData)
 	if err := database.DB.CreateOrUpdateLatestUserNameInfo(userNameInfo, creatorMetaID); err != nil {
 		return fmt.Errorf("failed to save user name info to database: %w", err)
 	}
+	recordUserInfoChange(model.ChangeUserNameUpdated, metaData, firstPinID, creatorMetaID, height, timestamp, userNameInfo)
+	if prevOK {
+		s.notifyProfileChange(model.ProfileFieldName, metaData, prevProfile, creatorAddress, creatorMetaID, globalMetaId, height, timestamp)
+	}
 
 	// Save to database - history
 	if err := database.DB.AddUserNameInfoHistory(userNameInfo, creatorMetaID); err != nil {
@@ -1160,7 +1408,7 @@ func (s *IndexerService) processUserNameContent(metaData *indexer.MetaIDData, fi
 func (s *IndexerService) processUserAvatarInfoContent(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
 	// Get real creator address from CreatorInputLocation if available
 	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
+	if metaData.CreatorInputLocation != "" && !metaData.CreatorResolutionPending {
 		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
 		if err != nil {
 			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
@@ -1171,8 +1419,8 @@ func (s *IndexerService) processUserAvatarInfoContent(metaData *indexer.MetaIDDa
 		}
 	}
 
-	// Calculate Creator MetaID (SHA256 of address)
-	creatorMetaID := calculateMetaID(creatorAddress)
+	// Calculate Creator MetaID (per metaid.derivation)
+	creatorMetaID := metaid.Derive(creatorAddress)
 
 	// Save MetaID-Address mapping for bidirectional lookup
 	if err := database.DB.SaveMetaIdAddress(creatorMetaID, creatorAddress); err != nil {
@@ -1188,46 +1436,58 @@ func (s *IndexerService) processUserAvatarInfoContent(metaData *indexer.MetaIDDa
 	}
 
 	// Save MetaID-Timestamp mapping (only earliest timestamp)
-	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, timestamp); err != nil {
+	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, metaData.ChainName, timestamp); err != nil {
 		log.Printf("Failed to save MetaID-Timestamp mapping: %v", err)
 	}
 
+	// Validate the avatar image (decodable, within size/dimension limits; optionally downscaled)
+	avatarCheck := validateAvatar(metaData.Content, avatarLimits())
+	avatarContent := avatarCheck.Content
+
 	// Detect real content type from file content
-	realContentType := detectRealContentType(metaData.Content, metaData.ContentType)
+	realContentType := detectRealContentType(avatarContent, metaData.ContentType)
+	if avatarCheck.ContentType != "" {
+		realContentType = avatarCheck.ContentType
+	}
 
 	// Extract file extension from real content type
 	fileExtension := contentTypeToExtension(realContentType)
 
-	// Calculate file hashes
+	// Calculate file hashes (of the on-chain content)
 	fileMd5 := calculateMD5(metaData.Content)
 	fileHash := calculateSHA256(metaData.Content)
 
 	// Detect file type from real content type
 	fileType := detectFileType(realContentType)
 
-	// Determine storage path: indexer/avatar/{chain}/{txid}/{pinid}{extension}
-	// Use pinID as filename to ensure uniqueness, with file extension
-	storagePath := fmt.Sprintf("indexer/avatar/%s/%s/%s%s",
-		metaData.ChainName,
-		metaData.TxID,
-		metaData.PinID,
-		fileExtension)
+	// Invalid avatars are recorded without storing content, so clients fall back to a default
+	var storagePath, avatarUrl string
+	if avatarCheck.Invalid {
+		log.Printf("Invalid avatar PIN %s: %s", metaData.PinID, avatarCheck.Reason)
+	} else {
+		// Determine storage path: indexer/avatar/{chain}/{txid}/{pinid}{extension}
+		// Use pinID as filename to ensure uniqueness, with file extension
+		storagePath = fmt.Sprintf("indexer/avatar/%s/%s/%s%s",
+			metaData.ChainName,
+			metaData.TxID,
+			metaData.PinID,
+			fileExtension)
 
-	// Save file to storage
-	if err := s.storage.Save(storagePath, metaData.Content); err != nil {
-		return fmt.Errorf("failed to save avatar to storage: %w", err)
-	}
+		// Save file to storage
+		if err := s.storage.Save(storagePath, avatarContent); err != nil {
+			return fmt.Errorf("failed to save avatar to storage: %w", err)
+		}
 
-	log.Printf("Avatar saved to storage: %s (size: %d bytes)", storagePath, len(metaData.Content))
+		log.Printf("Avatar saved to storage: %s (size: %d bytes, downscaled: %v)", storagePath, len(avatarContent), avatarCheck.Downscaled)
 
-	// Build avatar URL based on storage type
-	var avatarUrl string
-	if conf.Cfg.Storage.Type == "oss" && conf.Cfg.Storage.OSS.Domain != "" {
-		// OSS storage: use domain + storage path
-		avatarUrl = fmt.Sprintf("%s/%s", conf.Cfg.Storage.OSS.Domain, storagePath)
-	} else {
-		// Local storage: use indexer API endpoint
-		avatarUrl = fmt.Sprintf("/api/v1/avatars/content/%s", metaData.PinID)
+		// Build avatar URL based on storage type
+		if conf.Cfg.Storage.Type == "oss" && conf.Cfg.Storage.OSS.Domain != "" {
+			// OSS storage: use domain + storage path
+			avatarUrl = fmt.Sprintf("%s/%s", conf.Cfg.Storage.OSS.Domain, storagePath)
+		} else {
+			// Local storage: use indexer API endpoint
+			avatarUrl = fmt.Sprintf("/api/v1/avatars/content/%s", metaData.PinID)
+		}
 	}
 
 	// Create user avatar info
@@ -1240,18 +1500,28 @@ func (s *IndexerService) processUserAvatarInfoContent(metaData *indexer.MetaIDDa
 		ChainName:     metaData.ChainName,
 		BlockHeight:   height,
 		Timestamp:     timestamp,
-		ContentType:   metaData.ContentType,
-		FileSize:      int64(len(metaData.Content)),
+		ContentType:   realContentType,
+		FileSize:      int64(len(avatarContent)),
 		FileMd5:       fileMd5,
 		FileHash:      fileHash,
 		FileExtension: fileExtension,
 		FileType:      fileType,
+		Width:         avatarCheck.Width,
+		Height:        avatarCheck.Height,
+		Downscaled:    avatarCheck.Downscaled,
+		Invalid:       avatarCheck.Invalid,
+		InvalidReason: avatarCheck.Reason,
 	}
 
-	// Save to database - latest info
+	// Save to database - latest info; the previous value is read first to report the change
+	prevProfile, prevOK := profileBefore(model.ProfileFieldAvatar, creatorMetaID, metaData)
 	if err := database.DB.CreateOrUpdateLatestUserAvatarInfo(userAvatarInfo, creatorMetaID); err != nil {
 		return fmt.Errorf("failed to save user avatar info to database: %w", err)
 	}
+	recordUserInfoChange(model.ChangeAvatarUpdated, metaData, firstPinID, creatorMetaID, height, timestamp, userAvatarInfo)
+	if prevOK {
+		s.notifyProfileChange(model.ProfileFieldAvatar, metaData, prevProfile, creatorAddress, creatorMetaID, globalMetaId, height, timestamp)
+	}
 
 	// Save to database - history
 	if err := database.DB.AddUserAvatarInfoHistory(userAvatarInfo, creatorMetaID); err != nil {
@@ -1271,8 +1541,8 @@ func (s *IndexerService) processUserAvatarInfoContent(metaData *indexer.MetaIDDa
 		}
 	}
 
-	log.Printf("User avatar info indexed successfully: PIN=%s, Avatar=%s, URL=%s, Type=%s, Ext=%s, Size=%d, MetaID=%s, Address=%s",
-		metaData.PinID, storagePath, avatarUrl, fileType, fileExtension, len(metaData.Content), creatorMetaID, creatorAddress)
+	log.Printf("User avatar info indexed successfully: PIN=%s, Avatar=%s, URL=%s, Type=%s, Ext=%s, Size=%d, Invalid=%v, MetaID=%s, Address=%s",
+		metaData.PinID, storagePath, avatarUrl, fileType, fileExtension, len(avatarContent), avatarCheck.Invalid, creatorMetaID, creatorAddress)
 
 	return nil
 }
@@ -1281,7 +1551,7 @@ func (s *IndexerService) processUserAvatarInfoContent(metaData *indexer.MetaIDDa
 func (s *IndexerService) processUserBioContent(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
 	// Get real creator address from CreatorInputLocation if available
 	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
+	if metaData.CreatorInputLocation != "" && !metaData.CreatorResolutionPending {
 		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
 		if err != nil {
 			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
@@ -1292,8 +1562,8 @@ func (s *IndexerService) processUserBioContent(metaData *indexer.MetaIDData, fir
 		}
 	}
 
-	// Calculate Creator MetaID (SHA256 of address)
-	creatorMetaID := calculateMetaID(creatorAddress)
+	// Calculate Creator MetaID (per metaid.derivation)
+	creatorMetaID := metaid.Derive(creatorAddress)
 
 	// Save MetaID-Address mapping for bidirectional lookup
 	if err := database.DB.SaveMetaIdAddress(creatorMetaID, creatorAddress); err != nil {
@@ -1309,7 +1579,7 @@ func (s *IndexerService) processUserBioContent(metaData *indexer.MetaIDData, fir
 	}
 
 	// Save MetaID-Timestamp mapping (only earliest timestamp)
-	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, timestamp); err != nil {
+	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, metaData.ChainName, timestamp); err != nil {
 		log.Printf("Failed to save MetaID-Timestamp mapping: %v", err)
 	}
 
@@ -1340,6 +1610,7 @@ func (s *IndexerService) processUserBioContent(metaData *indexer.MetaIDData, fir
 	if err := database.DB.CreateOrUpdateLatestUserBioInfo(userBioInfo, creatorMetaID); err != nil {
 		return fmt.Errorf("failed to save user bio info to database: %w", err)
 	}
+	recordUserInfoChange(model.ChangeUserBioUpdated, metaData, firstPinID, creatorMetaID, height, timestamp, userBioInfo)
 
 	// Save to database - history
 	if err := database.DB.AddUserBioInfoHistory(userBioInfo, creatorMetaID); err != nil {
@@ -1369,7 +1640,7 @@ func (s *IndexerService) processUserBioContent(metaData *indexer.MetaIDData, fir
 func (s *IndexerService) processUserChatPublicKeyContent(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
 	// Get real creator address from CreatorInputLocation if available
 	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
+	if metaData.CreatorInputLocation != "" && !metaData.CreatorResolutionPending {
 		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
 		if err != nil {
 			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
@@ -1380,8 +1651,8 @@ func (s *IndexerService) processUserChatPublicKeyContent(metaData *indexer.MetaI
 		}
 	}
 
-	// Calculate Creator MetaID (SHA256 of address)
-	creatorMetaID := calculateMetaID(creatorAddress)
+	// Calculate Creator MetaID (per metaid.derivation)
+	creatorMetaID := metaid.Derive(creatorAddress)
 
 	// Save MetaID-Address mapping for bidirectional lookup
 	if err := database.DB.SaveMetaIdAddress(creatorMetaID, creatorAddress); err != nil {
@@ -1397,12 +1668,16 @@ func (s *IndexerService) processUserChatPublicKeyContent(metaData *indexer.MetaI
 	}
 
 	// Save MetaID-Timestamp mapping (only earliest timestamp)
-	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, timestamp); err != nil {
+	if err := database.DB.SaveMetaIdTimestamp(creatorMetaID, metaData.ChainName, timestamp); err != nil {
 		log.Printf("Failed to save MetaID-Timestamp mapping: %v", err)
 	}
 
-	// Extract chat public key from content (assume content is text)
-	chatPublicKey := string(metaData.Content)
+	// Extract chat public key from content and validate its format (secp256k1 / x25519)
+	keyCheck := validateChatPublicKey(metaData.Content)
+	chatPublicKey := keyCheck.PublicKey
+	if keyCheck.Invalid {
+		log.Printf("Invalid chat public key PIN %s: %s", metaData.PinID, keyCheck.Reason)
+	}
 
 	// Create user chat public key info
 	userChatPublicKeyInfo := &model.UserChatPublicKeyInfo{
@@ -1413,12 +1688,21 @@ func (s *IndexerService) processUserChatPublicKeyContent(metaData *indexer.MetaI
 		ChainName:     metaData.ChainName,
 		BlockHeight:   height,
 		Timestamp:     timestamp,
+		KeyType:       keyCheck.KeyType,
+		KeyEncoding:   keyCheck.Encoding,
+		Invalid:       keyCheck.Invalid,
+		InvalidReason: keyCheck.Reason,
 	}
 
-	// Save to database - latest info
+	// Save to database - latest info; the previous value is read first to report the change
+	prevProfile, prevOK := profileBefore(model.ProfileFieldChatPublicKey, creatorMetaID, metaData)
 	if err := database.DB.CreateOrUpdateLatestUserChatPublicKeyInfo(userChatPublicKeyInfo, creatorMetaID); err != nil {
 		return fmt.Errorf("failed to save user chat public key info to database: %w", err)
 	}
+	recordUserInfoChange(model.ChangeChatPublicKeyUpdated, metaData, firstPinID, creatorMetaID, height, timestamp, userChatPublicKeyInfo)
+	if prevOK {
+		s.notifyProfileChange(model.ProfileFieldChatPublicKey, metaData, prevProfile, creatorAddress, creatorMetaID, globalMetaId, height, timestamp)
+	}
 
 	// Save to database - history
 	if err := database.DB.AddUserChatPublicKeyHistory(userChatPublicKeyInfo, creatorMetaID); err != nil {
@@ -1438,8 +1722,8 @@ func (s *IndexerService) processUserChatPublicKeyContent(metaData *indexer.MetaI
 		}
 	}
 
-	log.Printf("User chat public key indexed successfully: PIN=%s, ChatPublicKey=%s, MetaID=%s, Address=%s",
-		metaData.PinID, chatPublicKey, creatorMetaID, creatorAddress)
+	log.Printf("User chat public key indexed successfully: PIN=%s, ChatPublicKey=%s, KeyType=%s, Invalid=%v, MetaID=%s, Address=%s",
+		metaData.PinID, chatPublicKey, keyCheck.KeyType, keyCheck.Invalid, creatorMetaID, creatorAddress)
 
 	return nil
 }
@@ -1470,7 +1754,7 @@ func detectRealContentType(content []byte, declaredContentType string) string {
 	detectedType := http.DetectContentType(content)
 
 	// Log if detected type differs from declared type
-	if detectedType != declaredContentType {
+	if contenttype.MediaType(detectedType) != contenttype.MediaType(declaredContentType) {
 		log.Printf("Content type mismatch - Declared: %s, Detected: %s", declaredContentType, detectedType)
 	}
 
@@ -1479,7 +1763,7 @@ func detectRealContentType(content []byte, declaredContentType string) string {
 	// we trust the declared type
 	if detectedType == "application/octet-stream" && declaredContentType != "" {
 		// If detection returns generic binary type but we have a declared type, use declared
-		return declaredContentType
+		return contenttype.Normalize(declaredContentType)
 	}
 
 	return detectedType
@@ -1510,10 +1794,7 @@ func extractFileExtension(path string, contentType string, content []byte) strin
 // contentTypeToExtension map content type to file extension
 func contentTypeToExtension(contentType string) string {
 	// Remove parameters from content type (e.g., "image/jpeg;binary" -> "image/jpeg")
-	if idx := strings.Index(contentType, ";"); idx != -1 {
-		contentType = contentType[:idx]
-	}
-	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	contentType = contenttype.MediaType(contentType)
 
 	// Map content type to file extension
 	extensionMap := map[string]string{
@@ -1585,10 +1866,7 @@ func contentTypeToExtension(contentType string) string {
 // detectFileType detect file type category from content type
 func detectFileType(contentType string) string {
 	// Remove parameters from content type
-	if idx := strings.Index(contentType, ";"); idx != -1 {
-		contentType = contentType[:idx]
-	}
-	contentType = strings.TrimSpace(strings.ToLower(contentType))
+	contentType = contenttype.MediaType(contentType)
 
 	// Detect file type category
 	switch {
@@ -1628,15 +1906,6 @@ func calculateSHA256(content []byte) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// calculateMetaID calculate MetaID from address (SHA256 hash)
-func calculateMetaID(address string) string {
-	if address == "" {
-		return ""
-	}
-	hash := sha256.Sum256([]byte(address))
-	return hex.EncodeToString(hash[:])
-}
-
 // resolvePathAndFirstPinID resolve path and firstPinID if it's a reference (@pinId or host:@pinId)
 // Returns: (resolvedPath, firstPinID, firstPath)
 func (s *IndexerService) resolvePathAndFirstPinID(path string) (string, string, string, bool) {
@@ -1645,19 +1914,9 @@ func (s *IndexerService) resolvePathAndFirstPinID(path string) (string, string,
 	var refPinID string
 	isValidOperation := true
 
-	if strings.HasPrefix(path, "@") {
-		// Format: @pinId
-		refPinID = strings.TrimPrefix(path, "@")
-	} else if strings.Contains(path, ":@") {
-		// Format: host:@pinId
-		parts := strings.SplitN(path, ":@", 2)
-		if len(parts) == 2 {
-			refPinID = parts[1]
-		}
+	if parsed, err := metaid_protocols.ParseMetaIDPath(path); err == nil && parsed.IsReference() {
+		refPinID = parsed.RefPinID
 	}
-	//  else {
-	// 	isValidOperation = false
-	// }
 
 	// If no reference found, return original path and empty values
 	if refPinID == "" {
@@ -1696,7 +1955,10 @@ func (s *IndexerService) processChunkContent(metaData *indexer.MetaIDData, first
 	isCompressed := isGzipCompressed(metaData.Content)
 	if isCompressed {
 		log.Printf("Detected gzip compressed chunk content for PIN: %s, decompressing...", metaData.PinID)
-		decompressed, err := decompressGzip(metaData.Content)
+		decompressed, err := decompressGzip(metaData.Content, decompressLimits())
+		if errors.Is(err, ErrDecompressLimit) {
+			return s.saveRejectedChunk(metaData, height, err.Error())
+		}
 		if err != nil {
 			log.Printf("Failed to decompress gzip chunk content for PIN %s: %v, using original content", metaData.PinID, err)
 			// Continue with original content if decompression fails
@@ -1711,21 +1973,26 @@ func (s *IndexerService) processChunkContent(metaData *indexer.MetaIDData, first
 	chunkMd5 := calculateMD5(chunkContent)
 	chunkHash := calculateSHA256(chunkContent)
 
-	// Determine storage path: indexer/chunk/{chain}/{txid}/{pinid}
-	storagePath := fmt.Sprintf("indexer/chunk/%s/%s/%s",
-		metaData.ChainName,
-		metaData.TxID,
-		metaData.PinID)
+	// Determine storage path from the configured layout (storage.Layout)
+	layout := storage.CurrentLayout()
+	storagePath := layout.ChunkPath(storage.PathVars{
+		ChainName: metaData.ChainName,
+		PinID:     metaData.PinID,
+		TxID:      metaData.TxID,
+		Timestamp: timestamp,
+	})
 
 	// Save chunk to storage (save decompressed content if available)
-	storageType := "local"
-	if conf.Cfg.Storage.Type == "oss" {
-		storageType = "oss"
-	}
+	storageType := storage.RecordType(conf.Cfg.Storage.Type)
 
-	if err := s.storage.Save(storagePath, chunkContent); err != nil {
+	storageReceipt, pendingStorage, err := s.saveBlob(model.PendingStorageKindChunk, metaData.PinID, metaData.ChainName, storagePath, chunkContent)
+	if err != nil {
 		return fmt.Errorf("failed to save chunk to storage: %w", err)
 	}
+	status := model.StatusSuccess
+	if pendingStorage != "" {
+		status = model.StatusPendingStorage
+	}
 
 	log.Printf("Chunk saved to storage: %s (size: %d bytes, compressed: %v)", storagePath, len(chunkContent), isCompressed)
 
@@ -1744,13 +2011,17 @@ func (s *IndexerService) processChunkContent(metaData *indexer.MetaIDData, first
 		ChunkIndex:       chunkIndex,
 		ChunkSize:        int64(len(chunkContent)),
 		ChunkMd5:         chunkMd5,
+		ChunkSha256:      chunkHash,
 		IsGzipCompressed: isCompressed,
 		ParentPinID:      "", // Will be set when index is processed
 		StorageType:      storageType,
 		StoragePath:      storagePath,
+		StorageLayout:    layout.Version(),
+		StorageReceipt:   storageReceipt,
 		ChainName:        metaData.ChainName,
 		BlockHeight:      height,
-		Status:           model.StatusSuccess,
+		Status:           status,
+		StatusReason:     pendingStorage,
 		State:            0,
 	}
 
@@ -1758,6 +2029,13 @@ func (s *IndexerService) processChunkContent(metaData *indexer.MetaIDData, first
 	if err := s.indexerFileChunkDAO.Create(indexerFileChunk); err != nil {
 		return fmt.Errorf("failed to save chunk to database: %w", err)
 	}
+	recordChange(&model.IndexerChangeEvent{
+		Action:      model.ChangeChunkIndexed,
+		ChainName:   metaData.ChainName,
+		PinID:       metaData.PinID,
+		BlockHeight: height,
+		Timestamp:   timestamp,
+	}, indexerFileChunk)
 
 	log.Printf("Chunk indexed successfully: PIN=%s, Path=%s, Size=%d, Hash=%s, Compressed=%v",
 		metaData.PinID, metaData.Path, len(chunkContent), chunkHash, isCompressed)
@@ -1767,130 +2045,124 @@ func (s *IndexerService) processChunkContent(metaData *indexer.MetaIDData, first
 
 // processIndexContent process and save index content, then merge chunks if all are available
 func (s *IndexerService) processIndexContent(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64) error {
-	// Get real creator address from CreatorInputLocation if available
-	creatorAddress := metaData.CreatorAddress
-	if metaData.CreatorInputLocation != "" {
-		realAddress, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType)
-		if err != nil {
-			log.Printf("Failed to get creator address from location %s: %v, using fallback address",
-				metaData.CreatorInputLocation, err)
-		} else {
-			creatorAddress = realAddress
-			log.Printf("Found real creator address for index: %s (from location: %s)", realAddress, metaData.CreatorInputLocation)
-		}
-	}
-
-	// Parse index JSON content
-	// First parse to a flexible structure to handle numeric fields that might be floats
-	var rawIndex map[string]interface{}
-	if err := json.Unmarshal(metaData.Content, &rawIndex); err != nil {
-		return fmt.Errorf("failed to parse index JSON: %w", err)
+	if confirmed, err := s.confirmMempoolFile(metaData.PinID, height, timestamp); confirmed || err != nil {
+		return err
 	}
 
-	// Convert float values to int for numeric fields (chunkSize, fileSize)
-	if chunkSize, ok := rawIndex["chunkSize"]; ok {
-		switch v := chunkSize.(type) {
-		case float64:
-			rawIndex["chunkSize"] = int64(v)
-		}
-	}
-	if fileSize, ok := rawIndex["fileSize"]; ok {
-		switch v := fileSize.(type) {
-		case float64:
-			rawIndex["fileSize"] = int64(v)
-		}
-	}
+	// Get real creator address from CreatorInputLocation if available; a failed
+	// lookup is queued and the merged file saved with ResolutionPending
+	creatorAddress := s.fileCreatorAddress(metaData)
 
-	// Re-marshal and unmarshal to the proper struct
-	correctedJSON, err := json.Marshal(rawIndex)
+	// Parse index JSON content
+	metaFileIndex, err := parseMetaFileIndex(metaData.Content)
 	if err != nil {
-		return fmt.Errorf("failed to re-marshal corrected JSON: %w", err)
-	}
-
-	var metaFileIndex metaid_protocols.MetaFileIndex
-	if err := json.Unmarshal(correctedJSON, &metaFileIndex); err != nil {
-		return fmt.Errorf("failed to parse index JSON: %w", err)
+		return err
 	}
 
-	log.Printf("Parsed index: sha256=%s, fileSize=%d, chunkNumber=%d, chunkSize=%d, dataType=%s, name=%s",
-		metaFileIndex.Sha256, metaFileIndex.FileSize, metaFileIndex.ChunkNumber,
+	log.Printf("Parsed index: version=%d, sha256=%s, fileSize=%d, chunkNumber=%d, chunkSize=%d, dataType=%s, name=%s",
+		metaFileIndex.IndexVersion(), metaFileIndex.Sha256, metaFileIndex.FileSize, metaFileIndex.ChunkNumber,
 		metaFileIndex.ChunkSize, metaFileIndex.DataType, metaFileIndex.Name)
+	if err := metaFileIndex.Validate(); err != nil {
+		// Chunks are still merged by sha256/pinId; only the v2 metadata is off
+		log.Printf("Warning: invalid v%d index PIN=%s: %v", metaFileIndex.IndexVersion(), metaData.PinID, err)
+	}
 
 	// Check if all chunks are available
+	indexPinID := metaData.PinID
+	creatorMetaID := metaid.Derive(creatorAddress)
 	allChunksAvailable := true
 	var chunks []*model.IndexerFileChunk
 	for _, chunkInfo := range metaFileIndex.ChunkList {
-		chunk, err := s.indexerFileChunkDAO.GetByPinID(chunkInfo.PinId)
-		if err != nil || chunk == nil {
+		chunk := s.resolveIndexChunk(chunkInfo.PinId, chunkInfo.Sha256, indexPinID, creatorMetaID)
+		if chunk == nil {
 			log.Printf("Chunk not found: PIN=%s, SHA256=%s", chunkInfo.PinId, chunkInfo.Sha256)
 			allChunksAvailable = false
 			break
 		}
 
-		// Verify chunk hash matches
-		if chunk.ChunkMd5 != "" {
-			// We can verify using SHA256 if available, but for now just check existence
-			// The actual verification should be done when merging
-		}
-
+		// Chunk sha256 is verified against chunkInfo.Sha256 in mergeAndSaveIndex.
 		chunks = append(chunks, chunk)
 	}
 
-	// Update parent_pin_id for all chunks
-	indexPinID := metaData.PinID
-	for i, chunk := range chunks {
-		if chunk.ParentPinID != indexPinID {
-			chunk.ParentPinID = indexPinID
-			chunk.ChunkIndex = i // Set chunk index based on order in chunkList
-			if err := s.indexerFileChunkDAO.Update(chunk); err != nil {
-				log.Printf("Failed to update chunk parent PIN ID: %v", err)
-			}
-		}
-	}
+	// Attach the chunks that do not belong to another file yet
+	s.claimIndexChunks(indexPinID, chunks)
 
 	// If all chunks are available, merge and save the complete file now.
 	// Otherwise persist a PendingIndexFile so onBlockComplete can retry the
 	// merge once the missing chunks land (instead of silently dropping it).
 	if allChunksAvailable && len(chunks) > 0 {
-		if err := s.mergeAndSaveIndex(metaData, &metaFileIndex, creatorAddress, chunks, firstPinID, firstPath, height, timestamp); err != nil {
-			return err
+		if err := s.mergeAndSaveIndex(metaData, metaFileIndex, creatorAddress, chunks, firstPinID, firstPath, height, timestamp); err != nil {
+			if errors.Is(err, ErrDeltaBaseNotIndexed) {
+				log.Printf("Deferring merge of delta index PIN=%s: %v", indexPinID, err)
+				return s.savePendingIndex(metaData, metaFileIndex, firstPinID, firstPath, height, timestamp, nil)
+			}
+			var mismatch *ChunkHashMismatchError
+			if !errors.As(err, &mismatch) {
+				return err
+			}
+			// Keep the index around with the offending chunk recorded so the
+			// merge can be retried (and the chunk re-fetched) on later blocks.
+			log.Printf("Refusing to merge index PIN=%s: %v", indexPinID, err)
+			return s.savePendingIndex(metaData, metaFileIndex, firstPinID, firstPath, height, timestamp, mismatch)
 		}
 	} else {
 		log.Printf("Not all chunks available yet for index PIN=%s. Chunks found: %d/%d. Deferring merge; will retry on later blocks.",
 			indexPinID, len(chunks), metaFileIndex.ChunkNumber)
-
-		metaDataJSON, err := json.Marshal(metaData)
-		if err != nil {
-			return fmt.Errorf("failed to marshal metaData for pending index: %w", err)
-		}
-		indexJSON, err := json.Marshal(metaFileIndex)
-		if err != nil {
-			return fmt.Errorf("failed to marshal index JSON for pending index: %w", err)
-		}
-		// firstPinID for create == indexPinID (mirrors mergeAndSaveIndex below).
-		pendingFirstPinID := firstPinID
-		if pendingFirstPinID == "" || metaData.Operation == "create" {
-			pendingFirstPinID = indexPinID
-		}
-		pending := &model.PendingIndexFile{
-			PinID:       indexPinID,
-			FirstPinID:  pendingFirstPinID,
-			FirstPath:   firstPath,
-			TxID:        metaData.TxID,
-			ChainName:   metaData.ChainName,
-			BlockHeight: height,
-			Timestamp:   timestamp,
-			MetaData:    string(metaDataJSON),
-			IndexJSON:   string(indexJSON),
-		}
-		if err := s.pendingIndexFileDAO.Create(pending); err != nil {
-			return fmt.Errorf("failed to save pending index for deferred merge: %w", err)
-		}
+		return s.savePendingIndex(metaData, metaFileIndex, firstPinID, firstPath, height, timestamp, nil)
 	}
 
 	return nil
 }
 
+// savePendingIndex persists a PendingIndexFile for an index pin whose merge
+// could not complete yet, so retryPendingIndexMerges picks it up later.
+// mismatch, when non-nil, records the chunk that failed hash verification.
+func (s *IndexerService) savePendingIndex(
+	metaData *indexer.MetaIDData,
+	metaFileIndex *metaid_protocols.MetaFileIndex,
+	firstPinID, firstPath string,
+	height, timestamp int64,
+	mismatch *ChunkHashMismatchError,
+) error {
+	indexPinID := metaData.PinID
+	metaDataJSON, err := json.Marshal(metaData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metaData for pending index: %w", err)
+	}
+	indexJSON, err := json.Marshal(metaFileIndex)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index JSON for pending index: %w", err)
+	}
+	// firstPinID for create == indexPinID (mirrors mergeAndSaveIndex below).
+	pendingFirstPinID := firstPinID
+	if pendingFirstPinID == "" || metaData.Operation == "create" {
+		pendingFirstPinID = indexPinID
+	}
+	pending := &model.PendingIndexFile{
+		PinID:       indexPinID,
+		FirstPinID:  pendingFirstPinID,
+		FirstPath:   firstPath,
+		TxID:        metaData.TxID,
+		ChainName:   metaData.ChainName,
+		BlockHeight: height,
+		Timestamp:   timestamp,
+		MetaData:    string(metaDataJSON),
+		IndexJSON:   string(indexJSON),
+	}
+	// A re-processed index (rescan) keeps the attempts already spent on it
+	if existing, err := s.pendingIndexFileDAO.GetByPinID(indexPinID); err == nil && existing != nil {
+		pending.MismatchAttempts = existing.MismatchAttempts
+		pending.Status = existing.Status
+	}
+	if mismatch != nil {
+		recordChunkMismatch(pending, mismatch)
+	}
+	if err := s.pendingIndexFileDAO.Create(pending); err != nil {
+		return fmt.Errorf("failed to save pending index for deferred merge: %w", err)
+	}
+	return nil
+}
+
 // mergeAndSaveIndex merges the already-verified-available chunks of a multi-
 // chunk file into one file, saves it to storage, and writes the IndexerFile
 // record. Shared by processIndexContent (live path) and retryPendingIndexMerges
@@ -1907,6 +2179,23 @@ func (s *IndexerService) mergeAndSaveIndex(
 	indexPinID := metaData.PinID
 	log.Printf("All chunks available, merging file: index PIN=%s", indexPinID)
 
+	// Determine firstPinID based on operation
+	fileFirstPinID := firstPinID
+	if fileFirstPinID == "" {
+		fileFirstPinID = indexPinID // Fallback to indexPinID
+	}
+	if metaData.Operation == "create" {
+		fileFirstPinID = indexPinID // For create, firstPinID = PinID
+	}
+
+	// A rejected chunk (e.g. a gzip bomb) rejects the whole file
+	for i, chunk := range chunks {
+		if chunk.Status == model.StatusRejected {
+			reason := fmt.Sprintf("chunk %d (PIN=%s) rejected: %s", i, chunk.PinID, chunk.StatusReason)
+			return s.saveRejectedFile(metaData, model.ChunkTypeMulti, fileFirstPinID, firstPath, creatorAddress, height, timestamp, reason)
+		}
+	}
+
 	// Check if all chunks are gzip compressed
 	allChunksCompressed := true
 	for _, chunk := range chunks {
@@ -1916,13 +2205,28 @@ func (s *IndexerService) mergeAndSaveIndex(
 		}
 	}
 
+	// Files over indexer.max_merged_file_mb are not merged: they are kept as
+	// their chunks and streamed from them
+	var chunksSize int64
+	for _, chunk := range chunks {
+		chunksSize += chunk.ChunkSize
+	}
+	if max(chunksSize, metaFileIndex.FileSize) > maxMergedFileBytes() {
+		return s.saveChunkedIndex(metaData, metaFileIndex, creatorAddress, chunks, fileFirstPinID, firstPath, height, timestamp, allChunksCompressed)
+	}
+
+	// Load and verify each chunk against the index chunkList sha256 before
+	// merging; a mismatch that re-fetching cannot repair refuses the merge.
+	chunkContents, err := s.loadVerifiedChunks(metaFileIndex, chunks)
+	if err != nil {
+		return err
+	}
+
 	// Merge chunks in order
 	var mergedContent []byte
-	for _, chunk := range chunks {
-		// Load chunk content from storage
-		chunkContent, err := s.storage.Get(chunk.StoragePath)
-		if err != nil {
-			return fmt.Errorf("failed to load chunk from storage: %w", err)
+	for i, chunkContent := range chunkContents {
+		if _, length := metaFileIndex.ChunkRange(i); int64(len(chunkContent)) != length {
+			log.Printf("Warning: chunk %d length mismatch. Expected: %d, Got: %d", i, length, len(chunkContent))
 		}
 		mergedContent = append(mergedContent, chunkContent...)
 	}
@@ -1939,128 +2243,194 @@ func (s *IndexerService) mergeAndSaveIndex(
 		log.Printf("Warning: Merged file size mismatch. Expected: %d, Got: %d", metaFileIndex.FileSize, len(mergedContent))
 	}
 
-	// Detect real content type
-	realContentType := detectRealContentType(mergedContent, metaFileIndex.DataType)
-
-	// Extract file extension
-	fileExtension := contentTypeToExtension(realContentType)
-	if fileExtension == "" && metaFileIndex.Name != "" {
-		// Try to get extension from name
-		fileExtension = filepath.Ext(metaFileIndex.Name)
+	// A v2 index may declare the whole file gzipped before chunking; store it
+	// decompressed like single-PIN gzip content. Ciphertext is kept as is.
+	if metaFileIndex.IsGzipCompressed() && !metaFileIndex.IsEncrypted() {
+		decompressed, err := decompressGzip(mergedContent, decompressLimits())
+		if errors.Is(err, ErrDecompressLimit) {
+			return s.saveRejectedFile(metaData, model.ChunkTypeMulti, fileFirstPinID, firstPath, creatorAddress, height, timestamp, err.Error())
+		}
+		if err != nil {
+			log.Printf("Failed to decompress gzip file of index PIN %s: %v, using merged content", indexPinID, err)
+		} else {
+			mergedContent = decompressed
+			allChunksCompressed = true
+		}
 	}
 
-	// Calculate file hashes
-	fileMd5 := calculateMD5(mergedContent)
-	fileHash := calculateSHA256(mergedContent)
-
-	// Detect file type
-	fileType := detectFileType(realContentType)
+	// A delta index carries the difference to an earlier file: rebuild the
+	// file from the base before it is inspected and stored
+	fileSize := metaFileIndex.FileSize
+	if metaFileIndex.IsDelta() {
+		rebuilt, err := s.applyFileDelta(metaFileIndex.Delta, mergedContent)
+		if errors.Is(err, metaid_protocols.ErrInvalidDelta) {
+			return s.saveRejectedFile(metaData, model.ChunkTypeMulti, fileFirstPinID, firstPath, creatorAddress, height, timestamp, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+		mergedContent = rebuilt
+		fileSize = int64(len(rebuilt))
+	}
 
-	// Determine storage path: indexer/{chain}/{indexPinID}{extension}
-	storagePath := fmt.Sprintf("indexer/%s/%s%s",
-		metaData.ChainName,
-		indexPinID,
-		fileExtension)
+	// Detect real content type (sniffing ciphertext is meaningless)
+	realContentType := metaFileIndex.DataType
+	if !metaFileIndex.IsEncrypted() {
+		realContentType = detectRealContentType(mergedContent, metaFileIndex.DataType)
+	}
 
-	// Save merged file to storage
-	storageType := "local"
-	if conf.Cfg.Storage.Type == "oss" {
-		storageType = "oss"
+	indexerFile, err := newMergedIndexFile(metaData, metaFileIndex, creatorAddress, fileFirstPinID, firstPath, height, timestamp)
+	if err != nil {
+		return err
 	}
+	indexerFile.FileExtension = mergedFileExtension(realContentType, metaFileIndex.Name)
+	indexerFile.FileType = detectFileType(realContentType)
+	indexerFile.FileSize = fileSize
+	indexerFile.FileMd5 = calculateMD5(mergedContent)
+	indexerFile.FileHash = calculateSHA256(mergedContent)
+	indexerFile.IsGzipCompressed = allChunksCompressed
+
+	// Determine storage path from the configured layout (storage.Layout)
+	layout := storage.CurrentLayout()
+	storagePath := layout.FilePath(storage.PathVars{
+		ChainName: metaData.ChainName,
+		PinID:     indexPinID,
+		Extension: indexerFile.FileExtension,
+		MetaID:    indexerFile.CreatorMetaId,
+		Timestamp: timestamp,
+	})
 
-	if err := s.storage.Save(storagePath, mergedContent); err != nil {
+	// Save merged file to storage
+	storageReceipt, pendingStorage, err := s.saveBlob(model.PendingStorageKindFile, indexPinID, metaData.ChainName, storagePath, mergedContent)
+	if err != nil {
 		return fmt.Errorf("failed to save merged file to storage: %w", err)
 	}
+	if pendingStorage != "" {
+		indexerFile.Status = model.StatusPendingStorage
+		indexerFile.StatusReason = pendingStorage
+	}
+	indexerFile.StorageType = storage.RecordType(conf.Cfg.Storage.Type)
+	indexerFile.StoragePath = storagePath
+	indexerFile.StorageLayout = layout.Version()
+	indexerFile.StorageReceipt = storageReceipt
 
 	log.Printf("Merged file saved to storage: %s (size: %d bytes)", storagePath, len(mergedContent))
 
-	// Calculate Creator MetaID
-	creatorMetaID := calculateMetaID(creatorAddress)
-	globalMetaId := common_service.ConvertToGlobalMetaId(creatorAddress)
+	return s.createMergedIndexFile(metaData, indexerFile)
+}
 
+// newMergedIndexFile the record of a multi-chunk file with the fields taken
+// from the PIN and its index; the caller fills in the content and storage
+// fields
+func newMergedIndexFile(
+	metaData *indexer.MetaIDData,
+	metaFileIndex *metaid_protocols.MetaFileIndex,
+	creatorAddress string,
+	firstPinID, firstPath string,
+	height, timestamp int64,
+) (*model.IndexerFile, error) {
 	data, err := json.Marshal(metaFileIndex)
 	if err != nil {
-		return fmt.Errorf("failed to marshal metaFileIndex: %w", err)
-	}
-
-	// Determine firstPinID based on operation
-	fileFirstPinID := firstPinID
-	if fileFirstPinID == "" {
-		fileFirstPinID = indexPinID // Fallback to indexPinID
+		return nil, fmt.Errorf("failed to marshal metaFileIndex: %w", err)
 	}
-	if metaData.Operation == "create" {
-		fileFirstPinID = indexPinID // For create, firstPinID = PinID
-	}
-
-	// Create database record for merged file
-	indexerFile := &model.IndexerFile{
-		FirstPinID:          fileFirstPinID,
+	return &model.IndexerFile{
+		FirstPinID:          firstPinID,
 		FirstPath:           firstPath,
-		PinID:               indexPinID,
+		PinID:               metaData.PinID,
 		TxID:                metaData.TxID,
 		Vout:                metaData.Vout,
 		Path:                metaData.Path,
 		Operation:           metaData.Operation,
 		ParentPath:          metaData.ParentPath,
-		Encryption:          metaData.Encryption,
+		Encryption:          indexFileEncryption(metaData.Encryption, metaFileIndex),
 		Version:             metaData.Version,
-		ContentType:         metaFileIndex.DataType,
+		ContentType:         contenttype.Normalize(metaFileIndex.DataType),
 		Data:                string(data),
 		ChunkType:           model.ChunkTypeMulti,
-		FileType:            fileType,
-		FileExtension:       fileExtension,
 		FileName:            metaFileIndex.Name,
-		FileSize:            metaFileIndex.FileSize,
-		FileMd5:             fileMd5,
-		FileHash:            fileHash,
-		IsGzipCompressed:    allChunksCompressed,
-		StorageType:         storageType,
-		StoragePath:         storagePath,
 		ChainName:           metaData.ChainName,
-			BlockHeight:         height,
-			Timestamp:           timestamp,
-			CreatorMetaId:       creatorMetaID,
-			CreatorAddress:      creatorAddress,
-			CreatorGlobalMetaId: globalMetaId,
-			OwnerAddress:        metaData.OwnerAddress,
-			OwnerMetaId:         calculateMetaID(metaData.OwnerAddress),
-			Status:              model.StatusSuccess,
-			State:               0,
-		}
+		BlockHeight:         height,
+		Timestamp:           timestamp,
+		FirstSeenAt:         timestamp,
+		ConfirmedAt:         confirmedAt(height, timestamp),
+		CreatorMetaId:       metaid.Derive(creatorAddress),
+		CreatorAddress:      creatorAddress,
+		ResolutionPending:   metaData.CreatorResolutionPending,
+		CreatorGlobalMetaId: common_service.ConvertToGlobalMetaId(creatorAddress),
+		OwnerAddress:        metaData.OwnerAddress,
+		OwnerMetaId:         metaid.Derive(metaData.OwnerAddress),
+		Status:              model.StatusSuccess,
+		State:               0,
+	}, nil
+}
 
-		// Save to database
-		if err := s.indexerFileDAO.Create(indexerFile); err != nil {
-			return fmt.Errorf("failed to save merged file to database: %w", err)
-		}
+// mergedFileExtension the extension of a merged file from its sniffed content
+// type, else from the name in its index
+func mergedFileExtension(contentType, name string) string {
+	if ext := contentTypeToExtension(contentType); ext != "" {
+		return ext
+	}
+	return filepath.Ext(name)
+}
 
-		// Add to file info history
-		fileHistory := &model.FileInfoHistory{
-			FirstPinID:  fileFirstPinID,
-			FirstPath:   firstPath,
-			PinID:       indexPinID,
-			Path:        metaData.Path,
-			Operation:   metaData.Operation,
-			ContentType: metaData.ContentType,
-			ChainName:   metaData.ChainName,
-			BlockHeight: height,
-			Timestamp:   timestamp,
-		}
-		if err := database.DB.AddFileInfoHistory(fileHistory, fileFirstPinID); err != nil {
-			log.Printf("Failed to add file info to history: %v", err)
-		}
+// createMergedIndexFile saves the record of a multi-chunk file and adds it to
+// the file history
+func (s *IndexerService) createMergedIndexFile(metaData *indexer.MetaIDData, indexerFile *model.IndexerFile) error {
+	if err := s.indexerFileDAO.Create(indexerFile); err != nil {
+		return fmt.Errorf("failed to save merged file to database: %w", err)
+	}
+	if indexerFile.Status == model.StatusSuccess {
+		publishIndexedFile(indexerFile)
+	}
+	recordFileChange(model.ChangeChunksMerged, indexerFile)
 
-		log.Printf("Merged file indexed successfully (%s): PIN=%s, FirstPIN=%s, Name=%s, Type=%s, Size=%d",
-			metaData.Operation, indexPinID, fileFirstPinID, metaFileIndex.Name, fileType, metaFileIndex.FileSize)
+	// Add to file info history
+	fileHistory := &model.FileInfoHistory{
+		FirstPinID:  indexerFile.FirstPinID,
+		FirstPath:   indexerFile.FirstPath,
+		PinID:       indexerFile.PinID,
+		Path:        metaData.Path,
+		Operation:   metaData.Operation,
+		ContentType: metaData.ContentType,
+		ChainName:   metaData.ChainName,
+		BlockHeight: indexerFile.BlockHeight,
+		Timestamp:   indexerFile.Timestamp,
+	}
+	if err := database.DB.AddFileInfoHistory(fileHistory, indexerFile.FirstPinID); err != nil {
+		log.Printf("Failed to add file info to history: %v", err)
+	}
 
+	log.Printf("Merged file indexed successfully (%s): PIN=%s, FirstPIN=%s, Name=%s, Type=%s, Size=%d",
+		metaData.Operation, indexerFile.PinID, indexerFile.FirstPinID, indexerFile.FileName, indexerFile.FileType, indexerFile.FileSize)
 	return nil
 }
 
+// maxChunkMismatchAttempts is how many merge attempts of a pending index may be
+// refused by a chunk hash mismatch (each one re-fetching the chunk from the
+// node) before the index is rejected and no longer retried.
+const maxChunkMismatchAttempts = 5
+
+// recordChunkMismatch records a hash-mismatch merge failure on a pending index
+// and rejects the index once maxChunkMismatchAttempts is reached.
+func recordChunkMismatch(p *model.PendingIndexFile, mismatch *ChunkHashMismatchError) {
+	p.MismatchChunkPinID = mismatch.PinID
+	p.MismatchChunkIndex = mismatch.ChunkIndex
+	p.MismatchAttempts++
+	p.LastError = mismatch.Error()
+	if p.MismatchAttempts >= maxChunkMismatchAttempts {
+		p.Status = model.StatusRejected
+		log.Printf("Rejecting index PIN=%s after %d chunk hash mismatches: %s",
+			p.PinID, p.MismatchAttempts, p.LastError)
+	}
+}
+
 // retryPendingIndexMerges attempts to merge any deferred index pins for a chain
 // whose chunks have since arrived. Called from onBlockComplete (once per block
 // during live scanning). Synchronous and bounded: it scans the pending set for
 // the chain (small — records are deleted on success) and re-runs the
 // availability check + mergeAndSaveIndex for each. On success the pending
 // record is deleted; if chunks are still missing it is left for a later block.
+// Rejected records (too many chunk hash mismatches) are skipped.
 func (s *IndexerService) retryPendingIndexMerges(chainName string) {
 	pending, err := s.pendingIndexFileDAO.ListByChain(chainName)
 	if err != nil {
@@ -2072,6 +2442,9 @@ func (s *IndexerService) retryPendingIndexMerges(chainName string) {
 	}
 
 	for _, p := range pending {
+		if p.Status == model.StatusRejected {
+			continue
+		}
 		var metaData indexer.MetaIDData
 		if err := json.Unmarshal([]byte(p.MetaData), &metaData); err != nil {
 			log.Printf("retryPendingIndexMerges: parse metaData for %s: %v", p.PinID, err)
@@ -2083,12 +2456,18 @@ func (s *IndexerService) retryPendingIndexMerges(chainName string) {
 			continue
 		}
 
+		// Resolve creator address from the stored metaData, same as the live path.
+		// A lookup that failed before is tried again.
+		metaData.CreatorResolutionPending = false
+		creatorAddress := s.fileCreatorAddress(&metaData)
+
 		// Re-check chunk availability (same logic as processIndexContent).
+		creatorMetaID := metaid.Derive(creatorAddress)
 		var chunks []*model.IndexerFileChunk
 		allAvailable := true
 		for _, chunkInfo := range metaFileIndex.ChunkList {
-			chunk, err := s.indexerFileChunkDAO.GetByPinID(chunkInfo.PinId)
-			if err != nil || chunk == nil {
+			chunk := s.resolveIndexChunk(chunkInfo.PinId, chunkInfo.Sha256, p.PinID, creatorMetaID)
+			if chunk == nil {
 				allAvailable = false
 				break
 			}
@@ -2097,18 +2476,20 @@ func (s *IndexerService) retryPendingIndexMerges(chainName string) {
 		if !allAvailable || len(chunks) == 0 {
 			continue // still missing; leave for a later block
 		}
-
-		// Resolve creator address from the stored metaData, same as the live path.
-		creatorAddress := metaData.CreatorAddress
-		if metaData.CreatorInputLocation != "" {
-			if real, err := s.parser.FindCreatorAddressFromCreatorInputLocation(metaData.CreatorInputLocation, metaData.CreatorInputTxVinLocation, s.chainType); err == nil {
-				creatorAddress = real
-			}
-		}
+		s.claimIndexChunks(p.PinID, chunks)
 
 		if err := s.mergeAndSaveIndex(&metaData, &metaFileIndex, creatorAddress, chunks, p.FirstPinID, p.FirstPath, p.BlockHeight, p.Timestamp); err != nil {
 			log.Printf("retryPendingIndexMerges: merge %s failed: %v", p.PinID, err)
-			continue // keep the pending record; will retry next block
+			var mismatch *ChunkHashMismatchError
+			if errors.As(err, &mismatch) {
+				recordChunkMismatch(p, mismatch)
+			} else {
+				p.LastError = err.Error()
+			}
+			if err := s.pendingIndexFileDAO.Create(p); err != nil {
+				log.Printf("retryPendingIndexMerges: record failure for %s: %v", p.PinID, err)
+			}
+			continue // keep the pending record; will retry next block unless rejected
 		}
 		if err := s.pendingIndexFileDAO.Delete(p.PinID); err != nil {
 			log.Printf("retryPendingIndexMerges: delete pending %s after merge: %v", p.PinID, err)
@@ -2120,9 +2501,13 @@ func (s *IndexerService) retryPendingIndexMerges(chainName string) {
 func (s *IndexerService) RescanBlocksAsync(chain string, startHeight, endHeight int64) (string, error) {
 	// Check if a task is already running
 	s.rescanMu.Lock()
+	if s.stopping.Load() {
+		s.rescanMu.Unlock()
+		return "", ErrShuttingDown
+	}
 	if s.currentRescanTask != nil && s.currentRescanTask.Status == RescanStatusRunning {
 		s.rescanMu.Unlock()
-		return "", fmt.Errorf("another rescan task is already running: %s", s.currentRescanTask.TaskID)
+		return "", fmt.Errorf("%w: %s", ErrRescanRunning, s.currentRescanTask.TaskID)
 	}
 
 	// Validate parameters
@@ -2175,6 +2560,15 @@ func (s *IndexerService) RescanBlocksAsync(chain string, startHeight, endHeight
 		scanner = s.scanner
 	}
 
+	// Refuse heights a pruned node no longer has (re-checked: pruning advances)
+	if _, err := scanner.DetectPruning(); err != nil {
+		log.Printf("[%s] Failed to detect node pruning: %v", chainName, err)
+	}
+	if err := scanner.CheckHeightAvailable(startHeight); err != nil {
+		s.rescanMu.Unlock()
+		return "", err
+	}
+
 	// Generate task ID
 	taskID := fmt.Sprintf("rescan_%s_%d_%d_%d", chainName, startHeight, endHeight, time.Now().Unix())
 
@@ -2196,16 +2590,24 @@ func (s *IndexerService) RescanBlocksAsync(chain string, startHeight, endHeight
 		CancelFunc:      cancel,
 	}
 
+	task.report = newRescanReport(taskID, chainName, startHeight, endHeight)
 	s.currentRescanTask = task
+	s.keepRescanReport(taskID, task.report)
 	s.rescanMu.Unlock()
 
-	// Create handler for processing transactions during rescan
-	handler := s.handleTransaction
+	// Create handler for processing transactions during rescan, recording
+	// each PIN's outcome in the task's report
+	handler := func(tx interface{}, metaDataTx *indexer.MetaIDDataTx, height, timestamp int64) error {
+		return s.handleTransactionReport(tx, metaDataTx, height, timestamp, task.report)
+	}
 
 	// Start rescan in goroutine
+	s.rescans.Add(1)
 	go func() {
+		defer s.rescans.Done()
 		log.Printf("[Rescan %s] Starting rescan task: %s (height %d to %d)", chainName, taskID, startHeight, endHeight)
 
+		stoppedAt := endHeight + 1 // First height not rescanned
 		defer func() {
 			// Clean up on completion
 			s.rescanMu.Lock()
@@ -2215,12 +2617,27 @@ func (s *IndexerService) RescanBlocksAsync(chain string, startHeight, endHeight
 				}
 			}
 			s.rescanMu.Unlock()
+
+			counts := task.report.counts()
+			task.mu.RLock()
+			message := fmt.Sprintf("%s rescan %s %s: %d of %d blocks (height %d to %d), %d PINs indexed, %d PINs failed, %d blocks failed",
+				chainName, taskID, task.Status, task.ProcessedBlocks, totalBlocks, startHeight, endHeight,
+				counts.IndexedPins, counts.FailedPins, counts.FailedBlocks)
+			if task.ErrorMessage != "" {
+				message += "; first error: " + task.ErrorMessage
+			}
+			if task.interrupted {
+				message += fmt.Sprintf("; interrupted by shutdown, rescan %d to %d to finish", stoppedAt, endHeight)
+			}
+			task.mu.RUnlock()
+			notify.Alertf(notify.EventRescanCompleted, "%s", message)
 		}()
 
 		for height := startHeight; height <= endHeight; height++ {
 			// Check for cancellation
 			select {
 			case <-ctx.Done():
+				stoppedAt = height
 				task.mu.Lock()
 				task.Status = RescanStatusCancelled
 				task.mu.Unlock()
@@ -2229,8 +2646,19 @@ func (s *IndexerService) RescanBlocksAsync(chain string, startHeight, endHeight
 			default:
 			}
 
+			// Wait while disk space is low (indexer.disk_watchdog)
+			if s.diskWatchdog.paused() {
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Duration(conf.Cfg.Indexer.ScanInterval) * time.Second):
+				}
+				height--
+				continue
+			}
+
 			// Scan block
 			_, err := scanner.ScanBlock(height, handler)
+			task.report.block(height, err)
 			if err != nil {
 				log.Printf("[Rescan %s] Failed to scan block %d: %v", chainName, height, err)
 				// Update error but continue
@@ -2242,6 +2670,8 @@ func (s *IndexerService) RescanBlocksAsync(chain string, startHeight, endHeight
 				continue
 			}
 
+			s.flushCounters(chainName, height)
+
 			// Update task progress
 			task.mu.Lock()
 			task.ProcessedBlocks++
@@ -2297,6 +2727,7 @@ func (s *IndexerService) GetRescanStatus() *RescanTask {
 		TotalBlocks:     s.currentRescanTask.TotalBlocks,
 		StartTime:       s.currentRescanTask.StartTime,
 		ErrorMessage:    s.currentRescanTask.ErrorMessage,
+		Counts:          s.currentRescanTask.report.counts(),
 	}
 
 	return taskCopy
@@ -2323,3 +2754,12 @@ func (s *IndexerService) StopRescan() error {
 	log.Printf("[Rescan] Stopping task: %s", s.currentRescanTask.TaskID)
 	return nil
 }
+
+// confirmedAt returns the confirming block time for a PIN handled at height, or
+// 0 for mempool PINs (height 0) whose timestamp is only the first-seen time.
+func confirmedAt(height, timestamp int64) int64 {
+	if height > 0 {
+		return timestamp
+	}
+	return 0
+}
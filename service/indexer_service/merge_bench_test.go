@@ -0,0 +1,75 @@
+package indexer_service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"testing"
+
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// BenchmarkMergeAndSaveIndex merges indexed chunks of 256 KiB into a file,
+// stores it and writes its record, as the indexer does once every chunk of a
+// metafile index has arrived
+func BenchmarkMergeAndSaveIndex(b *testing.B) {
+	const chunkSize = 256 << 10
+	for _, chunkCount := range []int{4, 16} {
+		b.Run(fmt.Sprintf("%dx256KiB", chunkCount), func(b *testing.B) {
+			out := log.Writer()
+			log.SetOutput(io.Discard)
+			b.Cleanup(func() { log.SetOutput(out) })
+			s, stor := newMergeTestService(b)
+
+			content := make([]byte, chunkCount*chunkSize)
+			rand.New(rand.NewSource(1)).Read(content)
+			index := &metaid_protocols.MetaFileIndex{
+				Sha256:      calculateSHA256(content),
+				FileSize:    int64(len(content)),
+				ChunkNumber: chunkCount,
+				ChunkSize:   chunkSize,
+				DataType:    "application/octet-stream",
+				Name:        "bench.bin",
+			}
+			var chunks []*model.IndexerFileChunk
+			for i := 0; i < chunkCount; i++ {
+				data := content[i*chunkSize : (i+1)*chunkSize]
+				chunk := &model.IndexerFileChunk{
+					PinID:       fmt.Sprintf("benchchunk%di0", i),
+					StoragePath: fmt.Sprintf("indexer/chunk/mvc/benchchunk%di0", i),
+					ChunkSize:   chunkSize,
+					ChunkMd5:    calculateMD5(data),
+					ChunkSha256: calculateSHA256(data),
+					ChainName:   "mvc",
+					Status:      model.StatusSuccess,
+				}
+				if err := stor.Save(chunk.StoragePath, data); err != nil {
+					b.Fatal(err)
+				}
+				if err := s.indexerFileChunkDAO.Create(chunk); err != nil {
+					b.Fatal(err)
+				}
+				chunks = append(chunks, chunk)
+				index.ChunkList = append(index.ChunkList, metaid_protocols.MetaFileChunk{Sha256: chunk.ChunkSha256, PinId: chunk.PinID})
+			}
+
+			b.SetBytes(int64(len(content)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				metaData := &indexer.MetaIDData{
+					PinID:     fmt.Sprintf("benchindex%di0", i),
+					Path:      "/file/bench.bin",
+					Operation: "create",
+					ChainName: "mvc",
+				}
+				if err := s.mergeAndSaveIndex(metaData, index, "1BoatSLRHtKNngkdXEeobR76b53LETtpyT", chunks, "", "/file/bench.bin", 100, 1700000000000); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package indexer_service
+
+import (
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// parseMetaFileIndex parses the JSON of a metafile/index PIN, checking its
+// schema (metaid_protocols.ParseMetaFileIndex). chunkSize, fileSize and the
+// other integers written as integral floats (e.g. 1.048576e+06) are accepted.
+func parseMetaFileIndex(content []byte) (*metaid_protocols.MetaFileIndex, error) {
+	return metaid_protocols.ParseMetaFileIndex(content)
+}
+
+// indexFileEncryption returns the encryption recorded for a merged file: the
+// PIN's own encryption field, else the algorithm of a v2 index encryption
+// envelope, so encrypted large files are treated like encrypted PINs.
+func indexFileEncryption(pinEncryption string, metaFileIndex *metaid_protocols.MetaFileIndex) string {
+	if (pinEncryption == "" || pinEncryption == "0") && metaFileIndex.IsEncrypted() {
+		return metaFileIndex.Encryption.Algorithm
+	}
+	return pinEncryption
+}
@@ -0,0 +1,66 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+func TestIndexFileEncryption(t *testing.T) {
+	plain := &metaid_protocols.MetaFileIndex{}
+	encrypted := &metaid_protocols.MetaFileIndex{
+		Version:    metaid_protocols.MetaFileIndexV2,
+		Encryption: &metaid_protocols.MetaFileEncryption{Algorithm: "aes-256-gcm"},
+	}
+	cases := []struct {
+		pin   string
+		index *metaid_protocols.MetaFileIndex
+		want  string
+	}{
+		{"0", plain, "0"},
+		{"", plain, ""},
+		{"0", encrypted, "aes-256-gcm"},
+		{"", encrypted, "aes-256-gcm"},
+		{"ecies", encrypted, "ecies"},
+	}
+	for _, tc := range cases {
+		if got := indexFileEncryption(tc.pin, tc.index); got != tc.want {
+			t.Errorf("indexFileEncryption(%q, encrypted=%v) = %q, want %q", tc.pin, tc.index.IsEncrypted(), got, tc.want)
+		}
+	}
+	// Encrypted merged files stay out of the public feed
+	if IsPublicFile(&model.IndexerFile{Status: model.StatusSuccess, Encryption: indexFileEncryption("0", encrypted)}) {
+		t.Error("encrypted v2 file is feed visible")
+	}
+}
+
+// FuzzProcessIndexContent feeds arbitrary index PIN content to the indexer:
+// it must never panic, and content failing the schema must leave no record.
+func FuzzProcessIndexContent(f *testing.F) {
+	f.Add([]byte(`{"sha256":"aa","fileSize":2,"chunkNumber":1,"chunkSize":2,"chunkList":[{"sha256":"bb","pinId":"fuzzchunk-1i0"}]}`))
+	f.Add([]byte(`{"version":2,"fileSize":4,"chunkNumber":2,"chunkList":[{"pinId":"a","offset":0,"length":2},{"pinId":"b","offset":1,"length":9}],"delta":{"algorithm":"mfs-delta-v1"}}`))
+	f.Add([]byte(`{"chunkList":[{"pinId":"p","length":1e20}]}`))
+	f.Add([]byte(`null`))
+	f.Fuzz(func(t *testing.T, content []byte) {
+		s, _ := newMergeTestService(t)
+		metaData := &indexer.MetaIDData{
+			PinID: "fuzzindex-1i0", Operation: "create", Path: "/file/index", ContentType: "metafile/index",
+			Content: content, ChainName: "mvc", CreatorAddress: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+		}
+		err := s.processIndexContent(metaData, metaData.PinID, metaData.Path, 100, 1700000000)
+
+		if _, schemaErr := metaid_protocols.ParseMetaFileIndex(content); schemaErr != nil {
+			if err == nil {
+				t.Fatalf("index failing the schema processed: %v", schemaErr)
+			}
+			if file, _ := s.indexerFileDAO.GetByPinID(metaData.PinID); file != nil {
+				t.Fatalf("index failing the schema left a file record: %+v", file)
+			}
+			if pending, _ := s.pendingIndexFileDAO.GetByPinID(metaData.PinID); pending != nil {
+				t.Fatalf("index failing the schema left a pending record: %+v", pending)
+			}
+		}
+	})
+}
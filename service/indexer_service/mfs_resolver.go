@@ -0,0 +1,59 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrMfsNotFound is returned when no indexed file matches an mfs:// URI
+var ErrMfsNotFound = errors.New("no indexed file for mfs uri")
+
+// ResolveMfsURI parses raw (mfs://{pinId} or mfs://{sha256}) and returns the
+// file it names. A sha256 URI resolves to the earliest successful PIN with
+// that content, so the answer is stable however often the content was
+// inscribed again. Parse errors wrap metaid_protocols.ErrInvalidMfsURI.
+func (s *IndexerFileService) ResolveMfsURI(raw string) (*metaid_protocols.MfsURI, *model.IndexerFile, error) {
+	uri, err := metaid_protocols.ParseMfsURI(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if uri.Kind == metaid_protocols.MfsKindPin {
+		file, err := s.indexerFileDAO.GetByPinID(uri.PinID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get file: %w", err)
+		}
+		if file == nil {
+			return nil, nil, ErrMfsNotFound
+		}
+		if file.Status != model.StatusSuccess {
+			return nil, nil, fmt.Errorf("%w: file %s: %s", ErrMfsNotFound, file.Status, file.StatusReason)
+		}
+		return uri, file, nil
+	}
+
+	files, err := s.indexerFileDAO.GetByFileHash(uri.Sha256)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get files by hash: %w", err)
+	}
+	file := earliestFile(files)
+	if file == nil {
+		return nil, nil, ErrMfsNotFound
+	}
+	return uri, file, nil
+}
+
+// earliestFile returns the file with the lowest timestamp (PIN ID breaks ties)
+func earliestFile(files []*model.IndexerFile) *model.IndexerFile {
+	var earliest *model.IndexerFile
+	for _, file := range files {
+		if earliest == nil || file.Timestamp < earliest.Timestamp ||
+			(file.Timestamp == earliest.Timestamp && file.PinID < earliest.PinID) {
+			earliest = file
+		}
+	}
+	return earliest
+}
@@ -0,0 +1,22 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestEarliestFile(t *testing.T) {
+	if earliestFile(nil) != nil {
+		t.Error("earliestFile(nil) != nil")
+	}
+	files := []*model.IndexerFile{
+		{PinID: "c1i0", Timestamp: 3},
+		{PinID: "b1i0", Timestamp: 1},
+		{PinID: "a1i0", Timestamp: 1},
+		{PinID: "d1i0", Timestamp: 2},
+	}
+	if got := earliestFile(files); got.PinID != "a1i0" {
+		t.Errorf("earliestFile = %s, want a1i0", got.PinID)
+	}
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // LatestSchemaVersion 当前最新 schema 版本，新增 migrate 时递增
-const LatestSchemaVersion = 1
+const LatestSchemaVersion = 7
 
 // MigrateService 负责 indexer 启动时根据版本号执行 migrate
 type MigrateService struct{}
@@ -57,6 +57,18 @@ func (s *MigrateService) runMigrate(version int) error {
 	switch version {
 	case 1:
 		return s.migrateV1()
+	case 2:
+		return s.migrateV2()
+	case 3:
+		return s.migrateV3()
+	case 4:
+		return s.migrateV4()
+	case 5:
+		return s.migrateV5()
+	case 6:
+		return s.migrateV6()
+	case 7:
+		return s.migrateV7()
 	default:
 		log.Printf("[Migrate] No migration defined for version %d", version)
 		return nil
@@ -86,3 +98,72 @@ func (s *MigrateService) migrateV1() error {
 	log.Printf("[Migrate] V1: completed, total %d files backfilled", count)
 	return nil
 }
+
+// migrateV2 遍历 collectionLatestFileInfo，回填 collectionFileMetaIDTypeChain（按 MetaID + 文件类型 + 链过滤）
+func (s *MigrateService) migrateV2() error {
+	log.Println("[Migrate] V2: Backfilling file_meta_type_chain from latest_file_info...")
+	var count int
+	err := database.DB.IterateLatestFileInfo(func(file *model.IndexerFile) error {
+		if err := database.DB.WriteFileToCreatorFilterIndex(file); err != nil {
+			return err
+		}
+		count++
+		if count%1000 == 0 {
+			log.Printf("[Migrate] V2: processed %d files...", count)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[Migrate] V2: completed, total %d files backfilled", count)
+	return nil
+}
+
+// migrateV3 根据 file_pin 与 meta_id_timestamp 重建 daily_stats（按天按链的新增文件、用户与字节数）
+func (s *MigrateService) migrateV3() error {
+	log.Println("[Migrate] V3: Rebuilding daily_stats from file_pin and meta_id_timestamp...")
+	if err := database.DB.RebuildDailyStats(); err != nil {
+		return err
+	}
+	log.Println("[Migrate] V3: completed")
+	return nil
+}
+
+// migrateV4 原用于重建 file_counts；计数已迁移到 stat_counters，由 V5 统一重建
+func (s *MigrateService) migrateV4() error {
+	log.Println("[Migrate] V4: file_counts superseded by stat_counters (rebuilt in V5), skipping")
+	return nil
+}
+
+// migrateV5 根据文件、分片与 MetaID 索引重建 stat_counters（文件、分片、用户总数及按链、按创建者计数）
+func (s *MigrateService) migrateV5() error {
+	log.Println("[Migrate] V5: Rebuilding stat_counters from file, chunk and MetaID indexes...")
+	drifts, err := database.DB.ReconcileCounters(true)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Migrate] V5: completed, %d counters rebuilt", len(drifts))
+	return nil
+}
+
+// migrateV6 按内容 SHA256 重建 file_hash 索引（此前按 MD5 写入），供 mfs://{sha256} 解析
+func (s *MigrateService) migrateV6() error {
+	log.Println("[Migrate] V6: Rebuilding file_hash index by content sha256 from file_pin...")
+	if err := database.DB.RebuildFileHashIndex(); err != nil {
+		return err
+	}
+	log.Println("[Migrate] V6: completed")
+	return nil
+}
+
+// migrateV7 按新的版本排序（时间戳、区块高度、PIN ID）从历史记录重算 latest_* 集合
+func (s *MigrateService) migrateV7() error {
+	log.Println("[Migrate] V7: Recomputing latest_* collections from history...")
+	drifts, err := database.DB.RepairLatestIndexes(true)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Migrate] V7: completed, %d latest entries repaired", len(drifts))
+	return nil
+}
@@ -0,0 +1,243 @@
+package indexer_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/storage"
+)
+
+// upstreamMirror fetches PINs a mirror deployment (indexer.mirror) has not
+// seen from the upstream indexer's public API. Concurrent requests for the
+// same PIN share one upstream fetch.
+type upstreamMirror struct {
+	baseURL  string
+	client   *http.Client
+	maxBytes int64
+
+	mu       sync.Mutex
+	inflight map[string]*mirrorFetch
+}
+
+type mirrorFetch struct {
+	done chan struct{}
+	file *model.IndexerFile
+	err  error
+}
+
+// mirrorFileInfo the fields of the upstream GET /files/{pinId} response the
+// mirror keeps (see respond.IndexerFileResponse)
+type mirrorFileInfo struct {
+	PinID          string `json:"pin_id"`
+	TxID           string `json:"tx_id"`
+	Path           string `json:"path"`
+	Operation      string `json:"operation"`
+	Encryption     string `json:"encryption"`
+	ContentType    string `json:"content_type"`
+	FileType       string `json:"file_type"`
+	FileExtension  string `json:"file_extension"`
+	FileName       string `json:"file_name"`
+	FileSize       int64  `json:"file_size"`
+	FileMd5        string `json:"file_md5"`
+	FileHash       string `json:"file_hash"`
+	ChainName      string `json:"chain_name"`
+	BlockHeight    int64  `json:"block_height"`
+	Timestamp      int64  `json:"timestamp"`
+	FirstSeenAt    int64  `json:"first_seen_at"`
+	ConfirmedAt    int64  `json:"confirmed_at"`
+	CreatorMetaId  string `json:"creator_meta_id"`
+	CreatorAddress string `json:"creator_address"`
+	OwnerMetaId    string `json:"owner_meta_id"`
+	OwnerAddress   string `json:"owner_address"`
+}
+
+// newUpstreamMirror returns the mirror configured by indexer.mirror, or nil
+// when mirror mode is off
+func newUpstreamMirror(cfg conf.IndexerMirrorConfig) *upstreamMirror {
+	if !cfg.Enabled || cfg.Upstream == "" {
+		return nil
+	}
+	return &upstreamMirror{
+		baseURL:  strings.TrimSuffix(cfg.Upstream, "/") + "/api/v1",
+		client:   &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		maxBytes: int64(cfg.MaxFileMB) * 1024 * 1024,
+		inflight: make(map[string]*mirrorFetch),
+	}
+}
+
+// fetch runs load for pinID unless a fetch of it is already running, in which
+// case it waits for and returns that fetch's result
+func (m *upstreamMirror) fetch(pinID string, load func() (*model.IndexerFile, error)) (*model.IndexerFile, error) {
+	m.mu.Lock()
+	if f, ok := m.inflight[pinID]; ok {
+		m.mu.Unlock()
+		<-f.done
+		return f.file, f.err
+	}
+	f := &mirrorFetch{done: make(chan struct{})}
+	m.inflight[pinID] = f
+	m.mu.Unlock()
+
+	f.file, f.err = load()
+	m.mu.Lock()
+	delete(m.inflight, pinID)
+	m.mu.Unlock()
+	close(f.done)
+	return f.file, f.err
+}
+
+// getFileInfo fetches the upstream metadata of pinID; (nil, nil) when the
+// upstream does not have it
+func (m *upstreamMirror) getFileInfo(pinID string) (*mirrorFileInfo, error) {
+	resp, err := m.client.Get(m.baseURL + "/files/" + url.PathEscape(pinID))
+	if err != nil {
+		return nil, fmt.Errorf("upstream request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    *mirrorFileInfo `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid upstream response: %w", err)
+	}
+	switch {
+	case body.Code == 40400:
+		return nil, nil
+	case body.Code != 0:
+		return nil, fmt.Errorf("upstream error %d: %s", body.Code, body.Message)
+	case body.Data == nil || body.Data.PinID != pinID:
+		return nil, errors.New("upstream returned a different PIN")
+	}
+	return body.Data, nil
+}
+
+// getContent fetches the upstream content of pinID
+func (m *upstreamMirror) getContent(pinID string) ([]byte, error) {
+	resp, err := m.client.Get(m.baseURL + "/files/content/" + url.PathEscape(pinID))
+	if err != nil {
+		return nil, fmt.Errorf("upstream request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream returned HTTP %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, m.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream content: %w", err)
+	}
+	if int64(len(content)) > m.maxBytes {
+		return nil, fmt.Errorf("upstream content exceeds %d bytes", m.maxBytes)
+	}
+	return content, nil
+}
+
+// mirrorFile fetches pinID from the upstream indexer, stores its content and
+// record like an indexed file and returns the record; (nil, nil) when the
+// upstream does not have it either. Content whose sha256 does not match the
+// upstream file_hash is not cached.
+func (s *IndexerFileService) mirrorFile(pinID string) (*model.IndexerFile, error) {
+	if !metaid_protocols.IsPinID(pinID) {
+		return nil, nil
+	}
+	return s.mirror.fetch(pinID, func() (*model.IndexerFile, error) {
+		// Another request may have cached it while this one waited
+		if file, err := s.indexerFileDAO.GetByPinID(pinID); err != nil || file != nil {
+			return file, err
+		}
+
+		info, err := s.mirror.getFileInfo(pinID)
+		if err != nil || info == nil {
+			return nil, err
+		}
+		if info.FileSize > s.mirror.maxBytes {
+			return nil, fmt.Errorf("file size %d exceeds indexer.mirror.max_file_mb", info.FileSize)
+		}
+		content, err := s.mirror.getContent(pinID)
+		if err != nil {
+			return nil, err
+		}
+		fileHash := calculateSHA256(content)
+		if info.FileHash != "" && !strings.EqualFold(fileHash, info.FileHash) {
+			return nil, fmt.Errorf("upstream content sha256 %s does not match file_hash %s", fileHash, info.FileHash)
+		}
+
+		layout := storage.CurrentLayout()
+		storagePath := layout.FilePath(storage.PathVars{
+			ChainName: info.ChainName,
+			PinID:     pinID,
+			Extension: info.FileExtension,
+			MetaID:    info.CreatorMetaId,
+			Timestamp: info.Timestamp,
+		})
+		storageReceipt, err := storage.SaveWithReceipt(s.storage, storagePath, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save file to storage: %w", err)
+		}
+
+		file := &model.IndexerFile{
+			FirstPinID:     pinID,
+			FirstPath:      info.Path,
+			PinID:          pinID,
+			TxID:           info.TxID,
+			Vout:           pinVout(pinID),
+			Path:           info.Path,
+			Operation:      info.Operation,
+			Encryption:     info.Encryption,
+			ContentType:    contenttype.Normalize(info.ContentType),
+			ChunkType:      model.ChunkTypeSingle,
+			FileType:       info.FileType,
+			FileExtension:  info.FileExtension,
+			FileName:       info.FileName,
+			FileSize:       int64(len(content)),
+			FileMd5:        calculateMD5(content),
+			FileHash:       fileHash,
+			StorageType:    storage.RecordType(conf.Cfg.Storage.Type),
+			StoragePath:    storagePath,
+			StorageLayout:  layout.Version(),
+			StorageReceipt: storageReceipt,
+			ChainName:      info.ChainName,
+			BlockHeight:    info.BlockHeight,
+			Timestamp:      info.Timestamp,
+			FirstSeenAt:    info.FirstSeenAt,
+			ConfirmedAt:    info.ConfirmedAt,
+			CreatorMetaId:  info.CreatorMetaId,
+			CreatorAddress: info.CreatorAddress,
+			OwnerMetaId:    info.OwnerMetaId,
+			OwnerAddress:   info.OwnerAddress,
+			Status:         model.StatusSuccess,
+		}
+		if err := s.indexerFileDAO.Create(file); err != nil {
+			return nil, fmt.Errorf("failed to save mirrored file: %w", err)
+		}
+		log.Printf("[Mirror] Cached %s from upstream (%d bytes)", pinID, len(content))
+		return file, nil
+	})
+}
+
+// pinVout returns the output index of a {txid}i{vout} PIN ID
+func pinVout(pinID string) uint32 {
+	var vout uint32
+	if i := strings.LastIndex(pinID, "i"); i >= 0 {
+		fmt.Sscanf(pinID[i+1:], "%d", &vout)
+	}
+	return vout
+}
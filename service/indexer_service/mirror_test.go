@@ -0,0 +1,49 @@
+package indexer_service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+)
+
+func TestUpstreamMirrorFetch(t *testing.T) {
+	pinID := strings.Repeat("ab", 32) + "i2"
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/files/" + pinID:
+			w.Write([]byte(`{"code":0,"message":"success","data":{"pin_id":"` + pinID + `","chain_name":"mvc","file_size":5}}`))
+		case "/api/v1/files/content/" + pinID:
+			w.Write([]byte("hello"))
+		case "/api/v1/files/content/big":
+			w.Write(make([]byte, 2<<20))
+		default:
+			w.Write([]byte(`{"code":40400,"message":"file not found","data":null}`))
+		}
+	}))
+	defer upstream.Close()
+
+	if newUpstreamMirror(conf.IndexerMirrorConfig{Upstream: upstream.URL}) != nil {
+		t.Fatal("mirror created while disabled")
+	}
+	m := newUpstreamMirror(conf.IndexerMirrorConfig{Enabled: true, Upstream: upstream.URL + "/", TimeoutSeconds: 5, MaxFileMB: 1})
+
+	info, err := m.getFileInfo(pinID)
+	if err != nil || info == nil || info.ChainName != "mvc" || info.FileSize != 5 {
+		t.Fatalf("getFileInfo = %+v, %v", info, err)
+	}
+	if info, err := m.getFileInfo(strings.Repeat("cd", 32) + "i0"); err != nil || info != nil {
+		t.Errorf("unknown PIN: getFileInfo = %+v, %v; want nil, nil", info, err)
+	}
+	if content, err := m.getContent(pinID); err != nil || string(content) != "hello" {
+		t.Errorf("getContent = %q, %v", content, err)
+	}
+	if _, err := m.getContent("big"); err == nil {
+		t.Error("content over max_file_mb was accepted")
+	}
+	if vout := pinVout(pinID); vout != 2 {
+		t.Errorf("pinVout = %d, want 2", vout)
+	}
+}
@@ -0,0 +1,298 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// PIN parser modes (indexer.parser_mode, per chain indexer.chains[].parser_mode)
+const (
+	ParserModeStrict  = "strict"  // PINs with problems are not indexed
+	ParserModeLenient = "lenient" // PINs with problems are indexed as far as possible, with a warning
+)
+
+// FeatureMetaFileIndexV2 experimental protocol toggle (indexer.parser_features):
+// v2 metafile/index PINs declaring compression, encryption or chunk ranges
+const FeatureMetaFileIndexV2 = "metafile_index_v2"
+
+// parserFeatureDefaults experimental protocols and whether they are on when
+// not configured
+var parserFeatureDefaults = map[string]bool{
+	FeatureMetaFileIndexV2: true,
+}
+
+// Kinds of PIN problems, the keys of ParserCounts.Reasons
+const (
+	problemUnknownOperation   = "unknown_operation"
+	problemNoReference        = "no_pin_reference"
+	problemMissingContentType = "missing_content_type"
+	problemEmptyContent       = "empty_content"
+	problemContentTypeMatch   = "content_type_mismatch"
+	problemInvalidIndex       = "invalid_index"
+	problemMalformedIndex     = "malformed_index"
+	problemFeatureDisabled    = "feature_disabled"
+)
+
+// fatalProblems reject the PIN in every mode: it cannot be indexed best-effort
+var fatalProblems = map[string]bool{
+	problemMalformedIndex:  true,
+	problemFeatureDisabled: true,
+}
+
+// pinProblem something malformed about a PIN
+type pinProblem struct {
+	kind   string
+	detail string
+}
+
+// parserPolicy how the indexer treats the PINs of one chain
+type parserPolicy struct {
+	mode     string
+	features map[string]bool
+}
+
+// parserPolicyFor resolves the policy of chainName: indexer.parser_mode and
+// indexer.parser_features, overridden by the chain's indexer.chains entry.
+// Unknown modes and features are ignored.
+func parserPolicyFor(chainName string) parserPolicy {
+	policy := parserPolicy{mode: ParserModeLenient, features: make(map[string]bool, len(parserFeatureDefaults))}
+	for feature, on := range parserFeatureDefaults {
+		policy.features[feature] = on
+	}
+	if conf.Cfg == nil {
+		return policy
+	}
+
+	apply := func(mode string, features map[string]bool) {
+		if mode == ParserModeStrict || mode == ParserModeLenient {
+			policy.mode = mode
+		}
+		for feature, on := range features {
+			if _, ok := parserFeatureDefaults[feature]; ok {
+				policy.features[feature] = on
+			}
+		}
+	}
+	apply(conf.Cfg.Indexer.ParserMode, conf.Cfg.Indexer.ParserFeatures)
+	for _, chain := range conf.Cfg.Indexer.Chains {
+		if chain.Name == chainName {
+			apply(chain.ParserMode, chain.ParserFeatures)
+		}
+	}
+	return policy
+}
+
+// logParserPolicy logs the policy of chainName at startup and warns about
+// feature names that are not known experimental protocols
+func logParserPolicy(chainName string) {
+	policy := parserPolicyFor(chainName)
+	log.Printf("[%s] PIN parser mode: %s, features: %v", chainName, policy.mode, policy.features)
+
+	configured := []map[string]bool{conf.Cfg.Indexer.ParserFeatures}
+	for _, chain := range conf.Cfg.Indexer.Chains {
+		if chain.Name == chainName {
+			configured = append(configured, chain.ParserFeatures)
+		}
+	}
+	for _, features := range configured {
+		for feature := range features {
+			if _, ok := parserFeatureDefaults[feature]; !ok {
+				log.Printf("[%s] Warning: unknown parser feature %q ignored (known: %s)", chainName, feature, strings.Join(parserFeatureNames(), ", "))
+			}
+		}
+	}
+}
+
+// pinProblems checks a PIN about to be indexed under firstPath
+func (p parserPolicy) pinProblems(metaData *indexer.MetaIDData, firstPath string) []pinProblem {
+	var problems []pinProblem
+	add := func(kind, format string, args ...interface{}) {
+		problems = append(problems, pinProblem{kind: kind, detail: fmt.Sprintf(format, args...)})
+	}
+
+	switch metaData.Operation {
+	case "create":
+		if len(metaData.Content) == 0 {
+			add(problemEmptyContent, "create without content")
+		}
+	case "modify", "revoke":
+		if parsed, err := metaid_protocols.ParseMetaIDPath(metaData.Path); err != nil || !parsed.IsReference() {
+			add(problemNoReference, "%s of %q without @pinId reference", metaData.Operation, metaData.Path)
+		}
+	default:
+		add(problemUnknownOperation, "unknown operation %q", metaData.Operation)
+	}
+	if metaData.Operation != "revoke" && strings.TrimSpace(metaData.ContentType) == "" {
+		add(problemMissingContentType, "no content type")
+	}
+
+	switch {
+	case isChunkPath(metaData.Path) && !isChunkContentType(metaData.ContentType):
+		add(problemContentTypeMatch, "chunk path with content type %q", metaData.ContentType)
+	case isIndexPath(firstPath) && !isIndexContentType(metaData.ContentType):
+		add(problemContentTypeMatch, "index path with content type %q", metaData.ContentType)
+	case isIndexPath(firstPath):
+		metaFileIndex, err := parseMetaFileIndex(metaData.Content)
+		if err != nil {
+			add(problemMalformedIndex, "%v", err)
+			break
+		}
+		if metaFileIndex.IndexVersion() >= 2 && !p.features[FeatureMetaFileIndexV2] {
+			add(problemFeatureDisabled, "v%d index while %s is disabled", metaFileIndex.IndexVersion(), FeatureMetaFileIndexV2)
+		} else if err := metaFileIndex.Validate(); err != nil {
+			add(problemInvalidIndex, "invalid v%d index: %v", metaFileIndex.IndexVersion(), err)
+		}
+	}
+	return problems
+}
+
+// admit applies the policy to a PIN about to be indexed under firstPath and,
+// when count is set, counts the outcome. PINs with a malformed index or
+// using a disabled feature are always rejected; other problems reject the
+// PIN in strict mode and are logged in lenient mode. A rejected PIN comes
+// with the reason to quarantine it under.
+func (p parserPolicy) admit(metaData *indexer.MetaIDData, firstPath string, count bool) (bool, string) {
+	problems := p.pinProblems(metaData, firstPath)
+	reject := false
+	details := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		reject = reject || p.mode == ParserModeStrict || fatalProblems[problem.kind]
+		details = append(details, problem.detail)
+	}
+	if count {
+		parserCounters.count(metaData.ChainName, p.mode, problems, reject)
+	}
+
+	switch {
+	case reject:
+		log.Printf("[Parser] Rejected PIN %s (%s mode): %s", metaData.PinID, p.mode, strings.Join(details, "; "))
+	case len(problems) > 0:
+		log.Printf("[Parser] Warning: PIN %s indexed best-effort: %s", metaData.PinID, strings.Join(details, "; "))
+	}
+	if reject {
+		return false, strings.Join(details, "; ")
+	}
+	return true, ""
+}
+
+// quarantinePIN records a rejected chunk, index or file PIN as rejected with
+// the policy's reason, so the PIN is not retried and GetFileStatus reports
+// why. Other PINs are only skipped. A PIN already recorded is left alone.
+func (s *IndexerService) quarantinePIN(metaData *indexer.MetaIDData, firstPinID, firstPath string, height, timestamp int64, reason string) {
+	reason = "quarantined: " + reason
+	var err error
+	switch {
+	case isChunkPath(metaData.Path) && isChunkContentType(metaData.ContentType):
+		if existing, _ := s.indexerFileChunkDAO.GetByPinID(metaData.PinID); existing != nil {
+			return
+		}
+		err = s.saveRejectedChunk(metaData, height, reason)
+	case isIndexPath(firstPath) && isIndexContentType(metaData.ContentType), isFilePath(firstPath):
+		if existing, _ := s.indexerFileDAO.GetByPinID(metaData.PinID); existing != nil {
+			return
+		}
+		chunkType := model.ChunkTypeSingle
+		if isIndexPath(firstPath) && isIndexContentType(metaData.ContentType) {
+			chunkType = model.ChunkTypeMulti
+		}
+		err = s.saveRejectedFile(metaData, chunkType, firstPinID, firstPath, metaData.CreatorAddress, height, timestamp, reason)
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("[Parser] Failed to quarantine PIN %s: %v", metaData.PinID, err)
+	}
+}
+
+// ParserCounts PINs of one chain handled in one parser mode since startup
+type ParserCounts struct {
+	Accepted int64            // Indexed without problems
+	Warned   int64            // Indexed despite problems (lenient mode)
+	Rejected int64            // Not indexed
+	Reasons  map[string]int64 // Problems found, by kind
+}
+
+// ParserStats the parser policy of a chain and its PIN counts per mode
+type ParserStats struct {
+	Mode     string
+	Features map[string]bool
+	Counts   map[string]ParserCounts // By mode
+}
+
+// parserCounterSet in-memory PIN counts by chain and mode
+type parserCounterSet struct {
+	mu     sync.Mutex
+	chains map[string]map[string]*ParserCounts
+}
+
+var parserCounters = &parserCounterSet{}
+
+func (c *parserCounterSet) count(chainName, mode string, problems []pinProblem, rejected bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.chains == nil {
+		c.chains = make(map[string]map[string]*ParserCounts)
+	}
+	modes := c.chains[chainName]
+	if modes == nil {
+		modes = make(map[string]*ParserCounts)
+		c.chains[chainName] = modes
+	}
+	counts := modes[mode]
+	if counts == nil {
+		counts = &ParserCounts{Reasons: make(map[string]int64)}
+		modes[mode] = counts
+	}
+
+	switch {
+	case rejected:
+		counts.Rejected++
+	case len(problems) > 0:
+		counts.Warned++
+	default:
+		counts.Accepted++
+	}
+	for _, problem := range problems {
+		counts.Reasons[problem.kind]++
+	}
+}
+
+// snapshot copies the counts of chainName
+func (c *parserCounterSet) snapshot(chainName string) map[string]ParserCounts {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]ParserCounts, len(c.chains[chainName]))
+	for mode, counts := range c.chains[chainName] {
+		reasons := make(map[string]int64, len(counts.Reasons))
+		for kind, n := range counts.Reasons {
+			reasons[kind] = n
+		}
+		out[mode] = ParserCounts{Accepted: counts.Accepted, Warned: counts.Warned, Rejected: counts.Rejected, Reasons: reasons}
+	}
+	return out
+}
+
+// GetParserStats returns the parser policy of chainName and the PINs it
+// accepted, warned about and rejected in each mode since startup
+func GetParserStats(chainName string) ParserStats {
+	policy := parserPolicyFor(chainName)
+	return ParserStats{Mode: policy.mode, Features: policy.features, Counts: parserCounters.snapshot(chainName)}
+}
+
+// parserFeatureNames returns the known experimental protocol features, sorted
+func parserFeatureNames() []string {
+	names := make([]string, 0, len(parserFeatureDefaults))
+	for name := range parserFeatureDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
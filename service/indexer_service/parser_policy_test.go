@@ -0,0 +1,151 @@
+package indexer_service
+
+import (
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+)
+
+func setParserConfig(t *testing.T, cfg conf.IndexerConfig) {
+	t.Helper()
+	prev, prevCounters := conf.Cfg, parserCounters
+	conf.Cfg = &conf.Config{Indexer: cfg}
+	parserCounters = &parserCounterSet{}
+	t.Cleanup(func() { conf.Cfg, parserCounters = prev, prevCounters })
+}
+
+func TestParserPolicyFor(t *testing.T) {
+	setParserConfig(t, conf.IndexerConfig{
+		ParserMode:     ParserModeLenient,
+		ParserFeatures: map[string]bool{"unknown_feature": true},
+		Chains: []conf.ChainInstanceConfig{
+			{Name: "mvc", ParserMode: ParserModeStrict, ParserFeatures: map[string]bool{FeatureMetaFileIndexV2: false}},
+			{Name: "doge", ParserMode: "paranoid"},
+		},
+	})
+
+	tests := []struct {
+		chain string
+		mode  string
+		v2    bool
+	}{
+		{"mvc", ParserModeStrict, false},
+		{"doge", ParserModeLenient, true},
+		{"btc", ParserModeLenient, true},
+	}
+	for _, tt := range tests {
+		policy := parserPolicyFor(tt.chain)
+		if policy.mode != tt.mode || policy.features[FeatureMetaFileIndexV2] != tt.v2 {
+			t.Errorf("%s: policy = %+v, want mode %s and %s=%v", tt.chain, policy, tt.mode, FeatureMetaFileIndexV2, tt.v2)
+		}
+		if _, ok := policy.features["unknown_feature"]; ok {
+			t.Errorf("%s: unknown feature kept in policy", tt.chain)
+		}
+	}
+}
+
+func TestParserPolicyAdmit(t *testing.T) {
+	setParserConfig(t, conf.IndexerConfig{})
+
+	v2Index := `{"version":2,"sha256":"aa","fileSize":10,"chunkNumber":1,"chunkSize":10,"chunkList":[{"sha256":"bb","pinId":"p","offset":0,"length":10}]}`
+	badV2Index := `{"version":2,"sha256":"aa","fileSize":10,"chunkNumber":2,"chunkSize":10,"chunkList":[{"sha256":"bb","pinId":"p","offset":0,"length":10}]}`
+	tests := []struct {
+		name      string
+		pin       indexer.MetaIDData
+		firstPath string
+		problem   string // "" = no problem
+	}{
+		{"valid file", indexer.MetaIDData{Operation: "create", Path: "/file/a.png", ContentType: "image/png", Content: []byte("png")}, "/file/a.png", ""},
+		{"valid modify", indexer.MetaIDData{Operation: "modify", Path: "@" + strings.Repeat("ab", 32) + "i0", ContentType: "text/plain", Content: []byte("x")}, "/info/name", ""},
+		{"valid v2 index", indexer.MetaIDData{Operation: "create", Path: "/file/index", ContentType: "metafile/index", Content: []byte(v2Index)}, "/file/index", ""},
+		{"unknown operation", indexer.MetaIDData{Operation: "burn", Path: "/file/a.png", ContentType: "image/png", Content: []byte("png")}, "/file/a.png", problemUnknownOperation},
+		{"modify without reference", indexer.MetaIDData{Operation: "modify", Path: "/info/name", ContentType: "text/plain", Content: []byte("x")}, "/info/name", problemNoReference},
+		{"empty create", indexer.MetaIDData{Operation: "create", Path: "/file/a.png", ContentType: "image/png"}, "/file/a.png", problemEmptyContent},
+		{"no content type", indexer.MetaIDData{Operation: "create", Path: "/file/a.png", Content: []byte("png")}, "/file/a.png", problemMissingContentType},
+		{"chunk content type", indexer.MetaIDData{Operation: "create", Path: "/file/_chunk", ContentType: "image/png", Content: []byte("png")}, "/file/_chunk", problemContentTypeMatch},
+		{"invalid v2 index", indexer.MetaIDData{Operation: "create", Path: "/file/index", ContentType: "metafile/index", Content: []byte(badV2Index)}, "/file/index", problemInvalidIndex},
+		{"index not JSON", indexer.MetaIDData{Operation: "create", Path: "/file/index", ContentType: "metafile/index", Content: []byte("{")}, "/file/index", problemMalformedIndex},
+	}
+
+	for _, mode := range []string{ParserModeStrict, ParserModeLenient} {
+		policy := parserPolicy{mode: mode, features: map[string]bool{FeatureMetaFileIndexV2: true}}
+		for _, tt := range tests {
+			pin := tt.pin
+			pin.ChainName = "mvc"
+			want := tt.problem == "" || (mode == ParserModeLenient && !fatalProblems[tt.problem])
+			if got, _ := policy.admit(&pin, tt.firstPath, true); got != want {
+				t.Errorf("%s mode, %s: admit = %v, want %v (problems %v)", mode, tt.name, got, want, policy.pinProblems(&pin, tt.firstPath))
+			}
+		}
+	}
+
+	stats := GetParserStats("mvc")
+	if stats.Mode != ParserModeLenient {
+		t.Errorf("mode = %s, want lenient by default", stats.Mode)
+	}
+	strict, lenient := stats.Counts[ParserModeStrict], stats.Counts[ParserModeLenient]
+	if strict.Accepted != 3 || strict.Rejected != 7 || strict.Warned != 0 {
+		t.Errorf("strict counts = %+v, want 3 accepted, 7 rejected", strict)
+	}
+	if lenient.Accepted != 3 || lenient.Warned != 6 || lenient.Rejected != 1 {
+		t.Errorf("lenient counts = %+v, want 3 accepted, 6 warned, 1 rejected", lenient)
+	}
+	if strict.Reasons[problemInvalidIndex] != 1 || lenient.Reasons[problemMalformedIndex] != 1 {
+		t.Errorf("invalid_index = %d, malformed_index = %d, want 1 and 1", strict.Reasons[problemInvalidIndex], lenient.Reasons[problemMalformedIndex])
+	}
+}
+
+func TestParserPolicyDisabledFeature(t *testing.T) {
+	setParserConfig(t, conf.IndexerConfig{})
+
+	policy := parserPolicy{mode: ParserModeLenient, features: map[string]bool{FeatureMetaFileIndexV2: false}}
+	v1 := indexer.MetaIDData{Operation: "create", Path: "/file/index", ContentType: "metafile/index", ChainName: "doge",
+		Content: []byte(`{"sha256":"aa","fileSize":10,"chunkNumber":1,"chunkSize":10,"chunkList":[{"sha256":"bb","pinId":"p"}]}`)}
+	v2 := v1
+	v2.Content = []byte(`{"version":2,"sha256":"aa","fileSize":10,"chunkNumber":1,"chunkSize":10,"chunkList":[{"sha256":"bb","pinId":"p","offset":0,"length":10}]}`)
+
+	if ok, _ := policy.admit(&v1, "/file/index", true); !ok {
+		t.Error("v1 index rejected while only metafile_index_v2 is disabled")
+	}
+	if ok, reason := policy.admit(&v2, "/file/index", true); ok || reason == "" {
+		t.Error("v2 index admitted in lenient mode while metafile_index_v2 is disabled")
+	}
+	if ok, _ := policy.admit(&v2, "/file/index", false); ok {
+		t.Error("v2 index admitted from the mempool")
+	}
+	counts := GetParserStats("doge").Counts[ParserModeLenient]
+	if counts.Accepted != 1 || counts.Rejected != 1 || counts.Reasons[problemFeatureDisabled] != 1 {
+		t.Errorf("counts = %+v, want 1 accepted, 1 rejected for feature_disabled (mempool not counted)", counts)
+	}
+}
+
+func TestQuarantinePIN(t *testing.T) {
+	s, _ := newMergeTestService(t)
+	policy := parserPolicy{mode: ParserModeLenient, features: map[string]bool{FeatureMetaFileIndexV2: true}}
+	index := indexer.MetaIDData{PinID: "badindex-1i0", Operation: "create", Path: "/file/index", ContentType: "metafile/index",
+		ChainName: "mvc", Content: []byte(`{"fileSize":-1,"chunkList":[{"pinId":"p"}]}`)}
+
+	ok, reason := policy.admit(&index, index.Path, false)
+	if ok {
+		t.Fatal("index failing the schema admitted in lenient mode")
+	}
+	s.quarantinePIN(&index, index.PinID, index.Path, 100, 1700000000, reason)
+	file, _ := s.indexerFileDAO.GetByPinID(index.PinID)
+	if file == nil || file.Status != model.StatusRejected || file.ChunkType != model.ChunkTypeMulti || !strings.HasPrefix(file.StatusReason, "quarantined: ") {
+		t.Fatalf("quarantined index = %+v", file)
+	}
+	// A second sighting keeps the first record
+	s.quarantinePIN(&index, index.PinID, index.Path, 101, 1700000001, "other")
+	if again, _ := s.indexerFileDAO.GetByPinID(index.PinID); again == nil || again.BlockHeight != 100 {
+		t.Errorf("quarantine record replaced: %+v", again)
+	}
+
+	chunk := indexer.MetaIDData{PinID: "badchunk-1i0", Operation: "create", Path: "/file/_chunk", ContentType: "image/png", ChainName: "mvc", Content: []byte("x")}
+	s.quarantinePIN(&chunk, chunk.PinID, chunk.Path, 100, 1700000000, "content type")
+	if got, _ := s.indexerFileChunkDAO.GetByPinID(chunk.PinID); got != nil {
+		t.Errorf("chunk with a non-chunk content type recorded as a chunk: %+v", got)
+	}
+}
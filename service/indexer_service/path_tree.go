@@ -0,0 +1,207 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"meta-file-system/model"
+	common_service "meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrInvalidTreePath is returned for a directory that is not a MetaID path
+var ErrInvalidTreePath = errors.New("invalid path")
+
+// MaxPathTreeFiles files of a user read to build one path tree view; larger
+// subtrees are cut off and reported as truncated
+const MaxPathTreeFiles = 10000
+
+// PathTreeDirectory a subdirectory of a path tree view, with the files below
+// it at any depth
+type PathTreeDirectory struct {
+	Name      string
+	Path      string
+	FileCount int64
+	TotalSize int64
+}
+
+// PathTree a user's files under one MetaID path, presented as a directory:
+// its subdirectories and the files directly in it. FileCount and TotalSize
+// cover the whole subtree.
+type PathTree struct {
+	Path        string
+	FileCount   int64
+	TotalSize   int64
+	Directories []PathTreeDirectory
+	Files       []*model.IndexerFile
+	Truncated   bool // More than MaxPathTreeFiles files below Path; counts are partial
+}
+
+// GetPathTree lists the directory dir ("/" for the root) of the files of a
+// creator MetaID or GlobalMetaID. The tree is derived from the files' MetaID
+// paths: a path ending in the file name places the file in its parent
+// directory, any other path is the file's directory. Only the latest version
+// of each file counts, and revoked files are left out.
+func (s *IndexerFileService) GetPathTree(metaidOrGlobalMetaId, dir string) (*PathTree, error) {
+	base, err := parseTreePath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &PathTree{Path: "/"}
+	if base != nil {
+		tree.Path = base.Path
+	}
+	files, hasMore, err := s.creatorFilesUnder(metaidOrGlobalMetaId, base)
+	if err != nil {
+		return nil, err
+	}
+	tree.Truncated = hasMore
+
+	buildPathTree(tree, base, files)
+	return tree, nil
+}
+
+// parseTreePath parses a directory of a path tree; nil for the root
+func parseTreePath(dir string) (*metaid_protocols.MetaIDPath, error) {
+	if strings.Trim(dir, "/ ") == "" {
+		return nil, nil
+	}
+	parsed, err := metaid_protocols.ParseMetaIDPath(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTreePath, err)
+	}
+	if parsed.IsReference() {
+		return nil, fmt.Errorf("%w: @pinId references are not paths", ErrInvalidTreePath)
+	}
+	return parsed, nil
+}
+
+// creatorFilesUnder lists up to MaxPathTreeFiles files of a creator under base
+// (nil for all), newest first; hasMore reports that there are more
+func (s *IndexerFileService) creatorFilesUnder(metaidOrGlobalMetaId string, base *metaid_protocols.MetaIDPath) ([]*model.IndexerFile, bool, error) {
+	filter := model.IndexerFileFilter{}
+	if base != nil {
+		filter.PathPrefix = base.Path
+	}
+	var files []*model.IndexerFile
+	var hasMore bool
+	var err error
+	if common_service.IsGlobalMetaId(metaidOrGlobalMetaId) {
+		files, _, hasMore, err = s.indexerFileDAO.GetByCreatorGlobalMetaIDWithCursor(metaidOrGlobalMetaId, filter, 0, MaxPathTreeFiles)
+	} else {
+		files, _, hasMore, err = s.indexerFileDAO.GetByCreatorMetaIDWithCursor(metaidOrGlobalMetaId, filter, 0, MaxPathTreeFiles)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get files by creator: %w", err)
+	}
+	return files, hasMore, nil
+}
+
+// latestFiles keeps the latest version of each file from files (newest
+// first) and drops revoked files
+func latestFiles(files []*model.IndexerFile) []*model.IndexerFile {
+	seen := make(map[string]struct{}) // FirstPinIDs already taken
+	latest := make([]*model.IndexerFile, 0, len(files))
+	for _, file := range files {
+		// Listings are newest first: the first version seen is the latest
+		firstPinID := file.FirstPinID
+		if firstPinID == "" {
+			firstPinID = file.PinID
+		}
+		if _, ok := seen[firstPinID]; ok {
+			continue
+		}
+		seen[firstPinID] = struct{}{}
+		if file.Operation != "revoke" {
+			latest = append(latest, file)
+		}
+	}
+	return latest
+}
+
+// buildPathTree fills tree from files (newest first) listed under base (nil
+// for the root)
+func buildPathTree(tree *PathTree, base *metaid_protocols.MetaIDPath, files []*model.IndexerFile) {
+	var baseSegments []string
+	if base != nil {
+		baseSegments = base.Segments
+	}
+
+	dirs := make(map[string]*PathTreeDirectory)
+	for _, file := range latestFiles(files) {
+		dirSegments, dirNames, ok := fileDirectory(file)
+		if !ok || !hasSegmentPrefix(dirSegments, baseSegments) {
+			continue
+		}
+		tree.FileCount++
+		tree.TotalSize += file.FileSize
+
+		if len(dirSegments) == len(baseSegments) {
+			tree.Files = append(tree.Files, file)
+			continue
+		}
+		key := dirSegments[len(baseSegments)]
+		sub := dirs[key]
+		if sub == nil {
+			name := dirNames[len(baseSegments)]
+			sub = &PathTreeDirectory{Name: name, Path: strings.TrimSuffix(tree.Path, "/") + "/" + name}
+			dirs[key] = sub
+		}
+		sub.FileCount++
+		sub.TotalSize += file.FileSize
+	}
+
+	for _, sub := range dirs {
+		tree.Directories = append(tree.Directories, *sub)
+	}
+	sort.Slice(tree.Directories, func(i, j int) bool {
+		return strings.ToLower(tree.Directories[i].Name) < strings.ToLower(tree.Directories[j].Name)
+	})
+	sort.SliceStable(tree.Files, func(i, j int) bool {
+		return strings.ToLower(pathTreeFileName(tree.Files[i])) < strings.ToLower(pathTreeFileName(tree.Files[j]))
+	})
+}
+
+// fileDirectory returns the lower-cased segments and the segments as
+// inscribed of the directory holding file; ok is false for paths that are
+// not valid MetaID paths
+func fileDirectory(file *model.IndexerFile) ([]string, []string, bool) {
+	parsed, err := metaid_protocols.ParseMetaIDPath(file.Path)
+	if err != nil || parsed.IsReference() {
+		return nil, nil, false
+	}
+	segments := parsed.Segments
+	names := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) == 0 {
+		names = nil
+	}
+	if n := len(segments); n > 0 && file.FileName != "" && segments[n-1] == strings.ToLower(file.FileName) {
+		segments, names = segments[:n-1], names[:n-1]
+	}
+	return segments, names, true
+}
+
+// hasSegmentPrefix reports whether segments starts with prefix
+func hasSegmentPrefix(segments, prefix []string) bool {
+	if len(segments) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if segments[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// pathTreeFileName name a file is listed under: its file name, or its PIN ID
+// when the path carries none
+func pathTreeFileName(file *model.IndexerFile) string {
+	if file.FileName != "" {
+		return file.FileName
+	}
+	return file.PinID
+}
@@ -0,0 +1,88 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+func treeFile(pinID, firstPinID, path, fileName string, size int64) *model.IndexerFile {
+	return &model.IndexerFile{PinID: pinID, FirstPinID: firstPinID, Path: path, FileName: fileName, FileSize: size, Operation: "create"}
+}
+
+func TestBuildPathTreeRoot(t *testing.T) {
+	files := []*model.IndexerFile{
+		treeFile("p3", "p3", "/file/photos/2024/cat.png", "cat.png", 30),
+		treeFile("p2", "p2", "/file/Photos/dog.png", "dog.png", 20),
+		treeFile("p1", "p1", "/info/name", "", 5),
+	}
+	tree := &PathTree{Path: "/"}
+	buildPathTree(tree, nil, files)
+
+	if tree.FileCount != 3 || tree.TotalSize != 55 {
+		t.Fatalf("root totals = %d files / %d bytes, want 3 / 55", tree.FileCount, tree.TotalSize)
+	}
+	if len(tree.Files) != 0 {
+		t.Errorf("root files = %d, want 0", len(tree.Files))
+	}
+	if len(tree.Directories) != 2 {
+		t.Fatalf("root directories = %+v, want file and info", tree.Directories)
+	}
+	if got := tree.Directories[0]; got.Name != "file" || got.Path != "/file" || got.FileCount != 2 || got.TotalSize != 50 {
+		t.Errorf("directories[0] = %+v", got)
+	}
+	if got := tree.Directories[1]; got.Name != "info" || got.FileCount != 1 {
+		t.Errorf("directories[1] = %+v", got)
+	}
+}
+
+func TestBuildPathTreeSubdirectory(t *testing.T) {
+	base, err := metaid_protocols.ParseMetaIDPath("/file/photos")
+	if err != nil {
+		t.Fatalf("parse base: %v", err)
+	}
+	files := []*model.IndexerFile{
+		// Newest first: p4 modifies p2, so p2 is not counted again
+		{PinID: "p4", FirstPinID: "p2", Path: "/file/photos/dog.png", FileName: "dog.png", FileSize: 25, Operation: "modify"},
+		treeFile("p3", "p3", "/file/photos/2024/cat.png", "cat.png", 30),
+		treeFile("p2", "p2", "/file/Photos/dog.png", "dog.png", 20),
+		{PinID: "p5", FirstPinID: "p1", Path: "/file/photos/ant.png", FileName: "ant.png", Operation: "revoke"},
+		treeFile("p1", "p1", "/file/photos/ant.png", "ant.png", 10),
+		treeFile("p0", "p0", "/file/photosx/bee.png", "bee.png", 7),
+	}
+	tree := &PathTree{Path: base.Path}
+	buildPathTree(tree, base, files)
+
+	if tree.FileCount != 2 || tree.TotalSize != 55 {
+		t.Fatalf("totals = %d files / %d bytes, want 2 / 55", tree.FileCount, tree.TotalSize)
+	}
+	if len(tree.Files) != 1 || tree.Files[0].PinID != "p4" {
+		t.Fatalf("files = %+v, want latest dog.png (p4)", tree.Files)
+	}
+	if len(tree.Directories) != 1 {
+		t.Fatalf("directories = %+v, want 2024", tree.Directories)
+	}
+	if got := tree.Directories[0]; got.Name != "2024" || got.Path != "/file/photos/2024" || got.FileCount != 1 || got.TotalSize != 30 {
+		t.Errorf("directories[0] = %+v", got)
+	}
+}
+
+func TestBuildPathTreeSortsFilesByName(t *testing.T) {
+	files := []*model.IndexerFile{
+		treeFile("p2", "p2", "/file/b.txt", "b.txt", 1),
+		treeFile("p1", "p1", "/file/A.txt", "A.txt", 1),
+		treeFile("p0", "p0", "/file", "c.txt", 1),
+	}
+	base, _ := metaid_protocols.ParseMetaIDPath("/file")
+	tree := &PathTree{Path: base.Path}
+	buildPathTree(tree, base, files)
+
+	var names []string
+	for _, f := range tree.Files {
+		names = append(names, f.FileName)
+	}
+	if want := []string{"A.txt", "b.txt", "c.txt"}; !equalStrings(names, want) {
+		t.Errorf("file order = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,434 @@
+package indexer_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+// PeerFetchHeader marks the content requests a gateway sends to its peers. A
+// gateway answers them from its own storage only, so peers lacking a blob
+// never ask each other in circles.
+const PeerFetchHeader = "X-Mfs-Peer-Fetch"
+
+// Peer settings used when indexer.peers.* is not configured
+const (
+	DefaultPeerDiscoverMinutes = 10
+	DefaultPeerTimeoutSeconds  = 30
+	DefaultPeerMaxFileMB       = 100
+
+	// maxPeers bound on configured peers
+	maxPeers = 32
+	// maxDiscoveredPeers bound on discovered peers, kept apart from the
+	// configured ones so peer lists cannot push those out
+	maxDiscoveredPeers = 16
+	// peerBackoff how long a peer that failed is skipped
+	peerBackoff = time.Minute
+)
+
+// ErrPeerContentNotFound is returned when no peer has the content of a file
+var ErrPeerContentNotFound = errors.New("content not found on any peer")
+
+// PeerStatus a peer gateway and the outcome of the last requests to it
+type PeerStatus struct {
+	URL           string `json:"url"`
+	Discovered    bool   `json:"discovered"`                // Listed by another peer rather than configured
+	Available     bool   `json:"available"`                 // Not skipped after a recent failure
+	LastSuccessAt int64  `json:"last_success_at,omitempty"` // Last answered request (ms)
+	LastErrorAt   int64  `json:"last_error_at,omitempty"`   // Last failed request (ms)
+	LastError     string `json:"last_error,omitempty"`
+}
+
+// peerGateways the peers of indexer.peers. Concurrent requests for the same
+// PIN share one fetch. Discovered peers must resolve to public addresses and
+// are reached through publicClient, which checks that again when connecting.
+type peerGateways struct {
+	client        *http.Client
+	publicClient  *http.Client
+	maxBytes      int64
+	discover      bool
+	discoverEvery time.Duration
+
+	mu           sync.Mutex
+	peers        []*PeerStatus
+	discoveredAt time.Time
+	discovering  bool
+
+	fetchMu  sync.Mutex
+	inflight map[string]*peerFetch
+}
+
+type peerFetch struct {
+	done    chan struct{}
+	content []byte
+	err     error
+}
+
+// newPeerGateways returns the peers configured by indexer.peers, or nil when
+// none are
+func newPeerGateways(cfg conf.IndexerPeersConfig) *peerGateways {
+	timeout := time.Duration(DefaultPeerTimeoutSeconds) * time.Second
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseBlockedAddress}
+	p := &peerGateways{
+		client: &http.Client{Timeout: timeout},
+		publicClient: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext}, // No proxy: the dialed address is the peer
+		},
+		maxBytes:      DefaultPeerMaxFileMB * 1024 * 1024,
+		discover:      cfg.Discover,
+		discoverEvery: time.Duration(DefaultPeerDiscoverMinutes) * time.Minute,
+		inflight:      make(map[string]*peerFetch),
+	}
+	if cfg.MaxFileMB > 0 {
+		p.maxBytes = int64(cfg.MaxFileMB) * 1024 * 1024
+	}
+	if cfg.DiscoverMinutes > 0 {
+		p.discoverEvery = time.Duration(cfg.DiscoverMinutes) * time.Minute
+	}
+	for _, raw := range cfg.Urls {
+		if !p.add(raw, false) {
+			log.Printf("[Peers] Ignoring invalid peer URL %q", raw)
+		}
+	}
+	if len(p.peers) == 0 {
+		return nil
+	}
+	return p
+}
+
+// normalizePeerURL returns the base URL of an http(s) peer without a
+// trailing slash
+func normalizePeerURL(raw string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	return strings.TrimSuffix(u.Scheme+"://"+u.Host+u.Path, "/"), true
+}
+
+// add adds a peer unless it is known already or its limit (maxPeers
+// configured, maxDiscoveredPeers discovered) is reached; false when raw is not
+// a peer URL, or for a discovered one, not a public address
+func (p *peerGateways) add(raw string, discovered bool) bool {
+	peerURL, ok := normalizePeerURL(raw)
+	if !ok {
+		return false
+	}
+	if !p.fits(peerURL, discovered) {
+		return true
+	}
+	if discovered {
+		u, _ := url.Parse(peerURL)
+		if err := checkWebhookHost(u.Hostname()); err != nil {
+			log.Printf("[Peers] Ignoring discovered peer %s: %v", peerURL, err)
+			return false
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fitsLocked(peerURL, discovered) {
+		p.peers = append(p.peers, &PeerStatus{URL: peerURL, Discovered: discovered})
+	}
+	return true
+}
+
+// fits reports whether peerURL is new and within the limit of its kind
+func (p *peerGateways) fits(peerURL string, discovered bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fitsLocked(peerURL, discovered)
+}
+
+func (p *peerGateways) fitsLocked(peerURL string, discovered bool) bool {
+	count := 0
+	for _, peer := range p.peers {
+		if peer.URL == peerURL {
+			return false
+		}
+		if peer.Discovered == discovered {
+			count++
+		}
+	}
+	if discovered {
+		return count < maxDiscoveredPeers
+	}
+	return count < maxPeers
+}
+
+// clientFor the client for requests to peerURL: publicClient for discovered
+// peers
+func (p *peerGateways) clientFor(peerURL string) *http.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, peer := range p.peers {
+		if peer.URL == peerURL && peer.Discovered {
+			return p.publicClient
+		}
+	}
+	return p.client
+}
+
+// list returns the status of every peer, configured ones first
+func (p *peerGateways) list() []PeerStatus {
+	p.maybeDiscover()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]PeerStatus, 0, len(p.peers))
+	for _, peer := range p.peers {
+		status := *peer
+		status.Available = p.availableLocked(peer, time.Now())
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func (p *peerGateways) availableLocked(peer *PeerStatus, now time.Time) bool {
+	return peer.LastErrorAt <= peer.LastSuccessAt || now.Sub(time.UnixMilli(peer.LastErrorAt)) >= peerBackoff
+}
+
+// candidates the URLs of the peers not skipped after a recent failure
+func (p *peerGateways) candidates() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var urls []string
+	for _, peer := range p.peers {
+		if p.availableLocked(peer, now) {
+			urls = append(urls, peer.URL)
+		}
+	}
+	return urls
+}
+
+// record notes the outcome of a request to peerURL
+func (p *peerGateways) record(peerURL string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, peer := range p.peers {
+		if peer.URL != peerURL {
+			continue
+		}
+		if err == nil {
+			peer.LastSuccessAt = time.Now().UnixMilli()
+		} else {
+			peer.LastErrorAt = time.Now().UnixMilli()
+			peer.LastError = err.Error()
+		}
+		return
+	}
+}
+
+// fetchContent fetches the content of file from the first peer that has it
+// and whose copy matches the file hash. Peers answering with other bytes are
+// recorded as failed.
+func (p *peerGateways) fetchContent(file *model.IndexerFile) ([]byte, error) {
+	if file.FileHash == "" {
+		return nil, errors.New("file has no hash to verify peer content against")
+	}
+	if file.FileSize > p.maxBytes {
+		return nil, fmt.Errorf("file size %d exceeds indexer.peers.max_file_mb", file.FileSize)
+	}
+	p.maybeDiscover()
+
+	p.fetchMu.Lock()
+	if f, ok := p.inflight[file.PinID]; ok {
+		p.fetchMu.Unlock()
+		<-f.done
+		return f.content, f.err
+	}
+	f := &peerFetch{done: make(chan struct{})}
+	p.inflight[file.PinID] = f
+	p.fetchMu.Unlock()
+
+	f.content, f.err = p.fetchFromPeers(file)
+	p.fetchMu.Lock()
+	delete(p.inflight, file.PinID)
+	p.fetchMu.Unlock()
+	close(f.done)
+	return f.content, f.err
+}
+
+func (p *peerGateways) fetchFromPeers(file *model.IndexerFile) ([]byte, error) {
+	for _, peerURL := range p.candidates() {
+		content, err := p.getContent(peerURL, file.PinID)
+		if err == nil && content != nil {
+			if hash := calculateSHA256(content); !strings.EqualFold(hash, file.FileHash) {
+				err = fmt.Errorf("content of %s has sha256 %s, the file has %s", file.PinID, hash, file.FileHash)
+			}
+		}
+		p.record(peerURL, err)
+		if err != nil {
+			log.Printf("[Peers] Failed to fetch %s from %s: %v", file.PinID, peerURL, err)
+			continue
+		}
+		if content != nil {
+			log.Printf("[Peers] Fetched %s from %s (%d bytes)", file.PinID, peerURL, len(content))
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPeerContentNotFound, file.PinID)
+}
+
+// getContent fetches the content of pinID from a peer; nil content when the
+// peer does not have it
+func (p *peerGateways) getContent(peerURL, pinID string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, peerURL+"/api/v1/files/content/"+url.PathEscape(pinID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(PeerFetchHeader, "1")
+	resp, err := p.clientFor(peerURL).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("peer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned HTTP %d", resp.StatusCode)
+	}
+
+	// Content is sent with a Content-Disposition; errors are the JSON
+	// envelope, 40400 when the peer does not have the file
+	if resp.Header.Get("Content-Disposition") == "" {
+		var body struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+			return nil, fmt.Errorf("invalid peer response: %w", err)
+		}
+		if body.Code == 40400 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("peer error %d: %s", body.Code, body.Message)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read peer content: %w", err)
+	}
+	if int64(len(content)) > p.maxBytes {
+		return nil, fmt.Errorf("peer content exceeds %d bytes", p.maxBytes)
+	}
+	return content, nil
+}
+
+// maybeDiscover starts reading the peer lists of the peers in the
+// background when discovery is on and the last read is older than
+// discoverEvery
+func (p *peerGateways) maybeDiscover() {
+	if !p.discover {
+		return
+	}
+	p.mu.Lock()
+	if p.discovering || time.Since(p.discoveredAt) < p.discoverEvery {
+		p.mu.Unlock()
+		return
+	}
+	p.discovering = true
+	p.mu.Unlock()
+
+	go func() {
+		p.discoverPeers()
+		p.mu.Lock()
+		p.discovering = false
+		p.mu.Unlock()
+	}()
+}
+
+// discoverPeers adds the gateways listed by each available peer
+func (p *peerGateways) discoverPeers() {
+	for _, peerURL := range p.candidates() {
+		listed, err := p.getPeerList(peerURL)
+		if err != nil {
+			log.Printf("[Peers] Failed to read the peer list of %s: %v", peerURL, err)
+			continue
+		}
+		for _, raw := range listed {
+			p.add(raw, true)
+		}
+	}
+	p.mu.Lock()
+	p.discoveredAt = time.Now()
+	p.mu.Unlock()
+}
+
+// getPeerList reads the peer URLs a gateway lists at GET /api/v1/peers
+func (p *peerGateways) getPeerList(peerURL string) ([]string, error) {
+	resp, err := p.clientFor(peerURL).Get(peerURL + "/api/v1/peers")
+	if err != nil {
+		return nil, fmt.Errorf("peer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Code int `json:"code"`
+		Data struct {
+			Peers []PeerStatus `json:"peers"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid peer response: %w", err)
+	}
+	if body.Code != 0 {
+		return nil, fmt.Errorf("peer error %d", body.Code)
+	}
+	urls := make([]string, 0, len(body.Data.Peers))
+	for _, peer := range body.Data.Peers {
+		urls = append(urls, peer.URL)
+	}
+	return urls, nil
+}
+
+// ListPeers lists the peer gateways of indexer.peers with their status;
+// empty when none are configured
+func (s *IndexerFileService) ListPeers() []PeerStatus {
+	if s.peers == nil {
+		return []PeerStatus{}
+	}
+	return s.peers.list()
+}
+
+// readFile reads the stored content of file. When the blob cannot be read
+// and peers are configured, the content is fetched from a peer, verified
+// against the file hash and cached in storage. The content of a provisional
+// file is refused under indexer.confirmations.refuse_provisional.
+func (s *IndexerFileService) readFile(file *model.IndexerFile) ([]byte, error) {
+	if err := CheckProvisionalContent(file); err != nil {
+		return nil, err
+	}
+	content, err := readFileBlob(s.storage, s.pendingStorageDAO, file)
+	if err == nil || s.peers == nil || errors.Is(err, ErrFileStreamOnly) {
+		return content, err
+	}
+
+	content, peerErr := s.peers.fetchContent(file)
+	if peerErr != nil {
+		log.Printf("[Peers] No peer served %s: %v", file.PinID, peerErr)
+		return nil, err
+	}
+	if file.StoragePath != "" {
+		if err := s.storage.Save(file.StoragePath, content); err != nil {
+			log.Printf("[Peers] Failed to cache %s: %v", file.PinID, err)
+		}
+	}
+	return content, nil
+}
@@ -0,0 +1,147 @@
+package indexer_service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+// newTestPeer serves content for /api/v1/files/content/{pinId}, the JSON
+// not-found envelope for other PINs, and peers at /api/v1/peers
+func newTestPeer(t *testing.T, content map[string]string, peers string) *httptest.Server {
+	t.Helper()
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/peers" {
+			w.Write([]byte(`{"code":0,"message":"success","data":{"peers":` + peers + `}}`))
+			return
+		}
+		if r.Header.Get(PeerFetchHeader) == "" {
+			t.Errorf("peer request without %s", PeerFetchHeader)
+		}
+		for pinID, body := range content {
+			if r.URL.Path == "/api/v1/files/content/"+pinID {
+				w.Header().Set("Content-Disposition", `inline; filename="f.txt"`)
+				w.Write([]byte(body))
+				return
+			}
+		}
+		w.Write([]byte(`{"code":40400,"message":"file not found","data":null}`))
+	}))
+	t.Cleanup(peer.Close)
+	return peer
+}
+
+func TestReadFile_FetchesMissingBlobFromPeers(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	const pinID = "peerfile1i0"
+	empty := newTestPeer(t, nil, "[]")
+	tampered := newTestPeer(t, map[string]string{pinID: "HELLO"}, "[]")
+	good := newTestPeer(t, map[string]string{pinID: "hello"}, "[]")
+	conf.Cfg.Indexer.Peers = conf.IndexerPeersConfig{Urls: []string{empty.URL, tampered.URL + "/", "not a url", good.URL}}
+
+	file := &model.IndexerFile{
+		FirstPinID:  pinID,
+		PinID:       pinID,
+		Path:        "/file/f.txt",
+		ChunkType:   model.ChunkTypeSingle,
+		FileName:    "f.txt",
+		FileSize:    5,
+		FileHash:    calculateSHA256([]byte("hello")),
+		StorageType: "local",
+		StoragePath: "indexer/mvc/peerfile1i0.txt",
+		ChainName:   "mvc",
+		Status:      model.StatusSuccess,
+	}
+	if err := s.indexerFileDAO.Create(file); err != nil {
+		t.Fatal(err)
+	}
+
+	fileService := NewIndexerFileService(stor)
+	if local, err := fileService.OpenLocalFileContent(pinID); err == nil {
+		t.Fatalf("OpenLocalFileContent served %d bytes of a missing blob", local.Size)
+	}
+	content, _, _, err := fileService.GetFileContent(pinID)
+	if err != nil || string(content) != "hello" {
+		t.Fatalf("GetFileContent = %q, %v; want the good peer's copy", content, err)
+	}
+	if cached, err := stor.Get(file.StoragePath); err != nil || string(cached) != "hello" {
+		t.Errorf("cached blob = %q, %v", cached, err)
+	}
+
+	peers := fileService.ListPeers()
+	if len(peers) != 3 {
+		t.Fatalf("ListPeers = %+v, want the 3 valid peers", peers)
+	}
+	if !peers[0].Available || peers[1].Available || peers[1].LastError == "" || !peers[2].Available {
+		t.Errorf("ListPeers = %+v, want only the tampering peer unavailable", peers)
+	}
+}
+
+func TestPeerGatewaysDiscover(t *testing.T) {
+	fakeWebhookResolver(t, map[string]string{"peer.example.com": "93.184.216.34"})
+	listing := newTestPeer(t, nil, `[{"url":"https://peer.example.com/"},{"url":"ftp://peer.example.com"}]`)
+	p := newPeerGateways(conf.IndexerPeersConfig{Urls: []string{listing.URL}, Discover: true})
+	p.discoverPeers()
+
+	peers := p.list()
+	if len(peers) != 2 || peers[1].URL != "https://peer.example.com" || !peers[1].Discovered || peers[0].Discovered {
+		t.Errorf("peers = %+v, want the listing peer and the discovered https peer", peers)
+	}
+	if newPeerGateways(conf.IndexerPeersConfig{}) != nil {
+		t.Error("peers created without URLs")
+	}
+}
+
+func TestPeerGatewaysDiscoverRefusesNonPublicPeers(t *testing.T) {
+	fakeWebhookResolver(t, map[string]string{"peer.example.com": "93.184.216.34", "internal.example": "10.0.0.5"})
+	listing := newTestPeer(t, nil, `[{"url":"http://127.0.0.1:8080"},{"url":"http://169.254.169.254"},{"url":"http://10.1.2.3"},`+
+		`{"url":"http://[::1]"},{"url":"https://internal.example"},{"url":"https://nowhere.example"},{"url":"https://peer.example.com"}]`)
+	p := newPeerGateways(conf.IndexerPeersConfig{Urls: []string{listing.URL}, Discover: true})
+	p.discoverPeers()
+
+	peers := p.list()
+	if len(peers) != 2 || peers[1].URL != "https://peer.example.com" {
+		t.Errorf("peers = %+v, want the listing peer and the public discovered peer", peers)
+	}
+	// Discovered peers are reached through a client that refuses non-public
+	// addresses when connecting, whatever their name resolved to before
+	if p.clientFor("https://peer.example.com") != p.publicClient || p.clientFor(listing.URL) != p.client {
+		t.Error("discovered peers must use publicClient, configured peers client")
+	}
+	if resp, err := p.publicClient.Get(listing.URL + "/api/v1/peers"); err == nil {
+		resp.Body.Close()
+		t.Error("publicClient reached a loopback address")
+	}
+}
+
+func TestPeerGatewaysDiscoveredLimit(t *testing.T) {
+	hosts := map[string]string{}
+	listed := "["
+	for i := 0; i < maxPeers+8; i++ {
+		host := fmt.Sprintf("peer%d.example.com", i)
+		hosts[host] = "93.184.216.34"
+		if i > 0 {
+			listed += ","
+		}
+		listed += `{"url":"https://` + host + `"}`
+	}
+	fakeWebhookResolver(t, hosts)
+	listing := newTestPeer(t, nil, listed+"]")
+	configured := []string{listing.URL, newTestPeer(t, nil, "[]").URL}
+	p := newPeerGateways(conf.IndexerPeersConfig{Urls: configured, Discover: true})
+	p.discoverPeers()
+
+	peers := p.list()
+	if len(peers) != len(configured)+maxDiscoveredPeers {
+		t.Fatalf("%d peers, want %d configured and %d discovered", len(peers), len(configured), maxDiscoveredPeers)
+	}
+	for i, url := range configured {
+		if peers[i].URL != url || peers[i].Discovered {
+			t.Errorf("peers[%d] = %+v, want configured %s", i, peers[i], url)
+		}
+	}
+}
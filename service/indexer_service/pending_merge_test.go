@@ -8,12 +8,13 @@ import (
 	"meta-file-system/indexer"
 	"meta-file-system/model"
 	"meta-file-system/model/dao"
+	"meta-file-system/service/common_service/metaid"
 	"meta-file-system/storage"
 )
 
 // setTestPebble swaps the global database.DB to a fresh temp Pebble and
 // restores it on test end. The DAOs read database.DB at construction time.
-func setTestPebble(t *testing.T) {
+func setTestPebble(t testing.TB) {
 	t.Helper()
 	dbi, err := database.NewPebbleDatabase(&database.PebbleConfig{DataDir: t.TempDir()})
 	if err != nil {
@@ -29,7 +30,7 @@ func setTestPebble(t *testing.T) {
 
 // setTestConfig installs a minimal conf.Cfg so the merge path (which reads
 // conf.Cfg.Storage.Type) does not dereference nil.
-func setTestConfig(t *testing.T) {
+func setTestConfig(t testing.TB) {
 	t.Helper()
 	prev := conf.Cfg
 	conf.Cfg = &conf.Config{
@@ -42,7 +43,7 @@ func setTestConfig(t *testing.T) {
 // newMergeTestService builds a minimal IndexerService for retry/merge tests:
 // real temp Pebble-backed DAOs + a temp LocalStorage. parser/chainType are set
 // to defaults; the merge path does not touch the scanner.
-func newMergeTestService(t *testing.T) (*IndexerService, *storage.LocalStorage) {
+func newMergeTestService(t testing.TB) (*IndexerService, *storage.LocalStorage) {
 	t.Helper()
 	setTestPebble(t)
 	setTestConfig(t)
@@ -58,6 +59,8 @@ func newMergeTestService(t *testing.T) (*IndexerService, *storage.LocalStorage)
 		indexerFileDAO:      dao.NewIndexerFileDAO(),
 		indexerFileChunkDAO: dao.NewIndexerFileChunkDAO(),
 		pendingIndexFileDAO: dao.NewPendingIndexFileDAO(),
+		pendingStorageDAO:   dao.NewPendingStorageWriteDAO(),
+		pendingCreatorDAO:   dao.NewPendingCreatorResolutionDAO(),
 		storage:             stor,
 		chainType:           indexer.ChainTypeMVC,
 	}
@@ -66,7 +69,7 @@ func newMergeTestService(t *testing.T) (*IndexerService, *storage.LocalStorage)
 
 // seedChunks writes IndexerFileChunk records and their bytes to storage, and
 // returns the chunkList JSON to embed in a pending record / index JSON.
-func seedChunks(t *testing.T, s *IndexerService, stor *storage.LocalStorage, indexPinID string, contents []string) string {
+func seedChunks(t testing.TB, s *IndexerService, stor *storage.LocalStorage, indexPinID string, contents []string) string {
 	t.Helper()
 	chunkList := ""
 	for i, c := range contents {
@@ -80,7 +83,9 @@ func seedChunks(t *testing.T, s *IndexerService, stor *storage.LocalStorage, ind
 			StoragePath:    storagePath,
 			ChunkSize:      int64(len(c)),
 			ChunkMd5:       "deadbeef",
+			ChunkSha256:    calculateSHA256([]byte(c)),
 			ChainName:      "mvc",
+			Status:         model.StatusSuccess,
 		}
 		if err := s.indexerFileChunkDAO.Create(chunk); err != nil {
 			t.Fatalf("seed chunk DAO: %v", err)
@@ -88,7 +93,7 @@ func seedChunks(t *testing.T, s *IndexerService, stor *storage.LocalStorage, ind
 		if i > 0 {
 			chunkList += ","
 		}
-		chunkList += `{"pinId":"` + chunkPinID + `","sha256":"x"}`
+		chunkList += `{"pinId":"` + chunkPinID + `","sha256":"` + calculateSHA256([]byte(c)) + `"}`
 	}
 	return chunkList
 }
@@ -173,3 +178,193 @@ func TestRetryPendingIndexMerges_LeavesRecordWhenChunksStillMissing(t *testing.T
 		t.Error("pending record should survive when chunks are still missing")
 	}
 }
+
+// TestRetryPendingIndexMerges_RefusesMergeOnChunkHashMismatch proves a chunk
+// whose stored bytes do not match the index sha256 blocks the merge, and that
+// the offending chunk is recorded on the surviving pending record.
+func TestRetryPendingIndexMerges_RefusesMergeOnChunkHashMismatch(t *testing.T) {
+	s, stor := newMergeTestService(t)
+
+	const indexPinID = "idxpin-mismatch-1i0"
+	chunkList := seedChunks(t, s, stor, indexPinID, []string{"AAA", "BBB"})
+	// Corrupt the second chunk in storage after it was indexed.
+	if err := stor.Save("indexer/chunk/mvc/"+indexPinID+"-chunk2", []byte("XXX")); err != nil {
+		t.Fatalf("corrupt chunk: %v", err)
+	}
+
+	metaData := `{"pinID":"` + indexPinID + `","chainName":"mvc","operation":"create","creatorAddress":"1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}`
+	indexJSON := `{"sha256":"ignored","fileSize":6,"chunkNumber":2,"chunkList":[` + chunkList + `],"dataType":"text/plain","name":"f.txt"}`
+	if err := s.pendingIndexFileDAO.Create(&model.PendingIndexFile{
+		PinID: indexPinID, FirstPinID: indexPinID, ChainName: "mvc",
+		MetaData: metaData, IndexJSON: indexJSON,
+	}); err != nil {
+		t.Fatalf("seed pending: %v", err)
+	}
+
+	s.retryPendingIndexMerges("mvc")
+
+	if file, _ := s.indexerFileDAO.GetByPinID(indexPinID); file != nil {
+		t.Errorf("file should not be merged with a corrupt chunk, got %+v", file)
+	}
+	got, _ := s.pendingIndexFileDAO.GetByPinID(indexPinID)
+	if got == nil {
+		t.Fatal("pending record should survive a hash mismatch")
+	}
+	if got.MismatchChunkPinID != indexPinID+"-chunk2" || got.MismatchChunkIndex != 1 {
+		t.Errorf("mismatch = (%q, %d), want (%q, 1)", got.MismatchChunkPinID, got.MismatchChunkIndex, indexPinID+"-chunk2")
+	}
+}
+
+// TestRetryPendingIndexMerges_RejectsAfterMaxMismatchAttempts proves a chunk
+// that keeps failing verification is not re-fetched forever: after
+// maxChunkMismatchAttempts the pending record is rejected and later blocks
+// leave it alone.
+func TestRetryPendingIndexMerges_RejectsAfterMaxMismatchAttempts(t *testing.T) {
+	s, stor := newMergeTestService(t)
+
+	const indexPinID = "idxpin-reject-1i0"
+	chunkList := seedChunks(t, s, stor, indexPinID, []string{"AAA", "BBB"})
+	if err := stor.Save("indexer/chunk/mvc/"+indexPinID+"-chunk1", []byte("XXX")); err != nil {
+		t.Fatalf("corrupt chunk: %v", err)
+	}
+
+	metaData := `{"pinID":"` + indexPinID + `","chainName":"mvc","operation":"create","creatorAddress":"1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}`
+	indexJSON := `{"sha256":"ignored","fileSize":6,"chunkNumber":2,"chunkList":[` + chunkList + `],"dataType":"text/plain","name":"f.txt"}`
+	if err := s.pendingIndexFileDAO.Create(&model.PendingIndexFile{
+		PinID: indexPinID, FirstPinID: indexPinID, ChainName: "mvc",
+		MetaData: metaData, IndexJSON: indexJSON,
+	}); err != nil {
+		t.Fatalf("seed pending: %v", err)
+	}
+
+	for i := 1; i < maxChunkMismatchAttempts; i++ {
+		s.retryPendingIndexMerges("mvc")
+	}
+	got, _ := s.pendingIndexFileDAO.GetByPinID(indexPinID)
+	if got == nil || got.Status != "" || got.MismatchAttempts != maxChunkMismatchAttempts-1 {
+		t.Fatalf("before limit: %+v, want pending with %d attempts", got, maxChunkMismatchAttempts-1)
+	}
+
+	s.retryPendingIndexMerges("mvc")
+	got, _ = s.pendingIndexFileDAO.GetByPinID(indexPinID)
+	if got == nil || got.Status != model.StatusRejected || got.MismatchAttempts != maxChunkMismatchAttempts {
+		t.Fatalf("at limit: %+v, want rejected with %d attempts", got, maxChunkMismatchAttempts)
+	}
+	if got.LastError == "" || got.MismatchChunkIndex != 0 {
+		t.Errorf("LastError = %q, MismatchChunkIndex = %d, want the chunk 0 mismatch", got.LastError, got.MismatchChunkIndex)
+	}
+
+	// Rejected records are no longer retried
+	s.retryPendingIndexMerges("mvc")
+	got, _ = s.pendingIndexFileDAO.GetByPinID(indexPinID)
+	if got.MismatchAttempts != maxChunkMismatchAttempts {
+		t.Errorf("MismatchAttempts = %d after rejection, want %d", got.MismatchAttempts, maxChunkMismatchAttempts)
+	}
+	if file, _ := s.indexerFileDAO.GetByPinID(indexPinID); file != nil {
+		t.Errorf("rejected index should not be merged, got %+v", file)
+	}
+}
+
+// TestRetryPendingIndexMerges_ResolvesRetriedChunkBySha256 proves an index that
+// references a chunk PinID which was never indexed (the user re-inscribed the
+// same chunk data) still merges using the indexed chunk with the same sha256.
+func TestRetryPendingIndexMerges_ResolvesRetriedChunkBySha256(t *testing.T) {
+	s, stor := newMergeTestService(t)
+
+	const indexPinID = "idxpin-dedup-1i0"
+	seedChunks(t, s, stor, indexPinID, []string{"AAA", "BBB"})
+	// The index points at a retry inscription of chunk 2 that was never indexed.
+	chunkList := `{"pinId":"` + indexPinID + `-chunk1","sha256":"` + calculateSHA256([]byte("AAA")) + `"},` +
+		`{"pinId":"retried-chunk2-i0","sha256":"` + calculateSHA256([]byte("BBB")) + `"}`
+
+	metaData := `{"pinID":"` + indexPinID + `","chainName":"mvc","operation":"create","creatorAddress":"1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}`
+	indexJSON := `{"sha256":"ignored","fileSize":6,"chunkNumber":2,"chunkList":[` + chunkList + `],"dataType":"text/plain","name":"f.txt"}`
+	if err := s.pendingIndexFileDAO.Create(&model.PendingIndexFile{
+		PinID: indexPinID, FirstPinID: indexPinID, ChainName: "mvc",
+		MetaData: metaData, IndexJSON: indexJSON,
+	}); err != nil {
+		t.Fatalf("seed pending: %v", err)
+	}
+
+	s.retryPendingIndexMerges("mvc")
+
+	file, err := s.indexerFileDAO.GetByPinID(indexPinID)
+	if err != nil || file == nil {
+		t.Fatalf("merge should resolve the retried chunk by sha256, got file=%+v err=%v", file, err)
+	}
+	if file.FileHash != calculateSHA256([]byte("AAABBB")) {
+		t.Errorf("FileHash = %s, want hash of AAABBB", file.FileHash)
+	}
+}
+
+// TestRetryPendingIndexMerges_DoesNotClaimOtherCreatorsChunk proves the sha256
+// fallback never uses (or re-parents) a chunk that already belongs to another
+// creator's file, while an unattached chunk with the same content is used and
+// attached to the index.
+func TestRetryPendingIndexMerges_DoesNotClaimOtherCreatorsChunk(t *testing.T) {
+	s, stor := newMergeTestService(t)
+
+	const indexPinID = "idxpin-foreign-1i0"
+	const otherFilePinID = "otherfile-1i0"
+	if err := s.indexerFileDAO.Create(&model.IndexerFile{
+		PinID: otherFilePinID, FirstPinID: otherFilePinID, ChainName: "mvc",
+		CreatorMetaId: metaid.Derive("1OtherCreatorAddress"), Status: model.StatusSuccess,
+	}); err != nil {
+		t.Fatalf("seed other file: %v", err)
+	}
+	foreign := &model.IndexerFileChunk{
+		PinID: "foreign-chunk-i0", StoragePath: "indexer/chunk/mvc/foreign-chunk-i0", ChunkSize: 3,
+		ChunkSha256: calculateSHA256([]byte("BBB")), ParentPinID: otherFilePinID, ChunkIndex: 4,
+		ChainName: "mvc", Status: model.StatusSuccess,
+	}
+	if err := stor.Save(foreign.StoragePath, []byte("BBB")); err != nil {
+		t.Fatalf("seed foreign chunk storage: %v", err)
+	}
+	if err := s.indexerFileChunkDAO.Create(foreign); err != nil {
+		t.Fatalf("seed foreign chunk: %v", err)
+	}
+
+	chunkList := `{"pinId":"missing-chunk-i0","sha256":"` + calculateSHA256([]byte("BBB")) + `"}`
+	metaData := `{"pinID":"` + indexPinID + `","chainName":"mvc","operation":"create","creatorAddress":"1BoatSLRHtKNngkdXEeobR76b53LETtpyT"}`
+	indexJSON := `{"sha256":"ignored","fileSize":3,"chunkNumber":1,"chunkList":[` + chunkList + `],"dataType":"text/plain","name":"f.txt"}`
+	if err := s.pendingIndexFileDAO.Create(&model.PendingIndexFile{
+		PinID: indexPinID, FirstPinID: indexPinID, ChainName: "mvc",
+		MetaData: metaData, IndexJSON: indexJSON,
+	}); err != nil {
+		t.Fatalf("seed pending: %v", err)
+	}
+
+	s.retryPendingIndexMerges("mvc")
+
+	if file, _ := s.indexerFileDAO.GetByPinID(indexPinID); file != nil {
+		t.Errorf("index must not merge another creator's chunk, got %+v", file)
+	}
+	got, _ := s.indexerFileChunkDAO.GetByPinID(foreign.PinID)
+	if got == nil || got.ParentPinID != otherFilePinID || got.ChunkIndex != 4 {
+		t.Fatalf("foreign chunk was modified: %+v", got)
+	}
+
+	// An unattached chunk with the same content can be used
+	free := &model.IndexerFileChunk{
+		PinID: "free-chunk-i0", StoragePath: "indexer/chunk/mvc/free-chunk-i0", ChunkSize: 3,
+		ChunkSha256: calculateSHA256([]byte("BBB")), ChainName: "mvc", Status: model.StatusSuccess,
+	}
+	if err := stor.Save(free.StoragePath, []byte("BBB")); err != nil {
+		t.Fatalf("seed free chunk storage: %v", err)
+	}
+	if err := s.indexerFileChunkDAO.Create(free); err != nil {
+		t.Fatalf("seed free chunk: %v", err)
+	}
+
+	s.retryPendingIndexMerges("mvc")
+
+	if file, _ := s.indexerFileDAO.GetByPinID(indexPinID); file == nil {
+		t.Fatal("index should merge using the unattached chunk")
+	}
+	if got, _ := s.indexerFileChunkDAO.GetByPinID(free.PinID); got == nil || got.ParentPinID != indexPinID {
+		t.Errorf("free chunk parent = %+v, want %s", got, indexPinID)
+	}
+	if got, _ := s.indexerFileChunkDAO.GetByPinID(foreign.PinID); got == nil || got.ParentPinID != otherFilePinID {
+		t.Errorf("foreign chunk parent changed: %+v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+)
+
+// profileValue a version of a profile field: the PIN that set it, the value
+// reported in ProfileChange and the key versions are compared by
+type profileValue struct {
+	PinID string
+	Value string
+	Key   string
+}
+
+// latestProfileValue reads the latest value of field for metaID; nil when the
+// user has none yet
+func latestProfileValue(field model.ProfileField, metaID string) (*profileValue, error) {
+	var (
+		value *profileValue
+		err   error
+	)
+	switch field {
+	case model.ProfileFieldName:
+		var info *model.UserNameInfo
+		if info, err = database.DB.GetLatestUserNameInfo(metaID); err == nil && info != nil {
+			value = &profileValue{PinID: info.PinID, Value: info.Name, Key: info.Name}
+		}
+	case model.ProfileFieldAvatar:
+		var info *model.UserAvatarInfo
+		if info, err = database.DB.GetLatestUserAvatarInfo(metaID); err == nil && info != nil {
+			// The URL names the PIN, so the same image set again compares by hash
+			key := info.FileHash
+			if key == "" {
+				key = info.AvatarUrl
+			}
+			value = &profileValue{PinID: info.PinID, Value: info.AvatarUrl, Key: key}
+		}
+	case model.ProfileFieldChatPublicKey:
+		var info *model.UserChatPublicKeyInfo
+		if info, err = database.DB.GetLatestUserChatPublicKeyInfo(metaID); err == nil && info != nil {
+			value = &profileValue{PinID: info.PinID, Value: info.ChatPublicKey, Key: info.ChatPublicKey}
+		}
+	default:
+		return nil, fmt.Errorf("unknown profile field %q", field)
+	}
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// diffProfile returns the change pinID made to field, from prev (nil when the
+// user had no value) to the latest value after the write. It is nil when pinID
+// did not become the latest value (an older PIN indexed late), was already the
+// latest one (a mempool PIN confirmed, or indexed again) or left the value as
+// it was.
+func diffProfile(field model.ProfileField, pinID string, prev, latest *profileValue) *model.ProfileChange {
+	if latest == nil || latest.PinID != pinID {
+		return nil
+	}
+	change := &model.ProfileChange{Field: field, Current: latest.Value, PinId: pinID}
+	if prev != nil {
+		if prev.PinID == pinID || prev.Key == latest.Key {
+			return nil
+		}
+		change.Previous, change.PreviousPinId = prev.Value, prev.PinID
+	}
+	return change
+}
+
+// profileBefore reads the value of field before metaData is written; ok is
+// false when it cannot be read, so no change is reported for the PIN
+func profileBefore(field model.ProfileField, metaID string, metaData *indexer.MetaIDData) (prev *profileValue, ok bool) {
+	prev, err := latestProfileValue(field, metaID)
+	if err != nil {
+		log.Printf("Failed to read %s of %s before PIN %s, not reporting a profile change: %v", field, metaID, metaData.PinID, err)
+		return nil, false
+	}
+	return prev, true
+}
+
+// notifyProfileChange reports the change metaData made to field of the user,
+// given the value before the write: a profile_changed change log event and a
+// profile_changed watch event for the watches and subscribers of the user.
+func (s *IndexerService) notifyProfileChange(field model.ProfileField, metaData *indexer.MetaIDData, prev *profileValue, address, metaID, globalMetaID string, height, timestamp int64) {
+	latest, err := latestProfileValue(field, metaID)
+	if err != nil {
+		log.Printf("Failed to read %s of %s after PIN %s: %v", field, metaID, metaData.PinID, err)
+		return
+	}
+	change := diffProfile(field, metaData.PinID, prev, latest)
+	if change == nil {
+		return
+	}
+	change.MetaId, change.Address, change.GlobalMetaId = metaID, address, globalMetaID
+	change.ChainName, change.BlockHeight, change.Timestamp = metaData.ChainName, height, timestamp
+
+	recordChange(&model.IndexerChangeEvent{
+		Action:      model.ChangeProfileChanged,
+		ChainName:   metaData.ChainName,
+		PinID:       metaData.PinID,
+		MetaId:      metaID,
+		BlockHeight: height,
+		Timestamp:   timestamp,
+	}, change)
+
+	if err := loadWatches(); err != nil {
+		log.Printf("Failed to load watchlist: %v", err)
+		return
+	}
+	if !watchers.active() || !watchers.watched(address, metaID, globalMetaID) {
+		return
+	}
+	watchers.dispatch(model.IndexerWatchEvent{
+		Event:               model.WatchEventProfileChanged,
+		PinID:               metaData.PinID,
+		Path:                metaData.Path,
+		Operation:           metaData.Operation,
+		ContentType:         metaData.ContentType,
+		ChainName:           metaData.ChainName,
+		BlockHeight:         height,
+		Confirmed:           height > 0,
+		Timestamp:           timestamp,
+		CreatorAddress:      address,
+		CreatorMetaId:       metaID,
+		CreatorGlobalMetaId: globalMetaID,
+		Profile:             change,
+	}, database.DB.AddWatchEvent)
+}
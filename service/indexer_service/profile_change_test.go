@@ -0,0 +1,103 @@
+package indexer_service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid"
+)
+
+func TestDiffProfile(t *testing.T) {
+	alice := &profileValue{PinID: "p1i0", Value: "alice", Key: "alice"}
+	cases := []struct {
+		name         string
+		pinID        string
+		prev, latest *profileValue
+		want         string // Previous -> Current, "" for no change
+	}{
+		{"first value", "p1i0", nil, alice, " -> alice"},
+		{"renamed", "p2i0", alice, &profileValue{PinID: "p2i0", Value: "bob", Key: "bob"}, "alice -> bob"},
+		{"same value again", "p2i0", alice, &profileValue{PinID: "p2i0", Value: "alice", Key: "alice"}, ""},
+		{"pin already latest", "p1i0", alice, alice, ""},
+		{"older pin indexed late", "p0i0", alice, alice, ""},
+		{"nothing written", "p1i0", nil, nil, ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			change := diffProfile(model.ProfileFieldName, tc.pinID, tc.prev, tc.latest)
+			got := ""
+			if change != nil {
+				got = change.Previous + " -> " + change.Current
+				if change.PinId != tc.pinID || (tc.prev != nil && change.PreviousPinId != tc.prev.PinID) {
+					t.Errorf("change = %+v", change)
+				}
+			}
+			if got != tc.want {
+				t.Errorf("diffProfile = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestProcessUserNameContent_ReportsProfileChanges(t *testing.T) {
+	s, _ := newMergeTestService(t)
+	const address = "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"
+	sub := watchers.subscribe([]string{metaid.Derive(address)})
+	t.Cleanup(func() { watchers.unsubscribe(sub) })
+
+	index := func(pinID, name string, height, timestamp int64) {
+		t.Helper()
+		metaData := &indexer.MetaIDData{PinID: pinID, Path: "/info/name", Operation: "modify", Content: []byte(name), ChainName: "mvc", CreatorAddress: address}
+		if err := s.processUserNameContent(metaData, pinID, "/info/name", height, timestamp); err != nil {
+			t.Fatalf("processUserNameContent(%s): %v", pinID, err)
+		}
+	}
+	index("namepin1i0", "alice", 0, 1000)
+	index("namepin1i0", "alice", 100, 1000) // Confirmed: not a change
+	index("namepin2i0", "alice", 101, 2000) // Same name
+	index("namepin3i0", "bob", 102, 3000)
+	index("namepin0i0", "carol", 103, 500) // Older than the latest name
+
+	events, err := database.DB.ListChangeEvents(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changes []model.ProfileChange
+	for _, event := range events {
+		if event.Action != model.ChangeProfileChanged {
+			continue
+		}
+		var change model.ProfileChange
+		if err := json.Unmarshal(event.Record, &change); err != nil {
+			t.Fatal(err)
+		}
+		changes = append(changes, change)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("profile changes = %+v, want alice and alice -> bob", changes)
+	}
+	if c := changes[0]; c.Previous != "" || c.Current != "alice" || c.PinId != "namepin1i0" || c.Address != address {
+		t.Errorf("first change = %+v", c)
+	}
+	if c := changes[1]; c.Previous != "alice" || c.Current != "bob" || c.PreviousPinId != "namepin2i0" || c.PinId != "namepin3i0" || c.BlockHeight != 102 {
+		t.Errorf("rename = %+v", c)
+	}
+
+	for _, want := range []string{"alice", "bob"} {
+		select {
+		case e := <-sub.Events:
+			if e.Event != model.WatchEventProfileChanged || e.Profile == nil || e.Profile.Current != want {
+				t.Errorf("subscriber event = %+v, want profile change to %s", e, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no subscriber event for %s", want)
+		}
+	}
+	if len(sub.Events) != 0 {
+		t.Errorf("subscriber got %d extra events", len(sub.Events))
+	}
+}
@@ -0,0 +1,103 @@
+package indexer_service
+
+import (
+	"fmt"
+	"time"
+
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/service/common_service/metaid_protocols"
+	"meta-file-system/storage"
+
+	"github.com/bitcoinsv/bsvd/wire"
+	btcwire "github.com/btcsuite/btcd/wire"
+)
+
+// Replayer indexes blocks read from disk (cmd/replay) with the indexer's
+// parser and handleTransaction, without a block scanner, ZMQ or any of the
+// indexer's background jobs
+type Replayer struct {
+	s       *IndexerService
+	resolve func(data *indexer.MetaIDData)
+}
+
+// NewReplayer creates a replayer of chainType blocks. With node set, creator
+// addresses are looked up on it as the scanner does. Without, nothing is
+// asked of a node: file PINs are queued for the creator retrier of the next
+// indexer run, and other PINs keep the fallback creator address.
+func NewReplayer(stor storage.Storage, chainType indexer.ChainType, node *indexer.BlockScanner) *Replayer {
+	s := &IndexerService{
+		fileDAO:              dao.NewFileDAO(),
+		indexerFileDAO:       dao.NewIndexerFileDAO(),
+		indexerFileChunkDAO:  dao.NewIndexerFileChunkDAO(),
+		pendingIndexFileDAO:  dao.NewPendingIndexFileDAO(),
+		pendingStorageDAO:    dao.NewPendingStorageWriteDAO(),
+		pendingCreatorDAO:    dao.NewPendingCreatorResolutionDAO(),
+		indexerUserAvatarDAO: dao.NewIndexerUserAvatarDAO(),
+		syncStatusDAO:        dao.NewIndexerSyncStatusDAO(),
+		storage:              stor,
+		chainType:            chainType,
+		parser:               indexer.NewMetaIDParser(""),
+		txDeliveries:         newTxDeliveries(),
+	}
+	logParserPolicy(string(chainType))
+
+	r := &Replayer{s: s}
+	if node != nil {
+		s.parser.SetBlockScanner(node)
+		r.resolve = s.creatorResolver(chainType)
+	} else {
+		r.resolve = func(data *indexer.MetaIDData) {
+			if isChunkPath(data.Path) || (data.Operation == "create" && !metaid_protocols.IsProtocolPath(data.Path)) {
+				return
+			}
+			if data.CreatorInputLocation != "" || data.CreatorInputTxVinLocation != "" {
+				data.CreatorResolutionPending = true
+			}
+		}
+	}
+	return r
+}
+
+// ReplayBlock indexes block (*btcwire.MsgBlock or *wire.MsgBlock, see
+// indexer.DecodeBlock) at height like the scanner indexes a fetched block,
+// then raises the chain's sync height to height. The sync height is never
+// lowered, so replaying old blocks leaves the scanner where it was.
+func (r *Replayer) ReplayBlock(block interface{}, height int64) (indexer.PipelineResult, error) {
+	s := r.s
+	var timestamp int64
+	switch b := block.(type) {
+	case *btcwire.MsgBlock:
+		timestamp = indexer.BlockHeaderTimestamp(s.chainType, b.Header.Timestamp)
+	case *wire.MsgBlock:
+		timestamp = indexer.BlockHeaderTimestamp(s.chainType, b.Header.Timestamp)
+	default:
+		return indexer.PipelineResult{}, fmt.Errorf("invalid %s block type %T", s.chainType, block)
+	}
+
+	pipeline := indexer.NewBlockPipeline(s.chainType, blockPipelineConfig(), r.resolve)
+	result, err := pipeline.Run(block, nil, func(tx interface{}, metaDataTx *indexer.MetaIDDataTx) error {
+		return s.handleTransaction(tx, metaDataTx, height, timestamp)
+	})
+	if err != nil {
+		return result, err
+	}
+
+	chainName := string(s.chainType)
+	s.flushCounters(chainName, height)
+	status, err := s.syncStatusDAO.GetByChainName(chainName)
+	switch {
+	case err != nil:
+		return result, fmt.Errorf("failed to read sync status: %w", err)
+	case status == nil:
+		err = s.syncStatusDAO.CreateOrUpdate(&model.IndexerSyncStatus{ChainName: chainName, CurrentSyncHeight: height, CreatedAt: time.Now()})
+	case status.CurrentSyncHeight < height:
+		err = s.syncStatusDAO.UpdateCurrentSyncHeight(chainName, height)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to update sync height: %w", err)
+	}
+	s.retryPendingIndexMerges(chainName)
+	return result, nil
+}
@@ -0,0 +1,214 @@
+package indexer_service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"meta-file-system/indexer"
+)
+
+// PIN outcomes recorded in a rescan report
+const (
+	PinOutcomeIndexed   = "indexed"
+	PinOutcomeDuplicate = "skipped_duplicate"
+	PinOutcomeSkipped   = "skipped"
+	PinOutcomeRejected  = "rejected"
+	PinOutcomeFailed    = "failed"
+)
+
+const (
+	// rescanReportMaxPins caps the PIN entries a report keeps; counts stay exact
+	rescanReportMaxPins = 100000
+	// rescanReportsKept is how many finished rescan reports stay downloadable
+	rescanReportsKept = 10
+)
+
+// ErrRescanReportNotFound is returned for a task ID without a kept report
+var ErrRescanReportNotFound = errors.New("rescan report not found")
+
+// RescanPinResult is the outcome of one PIN during a rescan
+type RescanPinResult struct {
+	Height  int64  `json:"height"`
+	PinID   string `json:"pin_id"`
+	Path    string `json:"path"`
+	Outcome string `json:"outcome"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// RescanBlockResult is the outcome of one block during a rescan
+type RescanBlockResult struct {
+	Height  int64  `json:"height"`
+	Pins    int64  `json:"pins"`
+	Indexed int64  `json:"indexed"`
+	Failed  int64  `json:"failed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RescanCounts sums the outcomes of a rescan
+type RescanCounts struct {
+	IndexedPins   int64 `json:"indexed_pins"`
+	DuplicatePins int64 `json:"duplicate_pins"`
+	SkippedPins   int64 `json:"skipped_pins"`
+	RejectedPins  int64 `json:"rejected_pins"`
+	FailedPins    int64 `json:"failed_pins"`
+	FailedBlocks  int64 `json:"failed_blocks"`
+}
+
+// RescanReport is the downloadable per-block and per-PIN result of a rescan
+type RescanReport struct {
+	TaskID      string              `json:"task_id"`
+	Chain       string              `json:"chain"`
+	StartHeight int64               `json:"start_height"`
+	EndHeight   int64               `json:"end_height"`
+	GeneratedAt int64               `json:"generated_at"`
+	Counts      RescanCounts        `json:"counts"`
+	Truncated   bool                `json:"truncated"` // PIN entries beyond the cap were dropped
+	Blocks      []RescanBlockResult `json:"blocks"`
+	Pins        []RescanPinResult   `json:"pins"`
+}
+
+// rescanReport records outcomes while a rescan runs. A nil report records
+// nothing, so live indexing shares the same code path.
+type rescanReport struct {
+	mu      sync.Mutex
+	report  RescanReport
+	current RescanBlockResult // tallies of the block being scanned
+}
+
+func newRescanReport(taskID, chain string, startHeight, endHeight int64) *rescanReport {
+	return &rescanReport{report: RescanReport{
+		TaskID:      taskID,
+		Chain:       chain,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		Blocks:      []RescanBlockResult{},
+		Pins:        []RescanPinResult{},
+	}}
+}
+
+// pin records the outcome of one PIN
+func (r *rescanReport) pin(height int64, metaData *indexer.MetaIDData, outcome, reason string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := &r.report.Counts
+	switch outcome {
+	case PinOutcomeIndexed:
+		counts.IndexedPins++
+		r.current.Indexed++
+	case PinOutcomeDuplicate:
+		counts.DuplicatePins++
+	case PinOutcomeSkipped:
+		counts.SkippedPins++
+	case PinOutcomeRejected:
+		counts.RejectedPins++
+	case PinOutcomeFailed:
+		counts.FailedPins++
+		r.current.Failed++
+	}
+	r.current.Pins++
+
+	if len(r.report.Pins) >= rescanReportMaxPins {
+		r.report.Truncated = true
+		return
+	}
+	r.report.Pins = append(r.report.Pins, RescanPinResult{
+		Height:  height,
+		PinID:   metaData.PinID,
+		Path:    metaData.Path,
+		Outcome: outcome,
+		Reason:  reason,
+	})
+}
+
+// block closes the tallies of a scanned block; err is the scan error, if any
+func (r *rescanReport) block(height int64, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := r.current
+	result.Height = height
+	if err != nil {
+		result.Error = err.Error()
+		r.report.Counts.FailedBlocks++
+	}
+	r.report.Blocks = append(r.report.Blocks, result)
+	r.current = RescanBlockResult{}
+}
+
+// counts returns the outcome totals so far
+func (r *rescanReport) counts() RescanCounts {
+	if r == nil {
+		return RescanCounts{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.report.Counts
+}
+
+// snapshot returns a copy of the report as recorded so far
+func (r *rescanReport) snapshot() *RescanReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := r.report
+	out.GeneratedAt = time.Now().Unix()
+	out.Blocks = append([]RescanBlockResult(nil), r.report.Blocks...)
+	out.Pins = append([]RescanPinResult(nil), r.report.Pins...)
+	return &out
+}
+
+// CSV renders the PIN entries of the report, one row per PIN. Failed blocks
+// appear as rows with outcome "block_failed" and no PIN ID.
+func (r *RescanReport) CSV() []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"height", "pin_id", "path", "outcome", "reason"})
+	for _, b := range r.Blocks {
+		if b.Error != "" {
+			_ = w.Write([]string{strconv.FormatInt(b.Height, 10), "", "", "block_failed", b.Error})
+		}
+	}
+	for _, p := range r.Pins {
+		_ = w.Write([]string{strconv.FormatInt(p.Height, 10), p.PinID, p.Path, p.Outcome, p.Reason})
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// keepRescanReport stores a task's report, dropping the oldest beyond
+// rescanReportsKept. Callers hold rescanMu.
+func (s *IndexerService) keepRescanReport(taskID string, report *rescanReport) {
+	if s.rescanReports == nil {
+		s.rescanReports = make(map[string]*rescanReport)
+	}
+	s.rescanReports[taskID] = report
+	s.rescanReportOrder = append(s.rescanReportOrder, taskID)
+	for len(s.rescanReportOrder) > rescanReportsKept {
+		delete(s.rescanReports, s.rescanReportOrder[0])
+		s.rescanReportOrder = s.rescanReportOrder[1:]
+	}
+}
+
+// GetRescanReport returns the report of a rescan task (running or one of the
+// last finished ones)
+func (s *IndexerService) GetRescanReport(taskID string) (*RescanReport, error) {
+	s.rescanMu.Lock()
+	report := s.rescanReports[taskID]
+	s.rescanMu.Unlock()
+
+	if report == nil {
+		return nil, ErrRescanReportNotFound
+	}
+	return report.snapshot(), nil
+}
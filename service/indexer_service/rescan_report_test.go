@@ -0,0 +1,59 @@
+package indexer_service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"meta-file-system/indexer"
+)
+
+func TestRescanReportCountsOutcomes(t *testing.T) {
+	report := newRescanReport("rescan_mvc_10_11_1", "mvc", 10, 11)
+	report.pin(10, &indexer.MetaIDData{PinID: "a1i0", Path: "/file/a"}, PinOutcomeIndexed, "")
+	report.pin(10, &indexer.MetaIDData{PinID: "a2i0", Path: "/file/b"}, PinOutcomeDuplicate, "")
+	report.pin(10, &indexer.MetaIDData{PinID: "a3i0", Path: "/file/c"}, PinOutcomeFailed, "storage unavailable")
+	report.block(10, nil)
+	report.block(11, errors.New("block not found"))
+
+	counts := report.counts()
+	want := RescanCounts{IndexedPins: 1, DuplicatePins: 1, FailedPins: 1, FailedBlocks: 1}
+	if counts != want {
+		t.Errorf("counts = %+v, want %+v", counts, want)
+	}
+
+	snap := report.snapshot()
+	if len(snap.Blocks) != 2 || snap.Blocks[0].Pins != 3 || snap.Blocks[0].Failed != 1 || snap.Blocks[1].Pins != 0 || snap.Blocks[1].Error == "" {
+		t.Errorf("blocks = %+v", snap.Blocks)
+	}
+
+	csv := string(snap.CSV())
+	for _, row := range []string{"11,,,block_failed,block not found", "10,a3i0,/file/c,failed,storage unavailable"} {
+		if !strings.Contains(csv, row+"\n") {
+			t.Errorf("CSV lacks row %q:\n%s", row, csv)
+		}
+	}
+}
+
+func TestNilRescanReportRecordsNothing(t *testing.T) {
+	var report *rescanReport
+	report.pin(1, &indexer.MetaIDData{PinID: "a1i0"}, PinOutcomeFailed, "x")
+	report.block(1, errors.New("x"))
+	if report.counts() != (RescanCounts{}) {
+		t.Error("nil report counted outcomes")
+	}
+}
+
+func TestKeepRescanReportDropsOldest(t *testing.T) {
+	s := &IndexerService{}
+	for i := 0; i <= rescanReportsKept; i++ {
+		taskID := string(rune('a' + i))
+		s.keepRescanReport(taskID, newRescanReport(taskID, "mvc", 1, 1))
+	}
+	if _, err := s.GetRescanReport("a"); !errors.Is(err, ErrRescanReportNotFound) {
+		t.Errorf("oldest report kept: %v", err)
+	}
+	if _, err := s.GetRescanReport("b"); err != nil {
+		t.Errorf("recent report dropped: %v", err)
+	}
+}
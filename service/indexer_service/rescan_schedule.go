@@ -0,0 +1,129 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/service/common_service/cron"
+)
+
+// rescanJob a recurring rescan (indexer.rescan_schedule.jobs)
+type rescanJob struct {
+	conf.IndexerRescanJobConfig
+	schedule *cron.Schedule
+	next     time.Time // When the job next comes due
+	due      bool      // Came due and has not started yet
+}
+
+// rescanScheduler starts the rescan jobs when they come due
+type rescanScheduler struct {
+	jobs       []*rescanJob
+	syncHeight func(chain string) (int64, error)
+	start      func(chain string, startHeight, endHeight int64) (string, error)
+}
+
+// newRescanJobs validates the configured jobs and schedules their first runs after now
+func newRescanJobs(cfgs []conf.IndexerRescanJobConfig, now time.Time) ([]*rescanJob, error) {
+	var jobs []*rescanJob
+	for i, cfg := range cfgs {
+		schedule, err := cron.Parse(cfg.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("indexer.rescan_schedule.jobs[%d]: %w", i, err)
+		}
+		cfg.Chain = strings.ToLower(cfg.Chain)
+		if cfg.Chain != "btc" && cfg.Chain != "mvc" {
+			return nil, fmt.Errorf("indexer.rescan_schedule.jobs[%d]: unsupported chain %q, only 'btc' and 'mvc' are supported", i, cfg.Chain)
+		}
+		if cfg.Name == "" {
+			cfg.Name = cfg.Chain + " " + cfg.Cron
+		}
+		next := schedule.Next(now)
+		if next.IsZero() {
+			return nil, fmt.Errorf("indexer.rescan_schedule.jobs[%d]: cron expression %q never fires", i, cfg.Cron)
+		}
+		jobs = append(jobs, &rescanJob{IndexerRescanJobConfig: cfg, schedule: schedule, next: next})
+	}
+	return jobs, nil
+}
+
+// tick starts the jobs due at now. A job stays due while another rescan is
+// running and starts on a later tick; occurrences that pass meanwhile are
+// not queued again.
+func (r *rescanScheduler) tick(now time.Time) {
+	for _, job := range r.jobs {
+		if !job.due && !job.next.IsZero() && !now.Before(job.next) {
+			job.due = true
+			job.next = job.schedule.Next(now)
+		}
+		if !job.due {
+			continue
+		}
+
+		height, err := r.syncHeight(job.Chain)
+		if err != nil {
+			log.Printf("[RescanSchedule] Job %s: failed to read the %s sync height, retrying: %v", job.Name, job.Chain, err)
+			continue
+		}
+		if height <= 0 {
+			log.Printf("[RescanSchedule] Job %s: %s has not synced any block yet, skipped", job.Name, job.Chain)
+			job.due = false
+			continue
+		}
+		startHeight := height - job.Blocks + 1
+		if startHeight < 1 {
+			startHeight = 1
+		}
+
+		taskID, err := r.start(job.Chain, startHeight, height)
+		switch {
+		case errors.Is(err, ErrRescanRunning):
+			// Starts once the running rescan is finished
+		case errors.Is(err, ErrShuttingDown):
+			return
+		case err != nil:
+			log.Printf("[RescanSchedule] Job %s: failed to start rescan of %s %d to %d: %v", job.Name, job.Chain, startHeight, height, err)
+			job.due = false
+		default:
+			log.Printf("[RescanSchedule] Job %s: started %s (%s height %d to %d), next run at %s",
+				job.Name, taskID, job.Chain, startHeight, height, job.next.Format(time.RFC3339))
+			job.due = false
+		}
+	}
+}
+
+// rescanScheduleTick how often due jobs are checked
+const rescanScheduleTick = 30 * time.Second
+
+// runRescanSchedule starts the scheduled rescans until stop is closed
+func (s *IndexerService) runRescanSchedule(jobs []*rescanJob, stop <-chan struct{}) {
+	r := &rescanScheduler{
+		jobs: jobs,
+		syncHeight: func(chain string) (int64, error) {
+			status, err := s.syncStatusDAO.GetByChainName(chain)
+			if err != nil || status == nil {
+				return 0, err
+			}
+			return status.CurrentSyncHeight, nil
+		},
+		start: s.RescanBlocksAsync,
+	}
+	for _, job := range jobs {
+		log.Printf("[RescanSchedule] Job %s: last %d %s blocks at %q, first run at %s",
+			job.Name, job.Blocks, job.Chain, job.Cron, job.next.Format(time.RFC3339))
+	}
+
+	ticker := time.NewTicker(rescanScheduleTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			r.tick(now)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+func TestNewRescanJobs(t *testing.T) {
+	now := time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC)
+	jobs, err := newRescanJobs([]conf.IndexerRescanJobConfig{{Cron: "0 3 * * *", Chain: "MVC", Blocks: 144}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job := jobs[0]; job.Chain != "mvc" || job.Name != "mvc 0 3 * * *" || !job.next.Equal(now.Add(time.Hour)) {
+		t.Errorf("job = %+v, next %s", job.IndexerRescanJobConfig, job.next)
+	}
+
+	for _, cfg := range []conf.IndexerRescanJobConfig{
+		{Cron: "0 3 * *", Chain: "mvc"},
+		{Cron: "0 3 * * *", Chain: "doge"},
+		{Cron: "0 0 31 2 *", Chain: "btc"},
+	} {
+		if _, err := newRescanJobs([]conf.IndexerRescanJobConfig{cfg}, now); err == nil {
+			t.Errorf("job %+v accepted", cfg)
+		}
+	}
+}
+
+func TestRescanSchedulerTick(t *testing.T) {
+	now := time.Date(2026, 10, 16, 2, 59, 0, 0, time.UTC)
+	jobs, err := newRescanJobs([]conf.IndexerRescanJobConfig{{Name: "nightly", Cron: "0 3 * * *", Chain: "mvc", Blocks: 144}}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var started []string
+	running := false
+	r := &rescanScheduler{
+		jobs:       jobs,
+		syncHeight: func(chain string) (int64, error) { return 1000, nil },
+		start: func(chain string, startHeight, endHeight int64) (string, error) {
+			if running {
+				return "", fmt.Errorf("%w: manual", ErrRescanRunning)
+			}
+			started = append(started, fmt.Sprintf("%s %d-%d", chain, startHeight, endHeight))
+			return "task", nil
+		},
+	}
+
+	r.tick(now.Add(30 * time.Second))
+	if len(started) != 0 {
+		t.Fatalf("started before due: %v", started)
+	}
+
+	// Due while a manual rescan runs: starts once it is finished, only once
+	running = true
+	r.tick(now.Add(time.Minute))
+	r.tick(now.Add(2 * time.Minute))
+	if len(started) != 0 || !jobs[0].due {
+		t.Fatalf("started while busy: %v, due %v", started, jobs[0].due)
+	}
+	running = false
+	r.tick(now.Add(3 * time.Minute))
+	r.tick(now.Add(4 * time.Minute))
+	if len(started) != 1 || started[0] != "mvc 857-1000" {
+		t.Fatalf("started = %v, want one rescan of mvc 857-1000", started)
+	}
+	if want := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC); !jobs[0].next.Equal(want) {
+		t.Errorf("next = %s, want %s", jobs[0].next, want)
+	}
+
+	// Start errors other than a running rescan drop the run
+	r.start = func(chain string, startHeight, endHeight int64) (string, error) {
+		return "", errors.New("scanner not initialized")
+	}
+	r.tick(jobs[0].next)
+	if jobs[0].due {
+		t.Error("failed run still due")
+	}
+}
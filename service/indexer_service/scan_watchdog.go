@@ -0,0 +1,99 @@
+package indexer_service
+
+import (
+	"log"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/notify"
+)
+
+// scanWatchdogInterval how often the sync height is compared with the node tip
+const scanWatchdogInterval = time.Minute
+
+// scanProgress last sync height of a chain and when it last moved
+type scanProgress struct {
+	height  int64
+	since   time.Time
+	alerted bool
+}
+
+// scanWatchdog detects chains whose sync height stopped advancing while the
+// node is ahead (notify.scanner_stall_minutes)
+type scanWatchdog struct {
+	stallAfter time.Duration
+	chains     map[string]*scanProgress
+}
+
+func newScanWatchdog(stallAfter time.Duration) *scanWatchdog {
+	return &scanWatchdog{stallAfter: stallAfter, chains: make(map[string]*scanProgress)}
+}
+
+// observe records the sync height of chain against the node tip at now and
+// reports whether the chain has just been stalled for stallAfter (once per stall)
+func (w *scanWatchdog) observe(chain string, height, tip int64, now time.Time) bool {
+	p, ok := w.chains[chain]
+	if !ok || p.height != height || height >= tip {
+		// Moving or caught up: restart the clock
+		w.chains[chain] = &scanProgress{height: height, since: now}
+		return false
+	}
+	if p.alerted || now.Sub(p.since) < w.stallAfter {
+		return false
+	}
+	p.alerted = true
+	return true
+}
+
+// watchScanner alerts on stalled chains until stop is closed
+func (s *IndexerService) watchScanner(stop <-chan struct{}) {
+	stallAfter := time.Duration(conf.Cfg.Notify.ScannerStallMinutes) * time.Minute
+	watchdog := newScanWatchdog(stallAfter)
+	ticker := time.NewTicker(scanWatchdogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		for chainName, scanner := range s.watchedScanners() {
+			if s.chainPauses.paused(chainName) {
+				// Not a stall; the clock restarts on resume
+				delete(watchdog.chains, chainName)
+				continue
+			}
+			status, err := s.syncStatusDAO.GetByChainName(chainName)
+			if err != nil || status == nil {
+				continue
+			}
+			tip, err := scanner.GetBlockCount()
+			if err != nil {
+				log.Printf("[%s] Scanner watchdog: failed to get block count: %v", chainName, err)
+				continue
+			}
+			if watchdog.observe(chainName, status.CurrentSyncHeight, tip, time.Now()) {
+				notify.Alertf(notify.EventScannerStalled, "%s scanner stalled at height %d for %v; node tip is %d",
+					chainName, status.CurrentSyncHeight, stallAfter, tip)
+			}
+		}
+	}
+}
+
+// watchedScanners returns the scanner of each indexed chain
+func (s *IndexerService) watchedScanners() map[string]*indexer.BlockScanner {
+	scanners := make(map[string]*indexer.BlockScanner)
+	if s.isMultiChain && s.coordinator != nil {
+		for chainName := range s.coordinator.GetChainProgress() {
+			if scanner := s.coordinator.GetScanner(chainName); scanner != nil {
+				scanners[chainName] = scanner
+			}
+		}
+	} else if s.scanner != nil {
+		scanners[string(s.chainType)] = s.scanner
+	}
+	return scanners
+}
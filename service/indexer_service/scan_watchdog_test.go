@@ -0,0 +1,33 @@
+package indexer_service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScanWatchdogObserve(t *testing.T) {
+	w := newScanWatchdog(10 * time.Minute)
+	start := time.Now()
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	steps := []struct {
+		height, tip int64
+		minute      int
+		stalled     bool
+	}{
+		{100, 120, 0, false},  // first observation
+		{100, 120, 9, false},  // not long enough
+		{100, 125, 10, true},  // stalled for 10 minutes
+		{100, 130, 30, false}, // alerted once per stall
+		{101, 130, 31, false}, // moving again
+		{101, 130, 40, false},
+		{101, 130, 41, true},
+		{130, 130, 50, false}, // caught up
+		{130, 130, 90, false}, // idle at the tip is not a stall
+	}
+	for i, step := range steps {
+		if got := w.observe("mvc", step.height, step.tip, at(step.minute)); got != step.stalled {
+			t.Errorf("step %d (height %d, tip %d, minute %d): stalled = %v, want %v", i, step.height, step.tip, step.minute, got, step.stalled)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package indexer_service
+
+import (
+	"context"
+	"log"
+
+	"meta-file-system/shutdown"
+)
+
+// runBackground starts a background job of Start; Shutdown waits for it
+// after closing stop
+func (s *IndexerService) runBackground(job func(stop <-chan struct{}), stop chan struct{}) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		job(stop)
+	}()
+}
+
+// Shutdown stops the indexer for a service shutdown. New rescans are refused,
+// the running rescan is cancelled after its current block, the scanners stop
+// after theirs and the background jobs after their current round. Returns
+// once all of them stopped, or with ctx's error when ctx is done first.
+func (s *IndexerService) Shutdown(ctx context.Context) error {
+	s.stopping.Store(true)
+	s.interruptRescan()
+	return shutdown.Within(ctx, func() {
+		s.Stop()
+		s.rescans.Wait()
+		s.background.Wait()
+		if !s.isMultiChain && s.scanner != nil {
+			s.scanner.Wait()
+		}
+	})
+}
+
+// interruptRescan cancels the running rescan, marking it interrupted so its
+// completion alert names the blocks left to rescan
+func (s *IndexerService) interruptRescan() {
+	s.rescanMu.Lock()
+	defer s.rescanMu.Unlock()
+
+	task := s.currentRescanTask
+	if task == nil || task.Status != RescanStatusRunning || task.CancelFunc == nil {
+		return
+	}
+	task.mu.Lock()
+	task.interrupted = true
+	task.mu.Unlock()
+	task.CancelFunc()
+	log.Printf("[Rescan] Interrupting task %s for shutdown", task.TaskID)
+}
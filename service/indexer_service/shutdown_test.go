@@ -0,0 +1,48 @@
+package indexer_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdownInterruptsRescan(t *testing.T) {
+	s := &IndexerService{}
+	rescanCtx, cancel := context.WithCancel(context.Background())
+	task := &RescanTask{TaskID: "rescan_mvc_1_10", Status: RescanStatusRunning, CancelFunc: cancel}
+	s.currentRescanTask = task
+
+	// The rescan goroutine returns after its current block once cancelled
+	s.rescans.Add(1)
+	go func() {
+		defer s.rescans.Done()
+		<-rescanCtx.Done()
+	}()
+
+	ctx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stop()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if !task.interrupted {
+		t.Error("rescan not marked interrupted")
+	}
+
+	if _, err := s.RescanBlocksAsync("mvc", 1, 10); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("rescan during shutdown: err = %v", err)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	s := &IndexerService{}
+	block := make(chan struct{})
+	defer close(block)
+	s.runBackground(func(<-chan struct{}) { <-block }, make(chan struct{}))
+
+	ctx, stop := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer stop()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stuck job: err = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package indexer_service
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrSiteNotFound is returned when a site path matches no manifest file
+var ErrSiteNotFound = errors.New("site path not found")
+
+const (
+	// maxSiteManifestBytes largest manifest file accepted
+	maxSiteManifestBytes = 1 << 20
+	// maxCachedSiteManifests parsed manifests kept in memory (manifests are
+	// immutable PINs, so entries never go stale)
+	maxCachedSiteManifests = 256
+)
+
+// SiteContent a site file ready to serve
+type SiteContent struct {
+	metaid_protocols.SiteFile
+	File        *model.IndexerFile
+	Content     []byte
+	ContentType string
+}
+
+// GetSiteManifest loads and parses the site manifest inscribed as manifestPinID
+func (s *IndexerFileService) GetSiteManifest(manifestPinID string) (*metaid_protocols.SiteManifest, error) {
+	manifestPinID = strings.ToLower(manifestPinID)
+	s.siteMu.Lock()
+	manifest, ok := s.sites[manifestPinID]
+	s.siteMu.Unlock()
+	if ok {
+		return manifest, nil
+	}
+
+	file, err := s.GetFileByPinID(manifestPinID)
+	if err != nil {
+		return nil, err
+	}
+	if file.FileSize > maxSiteManifestBytes {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d", metaid_protocols.ErrInvalidSiteManifest, file.FileSize, maxSiteManifestBytes)
+	}
+	content, err := s.readFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest content: %w", err)
+	}
+	manifest, err = metaid_protocols.ParseSiteManifest(content)
+	if err != nil {
+		return nil, err
+	}
+
+	s.siteMu.Lock()
+	if s.sites == nil || len(s.sites) >= maxCachedSiteManifests {
+		s.sites = make(map[string]*metaid_protocols.SiteManifest)
+	}
+	s.sites[manifestPinID] = manifest
+	s.siteMu.Unlock()
+	return manifest, nil
+}
+
+// GetSiteFile resolves reqPath against the site of manifestPinID and reads
+// the file it maps to. isDir reports a directory requested without its
+// trailing slash (the caller redirects so relative links resolve).
+func (s *IndexerFileService) GetSiteFile(manifestPinID, reqPath string) (*SiteContent, bool, error) {
+	manifest, err := s.GetSiteManifest(manifestPinID)
+	if err != nil {
+		return nil, false, err
+	}
+	siteFile, isDir, ok := manifest.Resolve(reqPath)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: %s", ErrSiteNotFound, reqPath)
+	}
+	if isDir && !strings.HasSuffix(reqPath, "/") {
+		return &SiteContent{SiteFile: siteFile}, true, nil
+	}
+
+	file, err := s.GetFileByPinID(siteFile.PinID)
+	if err != nil {
+		return nil, false, fmt.Errorf("site file %s (%s): %w", siteFile.Path, siteFile.PinID, err)
+	}
+	content, err := s.readFile(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get site file content: %w", err)
+	}
+	return &SiteContent{
+		SiteFile:    siteFile,
+		File:        file,
+		Content:     content,
+		ContentType: siteContentType(siteFile.Path, file.ContentType),
+	}, false, nil
+}
+
+// siteContentType picks the Content-Type of a site file from its manifest
+// path, as browsers need text/html, text/css or text/javascript whatever the
+// file was inscribed with; the indexed type is the fallback.
+func siteContentType(sitePath, indexedType string) string {
+	if byExt := mime.TypeByExtension(path.Ext(sitePath)); byExt != "" {
+		return byExt
+	}
+	return contenttype.Header(indexedType)
+}
@@ -0,0 +1,53 @@
+package indexer_service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSiteContentType(t *testing.T) {
+	tests := []struct {
+		path, indexed, want string
+	}{
+		{"index.html", "application/octet-stream", "text/html"},
+		{"assets/app.js", "", "javascript"},
+		{"style.css", "text/plain", "text/css"},
+		{"logo.svg", "", "image/svg+xml"},
+		{"LICENSE", "text/plain;binary", "text/plain"},
+		{"data", "", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := siteContentType(tt.path, tt.indexed); !strings.Contains(got, tt.want) {
+			t.Errorf("siteContentType(%q, %q) = %q, want %q", tt.path, tt.indexed, got, tt.want)
+		}
+	}
+}
+
+func TestSiteRefusesProvisionalFiles(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	seedProvisionalPolicy(t)
+	indexPinID := strings.Repeat("a", 64) + "i0"
+	pagePinID := strings.Repeat("b", 64) + "i0"
+	manifestPinID := strings.Repeat("c", 64) + "i0"
+	seedContentFile(t, s, stor, indexPinID, "index.html", 100, "<h1>home</h1>")
+	seedContentFile(t, s, stor, pagePinID, "page.html", 199, "<h1>new</h1>")
+	seedContentFile(t, s, stor, manifestPinID, "site.json", 100,
+		`{"version":1,"files":{"/index.html":"`+indexPinID+`","/page.html":"`+pagePinID+`"}}`)
+	fileService := NewIndexerFileService(stor)
+
+	site, _, err := fileService.GetSiteFile(manifestPinID, "/")
+	if err != nil || string(site.Content) != "<h1>home</h1>" {
+		t.Fatalf("confirmed page = %+v, %v", site, err)
+	}
+	if _, _, err := fileService.GetSiteFile(manifestPinID, "/page.html"); !errors.Is(err, ErrProvisionalContent) {
+		t.Errorf("provisional page: err = %v, want ErrProvisionalContent", err)
+	}
+
+	// A provisional manifest refuses the whole site
+	newManifestPinID := strings.Repeat("d", 64) + "i0"
+	seedContentFile(t, s, stor, newManifestPinID, "site.json", 200, `{"version":1,"files":{"/index.html":"`+indexPinID+`"}}`)
+	if _, _, err := fileService.GetSiteFile(newManifestPinID, "/"); !errors.Is(err, ErrProvisionalContent) {
+		t.Errorf("provisional manifest: err = %v, want ErrProvisionalContent", err)
+	}
+}
@@ -0,0 +1,205 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/storage"
+)
+
+// StorageLayoutMigrator relocates indexed file and chunk blobs to another
+// storage.Layout. Each blob is copied to its new path, its record is updated
+// to the new path and layout in a single write, and only then is the old blob
+// deleted, so a record always points at a blob that exists. Old blobs still
+// used by other records are kept (storage.SafeDelete). An interrupted run
+// leaves at most an orphaned copy and can simply be run again.
+type StorageLayoutMigrator struct {
+	storage             storage.Storage
+	indexerFileDAO      *dao.IndexerFileDAO
+	indexerFileChunkDAO *dao.IndexerFileChunkDAO
+}
+
+// StorageLayoutMigrateResult outcome of a migration run
+type StorageLayoutMigrateResult struct {
+	FilesMoved  int // File blobs relocated
+	ChunksMoved int // Chunk blobs relocated
+	Skipped     int // Records already in the target layout or without a blob
+	Failed      int // Records left in their old layout (see log)
+}
+
+// NewStorageLayoutMigrator create storage layout migrator
+func NewStorageLayoutMigrator(stor storage.Storage) *StorageLayoutMigrator {
+	return &StorageLayoutMigrator{
+		storage:             stor,
+		indexerFileDAO:      dao.NewIndexerFileDAO(),
+		indexerFileChunkDAO: dao.NewIndexerFileChunkDAO(),
+	}
+}
+
+// Migrate moves every file and chunk blob not yet at its layout version path
+// to it. With LayoutTemplate, blobs written under a previous template are
+// moved too. Records that fail are logged and counted; the run continues with
+// the rest.
+func (m *StorageLayoutMigrator) Migrate(version int) (*StorageLayoutMigrateResult, error) {
+	layout, err := storage.LayoutFor(version)
+	if err != nil {
+		return nil, err
+	}
+	result := &StorageLayoutMigrateResult{}
+	refs := blobRefs{}
+	log.Printf("[StorageLayout] Migrating blobs to layout v%d...", layout.Version())
+
+	// Collect first and write afterwards so records are not rewritten while
+	// the iterator over them is still open
+	var files []*model.IndexerFile
+	err = m.indexerFileDAO.Iterate(func(file *model.IndexerFile) error {
+		refs.add(file.StoragePath)
+		if file.StoragePath == "" || (storage.LayoutVersionOf(file.StorageLayout) == layout.Version() && file.StoragePath == layout.FilePath(filePathVars(file))) {
+			result.Skipped++
+			return nil
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to iterate files: %w", err)
+	}
+	var chunks []*model.IndexerFileChunk
+	err = m.indexerFileChunkDAO.Iterate(func(chunk *model.IndexerFileChunk) error {
+		refs.add(chunk.StoragePath)
+		if chunk.StoragePath == "" || (storage.LayoutVersionOf(chunk.StorageLayout) == layout.Version() && chunkPlaced(layout, chunk)) {
+			result.Skipped++
+			return nil
+		}
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to iterate chunks: %w", err)
+	}
+
+	for _, file := range files {
+		newPath := layout.FilePath(filePathVars(file))
+		err := m.relocate(refs, file.StoragePath, newPath, file.StorageReceipt, func(receipt string) error {
+			file.StoragePath = newPath
+			file.StorageLayout = layout.Version()
+			file.StorageReceipt = receipt
+			return m.indexerFileDAO.UpdateFields(file)
+		})
+		if err != nil {
+			log.Printf("[StorageLayout] File PIN=%s not migrated: %v", file.PinID, err)
+			result.Failed++
+			continue
+		}
+		result.FilesMoved++
+		if result.FilesMoved%1000 == 0 {
+			log.Printf("[StorageLayout] %d files migrated...", result.FilesMoved)
+		}
+	}
+
+	for _, chunk := range chunks {
+		newPath := layout.ChunkPath(chunkPathVars(chunk))
+		err := m.relocate(refs, chunk.StoragePath, newPath, chunk.StorageReceipt, func(receipt string) error {
+			chunk.StoragePath = newPath
+			chunk.StorageLayout = layout.Version()
+			chunk.StorageReceipt = receipt
+			return m.indexerFileChunkDAO.Update(chunk)
+		})
+		if err != nil {
+			log.Printf("[StorageLayout] Chunk PIN=%s not migrated: %v", chunk.PinID, err)
+			result.Failed++
+			continue
+		}
+		result.ChunksMoved++
+		if result.ChunksMoved%1000 == 0 {
+			log.Printf("[StorageLayout] %d chunks migrated...", result.ChunksMoved)
+		}
+	}
+
+	log.Printf("[StorageLayout] Migration to layout v%d completed: files=%d, chunks=%d, skipped=%d, failed=%d",
+		layout.Version(), result.FilesMoved, result.ChunksMoved, result.Skipped, result.Failed)
+	return result, nil
+}
+
+// filePathVars the layout variables of an indexed file
+func filePathVars(file *model.IndexerFile) storage.PathVars {
+	return storage.PathVars{
+		ChainName: file.ChainName,
+		PinID:     file.PinID,
+		Extension: file.FileExtension,
+		MetaID:    file.CreatorMetaId,
+		Timestamp: file.Timestamp,
+	}
+}
+
+// chunkPathVars the layout variables of an indexed chunk. Chunk records do not
+// keep the PIN timestamp, so dates come from when the chunk was indexed.
+func chunkPathVars(chunk *model.IndexerFileChunk) storage.PathVars {
+	return storage.PathVars{
+		ChainName: chunk.ChainName,
+		PinID:     chunk.PinID,
+		TxID:      chunk.TxID,
+		Timestamp: chunk.CreatedAt.UnixMilli(),
+	}
+}
+
+// chunkPlaced reports whether a chunk recorded in layout's version is at its
+// path. Template paths of chunks may use the PIN date, which chunk records do
+// not keep, so chunks already in LayoutTemplate stay where they are.
+func chunkPlaced(layout storage.Layout, chunk *model.IndexerFileChunk) bool {
+	return layout.Version() == storage.LayoutTemplate || chunk.StoragePath == layout.ChunkPath(chunkPathVars(chunk))
+}
+
+// blobRefs counts the file and chunk records using each storage path
+type blobRefs map[string]int64
+
+func (r blobRefs) add(path string) {
+	if path != "" {
+		r[path]++
+	}
+}
+
+// move records that one record was repointed from oldPath to newPath
+func (r blobRefs) move(oldPath, newPath string) {
+	if r[oldPath]--; r[oldPath] <= 0 {
+		delete(r, oldPath)
+	}
+	r.add(newPath)
+}
+
+// guard a storage.DeleteGuard keeping blobs that records still use
+func (r blobRefs) guard() storage.DeleteGuard {
+	return storage.DeleteGuard{
+		Refs: func(key string) (int64, error) { return r[key], nil },
+	}
+}
+
+// relocate copies oldPath to newPath, runs commit with the storage receipt
+// of the copy to repoint the record and removes oldPath unless another record
+// still uses it. If commit fails the copy is removed and oldPath is kept.
+func (m *StorageLayoutMigrator) relocate(refs blobRefs, oldPath, newPath, oldReceipt string, commit func(receipt string) error) error {
+	if oldPath == newPath {
+		return commit(oldReceipt)
+	}
+	data, err := m.storage.Get(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", oldPath, err)
+	}
+	receipt, err := storage.SaveWithReceipt(m.storage, newPath, data)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", newPath, err)
+	}
+	if err := commit(receipt); err != nil {
+		if delErr := storage.SafeDelete(m.storage, newPath, refs.guard()); delErr != nil {
+			log.Printf("[StorageLayout] Failed to remove copy %s: %v", newPath, delErr)
+		}
+		return fmt.Errorf("failed to update record: %w", err)
+	}
+	refs.move(oldPath, newPath)
+	if err := storage.SafeDelete(m.storage, oldPath, refs.guard()); err != nil {
+		log.Printf("[StorageLayout] Record moved to %s but old blob %s was not removed: %v", newPath, oldPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/storage"
+)
+
+func TestLayoutPaths(t *testing.T) {
+	tests := []struct {
+		version   int
+		wantFile  string
+		wantChunk string
+	}{
+		{0, "indexer/mvc/abcdi0.png", "indexer/chunk/mvc/abcd/abcdi0"},
+		{storage.LayoutV1, "indexer/mvc/abcdi0.png", "indexer/chunk/mvc/abcd/abcdi0"},
+		{storage.LayoutV2, "indexer/v2/mvc/ab/abcdi0.png", "indexer/v2/chunk/mvc/ab/abcdi0"},
+	}
+	for _, tt := range tests {
+		layout, err := storage.LayoutFor(tt.version)
+		if err != nil {
+			t.Fatalf("LayoutFor(%d): %v", tt.version, err)
+		}
+		if got := layout.FilePath(storage.PathVars{ChainName: "mvc", PinID: "abcdi0", Extension: ".png"}); got != tt.wantFile {
+			t.Errorf("v%d FilePath = %q, want %q", tt.version, got, tt.wantFile)
+		}
+		if got := layout.ChunkPath(storage.PathVars{ChainName: "mvc", TxID: "abcd", PinID: "abcdi0"}); got != tt.wantChunk {
+			t.Errorf("v%d ChunkPath = %q, want %q", tt.version, got, tt.wantChunk)
+		}
+	}
+	if _, err := storage.LayoutFor(99); err == nil {
+		t.Error("LayoutFor(99) should fail")
+	}
+}
+
+func TestStorageLayoutMigrator_RelocatesBlobsAndRecords(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	v1, _ := storage.LayoutFor(storage.LayoutV1)
+
+	// Two versions of one file; the latest PIN sorts first so the older one
+	// is updated last and must not replace it in the first-PIN indexes
+	const firstPinID, latestPinID = "bbbbfirsti0", "aaaalatesti0"
+	for _, f := range []struct {
+		pinID     string
+		timestamp int64
+		content   string
+	}{{firstPinID, 1000, "v1"}, {latestPinID, 2000, "v2"}} {
+		path := v1.FilePath(storage.PathVars{ChainName: "mvc", PinID: f.pinID, Extension: ".txt"})
+		if err := stor.Save(path, []byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.indexerFileDAO.Create(&model.IndexerFile{
+			FirstPinID:     firstPinID,
+			PinID:          f.pinID,
+			Path:           "/file/a.txt",
+			ChainName:      "mvc",
+			FileExtension:  ".txt",
+			Timestamp:      f.timestamp,
+			CreatorAddress: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+			StoragePath:    path,
+			Status:         model.StatusSuccess,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seedChunks(t, s, stor, "cccci0", []string{"AAA"})
+	if err := s.indexerFileDAO.Create(&model.IndexerFile{PinID: "ddddrejectedi0", ChainName: "mvc", Status: model.StatusRejected}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewStorageLayoutMigrator(stor).Migrate(storage.LayoutV2)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if result.FilesMoved != 2 || result.ChunksMoved != 1 || result.Skipped != 1 || result.Failed != 0 {
+		t.Fatalf("result = %+v, want 2 files, 1 chunk, 1 skipped", result)
+	}
+
+	v2, _ := storage.LayoutFor(storage.LayoutV2)
+	for pinID, content := range map[string]string{firstPinID: "v1", latestPinID: "v2"} {
+		file, err := s.indexerFileDAO.GetByPinID(pinID)
+		if err != nil || file == nil {
+			t.Fatalf("GetByPinID(%s): %v", pinID, err)
+		}
+		if want := v2.FilePath(storage.PathVars{ChainName: "mvc", PinID: pinID, Extension: ".txt"}); file.StoragePath != want || file.StorageLayout != storage.LayoutV2 {
+			t.Errorf("%s: StoragePath=%q StorageLayout=%d, want %q v2", pinID, file.StoragePath, file.StorageLayout, want)
+		}
+		if got, err := stor.Get(file.StoragePath); err != nil || string(got) != content {
+			t.Errorf("%s: blob = %q, %v; want %q", pinID, got, err, content)
+		}
+		if stor.Exists(v1.FilePath(storage.PathVars{ChainName: "mvc", PinID: pinID, Extension: ".txt"})) {
+			t.Errorf("%s: old blob was not removed", pinID)
+		}
+	}
+
+	latest, err := s.indexerFileDAO.GetLatestFileInfoByFirstPinID(firstPinID)
+	if err != nil || latest == nil || latest.PinID != latestPinID || latest.StorageLayout != storage.LayoutV2 {
+		t.Errorf("latest file info = %+v, %v; want %s in layout v2", latest, err, latestPinID)
+	}
+	byCreator, _, _, err := s.indexerFileDAO.GetByCreatorAddressWithCursor("1BoatSLRHtKNngkdXEeobR76b53LETtpyT", 0, 10)
+	if err != nil || len(byCreator) != 1 || byCreator[0].PinID != latestPinID || byCreator[0].StorageLayout != storage.LayoutV2 {
+		t.Errorf("creator listing = %+v, %v; want only %s in layout v2", byCreator, err, latestPinID)
+	}
+	// Records are rewritten in place: one extension index entry per PIN, no duplicates
+	byExt, _, err := s.indexerFileDAO.GetByExtensionWithCursor(".txt", "", 10)
+	if err != nil || len(byExt) != 2 {
+		t.Errorf("extension listing = %d entries, %v; want 2", len(byExt), err)
+	}
+	for _, f := range byExt {
+		if f.StorageLayout != storage.LayoutV2 {
+			t.Errorf("extension index entry %s still in layout %d", f.PinID, f.StorageLayout)
+		}
+	}
+	content, _, _, err := NewIndexerFileService(stor).GetFileContent(latestPinID)
+	if err != nil || string(content) != "v2" {
+		t.Errorf("GetFileContent = %q, %v; want v2", content, err)
+	}
+
+	chunk, err := s.indexerFileChunkDAO.GetByPinID("cccci0-chunk1")
+	if err != nil || chunk == nil {
+		t.Fatalf("chunk GetByPinID: %v", err)
+	}
+	if got, err := stor.Get(chunk.StoragePath); err != nil || string(got) != "AAA" || chunk.StorageLayout != storage.LayoutV2 {
+		t.Errorf("chunk StoragePath=%q StorageLayout=%d blob=%q err=%v", chunk.StoragePath, chunk.StorageLayout, got, err)
+	}
+
+	// Already migrated: a second run moves nothing
+	again, err := NewStorageLayoutMigrator(stor).Migrate(storage.LayoutV2)
+	if err != nil || again.FilesMoved != 0 || again.ChunksMoved != 0 || again.Skipped != 4 {
+		t.Errorf("second run = %+v, %v; want everything skipped", again, err)
+	}
+}
+
+func TestStorageLayoutMigrator_KeepsBlobsStillReferenced(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	v1, _ := storage.LayoutFor(storage.LayoutV1)
+
+	// Two records share one blob (same content indexed twice): moving the
+	// first must leave it in place for the second to be copied from
+	shared := v1.FilePath(storage.PathVars{ChainName: "mvc", PinID: "aaaai0", Extension: ".txt"})
+	if err := stor.Save(shared, []byte("same")); err != nil {
+		t.Fatal(err)
+	}
+	for _, pinID := range []string{"aaaai0", "bbbbi0"} {
+		if err := s.indexerFileDAO.Create(&model.IndexerFile{
+			FirstPinID:    pinID,
+			PinID:         pinID,
+			ChainName:     "mvc",
+			FileExtension: ".txt",
+			StoragePath:   shared,
+			Status:        model.StatusSuccess,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := NewStorageLayoutMigrator(stor).Migrate(storage.LayoutV2)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if result.FilesMoved != 2 || result.Failed != 0 {
+		t.Fatalf("result = %+v, want 2 files moved, none failed", result)
+	}
+	for _, pinID := range []string{"aaaai0", "bbbbi0"} {
+		file, err := s.indexerFileDAO.GetByPinID(pinID)
+		if err != nil || file == nil {
+			t.Fatalf("GetByPinID(%s): %v", pinID, err)
+		}
+		if got, err := stor.Get(file.StoragePath); err != nil || string(got) != "same" {
+			t.Errorf("%s: blob = %q, %v; want same", pinID, got, err)
+		}
+	}
+	if stor.Exists(shared) {
+		t.Error("shared blob was not removed once no record used it")
+	}
+}
@@ -0,0 +1,245 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/storage"
+)
+
+// StorageMigrationProgress state of the storage backend migration, as of the
+// last completed pass
+type StorageMigrationProgress struct {
+	Source      string
+	Target      string
+	Running     bool   // A pass is in progress
+	Passes      int    // Completed passes
+	Total       int64  // File and chunk records with a blob
+	Migrated    int64  // Records confirmed on Target
+	Failed      int64  // Records whose copy or verification failed
+	LastError   string // Last failure, if any
+	StartedAt   int64  // Unix seconds
+	LastPassAt  int64  // Unix seconds
+	CompletedAt int64  // Unix seconds since every record has been on Target, 0 while any is pending
+}
+
+// Pending records not yet confirmed on Target
+func (p *StorageMigrationProgress) Pending() int64 {
+	return p.Total - p.Migrated
+}
+
+// StorageMigrationJob copies indexed blobs from the Source to the Target of a
+// DualStorage in the background. A record's StorageType is flipped to the
+// target only after the copy on Target is read back with the same SHA256 as
+// Source. New blobs are dual-written meanwhile and picked up by the next pass.
+type StorageMigrationJob struct {
+	dual                *storage.DualStorage
+	indexerFileDAO      *dao.IndexerFileDAO
+	indexerFileChunkDAO *dao.IndexerFileChunkDAO
+	interval            time.Duration
+	stopChan            chan struct{}
+
+	mu       sync.RWMutex
+	progress StorageMigrationProgress
+}
+
+// NewStorageMigrationJob create storage migration job
+func NewStorageMigrationJob(dual *storage.DualStorage, interval time.Duration) *StorageMigrationJob {
+	return &StorageMigrationJob{
+		dual:                dual,
+		indexerFileDAO:      dao.NewIndexerFileDAO(),
+		indexerFileChunkDAO: dao.NewIndexerFileChunkDAO(),
+		interval:            interval,
+		stopChan:            make(chan struct{}),
+		progress: StorageMigrationProgress{
+			Source:    dual.SourceType,
+			Target:    dual.TargetType,
+			StartedAt: time.Now().Unix(),
+		},
+	}
+}
+
+// newStorageMigrationJobFor returns the migration job for stor when it is a
+// DualStorage (storage.migration enabled), otherwise nil
+func newStorageMigrationJobFor(stor storage.Storage) *StorageMigrationJob {
+	dual, ok := stor.(*storage.DualStorage)
+	if !ok {
+		return nil
+	}
+	return NewStorageMigrationJob(dual, time.Duration(conf.Cfg.Storage.Migration.Interval)*time.Second)
+}
+
+// Start runs a pass now and then every interval
+func (j *StorageMigrationJob) Start() {
+	log.Printf("[StorageMigration] Started: %s -> %s, every %s", j.dual.SourceType, j.dual.TargetType, j.interval)
+	go j.run()
+}
+
+// Stop stops the job after the current pass
+func (j *StorageMigrationJob) Stop() {
+	close(j.stopChan)
+}
+
+// Progress returns a snapshot of the migration progress
+func (j *StorageMigrationJob) Progress() StorageMigrationProgress {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.progress
+}
+
+func (j *StorageMigrationJob) run() {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	j.RunPass()
+	for {
+		select {
+		case <-j.stopChan:
+			log.Println("[StorageMigration] Stopped")
+			return
+		case <-ticker.C:
+			j.RunPass()
+		}
+	}
+}
+
+// RunPass checks every file and chunk record once, copying and flipping
+// those not yet on Target
+func (j *StorageMigrationJob) RunPass() {
+	j.mu.Lock()
+	j.progress.Running = true
+	j.mu.Unlock()
+
+	var total, migrated, failed int64
+	var lastErr string
+
+	// migrate handles one record; flip re-reads it and stores the new
+	// StorageType and storage receipt, reporting false when it changed
+	// meanwhile
+	migrate := func(kind, pinID, storageType, storagePath string, flip func(receipt string) (bool, error)) {
+		if storagePath == "" {
+			return
+		}
+		total++
+		if storageType == j.dual.TargetType {
+			migrated++
+			return
+		}
+		receipt, err := j.copyVerified(storagePath)
+		if err == nil {
+			var flipped bool
+			if flipped, err = flip(receipt); err == nil && !flipped {
+				return // Record changed during the pass; next pass retries
+			}
+		}
+		if err != nil {
+			failed++
+			lastErr = fmt.Sprintf("%s %s: %v", kind, pinID, err)
+			log.Printf("[StorageMigration] %s", lastErr)
+			return
+		}
+		migrated++
+	}
+
+	// Collect first and write afterwards so records are not rewritten while
+	// the iterator over them is still open
+	var files []*model.IndexerFile
+	var chunks []*model.IndexerFileChunk
+	err := j.indexerFileDAO.Iterate(func(file *model.IndexerFile) error {
+		files = append(files, file)
+		return nil
+	})
+	if err == nil {
+		err = j.indexerFileChunkDAO.Iterate(func(chunk *model.IndexerFileChunk) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+	}
+	if err == nil {
+		for _, file := range files {
+			migrate("file", file.PinID, file.StorageType, file.StoragePath, func(receipt string) (bool, error) {
+				fresh, err := j.indexerFileDAO.GetByPinID(file.PinID)
+				if err != nil || fresh == nil || fresh.StoragePath != file.StoragePath {
+					return false, err
+				}
+				fresh.StorageType = j.dual.TargetType
+				fresh.StorageReceipt = receipt
+				return true, j.indexerFileDAO.UpdateFields(fresh)
+			})
+		}
+		for _, chunk := range chunks {
+			migrate("chunk", chunk.PinID, chunk.StorageType, chunk.StoragePath, func(receipt string) (bool, error) {
+				fresh, err := j.indexerFileChunkDAO.GetByPinID(chunk.PinID)
+				if err != nil || fresh == nil || fresh.StoragePath != chunk.StoragePath {
+					return false, err
+				}
+				fresh.StorageType = j.dual.TargetType
+				fresh.StorageReceipt = receipt
+				return true, j.indexerFileChunkDAO.Update(fresh)
+			})
+		}
+	}
+	if err != nil {
+		lastErr = err.Error()
+		log.Printf("[StorageMigration] Pass aborted: %v", err)
+	}
+
+	now := time.Now().Unix()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	p := &j.progress
+	p.Running = false
+	p.Passes++
+	p.LastPassAt = now
+	if err != nil {
+		// Counts from an aborted pass are partial; keep the previous ones
+		p.LastError = lastErr
+		return
+	}
+	p.Total, p.Migrated, p.Failed, p.LastError = total, migrated, failed, lastErr
+	if p.Pending() > 0 {
+		p.CompletedAt = 0
+	} else if p.CompletedAt == 0 {
+		p.CompletedAt = now
+		log.Printf("[StorageMigration] All %d records are on %s; set storage.type to %s and disable storage.migration", total, j.dual.TargetType, j.dual.TargetType)
+	}
+	log.Printf("[StorageMigration] Pass %d: total=%d, migrated=%d, pending=%d, failed=%d", p.Passes, p.Total, p.Migrated, p.Pending(), p.Failed)
+}
+
+// copyVerified ensures Target holds key with the same content as Source and
+// returns the storage receipt of the copy. A Target issuing receipts (storage
+// gateway) is always written, since dual writes do not keep its receipt.
+func (j *StorageMigrationJob) copyVerified(key string) (string, error) {
+	data, err := j.dual.Source.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from %s: %w", j.dual.SourceType, err)
+	}
+	want := calculateSHA256(data)
+
+	// Usually already dual-written; only download copies of the right size
+	if _, receipts := j.dual.Target.(storage.ReceiptSaver); !receipts {
+		if info, err := j.dual.Target.Stat(key); err == nil && info.Size == int64(len(data)) {
+			if existing, err := j.dual.Target.Get(key); err == nil && calculateSHA256(existing) == want {
+				return "", nil
+			}
+		}
+	}
+
+	receipt, err := storage.SaveWithReceipt(j.dual.Target, key, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to write to %s: %w", j.dual.TargetType, err)
+	}
+	copied, err := j.dual.Target.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to read back from %s: %w", j.dual.TargetType, err)
+	}
+	if got := calculateSHA256(copied); got != want {
+		return "", fmt.Errorf("hash mismatch on %s: got %s, want %s", j.dual.TargetType, got, want)
+	}
+	return receipt, nil
+}
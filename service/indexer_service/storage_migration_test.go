@@ -0,0 +1,88 @@
+package indexer_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+	"meta-file-system/storage"
+)
+
+func TestStorageMigrationJob_CopiesVerifiesAndFlips(t *testing.T) {
+	s, source := newMergeTestService(t)
+	target, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dual := storage.NewDualStorage(source, target, "local", "oss")
+
+	// Written before the migration: only on source
+	if err := source.Save("indexer/mvc/oldi0.txt", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	// Written during the migration: dual-written
+	if err := dual.Save("indexer/mvc/newi0.txt", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := target.Get("indexer/mvc/newi0.txt"); err != nil || string(got) != "new" {
+		t.Fatalf("dual write: target has %q, %v", got, err)
+	}
+	for _, f := range []*model.IndexerFile{
+		{FirstPinID: "oldi0", PinID: "oldi0", ChainName: "mvc", FileExtension: ".txt", StorageType: "local", StoragePath: "indexer/mvc/oldi0.txt", Status: model.StatusSuccess},
+		{PinID: "newi0", ChainName: "mvc", FileExtension: ".txt", StorageType: "local", StoragePath: "indexer/mvc/newi0.txt", Status: model.StatusSuccess},
+		{PinID: "losti0", ChainName: "mvc", FileExtension: ".txt", StorageType: "local", StoragePath: "indexer/mvc/losti0.txt", Status: model.StatusSuccess},
+		{PinID: "rejectedi0", ChainName: "mvc", Status: model.StatusRejected},
+	} {
+		if err := s.indexerFileDAO.Create(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	seedChunks(t, s, source, "chunkedi0", []string{"AAA"})
+
+	job := NewStorageMigrationJob(dual, 0)
+	job.RunPass()
+
+	p := job.Progress()
+	if p.Total != 4 || p.Migrated != 3 || p.Failed != 1 || p.Pending() != 1 || p.CompletedAt != 0 || p.LastError == "" {
+		t.Fatalf("progress = %+v, want 4 total, 3 migrated, 1 failed", p)
+	}
+	for _, pinID := range []string{"oldi0", "newi0"} {
+		file, _ := s.indexerFileDAO.GetByPinID(pinID)
+		if file.StorageType != "oss" {
+			t.Errorf("%s: StorageType = %q, want oss", pinID, file.StorageType)
+		}
+		if got, err := target.Get(file.StoragePath); err != nil || got == nil {
+			t.Errorf("%s: not on target: %v", pinID, err)
+		}
+	}
+	// Flips rewrite the records in place: no duplicate index entries, latest copies updated
+	if byExt, _, err := s.indexerFileDAO.GetByExtensionWithCursor(".txt", "", 10); err != nil || len(byExt) != 3 {
+		t.Errorf("extension listing = %d entries, %v; want 3", len(byExt), err)
+	}
+	if latest, _ := s.indexerFileDAO.GetLatestFileInfoByFirstPinID("oldi0"); latest == nil || latest.StorageType != "oss" {
+		t.Errorf("latest file info = %+v, want StorageType oss", latest)
+	}
+	if lost, _ := s.indexerFileDAO.GetByPinID("losti0"); lost.StorageType != "local" {
+		t.Errorf("record without a source blob was flipped to %q", lost.StorageType)
+	}
+	chunk, _ := s.indexerFileChunkDAO.GetByPinID("chunkedi0-chunk1")
+	if got, err := target.Get(chunk.StoragePath); chunk.StorageType != "oss" || err != nil || string(got) != "AAA" {
+		t.Errorf("chunk StorageType=%q target=%q err=%v", chunk.StorageType, got, err)
+	}
+
+	// Once the missing blob turns up the next pass completes the migration
+	if err := source.Save("indexer/mvc/losti0.txt", []byte("lost")); err != nil {
+		t.Fatal(err)
+	}
+	job.RunPass()
+	if p := job.Progress(); p.Migrated != 4 || p.Pending() != 0 || p.Failed != 0 || p.CompletedAt == 0 || p.Passes != 2 {
+		t.Errorf("second pass progress = %+v, want completed", p)
+	}
+
+	// Flipped records keep being served after the source copy is gone
+	if err := source.Delete("indexer/mvc/oldi0.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := dual.Get("indexer/mvc/oldi0.txt"); err != nil || string(got) != "old" {
+		t.Errorf("dual read fallback = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,190 @@
+package indexer_service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/storage"
+)
+
+// saveBlob writes data to storagePath and returns the storage receipt. When
+// the storage backend refuses the write (e.g. an OSS outage), the blob is
+// queued in PendingStorageWrite instead and pendingReason says why: the
+// caller commits its record with StatusPendingStorage and the storage
+// retrier stores the blob later. An error means the blob could be neither
+// stored nor queued.
+func (s *IndexerService) saveBlob(kind, pinID, chainName, storagePath string, data []byte) (receipt, pendingReason string, err error) {
+	receipt, err = storage.SaveWithReceipt(s.storage, storagePath, data)
+	if err == nil || s.pendingStorageDAO == nil {
+		return receipt, "", err
+	}
+
+	now := time.Now()
+	write := &model.PendingStorageWrite{
+		StoragePath: storagePath,
+		PinID:       pinID,
+		ChainName:   chainName,
+		Kind:        kind,
+		Size:        int64(len(data)),
+		Sha256:      calculateSHA256(data),
+		Content:     data,
+		Attempts:    1,
+		LastError:   err.Error(),
+		NextRetryAt: now.Add(storageRetryBackoff(1)).Unix(),
+		CreatedAt:   now,
+	}
+	if qerr := s.pendingStorageDAO.Save(write); qerr != nil {
+		return "", "", fmt.Errorf("%w (queueing for retry failed: %v)", err, qerr)
+	}
+	log.Printf("Storage write failed for %s PIN %s, queued for retry: %s: %v", kind, pinID, storagePath, err)
+	return "", "storage write failed: " + err.Error(), nil
+}
+
+// getBlob reads a blob from st; a blob whose storage write is still queued
+// is read from the queue, so files awaiting storage stay servable
+func getBlob(st storage.Storage, queue *dao.PendingStorageWriteDAO, storagePath string) ([]byte, error) {
+	content, err := st.Get(storagePath)
+	if err == nil || queue == nil {
+		return content, err
+	}
+	if write, qerr := queue.GetByStoragePath(storagePath); qerr == nil && write != nil {
+		return write.Content, nil
+	}
+	return nil, err
+}
+
+// storageRetryBackoff delay before attempt+1 of a queued write: the retry
+// interval doubled per failed attempt, capped at max_backoff_seconds
+func storageRetryBackoff(attempts int) time.Duration {
+	cfg := conf.Cfg.Indexer.StorageRetry
+	return doublingBackoff(time.Duration(cfg.IntervalSeconds)*time.Second, time.Duration(cfg.MaxBackoffSeconds)*time.Second, attempts, 30*time.Second)
+}
+
+// doublingBackoff interval (defaultInterval when unset) doubled per failed
+// attempt after the first, capped at maxBackoff when set
+func doublingBackoff(interval, maxBackoff time.Duration, attempts int, defaultInterval time.Duration) time.Duration {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	backoff := interval
+	for i := 1; i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+// retryStorageWrites retries queued storage writes every retry interval
+// until stop is closed
+func (s *IndexerService) retryStorageWrites(stop <-chan struct{}) {
+	interval := time.Duration(conf.Cfg.Indexer.StorageRetry.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if stored := s.flushPendingStorageWrites(time.Now()); stored > 0 {
+			log.Printf("[StorageRetry] %d queued blobs stored", stored)
+		}
+	}
+}
+
+// flushPendingStorageWrites writes the queued blobs that are due at now and
+// returns how many were stored. A stored blob's file or chunk record is set
+// to StatusSuccess with the new receipt and its queue record deleted; a
+// failed write is rescheduled with a longer backoff.
+func (s *IndexerService) flushPendingStorageWrites(now time.Time) int {
+	writes, err := s.pendingStorageDAO.List()
+	if err != nil {
+		log.Printf("[StorageRetry] Failed to list queued writes: %v", err)
+		return 0
+	}
+
+	stored := 0
+	for _, write := range writes {
+		if write.NextRetryAt > now.Unix() {
+			continue
+		}
+		receipt, err := storage.SaveWithReceipt(s.storage, write.StoragePath, write.Content)
+		if err != nil {
+			write.Attempts++
+			write.LastError = err.Error()
+			write.NextRetryAt = now.Add(storageRetryBackoff(write.Attempts)).Unix()
+			if err := s.pendingStorageDAO.Save(write); err != nil {
+				log.Printf("[StorageRetry] Failed to reschedule %s: %v", write.StoragePath, err)
+			}
+			continue
+		}
+		if err := s.completeStorageWrite(write, receipt); err != nil {
+			// The blob is stored; keep the queue record so the next pass
+			// updates the record again
+			log.Printf("[StorageRetry] Stored %s but failed to update %s PIN %s: %v", write.StoragePath, write.Kind, write.PinID, err)
+			continue
+		}
+		if err := s.pendingStorageDAO.Delete(write.StoragePath); err != nil {
+			log.Printf("[StorageRetry] Failed to delete queued write %s: %v", write.StoragePath, err)
+		}
+		stored++
+	}
+	return stored
+}
+
+// completeStorageWrite marks the record waiting for write as stored. Records
+// that moved on meanwhile (another storage path, or no longer pending) are
+// left alone.
+func (s *IndexerService) completeStorageWrite(write *model.PendingStorageWrite, receipt string) error {
+	switch write.Kind {
+	case model.PendingStorageKindChunk:
+		chunk, err := s.indexerFileChunkDAO.GetByPinID(write.PinID)
+		if err != nil || chunk == nil || chunk.StoragePath != write.StoragePath || chunk.Status != model.StatusPendingStorage {
+			return err
+		}
+		chunk.Status = model.StatusSuccess
+		chunk.StatusReason = ""
+		chunk.StorageReceipt = receipt
+		return s.indexerFileChunkDAO.Update(chunk)
+	default:
+		file, err := s.indexerFileDAO.GetByPinID(write.PinID)
+		if err != nil || file == nil || file.StoragePath != write.StoragePath || file.Status != model.StatusPendingStorage {
+			return err
+		}
+		file.Status = model.StatusSuccess
+		file.StatusReason = ""
+		file.StorageReceipt = receipt
+		if err := s.indexerFileDAO.Update(file); err != nil {
+			return err
+		}
+		publishIndexedFile(file)
+		return nil
+	}
+}
+
+// ListPendingStorageWrites returns the blobs waiting for the storage backend,
+// oldest first, without their content
+func (s *IndexerService) ListPendingStorageWrites() ([]*model.PendingStorageWrite, error) {
+	writes, err := s.pendingStorageDAO.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, write := range writes {
+		write.Content = nil
+	}
+	sort.Slice(writes, func(i, j int) bool {
+		return writes[i].CreatedAt.Before(writes[j].CreatedAt)
+	})
+	return writes, nil
+}
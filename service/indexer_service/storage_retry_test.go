@@ -0,0 +1,106 @@
+package indexer_service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/storage"
+)
+
+// outageStorage a LocalStorage whose writes fail while down is set
+type outageStorage struct {
+	*storage.LocalStorage
+	down bool
+}
+
+func (o *outageStorage) Save(key string, data []byte) error {
+	if o.down {
+		return errors.New("storage unavailable")
+	}
+	return o.LocalStorage.Save(key, data)
+}
+
+func TestStorageOutage_QueuesAndRetries(t *testing.T) {
+	s, stor := newMergeTestService(t)
+	outage := &outageStorage{LocalStorage: stor, down: true}
+	s.storage = outage
+	const pinID = "outagepin1i0"
+
+	metaData := &indexer.MetaIDData{
+		PinID:          pinID,
+		TxID:           "outagepin1",
+		Operation:      "create",
+		Path:           "/file/note.txt",
+		ContentType:    "text/plain",
+		Content:        []byte("written during an outage"),
+		ChainName:      "mvc",
+		CreatorAddress: "1BoatSLRHtKNngkdXEeobR76b53LETtpyT",
+	}
+	if err := s.processFileContent(metaData, pinID, metaData.Path, 100, 1700000000000); err != nil {
+		t.Fatalf("processFileContent: %v", err)
+	}
+
+	file, err := s.indexerFileDAO.GetByPinID(pinID)
+	if err != nil || file == nil {
+		t.Fatalf("GetByPinID: %v", err)
+	}
+	if file.Status != model.StatusPendingStorage || file.StatusReason == "" {
+		t.Fatalf("Status=%q StatusReason=%q, want pending_storage with the storage error", file.Status, file.StatusReason)
+	}
+	writes, err := s.ListPendingStorageWrites()
+	if err != nil || len(writes) != 1 || writes[0].StoragePath != file.StoragePath || writes[0].Content != nil {
+		t.Fatalf("ListPendingStorageWrites = %+v, %v, want the file's write without content", writes, err)
+	}
+
+	// Served from the queue while the backend is down
+	fileService := NewIndexerFileService(outage)
+	status, err := fileService.GetFileStatus(pinID)
+	if err != nil || status.Status != FileStatusPendingStorage || status.Reason != file.StatusReason {
+		t.Errorf("GetFileStatus = %+v, %v, want pending_storage with reason", status, err)
+	}
+	if content, _, _, err := fileService.GetFileContent(pinID); err != nil || string(content) != string(metaData.Content) {
+		t.Errorf("GetFileContent = %q, %v, want the queued content", content, err)
+	}
+
+	// Not due yet, then due but still down: rescheduled
+	if stored := s.flushPendingStorageWrites(time.Now()); stored != 0 {
+		t.Errorf("flush before NextRetryAt stored %d", stored)
+	}
+	later := time.Now().Add(time.Hour)
+	if stored := s.flushPendingStorageWrites(later); stored != 0 {
+		t.Errorf("flush while down stored %d", stored)
+	}
+	if w, _ := s.pendingStorageDAO.GetByStoragePath(file.StoragePath); w == nil || w.Attempts != 2 || w.NextRetryAt <= later.Unix() {
+		t.Errorf("queued write after a failed retry = %+v, want 2 attempts rescheduled", w)
+	}
+
+	// Backend back: stored, record succeeds, queue drained
+	outage.down = false
+	if stored := s.flushPendingStorageWrites(later.Add(time.Hour)); stored != 1 {
+		t.Fatalf("flush after recovery stored %d, want 1", stored)
+	}
+	if !stor.Exists(file.StoragePath) {
+		t.Error("blob not written to storage")
+	}
+	if file, _ = s.indexerFileDAO.GetByPinID(pinID); file.Status != model.StatusSuccess || file.StatusReason != "" {
+		t.Errorf("Status=%q StatusReason=%q after retry, want success", file.Status, file.StatusReason)
+	}
+	if w, _ := s.pendingStorageDAO.GetByStoragePath(file.StoragePath); w != nil {
+		t.Error("queued write left behind after it was stored")
+	}
+}
+
+func TestStorageRetryBackoff(t *testing.T) {
+	setTestConfig(t)
+	cfg := &conf.Cfg.Indexer.StorageRetry
+	cfg.IntervalSeconds, cfg.MaxBackoffSeconds = 30, 100
+	for attempts, want := range map[int]time.Duration{1: 30 * time.Second, 2: 60 * time.Second, 3: 100 * time.Second, 10: 100 * time.Second} {
+		if got := storageRetryBackoff(attempts); got != want {
+			t.Errorf("storageRetryBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
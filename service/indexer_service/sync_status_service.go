@@ -89,6 +89,23 @@ func (s *SyncStatusService) GetLatestBlockHeight() (int64, error) {
 	return latestHeight, nil
 }
 
+// GetPruneInfo returns the pruning state detected on the chain's node; ok is
+// false when the chain has no scanner here
+func (s *SyncStatusService) GetPruneInfo(chainName string) (indexer.PruneInfo, bool) {
+	var scanner *indexer.BlockScanner
+	if s.isMultiChain {
+		if s.coordinator != nil {
+			scanner = s.coordinator.GetScanner(chainName)
+		}
+	} else if s.scanner != nil && string(s.scanner.ChainType()) == chainName {
+		scanner = s.scanner
+	}
+	if scanner == nil {
+		return indexer.PruneInfo{}, false
+	}
+	return scanner.PruneInfo(), true
+}
+
 // GetLatestBlockHeightsForAllChains get latest block heights for all chains (multi-chain mode)
 func (s *SyncStatusService) GetLatestBlockHeightsForAllChains() (map[string]int64, error) {
 	s.cacheMu.Lock()
@@ -0,0 +1,83 @@
+package indexer_service
+
+import "sync"
+
+// txDeliveryCacheSize bounds how many handled transactions are remembered
+const txDeliveryCacheSize = 200000
+
+// txDelivery is how a transaction was last handled
+type txDelivery uint8
+
+const (
+	txUnseen      txDelivery = iota
+	txSeenMempool            // handled from ZMQ (height 0)
+	txSeenBlock              // handled from a block
+)
+
+// txDeliveries serializes the ZMQ and block deliveries of a transaction, keyed
+// by chain and txid, and remembers how each transaction was handled. The
+// mempool delivery usually comes first and is handled as soon as it arrives;
+// a block delivery of the same transaction waits for it and then only
+// confirms what it indexed.
+type txDeliveries struct {
+	mu       sync.Mutex
+	inflight map[string]chan struct{}
+	seen     map[string]txDelivery
+	order    []string // seen keys, oldest first, for eviction
+}
+
+func newTxDeliveries() *txDeliveries {
+	return &txDeliveries{
+		inflight: make(map[string]chan struct{}),
+		seen:     make(map[string]txDelivery),
+	}
+}
+
+// begin waits until no other delivery of the transaction is being handled,
+// records this one and returns how the transaction was handled before.
+// release must be called once the delivery is handled. A nil txDeliveries
+// serializes nothing.
+func (d *txDeliveries) begin(chain, txID string, inBlock bool) (txDelivery, func()) {
+	if d == nil || txID == "" {
+		return txUnseen, func() {}
+	}
+	key := chain + ":" + txID
+
+	d.mu.Lock()
+	for {
+		done, busy := d.inflight[key]
+		if !busy {
+			break
+		}
+		d.mu.Unlock()
+		<-done
+		d.mu.Lock()
+	}
+	done := make(chan struct{})
+	d.inflight[key] = done
+
+	prev, known := d.seen[key]
+	next := txSeenMempool
+	if inBlock {
+		next = txSeenBlock
+	}
+	// A late mempool delivery does not demote a confirmed transaction
+	if next > prev {
+		d.seen[key] = next
+	}
+	if !known {
+		d.order = append(d.order, key)
+		for len(d.order) > txDeliveryCacheSize {
+			delete(d.seen, d.order[0])
+			d.order = d.order[1:]
+		}
+	}
+	d.mu.Unlock()
+
+	return prev, func() {
+		d.mu.Lock()
+		delete(d.inflight, key)
+		d.mu.Unlock()
+		close(done)
+	}
+}
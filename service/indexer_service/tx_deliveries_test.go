@@ -0,0 +1,63 @@
+package indexer_service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTxDeliveriesRemembersHowTxWasHandled(t *testing.T) {
+	d := newTxDeliveries()
+
+	prev, release := d.begin("mvc", "aa", false)
+	release()
+	if prev != txUnseen {
+		t.Fatalf("first delivery prev = %d", prev)
+	}
+	if prev, release = d.begin("mvc", "aa", false); prev != txSeenMempool {
+		t.Errorf("repeated ZMQ delivery prev = %d, want mempool", prev)
+	}
+	release()
+	if prev, release = d.begin("mvc", "aa", true); prev != txSeenMempool {
+		t.Errorf("block delivery prev = %d, want mempool", prev)
+	}
+	release()
+	if prev, release = d.begin("mvc", "aa", false); prev != txSeenBlock {
+		t.Errorf("late ZMQ delivery prev = %d, want block", prev)
+	}
+	release()
+	if prev, release = d.begin("btc", "aa", false); prev != txUnseen {
+		t.Errorf("other chain prev = %d, want unseen", prev)
+	}
+	release()
+}
+
+func TestTxDeliveriesSerializesSameTx(t *testing.T) {
+	d := newTxDeliveries()
+	_, release := d.begin("mvc", "bb", false)
+
+	got := make(chan txDelivery)
+	go func() {
+		prev, release := d.begin("mvc", "bb", true)
+		release()
+		got <- prev
+	}()
+
+	select {
+	case <-got:
+		t.Fatal("block delivery ran while the mempool delivery was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	if prev := <-got; prev != txSeenMempool {
+		t.Errorf("block delivery prev = %d, want mempool", prev)
+	}
+}
+
+func TestTxDeliveriesNil(t *testing.T) {
+	var d *txDeliveries
+	prev, release := d.begin("mvc", "cc", true)
+	release()
+	if prev != txUnseen {
+		t.Errorf("nil prev = %d", prev)
+	}
+}
@@ -0,0 +1,182 @@
+package indexer_service
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// Outbox entries read from the database per page
+const watchOutboxPage = 100
+
+// outboxStore is the part of the database the outbox dispatcher uses
+type outboxStore interface {
+	ListOutboxEvents(afterID int64, size int) ([]*model.IndexerOutboxEvent, error)
+	UpdateOutboxEvent(entry *model.IndexerOutboxEvent) error
+	DeleteOutboxEvent(id int64) error
+}
+
+// watchOutboxBackoff delay before attempt+1 of an outbox delivery: the retry
+// interval doubled per failed attempt, capped at max_backoff_seconds
+func watchOutboxBackoff(attempts int) time.Duration {
+	cfg := conf.Cfg.Indexer.WatchOutbox
+	return doublingBackoff(time.Duration(cfg.IntervalSeconds)*time.Second, time.Duration(cfg.MaxBackoffSeconds)*time.Second, attempts, 10*time.Second)
+}
+
+// dispatchWatchOutbox delivers the watch event outbox until stop is closed:
+// on start (entries a previous run left), whenever dispatch writes new
+// entries and every retry interval
+func (s *IndexerService) dispatchWatchOutbox(stop <-chan struct{}) {
+	if err := loadWatches(); err != nil {
+		log.Printf("[WatchOutbox] Failed to load watchlist: %v", err)
+	}
+	interval := time.Duration(conf.Cfg.Indexer.WatchOutbox.IntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		watchers.drainOutbox(database.DB, time.Now())
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-watchers.outboxKick:
+		}
+	}
+}
+
+// kickOutbox wakes the outbox dispatcher after new entries were written
+func (r *watchRegistry) kickOutbox() {
+	select {
+	case r.outboxKick <- struct{}{}:
+	default:
+	}
+}
+
+// drainOutbox delivers the outbox entries that are due at now, oldest first,
+// and returns how many were delivered. A delivered entry is deleted; a failed
+// one is rescheduled with a longer backoff until max_attempts, after which it
+// is dropped. An entry is deleted only after its delivery, so a crash in
+// between delivers it again (at least once).
+func (r *watchRegistry) drainOutbox(store outboxStore, now time.Time) int {
+	maxAttempts := conf.Cfg.Indexer.WatchOutbox.MaxAttempts
+	delivered := 0
+	var afterID int64
+	for {
+		entries, err := store.ListOutboxEvents(afterID, watchOutboxPage)
+		if err != nil {
+			log.Printf("[WatchOutbox] Failed to list entries: %v", err)
+			return delivered
+		}
+		if len(entries) == 0 {
+			return delivered
+		}
+		afterID = entries[len(entries)-1].ID
+
+		var due []*model.IndexerOutboxEvent
+		for _, entry := range entries {
+			if entry.NextAttemptAt <= now.Unix() {
+				due = append(due, entry)
+			}
+		}
+		errs := r.deliverOutboxEntries(due)
+		for i, entry := range due {
+			if errs[i] == nil {
+				if err := store.DeleteOutboxEvent(entry.ID); err != nil {
+					log.Printf("[WatchOutbox] Failed to delete delivered entry %d: %v", entry.ID, err)
+				}
+				delivered++
+				continue
+			}
+			entry.Attempts++
+			entry.LastError = errs[i].Error()
+			if len(entry.LastError) > 1024 {
+				entry.LastError = entry.LastError[:1024]
+			}
+			if maxAttempts > 0 && entry.Attempts >= maxAttempts {
+				log.Printf("[WatchOutbox] Giving up on %s delivery %d to %s after %d attempts: %v",
+					entry.Channel, entry.ID, entry.URL, entry.Attempts, errs[i])
+				if err := store.DeleteOutboxEvent(entry.ID); err != nil {
+					log.Printf("[WatchOutbox] Failed to delete entry %d: %v", entry.ID, err)
+				}
+				continue
+			}
+			entry.NextAttemptAt = now.Add(watchOutboxBackoff(entry.Attempts)).Unix()
+			if err := store.UpdateOutboxEvent(entry); err != nil {
+				log.Printf("[WatchOutbox] Failed to reschedule entry %d: %v", entry.ID, err)
+			}
+		}
+
+		if len(entries) < watchOutboxPage {
+			return delivered
+		}
+	}
+}
+
+// deliverOutboxEntries delivers entries, up to watchWebhookWorkers at a time,
+// and returns the error of each
+func (r *watchRegistry) deliverOutboxEntries(entries []*model.IndexerOutboxEvent) []error {
+	errs := make([]error, len(entries))
+	sem := make(chan struct{}, watchWebhookWorkers)
+	var wg sync.WaitGroup
+	for i, entry := range entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry *model.IndexerOutboxEvent) {
+			defer func() { <-sem; wg.Done() }()
+			errs[i] = r.deliverOutboxEntry(entry)
+		}(i, entry)
+	}
+	wg.Wait()
+	return errs
+}
+
+// deliverOutboxEntry publishes one entry. Entries that can never be delivered
+// (undecodable, or the webhook of a deleted watch) count as delivered.
+func (r *watchRegistry) deliverOutboxEntry(entry *model.IndexerOutboxEvent) error {
+	var event model.IndexerWatchEvent
+	if err := json.Unmarshal(entry.Event, &event); err != nil {
+		log.Printf("[WatchOutbox] Dropping entry %d with undecodable event: %v", entry.ID, err)
+		return nil
+	}
+	switch entry.Channel {
+	case model.OutboxBroadcast:
+		r.broadcast(&event)
+		return nil
+	case model.OutboxWebhook:
+		if !r.hasWatch(entry.WatchID) {
+			log.Printf("[WatchOutbox] Dropping webhook for PIN %s: watch %d was deleted", event.PinID, entry.WatchID)
+			return nil
+		}
+		return deliverWebhook(r.webhookClient, entry.URL, WatchWebhookPayload{WatchID: entry.WatchID, Label: entry.Label, Event: &event})
+	default:
+		log.Printf("[WatchOutbox] Dropping entry %d with unknown channel %q", entry.ID, entry.Channel)
+		return nil
+	}
+}
+
+// hasWatch reports whether watch id exists; true while the watchlist is not
+// loaded, as it cannot tell
+func (r *watchRegistry) hasWatch(id int64) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.loaded {
+		return true
+	}
+	for _, list := range r.watches {
+		for _, watch := range list {
+			if watch.ID == id {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,601 @@
+package indexer_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"meta-file-system/database"
+	"meta-file-system/indexer"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service"
+	"meta-file-system/service/common_service/metaid"
+)
+
+// Watchlist limits
+const (
+	MaxWatches            = 10000 // Total watchlist entries
+	MaxWatchesPerClient   = 100   // Watchlist entries created from one client IP
+	MaxWatchTargetLength  = 255
+	MaxSubscriptionTarget = 100 // Targets per WebSocket subscription
+	DefaultWatchEvents    = 20
+	MaxWatchEvents        = 100
+
+	watchWebhookTimeout   = 10 * time.Second
+	watchWebhookRedirects = 3
+	watchWebhookWorkers   = 4 // Outbox deliveries in flight
+	watchSubscriberBuffer = 64
+	watchRecentPins       = 10000 // PIN states remembered to suppress duplicate notifications
+)
+
+// ErrInvalidWatch is returned (wrapped) for watch requests that fail validation
+var ErrInvalidWatch = errors.New("invalid watch")
+
+// lookupWebhookHost resolves webhook hosts; replaced in tests
+var lookupWebhookHost = net.DefaultResolver.LookupIPAddr
+
+// WatchWebhookPayload body POSTed to a watch's webhook URL
+type WatchWebhookPayload struct {
+	WatchID int64                    `json:"watch_id"`
+	Label   string                   `json:"label,omitempty"`
+	Event   *model.IndexerWatchEvent `json:"event"`
+}
+
+// WatchSubscription a live (WebSocket) subscription to the events of some targets.
+// Events is closed when the subscription is removed.
+type WatchSubscription struct {
+	targets map[string]bool
+	Events  chan *model.IndexerWatchEvent
+}
+
+// watchRegistry keeps the watchlist in memory (seeded from the database on
+// first use) together with live subscriptions, so the indexer can match every
+// PIN without a database lookup.
+type watchRegistry struct {
+	createMu sync.Mutex // Held from the limit checks to the add of a new watch
+
+	mu          sync.RWMutex
+	loaded      bool
+	watches     map[string][]*model.IndexerWatch // target -> watches
+	count       int
+	perClient   map[string]int // client -> watches
+	subscribers map[*WatchSubscription]struct{}
+
+	recentMu    sync.Mutex
+	recent      map[string]struct{} // [{event}:]{pin_id}:{confirmed} already dispatched
+	recentOrder []string
+
+	outboxKick    chan struct{} // Signalled when outbox entries are written
+	webhookClient *http.Client
+}
+
+var watchers = newWatchRegistry()
+
+func newWatchRegistry() *watchRegistry {
+	return &watchRegistry{
+		watches:       make(map[string][]*model.IndexerWatch),
+		perClient:     make(map[string]int),
+		subscribers:   make(map[*WatchSubscription]struct{}),
+		recent:        make(map[string]struct{}),
+		outboxKick:    make(chan struct{}, 1),
+		webhookClient: newWebhookClient(),
+	}
+}
+
+// ensureLoaded seeds the registry with load on first use
+func (r *watchRegistry) ensureLoaded(load func() ([]*model.IndexerWatch, error)) error {
+	r.mu.RLock()
+	loaded := r.loaded
+	r.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	seed, err := load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.loaded {
+		for _, watch := range seed {
+			r.addLocked(watch)
+		}
+		r.loaded = true
+	}
+	return nil
+}
+
+func (r *watchRegistry) addLocked(watch *model.IndexerWatch) {
+	for _, existing := range r.watches[watch.Target] {
+		if existing.ID == watch.ID {
+			return
+		}
+	}
+	r.watches[watch.Target] = append(r.watches[watch.Target], watch)
+	r.count++
+	r.perClient[watch.Client]++
+}
+
+func (r *watchRegistry) add(watch *model.IndexerWatch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.addLocked(watch)
+}
+
+func (r *watchRegistry) remove(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for target, list := range r.watches {
+		for i, watch := range list {
+			if watch.ID != id {
+				continue
+			}
+			// Copy so slices handed out by match stay valid
+			kept := append(append([]*model.IndexerWatch(nil), list[:i]...), list[i+1:]...)
+			if len(kept) == 0 {
+				delete(r.watches, target)
+			} else {
+				r.watches[target] = kept
+			}
+			r.count--
+			if r.perClient[watch.Client]--; r.perClient[watch.Client] <= 0 {
+				delete(r.perClient, watch.Client)
+			}
+			return
+		}
+	}
+}
+
+func (r *watchRegistry) size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.count
+}
+
+func (r *watchRegistry) clientSize(client string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.perClient[client]
+}
+
+// watched reports whether any watch or subscription is on one of targets
+func (r *watchRegistry) watched(targets ...string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, target := range targets {
+		if target == "" {
+			continue
+		}
+		if len(r.watches[target]) > 0 {
+			return true
+		}
+		for sub := range r.subscribers {
+			if sub.targets[target] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// active reports whether any watch or subscription exists
+func (r *watchRegistry) active() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.count > 0 || len(r.subscribers) > 0
+}
+
+func (r *watchRegistry) subscribe(targets []string) *WatchSubscription {
+	sub := &WatchSubscription{
+		targets: make(map[string]bool, len(targets)),
+		Events:  make(chan *model.IndexerWatchEvent, watchSubscriberBuffer),
+	}
+	for _, target := range targets {
+		sub.targets[target] = true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (r *watchRegistry) unsubscribe(sub *WatchSubscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subscribers[sub]; ok {
+		delete(r.subscribers, sub)
+		close(sub.Events)
+	}
+}
+
+// markDispatched records key and reports whether it was new
+func (r *watchRegistry) markDispatched(key string) bool {
+	r.recentMu.Lock()
+	defer r.recentMu.Unlock()
+	if _, ok := r.recent[key]; ok {
+		return false
+	}
+	r.recent[key] = struct{}{}
+	r.recentOrder = append(r.recentOrder, key)
+	if len(r.recentOrder) > watchRecentPins {
+		delete(r.recent, r.recentOrder[0])
+		r.recentOrder = r.recentOrder[1:]
+	}
+	return true
+}
+
+// dispatch records base once per watched creator identity (via save), together
+// with an outbox entry per webhook of the matching watches and one for live
+// subscribers; the outbox dispatcher publishes them. Identities that only have
+// subscribers are pushed at once without being recorded.
+func (r *watchRegistry) dispatch(base model.IndexerWatchEvent, save func(*model.IndexerWatchEvent, ...*model.IndexerOutboxEvent) error) {
+	key := fmt.Sprintf("%s:%v", base.PinID, base.Confirmed)
+	if base.Event != "" && base.Event != model.WatchEventPin {
+		key = base.Event + ":" + key
+	}
+	if !r.markDispatched(key) {
+		return
+	}
+
+	seen := make(map[string]bool, 3)
+	for _, target := range []string{base.CreatorAddress, base.CreatorMetaId, base.CreatorGlobalMetaId} {
+		if target == "" || seen[target] {
+			continue
+		}
+		seen[target] = true
+
+		r.mu.RLock()
+		watches := r.watches[target]
+		r.mu.RUnlock()
+
+		event := base
+		event.Target = target
+		if len(watches) == 0 {
+			r.broadcast(&event)
+			continue
+		}
+
+		var outbox []*model.IndexerOutboxEvent
+		for _, watch := range watches {
+			if watch.WebhookURL != "" {
+				outbox = append(outbox, &model.IndexerOutboxEvent{
+					Channel: model.OutboxWebhook,
+					WatchID: watch.ID,
+					Label:   watch.Label,
+					URL:     watch.WebhookURL,
+				})
+			}
+		}
+		if r.subscribed(target) {
+			outbox = append(outbox, &model.IndexerOutboxEvent{Channel: model.OutboxBroadcast})
+		}
+		if err := save(&event, outbox...); err != nil {
+			// Nothing was recorded, so nothing will retry: publish once right away
+			log.Printf("Failed to record watch event for PIN %s (target %s): %v", event.PinID, target, err)
+			for _, entry := range outbox {
+				if entry.Channel == model.OutboxWebhook {
+					go func(entry *model.IndexerOutboxEvent, event model.IndexerWatchEvent) {
+						payload := WatchWebhookPayload{WatchID: entry.WatchID, Label: entry.Label, Event: &event}
+						if err := deliverWebhook(r.webhookClient, entry.URL, payload); err != nil {
+							log.Printf("Watch webhook %s failed for PIN %s: %v", entry.URL, event.PinID, err)
+						}
+					}(entry, event)
+				}
+			}
+			r.broadcast(&event)
+			continue
+		}
+		if len(outbox) > 0 {
+			r.kickOutbox()
+		}
+	}
+}
+
+// subscribed reports whether a live subscription is on target
+func (r *watchRegistry) subscribed(target string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for sub := range r.subscribers {
+		if sub.targets[target] {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcast pushes event to the subscriptions of its target; slow subscribers drop events
+func (r *watchRegistry) broadcast(event *model.IndexerWatchEvent) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for sub := range r.subscribers {
+		if !sub.targets[event.Target] {
+			continue
+		}
+		select {
+		case sub.Events <- event:
+		default:
+			log.Printf("Watch subscriber too slow, dropping event for PIN %s", event.PinID)
+		}
+	}
+}
+
+// deliverWebhook POSTs payload to url; a non-2xx status is a failure
+func deliverWebhook(client *http.Client, url string, payload WatchWebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// loadWatches seeds the registry from the database
+func loadWatches() error {
+	return watchers.ensureLoaded(database.DB.ListWatches)
+}
+
+// notifyWatchers records and delivers an event when a PIN comes from a watched
+// address, MetaID or GlobalMetaID (or one with a live subscription). The creator
+// is taken from the parsed PIN, so no node lookup is made per PIN.
+func (s *IndexerService) notifyWatchers(metaData *indexer.MetaIDData, height, timestamp int64) {
+	if err := loadWatches(); err != nil {
+		log.Printf("Failed to load watchlist: %v", err)
+		return
+	}
+	if !watchers.active() || metaData.CreatorAddress == "" {
+		return
+	}
+
+	address := metaData.CreatorAddress
+	metaID := metaid.Derive(address)
+	globalMetaID := common_service.ConvertToGlobalMetaId(address)
+	if !watchers.watched(address, metaID, globalMetaID) {
+		return
+	}
+	watchers.dispatch(model.IndexerWatchEvent{
+		Event:               model.WatchEventPin,
+		PinID:               metaData.PinID,
+		Path:                metaData.Path,
+		Operation:           metaData.Operation,
+		ContentType:         metaData.ContentType,
+		ChainName:           metaData.ChainName,
+		BlockHeight:         height,
+		Confirmed:           height > 0,
+		Timestamp:           timestamp,
+		CreatorAddress:      address,
+		CreatorMetaId:       metaID,
+		CreatorGlobalMetaId: globalMetaID,
+	}, database.DB.AddWatchEvent)
+}
+
+// blockedWebhookIP reports addresses webhooks must not reach: loopback, private,
+// shared (CGNAT), link-local (including cloud metadata at 169.254.169.254),
+// unspecified and multicast
+func blockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
+
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// checkWebhookHost resolves host and rejects it if any of its addresses is
+// blocked. Discovered peers are checked the same way.
+func checkWebhookHost(host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if blockedWebhookIP(ip) {
+			return fmt.Errorf("address %s is not public", host)
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), watchWebhookTimeout)
+	defer cancel()
+	addrs, err := lookupWebhookHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve host %s: %v", host, err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("cannot resolve host %s", host)
+	}
+	for _, addr := range addrs {
+		if blockedWebhookIP(addr.IP) {
+			return fmt.Errorf("host %s resolves to non-public address %s", host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// refuseBlockedAddress is a net.Dialer Control refusing connections to
+// addresses blockedWebhookIP reports
+func refuseBlockedAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || blockedWebhookIP(ip) {
+		return fmt.Errorf("address %s is not allowed", host)
+	}
+	return nil
+}
+
+// newWebhookClient returns the client used for webhook deliveries. The address
+// is checked again when connecting, so redirects and DNS changes after
+// ValidateWatch cannot reach a blocked address either.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: watchWebhookTimeout, Control: refuseBlockedAddress}
+	return &http.Client{
+		Timeout:   watchWebhookTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext}, // No proxy: the dialed address is the target
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= watchWebhookRedirects {
+				return fmt.Errorf("stopped after %d redirects", watchWebhookRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// ValidateWatch normalizes and checks a watch request. A webhook host must
+// resolve to public addresses only.
+func ValidateWatch(target, webhookURL string) (string, string, error) {
+	target = strings.TrimSpace(target)
+	webhookURL = strings.TrimSpace(webhookURL)
+	if target == "" {
+		return "", "", fmt.Errorf("%w: target is required", ErrInvalidWatch)
+	}
+	if len(target) > MaxWatchTargetLength || strings.ContainsAny(target, ":; \t\r\n") {
+		return "", "", fmt.Errorf("%w: target must be an address, MetaID or GlobalMetaID", ErrInvalidWatch)
+	}
+	if webhookURL != "" {
+		u, err := url.Parse(webhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+			return "", "", fmt.Errorf("%w: webhook_url must be an absolute http(s) URL", ErrInvalidWatch)
+		}
+		if err := checkWebhookHost(u.Hostname()); err != nil {
+			return "", "", fmt.Errorf("%w: %v", ErrInvalidWatch, err)
+		}
+	}
+	return target, webhookURL, nil
+}
+
+// CreateWatch adds target (address, MetaID or GlobalMetaID) to the watchlist on
+// behalf of client (the requester's IP, see http.trusted_proxies), which may
+// hold MaxWatchesPerClient entries out of MaxWatches in total. Creations are
+// serialized so concurrent requests cannot pass the limits together.
+func (s *IndexerFileService) CreateWatch(target, webhookURL, label, client string) (*model.IndexerWatch, error) {
+	target, webhookURL, err := ValidateWatch(target, webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadWatches(); err != nil {
+		return nil, err
+	}
+	watchers.createMu.Lock()
+	defer watchers.createMu.Unlock()
+	if watchers.size() >= MaxWatches {
+		return nil, fmt.Errorf("%w: watchlist is full (%d entries)", ErrInvalidWatch, MaxWatches)
+	}
+	if watchers.clientSize(client) >= MaxWatchesPerClient {
+		return nil, fmt.Errorf("%w: at most %d watches per client", ErrInvalidWatch, MaxWatchesPerClient)
+	}
+
+	watch := &model.IndexerWatch{
+		Target:     target,
+		WebhookURL: webhookURL,
+		Label:      strings.TrimSpace(label),
+		Client:     client,
+	}
+	if err := database.DB.CreateWatch(watch); err != nil {
+		return nil, err
+	}
+	watchers.add(watch)
+	return watch, nil
+}
+
+// DeleteWatch removes a watchlist entry; returns database.ErrNotFound if it does not exist
+func (s *IndexerFileService) DeleteWatch(id int64) error {
+	if err := database.DB.DeleteWatch(id); err != nil {
+		return err
+	}
+	watchers.remove(id)
+	return nil
+}
+
+// ListWatches lists watchlist entries, optionally only those of target
+func (s *IndexerFileService) ListWatches(target string) ([]*model.IndexerWatch, error) {
+	watches, err := database.DB.ListWatches()
+	if err != nil {
+		return nil, err
+	}
+	target = strings.TrimSpace(target)
+	filtered := make([]*model.IndexerWatch, 0, len(watches))
+	for _, watch := range watches {
+		if target != "" && watch.Target != target {
+			continue
+		}
+		watch.Client = "" // Freshly loaded records; the listing is public
+		filtered = append(filtered, watch)
+	}
+	return filtered, nil
+}
+
+// ListWatchEvents lists recorded events of target with ID > cursor, oldest first.
+// Returns the events, the next cursor and whether more events follow.
+func (s *IndexerFileService) ListWatchEvents(target string, cursor int64, size int) ([]*model.IndexerWatchEvent, int64, bool, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, 0, false, fmt.Errorf("%w: target is required", ErrInvalidWatch)
+	}
+	if size < 1 || size > MaxWatchEvents {
+		size = DefaultWatchEvents
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+
+	events, err := database.DB.ListWatchEvents(target, cursor, size+1)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	hasMore := len(events) > size
+	if hasMore {
+		events = events[:size]
+	}
+	nextCursor := cursor
+	if len(events) > 0 {
+		nextCursor = events[len(events)-1].ID
+	}
+	return events, nextCursor, hasMore, nil
+}
+
+// SubscribeWatchEvents starts a live subscription to the events of targets.
+// Targets need not be on the watchlist; events of unwatched targets are only pushed
+// to subscribers, never recorded. Call UnsubscribeWatchEvents when done.
+func (s *IndexerFileService) SubscribeWatchEvents(targets []string) (*WatchSubscription, error) {
+	normalized := make([]string, 0, len(targets))
+	for _, target := range targets {
+		for _, t := range strings.Split(target, ",") {
+			if strings.TrimSpace(t) == "" {
+				continue
+			}
+			t, _, err := ValidateWatch(t, "")
+			if err != nil {
+				return nil, err
+			}
+			normalized = append(normalized, t)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one target is required", ErrInvalidWatch)
+	}
+	if len(normalized) > MaxSubscriptionTarget {
+		return nil, fmt.Errorf("%w: at most %d targets per subscription", ErrInvalidWatch, MaxSubscriptionTarget)
+	}
+	return watchers.subscribe(normalized), nil
+}
+
+// UnsubscribeWatchEvents ends a subscription and closes its Events channel
+func (s *IndexerFileService) UnsubscribeWatchEvents(sub *WatchSubscription) {
+	watchers.unsubscribe(sub)
+}
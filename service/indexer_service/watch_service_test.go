@@ -0,0 +1,401 @@
+package indexer_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+)
+
+// fakeWebhookResolver makes lookupWebhookHost resolve from hosts for the test
+func fakeWebhookResolver(t *testing.T, hosts map[string]string) {
+	orig := lookupWebhookHost
+	lookupWebhookHost = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		ip, ok := hosts[host]
+		if !ok {
+			return nil, errors.New("no such host")
+		}
+		return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+	}
+	t.Cleanup(func() { lookupWebhookHost = orig })
+}
+
+func TestValidateWatch(t *testing.T) {
+	fakeWebhookResolver(t, map[string]string{"example.com": "93.184.216.34", "internal.example": "10.0.0.5"})
+	cases := []struct {
+		name    string
+		target  string
+		webhook string
+		wantErr bool
+	}{
+		{"address", " 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa ", "", false},
+		{"with webhook", "meta1", "https://example.com/hook", false},
+		{"empty target", "  ", "", true},
+		{"separator in target", "a:b", "", true},
+		{"relative webhook", "meta1", "/hook", true},
+		{"non-http webhook", "meta1", "ftp://example.com/hook", true},
+		{"loopback webhook", "meta1", "http://127.0.0.1:8080/hook", true},
+		{"metadata webhook", "meta1", "http://169.254.169.254/latest/meta-data", true},
+		{"private webhook", "meta1", "http://10.1.2.3/hook", true},
+		{"ipv6 loopback webhook", "meta1", "http://[::1]/hook", true},
+		{"host resolving to private", "meta1", "https://internal.example/hook", true},
+		{"unresolvable host", "meta1", "https://nowhere.example/hook", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			target, _, err := ValidateWatch(tc.target, tc.webhook)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidWatch) {
+				t.Errorf("err = %v, want ErrInvalidWatch", err)
+			}
+			if err == nil && (target == "" || target[0] == ' ') {
+				t.Errorf("target = %q, want trimmed", target)
+			}
+		})
+	}
+}
+
+func TestWatchRegistryDispatch(t *testing.T) {
+	setTestConfig(t)
+	hooks := make(chan WatchWebhookPayload, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload WatchWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode webhook: %v", err)
+		}
+		hooks <- payload
+	}))
+	defer srv.Close()
+
+	r := newWatchRegistry()
+	r.webhookClient = srv.Client() // The test server listens on loopback, which the default client refuses
+	if err := r.ensureLoaded(func() ([]*model.IndexerWatch, error) {
+		return []*model.IndexerWatch{{ID: 1, Target: "meta1", WebhookURL: srv.URL, Label: "wallet"}}, nil
+	}); err != nil {
+		t.Fatalf("ensureLoaded: %v", err)
+	}
+	sub := r.subscribe([]string{"addr1", "other"})
+	defer r.unsubscribe(sub)
+
+	var saved []*model.IndexerWatchEvent
+	store := &fakeOutbox{}
+	save := func(e *model.IndexerWatchEvent, outbox ...*model.IndexerOutboxEvent) error {
+		e.ID = int64(len(saved) + 1)
+		saved = append(saved, e)
+		return store.add(e, outbox...)
+	}
+	base := model.IndexerWatchEvent{PinID: "pin1i0", CreatorAddress: "addr1", CreatorMetaId: "meta1"}
+
+	r.dispatch(base, save)
+	r.dispatch(base, save) // Duplicate PIN state is suppressed
+
+	if len(saved) != 1 || saved[0].Target != "meta1" {
+		t.Fatalf("saved = %+v, want one event for meta1", saved)
+	}
+	if len(store.entries) != 1 || store.entries[0].Channel != model.OutboxWebhook {
+		t.Fatalf("outbox = %+v, want one webhook entry", store.entries)
+	}
+	if n := r.drainOutbox(store, time.Now()); n != 1 || len(store.entries) != 0 {
+		t.Fatalf("drainOutbox delivered %d, left %d entries", n, len(store.entries))
+	}
+	select {
+	case e := <-sub.Events:
+		if e.Target != "addr1" || e.ID != 0 {
+			t.Errorf("subscriber event = %+v, want unrecorded addr1 event", e)
+		}
+	default:
+		t.Fatal("subscriber got no event")
+	}
+	if len(sub.Events) != 0 {
+		t.Errorf("subscriber got %d extra events", len(sub.Events))
+	}
+	select {
+	case payload := <-hooks:
+		if payload.WatchID != 1 || payload.Label != "wallet" || payload.Event.PinID != "pin1i0" {
+			t.Errorf("webhook payload = %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+
+	// Confirmation of the same PIN is a new event
+	base.Confirmed, base.BlockHeight = true, 100
+	r.dispatch(base, save)
+	if len(saved) != 2 || !saved[1].Confirmed {
+		t.Errorf("saved = %+v, want confirmed event", saved)
+	}
+	if e := <-sub.Events; !e.Confirmed {
+		t.Errorf("subscriber event = %+v, want confirmed", e)
+	}
+	r.drainOutbox(store, time.Now())
+	<-hooks
+
+	r.remove(1)
+	if r.size() != 0 {
+		t.Errorf("size after remove = %d", r.size())
+	}
+	r.unsubscribe(sub)
+	if r.active() {
+		t.Error("registry still active without watches or subscribers")
+	}
+	if _, ok := <-sub.Events; ok {
+		t.Error("Events not closed after unsubscribe")
+	}
+}
+
+func TestWebhookClientRefusesBlockedAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook reached a loopback server")
+	}))
+	defer srv.Close()
+
+	resp, err := newWebhookClient().Post(srv.URL, "application/json", nil)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("POST to loopback succeeded, want refused")
+	}
+}
+
+func TestWatchRegistryPerClientCount(t *testing.T) {
+	r := newWatchRegistry()
+	if err := r.ensureLoaded(func() ([]*model.IndexerWatch, error) {
+		return []*model.IndexerWatch{
+			{ID: 1, Target: "meta1", Client: "1.2.3.4"},
+			{ID: 2, Target: "meta2", Client: "1.2.3.4"},
+			{ID: 3, Target: "meta3", Client: "5.6.7.8"},
+		}, nil
+	}); err != nil {
+		t.Fatalf("ensureLoaded: %v", err)
+	}
+	if got := r.clientSize("1.2.3.4"); got != 2 {
+		t.Errorf("clientSize = %d, want 2", got)
+	}
+	r.remove(1)
+	if got := r.clientSize("1.2.3.4"); got != 1 {
+		t.Errorf("clientSize after remove = %d, want 1", got)
+	}
+	if !r.watched("", "meta3") || r.watched("meta1", "nobody") {
+		t.Error("watched does not match the remaining watches")
+	}
+}
+
+// setTestWatchers replaces the global registry with one seeded from seed
+func setTestWatchers(t *testing.T, seed []*model.IndexerWatch) {
+	t.Helper()
+	prev := watchers
+	watchers = newWatchRegistry()
+	if err := watchers.ensureLoaded(func() ([]*model.IndexerWatch, error) { return seed, nil }); err != nil {
+		t.Fatalf("ensureLoaded: %v", err)
+	}
+	t.Cleanup(func() { watchers = prev })
+}
+
+// slowWatchDB delays CreateWatch so concurrent creations overlap
+type slowWatchDB struct {
+	database.Database
+}
+
+func (d slowWatchDB) CreateWatch(watch *model.IndexerWatch) error {
+	time.Sleep(time.Millisecond)
+	return d.Database.CreateWatch(watch)
+}
+
+// createWatches runs CreateWatch concurrently once per client and returns how many succeeded
+func createWatches(t *testing.T, clients []string) int {
+	t.Helper()
+	s := &IndexerFileService{}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created := 0
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client string) {
+			defer wg.Done()
+			_, err := s.CreateWatch("meta1", "", "", client)
+			if err != nil && !errors.Is(err, ErrInvalidWatch) {
+				t.Errorf("CreateWatch: %v", err)
+			}
+			if err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}(client)
+	}
+	wg.Wait()
+	return created
+}
+
+func TestCreateWatchPerClientLimitHoldsConcurrently(t *testing.T) {
+	setTestPebble(t)
+	database.DB = slowWatchDB{database.DB}
+	setTestWatchers(t, nil)
+
+	clients := make([]string, MaxWatchesPerClient+50)
+	for i := range clients {
+		clients[i] = "1.2.3.4"
+	}
+	if got := createWatches(t, clients); got != MaxWatchesPerClient {
+		t.Errorf("created = %d, want %d", got, MaxWatchesPerClient)
+	}
+	if got := watchers.clientSize("1.2.3.4"); got != MaxWatchesPerClient {
+		t.Errorf("clientSize = %d, want %d", got, MaxWatchesPerClient)
+	}
+}
+
+func TestCreateWatchGlobalLimitHoldsConcurrently(t *testing.T) {
+	setTestPebble(t)
+	database.DB = slowWatchDB{database.DB}
+	seed := make([]*model.IndexerWatch, MaxWatches-5)
+	for i := range seed {
+		seed[i] = &model.IndexerWatch{ID: int64(i + 1), Target: "meta0", Client: fmt.Sprintf("seed-%d", i)}
+	}
+	setTestWatchers(t, seed)
+
+	clients := make([]string, 20)
+	for i := range clients {
+		clients[i] = fmt.Sprintf("10.0.0.%d", i)
+	}
+	if got := createWatches(t, clients); got != 5 {
+		t.Errorf("created = %d, want 5", got)
+	}
+	if got := watchers.size(); got != MaxWatches {
+		t.Errorf("size = %d, want %d", got, MaxWatches)
+	}
+}
+
+// fakeOutbox an in-memory outboxStore
+type fakeOutbox struct {
+	mu      sync.Mutex
+	nextID  int64
+	entries []*model.IndexerOutboxEvent
+}
+
+func (f *fakeOutbox) add(event *model.IndexerWatchEvent, outbox ...*model.IndexerOutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range outbox {
+		f.nextID++
+		entry.ID, entry.Event = f.nextID, data
+		f.entries = append(f.entries, entry)
+	}
+	return nil
+}
+
+func (f *fakeOutbox) ListOutboxEvents(afterID int64, size int) ([]*model.IndexerOutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var list []*model.IndexerOutboxEvent
+	for _, entry := range f.entries {
+		if entry.ID > afterID && len(list) < size {
+			copied := *entry
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeOutbox) UpdateOutboxEvent(entry *model.IndexerOutboxEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, e := range f.entries {
+		if e.ID == entry.ID {
+			f.entries[i] = entry
+		}
+	}
+	return nil
+}
+
+func (f *fakeOutbox) DeleteOutboxEvent(id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, e := range f.entries {
+		if e.ID == id {
+			f.entries = append(f.entries[:i], f.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestWatchOutboxRetriesFailedWebhooks(t *testing.T) {
+	setTestConfig(t)
+	cfg := &conf.Cfg.Indexer.WatchOutbox
+	cfg.IntervalSeconds, cfg.MaxBackoffSeconds, cfg.MaxAttempts = 10, 60, 3
+
+	var mu sync.Mutex
+	status := http.StatusInternalServerError
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	r := newWatchRegistry()
+	r.webhookClient = srv.Client()
+	if err := r.ensureLoaded(func() ([]*model.IndexerWatch, error) {
+		return []*model.IndexerWatch{{ID: 1, Target: "meta1", WebhookURL: srv.URL}, {ID: 2, Target: "meta1", WebhookURL: srv.URL}}, nil
+	}); err != nil {
+		t.Fatalf("ensureLoaded: %v", err)
+	}
+	store := &fakeOutbox{}
+	r.dispatch(model.IndexerWatchEvent{PinID: "pin1i0", CreatorMetaId: "meta1"}, func(e *model.IndexerWatchEvent, outbox ...*model.IndexerOutboxEvent) error {
+		return store.add(e, outbox...)
+	})
+
+	now := time.Unix(1700000000, 0)
+	if n := r.drainOutbox(store, now); n != 0 || len(store.entries) != 2 {
+		t.Fatalf("failing webhooks: delivered %d, %d entries left", n, len(store.entries))
+	}
+	if e := store.entries[0]; e.Attempts != 1 || e.NextAttemptAt != now.Unix()+10 || e.LastError == "" {
+		t.Fatalf("rescheduled entry = %+v", e)
+	}
+	// Not due yet
+	r.drainOutbox(store, now.Add(5*time.Second))
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 (retry before backoff)", calls)
+	}
+
+	// A deleted watch drops its entry; the other one is delivered
+	r.remove(2)
+	mu.Lock()
+	status = http.StatusOK
+	mu.Unlock()
+	if n := r.drainOutbox(store, now.Add(10*time.Second)); n != 2 || len(store.entries) != 0 {
+		t.Errorf("retry: delivered %d, %d entries left", n, len(store.entries))
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestWatchOutboxGivesUp(t *testing.T) {
+	setTestConfig(t)
+	conf.Cfg.Indexer.WatchOutbox.MaxAttempts = 1
+
+	r := newWatchRegistry()
+	store := &fakeOutbox{}
+	store.add(&model.IndexerWatchEvent{PinID: "pin1i0"}, &model.IndexerOutboxEvent{Channel: model.OutboxWebhook, URL: "http://127.0.0.1:1/"})
+	if n := r.drainOutbox(store, time.Now()); n != 0 || len(store.entries) != 0 {
+		t.Errorf("delivered %d, %d entries left; want dropped after max_attempts", n, len(store.entries))
+	}
+}
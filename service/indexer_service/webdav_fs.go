@@ -0,0 +1,338 @@
+package indexer_service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/webdav"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/service/common_service"
+)
+
+// WebDAVPrefix URL path the WebDAV gateway is mounted under
+const WebDAVPrefix = "/webdav"
+
+// webdavTreeCacheSize directories kept by the WebDAV tree cache before it is
+// cleared
+const webdavTreeCacheSize = 1024
+
+// webdavFS is a read-only webdav.FileSystem over the path trees of indexed
+// files: /{metaidOrGlobalMetaId}/ is the root of a creator's tree (see
+// GetPathTree), its collections are the tree's directories and its files the
+// latest version of each file. The root collection is empty; users are not
+// enumerated. A file whose name is taken in its directory (by a directory or
+// a newer file) is listed as "{pinId}_{name}".
+type webdavFS struct {
+	loadTree    func(metaidOrGlobalMetaId, dir string) (*PathTree, error)
+	readContent func(file *model.IndexerFile) ([]byte, error)
+	ttl         time.Duration
+
+	// A PROPFIND opens every listed entry, which resolves it against its
+	// parent directory; recently listed directories are reused for that
+	mu    sync.Mutex
+	trees map[string]cachedPathTree
+}
+
+type cachedPathTree struct {
+	tree     *PathTree
+	loadedAt time.Time
+}
+
+// NewWebDAVFileSystem returns the read-only file system of the WebDAV gateway;
+// directories are cached for indexer.webdav.cache_seconds
+func NewWebDAVFileSystem(s *IndexerFileService) webdav.FileSystem {
+	return &webdavFS{
+		loadTree: s.GetPathTree,
+		readContent: func(file *model.IndexerFile) ([]byte, error) {
+			return s.readFile(file)
+		},
+		ttl:   time.Duration(conf.Cfg.Indexer.WebDAV.CacheSeconds) * time.Second,
+		trees: make(map[string]cachedPathTree),
+	}
+}
+
+func (fs *webdavFS) Mkdir(ctx context.Context, name string, perm os.FileMode) error {
+	return os.ErrPermission
+}
+
+func (fs *webdavFS) RemoveAll(ctx context.Context, name string) error {
+	return os.ErrPermission
+}
+
+func (fs *webdavFS) Rename(ctx context.Context, oldName, newName string) error {
+	return os.ErrPermission
+}
+
+func (fs *webdavFS) OpenFile(ctx context.Context, name string, flag int, perm os.FileMode) (webdav.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND) != 0 {
+		return nil, os.ErrPermission
+	}
+	node, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return &webdavFile{fs: fs, node: node}, nil
+}
+
+func (fs *webdavFS) Stat(ctx context.Context, name string) (os.FileInfo, error) {
+	node, err := fs.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// resolve maps a WebDAV path to a collection or file
+func (fs *webdavFS) resolve(name string) (*webdavNode, error) {
+	clean := path.Clean("/" + name)
+	if clean == "/" {
+		return &webdavNode{name: "/", isDir: true}, nil
+	}
+	parts := strings.SplitN(strings.TrimPrefix(clean, "/"), "/", 2)
+	metaid := parts[0]
+	if !metaIDPattern.MatchString(metaid) && !common_service.IsGlobalMetaId(metaid) {
+		return nil, os.ErrNotExist
+	}
+	if len(parts) == 1 {
+		tree, err := fs.tree(metaid, "/")
+		if err != nil {
+			return nil, err
+		}
+		if tree.FileCount == 0 {
+			return nil, os.ErrNotExist
+		}
+		return &webdavNode{name: metaid, metaid: metaid, dir: "/", isDir: true}, nil
+	}
+
+	parent, base := path.Split("/" + parts[1])
+	tree, err := fs.tree(metaid, parent)
+	if err != nil {
+		return nil, err
+	}
+	entries := webdavEntries(metaid, tree)
+	for _, entry := range entries {
+		if entry.name == base {
+			return entry, nil
+		}
+	}
+	// Clients on case-insensitive systems may change the case of a name
+	for _, entry := range entries {
+		if strings.EqualFold(entry.name, base) {
+			return entry, nil
+		}
+	}
+	return nil, os.ErrNotExist
+}
+
+// tree returns the path tree of dir, from the cache while it is fresh
+func (fs *webdavFS) tree(metaid, dir string) (*PathTree, error) {
+	dir = path.Clean("/" + dir)
+	key := metaid + ":" + strings.ToLower(dir)
+	fs.mu.Lock()
+	cached, ok := fs.trees[key]
+	fs.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < fs.ttl {
+		return cached.tree, nil
+	}
+
+	tree, err := fs.loadTree(metaid, dir)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTreePath) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	fs.mu.Lock()
+	if len(fs.trees) >= webdavTreeCacheSize {
+		fs.trees = make(map[string]cachedPathTree)
+	}
+	fs.trees[key] = cachedPathTree{tree: tree, loadedAt: time.Now()}
+	fs.mu.Unlock()
+	return tree, nil
+}
+
+// children lists a collection: directories first, then files
+func (fs *webdavFS) children(node *webdavNode) ([]*webdavNode, error) {
+	if node.metaid == "" {
+		return nil, nil
+	}
+	tree, err := fs.tree(node.metaid, node.dir)
+	if err != nil {
+		return nil, err
+	}
+	return webdavEntries(node.metaid, tree), nil
+}
+
+// webdavEntries names the entries of tree uniquely; a directory keeps its
+// name, and of several files with one name the newest keeps it
+func webdavEntries(metaid string, tree *PathTree) []*webdavNode {
+	entries := make([]*webdavNode, 0, len(tree.Directories)+len(tree.Files))
+	taken := make(map[string]struct{}, cap(entries))
+	for _, dir := range tree.Directories {
+		entries = append(entries, &webdavNode{name: dir.Name, metaid: metaid, dir: dir.Path, isDir: true})
+		taken[dir.Name] = struct{}{}
+	}
+	for _, file := range tree.Files {
+		name := pathTreeFileName(file)
+		if _, ok := taken[name]; ok {
+			name = file.PinID + "_" + name
+		}
+		taken[name] = struct{}{}
+		entries = append(entries, &webdavNode{name: name, metaid: metaid, file: file})
+	}
+	return entries
+}
+
+// webdavNode a collection or file of the gateway; it is its own os.FileInfo
+type webdavNode struct {
+	name   string
+	metaid string // Creator the node belongs to, "" for the root
+	dir    string // MetaID directory of a collection
+	isDir  bool
+	file   *model.IndexerFile // Latest version, for files
+}
+
+func (n *webdavNode) Name() string { return n.name }
+
+func (n *webdavNode) Size() int64 {
+	if n.file == nil {
+		return 0
+	}
+	return n.file.FileSize
+}
+
+func (n *webdavNode) Mode() os.FileMode {
+	if n.isDir {
+		return os.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (n *webdavNode) ModTime() time.Time {
+	if n.file == nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(n.file.Timestamp)
+}
+
+func (n *webdavNode) IsDir() bool { return n.isDir }
+
+func (n *webdavNode) Sys() interface{} { return nil }
+
+// ContentType the indexed content type, so PROPFIND does not read the file
+func (n *webdavNode) ContentType(ctx context.Context) (string, error) {
+	if n.file == nil || n.file.ContentType == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return n.file.ContentType, nil
+}
+
+// ETag the file sha256, as on the content routes
+func (n *webdavNode) ETag(ctx context.Context) (string, error) {
+	if n.file == nil || n.file.FileHash == "" {
+		return "", webdav.ErrNotImplemented
+	}
+	return "\"" + n.file.FileHash + "\"", nil
+}
+
+// webdavFile an open node; file content is read from storage on the first
+// Read, so opening a file to list or stat it costs nothing
+type webdavFile struct {
+	fs      *webdavFS
+	node    *webdavNode
+	content []byte
+	offset  int64
+	entries []*webdavNode
+	listed  int
+	loaded  bool
+}
+
+func (f *webdavFile) Close() error { return nil }
+
+func (f *webdavFile) Write(p []byte) (int, error) { return 0, os.ErrPermission }
+
+func (f *webdavFile) Stat() (os.FileInfo, error) { return f.node, nil }
+
+func (f *webdavFile) Read(p []byte) (int, error) {
+	if f.node.isDir {
+		return 0, os.ErrInvalid
+	}
+	if f.content == nil {
+		content, err := f.fs.readContent(f.node.file)
+		if err != nil {
+			return 0, err
+		}
+		f.content = content
+	}
+	if f.offset >= int64(len(f.content)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.content[f.offset:])
+	f.offset += int64(n)
+	return n, nil
+}
+
+// Seek is answered from the indexed file size while the content is not read.
+// A GET seeks before it writes the response, so a provisional file refused
+// under indexer.confirmations.refuse_provisional fails there, not part way.
+func (f *webdavFile) Seek(offset int64, whence int) (int64, error) {
+	if f.content == nil && f.node.file != nil {
+		if err := CheckProvisionalContent(f.node.file); err != nil {
+			return 0, err
+		}
+	}
+	size := f.node.Size()
+	if f.content != nil {
+		size = int64(len(f.content))
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += size
+	default:
+		return 0, os.ErrInvalid
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *webdavFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.node.isDir {
+		return nil, os.ErrInvalid
+	}
+	if !f.loaded {
+		entries, err := f.fs.children(f.node)
+		if err != nil {
+			return nil, err
+		}
+		f.entries, f.loaded = entries, true
+	}
+	rest := f.entries[f.listed:]
+	if count > 0 {
+		if len(rest) == 0 {
+			return nil, io.EOF
+		}
+		if len(rest) > count {
+			rest = rest[:count]
+		}
+	}
+	f.listed += len(rest)
+	infos := make([]os.FileInfo, 0, len(rest))
+	for _, entry := range rest {
+		infos = append(infos, entry)
+	}
+	return infos, nil
+}
@@ -0,0 +1,179 @@
+package indexer_service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"meta-file-system/model"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+const webdavTestMetaID = "0000000000000000000000000000000000000000000000000000000000000abc"
+
+// newTestWebDAVFS serves files through buildPathTree, as GetPathTree does
+func newTestWebDAVFS(files []*model.IndexerFile) (*webdavFS, *int) {
+	loads := 0
+	fs := &webdavFS{
+		loadTree: func(metaid, dir string) (*PathTree, error) {
+			loads++
+			if metaid != webdavTestMetaID {
+				return &PathTree{Path: "/"}, nil
+			}
+			tree := &PathTree{Path: "/"}
+			var base *metaid_protocols.MetaIDPath
+			if strings.Trim(dir, "/") != "" {
+				parsed, err := metaid_protocols.ParseMetaIDPath(dir)
+				if err != nil {
+					return nil, ErrInvalidTreePath
+				}
+				base, tree.Path = parsed, parsed.Path
+			}
+			buildPathTree(tree, base, files)
+			return tree, nil
+		},
+		readContent: func(file *model.IndexerFile) ([]byte, error) {
+			return []byte("content of " + file.PinID), nil
+		},
+		ttl:   time.Minute,
+		trees: make(map[string]cachedPathTree),
+	}
+	return fs, &loads
+}
+
+func webdavNames(t *testing.T, fs *webdavFS, name string) []string {
+	t.Helper()
+	f, err := fs.OpenFile(context.Background(), name, os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	infos, err := f.Readdir(0)
+	if err != nil {
+		t.Fatalf("readdir %s: %v", name, err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
+
+func TestWebDAVFSListsPathTree(t *testing.T) {
+	fs, loads := newTestWebDAVFS([]*model.IndexerFile{
+		treeFile("p3", "p3", "/file/photos/cat.png", "cat.png", 11),
+		treeFile("p2", "p2", "/file/photos", "photos", 5),
+		treeFile("p1", "p1", "/file/notes.txt", "notes.txt", 3),
+	})
+
+	if got := webdavNames(t, fs, "/"); len(got) != 0 {
+		t.Errorf("root = %v, want empty", got)
+	}
+	if got, want := webdavNames(t, fs, "/"+webdavTestMetaID), []string{"file"}; !equalStrings(got, want) {
+		t.Errorf("user root = %v, want %v", got, want)
+	}
+	// The file named like the directory is kept reachable under its PIN ID
+	if got, want := webdavNames(t, fs, "/"+webdavTestMetaID+"/file/"), []string{"photos", "notes.txt", "p2_photos"}; !equalStrings(got, want) {
+		t.Errorf("/file = %v, want %v", got, want)
+	}
+
+	info, err := fs.Stat(context.Background(), "/"+webdavTestMetaID+"/FILE/Photos/cat.png")
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.IsDir() || info.Size() != 11 {
+		t.Errorf("cat.png info = dir %v size %d", info.IsDir(), info.Size())
+	}
+	before := *loads
+	if _, err := fs.Stat(context.Background(), "/"+webdavTestMetaID+"/file/notes.txt"); err != nil {
+		t.Fatalf("stat notes.txt: %v", err)
+	}
+	if *loads != before {
+		t.Error("a listed directory must be served from the cache")
+	}
+
+	for _, name := range []string{"/nobody", "/" + webdavTestMetaID + "/file/missing.txt"} {
+		if _, err := fs.Stat(context.Background(), name); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("stat %s = %v, want ErrNotExist", name, err)
+		}
+	}
+}
+
+func TestWebDAVFSReadsContentLazily(t *testing.T) {
+	fs, _ := newTestWebDAVFS([]*model.IndexerFile{treeFile("p1", "p1", "/file/a.txt", "a.txt", 13)})
+	reads := 0
+	readContent := fs.readContent
+	fs.readContent = func(file *model.IndexerFile) ([]byte, error) {
+		reads++
+		return readContent(file)
+	}
+
+	f, err := fs.OpenFile(context.Background(), "/"+webdavTestMetaID+"/file/a.txt", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if size, err := f.Seek(0, io.SeekEnd); err != nil || size != 13 {
+		t.Fatalf("seek end = %d, %v; want 13", size, err)
+	}
+	if reads != 0 {
+		t.Fatal("content must not be read before the first Read")
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("seek start: %v", err)
+	}
+	data, err := io.ReadAll(f)
+	if err != nil || string(data) != "content of p1" {
+		t.Fatalf("read = %q, %v", data, err)
+	}
+}
+
+func TestWebDAVFSIsReadOnly(t *testing.T) {
+	fs, _ := newTestWebDAVFS([]*model.IndexerFile{treeFile("p1", "p1", "/file/a.txt", "a.txt", 1)})
+	ctx := context.Background()
+	name := "/" + webdavTestMetaID + "/file/a.txt"
+
+	if _, err := fs.OpenFile(ctx, name, os.O_RDWR, 0); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("open for writing = %v, want ErrPermission", err)
+	}
+	if _, err := fs.OpenFile(ctx, "/"+webdavTestMetaID+"/file/new.txt", os.O_CREATE|os.O_WRONLY, 0644); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("create = %v, want ErrPermission", err)
+	}
+	if err := fs.Mkdir(ctx, "/"+webdavTestMetaID+"/file/dir", 0755); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("mkdir = %v, want ErrPermission", err)
+	}
+	if err := fs.RemoveAll(ctx, name); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("remove = %v, want ErrPermission", err)
+	}
+	if err := fs.Rename(ctx, name, name+".bak"); !errors.Is(err, os.ErrPermission) {
+		t.Errorf("rename = %v, want ErrPermission", err)
+	}
+}
+
+func TestWebDAVFSRefusesProvisionalFiles(t *testing.T) {
+	setTestConfig(t)
+	seedProvisionalPolicy(t)
+	confirmed := treeFile("p1", "p1", "/file/old.txt", "old.txt", 12)
+	provisional := treeFile("p2", "p2", "/file/new.txt", "new.txt", 12)
+	confirmed.ChainName, confirmed.BlockHeight = "mvc", 100
+	provisional.ChainName, provisional.BlockHeight = "mvc", 199
+	fs, _ := newTestWebDAVFS([]*model.IndexerFile{confirmed, provisional})
+
+	// Still listed
+	if names := webdavNames(t, fs, "/"+webdavTestMetaID+"/file"); len(names) != 2 {
+		t.Fatalf("names = %v, want both files", names)
+	}
+	for name, wantErr := range map[string]bool{"old.txt": false, "new.txt": true} {
+		f, err := fs.OpenFile(context.Background(), "/"+webdavTestMetaID+"/file/"+name, os.O_RDONLY, 0)
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		// A GET sizes the file by seeking before writing the response
+		_, err = f.Seek(0, io.SeekEnd)
+		if gotErr := errors.Is(err, ErrProvisionalContent); gotErr != wantErr {
+			t.Errorf("seek %s: err = %v, want provisional %v", name, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package upload_service
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"time"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+)
+
+const (
+	// maxAssistentPageSize largest page of GetAssistentDashboard
+	maxAssistentPageSize = 100
+	// maxAssistentPendingOutputs unspent outputs listed by GetAssistentDetail
+	maxAssistentPendingOutputs = 200
+)
+
+// AssistentBalance ledger totals of the assistent addresses of a chain, or of
+// one user's assistent address. FeesPaid is what users paid in that is no
+// longer unspent: network fees of the transactions the assistent signed, plus
+// any dust locked in PIN outputs.
+type AssistentBalance struct {
+	Chain            string `json:"chain" example:"mvc"`
+	MetaId           string `json:"metaId,omitempty"`           // User MetaID (per-user rows)
+	Address          string `json:"address,omitempty"`          // User address (per-user rows)
+	AssistentAddress string `json:"assistentAddress,omitempty"` // Assistent address (per-user rows)
+	Outputs          int64  `json:"outputs" example:"120"`      // Outputs paid to assistent addresses
+	Received         int64  `json:"received" example:"2500000"` // Satoshis paid in by users
+	Balance          int64  `json:"balance" example:"30000"`    // Satoshis in unspent outputs
+	PendingOutputs   int64  `json:"pendingOutputs" example:"2"` // Unspent outputs
+	FeesPaid         int64  `json:"feesPaid" example:"2470000"` // received - balance
+}
+
+// AssistentDashboard totals per chain and per user, largest balance first
+type AssistentDashboard struct {
+	Totals     []*AssistentBalance `json:"totals"`
+	Users      []*AssistentBalance `json:"users"`
+	NextCursor int                 `json:"nextCursor"` // Offset of the next page of users
+	HasMore    bool                `json:"hasMore"`
+}
+
+// AssistentDetail balances and unspent outputs of one user or assistent address
+type AssistentDetail struct {
+	Balances       []*AssistentBalance    `json:"balances"`
+	PendingOutputs []*model.AssistentUtxo `json:"pendingOutputs"` // Oldest first, at most 200
+}
+
+func toAssistentBalance(t *dao.AssistentTotals) *AssistentBalance {
+	return &AssistentBalance{
+		Chain:            t.Chain,
+		MetaId:           t.MetaId,
+		Address:          t.Address,
+		AssistentAddress: t.AssistentAddress,
+		Outputs:          t.Outputs,
+		Received:         t.Received,
+		Balance:          t.Balance,
+		PendingOutputs:   t.PendingOutputs,
+		FeesPaid:         t.Received - t.Balance,
+	}
+}
+
+func toAssistentBalances(totals []*dao.AssistentTotals) []*AssistentBalance {
+	balances := make([]*AssistentBalance, 0, len(totals))
+	for _, t := range totals {
+		balances = append(balances, toAssistentBalance(t))
+	}
+	return balances
+}
+
+// GetAssistentDashboard returns the ledger totals of each chain and a page of
+// per-user totals (cursor is the offset of the page), optionally for one chain
+func (s *UploadService) GetAssistentDashboard(chain string, cursor, size int) (*AssistentDashboard, error) {
+	if cursor < 0 {
+		cursor = 0
+	}
+	if size <= 0 {
+		size = 20
+	}
+	if size > maxAssistentPageSize {
+		size = maxAssistentPageSize
+	}
+
+	totals, err := s.assistentUtxoDAO.TotalsByChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.assistentUtxoDAO.TotalsByUser(chain, "", cursor, size+1)
+	if err != nil {
+		return nil, err
+	}
+	dashboard := &AssistentDashboard{Totals: toAssistentBalances(totals)}
+	if len(users) > size {
+		users = users[:size]
+		dashboard.HasMore = true
+		dashboard.NextCursor = cursor + size
+	}
+	dashboard.Users = toAssistentBalances(users)
+	return dashboard, nil
+}
+
+// GetAssistentDetail returns the totals and unspent outputs of a user address
+// or an assistent address
+func (s *UploadService) GetAssistentDetail(address string) (*AssistentDetail, error) {
+	totals, err := s.assistentUtxoDAO.TotalsByUser("", address, 0, maxAssistentPageSize)
+	if err != nil {
+		return nil, err
+	}
+	utxos, err := s.assistentUtxoDAO.ListUnspent(address, maxAssistentPendingOutputs)
+	if err != nil {
+		return nil, err
+	}
+	return &AssistentDetail{Balances: toAssistentBalances(totals), PendingOutputs: utxos}, nil
+}
+
+// ledgerOutput / ledgerTx chain-independent view of a broadcast transaction
+type ledgerOutput struct {
+	value    int64
+	pkScript []byte
+}
+
+type ledgerTx struct {
+	txID    string
+	size    int // Serialized bytes
+	inputs  []ledgerOutPoint
+	outputs []ledgerOutput
+}
+
+type ledgerOutPoint struct {
+	txID string
+	vout uint32
+}
+
+// decodeChainTx decodes txHex of chain (doge, otherwise mvc)
+func decodeChainTx(chain, txHex string) (*ledgerTx, error) {
+	lt := &ledgerTx{size: len(strings.TrimSpace(txHex)) / 2}
+	if chain == "doge" {
+		tx, err := common.DecodeDogeTx(txHex)
+		if err != nil {
+			return nil, err
+		}
+		lt.txID = tx.TxHash().String()
+		for _, in := range tx.TxIn {
+			lt.inputs = append(lt.inputs, ledgerOutPoint{in.PreviousOutPoint.Hash.String(), in.PreviousOutPoint.Index})
+		}
+		for _, out := range tx.TxOut {
+			lt.outputs = append(lt.outputs, ledgerOutput{out.Value, out.PkScript})
+		}
+		return lt, nil
+	}
+
+	tx, err := decodeMvcTx(txHex)
+	if err != nil {
+		return nil, err
+	}
+	lt.txID = common.GetMvcTxhashFromRaw(txHex)
+	for _, in := range tx.TxIn {
+		lt.inputs = append(lt.inputs, ledgerOutPoint{in.PreviousOutPoint.Hash.String(), in.PreviousOutPoint.Index})
+	}
+	for _, out := range tx.TxOut {
+		lt.outputs = append(lt.outputs, ledgerOutput{out.Value, out.PkScript})
+	}
+	return lt, nil
+}
+
+// decodeLedgerTx decodes txHex of chain (doge, otherwise mvc) and builds the
+// locking script of the assistent address on that chain
+func decodeLedgerTx(chain, txHex, assistentAddress string) (*ledgerTx, []byte, error) {
+	lt, err := decodeChainTx(chain, txHex)
+	if err != nil {
+		return nil, nil, err
+	}
+	if chain == "doge" {
+		pkScript, err := scripts.PayToDogeAddress(assistentAddress, common.DogeMainNetParams)
+		return lt, pkScript, err
+	}
+	netParam := &chaincfg2.TestNet3Params
+	if conf.Cfg.Net == "mainnet" {
+		netParam = &chaincfg2.MainNetParams
+	}
+	pkScript, err := scripts.PayToMvcAddress(assistentAddress, netParam)
+	return lt, pkScript, err
+}
+
+// recordAssistentTx updates the assistent ledger after txHex was broadcast
+// for the user at address: outputs of the ledger it spends are marked spent
+// and its outputs paying the user's assistent address are recorded. Ledger
+// errors are only logged; they never fail an upload.
+func (s *UploadService) recordAssistentTx(chain, address, txHex string) {
+	assistent, err := s.fileAssistentDAO.GetByAddress(address)
+	if err != nil || assistent == nil {
+		if err != nil {
+			log.Printf("Assistent ledger: failed to load assistent of %s: %v", address, err)
+		}
+		return
+	}
+	tx, assistentPkScript, err := decodeLedgerTx(chain, txHex, assistent.AssistentAddress)
+	if err != nil {
+		log.Printf("Assistent ledger: failed to decode %s transaction: %v", chain, err)
+		return
+	}
+
+	now := time.Now()
+	spendsAssistent := false
+	for _, in := range tx.inputs {
+		spent, err := s.assistentUtxoDAO.MarkSpent(in.txID, in.vout, tx.txID, now)
+		if err != nil {
+			log.Printf("Assistent ledger: failed to mark %s:%d spent: %v", in.txID, in.vout, err)
+			continue
+		}
+		spendsAssistent = spendsAssistent || spent != nil
+	}
+
+	for vout, out := range tx.outputs {
+		if !bytes.Equal(out.pkScript, assistentPkScript) {
+			continue
+		}
+		utxo := &model.AssistentUtxo{
+			Chain:            chain,
+			MetaId:           assistent.MetaId,
+			Address:          address,
+			AssistentAddress: assistent.AssistentAddress,
+			TxId:             tx.txID,
+			Vout:             uint32(vout),
+			Value:            out.value,
+			IsChange:         spendsAssistent,
+		}
+		if err := s.assistentUtxoDAO.Record(utxo); err != nil {
+			log.Printf("Assistent ledger: failed to record %s:%d: %v", tx.txID, vout, err)
+		}
+	}
+}
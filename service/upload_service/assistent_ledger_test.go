@@ -0,0 +1,63 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+)
+
+func TestDecodeLedgerTx_Mvc(t *testing.T) {
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{Net: "testnet"}
+	t.Cleanup(func() { conf.Cfg = prev })
+
+	addr, err := bsvutil2.NewAddressPubKeyHash(bytes.Repeat([]byte{7}, 20), &chaincfg2.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assistent := addr.EncodeAddress()
+	pkScript, err := scripts.PayToMvcAddress(assistent, &chaincfg2.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := wire2.NewMsgTx(10)
+	tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(&chainhash2.Hash{9}, 3), nil))
+	tx.AddTxOut(wire2.NewTxOut(0, []byte{0x6a}))
+	tx.AddTxOut(wire2.NewTxOut(1500, pkScript))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	txHex := hex.EncodeToString(buf.Bytes())
+
+	lt, assistentPkScript, err := decodeLedgerTx("mvc", txHex, assistent)
+	if err != nil {
+		t.Fatalf("decodeLedgerTx: %v", err)
+	}
+	if lt.txID != common.GetMvcTxhashFromRaw(txHex) {
+		t.Errorf("txID = %s, want %s", lt.txID, common.GetMvcTxhashFromRaw(txHex))
+	}
+	if len(lt.inputs) != 1 || lt.inputs[0].txID != (&chainhash2.Hash{9}).String() || lt.inputs[0].vout != 3 {
+		t.Errorf("inputs = %+v", lt.inputs)
+	}
+	if len(lt.outputs) != 2 || lt.outputs[1].value != 1500 {
+		t.Fatalf("outputs = %+v", lt.outputs)
+	}
+	if bytes.Equal(lt.outputs[0].pkScript, assistentPkScript) || !bytes.Equal(lt.outputs[1].pkScript, assistentPkScript) {
+		t.Error("assistent output not matched by its locking script")
+	}
+
+	if _, _, err := decodeLedgerTx("mvc", "zz", assistent); err == nil {
+		t.Error("invalid hex decoded")
+	}
+}
@@ -0,0 +1,390 @@
+package upload_service
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	"github.com/btcsuite/btcd/btcec/v2"
+	btcchainhash "github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/node"
+)
+
+// Destinations of the sweep of a rotated assistent address
+const (
+	SweepToAssistent = "assistent" // The new assistent address (default)
+	SweepToUser      = "user"      // The user's own address
+)
+
+const (
+	// maxAssistentSweepInputs outputs spent by one sweep transaction; a retry
+	// sweeps what is left
+	maxAssistentSweepInputs = 500
+	// maxAssistentRotationPageSize largest page of ListAssistentRotations
+	maxAssistentRotationPageSize = 100
+)
+
+var (
+	// ErrAssistentNotFound the user has no active assistent on the uploader
+	ErrAssistentNotFound = errors.New("no assistent for this address")
+	// ErrAssistentInUse upload tasks of the user still hold transactions
+	// signed by the assistent key
+	ErrAssistentInUse = errors.New("assistent is used by upload tasks")
+	// ErrRotationNotFound no rotation with that ID
+	ErrRotationNotFound = errors.New("assistent rotation not found")
+	// ErrInvalidRotation the rotate request is missing or has bad parameters
+	ErrInvalidRotation = errors.New("invalid rotation request")
+)
+
+// RotateAssistentRequest replaces the assistent key of a user
+type RotateAssistentRequest struct {
+	Address     string // User address (required)
+	Chain       string // mvc (default) or doge
+	SweepTo     string // assistent (default) or user
+	Reason      string // Why the key is rotated, kept in the audit trail
+	FeeRate     int64  // Sweep fee rate (sat/byte), 0 = chain default
+	Force       bool   // Rotate even while upload tasks of the user may still broadcast
+	RequestedBy string // Client IP of the admin request
+}
+
+// AssistentRotationList rotations of a user, newest first
+type AssistentRotationList struct {
+	Rotations  []*model.AssistentRotation `json:"rotations"`
+	NextCursor int64                      `json:"nextCursor"` // Pass as cursor for the next page
+	HasMore    bool                       `json:"hasMore"`
+}
+
+// RotateFileAssistent replaces the assistent key of a user, e.g. when it is
+// suspected leaked: a new key is generated and becomes the user's assistent,
+// the old one is retired, and the unspent outputs the ledger holds for the
+// old address are swept to the new assistent address or back to the user.
+// The rotation is recorded in the audit trail first, so a failed sweep can
+// be retried with SweepRetiredAssistent.
+func (s *UploadService) RotateFileAssistent(req *RotateAssistentRequest) (*model.AssistentRotation, error) {
+	if req.Address == "" {
+		return nil, fmt.Errorf("%w: address is required", ErrInvalidRotation)
+	}
+	chain := req.Chain
+	if chain == "" {
+		chain = "mvc"
+	}
+	if chain != "mvc" && chain != "doge" {
+		return nil, fmt.Errorf("%w: unsupported chain %s", ErrInvalidRotation, chain)
+	}
+	sweepTo := req.SweepTo
+	if sweepTo == "" {
+		sweepTo = SweepToAssistent
+	}
+	if sweepTo != SweepToAssistent && sweepTo != SweepToUser {
+		return nil, fmt.Errorf("%w: sweepTo must be %s or %s", ErrInvalidRotation, SweepToAssistent, SweepToUser)
+	}
+
+	old, err := s.fileAssistentDAO.GetByAddress(req.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assistent: %w", err)
+	}
+	if old == nil {
+		return nil, ErrAssistentNotFound
+	}
+	if !req.Force {
+		active, err := s.fileUploaderTaskDAO.CountActiveByAddress(req.Address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count upload tasks: %w", err)
+		}
+		if active > 0 {
+			return nil, fmt.Errorf("%w: %d tasks may still broadcast; wait for them, cancel them or set force", ErrAssistentInUse, active)
+		}
+	}
+
+	var next *model.FileAssistent
+	if chain == "doge" {
+		next, err = newFileAssistentDoge(old.MetaId, old.Address, common.DogeMainNetParams)
+	} else {
+		next, err = newFileAssistent(old.MetaId, old.Address, mvcNetParam())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &model.AssistentRotation{
+		Chain:               chain,
+		MetaId:              old.MetaId,
+		Address:             old.Address,
+		OldAssistentAddress: old.AssistentAddress,
+		NewAssistentAddress: next.AssistentAddress,
+		Reason:              strings.TrimSpace(req.Reason),
+		RequestedBy:         req.RequestedBy,
+		SweepTo:             sweepTo,
+		SweepAddress:        next.AssistentAddress,
+		SweepStatus:         model.AssistentSweepNone,
+	}
+	if sweepTo == SweepToUser {
+		rotation.SweepAddress = old.Address
+	}
+	if err := s.fileAssistentDAO.Rotate(old, next, rotation, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to rotate assistent: %w", err)
+	}
+	log.Printf("Assistent of %s rotated: %s retired for %s (%s)", old.Address, old.AssistentAddress, next.AssistentAddress, rotation.Reason)
+
+	s.sweepAssistent(rotation, old, req.FeeRate)
+	return rotation, nil
+}
+
+// SweepRetiredAssistent sweeps the outputs still held by the retired address
+// of a rotation, e.g. after its sweep failed or when more than one sweep
+// transaction's worth of outputs was left
+func (s *UploadService) SweepRetiredAssistent(rotationID int64, feeRate int64) (*model.AssistentRotation, error) {
+	rotation, err := s.rotationDAO.GetByID(rotationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rotation: %w", err)
+	}
+	if rotation == nil {
+		return nil, ErrRotationNotFound
+	}
+	old, err := s.fileAssistentDAO.GetByID(rotation.OldAssistentId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assistent: %w", err)
+	}
+	if old == nil {
+		return nil, fmt.Errorf("retired assistent %d no longer exists", rotation.OldAssistentId)
+	}
+	s.sweepAssistent(rotation, old, feeRate)
+	return rotation, nil
+}
+
+// ListAssistentRotations lists the rotations of a user address (or of the
+// rotation that retired an assistent address), newest first
+func (s *UploadService) ListAssistentRotations(address string, cursor int64, size int) (*AssistentRotationList, error) {
+	if size <= 0 || size > maxAssistentRotationPageSize {
+		size = maxAssistentRotationPageSize
+	}
+	rotations, err := s.rotationDAO.ListByAddress(address, cursor, size+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotations: %w", err)
+	}
+	list := &AssistentRotationList{Rotations: rotations}
+	if len(rotations) > size {
+		list.Rotations, list.HasMore = rotations[:size], true
+		list.NextCursor = rotations[size-1].ID
+	}
+	return list, nil
+}
+
+// sweepAssistent moves the unspent ledger outputs of the retired assistent
+// old to the sweep address of rotation and records the outcome on it. The
+// ledger is updated like for any transaction the uploader broadcasts.
+func (s *UploadService) sweepAssistent(rotation *model.AssistentRotation, old *model.FileAssistent, feeRate int64) {
+	utxos, err := s.assistentUtxoDAO.ListUnspent(old.AssistentAddress, maxAssistentSweepInputs)
+	if err == nil {
+		kept := utxos[:0]
+		for _, u := range utxos {
+			if u.Chain == rotation.Chain && u.AssistentAddress == old.AssistentAddress {
+				kept = append(kept, u)
+			}
+		}
+		utxos = kept
+	}
+	if err == nil && len(utxos) == 0 {
+		if rotation.SweepStatus != model.AssistentSweepSuccess {
+			rotation.SweepStatus = model.AssistentSweepNone
+		}
+		rotation.SweepError = ""
+		s.saveAssistentRotation(rotation)
+		return
+	}
+
+	var txHex, txID string
+	var swept, fee int64
+	if err == nil {
+		if feeRate <= 0 {
+			_, _, feeRate = conf.GetUploaderChainParam(rotation.Chain)
+		}
+		if feeRate <= 0 {
+			feeRate = 1
+		}
+		dustLimit := conf.GetUploaderChainPolicy(rotation.Chain).DustLimit
+		if rotation.Chain == "doge" {
+			txHex, swept, fee, err = buildAssistentSweepTxDoge(old, utxos, rotation.SweepAddress, feeRate, dustLimit)
+		} else {
+			txHex, swept, fee, err = buildAssistentSweepTx(old, utxos, rotation.SweepAddress, feeRate, dustLimit)
+		}
+	}
+	if err == nil {
+		err = s.preflightTransactions(rotation.Chain, []preflightTx{{"sweep", txHex}})
+	}
+	if err == nil {
+		// Outputs an upload in flight is spending are left for the next sweep
+		lockOwner := fmt.Sprintf("sweep:%d", rotation.ID)
+		if err = s.lockUploadInputs(lockOwner, rotation.Chain, nil, txHex); err == nil {
+			defer s.releaseUtxoLocks(lockOwner)
+		}
+	}
+	if err == nil {
+		rpcChain := conf.Cfg.Net
+		if rotation.Chain == "doge" {
+			rpcChain = "doge"
+		}
+		txID, err = node.BroadcastTxResilient(rpcChain, txHex)
+	}
+	if err != nil {
+		log.Printf("Sweep of retired assistent %s failed: %v", old.AssistentAddress, err)
+		rotation.SweepStatus = model.AssistentSweepFailed
+		rotation.SweepError = err.Error()
+		s.saveAssistentRotation(rotation)
+		return
+	}
+
+	s.recordAssistentTx(rotation.Chain, rotation.Address, txHex)
+	log.Printf("Swept %d outputs (%d satoshis) of retired assistent %s to %s: %s", len(utxos), swept, old.AssistentAddress, rotation.SweepAddress, txID)
+	rotation.SweepStatus = model.AssistentSweepSuccess
+	rotation.SweepTxId = txID
+	rotation.SweptOutputs += len(utxos)
+	rotation.SweptValue += swept
+	rotation.SweepFee += fee
+	rotation.SweepError = ""
+	s.saveAssistentRotation(rotation)
+}
+
+func (s *UploadService) saveAssistentRotation(rotation *model.AssistentRotation) {
+	if err := s.rotationDAO.Update(rotation); err != nil {
+		log.Printf("Failed to save assistent rotation %d: %v", rotation.ID, err)
+	}
+}
+
+// estimateSweepFee fee of a P2PKH transaction spending inputs into one output
+func estimateSweepFee(inputs int, feeRate int64) int64 {
+	return int64(10+inputs*148+34) * feeRate
+}
+
+// sweepValue returns what is left of the inputs after the fee, or an error
+// when that is below the dust limit
+func sweepValue(utxos []*model.AssistentUtxo, feeRate, dustLimit int64) (int64, int64, error) {
+	var total int64
+	for _, u := range utxos {
+		total += u.Value
+	}
+	fee := estimateSweepFee(len(utxos), feeRate)
+	if total-fee < dustLimit {
+		return 0, 0, fmt.Errorf("%d satoshis in %d outputs do not cover the sweep fee %d plus the dust limit %d", total, len(utxos), fee, dustLimit)
+	}
+	return total - fee, fee, nil
+}
+
+// buildAssistentSweepTx signs an MVC transaction spending utxos of the
+// assistent into one output paying toAddress
+func buildAssistentSweepTx(assistent *model.FileAssistent, utxos []*model.AssistentUtxo, toAddress string, feeRate, dustLimit int64) (string, int64, int64, error) {
+	value, fee, err := sweepValue(utxos, feeRate, dustLimit)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	netParam := mvcNetParam()
+	pkScript, err := scripts.PayToMvcAddress(assistent.AssistentAddress, netParam)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to build assistent pkScript: %w", err)
+	}
+	toScript, err := scripts.PayToMvcAddress(toAddress, netParam)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to build sweep pkScript: %w", err)
+	}
+	privateKeyBytes, err := hex.DecodeString(assistent.AssistentPriHex)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to decode assistent private key: %w", err)
+	}
+	privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
+
+	tx := wire2.NewMsgTx(10)
+	for _, u := range utxos {
+		hash, err := chainhash2.NewHashFromStr(u.TxId)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to parse txid %s: %w", u.TxId, err)
+		}
+		tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(hash, u.Vout), nil))
+	}
+	tx.AddTxOut(wire2.NewTxOut(value, toScript))
+	for i, u := range utxos {
+		sigScript, err := txscript2.SignatureScript(tx, i, u.Value, pkScript, txscript2.SigHashAll, privateKey, true)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to sign sweep input %d: %w", i, err)
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	txHex, err := common.MvcToRaw(tx)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to serialize sweep tx: %w", err)
+	}
+	return txHex, value, fee, nil
+}
+
+// buildAssistentSweepTxDoge signs a DOGE transaction spending utxos of the
+// assistent into one output paying toAddress
+func buildAssistentSweepTxDoge(assistent *model.FileAssistent, utxos []*model.AssistentUtxo, toAddress string, feeRate, dustLimit int64) (string, int64, int64, error) {
+	value, fee, err := sweepValue(utxos, feeRate, dustLimit)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	pkScript, err := scripts.PayToDogeAddress(assistent.AssistentAddress, common.DogeMainNetParams)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to build assistent pkScript: %w", err)
+	}
+	toScript, err := scripts.PayToDogeAddress(toAddress, common.DogeMainNetParams)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to build sweep pkScript: %w", err)
+	}
+	privateKeyBytes, err := hex.DecodeString(assistent.AssistentPriHex)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to decode assistent private key: %w", err)
+	}
+	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
+
+	tx := wire.NewMsgTx(2)
+	for _, u := range utxos {
+		hash, err := btcchainhash.NewHashFromStr(u.TxId)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to parse txid %s: %w", u.TxId, err)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, u.Vout), nil, nil))
+	}
+	tx.AddTxOut(wire.NewTxOut(value, toScript))
+	for i := range utxos {
+		signature, err := txscript.RawTxInSignature(tx, i, pkScript, txscript.SigHashAll, privateKey)
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to sign sweep input %d: %w", i, err)
+		}
+		sigScript, err := txscript.NewScriptBuilder().
+			AddData(signature).
+			AddData(privateKey.PubKey().SerializeCompressed()).
+			Script()
+		if err != nil {
+			return "", 0, 0, fmt.Errorf("failed to build sig script: %w", err)
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	txHex, err := common.ToRaw(tx)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("failed to serialize sweep tx: %w", err)
+	}
+	return txHex, value, fee, nil
+}
+
+// mvcNetParam returns the MVC network parameters of conf.Cfg.Net
+func mvcNetParam() *chaincfg2.Params {
+	if conf.Cfg != nil && conf.Cfg.Net == "mainnet" {
+		return &chaincfg2.MainNetParams
+	}
+	return &chaincfg2.TestNet3Params
+}
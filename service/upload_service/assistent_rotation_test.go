@@ -0,0 +1,51 @@
+package upload_service
+
+import (
+	"strings"
+	"testing"
+
+	"meta-file-system/common"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+func TestBuildAssistentSweepTx(t *testing.T) {
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{Net: "testnet"}
+	t.Cleanup(func() { conf.Cfg = prev })
+
+	old, err := newFileAssistent("meta", "", mvcNetParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := newFileAssistent("meta", "", mvcNetParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := []*model.AssistentUtxo{
+		{TxId: strings.Repeat("11", 32), Vout: 0, Value: 5000},
+		{TxId: strings.Repeat("22", 32), Vout: 3, Value: 2000},
+	}
+
+	txHex, swept, fee, err := buildAssistentSweepTx(old, utxos, next.AssistentAddress, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee != estimateSweepFee(2, 1) || swept != 7000-fee {
+		t.Fatalf("swept %d fee %d, want %d %d", swept, fee, 7000-estimateSweepFee(2, 1), estimateSweepFee(2, 1))
+	}
+	lt, err := decodeChainTx("mvc", txHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lt.inputs) != 2 || lt.inputs[1].vout != 3 || len(lt.outputs) != 1 || lt.outputs[0].value != swept {
+		t.Fatalf("unexpected sweep tx: %+v", lt)
+	}
+	if lt.txID != common.GetMvcTxhashFromRaw(txHex) {
+		t.Fatalf("txid %s, want %s", lt.txID, common.GetMvcTxhashFromRaw(txHex))
+	}
+
+	if _, _, _, err := buildAssistentSweepTx(old, utxos[1:], next.AssistentAddress, 20, 1); err == nil {
+		t.Fatal("expected an error when the fee eats the output")
+	}
+}
@@ -3,12 +3,15 @@ package upload_service
 import (
 	"log"
 	"time"
+
+	"meta-file-system/conf"
 )
 
 // CleanupProcessor 清理过期上传的处理器
 type CleanupProcessor struct {
 	uploadService *UploadService
 	stopChan      chan struct{}
+	done          chan struct{} // Closed when run returns
 	interval      time.Duration
 	batchSize     int
 	expiredBefore time.Duration // 清理多少时间之前过期的记录（例如：1小时前过期的）
@@ -19,6 +22,7 @@ func NewCleanupProcessor(uploadService *UploadService) *CleanupProcessor {
 	return &CleanupProcessor{
 		uploadService: uploadService,
 		stopChan:      make(chan struct{}),
+		done:          make(chan struct{}),
 		interval:      10 * time.Minute, // 每10分钟执行一次清理
 		batchSize:     100,              // 每次处理100条记录
 		expiredBefore: 1 * time.Hour,    // 清理1小时前过期的记录（给一些缓冲时间）
@@ -39,6 +43,7 @@ func (cp *CleanupProcessor) Stop() {
 
 // run 运行清理处理器主循环
 func (cp *CleanupProcessor) run() {
+	defer close(cp.done)
 	ticker := time.NewTicker(cp.interval)
 	defer ticker.Stop()
 
@@ -86,4 +91,63 @@ func (cp *CleanupProcessor) cleanupExpiredUploads() {
 	if deletedCount > 0 {
 		log.Printf("Deleted %d expired upload records from database", deletedCount)
 	}
+
+	cp.pruneEphemeralFiles()
+	cp.expireStaleTasks()
+	cp.deleteExpiredIdempotencyKeys()
+	cp.deleteExpiredUtxoLocks()
+}
+
+// deleteExpiredIdempotencyKeys 删除超过 uploader.idempotency.ttl_hours 的幂等键及其保存的响应
+func (cp *CleanupProcessor) deleteExpiredIdempotencyKeys() {
+	deletedCount, err := cp.uploadService.DeleteExpiredIdempotencyKeys(time.Now())
+	if err != nil {
+		log.Printf("Failed to delete expired idempotency keys: %v", err)
+	}
+
+	if deletedCount > 0 {
+		log.Printf("Deleted %d expired idempotency keys", deletedCount)
+	}
+}
+
+// deleteExpiredUtxoLocks 删除超过 uploader.utxo_lock.ttl_minutes 的输入锁
+func (cp *CleanupProcessor) deleteExpiredUtxoLocks() {
+	deletedCount, err := cp.uploadService.DeleteExpiredUtxoLocks(time.Now())
+	if err != nil {
+		log.Printf("Failed to delete expired input locks: %v", err)
+	}
+
+	if deletedCount > 0 {
+		log.Printf("Deleted %d expired input locks", deletedCount)
+	}
+}
+
+// expireStaleTasks 将超过 uploader.task_ttl 仍未广播的异步上传任务标记为 expired
+func (cp *CleanupProcessor) expireStaleTasks() {
+	expiredCount, err := cp.uploadService.ExpireStaleTasks(time.Now())
+	if err != nil {
+		log.Printf("Failed to expire stale upload tasks: %v", err)
+	}
+
+	if expiredCount > 0 {
+		log.Printf("Expired %d stale upload tasks", expiredCount)
+	}
+}
+
+// pruneEphemeralFiles 清理超过保留期的 ephemeral 文件本地记录（链上数据不受影响）
+func (cp *CleanupProcessor) pruneEphemeralFiles() {
+	if conf.Cfg == nil || conf.Cfg.Uploader.EphemeralRetentionHours <= 0 {
+		return
+	}
+	beforeTime := time.Now().Add(-time.Duration(conf.Cfg.Uploader.EphemeralRetentionHours) * time.Hour)
+
+	prunedCount, err := cp.uploadService.PruneEphemeralFiles(beforeTime, cp.batchSize)
+	if err != nil {
+		log.Printf("Failed to prune ephemeral files: %v", err)
+		return
+	}
+
+	if prunedCount > 0 {
+		log.Printf("Pruned %d ephemeral file records", prunedCount)
+	}
 }
@@ -0,0 +1,405 @@
+package upload_service
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/node"
+)
+
+const (
+	// maxDelegatedPageSize largest page of the charge and invoice listings
+	maxDelegatedPageSize = 100
+	// sigHashNoneAnyoneCanPay the "signNull" flags of chunked upload pre-tx inputs
+	sigHashNoneAnyoneCanPay = txscript2.SigHashNone | txscript2.SigHashAnyOneCanPay
+)
+
+var (
+	// ErrDelegatedDisabled delegated uploads are not enabled
+	ErrDelegatedDisabled = errors.New("delegated upload is disabled")
+	// ErrDelegatedUnauthorized the API key is missing or unknown
+	ErrDelegatedUnauthorized = errors.New("invalid or missing API key")
+	// ErrDelegatedFeeLimit the upload would cost more than uploader.delegated.max_fee_per_upload
+	ErrDelegatedFeeLimit = errors.New("delegated upload exceeds the per-upload fee limit")
+	// ErrDelegatedFunding the hot wallet failed to fund the upload
+	ErrDelegatedFunding = errors.New("delegated upload funding failed")
+)
+
+// delegatedWalletRPC returns the hot wallet node RPC of delegated uploads
+func delegatedWalletRPC() (string, string, string) {
+	cfg := conf.Cfg.Uploader.Delegated
+	if cfg.RpcUrl != "" {
+		return cfg.RpcUrl, cfg.RpcUser, cfg.RpcPass
+	}
+	rpc := conf.RpcConfigMap["mvc"]
+	return rpc.Url, rpc.Username, rpc.Password
+}
+
+// delegatedSend pays amount satoshis from the hot wallet to address; replaced in tests
+var delegatedSend = func(address string, amount int64) (string, error) {
+	url, user, pass := delegatedWalletRPC()
+	if url == "" {
+		return "", fmt.Errorf("hot wallet RPC is not configured")
+	}
+	return node.SendToAddress(url, user, pass, address, amount)
+}
+
+// delegatedRawTx fetches a hot wallet payment; replaced in tests
+var delegatedRawTx = func(txId string) (string, error) {
+	url, user, pass := delegatedWalletRPC()
+	return node.GetRawTransactionFrom(url, user, pass, txId)
+}
+
+// DelegatedClient returns the client name of apiKey when delegated uploads
+// are enabled and the key is one of uploader.delegated.api_keys
+func DelegatedClient(apiKey string) (string, error) {
+	cfg := conf.Cfg.Uploader.Delegated
+	if !cfg.Enabled {
+		return "", ErrDelegatedDisabled
+	}
+	if apiKey == "" {
+		return "", ErrDelegatedUnauthorized
+	}
+	for client, key := range cfg.ApiKeys {
+		if key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			return client, nil
+		}
+	}
+	return "", ErrDelegatedUnauthorized
+}
+
+// DelegatedUploadResponse result of a delegated upload and the charge recorded for it
+type DelegatedUploadResponse struct {
+	*ChunkedUploadResponse
+	ChargeId int64 `json:"chargeId" example:"42"`    // Charge recorded for the upload
+	Charged  int64 `json:"charged" example:"125000"` // Satoshis sent by the hot wallet, invoiced to the user
+}
+
+// DelegatedUpload runs a chunked MVC upload funded by the operator hot wallet
+// instead of client-built pre-transactions. The hot wallet pays the estimated
+// chunk and index funding to the user's assistent address; the uploader
+// builds the two pre-transactions from those outputs, runs ChunkedUpload with
+// broadcast, and records what the hot wallet sent as an unsettled charge of
+// the user. A charge is recorded once the hot wallet has paid, even when the
+// upload then fails: the value stays on the user's assistent address.
+func (s *UploadService) DelegatedUpload(client string, req *ChunkedUploadRequest) (*DelegatedUploadResponse, error) {
+	if req.Chain != "" && req.Chain != "mvc" {
+		return nil, fmt.Errorf("delegated upload supports the mvc chain only")
+	}
+	if req.Address == "" {
+		return nil, fmt.Errorf("user address is required")
+	}
+	netParam := &chaincfg2.MainNetParams
+	if conf.Cfg.Net != "mainnet" {
+		netParam = &chaincfg2.TestNet3Params
+	}
+	if _, err := bsvutil2.DecodeAddress(req.Address, netParam); err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	// Before the hot wallet pays anything
+	if err := verifyContentSHA256(req.ContentSHA256, req.Content); err != nil {
+		return nil, err
+	}
+
+	// Replace the content by its delta once, before the estimate sizes it
+	if err := s.prepareDeltaUpload("mvc", req.DeltaBasePinId, &req.Delta, &req.Content, req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+
+	estimate, err := s.EstimateChunkedUpload(&EstimateChunkedUploadRequest{
+		FileName:    req.FileName,
+		Content:     req.Content,
+		Path:        req.Path,
+		ContentType: req.ContentType,
+		Chain:       "mvc",
+		FeeRate:     req.FeeRate,
+		Compression: req.Compression,
+		Encryption:  req.Encryption,
+		Delta:       req.Delta,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if limit := conf.Cfg.Uploader.Delegated.MaxFeePerUpload; estimate.TotalFee > limit {
+		return nil, fmt.Errorf("%w: needs %d satoshis, limit %d", ErrDelegatedFeeLimit, estimate.TotalFee, limit)
+	}
+
+	assistent, err := s.getOrCreateFileAssistent(req.MetaId, req.Address, netParam, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare assistent address: %w", err)
+	}
+	assistentPkScript, err := scripts.PayToMvcAddress(assistent.AssistentAddress, netParam)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build assistent pkScript: %w", err)
+	}
+
+	charge := &model.DelegatedCharge{
+		Client:  client,
+		MetaId:  req.MetaId,
+		Address: req.Address,
+		Chain:   "mvc",
+		Status:  model.ChargeUnsettled,
+	}
+	chunkInput, txId, err := s.fundDelegated(req.Address, assistent, assistentPkScript, estimate.ChunkPreTxFee)
+	if txId != "" {
+		charge.ChunkFundingTxId = txId
+		charge.Amount += estimate.ChunkPreTxFee
+	}
+	if err != nil {
+		if txId != "" {
+			s.saveDelegatedCharge(charge, model.StatusFailed, fmt.Sprintf("chunk funding failed: %v", err))
+		}
+		return nil, fmt.Errorf("%w: chunk funding: %w", ErrDelegatedFunding, err)
+	}
+
+	indexInput, txId, err := s.fundDelegated(req.Address, assistent, assistentPkScript, estimate.IndexPreTxFee)
+	if txId != "" {
+		charge.IndexFundingTxId = txId
+		charge.Amount += estimate.IndexPreTxFee
+	}
+	if err != nil {
+		s.saveDelegatedCharge(charge, model.StatusFailed, fmt.Sprintf("index funding failed: %v", err))
+		return nil, fmt.Errorf("%w: index funding: %w", ErrDelegatedFunding, err)
+	}
+
+	req.Chain = "mvc"
+	req.DryRun = false
+	req.IsBroadcast = true
+	req.preFunded = true
+	if req.ChunkPreTxHex, err = buildDelegatedPreTx(chunkInput); err == nil {
+		req.IndexPreTxHex, err = buildDelegatedPreTx(indexInput)
+	}
+	if err != nil {
+		s.saveDelegatedCharge(charge, model.StatusFailed, err.Error())
+		return nil, err
+	}
+
+	resp, err := s.ChunkedUpload(req)
+	if err != nil {
+		s.saveDelegatedCharge(charge, model.StatusFailed, err.Error())
+		return nil, err
+	}
+	charge.FileId = resp.FileId
+	charge.FileSize = int64(len(req.Content))
+	charge.IndexTxId = resp.IndexTxId
+	s.saveDelegatedCharge(charge, model.Status(resp.Status), resp.Message)
+
+	log.Printf("Delegated upload for %s (client %s): fileId=%s, charged %d satoshis", req.Address, client, resp.FileId, charge.Amount)
+	return &DelegatedUploadResponse{ChunkedUploadResponse: resp, ChargeId: charge.ID, Charged: charge.Amount}, nil
+}
+
+// fundDelegated pays amount from the hot wallet to the assistent address and
+// returns the paid output as a spendable input, plus the payment txid (also
+// set when the payment went out but its output could not be loaded). The
+// payment is recorded in the assistent ledger.
+func (s *UploadService) fundDelegated(address string, assistent *model.FileAssistent, assistentPkScript []byte, amount int64) (*common.TxInputUtxo, string, error) {
+	txId, err := delegatedSend(assistent.AssistentAddress, amount)
+	if err != nil {
+		return nil, "", err
+	}
+	txHex, err := delegatedRawTx(txId)
+	if err != nil {
+		return nil, txId, fmt.Errorf("failed to load payment %s: %w", txId, err)
+	}
+	tx, err := decodeMvcTx(txHex)
+	if err != nil {
+		return nil, txId, fmt.Errorf("failed to decode payment %s: %w", txId, err)
+	}
+	s.recordAssistentTx("mvc", address, txHex)
+
+	for vout, out := range tx.TxOut {
+		if out.Value == amount && bytes.Equal(out.PkScript, assistentPkScript) {
+			return &common.TxInputUtxo{
+				TxId:     txId,
+				TxIndex:  int64(vout),
+				PkScript: hex.EncodeToString(assistentPkScript),
+				Amount:   uint64(amount),
+				PriHex:   assistent.AssistentPriHex,
+			}, txId, nil
+		}
+	}
+	return nil, txId, fmt.Errorf("payment %s has no %d satoshi output to %s", txId, amount, assistent.AssistentAddress)
+}
+
+// buildDelegatedPreTx builds a pre-transaction spending input, signed
+// SIGHASH_NONE|ANYONECANPAY like client-built pre-txs so ChunkedUpload can
+// add its outputs
+func buildDelegatedPreTx(input *common.TxInputUtxo) (string, error) {
+	hash, err := chainhash2.NewHashFromStr(input.TxId)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse funding txid: %w", err)
+	}
+	privateKeyBytes, err := hex.DecodeString(input.PriHex)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode assistent private key: %w", err)
+	}
+	privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
+	pkScript, err := hex.DecodeString(input.PkScript)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode pkScript: %w", err)
+	}
+
+	tx := wire2.NewMsgTx(10)
+	tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(hash, uint32(input.TxIndex)), nil))
+	sigScript, err := txscript2.SignatureScript(tx, 0, int64(input.Amount), pkScript, sigHashNoneAnyoneCanPay, privateKey, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign pre-tx: %w", err)
+	}
+	tx.TxIn[0].SignatureScript = sigScript
+	return common.MvcToRaw(tx)
+}
+
+// saveDelegatedCharge records charge with the upload outcome; failures are
+// logged, since the hot wallet has already paid
+func (s *UploadService) saveDelegatedCharge(charge *model.DelegatedCharge, uploadStatus model.Status, message string) {
+	charge.UploadStatus = uploadStatus
+	if len(message) > 500 {
+		message = message[:500]
+	}
+	charge.Message = message
+	if err := s.delegatedChargeDAO.Create(charge); err != nil {
+		log.Printf("Delegated upload: failed to record charge of %s (%d satoshis, funding %s/%s): %v",
+			charge.Address, charge.Amount, charge.ChunkFundingTxId, charge.IndexFundingTxId, err)
+	}
+}
+
+// DelegatedChargeList a page of charges, newest first
+type DelegatedChargeList struct {
+	Charges    []*model.DelegatedCharge `json:"charges"`
+	NextCursor int64                    `json:"nextCursor"` // Pass as cursor for the next page
+	HasMore    bool                     `json:"hasMore"`
+}
+
+// DelegatedInvoice what one user owes for the uploads one client requested
+type DelegatedInvoice struct {
+	Client  string    `json:"client" example:"my-app"`
+	Address string    `json:"address"`
+	MetaId  string    `json:"metaId"`
+	Uploads int64     `json:"uploads" example:"12"`    // Charges
+	Amount  int64     `json:"amount" example:"840000"` // Satoshis
+	FirstAt time.Time `json:"firstAt"`                 // Oldest charge
+	LastAt  time.Time `json:"lastAt"`                  // Newest charge
+}
+
+// DelegatedInvoiceList invoices per client and user, largest amount first
+type DelegatedInvoiceList struct {
+	Status     string              `json:"status" example:"unsettled"` // Charges included
+	Invoices   []*DelegatedInvoice `json:"invoices"`
+	Total      int64               `json:"total"`      // Satoshis on this page
+	NextCursor int                 `json:"nextCursor"` // Offset of the next page
+	HasMore    bool                `json:"hasMore"`
+}
+
+// DelegatedSettleRequest settles unsettled charges of a user
+type DelegatedSettleRequest struct {
+	Address   string  // User address (required)
+	Client    string  // Only charges of this client (optional)
+	ChargeIds []int64 // Only these charges (optional, default all unsettled)
+	Reference string  // Payment reference: txid, invoice number, ... (required)
+}
+
+// DelegatedSettleResponse charges settled by a settle request
+type DelegatedSettleResponse struct {
+	Settled   int                      `json:"settled" example:"3"`     // Charges settled
+	Amount    int64                    `json:"amount" example:"210000"` // Satoshis settled
+	Reference string                   `json:"reference"`
+	Charges   []*model.DelegatedCharge `json:"charges"`
+}
+
+func delegatedPageSize(size int) int {
+	if size <= 0 || size > maxDelegatedPageSize {
+		return maxDelegatedPageSize
+	}
+	return size
+}
+
+// ListDelegatedCharges lists charges matching filter, newest first
+func (s *UploadService) ListDelegatedCharges(filter dao.DelegatedChargeFilter, cursor int64, size int) (*DelegatedChargeList, error) {
+	size = delegatedPageSize(size)
+	charges, err := s.delegatedChargeDAO.List(filter, cursor, size+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list charges: %w", err)
+	}
+	list := &DelegatedChargeList{Charges: charges}
+	if len(charges) > size {
+		list.Charges, list.HasMore = charges[:size], true
+		list.NextCursor = charges[size-1].ID
+	}
+	return list, nil
+}
+
+// ListDelegatedInvoices sums charges per client and user; status defaults to unsettled
+func (s *UploadService) ListDelegatedInvoices(client, status string, cursor, size int) (*DelegatedInvoiceList, error) {
+	if status == "" {
+		status = model.ChargeUnsettled
+	}
+	if status != model.ChargeUnsettled && status != model.ChargeSettled {
+		return nil, fmt.Errorf("status must be %s or %s", model.ChargeUnsettled, model.ChargeSettled)
+	}
+	size = delegatedPageSize(size)
+	if cursor < 0 {
+		cursor = 0
+	}
+	totals, err := s.delegatedChargeDAO.Invoices(dao.DelegatedChargeFilter{Client: client, Status: status}, cursor, size+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum charges: %w", err)
+	}
+	list := &DelegatedInvoiceList{Status: status, Invoices: make([]*DelegatedInvoice, 0, len(totals))}
+	if len(totals) > size {
+		totals, list.HasMore = totals[:size], true
+		list.NextCursor = cursor + size
+	}
+	for _, t := range totals {
+		list.Invoices = append(list.Invoices, &DelegatedInvoice{
+			Client:  t.Client,
+			Address: t.Address,
+			MetaId:  t.MetaId,
+			Uploads: t.Uploads,
+			Amount:  t.Amount,
+			FirstAt: t.FirstAt,
+			LastAt:  t.LastAt,
+		})
+		list.Total += t.Amount
+	}
+	return list, nil
+}
+
+// SettleDelegatedCharges marks unsettled charges of a user paid
+func (s *UploadService) SettleDelegatedCharges(req *DelegatedSettleRequest) (*DelegatedSettleResponse, error) {
+	if req.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+	if req.Reference == "" {
+		return nil, fmt.Errorf("reference is required")
+	}
+	if len(req.Reference) > 255 {
+		return nil, fmt.Errorf("reference exceeds 255 characters")
+	}
+	charges, err := s.delegatedChargeDAO.Settle(dao.DelegatedChargeFilter{Client: req.Client, Address: req.Address}, req.ChargeIds, req.Reference, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to settle charges: %w", err)
+	}
+	resp := &DelegatedSettleResponse{Settled: len(charges), Reference: req.Reference, Charges: charges}
+	for _, charge := range charges {
+		resp.Amount += charge.Amount
+	}
+	log.Printf("Delegated upload: settled %d charges of %s (%d satoshis), reference %s", resp.Settled, req.Address, resp.Amount, req.Reference)
+	return resp, nil
+}
@@ -0,0 +1,101 @@
+package upload_service
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/common"
+	"meta-file-system/conf"
+)
+
+func TestDelegatedClient(t *testing.T) {
+	prevCfg := conf.Cfg
+	t.Cleanup(func() { conf.Cfg = prevCfg })
+
+	delegated := conf.UploadDelegatedConfig{ApiKeys: map[string]string{"my-app": "key-1", "other": "key-2", "revoked": ""}}
+	conf.Cfg = &conf.Config{Uploader: conf.UploaderConfig{Delegated: delegated}}
+	if _, err := DelegatedClient("key-1"); !errors.Is(err, ErrDelegatedDisabled) {
+		t.Fatalf("disabled: err = %v, want ErrDelegatedDisabled", err)
+	}
+
+	delegated.Enabled = true
+	conf.Cfg = &conf.Config{Uploader: conf.UploaderConfig{Delegated: delegated}}
+	tests := []struct {
+		key    string
+		client string
+	}{
+		{"key-1", "my-app"},
+		{"key-2", "other"},
+		{"", ""},
+		{"key-3", ""},
+		{"key-1 ", ""},
+	}
+	for _, tt := range tests {
+		client, err := DelegatedClient(tt.key)
+		if tt.client == "" {
+			if !errors.Is(err, ErrDelegatedUnauthorized) {
+				t.Errorf("key %q: client %q, err %v; want ErrDelegatedUnauthorized", tt.key, client, err)
+			}
+			continue
+		}
+		if err != nil || client != tt.client {
+			t.Errorf("key %q: client %q, err %v; want %q", tt.key, client, err, tt.client)
+		}
+	}
+}
+
+func TestBuildDelegatedPreTx(t *testing.T) {
+	privateKey, err := bsvec2.NewPrivateKey(bsvec2.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := bsvutil2.NewAddressPubKeyHash(bsvutil2.Hash160(privateKey.PubKey().SerializeCompressed()), &chaincfg2.TestNet3Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkScript, err := txscript2.PayToAddrScript(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txHex, err := buildDelegatedPreTx(&common.TxInputUtxo{
+		TxId:     strings.Repeat("ab", 32),
+		TxIndex:  1,
+		PkScript: hex.EncodeToString(pkScript),
+		Amount:   50000,
+		PriHex:   hex.EncodeToString(privateKey.Serialize()),
+	})
+	if err != nil {
+		t.Fatalf("buildDelegatedPreTx: %v", err)
+	}
+	tx, err := decodeMvcTx(txHex)
+	if err != nil {
+		t.Fatalf("decode pre-tx: %v", err)
+	}
+	if len(tx.TxIn) != 1 || len(tx.TxOut) != 0 {
+		t.Fatalf("pre-tx has %d inputs and %d outputs, want 1 and 0", len(tx.TxIn), len(tx.TxOut))
+	}
+	in := tx.TxIn[0]
+	if in.PreviousOutPoint.Hash.String() != strings.Repeat("ab", 32) || in.PreviousOutPoint.Index != 1 {
+		t.Errorf("pre-tx spends %s, want the funding output", in.PreviousOutPoint)
+	}
+
+	// <sig+hashtype> <pubkey>: ChunkedUpload may only add outputs if the
+	// input is signed SIGHASH_NONE|ANYONECANPAY (with FORKID)
+	script := in.SignatureScript
+	if len(script) == 0 || int(script[0]) >= len(script) {
+		t.Fatalf("malformed signature script %x", script)
+	}
+	sig := script[1 : 1+int(script[0])]
+	want := byte(sigHashNoneAnyoneCanPay | txscript2.SigHashForkID)
+	if got := sig[len(sig)-1]; got != want {
+		t.Errorf("sighash type = %#x, want %#x", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package upload_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrInvalidDeltaUpload is returned (wrapped) when a delta upload cannot be
+// made: delta uploads are off, the base PIN is unknown or too large, or the
+// delta would not be smaller than the file
+var ErrInvalidDeltaUpload = errors.New("invalid delta upload")
+
+// deltaBaseInfo the fields of the indexer's GET /files/{pinId} response a
+// delta upload checks
+type deltaBaseInfo struct {
+	PinID    string `json:"pin_id"`
+	FileSize int64  `json:"file_size"`
+	FileHash string `json:"file_hash"`
+}
+
+// prepareDeltaUpload turns a chunked request with deltaBasePinID into a delta
+// upload. It runs once per request: when *delta is already set (e.g. the
+// estimate DelegatedUpload runs before the upload) the content already is
+// the delta. The base may be as large as the chain's max file size.
+func (s *UploadService) prepareDeltaUpload(chain, basePinID string, delta **metaid_protocols.MetaFileDelta, content *[]byte, compression string, encryption *metaid_protocols.MetaFileEncryption) error {
+	if basePinID == "" || *delta != nil {
+		return nil
+	}
+	if chain == "" {
+		chain = "mvc"
+	}
+	maxFileSize, _, _ := conf.GetUploaderChainParam(chain)
+	d, err := s.applyDeltaUpload(basePinID, content, compression, encryption, maxFileSize)
+	if err != nil {
+		return err
+	}
+	*delta = d
+	return nil
+}
+
+// applyDeltaUpload replaces *content with its mfs-delta-v1 delta against the
+// indexed content of basePinID and returns the delta envelope for the index.
+// The delta is computed the same way every time, so an estimate and the
+// upload that follows it agree on the size. Encrypted or gzip content is
+// refused: the indexer applies the delta to the plain base file.
+func (s *UploadService) applyDeltaUpload(basePinID string, content *[]byte, compression string, encryption *metaid_protocols.MetaFileEncryption, maxBaseSize int64) (*metaid_protocols.MetaFileDelta, error) {
+	basePinID = strings.TrimSpace(basePinID)
+	if !metaid_protocols.IsPinID(basePinID) {
+		return nil, fmt.Errorf("%w: deltaBasePinId %q is not a PIN ID", ErrInvalidDeltaUpload, basePinID)
+	}
+	if encryption != nil {
+		return nil, fmt.Errorf("%w: a delta cannot be encrypted", ErrInvalidDeltaUpload)
+	}
+	if strings.EqualFold(strings.TrimSpace(compression), metaid_protocols.MetaFileCompressionGzip) {
+		return nil, fmt.Errorf("%w: a delta cannot declare gzip compression; send the plain file", ErrInvalidDeltaUpload)
+	}
+
+	base, err := fetchDeltaBase(basePinID, maxBaseSize)
+	if err != nil {
+		return nil, err
+	}
+	target := *content
+	patch := metaid_protocols.EncodeDelta(base, target)
+	if len(patch) >= len(target) {
+		return nil, fmt.Errorf("%w: the delta (%d bytes) is not smaller than the file (%d bytes); upload the file in full", ErrInvalidDeltaUpload, len(patch), len(target))
+	}
+
+	baseHash := sha256.Sum256(base)
+	targetHash := sha256.Sum256(target)
+	*content = patch
+	return &metaid_protocols.MetaFileDelta{
+		Algorithm:    metaid_protocols.MetaFileDeltaMfsV1,
+		BasePinId:    basePinID,
+		BaseSha256:   hex.EncodeToString(baseHash[:]),
+		TargetSha256: hex.EncodeToString(targetHash[:]),
+		TargetSize:   int64(len(target)),
+	}, nil
+}
+
+// deltaSize the bytes a delta upload inscribes, 0 for a full upload
+func deltaSize(delta *metaid_protocols.MetaFileDelta, content []byte) int64 {
+	if delta == nil {
+		return 0
+	}
+	return int64(len(content))
+}
+
+// fetchDeltaBase reads the content of pinID from uploader.delta.indexer_url
+// and checks it against the indexer's file_hash
+func fetchDeltaBase(pinID string, maxSize int64) ([]byte, error) {
+	baseURL := strings.TrimSuffix(strings.TrimSpace(conf.Cfg.Uploader.Delta.IndexerUrl), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("%w: delta uploads are not enabled (uploader.delta.indexer_url)", ErrInvalidDeltaUpload)
+	}
+	baseURL += "/api/v1/files/"
+	client := &http.Client{Timeout: time.Duration(conf.Cfg.Uploader.Delta.TimeoutSeconds) * time.Second}
+
+	resp, err := client.Get(baseURL + url.PathEscape(pinID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up delta base %s: %w", pinID, err)
+	}
+	var body struct {
+		Code    int            `json:"code"`
+		Message string         `json:"message"`
+		Data    *deltaBaseInfo `json:"data"`
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to look up delta base %s: indexer returned HTTP %d", pinID, resp.StatusCode)
+	case err != nil:
+		return nil, fmt.Errorf("failed to look up delta base %s: invalid indexer response: %w", pinID, err)
+	case body.Code == 40400:
+		return nil, fmt.Errorf("%w: base %s is not indexed", ErrInvalidDeltaUpload, pinID)
+	case body.Code != 0 || body.Data == nil:
+		return nil, fmt.Errorf("failed to look up delta base %s: indexer error %d: %s", pinID, body.Code, body.Message)
+	case maxSize > 0 && body.Data.FileSize > maxSize:
+		return nil, fmt.Errorf("%w: base %s is %d bytes, more than the %d bytes allowed", ErrInvalidDeltaUpload, pinID, body.Data.FileSize, maxSize)
+	}
+
+	resp, err = client.Get(baseURL + "content/" + url.PathEscape(pinID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delta base %s: %w", pinID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to read delta base %s: indexer returned HTTP %d", pinID, resp.StatusCode)
+	}
+	limit := maxSize
+	if limit <= 0 {
+		limit = 1 << 30
+	}
+	base, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delta base %s: %w", pinID, err)
+	}
+	if int64(len(base)) > limit {
+		return nil, fmt.Errorf("%w: base %s exceeds %d bytes", ErrInvalidDeltaUpload, pinID, limit)
+	}
+	hash := sha256.Sum256(base)
+	if body.Data.FileHash != "" && !strings.EqualFold(hex.EncodeToString(hash[:]), body.Data.FileHash) {
+		return nil, fmt.Errorf("delta base %s content does not match its file_hash %s", pinID, body.Data.FileHash)
+	}
+	return base, nil
+}
@@ -0,0 +1,197 @@
+package upload_service
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/conf"
+	"meta-file-system/node"
+)
+
+var (
+	// ErrFaucetDisabled the faucet is not enabled or the service runs on mainnet
+	ErrFaucetDisabled = errors.New("faucet is disabled")
+	// ErrFaucetRateLimited the address, client or global faucet limit was hit
+	ErrFaucetRateLimited = errors.New("faucet rate limit exceeded")
+	// ErrFaucetPayout the faucet node failed to send the grant
+	ErrFaucetPayout = errors.New("faucet payout failed")
+	// ErrFaucetClientUnknown the caller has no client IP (unix socket listener
+	// without a trusted proxy), so its cooldown cannot be enforced
+	ErrFaucetClientUnknown = errors.New("faucet needs the client IP address")
+)
+
+// faucetSend pays out a faucet grant; replaced in tests
+var faucetSend = func(address string, amount int64) (string, error) {
+	faucet := conf.Cfg.Uploader.Faucet
+	url, user, pass := faucet.RpcUrl, faucet.RpcUser, faucet.RpcPass
+	if url == "" {
+		rpc := conf.RpcConfigMap["mvc"]
+		url, user, pass = rpc.Url, rpc.Username, rpc.Password
+	}
+	if url == "" {
+		return "", fmt.Errorf("faucet RPC is not configured")
+	}
+	return node.SendToAddress(url, user, pass, address, amount)
+}
+
+// FaucetRequest asks the testnet faucet to fund an address
+type FaucetRequest struct {
+	Address   string // Address to fund (empty = generate a fresh address)
+	MetaId    string // MetaID (with Assistant)
+	Assistant bool   // Fund the chunked upload assistant address of Address instead of Address itself
+	ClientIP  string // Caller IP (http.trusted_proxies), rate limited like an address; required
+}
+
+// FaucetResponse result of a faucet grant
+type FaucetResponse struct {
+	Chain         string `json:"chain"`                   // Funded chain (mvc)
+	Address       string `json:"address"`                 // Funded address
+	Assistant     bool   `json:"assistant"`               // Whether Address is the user's assistant address
+	PrivateKeyHex string `json:"privateKeyHex,omitempty"` // Private key of a freshly generated address (testnet only)
+	PrivateKeyWif string `json:"privateKeyWif,omitempty"` // WIF of a freshly generated address (testnet only)
+	Amount        int64  `json:"amount"`                  // Satoshis sent
+	TxId          string `json:"txId"`                    // Funding transaction ID
+}
+
+// faucetLimiter enforces the per-key cooldown and the global hourly cap in memory
+type faucetLimiter struct {
+	mu        sync.Mutex
+	lastGrant map[string]time.Time
+	grants    []time.Time // Grants within the last hour, oldest first
+}
+
+func newFaucetLimiter() *faucetLimiter {
+	return &faucetLimiter{lastGrant: make(map[string]time.Time)}
+}
+
+// reserve records a grant for keys at now, or returns how long to wait when a
+// key is cooling down or the hourly cap is reached. Empty keys are ignored.
+func (l *faucetLimiter) reserve(now time.Time, cooldown time.Duration, maxPerHour int, keys ...string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hourAgo := now.Add(-time.Hour)
+	for len(l.grants) > 0 && !l.grants[0].After(hourAgo) {
+		l.grants = l.grants[1:]
+	}
+	if maxPerHour > 0 && len(l.grants) >= maxPerHour {
+		return l.grants[0].Add(time.Hour).Sub(now), false
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if last, ok := l.lastGrant[key]; ok && now.Sub(last) < cooldown {
+			return last.Add(cooldown).Sub(now), false
+		}
+	}
+
+	for _, key := range keys {
+		if key != "" {
+			l.lastGrant[key] = now
+		}
+	}
+	l.grants = append(l.grants, now)
+	return 0, true
+}
+
+// release undoes a reservation made at at, e.g. when the payout failed
+func (l *faucetLimiter) release(at time.Time, keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if last, ok := l.lastGrant[key]; ok && last.Equal(at) {
+			delete(l.lastGrant, key)
+		}
+	}
+	for i := len(l.grants) - 1; i >= 0; i-- {
+		if l.grants[i].Equal(at) {
+			l.grants = append(l.grants[:i], l.grants[i+1:]...)
+			break
+		}
+	}
+}
+
+// FundFromFaucet sends testnet coins to an address, the user's assistant
+// address, or a freshly generated address whose key is returned. Only
+// available when the faucet is enabled outside mainnet.
+func (s *UploadService) FundFromFaucet(req *FaucetRequest) (*FaucetResponse, error) {
+	if !conf.FaucetAllowed() {
+		return nil, ErrFaucetDisabled
+	}
+	// Addresses cost nothing to make, so only the caller's IP bounds what
+	// one caller can claim within max_per_hour
+	if req.ClientIP == "" {
+		return nil, ErrFaucetClientUnknown
+	}
+	netParam := &chaincfg2.TestNet3Params
+	faucet := conf.Cfg.Uploader.Faucet
+	resp := &FaucetResponse{Chain: "mvc", Amount: faucet.Amount}
+
+	switch {
+	case req.Assistant:
+		if req.Address == "" {
+			return nil, fmt.Errorf("address is required to fund its assistant address")
+		}
+		if _, err := bsvutil2.DecodeAddress(req.Address, netParam); err != nil {
+			return nil, fmt.Errorf("invalid address: %w", err)
+		}
+		assistent, err := s.getOrCreateFileAssistent(req.MetaId, req.Address, netParam, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare assistent address: %w", err)
+		}
+		resp.Address = assistent.AssistentAddress
+		resp.Assistant = true
+	case req.Address != "":
+		if _, err := bsvutil2.DecodeAddress(req.Address, netParam); err != nil {
+			return nil, fmt.Errorf("invalid address: %w", err)
+		}
+		resp.Address = req.Address
+	default:
+		privateKey, err := bsvec2.NewPrivateKey(bsvec2.S256())
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate private key: %w", err)
+		}
+		addressPubKey, err := bsvutil2.NewAddressPubKey(privateKey.PubKey().SerializeCompressed(), netParam)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive address: %w", err)
+		}
+		wif, err := bsvutil2.NewWIF(privateKey, netParam, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode private key: %w", err)
+		}
+		resp.Address = addressPubKey.EncodeAddress()
+		resp.PrivateKeyHex = hex.EncodeToString(privateKey.Serialize())
+		resp.PrivateKeyWif = wif.String()
+	}
+
+	keys := []string{"addr:" + resp.Address, "ip:" + req.ClientIP}
+	now := time.Now()
+	cooldown := time.Duration(faucet.AddressCooldown) * time.Second
+	if wait, ok := s.faucetLimiter().reserve(now, cooldown, faucet.MaxPerHour, keys...); !ok {
+		return nil, fmt.Errorf("%w, retry in %s", ErrFaucetRateLimited, wait.Round(time.Second))
+	}
+
+	txId, err := faucetSend(resp.Address, faucet.Amount)
+	if err != nil {
+		s.faucetLimiter().release(now, keys...)
+		return nil, fmt.Errorf("%w: %w", ErrFaucetPayout, err)
+	}
+	resp.TxId = txId
+	log.Printf("Faucet funded %s with %d satoshis: txId=%s, assistant=%v", resp.Address, faucet.Amount, txId, resp.Assistant)
+	return resp, nil
+}
+
+// faucetLimiter returns the service's faucet limiter, creating it on first use
+func (s *UploadService) faucetLimiter() *faucetLimiter {
+	s.faucetOnce.Do(func() { s.faucet = newFaucetLimiter() })
+	return s.faucet
+}
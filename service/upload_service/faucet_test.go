@@ -0,0 +1,168 @@
+package upload_service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/conf"
+)
+
+func setFaucetConfig(t *testing.T, net string, faucet conf.FaucetConfig) *[]string {
+	t.Helper()
+	prevCfg, prevSend := conf.Cfg, faucetSend
+	conf.Cfg = &conf.Config{Net: net, Uploader: conf.UploaderConfig{Faucet: faucet}}
+	var sent []string
+	faucetSend = func(address string, amount int64) (string, error) {
+		sent = append(sent, address)
+		return "txid", nil
+	}
+	t.Cleanup(func() { conf.Cfg, faucetSend = prevCfg, prevSend })
+	return &sent
+}
+
+func TestFaucetLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name   string
+		offset time.Duration
+		keys   []string
+		want   bool
+	}{
+		{"first grant", 0, []string{"addr:a", "ip:1"}, true},
+		{"same address cooling down", time.Minute, []string{"addr:a", "ip:2"}, false},
+		{"same ip cooling down", time.Minute, []string{"addr:b", "ip:1"}, false},
+		{"other address and ip", time.Minute, []string{"addr:b", "ip:2"}, true},
+		{"hourly cap reached", 2 * time.Minute, []string{"addr:c", "ip:3"}, false},
+		{"cooldown over but cap window open", 11 * time.Minute, []string{"addr:a", "ip:1"}, false},
+		{"cap window elapsed", time.Hour + time.Second, []string{"addr:a", "ip:1"}, true},
+	}
+	l := newFaucetLimiter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := l.reserve(now.Add(tt.offset), 10*time.Minute, 2, tt.keys...)
+			if ok != tt.want {
+				t.Fatalf("reserve = %v (wait %s), want %v", ok, wait, tt.want)
+			}
+			if !ok && wait <= 0 {
+				t.Errorf("wait = %s, want positive", wait)
+			}
+		})
+	}
+
+	// A released reservation frees both the key and the hourly slot
+	at := now.Add(3 * time.Hour)
+	if _, ok := l.reserve(at, 10*time.Minute, 1, "addr:d"); !ok {
+		t.Fatal("reserve addr:d failed")
+	}
+	l.release(at, "addr:d")
+	if _, ok := l.reserve(at.Add(time.Second), 10*time.Minute, 1, "addr:d"); !ok {
+		t.Error("reserve after release failed")
+	}
+}
+
+func TestFundFromFaucet(t *testing.T) {
+	enabled := conf.FaucetConfig{Enabled: true, Amount: 1000, AddressCooldown: 3600, MaxPerHour: 10}
+
+	t.Run("disabled", func(t *testing.T) {
+		setFaucetConfig(t, "testnet", conf.FaucetConfig{Amount: 1000})
+		if _, err := (&UploadService{}).FundFromFaucet(&FaucetRequest{}); !errors.Is(err, ErrFaucetDisabled) {
+			t.Errorf("err = %v, want ErrFaucetDisabled", err)
+		}
+	})
+
+	t.Run("never on mainnet", func(t *testing.T) {
+		setFaucetConfig(t, "mainnet", enabled)
+		if _, err := (&UploadService{}).FundFromFaucet(&FaucetRequest{}); !errors.Is(err, ErrFaucetDisabled) {
+			t.Errorf("err = %v, want ErrFaucetDisabled", err)
+		}
+	})
+
+	t.Run("fresh address", func(t *testing.T) {
+		sent := setFaucetConfig(t, "testnet", enabled)
+		resp, err := (&UploadService{}).FundFromFaucet(&FaucetRequest{ClientIP: "10.0.0.1"})
+		if err != nil {
+			t.Fatalf("FundFromFaucet: %v", err)
+		}
+		if resp.PrivateKeyWif == "" || resp.PrivateKeyHex == "" || resp.TxId != "txid" || resp.Amount != 1000 {
+			t.Errorf("unexpected response %+v", resp)
+		}
+		wif, err := bsvutil2.DecodeWIF(resp.PrivateKeyWif)
+		if err != nil {
+			t.Fatalf("decode wif: %v", err)
+		}
+		addr, err := bsvutil2.NewAddressPubKey(wif.SerializePubKey(), &chaincfg2.TestNet3Params)
+		if err != nil || addr.EncodeAddress() != resp.Address {
+			t.Errorf("wif does not control %s (%v)", resp.Address, err)
+		}
+		if len(*sent) != 1 || (*sent)[0] != resp.Address {
+			t.Errorf("sent = %v, want [%s]", *sent, resp.Address)
+		}
+	})
+
+	t.Run("rate limited per client", func(t *testing.T) {
+		sent := setFaucetConfig(t, "testnet", enabled)
+		s := &UploadService{}
+		if _, err := s.FundFromFaucet(&FaucetRequest{ClientIP: "10.0.0.2"}); err != nil {
+			t.Fatalf("first grant: %v", err)
+		}
+		if _, err := s.FundFromFaucet(&FaucetRequest{ClientIP: "10.0.0.2"}); !errors.Is(err, ErrFaucetRateLimited) {
+			t.Errorf("err = %v, want ErrFaucetRateLimited", err)
+		}
+		if len(*sent) != 1 {
+			t.Errorf("sent %d grants, want 1", len(*sent))
+		}
+	})
+
+	t.Run("failed payout is not counted", func(t *testing.T) {
+		setFaucetConfig(t, "testnet", enabled)
+		faucetSend = func(address string, amount int64) (string, error) {
+			return "", errors.New("insufficient funds")
+		}
+		s := &UploadService{}
+		if _, err := s.FundFromFaucet(&FaucetRequest{ClientIP: "10.0.0.3"}); !errors.Is(err, ErrFaucetPayout) {
+			t.Fatalf("err = %v, want ErrFaucetPayout", err)
+		}
+		faucetSend = func(address string, amount int64) (string, error) { return "txid", nil }
+		if _, err := s.FundFromFaucet(&FaucetRequest{ClientIP: "10.0.0.3"}); err != nil {
+			t.Errorf("retry after failed payout: %v", err)
+		}
+	})
+
+	t.Run("rotating addresses from one client", func(t *testing.T) {
+		sent := setFaucetConfig(t, "testnet", enabled)
+		s := &UploadService{}
+		for i := 0; i < 3; i++ {
+			_, err := s.FundFromFaucet(&FaucetRequest{ClientIP: "10.0.0.4"})
+			if i > 0 && !errors.Is(err, ErrFaucetRateLimited) {
+				t.Errorf("grant %d: err = %v, want ErrFaucetRateLimited", i+1, err)
+			}
+		}
+		if len(*sent) != 1 {
+			t.Errorf("sent %d grants, want 1", len(*sent))
+		}
+	})
+
+	t.Run("client IP unknown", func(t *testing.T) {
+		sent := setFaucetConfig(t, "testnet", enabled)
+		s := &UploadService{}
+		for _, req := range []*FaucetRequest{{}, {Address: "mhZz3V4V6JqGfKXsVgzq3wUUzFhNRJ6X3M"}} {
+			if _, err := s.FundFromFaucet(req); !errors.Is(err, ErrFaucetClientUnknown) {
+				t.Errorf("%+v: err = %v, want ErrFaucetClientUnknown", req, err)
+			}
+		}
+		if len(*sent) != 0 {
+			t.Errorf("sent %d grants, want none", len(*sent))
+		}
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		setFaucetConfig(t, "testnet", enabled)
+		if _, err := (&UploadService{}).FundFromFaucet(&FaucetRequest{Address: "not-an-address", ClientIP: "10.0.0.5"}); err == nil {
+			t.Error("expected error for invalid address")
+		}
+	})
+}
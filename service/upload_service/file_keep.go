@@ -0,0 +1,345 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"gorm.io/gorm"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/tool"
+)
+
+// Actions of a signed keep request
+const (
+	KeepActionKeep   = "keep"
+	KeepActionUnkeep = "unkeep"
+)
+
+// maxKeptFilesPage most kept files listed for one owner
+const maxKeptFilesPage = 1000
+
+var (
+	// ErrKeepDisabled uploader.keep.enabled is not set
+	ErrKeepDisabled = errors.New("file keeping is disabled")
+	// ErrInvalidKeepRequest the request is incomplete or the file cannot be kept
+	ErrInvalidKeepRequest = errors.New("invalid keep request")
+	// ErrKeepForbidden the signature does not prove the request comes from the file's owner
+	ErrKeepForbidden = errors.New("keep request is not signed by the file's owner")
+	// ErrFileNotKept unkeep of a file that is not kept
+	ErrFileNotKept = errors.New("file is not kept")
+	// ErrKeepQuotaExceeded (as a *KeepQuotaError) keeping the file would exceed the owner's quota
+	ErrKeepQuotaExceeded = errors.New("keep quota exceeded")
+)
+
+// KeepQuotaError keeping the file would take the owner over
+// uploader.keep.max_files_per_owner or max_mb_per_owner
+type KeepQuotaError struct {
+	Owner string
+	Usage KeepUsage
+	Size  int64 // Size of the file the owner asked to keep
+}
+
+func (e *KeepQuotaError) Error() string {
+	u := e.Usage
+	if u.MaxFiles > 0 && u.Files >= int64(u.MaxFiles) {
+		return fmt.Sprintf("%s: %s keeps %d of %d files; unkeep a file first", ErrKeepQuotaExceeded, e.Owner, u.Files, u.MaxFiles)
+	}
+	return fmt.Sprintf("%s: %s keeps %d of %d bytes and the file has %d; unkeep a file first", ErrKeepQuotaExceeded, e.Owner, u.Bytes, u.MaxBytes, e.Size)
+}
+
+// Is makes errors.Is(err, ErrKeepQuotaExceeded) match
+func (e *KeepQuotaError) Is(target error) bool {
+	return target == ErrKeepQuotaExceeded
+}
+
+// KeepRequest a keep or unkeep request signed by the key of the file's
+// uploader address. The signature is an ECDSA (DER, hex) signature of the
+// SHA256 of KeepMessage(action, PinId, Timestamp).
+type KeepRequest struct {
+	PinId     string
+	PublicKey string // Hex public key of the uploader address (compressed or uncompressed)
+	Timestamp int64  // Unix seconds the request was signed at
+	Signature string
+}
+
+// KeepUsage what an owner keeps and the limits (0 = no limit)
+type KeepUsage struct {
+	Files    int64 `json:"files" example:"3"`       // Files kept
+	Bytes    int64 `json:"bytes" example:"1048576"` // Total size of the files kept
+	MaxFiles int   `json:"maxFiles" example:"100"`  // Files the owner may keep (uploader.keep.max_files_per_owner)
+	MaxBytes int64 `json:"maxBytes" example:"0"`    // Total size the owner may keep (uploader.keep.max_mb_per_owner)
+}
+
+// KeepStatus retention of a file after a keep or unkeep request
+type KeepStatus struct {
+	PinId      string     `json:"pinId"`
+	FileId     string     `json:"fileId"`
+	Owner      string     `json:"owner"` // Uploader address of the file
+	Kept       bool       `json:"kept"`  // The file is kept: never pruned
+	KeptAt     *time.Time `json:"keptAt,omitempty"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+	Usage      KeepUsage  `json:"usage"` // Owner's kept files and limits after the request
+}
+
+// KeptFiles the files an owner keeps
+type KeptFiles struct {
+	Owner string                 `json:"owner"`
+	Files []*model.FileRetention `json:"files"` // Most recently kept first, at most 1000
+	Usage KeepUsage              `json:"usage"`
+}
+
+// KeepMessage the message a keep or unkeep request signs
+func KeepMessage(action, pinID string, timestamp int64) string {
+	return fmt.Sprintf("meta-file-system %s %s %d", action, pinID, timestamp)
+}
+
+// keepSignatureMaxAge how far the signing time of a request may be from now
+func keepSignatureMaxAge() time.Duration {
+	if conf.Cfg == nil || conf.Cfg.Uploader.Keep.SignatureMaxAgeSecs <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(conf.Cfg.Uploader.Keep.SignatureMaxAgeSecs) * time.Second
+}
+
+// verifyKeepRequest checks that req is an action request for its PIN signed
+// around now by the key of the P2PKH address owner
+func verifyKeepRequest(action string, req *KeepRequest, owner string, now time.Time) error {
+	signedAt := time.Unix(req.Timestamp, 0)
+	if maxAge := keepSignatureMaxAge(); signedAt.Before(now.Add(-maxAge)) || signedAt.After(now.Add(maxAge)) {
+		return fmt.Errorf("%w: signed at %s, more than %s from the server time", ErrKeepForbidden, signedAt.UTC().Format(time.RFC3339), maxAge)
+	}
+
+	pubKey, err := hex.DecodeString(req.PublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: public key is not hex", ErrInvalidKeepRequest)
+	}
+	// MVC, BTC and DOGE P2PKH addresses share the layout version|hash160, so
+	// the hash is compared without picking the chain's parameters
+	ownerHash, _, err := base58.CheckDecode(owner)
+	if err != nil || len(ownerHash) != 20 {
+		return fmt.Errorf("%w: owner address %s is not a P2PKH address", ErrInvalidKeepRequest, owner)
+	}
+	if !bytes.Equal(btcutil.Hash160(pubKey), ownerHash) {
+		return fmt.Errorf("%w: public key is not the key of %s", ErrKeepForbidden, owner)
+	}
+
+	ok, err := tool.VerifySign(KeepMessage(action, req.PinId, req.Timestamp), req.Signature, req.PublicKey)
+	if err != nil || !ok {
+		return fmt.Errorf("%w: signature does not match %q", ErrKeepForbidden, KeepMessage(action, req.PinId, req.Timestamp))
+	}
+	return nil
+}
+
+// keepUsage the owner's kept files with the configured limits
+func (s *UploadService) keepUsage(owner string) (KeepUsage, error) {
+	files, size, err := s.retentionDAO.ActiveUsage(owner)
+	if err != nil {
+		return KeepUsage{}, fmt.Errorf("failed to count kept files: %w", err)
+	}
+	cfg := conf.Cfg.Uploader.Keep
+	return KeepUsage{Files: files, Bytes: size, MaxFiles: cfg.MaxFilesPerOwner, MaxBytes: cfg.MaxMBPerOwner * 1024 * 1024}, nil
+}
+
+// checkKeepQuota refuses a file of size that would take usage over a limit
+func checkKeepQuota(owner string, usage KeepUsage, size int64) error {
+	if (usage.MaxFiles > 0 && usage.Files+1 > int64(usage.MaxFiles)) ||
+		(usage.MaxBytes > 0 && usage.Bytes+size > usage.MaxBytes) {
+		return &KeepQuotaError{Owner: owner, Usage: usage, Size: size}
+	}
+	return nil
+}
+
+// validateKeepRequest checks the fields every keep and unkeep request needs
+func validateKeepRequest(req *KeepRequest) error {
+	if conf.Cfg == nil || !conf.Cfg.Uploader.Keep.Enabled {
+		return ErrKeepDisabled
+	}
+	req.PinId = strings.TrimSpace(req.PinId)
+	req.PublicKey = strings.TrimSpace(req.PublicKey)
+	req.Signature = strings.TrimSpace(req.Signature)
+	if req.PinId == "" || req.PublicKey == "" || req.Signature == "" || req.Timestamp <= 0 {
+		return fmt.Errorf("%w: pinId, publicKey, timestamp and signature are required", ErrInvalidKeepRequest)
+	}
+	return nil
+}
+
+// checkKeepReplay refuses a request not signed after the latest one recorded
+// for the file, so a captured request cannot be sent again
+func checkKeepReplay(req *KeepRequest, retention *model.FileRetention) error {
+	if retention != nil && req.Timestamp <= retention.SignedAt.Unix() {
+		return fmt.Errorf("%w: signed at or before the latest request for this file", ErrKeepForbidden)
+	}
+	return nil
+}
+
+// lockKeeps serializes the keep requests of owner from the quota check to
+// the save, so concurrent requests cannot together exceed the quota; call the
+// returned function to unlock
+func (s *UploadService) lockKeeps(owner string) func() {
+	mu, _ := s.keepLocks.LoadOrStore(owner, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// KeepFile records the owner's promise to keep an uploaded file: its local
+// records are never pruned, whatever its storage class, until the owner
+// unkeeps it. Keeping a kept file changes nothing.
+func (s *UploadService) KeepFile(req *KeepRequest) (*KeepStatus, error) {
+	if err := validateKeepRequest(req); err != nil {
+		return nil, err
+	}
+	file, err := s.fileDAO.GetByPinID(req.PinId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadedFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get uploaded file: %w", err)
+	}
+	if file.Status != model.StatusSuccess {
+		return nil, fmt.Errorf("%w: the upload is %s, only uploaded files can be kept", ErrInvalidKeepRequest, file.Status)
+	}
+
+	now := time.Now()
+	if err := verifyKeepRequest(KeepActionKeep, req, file.Address, now); err != nil {
+		return nil, err
+	}
+	defer s.lockKeeps(file.Address)()
+	retention, err := s.retentionDAO.GetByPinID(req.PinId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file retention: %w", err)
+	}
+	usage, err := s.keepUsage(file.Address)
+	if err != nil {
+		return nil, err
+	}
+	if retention != nil && retention.Status == model.FileRetentionActive {
+		// A retried request changes nothing
+		return keepStatus(retention, usage), nil
+	}
+	if err := checkKeepReplay(req, retention); err != nil {
+		return nil, err
+	}
+	if err := checkKeepQuota(file.Address, usage, file.FileSize); err != nil {
+		return nil, err
+	}
+
+	if retention == nil {
+		retention = &model.FileRetention{PinId: file.PinId}
+	}
+	retention.FileId = file.FileId
+	retention.Owner = file.Address
+	retention.FileSize = file.FileSize
+	retention.Status = model.FileRetentionActive
+	retention.PublicKey = req.PublicKey
+	retention.Signature = req.Signature
+	retention.SignedAt = time.Unix(req.Timestamp, 0)
+	retention.KeptAt = now
+	retention.ReleasedAt = nil
+	if err := s.retentionDAO.Save(retention); err != nil {
+		return nil, fmt.Errorf("failed to save file retention: %w", err)
+	}
+
+	usage.Files++
+	usage.Bytes += file.FileSize
+	return keepStatus(retention, usage), nil
+}
+
+// UnkeepFile releases a kept file: its storage class applies again.
+func (s *UploadService) UnkeepFile(req *KeepRequest) (*KeepStatus, error) {
+	if err := validateKeepRequest(req); err != nil {
+		return nil, err
+	}
+	retention, err := s.retentionDAO.GetByPinID(req.PinId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file retention: %w", err)
+	}
+	if retention == nil || retention.Status != model.FileRetentionActive {
+		return nil, ErrFileNotKept
+	}
+
+	now := time.Now()
+	if err := verifyKeepRequest(KeepActionUnkeep, req, retention.Owner, now); err != nil {
+		return nil, err
+	}
+	if err := checkKeepReplay(req, retention); err != nil {
+		return nil, err
+	}
+
+	retention.Status = model.FileRetentionReleased
+	retention.PublicKey = req.PublicKey
+	retention.Signature = req.Signature
+	retention.SignedAt = time.Unix(req.Timestamp, 0)
+	retention.ReleasedAt = &now
+	if err := s.retentionDAO.Save(retention); err != nil {
+		return nil, fmt.Errorf("failed to save file retention: %w", err)
+	}
+
+	usage, err := s.keepUsage(retention.Owner)
+	if err != nil {
+		return nil, err
+	}
+	return keepStatus(retention, usage), nil
+}
+
+// ListKeptFiles returns the files an address keeps, most recently kept first.
+func (s *UploadService) ListKeptFiles(owner string) (*KeptFiles, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return nil, fmt.Errorf("%w: address is required", ErrInvalidKeepRequest)
+	}
+	files, err := s.retentionDAO.ListActiveByOwner(owner, maxKeptFilesPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kept files: %w", err)
+	}
+	usage, err := s.keepUsage(owner)
+	if err != nil {
+		return nil, err
+	}
+	return &KeptFiles{Owner: owner, Files: files, Usage: usage}, nil
+}
+
+// linkRetentions marks the files their owner keeps. Failures only drop the
+// marks.
+func (s *UploadService) linkRetentions(files []*UploadedFile) {
+	pinIDs := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.File.PinId != "" {
+			pinIDs = append(pinIDs, f.File.PinId)
+		}
+	}
+	if len(pinIDs) == 0 {
+		return
+	}
+	retentions, err := s.retentionDAO.GetActiveByPinIDs(pinIDs)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		f.Retention = retentions[f.File.PinId]
+	}
+}
+
+func keepStatus(retention *model.FileRetention, usage KeepUsage) *KeepStatus {
+	status := &KeepStatus{
+		PinId:      retention.PinId,
+		FileId:     retention.FileId,
+		Owner:      retention.Owner,
+		Kept:       retention.Status == model.FileRetentionActive,
+		ReleasedAt: retention.ReleasedAt,
+		Usage:      usage,
+	}
+	if status.Kept {
+		keptAt := retention.KeptAt
+		status.KeptAt = &keptAt
+	}
+	return status
+}
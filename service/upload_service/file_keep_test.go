@@ -0,0 +1,213 @@
+package upload_service
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+
+	"meta-file-system/common"
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/tool"
+)
+
+// signedKeepRequest a keep request for pinID signed at signedAt by key
+func signedKeepRequest(t *testing.T, key *btcec.PrivateKey, action, pinID string, signedAt time.Time) *KeepRequest {
+	t.Helper()
+	sig, err := tool.SignMessage(KeepMessage(action, pinID, signedAt.Unix()), hex.EncodeToString(key.Serialize()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &KeepRequest{
+		PinId:     pinID,
+		PublicKey: hex.EncodeToString(key.PubKey().SerializeCompressed()),
+		Timestamp: signedAt.Unix(),
+		Signature: sig,
+	}
+}
+
+func p2pkhAddress(t *testing.T, key *btcec.PrivateKey, params *chaincfg.Params) string {
+	t.Helper()
+	addr, err := btcutil.NewAddressPubKeyHash(btcutil.Hash160(key.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr.EncodeAddress()
+}
+
+func TestVerifyKeepRequest(t *testing.T) {
+	key, _ := btcec.NewPrivateKey()
+	other, _ := btcec.NewPrivateKey()
+	now := time.Unix(1767225600, 0)
+	const pinID = "4e3f0c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2fi0"
+	mvcOwner := p2pkhAddress(t, key, &chaincfg.MainNetParams)
+	dogeOwner := p2pkhAddress(t, key, common.DogeMainNetParams)
+
+	for _, owner := range []string{mvcOwner, dogeOwner} {
+		req := signedKeepRequest(t, key, KeepActionKeep, pinID, now.Add(-time.Minute))
+		if err := verifyKeepRequest(KeepActionKeep, req, owner, now); err != nil {
+			t.Errorf("owner %s: %v", owner, err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		req     *KeepRequest
+		action  string
+		owner   string
+		wantErr error
+	}{
+		{"signed for unkeep", signedKeepRequest(t, key, KeepActionUnkeep, pinID, now), KeepActionKeep, mvcOwner, ErrKeepForbidden},
+		{"key of another address", signedKeepRequest(t, other, KeepActionKeep, pinID, now), KeepActionKeep, mvcOwner, ErrKeepForbidden},
+		{"stale", signedKeepRequest(t, key, KeepActionKeep, pinID, now.Add(-time.Hour)), KeepActionKeep, mvcOwner, ErrKeepForbidden},
+		{"future", signedKeepRequest(t, key, KeepActionKeep, pinID, now.Add(time.Hour)), KeepActionKeep, mvcOwner, ErrKeepForbidden},
+		{"owner not P2PKH", signedKeepRequest(t, key, KeepActionKeep, pinID, now), KeepActionKeep, "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", ErrInvalidKeepRequest},
+	}
+	for _, tt := range tests {
+		if err := verifyKeepRequest(tt.action, tt.req, tt.owner, now); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// Signatures of another message with the right key
+	req := signedKeepRequest(t, key, KeepActionKeep, pinID, now)
+	req.Timestamp++
+	if err := verifyKeepRequest(KeepActionKeep, req, mvcOwner, now); !errors.Is(err, ErrKeepForbidden) {
+		t.Errorf("altered timestamp: err = %v", err)
+	}
+	req = signedKeepRequest(t, key, KeepActionKeep, pinID[:len(pinID)-1]+"1", now)
+	req.PinId = pinID
+	if err := verifyKeepRequest(KeepActionKeep, req, mvcOwner, now); !errors.Is(err, ErrKeepForbidden) {
+		t.Errorf("signed for another PIN: err = %v", err)
+	}
+}
+
+func TestCheckKeepQuota(t *testing.T) {
+	usage := KeepUsage{Files: 2, Bytes: 900, MaxFiles: 3, MaxBytes: 1000}
+	if err := checkKeepQuota("owner", usage, 100); err != nil {
+		t.Errorf("within quota: %v", err)
+	}
+
+	err := checkKeepQuota("owner", usage, 101)
+	var exceeded *KeepQuotaError
+	if !errors.Is(err, ErrKeepQuotaExceeded) || !errors.As(err, &exceeded) || exceeded.Size != 101 {
+		t.Errorf("over bytes: err = %v", err)
+	}
+
+	usage.Files = 3
+	if err := checkKeepQuota("owner", usage, 1); !errors.Is(err, ErrKeepQuotaExceeded) {
+		t.Errorf("over files: err = %v", err)
+	}
+
+	// 0 = no limit
+	if err := checkKeepQuota("owner", KeepUsage{Files: 1000, Bytes: 1 << 40}, 1<<30); err != nil {
+		t.Errorf("no limits: %v", err)
+	}
+}
+
+func TestKeepRequestChecks(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+
+	conf.Cfg = &conf.Config{}
+	if err := validateKeepRequest(&KeepRequest{PinId: "pin", PublicKey: "02", Timestamp: 1, Signature: "30"}); !errors.Is(err, ErrKeepDisabled) {
+		t.Errorf("disabled: err = %v", err)
+	}
+	conf.Cfg.Uploader.Keep.Enabled = true
+	if err := validateKeepRequest(&KeepRequest{PinId: " pin ", PublicKey: "02", Signature: "30"}); !errors.Is(err, ErrInvalidKeepRequest) {
+		t.Errorf("missing timestamp: err = %v", err)
+	}
+
+	// Requests must be signed after the latest one recorded for the file
+	retention := &model.FileRetention{SignedAt: time.Unix(1767225600, 0)}
+	if err := checkKeepReplay(&KeepRequest{Timestamp: 1767225600}, retention); !errors.Is(err, ErrKeepForbidden) {
+		t.Errorf("replayed: err = %v", err)
+	}
+	if err := checkKeepReplay(&KeepRequest{Timestamp: 1767225601}, retention); err != nil {
+		t.Errorf("newer: %v", err)
+	}
+	if err := checkKeepReplay(&KeepRequest{Timestamp: 1}, nil); err != nil {
+		t.Errorf("first request: %v", err)
+	}
+}
+
+// setTestUploaderDB opens a SQLite uploader database for the test
+func setTestUploaderDB(t *testing.T) {
+	t.Helper()
+	prevCfg, prevDB := conf.Cfg, database.UploaderDB
+	conf.Cfg = &conf.Config{}
+	conf.Cfg.Database.UploaderType = string(database.DBTypeSQLite)
+	conf.Cfg.Database.SqlitePath = filepath.Join(t.TempDir(), "uploader.db")
+	if err := database.InitUploaderDB(); err != nil {
+		t.Fatalf("InitUploaderDB: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.CloseUploaderDB()
+		conf.Cfg, database.UploaderDB = prevCfg, prevDB
+	})
+}
+
+func TestKeepFileQuotaHoldsConcurrently(t *testing.T) {
+	setTestUploaderDB(t)
+	conf.Cfg.Uploader.Keep = conf.UploadKeepConfig{Enabled: true, MaxFilesPerOwner: 3}
+	s := NewUploadService(nil)
+
+	key, _ := btcec.NewPrivateKey()
+	owner := p2pkhAddress(t, key, &chaincfg.MainNetParams)
+	var reqs []*KeepRequest
+	for i := 0; i < 50; i++ {
+		pinID := fmt.Sprintf("keep%02di0", i)
+		if err := s.fileDAO.Create(&model.File{
+			FileId:   "meta_" + pinID,
+			FileSize: 10,
+			Address:  owner,
+			TxID:     fmt.Sprintf("keep%02d", i),
+			PinId:    pinID,
+			Path:     "/file/keep.txt",
+			Status:   model.StatusSuccess,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		reqs = append(reqs, signedKeepRequest(t, key, KeepActionKeep, pinID, time.Now()))
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	kept := 0
+	start := make(chan struct{})
+	for _, req := range reqs {
+		wg.Add(1)
+		go func(req *KeepRequest) {
+			defer wg.Done()
+			<-start
+			_, err := s.KeepFile(req)
+			if err != nil && !errors.Is(err, ErrKeepQuotaExceeded) {
+				t.Errorf("KeepFile(%s): %v", req.PinId, err)
+			}
+			if err == nil {
+				mu.Lock()
+				kept++
+				mu.Unlock()
+			}
+		}(req)
+	}
+	close(start)
+	wg.Wait()
+
+	files, _, err := s.retentionDAO.ActiveUsage(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept != 3 || files != 3 {
+		t.Errorf("kept = %d, active retentions = %d; want 3 within the quota", kept, files)
+	}
+}
@@ -0,0 +1,446 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/node"
+)
+
+// Chunk funding strategies (uploader.chunk_funding.strategy, fundingStrategy
+// of chunked uploads)
+const (
+	// ChunkFundingAssistent chunk outputs pay a key generated for each user,
+	// funded by the client's chunk pre-tx
+	ChunkFundingAssistent = "assistent"
+	// ChunkFundingHotWallet chunk outputs are funded by, and pay, the operator hot wallet
+	ChunkFundingHotWallet = "hot_wallet"
+)
+
+// maxHotWalletInputs hot wallet outputs one funding transaction may spend
+const maxHotWalletInputs = 50
+
+var (
+	// ErrInvalidFundingStrategy the requested chunk funding strategy is unknown or not allowed
+	ErrInvalidFundingStrategy = errors.New("invalid chunk funding strategy")
+	// ErrHotWalletNotConfigured uploader.chunk_funding.hot_wallet_key is not set
+	ErrHotWalletNotConfigured = errors.New("chunk funding hot wallet is not configured")
+	// ErrHotWalletFunds the hot wallet has too few spendable outputs for the upload
+	ErrHotWalletFunds = errors.New("hot wallet has too few spendable outputs")
+	// ErrHotWalletLimit the upload needs more than uploader.chunk_funding.max_per_upload
+	ErrHotWalletLimit = errors.New("chunk funding exceeds the hot wallet per-upload limit")
+	// ErrInvalidDeposit the transaction is not a deposit to the hot wallet
+	ErrInvalidDeposit = errors.New("invalid hot wallet deposit")
+)
+
+// IsChunkFundingError reports whether err rejects the chunk funding of an
+// upload (strategy not allowed, hot wallet missing, short of funds or over
+// its per-upload limit)
+func IsChunkFundingError(err error) bool {
+	return errors.Is(err, ErrInvalidFundingStrategy) || errors.Is(err, ErrHotWalletNotConfigured) ||
+		errors.Is(err, ErrHotWalletFunds) || errors.Is(err, ErrHotWalletLimit)
+}
+
+// hotWalletRawTx fetches a deposit transaction; replaced in tests
+var hotWalletRawTx = func(txId string) (string, error) {
+	return node.GetTxRaw(conf.Cfg.Net, txId)
+}
+
+// hotWalletEnabled reports whether a chunk funding hot wallet is configured
+func hotWalletEnabled() bool {
+	return conf.Cfg != nil && conf.Cfg.Uploader.ChunkFunding.HotWalletKey != ""
+}
+
+// resolveChunkFundingStrategy returns the strategy funding the chunks of an
+// upload: the requested one when uploader.chunk_funding.allow_request_strategy
+// is set, otherwise the deployment's
+func resolveChunkFundingStrategy(requested string) (string, error) {
+	cfg := conf.Cfg.Uploader.ChunkFunding
+	strategy := cfg.Strategy
+	if strategy == "" {
+		strategy = ChunkFundingAssistent
+	}
+	requested = strings.TrimSpace(requested)
+	if requested != "" && requested != strategy {
+		if requested != ChunkFundingAssistent && requested != ChunkFundingHotWallet {
+			return "", fmt.Errorf("%w: %q, use %s or %s", ErrInvalidFundingStrategy, requested, ChunkFundingAssistent, ChunkFundingHotWallet)
+		}
+		if !cfg.AllowRequestStrategy {
+			return "", fmt.Errorf("%w: this uploader funds chunks with %s only", ErrInvalidFundingStrategy, strategy)
+		}
+		strategy = requested
+	}
+	if strategy == ChunkFundingHotWallet && cfg.HotWalletKey == "" {
+		return "", ErrHotWalletNotConfigured
+	}
+	return strategy, nil
+}
+
+// resolveChainFundingStrategy resolves the strategy of an upload on chain:
+// the hot wallet funds MVC uploads only, DOGE uploads use assistent funding
+// unless hot_wallet is requested
+func resolveChainFundingStrategy(chain, requested string) (string, error) {
+	if chain == "doge" {
+		if strings.TrimSpace(requested) == ChunkFundingHotWallet {
+			return "", fmt.Errorf("%w: hot_wallet funds mvc uploads only", ErrInvalidFundingStrategy)
+		}
+		return ChunkFundingAssistent, nil
+	}
+	return resolveChunkFundingStrategy(requested)
+}
+
+// hotWallet the configured chunk funding key
+type hotWallet struct {
+	address  string
+	pkScript []byte
+	priHex   string
+}
+
+// loadHotWallet parses uploader.chunk_funding.hot_wallet_key (hex or WIF)
+func loadHotWallet(netParam *chaincfg2.Params) (*hotWallet, error) {
+	key := strings.TrimSpace(conf.Cfg.Uploader.ChunkFunding.HotWalletKey)
+	if key == "" {
+		return nil, ErrHotWalletNotConfigured
+	}
+	var privateKey *bsvec2.PrivateKey
+	if raw, err := hex.DecodeString(key); err == nil && len(raw) == 32 {
+		privateKey, _ = bsvec2.PrivKeyFromBytes(bsvec2.S256(), raw)
+	} else {
+		wif, err := bsvutil2.DecodeWIF(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uploader.chunk_funding.hot_wallet_key: not a hex private key or WIF")
+		}
+		privateKey = wif.PrivKey
+	}
+
+	addressPubKey, err := bsvutil2.NewAddressPubKey(privateKey.PubKey().SerializeCompressed(), netParam)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive hot wallet address: %w", err)
+	}
+	address := addressPubKey.EncodeAddress()
+	pkScript, err := scripts.PayToMvcAddress(address, netParam)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build hot wallet pkScript: %w", err)
+	}
+	return &hotWallet{
+		address:  address,
+		pkScript: pkScript,
+		priHex:   hex.EncodeToString(privateKey.Serialize()),
+	}, nil
+}
+
+// selectHotWalletUtxos picks outputs from utxos (largest first) until they
+// pay the chunk outputs and the fee of the funding transaction. Returns the
+// outputs, the change (0 when below the chain's min change, which then goes
+// to the fee) and the fee.
+func selectHotWalletUtxos(utxos []*model.HotWalletUtxo, chunkAmounts []int64, feeRate int64, policy conf.UploaderChainPolicy) ([]*model.HotWalletUtxo, int64, int64, error) {
+	var outputs int64
+	for _, amount := range chunkAmounts {
+		outputs += amount
+	}
+	feeFor := func(inputs, p2pkhOutputs int) int64 {
+		fee := int64(mvcTxSize(inputs, p2pkhOutputs)) * feeRate
+		if fee < policy.DustLimit {
+			fee = policy.DustLimit
+		}
+		return fee
+	}
+
+	var total int64
+	for i, u := range utxos {
+		if i == maxHotWalletInputs {
+			break
+		}
+		total += u.Value
+		selected := utxos[:i+1]
+		if fee := feeFor(len(selected), len(chunkAmounts)+1); total-outputs-fee >= policy.MinChange && total-outputs-fee > 0 {
+			return selected, total - outputs - fee, fee, nil
+		}
+		if fee := feeFor(len(selected), len(chunkAmounts)); total >= outputs+fee {
+			return selected, 0, total - outputs, nil
+		}
+	}
+	return nil, 0, 0, fmt.Errorf("%w: need %d satoshis plus fees, %d spendable in %d outputs",
+		ErrHotWalletFunds, outputs, total, min(len(utxos), maxHotWalletInputs))
+}
+
+// buildHotWalletFundingTx signs an MVC transaction spending utxos of the hot
+// wallet into one output per chunk amount paying the hot wallet (vout i for
+// chunk i), then the change, if any
+func buildHotWalletFundingTx(wallet *hotWallet, utxos []*model.HotWalletUtxo, chunkAmounts []int64, change int64) (*wire2.MsgTx, error) {
+	privateKeyBytes, err := hex.DecodeString(wallet.priHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode hot wallet private key: %w", err)
+	}
+	privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), privateKeyBytes)
+
+	tx := wire2.NewMsgTx(10)
+	for _, u := range utxos {
+		hash, err := chainhash2.NewHashFromStr(u.TxId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse txid %s: %w", u.TxId, err)
+		}
+		tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(hash, u.Vout), nil))
+	}
+	for _, amount := range chunkAmounts {
+		tx.AddTxOut(wire2.NewTxOut(amount, wallet.pkScript))
+	}
+	if change > 0 {
+		tx.AddTxOut(wire2.NewTxOut(change, wallet.pkScript))
+	}
+	for i, u := range utxos {
+		sigScript, err := txscript2.SignatureScript(tx, i, u.Value, wallet.pkScript, txscript2.SigHashAll, privateKey, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign funding input %d: %w", i, err)
+		}
+		tx.TxIn[i].SignatureScript = sigScript
+	}
+	return tx, nil
+}
+
+// fundChunksFromHotWallet builds the chunk funding transaction of an upload
+// from hot wallet outputs. Unless dryRun, the outputs it spends are locked
+// under its txid and its change is recorded pending, until the transaction
+// is broadcast (recordHotWalletTx) or the upload fails
+// (releaseHotWalletFunding).
+func (s *UploadService) fundChunksFromHotWallet(wallet *hotWallet, chunkAmounts []int64, feeRate int64, dryRun bool) (*wire2.MsgTx, error) {
+	cfg := conf.Cfg.Uploader.ChunkFunding
+	policy := conf.GetUploaderChainPolicy("mvc")
+	now := time.Now()
+	staleBefore := now.Add(-time.Duration(cfg.LockMinutes) * time.Minute)
+
+	s.hotWalletMu.Lock()
+	defer s.hotWalletMu.Unlock()
+
+	utxos, err := s.hotWalletUtxoDAO.ListSpendable("mvc", wallet.address, staleBefore, maxHotWalletInputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hot wallet outputs: %w", err)
+	}
+	selected, change, fee, err := selectHotWalletUtxos(utxos, chunkAmounts, feeRate, policy)
+	if err != nil {
+		return nil, err
+	}
+	var outputs int64
+	for _, amount := range chunkAmounts {
+		outputs += amount
+	}
+	if cfg.MaxPerUpload > 0 && outputs+fee > cfg.MaxPerUpload {
+		return nil, fmt.Errorf("%w: needs %d satoshis, limit %d", ErrHotWalletLimit, outputs+fee, cfg.MaxPerUpload)
+	}
+
+	tx, err := buildHotWalletFundingTx(wallet, selected, chunkAmounts, change)
+	if err != nil || dryRun {
+		return tx, err
+	}
+	txHex, err := common.MvcToRaw(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize chunk funding tx: %w", err)
+	}
+	txID := common.GetMvcTxhashFromRaw(txHex)
+
+	ids := make([]int64, 0, len(selected))
+	for _, u := range selected {
+		ids = append(ids, u.ID)
+	}
+	locked, err := s.hotWalletUtxoDAO.Lock(ids, txID, staleBefore, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock hot wallet outputs: %w", err)
+	}
+	if !locked {
+		return nil, fmt.Errorf("%w: outputs taken by another upload, retry", ErrHotWalletFunds)
+	}
+	if change > 0 {
+		err := s.hotWalletUtxoDAO.Record(&model.HotWalletUtxo{
+			Chain:    "mvc",
+			Address:  wallet.address,
+			TxId:     txID,
+			Vout:     uint32(len(chunkAmounts)),
+			Value:    change,
+			IsChange: true,
+			Status:   model.HotWalletUtxoPending,
+		})
+		if err != nil {
+			s.releaseHotWalletFunding(txHex)
+			return nil, fmt.Errorf("failed to record hot wallet change: %w", err)
+		}
+	}
+
+	log.Printf("Hot wallet funds %d chunks: tx=%s, inputs=%d, outputs=%d, fee=%d, change=%d", len(chunkAmounts), txID, len(selected), outputs, fee, change)
+	return tx, nil
+}
+
+// releaseHotWalletFunding frees the hot wallet outputs locked by a chunk
+// funding transaction that will not be broadcast (the upload failed) and
+// drops its change. Outputs of an assistent funded upload are not in the
+// ledger, so nothing changes for them.
+func (s *UploadService) releaseHotWalletFunding(fundingTxHex string) {
+	fundingTxHex = strings.TrimSpace(fundingTxHex)
+	if !hotWalletEnabled() || fundingTxHex == "" {
+		return
+	}
+	txID := common.GetMvcTxhashFromRaw(fundingTxHex)
+	released, err := s.hotWalletUtxoDAO.Release(txID)
+	if err != nil {
+		log.Printf("Hot wallet: failed to release the outputs locked by %s: %v", txID, err)
+		return
+	}
+	if released > 0 {
+		log.Printf("Hot wallet: released %d outputs locked by %s", released, txID)
+	}
+}
+
+// recordHotWalletTx updates the hot wallet ledger after the uploader
+// broadcast txHex: ledger outputs it spends are marked spent and its pending
+// change becomes available. Ledger errors are only logged.
+func (s *UploadService) recordHotWalletTx(txHex string) {
+	if !hotWalletEnabled() {
+		return
+	}
+	tx, err := decodeChainTx("mvc", txHex)
+	if err != nil {
+		log.Printf("Hot wallet ledger: failed to decode transaction: %v", err)
+		return
+	}
+	now := time.Now()
+	for _, in := range tx.inputs {
+		if _, err := s.hotWalletUtxoDAO.MarkSpent(in.txID, in.vout, tx.txID, now); err != nil {
+			log.Printf("Hot wallet ledger: failed to mark %s:%d spent: %v", in.txID, in.vout, err)
+		}
+	}
+	if err := s.hotWalletUtxoDAO.Confirm(tx.txID); err != nil {
+		log.Printf("Hot wallet ledger: failed to confirm the change of %s: %v", tx.txID, err)
+	}
+}
+
+// HotWalletDeposit outputs of a deposit added to the hot wallet
+type HotWalletDeposit struct {
+	TxId    string `json:"txId"`
+	Outputs int    `json:"outputs" example:"1"`     // Outputs paying the hot wallet
+	Value   int64  `json:"value" example:"5000000"` // Satoshis
+}
+
+// RecordHotWalletDeposit adds the outputs of transaction txID paying the hot
+// wallet address to its spendable outputs. The transaction must not spend hot
+// wallet outputs: the uploader's own funding transactions are not deposits.
+// Recording a deposit again changes nothing.
+func (s *UploadService) RecordHotWalletDeposit(txID string) (*HotWalletDeposit, error) {
+	if !hotWalletEnabled() {
+		return nil, ErrHotWalletNotConfigured
+	}
+	txID = strings.TrimSpace(txID)
+	if _, err := chainhash2.NewHashFromStr(txID); err != nil || len(txID) != 64 {
+		return nil, fmt.Errorf("%w: txId must be a 64 character transaction ID", ErrInvalidDeposit)
+	}
+	wallet, err := loadHotWallet(mvcNetParam())
+	if err != nil {
+		return nil, err
+	}
+	txHex, err := hotWalletRawTx(txID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transaction %s: %w", txID, err)
+	}
+	tx, err := decodeChainTx("mvc", txHex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction %s: %w", txID, err)
+	}
+	if tx.txID != txID {
+		return nil, fmt.Errorf("node returned transaction %s for %s", tx.txID, txID)
+	}
+	for _, in := range tx.inputs {
+		spends, err := s.hotWalletUtxoDAO.Exists(in.txID, in.vout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check input %s:%d: %w", in.txID, in.vout, err)
+		}
+		if spends {
+			return nil, fmt.Errorf("%w: %s spends hot wallet outputs", ErrInvalidDeposit, txID)
+		}
+	}
+
+	deposit := &HotWalletDeposit{TxId: txID}
+	for vout, out := range tx.outputs {
+		if !bytes.Equal(out.pkScript, wallet.pkScript) {
+			continue
+		}
+		err := s.hotWalletUtxoDAO.Record(&model.HotWalletUtxo{
+			Chain:   "mvc",
+			Address: wallet.address,
+			TxId:    txID,
+			Vout:    uint32(vout),
+			Value:   out.value,
+			Status:  model.HotWalletUtxoAvailable,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record %s:%d: %w", txID, vout, err)
+		}
+		deposit.Outputs++
+		deposit.Value += out.value
+	}
+	if deposit.Outputs == 0 {
+		return nil, fmt.Errorf("%w: %s pays nothing to %s", ErrInvalidDeposit, txID, wallet.address)
+	}
+	log.Printf("Hot wallet deposit %s: %d outputs, %d satoshis", txID, deposit.Outputs, deposit.Value)
+	return deposit, nil
+}
+
+// HotWalletBalance outputs and satoshis of the hot wallet in one status
+type HotWalletBalance struct {
+	Status  string `json:"status" example:"available"` // available, locked (by an upload not broadcast yet), pending (change not broadcast yet) or spent
+	Outputs int64  `json:"outputs" example:"3"`
+	Value   int64  `json:"value" example:"4200000"` // Satoshis
+}
+
+// HotWalletStatus the chunk funding configuration and hot wallet ledger
+type HotWalletStatus struct {
+	Strategy             string              `json:"strategy" example:"hot_wallet"` // Deployment strategy (uploader.chunk_funding.strategy)
+	AllowRequestStrategy bool                `json:"allowRequestStrategy"`          // Requests may pick the strategy
+	Address              string              `json:"address"`                       // Hot wallet address; send deposits here
+	MaxPerUpload         int64               `json:"maxPerUpload" example:"1000000"`
+	Balance              int64               `json:"balance" example:"4200000"` // Satoshis not spent: available, locked and pending
+	Balances             []*HotWalletBalance `json:"balances"`
+}
+
+// GetHotWalletStatus returns the hot wallet address and its ledger totals
+func (s *UploadService) GetHotWalletStatus() (*HotWalletStatus, error) {
+	if !hotWalletEnabled() {
+		return nil, ErrHotWalletNotConfigured
+	}
+	wallet, err := loadHotWallet(mvcNetParam())
+	if err != nil {
+		return nil, err
+	}
+	cfg := conf.Cfg.Uploader.ChunkFunding
+	totals, err := s.hotWalletUtxoDAO.TotalsByStatus("mvc", wallet.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum hot wallet outputs: %w", err)
+	}
+	status := &HotWalletStatus{
+		Strategy:             cfg.Strategy,
+		AllowRequestStrategy: cfg.AllowRequestStrategy,
+		Address:              wallet.address,
+		MaxPerUpload:         cfg.MaxPerUpload,
+		Balances:             make([]*HotWalletBalance, 0, len(totals)),
+	}
+	for _, t := range totals {
+		status.Balances = append(status.Balances, &HotWalletBalance{Status: t.Status, Outputs: t.Outputs, Value: t.Value})
+		if t.Status != model.HotWalletUtxoSpent {
+			status.Balance += t.Value
+		}
+	}
+	return status, nil
+}
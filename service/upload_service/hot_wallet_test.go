@@ -0,0 +1,159 @@
+package upload_service
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
+	bsvutil2 "github.com/bitcoinsv/bsvutil"
+
+	"meta-file-system/common"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+const testHotWalletKey = "0101010101010101010101010101010101010101010101010101010101010101"
+
+func setChunkFundingConfig(t *testing.T, cfg conf.UploadChunkFundingConfig) {
+	t.Helper()
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{Net: "testnet"}
+	conf.Cfg.Uploader.ChunkFunding = cfg
+	t.Cleanup(func() { conf.Cfg = prev })
+}
+
+func TestResolveChunkFundingStrategy(t *testing.T) {
+	setChunkFundingConfig(t, conf.UploadChunkFundingConfig{Strategy: ChunkFundingAssistent})
+	if got, err := resolveChunkFundingStrategy(""); err != nil || got != ChunkFundingAssistent {
+		t.Fatalf("default = %q, %v", got, err)
+	}
+	if _, err := resolveChunkFundingStrategy(ChunkFundingHotWallet); !errors.Is(err, ErrInvalidFundingStrategy) {
+		t.Fatalf("hot_wallet without allow_request_strategy: %v", err)
+	}
+
+	conf.Cfg.Uploader.ChunkFunding.AllowRequestStrategy = true
+	if _, err := resolveChunkFundingStrategy("bank"); !errors.Is(err, ErrInvalidFundingStrategy) {
+		t.Fatalf("unknown strategy: %v", err)
+	}
+	if _, err := resolveChunkFundingStrategy(ChunkFundingHotWallet); !errors.Is(err, ErrHotWalletNotConfigured) {
+		t.Fatalf("hot_wallet without key: %v", err)
+	}
+	conf.Cfg.Uploader.ChunkFunding.HotWalletKey = testHotWalletKey
+	if got, err := resolveChunkFundingStrategy(" hot_wallet "); err != nil || got != ChunkFundingHotWallet {
+		t.Fatalf("requested hot_wallet = %q, %v", got, err)
+	}
+	if _, err := resolveChainFundingStrategy("doge", ChunkFundingHotWallet); !errors.Is(err, ErrInvalidFundingStrategy) {
+		t.Fatalf("doge hot_wallet: %v", err)
+	}
+
+	conf.Cfg.Uploader.ChunkFunding.Strategy = ChunkFundingHotWallet
+	if got, err := resolveChainFundingStrategy("doge", ""); err != nil || got != ChunkFundingAssistent {
+		t.Fatalf("doge default = %q, %v, want assistent", got, err)
+	}
+}
+
+func TestLoadHotWallet(t *testing.T) {
+	setChunkFundingConfig(t, conf.UploadChunkFundingConfig{HotWalletKey: testHotWalletKey})
+	fromHex, err := loadHotWallet(mvcNetParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromHex.priHex != testHotWalletKey || fromHex.address == "" || len(fromHex.pkScript) != 25 {
+		t.Fatalf("unexpected hot wallet %+v", fromHex)
+	}
+
+	privateKey, _ := bsvec2.PrivKeyFromBytes(bsvec2.S256(), bytes.Repeat([]byte{1}, 32))
+	wif, err := bsvutil2.NewWIF(privateKey, mvcNetParam(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Cfg.Uploader.ChunkFunding.HotWalletKey = wif.String()
+	fromWIF, err := loadHotWallet(mvcNetParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromWIF.address != fromHex.address {
+		t.Fatalf("WIF address %s, hex address %s", fromWIF.address, fromHex.address)
+	}
+
+	conf.Cfg.Uploader.ChunkFunding.HotWalletKey = "not a key"
+	if _, err := loadHotWallet(mvcNetParam()); err == nil {
+		t.Fatal("expected an error for an invalid key")
+	}
+}
+
+func TestSelectHotWalletUtxos(t *testing.T) {
+	policy := conf.UploaderChainPolicy{DustLimit: 1, MinChange: 1000}
+	chunkAmounts := []int64{3000, 2000}
+	utxos := []*model.HotWalletUtxo{
+		{ID: 1, Value: 4000},
+		{ID: 2, Value: 2000},
+		{ID: 3, Value: 1000},
+	}
+
+	// 6000 pays 5000 plus the fee; the rest is below MinChange
+	selected, change, fee, err := selectHotWalletUtxos(utxos, chunkAmounts, 1, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || change != 0 || fee != 1000 {
+		t.Fatalf("selected %d outputs, change %d, fee %d", len(selected), change, fee)
+	}
+
+	// Change of at least MinChange gets its own output
+	policy.MinChange = 100
+	selected, change, fee, err = selectHotWalletUtxos(utxos[:1], []int64{1000}, 1, policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 1 || change != 3000-fee || fee != int64(mvcTxSize(1, 2)) {
+		t.Fatalf("change %d fee %d, want fee %d", change, fee, mvcTxSize(1, 2))
+	}
+
+	if _, _, _, err := selectHotWalletUtxos(utxos, []int64{7000}, 1, policy); !errors.Is(err, ErrHotWalletFunds) {
+		t.Fatalf("insufficient funds: %v", err)
+	}
+}
+
+func TestBuildHotWalletFundingTx(t *testing.T) {
+	setChunkFundingConfig(t, conf.UploadChunkFundingConfig{HotWalletKey: testHotWalletKey})
+	wallet, err := loadHotWallet(mvcNetParam())
+	if err != nil {
+		t.Fatal(err)
+	}
+	utxos := []*model.HotWalletUtxo{
+		{TxId: strings.Repeat("11", 32), Vout: 1, Value: 5000},
+		{TxId: strings.Repeat("22", 32), Vout: 0, Value: 3000},
+	}
+
+	tx, err := buildHotWalletFundingTx(wallet, utxos, []int64{1500, 2500}, 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txHex, err := common.MvcToRaw(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lt, err := decodeChainTx("mvc", txHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lt.inputs) != 2 || lt.inputs[0].vout != 1 || lt.inputs[1].txID != utxos[1].TxId {
+		t.Fatalf("unexpected inputs %+v", lt.inputs)
+	}
+	if len(lt.outputs) != 3 || lt.outputs[0].value != 1500 || lt.outputs[1].value != 2500 || lt.outputs[2].value != 3600 {
+		t.Fatalf("unexpected outputs %+v", lt.outputs)
+	}
+	for i, out := range lt.outputs {
+		if !bytes.Equal(out.pkScript, wallet.pkScript) {
+			t.Fatalf("output %d does not pay the hot wallet", i)
+		}
+	}
+	for i, in := range tx.TxIn {
+		if len(in.SignatureScript) == 0 {
+			t.Fatalf("input %d not signed", i)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+
+	"gorm.io/gorm"
+)
+
+// MaxIdempotencyKeyLength longest Idempotency-Key header accepted
+const MaxIdempotencyKeyLength = 255
+
+var (
+	// ErrIdempotencyMismatch the key was first used with a different request
+	ErrIdempotencyMismatch = errors.New("Idempotency-Key was already used with a different request")
+	// ErrIdempotencyInProgress the first request with the key has not finished yet
+	ErrIdempotencyInProgress = errors.New("a request with this Idempotency-Key is still being processed")
+)
+
+// BeginIdempotentRequest claims key on scope (the upload route) for a request
+// with the given fingerprint. A completed record is returned when the same
+// request already ran: its Response is to be sent again instead of running
+// the request. Otherwise the returned record is processing and the caller
+// must end it with FinishIdempotentRequest or AbandonIdempotentRequest.
+// Keys past uploader.idempotency.ttl_hours, or processing for longer than
+// processing_minutes, are reused.
+func (s *UploadService) BeginIdempotentRequest(scope, key, fingerprint string) (*model.UploadIdempotency, error) {
+	cfg := conf.Cfg.Uploader.Idempotency
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(cfg.TtlHours) * time.Hour)
+
+	record := &model.UploadIdempotency{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      model.IdempotencyProcessing,
+		ExpiresAt:   expiresAt,
+	}
+	createErr := s.idempotencyDAO.Create(record)
+	if createErr == nil {
+		return record, nil
+	}
+
+	// The key is taken (or the insert failed for another reason)
+	existing, err := s.idempotencyDAO.Get(scope, key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to save idempotency key: %w", createErr)
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	expired := now.After(existing.ExpiresAt)
+	abandoned := existing.Status == model.IdempotencyProcessing &&
+		now.Sub(existing.UpdatedAt) > time.Duration(cfg.ProcessingMinutes)*time.Minute
+	switch {
+	case expired:
+	case existing.Fingerprint != fingerprint:
+		return nil, ErrIdempotencyMismatch
+	case existing.Status == model.IdempotencyCompleted:
+		return existing, nil
+	case !abandoned:
+		return nil, ErrIdempotencyInProgress
+	}
+
+	taken, err := s.idempotencyDAO.TakeOver(existing, fingerprint, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reuse idempotency key: %w", err)
+	}
+	if !taken {
+		// Another retry reused it first
+		return nil, ErrIdempotencyInProgress
+	}
+	existing.Fingerprint = fingerprint
+	existing.Status = model.IdempotencyProcessing
+	existing.Response = nil
+	existing.ExpiresAt = expiresAt
+	return existing, nil
+}
+
+// FinishIdempotentRequest stores the response of the request holding record;
+// retries with the key get it back until the key expires
+func (s *UploadService) FinishIdempotentRequest(record *model.UploadIdempotency, response []byte) error {
+	if err := s.idempotencyDAO.Complete(record.ID, response); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// AbandonIdempotentRequest releases the key of a request that failed in a
+// way worth retrying, so the next request with the key runs again
+func (s *UploadService) AbandonIdempotentRequest(record *model.UploadIdempotency) error {
+	if err := s.idempotencyDAO.Delete(record.ID); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys drops keys past uploader.idempotency.ttl_hours.
+// Returns the number of keys deleted.
+func (s *UploadService) DeleteExpiredIdempotencyKeys(now time.Time) (int64, error) {
+	n, err := s.idempotencyDAO.DeleteExpired(now)
+	if err != nil {
+		return n, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package upload_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ValidateMetaFileEncoding validates the compression (none or gzip, empty
+// means none) and encryption envelope a chunked upload declares in its v2
+// index. The uploader does not compress or encrypt itself: the content must
+// already be in the declared form.
+func ValidateMetaFileEncoding(compression string, encryption *metaid_protocols.MetaFileEncryption) error {
+	if err := metaid_protocols.ValidateMetaFileCompression(strings.ToLower(strings.TrimSpace(compression))); err != nil {
+		return err
+	}
+	if encryption != nil && strings.TrimSpace(encryption.Algorithm) == "" {
+		return fmt.Errorf("encryption.algorithm is required when encryption is set")
+	}
+	return nil
+}
+
+// normalizeMetaFileEncoding validates a request's compression and encryption
+// and rewrites compression to its canonical form
+func normalizeMetaFileEncoding(compression *string, encryption *metaid_protocols.MetaFileEncryption) error {
+	if err := ValidateMetaFileEncoding(*compression, encryption); err != nil {
+		return err
+	}
+	*compression = strings.ToLower(strings.TrimSpace(*compression))
+	if *compression == "" {
+		*compression = metaid_protocols.MetaFileCompressionNone
+	}
+	return nil
+}
+
+// encodeMetaFileEncryption serializes an encryption envelope for an async
+// task row (empty when there is none)
+func encodeMetaFileEncryption(encryption *metaid_protocols.MetaFileEncryption) (string, error) {
+	if encryption == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(encryption)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode encryption envelope: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeMetaFileEncryption reverses encodeMetaFileEncryption
+func decodeMetaFileEncryption(data string) (*metaid_protocols.MetaFileEncryption, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var encryption metaid_protocols.MetaFileEncryption
+	if err := json.Unmarshal([]byte(data), &encryption); err != nil {
+		return nil, err
+	}
+	return &encryption, nil
+}
+
+// encodeMetaFileDelta serializes a delta envelope for an async task row
+// (empty when there is none)
+func encodeMetaFileDelta(delta *metaid_protocols.MetaFileDelta) (string, error) {
+	if delta == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(delta)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode delta envelope: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeMetaFileDelta reverses encodeMetaFileDelta
+func decodeMetaFileDelta(data string) (*metaid_protocols.MetaFileDelta, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var delta metaid_protocols.MetaFileDelta
+	if err := json.Unmarshal([]byte(data), &delta); err != nil {
+		return nil, err
+	}
+	return &delta, nil
+}
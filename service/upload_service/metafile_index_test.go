@@ -0,0 +1,72 @@
+package upload_service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+func TestNormalizeMetaFileEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+		enc      *metaid_protocols.MetaFileEncryption
+		wantErr  bool
+	}{
+		{"", metaid_protocols.MetaFileCompressionNone, nil, false},
+		{" GZIP ", metaid_protocols.MetaFileCompressionGzip, nil, false},
+		{"zstd", "", nil, true},
+		{"none", "none", &metaid_protocols.MetaFileEncryption{Algorithm: "aes-256-gcm"}, false},
+		{"none", "none", &metaid_protocols.MetaFileEncryption{KeyId: "k1"}, true},
+	} {
+		got := tc.in
+		err := normalizeMetaFileEncoding(&got, tc.enc)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("normalizeMetaFileEncoding(%q) err = %v, wantErr %v", tc.in, err, tc.wantErr)
+		}
+		if err == nil && got != tc.want {
+			t.Errorf("normalizeMetaFileEncoding(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestChunkedIndexSize_MatchesV2Index(t *testing.T) {
+	req := &UploadCostRequest{FileName: "a.bin", ContentType: "application/octet-stream"}
+	for _, tc := range []struct {
+		fileSize, chunkSize int64
+	}{
+		{1, 2},
+		{2000, 1000},
+		{5*1024*1024 + 17, 2 * 1024 * 1024},
+		{123456, 1000},
+	} {
+		chunkNumber := int((tc.fileSize + tc.chunkSize - 1) / tc.chunkSize)
+		var chunkList []metaid_protocols.MetaFileChunk
+		for i := 0; i < chunkNumber; i++ {
+			_, length := (&metaid_protocols.MetaFileIndex{FileSize: tc.fileSize, ChunkSize: tc.chunkSize}).ChunkRange(i)
+			chunkList = metaid_protocols.AppendMetaFileChunk(chunkList, strings.Repeat("a", 64), strings.Repeat("b", placeholderPinIDLen), length)
+		}
+		data, err := json.Marshal(metaid_protocols.MetaFileIndex{
+			Version:     metaid_protocols.MetaFileIndexV2,
+			Sha256:      strings.Repeat("c", 64),
+			FileSize:    tc.fileSize,
+			ChunkNumber: chunkNumber,
+			ChunkSize:   tc.chunkSize,
+			DataType:    req.ContentType,
+			Name:        req.FileName,
+			Compression: metaid_protocols.MetaFileCompressionNone,
+			ChunkList:   chunkList,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := chunkedIndexSize(req, tc.fileSize, tc.chunkSize, chunkNumber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != len(data) {
+			t.Errorf("chunkedIndexSize(%d, %d) = %d, want %d", tc.fileSize, tc.chunkSize, got, len(data))
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"meta-file-system/conf"
+)
+
+// ErrPayloadTooLarge is returned (as a *PayloadTooLargeError) when a
+// single-PIN upload would inscribe more than the chain's payload limit
+var ErrPayloadTooLarge = errors.New("payload too large for a single PIN")
+
+// PayloadTooLargeError payload of a single-PIN upload above the chain's
+// uploader max_single_payload_bytes
+type PayloadTooLargeError struct {
+	Chain string // Blockchain
+	Size  int64  // Payload size in bytes (after gzip)
+	Max   int64  // Largest single-PIN payload in bytes
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("%s: payload is %d bytes, %s allows at most %d bytes per PIN; use chunked upload (/files/chunked-upload) or set chunkedFallback=true",
+		ErrPayloadTooLarge, e.Size, e.Chain, e.Max)
+}
+
+// Is makes errors.Is(err, ErrPayloadTooLarge) match
+func (e *PayloadTooLargeError) Is(target error) bool {
+	return target == ErrPayloadTooLarge
+}
+
+// checkSinglePayload returns a *PayloadTooLargeError when size exceeds the
+// single-PIN payload limit of chain
+func checkSinglePayload(chain string, size int) error {
+	max := conf.GetUploaderMaxSinglePayload(chain)
+	if max > 0 && int64(size) > max {
+		return &PayloadTooLargeError{Chain: chain, Size: int64(size), Max: max}
+	}
+	return nil
+}
+
+// directUploadViaChunks routes a direct upload whose payload is too large for
+// one PIN through the chunked pipeline: PreTxHex funds the chunks and
+// IndexPreTxHex the index transaction.
+func (s *UploadService) directUploadViaChunks(req *DirectUploadRequest, cause error) (*UploadResponse, error) {
+	if req.IndexPreTxHex == "" {
+		return nil, fmt.Errorf("%w (chunked fallback needs indexPreTxHex)", cause)
+	}
+	log.Printf("DirectUpload: %v, falling back to chunked upload", cause)
+
+	chunked, err := s.ChunkedUpload(&ChunkedUploadRequest{
+		MetaId:        req.MetaId,
+		Address:       req.Address,
+		FileName:      req.FileName,
+		Content:       req.Content,
+		Path:          req.Path,
+		Operation:     req.Operation,
+		ContentType:   req.ContentType,
+		Chain:         "mvc",
+		ChunkPreTxHex: req.PreTxHex,
+		IndexPreTxHex: req.IndexPreTxHex,
+		MergeTxHex:    req.MergeTxHex,
+		FeeRate:       req.FeeRate,
+		IsBroadcast:   true,
+		DryRun:        req.DryRun,
+		StorageClass:  req.StorageClass,
+		preFunded:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pinId := chunked.IndexPinId
+	if pinId == "" && chunked.IndexTxId != "" {
+		pinId = chunked.IndexTxId + "i0"
+	}
+	return &UploadResponse{
+		FileId:  chunked.FileId,
+		Status:  chunked.Status,
+		TxId:    chunked.IndexTxId,
+		PinId:   pinId,
+		Message: chunked.Message,
+		DryRun:  chunked.DryRun,
+		Chunked: chunked,
+	}, nil
+}
@@ -0,0 +1,147 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-file-system/conf"
+)
+
+func setPayloadLimitConfig(t *testing.T, global, mvc int64) {
+	t.Helper()
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{
+		Net: "testnet",
+		Uploader: conf.UploaderConfig{
+			MaxFileSize:           10 * 1024 * 1024,
+			FeeRate:               5,
+			ChunkSize:             2 * 1024 * 1024,
+			MaxSinglePayloadBytes: global,
+			Chains: []conf.UploaderChainConfig{
+				{Name: "mvc", ChunkSize: 1, MaxSinglePayloadBytes: mvc},
+			},
+		},
+	}
+	t.Cleanup(func() { conf.Cfg = prev })
+}
+
+func testPreTxHex(t *testing.T) string {
+	t.Helper()
+	preTx := wire2.NewMsgTx(10)
+	preTx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(&chainhash2.Hash{1}, 0), nil))
+	var buf bytes.Buffer
+	if err := preTx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(buf.Bytes())
+}
+
+func TestGetUploaderMaxSinglePayload(t *testing.T) {
+	setPayloadLimitConfig(t, 0, 0)
+	if got := conf.GetUploaderMaxSinglePayload("mvc"); got != 1024*1024 {
+		t.Errorf("default = %d, want the mvc chunk size %d", got, 1024*1024)
+	}
+	if got := conf.GetUploaderMaxSinglePayload("btc"); got != 2*1024*1024 {
+		t.Errorf("unconfigured chain = %d, want the global chunk size", got)
+	}
+
+	conf.Cfg.Uploader.MaxSinglePayloadBytes = 5000
+	if got := conf.GetUploaderMaxSinglePayload("mvc"); got != 5000 {
+		t.Errorf("global limit = %d, want 5000", got)
+	}
+	conf.Cfg.Uploader.Chains[0].MaxSinglePayloadBytes = 300
+	if got := conf.GetUploaderMaxSinglePayload("mvc"); got != 300 {
+		t.Errorf("chain limit = %d, want 300", got)
+	}
+}
+
+func TestDirectUpload_RejectsPayloadAboveSinglePinLimit(t *testing.T) {
+	setPayloadLimitConfig(t, 0, 100)
+	s := &UploadService{}
+
+	_, err := s.DirectUpload(&DirectUploadRequest{
+		Content:  bytes.Repeat([]byte{7}, 101),
+		Path:     "/file",
+		PreTxHex: testPreTxHex(t),
+		DryRun:   true,
+	})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("DirectUpload = %v, want ErrPayloadTooLarge", err)
+	}
+	var tooLarge *PayloadTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Chain != "mvc" || tooLarge.Size != 101 || tooLarge.Max != 100 {
+		t.Errorf("error = %#v, want mvc 101/100", tooLarge)
+	}
+
+	// Exactly at the limit is still a single PIN
+	if _, err := s.DirectUpload(&DirectUploadRequest{
+		Content:  bytes.Repeat([]byte{7}, 100),
+		Path:     "/file",
+		PreTxHex: testPreTxHex(t),
+		DryRun:   true,
+	}); err != nil {
+		t.Errorf("DirectUpload at the limit: %v", err)
+	}
+
+	// Gzip shrinks compressible content below the limit
+	gzip := true
+	if _, err := s.DirectUpload(&DirectUploadRequest{
+		Content:  bytes.Repeat([]byte{7}, 1000),
+		Path:     "/file",
+		PreTxHex: testPreTxHex(t),
+		DryRun:   true,
+		Gzip:     &gzip,
+	}); err != nil {
+		t.Errorf("DirectUpload of compressible content: %v", err)
+	}
+}
+
+func TestPreUpload_RejectsPayloadAboveSinglePinLimit(t *testing.T) {
+	setPayloadLimitConfig(t, 100, 0)
+	s := &UploadService{}
+
+	_, err := s.PreUpload(&UploadRequest{
+		Content: bytes.Repeat([]byte{7}, 101),
+		Path:    "/file",
+	})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("PreUpload = %v, want ErrPayloadTooLarge", err)
+	}
+}
+
+func TestDirectUpload_ChunkedFallback(t *testing.T) {
+	setPayloadLimitConfig(t, 0, 100)
+	s := &UploadService{}
+
+	// Without an index pre-tx the structured error is kept
+	_, err := s.DirectUpload(&DirectUploadRequest{
+		Content:         bytes.Repeat([]byte{7}, 101),
+		Path:            "/file",
+		PreTxHex:        testPreTxHex(t),
+		ChunkedFallback: true,
+		DryRun:          true,
+	})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("fallback without indexPreTxHex = %v, want ErrPayloadTooLarge", err)
+	}
+
+	// With one the request reaches the chunked pipeline, which rejects a
+	// base path it cannot chunk under before touching the database
+	_, err = s.DirectUpload(&DirectUploadRequest{
+		Address:         "addr",
+		Content:         bytes.Repeat([]byte{7}, 101),
+		Path:            "/protocols/simplebuzz",
+		PreTxHex:        testPreTxHex(t),
+		IndexPreTxHex:   testPreTxHex(t),
+		ChunkedFallback: true,
+		DryRun:          true,
+	})
+	if !errors.Is(err, ErrUnsupportedBasePath) {
+		t.Fatalf("fallback = %v, want the chunked pipeline's ErrUnsupportedBasePath", err)
+	}
+}
@@ -0,0 +1,266 @@
+package upload_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/node"
+)
+
+var (
+	// ErrInvalidProofHash the hash is not 64 hex characters
+	ErrInvalidProofHash = errors.New("invalid proof hash: want a SHA256 as 64 hex characters")
+	// ErrProofNotFound no proof recorded for the hash
+	ErrProofNotFound = errors.New("proof not found")
+)
+
+// ProofPath MetaID path proof-of-existence hashes are inscribed under
+const ProofPath = "/proof/sha256"
+
+// maxProofsPerHash proofs of one hash verified per request, oldest first
+const maxProofsPerHash = 10
+
+var proofHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Status of a proof transaction as reported by the node
+const (
+	ProofStatusConfirmed   = "confirmed"   // In a block; merkle proof included
+	ProofStatusUnconfirmed = "unconfirmed" // Broadcast, not mined yet
+	ProofStatusUnavailable = "unavailable" // The node could not return the transaction
+)
+
+// ProofRequest inscribes a SHA256 hash (no content) through a direct upload
+type ProofRequest struct {
+	Hash             string // SHA256 of the notarized data (hex)
+	MetaId           string // MetaID
+	Address          string // Address (also used as change address if ChangeAddress is empty)
+	ChangeAddress    string // Change address (optional, defaults to Address)
+	PreTxHex         string // Pre-transaction hex (signed, with inputs and outputs)
+	MergeTxHex       string // Merge transaction hex (optional)
+	FeeRate          int64  // Fee rate (satoshis per byte, optional, defaults to config)
+	TotalInputAmount int64  // Total input amount in satoshis (optional, for change calculation)
+	DryRun           bool   // Build and return the transaction without broadcasting or saving anything
+}
+
+// normalizeProofHash lower-cases hash and checks it is a hex SHA256
+func normalizeProofHash(hash string) (string, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if !proofHashPattern.MatchString(hash) {
+		return "", ErrInvalidProofHash
+	}
+	return hash, nil
+}
+
+// CreateProof inscribes req.Hash as text under ProofPath, using the same
+// pre-tx flow as a direct upload, and records the proof for verification.
+func (s *UploadService) CreateProof(req *ProofRequest) (*UploadResponse, error) {
+	hash, err := normalizeProofHash(req.Hash)
+	if err != nil {
+		return nil, err
+	}
+	noGzip := false
+	resp, err := s.DirectUpload(&DirectUploadRequest{
+		MetaId:           req.MetaId,
+		Address:          req.Address,
+		FileName:         hash,
+		Content:          []byte(hash),
+		Path:             ProofPath,
+		Operation:        "create",
+		ContentType:      "text/plain",
+		MergeTxHex:       req.MergeTxHex,
+		PreTxHex:         req.PreTxHex,
+		ChangeAddress:    req.ChangeAddress,
+		FeeRate:          req.FeeRate,
+		TotalInputAmount: req.TotalInputAmount,
+		DryRun:           req.DryRun,
+		Gzip:             &noGzip,
+		StorageClass:     string(model.StorageClassHot),
+	})
+	if err != nil || resp.DryRun {
+		return resp, err
+	}
+
+	proof := &model.Proof{Hash: hash, TxID: resp.TxId, PinId: resp.PinId, MetaId: req.MetaId, Address: req.Address}
+	if err := s.proofDAO.Save(proof); err != nil {
+		// The inscription is on chain; GetProof just cannot find it by hash
+		log.Printf("Failed to record proof %s (tx %s already broadcast): %v", hash, resp.TxId, err)
+	}
+	return resp, nil
+}
+
+// ProofVerification one inscription of a hash and where it was mined
+type ProofVerification struct {
+	TxId          string    `json:"txId"`                    // Inscription transaction ID
+	PinId         string    `json:"pinId"`                   // Pin ID
+	MetaId        string    `json:"metaId"`                  // MetaID of the notarizer
+	Address       string    `json:"address"`                 // Address of the notarizer
+	CreatedAt     time.Time `json:"createdAt"`               // When the uploader broadcast it
+	Status        string    `json:"status"`                  // confirmed, unconfirmed or unavailable
+	Message       string    `json:"message,omitempty"`       // Why the status is unavailable
+	TxHex         string    `json:"txHex,omitempty"`         // Raw transaction (its OP_RETURN carries the hash)
+	BlockHash     string    `json:"blockHash,omitempty"`     // Block containing the transaction
+	BlockHeight   int64     `json:"blockHeight,omitempty"`   // Height of that block
+	BlockTime     int64     `json:"blockTime,omitempty"`     // Block timestamp (unix seconds)
+	Confirmations uint64    `json:"confirmations,omitempty"` // Confirmations when queried
+	MerkleRoot    string    `json:"merkleRoot,omitempty"`    // Merkle root of the block header
+	TxIndex       int       `json:"txIndex"`                 // Position of the transaction in the block
+	MerkleProof   []string  `json:"merkleProof,omitempty"`   // Sibling hashes from the leaf level up (see docs)
+}
+
+// ProofResponse the proofs of a hash
+type ProofResponse struct {
+	Hash   string              `json:"hash"`   // Notarized SHA256
+	Proofs []ProofVerification `json:"proofs"` // Inscriptions of the hash, oldest first
+}
+
+// GetProof returns the verification data of every recorded inscription of
+// hash: the raw transaction and, once mined, its block and merkle branch.
+func (s *UploadService) GetProof(hash string) (*ProofResponse, error) {
+	hash, err := normalizeProofHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	proofs, err := s.proofDAO.ListByHash(hash, maxProofsPerHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proofs: %w", err)
+	}
+	if len(proofs) == 0 {
+		return nil, ErrProofNotFound
+	}
+	resp := &ProofResponse{Hash: hash, Proofs: make([]ProofVerification, 0, len(proofs))}
+	for _, p := range proofs {
+		resp.Proofs = append(resp.Proofs, s.verifyProof(p))
+	}
+	return resp, nil
+}
+
+// verifyProof looks the proof transaction up on the node. The merkle branch
+// is computed from the block's transaction list the first time the
+// transaction is seen in a block and cached on the proof.
+func (s *UploadService) verifyProof(p *model.Proof) ProofVerification {
+	v := ProofVerification{TxId: p.TxID, PinId: p.PinId, MetaId: p.MetaId, Address: p.Address, CreatedAt: p.CreatedAt}
+	tx, err := node.GetTxDetail(conf.Cfg.Net, p.TxID)
+	if err != nil {
+		v.Status, v.Message = ProofStatusUnavailable, err.Error()
+		return v
+	}
+	if txHex, err := node.GetTxRaw(conf.Cfg.Net, p.TxID); err == nil {
+		v.TxHex = txHex
+	}
+	if tx.Blockhash == "" {
+		v.Status = ProofStatusUnconfirmed
+		return v
+	}
+
+	if p.BlockHash != tx.Blockhash || p.MerklePath == "" {
+		if err := s.locateProofBlock(p, tx.Blockhash); err != nil {
+			v.Status, v.Message = ProofStatusUnavailable, err.Error()
+			return v
+		}
+	}
+	var branch []string
+	if err := json.Unmarshal([]byte(p.MerklePath), &branch); err != nil {
+		v.Status, v.Message = ProofStatusUnavailable, fmt.Sprintf("invalid cached merkle path: %v", err)
+		return v
+	}
+
+	v.Status = ProofStatusConfirmed
+	v.BlockHash = p.BlockHash
+	v.BlockHeight = p.BlockHeight
+	v.BlockTime = tx.Blocktime
+	v.Confirmations = tx.Confirmations
+	v.MerkleRoot = p.MerkleRoot
+	v.TxIndex = p.TxIndex
+	v.MerkleProof = branch
+	return v
+}
+
+// locateProofBlock finds the proof transaction in block blockHash and caches
+// the block and merkle branch on p
+func (s *UploadService) locateProofBlock(p *model.Proof, blockHash string) error {
+	block, err := node.GetBlock(conf.Cfg.Net, blockHash)
+	if err != nil {
+		return fmt.Errorf("failed to get block %s: %w", blockHash, err)
+	}
+	index := -1
+	for i, txId := range block.Tx {
+		if txId == p.TxID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("transaction %s not listed in block %s", p.TxID, blockHash)
+	}
+	branch, root, err := merkleBranch(block.Tx, index)
+	if err != nil {
+		return err
+	}
+	if root != block.Merkleroot {
+		return fmt.Errorf("computed merkle root %s does not match block %s root %s", root, blockHash, block.Merkleroot)
+	}
+	path, err := json.Marshal(branch)
+	if err != nil {
+		return err
+	}
+
+	p.BlockHash = blockHash
+	p.BlockHeight = int64(block.Height)
+	p.MerkleRoot = root
+	p.TxIndex = index
+	p.MerklePath = string(path)
+	if err := s.proofDAO.Update(p); err != nil {
+		log.Printf("Failed to cache merkle proof of %s: %v", p.PinId, err)
+	}
+	return nil
+}
+
+// merkleBranch returns the sibling hashes on the path from txIds[index] to
+// the merkle root, leaf level first, and the root. Hashes are hex in the
+// usual reversed (RPC) byte order. A level with an odd count pairs its last
+// hash with itself, so that sibling is the hash itself.
+func merkleBranch(txIds []string, index int) ([]string, string, error) {
+	if index < 0 || index >= len(txIds) {
+		return nil, "", fmt.Errorf("transaction index %d out of range (block has %d)", index, len(txIds))
+	}
+	level := make([]chainhash.Hash, len(txIds))
+	for i, txId := range txIds {
+		h, err := chainhash.NewHashFromStr(txId)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid transaction ID %q: %w", txId, err)
+		}
+		level[i] = *h
+	}
+
+	branch := make([]string, 0)
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		branch = append(branch, level[index^1].String())
+		next := make([]chainhash.Hash, len(level)/2)
+		for i := range next {
+			next[i] = hashMerklePair(level[2*i], level[2*i+1])
+		}
+		level = next
+		index /= 2
+	}
+	return branch, level[0].String(), nil
+}
+
+// hashMerklePair double-SHA256s the concatenation of left and right (internal byte order)
+func hashMerklePair(left, right chainhash.Hash) chainhash.Hash {
+	var buf [chainhash.HashSize * 2]byte
+	copy(buf[:chainhash.HashSize], left[:])
+	copy(buf[chainhash.HashSize:], right[:])
+	return chainhash.DoubleHashH(buf[:])
+}
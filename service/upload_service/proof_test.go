@@ -0,0 +1,99 @@
+package upload_service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+)
+
+// Bitcoin block 100000
+var block100000 = struct {
+	txIds []string
+	root  string
+}{
+	txIds: []string{
+		"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+		"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+		"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+	},
+	root: "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766",
+}
+
+// rootFromBranch recomputes the merkle root the way a client checks a proof
+func rootFromBranch(t *testing.T, txId string, index int, branch []string) string {
+	t.Helper()
+	h, err := chainhash.NewHashFromStr(txId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur := *h
+	for _, node := range branch {
+		sibling, err := chainhash.NewHashFromStr(node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index%2 == 0 {
+			cur = hashMerklePair(cur, *sibling)
+		} else {
+			cur = hashMerklePair(*sibling, cur)
+		}
+		index /= 2
+	}
+	return cur.String()
+}
+
+func TestMerkleBranch(t *testing.T) {
+	for i, txId := range block100000.txIds {
+		branch, root, err := merkleBranch(block100000.txIds, i)
+		if err != nil {
+			t.Fatalf("index %d: %v", i, err)
+		}
+		if root != block100000.root {
+			t.Errorf("index %d: root = %s, want %s", i, root, block100000.root)
+		}
+		if len(branch) != 2 {
+			t.Errorf("index %d: branch has %d hashes, want 2", i, len(branch))
+		}
+		if got := rootFromBranch(t, txId, i, branch); got != block100000.root {
+			t.Errorf("index %d: branch leads to %s", i, got)
+		}
+	}
+
+	// Odd levels pair the last hash with itself
+	odd := block100000.txIds[:3]
+	_, want, _ := merkleBranch(odd, 0)
+	branch, root, err := merkleBranch(odd, 2)
+	if err != nil || root != want {
+		t.Fatalf("odd block: root %s (%v), want %s", root, err, want)
+	}
+	if branch[0] != odd[2] {
+		t.Errorf("odd block: last transaction's sibling = %s, want itself", branch[0])
+	}
+	if got := rootFromBranch(t, odd[2], 2, branch); got != want {
+		t.Errorf("odd block: branch leads to %s", got)
+	}
+
+	// A block with only the coinbase has the coinbase as root
+	if branch, root, err := merkleBranch(odd[:1], 0); err != nil || root != odd[0] || len(branch) != 0 {
+		t.Errorf("single transaction: branch %v, root %s, err %v", branch, root, err)
+	}
+
+	if _, _, err := merkleBranch(odd, 3); err == nil {
+		t.Error("index out of range accepted")
+	}
+}
+
+func TestNormalizeProofHash(t *testing.T) {
+	upper := strings.ToUpper(block100000.root)
+	if got, err := normalizeProofHash(" " + upper + " "); err != nil || got != block100000.root {
+		t.Errorf("normalizeProofHash(upper) = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", block100000.root[:63], block100000.root + "0", "zz" + block100000.root[2:]} {
+		if _, err := normalizeProofHash(bad); !errors.Is(err, ErrInvalidProofHash) {
+			t.Errorf("normalizeProofHash(%q) err = %v", bad, err)
+		}
+	}
+}
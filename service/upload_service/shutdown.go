@@ -0,0 +1,65 @@
+package upload_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"meta-file-system/model"
+	"meta-file-system/shutdown"
+)
+
+// errTaskInterrupted stops a task driver between two stages on shutdown; the
+// task goes back to pending and resumes from its stage after a restart
+var errTaskInterrupted = errors.New("upload task interrupted by shutdown")
+
+// BeginShutdown makes running upload tasks stop before their next stage
+func (s *UploadService) BeginShutdown() {
+	s.stopping.Store(true)
+}
+
+// taskCheckpoint is checked before every stage of a task driver. Every stage
+// persists its result, so a task stopped here resumes where it left off.
+func (s *UploadService) taskCheckpoint(task *model.FileUploaderTask) error {
+	if s.stopping.Load() {
+		return fmt.Errorf("%w at stage %s", errTaskInterrupted, task.Stage)
+	}
+	return nil
+}
+
+// requeueInterruptedTask returns a task stopped by shutdown to pending. Its
+// inputs stay reserved and its payload is kept for the resumed run.
+func (s *UploadService) requeueInterruptedTask(task *model.FileUploaderTask) error {
+	task.Status = model.StatusPending
+	task.CurrentStep = fmt.Sprintf("Interrupted by shutdown at stage %s, resumes after restart", task.Stage)
+	if err := s.fileUploaderTaskDAO.Update(task); err != nil {
+		return fmt.Errorf("failed to update task status: %w", err)
+	}
+	log.Printf("Task %s interrupted by shutdown at stage %s", task.TaskId, task.Stage)
+	return nil
+}
+
+// Shutdown stops the task processor for a service shutdown: no new task is
+// picked up and running tasks stop before their next stage. Returns once they
+// stopped, or with ctx's error when ctx is done first.
+func (tp *TaskProcessor) Shutdown(ctx context.Context) error {
+	tp.Stop()
+	tp.uploadService.BeginShutdown()
+	return shutdown.Within(ctx, func() {
+		<-tp.done
+		tp.tasks.Wait()
+	})
+}
+
+// Shutdown stops the cleanup processor after its current round
+func (cp *CleanupProcessor) Shutdown(ctx context.Context) error {
+	cp.Stop()
+	return shutdown.Within(ctx, func() { <-cp.done })
+}
+
+// Shutdown stops the broadcast scheduler after its current round
+func (bs *BroadcastScheduler) Shutdown(ctx context.Context) error {
+	bs.Stop()
+	return shutdown.Within(ctx, func() { <-bs.done })
+}
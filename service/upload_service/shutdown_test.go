@@ -0,0 +1,80 @@
+package upload_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"meta-file-system/model"
+)
+
+func TestTaskDriversStopBeforeNextStage(t *testing.T) {
+	s := &UploadService{}
+	s.BeginShutdown()
+
+	stages := []model.TaskStage{
+		"",
+		model.TaskStageCreated,
+		model.TaskStagePrepared,
+		model.TaskStageMergeBroadcast,
+		model.TaskStageFundingBroadcast,
+		model.TaskStageChunkBroadcast,
+	}
+	for _, chain := range []string{"mvc", "doge"} {
+		for _, stage := range stages {
+			task := &model.FileUploaderTask{Chain: chain, Stage: stage}
+			var err error
+			if chain == "doge" {
+				_, err = s.chunkedUploadOnTaskInDoge(&ChunkedUploadRequest{}, task)
+			} else {
+				_, err = s.chunkedUploadOnTask(&ChunkedUploadRequest{}, task)
+			}
+			if !errors.Is(err, errTaskInterrupted) {
+				t.Errorf("%s stage %q: err = %v, want errTaskInterrupted", chain, stage, err)
+			}
+			if want := stage; want != "" && task.Stage != want {
+				t.Errorf("%s stage %q: stage moved to %q", chain, stage, task.Stage)
+			}
+		}
+	}
+
+	// Broadcast transactions are not undone: a task past its index broadcast
+	// still completes
+	task := &model.FileUploaderTask{Chain: "mvc", Stage: model.TaskStageIndexBroadcast, IndexTxId: "index"}
+	if resp, err := s.chunkedUploadOnTask(&ChunkedUploadRequest{}, task); err != nil || resp.IndexTxId != "index" {
+		t.Errorf("index stage: resp = %+v, err = %v", resp, err)
+	}
+}
+
+func TestTaskProcessorShutdownWaitsForTasks(t *testing.T) {
+	tp := &TaskProcessor{
+		uploadService: &UploadService{},
+		stopChan:      make(chan struct{}),
+		done:          make(chan struct{}),
+		interval:      time.Hour,
+	}
+	go tp.run()
+	tp.tasks.Add(1) // A task still running its stage
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- tp.Shutdown(context.Background()) }()
+	select {
+	case err := <-stopped:
+		t.Fatalf("Shutdown returned with a task running: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !tp.uploadService.stopping.Load() {
+		t.Error("running tasks were not told to stop")
+	}
+
+	tp.tasks.Done()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown did not return after the task stopped")
+	}
+}
@@ -0,0 +1,70 @@
+package upload_service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"meta-file-system/model"
+)
+
+// ParseStorageClass validates an upload's storage class hint; empty means hot.
+func ParseStorageClass(class string) (model.StorageClass, error) {
+	switch c := model.StorageClass(strings.ToLower(strings.TrimSpace(class))); c {
+	case "":
+		return model.StorageClassHot, nil
+	case model.StorageClassHot, model.StorageClassCold, model.StorageClassEphemeral:
+		return c, nil
+	default:
+		return "", fmt.Errorf("invalid storage class %q (hot, cold or ephemeral)", class)
+	}
+}
+
+// normalizeStorageClass validates a request's storage class and rewrites it to
+// its canonical form
+func normalizeStorageClass(class *string) error {
+	c, err := ParseStorageClass(*class)
+	if err != nil {
+		return err
+	}
+	*class = string(c)
+	return nil
+}
+
+// PruneEphemeralFiles deletes the local records of ephemeral uploads that
+// finished (success or failed) before beforeTime, with their chunk records
+// unless another file still uses the same content. Files their owner keeps
+// (KeepFile) are skipped. The on-chain data and the indexer are not touched.
+// Returns the number of files pruned.
+func (s *UploadService) PruneEphemeralFiles(beforeTime time.Time, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	files, err := s.fileDAO.ListFinishedByStorageClass(model.StorageClassEphemeral, beforeTime, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list ephemeral files: %w", err)
+	}
+
+	pruned := 0
+	for _, file := range files {
+		if err := s.fileDAO.Delete(file.ID); err != nil {
+			log.Printf("Failed to prune ephemeral file (fileId=%s): %v", file.FileId, err)
+			continue
+		}
+		pruned++
+
+		if file.ChunkType != model.ChunkTypeMulti || file.FileHash == "" {
+			continue
+		}
+		if remaining, err := s.fileDAO.CountByFileHash(file.FileHash); err != nil || remaining > 0 {
+			continue
+		}
+		if err := s.fileChunkDAO.DeleteByFileHash(file.FileHash); err != nil {
+			log.Printf("Failed to prune chunks of ephemeral file (fileId=%s): %v", file.FileId, err)
+		}
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,43 @@
+package upload_service
+
+import (
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func TestParseStorageClass(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    model.StorageClass
+		wantErr bool
+	}{
+		{"", model.StorageClassHot, false},
+		{"hot", model.StorageClassHot, false},
+		{" Cold ", model.StorageClassCold, false},
+		{"EPHEMERAL", model.StorageClassEphemeral, false},
+		{"archive", "", true},
+	}
+	for _, tc := range cases {
+		got, err := ParseStorageClass(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("ParseStorageClass(%q) err = %v, wantErr %v", tc.in, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("ParseStorageClass(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestUploadRejectsUnknownStorageClass(t *testing.T) {
+	// The uploader DB is not initialised: validation must fail before any save
+	s := &UploadService{}
+	_, err := s.PreUpload(&UploadRequest{Content: []byte("x"), Path: "/file", StorageClass: "forever"})
+	if err == nil {
+		t.Fatal("PreUpload accepted an unknown storage class")
+	}
+	_, err = s.ChunkedUpload(&ChunkedUploadRequest{Content: []byte("x"), Path: "/file", StorageClass: "forever"})
+	if err == nil {
+		t.Fatal("ChunkedUpload accepted an unknown storage class")
+	}
+}
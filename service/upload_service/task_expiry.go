@@ -0,0 +1,91 @@
+package upload_service
+
+import (
+	"fmt"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+const (
+	// DefaultTaskStatsHours window of GetTaskStats when none is given
+	DefaultTaskStatsHours = 24
+	// MaxTaskStatsHours largest window of GetTaskStats (30 days)
+	MaxTaskStatsHours = 720
+)
+
+// ExpireStaleTasks expires async upload tasks that broadcast nothing within
+// uploader.task_ttl: pending tasks older than pending_hours and processing
+// tasks without progress for processing_hours. Their payload (content and
+// built transactions) is dropped. Returns the number of tasks expired.
+func (s *UploadService) ExpireStaleTasks(now time.Time) (int64, error) {
+	ttl := conf.Cfg.Uploader.TaskTTL
+	var expired int64
+	for _, stale := range []struct {
+		status model.Status
+		hours  int
+	}{
+		{model.StatusPending, ttl.PendingHours},
+		{"processing", ttl.ProcessingHours},
+	} {
+		message := fmt.Sprintf("Expired: %s for %dh without broadcasting", stale.status, stale.hours)
+		n, err := s.fileUploaderTaskDAO.ExpireStale(stale.status, now.Add(-time.Duration(stale.hours)*time.Hour), now, message)
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire %s tasks: %w", stale.status, err)
+		}
+		expired += n
+	}
+	return expired, nil
+}
+
+// TaskStats outcome counts of async upload tasks finished in the window,
+// plus the tasks still open
+type TaskStats struct {
+	Hours       int     `json:"hours" example:"24"`         // Window: tasks finished in the last hours
+	Completed   int64   `json:"completed" example:"95"`     // Finished with success
+	Failed      int64   `json:"failed" example:"2"`         // Finished with an error
+	Cancelled   int64   `json:"cancelled" example:"1"`      // Cancelled before broadcast
+	Expired     int64   `json:"expired" example:"5"`        // Expired by uploader.task_ttl
+	ExpiredRate float64 `json:"expiredRate" example:"0.05"` // expired / (completed + expired); 0 when both are 0
+	Pending     int64   `json:"pending" example:"3"`        // Open now: waiting for the task processor
+	Processing  int64   `json:"processing" example:"1"`     // Open now: being built or broadcast
+	Scheduled   int64   `json:"scheduled" example:"4"`      // Open now: broadcast held (broadcastAt / targetFeeRate)
+}
+
+// GetTaskStats counts tasks finished in the last hours by outcome, so
+// operators can watch the expired-vs-completed rate
+func (s *UploadService) GetTaskStats(hours int) (*TaskStats, error) {
+	if hours <= 0 {
+		hours = DefaultTaskStatsHours
+	}
+	if hours > MaxTaskStatsHours {
+		hours = MaxTaskStatsHours
+	}
+
+	finished, err := s.fileUploaderTaskDAO.CountFinishedSince(time.Now().Add(-time.Duration(hours) * time.Hour))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count finished tasks: %w", err)
+	}
+	stats := &TaskStats{
+		Hours:     hours,
+		Completed: finished[model.StatusSuccess],
+		Failed:    finished[model.StatusFailed],
+		Cancelled: finished[model.TaskStatusCancelled],
+		Expired:   finished[model.TaskStatusExpired],
+	}
+	if total := stats.Completed + stats.Expired; total > 0 {
+		stats.ExpiredRate = float64(stats.Expired) / float64(total)
+	}
+
+	for status, count := range map[model.Status]*int64{
+		model.StatusPending:       &stats.Pending,
+		"processing":              &stats.Processing,
+		model.TaskStatusScheduled: &stats.Scheduled,
+	} {
+		if *count, err = s.fileUploaderTaskDAO.Count(string(status)); err != nil {
+			return nil, fmt.Errorf("failed to count %s tasks: %w", status, err)
+		}
+	}
+	return stats, nil
+}
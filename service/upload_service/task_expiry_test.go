@@ -0,0 +1,53 @@
+package upload_service
+
+import (
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+func TestExpireStaleTasksAndStats(t *testing.T) {
+	setTestUploaderDB(t)
+	conf.Cfg.Uploader.TaskTTL = conf.UploadTaskTTLConfig{PendingHours: 24, ProcessingHours: 6}
+	s := NewUploadService(nil)
+	now := time.Now()
+
+	stats, err := s.GetTaskStats(0)
+	if err != nil {
+		t.Fatalf("GetTaskStats on no tasks: %v", err)
+	}
+	if stats.Hours != DefaultTaskStatsHours || stats.ExpiredRate != 0 {
+		t.Errorf("no tasks: stats = %+v, want the default window and rate 0", stats)
+	}
+
+	finished := now.Add(-time.Hour)
+	for _, task := range []*model.FileUploaderTask{
+		{TaskId: "pending-stale", Status: model.StatusPending, Stage: model.TaskStageCreated, UpdatedAt: now.Add(-25 * time.Hour)},
+		{TaskId: "pending-fresh", Status: model.StatusPending, Stage: model.TaskStageCreated, UpdatedAt: now.Add(-23 * time.Hour)},
+		{TaskId: "processing-stale", Status: "processing", Stage: model.TaskStagePrepared, UpdatedAt: now.Add(-7 * time.Hour)},
+		{TaskId: "processing-fresh", Status: "processing", Stage: model.TaskStagePrepared, UpdatedAt: now.Add(-5 * time.Hour)},
+		{TaskId: "done", Status: model.StatusSuccess, Stage: model.TaskStageCompleted, FinishedAt: &finished},
+	} {
+		if err := s.fileUploaderTaskDAO.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expired, err := s.ExpireStaleTasks(now)
+	if err != nil || expired != 2 {
+		t.Fatalf("ExpireStaleTasks = %d, %v; want 2", expired, err)
+	}
+
+	stats, err = s.GetTaskStats(MaxTaskStatsHours + 1)
+	if err != nil {
+		t.Fatalf("GetTaskStats: %v", err)
+	}
+	if stats.Hours != MaxTaskStatsHours || stats.Completed != 1 || stats.Expired != 2 || stats.Pending != 1 || stats.Processing != 1 {
+		t.Errorf("stats = %+v, want 1 completed, 2 expired, 1 pending, 1 processing over %dh", stats, MaxTaskStatsHours)
+	}
+	if want := 2.0 / 3.0; stats.ExpiredRate != want {
+		t.Errorf("ExpiredRate = %v, want %v", stats.ExpiredRate, want)
+	}
+}
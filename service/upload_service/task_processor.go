@@ -2,6 +2,7 @@ package upload_service
 
 import (
 	"log"
+	"sync"
 	"time"
 
 	"meta-file-system/model"
@@ -13,6 +14,8 @@ type TaskProcessor struct {
 	uploadService    *UploadService
 	taskDAO          *dao.FileUploaderTaskDAO
 	stopChan         chan struct{}
+	done             chan struct{}  // Closed when run returns
+	tasks            sync.WaitGroup // Running processTask goroutines
 	interval         time.Duration
 	batchSize        int
 	stalledThreshold time.Duration
@@ -24,6 +27,7 @@ func NewTaskProcessor(uploadService *UploadService) *TaskProcessor {
 		uploadService:    uploadService,
 		taskDAO:          dao.NewFileUploaderTaskDAO(),
 		stopChan:         make(chan struct{}),
+		done:             make(chan struct{}),
 		interval:         5 * time.Second, // 每5秒轮询一次
 		batchSize:        5,               // 每次处理5个任务
 		stalledThreshold: 2 * time.Minute, // processing 任务超过2分钟视为卡住
@@ -44,6 +48,7 @@ func (tp *TaskProcessor) Stop() {
 
 // run 运行任务处理器主循环
 func (tp *TaskProcessor) run() {
+	defer close(tp.done)
 	ticker := time.NewTicker(tp.interval)
 	defer ticker.Stop()
 
@@ -93,12 +98,14 @@ func (tp *TaskProcessor) processPendingTasks() {
 	// 处理每个任务
 	for _, task := range uniqueTasks {
 		// 使用 goroutine 异步处理每个任务，避免阻塞
+		tp.tasks.Add(1)
 		go tp.processTask(task)
 	}
 }
 
 // processTask 处理单个任务
 func (tp *TaskProcessor) processTask(task *model.FileUploaderTask) {
+	defer tp.tasks.Done()
 	log.Printf("Processing task: taskId=%s, fileId=%s", task.TaskId, task.FileId)
 
 	// 处理任务
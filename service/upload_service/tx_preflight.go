@@ -0,0 +1,218 @@
+package upload_service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"meta-file-system/conf"
+	"meta-file-system/node"
+)
+
+// ErrTxRejected is returned (as a *TxRejectedError) when a built upload
+// transaction fails the node policy checks run before anything is broadcast
+var ErrTxRejected = errors.New("transaction rejected by node policy")
+
+// Sources of a TxRejectedError
+const (
+	TxRejectedByLocalCheck = "local" // Size, value and conflict checks of the uploader
+	TxRejectedByNode       = "node"  // The chain node's testmempoolaccept
+)
+
+// TxRejectedError one transaction of an upload the node would not accept
+type TxRejectedError struct {
+	Chain  string // Blockchain
+	Tx     string // Which transaction of the upload: merge, funding, chunk N, index
+	TxID   string // Transaction ID, empty when it could not be decoded
+	Reason string // Node reject reason, e.g. tx-size, dust, bad-txns-in-belowout
+	Detail string // Human-readable detail, optional
+	Source string // local or node
+}
+
+func (e *TxRejectedError) Error() string {
+	msg := fmt.Sprintf("%s: %s %s transaction", ErrTxRejected, e.Chain, e.Tx)
+	if e.TxID != "" {
+		msg += " " + e.TxID
+	}
+	msg += ": " + e.Reason
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	return msg
+}
+
+// Is makes errors.Is(err, ErrTxRejected) match
+func (e *TxRejectedError) Is(target error) bool {
+	return target == ErrTxRejected
+}
+
+// preflightTx a built transaction and its role in the upload
+type preflightTx struct {
+	label string // merge, funding, chunk N, index
+	hex   string
+}
+
+// preflightTransactions checks the transactions of one upload, in broadcast
+// order, before the first of them is broadcast, so a policy violation fails
+// the upload up front instead of after part of it is on chain. Local checks
+// always run; with uploader.preflight.node_check auto the node is asked with
+// testmempoolaccept too, for the transactions whose parents are not in txs.
+func (s *UploadService) preflightTransactions(chain string, txs []preflightTx) error {
+	decoded, err := checkTxPolicy(chain, txs)
+	if err != nil {
+		return err
+	}
+	if conf.Cfg == nil || conf.Cfg.Uploader.Preflight.NodeCheck != "auto" {
+		return nil
+	}
+
+	rpcChain := conf.Cfg.Net
+	if chain == "doge" {
+		rpcChain = "doge"
+	}
+	if _, unsupported := s.noMempoolAccept.Load(rpcChain); unsupported {
+		return nil
+	}
+	inSet := make(map[string]bool, len(decoded))
+	for _, lt := range decoded {
+		inSet[lt.txID] = true
+	}
+	for i, lt := range decoded {
+		if hasParentIn(lt, inSet) {
+			// The node cannot see the parent before it is broadcast
+			continue
+		}
+		results, err := node.TestMempoolAccept(rpcChain, strings.TrimSpace(txs[i].hex))
+		if node.IsMethodNotFoundError(err) {
+			s.noMempoolAccept.Store(rpcChain, true)
+			log.Printf("Preflight: %s node has no testmempoolaccept, using local checks only", rpcChain)
+			return nil
+		}
+		if err != nil {
+			log.Printf("Preflight: testmempoolaccept of %s %s failed, skipped: %v", chain, txs[i].label, err)
+			continue
+		}
+		for _, r := range results {
+			if r.Allowed || strings.Contains(r.RejectReason, "already") {
+				continue
+			}
+			return &TxRejectedError{Chain: chain, Tx: txs[i].label, TxID: lt.txID, Reason: r.RejectReason, Source: TxRejectedByNode}
+		}
+	}
+	return nil
+}
+
+// checkTxPolicy runs the local policy checks on txs and returns them decoded:
+// size, empty inputs or outputs, output values, inputs spent twice within
+// the set, and the fee of transactions whose inputs all come from the set
+func checkTxPolicy(chain string, txs []preflightTx) ([]*ledgerTx, error) {
+	maxSize := conf.GetUploaderMaxTxSize(chain)
+	decoded := make([]*ledgerTx, 0, len(txs))
+	byID := make(map[string]*ledgerTx, len(txs))
+	spent := make(map[ledgerOutPoint]string)
+	for _, t := range txs {
+		reject := func(txID, reason, detail string) error {
+			return &TxRejectedError{Chain: chain, Tx: t.label, TxID: txID, Reason: reason, Detail: detail, Source: TxRejectedByLocalCheck}
+		}
+
+		lt, err := decodeChainTx(chain, t.hex)
+		if err != nil {
+			return nil, reject("", "tx-decode-failed", err.Error())
+		}
+		switch {
+		case len(lt.inputs) == 0:
+			return nil, reject(lt.txID, "bad-txns-vin-empty", "")
+		case len(lt.outputs) == 0:
+			return nil, reject(lt.txID, "bad-txns-vout-empty", "")
+		case int64(lt.size) > maxSize:
+			return nil, reject(lt.txID, "tx-size", fmt.Sprintf("%d bytes, %s allows at most %d", lt.size, chain, maxSize))
+		}
+
+		var outValue int64
+		for i, out := range lt.outputs {
+			if out.value < 0 {
+				return nil, reject(lt.txID, "bad-txns-vout-negative", fmt.Sprintf("output %d", i))
+			}
+			if out.value == 0 && !isDataScript(out.pkScript) {
+				return nil, reject(lt.txID, "dust", fmt.Sprintf("output %d carries no value", i))
+			}
+			outValue += out.value
+		}
+
+		var inValue int64
+		allKnown := true
+		seen := make(map[ledgerOutPoint]bool, len(lt.inputs))
+		for _, in := range lt.inputs {
+			if seen[in] {
+				return nil, reject(lt.txID, "bad-txns-inputs-duplicate", fmt.Sprintf("%s:%d", in.txID, in.vout))
+			}
+			seen[in] = true
+			if other, ok := spent[in]; ok {
+				return nil, reject(lt.txID, "txn-mempool-conflict", fmt.Sprintf("%s:%d is also spent by the %s transaction", in.txID, in.vout, other))
+			}
+			spent[in] = t.label
+
+			parent, ok := byID[in.txID]
+			if !ok {
+				allKnown = false
+				continue
+			}
+			if int(in.vout) >= len(parent.outputs) {
+				return nil, reject(lt.txID, "bad-txns-inputs-missingorspent", fmt.Sprintf("%s has no output %d", in.txID, in.vout))
+			}
+			inValue += parent.outputs[in.vout].value
+		}
+		if allKnown && inValue < outValue {
+			return nil, reject(lt.txID, "bad-txns-in-belowout", fmt.Sprintf("inputs %d < outputs %d", inValue, outValue))
+		}
+
+		decoded = append(decoded, lt)
+		byID[lt.txID] = lt
+	}
+	return decoded, nil
+}
+
+// hasParentIn reports whether lt spends an output of a transaction in txIDs
+func hasParentIn(lt *ledgerTx, txIDs map[string]bool) bool {
+	for _, in := range lt.inputs {
+		if txIDs[in.txID] {
+			return true
+		}
+	}
+	return false
+}
+
+// isDataScript reports whether pkScript is an OP_RETURN data output
+// (OP_RETURN or OP_FALSE OP_RETURN), the only outputs allowed zero value
+func isDataScript(pkScript []byte) bool {
+	return bytes.HasPrefix(pkScript, []byte{0x6a}) || bytes.HasPrefix(pkScript, []byte{0x00, 0x6a})
+}
+
+// preflightTaskTxs lists the transactions a prepared task broadcasts, in
+// broadcast order. The index of an MVC task is only built after the chunks
+// are broadcast and is checked on its own then.
+func preflightTaskTxs(mergeTxHex, fundingTxHex string, chunkTxHexes, indexTxHexes []string) []preflightTx {
+	var txs []preflightTx
+	if hex := strings.TrimSpace(mergeTxHex); hex != "" {
+		txs = append(txs, preflightTx{"merge", hex})
+	}
+	if hex := strings.TrimSpace(fundingTxHex); hex != "" {
+		txs = append(txs, preflightTx{"funding", hex})
+	}
+	for i, hex := range chunkTxHexes {
+		txs = append(txs, preflightTx{fmt.Sprintf("chunk %d", i), hex})
+	}
+	for i, hex := range indexTxHexes {
+		if strings.TrimSpace(hex) == "" {
+			continue
+		}
+		label := "index"
+		if len(indexTxHexes) > 1 {
+			label = fmt.Sprintf("index %d", i+1)
+		}
+		txs = append(txs, preflightTx{label, hex})
+	}
+	return txs
+}
@@ -0,0 +1,118 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-file-system/common"
+	"meta-file-system/conf"
+)
+
+func preflightMvcTx(t *testing.T, inputs []*wire2.OutPoint, values ...int64) (string, *chainhash2.Hash) {
+	t.Helper()
+	tx := wire2.NewMsgTx(10)
+	for _, op := range inputs {
+		tx.AddTxIn(wire2.NewTxIn(op, nil))
+	}
+	for _, v := range values {
+		tx.AddTxOut(wire2.NewTxOut(v, []byte{0x76, 0xa9, 0x14}))
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	txHex := hex.EncodeToString(buf.Bytes())
+	txID, err := chainhash2.NewHashFromStr(common.GetMvcTxhashFromRaw(txHex))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txHex, txID
+}
+
+func TestCheckTxPolicy(t *testing.T) {
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{Net: "testnet"}
+	conf.Cfg.Uploader.Chains = []conf.UploaderChainConfig{{Name: "mvc", MaxTxSize: 1000}}
+	t.Cleanup(func() { conf.Cfg = prev })
+
+	external := wire2.NewOutPoint(&chainhash2.Hash{1}, 0)
+	fundingHex, fundingID := preflightMvcTx(t, []*wire2.OutPoint{external}, 1000, 1000)
+	chunkHex, _ := preflightMvcTx(t, []*wire2.OutPoint{wire2.NewOutPoint(fundingID, 0)}, 900)
+
+	withData := wire2.NewMsgTx(10)
+	withData.AddTxIn(wire2.NewTxIn(external, nil))
+	withData.AddTxOut(wire2.NewTxOut(0, []byte{0x00, 0x6a, 0x01, 0x01}))
+	var buf bytes.Buffer
+	if err := withData.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dataHex := hex.EncodeToString(buf.Bytes())
+
+	bigValues := make([]int64, 100)
+	for i := range bigValues {
+		bigValues[i] = 1
+	}
+	bigHex, _ := preflightMvcTx(t, []*wire2.OutPoint{external}, bigValues...)
+	overspendHex, _ := preflightMvcTx(t, []*wire2.OutPoint{wire2.NewOutPoint(fundingID, 1)}, 1001)
+	missingHex, _ := preflightMvcTx(t, []*wire2.OutPoint{wire2.NewOutPoint(fundingID, 5)}, 1)
+	zeroHex, _ := preflightMvcTx(t, []*wire2.OutPoint{external}, 0)
+	noInputHex, _ := preflightMvcTx(t, nil, 1)
+	dupHex, _ := preflightMvcTx(t, []*wire2.OutPoint{external, external}, 1)
+
+	cases := []struct {
+		name   string
+		txs    []preflightTx
+		reason string // empty: accepted
+		tx     string
+	}{
+		{"chain", []preflightTx{{"funding", fundingHex}, {"chunk 0", chunkHex}}, "", ""},
+		{"data output", []preflightTx{{"upload", dataHex}}, "", ""},
+		{"undecodable", []preflightTx{{"upload", "zz"}}, "tx-decode-failed", "upload"},
+		{"no inputs", []preflightTx{{"upload", noInputHex}}, "bad-txns-vin-empty", "upload"},
+		{"too large", []preflightTx{{"upload", bigHex}}, "tx-size", "upload"},
+		{"zero value", []preflightTx{{"upload", zeroHex}}, "dust", "upload"},
+		{"duplicate input", []preflightTx{{"upload", dupHex}}, "bad-txns-inputs-duplicate", "upload"},
+		{"conflict", []preflightTx{{"funding", fundingHex}, {"merge", dataHex}}, "txn-mempool-conflict", "merge"},
+		{"in below out", []preflightTx{{"funding", fundingHex}, {"chunk 1", overspendHex}}, "bad-txns-in-belowout", "chunk 1"},
+		{"missing output", []preflightTx{{"funding", fundingHex}, {"chunk 1", missingHex}}, "bad-txns-inputs-missingorspent", "chunk 1"},
+	}
+	for _, tc := range cases {
+		_, err := checkTxPolicy("mvc", tc.txs)
+		if tc.reason == "" {
+			if err != nil {
+				t.Errorf("%s: rejected: %v", tc.name, err)
+			}
+			continue
+		}
+		var rejected *TxRejectedError
+		if !errors.As(err, &rejected) || !errors.Is(err, ErrTxRejected) {
+			t.Errorf("%s: err = %v, want %s", tc.name, err, tc.reason)
+			continue
+		}
+		if rejected.Reason != tc.reason || rejected.Tx != tc.tx || rejected.Source != TxRejectedByLocalCheck {
+			t.Errorf("%s: rejected %s/%s/%s, want %s/%s/local", tc.name, rejected.Tx, rejected.Reason, rejected.Source, tc.tx, tc.reason)
+		}
+	}
+}
+
+func TestPreflightTaskTxs(t *testing.T) {
+	txs := preflightTaskTxs(" ", "f", []string{"c0", "c1"}, []string{"i1", "i2"})
+	var labels []string
+	for _, tx := range txs {
+		labels = append(labels, tx.label)
+	}
+	want := []string{"funding", "chunk 0", "chunk 1", "index 1", "index 2"}
+	if len(labels) != len(want) {
+		t.Fatalf("labels = %v, want %v", labels, want)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Fatalf("labels = %v, want %v", labels, want)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package upload_service
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	chaincfg2 "github.com/bitcoinsv/bsvd/chaincfg"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+	"gorm.io/gorm"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/node"
+	"meta-file-system/service/common_service/contenttype"
+)
+
+var (
+	// ErrUploadBatchDisabled uploader.batch is not enabled
+	ErrUploadBatchDisabled = errors.New("upload batching is disabled")
+	// ErrUploadBatchRejected the upload cannot share a transaction (size, pre-tx shape or funding)
+	ErrUploadBatchRejected = errors.New("upload not accepted into a batch")
+	// ErrUploadBatchNotFound no uploaded files for that transaction
+	ErrUploadBatchNotFound = errors.New("upload batch not found")
+)
+
+const (
+	// batchTxVersion version of batch transactions; pre-txs must match it, as it is signed
+	batchTxVersion = 10
+	// batchTxOverheadBytes version, locktime and input/output counts of a batch transaction
+	batchTxOverheadBytes = 4 + 4 + 3 + 3
+	// batchChangeOutputBytes size of a P2PKH change output
+	batchChangeOutputBytes = 8 + 1 + 25
+	// sigHashSingleAnyoneCanPay the signature flags a batched pre-tx input needs
+	sigHashSingleAnyoneCanPay = byte(txscript2.SigHashSingle | txscript2.SigHashAnyOneCanPay)
+)
+
+// batchEntry one direct upload waiting in a batch. Its pre-tx input is
+// signed SIGHASH_SINGLE|ANYONECANPAY, committing only to itself and the owner
+// output at the same index, so entries of different users can share a transaction.
+type batchEntry struct {
+	req          *DirectUploadRequest
+	input        *wire2.TxIn
+	ownerOut     *wire2.TxOut
+	inscription  []byte // MetaID OP_RETURN script
+	changeScript []byte // Change output script (nil = leftover goes to the fee)
+	fileId       string
+	fileHash     string
+	fileMd5      string
+	compressed   bool
+	savedFee     int64
+	result       chan batchResult
+}
+
+// batchResult outcome delivered to the request waiting on an entry
+type batchResult struct {
+	resp *UploadResponse
+	err  error
+}
+
+// pendingBatch entries of one fee rate collected during a window
+type pendingBatch struct {
+	feeRate  int64
+	entries  []*batchEntry
+	closesAt time.Time
+	timer    *time.Timer
+}
+
+// uploadBatcher groups batched direct uploads by fee rate. A batch is sent
+// when its window closes or it reaches max_files, whichever comes first.
+type uploadBatcher struct {
+	mu      sync.Mutex
+	pending map[int64]*pendingBatch
+	send    func(feeRate int64, entries []*batchEntry)
+}
+
+// add queues e into the open batch of feeRate, opening one if needed
+func (b *uploadBatcher) add(feeRate int64, e *batchEntry, cfg conf.UploadBatchConfig) {
+	b.mu.Lock()
+	batch := b.pending[feeRate]
+	if batch == nil {
+		window := time.Duration(cfg.WindowSeconds) * time.Second
+		batch = &pendingBatch{feeRate: feeRate, closesAt: time.Now().Add(window)}
+		opened := batch
+		batch.timer = time.AfterFunc(window, func() { b.close(feeRate, opened) })
+		b.pending[feeRate] = batch
+	}
+	batch.entries = append(batch.entries, e)
+	full := len(batch.entries) >= cfg.MaxFiles
+	if full {
+		batch.timer.Stop()
+		delete(b.pending, feeRate)
+	}
+	b.mu.Unlock()
+
+	if full {
+		go b.send(feeRate, batch.entries)
+	}
+}
+
+// close sends batch when its window ends, unless it was already sent full
+func (b *uploadBatcher) close(feeRate int64, batch *pendingBatch) {
+	b.mu.Lock()
+	if b.pending[feeRate] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.pending, feeRate)
+	b.mu.Unlock()
+
+	b.send(feeRate, batch.entries)
+}
+
+// snapshot returns the open batches, lowest fee rate first
+func (b *uploadBatcher) snapshot() []PendingUploadBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	batches := make([]PendingUploadBatch, 0, len(b.pending))
+	for _, batch := range b.pending {
+		batches = append(batches, PendingUploadBatch{
+			FeeRate:  batch.feeRate,
+			Files:    len(batch.entries),
+			ClosesAt: batch.closesAt,
+		})
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i].FeeRate < batches[j].FeeRate })
+	return batches
+}
+
+// getUploadBatcher returns the batcher of this uploader instance
+func (s *UploadService) getUploadBatcher() *uploadBatcher {
+	s.batcherOnce.Do(func() {
+		s.batcher = &uploadBatcher{
+			pending: make(map[int64]*pendingBatch),
+			send:    s.sendUploadBatch,
+		}
+	})
+	return s.batcher
+}
+
+// directUploadBatched queues a direct upload into the batch of its fee rate
+// and waits until the batch is broadcast. preTx must hold exactly one input,
+// signed SIGHASH_SINGLE|ANYONECANPAY, and its owner output; the uploader adds
+// the inscription and the change once the batch closes.
+func (s *UploadService) directUploadBatched(req *DirectUploadRequest, preTx *wire2.MsgTx, inscription []byte, payloadLen int, compressed bool, savedFee int64) (*UploadResponse, error) {
+	cfg := conf.Cfg.Uploader.Batch
+	if !cfg.Enabled {
+		return nil, ErrUploadBatchDisabled
+	}
+	if payloadLen > cfg.MaxFileBytes {
+		return nil, fmt.Errorf("%w: payload is %d bytes, batch limit %d", ErrUploadBatchRejected, payloadLen, cfg.MaxFileBytes)
+	}
+	if err := checkBatchPreTx(preTx); err != nil {
+		return nil, err
+	}
+	if req.TotalInputAmount <= 0 {
+		return nil, fmt.Errorf("%w: totalInputAmount is required", ErrUploadBatchRejected)
+	}
+
+	sha256hash := sha256.Sum256(req.Content)
+	md5hash := md5.Sum(req.Content)
+	entry := &batchEntry{
+		req:         req,
+		input:       preTx.TxIn[0],
+		ownerOut:    preTx.TxOut[0],
+		inscription: inscription,
+		fileHash:    hex.EncodeToString(sha256hash[:]),
+		fileMd5:     hex.EncodeToString(md5hash[:]),
+		compressed:  compressed,
+		savedFee:    savedFee,
+		result:      make(chan batchResult, 1),
+	}
+	entry.fileId = req.MetaId + "_" + entry.fileHash
+
+	// Same content already on chain: answer like a direct upload does
+	if existing, err := s.fileDAO.GetByFileID(entry.fileId); err == nil && existing.Status == model.StatusSuccess {
+		return &UploadResponse{FileId: existing.FileId, Status: string(existing.Status), TxId: existing.TxID, PinId: existing.PinId, Message: "success"}, nil
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check file record: %w", err)
+	}
+
+	if req.ChangeAddress != "" {
+		netParam := &chaincfg2.TestNet3Params
+		if conf.Cfg.Net == "mainnet" {
+			netParam = &chaincfg2.MainNetParams
+		}
+		changeScript, err := scripts.PayToMvcAddress(req.ChangeAddress, netParam)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create change script: %w", err)
+		}
+		entry.changeScript = changeScript
+	}
+
+	// The entry must pay its own way even alone (the split retry sends it so)
+	minChange := conf.GetUploaderChainPolicy("mvc").MinChange
+	if _, err := buildBatchTx([]*batchEntry{entry}, req.FeeRate, minChange); err != nil {
+		return nil, err
+	}
+
+	// The merge tx creates the pre-tx input; send it now so one failing
+	// merge cannot sink the whole batch
+	if req.MergeTxHex != "" {
+		if err := s.preflightTransactions("mvc", []preflightTx{{"merge", req.MergeTxHex}}); err != nil {
+			return nil, err
+		}
+		if _, err := node.BroadcastTxResilient(conf.Cfg.Net, req.MergeTxHex); err != nil && !isDuplicateBroadcastError(err) {
+			return nil, fmt.Errorf("failed to broadcast merge transaction: %w", err)
+		}
+	}
+
+	s.getUploadBatcher().add(req.FeeRate, entry, cfg)
+	select {
+	case result := <-entry.result:
+		return result.resp, result.err
+	case <-time.After(time.Duration(cfg.WindowSeconds)*time.Second + 2*time.Minute):
+		return nil, fmt.Errorf("batched upload of %s timed out waiting for its batch", entry.fileId)
+	}
+}
+
+// checkBatchPreTx checks a pre-tx can be merged into a batch transaction
+func checkBatchPreTx(tx *wire2.MsgTx) error {
+	if len(tx.TxIn) != 1 || len(tx.TxOut) != 1 {
+		return fmt.Errorf("%w: pre-tx must have exactly one input and one output, has %d and %d", ErrUploadBatchRejected, len(tx.TxIn), len(tx.TxOut))
+	}
+	if tx.Version != batchTxVersion || tx.LockTime != 0 {
+		return fmt.Errorf("%w: pre-tx must be version %d with locktime 0", ErrUploadBatchRejected, batchTxVersion)
+	}
+	pushes, err := txscript2.PushedData(tx.TxIn[0].SignatureScript)
+	if err != nil || len(pushes) == 0 || len(pushes[0]) == 0 {
+		return fmt.Errorf("%w: pre-tx input is not signed", ErrUploadBatchRejected)
+	}
+	sig := pushes[0]
+	if sig[len(sig)-1]&sigHashSingleAnyoneCanPay != sigHashSingleAnyoneCanPay {
+		return fmt.Errorf("%w: pre-tx input must be signed SIGHASH_SINGLE|ANYONECANPAY", ErrUploadBatchRejected)
+	}
+	return nil
+}
+
+// buildBatchTx assembles entries into one transaction: inputs in entry order,
+// then the owner outputs at the same indexes (as their SIGHASH_SINGLE
+// signatures require), then one inscription per entry and the change outputs.
+// Each entry pays for its own input and outputs plus an equal share of the
+// transaction overhead at feeRate; what it has left above minChange comes
+// back as change.
+func buildBatchTx(entries []*batchEntry, feeRate, minChange int64) (*wire2.MsgTx, error) {
+	tx := wire2.NewMsgTx(batchTxVersion)
+	for _, e := range entries {
+		tx.AddTxIn(e.input)
+	}
+	for _, e := range entries {
+		tx.AddTxOut(e.ownerOut)
+	}
+	for _, e := range entries {
+		tx.AddTxOut(wire2.NewTxOut(0, e.inscription))
+	}
+
+	overhead := (batchTxOverheadBytes + len(entries) - 1) / len(entries)
+	for _, e := range entries {
+		size := e.input.SerializeSize() + e.ownerOut.SerializeSize() + wire2.NewTxOut(0, e.inscription).SerializeSize() + overhead
+		left := e.req.TotalInputAmount - e.ownerOut.Value - int64(size)*feeRate
+		if left < 0 {
+			return nil, fmt.Errorf("%w: %s is %d satoshis short of its fee", ErrUploadBatchRejected, e.fileId, -left)
+		}
+		change := left - batchChangeOutputBytes*feeRate
+		if e.changeScript != nil && change >= minChange {
+			tx.AddTxOut(wire2.NewTxOut(change, e.changeScript))
+		}
+	}
+	return tx, nil
+}
+
+// sendUploadBatch broadcasts the entries of a closed batch as one transaction.
+// If that fails and the batch holds several uploads, each is retried alone,
+// so one bad input does not fail everybody else's upload.
+func (s *UploadService) sendUploadBatch(feeRate int64, entries []*batchEntry) {
+	err := s.broadcastUploadBatch(feeRate, entries)
+	if err == nil {
+		return
+	}
+	if len(entries) == 1 {
+		entries[0].result <- batchResult{err: err}
+		return
+	}
+	log.Printf("Upload batch of %d at fee rate %d failed, sending each alone: %v", len(entries), feeRate, err)
+	for _, e := range entries {
+		if err := s.broadcastUploadBatch(feeRate, []*batchEntry{e}); err != nil {
+			e.result <- batchResult{err: err}
+		}
+	}
+}
+
+// broadcastUploadBatch builds and broadcasts the batch transaction of
+// entries, records their files and answers their waiting requests
+func (s *UploadService) broadcastUploadBatch(feeRate int64, entries []*batchEntry) error {
+	tx, err := buildBatchTx(entries, feeRate, conf.GetUploaderChainPolicy("mvc").MinChange)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return fmt.Errorf("failed to serialize batch transaction: %w", err)
+	}
+	rawTx := hex.EncodeToString(buf.Bytes())
+	txId := common.GetMvcTxhashFromRaw(rawTx)
+
+	if err := s.preflightTransactions("mvc", []preflightTx{{"batch", rawTx}}); err != nil {
+		return err
+	}
+	if _, err := node.BroadcastTxResilient(conf.Cfg.Net, rawTx); err != nil {
+		return fmt.Errorf("failed to broadcast batch transaction: %w", err)
+	}
+	log.Printf("Upload batch broadcast: txId=%s, files=%d, feeRate=%d, size=%d", txId, len(entries), feeRate, tx.SerializeSize())
+
+	for i, e := range entries {
+		// The k-th inscription belongs to the k-th owner output, as i0 does for a single direct upload
+		pinId := fmt.Sprintf("%si%d", txId, i)
+		if err := s.saveBatchedFile(e, txId, pinId); err != nil {
+			log.Printf("Failed to record batched file %s (tx %s already broadcast): %v", e.fileId, txId, err)
+		}
+		e.result <- batchResult{resp: &UploadResponse{
+			FileId:         e.fileId,
+			Status:         string(model.StatusSuccess),
+			TxId:           txId,
+			PinId:          pinId,
+			Message:        "success",
+			GzipCompressed: e.compressed,
+			SavedFee:       e.savedFee,
+			BatchSize:      len(entries),
+		}}
+	}
+	return nil
+}
+
+// saveBatchedFile records the file of a broadcast batch entry, reusing a
+// pending or failed record of the same file
+func (s *UploadService) saveBatchedFile(e *batchEntry, txId, pinId string) error {
+	req := e.req
+	file, err := s.fileDAO.GetByFileID(e.fileId)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		file = &model.File{FileId: e.fileId}
+	}
+	file.FileName = req.FileName
+	file.FileType = contenttype.Normalize(req.ContentType)
+	file.MetaId = req.MetaId
+	file.Address = req.Address
+	file.Path = req.Path
+	file.ContentType = req.ContentType
+	file.FileSize = int64(len(req.Content))
+	file.FileHash = e.fileHash
+	file.FileMd5 = e.fileMd5
+	file.FileContentType = contenttype.Normalize(req.ContentType)
+	file.ChunkType = model.ChunkTypeSingle
+	file.IsGzipCompressed = e.compressed
+	file.StorageClass = model.StorageClass(req.StorageClass)
+	file.Operation = req.Operation
+	file.TxID = txId
+	file.PinId = pinId
+	file.Status = model.StatusSuccess
+	if file.ID == 0 {
+		return s.fileDAO.Create(file)
+	}
+	return s.fileDAO.Update(file)
+}
+
+// PendingUploadBatch an open batch collecting uploads
+type PendingUploadBatch struct {
+	FeeRate  int64     `json:"feeRate"`  // Fee rate shared by the batch (sat/byte)
+	Files    int       `json:"files"`    // Uploads collected so far
+	ClosesAt time.Time `json:"closesAt"` // When the batch is broadcast unless it fills up first
+}
+
+// UploadBatchStatusResponse batching configuration and open batches
+type UploadBatchStatusResponse struct {
+	Enabled       bool                 `json:"enabled"`       // uploader.batch.enabled
+	WindowSeconds int                  `json:"windowSeconds"` // Collect time after a batch's first upload
+	MaxFiles      int                  `json:"maxFiles"`      // Uploads per batch
+	MaxFileBytes  int                  `json:"maxFileBytes"`  // Largest payload accepted into a batch
+	Pending       []PendingUploadBatch `json:"pending"`       // Open batches of this uploader instance
+}
+
+// UploadBatchStatus returns the batching configuration and the open batches
+func (s *UploadService) UploadBatchStatus() *UploadBatchStatusResponse {
+	cfg := conf.Cfg.Uploader.Batch
+	resp := &UploadBatchStatusResponse{
+		Enabled:       cfg.Enabled,
+		WindowSeconds: cfg.WindowSeconds,
+		MaxFiles:      cfg.MaxFiles,
+		MaxFileBytes:  cfg.MaxFileBytes,
+		Pending:       []PendingUploadBatch{},
+	}
+	if cfg.Enabled {
+		resp.Pending = s.getUploadBatcher().snapshot()
+	}
+	return resp
+}
+
+// UploadBatchFile one file inscribed by a batch transaction
+type UploadBatchFile struct {
+	FileId   string `json:"fileId"`   // File ID
+	FileName string `json:"fileName"` // File name
+	Address  string `json:"address"`  // Uploader address
+	PinId    string `json:"pinId"`    // PinID of the file's inscription
+}
+
+// UploadBatchResponse the files of one batch transaction
+type UploadBatchResponse struct {
+	TxId  string            `json:"txId"`  // Batch transaction ID
+	Files []UploadBatchFile `json:"files"` // Files in inscription order
+}
+
+// GetUploadBatch lists the files a batch transaction inscribed with their PinIDs
+func (s *UploadService) GetUploadBatch(txId string) (*UploadBatchResponse, error) {
+	files, err := s.fileDAO.ListByTxID(txId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list batch files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, ErrUploadBatchNotFound
+	}
+	resp := &UploadBatchResponse{TxId: txId, Files: make([]UploadBatchFile, 0, len(files))}
+	for _, f := range files {
+		resp.Files = append(resp.Files, UploadBatchFile{FileId: f.FileId, FileName: f.FileName, Address: f.Address, PinId: f.PinId})
+	}
+	return resp, nil
+}
@@ -0,0 +1,147 @@
+package upload_service
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	txscript2 "github.com/bitcoinsv/bsvd/txscript"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-file-system/conf"
+)
+
+// testBatchPreTx builds a one-input, one-output pre-tx whose signature ends in sigHash
+func testBatchPreTx(t *testing.T, seed byte, sigHash byte, ownerValue int64) *wire2.MsgTx {
+	t.Helper()
+	sig := append(bytes.Repeat([]byte{0x30}, 71), sigHash)
+	sigScript, err := txscript2.NewScriptBuilder().AddData(sig).AddData(bytes.Repeat([]byte{0x02}, 33)).Script()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := wire2.NewMsgTx(batchTxVersion)
+	tx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(&chainhash.Hash{seed}, 0), sigScript))
+	tx.AddTxOut(wire2.NewTxOut(ownerValue, bytes.Repeat([]byte{seed}, 25)))
+	return tx
+}
+
+func testBatchEntry(t *testing.T, seed byte, totalInput int64, change bool) *batchEntry {
+	preTx := testBatchPreTx(t, seed, 0xc3, 1)
+	e := &batchEntry{
+		req:         &DirectUploadRequest{TotalInputAmount: totalInput},
+		input:       preTx.TxIn[0],
+		ownerOut:    preTx.TxOut[0],
+		inscription: append([]byte{0x00, 0x6a}, bytes.Repeat([]byte{seed}, 100)...),
+		fileId:      string(rune('a' + seed)),
+	}
+	if change {
+		e.changeScript = bytes.Repeat([]byte{0xcc}, 25)
+	}
+	return e
+}
+
+func TestBuildBatchTx(t *testing.T) {
+	entries := []*batchEntry{
+		testBatchEntry(t, 1, 100000, true),
+		testBatchEntry(t, 2, 100000, false),
+		testBatchEntry(t, 3, 800, true), // too little left for change
+	}
+	tx, err := buildBatchTx(entries, 1, 600)
+	if err != nil {
+		t.Fatalf("buildBatchTx: %v", err)
+	}
+	if len(tx.TxIn) != 3 || len(tx.TxOut) != 7 {
+		t.Fatalf("tx has %d inputs and %d outputs, want 3 and 7", len(tx.TxIn), len(tx.TxOut))
+	}
+	for i, e := range entries {
+		if tx.TxIn[i] != e.input || tx.TxOut[i] != e.ownerOut {
+			t.Errorf("entry %d: input/owner output not at index %d", i, i)
+		}
+		if !bytes.Equal(tx.TxOut[3+i].PkScript, e.inscription) {
+			t.Errorf("entry %d: inscription not at index %d", i, 3+i)
+		}
+	}
+	if change := tx.TxOut[6]; !bytes.Equal(change.PkScript, entries[0].changeScript) || change.Value <= 0 {
+		t.Errorf("change output = %+v", change)
+	}
+
+	var in, out int64
+	for _, e := range entries {
+		in += e.req.TotalInputAmount
+	}
+	for _, o := range tx.TxOut {
+		out += o.Value
+	}
+	if fee := in - out; fee < int64(tx.SerializeSize()) {
+		t.Errorf("fee %d does not cover %d bytes at 1 sat/byte", fee, tx.SerializeSize())
+	}
+
+	if _, err := buildBatchTx([]*batchEntry{testBatchEntry(t, 4, 50, true)}, 1, 600); !errors.Is(err, ErrUploadBatchRejected) {
+		t.Errorf("underfunded entry: err = %v, want ErrUploadBatchRejected", err)
+	}
+}
+
+func TestCheckBatchPreTx(t *testing.T) {
+	if err := checkBatchPreTx(testBatchPreTx(t, 1, 0xc3, 1)); err != nil {
+		t.Errorf("SIGHASH_SINGLE|ANYONECANPAY pre-tx rejected: %v", err)
+	}
+
+	sigHashAll := testBatchPreTx(t, 1, 0x41, 1)
+	twoOutputs := testBatchPreTx(t, 1, 0xc3, 1)
+	twoOutputs.AddTxOut(wire2.NewTxOut(1, []byte{0x51}))
+	otherVersion := testBatchPreTx(t, 1, 0xc3, 1)
+	otherVersion.Version = 1
+	unsigned := testBatchPreTx(t, 1, 0xc3, 1)
+	unsigned.TxIn[0].SignatureScript = nil
+
+	for name, tx := range map[string]*wire2.MsgTx{
+		"sighash all": sigHashAll, "two outputs": twoOutputs, "version": otherVersion, "unsigned": unsigned,
+	} {
+		if err := checkBatchPreTx(tx); !errors.Is(err, ErrUploadBatchRejected) {
+			t.Errorf("%s: err = %v, want ErrUploadBatchRejected", name, err)
+		}
+	}
+}
+
+func TestUploadBatcher(t *testing.T) {
+	var mu sync.Mutex
+	sent := map[int64][]int{}
+	done := make(chan struct{}, 4)
+	b := &uploadBatcher{
+		pending: make(map[int64]*pendingBatch),
+		send: func(feeRate int64, entries []*batchEntry) {
+			mu.Lock()
+			sent[feeRate] = append(sent[feeRate], len(entries))
+			mu.Unlock()
+			done <- struct{}{}
+		},
+	}
+	cfg := conf.UploadBatchConfig{WindowSeconds: 1, MaxFiles: 2}
+
+	// Fee rate 5 fills up and goes at once; fee rate 8 waits for its window
+	b.add(5, &batchEntry{}, cfg)
+	b.add(8, &batchEntry{}, cfg)
+	if pending := b.snapshot(); len(pending) != 2 || pending[0].FeeRate != 5 || pending[1].Files != 1 {
+		t.Fatalf("snapshot = %+v", pending)
+	}
+	b.add(5, &batchEntry{}, cfg)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("batch was not sent")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent[5]) != 1 || sent[5][0] != 2 || len(sent[8]) != 1 || sent[8][0] != 1 {
+		t.Errorf("sent batches = %v", sent)
+	}
+	if pending := b.snapshot(); len(pending) != 0 {
+		t.Errorf("batches still open: %+v", pending)
+	}
+}
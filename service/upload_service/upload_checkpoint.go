@@ -0,0 +1,288 @@
+package upload_service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/node"
+)
+
+// Defaults of RecoverInFlightUploads
+const (
+	DefaultRecoverStalledAfter = 2 * time.Minute // Checkpoints untouched this long are no longer being broadcast
+	DefaultRecoverLimit        = 100
+	MaxRecoverLimit            = 1000
+)
+
+// indexBroadcastDelay gives nodes time to accept the chunk transactions
+// before the index that references them is broadcast
+var indexBroadcastDelay = 5 * time.Second
+
+// RecoveredUpload outcome of one resumed synchronous upload
+type RecoveredUpload struct {
+	FileId    string `json:"fileId"`              // File ID
+	FromStage string `json:"fromStage"`           // Stage the upload was resumed from
+	Status    string `json:"status"`              // success, failed, or pending when its progress could not be saved
+	IndexTxId string `json:"indexTxId,omitempty"` // Index transaction ID (success)
+	Error     string `json:"error,omitempty"`     // Broadcast error (failed)
+}
+
+// RecoverUploadsResponse result of a recovery run
+type RecoverUploadsResponse struct {
+	Uploads   []RecoveredUpload `json:"uploads"`   // Uploads picked up, oldest first
+	Succeeded int               `json:"succeeded"` // Uploads whose index transaction is now broadcast
+	Failed    int               `json:"failed"`    // Uploads marked failed
+}
+
+// newUploadCheckpoint builds the checkpoint of a synchronous chunked upload
+// from its built transactions, before anything is broadcast
+func newUploadCheckpoint(fileId, fileHash, mergeTxHex, chunkFundingTxHex string, chunkTxs, chunkTxIds []string, indexTxHex, indexTxId string) (*model.UploadCheckpoint, error) {
+	chunkTxHexesJSON, err := json.Marshal(chunkTxs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chunk transactions: %w", err)
+	}
+	chunkTxIdsJSON, err := json.Marshal(chunkTxIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chunk transaction IDs: %w", err)
+	}
+	return &model.UploadCheckpoint{
+		FileId:         fileId,
+		FileHash:       fileHash,
+		Chain:          "mvc",
+		Stage:          model.TaskStagePrepared,
+		Status:         model.StatusPending,
+		TotalChunks:    len(chunkTxs),
+		MergeTxHex:     mergeTxHex,
+		ChunkFundingTx: chunkFundingTxHex,
+		ChunkTxHexes:   string(chunkTxHexesJSON),
+		ChunkTxIds:     string(chunkTxIdsJSON),
+		IndexTxHex:     indexTxHex,
+		IndexTxId:      indexTxId,
+	}, nil
+}
+
+// errCheckpointNotSaved marks a checkpoint run stopped because its progress
+// could not be saved; the upload stays pending for recovery
+var errCheckpointNotSaved = errors.New("failed to save upload checkpoint")
+
+// runUploadCheckpoint broadcasts the transactions of cp still due, in order,
+// calling save after each step so a restart resumes after the last one done.
+// broadcast must treat an already-known transaction as success; onChunk
+// (optional) reports chunk progress.
+func runUploadCheckpoint(cp *model.UploadCheckpoint, broadcast func(txHex string) error, save func(*model.UploadCheckpoint) error, onChunk func(done, total int)) error {
+	if cp.Stage == "" || cp.Stage == model.TaskStageCreated {
+		cp.Stage = model.TaskStagePrepared
+	}
+
+	if cp.Stage == model.TaskStagePrepared {
+		if cp.MergeTxHex != "" {
+			if err := broadcast(cp.MergeTxHex); err != nil {
+				return fmt.Errorf("failed to broadcast merge transaction: %w", err)
+			}
+		}
+		cp.Stage = model.TaskStageMergeBroadcast
+		if err := save(cp); err != nil {
+			return fmt.Errorf("%w: %v", errCheckpointNotSaved, err)
+		}
+	}
+
+	if cp.Stage == model.TaskStageMergeBroadcast {
+		if cp.ChunkFundingTx == "" {
+			return fmt.Errorf("chunk funding transaction missing")
+		}
+		if err := broadcast(cp.ChunkFundingTx); err != nil {
+			return fmt.Errorf("failed to broadcast chunk funding transaction: %w", err)
+		}
+		cp.Stage = model.TaskStageFundingBroadcast
+		if err := save(cp); err != nil {
+			return fmt.Errorf("%w: %v", errCheckpointNotSaved, err)
+		}
+	}
+
+	if cp.Stage == model.TaskStageFundingBroadcast {
+		chunkTxHexes, err := decodeStringArray(cp.ChunkTxHexes)
+		if err != nil {
+			return fmt.Errorf("failed to parse chunk transactions: %w", err)
+		}
+		for i := cp.ProcessedChunks; i < len(chunkTxHexes); i++ {
+			if err := broadcast(chunkTxHexes[i]); err != nil {
+				return fmt.Errorf("failed to broadcast chunk transaction %d: %w", i, err)
+			}
+			cp.ProcessedChunks = i + 1
+			if err := save(cp); err != nil {
+				return fmt.Errorf("%w: %v", errCheckpointNotSaved, err)
+			}
+			if onChunk != nil {
+				onChunk(i+1, len(chunkTxHexes))
+			}
+		}
+		cp.Stage = model.TaskStageChunkBroadcast
+		if err := save(cp); err != nil {
+			return fmt.Errorf("%w: %v", errCheckpointNotSaved, err)
+		}
+		time.Sleep(indexBroadcastDelay)
+	}
+
+	if cp.Stage == model.TaskStageChunkBroadcast {
+		if cp.IndexTxHex == "" {
+			return fmt.Errorf("index transaction missing")
+		}
+		if err := broadcast(cp.IndexTxHex); err != nil {
+			return fmt.Errorf("failed to broadcast index transaction: %w", err)
+		}
+		cp.Stage = model.TaskStageIndexBroadcast
+		if err := save(cp); err != nil {
+			return fmt.Errorf("%w: %v", errCheckpointNotSaved, err)
+		}
+	}
+	return nil
+}
+
+// broadcastUploadCheckpoint saves cp, broadcasts what it still needs and
+// settles the file, its chunks and the checkpoint on success or failure.
+func (s *UploadService) broadcastUploadCheckpoint(cp *model.UploadCheckpoint, task *model.FileUploaderTask) error {
+	if cp.ID == 0 {
+		if err := s.uploadCheckpointDAO.Save(cp); err != nil {
+			return fmt.Errorf("failed to save upload checkpoint: %w", err)
+		}
+	}
+	if cp.Stage == model.TaskStagePrepared {
+		chunkTxHexes, _ := decodeStringArray(cp.ChunkTxHexes)
+		txs := preflightTaskTxs(cp.MergeTxHex, cp.ChunkFundingTx, chunkTxHexes, []string{cp.IndexTxHex})
+		if err := s.preflightTransactions("mvc", txs); err != nil {
+			return s.settleUploadCheckpoint(cp, err)
+		}
+	}
+
+	// The assistent ledger is keyed by the uploader address of the file
+	address := ""
+	if file, err := s.fileDAO.GetByFileID(cp.FileId); err == nil && file != nil {
+		address = file.Address
+	}
+
+	chain := conf.Cfg.Net
+	broadcast := func(txHex string) error {
+		if _, err := node.BroadcastTxResilient(chain, txHex); err != nil && !isDuplicateBroadcastError(err) {
+			return err
+		}
+		if address != "" {
+			s.recordAssistentTx("mvc", address, txHex)
+		}
+		if txHex == cp.ChunkFundingTx {
+			s.recordHotWalletTx(txHex)
+		}
+		return nil
+	}
+	onChunk := func(done, total int) {
+		s.updateUploadTaskProgress(task,
+			fmt.Sprintf("Broadcasting chunk transactions (%d/%d)", done, total),
+			calcProgressRange(85, 95, done, total),
+			total)
+	}
+
+	return s.settleUploadCheckpoint(cp, runUploadCheckpoint(cp, broadcast, s.uploadCheckpointDAO.Update, onChunk))
+}
+
+// settleUploadCheckpoint records the outcome of a checkpoint run: success once
+// the index is broadcast, failed on a broadcast error. A run stopped because
+// its progress could not be saved stays pending for recovery. Returns the
+// error the upload failed with, if any.
+func (s *UploadService) settleUploadCheckpoint(cp *model.UploadCheckpoint, runErr error) error {
+	fileStatus := model.StatusSuccess
+	fileUpdates := map[string]interface{}{
+		"status": model.StatusSuccess,
+		"tx_id":  cp.IndexTxId,
+		"pin_id": fmt.Sprintf("%si0", cp.IndexTxId),
+	}
+	switch {
+	case cp.Stage == model.TaskStageIndexBroadcast:
+		// Only the bookkeeping after the index broadcast may have failed
+		runErr = nil
+	case errors.Is(runErr, errCheckpointNotSaved):
+		cp.ErrorMessage = runErr.Error()
+		return runErr
+	case runErr != nil:
+		fileStatus = model.StatusFailed
+		fileUpdates = map[string]interface{}{"status": model.StatusFailed}
+		cp.ErrorMessage = runErr.Error()
+	}
+
+	if err := database.UploaderDB.Model(&model.File{}).Where("file_id = ?", cp.FileId).Updates(fileUpdates).Error; err != nil {
+		log.Printf("Failed to update file status: FileId=%s, %v", cp.FileId, err)
+	}
+	chunkQuery := database.UploaderDB.Model(&model.FileChunk{})
+	if fileStatus == model.StatusSuccess {
+		chunkTxIds, _ := decodeStringArray(cp.ChunkTxIds)
+		pinIDs := make([]string, 0, len(chunkTxIds))
+		for _, txID := range chunkTxIds {
+			pinIDs = append(pinIDs, fmt.Sprintf("%si0", txID))
+		}
+		chunkQuery = chunkQuery.Where("pin_id IN ?", pinIDs)
+	} else {
+		chunkQuery = chunkQuery.Where("file_hash = ?", cp.FileHash)
+	}
+	if err := chunkQuery.Update("status", fileStatus).Error; err != nil {
+		log.Printf("Failed to update chunk status: FileId=%s, %v", cp.FileId, err)
+	}
+
+	if fileStatus == model.StatusFailed && cp.Stage != model.TaskStageFundingBroadcast && cp.Stage != model.TaskStageChunkBroadcast {
+		s.releaseHotWalletFunding(cp.ChunkFundingTx)
+	}
+
+	finishedAt := time.Now()
+	cp.Status = fileStatus
+	cp.FinishedAt = &finishedAt
+	cp.MergeTxHex = ""
+	cp.ChunkFundingTx = ""
+	cp.ChunkTxHexes = ""
+	cp.IndexTxHex = ""
+	if err := s.uploadCheckpointDAO.Update(cp); err != nil {
+		log.Printf("Failed to update upload checkpoint: FileId=%s, %v", cp.FileId, err)
+	}
+	return runErr
+}
+
+// RecoverInFlightUploads resumes synchronous chunked uploads whose broadcast
+// was interrupted (e.g. by a restart): pending checkpoints not updated for
+// stalledAfter are broadcast from where they stopped, and their file ends up
+// success or failed. Already-broadcast transactions count as done.
+func (s *UploadService) RecoverInFlightUploads(stalledAfter time.Duration, limit int) (*RecoverUploadsResponse, error) {
+	if stalledAfter < 0 {
+		stalledAfter = DefaultRecoverStalledAfter
+	}
+	if limit <= 0 || limit > MaxRecoverLimit {
+		limit = DefaultRecoverLimit
+	}
+
+	cps, err := s.uploadCheckpointDAO.GetStalledPending(time.Now().Add(-stalledAfter), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list in-flight uploads: %w", err)
+	}
+
+	resp := &RecoverUploadsResponse{Uploads: make([]RecoveredUpload, 0, len(cps))}
+	for _, cp := range cps {
+		result := RecoveredUpload{FileId: cp.FileId, FromStage: string(cp.Stage)}
+		cp.Attempts++
+		log.Printf("Recovering in-flight upload: FileId=%s, stage=%s, chunks=%d/%d", cp.FileId, cp.Stage, cp.ProcessedChunks, cp.TotalChunks)
+
+		if err := s.broadcastUploadCheckpoint(cp, nil); err != nil {
+			result.Error = err.Error()
+		}
+		result.Status = string(cp.Status)
+		switch cp.Status {
+		case model.StatusSuccess:
+			result.IndexTxId = cp.IndexTxId
+			resp.Succeeded++
+		case model.StatusFailed:
+			resp.Failed++
+		}
+		resp.Uploads = append(resp.Uploads, result)
+	}
+	return resp, nil
+}
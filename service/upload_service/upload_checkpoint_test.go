@@ -0,0 +1,102 @@
+package upload_service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"meta-file-system/model"
+)
+
+func newTestCheckpoint(t *testing.T) *model.UploadCheckpoint {
+	t.Helper()
+	cp, err := newUploadCheckpoint("file1", "hash1", "merge", "funding", []string{"chunk0", "chunk1"}, []string{"c0", "c1"}, "index", "idx")
+	if err != nil {
+		t.Fatalf("newUploadCheckpoint: %v", err)
+	}
+	return cp
+}
+
+func TestRunUploadCheckpoint_BroadcastsInOrder(t *testing.T) {
+	indexBroadcastDelay = 0
+	cp := newTestCheckpoint(t)
+
+	var sent []string
+	saves := 0
+	err := runUploadCheckpoint(cp, func(tx string) error { sent = append(sent, tx); return nil },
+		func(*model.UploadCheckpoint) error { saves++; return nil }, nil)
+	if err != nil {
+		t.Fatalf("runUploadCheckpoint: %v", err)
+	}
+	if want := []string{"merge", "funding", "chunk0", "chunk1", "index"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("broadcast %v, want %v", sent, want)
+	}
+	if cp.Stage != model.TaskStageIndexBroadcast || cp.ProcessedChunks != 2 {
+		t.Errorf("stage %s, chunks %d", cp.Stage, cp.ProcessedChunks)
+	}
+	if saves != 6 {
+		t.Errorf("saved %d times, want 6", saves)
+	}
+}
+
+func TestRunUploadCheckpoint_ResumesAfterLastStep(t *testing.T) {
+	indexBroadcastDelay = 0
+	cp := newTestCheckpoint(t)
+	cp.Stage = model.TaskStageFundingBroadcast
+	cp.ProcessedChunks = 1
+
+	var sent []string
+	err := runUploadCheckpoint(cp, func(tx string) error { sent = append(sent, tx); return nil },
+		func(*model.UploadCheckpoint) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("runUploadCheckpoint: %v", err)
+	}
+	if want := []string{"chunk1", "index"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("broadcast %v, want %v", sent, want)
+	}
+}
+
+func TestRunUploadCheckpoint_StopsOnBroadcastError(t *testing.T) {
+	indexBroadcastDelay = 0
+	cp := newTestCheckpoint(t)
+
+	err := runUploadCheckpoint(cp, func(tx string) error {
+		if tx == "chunk1" {
+			return errors.New("rejected")
+		}
+		return nil
+	}, func(*model.UploadCheckpoint) error { return nil }, nil)
+	if err == nil || !strings.Contains(err.Error(), "chunk transaction 1") {
+		t.Fatalf("err = %v, want chunk 1 broadcast error", err)
+	}
+	if errors.Is(err, errCheckpointNotSaved) {
+		t.Errorf("broadcast error reported as not saved")
+	}
+	if cp.Stage != model.TaskStageFundingBroadcast || cp.ProcessedChunks != 1 {
+		t.Errorf("stage %s, chunks %d; want funding_broadcast, 1", cp.Stage, cp.ProcessedChunks)
+	}
+}
+
+func TestRunUploadCheckpoint_SaveError(t *testing.T) {
+	indexBroadcastDelay = 0
+	cp := newTestCheckpoint(t)
+
+	err := runUploadCheckpoint(cp, func(string) error { return nil },
+		func(*model.UploadCheckpoint) error { return errors.New("db down") }, nil)
+	if !errors.Is(err, errCheckpointNotSaved) {
+		t.Fatalf("err = %v, want errCheckpointNotSaved", err)
+	}
+}
+
+func TestRunUploadCheckpoint_MissingFundingTx(t *testing.T) {
+	indexBroadcastDelay = 0
+	cp := newTestCheckpoint(t)
+	cp.ChunkFundingTx = ""
+
+	err := runUploadCheckpoint(cp, func(string) error { return nil },
+		func(*model.UploadCheckpoint) error { return nil }, nil)
+	if err == nil {
+		t.Fatal("expected error for missing chunk funding transaction")
+	}
+}
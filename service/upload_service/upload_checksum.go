@@ -0,0 +1,56 @@
+package upload_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned (as a *ChecksumMismatchError) when the
+// content received does not hash to the SHA256 the client declared
+var ErrChecksumMismatch = errors.New("content checksum mismatch")
+
+// ErrInvalidChecksum a declared SHA256 that is not 64 hex digits
+var ErrInvalidChecksum = errors.New("invalid sha256")
+
+// ChecksumMismatchError content whose SHA256 differs from the declared one,
+// typically a truncated or corrupted upload
+type ChecksumMismatchError struct {
+	Declared string // SHA256 declared by the client (lowercase hex)
+	Actual   string // SHA256 of the content received
+	Size     int64  // Bytes received
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s: declared sha256 %s, received %d bytes with sha256 %s; the content was truncated or corrupted, send it again",
+		ErrChecksumMismatch, e.Declared, e.Size, e.Actual)
+}
+
+// Is makes errors.Is(err, ErrChecksumMismatch) match
+func (e *ChecksumMismatchError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
+
+// verifyContentSHA256 checks content against the SHA256 the client declared
+// (hex, any case) before anything is built from it; an empty declaration is
+// not checked
+func verifyContentSHA256(declared string, content []byte) error {
+	declared = strings.ToLower(strings.TrimSpace(declared))
+	if declared == "" {
+		return nil
+	}
+	if len(declared) != sha256.Size*2 {
+		return fmt.Errorf("%w: want %d hex digits, got %d", ErrInvalidChecksum, sha256.Size*2, len(declared))
+	}
+	if _, err := hex.DecodeString(declared); err != nil {
+		return fmt.Errorf("%w: not hex", ErrInvalidChecksum)
+	}
+
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != declared {
+		return &ChecksumMismatchError{Declared: declared, Actual: actual, Size: int64(len(content))}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package upload_service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVerifyContentSHA256(t *testing.T) {
+	content := []byte("hello")
+	const sum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	for _, declared := range []string{"", sum, strings.ToUpper(sum), " " + sum + "\n"} {
+		if err := verifyContentSHA256(declared, content); err != nil {
+			t.Errorf("verifyContentSHA256(%q): %v", declared, err)
+		}
+	}
+
+	err := verifyContentSHA256(sum, content[:4])
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("truncated content: err = %v, want a *ChecksumMismatchError", err)
+	}
+	if mismatch.Declared != sum || mismatch.Size != 4 || mismatch.Actual == sum {
+		t.Errorf("mismatch = %+v", mismatch)
+	}
+
+	for _, declared := range []string{"abc", strings.Repeat("zz", 32), sum + "00"} {
+		if err := verifyContentSHA256(declared, content); !errors.Is(err, ErrInvalidChecksum) {
+			t.Errorf("verifyContentSHA256(%q) = %v, want ErrInvalidChecksum", declared, err)
+		}
+	}
+}
+
+func TestChunkedUpload_RejectsChecksumMismatchFirst(t *testing.T) {
+	setPayloadLimitConfig(t, 0, 0)
+	s := &UploadService{}
+	// No pre-transactions: the checksum is checked before anything else
+	_, err := s.ChunkedUpload(&ChunkedUploadRequest{
+		Address:       "addr",
+		Content:       []byte("hello, truncat"),
+		Path:          "/file",
+		ContentSHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+	})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("ChunkedUpload err = %v, want ErrChecksumMismatch", err)
+	}
+}
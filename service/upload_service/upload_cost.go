@@ -0,0 +1,433 @@
+package upload_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-file-system/common"
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// Upload modes compared by EstimateUploadCost
+const (
+	UploadModeDirect  = "direct"
+	UploadModeChunked = "chunked"
+)
+
+const (
+	// dogeMaxInscriptionScriptSize mirrors the script limit enforced by common.EstimateDogeInscriptionFee
+	dogeMaxInscriptionScriptSize = 500 * 1024
+	// placeholderPinIDLen length of a real PinID (64 hex txid + "i0") used to size index payloads
+	placeholderPinIDLen = 66
+	// maxBreakEvenSteps bounds the chunk-size steps scanned when looking for a break-even size
+	maxBreakEvenSteps = 1000
+)
+
+// UploadCostRequest describes a size-based upload cost query (no file content needed)
+type UploadCostRequest struct {
+	FileSize    int64  // File size in bytes
+	FileName    string // File name (optional, included in the chunked index)
+	Path        string // Base MetaID path (default /file)
+	ContentType string // MIME type (default application/octet-stream)
+	Chain       string // Blockchain (optional, empty = every configured uploader chain)
+	FeeRate     int64  // Fee rate override (optional, defaults to chain config)
+}
+
+// DirectUploadCost estimated cost of a single-transaction OP_RETURN upload
+type DirectUploadCost struct {
+	Supported bool   `json:"supported"`        // Whether direct upload is possible for this file on this chain
+	Reason    string `json:"reason,omitempty"` // Why direct upload is not possible
+	FeeRate   int64  `json:"feeRate"`          // Fee rate used
+	TxSize    int    `json:"txSize"`           // Estimated transaction size in bytes
+	TotalFee  int64  `json:"totalFee"`         // Estimated total fee in satoshis
+}
+
+// ChunkedUploadCost estimated cost of a chunked upload (chunk transactions + index transaction)
+type ChunkedUploadCost struct {
+	Supported     bool   `json:"supported"`        // Whether chunked upload is possible for this file on this chain
+	Reason        string `json:"reason,omitempty"` // Why chunked upload is not possible
+	FeeRate       int64  `json:"feeRate"`          // Fee rate used
+	ChunkNumber   int    `json:"chunkNumber"`      // Number of chunks
+	ChunkSize     int64  `json:"chunkSize"`        // Chunk size in bytes
+	PerChunkFee   int64  `json:"perChunkFee"`      // Average fee per chunk
+	ChunkPreTxFee int64  `json:"chunkPreTxFee"`    // Funding required for chunk transactions
+	IndexPreTxFee int64  `json:"indexPreTxFee"`    // Funding required for the index transaction
+	TotalFee      int64  `json:"totalFee"`         // Estimated total fee in satoshis
+}
+
+// ChainUploadCost compares upload modes on one chain
+type ChainUploadCost struct {
+	Chain             string            `json:"chain"`             // Blockchain
+	FeeRateUnit       string            `json:"feeRateUnit"`       // sat/byte (mvc) or sat/KB (doge)
+	MaxFileSize       int64             `json:"maxFileSize"`       // Chunked upload size limit in bytes
+	DirectMaxFileSize int64             `json:"directMaxFileSize"` // Direct upload size limit in bytes (0 = unlimited)
+	Direct            DirectUploadCost  `json:"direct"`            // Direct upload estimate
+	Chunked           ChunkedUploadCost `json:"chunked"`           // Chunked upload estimate
+	Recommended       string            `json:"recommended"`       // Cheapest supported mode: direct or chunked (empty if none)
+	Savings           int64             `json:"savings"`           // Satoshis saved by the recommended mode over the other one (0 if only one is supported)
+	BreakEvenFileSize int64             `json:"breakEvenFileSize"` // Smallest size (in chunk-size steps) from which chunked costs no more than direct; 0 if never within the direct limit
+	Guidance          string            `json:"guidance"`          // Human-readable recommendation
+	DustLimit         int64             `json:"dustLimit"`         // Smallest output/funding value used (satoshis)
+	MinChange         int64             `json:"minChange"`         // Change below this is left to the fee (satoshis)
+}
+
+// UploadCostResponse upload cost comparison for every requested chain
+type UploadCostResponse struct {
+	FileSize    int64             `json:"fileSize"`    // File size in bytes
+	ContentType string            `json:"contentType"` // MIME type used for the estimate
+	Chains      []ChainUploadCost `json:"chains"`      // Per-chain comparison
+}
+
+// EstimateUploadCost estimates direct and chunked upload costs for a file of the
+// given size on each supported chain at the configured fee rates. Costs are
+// derived from script and transaction sizes, so no file content is needed.
+func (s *UploadService) EstimateUploadCost(req *UploadCostRequest) (*UploadCostResponse, error) {
+	if req.FileSize <= 0 {
+		return nil, fmt.Errorf("file size must be positive")
+	}
+	if strings.TrimSpace(req.Path) == "" {
+		req.Path = "/file"
+	}
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
+
+	chains := conf.GetUploaderChainNames()
+	if len(chains) == 0 {
+		chains = []string{"mvc"}
+	}
+	if req.Chain != "" {
+		found := false
+		for _, name := range chains {
+			if name == req.Chain {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("chain not supported: %s, supported: %s", req.Chain, strings.Join(chains, ", "))
+		}
+		chains = []string{req.Chain}
+	}
+
+	resp := &UploadCostResponse{
+		FileSize:    req.FileSize,
+		ContentType: req.ContentType,
+		Chains:      make([]ChainUploadCost, 0, len(chains)),
+	}
+	for _, chain := range chains {
+		resp.Chains = append(resp.Chains, estimateChainUploadCost(req, chain))
+	}
+	return resp, nil
+}
+
+// estimateChainUploadCost compares both modes on one chain and fills in the recommendation
+func estimateChainUploadCost(req *UploadCostRequest, chain string) ChainUploadCost {
+	maxFileSize, _, _ := conf.GetUploaderChainParam(chain)
+	policy := conf.GetUploaderChainPolicy(chain)
+	cost := ChainUploadCost{
+		Chain:       chain,
+		FeeRateUnit: "sat/byte",
+		MaxFileSize: maxFileSize,
+		DustLimit:   policy.DustLimit,
+		MinChange:   policy.MinChange,
+	}
+	if chain == "doge" {
+		cost.FeeRateUnit = "sat/KB"
+	}
+	cost.DirectMaxFileSize = directUploadMaxFileSize(chain)
+
+	cost.Direct = estimateDirectUploadCost(req, chain, req.FileSize)
+	cost.Chunked = estimateChunkedUploadCostBySize(req, chain, req.FileSize)
+
+	switch {
+	case cost.Direct.Supported && cost.Chunked.Supported:
+		if cost.Chunked.TotalFee < cost.Direct.TotalFee {
+			cost.Recommended = UploadModeChunked
+			cost.Savings = cost.Direct.TotalFee - cost.Chunked.TotalFee
+		} else {
+			cost.Recommended = UploadModeDirect
+			cost.Savings = cost.Chunked.TotalFee - cost.Direct.TotalFee
+		}
+	case cost.Direct.Supported:
+		cost.Recommended = UploadModeDirect
+	case cost.Chunked.Supported:
+		cost.Recommended = UploadModeChunked
+	}
+
+	if chain == "mvc" {
+		cost.BreakEvenFileSize = findUploadBreakEven(req, chain, cost.Chunked.ChunkSize, cost.MaxFileSize, cost.DirectMaxFileSize)
+	}
+	cost.Guidance = uploadCostGuidance(&cost)
+	return cost
+}
+
+// estimateDirectUploadCost sizes the transaction built by DirectUpload: one
+// P2PKH input, the MetaID OP_RETURN output and a change output.
+func estimateDirectUploadCost(req *UploadCostRequest, chain string, fileSize int64) DirectUploadCost {
+	feeRate := int64(0)
+	if conf.Cfg != nil {
+		feeRate = conf.Cfg.Uploader.FeeRate
+	}
+	if req.FeeRate > 0 {
+		feeRate = req.FeeRate
+	}
+	cost := DirectUploadCost{FeeRate: normalizeFeeRate(feeRate)}
+
+	if chain != "mvc" {
+		cost.Reason = "direct upload is not available on this chain"
+		return cost
+	}
+	if max := directUploadMaxFileSize(chain); max > 0 && fileSize > max {
+		cost.Reason = fmt.Sprintf("file size exceeds direct upload limit (max %d bytes)", max)
+		return cost
+	}
+
+	// 1 P2PKH input + OP_RETURN + P2PKH change
+	scriptLen := metaIDScriptSize("create", req.Path, req.ContentType, int(fileSize))
+	cost.TxSize = mvcTxSize(1, 1, scriptLen)
+	cost.TotalFee = int64(cost.TxSize) * cost.FeeRate
+	cost.Supported = true
+	return cost
+}
+
+// directUploadMaxFileSize returns the smaller of uploader.max_file_size and the
+// chain's single-PIN payload limit (0 = unlimited). Gzip may let larger
+// compressible files through; estimates do not count on it.
+func directUploadMaxFileSize(chain string) int64 {
+	max := conf.GetUploaderMaxSinglePayload(chain)
+	if conf.Cfg != nil && conf.Cfg.Uploader.MaxFileSize > 0 && (max <= 0 || conf.Cfg.Uploader.MaxFileSize < max) {
+		max = conf.Cfg.Uploader.MaxFileSize
+	}
+	return max
+}
+
+// estimateChunkedUploadCostBySize mirrors EstimateChunkedUpload using only the file size
+func estimateChunkedUploadCostBySize(req *UploadCostRequest, chain string, fileSize int64) ChunkedUploadCost {
+	maxFileSize, chunkSize, feeRate := conf.GetUploaderChainParam(chain)
+	if req.FeeRate > 0 {
+		feeRate = req.FeeRate
+	}
+	feeRate = normalizeFeeRate(feeRate)
+	if chunkSize <= 0 {
+		chunkSize = 2000 * 1024 // default 2000 KB
+	}
+	policy := conf.GetUploaderChainPolicy(chain)
+	cost := ChunkedUploadCost{FeeRate: feeRate, ChunkSize: chunkSize}
+
+	if maxFileSize > 0 && fileSize > maxFileSize {
+		cost.Reason = fmt.Sprintf("file size exceeds chunked upload limit (max %d bytes)", maxFileSize)
+		return cost
+	}
+
+	chunkNumber := int((fileSize + chunkSize - 1) / chunkSize)
+	lastChunkSize := fileSize - int64(chunkNumber-1)*chunkSize
+	cost.ChunkNumber = chunkNumber
+
+	chunkPath, indexPath, err := chunkedUploadPaths(req.Path)
+	if err != nil {
+		cost.Reason = err.Error()
+		return cost
+	}
+	indexLen, err := chunkedIndexSize(req, fileSize, chunkSize, chunkNumber)
+	if err != nil {
+		cost.Reason = err.Error()
+		return cost
+	}
+
+	// Every full chunk costs the same; only the last one may be shorter
+	var fullChunkFee, lastChunkFee, fundingTxFee int64
+	if chain == "doge" {
+		chunkContentType := metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary"
+		indexContentType := metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8"
+		// Each chunk output must fund 100000 P2SH + inscription fees
+		fee, err := common.EstimateDogeInscriptionFee(make([]byte, chunkSize), chunkPath, chunkContentType, feeRate)
+		if err != nil {
+			cost.Reason = err.Error()
+			return cost
+		}
+		fullChunkFee = 100000 + fee
+		fee, err = common.EstimateDogeInscriptionFee(make([]byte, lastChunkSize), chunkPath, chunkContentType, feeRate)
+		if err != nil {
+			cost.Reason = err.Error()
+			return cost
+		}
+		lastChunkFee = 100000 + fee
+
+		if indexLen > dogeMaxInscriptionScriptSize {
+			cost.Reason = "index too large for DOGE: file has too many chunks, reduce file size"
+			return cost
+		}
+		cost.IndexPreTxFee, err = common.EstimateDogeInscriptionFee(make([]byte, indexLen), indexPath, indexContentType, feeRate)
+		if err != nil {
+			cost.Reason = err.Error()
+			return cost
+		}
+
+		feeRatePerByte := feeRate / 1024
+		if feeRatePerByte < 1 {
+			feeRatePerByte = 1
+		}
+		fundingTxFee = chunkFundingTxFee(chunkNumber, feeRatePerByte, policy.DustLimit)
+	} else {
+		chunkContentType := metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary"
+		indexContentType := metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8"
+		fullChunkFee = chunkFundingValueForScriptSize(metaIDScriptSize("create", chunkPath, chunkContentType, int(chunkSize)), feeRate, policy.DustLimit)
+		lastChunkFee = chunkFundingValueForScriptSize(metaIDScriptSize("create", chunkPath, chunkContentType, int(lastChunkSize)), feeRate, policy.DustLimit)
+
+		// Index tx contains 1 input + a user output + OP_RETURN
+		indexScriptLen := metaIDScriptSize("create", indexPath, indexContentType, indexLen)
+		indexTxSize := mvcTxSize(1, 1, indexScriptLen)
+		cost.IndexPreTxFee = int64(indexTxSize) * feeRate
+		if cost.IndexPreTxFee < policy.DustLimit {
+			cost.IndexPreTxFee = policy.DustLimit
+		}
+		fundingTxFee = chunkFundingTxFee(chunkNumber, feeRate, policy.DustLimit)
+	}
+
+	totalChunkFee := fullChunkFee*int64(chunkNumber-1) + lastChunkFee
+	cost.PerChunkFee = totalChunkFee / int64(chunkNumber)
+	cost.ChunkPreTxFee = totalChunkFee + fundingTxFee
+	cost.TotalFee = cost.ChunkPreTxFee + cost.IndexPreTxFee
+	cost.Supported = true
+	return cost
+}
+
+// findUploadBreakEven scans file sizes in chunk-size steps up to the smaller of
+// both limits and returns the first one at which chunked upload costs no more
+// than direct upload, or 0 if there is none.
+func findUploadBreakEven(req *UploadCostRequest, chain string, chunkSize, maxFileSize, directMaxFileSize int64) int64 {
+	if chunkSize <= 0 {
+		return 0
+	}
+	limit := maxFileSize
+	if directMaxFileSize > 0 && (limit <= 0 || directMaxFileSize < limit) {
+		limit = directMaxFileSize
+	}
+	if limit <= 0 {
+		limit = chunkSize * maxBreakEvenSteps
+	}
+	step := chunkSize
+	if limit/step > maxBreakEvenSteps {
+		step = (limit/maxBreakEvenSteps + chunkSize - 1) / chunkSize * chunkSize
+	}
+	for size := step; size <= limit; size += step {
+		direct := estimateDirectUploadCost(req, chain, size)
+		chunked := estimateChunkedUploadCostBySize(req, chain, size)
+		if !direct.Supported || !chunked.Supported {
+			return 0
+		}
+		if chunked.TotalFee <= direct.TotalFee {
+			return size
+		}
+	}
+	return 0
+}
+
+// uploadCostGuidance explains the recommendation so wallets can show it as-is
+func uploadCostGuidance(cost *ChainUploadCost) string {
+	switch {
+	case cost.Recommended == "":
+		return "file cannot be uploaded on this chain: " + cost.Chunked.Reason
+	case !cost.Direct.Supported:
+		return fmt.Sprintf("use chunked upload (%d chunks): %s", cost.Chunked.ChunkNumber, cost.Direct.Reason)
+	case !cost.Chunked.Supported:
+		return "use direct upload: " + cost.Chunked.Reason
+	}
+
+	guidance := fmt.Sprintf("%s upload is cheaper by %d satoshis", cost.Recommended, cost.Savings)
+	if cost.BreakEvenFileSize > 0 {
+		return guidance + fmt.Sprintf("; chunked upload costs no more than direct upload from %d bytes", cost.BreakEvenFileSize)
+	}
+	if cost.DirectMaxFileSize > 0 {
+		return guidance + fmt.Sprintf("; direct upload stays cheaper up to its %d byte limit, use chunked upload above it", cost.DirectMaxFileSize)
+	}
+	return guidance + "; direct upload stays cheaper at every size"
+}
+
+// chunkedIndexSize returns the length of the v2 MetaFileIndex JSON of a
+// chunked upload, using placeholder hashes and PinIDs of real length.
+func chunkedIndexSize(req *UploadCostRequest, fileSize, chunkSize int64, chunkNumber int) (int, error) {
+	index := metaid_protocols.MetaFileIndex{
+		Version:     metaid_protocols.MetaFileIndexV2,
+		Sha256:      strings.Repeat("0", 64),
+		FileSize:    fileSize,
+		ChunkNumber: chunkNumber,
+		ChunkSize:   chunkSize,
+		DataType:    req.ContentType,
+		Name:        req.FileName,
+		Compression: metaid_protocols.MetaFileCompressionNone,
+		ChunkList:   []metaid_protocols.MetaFileChunk{},
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal index data for estimation: %w", err)
+	}
+	// Each entry is {"sha256":"<64 hex>","pinId":"<pinId>","offset":N,"length":M},
+	// comma separated; the first entry omits its zero offset
+	size := len(data) + chunkNumber - 1
+	for i := 0; i < chunkNumber; i++ {
+		offset := int64(i) * chunkSize
+		length := chunkSize
+		if offset+length > fileSize {
+			length = fileSize - offset
+		}
+		size += len(`{"sha256":"","pinId":"","length":}`) + 64 + placeholderPinIDLen + len(strconv.FormatInt(length, 10))
+		if offset > 0 {
+			size += len(`,"offset":`) + len(strconv.FormatInt(offset, 10))
+		}
+	}
+	return size, nil
+}
+
+// metaIDScriptSize returns the length of an OP_0 OP_RETURN MetaID script as
+// built by DirectUpload / buildChunkOpReturnScript / buildIndexOpReturnScript
+// for a payload of payloadLen bytes
+func metaIDScriptSize(operation, path, contentType string, payloadLen int) int {
+	return scripts.MetaIdOpReturnSize(&scripts.MetaId{Operation: operation, Path: path, ContentType: contentType}, payloadLen)
+}
+
+// opReturnOutputSize returns the serialized size of an output carrying a script of scriptLen bytes
+func opReturnOutputSize(scriptLen int) int {
+	return 8 + wire2.VarIntSerializeSize(uint64(scriptLen)) + scriptLen
+}
+
+// mvcTxSize returns the signed size of an MVC transaction with inputs P2PKH
+// inputs, p2pkhOutputs P2PKH outputs and one OP_RETURN output per script
+// length, sized by the shared common.ChainTxBuilder estimate
+func mvcTxSize(inputs, p2pkhOutputs int, opReturnScriptLens ...int) int {
+	inputModes := make([]common.SignMode, inputs)
+	for i := range inputModes {
+		inputModes[i] = common.SignModeLegacy
+	}
+	outputScripts := make([][]byte, p2pkhOutputs)
+	for i := range outputScripts {
+		outputScripts[i] = make([]byte, 25) // OP_DUP OP_HASH160 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+	}
+	_, size, _ := common.EstimateChainTxFee(&common.MvcTxBuilder{}, inputModes, outputScripts, nil, 1)
+
+	// OP_RETURN scripts are sized without materialising the payload
+	for _, scriptLen := range opReturnScriptLens {
+		size += opReturnOutputSize(scriptLen)
+	}
+	outputCount := p2pkhOutputs + len(opReturnScriptLens)
+	size += wire2.VarIntSerializeSize(uint64(outputCount)) - wire2.VarIntSerializeSize(uint64(p2pkhOutputs))
+	return size
+}
+
+// chunkFundingTxFee estimates the fee of the chunk funding transaction (1 input + chunkNumber outputs)
+func chunkFundingTxFee(chunkNumber int, feeRatePerByte, dustLimit int64) int64 {
+	fee := int64(mvcTxSize(1, chunkNumber)) * feeRatePerByte
+	if fee < dustLimit {
+		fee = dustLimit
+	}
+	return fee
+}
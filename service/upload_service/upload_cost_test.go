@@ -0,0 +1,219 @@
+package upload_service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+func setUploadCostConfig(t *testing.T) {
+	t.Helper()
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{
+		Uploader: conf.UploaderConfig{
+			MaxFileSize: 10 * 1024 * 1024,
+			FeeRate:     5,
+			ChunkSize:   2 * 1024 * 1024,
+			Chains: []conf.UploaderChainConfig{
+				{Name: "mvc", MaxFileSize: 100, ChunkSize: 2, FeeRate: 5},
+				{Name: "doge", MaxFileSize: 1, ChunkSizeBytes: 1200, FeeRate: 200000},
+			},
+		},
+	}
+	t.Cleanup(func() { conf.Cfg = prev })
+}
+
+func TestMetaIDScriptSize_MatchesBuiltScripts(t *testing.T) {
+	for _, n := range []int{2, 75, 76, 255, 256, 520, 521, 1200, 70000} {
+		data := bytes.Repeat([]byte("a"), n)
+		script, err := buildChunkOpReturnScript("/file/file/_chunk", data)
+		if err != nil {
+			t.Fatalf("buildChunkOpReturnScript(%d): %v", n, err)
+		}
+		contentType := metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary"
+		if got := metaIDScriptSize("create", "/file/file/_chunk", contentType, n); got != len(script) {
+			t.Errorf("metaIDScriptSize(%d) = %d, want %d", n, got, len(script))
+		}
+	}
+}
+
+func TestEstimateUploadCost_ChunkedMatchesContentEstimate(t *testing.T) {
+	setUploadCostConfig(t)
+	s := &UploadService{}
+
+	tests := []struct {
+		chain string
+		size  int
+	}{
+		{"mvc", 1000},
+		{"mvc", 5*1024*1024 + 17},
+		{"doge", 1200},
+		{"doge", 5000},
+	}
+	for _, tt := range tests {
+		want, err := s.EstimateChunkedUpload(&EstimateChunkedUploadRequest{
+			FileName:    "a.bin",
+			Content:     bytes.Repeat([]byte{7}, tt.size),
+			Path:        "/file",
+			ContentType: "application/octet-stream",
+			Chain:       tt.chain,
+		})
+		if err != nil {
+			t.Fatalf("%s/%d EstimateChunkedUpload: %v", tt.chain, tt.size, err)
+		}
+		got := estimateChunkedUploadCostBySize(&UploadCostRequest{
+			FileName:    "a.bin",
+			Path:        "/file",
+			ContentType: "application/octet-stream",
+		}, tt.chain, int64(tt.size))
+		if !got.Supported {
+			t.Fatalf("%s/%d not supported: %s", tt.chain, tt.size, got.Reason)
+		}
+		if got.ChunkNumber != want.ChunkNumber || got.ChunkPreTxFee != want.ChunkPreTxFee {
+			t.Errorf("%s/%d chunks=%d pre=%d, want chunks=%d pre=%d",
+				tt.chain, tt.size, got.ChunkNumber, got.ChunkPreTxFee, want.ChunkNumber, want.ChunkPreTxFee)
+		}
+		// Real PinIDs are longer than the content estimate's placeholders
+		if got.IndexPreTxFee < want.IndexPreTxFee {
+			t.Errorf("%s/%d index fee %d below content estimate %d", tt.chain, tt.size, got.IndexPreTxFee, want.IndexPreTxFee)
+		}
+	}
+}
+
+func TestEstimateUploadCost_Recommendation(t *testing.T) {
+	setUploadCostConfig(t)
+	s := &UploadService{}
+
+	tests := []struct {
+		name        string
+		req         UploadCostRequest
+		wantErr     bool
+		chain       string
+		direct      bool
+		chunked     bool
+		recommended string
+	}{
+		{name: "small mvc file", req: UploadCostRequest{FileSize: 2048, Chain: "mvc"}, chain: "mvc", direct: true, chunked: true, recommended: UploadModeDirect},
+		{name: "above direct limit", req: UploadCostRequest{FileSize: 20 * 1024 * 1024, Chain: "mvc"}, chain: "mvc", chunked: true, recommended: UploadModeChunked},
+		{name: "doge is chunked only", req: UploadCostRequest{FileSize: 2048, Chain: "doge"}, chain: "doge", chunked: true, recommended: UploadModeChunked},
+		{name: "above every limit", req: UploadCostRequest{FileSize: 2 * 1024 * 1024, Chain: "doge"}, chain: "doge"},
+		{name: "unknown chain", req: UploadCostRequest{FileSize: 2048, Chain: "btc"}, wantErr: true},
+		{name: "empty file", req: UploadCostRequest{FileSize: 0}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			resp, err := s.EstimateUploadCost(&req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", resp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EstimateUploadCost: %v", err)
+			}
+			if len(resp.Chains) != 1 || resp.Chains[0].Chain != tt.chain {
+				t.Fatalf("chains = %+v", resp.Chains)
+			}
+			cost := resp.Chains[0]
+			if cost.Direct.Supported != tt.direct || cost.Chunked.Supported != tt.chunked || cost.Recommended != tt.recommended {
+				t.Errorf("direct=%v chunked=%v recommended=%q, want %v %v %q",
+					cost.Direct.Supported, cost.Chunked.Supported, cost.Recommended, tt.direct, tt.chunked, tt.recommended)
+			}
+			if cost.Guidance == "" {
+				t.Error("guidance is empty")
+			}
+			if cost.Direct.Supported && cost.Chunked.Supported {
+				diff := cost.Chunked.TotalFee - cost.Direct.TotalFee
+				if diff < 0 {
+					diff = -diff
+				}
+				if cost.Savings != diff {
+					t.Errorf("savings = %d, want %d", cost.Savings, diff)
+				}
+			}
+		})
+	}
+
+	// Without a chain every configured uploader chain is compared
+	resp, err := s.EstimateUploadCost(&UploadCostRequest{FileSize: 4096})
+	if err != nil {
+		t.Fatalf("EstimateUploadCost: %v", err)
+	}
+	var chains []string
+	for _, c := range resp.Chains {
+		chains = append(chains, c.Chain)
+	}
+	if strings.Join(chains, ",") != "mvc,doge" {
+		t.Errorf("chains = %v, want mvc,doge", chains)
+	}
+}
+
+func TestMvcTxSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		inputs, p2pkh int
+		scripts       []int
+		want          int
+	}{
+		{"direct upload", 1, 1, []int{300}, 4 + 1 + 148 + 1 + 34 + opReturnOutputSize(300) + 4},
+		{"chunk tx", 1, 0, []int{70000}, 4 + 1 + 148 + 1 + opReturnOutputSize(70000) + 4},
+		{"chunk funding", 1, 10, nil, 4 + 1 + 148 + 1 + 34*10 + 4},
+		{"many outputs", 1, 300, nil, 4 + 1 + 148 + 3 + 34*300 + 4},
+	}
+	for _, tt := range tests {
+		if got := mvcTxSize(tt.inputs, tt.p2pkh, tt.scripts...); got != tt.want {
+			t.Errorf("%s: mvcTxSize = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUploaderChainPolicy_AppliedToEstimates(t *testing.T) {
+	setUploadCostConfig(t)
+	conf.Cfg.Uploader.MinChange = 1000
+	conf.Cfg.Uploader.Chains[0].DustLimit = 5000
+	conf.Cfg.Uploader.Chains[0].FeeRate = 1
+
+	tests := []struct {
+		chain         string
+		wantDust      int64
+		wantMinChange int64
+	}{
+		{"mvc", 5000, 5000}, // min_change is never below the dust limit
+		{"doge", 600, 1000},
+		{"btc", 600, 1000}, // not configured: global defaults
+	}
+	for _, tt := range tests {
+		policy := conf.GetUploaderChainPolicy(tt.chain)
+		if policy.DustLimit != tt.wantDust || policy.MinChange != tt.wantMinChange {
+			t.Errorf("%s policy = %+v, want dust %d, min change %d", tt.chain, policy, tt.wantDust, tt.wantMinChange)
+		}
+	}
+
+	s := &UploadService{}
+	resp, err := s.EstimateChunkedUpload(&EstimateChunkedUploadRequest{
+		Content: []byte("hello"),
+		Path:    "/file",
+		Chain:   "mvc",
+	})
+	if err != nil {
+		t.Fatalf("EstimateChunkedUpload: %v", err)
+	}
+	if resp.DustLimit != 5000 || resp.MinChange != 5000 {
+		t.Errorf("response policy = %d/%d, want 5000/5000", resp.DustLimit, resp.MinChange)
+	}
+	// At 1 sat/byte every small transaction is raised to the dust limit
+	if resp.ChunkFees[0] != 5000 || resp.IndexPreTxFee != 5000 || resp.ChunkPreTxFee != 10000 {
+		t.Errorf("chunk fee %d, index fee %d, chunk pre-tx %d; want 5000, 5000, 10000",
+			resp.ChunkFees[0], resp.IndexPreTxFee, resp.ChunkPreTxFee)
+	}
+
+	cost := estimateChainUploadCost(&UploadCostRequest{FileSize: 5, Path: "/file"}, "mvc")
+	if cost.DustLimit != 5000 || cost.MinChange != 5000 || cost.Chunked.TotalFee != resp.TotalFee {
+		t.Errorf("upload cost = %+v, want dust policy 5000/5000 and total %d", cost, resp.TotalFee)
+	}
+}
@@ -0,0 +1,76 @@
+package upload_service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+)
+
+// uploadGzipEnabled resolves a request's gzip choice (nil = not set) against
+// uploader.gzip.enabled
+func uploadGzipEnabled(requested *bool) bool {
+	if requested != nil {
+		return *requested
+	}
+	return conf.Cfg != nil && conf.Cfg.Uploader.Gzip.Enabled
+}
+
+// uploadGzipMinSavingPercent returns uploader.gzip.min_saving_percent (default 10)
+func uploadGzipMinSavingPercent() int {
+	if conf.Cfg == nil || conf.Cfg.Uploader.Gzip.MinSavingPercent <= 0 {
+		return 10
+	}
+	return conf.Cfg.Uploader.Gzip.MinSavingPercent
+}
+
+// gzipForInscription returns the payload to inscribe for content. With
+// compress set, content is gzipped and the gzip form is returned (with true)
+// only when it is at least minSavingPercent smaller; content that is already
+// gzip is left alone. The indexer inflates gzip payloads, so the indexed file
+// is the original content either way.
+func gzipForInscription(content []byte, compress bool, minSavingPercent int) ([]byte, bool, error) {
+	if !compress || len(content) == 0 || isGzipContent(content) {
+		return content, false, nil
+	}
+
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, false, fmt.Errorf("failed to gzip content: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to gzip content: %w", err)
+	}
+
+	saved := len(content) - buf.Len()
+	if saved <= 0 || int64(saved)*100 < int64(len(content))*int64(minSavingPercent) {
+		return content, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// isGzipContent reports whether content starts with the gzip magic number
+func isGzipContent(content []byte) bool {
+	return len(content) >= 2 && content[0] == 0x1f && content[1] == 0x8b
+}
+
+// inscriptionSavedFee returns the fee saved at feeRate by inscribing m with a
+// payloadLen-byte payload instead of the contentLen-byte original
+func inscriptionSavedFee(m *scripts.MetaId, contentLen, payloadLen int, feeRate int64) int64 {
+	if payloadLen >= contentLen {
+		return 0
+	}
+	outputSize := func(n int) int {
+		scriptLen := scripts.MetaIdOpReturnSize(m, n)
+		return 8 + wire2.VarIntSerializeSize(uint64(scriptLen)) + scriptLen
+	}
+	return int64(outputSize(contentLen)-outputSize(payloadLen)) * feeRate
+}
@@ -0,0 +1,123 @@
+package upload_service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+
+	"meta-file-system/common/scripts"
+	"meta-file-system/conf"
+
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+)
+
+func TestGzipForInscription(t *testing.T) {
+	text := []byte(strings.Repeat(`{"name":"metaid","value":12345}`, 200))
+	random := make([]byte, 4096)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(text)
+	w.Close()
+
+	tests := []struct {
+		name           string
+		content        []byte
+		compress       bool
+		minSaving      int
+		wantCompressed bool
+	}{
+		{"compressible", text, true, 10, true},
+		{"disabled", text, false, 10, false},
+		{"incompressible", random, true, 10, false},
+		{"already gzip", gz.Bytes(), true, 10, false},
+		{"saving below threshold", text, true, 100, false},
+		{"empty", nil, true, 10, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, compressed, err := gzipForInscription(tt.content, tt.compress, tt.minSaving)
+			if err != nil {
+				t.Fatalf("gzipForInscription: %v", err)
+			}
+			if compressed != tt.wantCompressed {
+				t.Fatalf("compressed = %v, want %v", compressed, tt.wantCompressed)
+			}
+			if !compressed {
+				if !bytes.Equal(payload, tt.content) {
+					t.Error("uncompressed payload differs from content")
+				}
+				return
+			}
+			if len(payload) >= len(tt.content) {
+				t.Errorf("payload %d bytes, want fewer than %d", len(payload), len(tt.content))
+			}
+			r, err := gzip.NewReader(bytes.NewReader(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(got, tt.content) {
+				t.Errorf("payload does not inflate to content (err=%v)", err)
+			}
+		})
+	}
+}
+
+func TestInscriptionSavedFee_MatchesBuiltScripts(t *testing.T) {
+	m := &scripts.MetaId{Operation: "create", Path: "/file", ContentType: "text/plain"}
+	outputSize := func(n int) int {
+		script := scripts.MetaIdOpReturn(&scripts.MetaId{
+			Operation:   m.Operation,
+			Path:        m.Path,
+			ContentType: m.ContentType,
+			Payload:     bytes.Repeat([]byte("a"), n),
+		})
+		return wire2.NewTxOut(0, script).SerializeSize()
+	}
+	for _, tc := range []struct{ contentLen, payloadLen int }{{6000, 300}, {521, 520}, {300, 70}, {100, 100}, {50, 80}} {
+		want := int64(0)
+		if tc.payloadLen < tc.contentLen {
+			want = int64(outputSize(tc.contentLen)-outputSize(tc.payloadLen)) * 3
+		}
+		if got := inscriptionSavedFee(m, tc.contentLen, tc.payloadLen, 3); got != want {
+			t.Errorf("inscriptionSavedFee(%d, %d) = %d, want %d", tc.contentLen, tc.payloadLen, got, want)
+		}
+	}
+}
+
+func TestUploadGzipEnabled(t *testing.T) {
+	prev := conf.Cfg
+	t.Cleanup(func() { conf.Cfg = prev })
+	on, off := true, false
+
+	tests := []struct {
+		name      string
+		cfg       bool
+		requested *bool
+		want      bool
+	}{
+		{"default off", false, nil, false},
+		{"default on", true, nil, true},
+		{"request on", false, &on, true},
+		{"request off", true, &off, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf.Cfg = &conf.Config{Uploader: conf.UploaderConfig{Gzip: conf.UploadGzipConfig{Enabled: tt.cfg}}}
+			if got := uploadGzipEnabled(tt.requested); got != tt.want {
+				t.Errorf("uploadGzipEnabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	conf.Cfg = nil
+	if got := uploadGzipMinSavingPercent(); got != 10 {
+		t.Errorf("uploadGzipMinSavingPercent without config = %d, want 10", got)
+	}
+}
@@ -0,0 +1,141 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+)
+
+// ErrUploadedFileNotFound no uploaded file with the requested file ID
+var ErrUploadedFileNotFound = errors.New("uploaded file not found")
+
+// UploadedFileListRequest selects a user's upload history
+type UploadedFileListRequest struct {
+	MetaId  string       // MetaID (MetaId and/or Address is required)
+	Address string       // Uploader address
+	Status  model.Status // Optional: pending, success or failed
+	Cursor  int64        // Last file ID of the previous page (0 for the first page)
+	Size    int          // Page size (default 20, max 100)
+}
+
+// UploadedFile an uploaded file with its chunks and the indexer's view of it
+type UploadedFile struct {
+	File   *model.File
+	Chunks []*model.FileChunk // Detail only, ordered by chunk index
+
+	// IndexerLinked is true when the indexer data could be read (indexer on
+	// MySQL with the same DSN); Indexed is then its record of the PIN, or nil
+	// while the PIN is not indexed yet
+	IndexerLinked bool
+	Indexed       *model.IndexerFile
+
+	// Retention is the owner's active keep of the file; nil when not kept
+	Retention *model.FileRetention
+}
+
+// UploadedFileListResponse a page of a user's upload history
+type UploadedFileListResponse struct {
+	Files      []*UploadedFile
+	NextCursor int64
+	HasMore    bool
+}
+
+// ListUploadedFiles lists the files uploaded by a MetaID and/or address, newest first.
+func (s *UploadService) ListUploadedFiles(req *UploadedFileListRequest) (*UploadedFileListResponse, error) {
+	if req.MetaId == "" && req.Address == "" {
+		return nil, fmt.Errorf("metaId or address is required")
+	}
+	if req.Size <= 0 || req.Size > 100 {
+		req.Size = 20
+	}
+
+	files, nextCursor, hasMore, err := s.fileDAO.ListByOwnerWithCursor(dao.FileListFilter{
+		MetaId:  req.MetaId,
+		Address: req.Address,
+		Status:  req.Status,
+	}, req.Cursor, req.Size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploaded files: %w", err)
+	}
+
+	result := make([]*UploadedFile, 0, len(files))
+	for _, f := range files {
+		result = append(result, &UploadedFile{File: f})
+	}
+	s.linkIndexerFiles(result)
+	s.linkRetentions(result)
+
+	return &UploadedFileListResponse{
+		Files:      result,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// GetUploadedFile returns an uploaded file with its chunks.
+func (s *UploadService) GetUploadedFile(fileId string) (*UploadedFile, error) {
+	file, err := s.fileDAO.GetByFileID(fileId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadedFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get uploaded file: %w", err)
+	}
+	// Raw transactions can be large and are already on chain
+	file.ContentHex, file.PreTxRaw, file.TxRaw = "", "", ""
+
+	uploaded := &UploadedFile{File: file}
+	if file.ChunkType == model.ChunkTypeMulti && file.FileHash != "" {
+		chunks, err := s.fileChunkDAO.GetByFileHash(file.FileHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file chunks: %w", err)
+		}
+		for _, chunk := range chunks {
+			chunk.ContentHex, chunk.TxRaw = "", ""
+		}
+		uploaded.Chunks = chunks
+	}
+	s.linkIndexerFiles([]*UploadedFile{uploaded})
+	s.linkRetentions([]*UploadedFile{uploaded})
+	return uploaded, nil
+}
+
+// linkIndexerFiles attaches the indexer records of the files' PINs when the
+// indexer tables are reachable. Failures only drop the linkage.
+func (s *UploadService) linkIndexerFiles(files []*UploadedFile) {
+	if len(files) == 0 || conf.Cfg == nil {
+		return
+	}
+	// The indexer tables are only reachable when both services use the same
+	// relational database (checked by HasIndexerTables)
+	switch conf.Cfg.Database.IndexerType {
+	case "", "mysql", "sqlite":
+	default:
+		return
+	}
+	if !s.fileDAO.HasIndexerTables() {
+		return
+	}
+
+	pinIDs := make([]string, 0, len(files))
+	for _, f := range files {
+		if f.File.PinId != "" {
+			pinIDs = append(pinIDs, f.File.PinId)
+		}
+	}
+	indexed, err := s.fileDAO.GetIndexerFilesByPinIDs(pinIDs)
+	if err != nil {
+		log.Printf("Failed to link uploaded files to indexer data: %v", err)
+		return
+	}
+	for _, f := range files {
+		f.IndexerLinked = true
+		f.Indexed = indexed[f.File.PinId]
+	}
+}
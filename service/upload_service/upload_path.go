@@ -0,0 +1,85 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"meta-file-system/conf"
+	"meta-file-system/service/common_service/metaid_protocols"
+)
+
+// ErrUnsupportedBasePath the request path cannot carry the chunk and index
+// paths of a chunked upload
+var ErrUnsupportedBasePath = errors.New("unsupported base path for chunked upload")
+
+// chunkedUploadPaths derives the MetaID paths inscribed by a chunked upload
+// from the request path. The base is the part of the path before its first
+// "file" segment (the whole path when there is none), so "/file",
+// "/file/a.png" and "/file/_chunk" all give /file/_chunk and /file/index, and
+// "myapp:/app/file/a.png" gives myapp:/app/file/_chunk and
+// myapp:/app/file/index. A host prefix is always kept; a non-empty base must
+// be listed in uploader.chunk_base_paths. @pinId references are rejected.
+func chunkedUploadPaths(path string) (chunkPath, indexPath string, err error) {
+	p, err := metaid_protocols.ParseMetaIDPath(path)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid file path: %w", err)
+	}
+	if p.IsReference() {
+		return "", "", fmt.Errorf("%w: %q is a PIN reference", ErrUnsupportedBasePath, path)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(p.Path, "/"), "/")
+	for i, seg := range p.Segments {
+		if seg == "file" {
+			segments = segments[:i]
+			break
+		}
+	}
+	base := ""
+	if len(segments) > 0 {
+		base = "/" + strings.Join(segments, "/")
+		if !isAllowedChunkBasePath(base) {
+			return "", "", fmt.Errorf("%w: %q (allowed: %s)", ErrUnsupportedBasePath, base, allowedChunkBasePaths())
+		}
+	}
+
+	prefix := base
+	if p.Host != "" {
+		prefix = p.Host + ":" + base
+	}
+	chunkPath = prefix + "/file/" + metaid_protocols.MonitorFileChunk
+	indexPath = prefix + "/file/" + metaid_protocols.MonitorFileIndex
+	if len(chunkPath) > metaid_protocols.MaxPathLength {
+		return "", "", fmt.Errorf("invalid file path: %w", metaid_protocols.ErrPathTooLong)
+	}
+	return chunkPath, indexPath, nil
+}
+
+// isAllowedChunkBasePath reports whether base (non-empty) is configured in
+// uploader.chunk_base_paths, compared case-insensitively on whole segments
+func isAllowedChunkBasePath(base string) bool {
+	if conf.Cfg == nil {
+		return false
+	}
+	for _, allowed := range conf.Cfg.Uploader.ChunkBasePaths {
+		p, err := metaid_protocols.ParseMetaIDPath(allowed)
+		if err != nil || p.IsReference() {
+			continue
+		}
+		if p.Normalized == strings.ToLower(base) {
+			return true
+		}
+	}
+	return false
+}
+
+func allowedChunkBasePaths() string {
+	allowed := []string{"/file"}
+	if conf.Cfg != nil {
+		for _, base := range conf.Cfg.Uploader.ChunkBasePaths {
+			allowed = append(allowed, strings.TrimSuffix(base, "/")+"/file")
+		}
+	}
+	return strings.Join(allowed, ", ")
+}
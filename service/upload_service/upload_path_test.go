@@ -0,0 +1,84 @@
+package upload_service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"meta-file-system/conf"
+)
+
+func TestChunkedUploadPaths(t *testing.T) {
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{Uploader: conf.UploaderConfig{ChunkBasePaths: []string{"/App/", "/org/team"}}}
+	t.Cleanup(func() { conf.Cfg = prev })
+
+	const pinRef = "@0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdefi0"
+	tests := []struct {
+		path        string
+		wantChunk   string
+		wantIndex   string
+		unsupported bool
+		invalid     bool
+	}{
+		{path: "/file", wantChunk: "/file/_chunk", wantIndex: "/file/index"},
+		{path: "/file/photos/a.png", wantChunk: "/file/_chunk", wantIndex: "/file/index"},
+		{path: "/File/_chunk", wantChunk: "/file/_chunk", wantIndex: "/file/index"},
+		{path: "MyApp:/file/a.png", wantChunk: "myapp:/file/_chunk", wantIndex: "myapp:/file/index"},
+		{path: "/app/file/a.png", wantChunk: "/app/file/_chunk", wantIndex: "/app/file/index"},
+		{path: "/app", wantChunk: "/app/file/_chunk", wantIndex: "/app/file/index"},
+		{path: "myapp:/org/team/file", wantChunk: "myapp:/org/team/file/_chunk", wantIndex: "myapp:/org/team/file/index"},
+		{path: "/protocols/simplebuzz", unsupported: true},
+		{path: "/org/file", unsupported: true},
+		{path: pinRef, unsupported: true},
+		{path: "myapp:" + pinRef, unsupported: true},
+		{path: "/file/../etc", invalid: true},
+		{path: strings.Repeat("a", 490) + ":/file", invalid: true},
+	}
+	for _, tt := range tests {
+		chunkPath, indexPath, err := chunkedUploadPaths(tt.path)
+		switch {
+		case tt.unsupported:
+			if !errors.Is(err, ErrUnsupportedBasePath) {
+				t.Errorf("%q: err = %v, want ErrUnsupportedBasePath", tt.path, err)
+			}
+		case tt.invalid:
+			if err == nil || errors.Is(err, ErrUnsupportedBasePath) {
+				t.Errorf("%q: err = %v, want invalid path", tt.path, err)
+			}
+		case err != nil:
+			t.Errorf("%q: unexpected error %v", tt.path, err)
+		case chunkPath != tt.wantChunk || indexPath != tt.wantIndex:
+			t.Errorf("%q: got %q, %q; want %q, %q", tt.path, chunkPath, indexPath, tt.wantChunk, tt.wantIndex)
+		}
+	}
+}
+
+func TestChunkedUpload_RejectsUnsupportedBasePath(t *testing.T) {
+	setUploadCostConfig(t)
+	s := &UploadService{}
+
+	_, err := s.EstimateChunkedUpload(&EstimateChunkedUploadRequest{
+		Content: []byte("hello"),
+		Path:    "/protocols/simplebuzz",
+	})
+	if !errors.Is(err, ErrUnsupportedBasePath) {
+		t.Errorf("EstimateChunkedUpload err = %v, want ErrUnsupportedBasePath", err)
+	}
+
+	_, err = s.ChunkedUpload(&ChunkedUploadRequest{
+		Content:       []byte("hello"),
+		Path:          "/protocols/simplebuzz",
+		Address:       "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+		ChunkPreTxHex: "00",
+		IndexPreTxHex: "00",
+	})
+	if !errors.Is(err, ErrUnsupportedBasePath) {
+		t.Errorf("ChunkedUpload err = %v, want ErrUnsupportedBasePath", err)
+	}
+
+	cost := estimateChunkedUploadCostBySize(&UploadCostRequest{Path: "/protocols/simplebuzz"}, "mvc", 1000)
+	if cost.Supported || !strings.Contains(cost.Reason, ErrUnsupportedBasePath.Error()) {
+		t.Errorf("upload cost = %+v, want unsupported base path", cost)
+	}
+}
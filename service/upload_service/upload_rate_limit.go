@@ -0,0 +1,235 @@
+package upload_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+)
+
+// ErrUploadRateLimited an address or client IP exceeded its upload rate limit
+var ErrUploadRateLimited = errors.New("upload rate limit exceeded")
+
+// Upload rate limit scopes
+const (
+	RateLimitScopeAddress = "address"
+	RateLimitScopeIP      = "ip"
+)
+
+const uploadRateLimitKeyPrefix = "upload_rate_limit:"
+
+// UploadRateLimitError details the bucket that rejected an upload
+type UploadRateLimitError struct {
+	Scope      string        // address or ip
+	Key        string        // Limited address or client IP
+	PerMinute  int           // Sustained uploads per minute of the bucket
+	Burst      int           // Bucket capacity
+	RetryAfter time.Duration // Time until the next token
+}
+
+func (e *UploadRateLimitError) Error() string {
+	return fmt.Sprintf("%s for %s %s (%d/min, burst %d), retry in %s",
+		ErrUploadRateLimited, e.Scope, e.Key, e.PerMinute, e.Burst, e.RetryAfter.Round(time.Second))
+}
+
+func (e *UploadRateLimitError) Unwrap() error {
+	return ErrUploadRateLimited
+}
+
+// RetryAfterSeconds returns RetryAfter rounded up to whole seconds (at least 1)
+func (e *UploadRateLimitError) RetryAfterSeconds() int {
+	secs := int(math.Ceil(e.RetryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// rateBucket one token bucket checked by a take
+type rateBucket struct {
+	scope     string
+	key       string
+	perMinute int
+	burst     int
+}
+
+// perMs returns the bucket refill rate in tokens per millisecond
+func (b rateBucket) perMs() float64 {
+	return float64(b.perMinute) / 60000
+}
+
+// bucketState tokens left in a bucket at the last take
+type bucketState struct {
+	tokens float64
+	at     time.Time
+}
+
+// memoryRateLimiter token buckets of a single uploader instance
+type memoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]bucketState
+	lastGC  time.Time
+}
+
+func newMemoryRateLimiter() *memoryRateLimiter {
+	return &memoryRateLimiter{buckets: make(map[string]bucketState)}
+}
+
+// take consumes one token from every bucket, or none when any bucket is
+// empty; it then returns the empty bucket with the longest wait
+func (l *memoryRateLimiter) take(now time.Time, buckets []rateBucket) *UploadRateLimitError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.gc(now)
+
+	tokens := make([]float64, len(buckets))
+	var limited *UploadRateLimitError
+	for i, b := range buckets {
+		tokens[i] = float64(b.burst)
+		if state, ok := l.buckets[b.scope+":"+b.key]; ok {
+			tokens[i] = math.Min(float64(b.burst), state.tokens+float64(now.Sub(state.at).Milliseconds())*b.perMs())
+		}
+		if tokens[i] < 1 {
+			wait := time.Duration(math.Ceil((1-tokens[i])/b.perMs())) * time.Millisecond
+			if limited == nil || wait > limited.RetryAfter {
+				limited = &UploadRateLimitError{Scope: b.scope, Key: b.key, PerMinute: b.perMinute, Burst: b.burst, RetryAfter: wait}
+			}
+		}
+	}
+	if limited != nil {
+		return limited
+	}
+	for i, b := range buckets {
+		l.buckets[b.scope+":"+b.key] = bucketState{tokens: tokens[i] - 1, at: now}
+	}
+	return nil
+}
+
+// gc drops buckets idle for an hour (refilled long before) every few minutes
+func (l *memoryRateLimiter) gc(now time.Time) {
+	if now.Sub(l.lastGC) < 5*time.Minute {
+		return
+	}
+	l.lastGC = now
+	for key, state := range l.buckets {
+		if now.Sub(state.at) > time.Hour {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// redisTakeScript takes one token from every bucket in KEYS atomically.
+// ARGV: now (ms), then rate (tokens/ms) and burst per key. Returns {0, 0}
+// when allowed, or {index of the limiting key (1-based), wait in ms}.
+var redisTakeScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local tokens = {}
+local limited, wait = 0, 0
+for i, key in ipairs(KEYS) do
+  local rate = tonumber(ARGV[i * 2])
+  local burst = tonumber(ARGV[i * 2 + 1])
+  local state = redis.call('HMGET', key, 'tokens', 'at')
+  local t = tonumber(state[1]) or burst
+  local at = tonumber(state[2]) or now
+  if now > at then
+    t = math.min(burst, t + (now - at) * rate)
+  end
+  tokens[i] = t
+  if t < 1 then
+    local w = math.ceil((1 - t) / rate)
+    if w > wait then
+      limited, wait = i, w
+    end
+  end
+end
+if limited > 0 then
+  return {limited, wait}
+end
+for i, key in ipairs(KEYS) do
+  local rate = tonumber(ARGV[i * 2])
+  local burst = tonumber(ARGV[i * 2 + 1])
+  redis.call('HSET', key, 'tokens', tostring(tokens[i] - 1), 'at', tostring(now))
+  redis.call('PEXPIRE', key, math.ceil(burst / rate) + 1000)
+end
+return {0, 0}
+`)
+
+// redisTake runs take against buckets shared by all uploader instances
+func redisTake(now time.Time, buckets []rateBucket) (*UploadRateLimitError, error) {
+	keys := make([]string, len(buckets))
+	args := []interface{}{now.UnixMilli()}
+	for i, b := range buckets {
+		keys[i] = uploadRateLimitKeyPrefix + b.scope + ":" + b.key
+		args = append(args, b.perMs(), b.burst)
+	}
+	res, err := redisTakeScript.Run(context.Background(), database.RedisClient, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 2 || res[0] < 0 || res[0] > int64(len(buckets)) {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+	if res[0] == 0 {
+		return nil, nil
+	}
+	b := buckets[res[0]-1]
+	return &UploadRateLimitError{Scope: b.scope, Key: b.key, PerMinute: b.perMinute, Burst: b.burst, RetryAfter: time.Duration(res[1]) * time.Millisecond}, nil
+}
+
+// uploadRateBuckets returns the buckets an upload by address from clientIP
+// draws from; empty keys and disabled limits are skipped
+func uploadRateBuckets(cfg conf.UploadRateLimitConfig, address, clientIP string) []rateBucket {
+	var buckets []rateBucket
+	if address != "" && cfg.AddressPerMinute > 0 {
+		buckets = append(buckets, rateBucket{scope: RateLimitScopeAddress, key: address, perMinute: cfg.AddressPerMinute, burst: cfg.AddressBurst})
+	}
+	if clientIP != "" && cfg.IpPerMinute > 0 {
+		buckets = append(buckets, rateBucket{scope: RateLimitScopeIP, key: clientIP, perMinute: cfg.IpPerMinute, burst: cfg.IpBurst})
+	}
+	return buckets
+}
+
+// CheckUploadRateLimit takes one upload from the address and client IP token
+// buckets (uploader.rate_limit). Buckets live in Redis when it is enabled, so
+// limits hold across uploader instances, and in memory otherwise or while
+// Redis fails. Returns an *UploadRateLimitError wrapping ErrUploadRateLimited
+// when either bucket is empty; nothing is taken from the other one then.
+func (s *UploadService) CheckUploadRateLimit(address, clientIP string) error {
+	if conf.Cfg == nil || !conf.Cfg.Uploader.RateLimit.Enabled {
+		return nil
+	}
+	buckets := uploadRateBuckets(conf.Cfg.Uploader.RateLimit, address, clientIP)
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if database.IsRedisEnabled() {
+		limited, err := redisTake(now, buckets)
+		if err == nil {
+			if limited != nil {
+				return limited
+			}
+			return nil
+		}
+		log.Printf("⚠️  Redis upload rate limit failed, using in-memory buckets: %v", err)
+	}
+	if limited := s.uploadRateLimiter().take(now, buckets); limited != nil {
+		return limited
+	}
+	return nil
+}
+
+// uploadRateLimiter returns the service's in-memory upload limiter, creating it on first use
+func (s *UploadService) uploadRateLimiter() *memoryRateLimiter {
+	s.rateLimitOnce.Do(func() { s.rateLimiter = newMemoryRateLimiter() })
+	return s.rateLimiter
+}
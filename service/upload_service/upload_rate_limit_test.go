@@ -0,0 +1,81 @@
+package upload_service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+func TestMemoryRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cfg := conf.UploadRateLimitConfig{AddressPerMinute: 6, AddressBurst: 2, IpPerMinute: 60, IpBurst: 3}
+	tests := []struct {
+		name      string
+		offset    time.Duration
+		address   string
+		ip        string
+		wantScope string // empty = allowed
+	}{
+		{"burst 1", 0, "a", "1", ""},
+		{"burst 2", 0, "a", "1", ""},
+		{"address bucket empty", 0, "a", "2", RateLimitScopeAddress},
+		{"other address same ip", 0, "b", "1", ""},
+		{"ip bucket empty", 0, "c", "1", RateLimitScopeIP},
+		{"ip refilled after a second", time.Second, "c", "1", ""},
+		{"address refills every 10s", 10 * time.Second, "a", "3", ""},
+		{"address empty again", 10 * time.Second, "a", "4", RateLimitScopeAddress},
+	}
+	l := newMemoryRateLimiter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited := l.take(now.Add(tt.offset), uploadRateBuckets(cfg, tt.address, tt.ip))
+			if tt.wantScope == "" {
+				if limited != nil {
+					t.Fatalf("take limited: %v", limited)
+				}
+				return
+			}
+			if limited == nil || limited.Scope != tt.wantScope {
+				t.Fatalf("take = %v, want %s limit", limited, tt.wantScope)
+			}
+			if limited.RetryAfter <= 0 || limited.RetryAfterSeconds() < 1 {
+				t.Errorf("RetryAfter = %s, want positive", limited.RetryAfter)
+			}
+		})
+	}
+
+	// A rejected take leaves the other bucket untouched: ip 4 still has its burst
+	for i := 0; i < cfg.IpBurst; i++ {
+		if limited := l.take(now.Add(10*time.Second), uploadRateBuckets(cfg, "", "4")); limited != nil {
+			t.Fatalf("take %d for ip 4 limited: %v", i, limited)
+		}
+	}
+}
+
+func TestCheckUploadRateLimit(t *testing.T) {
+	prev := conf.Cfg
+	t.Cleanup(func() { conf.Cfg = prev })
+	conf.Cfg = &conf.Config{}
+
+	s := &UploadService{}
+	for i := 0; i < 5; i++ {
+		if err := s.CheckUploadRateLimit("addr", "10.0.0.1"); err != nil {
+			t.Fatalf("disabled limit rejected upload: %v", err)
+		}
+	}
+
+	conf.Cfg.Uploader.RateLimit = conf.UploadRateLimitConfig{Enabled: true, AddressPerMinute: 1, AddressBurst: 1, IpPerMinute: -1}
+	if err := s.CheckUploadRateLimit("addr", "10.0.0.1"); err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	err := s.CheckUploadRateLimit("addr", "10.0.0.1")
+	var limited *UploadRateLimitError
+	if !errors.Is(err, ErrUploadRateLimited) || !errors.As(err, &limited) || limited.Key != "addr" {
+		t.Fatalf("second upload err = %v, want address rate limit", err)
+	}
+	if err := s.CheckUploadRateLimit("", "10.0.0.1"); err != nil {
+		t.Errorf("upload without address or IP limit: %v", err)
+	}
+}
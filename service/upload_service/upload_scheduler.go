@@ -0,0 +1,233 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+	"meta-file-system/node"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUploadTaskNotFound no task with that ID for the address
+	ErrUploadTaskNotFound = errors.New("upload task not found")
+	// ErrUploadTaskNotCancellable the task is already broadcasting or finished
+	ErrUploadTaskNotCancellable = errors.New("upload task can no longer be cancelled")
+	// ErrInvalidUploadSchedule broadcastAt / targetFeeRate out of range
+	ErrInvalidUploadSchedule = errors.New("invalid upload schedule")
+)
+
+// errTaskScheduled stops a task driver after the build stage while its
+// broadcast is held for the scheduler
+var errTaskScheduled = errors.New("upload task broadcast is scheduled")
+
+// validateUploadSchedule checks broadcastAt / targetFeeRate of an async upload
+// request; a fee target without a time gets the latest allowed time as its
+// deadline, so no task is held forever.
+func validateUploadSchedule(req *ChunkedUploadRequest, now time.Time) error {
+	if req.BroadcastAt == nil && req.TargetFeeRate == 0 {
+		return nil
+	}
+	latest := now.Add(time.Duration(conf.Cfg.Uploader.Schedule.MaxDelayHours) * time.Hour)
+	if req.BroadcastAt != nil {
+		if !req.BroadcastAt.After(now) {
+			return fmt.Errorf("%w: broadcastAt must be in the future", ErrInvalidUploadSchedule)
+		}
+		if req.BroadcastAt.After(latest) {
+			return fmt.Errorf("%w: broadcastAt must be within %d hours", ErrInvalidUploadSchedule, conf.Cfg.Uploader.Schedule.MaxDelayHours)
+		}
+	}
+	if req.TargetFeeRate < 0 {
+		return fmt.Errorf("%w: targetFeeRate must not be negative", ErrInvalidUploadSchedule)
+	}
+	if req.TargetFeeRate > req.FeeRate {
+		return fmt.Errorf("%w: targetFeeRate %d is above the task fee rate %d", ErrInvalidUploadSchedule, req.TargetFeeRate, req.FeeRate)
+	}
+	if req.BroadcastAt == nil {
+		req.BroadcastAt = &latest
+	}
+	return nil
+}
+
+// broadcastHeld reports whether task waits for the scheduler before broadcasting
+func broadcastHeld(task *model.FileUploaderTask) bool {
+	return (task.BroadcastAt != nil || task.TargetFeeRate > 0) && task.ReleasedAt == nil
+}
+
+// CancelUploadTask cancels a task of address before any of its transactions
+// has been broadcast (pending, or scheduled with its broadcast held).
+func (s *UploadService) CancelUploadTask(taskId, address string) (*model.FileUploaderTask, error) {
+	if taskId == "" || address == "" {
+		return nil, fmt.Errorf("task ID and address are required")
+	}
+	task, err := s.fileUploaderTaskDAO.GetByTaskID(taskId)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && task.Address != address) {
+		return nil, ErrUploadTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	cancelled, err := s.fileUploaderTaskDAO.Cancel(taskId, address, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel task: %w", err)
+	}
+	if !cancelled {
+		return nil, fmt.Errorf("%w: status %s, stage %s", ErrUploadTaskNotCancellable, task.Status, task.Stage)
+	}
+	log.Printf("Upload task cancelled: taskId=%s", taskId)
+	s.releaseUtxoLocks(uploadLockOwner(task))
+	return s.fileUploaderTaskDAO.GetByTaskID(taskId)
+}
+
+// networkFeeRate returns the node fee estimate of chain in the task fee rate
+// unit (MVC sat/byte, DOGE sat/KB), rounded up
+func networkFeeRate(chain string) (int64, error) {
+	rpcChain := chain
+	if chain == "" || chain == "mvc" {
+		rpcChain = conf.Cfg.Net
+	}
+	perKB, err := node.EstimateFeePerKB(rpcChain, conf.Cfg.Uploader.Schedule.FeeEstimateBlocks)
+	if err != nil {
+		return 0, err
+	}
+	return feeRateFromCoinsPerKB(chain, perKB), nil
+}
+
+// feeRateFromCoinsPerKB converts a node estimate (coins per KB) to the task fee rate unit
+func feeRateFromCoinsPerKB(chain string, perKB float64) int64 {
+	satPerKB := math.Round(perKB * 1e8) // whole satoshis, free of float noise
+	if chain == "doge" {
+		return int64(satPerKB)
+	}
+	return int64(math.Ceil(satPerKB / 1000))
+}
+
+// scheduleDecision what the scheduler does with a held task
+type scheduleDecision int
+
+const (
+	scheduleWait    scheduleDecision = iota // 继续等待
+	scheduleRelease                         // 放行广播
+	scheduleFail                            // 费率不足，任务失败
+)
+
+// decideSchedule 根据时间和网络费率决定挂起任务的去向。
+// networkRate <= 0 表示无法获取费率估算：只按时间放行，且不做费率校验。
+func decideSchedule(task *model.FileUploaderTask, now time.Time, networkRate int64) (scheduleDecision, string) {
+	timeDue := task.BroadcastAt != nil && !now.Before(*task.BroadcastAt)
+	feeDue := task.TargetFeeRate > 0 && networkRate > 0 && networkRate <= task.TargetFeeRate
+	if !timeDue && !feeDue {
+		return scheduleWait, ""
+	}
+	if networkRate > task.FeeRate {
+		return scheduleFail, fmt.Sprintf("network fee rate %d is above the task fee rate %d at broadcast time", networkRate, task.FeeRate)
+	}
+	if feeDue {
+		return scheduleRelease, fmt.Sprintf("Released: network fee rate %d reached target %d", networkRate, task.TargetFeeRate)
+	}
+	return scheduleRelease, "Released: scheduled broadcast time reached"
+}
+
+// BroadcastScheduler 定时广播调度器：放行到期或费率达标的挂起任务
+type BroadcastScheduler struct {
+	taskDAO   *dao.FileUploaderTaskDAO
+	lockDAO   *dao.UtxoLockDAO
+	stopChan  chan struct{}
+	done      chan struct{} // Closed when run returns
+	interval  time.Duration
+	batchSize int
+}
+
+// NewBroadcastScheduler 创建定时广播调度器
+func NewBroadcastScheduler() *BroadcastScheduler {
+	return &BroadcastScheduler{
+		taskDAO:   dao.NewFileUploaderTaskDAO(),
+		lockDAO:   dao.NewUtxoLockDAO(),
+		stopChan:  make(chan struct{}),
+		done:      make(chan struct{}),
+		interval:  time.Duration(conf.Cfg.Uploader.Schedule.IntervalSeconds) * time.Second,
+		batchSize: 50, // 每次最多检查50个挂起任务
+	}
+}
+
+// Start 启动调度器
+func (bs *BroadcastScheduler) Start() {
+	log.Println("Broadcast scheduler started")
+	go bs.run()
+}
+
+// Stop 停止调度器
+func (bs *BroadcastScheduler) Stop() {
+	log.Println("Stopping broadcast scheduler...")
+	close(bs.stopChan)
+}
+
+// run 调度器主循环
+func (bs *BroadcastScheduler) run() {
+	defer close(bs.done)
+	ticker := time.NewTicker(bs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-bs.stopChan:
+			log.Println("Broadcast scheduler stopped")
+			return
+		case <-ticker.C:
+			bs.releaseDueTasks()
+		}
+	}
+}
+
+// releaseDueTasks 检查挂起任务，放行到期的任务交给任务处理器广播
+func (bs *BroadcastScheduler) releaseDueTasks() {
+	tasks, err := bs.taskDAO.GetScheduledTasks(bs.batchSize)
+	if err != nil {
+		log.Printf("Failed to get scheduled tasks: %v", err)
+		return
+	}
+	if len(tasks) == 0 {
+		return
+	}
+
+	// 每条链每轮只查询一次费率
+	rates := make(map[string]int64)
+	now := time.Now()
+	for _, task := range tasks {
+		rate, ok := rates[task.Chain]
+		if !ok {
+			if rate, err = networkFeeRate(task.Chain); err != nil {
+				log.Printf("Failed to estimate %s fee rate, releasing scheduled tasks by time only: %v", task.Chain, err)
+				rate = 0
+			}
+			rates[task.Chain] = rate
+		}
+
+		decision, message := decideSchedule(task, now, rate)
+		switch decision {
+		case scheduleRelease:
+			released, err := bs.taskDAO.ReleaseScheduledTask(task.ID, now, message)
+			if err != nil {
+				log.Printf("Failed to release scheduled task %s: %v", task.TaskId, err)
+			} else if released {
+				log.Printf("Scheduled task released: taskId=%s, %s", task.TaskId, message)
+			}
+		case scheduleFail:
+			if failed, err := bs.taskDAO.FailScheduledTask(task.ID, now, message); err != nil {
+				log.Printf("Failed to fail scheduled task %s: %v", task.TaskId, err)
+			} else {
+				if failed {
+					releaseInputLocks(bs.lockDAO, uploadLockOwner(task))
+				}
+				log.Printf("Scheduled task failed fee re-validation: taskId=%s, %s", task.TaskId, message)
+			}
+		}
+	}
+}
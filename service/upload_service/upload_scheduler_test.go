@@ -0,0 +1,104 @@
+package upload_service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"meta-file-system/conf"
+	"meta-file-system/model"
+)
+
+func TestValidateUploadSchedule(t *testing.T) {
+	oldCfg := conf.Cfg
+	defer func() { conf.Cfg = oldCfg }()
+	conf.Cfg = &conf.Config{Uploader: conf.UploaderConfig{Schedule: conf.UploadScheduleConfig{MaxDelayHours: 24}}}
+
+	now := time.Now()
+	at := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+
+	tests := []struct {
+		name string
+		req  ChunkedUploadRequest
+		ok   bool
+	}{
+		{"not scheduled", ChunkedUploadRequest{FeeRate: 5}, true},
+		{"in an hour", ChunkedUploadRequest{FeeRate: 5, BroadcastAt: at(time.Hour)}, true},
+		{"fee target", ChunkedUploadRequest{FeeRate: 5, TargetFeeRate: 2}, true},
+		{"in the past", ChunkedUploadRequest{FeeRate: 5, BroadcastAt: at(-time.Minute)}, false},
+		{"beyond max delay", ChunkedUploadRequest{FeeRate: 5, BroadcastAt: at(25 * time.Hour)}, false},
+		{"target above fee rate", ChunkedUploadRequest{FeeRate: 5, TargetFeeRate: 6}, false},
+		{"negative target", ChunkedUploadRequest{FeeRate: 5, TargetFeeRate: -1}, false},
+	}
+	for _, tt := range tests {
+		err := validateUploadSchedule(&tt.req, now)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidUploadSchedule) {
+			t.Errorf("%s: err = %v, want ErrInvalidUploadSchedule", tt.name, err)
+		}
+	}
+
+	// A fee target alone is bounded by the latest allowed time
+	req := ChunkedUploadRequest{FeeRate: 5, TargetFeeRate: 2}
+	if err := validateUploadSchedule(&req, now); err != nil || req.BroadcastAt == nil || !req.BroadcastAt.Equal(now.Add(24*time.Hour)) {
+		t.Errorf("fee target deadline = %v, %v", req.BroadcastAt, err)
+	}
+}
+
+func TestDecideSchedule(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		task        model.FileUploaderTask
+		networkRate int64
+		want        scheduleDecision
+	}{
+		{"not due", model.FileUploaderTask{FeeRate: 5, BroadcastAt: &future}, 3, scheduleWait},
+		{"time due", model.FileUploaderTask{FeeRate: 5, BroadcastAt: &past}, 3, scheduleRelease},
+		{"time due, no estimate", model.FileUploaderTask{FeeRate: 5, BroadcastAt: &past}, 0, scheduleRelease},
+		{"time due, fee too high", model.FileUploaderTask{FeeRate: 5, BroadcastAt: &past}, 8, scheduleFail},
+		{"fee target reached", model.FileUploaderTask{FeeRate: 5, TargetFeeRate: 2, BroadcastAt: &future}, 2, scheduleRelease},
+		{"fee target missed", model.FileUploaderTask{FeeRate: 5, TargetFeeRate: 2, BroadcastAt: &future}, 3, scheduleWait},
+		{"fee target, no estimate", model.FileUploaderTask{FeeRate: 5, TargetFeeRate: 2, BroadcastAt: &future}, 0, scheduleWait},
+	}
+	for _, tt := range tests {
+		if got, _ := decideSchedule(&tt.task, now, tt.networkRate); got != tt.want {
+			t.Errorf("%s: decision = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBroadcastHeld(t *testing.T) {
+	now := time.Now()
+	if broadcastHeld(&model.FileUploaderTask{}) {
+		t.Error("unscheduled task is held")
+	}
+	if !broadcastHeld(&model.FileUploaderTask{BroadcastAt: &now}) || !broadcastHeld(&model.FileUploaderTask{TargetFeeRate: 1}) {
+		t.Error("scheduled task is not held")
+	}
+	if broadcastHeld(&model.FileUploaderTask{BroadcastAt: &now, ReleasedAt: &now}) {
+		t.Error("released task is still held")
+	}
+}
+
+func TestFeeRateFromCoinsPerKB(t *testing.T) {
+	for _, tt := range []struct {
+		chain string
+		perKB float64
+		want  int64
+	}{
+		{"mvc", 0.00001, 1},     // 1000 sat/KB = 1 sat/byte
+		{"mvc", 0.000005, 1},    // 0.5 sat/byte rounds up
+		{"mvc", 0.00002001, 3},  // 2.001 sat/byte rounds up
+		{"doge", 0.01, 1000000}, // sat/KB
+		{"doge", 0.005, 500000},
+	} {
+		if got := feeRateFromCoinsPerKB(tt.chain, tt.perKB); got != tt.want {
+			t.Errorf("feeRateFromCoinsPerKB(%s, %v) = %d, want %d", tt.chain, tt.perKB, got, tt.want)
+		}
+	}
+}
@@ -7,9 +7,12 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	bsvec2 "github.com/bitcoinsv/bsvd/bsvec"
@@ -27,12 +30,15 @@ import (
 	"gorm.io/gorm"
 
 	"meta-file-system/common"
+	"meta-file-system/common/scripts"
 	"meta-file-system/conf"
 	"meta-file-system/database"
 	"meta-file-system/indexer"
 	"meta-file-system/model"
 	"meta-file-system/model/dao"
 	"meta-file-system/node"
+	"meta-file-system/service/common_service/contenttype"
+	"meta-file-system/service/common_service/metaid"
 	"meta-file-system/service/common_service/metaid_protocols"
 	"meta-file-system/storage"
 )
@@ -44,7 +50,32 @@ type UploadService struct {
 	fileAssistentDAO    *dao.FileAssistentDAO
 	fileUploaderTaskDAO *dao.FileUploaderTaskDAO
 	multipartUploadDAO  *dao.MultipartUploadDAO
+	uploadCheckpointDAO *dao.UploadCheckpointDAO
+	proofDAO            *dao.ProofDAO
+	assistentUtxoDAO    *dao.AssistentUtxoDAO
+	delegatedChargeDAO  *dao.DelegatedChargeDAO
+	idempotencyDAO      *dao.UploadIdempotencyDAO
+	rotationDAO         *dao.AssistentRotationDAO
+	hotWalletUtxoDAO    *dao.HotWalletUtxoDAO
+	utxoLockDAO         *dao.UtxoLockDAO
+	retentionDAO        *dao.FileRetentionDAO
 	storage             storage.Storage
+
+	hotWalletMu sync.Mutex // Serializes picking hot wallet outputs within the process
+	keepLocks   sync.Map   // Owner address -> *sync.Mutex serializing keep quota checks and saves
+
+	faucetOnce sync.Once
+	faucet     *faucetLimiter
+
+	rateLimitOnce sync.Once
+	rateLimiter   *memoryRateLimiter
+
+	batcherOnce sync.Once
+	batcher     *uploadBatcher
+
+	noMempoolAccept sync.Map // RPC chains whose node lacks testmempoolaccept
+
+	stopping atomic.Bool // Set on shutdown; running tasks stop before their next stage
 }
 
 // NewUploadService create upload service instance
@@ -55,6 +86,15 @@ func NewUploadService(storage storage.Storage) *UploadService {
 		fileAssistentDAO:    dao.NewFileAssistentDAO(),
 		fileUploaderTaskDAO: dao.NewFileUploaderTaskDAO(),
 		multipartUploadDAO:  dao.NewMultipartUploadDAO(),
+		uploadCheckpointDAO: dao.NewUploadCheckpointDAO(),
+		proofDAO:            dao.NewProofDAO(),
+		assistentUtxoDAO:    dao.NewAssistentUtxoDAO(),
+		delegatedChargeDAO:  dao.NewDelegatedChargeDAO(),
+		idempotencyDAO:      dao.NewUploadIdempotencyDAO(),
+		rotationDAO:         dao.NewAssistentRotationDAO(),
+		hotWalletUtxoDAO:    dao.NewHotWalletUtxoDAO(),
+		utxoLockDAO:         dao.NewUtxoLockDAO(),
+		retentionDAO:        dao.NewFileRetentionDAO(),
 		storage:             storage,
 	}
 }
@@ -73,6 +113,9 @@ type UploadRequest struct {
 	Outputs       []*common.TxOutput    // Outputs
 	OtherOutputs  []*common.TxOutput    // Other outputs
 	FeeRate       int64                 // Fee rate
+	Gzip          *bool                 // Gzip content before inscription when it saves enough (nil = uploader.gzip.enabled)
+	StorageClass  string                // Storage class hint: hot/cold/ephemeral (default hot)
+	ContentSHA256 string                // SHA256 of Content declared by the client (hex, optional); checked before anything is built
 }
 
 // DirectUploadRequest direct upload request (one-step upload with PreTxHex)
@@ -89,8 +132,17 @@ type DirectUploadRequest struct {
 	ChangeAddress    string // Change address (optional, defaults to Address)
 	FeeRate          int64  // Fee rate (satoshis per byte, optional, defaults to config)
 	TotalInputAmount int64  // Total input amount in satoshis (optional, for change calculation)
+	DryRun           bool   // Build and return the transaction without broadcasting or saving anything
+	Gzip             *bool  // Gzip content before inscription when it saves enough (nil = uploader.gzip.enabled)
+	StorageClass     string // Storage class hint: hot/cold/ephemeral (default hot)
+	ChunkedFallback  bool   // Route the upload through ChunkedUpload when the payload exceeds the single-PIN limit
+	IndexPreTxHex    string // Index pre-transaction hex, required by ChunkedFallback (PreTxHex then funds the chunks)
+	Batch            bool   // Consent to share one transaction with other small uploads of the same fee rate (uploader.batch)
 }
 
+// statusDryRun status returned by uploads built with DryRun
+const statusDryRun = "dry_run"
+
 const minFeeRate int64 = 5
 
 func normalizeFeeRate(f int64) int64 {
@@ -100,6 +152,29 @@ func normalizeFeeRate(f int64) int64 {
 	return f
 }
 
+// normalizeRequestPath validates a request's MetaID path against the path
+// grammar and rewrites it to its canonical form before it is inscribed.
+func normalizeRequestPath(path *string) error {
+	if strings.TrimSpace(*path) == "" {
+		return fmt.Errorf("file path is required")
+	}
+	normalized, err := metaid_protocols.NormalizeUploadPath(*path)
+	if err != nil {
+		return fmt.Errorf("invalid file path: %w", err)
+	}
+	*path = normalized
+	return nil
+}
+
+// normalizeRequestMetaId fills in a missing MetaID from the user address (per
+// metaid.derivation), so the upload is filed under the MetaID the indexer
+// assigns to its PIN.
+func normalizeRequestMetaId(metaId *string, address string) {
+	if strings.TrimSpace(*metaId) == "" {
+		*metaId = metaid.Derive(address)
+	}
+}
+
 // PreUploadResponse pre-upload response
 type PreUploadResponse struct {
 	FileId    string `json:"fileId"`    // File ID (unique identifier)
@@ -112,15 +187,27 @@ type PreUploadResponse struct {
 	Message   string `json:"message"`   // Message (e.g., exists, success, etc.)
 	CalTxFee  int64  `json:"calTxFee"`  // Calculated transaction fee
 	CalTxSize int64  `json:"calTxSize"` // Calculated transaction size
+
+	GzipCompressed bool  `json:"gzipCompressed,omitempty"` // Content is inscribed gzip compressed
+	SavedFee       int64 `json:"savedFee,omitempty"`       // Fee saved by compression (satoshis)
 }
 
 // UploadResponse upload response
 type UploadResponse struct {
-	FileId  string `json:"fileId"`  // File ID
-	Status  string `json:"status"`  // Status
-	TxId    string `json:"txId"`    // Transaction ID
-	PinId   string `json:"pinId"`   // Pin ID
-	Message string `json:"message"` // Message
+	FileId  string `json:"fileId"`           // File ID
+	Status  string `json:"status"`           // Status
+	TxId    string `json:"txId"`             // Transaction ID
+	PinId   string `json:"pinId"`            // Pin ID
+	Message string `json:"message"`          // Message
+	TxHex   string `json:"txHex,omitempty"`  // Final transaction hex (dry run only)
+	DryRun  bool   `json:"dryRun,omitempty"` // Nothing was broadcast or saved
+
+	GzipCompressed bool  `json:"gzipCompressed,omitempty"` // Content was inscribed gzip compressed
+	SavedFee       int64 `json:"savedFee,omitempty"`       // Fee saved by compression (satoshis)
+
+	Chunked *ChunkedUploadResponse `json:"chunked,omitempty"` // Chunked upload result when a direct upload fell back to chunks (TxId/PinId are the index's)
+
+	BatchSize int `json:"batchSize,omitempty"` // Uploads sharing the transaction (batched direct upload only)
 }
 
 // PreUpload pre-upload: build transaction and save file metadata
@@ -129,17 +216,22 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 	if len(req.Content) == 0 {
 		return nil, fmt.Errorf("file content is empty")
 	}
-	if req.Path == "" {
-		return nil, fmt.Errorf("file path is required")
+	if err := verifyContentSHA256(req.ContentSHA256, req.Content); err != nil {
+		return nil, err
+	}
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	normalizeRequestMetaId(&req.MetaId, req.Address)
+	if err := normalizeStorageClass(&req.StorageClass); err != nil {
+		return nil, err
 	}
 
 	// Set default values
 	if req.Operation == "" {
 		req.Operation = "create"
 	}
-	if req.ContentType == "" {
-		req.ContentType = "application/octet-stream"
-	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
 	if req.FeeRate == 0 {
 		req.FeeRate = conf.Cfg.Uploader.FeeRate
 	}
@@ -153,6 +245,14 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		netParam = &chaincfg2.TestNet3Params
 	}
 
+	payload, compressed, err := gzipForInscription(req.Content, uploadGzipEnabled(req.Gzip), uploadGzipMinSavingPercent())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSinglePayload("mvc", len(payload)); err != nil {
+		return nil, err
+	}
+
 	// Build transaction
 	tx, err := common.BuildMvcCommonMetaIdTxForUnkwonInput(
 		netParam,
@@ -161,7 +261,7 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		req.OtherOutputs,
 		req.Operation,
 		req.Path,
-		req.Content,
+		payload,
 		req.ContentType,
 		req.ChangeAddress,
 		req.FeeRate,
@@ -173,6 +273,11 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 
 	txSize := tx.SerializeSize()
 	txFee := int64(txSize) * req.FeeRate
+	savedFee := inscriptionSavedFee(&scripts.MetaId{
+		Operation:   req.Operation,
+		Path:        req.Path,
+		ContentType: req.ContentType,
+	}, len(req.Content), len(payload), req.FeeRate)
 
 	// Get transaction ID and raw transaction
 	// txID := tx.Txhash().String()
@@ -227,7 +332,7 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 	file := &model.File{
 		FileId:          fileId,
 		FileName:        req.FileName,
-		FileType:        strings.ReplaceAll(req.ContentType, ";binary", ""),
+		FileType:        contenttype.Normalize(req.ContentType),
 		MetaId:          req.MetaId,
 		Address:         req.Address,
 		Path:            req.Path,
@@ -235,11 +340,13 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		FileSize:        int64(len(req.Content)),
 		FileHash:        filehashStr,
 		FileMd5:         md5hashStr,
-		FileContentType: strings.ReplaceAll(req.ContentType, ";binary", ""),
+		FileContentType: contenttype.Normalize(req.ContentType),
 		ChunkType:       model.ChunkTypeSingle,
 		Operation:       req.Operation,
 		// PreTxRaw:        preTxRaw,
-		Status: model.StatusPending, // Set status to pending
+		IsGzipCompressed: compressed,
+		StorageClass:     model.StorageClass(req.StorageClass),
+		Status:           model.StatusPending, // Set status to pending
 	}
 
 	if err := s.fileDAO.Create(file); err != nil {
@@ -259,12 +366,26 @@ func (s *UploadService) PreUpload(req *UploadRequest) (*PreUploadResponse, error
 		CalTxFee:  txFee,
 		CalTxSize: int64(txSize),
 		Message:   "success",
+
+		GzipCompressed: compressed,
+		SavedFee:       savedFee,
 	}, nil
 }
 
 // CommitUpload commit upload: broadcast transaction and update file status
 // Use database transaction to ensure data consistency
 func (s *UploadService) CommitUpload(fileId string, signedRawTx string) (*UploadResponse, error) {
+	// A rejected transaction leaves the file pending so it can be re-signed
+	if err := s.preflightTransactions("mvc", []preflightTx{{"upload", signedRawTx}}); err != nil {
+		return nil, err
+	}
+
+	// Inputs are reserved until the transaction is broadcast
+	lockOwner := uploadLockOwner(nil)
+	if err := s.lockUploadInputs(lockOwner, "mvc", nil, signedRawTx); err != nil {
+		return nil, err
+	}
+	defer s.releaseUtxoLocks(lockOwner)
 
 	var (
 		txId   string
@@ -332,8 +453,12 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	if len(req.Content) == 0 {
 		return nil, fmt.Errorf("file content is empty")
 	}
-	if req.Path == "" {
-		return nil, fmt.Errorf("file path is required")
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	normalizeRequestMetaId(&req.MetaId, req.Address)
+	if err := normalizeStorageClass(&req.StorageClass); err != nil {
+		return nil, err
 	}
 	// if req.MergeTxHex == "" {
 	// 	return nil, fmt.Errorf("MergeTxHex is required")
@@ -349,9 +474,7 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	if req.Operation == "" {
 		req.Operation = "create"
 	}
-	if req.ContentType == "" {
-		req.ContentType = "application/octet-stream"
-	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
 	if req.ChangeAddress == "" && req.Address != "" {
 		req.ChangeAddress = req.Address
 	}
@@ -386,31 +509,29 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 		outAmount += out.Value
 	}
 
-	// Build MetaID OP_RETURN output
-	inscriptionBuilder := txscript.NewScriptBuilder().
-		AddOp(txscript.OP_0).
-		AddOp(txscript.OP_RETURN).
-		AddData([]byte("metaid")).       // <metaid_flag>
-		AddData([]byte(req.Operation)).  // <operation>
-		AddData([]byte(req.Path)).       // <path>
-		AddData([]byte("0")).            // <Encryption>
-		AddData([]byte("1.0.0")).        // <version>
-		AddData([]byte(req.ContentType)) // <content-type>
-
-	// Split content into chunks (max 520 bytes per chunk)
-	maxChunkSize := 520
-	bodySize := len(req.Content)
-	for i := 0; i < bodySize; i += maxChunkSize {
-		end := i + maxChunkSize
-		if end > bodySize {
-			end = bodySize
+	payload, compressed, err := gzipForInscription(req.Content, uploadGzipEnabled(req.Gzip), uploadGzipMinSavingPercent())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSinglePayload("mvc", len(payload)); err != nil {
+		if req.ChunkedFallback {
+			return s.directUploadViaChunks(req, err)
 		}
-		inscriptionBuilder.AddFullData(req.Content[i:end]) // <payload>
+		return nil, err
 	}
 
-	inscriptionScript, err := inscriptionBuilder.Script()
-	if err != nil {
-		return nil, fmt.Errorf("failed to build inscription script: %w", err)
+	// Build MetaID OP_RETURN output
+	inscription := &scripts.MetaId{
+		Operation:   req.Operation,
+		Path:        req.Path,
+		ContentType: req.ContentType,
+		Payload:     payload,
+	}
+	inscriptionScript := scripts.MetaIdOpReturn(inscription)
+	savedFee := inscriptionSavedFee(inscription, len(req.Content), len(payload), req.FeeRate)
+
+	if req.Batch && !req.DryRun {
+		return s.directUploadBatched(req, tx, inscriptionScript, len(payload), compressed, savedFee)
 	}
 
 	// Add MetaID OP_RETURN output to transaction
@@ -418,11 +539,7 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 
 	// Add change output if change address and total input amount are provided
 	if req.ChangeAddress != "" && req.TotalInputAmount > 0 {
-		addr, err := bsvutil2.DecodeAddress(req.ChangeAddress, netParam)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode change address: %w", err)
-		}
-		pkScriptByte, err := txscript2.PayToAddrScript(addr)
+		pkScriptByte, err := scripts.PayToMvcAddress(req.ChangeAddress, netParam)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create change script: %w", err)
 		}
@@ -443,14 +560,15 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 
 		// Calculate change value
 		changeVal := req.TotalInputAmount - outAmount - txFee
-		if changeVal >= 600 {
+		minChange := conf.GetUploaderChainPolicy("mvc").MinChange
+		if changeVal >= minChange {
 			// Set change output value
 			tx.TxOut[len(tx.TxOut)-1].Value = changeVal
 			log.Printf("DirectUpload: change output added with value=%d", changeVal)
 		} else {
 			// Remove change output if change is too small
 			tx.TxOut = tx.TxOut[:len(tx.TxOut)-1]
-			log.Printf("DirectUpload: change output removed (changeVal=%d < 600)", changeVal)
+			log.Printf("DirectUpload: change output removed (changeVal=%d < %d)", changeVal, minChange)
 		}
 	}
 
@@ -463,6 +581,14 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	// Get transaction hash
 	txhash := common.GetMvcTxhashFromRaw(signedRawTx)
 
+	preflight := []preflightTx{{"upload", signedRawTx}}
+	if req.MergeTxHex != "" {
+		preflight = append([]preflightTx{{"merge", req.MergeTxHex}}, preflight...)
+	}
+	if err := s.preflightTransactions("mvc", preflight); err != nil {
+		return nil, err
+	}
+
 	// Calculate file hash
 	sha256hash := sha256.Sum256(req.Content)
 	md5hash := md5.Sum(req.Content)
@@ -472,6 +598,29 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 	// Generate FileId (ensure uniqueness)
 	fileId := req.MetaId + "_" + filehashStr
 
+	if req.DryRun {
+		log.Printf("DirectUpload dry run: fileId=%s, txId=%s, txSize=%d", fileId, txhash, tx.SerializeSize())
+		return &UploadResponse{
+			FileId:  fileId,
+			Status:  statusDryRun,
+			TxId:    txhash,
+			PinId:   fmt.Sprintf("%si0", txhash),
+			Message: "dry run: transaction built, not broadcast or saved",
+			TxHex:   signedRawTx,
+			DryRun:  true,
+
+			GzipCompressed: compressed,
+			SavedFee:       savedFee,
+		}, nil
+	}
+
+	// Inputs are reserved until the transactions are broadcast
+	lockOwner := uploadLockOwner(nil)
+	if err := s.lockUploadInputs(lockOwner, "mvc", nil, req.MergeTxHex, signedRawTx); err != nil {
+		return nil, err
+	}
+	defer s.releaseUtxoLocks(lockOwner)
+
 	var (
 		finalTxId string
 		pinId     string
@@ -539,22 +688,24 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 
 		// File does not exist, create new record
 		file := &model.File{
-			FileId:          fileId,
-			FileName:        req.FileName,
-			FileType:        strings.ReplaceAll(req.ContentType, ";binary", ""),
-			MetaId:          req.MetaId,
-			Address:         req.Address,
-			Path:            req.Path,
-			ContentType:     req.ContentType,
-			FileSize:        int64(len(req.Content)),
-			FileHash:        filehashStr,
-			FileMd5:         md5hashStr,
-			FileContentType: strings.ReplaceAll(req.ContentType, ";binary", ""),
-			ChunkType:       model.ChunkTypeSingle,
-			Operation:       req.Operation,
-			TxID:            txhash,
-			PinId:           fmt.Sprintf("%si0", txhash),
-			Status:          model.StatusSuccess,
+			FileId:           fileId,
+			FileName:         req.FileName,
+			FileType:         contenttype.Normalize(req.ContentType),
+			MetaId:           req.MetaId,
+			Address:          req.Address,
+			Path:             req.Path,
+			ContentType:      req.ContentType,
+			FileSize:         int64(len(req.Content)),
+			FileHash:         filehashStr,
+			FileMd5:          md5hashStr,
+			FileContentType:  contenttype.Normalize(req.ContentType),
+			ChunkType:        model.ChunkTypeSingle,
+			IsGzipCompressed: compressed,
+			StorageClass:     model.StorageClass(req.StorageClass),
+			Operation:        req.Operation,
+			TxID:             txhash,
+			PinId:            fmt.Sprintf("%si0", txhash),
+			Status:           model.StatusSuccess,
 		}
 
 		if err := dbTx.Create(file).Error; err != nil {
@@ -599,17 +750,24 @@ func (s *UploadService) DirectUpload(req *DirectUploadRequest) (*UploadResponse,
 		TxId:    finalTxId,
 		PinId:   pinId,
 		Message: "success",
+
+		GzipCompressed: compressed,
+		SavedFee:       savedFee,
 	}, nil
 }
 
 // EstimateChunkedUploadRequest describes the payload used to estimate chunked upload fees.
 type EstimateChunkedUploadRequest struct {
-	FileName    string // File name
-	Content     []byte // File content
-	Path        string // Base MetaID path (will append /file/_chunk and /file/index)
-	ContentType string // MIME type (e.g. image/jpeg, text/plain)
-	Chain       string // Blockchain: mvc or doge (default mvc), used for per-chain fee_rate/chunk_size
-	FeeRate     int64  // Fee rate (optional, defaults to chain config)
+	FileName       string                               // File name
+	Content        []byte                               // File content
+	Path           string                               // MetaID path; chunks and index go to {base}/file/_chunk and {base}/file/index (see chunkedUploadPaths)
+	ContentType    string                               // MIME type (e.g. image/jpeg, text/plain)
+	Chain          string                               // Blockchain: mvc or doge (default mvc), used for per-chain fee_rate/chunk_size
+	FeeRate        int64                                // Fee rate (optional, defaults to chain config)
+	Compression    string                               // Compression declared in the index: none or gzip (default none)
+	Encryption     *metaid_protocols.MetaFileEncryption // Encryption envelope declared in the index (content is already ciphertext)
+	DeltaBasePinId string                               // Inscribe only the delta of Content against this PIN's file (uploader.delta)
+	Delta          *metaid_protocols.MetaFileDelta      // Delta envelope, set once Content was replaced by the delta
 }
 
 // EstimateChunkedUploadResponse contains fee estimation details for chunked upload.
@@ -623,25 +781,42 @@ type EstimateChunkedUploadResponse struct {
 	IndexPreTxFee int64   `json:"indexPreTxFee"` // Funding required for the index transaction
 	TotalFee      int64   `json:"totalFee"`      // Total fee (ChunkPreTxFee + IndexPreTxFee)
 	PerChunkFee   int64   `json:"perChunkFee"`   // Average fee per chunk
+	DustLimit     int64   `json:"dustLimit"`     // Smallest output/funding value used (satoshis)
+	MinChange     int64   `json:"minChange"`     // Change below this is left to the fee (satoshis)
 	Message       string  `json:"message"`       // Additional message
+
+	Delta     *metaid_protocols.MetaFileDelta `json:"delta,omitempty"`     // Delta uploads: the base and the rebuilt file
+	DeltaSize int64                           `json:"deltaSize,omitempty"` // Delta uploads: bytes inscribed instead of the file
 }
 
 // ChunkedUploadRequest describes a chunked upload payload.
 type ChunkedUploadRequest struct {
-	MetaId        string                  // MetaID
-	Address       string                  // User address
-	FileName      string                  // File name
-	Content       []byte                  // File content
-	Path          string                  // Base MetaID path (auto appends /file/_chunk and /file/index)
-	Operation     string                  // create/update
-	ContentType   string                  // MIME type (e.g. image/jpeg, text/plain)
-	Chain         string                  // Blockchain: mvc or doge (default mvc)
-	ChunkPreTxHex string                  // Pre-built chunk funding transaction (contains inputs, signNull)
-	IndexPreTxHex string                  // Pre-built index transaction (contains inputs, signNull)
-	MergeTxHex    string                  // Optional merge transaction hex (creates two UTXOs, broadcast first)
-	FeeRate       int64                   // Fee rate
-	IsBroadcast   bool                    // Whether to broadcast automatically
-	Task          *model.FileUploaderTask `json:"-"` // Associated async task (not exposed externally)
+	MetaId         string                               // MetaID
+	Address        string                               // User address
+	FileName       string                               // File name
+	Content        []byte                               // File content
+	Path           string                               // MetaID path; chunks and index go to {base}/file/_chunk and {base}/file/index (see chunkedUploadPaths)
+	Operation      string                               // create/update
+	ContentType    string                               // MIME type (e.g. image/jpeg, text/plain)
+	Chain          string                               // Blockchain: mvc or doge (default mvc)
+	ChunkPreTxHex  string                               // Pre-built chunk funding transaction (contains inputs, signNull)
+	IndexPreTxHex  string                               // Pre-built index transaction (contains inputs, signNull)
+	MergeTxHex     string                               // Optional merge transaction hex (creates two UTXOs, broadcast first)
+	FeeRate        int64                                // Fee rate
+	IsBroadcast    bool                                 // Whether to broadcast automatically
+	DryRun         bool                                 // Build and return all transactions without broadcasting or saving anything
+	StorageClass   string                               // Storage class hint: hot/cold/ephemeral (default hot)
+	Compression    string                               // Compression declared in the index: none or gzip (default none)
+	Encryption     *metaid_protocols.MetaFileEncryption // Encryption envelope declared in the index (content is already ciphertext)
+	DeltaBasePinId string                               // Inscribe only the delta of Content against this PIN's file (uploader.delta)
+	Delta          *metaid_protocols.MetaFileDelta      // Delta envelope, set once Content was replaced by the delta
+	BroadcastAt    *time.Time                           // Async task only: build now, broadcast at this time
+	TargetFeeRate  int64                                // Async task only: broadcast earlier once the network fee rate is at or below this
+	ContentSHA256  string                               // SHA256 of Content declared by the client (hex, optional); checked before anything is built
+	Task           *model.FileUploaderTask              `json:"-"` // Associated async task (not exposed externally)
+
+	FundingStrategy string // Chunk funding: assistent or hot_wallet (empty = uploader.chunk_funding.strategy)
+	preFunded       bool   // Chunks funded by ChunkPreTxHex whatever the strategy (delegated and direct fallback uploads)
 }
 
 // EstimateChunkedUpload estimates fees for chunked upload.
@@ -650,14 +825,18 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 	if len(req.Content) == 0 {
 		return nil, fmt.Errorf("file content is empty")
 	}
-	if req.Path == "" {
-		return nil, fmt.Errorf("file path is required")
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	if err := normalizeMetaFileEncoding(&req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	if err := s.prepareDeltaUpload(req.Chain, req.DeltaBasePinId, &req.Delta, &req.Content, req.Compression, req.Encryption); err != nil {
+		return nil, err
 	}
 
 	// Apply defaults
-	if req.ContentType == "" {
-		req.ContentType = "application/octet-stream"
-	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
 	chain := req.Chain
 	if chain == "" {
 		chain = "mvc"
@@ -670,19 +849,20 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 	if chunkSize <= 0 {
 		chunkSize = 2000 * 1024 // default 2000 KB
 	}
+	policy := conf.GetUploaderChainPolicy(chain)
 
 	// Split file
 	chunks := splitFile(req.Content, chunkSize)
 	chunkNumber := len(chunks)
 
-	// Build chunk path
-	chunkPath := fmt.Sprintf("%s/file/_chunk", req.Path)
-	if !strings.HasPrefix(chunkPath, "/") {
-		chunkPath = "/" + chunkPath
+	// Build chunk and index paths
+	chunkPath, indexPath, err := chunkedUploadPaths(req.Path)
+	if err != nil {
+		return nil, err
 	}
 
 	if chain == "doge" {
-		return s.estimateChunkedUploadDoge(req, chunks, chunkSize, chunkNumber, chunkPath, feeRate)
+		return s.estimateChunkedUploadDoge(req, chunks, chunkSize, chunkNumber, chunkPath, indexPath, feeRate, policy)
 	}
 
 	// MVC path
@@ -697,7 +877,7 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build chunk script for estimation: %w", err)
 		}
-		chunkFee := estimateChunkFundingValue(chunkScript, feeRate)
+		chunkFee := estimateChunkFundingValue(chunkScript, feeRate, policy.DustLimit)
 		chunkFees = append(chunkFees, chunkFee)
 		totalChunkFee += chunkFee
 	}
@@ -709,27 +889,8 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 
 	// Estimate fee for chunk funding transaction
 	// chunkFundingTx contains 1 input + chunkNumber outputs
-	const chunkFundingInputSize = 148  // P2PKH input with signature
-	const chunkFundingOutputSize = 34  // P2PKH output
-	estimatedChunkFundingTxSize := 4 + // version
-		1 + // input count (varint)
-		chunkFundingInputSize + // input
-		1 + // output count (varint)
-		chunkFundingOutputSize*chunkNumber + // outputs
-		4 // locktime
-	chunkFundingTxFee := int64(estimatedChunkFundingTxSize) * feeRate
-	if chunkFundingTxFee < 600 {
-		chunkFundingTxFee = 600
-	}
-
 	// ChunkPreTxFee must cover every chunk output + chunkFundingTx fee
-	chunkPreTxFee := totalChunkFee + chunkFundingTxFee
-
-	// Build index path
-	indexPath := fmt.Sprintf("%s/file/index", req.Path)
-	if !strings.HasPrefix(indexPath, "/") {
-		indexPath = "/" + indexPath
-	}
+	chunkPreTxFee := totalChunkFee + chunkFundingTxFee(chunkNumber, feeRate, policy.DustLimit)
 
 	// Estimate index fee
 	// Build index payload first to know its size
@@ -737,29 +898,24 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 	filehashStr := hex.EncodeToString(sha256hash[:])
 
 	// Build chunk list using placeholder PinIDs
-	chunkList := make([]struct {
-		Sha256 string `json:"sha256"`
-		PinId  string `json:"pinId"`
-	}, 0, chunkNumber)
+	chunkList := make([]metaid_protocols.MetaFileChunk, 0, chunkNumber)
 	for i, chunkData := range chunks {
 		chunkHash := sha256.Sum256(chunkData)
 		chunkHashStr := hex.EncodeToString(chunkHash[:])
-		chunkList = append(chunkList, struct {
-			Sha256 string `json:"sha256"`
-			PinId  string `json:"pinId"`
-		}{
-			Sha256: chunkHashStr,
-			PinId:  fmt.Sprintf("placeholder_%d", i), // placeholder
-		})
+		chunkList = metaid_protocols.AppendMetaFileChunk(chunkList, chunkHashStr, fmt.Sprintf("placeholder_%d", i), int64(len(chunkData)))
 	}
 
 	metaFileIndex := metaid_protocols.MetaFileIndex{
+		Version:     metaid_protocols.MetaFileIndexV2,
 		Sha256:      filehashStr,
 		FileSize:    int64(len(req.Content)),
 		ChunkNumber: chunkNumber,
 		ChunkSize:   chunkSize,
 		DataType:    req.ContentType,
 		Name:        req.FileName,
+		Compression: req.Compression,
+		Encryption:  req.Encryption,
+		Delta:       req.Delta,
 		ChunkList:   chunkList,
 	}
 
@@ -782,8 +938,8 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 	// Assume there is 1 input (provided by user)
 	indexTxSize := 4 + 1 + indexInputSize + 1 + indexOutputSize + indexOpReturnSize + 4
 	indexFee := int64(indexTxSize) * feeRate
-	if indexFee < 600 {
-		indexFee = 600
+	if indexFee < policy.DustLimit {
+		indexFee = policy.DustLimit
 	}
 
 	totalFee := chunkPreTxFee + indexFee
@@ -797,7 +953,11 @@ func (s *UploadService) EstimateChunkedUpload(req *EstimateChunkedUploadRequest)
 		IndexPreTxFee: indexFee,
 		TotalFee:      totalFee,
 		PerChunkFee:   perChunkFee,
+		DustLimit:     policy.DustLimit,
+		MinChange:     policy.MinChange,
 		Message:       "success",
+		Delta:         req.Delta,
+		DeltaSize:     deltaSize(req.Delta, req.Content),
 	}, nil
 }
 
@@ -809,7 +969,9 @@ func (s *UploadService) estimateChunkedUploadDoge(
 	chunkSize int64,
 	chunkNumber int,
 	chunkPath string,
+	indexPath string,
 	feeRate int64,
+	policy conf.UploaderChainPolicy,
 ) (*EstimateChunkedUploadResponse, error) {
 	chunkContentType := metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary"
 	totalChunkFee := int64(0)
@@ -836,46 +998,30 @@ func (s *UploadService) estimateChunkedUploadDoge(
 	if feeRatePerByte < 1 {
 		feeRatePerByte = 1
 	}
-	const chunkFundingInputSize = 148
-	const chunkFundingOutputSize = 34
-	estimatedChunkFundingTxSize := 4 + 1 + chunkFundingInputSize + 1 + chunkFundingOutputSize*chunkNumber + 4
-	chunkFundingTxFee := int64(estimatedChunkFundingTxSize) * feeRatePerByte
-	if chunkFundingTxFee < 600 {
-		chunkFundingTxFee = 600
-	}
-	chunkPreTxFee := totalChunkFee + chunkFundingTxFee
+	chunkPreTxFee := totalChunkFee + chunkFundingTxFee(chunkNumber, feeRatePerByte, policy.DustLimit)
 
 	// Index: BuildDogeMetaIdInscriptionTxs same structure
-	indexPath := fmt.Sprintf("%s/file/index", req.Path)
-	if !strings.HasPrefix(indexPath, "/") {
-		indexPath = "/" + indexPath
-	}
 	indexContentType := metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8"
 
 	sha256hash := sha256.Sum256(req.Content)
 	filehashStr := hex.EncodeToString(sha256hash[:])
-	chunkList := make([]struct {
-		Sha256 string `json:"sha256"`
-		PinId  string `json:"pinId"`
-	}, 0, chunkNumber)
+	chunkList := make([]metaid_protocols.MetaFileChunk, 0, chunkNumber)
 	for i, chunkData := range chunks {
 		chunkHash := sha256.Sum256(chunkData)
 		chunkHashStr := hex.EncodeToString(chunkHash[:])
-		chunkList = append(chunkList, struct {
-			Sha256 string `json:"sha256"`
-			PinId  string `json:"pinId"`
-		}{
-			Sha256: chunkHashStr,
-			PinId:  fmt.Sprintf("placeholder_%d", i),
-		})
+		chunkList = metaid_protocols.AppendMetaFileChunk(chunkList, chunkHashStr, fmt.Sprintf("placeholder_%d", i), int64(len(chunkData)))
 	}
 	metaFileIndex := metaid_protocols.MetaFileIndex{
+		Version:     metaid_protocols.MetaFileIndexV2,
 		Sha256:      filehashStr,
 		FileSize:    int64(len(req.Content)),
 		ChunkNumber: chunkNumber,
 		ChunkSize:   chunkSize,
 		DataType:    req.ContentType,
 		Name:        req.FileName,
+		Compression: req.Compression,
+		Encryption:  req.Encryption,
+		Delta:       req.Delta,
 		ChunkList:   chunkList,
 	}
 	indexData, err := json.Marshal(metaFileIndex)
@@ -901,25 +1047,32 @@ func (s *UploadService) estimateChunkedUploadDoge(
 		IndexPreTxFee: indexFee,
 		TotalFee:      totalFee,
 		PerChunkFee:   perChunkFee,
+		DustLimit:     policy.DustLimit,
+		MinChange:     policy.MinChange,
 		Message:       "success",
+		Delta:         req.Delta,
+		DeltaSize:     deltaSize(req.Delta, req.Content),
 	}, nil
 }
 
 // ChunkedUploadResponse represents the result of a chunked upload build.
 type ChunkedUploadResponse struct {
-	FileId           string   `json:"fileId"`           // File ID
-	FileHash         string   `json:"fileHash"`         // File SHA256 hash
-	FileMd5          string   `json:"fileMd5"`          // File MD5 hash
-	ChunkNumber      int      `json:"chunkNumber"`      // Number of chunks
-	ChunkFundingTx   string   `json:"chunkFundingTx"`   // Funding transaction for chunk outputs
-	ChunkTxs         []string `json:"chunkTxs"`         // Chunk transaction hex list (ordered)
-	ChunkTxIds       []string `json:"chunkTxIds"`       // Chunk transaction IDs (flat, for broadcast)
-	ChunkRevealTxIds []string `json:"chunkRevealTxIds"` // DOGE: reveal tx id per chunk for index
-	IndexTx          string   `json:"indexTx"`          // Index transaction hex (MVC single tx)
-	IndexTxs         []string `json:"indexTxs"`         // DOGE: index txs [commitHex, revealHex]
-	IndexTxId        string   `json:"indexTxId"`        // Index transaction ID
-	Status           string   `json:"status"`           // Status string
-	Message          string   `json:"message"`          // Additional message
+	FileId           string   `json:"fileId"`                // File ID
+	FileHash         string   `json:"fileHash"`              // File SHA256 hash
+	FileMd5          string   `json:"fileMd5"`               // File MD5 hash
+	ChunkNumber      int      `json:"chunkNumber"`           // Number of chunks
+	ChunkFundingTx   string   `json:"chunkFundingTx"`        // Funding transaction for chunk outputs
+	ChunkTxs         []string `json:"chunkTxs"`              // Chunk transaction hex list (ordered)
+	ChunkTxIds       []string `json:"chunkTxIds"`            // Chunk transaction IDs (flat, for broadcast)
+	ChunkRevealTxIds []string `json:"chunkRevealTxIds"`      // DOGE: reveal tx id per chunk for index
+	IndexTx          string   `json:"indexTx"`               // Index transaction hex (MVC single tx)
+	IndexTxs         []string `json:"indexTxs"`              // DOGE: index txs [commitHex, revealHex]
+	IndexTxId        string   `json:"indexTxId"`             // Index transaction ID
+	ChunkPinIds      []string `json:"chunkPinIds,omitempty"` // Chunk PinIDs (dry run only)
+	IndexPinId       string   `json:"indexPinId,omitempty"`  // Index PinID (dry run only)
+	DryRun           bool     `json:"dryRun,omitempty"`      // Nothing was broadcast or saved
+	Status           string   `json:"status"`                // Status string
+	Message          string   `json:"message"`               // Additional message
 }
 
 // ChunkedUpload splits a large file, builds chunk and index transactions, and optionally broadcasts them.
@@ -928,13 +1081,45 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	if len(req.Content) == 0 {
 		return nil, fmt.Errorf("file content is empty")
 	}
-	if req.Path == "" {
-		return nil, fmt.Errorf("file path is required")
+	// Content already replaced by its delta was checked by the caller
+	if req.Delta == nil {
+		if err := verifyContentSHA256(req.ContentSHA256, req.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	normalizeRequestMetaId(&req.MetaId, req.Address)
+	if err := normalizeStorageClass(&req.StorageClass); err != nil {
+		return nil, err
+	}
+	if err := normalizeMetaFileEncoding(&req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	if err := s.prepareDeltaUpload(req.Chain, req.DeltaBasePinId, &req.Delta, &req.Content, req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	chunkPath, indexPath, err := chunkedUploadPaths(req.Path)
+	if err != nil {
+		return nil, err
 	}
 	if req.Address == "" {
 		return nil, fmt.Errorf("user address is required")
 	}
-	if req.ChunkPreTxHex == "" {
+	fundingStrategy := ChunkFundingAssistent
+	if !req.preFunded {
+		if fundingStrategy, err = resolveChunkFundingStrategy(req.FundingStrategy); err != nil {
+			return nil, err
+		}
+	}
+	if fundingStrategy == ChunkFundingHotWallet {
+		// Outputs locked for an upload the uploader does not broadcast would
+		// never be marked spent
+		if !req.IsBroadcast && !req.DryRun && req.Task == nil {
+			return nil, fmt.Errorf("%w: hot_wallet funding needs isBroadcast or an upload task", ErrInvalidFundingStrategy)
+		}
+	} else if req.ChunkPreTxHex == "" {
 		return nil, fmt.Errorf("chunk pre-tx hex is required")
 	}
 	if req.IndexPreTxHex == "" {
@@ -945,9 +1130,7 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	if req.Operation == "" {
 		req.Operation = "create"
 	}
-	if req.ContentType == "" {
-		req.ContentType = "application/octet-stream"
-	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
 	chain := req.Chain
 	if chain == "" {
 		chain = "mvc"
@@ -973,20 +1156,48 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 		chunkSize = 2000 * 1024
 	}
 
-	chunkFundingTx, err := decodeMvcTx(req.ChunkPreTxHex)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode chunk pre-tx: %w", err)
-	}
-
 	indexBaseTx, err := decodeMvcTx(req.IndexPreTxHex)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode index pre-tx: %w", err)
 	}
 
-	// Obtain or create assistant address
-	assistent, err := s.getOrCreateFileAssistent(req.MetaId, req.Address, netParam)
-	if err != nil {
-		return nil, fmt.Errorf("failed to prepare assistent address: %w", err)
+	// Inputs of the pre-transactions are reserved until the upload is
+	// broadcast or fails. A task keeps them until ProcessUploadTask is done,
+	// transactions handed back to the client until the locks expire.
+	keepInputLocks := req.Task != nil
+	if !req.DryRun {
+		lockOwner := uploadLockOwner(req.Task)
+		if err := s.lockUploadInputs(lockOwner, chain, req.Task, req.MergeTxHex, req.ChunkPreTxHex, req.IndexPreTxHex); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if !keepInputLocks {
+				s.releaseUtxoLocks(lockOwner)
+			}
+		}()
+	}
+
+	// Chunk outputs pay the hot wallet, or an assistant address of the user
+	var fundingPkScript []byte
+	var fundingPriHex string
+	var wallet *hotWallet
+	var assistent *model.FileAssistent
+	if fundingStrategy == ChunkFundingHotWallet {
+		if wallet, err = loadHotWallet(netParam); err != nil {
+			return nil, err
+		}
+		fundingPkScript, fundingPriHex = wallet.pkScript, wallet.priHex
+	} else {
+		// Obtain or create assistant address
+		assistent, err = s.getOrCreateFileAssistent(req.MetaId, req.Address, netParam, req.DryRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare assistent address: %w", err)
+		}
+		fundingPkScript, err = scripts.PayToMvcAddress(assistent.AssistentAddress, netParam)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build assistent pkScript: %w", err)
+		}
+		fundingPriHex = assistent.AssistentPriHex
 	}
 
 	// Calculate file hashes
@@ -1005,32 +1216,16 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	log.Printf("File split into %d chunks, file size: %d bytes", chunkNumber, len(req.Content))
 	s.updateUploadTaskProgress(req.Task, fmt.Sprintf("File split completed, %d chunks total", chunkNumber), 30, 0)
 
-	assistentAddress, err := bsvutil2.DecodeAddress(assistent.AssistentAddress, netParam)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode assistent address: %w", err)
-	}
-	assistentPkScript, err := txscript2.PayToAddrScript(assistentAddress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build assistent pkScript: %w", err)
-	}
-
 	chunkScripts := make([][]byte, 0, chunkNumber)
 	chunkInputs := make([]*common.TxInputUtxo, 0, chunkNumber)
 
 	// Prepare chunk transactions
 	chunkTxs := make([]string, 0, chunkNumber)
 	chunkTxIds := make([]string, 0, chunkNumber)
-	chunkList := make([]struct {
-		Sha256 string `json:"sha256"`
-		PinId  string `json:"pinId"`
-	}, 0, chunkNumber)
+	chunkList := make([]metaid_protocols.MetaFileChunk, 0, chunkNumber)
 
 	// Build a transaction for each chunk
-	chunkPath := "/file/_chunk"
-	// chunkPath := fmt.Sprintf("%s/file/_chunk", req.Path)
-	// if !strings.HasPrefix(chunkPath, "/") {
-	// 	chunkPath = "/" + chunkPath
-	// }
+	policy := conf.GetUploaderChainPolicy("mvc")
 
 	// Calculate scripts and required amounts for all chunks
 	totalChunkOutputAmount := int64(0)
@@ -1041,80 +1236,107 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 			return nil, fmt.Errorf("failed to build chunk script: %w", err)
 		}
 		chunkScripts = append(chunkScripts, chunkScript)
-		chunkAmount := estimateChunkFundingValue(chunkScript, req.FeeRate)
+		chunkAmount := estimateChunkFundingValue(chunkScript, req.FeeRate, policy.DustLimit)
 		chunkAmounts = append(chunkAmounts, chunkAmount)
 		totalChunkOutputAmount += chunkAmount
 	}
 
-	// Estimate chunkFundingTx fee (same logic as estimation)
-	const inputSize = 148              // P2PKH input with signature
-	const outputSize = 34              // P2PKH output
-	estimatedChunkFundingTxSize := 4 + // version
-		1 + // input count (varint)
-		inputSize + // input
-		1 + // output count (varint)
-		outputSize*chunkNumber + // outputs
-		4 // locktime
-	chunkFundingTxFee := int64(estimatedChunkFundingTxSize) * req.FeeRate
-	if chunkFundingTxFee < 600 {
-		chunkFundingTxFee = 600
-	}
+	// The chunk funding transaction has one output per chunk from firstVout
+	var chunkFundingTx *wire2.MsgTx
+	firstVout := 0
+	if fundingStrategy == ChunkFundingHotWallet {
+		chunkFundingTx, err = s.fundChunksFromHotWallet(wallet, chunkAmounts, req.FeeRate, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		chunkFundingTx, err = decodeMvcTx(req.ChunkPreTxHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode chunk pre-tx: %w", err)
+		}
 
-	// Try to fetch funding amount from merge transaction if provided
-	var totalInputAmount int64 = 0
-	if req.MergeTxHex != "" {
-		mergeTx, err := decodeMvcTx(req.MergeTxHex)
-		if err == nil {
-			// Required amount = totalChunkOutputAmount + chunkFundingTxFee
-			requiredAmount := totalChunkOutputAmount + chunkFundingTxFee
-			// Find an output that matches the required amount
-			for i, output := range mergeTx.TxOut {
-				outputAmount := int64(output.Value)
-				// Allow a tolerance of 1000 satoshis
-				if outputAmount >= requiredAmount-1000 && outputAmount <= requiredAmount+1000 {
-					totalInputAmount = outputAmount
-					log.Printf("Found chunkPreTx output at index %d: %d satoshis (required: %d)", i, outputAmount, requiredAmount)
-					break
+		// Estimate chunkFundingTx fee (same logic as estimation)
+		const inputSize = 148              // P2PKH input with signature
+		const outputSize = 34              // P2PKH output
+		estimatedChunkFundingTxSize := 4 + // version
+			1 + // input count (varint)
+			inputSize + // input
+			1 + // output count (varint)
+			outputSize*chunkNumber + // outputs
+			4 // locktime
+		chunkFundingTxFee := int64(estimatedChunkFundingTxSize) * req.FeeRate
+		if chunkFundingTxFee < policy.DustLimit {
+			chunkFundingTxFee = policy.DustLimit
+		}
+
+		// Try to fetch funding amount from merge transaction if provided
+		var totalInputAmount int64 = 0
+		if req.MergeTxHex != "" {
+			mergeTx, err := decodeMvcTx(req.MergeTxHex)
+			if err == nil {
+				// Required amount = totalChunkOutputAmount + chunkFundingTxFee
+				requiredAmount := totalChunkOutputAmount + chunkFundingTxFee
+				// Find an output that matches the required amount
+				for i, output := range mergeTx.TxOut {
+					outputAmount := int64(output.Value)
+					// Allow a tolerance of 1000 satoshis
+					if outputAmount >= requiredAmount-1000 && outputAmount <= requiredAmount+1000 {
+						totalInputAmount = outputAmount
+						log.Printf("Found chunkPreTx output at index %d: %d satoshis (required: %d)", i, outputAmount, requiredAmount)
+						break
+					}
 				}
 			}
 		}
-	}
 
-	// If merge tx not provided or not sufficient, fall back to estimated amount
-	if totalInputAmount == 0 {
-		totalInputAmount = totalChunkOutputAmount + chunkFundingTxFee
-		log.Printf("Using estimated totalInputAmount: %d satoshis (chunkOutputs: %d + fee: %d)",
-			totalInputAmount, totalChunkOutputAmount, chunkFundingTxFee)
-	}
+		// If merge tx not provided or not sufficient, fall back to estimated amount
+		if totalInputAmount == 0 {
+			totalInputAmount = totalChunkOutputAmount + chunkFundingTxFee
+			log.Printf("Using estimated totalInputAmount: %d satoshis (chunkOutputs: %d + fee: %d)",
+				totalInputAmount, totalChunkOutputAmount, chunkFundingTxFee)
+		}
 
-	// Validate available amount
-	availableAmount := totalInputAmount - chunkFundingTxFee
-	if availableAmount < totalChunkOutputAmount {
-		return nil, fmt.Errorf("insufficient input amount: need %d satoshis (outputs: %d + fee: %d), but only have %d satoshis available",
-			totalChunkOutputAmount+chunkFundingTxFee, totalChunkOutputAmount, chunkFundingTxFee, availableAmount)
-	}
+		// Validate available amount
+		availableAmount := totalInputAmount - chunkFundingTxFee
+		if availableAmount < totalChunkOutputAmount {
+			return nil, fmt.Errorf("insufficient input amount: need %d satoshis (outputs: %d + fee: %d), but only have %d satoshis available",
+				totalChunkOutputAmount+chunkFundingTxFee, totalChunkOutputAmount, chunkFundingTxFee, availableAmount)
+		}
 
-	// Add outputs (use original amount, leftover becomes dust)
-	for _, chunkAmount := range chunkAmounts {
-		chunkFundingTx.AddTxOut(wire2.NewTxOut(chunkAmount, assistentPkScript))
+		// Add outputs (use original amount, leftover becomes dust)
+		firstVout = len(chunkFundingTx.TxOut)
+		for _, chunkAmount := range chunkAmounts {
+			chunkFundingTx.AddTxOut(wire2.NewTxOut(chunkAmount, fundingPkScript))
+		}
+
+		log.Printf("ChunkFundingTx: input=%d, fee=%d, outputs=%d (total=%d), remaining=%d",
+			totalInputAmount, chunkFundingTxFee, totalChunkOutputAmount, totalChunkOutputAmount, availableAmount-totalChunkOutputAmount)
+	}
 
+	for i, chunkAmount := range chunkAmounts {
 		chunkInputs = append(chunkInputs, &common.TxInputUtxo{
 			TxId:     "", // filled later
-			TxIndex:  int64(len(chunkFundingTx.TxOut) - 1),
-			PkScript: hex.EncodeToString(assistentPkScript),
+			TxIndex:  int64(firstVout + i),
+			PkScript: hex.EncodeToString(fundingPkScript),
 			Amount:   uint64(chunkAmount),
-			PriHex:   assistent.AssistentPriHex,
+			PriHex:   fundingPriHex,
 		})
 	}
 
-	log.Printf("ChunkFundingTx: input=%d, fee=%d, outputs=%d (total=%d), remaining=%d",
-		totalInputAmount, chunkFundingTxFee, totalChunkOutputAmount, totalChunkOutputAmount, availableAmount-totalChunkOutputAmount)
-
 	chunkFundingTxHex, err := common.MvcToRaw(chunkFundingTx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize chunk funding tx: %w", err)
 	}
 	chunkFundingTxHash := common.GetMvcTxhashFromRaw(chunkFundingTxHex)
+	// Hot wallet outputs locked for an upload that goes no further are freed
+	handedOff := false
+	if fundingStrategy == ChunkFundingHotWallet && !req.DryRun {
+		defer func() {
+			if !handedOff {
+				s.releaseHotWalletFunding(chunkFundingTxHex)
+			}
+		}()
+	}
 
 	for i := range chunkInputs {
 		chunkInputs[i].TxId = chunkFundingTxHash
@@ -1146,50 +1368,47 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 
 		chunkTxs = append(chunkTxs, chunkTxHex)
 		chunkTxIds = append(chunkTxIds, chunkTxId)
-		chunkList = append(chunkList, struct {
-			Sha256 string `json:"sha256"`
-			PinId  string `json:"pinId"`
-		}{
-			Sha256: chunkHashStr,
-			PinId:  chunkPinId,
-		})
+		chunkList = metaid_protocols.AppendMetaFileChunk(chunkList, chunkHashStr, chunkPinId, int64(len(chunkData)))
 
 		// Calculate chunk MD5
 		chunkMd5Hash := md5.Sum(chunkData)
 		chunkMd5Str := hex.EncodeToString(chunkMd5Hash[:])
 
-		// Check if chunk already exists before creating
-		existingChunk, err := s.fileChunkDAO.GetByTxID(chunkTxId)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			log.Printf("Failed to check existing chunk %d: %v", i, err)
-			// Continue processing other chunks even if check fails
-		} else if existingChunk != nil {
-			log.Printf("File chunk %d already exists: hash=%s, txId=%s, skipping create", i, chunkHashStr, chunkTxId)
-		} else {
-			// Persist chunk metadata
-			fileChunk := &model.FileChunk{
-				ChunkHash:   chunkHashStr,
-				ChunkSize:   int64(len(chunkData)),
-				ChunkMd5:    chunkMd5Str,
-				ChunkIndex:  int64(i),
-				FileHash:    filehashStr,
-				TxID:        chunkTxId,
-				PinId:       chunkPinId,
-				Path:        chunkPath,
-				ContentType: metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary",
-				Size:        int64(len(chunkData)),
-				StorageType: conf.Cfg.Storage.Type,
-				StoragePath: "", // Optional: set if persisting chunk content externally
-				Operation:   req.Operation,
-				// TxRaw:       chunkTxHex,
-				Status: model.StatusPending,
-			}
-
-			if err := s.fileChunkDAO.Create(fileChunk); err != nil {
-				log.Printf("Failed to save file chunk %d: %v", i, err)
-				// Continue processing other chunks even if persistence fails
+		// Persist chunk metadata (skipped in a dry run)
+		if !req.DryRun {
+			// Check if chunk already exists before creating
+			existingChunk, err := s.fileChunkDAO.GetByTxID(chunkTxId)
+			if err != nil && err != gorm.ErrRecordNotFound {
+				log.Printf("Failed to check existing chunk %d: %v", i, err)
+				// Continue processing other chunks even if check fails
+			} else if existingChunk != nil {
+				log.Printf("File chunk %d already exists: hash=%s, txId=%s, skipping create", i, chunkHashStr, chunkTxId)
 			} else {
-				log.Printf("File chunk %d saved: hash=%s, txId=%s", i, chunkHashStr, chunkTxId)
+				// Persist chunk metadata
+				fileChunk := &model.FileChunk{
+					ChunkHash:   chunkHashStr,
+					ChunkSize:   int64(len(chunkData)),
+					ChunkMd5:    chunkMd5Str,
+					ChunkIndex:  int64(i),
+					FileHash:    filehashStr,
+					TxID:        chunkTxId,
+					PinId:       chunkPinId,
+					Path:        chunkPath,
+					ContentType: metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary",
+					Size:        int64(len(chunkData)),
+					StorageType: conf.Cfg.Storage.Type,
+					StoragePath: "", // Optional: set if persisting chunk content externally
+					Operation:   req.Operation,
+					// TxRaw:       chunkTxHex,
+					Status: model.StatusPending,
+				}
+
+				if err := s.fileChunkDAO.Create(fileChunk); err != nil {
+					log.Printf("Failed to save file chunk %d: %v", i, err)
+					// Continue processing other chunks even if persistence fails
+				} else {
+					log.Printf("File chunk %d saved: hash=%s, txId=%s", i, chunkHashStr, chunkTxId)
+				}
 			}
 		}
 
@@ -1204,12 +1423,16 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	// Build index metadata payload
 	s.updateUploadTaskProgress(req.Task, "Chunk transactions built, preparing index", 75, len(chunkTxIds))
 	metaFileIndex := metaid_protocols.MetaFileIndex{
+		Version:     metaid_protocols.MetaFileIndexV2,
 		Sha256:      filehashStr,
 		FileSize:    int64(len(req.Content)),
 		ChunkNumber: chunkNumber,
 		ChunkSize:   chunkSize,
 		DataType:    req.ContentType,
 		Name:        req.FileName,
+		Compression: req.Compression,
+		Encryption:  req.Encryption,
+		Delta:       req.Delta,
 		ChunkList:   chunkList,
 	}
 
@@ -1219,13 +1442,6 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 		return nil, fmt.Errorf("failed to marshal index data: %w", err)
 	}
 
-	// Build index path
-	indexPath := "/file/index"
-	// indexPath := fmt.Sprintf("%s/file/index", req.Path)
-	// if !strings.HasPrefix(indexPath, "/") {
-	// 	indexPath = "/" + indexPath
-	// }
-
 	indexScript, err := buildIndexOpReturnScript(indexPath, indexData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build index script: %w", err)
@@ -1246,6 +1462,25 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	log.Printf("Index transaction built: fileHash=%s, chunkNumber=%d", filehashStr, chunkNumber)
 	s.updateUploadTaskProgress(req.Task, "Index transaction built", 80, len(chunkTxIds))
 
+	if req.DryRun {
+		return &ChunkedUploadResponse{
+			FileId:         fileId,
+			FileHash:       filehashStr,
+			FileMd5:        md5hashStr,
+			ChunkNumber:    chunkNumber,
+			ChunkFundingTx: chunkFundingTxHex,
+			ChunkTxs:       chunkTxs,
+			ChunkTxIds:     chunkTxIds,
+			IndexTx:        indexTxHex,
+			IndexTxId:      indexTxId,
+			ChunkPinIds:    chunkPinIDs(chunkList),
+			IndexPinId:     fmt.Sprintf("%si0", indexTxId),
+			DryRun:         true,
+			Status:         statusDryRun,
+			Message:        "dry run: transactions built, not broadcast or saved",
+		}, nil
+	}
+
 	// Check if the file already exists
 	existingFile, err := s.fileDAO.GetByFileID(fileId)
 	if err == nil && existingFile != nil {
@@ -1275,7 +1510,7 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	file := &model.File{
 		FileId:          fileId,
 		FileName:        req.FileName,
-		FileType:        strings.ReplaceAll(req.ContentType, ";binary", ""),
+		FileType:        contenttype.Normalize(req.ContentType),
 		MetaId:          req.MetaId,
 		Address:         req.Address,
 		Path:            indexPath,
@@ -1285,6 +1520,7 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 		FileMd5:         md5hashStr,
 		FileContentType: req.ContentType,
 		ChunkType:       model.ChunkTypeMulti,
+		StorageClass:    model.StorageClass(req.StorageClass),
 		Operation:       req.Operation,
 		Status:          model.StatusPending,
 	}
@@ -1305,128 +1541,32 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 		log.Printf("File metadata saved: FileId=%s, status=pending", fileId)
 	}
 
-	// Broadcast all transactions when requested
+	handedOff = true
+
+	// Broadcast all transactions when requested. They are checkpointed first so
+	// an upload interrupted by a restart can be resumed (RecoverInFlightUploads).
 	if req.IsBroadcast {
-		chain := conf.Cfg.Net
 		finalStatus := model.StatusSuccess
-		finalMessage := "success"
+		finalMessage := "all transactions broadcasted successfully"
 
 		s.updateUploadTaskProgress(req.Task, "Broadcasting transactions", 82, len(chunkTxIds))
 
-		// Use DB transaction to ensure consistency
-		err := database.UploaderDB.Transaction(func(tx *gorm.DB) error {
-			// 0. Broadcast merge transaction first if provided
-			if req.MergeTxHex != "" {
-				log.Printf("Broadcasting merge transaction first...")
-				mergeTxId, err := node.BroadcastTxResilient(chain, req.MergeTxHex)
-				if err != nil {
-					log.Printf("Failed to broadcast merge transaction: %v", err)
-					// Mark file as failed
-					if updateErr := tx.Model(&model.File{}).Where("file_id = ?", fileId).Update("status", model.StatusFailed).Error; updateErr != nil {
-						return fmt.Errorf("failed to update file status: %w", updateErr)
-					}
-					// Mark all chunks as failed
-					if updateErr := tx.Model(&model.FileChunk{}).Where("file_hash = ?", filehashStr).Update("status", model.StatusFailed).Error; updateErr != nil {
-						log.Printf("Failed to update chunk status: %v", updateErr)
-					}
-					return fmt.Errorf("failed to broadcast merge transaction: %w", err)
-				}
-				log.Printf("Merge transaction broadcasted successfully: %s", mergeTxId)
-			}
-
-			// 1. Broadcast chunk funding transaction
-			log.Printf("Broadcasting chunk funding transaction: %s", chunkFundingTxHash)
-			broadcastFundingTxID, err := node.BroadcastTxResilient(chain, chunkFundingTxHex)
-			if err != nil {
-				log.Printf("Failed to broadcast chunk funding transaction: %v", err)
-				// Mark file as failed
-				if updateErr := tx.Model(&model.File{}).Where("file_id = ?", fileId).Update("status", model.StatusFailed).Error; updateErr != nil {
-					return fmt.Errorf("failed to update file status: %w", updateErr)
-				}
-				// Mark all chunks as failed
-				if updateErr := tx.Model(&model.FileChunk{}).Where("file_hash = ?", filehashStr).Update("status", model.StatusFailed).Error; updateErr != nil {
-					log.Printf("Failed to update chunk status: %v", updateErr)
-				}
-				return fmt.Errorf("failed to broadcast chunk funding transaction: %w", err)
-			}
-			log.Printf("Chunk funding transaction broadcasted successfully: %s", broadcastFundingTxID)
-			s.updateUploadTaskProgress(req.Task, "ChunkFunding transaction broadcasted", 85, len(chunkTxIds))
-
-			// 2. Broadcast each chunk transaction sequentially
-			for i, chunkTxHex := range chunkTxs {
-				log.Printf("Broadcasting chunk transaction %d/%d: %s", i+1, chunkNumber, chunkTxIds[i])
-				broadcastChunkTxID, err := node.BroadcastTxResilient(chain, chunkTxHex)
-				if err != nil {
-					log.Printf("Failed to broadcast chunk transaction %d: %v", i, err)
-					// Mark file as failed
-					if updateErr := tx.Model(&model.File{}).Where("file_id = ?", fileId).Update("status", model.StatusFailed).Error; updateErr != nil {
-						return fmt.Errorf("failed to update file status: %w", updateErr)
-					}
-					// Mark all chunks as failed
-					if updateErr := tx.Model(&model.FileChunk{}).Where("file_hash = ?", filehashStr).Update("status", model.StatusFailed).Error; updateErr != nil {
-						log.Printf("Failed to update chunk status: %v", updateErr)
-					}
-					return fmt.Errorf("failed to broadcast chunk transaction %d: %w", i, err)
-				}
-				log.Printf("Chunk transaction %d/%d broadcasted successfully: %s", i+1, chunkNumber, broadcastChunkTxID)
-				progress := calcProgressRange(85, 95, i+1, chunkNumber)
-				s.updateUploadTaskProgress(req.Task,
-					fmt.Sprintf("Broadcasting chunk transactions (%d/%d)", i+1, chunkNumber),
-					progress,
-					len(chunkTxIds))
-
-				// Update chunk status
-				if updateErr := tx.Model(&model.FileChunk{}).
-					Where("pin_id = ?", fmt.Sprintf("%si0", chunkTxIds[i])).
-					Update("status", model.StatusSuccess).Error; updateErr != nil {
-					log.Printf("Failed to update chunk %d status: %v", i, updateErr)
-					// Ignore update failures and continue
-				}
-			}
-
-			time.Sleep(5 * time.Second)
-			// 3. Broadcast index transaction
-			s.updateUploadTaskProgress(req.Task, "Preparing to broadcast index transaction", 96, len(chunkTxIds))
-			log.Printf("Broadcasting index transaction: %s", indexTxId)
-			broadcastIndexTxID, err := node.BroadcastTxResilient(chain, indexTxHex)
-			if err != nil {
-				log.Printf("Failed to broadcast index transaction: %v", err)
-				// Mark file as failed
-				if updateErr := tx.Model(&model.File{}).Where("file_id = ?", fileId).Update("status", model.StatusFailed).Error; updateErr != nil {
-					return fmt.Errorf("failed to update file status: %w", updateErr)
-				}
-				// Mark all chunks as failed
-				if updateErr := tx.Model(&model.FileChunk{}).Where("file_hash = ?", filehashStr).Update("status", model.StatusFailed).Error; updateErr != nil {
-					log.Printf("Failed to update chunk status: %v", updateErr)
-				}
-				return fmt.Errorf("failed to broadcast index transaction: %w", err)
-			}
-			log.Printf("Index transaction broadcasted successfully: %s", broadcastIndexTxID)
-			s.updateUploadTaskProgress(req.Task, "Index transaction broadcasted", 98, len(chunkTxIds))
-
-			// 4. Mark file as success
-			if updateErr := tx.Model(&model.File{}).
-				Where("file_id = ?", fileId).
-				Updates(map[string]interface{}{
-					"status": model.StatusSuccess,
-					"tx_id":  indexTxId,
-					"pin_id": fmt.Sprintf("%si0", indexTxId),
-				}).Error; updateErr != nil {
-				return fmt.Errorf("failed to update file status: %w", updateErr)
-			}
-
-			log.Printf("All transactions broadcasted successfully for file: %s", fileId)
-			s.updateUploadTaskProgress(req.Task, "Broadcast finished, finalizing task", 99, len(chunkTxIds))
-			return nil
-		})
-
-		if err != nil {
+		checkpoint, err := newUploadCheckpoint(fileId, filehashStr, req.MergeTxHex, chunkFundingTxHex, chunkTxs, chunkTxIds, indexTxHex, indexTxId)
+		if err == nil {
+			err = s.broadcastUploadCheckpoint(checkpoint, req.Task)
+		}
+		if errors.Is(err, errCheckpointNotSaved) {
+			log.Printf("Broadcast interrupted, left for recovery: %v", err)
+			keepInputLocks = true
+			finalStatus = model.StatusPending
+			finalMessage = fmt.Sprintf("broadcast interrupted, will be resumed by upload recovery: %v", err)
+		} else if err != nil {
 			log.Printf("Failed to broadcast transactions: %v", err)
 			finalStatus = model.StatusFailed
 			finalMessage = fmt.Sprintf("broadcast failed: %v", err)
 		} else {
-			finalStatus = model.StatusSuccess
-			finalMessage = "all transactions broadcasted successfully"
+			log.Printf("All transactions broadcasted successfully for file: %s", fileId)
+			s.updateUploadTaskProgress(req.Task, "Broadcast finished, finalizing task", 99, len(chunkTxIds))
 		}
 
 		return &ChunkedUploadResponse{
@@ -1445,6 +1585,7 @@ func (s *UploadService) ChunkedUpload(req *ChunkedUploadRequest) (*ChunkedUpload
 	}
 
 	s.updateUploadTaskProgress(req.Task, "Chunk transactions ready, waiting to broadcast", 85, len(chunkTxIds))
+	keepInputLocks = true
 
 	return &ChunkedUploadResponse{
 		FileId:         fileId,
@@ -1467,12 +1608,29 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 	if len(req.Content) == 0 {
 		return nil, fmt.Errorf("file content is empty")
 	}
-	if req.Path == "" {
-		return nil, fmt.Errorf("file path is required")
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	normalizeRequestMetaId(&req.MetaId, req.Address)
+	if err := normalizeStorageClass(&req.StorageClass); err != nil {
+		return nil, err
+	}
+	if err := normalizeMetaFileEncoding(&req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	if err := s.prepareDeltaUpload(req.Chain, req.DeltaBasePinId, &req.Delta, &req.Content, req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	chunkPath, indexPath, err := chunkedUploadPaths(req.Path)
+	if err != nil {
+		return nil, err
 	}
 	if req.Address == "" {
 		return nil, fmt.Errorf("user address is required")
 	}
+	if _, err := resolveChainFundingStrategy("doge", req.FundingStrategy); err != nil {
+		return nil, err
+	}
 	if req.ChunkPreTxHex == "" {
 		return nil, fmt.Errorf("chunk pre-tx hex is required")
 	}
@@ -1480,9 +1638,7 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 	if req.Operation == "" {
 		req.Operation = "create"
 	}
-	if req.ContentType == "" {
-		req.ContentType = "application/octet-stream"
-	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
 	_, _, chainFeeRate := conf.GetUploaderChainParam("doge")
 	if req.FeeRate == 0 {
 		req.FeeRate = chainFeeRate
@@ -1501,7 +1657,22 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 		return nil, fmt.Errorf("failed to decode chunk pre-tx: %w", err)
 	}
 
-	assistent, err := s.getOrCreateFileAssistentDoge(req.MetaId, req.Address, netParam)
+	// Inputs of the chunk pre-tx are reserved as in ChunkedUpload; the
+	// transactions always go back to the client, so only failures release them
+	keepInputLocks := req.Task != nil
+	if !req.DryRun {
+		lockOwner := uploadLockOwner(req.Task)
+		if err := s.lockUploadInputs(lockOwner, "doge", req.Task, req.ChunkPreTxHex); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if !keepInputLocks {
+				s.releaseUtxoLocks(lockOwner)
+			}
+		}()
+	}
+
+	assistent, err := s.getOrCreateFileAssistentDoge(req.MetaId, req.Address, netParam, req.DryRun)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare assistent address: %w", err)
 	}
@@ -1540,16 +1711,12 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 		return nil, fmt.Errorf("chunk pre-tx has no spendable outputs")
 	}
 
-	chunkPath := "/file/_chunk"
 	chunkContentType := metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary"
 
 	chunkTxs := make([]string, 0)
 	chunkTxIds := make([]string, 0)
 	chunkRevealTxIds := make([]string, 0, chunkNumber)
-	chunkList := make([]struct {
-		Sha256 string `json:"sha256"`
-		PinId  string `json:"pinId"`
-	}, 0, chunkNumber)
+	chunkList := make([]metaid_protocols.MetaFileChunk, 0, chunkNumber)
 
 	for i, chunkData := range chunks {
 		txs, changeUtxos, err := common.BuildDogeMetaIdInscriptionTxs(
@@ -1585,10 +1752,7 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 
 		chunkHash := sha256.Sum256(chunkData)
 		chunkHashStr := hex.EncodeToString(chunkHash[:])
-		chunkList = append(chunkList, struct {
-			Sha256 string `json:"sha256"`
-			PinId  string `json:"pinId"`
-		}{Sha256: chunkHashStr, PinId: chunkPinId})
+		chunkList = metaid_protocols.AppendMetaFileChunk(chunkList, chunkHashStr, chunkPinId, int64(len(chunkData)))
 
 		availableUtxos = make([]*common.TxInputUtxo, 0, len(changeUtxos))
 		for _, cu := range changeUtxos {
@@ -1605,30 +1769,32 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 		chunkMd5Hash := md5.Sum(chunkData)
 		chunkMd5Str := hex.EncodeToString(chunkMd5Hash[:])
 
-		existingChunk, err := s.fileChunkDAO.GetByTxID(revealTxId)
-		if err != nil && err != gorm.ErrRecordNotFound {
-			log.Printf("Failed to check existing chunk %d: %v", i, err)
-		} else if existingChunk != nil {
-			log.Printf("File chunk %d already exists: hash=%s, txId=%s, skipping create", i, chunkHashStr, revealTxId)
-		} else {
-			fileChunk := &model.FileChunk{
-				ChunkHash:   chunkHashStr,
-				ChunkSize:   int64(len(chunkData)),
-				ChunkMd5:    chunkMd5Str,
-				ChunkIndex:  int64(i),
-				FileHash:    filehashStr,
-				TxID:        revealTxId,
-				PinId:       chunkPinId,
-				Path:        chunkPath,
-				ContentType: chunkContentType,
-				Size:        int64(len(chunkData)),
-				StorageType: conf.Cfg.Storage.Type,
-				StoragePath: "",
-				Operation:   req.Operation,
-				Status:      model.StatusPending,
-			}
-			if err := s.fileChunkDAO.Create(fileChunk); err != nil {
-				log.Printf("Failed to save file chunk %d: %v", i, err)
+		if !req.DryRun {
+			existingChunk, err := s.fileChunkDAO.GetByTxID(revealTxId)
+			if err != nil && err != gorm.ErrRecordNotFound {
+				log.Printf("Failed to check existing chunk %d: %v", i, err)
+			} else if existingChunk != nil {
+				log.Printf("File chunk %d already exists: hash=%s, txId=%s, skipping create", i, chunkHashStr, revealTxId)
+			} else {
+				fileChunk := &model.FileChunk{
+					ChunkHash:   chunkHashStr,
+					ChunkSize:   int64(len(chunkData)),
+					ChunkMd5:    chunkMd5Str,
+					ChunkIndex:  int64(i),
+					FileHash:    filehashStr,
+					TxID:        revealTxId,
+					PinId:       chunkPinId,
+					Path:        chunkPath,
+					ContentType: chunkContentType,
+					Size:        int64(len(chunkData)),
+					StorageType: conf.Cfg.Storage.Type,
+					StoragePath: "",
+					Operation:   req.Operation,
+					Status:      model.StatusPending,
+				}
+				if err := s.fileChunkDAO.Create(fileChunk); err != nil {
+					log.Printf("Failed to save file chunk %d: %v", i, err)
+				}
 			}
 		}
 
@@ -1639,12 +1805,16 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 	s.updateUploadTaskProgress(req.Task, "Chunk transactions built, preparing index", 75, len(chunkTxIds))
 
 	metaFileIndex := metaid_protocols.MetaFileIndex{
+		Version:     metaid_protocols.MetaFileIndexV2,
 		Sha256:      filehashStr,
 		FileSize:    int64(len(req.Content)),
 		ChunkNumber: chunkNumber,
 		ChunkSize:   chunkSize,
 		DataType:    req.ContentType,
 		Name:        req.FileName,
+		Compression: req.Compression,
+		Encryption:  req.Encryption,
+		Delta:       req.Delta,
 		ChunkList:   chunkList,
 	}
 
@@ -1653,7 +1823,6 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 		return nil, fmt.Errorf("failed to marshal index data: %w", err)
 	}
 
-	indexPath := "/file/index"
 	indexContentType := metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8"
 	indexTxs, _, err := common.BuildDogeMetaIdInscriptionTxs(
 		netParam,
@@ -1684,6 +1853,26 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 
 	s.updateUploadTaskProgress(req.Task, "Index transaction built", 80, len(chunkTxIds))
 
+	if req.DryRun {
+		return &ChunkedUploadResponse{
+			FileId:           fileId,
+			FileHash:         filehashStr,
+			FileMd5:          md5hashStr,
+			ChunkNumber:      chunkNumber,
+			ChunkFundingTx:   chunkFundingTxHex,
+			ChunkTxs:         chunkTxs,
+			ChunkTxIds:       chunkTxIds,
+			ChunkRevealTxIds: chunkRevealTxIds,
+			IndexTxs:         indexTxHexes,
+			IndexTxId:        indexTxId,
+			ChunkPinIds:      chunkPinIDs(chunkList),
+			IndexPinId:       fmt.Sprintf("%si0", indexTxId),
+			DryRun:           true,
+			Status:           statusDryRun,
+			Message:          "dry run: transactions built, not broadcast or saved",
+		}, nil
+	}
+
 	existingFile, err := s.fileDAO.GetByFileID(fileId)
 	if err == nil && existingFile != nil && existingFile.Status == model.StatusSuccess {
 		log.Printf("File already exists and uploaded successfully: FileId=%s", fileId)
@@ -1698,20 +1887,20 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 		}, nil
 	}
 
-	indexPathForFile := "/file/index"
 	file := &model.File{
 		FileId:          fileId,
 		FileName:        req.FileName,
-		FileType:        strings.ReplaceAll(req.ContentType, ";binary", ""),
+		FileType:        contenttype.Normalize(req.ContentType),
 		MetaId:          req.MetaId,
 		Address:         req.Address,
-		Path:            indexPathForFile,
+		Path:            indexPath,
 		ContentType:     metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8",
 		FileSize:        int64(len(req.Content)),
 		FileHash:        filehashStr,
 		FileMd5:         md5hashStr,
 		FileContentType: req.ContentType,
 		ChunkType:       model.ChunkTypeMulti,
+		StorageClass:    model.StorageClass(req.StorageClass),
 		Operation:       req.Operation,
 		Status:          model.StatusPending,
 	}
@@ -1726,6 +1915,7 @@ func (s *UploadService) ChunkedUploadInDoge(req *ChunkedUploadRequest) (*Chunked
 			return nil, fmt.Errorf("failed to save file metadata: %w", err)
 		}
 	}
+	keepInputLocks = true
 
 	return &ChunkedUploadResponse{
 		FileId:           fileId,
@@ -1762,15 +1952,28 @@ func (s *UploadService) ChunkedUploadForTask(req *ChunkedUploadRequest) (*Chunke
 	if len(req.Content) == 0 {
 		return nil, fmt.Errorf("file content is empty")
 	}
-	if req.Path == "" {
-		return nil, fmt.Errorf("file path is required")
+	if err := verifyContentSHA256(req.ContentSHA256, req.Content); err != nil {
+		return nil, err
+	}
+	if err := normalizeRequestPath(&req.Path); err != nil {
+		return nil, err
+	}
+	normalizeRequestMetaId(&req.MetaId, req.Address)
+	if err := normalizeStorageClass(&req.StorageClass); err != nil {
+		return nil, err
+	}
+	if err := normalizeMetaFileEncoding(&req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	if err := s.prepareDeltaUpload(req.Chain, req.DeltaBasePinId, &req.Delta, &req.Content, req.Compression, req.Encryption); err != nil {
+		return nil, err
+	}
+	if _, _, err := chunkedUploadPaths(req.Path); err != nil {
+		return nil, err
 	}
 	if req.Address == "" {
 		return nil, fmt.Errorf("user address is required")
 	}
-	if req.ChunkPreTxHex == "" {
-		return nil, fmt.Errorf("chunk pre-tx hex is required")
-	}
 
 	chain := req.Chain
 	if chain == "" {
@@ -1786,9 +1989,7 @@ func (s *UploadService) ChunkedUploadForTask(req *ChunkedUploadRequest) (*Chunke
 	if req.Operation == "" {
 		req.Operation = "create"
 	}
-	if req.ContentType == "" {
-		req.ContentType = "application/octet-stream"
-	}
+	req.ContentType = contenttype.Declaration(req.ContentType)
 	maxFileSize, chunkSizeParam, chainFeeRate := conf.GetUploaderChainParam(chain)
 	if req.FeeRate == 0 {
 		req.FeeRate = chainFeeRate
@@ -1797,6 +1998,21 @@ func (s *UploadService) ChunkedUploadForTask(req *ChunkedUploadRequest) (*Chunke
 	if maxFileSize > 0 && int64(len(req.Content)) > maxFileSize {
 		return nil, fmt.Errorf("file size exceeds limit for chain %s (size %d bytes, max %d bytes)", chain, len(req.Content), maxFileSize)
 	}
+	if err := validateUploadSchedule(req, time.Now()); err != nil {
+		return nil, err
+	}
+	fundingStrategy, err := resolveChainFundingStrategy(chain, req.FundingStrategy)
+	if err != nil {
+		return nil, err
+	}
+	if fundingStrategy == ChunkFundingHotWallet {
+		// Locks on hot wallet outputs expire after uploader.chunk_funding.lock_minutes
+		if req.BroadcastAt != nil || req.TargetFeeRate > 0 {
+			return nil, fmt.Errorf("%w: hot_wallet funded uploads cannot be scheduled", ErrInvalidFundingStrategy)
+		}
+	} else if req.ChunkPreTxHex == "" {
+		return nil, fmt.Errorf("chunk pre-tx hex is required")
+	}
 
 	sha256hash := sha256.Sum256(req.Content)
 	md5hash := md5.Sum(req.Content)
@@ -1813,6 +2029,15 @@ func (s *UploadService) ChunkedUploadForTask(req *ChunkedUploadRequest) (*Chunke
 
 	contentBase64 := base64.StdEncoding.EncodeToString(req.Content)
 
+	encryptionJSON, err := encodeMetaFileEncryption(req.Encryption)
+	if err != nil {
+		return nil, err
+	}
+	deltaJSON, err := encodeMetaFileDelta(req.Delta)
+	if err != nil {
+		return nil, err
+	}
+
 	taskId := fmt.Sprintf("task_%s_%s_%d", chain, filehashStr[:16], time.Now().Unix())
 	chunkTxIdsJSON, _ := json.Marshal([]string{})
 
@@ -1829,10 +2054,16 @@ func (s *UploadService) ChunkedUploadForTask(req *ChunkedUploadRequest) (*Chunke
 		Path:            req.Path,
 		Operation:       req.Operation,
 		ContentBase64:   contentBase64,
+		StorageClass:    model.StorageClass(req.StorageClass),
+		Compression:     req.Compression,
+		Encryption:      encryptionJSON,
+		Delta:           deltaJSON,
 		ChunkPreTxHex:   req.ChunkPreTxHex,
 		IndexPreTxHex:   req.IndexPreTxHex,
 		MergeTxHex:      req.MergeTxHex,
 		FeeRate:         req.FeeRate,
+		BroadcastAt:     req.BroadcastAt,
+		TargetFeeRate:   req.TargetFeeRate,
 		Status:          model.StatusPending,
 		Progress:        0,
 		TotalChunks:     chunkNumber,
@@ -1840,18 +2071,36 @@ func (s *UploadService) ChunkedUploadForTask(req *ChunkedUploadRequest) (*Chunke
 		CurrentStep:     "Task created, waiting to process",
 		FileId:          fileId,
 		ChunkTxIds:      string(chunkTxIdsJSON),
+		FundingStrategy: fundingStrategy,
 	}
 
+	// Inputs are reserved from now on, so no other upload spends them while
+	// the task waits for the processor or its broadcast time
+	lockOwner := uploadLockOwner(task)
+	if err := s.lockUploadInputs(lockOwner, chain, task, req.MergeTxHex, req.ChunkPreTxHex, req.IndexPreTxHex); err != nil {
+		return nil, err
+	}
 	if err := s.fileUploaderTaskDAO.Create(task); err != nil {
+		// Locks of an existing task with the same ID stay
+		if _, getErr := s.fileUploaderTaskDAO.GetByTaskID(taskId); errors.Is(getErr, gorm.ErrRecordNotFound) {
+			s.releaseUtxoLocks(lockOwner)
+		}
 		return nil, fmt.Errorf("failed to create upload task: %w", err)
 	}
 
 	log.Printf("Created chunked upload task: taskId=%s, fileId=%s, chunkNumber=%d, chain=%s", taskId, fileId, chunkNumber, chain)
 
+	message := "Task created, processing"
+	if broadcastHeld(task) {
+		message = fmt.Sprintf("Task created, broadcast scheduled for %s", task.BroadcastAt.Format(time.RFC3339))
+		if task.TargetFeeRate > 0 {
+			message += fmt.Sprintf(" or once the fee rate drops to %d", task.TargetFeeRate)
+		}
+	}
 	return &ChunkedUploadForTaskResponse{
 		TaskId:  taskId,
 		Status:  string(model.StatusPending),
-		Message: "Task created, processing",
+		Message: message,
 	}, nil
 }
 
@@ -1864,7 +2113,7 @@ func (s *UploadService) ListTasksByAddress(address string, cursor int64, size in
 		size = 20
 	}
 
-	tasks, nextCursor, err := s.fileUploaderTaskDAO.ListByAddressWithCursor(address, cursor, size)
+	tasks, nextCursor, hasMore, err := s.fileUploaderTaskDAO.ListByAddressWithCursor(address, cursor, size)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
@@ -1872,7 +2121,7 @@ func (s *UploadService) ListTasksByAddress(address string, cursor int64, size in
 	return &UploadTaskListResponse{
 		Tasks:      tasks,
 		NextCursor: nextCursor,
-		HasMore:    len(tasks) == size,
+		HasMore:    hasMore,
 	}, nil
 }
 
@@ -1884,9 +2133,14 @@ func (s *UploadService) ProcessUploadTask(task *model.FileUploaderTask) error {
 	task.StartedAt = &now
 	task.CurrentStep = "Processing started"
 	task.Progress = 5
-	if err := s.fileUploaderTaskDAO.Update(task); err != nil {
+	claimed, err := s.fileUploaderTaskDAO.ClaimForProcessing(task)
+	if err != nil {
 		return fmt.Errorf("failed to update task status: %w", err)
 	}
+	if !claimed {
+		log.Printf("Task %s is no longer pending (cancelled?), skipped", task.TaskId)
+		return nil
+	}
 
 	// Decode file content
 	content, err := base64.StdEncoding.DecodeString(task.ContentBase64)
@@ -1894,6 +2148,7 @@ func (s *UploadService) ProcessUploadTask(task *model.FileUploaderTask) error {
 		task.Status = model.StatusFailed
 		task.ErrorMessage = fmt.Sprintf("failed to decode content: %v", err)
 		task.Progress = 0
+		s.releaseUtxoLocks(uploadLockOwner(task))
 		s.clearTaskPayload(task)
 		s.fileUploaderTaskDAO.Update(task)
 		return fmt.Errorf("failed to decode content: %w", err)
@@ -1912,7 +2167,29 @@ func (s *UploadService) ProcessUploadTask(task *model.FileUploaderTask) error {
 		IndexPreTxHex: task.IndexPreTxHex,
 		MergeTxHex:    task.MergeTxHex,
 		FeeRate:       task.FeeRate,
+		StorageClass:  string(task.StorageClass),
+		Compression:   task.Compression,
 		IsBroadcast:   false, // chunkedUploadOnTask will drive broadcasting
+
+		FundingStrategy: task.FundingStrategy,
+	}
+	if chunkedReq.Encryption, err = decodeMetaFileEncryption(task.Encryption); err != nil {
+		task.Status = model.StatusFailed
+		task.ErrorMessage = fmt.Sprintf("failed to decode encryption envelope: %v", err)
+		task.Progress = 0
+		s.releaseUtxoLocks(uploadLockOwner(task))
+		s.clearTaskPayload(task)
+		s.fileUploaderTaskDAO.Update(task)
+		return fmt.Errorf("failed to decode encryption envelope: %w", err)
+	}
+	if chunkedReq.Delta, err = decodeMetaFileDelta(task.Delta); err != nil {
+		task.Status = model.StatusFailed
+		task.ErrorMessage = fmt.Sprintf("failed to decode delta envelope: %v", err)
+		task.Progress = 0
+		s.releaseUtxoLocks(uploadLockOwner(task))
+		s.clearTaskPayload(task)
+		s.fileUploaderTaskDAO.Update(task)
+		return fmt.Errorf("failed to decode delta envelope: %w", err)
 	}
 
 	// Update progress
@@ -1927,12 +2204,30 @@ func (s *UploadService) ProcessUploadTask(task *model.FileUploaderTask) error {
 	} else {
 		resp, err = s.chunkedUploadOnTask(chunkedReq, task)
 	}
+	if errors.Is(err, errTaskScheduled) {
+		// Transactions are built; the broadcast scheduler releases the task
+		// later. Its inputs stay reserved.
+		task.Status = model.TaskStatusScheduled
+		task.CurrentStep = "Transactions prepared, broadcast scheduled"
+		if err := s.fileUploaderTaskDAO.Update(task); err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
+		log.Printf("Task %s prepared, broadcast scheduled", task.TaskId)
+		return nil
+	}
+	if errors.Is(err, errTaskInterrupted) {
+		return s.requeueInterruptedTask(task)
+	}
 	if err != nil {
 		task.Status = model.StatusFailed
 		task.ErrorMessage = err.Error()
 		// task.Progress = 0
 		finishedAt := time.Now()
 		task.FinishedAt = &finishedAt
+		if task.Stage == model.TaskStagePrepared || task.Stage == model.TaskStageMergeBroadcast {
+			s.releaseHotWalletFunding(task.ChunkFundingTx)
+		}
+		s.releaseUtxoLocks(uploadLockOwner(task))
 		s.clearTaskPayload(task)
 		s.fileUploaderTaskDAO.Update(task)
 		return fmt.Errorf("failed to process chunked upload: %w", err)
@@ -1949,6 +2244,7 @@ func (s *UploadService) ProcessUploadTask(task *model.FileUploaderTask) error {
 	task.IndexTxId = resp.IndexTxId
 	finishedAt := time.Now()
 	task.FinishedAt = &finishedAt
+	s.releaseUtxoLocks(uploadLockOwner(task))
 	s.clearTaskPayload(task)
 
 	if err := s.fileUploaderTaskDAO.Update(task); err != nil {
@@ -1970,10 +2266,16 @@ func (s *UploadService) chunkedUploadOnTask(req *ChunkedUploadRequest, task *mod
 
 	// Stage 1: build transactions
 	if task.Stage == model.TaskStageCreated {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		if err := s.prepareChunkedUploadForTask(req, task); err != nil {
 			return nil, err
 		}
 	}
+	if task.Stage == model.TaskStagePrepared && broadcastHeld(task) {
+		return nil, errTaskScheduled
+	}
 
 	chunkTxHexes, err := decodeStringArray(task.ChunkTxHexes)
 	if err != nil {
@@ -1986,6 +2288,12 @@ func (s *UploadService) chunkedUploadOnTask(req *ChunkedUploadRequest, task *mod
 
 	// Stage 2: broadcast merge tx (if any)
 	if task.Stage == model.TaskStagePrepared {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
+		if err := s.preflightTransactions("mvc", preflightTaskTxs(task.MergeTxHex, task.ChunkFundingTx, chunkTxHexes, nil)); err != nil {
+			return nil, err
+		}
 		if err := s.broadcastMergeTxForTask(task); err != nil {
 			return nil, err
 		}
@@ -1993,6 +2301,9 @@ func (s *UploadService) chunkedUploadOnTask(req *ChunkedUploadRequest, task *mod
 
 	// Stage 3: broadcast chunk funding tx
 	if task.Stage == model.TaskStageMergeBroadcast {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		if err := s.broadcastFundingTxForTask(task); err != nil {
 			return nil, err
 		}
@@ -2000,6 +2311,9 @@ func (s *UploadService) chunkedUploadOnTask(req *ChunkedUploadRequest, task *mod
 
 	// Stage 4: broadcast chunk tx
 	if task.Stage == model.TaskStageFundingBroadcast {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		if err := s.broadcastChunkTransactionsForTask(task, chunkTxHexes, chunkTxIds); err != nil {
 			return nil, err
 		}
@@ -2007,6 +2321,9 @@ func (s *UploadService) chunkedUploadOnTask(req *ChunkedUploadRequest, task *mod
 
 	// Stage 5: broadcast index tx
 	if task.Stage == model.TaskStageChunkBroadcast {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		return s.broadcastIndexTxForTask(req, task, chunkTxIds)
 	}
 
@@ -2095,10 +2412,16 @@ func (s *UploadService) chunkedUploadOnTaskInDoge(req *ChunkedUploadRequest, tas
 	}
 
 	if task.Stage == model.TaskStageCreated {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		if err := s.prepareChunkedUploadForTaskInDoge(req, task); err != nil {
 			return nil, err
 		}
 	}
+	if task.Stage == model.TaskStagePrepared && broadcastHeld(task) {
+		return nil, errTaskScheduled
+	}
 
 	chunkTxHexes, err := decodeStringArray(task.ChunkTxHexes)
 	if err != nil {
@@ -2110,24 +2433,40 @@ func (s *UploadService) chunkedUploadOnTaskInDoge(req *ChunkedUploadRequest, tas
 	}
 
 	if task.Stage == model.TaskStagePrepared {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
+		indexTxHexes, _ := decodeStringArray(task.IndexTxHexes)
+		if err := s.preflightTransactions("doge", preflightTaskTxs(task.MergeTxHex, task.ChunkFundingTx, chunkTxHexes, indexTxHexes)); err != nil {
+			return nil, err
+		}
 		if err := s.broadcastMergeTxForTaskInDoge(task); err != nil {
 			return nil, err
 		}
 	}
 
 	if task.Stage == model.TaskStageMergeBroadcast {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		if err := s.broadcastFundingTxForTaskInDoge(task); err != nil {
 			return nil, err
 		}
 	}
 
 	if task.Stage == model.TaskStageFundingBroadcast {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		if err := s.broadcastChunkTransactionsForTaskInDoge(task, chunkTxHexes, chunkTxIds); err != nil {
 			return nil, err
 		}
 	}
 
 	if task.Stage == model.TaskStageChunkBroadcast {
+		if err := s.taskCheckpoint(task); err != nil {
+			return nil, err
+		}
 		return s.broadcastIndexTxForTaskInDoge(req, task, chunkTxIds)
 	}
 
@@ -2258,6 +2597,7 @@ func (s *UploadService) broadcastFundingTxForTaskInDoge(task *model.FileUploader
 				return fmt.Errorf("failed to broadcast chunk funding transaction: %w", err)
 			}
 		}
+		s.recordAssistentTx(chain, task.Address, fundingHex)
 
 		return nil
 	})
@@ -2333,6 +2673,7 @@ func (s *UploadService) broadcastSingleChunkTxInDoge(task *model.FileUploaderTas
 				return fmt.Errorf("failed to broadcast chunk transaction %d: %w", index, err)
 			}
 		}
+		s.recordAssistentTx(chain, task.Address, txHex)
 
 		task.ProcessedChunks = processed
 		task.Progress = progress
@@ -2468,6 +2809,8 @@ func (s *UploadService) broadcastFundingTxForTask(task *model.FileUploaderTask)
 				return fmt.Errorf("failed to broadcast chunk funding transaction: %w", err)
 			}
 		}
+		s.recordAssistentTx("mvc", task.Address, fundingHex)
+		s.recordHotWalletTx(fundingHex)
 
 		return nil
 	})
@@ -2544,6 +2887,7 @@ func (s *UploadService) broadcastSingleChunkTx(task *model.FileUploaderTask, chu
 			}
 
 		}
+		s.recordAssistentTx("mvc", task.Address, txHex)
 
 		task.ProcessedChunks = processed
 		task.Progress = progress
@@ -2576,30 +2920,25 @@ func (s *UploadService) broadcastIndexTxForTask(req *ChunkedUploadRequest, task
 		filehash = hex.EncodeToString(sha[:])
 	}
 
-	chunkList := make([]struct {
-		Sha256 string `json:"sha256"`
-		PinId  string `json:"pinId"`
-	}, 0, len(chunks))
+	chunkList := make([]metaid_protocols.MetaFileChunk, 0, len(chunks))
 	for i, chunkData := range chunks {
 		chunkHash := sha256.Sum256(chunkData)
 		chunkHashStr := hex.EncodeToString(chunkHash[:])
 		pinID := fmt.Sprintf("%si0", chunkTxIds[i])
-		chunkList = append(chunkList, struct {
-			Sha256 string `json:"sha256"`
-			PinId  string `json:"pinId"`
-		}{
-			Sha256: chunkHashStr,
-			PinId:  pinID,
-		})
+		chunkList = metaid_protocols.AppendMetaFileChunk(chunkList, chunkHashStr, pinID, int64(len(chunkData)))
 	}
 
 	metaFileIndex := metaid_protocols.MetaFileIndex{
+		Version:     metaid_protocols.MetaFileIndexV2,
 		Sha256:      filehash,
 		FileSize:    int64(len(req.Content)),
 		ChunkNumber: len(chunks),
 		ChunkSize:   chunkSize,
 		DataType:    req.ContentType,
 		Name:        req.FileName,
+		Compression: req.Compression,
+		Encryption:  req.Encryption,
+		Delta:       req.Delta,
 		ChunkList:   chunkList,
 	}
 	indexData, err := json.Marshal(metaFileIndex)
@@ -2607,7 +2946,11 @@ func (s *UploadService) broadcastIndexTxForTask(req *ChunkedUploadRequest, task
 		return nil, fmt.Errorf("failed to marshal index metadata: %w", err)
 	}
 
-	indexScript, err := buildIndexOpReturnScript("/file/index", indexData)
+	_, indexPath, err := chunkedUploadPaths(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	indexScript, err := buildIndexOpReturnScript(indexPath, indexData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build index script: %w", err)
 	}
@@ -2645,6 +2988,13 @@ func (s *UploadService) broadcastIndexTxForTask(req *ChunkedUploadRequest, task
 		broadcastChain = conf.Cfg.Net
 	}
 	err = database.UploaderDB.Transaction(func(tx *gorm.DB) error {
+		if err := s.preflightTransactions("mvc", []preflightTx{{"index", indexTxHex}}); err != nil {
+			if updateErr := tx.Model(&model.File{}).Where("file_id = ?", fileId).Update("status", model.StatusFailed).Error; updateErr != nil {
+				log.Printf("Failed to update file status: %v", updateErr)
+			}
+			return err
+		}
+
 		// Broadcast index transaction
 		if _, err := node.BroadcastTxResilient(broadcastChain, indexTxHex); err != nil {
 			if !isDuplicateBroadcastError(err) {
@@ -2793,7 +3143,7 @@ func (s *UploadService) GetTaskProgress(taskId string) (*model.FileUploaderTask,
 }
 
 // getOrCreateFileAssistent creates or retrieves the assistant address for a user.
-func (s *UploadService) getOrCreateFileAssistent(metaID, address string, netParam *chaincfg2.Params) (*model.FileAssistent, error) {
+func (s *UploadService) getOrCreateFileAssistent(metaID, address string, netParam *chaincfg2.Params, dryRun bool) (*model.FileAssistent, error) {
 	assistent, err := s.fileAssistentDAO.GetByAddress(address)
 	if err != nil {
 		return nil, err
@@ -2802,6 +3152,27 @@ func (s *UploadService) getOrCreateFileAssistent(metaID, address string, netPara
 		return assistent, nil
 	}
 
+	newAssistent, err := newFileAssistent(metaID, address, netParam)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		// Throwaway assistant: dry-run transactions are never broadcast
+		return newAssistent, nil
+	}
+
+	if err := s.fileAssistentDAO.Create(newAssistent); err != nil {
+		return nil, fmt.Errorf("failed to create assistent: %w", err)
+	}
+
+	log.Printf("Created new file assistent for user address %s, assistent address: %s", address, newAssistent.AssistentAddress)
+	return newAssistent, nil
+}
+
+// newFileAssistent generates a new assistant key and address for a user,
+// not yet saved.
+func newFileAssistent(metaID, address string, netParam *chaincfg2.Params) (*model.FileAssistent, error) {
 	// Generate private key
 	privateKey, err := bsvec2.NewPrivateKey(bsvec2.S256())
 	if err != nil {
@@ -2816,20 +3187,22 @@ func (s *UploadService) getOrCreateFileAssistent(metaID, address string, netPara
 		return nil, fmt.Errorf("failed to derive assistent address: %w", err)
 	}
 
-	newAssistent := &model.FileAssistent{
+	return &model.FileAssistent{
 		MetaId:           metaID,
 		Address:          address,
 		AssistentAddress: addressPubKey.EncodeAddress(),
 		AssistentPriHex:  privateKeyHex,
 		Status:           model.StatusSuccess,
-	}
+	}, nil
+}
 
-	if err := s.fileAssistentDAO.Create(newAssistent); err != nil {
-		return nil, fmt.Errorf("failed to create assistent: %w", err)
+// chunkPinIDs returns the PinIDs of a chunk list in order.
+func chunkPinIDs(chunkList []metaid_protocols.MetaFileChunk) []string {
+	pinIDs := make([]string, 0, len(chunkList))
+	for _, chunk := range chunkList {
+		pinIDs = append(pinIDs, chunk.PinId)
 	}
-
-	log.Printf("Created new file assistent for user address %s, assistent address: %s", address, newAssistent.AssistentAddress)
-	return newAssistent, nil
+	return pinIDs
 }
 
 // splitFile splits content into chunks of the provided size.
@@ -2865,35 +3238,22 @@ func decodeMvcTx(txHex string) (*wire2.MsgTx, error) {
 }
 
 func buildChunkOpReturnScript(path string, chunkData []byte) ([]byte, error) {
-	builder := txscript.NewScriptBuilder().
-		AddOp(txscript.OP_0).
-		AddOp(txscript.OP_RETURN).
-		AddData([]byte("metaid")).
-		AddData([]byte("create")).
-		AddData([]byte(path)).
-		AddData([]byte("0")).
-		AddData([]byte("1.0.0")).
-		AddData([]byte(metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary"))
-
-	maxChunkSize := 520
-	for i := 0; i < len(chunkData); i += maxChunkSize {
-		end := i + maxChunkSize
-		if end > len(chunkData) {
-			end = len(chunkData)
-		}
-		builder.AddFullData(chunkData[i:end])
-	}
+	return scripts.MetaIdOpReturn(&scripts.MetaId{
+		Path:        path,
+		ContentType: metaid_protocols.MonitorMetaIdFileChunkContentType + ";binary",
+		Payload:     chunkData,
+	}), nil
+}
 
-	return builder.Script()
+func estimateChunkFundingValue(chunkScript []byte, feeRate, dustLimit int64) int64 {
+	return chunkFundingValueForScriptSize(len(chunkScript), feeRate, dustLimit)
 }
 
-func estimateChunkFundingValue(chunkScript []byte, feeRate int64) int64 {
-	const inputSize = 148 // Approximate size of a P2PKH input with signature
-	opReturnSize := 8 + wire2.VarIntSerializeSize(uint64(len(chunkScript))) + len(chunkScript)
-	txSize := 4 + 1 + inputSize + 1 + opReturnSize + 4
-	fee := int64(txSize) * feeRate
-	if fee < 600 {
-		fee = 600
+// chunkFundingValueForScriptSize returns the funding a chunk tx carrying a script of scriptLen bytes needs
+func chunkFundingValueForScriptSize(scriptLen int, feeRate, dustLimit int64) int64 {
+	fee := int64(mvcTxSize(1, 0, scriptLen)) * feeRate
+	if fee < dustLimit {
+		fee = dustLimit
 	}
 	return fee
 }
@@ -2935,34 +3295,15 @@ func (s *UploadService) buildChunkTxWithFunding(
 }
 
 func buildIndexOpReturnScript(path string, indexData []byte) ([]byte, error) {
-	builder := txscript.NewScriptBuilder().
-		AddOp(txscript.OP_0).
-		AddOp(txscript.OP_RETURN).
-		AddData([]byte("metaid")).
-		AddData([]byte("create")).
-		AddData([]byte(path)).
-		AddData([]byte("0")).
-		AddData([]byte("1.0.0")).
-		AddData([]byte(metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8"))
-
-	maxChunkSize := 520
-	for i := 0; i < len(indexData); i += maxChunkSize {
-		end := i + maxChunkSize
-		if end > len(indexData) {
-			end = len(indexData)
-		}
-		builder.AddFullData(indexData[i:end])
-	}
-
-	return builder.Script()
+	return scripts.MetaIdOpReturn(&scripts.MetaId{
+		Path:        path,
+		ContentType: metaid_protocols.MonitorMetaIdFileIndexContentType + ";utf-8",
+		Payload:     indexData,
+	}), nil
 }
 
 func buildIndexTxFromPreTx(netParam *chaincfg2.Params, baseTx *wire2.MsgTx, userAddress string, indexScript []byte) (*wire2.MsgTx, error) {
-	userAddressBytes, err := bsvutil2.DecodeAddress(userAddress, netParam)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode user address: %w", err)
-	}
-	userPkScript, err := txscript2.PayToAddrScript(userAddressBytes)
+	userPkScript, err := scripts.PayToMvcAddress(userAddress, netParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build user pkScript: %w", err)
 	}
@@ -2976,18 +3317,18 @@ func buildIndexTxFromPreTx(netParam *chaincfg2.Params, baseTx *wire2.MsgTx, user
 
 // --- DOGE chain helpers ---
 
-func estimateChunkFundingValueDoge(chunkScript []byte, feeRate int64) int64 {
+func estimateChunkFundingValueDoge(chunkScript []byte, feeRate, dustLimit int64) int64 {
 	const inputSize = 148
 	opReturnSize := 8 + wire.VarIntSerializeSize(uint64(len(chunkScript))) + len(chunkScript)
 	txSize := 4 + 1 + inputSize + 1 + opReturnSize + 4
 	fee := int64(txSize) * feeRate
-	if fee < 600 {
-		fee = 600
+	if fee < dustLimit {
+		fee = dustLimit
 	}
 	return fee
 }
 
-func (s *UploadService) getOrCreateFileAssistentDoge(metaID, address string, netParam *chaincfg.Params) (*model.FileAssistent, error) {
+func (s *UploadService) getOrCreateFileAssistentDoge(metaID, address string, netParam *chaincfg.Params, dryRun bool) (*model.FileAssistent, error) {
 	assistent, err := s.fileAssistentDAO.GetByAddress(address)
 	if err != nil {
 		return nil, err
@@ -2996,6 +3337,27 @@ func (s *UploadService) getOrCreateFileAssistentDoge(metaID, address string, net
 		return assistent, nil
 	}
 
+	newAssistent, err := newFileAssistentDoge(metaID, address, netParam)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		// Throwaway assistant: dry-run transactions are never broadcast
+		return newAssistent, nil
+	}
+
+	if err := s.fileAssistentDAO.Create(newAssistent); err != nil {
+		return nil, fmt.Errorf("failed to create assistent: %w", err)
+	}
+
+	log.Printf("Created new DOGE file assistent for user address %s, assistent address: %s", address, newAssistent.AssistentAddress)
+	return newAssistent, nil
+}
+
+// newFileAssistentDoge generates a new DOGE assistant key and address for a
+// user, not yet saved.
+func newFileAssistentDoge(metaID, address string, netParam *chaincfg.Params) (*model.FileAssistent, error) {
 	privateKey, err := btcec.NewPrivateKey()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate assistent private key: %w", err)
@@ -3008,20 +3370,13 @@ func (s *UploadService) getOrCreateFileAssistentDoge(metaID, address string, net
 		return nil, fmt.Errorf("failed to derive assistent address: %w", err)
 	}
 
-	newAssistent := &model.FileAssistent{
+	return &model.FileAssistent{
 		MetaId:           metaID,
 		Address:          address,
 		AssistentAddress: addr.EncodeAddress(),
 		AssistentPriHex:  privateKeyHex,
 		Status:           model.StatusSuccess,
-	}
-
-	if err := s.fileAssistentDAO.Create(newAssistent); err != nil {
-		return nil, fmt.Errorf("failed to create assistent: %w", err)
-	}
-
-	log.Printf("Created new DOGE file assistent for user address %s, assistent address: %s", address, newAssistent.AssistentAddress)
-	return newAssistent, nil
+	}, nil
 }
 
 func (s *UploadService) buildChunkTxWithFundingDoge(input *common.TxInputUtxo, chunkScript []byte) (*wire.MsgTx, error) {
@@ -3066,11 +3421,7 @@ func (s *UploadService) buildChunkTxWithFundingDoge(input *common.TxInputUtxo, c
 }
 
 func buildIndexTxFromPreTxDoge(netParam *chaincfg.Params, baseTx *wire.MsgTx, userAddress string, indexScript []byte) (*wire.MsgTx, error) {
-	addr, err := btcutil.DecodeAddress(userAddress, netParam)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode user address: %w", err)
-	}
-	userPkScript, err := txscript.PayToAddrScript(addr)
+	userPkScript, err := scripts.PayToDogeAddress(userAddress, netParam)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build user pkScript: %w", err)
 	}
@@ -3350,8 +3701,16 @@ func (s *UploadService) CleanupExpiredUploads(beforeTime time.Time, batchSize in
 		if upload.Status != model.MultipartUploadStatusCompleted {
 			s.storage.AbortMultipartUpload(upload.Key, upload.UploadId)
 		} else {
-			// If completed, delete the file from storage
-			s.storage.Delete(upload.Key)
+			// If completed, delete the file from storage unless a newer upload
+			// of the same name reuses the key
+			err := storage.SafeDelete(s.storage, upload.Key, storage.DeleteGuard{
+				Refs: func(key string) (int64, error) {
+					return s.multipartUploadDAO.CountLiveByKey(key, upload.ID)
+				},
+			})
+			if err != nil {
+				log.Printf("Kept file of expired upload (uploadId=%s): %v", upload.UploadId, err)
+			}
 		}
 
 		// Mark as expired in database
@@ -0,0 +1,62 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+
+	"meta-file-system/common"
+	"meta-file-system/conf"
+)
+
+func TestDirectUpload_DryRunBuildsWithoutBroadcastOrSave(t *testing.T) {
+	prev := conf.Cfg
+	conf.Cfg = &conf.Config{Net: "testnet", Uploader: conf.UploaderConfig{MaxFileSize: 1024 * 1024, FeeRate: 5}}
+	t.Cleanup(func() { conf.Cfg = prev })
+
+	preTx := wire2.NewMsgTx(10)
+	preTx.AddTxIn(wire2.NewTxIn(wire2.NewOutPoint(&chainhash2.Hash{1}, 0), nil))
+	var buf bytes.Buffer
+	if err := preTx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// The uploader DB is not initialised: any save or broadcast would fail
+	s := &UploadService{}
+	resp, err := s.DirectUpload(&DirectUploadRequest{
+		MetaId:      "meta1",
+		FileName:    "hello.txt",
+		Content:     []byte("hello world"),
+		Path:        "/file",
+		ContentType: "text/plain",
+		PreTxHex:    hex.EncodeToString(buf.Bytes()),
+		DryRun:      true,
+	})
+	if err != nil {
+		t.Fatalf("DirectUpload: %v", err)
+	}
+
+	if !resp.DryRun || resp.Status != statusDryRun {
+		t.Errorf("dryRun=%v status=%q, want dry run", resp.DryRun, resp.Status)
+	}
+	if resp.TxHex == "" {
+		t.Fatal("missing transaction hex")
+	}
+	if txID := common.GetMvcTxhashFromRaw(resp.TxHex); txID != resp.TxId {
+		t.Errorf("txId = %s, hex hashes to %s", resp.TxId, txID)
+	}
+	if resp.PinId != resp.TxId+"i0" {
+		t.Errorf("pinId = %s, want %si0", resp.PinId, resp.TxId)
+	}
+
+	tx, err := decodeMvcTx(resp.TxHex)
+	if err != nil {
+		t.Fatalf("decode tx: %v", err)
+	}
+	if len(tx.TxOut) != 1 || !bytes.Contains(tx.TxOut[0].PkScript, []byte("hello world")) {
+		t.Errorf("tx outputs do not carry the MetaID payload: %+v", tx.TxOut)
+	}
+}
@@ -0,0 +1,246 @@
+package upload_service
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"meta-file-system/conf"
+)
+
+var (
+	// ErrUrlFetchDisabled uploader.url_fetch is not enabled
+	ErrUrlFetchDisabled = errors.New("url fetch is disabled")
+	// ErrUrlFetchRejected the URL, its address or the fetched content is not allowed
+	ErrUrlFetchRejected = errors.New("url fetch rejected")
+	// ErrUrlFetchFailed the remote server could not be reached or answered with an error
+	ErrUrlFetchFailed = errors.New("url fetch failed")
+)
+
+// maxUrlFetchRedirects redirects followed before a fetch is given up
+const maxUrlFetchRedirects = 5
+
+// UrlFetchRequest asks the uploader to download a remote file into storage
+type UrlFetchRequest struct {
+	SourceURL string // http(s) URL of the file
+	FileName  string // File name (empty = last segment of the URL path)
+	Chain     string // Chain the file will be uploaded to; bounds the size (default mvc)
+}
+
+// UrlFetchResponse a fetched file, ready for the chunked upload endpoints
+type UrlFetchResponse struct {
+	StorageKey  string `json:"storageKey"`  // Pass as storageKey to estimate-chunked-upload, chunked-upload or chunked-upload-task
+	SourceURL   string `json:"sourceUrl"`   // Requested URL
+	FinalURL    string `json:"finalUrl"`    // URL the content was served from, after redirects
+	FileName    string `json:"fileName"`    // File name to upload under
+	FileSize    int64  `json:"fileSize"`    // Size in bytes
+	ContentType string `json:"contentType"` // Content type from the response, sniffed when missing or generic
+	FileMd5     string `json:"fileMd5"`     // Hex MD5 of the content
+	FileHash    string `json:"fileHash"`    // Hex SHA256 of the content
+}
+
+// FetchFromURL downloads req.SourceURL into storage under a content-addressed
+// key, enforcing uploader.url_fetch: allowed schemes (redirects included), no
+// private network addresses, content types and a size limit checked while the
+// body streams in. The returned storage key feeds the chunked upload
+// endpoints like a completed multipart upload does.
+func (s *UploadService) FetchFromURL(ctx context.Context, req *UrlFetchRequest) (*UrlFetchResponse, error) {
+	cfg := conf.Cfg.Uploader.UrlFetch
+	if !cfg.Enabled {
+		return nil, ErrUrlFetchDisabled
+	}
+	source, err := validateFetchURL(strings.TrimSpace(req.SourceURL), cfg.AllowedSchemes)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := req.Chain
+	if chain == "" {
+		chain = "mvc"
+	}
+	maxSize, _, _ := conf.GetUploaderChainParam(chain)
+	if cfg.MaxSizeMB > 0 {
+		maxSize = cfg.MaxSizeMB * 1024 * 1024
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, source.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUrlFetchRejected, err)
+	}
+	client := newUrlFetchClient(cfg)
+	defer client.CloseIdleConnections()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		if errors.Is(err, ErrUrlFetchRejected) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUrlFetchFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s answered %s", ErrUrlFetchFailed, source.Host, resp.Status)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, fmt.Errorf("%w: file is %d bytes, limit %d", ErrUrlFetchRejected, resp.ContentLength, maxSize)
+	}
+	contentType := fetchedMediaType(resp.Header.Get("Content-Type"))
+	if contentType != "" && !contentTypeAllowed(contentType, cfg.AllowedContentTypes) {
+		return nil, fmt.Errorf("%w: content type %s is not allowed", ErrUrlFetchRejected, contentType)
+	}
+
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	content, err := io.ReadAll(io.TeeReader(io.LimitReader(resp.Body, maxSize+1), io.MultiWriter(md5Hash, sha256Hash)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading body: %v", ErrUrlFetchFailed, err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%w: file exceeds the %d byte limit", ErrUrlFetchRejected, maxSize)
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrUrlFetchRejected)
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = fetchedMediaType(http.DetectContentType(content))
+		if !contentTypeAllowed(contentType, cfg.AllowedContentTypes) {
+			return nil, fmt.Errorf("%w: content type %s is not allowed", ErrUrlFetchRejected, contentType)
+		}
+	}
+
+	finalURL := resp.Request.URL
+	fileName := strings.TrimSpace(req.FileName)
+	if fileName == "" {
+		fileName = path.Base(finalURL.Path)
+	}
+	fileName = fetchedFileName(fileName)
+
+	// Content-addressed, so fetching the same file twice reuses the key
+	fileHash := hex.EncodeToString(sha256Hash.Sum(nil))
+	key := fmt.Sprintf("uploads/url/%s/%s/%s", time.Now().Format("2006/01/02"), fileHash, fileName)
+	if err := s.storage.Save(key, content); err != nil {
+		return nil, fmt.Errorf("failed to save fetched file: %w", err)
+	}
+
+	return &UrlFetchResponse{
+		StorageKey:  key,
+		SourceURL:   source.String(),
+		FinalURL:    finalURL.String(),
+		FileName:    fileName,
+		FileSize:    int64(len(content)),
+		ContentType: contentType,
+		FileMd5:     hex.EncodeToString(md5Hash.Sum(nil)),
+		FileHash:    fileHash,
+	}, nil
+}
+
+// validateFetchURL parses raw and checks its scheme against schemes
+func validateFetchURL(raw string, schemes []string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid url %q", ErrUrlFetchRejected, raw)
+	}
+	if u.User != nil {
+		return nil, fmt.Errorf("%w: credentials in urls are not allowed", ErrUrlFetchRejected)
+	}
+	for _, scheme := range schemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: scheme %q is not allowed (allowed: %s)", ErrUrlFetchRejected, u.Scheme, strings.Join(schemes, ", "))
+}
+
+// newUrlFetchClient returns an HTTP client that re-checks the scheme of every
+// redirect and, unless private networks are allowed, refuses to connect to
+// loopback, private, link-local and other non-public addresses. The check runs
+// on the resolved address at dial time, so DNS names pointing inside the
+// network are refused too.
+func newUrlFetchClient(cfg conf.UploadUrlFetchConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: address %s is not public", ErrUrlFetchRejected, host)
+			}
+			return nil
+		}
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			if len(via) >= maxUrlFetchRedirects {
+				return fmt.Errorf("%w: more than %d redirects", ErrUrlFetchRejected, maxUrlFetchRedirects)
+			}
+			_, err := validateFetchURL(r.URL.String(), cfg.AllowedSchemes)
+			return err
+		},
+	}
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	// Carrier-grade NAT (100.64.0.0/10) is not covered by IsPrivate
+	if ip4 := ip.To4(); ip4 != nil && ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+		return false
+	}
+	return true
+}
+
+// fetchedFileName makes name safe as the last storage key segment
+func fetchedFileName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "download"
+	}
+	return name
+}
+
+// fetchedMediaType returns the lower-case media type of a Content-Type value
+func fetchedMediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(mediaType)
+}
+
+// contentTypeAllowed matches contentType against allowed entries, either exact
+// ("image/png") or a whole type ("image/*"); an empty list allows everything
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == contentType || (strings.HasSuffix(entry, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(entry, "*"))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package upload_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"meta-file-system/conf"
+	"meta-file-system/database"
+	"meta-file-system/model"
+	"meta-file-system/model/dao"
+)
+
+// ErrUtxoLocked is returned (as a *UtxoLockedError) when an upload spends an
+// output reserved by another upload in flight
+var ErrUtxoLocked = errors.New("input is reserved by another upload")
+
+// UtxoLockedError an input of the upload is held by another upload until it
+// broadcasts, fails or the lock expires
+type UtxoLockedError struct {
+	Chain     string
+	TxID      string
+	Vout      uint32
+	ExpiresAt time.Time
+}
+
+func (e *UtxoLockedError) Error() string {
+	return fmt.Sprintf("%s: %s:%d on %s until %s; wait for the other upload or build the transactions from other outputs",
+		ErrUtxoLocked, e.TxID, e.Vout, e.Chain, e.ExpiresAt.UTC().Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrUtxoLocked) match
+func (e *UtxoLockedError) Is(target error) bool {
+	return target == ErrUtxoLocked
+}
+
+// RetryAfterSeconds seconds until the lock expires, at least 1
+func (e *UtxoLockedError) RetryAfterSeconds() int {
+	seconds := int(time.Until(e.ExpiresAt).Seconds()) + 1
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// uploadInputOutpoints the outputs spent by the transactions, sorted and
+// without duplicates. Empty transactions are skipped.
+func uploadInputOutpoints(chain string, txHexes ...string) ([]model.UtxoLock, error) {
+	seen := make(map[ledgerOutPoint]bool)
+	var outpoints []model.UtxoLock
+	for _, txHex := range txHexes {
+		if strings.TrimSpace(txHex) == "" {
+			continue
+		}
+		lt, err := decodeChainTx(chain, txHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode transaction inputs: %w", err)
+		}
+		for _, in := range lt.inputs {
+			if seen[in] {
+				continue
+			}
+			seen[in] = true
+			outpoints = append(outpoints, model.UtxoLock{Chain: chain, TxId: in.txID, Vout: in.vout})
+		}
+	}
+	sort.Slice(outpoints, func(i, j int) bool {
+		if outpoints[i].TxId != outpoints[j].TxId {
+			return outpoints[i].TxId < outpoints[j].TxId
+		}
+		return outpoints[i].Vout < outpoints[j].Vout
+	})
+	return outpoints, nil
+}
+
+// uploadLockOwner owner of the locks of an upload: its task, or a new id for
+// an upload served within the request
+func uploadLockOwner(task *model.FileUploaderTask) string {
+	if task != nil {
+		return "task:" + task.TaskId
+	}
+	return "upload:" + uuid.NewString()
+}
+
+// lockUploadInputs reserves the inputs of the upload's transactions under
+// owner (see uploadLockOwner) for uploader.utxo_lock.ttl_minutes, counted
+// from the broadcast time of a scheduled task. Returns a *UtxoLockedError when
+// another upload holds one of them, with none of them locked. Does nothing
+// when uploader.utxo_lock.enabled is off.
+func (s *UploadService) lockUploadInputs(owner, chain string, task *model.FileUploaderTask, txHexes ...string) error {
+	cfg := conf.Cfg.Uploader.UtxoLock
+	if !cfg.Enabled || database.UploaderDB == nil {
+		return nil
+	}
+	outpoints, err := uploadInputOutpoints(chain, txHexes...)
+	if err != nil || len(outpoints) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	from := now
+	if task != nil && task.BroadcastAt != nil && task.BroadcastAt.After(now) {
+		from = *task.BroadcastAt
+	}
+	conflict, err := s.utxoLockDAO.Acquire(outpoints, owner, from.Add(time.Duration(cfg.TTLMinutes)*time.Minute), now)
+	if err != nil {
+		return fmt.Errorf("failed to lock upload inputs: %w", err)
+	}
+	if conflict != nil {
+		return &UtxoLockedError{Chain: conflict.Chain, TxID: conflict.TxId, Vout: conflict.Vout, ExpiresAt: conflict.ExpiresAt}
+	}
+	return nil
+}
+
+// releaseUtxoLocks frees the inputs reserved by owner
+func (s *UploadService) releaseUtxoLocks(owner string) {
+	releaseInputLocks(s.utxoLockDAO, owner)
+}
+
+// releaseInputLocks frees the inputs reserved by owner. A failure is only
+// logged: the locks expire anyway.
+func releaseInputLocks(lockDAO *dao.UtxoLockDAO, owner string) {
+	if !conf.Cfg.Uploader.UtxoLock.Enabled || database.UploaderDB == nil {
+		return
+	}
+	if err := lockDAO.Release(owner); err != nil {
+		log.Printf("Failed to release upload input locks of %s: %v", owner, err)
+	}
+}
+
+// DeleteExpiredUtxoLocks drops input locks past uploader.utxo_lock.ttl_minutes.
+// Returns the number of locks deleted.
+func (s *UploadService) DeleteExpiredUtxoLocks(now time.Time) (int64, error) {
+	n, err := s.utxoLockDAO.DeleteExpired(now)
+	if err != nil {
+		return n, fmt.Errorf("failed to delete expired input locks: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,70 @@
+package upload_service
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	chainhash2 "github.com/bitcoinsv/bsvd/chaincfg/chainhash"
+	wire2 "github.com/bitcoinsv/bsvd/wire"
+)
+
+// testSpendingTxHex an MVC transaction spending the outpoints
+func testSpendingTxHex(t *testing.T, outpoints ...wire2.OutPoint) string {
+	t.Helper()
+	tx := wire2.NewMsgTx(10)
+	for i := range outpoints {
+		tx.AddTxIn(wire2.NewTxIn(&outpoints[i], nil))
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(buf.Bytes())
+}
+
+func TestUploadInputOutpoints(t *testing.T) {
+	a, b := chainhash2.Hash{2}, chainhash2.Hash{1}
+	merge := testSpendingTxHex(t, *wire2.NewOutPoint(&a, 3), *wire2.NewOutPoint(&b, 1))
+	index := testSpendingTxHex(t, *wire2.NewOutPoint(&b, 1), *wire2.NewOutPoint(&b, 0))
+
+	outpoints, err := uploadInputOutpoints("mvc", merge, "", index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{b.String() + ":0", b.String() + ":1", a.String() + ":3"}
+	if len(outpoints) != len(want) {
+		t.Fatalf("outpoints = %+v, want %v", outpoints, want)
+	}
+	for i, op := range outpoints {
+		if got := fmt.Sprintf("%s:%d", op.TxId, op.Vout); got != want[i] || op.Chain != "mvc" {
+			t.Errorf("outpoint %d = %s on %s, want %s", i, got, op.Chain, want[i])
+		}
+	}
+
+	if _, err := uploadInputOutpoints("mvc", "not hex"); err == nil {
+		t.Error("expected an error for an undecodable transaction")
+	}
+}
+
+func TestUtxoLockedError(t *testing.T) {
+	err := error(&UtxoLockedError{Chain: "mvc", TxID: strings.Repeat("ab", 32), Vout: 1, ExpiresAt: time.Now().Add(90 * time.Second)})
+	if !errors.Is(err, ErrUtxoLocked) {
+		t.Fatal("errors.Is(err, ErrUtxoLocked) = false")
+	}
+	var locked *UtxoLockedError
+	if !errors.As(err, &locked) {
+		t.Fatal("errors.As failed")
+	}
+	if got := locked.RetryAfterSeconds(); got < 89 || got > 91 {
+		t.Errorf("RetryAfterSeconds = %d, want about 90", got)
+	}
+	locked.ExpiresAt = time.Now().Add(-time.Minute)
+	if got := locked.RetryAfterSeconds(); got != 1 {
+		t.Errorf("expired lock RetryAfterSeconds = %d, want 1", got)
+	}
+}
@@ -0,0 +1,129 @@
+// Package shutdown stops the components of a service together within one
+// deadline (shutdown.timeout_secs). Every component is told to stop at the
+// same time, so a slow one does not hold up the others: HTTP listeners drain
+// their requests while scanners finish their block and upload tasks reach
+// their next stage. The service exits once all of them stopped or the
+// deadline passed.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Manager the components of a service to stop on shutdown
+type Manager struct {
+	timeout time.Duration
+	steps   []step
+}
+
+type step struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// NewManager a manager stopping every component within timeout
+func NewManager(timeout time.Duration) *Manager {
+	return &Manager{timeout: timeout}
+}
+
+// Add registers a component. stop must return once the component stopped,
+// or with ctx's error once ctx is done.
+func (m *Manager) Add(name string, stop func(ctx context.Context) error) {
+	m.steps = append(m.steps, step{name: name, stop: stop})
+}
+
+// Shutdown stops every component concurrently and waits for them until the
+// deadline. The error names the components that failed to stop or were
+// still stopping at the deadline; those are abandoned.
+func (m *Manager) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	start := time.Now()
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(m.steps))
+	for _, s := range m.steps {
+		go func(s step) {
+			results <- result{name: s.name, err: s.stop(ctx)}
+		}(s)
+	}
+
+	pending := make(map[string]bool, len(m.steps))
+	for _, s := range m.steps {
+		pending[s.name] = true
+	}
+	var errs []error
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r.name, r.err))
+				continue
+			}
+			log.Printf("Stopped %s in %v", r.name, time.Since(start).Round(time.Millisecond))
+		case <-ctx.Done():
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				errs = append(errs, fmt.Errorf("%s: still stopping after %v", name, m.timeout))
+			}
+			return errors.Join(errs...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Within runs stop, which may block, and waits until it returns or ctx is
+// done. stop keeps running in the background after ctx is done.
+func Within(ctx context.Context, stop func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stop()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitForSignal blocks until SIGINT or SIGTERM, calling reload (if not nil)
+// on every SIGHUP. A second SIGINT or SIGTERM while the service shuts down
+// exits at once.
+func WaitForSignal(reload func()) {
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		if reload != nil {
+			reload()
+		}
+	}
+	go func() {
+		for sig := range sigChan {
+			if sig != syscall.SIGHUP {
+				log.Printf("Received %v during shutdown, exiting now", sig)
+				os.Exit(1)
+			}
+		}
+	}()
+}
@@ -0,0 +1,65 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagerStopsComponentsTogether(t *testing.T) {
+	m := NewManager(time.Second)
+	release := make(chan struct{})
+	m.Add("http", func(ctx context.Context) error {
+		close(release) // Runs while "scanner" is still stopping
+		return nil
+	})
+	m.Add("scanner", func(ctx context.Context) error {
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	if err := m.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagerDeadline(t *testing.T) {
+	m := NewManager(50 * time.Millisecond)
+	m.Add("fast", func(ctx context.Context) error { return nil })
+	m.Add("failing", func(ctx context.Context) error { return errors.New("broken") })
+	m.Add("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Hour)
+		return nil
+	})
+
+	start := time.Now()
+	err := m.Shutdown()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown took %v", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "failing: broken") || !strings.Contains(err.Error(), "stuck: still stopping") {
+		t.Errorf("err = %v", err)
+	}
+	if strings.Contains(err.Error(), "fast") {
+		t.Errorf("stopped component reported: %v", err)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	if err := Within(context.Background(), func() {}); err != nil {
+		t.Errorf("returning stop: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	block := make(chan struct{})
+	defer close(block)
+	if err := Within(ctx, func() { <-block }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("blocked stop: err = %v", err)
+	}
+}
@@ -2,7 +2,9 @@
 -- MetaID Indexer Database Schema
 -- ============================================
 -- This file contains all table definitions for the Indexer service
--- Tables: tb_indexer_file, tb_indexer_file_chunk, tb_indexer_user_avatar, tb_indexer_sync_status
+-- Tables: tb_indexer_file, tb_indexer_file_chunk, tb_indexer_user_avatar, tb_indexer_sync_status, tb_indexer_daily_stat,
+--         tb_indexer_counter, tb_indexer_watch, tb_indexer_watch_event, tb_indexer_outbox_event, tb_indexer_change_event,
+--         tb_indexer_domain
 -- ============================================
 
 -- --------------------------------------------
@@ -35,18 +37,24 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file` (
     -- Storage related fields
     `storage_type` VARCHAR(20) DEFAULT 'local' COMMENT 'Storage type: local/oss',
     `storage_path` VARCHAR(500) DEFAULT '' COMMENT 'Storage path',
+    `storage_layout` INT DEFAULT 0 COMMENT 'Storage path layout version (0 = 1)',
+    `storage_receipt` VARCHAR(255) DEFAULT NULL COMMENT 'Receipt of the external storage gateway',
     
     -- Blockchain related fields
     `chain_name` VARCHAR(20) NOT NULL COMMENT 'Chain name: btc/mvc',
     `block_height` BIGINT NOT NULL COMMENT 'Block height',
-    `timestamp` BIGINT NOT NULL COMMENT 'Block timestamp (seconds since epoch)',
+    `timestamp` BIGINT NOT NULL COMMENT 'Block timestamp (milliseconds since epoch), first-seen time for mempool PINs',
+    `first_seen_at` BIGINT DEFAULT 0 COMMENT 'First time the PIN was observed (ms)',
+    `confirmed_at` BIGINT DEFAULT 0 COMMENT 'Time of the confirming block (ms), 0 while unconfirmed',
     `creator_meta_id` VARCHAR(64) DEFAULT '' COMMENT 'Creator MetaID (SHA256 of address)',
     `creator_address` VARCHAR(100) DEFAULT '' COMMENT 'Creator address',
     `owner_address` VARCHAR(100) DEFAULT '' COMMENT 'Owner address (current)',
     `owner_meta_id` VARCHAR(64) DEFAULT '' COMMENT 'Owner MetaID (SHA256 of owner address)',
     
     -- Status fields
-    `status` VARCHAR(20) DEFAULT 'success' COMMENT 'Status: success/failed',
+    `status` VARCHAR(20) DEFAULT 'success' COMMENT 'Status: success/failed/rejected/pending_storage',
+    `status_reason` VARCHAR(255) DEFAULT '' COMMENT 'Why the content was rejected',
+    `resolution_pending` TINYINT(1) DEFAULT 0 COMMENT 'Creator address is the fallback, lookup queued for retry',
     `state` INT(11) DEFAULT 0 COMMENT 'State: 0=EXIST, 2=DELETED',
     
     -- Timestamps
@@ -62,6 +70,7 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file` (
     KEY `idx_creator_meta_id` (`creator_meta_id`),
     KEY `idx_owner_address` (`owner_address`),
     KEY `idx_chain_name` (`chain_name`),
+    KEY `idx_file_hash` (`file_hash`),
     KEY `idx_timestamp` (`timestamp`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer file metadata table';
 
@@ -84,19 +93,23 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file_chunk` (
     `chunk_index` INT NOT NULL COMMENT 'Chunk index (0-based)',
     `chunk_size` BIGINT DEFAULT 0 COMMENT 'Chunk size (bytes)',
     `chunk_md5` VARCHAR(64) DEFAULT '' COMMENT 'Chunk MD5 hash',
+    `chunk_sha256` VARCHAR(64) DEFAULT '' COMMENT 'Chunk SHA256 hash',
     `parent_pin_id` VARCHAR(255) NOT NULL COMMENT 'Parent file PIN ID',
     `is_gzip_compressed` TINYINT(1) DEFAULT 0 COMMENT 'Whether the original content was gzip compressed',
     
     -- Storage related fields
     `storage_type` VARCHAR(20) DEFAULT 'local' COMMENT 'Storage type: local/oss',
     `storage_path` VARCHAR(500) DEFAULT '' COMMENT 'Storage path',
+    `storage_layout` INT DEFAULT 0 COMMENT 'Storage path layout version (0 = 1)',
+    `storage_receipt` VARCHAR(255) DEFAULT NULL COMMENT 'Receipt of the external storage gateway',
     
     -- Blockchain related fields
     `chain_name` VARCHAR(20) NOT NULL COMMENT 'Chain name: btc/mvc',
     `block_height` BIGINT NOT NULL COMMENT 'Block height',
     
     -- Status fields
-    `status` VARCHAR(20) DEFAULT 'success' COMMENT 'Status: success/failed',
+    `status` VARCHAR(20) DEFAULT 'success' COMMENT 'Status: success/failed/rejected/pending_storage',
+    `status_reason` VARCHAR(255) DEFAULT '' COMMENT 'Why the content was rejected',
     `state` INT(11) DEFAULT 0 COMMENT 'State: 0=EXIST, 2=DELETED',
     
     -- Timestamps
@@ -108,6 +121,7 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_file_chunk` (
     KEY `idx_tx_id` (`tx_id`),
     KEY `idx_path` (`path`(255)),
     KEY `idx_parent_pin_id` (`parent_pin_id`),
+    KEY `idx_chunk_sha256` (`chunk_sha256`),
     KEY `idx_block_height` (`block_height`),
     KEY `idx_chain_name` (`chain_name`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer file chunk metadata table';
@@ -176,6 +190,132 @@ CREATE TABLE IF NOT EXISTS `tb_indexer_sync_status` (
     UNIQUE KEY `uk_chain_name` (`chain_name`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer synchronization status table';
 
+-- --------------------------------------------
+-- Table: tb_indexer_daily_stat
+-- Description: Per-day, per-chain counters maintained incrementally by the indexer
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_daily_stat` (
+    `date` VARCHAR(10) NOT NULL COMMENT 'UTC day: YYYY-MM-DD',
+    `chain_name` VARCHAR(20) NOT NULL COMMENT 'Chain name: btc/mvc/doge',
+    
+    -- Counters
+    `new_files` BIGINT NOT NULL DEFAULT 0 COMMENT 'Newly indexed file PINs',
+    `new_users` BIGINT NOT NULL DEFAULT 0 COMMENT 'MetaIDs first seen on this day',
+    `bytes` BIGINT NOT NULL DEFAULT 0 COMMENT 'Sum of indexed file sizes',
+    
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`date`, `chain_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer daily statistics table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_counter
+-- Description: Named counters (files, chunks and per-chain totals) maintained by the indexer, flushed once per block
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_counter` (
+    `name` VARCHAR(255) NOT NULL COMMENT 'Counter name, e.g. files or chunks:chain:mvc',
+    `value` BIGINT NOT NULL DEFAULT 0 COMMENT 'Current value',
+    
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer maintained counters table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_watch
+-- Description: Watchlist of addresses / MetaIDs whose new PINs are notified
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_watch` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `target` VARCHAR(255) NOT NULL COMMENT 'Watched address, MetaID or GlobalMetaID',
+    `webhook_url` VARCHAR(1024) DEFAULT NULL COMMENT 'Webhook URL events are POSTed to',
+    `label` VARCHAR(255) DEFAULT NULL COMMENT 'Client label',
+    `client` VARCHAR(64) DEFAULT NULL COMMENT 'IP of the client that created the watch',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_target` (`target`),
+    KEY `idx_client` (`client`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer watchlist table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_watch_event
+-- Description: PINs seen from watched identities
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_watch_event` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID, increasing',
+    `event` VARCHAR(20) DEFAULT NULL COMMENT 'Event kind: pin/profile_changed',
+    `target` VARCHAR(255) NOT NULL COMMENT 'Watched identity that matched',
+    `pin_id` VARCHAR(255) NOT NULL COMMENT 'PIN ID',
+    `path` VARCHAR(1024) DEFAULT NULL COMMENT 'PIN path',
+    `operation` VARCHAR(20) DEFAULT NULL COMMENT 'create/modify/revoke',
+    `content_type` VARCHAR(255) DEFAULT NULL COMMENT 'Content type',
+    `chain_name` VARCHAR(20) DEFAULT NULL COMMENT 'Chain name: btc/mvc/doge',
+    `block_height` BIGINT NOT NULL DEFAULT 0 COMMENT 'Block height, 0 in mempool',
+    `confirmed` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Confirmed in a block',
+    `timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT 'PIN timestamp (ms)',
+    `creator_address` VARCHAR(100) DEFAULT NULL COMMENT 'Creator address',
+    `creator_meta_id` VARCHAR(64) DEFAULT NULL COMMENT 'Creator MetaID',
+    `creator_global_meta_id` VARCHAR(255) DEFAULT NULL COMMENT 'Creator GlobalMetaID',
+    `profile` TEXT COMMENT 'JSON of the profile change (profile_changed events)',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_target_id` (`target`, `id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer watchlist event table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_outbox_event
+-- Description: Watch event publications (webhooks, WebSocket) not yet delivered
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_outbox_event` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID, increasing',
+    `channel` VARCHAR(20) NOT NULL COMMENT 'webhook/broadcast',
+    `watch_id` BIGINT NOT NULL DEFAULT 0 COMMENT 'Watch that matched (webhook)',
+    `label` VARCHAR(255) DEFAULT NULL COMMENT 'Watch label (webhook)',
+    `url` VARCHAR(1024) DEFAULT NULL COMMENT 'Webhook URL',
+    `event` MEDIUMTEXT COMMENT 'JSON of the watch event',
+    `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Failed deliveries',
+    `last_error` VARCHAR(1024) DEFAULT NULL COMMENT 'Last delivery failure',
+    `next_attempt_at` BIGINT NOT NULL DEFAULT 0 COMMENT 'Unix seconds of the next attempt',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    
+    PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer watch event outbox table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_change_event
+-- Description: Append-only log of indexing actions (change feed, replay)
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_change_event` (
+    `seq` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Sequence number, increasing',
+    `action` VARCHAR(40) NOT NULL COMMENT 'file_created/file_modified/file_revoked/file_confirmed/chunk_indexed/chunks_merged/user_name_updated/avatar_updated/user_bio_updated/chat_public_key_updated',
+    `chain_name` VARCHAR(20) DEFAULT NULL COMMENT 'Chain name: btc/mvc/doge',
+    `pin_id` VARCHAR(255) DEFAULT NULL COMMENT 'PIN ID',
+    `first_pin_id` VARCHAR(255) DEFAULT NULL COMMENT 'First PIN ID of the file',
+    `meta_id` VARCHAR(64) DEFAULT NULL COMMENT 'Creator MetaID',
+    `block_height` BIGINT NOT NULL DEFAULT 0 COMMENT 'Block height, 0 in mempool',
+    `timestamp` BIGINT NOT NULL DEFAULT 0 COMMENT 'PIN timestamp (ms)',
+    `record` MEDIUMTEXT COMMENT 'JSON of the written record',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+
+    PRIMARY KEY (`seq`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer change log table';
+
+-- --------------------------------------------
+-- Table: tb_indexer_domain
+-- Description: Custom domains mapped to hosted sites or MetaIDs
+-- --------------------------------------------
+CREATE TABLE IF NOT EXISTS `tb_indexer_domain` (
+    `domain` VARCHAR(255) NOT NULL COMMENT 'Lower-cased host name',
+    `target_type` VARCHAR(20) NOT NULL COMMENT 'site/metaid',
+    `target` VARCHAR(255) NOT NULL COMMENT 'Site manifest PIN ID or MetaID',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+
+    PRIMARY KEY (`domain`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Indexer custom domain table';
+
 -- --------------------------------------------
 -- Initialize default sync status records
 -- --------------------------------------------
@@ -203,6 +343,27 @@ ALTER TABLE `tb_indexer_file_chunk`
 ADD COLUMN `is_gzip_compressed` TINYINT(1) DEFAULT 0 COMMENT 'Whether the original content was gzip compressed' 
 AFTER `chunk_md5`;
 
+-- ============================================
+-- Migration: Index file_hash (mfs://{sha256} resolution)
+-- ============================================
+ALTER TABLE `tb_indexer_file`
+ADD KEY `idx_file_hash` (`file_hash`);
+
+-- ============================================
+-- Migration: Storage gateway receipts (storage.type gateway)
+-- ============================================
+ALTER TABLE `tb_indexer_file`
+ADD COLUMN `storage_receipt` VARCHAR(255) DEFAULT NULL COMMENT 'Receipt of the external storage gateway' AFTER `storage_layout`;
+ALTER TABLE `tb_indexer_file_chunk`
+ADD COLUMN `storage_receipt` VARCHAR(255) DEFAULT NULL COMMENT 'Receipt of the external storage gateway' AFTER `storage_layout`;
+
+-- ============================================
+-- Migration: Profile change watch events
+-- ============================================
+ALTER TABLE `tb_indexer_watch_event`
+ADD COLUMN `event` VARCHAR(20) DEFAULT NULL COMMENT 'Event kind: pin/profile_changed' AFTER `id`,
+ADD COLUMN `profile` TEXT COMMENT 'JSON of the profile change (profile_changed events)' AFTER `creator_global_meta_id`;
+
 -- ============================================
 -- End of Indexer Database Schema
 -- ============================================
@@ -17,6 +17,8 @@ CREATE TABLE IF NOT EXISTS `tb_file` (
     `file_md5` VARCHAR(191) DEFAULT NULL COMMENT 'File MD5',
     `file_content_type` VARCHAR(100) DEFAULT NULL COMMENT 'File content type (MIME Type)',
     `chunk_type` VARCHAR(20) DEFAULT NULL COMMENT 'Chunk type (single/multi)',
+    `is_gzip_compressed` TINYINT(1) DEFAULT 0 COMMENT 'Whether the content was inscribed gzip compressed',
+    `storage_class` VARCHAR(20) DEFAULT 'hot' COMMENT 'Storage class hint (hot/cold/ephemeral)',
     
     -- Content
     `content_hex` TEXT COMMENT 'Content hexadecimal',
@@ -51,10 +53,12 @@ CREATE TABLE IF NOT EXISTS `tb_file` (
     
     PRIMARY KEY (`id`),
     UNIQUE KEY `uk_file_id` (`file_id`),
+    KEY `idx_tx_id` (`tx_id`),
     KEY `idx_pin_id` (`pin_id`),
     KEY `idx_meta_id` (`meta_id`),
     KEY `idx_address` (`address`),
     KEY `idx_status` (`status`),
+    KEY `idx_storage_class` (`storage_class`),
     KEY `idx_created_at` (`created_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='File metadata table';
 
@@ -121,7 +125,8 @@ CREATE TABLE IF NOT EXISTS `tb_file_assistent` (
     `assistent_pri_hex` TEXT NOT NULL COMMENT 'Assistent private key (hex format, 托管地址私钥)',
     
     -- Status
-    `status` VARCHAR(20) DEFAULT 'success' COMMENT 'Status (success/failed)',
+    `status` VARCHAR(20) DEFAULT 'success' COMMENT 'Status (success/failed/retired)',
+    `retired_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Retired by a key rotation at',
     
     -- Timestamps
     `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
@@ -164,6 +169,10 @@ CREATE TABLE IF NOT EXISTS `tb_file_uploader_task` (
     `path` VARCHAR(50) DEFAULT NULL COMMENT 'MetaID path',
     `operation` VARCHAR(20) DEFAULT NULL COMMENT 'create/update',
     `content_base64` LONGTEXT COMMENT 'File content (base64 encoded)',
+    `storage_class` VARCHAR(20) DEFAULT 'hot' COMMENT 'Storage class hint (hot/cold/ephemeral)',
+    `compression` VARCHAR(20) DEFAULT NULL COMMENT 'Compression declared in the file index (none/gzip)',
+    `encryption` TEXT COMMENT 'Encryption envelope declared in the file index (JSON)',
+    `delta` TEXT COMMENT 'Delta envelope of a delta upload (JSON); the content is the delta',
     
     -- Transaction information
     `chunk_pre_tx_hex` TEXT COMMENT 'Pre-built chunk transaction',
@@ -171,9 +180,15 @@ CREATE TABLE IF NOT EXISTS `tb_file_uploader_task` (
     `merge_tx_hex` TEXT COMMENT 'Merge transaction hex',
     `fee_rate` BIGINT DEFAULT NULL COMMENT 'Fee rate',
     `chain` VARCHAR(20) DEFAULT 'mvc' COMMENT 'Blockchain (mvc/doge)',
+    `funding_strategy` VARCHAR(20) DEFAULT NULL COMMENT 'Chunk funding: assistent/hot_wallet (empty = uploader.chunk_funding.strategy)',
+    
+    -- Delayed broadcast
+    `broadcast_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Broadcast no later than this time (NULL = at once)',
+    `target_fee_rate` BIGINT DEFAULT NULL COMMENT 'Broadcast earlier once the network fee rate is at or below this',
+    `released_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'When the scheduler released the held broadcast',
     
     -- Task status and progress
-    `status` VARCHAR(20) DEFAULT 'pending' COMMENT 'pending/processing/success/failed',
+    `status` VARCHAR(20) DEFAULT 'pending' COMMENT 'pending/processing/scheduled/success/failed/cancelled',
     `progress` INT DEFAULT 0 COMMENT 'Progress percentage (0-100)',
     `total_chunks` INT DEFAULT 0 COMMENT 'Total chunks',
     `processed_chunks` INT DEFAULT 0 COMMENT 'Processed chunks',
@@ -200,7 +215,8 @@ CREATE TABLE IF NOT EXISTS `tb_file_uploader_task` (
     UNIQUE KEY `uk_task_id` (`task_id`),
     KEY `idx_status` (`status`),
     KEY `idx_created_at` (`created_at`),
-    KEY `idx_file_hash` (`file_hash`)
+    KEY `idx_file_hash` (`file_hash`),
+    KEY `idx_broadcast_at` (`broadcast_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='File uploader task table';
 
 -- =============================================
@@ -236,6 +252,267 @@ CREATE TABLE IF NOT EXISTS `tb_multipart_upload` (
     KEY `idx_created_at` (`created_at`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Multipart upload session table (temporary storage for cleanup)';
 
+-- =============================================
+-- Synchronous upload checkpoint table (tb_upload_checkpoint)
+-- =============================================
+-- Broadcast progress of chunked uploads sent with isBroadcast=true, so an upload
+-- cut off by a restart can be resumed (POST /api/v1/admin/uploads/recover or -recover-uploads)
+CREATE TABLE IF NOT EXISTS `tb_upload_checkpoint` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `file_id` VARCHAR(255) NOT NULL COMMENT 'File ID (metaid_filehash)',
+    `file_hash` VARCHAR(255) DEFAULT NULL COMMENT 'File SHA256 hash',
+    `chain` VARCHAR(20) DEFAULT 'mvc' COMMENT 'Blockchain',
+    
+    -- Broadcast progress
+    `stage` VARCHAR(50) DEFAULT 'prepared' COMMENT 'Last stage reached (prepared/merge_broadcast/funding_broadcast/chunk_broadcast/index_broadcast)',
+    `status` VARCHAR(20) DEFAULT 'pending' COMMENT 'pending/success/failed',
+    `total_chunks` INT DEFAULT 0 COMMENT 'Chunk transaction count',
+    `processed_chunks` INT DEFAULT 0 COMMENT 'Chunk transactions broadcast so far',
+    `attempts` INT DEFAULT 0 COMMENT 'Recovery runs that picked it up',
+    
+    -- Transactions (cleared once the upload leaves pending)
+    `merge_tx_hex` TEXT COMMENT 'Merge transaction hex',
+    `chunk_funding_tx` TEXT COMMENT 'Chunk funding transaction hex',
+    `chunk_tx_hexes` LONGTEXT COMMENT 'Chunk transaction hex list (JSON array)',
+    `chunk_tx_ids` TEXT COMMENT 'Chunk transaction ID list (JSON array)',
+    `index_tx_hex` TEXT COMMENT 'Index transaction hex',
+    `index_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Index transaction ID',
+    `error_message` TEXT COMMENT 'Broadcast error',
+    
+    -- Timestamps
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    `finished_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Finished at',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_file_id` (`file_id`),
+    KEY `idx_status` (`status`),
+    KEY `idx_updated_at` (`updated_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Synchronous chunked upload broadcast checkpoint table';
+
+-- =============================================
+-- Proof-of-existence table (tb_proof)
+-- =============================================
+-- SHA256 hashes inscribed under /proof/sha256 (POST /api/v1/proofs), with the
+-- block and merkle branch cached once the transaction is mined
+CREATE TABLE IF NOT EXISTS `tb_proof` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `hash` VARCHAR(64) NOT NULL COMMENT 'Notarized SHA256 (lower-case hex)',
+    `tx_id` VARCHAR(64) NOT NULL COMMENT 'Inscription transaction ID',
+    `pin_id` VARCHAR(80) NOT NULL COMMENT 'Pin ID',
+    `meta_id` VARCHAR(255) DEFAULT NULL COMMENT 'MetaID of the notarizer',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'Address of the notarizer',
+    
+    -- Block the transaction was found in
+    `block_hash` VARCHAR(64) DEFAULT NULL COMMENT 'Block hash',
+    `block_height` BIGINT DEFAULT 0 COMMENT 'Block height',
+    `merkle_root` VARCHAR(64) DEFAULT NULL COMMENT 'Block merkle root',
+    `tx_index` INT DEFAULT 0 COMMENT 'Transaction position in the block',
+    `merkle_path` LONGTEXT COMMENT 'Merkle branch (JSON array, leaf level first)',
+    
+    -- Timestamps
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_pin_id` (`pin_id`),
+    KEY `idx_hash` (`hash`),
+    KEY `idx_tx_id` (`tx_id`),
+    KEY `idx_address` (`address`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Proof-of-existence timestamp table';
+
+-- =============================================
+-- Assistent output ledger (tb_assistent_utxo)
+-- =============================================
+-- Outputs paying assistent addresses, recorded when the uploader broadcasts
+-- them and marked spent when it broadcasts their spending transaction
+-- (GET /api/v1/admin/assistants)
+CREATE TABLE IF NOT EXISTS `tb_assistent_utxo` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `chain` VARCHAR(20) DEFAULT NULL COMMENT 'Blockchain (mvc/doge)',
+    `meta_id` VARCHAR(255) DEFAULT NULL COMMENT 'User MetaID',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'User address',
+    `assistent_address` VARCHAR(100) DEFAULT NULL COMMENT 'Assistent address holding the output',
+    `tx_id` VARCHAR(64) NOT NULL COMMENT 'Transaction creating the output',
+    `vout` INT UNSIGNED NOT NULL COMMENT 'Output index',
+    `value` BIGINT DEFAULT 0 COMMENT 'Satoshis',
+    `is_change` TINYINT(1) DEFAULT 0 COMMENT 'Created by a transaction spending assistent outputs',
+    `spent_tx_id` VARCHAR(64) DEFAULT '' COMMENT 'Spending transaction, empty while unspent',
+    `spent_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Spent at',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_assistent_outpoint` (`tx_id`, `vout`),
+    KEY `idx_chain` (`chain`),
+    KEY `idx_address` (`address`),
+    KEY `idx_assistent_address` (`assistent_address`),
+    KEY `idx_spent_tx_id` (`spent_tx_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Assistent address output ledger';
+
+-- =============================================
+-- Delegated upload charges (tb_delegated_charge)
+-- =============================================
+-- Cost of uploads funded by the operator hot wallet (uploader.delegated),
+-- invoiced to the user until settled (GET /api/v1/admin/delegated/invoices)
+CREATE TABLE IF NOT EXISTS `tb_delegated_charge` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `client` VARCHAR(100) DEFAULT NULL COMMENT 'API client that requested the upload',
+    `file_id` VARCHAR(255) DEFAULT NULL COMMENT 'Uploaded file',
+    `meta_id` VARCHAR(255) DEFAULT NULL COMMENT 'User MetaID',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'User address, invoiced',
+    `chain` VARCHAR(20) DEFAULT NULL COMMENT 'Blockchain (mvc)',
+    `status` VARCHAR(20) DEFAULT NULL COMMENT 'unsettled/settled',
+    `upload_status` VARCHAR(20) DEFAULT NULL COMMENT 'Upload outcome: success/pending/failed',
+    `message` VARCHAR(500) DEFAULT NULL COMMENT 'Upload outcome message',
+    `chunk_funding_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Hot wallet payment funding the chunk transactions',
+    `index_funding_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Hot wallet payment funding the index transaction',
+    `index_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Index transaction of the upload',
+    `file_size` BIGINT DEFAULT 0 COMMENT 'Bytes uploaded',
+    `amount` BIGINT DEFAULT 0 COMMENT 'Satoshis sent by the hot wallet',
+    `settlement_ref` VARCHAR(255) DEFAULT NULL COMMENT 'Payment reference given when settling',
+    `settled_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Settled at',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_client` (`client`),
+    KEY `idx_file_id` (`file_id`),
+    KEY `idx_address` (`address`),
+    KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Delegated upload charges';
+
+-- =============================================
+-- Upload idempotency keys (tb_upload_idempotency)
+-- =============================================
+-- Idempotency-Key headers of upload requests and the response of the first
+-- request, replayed to retries until expires_at (uploader.idempotency)
+CREATE TABLE IF NOT EXISTS `tb_upload_idempotency` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `scope` VARCHAR(100) DEFAULT NULL COMMENT 'Route the key was used on',
+    `idem_key` VARCHAR(255) DEFAULT NULL COMMENT 'Idempotency-Key header',
+    `fingerprint` VARCHAR(64) DEFAULT NULL COMMENT 'SHA256 of the first request',
+    `status` VARCHAR(20) DEFAULT NULL COMMENT 'processing/completed',
+    `response` LONGBLOB COMMENT 'Response body of the first request',
+    `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Key dropped after',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `uk_scope_key` (`scope`, `idem_key`),
+    KEY `idx_status` (`status`),
+    KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Upload idempotency keys';
+
+-- =============================================
+-- Assistent key rotations (tb_assistent_rotation)
+-- =============================================
+-- Audit trail of assistent keys replaced through
+-- POST /api/v1/admin/assistants/{address}/rotate and of the sweeps of the
+-- retired addresses
+CREATE TABLE IF NOT EXISTS `tb_assistent_rotation` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `chain` VARCHAR(20) DEFAULT NULL COMMENT 'Blockchain (mvc/doge)',
+    `meta_id` VARCHAR(255) DEFAULT NULL COMMENT 'User MetaID',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'User address',
+    `old_assistent_id` BIGINT DEFAULT 0 COMMENT 'Retired tb_file_assistent row',
+    `old_assistent_address` VARCHAR(100) DEFAULT NULL COMMENT 'Retired assistent address',
+    `new_assistent_id` BIGINT DEFAULT 0 COMMENT 'tb_file_assistent row replacing it',
+    `new_assistent_address` VARCHAR(100) DEFAULT NULL COMMENT 'New assistent address',
+    `reason` VARCHAR(500) DEFAULT NULL COMMENT 'Why the key was rotated',
+    `requested_by` VARCHAR(100) DEFAULT NULL COMMENT 'Client IP of the admin request',
+    `sweep_to` VARCHAR(20) DEFAULT NULL COMMENT 'assistent/user',
+    `sweep_address` VARCHAR(100) DEFAULT NULL COMMENT 'Address the outputs were swept to',
+    `sweep_status` VARCHAR(20) DEFAULT NULL COMMENT 'none/success/failed',
+    `sweep_tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Latest sweep transaction',
+    `swept_outputs` INT DEFAULT 0 COMMENT 'Outputs swept so far',
+    `swept_value` BIGINT DEFAULT 0 COMMENT 'Satoshis received by sweep_address',
+    `sweep_fee` BIGINT DEFAULT 0 COMMENT 'Satoshis paid in sweep fees',
+    `sweep_error` TEXT COMMENT 'Why the latest sweep failed',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    KEY `idx_address` (`address`),
+    KEY `idx_old_assistent_address` (`old_assistent_address`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Assistent key rotations';
+
+-- =============================================
+-- Chunk funding hot wallet outputs (tb_hot_wallet_utxo)
+-- =============================================
+-- Outputs of the operator hot wallet funding chunk transactions
+-- (uploader.chunk_funding.strategy hot_wallet): deposits recorded through
+-- POST /api/v1/admin/hot-wallet/deposits and the change of funding
+-- transactions, locked by the upload spending them until it broadcasts
+CREATE TABLE IF NOT EXISTS `tb_hot_wallet_utxo` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `chain` VARCHAR(20) DEFAULT NULL COMMENT 'Blockchain (mvc)',
+    `address` VARCHAR(100) DEFAULT NULL COMMENT 'Hot wallet address holding the output',
+    `tx_id` VARCHAR(64) NOT NULL COMMENT 'Transaction creating the output',
+    `vout` INT UNSIGNED NOT NULL COMMENT 'Output index',
+    `value` BIGINT DEFAULT 0 COMMENT 'Satoshis',
+    `is_change` TINYINT(1) DEFAULT 0 COMMENT 'Change of a funding transaction',
+    `status` VARCHAR(20) DEFAULT NULL COMMENT 'available/locked/pending/spent',
+    `lock_id` VARCHAR(64) DEFAULT '' COMMENT 'Funding txid holding the lock',
+    `locked_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Locked at',
+    `spent_tx_id` VARCHAR(64) DEFAULT '' COMMENT 'Spending transaction',
+    `spent_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Spent at',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_hot_wallet_outpoint` (`tx_id`, `vout`),
+    KEY `idx_chain` (`chain`),
+    KEY `idx_address` (`address`),
+    KEY `idx_status` (`status`),
+    KEY `idx_lock_id` (`lock_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Chunk funding hot wallet outputs';
+
+-- =============================================
+-- Reserved upload inputs (tb_utxo_lock)
+-- =============================================
+-- Outputs spent by an upload in flight (uploader.utxo_lock): held until the
+-- upload broadcasts or fails, so concurrent uploads cannot spend them
+CREATE TABLE IF NOT EXISTS `tb_utxo_lock` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `chain` VARCHAR(20) DEFAULT NULL COMMENT 'Blockchain (mvc/doge)',
+    `tx_id` VARCHAR(64) DEFAULT NULL COMMENT 'Transaction creating the output',
+    `vout` INT UNSIGNED DEFAULT NULL COMMENT 'Output index',
+    `owner` VARCHAR(128) DEFAULT NULL COMMENT 'Holder: task:{taskId}, upload:{id} or sweep:{rotationId}',
+    `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'The output is free again after this',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_utxo_lock_outpoint` (`chain`, `tx_id`, `vout`),
+    KEY `idx_owner` (`owner`),
+    KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Reserved upload inputs';
+
+-- =============================================
+-- Owner-kept files (tb_file_retention)
+-- =============================================
+-- Files their owner asked to keep with a signed request (uploader.keep):
+-- never pruned, whatever their storage class, until unkept
+CREATE TABLE IF NOT EXISTS `tb_file_retention` (
+    `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Primary key ID',
+    `pin_id` VARCHAR(80) DEFAULT NULL COMMENT 'Kept PIN',
+    `file_id` VARCHAR(255) DEFAULT NULL COMMENT 'Uploaded file record',
+    `owner` VARCHAR(100) DEFAULT NULL COMMENT 'Uploader address of the file',
+    `file_size` BIGINT DEFAULT NULL COMMENT 'Counted against the owner quota',
+    `status` VARCHAR(20) DEFAULT NULL COMMENT 'active/released',
+    `public_key` VARCHAR(130) DEFAULT NULL COMMENT 'Key that signed the latest request',
+    `signature` VARCHAR(150) DEFAULT NULL COMMENT 'Latest request signature (DER, hex)',
+    `signed_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Timestamp of the latest request; older requests are refused',
+    `kept_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'When the file was (last) kept',
+    `released_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'When it was unkept',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Creation time',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Update time',
+    
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_tb_file_retention_pin_id` (`pin_id`),
+    KEY `idx_file_retention_owner` (`owner`, `status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Owner-kept files';
+
 -- =============================================
 -- Composite index optimization(optional, add based on query needs)
 -- =============================================
@@ -260,5 +537,45 @@ CREATE TABLE IF NOT EXISTS `tb_multipart_upload` (
 -- Run if upgrading to DOGE index inscription (commit+reveal):
 -- ALTER TABLE tb_file_uploader_task ADD COLUMN index_tx_hexes TEXT COMMENT 'DOGE: index txs [commitHex, revealHex]' AFTER chunk_tx_hexes;
 
+-- =============================================
+-- Migration: add storage class hint
+-- =============================================
+-- Run if upgrading from version without storage_class:
+-- ALTER TABLE tb_file ADD COLUMN storage_class VARCHAR(20) DEFAULT 'hot' COMMENT 'Storage class hint (hot/cold/ephemeral)' AFTER is_gzip_compressed, ADD INDEX idx_storage_class (storage_class);
+-- ALTER TABLE tb_file_uploader_task ADD COLUMN storage_class VARCHAR(20) DEFAULT 'hot' COMMENT 'Storage class hint (hot/cold/ephemeral)' AFTER content_base64;
 
+-- =============================================
+-- Migration: MetaFileIndex v2 compression/encryption
+-- =============================================
+-- Run if upgrading from version without compression/encryption on tasks:
+-- ALTER TABLE tb_file_uploader_task ADD COLUMN compression VARCHAR(20) DEFAULT NULL COMMENT 'Compression declared in the file index (none/gzip)' AFTER storage_class, ADD COLUMN encryption TEXT COMMENT 'Encryption envelope declared in the file index (JSON)' AFTER compression;
 
+-- =============================================
+-- Migration: delta uploads
+-- =============================================
+-- Run if upgrading from version without delta on tasks:
+-- ALTER TABLE tb_file_uploader_task ADD COLUMN delta TEXT COMMENT 'Delta envelope of a delta upload (JSON); the content is the delta' AFTER encryption;
+
+-- =============================================
+-- Migration: scheduled broadcast of upload tasks
+-- =============================================
+-- Run if upgrading from version without broadcastAt/targetFeeRate:
+-- ALTER TABLE tb_file_uploader_task ADD COLUMN broadcast_at TIMESTAMP NULL DEFAULT NULL COMMENT 'Broadcast no later than this time (NULL = at once)' AFTER chain, ADD COLUMN target_fee_rate BIGINT DEFAULT NULL COMMENT 'Broadcast earlier once the network fee rate is at or below this' AFTER broadcast_at, ADD COLUMN released_at TIMESTAMP NULL DEFAULT NULL COMMENT 'When the scheduler released the held broadcast' AFTER target_fee_rate, ADD INDEX idx_broadcast_at (broadcast_at);
+
+-- =============================================
+-- Migration: batched direct uploads share a transaction
+-- =============================================
+-- Run if tb_file was created by AutoMigrate with a unique tx_id index:
+-- ALTER TABLE tb_file DROP INDEX idx_tb_file_tx_id, ADD INDEX idx_tb_file_tx_id (tx_id);
+
+-- =============================================
+-- Migration: assistent key rotation
+-- =============================================
+-- Run if upgrading from version without retired_at on assistents (tb_assistent_rotation is created above):
+-- ALTER TABLE tb_file_assistent ADD COLUMN retired_at TIMESTAMP NULL DEFAULT NULL COMMENT 'Retired by a key rotation at' AFTER status;
+
+-- =============================================
+-- Migration: hot wallet chunk funding
+-- =============================================
+-- Run if upgrading from version without fundingStrategy (tb_hot_wallet_utxo is created above):
+-- ALTER TABLE tb_file_uploader_task ADD COLUMN funding_strategy VARCHAR(20) DEFAULT NULL COMMENT 'Chunk funding: assistent/hot_wallet (empty = uploader.chunk_funding.strategy)' AFTER chain;
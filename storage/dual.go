@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"log"
+)
+
+// DualStorage is used while moving blobs from one backend to another
+// (storage.migration). Writes and deletes go to both backends; reads are
+// served from Source and fall back to Target. A Target write failure is only
+// logged: the blob stays on Source and the migration job copies it later.
+// Multipart uploads are staging data and stay on Source.
+type DualStorage struct {
+	Source     Storage
+	Target     Storage
+	SourceType string // StorageType of records still on Source
+	TargetType string // StorageType of records confirmed on Target
+}
+
+// NewDualStorage create dual-write storage
+func NewDualStorage(source, target Storage, sourceType, targetType string) *DualStorage {
+	return &DualStorage{
+		Source:     source,
+		Target:     target,
+		SourceType: sourceType,
+		TargetType: targetType,
+	}
+}
+
+func (d *DualStorage) Save(key string, data []byte) error {
+	if err := d.Source.Save(key, data); err != nil {
+		return err
+	}
+	if err := d.Target.Save(key, data); err != nil {
+		log.Printf("[DualStorage] Failed to write %s to %s, left for migration: %v", key, d.TargetType, err)
+	}
+	return nil
+}
+
+// SaveWithReceipt returns the receipt of the Source write; a Target write
+// is handled like in Save
+func (d *DualStorage) SaveWithReceipt(key string, data []byte) (*Receipt, error) {
+	receipt, err := SaveWithReceipt(d.Source, key, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Target.Save(key, data); err != nil {
+		log.Printf("[DualStorage] Failed to write %s to %s, left for migration: %v", key, d.TargetType, err)
+	}
+	return &Receipt{Receipt: receipt}, nil
+}
+
+func (d *DualStorage) Get(key string) ([]byte, error) {
+	data, err := d.Source.Get(key)
+	if err == nil {
+		return data, nil
+	}
+	if targetData, targetErr := d.Target.Get(key); targetErr == nil {
+		return targetData, nil
+	}
+	return nil, err
+}
+
+func (d *DualStorage) Delete(key string) error {
+	if err := d.Target.Delete(key); err != nil && err != ErrNotFound {
+		log.Printf("[DualStorage] Failed to delete %s from %s: %v", key, d.TargetType, err)
+	}
+	return d.Source.Delete(key)
+}
+
+// Replicated reports whether key is on Target, or gone from Source
+func (d *DualStorage) Replicated(key string) bool {
+	return d.Target.Exists(key) || !d.Source.Exists(key)
+}
+
+func (d *DualStorage) Exists(key string) bool {
+	return d.Source.Exists(key) || d.Target.Exists(key)
+}
+
+func (d *DualStorage) Stat(key string) (*ObjectInfo, error) {
+	info, err := d.Source.Stat(key)
+	if err == nil {
+		return info, nil
+	}
+	if targetInfo, targetErr := d.Target.Stat(key); targetErr == nil {
+		return targetInfo, nil
+	}
+	return nil, err
+}
+
+// List lists Source only: during a migration it still holds every blob
+func (d *DualStorage) List(prefix string, fn func(info ObjectInfo) error) error {
+	return d.Source.List(prefix, fn)
+}
+
+func (d *DualStorage) InitiateMultipartUpload(key string) (string, error) {
+	return d.Source.InitiateMultipartUpload(key)
+}
+
+func (d *DualStorage) UploadPart(key, uploadId string, partNumber int, data []byte) (string, error) {
+	return d.Source.UploadPart(key, uploadId, partNumber, data)
+}
+
+func (d *DualStorage) CompleteMultipartUpload(key, uploadId string, parts []PartInfo) error {
+	return d.Source.CompleteMultipartUpload(key, uploadId, parts)
+}
+
+func (d *DualStorage) AbortMultipartUpload(key, uploadId string) error {
+	return d.Source.AbortMultipartUpload(key, uploadId)
+}
+
+func (d *DualStorage) ListParts(key, uploadId string) ([]PartInfo, error) {
+	return d.Source.ListParts(key, uploadId)
+}
+
+func (d *DualStorage) GetMultipartUpload(key, uploadId string) ([]byte, error) {
+	return d.Source.GetMultipartUpload(key, uploadId)
+}
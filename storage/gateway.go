@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GatewayStorage watch-only storage: blobs are pushed to an external storage
+// gateway that keeps them in the operator's own store and answers each write
+// with a receipt. The indexer keeps only metadata. Contract, for every key
+// (slash separated, each segment path-escaped):
+//
+//	PUT    {url}/objects/{key}  body = bytes, header X-Content-Sha256; 200/201 with a Receipt
+//	GET    {url}/objects/{key}  the bytes, 404 if unknown
+//	HEAD   {url}/objects/{key}  Content-Length and Last-Modified, 404 if unknown
+//	DELETE {url}/objects/{key}  2xx, or 404 if unknown
+//
+// Requests carry "Authorization: Bearer {token}" when a token is configured.
+// Listing and multipart uploads are not part of the contract.
+type GatewayStorage struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewGatewayStorage create gateway storage instance
+func NewGatewayStorage(baseURL, token string, timeoutSeconds int) (*GatewayStorage, error) {
+	baseURL = strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	if u, err := url.Parse(baseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: storage.gateway.url must be an http(s) URL", ErrInvalid)
+	}
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = 30
+	}
+	return &GatewayStorage{
+		baseURL: baseURL,
+		token:   token,
+		client:  &http.Client{Timeout: time.Duration(timeoutSeconds) * time.Second},
+	}, nil
+}
+
+func (s *GatewayStorage) objectURL(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + "/objects/" + strings.Join(segments, "/")
+}
+
+func (s *GatewayStorage) do(method, key string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, s.objectURL(key), reader)
+	if err != nil {
+		return nil, err
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	if body != nil {
+		hash := sha256.Sum256(body)
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("X-Content-Sha256", hex.EncodeToString(hash[:]))
+	}
+	return s.client.Do(req)
+}
+
+// Save push file to the gateway
+func (s *GatewayStorage) Save(key string, data []byte) error {
+	_, err := s.SaveWithReceipt(key, data)
+	return err
+}
+
+// SaveWithReceipt push file to the gateway and return its storage receipt.
+// A receipt without an id, or echoing another hash or size, fails the write.
+func (s *GatewayStorage) SaveWithReceipt(key string, data []byte) (*Receipt, error) {
+	if data == nil {
+		data = []byte{}
+	}
+	resp, err := s.do(http.MethodPut, key, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to push to storage gateway: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to push to storage gateway: HTTP %d", resp.StatusCode)
+	}
+
+	var receipt Receipt
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&receipt); err != nil {
+		return nil, fmt.Errorf("invalid storage gateway receipt: %w", err)
+	}
+	if receipt.Receipt == "" {
+		return nil, fmt.Errorf("invalid storage gateway receipt: no receipt id")
+	}
+	hash := sha256.Sum256(data)
+	if receipt.Sha256 != "" && !strings.EqualFold(receipt.Sha256, hex.EncodeToString(hash[:])) {
+		return nil, fmt.Errorf("storage gateway stored sha256 %s, sent %x", receipt.Sha256, hash)
+	}
+	if receipt.Size != 0 && receipt.Size != int64(len(data)) {
+		return nil, fmt.Errorf("storage gateway stored %d bytes, sent %d", receipt.Size, len(data))
+	}
+	return &receipt, nil
+}
+
+// Get read file from the gateway
+func (s *GatewayStorage) Get(key string) ([]byte, error) {
+	resp, err := s.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get from storage gateway: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("failed to get from storage gateway: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage gateway object: %w", err)
+	}
+	return data, nil
+}
+
+// Delete delete file from the gateway
+func (s *GatewayStorage) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete from storage gateway: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to delete from storage gateway: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Exists check if file exists on the gateway
+func (s *GatewayStorage) Exists(key string) bool {
+	_, err := s.Stat(key)
+	return err == nil
+}
+
+// Stat get size and modification time of a file on the gateway
+func (s *GatewayStorage) Stat(key string) (*ObjectInfo, error) {
+	resp, err := s.do(http.MethodHead, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat storage gateway object: %w", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("failed to stat storage gateway object: HTTP %d", resp.StatusCode)
+	}
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &ObjectInfo{Key: key, Size: size, ModTime: modTime}, nil
+}
+
+// List is not part of the gateway contract
+func (s *GatewayStorage) List(prefix string, fn func(info ObjectInfo) error) error {
+	return fmt.Errorf("%w: list (storage gateway)", ErrNotSupported)
+}
+
+func (s *GatewayStorage) InitiateMultipartUpload(key string) (string, error) {
+	return "", fmt.Errorf("%w: multipart upload (storage gateway)", ErrNotSupported)
+}
+
+func (s *GatewayStorage) UploadPart(key, uploadId string, partNumber int, data []byte) (string, error) {
+	return "", fmt.Errorf("%w: multipart upload (storage gateway)", ErrNotSupported)
+}
+
+func (s *GatewayStorage) CompleteMultipartUpload(key, uploadId string, parts []PartInfo) error {
+	return fmt.Errorf("%w: multipart upload (storage gateway)", ErrNotSupported)
+}
+
+func (s *GatewayStorage) AbortMultipartUpload(key, uploadId string) error {
+	return fmt.Errorf("%w: multipart upload (storage gateway)", ErrNotSupported)
+}
+
+func (s *GatewayStorage) ListParts(key, uploadId string) ([]PartInfo, error) {
+	return nil, fmt.Errorf("%w: multipart upload (storage gateway)", ErrNotSupported)
+}
+
+func (s *GatewayStorage) GetMultipartUpload(key, uploadId string) ([]byte, error) {
+	return nil, fmt.Errorf("%w: multipart upload (storage gateway)", ErrNotSupported)
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeGateway an in-memory storage gateway; badHash makes it echo a wrong sha256
+type fakeGateway struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	badHash bool
+}
+
+func (g *fakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	key := r.URL.Path[len("/objects/"):]
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	data, ok := g.objects[key]
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		hash := sha256.Sum256(body)
+		if r.Header.Get("X-Content-Sha256") != hex.EncodeToString(hash[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		g.objects[key] = body
+		echo := hex.EncodeToString(hash[:])
+		if g.badHash {
+			echo = "00"
+		}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Receipt{Receipt: "r-" + key, Sha256: echo, Size: int64(len(body))})
+	case http.MethodGet, http.MethodHead:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(g.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestGatewayStorage(t *testing.T) {
+	gateway := &fakeGateway{objects: map[string][]byte{}}
+	srv := httptest.NewServer(gateway)
+	defer srv.Close()
+
+	s, err := NewGatewayStorage(srv.URL+"/", "secret", 5)
+	if err != nil {
+		t.Fatalf("NewGatewayStorage: %v", err)
+	}
+	stor := withWriteAlerts(s, "gateway")
+
+	receipt, err := SaveWithReceipt(stor, "indexer/mvc/abc i0.png", []byte("hello"))
+	if err != nil || receipt != "r-indexer/mvc/abc i0.png" {
+		t.Fatalf("SaveWithReceipt = %q, %v", receipt, err)
+	}
+	if data, err := stor.Get("indexer/mvc/abc i0.png"); err != nil || string(data) != "hello" {
+		t.Fatalf("Get = %q, %v", data, err)
+	}
+	if info, err := stor.Stat("indexer/mvc/abc i0.png"); err != nil || info.Size != 5 {
+		t.Fatalf("Stat = %+v, %v", info, err)
+	}
+	if _, err := stor.Get("indexer/mvc/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) err = %v, want ErrNotFound", err)
+	}
+	if err := stor.Delete("indexer/mvc/abc i0.png"); err != nil || stor.Exists("indexer/mvc/abc i0.png") {
+		t.Errorf("Delete = %v, still exists: %v", err, stor.Exists("indexer/mvc/abc i0.png"))
+	}
+	if err := stor.List("indexer/", func(ObjectInfo) error { return nil }); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("List err = %v, want ErrNotSupported", err)
+	}
+
+	gateway.badHash = true
+	if _, err := SaveWithReceipt(stor, "indexer/mvc/def", []byte("hello")); err == nil {
+		t.Error("receipt with a wrong sha256 accepted")
+	}
+
+	local, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt, err := SaveWithReceipt(withWriteAlerts(local, "local"), "a", []byte("x")); err != nil || receipt != "" {
+		t.Errorf("local SaveWithReceipt = %q, %v", receipt, err)
+	}
+	if _, err := NewGatewayStorage("ftp://example.com", "", 0); !errors.Is(err, ErrInvalid) {
+		t.Errorf("ftp url err = %v, want ErrInvalid", err)
+	}
+}
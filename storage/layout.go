@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"fmt"
+
+	"meta-file-system/conf"
+)
+
+// Storage layout versions. Each indexed file/chunk records the layout its blob
+// was written with; records from before layouts were versioned (0) use LayoutV1.
+const (
+	LayoutV1 = 1 // indexer/{chain}/{pinid}{ext}, indexer/chunk/{chain}/{txid}/{pinid}
+	LayoutV2 = 2 // indexer/v2/{chain}/{pinid[0:2]}/{pinid}{ext}, indexer/v2/chunk/{chain}/{pinid[0:2]}/{pinid}
+
+	// LayoutTemplate paths come from storage.path_template. Records only keep
+	// the version, so blobs are located by their recorded storage path.
+	LayoutTemplate = 3
+
+	DefaultLayoutVersion = LayoutV1
+	LatestLayoutVersion  = LayoutV2
+)
+
+// PathVars what a layout may build a blob's path from
+type PathVars struct {
+	ChainName string
+	PinID     string
+	TxID      string // Chunks
+	Extension string // Files, with the leading dot
+	MetaID    string // Files: creator MetaID
+	Timestamp int64  // PIN timestamp (ms)
+}
+
+// Layout generates storage keys for indexer blobs. Paths depend only on the
+// arguments, so a blob can always be located again from its record.
+// Avatars keep their own path and are not covered by layouts.
+type Layout interface {
+	Version() int
+	FilePath(vars PathVars) string
+	ChunkPath(vars PathVars) string
+}
+
+// LayoutFor returns the layout for version (0 = LayoutV1). LayoutTemplate is
+// built from storage.path_template and fails if the templates are invalid.
+func LayoutFor(version int) (Layout, error) {
+	switch version {
+	case 0, LayoutV1:
+		return layoutV1{}, nil
+	case LayoutV2:
+		return layoutV2{}, nil
+	case LayoutTemplate:
+		var cfg conf.StoragePathTemplateConfig
+		if conf.Cfg != nil {
+			cfg = conf.Cfg.Storage.PathTemplate
+		}
+		return newTemplateLayout(cfg.File, cfg.Chunk)
+	default:
+		return nil, fmt.Errorf("unknown storage layout version %d", version)
+	}
+}
+
+// ValidateLayout checks storage.layout_version and, for LayoutTemplate, the
+// path templates; run at startup so a bad template fails before any blob is
+// written
+func ValidateLayout() error {
+	if conf.Cfg == nil || conf.Cfg.Storage.LayoutVersion == 0 {
+		return nil
+	}
+	_, err := LayoutFor(conf.Cfg.Storage.LayoutVersion)
+	return err
+}
+
+// CurrentLayout returns the layout configured by storage.layout_version for
+// new blobs, falling back to DefaultLayoutVersion when unset or unknown
+func CurrentLayout() Layout {
+	if conf.Cfg != nil && conf.Cfg.Storage.LayoutVersion != 0 {
+		if layout, err := LayoutFor(conf.Cfg.Storage.LayoutVersion); err == nil {
+			return layout
+		}
+	}
+	layout, _ := LayoutFor(DefaultLayoutVersion)
+	return layout
+}
+
+// LayoutVersionOf normalizes a recorded layout version (0 = LayoutV1)
+func LayoutVersionOf(recorded int) int {
+	if recorded == 0 {
+		return LayoutV1
+	}
+	return recorded
+}
+
+// layoutV1 the original flat layout
+type layoutV1 struct{}
+
+func (layoutV1) Version() int { return LayoutV1 }
+
+func (layoutV1) FilePath(v PathVars) string {
+	return fmt.Sprintf("indexer/%s/%s%s", v.ChainName, v.PinID, v.Extension)
+}
+
+func (layoutV1) ChunkPath(v PathVars) string {
+	return fmt.Sprintf("indexer/chunk/%s/%s/%s", v.ChainName, v.TxID, v.PinID)
+}
+
+// layoutV2 shards blobs by the first two characters of the PIN ID so no
+// directory / prefix grows without bound
+type layoutV2 struct{}
+
+func (layoutV2) Version() int { return LayoutV2 }
+
+func (layoutV2) FilePath(v PathVars) string {
+	return fmt.Sprintf("indexer/v2/%s/%s/%s%s", v.ChainName, shardOf(v.PinID), v.PinID, v.Extension)
+}
+
+func (layoutV2) ChunkPath(v PathVars) string {
+	return fmt.Sprintf("indexer/v2/chunk/%s/%s/%s", v.ChainName, shardOf(v.PinID), v.PinID)
+}
+
+// shardOf returns the shard directory for pinID
+func shardOf(pinID string) string {
+	if len(pinID) < 2 {
+		return "_"
+	}
+	return pinID[:2]
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -83,6 +85,64 @@ func (s *LocalStorage) Exists(key string) bool {
 	return err == nil
 }
 
+// Stat get size and modification time of a file
+func (s *LocalStorage) Stat(key string) (*ObjectInfo, error) {
+	info, err := os.Stat(filepath.Join(s.basePath, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// List visit files whose key starts with prefix, in key order. Multipart
+// staging data (.uploads) is skipped.
+func (s *LocalStorage) List(prefix string, fn func(info ObjectInfo) error) error {
+	// Walk only the directory the prefix points into
+	root := s.basePath
+	if dir := path.Dir(prefix); prefix != "" && dir != "." {
+		root = filepath.Join(s.basePath, filepath.FromSlash(dir))
+	}
+
+	var fnErr error
+	err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(s.basePath, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if info.IsDir() {
+			if key == ".uploads" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		fnErr = fn(ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	return nil
+}
+
 // InitiateMultipartUpload initiate multipart upload (local storage implementation)
 func (s *LocalStorage) InitiateMultipartUpload(key string) (string, error) {
 	// For local storage, we use a simple approach: create a temp directory
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLocalStorage_StatAndList(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	for key, data := range map[string]string{
+		"mvc/files/a.png":   "aaa",
+		"mvc/files/b.png":   "bb",
+		"mvc/chunks/c0":     "c",
+		"btc/files/d.png":   "dddd",
+		"mvc/filesx/e.png":  "e",
+		".uploads/u1/part1": "staging",
+	} {
+		if err := s.Save(key, []byte(data)); err != nil {
+			t.Fatalf("Save(%s): %v", key, err)
+		}
+	}
+
+	info, err := s.Stat("mvc/files/a.png")
+	if err != nil || info.Size != 3 || info.ModTime.IsZero() {
+		t.Fatalf("Stat = %+v, %v", info, err)
+	}
+	if _, err := s.Stat("mvc/files/missing.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(missing) err = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Stat("mvc/files"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(dir) err = %v, want ErrNotFound", err)
+	}
+
+	for _, tc := range []struct {
+		prefix string
+		want   []string
+	}{
+		{"mvc/files/", []string{"mvc/files/a.png", "mvc/files/b.png"}},
+		{"mvc/files", []string{"mvc/files/a.png", "mvc/files/b.png", "mvc/filesx/e.png"}},
+		{"mvc/none/", nil},
+		{"", []string{"btc/files/d.png", "mvc/chunks/c0", "mvc/files/a.png", "mvc/files/b.png", "mvc/filesx/e.png"}},
+	} {
+		var got []string
+		if err := s.List(tc.prefix, func(info ObjectInfo) error {
+			got = append(got, info.Key)
+			return nil
+		}); err != nil {
+			t.Fatalf("List(%q): %v", tc.prefix, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("List(%q) = %v, want %v", tc.prefix, got, tc.want)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = s.List("", func(ObjectInfo) error { calls++; return stop })
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("List stop: err = %v, calls = %d", err, calls)
+	}
+}
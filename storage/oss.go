@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"sort"
+	"strconv"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -83,6 +85,40 @@ func (s *OSSStorage) Exists(key string) bool {
 	return exists
 }
 
+// Stat get size and modification time of a file in OSS
+func (s *OSSStorage) Stat(key string) (*ObjectInfo, error) {
+	header, err := s.bucket.GetObjectMeta(key)
+	if err != nil {
+		if ossErr, ok := err.(oss.ServiceError); ok && ossErr.StatusCode == 404 {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat oss object: %w", err)
+	}
+	size, _ := strconv.ParseInt(header.Get(oss.HTTPHeaderContentLength), 10, 64)
+	modTime, _ := http.ParseTime(header.Get(oss.HTTPHeaderLastModified))
+	return &ObjectInfo{Key: key, Size: size, ModTime: modTime}, nil
+}
+
+// List visit OSS objects whose key starts with prefix, in key order
+func (s *OSSStorage) List(prefix string, fn func(info ObjectInfo) error) error {
+	token := ""
+	for {
+		result, err := s.bucket.ListObjectsV2(oss.Prefix(prefix), oss.ContinuationToken(token), oss.MaxKeys(1000))
+		if err != nil {
+			return fmt.Errorf("failed to list oss objects: %w", err)
+		}
+		for _, object := range result.Objects {
+			if err := fn(ObjectInfo{Key: object.Key, Size: object.Size, ModTime: object.LastModified}); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
 // InitiateMultipartUpload initiate multipart upload
 func (s *OSSStorage) InitiateMultipartUpload(key string) (string, error) {
 	imur, err := s.bucket.InitiateMultipartUpload(key)
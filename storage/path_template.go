@@ -0,0 +1,145 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Path template variables. Dates are UTC, from the PIN timestamp.
+var pathTemplateVars = map[string]func(v PathVars) string{
+	"chain":  func(v PathVars) string { return v.ChainName },
+	"pinId":  func(v PathVars) string { return v.PinID },
+	"txId":   func(v PathVars) string { return v.TxID },
+	"ext":    func(v PathVars) string { return v.Extension },
+	"shard":  func(v PathVars) string { return shardOf(v.PinID) },
+	"metaId": func(v PathVars) string { return orPlaceholder(v.MetaID) },
+	"year":   func(v PathVars) string { return pinTime(v).Format("2006") },
+	"month":  func(v PathVars) string { return pinTime(v).Format("01") },
+	"day":    func(v PathVars) string { return pinTime(v).Format("02") },
+}
+
+// Variables a chunk template may not use: chunk records do not keep them, so
+// a chunk could not be placed again by a layout migration
+var chunkTemplateExcluded = map[string]bool{"ext": true, "metaId": true}
+
+// pathTemplate a parsed storage.path_template entry: literal text and
+// variables, in order
+type pathTemplate struct {
+	raw   string
+	parts []templatePart
+}
+
+type templatePart struct {
+	literal string
+	render  func(v PathVars) string // nil for literal text
+}
+
+// parsePathTemplate parses and validates tmpl. A template must contain
+// {pinId}, so every blob gets its own path, and may only produce relative
+// paths of letters, digits and "/._-".
+func parsePathTemplate(tmpl string, chunk bool) (*pathTemplate, error) {
+	if tmpl == "" {
+		return nil, fmt.Errorf("path template is empty")
+	}
+	if strings.HasPrefix(tmpl, "/") || strings.HasSuffix(tmpl, "/") || strings.Contains(tmpl, "//") {
+		return nil, fmt.Errorf("path template %q must be a relative path without empty segments", tmpl)
+	}
+
+	t := &pathTemplate{raw: tmpl}
+	hasPinID := false
+	for rest := tmpl; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			t.parts = append(t.parts, templatePart{literal: rest})
+			break
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("path template %q has an unmatched }", tmpl)
+		}
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("path template %q has an unclosed {", tmpl)
+		}
+		name := rest[open+1 : open+end]
+		render, ok := pathTemplateVars[name]
+		if !ok || (chunk && chunkTemplateExcluded[name]) {
+			return nil, fmt.Errorf("path template %q uses unknown variable {%s}", tmpl, name)
+		}
+		hasPinID = hasPinID || name == "pinId"
+		t.parts = append(t.parts, templatePart{render: render})
+		rest = rest[open+end+1:]
+	}
+	if !hasPinID {
+		return nil, fmt.Errorf("path template %q must contain {pinId}", tmpl)
+	}
+
+	for _, part := range t.parts {
+		for _, segment := range strings.Split(part.literal, "/") {
+			if segment == "." || segment == ".." {
+				return nil, fmt.Errorf("path template %q must not contain . or .. segments", tmpl)
+			}
+		}
+		for _, r := range part.literal {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-", r)) {
+				return nil, fmt.Errorf("path template %q contains %q; use letters, digits and /._-", tmpl, r)
+			}
+		}
+	}
+	return t, nil
+}
+
+func (t *pathTemplate) render(v PathVars) string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.render != nil {
+			b.WriteString(part.render(v))
+		} else {
+			b.WriteString(part.literal)
+		}
+	}
+	return b.String()
+}
+
+// templateLayout LayoutTemplate: paths rendered from storage.path_template
+type templateLayout struct {
+	file  *pathTemplate
+	chunk *pathTemplate
+}
+
+func newTemplateLayout(file, chunk string) (*templateLayout, error) {
+	fileTmpl, err := parsePathTemplate(file, false)
+	if err != nil {
+		return nil, fmt.Errorf("storage.path_template.file: %w", err)
+	}
+	chunkTmpl, err := parsePathTemplate(chunk, true)
+	if err != nil {
+		return nil, fmt.Errorf("storage.path_template.chunk: %w", err)
+	}
+	return &templateLayout{file: fileTmpl, chunk: chunkTmpl}, nil
+}
+
+func (*templateLayout) Version() int { return LayoutTemplate }
+
+func (l *templateLayout) FilePath(v PathVars) string { return l.file.render(v) }
+
+func (l *templateLayout) ChunkPath(v PathVars) string { return l.chunk.render(v) }
+
+// pinTime the PIN timestamp of v in UTC; the zero time when unknown
+func pinTime(v PathVars) time.Time {
+	if v.Timestamp <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(v.Timestamp).UTC()
+}
+
+// orPlaceholder returns s, or "_" when it is empty, so no path segment is empty
+func orPlaceholder(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return s
+}
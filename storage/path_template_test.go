@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateLayout_Render(t *testing.T) {
+	layout, err := newTemplateLayout("indexer/t/{chain}/{year}/{month}/{day}/{metaId}/{shard}/{pinId}{ext}", "indexer/t/chunk/{chain}/{year}-{month}/{txId}/{pinId}")
+	if err != nil {
+		t.Fatalf("newTemplateLayout: %v", err)
+	}
+	vars := PathVars{
+		ChainName: "mvc",
+		PinID:     "abcdi0",
+		TxID:      "abcd",
+		Extension: ".png",
+		MetaID:    "meta1",
+		Timestamp: 1700000000000, // 2023-11-14T22:13:20Z
+	}
+	if got, want := layout.FilePath(vars), "indexer/t/mvc/2023/11/14/meta1/ab/abcdi0.png"; got != want {
+		t.Errorf("FilePath = %q, want %q", got, want)
+	}
+	if got, want := layout.ChunkPath(vars), "indexer/t/chunk/mvc/2023-11/abcd/abcdi0"; got != want {
+		t.Errorf("ChunkPath = %q, want %q", got, want)
+	}
+
+	// Unknown creator and time still give a path without empty segments
+	if got, want := layout.FilePath(PathVars{ChainName: "btc", PinID: "efghi0"}), "indexer/t/btc/0001/01/01/_/ef/efghi0"; got != want {
+		t.Errorf("FilePath without creator/time = %q, want %q", got, want)
+	}
+	if layout.Version() != LayoutTemplate {
+		t.Errorf("Version = %d, want %d", layout.Version(), LayoutTemplate)
+	}
+}
+
+func TestParsePathTemplate_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		tmpl  string
+		chunk bool
+		want  string
+	}{
+		{"", false, "empty"},
+		{"/indexer/{pinId}", false, "relative"},
+		{"indexer/{pinId}/", false, "relative"},
+		{"indexer//{pinId}", false, "relative"},
+		{"indexer/{chain}", false, "{pinId}"},
+		{"indexer/{pinid}", false, "unknown variable {pinid}"},
+		{"indexer/{pinId", false, "unclosed"},
+		{"indexer/pinId}", false, "unmatched"},
+		{"indexer/../{pinId}", false, ". or .."},
+		{"indexer/a b/{pinId}", false, "contains"},
+		{"indexer/{metaId}/{pinId}", true, "unknown variable {metaId}"},
+		{"indexer/{pinId}{ext}", true, "unknown variable {ext}"},
+	} {
+		_, err := parsePathTemplate(tc.tmpl, tc.chunk)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("parsePathTemplate(%q, chunk=%v) err = %v, want %q", tc.tmpl, tc.chunk, err, tc.want)
+		}
+	}
+}
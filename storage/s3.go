@@ -3,6 +3,7 @@ package storage
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"sort"
@@ -144,6 +145,57 @@ func (s *S3Storage) Exists(key string) bool {
 	return err == nil
 }
 
+// Stat get size and modification time of a file in S3
+func (s *S3Storage) Stat(key string) (*ObjectInfo, error) {
+	ctx := context.Background()
+
+	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to stat s3 object: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:     key,
+		Size:    aws.ToInt64(result.ContentLength),
+		ModTime: aws.ToTime(result.LastModified),
+	}, nil
+}
+
+// List visit S3 objects whose key starts with prefix, in key order
+func (s *S3Storage) List(prefix string, fn func(info ObjectInfo) error) error {
+	ctx := context.Background()
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list s3 objects: %w", err)
+		}
+		for _, object := range page.Contents {
+			info := ObjectInfo{
+				Key:     aws.ToString(object.Key),
+				Size:    aws.ToInt64(object.Size),
+				ModTime: aws.ToTime(object.LastModified),
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // InitiateMultipartUpload initiate multipart upload
 func (s *S3Storage) InitiateMultipartUpload(key string) (string, error) {
 	ctx := context.Background()
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrBlobReferenced    = errors.New("blob is still referenced")
+	ErrBlobNotReplicated = errors.New("blob is not yet replicated")
+)
+
+// DeleteGuard describes what must hold before a blob may be removed. Blob
+// removal by cleanup jobs and admin operations goes through SafeDelete with a
+// guard instead of calling Storage.Delete directly.
+type DeleteGuard struct {
+	// Refs counts the records other than the one being removed that still
+	// use key (one blob shared by several records, e.g. the same content
+	// uploaded twice); nil means the key is never shared
+	Refs func(key string) (int64, error)
+	// RequireReplica keeps the blob until the replicating storage (see
+	// Replicator) holds a copy on its target
+	RequireReplica bool
+}
+
+// Replicator is implemented by storages that copy blobs to a second backend
+type Replicator interface {
+	// Replicated reports whether key is on the replication target (or there
+	// is nothing to replicate because the source no longer has it)
+	Replicated(key string) bool
+}
+
+// SafeDelete removes the blob at key unless guard forbids it: ErrBlobReferenced
+// when other records still use it, ErrBlobNotReplicated when a replica is
+// required and s has not copied it yet. Both leave the blob untouched.
+func SafeDelete(s Storage, key string, guard DeleteGuard) error {
+	if key == "" {
+		return fmt.Errorf("blob key cannot be empty")
+	}
+	if guard.Refs != nil {
+		refs, err := guard.Refs(key)
+		if err != nil {
+			return fmt.Errorf("failed to count references of %s: %w", key, err)
+		}
+		if refs > 0 {
+			return fmt.Errorf("%w: %s (%d other records)", ErrBlobReferenced, key, refs)
+		}
+	}
+	if guard.RequireReplica {
+		if r, ok := s.(Replicator); ok && !r.Replicated(key) {
+			return fmt.Errorf("%w: %s", ErrBlobNotReplicated, key)
+		}
+	}
+	return s.Delete(key)
+}
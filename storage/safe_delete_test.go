@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSafeDelete(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	if err := s.Save("uploads/a.png", []byte("a")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	refs := int64(1)
+	guard := DeleteGuard{Refs: func(string) (int64, error) { return refs, nil }}
+	if err := SafeDelete(s, "uploads/a.png", guard); !errors.Is(err, ErrBlobReferenced) {
+		t.Fatalf("referenced blob: err = %v, want ErrBlobReferenced", err)
+	}
+	if !s.Exists("uploads/a.png") {
+		t.Fatal("referenced blob was deleted")
+	}
+
+	refs = 0
+	if err := SafeDelete(s, "uploads/a.png", guard); err != nil {
+		t.Fatalf("unreferenced blob: %v", err)
+	}
+	if s.Exists("uploads/a.png") {
+		t.Error("unreferenced blob was kept")
+	}
+}
+
+func TestSafeDeleteRequiresReplica(t *testing.T) {
+	source, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	target, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	dual := NewDualStorage(source, target, "local", "s3")
+
+	// Written before the migration started: only on Source
+	if err := source.Save("indexer/mvc/a.png", []byte("a")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	guard := DeleteGuard{RequireReplica: true}
+	if err := SafeDelete(dual, "indexer/mvc/a.png", guard); !errors.Is(err, ErrBlobNotReplicated) {
+		t.Fatalf("unreplicated blob: err = %v, want ErrBlobNotReplicated", err)
+	}
+	if err := SafeDelete(dual, "indexer/mvc/a.png", DeleteGuard{}); err != nil {
+		t.Fatalf("without RequireReplica: %v", err)
+	}
+
+	if err := dual.Save("indexer/mvc/b.png", []byte("b")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := SafeDelete(dual, "indexer/mvc/b.png", guard); err != nil {
+		t.Fatalf("replicated blob: %v", err)
+	}
+	if dual.Exists("indexer/mvc/b.png") {
+		t.Error("replicated blob was kept")
+	}
+}
@@ -2,6 +2,9 @@ package storage
 
 import (
 	"errors"
+	"fmt"
+	"time"
+
 	"meta-file-system/conf"
 )
 
@@ -11,6 +14,8 @@ type Storage interface {
 	Get(key string) ([]byte, error)
 	Delete(key string) error
 	Exists(key string) bool
+	Stat(key string) (*ObjectInfo, error)                     // ErrNotFound when the key does not exist
+	List(prefix string, fn func(info ObjectInfo) error) error // Visit every key starting with prefix; an error from fn stops and is returned
 
 	// Multipart upload methods for large files
 	InitiateMultipartUpload(key string) (string, error)                           // Returns uploadId
@@ -21,6 +26,13 @@ type Storage interface {
 	GetMultipartUpload(key, uploadId string) ([]byte, error)                      // Get complete file from multipart upload
 }
 
+// ObjectInfo metadata of a stored object
+type ObjectInfo struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
 // PartInfo part information for multipart upload
 type PartInfo struct {
 	PartNumber int    `json:"partNumber"`
@@ -29,14 +41,68 @@ type PartInfo struct {
 }
 
 var (
-	ErrNotFound = errors.New("file not found")
-	ErrInvalid  = errors.New("invalid storage configuration")
+	ErrNotFound     = errors.New("file not found")
+	ErrInvalid      = errors.New("invalid storage configuration")
+	ErrNotSupported = errors.New("operation not supported by this storage")
 )
 
-// NewStorage create storage instance by configuration
+// Receipt the storage receipt an external gateway returns for a stored blob
+type Receipt struct {
+	Receipt string `json:"receipt"`          // Opaque id of the stored object in the external system
+	Sha256  string `json:"sha256,omitempty"` // Echo of the stored content hash, checked when present
+	Size    int64  `json:"size,omitempty"`   // Echo of the stored size, checked when present
+}
+
+// ReceiptSaver is implemented by storages that return a receipt for each write
+type ReceiptSaver interface {
+	SaveWithReceipt(key string, data []byte) (*Receipt, error)
+}
+
+// SaveWithReceipt saves data at key and returns the receipt of the write, ""
+// for backends that do not issue receipts
+func SaveWithReceipt(s Storage, key string, data []byte) (string, error) {
+	if r, ok := s.(ReceiptSaver); ok {
+		receipt, err := r.SaveWithReceipt(key, data)
+		if err != nil {
+			return "", err
+		}
+		return receipt.Receipt, nil
+	}
+	return "", s.Save(key, data)
+}
+
+// NewStorage create storage instance by configuration. While
+// storage.migration is enabled the configured storage is wrapped in a
+// DualStorage that also writes to the migration target.
 func NewStorage() (Storage, error) {
-	storageType := conf.Cfg.Storage.Type
+	source, err := NewStorageByType(conf.Cfg.Storage.Type)
+	if err != nil {
+		return nil, err
+	}
 
+	migration := conf.Cfg.Storage.Migration
+	if !migration.Enabled || migration.Target == "" || migration.Target == RecordType(conf.Cfg.Storage.Type) {
+		return source, nil
+	}
+	target, err := NewStorageByType(migration.Target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize migration target storage %s: %w", migration.Target, err)
+	}
+	return NewDualStorage(source, target, RecordType(conf.Cfg.Storage.Type), migration.Target), nil
+}
+
+// NewStorageByType create storage instance of storageType (local/oss/s3/minio/gateway)
+// from its configuration section. Failed writes raise a storage_write_failed
+// alert (notify).
+func NewStorageByType(storageType string) (Storage, error) {
+	backend, err := newBackend(storageType)
+	if err != nil {
+		return nil, err
+	}
+	return withWriteAlerts(backend, RecordType(storageType)), nil
+}
+
+func newBackend(storageType string) (Storage, error) {
 	switch storageType {
 	case "local":
 		return NewLocalStorage(conf.Cfg.Storage.Local.BasePath)
@@ -49,8 +115,22 @@ func NewStorage() (Storage, error) {
 	case "minio":
 		return NewMinIOStorage(conf.Cfg.Storage.MinIO.Endpoint, conf.Cfg.Storage.MinIO.AccessKey,
 			conf.Cfg.Storage.MinIO.SecretKey, conf.Cfg.Storage.MinIO.Bucket)
+	case "gateway":
+		return NewGatewayStorage(conf.Cfg.Storage.Gateway.Url, conf.Cfg.Storage.Gateway.Token,
+			conf.Cfg.Storage.Gateway.TimeoutSeconds)
 	default:
 		// Default to local storage
 		return NewLocalStorage(conf.Cfg.Storage.Local.BasePath)
 	}
 }
+
+// RecordType returns the StorageType recorded for blobs written to
+// storageType (unknown or empty types fall back to local storage)
+func RecordType(storageType string) string {
+	switch storageType {
+	case "oss", "s3", "minio", "gateway":
+		return storageType
+	default:
+		return "local"
+	}
+}
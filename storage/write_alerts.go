@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"meta-file-system/notify"
+)
+
+// writeAlertStorage sends a storage_write_failed alert when a write to the
+// wrapped backend fails (notify.routes); reads and deletes pass through
+type writeAlertStorage struct {
+	Storage
+	storageType string
+}
+
+// receiptAlertStorage is writeAlertStorage for a backend issuing receipts
+type receiptAlertStorage struct {
+	*writeAlertStorage
+}
+
+// withWriteAlerts wraps the backend created by NewStorageByType
+func withWriteAlerts(s Storage, storageType string) Storage {
+	w := &writeAlertStorage{Storage: s, storageType: storageType}
+	if _, ok := s.(ReceiptSaver); ok {
+		return &receiptAlertStorage{w}
+	}
+	return w
+}
+
+func (w *writeAlertStorage) Save(key string, data []byte) error {
+	err := w.Storage.Save(key, data)
+	if err != nil {
+		notify.Alertf(notify.EventStorageWriteFailed, "%s storage: failed to write %s (%d bytes): %v", w.storageType, key, len(data), err)
+	}
+	return err
+}
+
+func (w *receiptAlertStorage) SaveWithReceipt(key string, data []byte) (*Receipt, error) {
+	receipt, err := w.Storage.(ReceiptSaver).SaveWithReceipt(key, data)
+	if err != nil {
+		notify.Alertf(notify.EventStorageWriteFailed, "%s storage: failed to write %s (%d bytes): %v", w.storageType, key, len(data), err)
+	}
+	return receipt, err
+}
+
+func (w *writeAlertStorage) UploadPart(key, uploadId string, partNumber int, data []byte) (string, error) {
+	etag, err := w.Storage.UploadPart(key, uploadId, partNumber, data)
+	if err != nil {
+		notify.Alertf(notify.EventStorageWriteFailed, "%s storage: failed to write part %d of %s: %v", w.storageType, partNumber, key, err)
+	}
+	return etag, err
+}
+
+func (w *writeAlertStorage) CompleteMultipartUpload(key, uploadId string, parts []PartInfo) error {
+	err := w.Storage.CompleteMultipartUpload(key, uploadId, parts)
+	if err != nil {
+		notify.Alertf(notify.EventStorageWriteFailed, "%s storage: failed to complete %s: %v", w.storageType, key, err)
+	}
+	return err
+}
@@ -0,0 +1,361 @@
+// MetaID File Explorer - a small client of the indexer API.
+// Routes (location.hash): #/ recent files, #/file/{pinId}, #/user/{metaId|address}, #/search/{query}
+const API_BASE = window.location.origin;
+const PAGE_SIZE = 24;
+// Keyword search requires an extension filter; search the common ones
+const SEARCH_EXTENSIONS = '.jpg,.jpeg,.png,.gif,.webp,.svg,.mp4,.webm,.mp3,.wav,.pdf,.txt,.md,.json,.html,.zip';
+
+const view = document.getElementById('view');
+
+function escapeHtml(value) {
+    return String(value ?? '').replace(/[&<>"']/g, ch => ({
+        '&': '&amp;', '<': '&lt;', '>': '&gt;', '"': '&quot;', "'": '&#39;'
+    })[ch]);
+}
+
+function formatSize(bytes) {
+    if (!bytes) return '0 B';
+    const units = ['B', 'KB', 'MB', 'GB'];
+    let i = 0;
+    while (bytes >= 1024 && i < units.length - 1) {
+        bytes /= 1024;
+        i++;
+    }
+    return `${bytes.toFixed(i === 0 ? 0 : 1)} ${units[i]}`;
+}
+
+function formatTime(seconds) {
+    if (!seconds) return '-';
+    return new Date(seconds * 1000).toLocaleString();
+}
+
+function shorten(value, keep = 8) {
+    value = String(value ?? '');
+    return value.length > keep * 2 + 3 ? `${value.slice(0, keep)}…${value.slice(-keep)}` : value;
+}
+
+function contentUrl(pinId) {
+    return `${API_BASE}/api/v1/files/content/${encodeURIComponent(pinId)}`;
+}
+
+// api fetches an indexer endpoint and returns data, throwing on a non-zero code
+async function api(path) {
+    const response = await fetch(`${API_BASE}/api/v1${path}`);
+    const body = await response.json();
+    if (body.code !== 0) {
+        const err = new Error(body.message || `request failed (${body.code})`);
+        err.code = body.code;
+        throw err;
+    }
+    return body.data;
+}
+
+// apiOrNull is api but returns null for not-found and invalid-param answers
+async function apiOrNull(path) {
+    try {
+        return await api(path);
+    } catch (err) {
+        if (err.code === 40400 || err.code === 40000) return null;
+        throw err;
+    }
+}
+
+function showError(err) {
+    view.innerHTML = `<div class="panel error">${escapeHtml(err.message || err)}</div>`;
+}
+
+function isImage(file) {
+    return (file.content_type || '').startsWith('image/') || file.file_type === 'image';
+}
+
+function fileIcon(file) {
+    const type = (file.content_type || file.file_type || '').split('/')[0];
+    return { video: '🎬', audio: '🎵', text: '📄', application: '📦' }[type] || '📁';
+}
+
+function renderFileCard(file) {
+    const thumb = isImage(file)
+        ? `<img loading="lazy" src="${escapeHtml(contentUrl(file.pin_id))}" alt="">`
+        : fileIcon(file);
+    const creator = file.user_info?.name || shorten(file.creator_address, 6);
+    return `
+        <a class="card" href="#/file/${encodeURIComponent(file.pin_id)}">
+            <div class="thumb">${thumb}</div>
+            <div class="body">
+                <div class="name" title="${escapeHtml(file.file_name)}">${escapeHtml(file.file_name || file.pin_id)}</div>
+                <div class="meta">${escapeHtml(formatSize(file.file_size))} · ${escapeHtml(file.chain_name)} · ${escapeHtml(formatTime(file.timestamp))}</div>
+                <div class="meta">${escapeHtml(creator)}</div>
+            </div>
+        </a>`;
+}
+
+// renderFileList renders a paged grid; loadPage(next) returns {files, next, hasMore}
+async function renderFileList(container, loadPage, emptyText = 'No files found.') {
+    const grid = document.createElement('div');
+    grid.className = 'grid';
+    const more = document.createElement('button');
+    more.className = 'more';
+    more.textContent = 'Load more';
+    container.append(grid, more);
+
+    let next = null;
+    const load = async () => {
+        more.disabled = true;
+        try {
+            const page = await loadPage(next);
+            grid.insertAdjacentHTML('beforeend', page.files.map(renderFileCard).join(''));
+            next = page.next;
+            more.style.display = page.hasMore ? '' : 'none';
+            if (!grid.children.length) {
+                grid.outerHTML = `<p class="muted">${escapeHtml(emptyText)}</p>`;
+            }
+        } catch (err) {
+            more.insertAdjacentHTML('beforebegin', `<p class="error">${escapeHtml(err.message)}</p>`);
+            more.style.display = 'none';
+        }
+        more.disabled = false;
+    };
+    more.addEventListener('click', load);
+    await load();
+}
+
+function cursorPager(path) {
+    const sep = path.includes('?') ? '&' : '?';
+    return async cursor => {
+        const data = await api(`${path}${sep}cursor=${cursor || 0}&size=${PAGE_SIZE}`);
+        return { files: data.files || [], next: data.next_cursor, hasMore: data.has_more };
+    };
+}
+
+async function showRecent() {
+    view.innerHTML = '<h2>Recent files</h2>';
+    await renderFileList(view, cursorPager('/files'));
+}
+
+function kvTable(rows) {
+    return `<table class="kv">${rows
+        .filter(([, value]) => value !== undefined && value !== null && value !== '')
+        .map(([key, value]) => `<tr><th>${escapeHtml(key)}</th><td>${value}</td></tr>`)
+        .join('')}</table>`;
+}
+
+function renderPreview(file) {
+    const url = escapeHtml(contentUrl(file.pin_id));
+    const type = file.content_type || '';
+    if (isImage(file)) return `<img class="preview" src="${url}" alt="">`;
+    if (type.startsWith('video/')) return `<video class="preview" src="${url}" controls></video>`;
+    if (type.startsWith('audio/')) return `<audio src="${url}" controls></audio>`;
+    return '';
+}
+
+async function showFile(pinId) {
+    view.innerHTML = '<p class="muted">Loading…</p>';
+    const file = await apiOrNull(`/files/${encodeURIComponent(pinId)}`);
+    if (!file) {
+        view.innerHTML = `<div class="panel">No indexed file with PIN ID <code>${escapeHtml(pinId)}</code>.</div>`;
+        return;
+    }
+
+    const user = file.user_info;
+    const creatorKey = file.creator_meta_id || file.creator_address;
+    const creator = creatorKey
+        ? `<a href="#/user/${encodeURIComponent(creatorKey)}">${escapeHtml(user?.name || file.creator_address)}</a>`
+        : '-';
+    view.innerHTML = `
+        <h2>${escapeHtml(file.file_name || file.pin_id)}</h2>
+        <div class="panel">
+            ${renderPreview(file)}
+            <p><a href="${escapeHtml(contentUrl(file.pin_id))}" target="_blank" rel="noopener">Open content</a></p>
+            ${kvTable([
+                ['PIN ID', escapeHtml(file.pin_id)],
+                ['URI', escapeHtml(`mfs://${file.pin_id}`)],
+                ['Path', escapeHtml(file.path)],
+                ['Operation', escapeHtml(file.operation)],
+                ['Content type', escapeHtml(file.content_type)],
+                ['Size', escapeHtml(formatSize(file.file_size))],
+                ['SHA-256', file.file_hash ? `<a href="#/search/${encodeURIComponent(`mfs://${file.file_hash}`)}">${escapeHtml(file.file_hash)}</a>` : ''],
+                ['Chain', escapeHtml(file.chain_name)],
+                ['Block height', escapeHtml(file.block_height || 'mempool')],
+                ['Time', escapeHtml(formatTime(file.timestamp))],
+                ['Creator', creator],
+                ['Creator address', escapeHtml(file.creator_address)],
+                ['Creator MetaID', escapeHtml(file.creator_meta_id)],
+                ['Status', escapeHtml(file.status)],
+            ])}
+        </div>
+        <div id="file-latest"></div>
+        <div id="file-chunks"></div>`;
+
+    // Later modify operations point back at this PIN; show the newest version if it differs
+    const latest = await apiOrNull(`/files/latest/${encodeURIComponent(file.pin_id)}`).catch(() => null);
+    if (latest && latest.pin_id && latest.pin_id !== file.pin_id) {
+        document.getElementById('file-latest').innerHTML = `
+            <h3>Latest version</h3>
+            <div class="grid">${renderFileCard(latest)}</div>`;
+    }
+
+    // Answers invalid-param (null) unless the PIN is a multi-chunk file index
+    const chunks = await apiOrNull(`/files/${encodeURIComponent(file.pin_id)}/chunks`).catch(() => null);
+    if (chunks) renderChunks(chunks);
+}
+
+function renderChunks(result) {
+    const rows = (result.chunks || []).map(chunk => `
+        <tr>
+            <td>${escapeHtml(chunk.index)}</td>
+            <td>${escapeHtml(shorten(chunk.pinId, 10))}</td>
+            <td>${escapeHtml(formatSize(chunk.size))}</td>
+            <td>${escapeHtml(chunk.status)}${chunk.statusReason ? `: ${escapeHtml(chunk.statusReason)}` : ''}</td>
+            <td>${chunk.confirmed ? 'yes' : 'no'}</td>
+            <td>${chunk.hashMatch ? 'yes' : 'no'}</td>
+        </tr>`).join('');
+    document.getElementById('file-chunks').innerHTML = `
+        <h3>Chunks (${escapeHtml(result.chunksIndexed)}/${escapeHtml(result.chunkNumber)} indexed, ${escapeHtml(result.mergeStatus)})</h3>
+        <div class="panel">
+            ${result.mergeError ? `<p class="error">${escapeHtml(result.mergeError)}</p>` : ''}
+            <table class="list">
+                <tr><th>#</th><th>PIN ID</th><th>Size</th><th>Status</th><th>Confirmed</th><th>Hash match</th></tr>
+                ${rows}
+            </table>
+        </div>`;
+}
+
+function renderHistory(title, items, describe) {
+    if (!items || !items.length) return '';
+    const rows = items.map(item => `
+        <tr>
+            <td>${escapeHtml(formatTime(item.timestamp))}</td>
+            <td>${describe(item)}</td>
+            <td>${escapeHtml(item.chainName)} ${escapeHtml(item.blockHeight || 'mempool')}</td>
+            <td>${escapeHtml(shorten(item.pinId, 10))}</td>
+        </tr>`).join('');
+    return `<h3>${escapeHtml(title)}</h3><div class="panel"><table class="list">${rows}</table></div>`;
+}
+
+async function showUser(key) {
+    view.innerHTML = '<p class="muted">Loading…</p>';
+    const isMetaId = /^[0-9a-f]{64}$/i.test(key);
+    const user = await apiOrNull(isMetaId
+        ? `/users/metaid/${encodeURIComponent(key)}`
+        : `/users/address/${encodeURIComponent(key)}`);
+    if (!user) {
+        view.innerHTML = `<div class="panel">No user found for <code>${escapeHtml(key)}</code>.</div>`;
+        if (!isMetaId) {
+            view.insertAdjacentHTML('beforeend', '<h3>Files created by this address</h3>');
+            await renderFileList(view, cursorPager(`/files/creator/${encodeURIComponent(key)}`));
+        }
+        return;
+    }
+
+    const avatar = user.avatarPinId
+        ? `<img class="avatar" src="${escapeHtml(contentUrl(user.avatarPinId))}" alt="">`
+        : '<div class="avatar"></div>';
+    view.innerHTML = `
+        <div class="panel">
+            ${avatar}
+            <h2>${escapeHtml(user.name || shorten(user.address, 8))}</h2>
+            ${kvTable([
+                ['MetaID', escapeHtml(user.metaId)],
+                ['Global MetaID', escapeHtml(user.globalMetaId)],
+                ['Address', escapeHtml(user.address)],
+                ['Bio', escapeHtml(typeof user.bio === 'string' ? user.bio : JSON.stringify(user.bio ?? ''))],
+                ['Chain', escapeHtml(user.chainName)],
+                ['Block height', escapeHtml(user.blockHeight)],
+            ])}
+        </div>
+        <div id="user-history"></div>
+        <h3>Files</h3>
+        <div id="user-files"></div>`;
+
+    const history = await apiOrNull(`/users/history/${encodeURIComponent(user.metaId)}`).catch(() => null);
+    if (history) {
+        document.getElementById('user-history').innerHTML =
+            renderHistory('Name history', history.nameHistory, item => escapeHtml(item.name)) +
+            renderHistory('Avatar history', history.avatarHistory,
+                item => `<a href="#/file/${encodeURIComponent(item.pinId)}">view</a>`) +
+            renderHistory('Bio history', history.bioHistory,
+                item => escapeHtml(typeof item.bio === 'string' ? item.bio : JSON.stringify(item.bio)));
+    }
+
+    await renderFileList(document.getElementById('user-files'),
+        cursorPager(`/files/metaid/${encodeURIComponent(user.metaId)}`));
+}
+
+async function showSearch(query) {
+    query = query.trim();
+    if (/^mfs:\/\//i.test(query)) {
+        const resolved = await apiOrNull(`/resolve?mode=json&uri=${encodeURIComponent(query)}`);
+        if (!resolved) {
+            view.innerHTML = `<div class="panel">Nothing indexed for <code>${escapeHtml(query)}</code>.</div>`;
+            return;
+        }
+        location.replace(`#/file/${encodeURIComponent(resolved.file.pin_id)}`);
+        return;
+    }
+    if (/^[0-9a-f]{64}i\d+$/i.test(query)) {
+        location.replace(`#/file/${encodeURIComponent(query.toLowerCase())}`);
+        return;
+    }
+    if (/^[0-9a-f]{64}$/i.test(query) || /^[13mn2][1-9A-HJ-NP-Za-km-z]{25,34}$/.test(query) || /^(bc1|tb1)[0-9a-z]{20,}$/i.test(query)) {
+        location.replace(`#/user/${encodeURIComponent(query)}`);
+        return;
+    }
+    if (/^id[0-9a-z]{20,}$/i.test(query)) {
+        view.innerHTML = `<h2>Files of ${escapeHtml(shorten(query, 10))}</h2>`;
+        await renderFileList(view, cursorPager(`/files/metaid/${encodeURIComponent(query)}`));
+        return;
+    }
+
+    view.innerHTML = `<h2>Files matching “${escapeHtml(query)}”</h2>`;
+    await renderFileList(view, async timestamp => {
+        const data = await api(`/files/keyword/${encodeURIComponent(query)}/extension` +
+            `?extension=${encodeURIComponent(SEARCH_EXTENSIONS)}&size=${PAGE_SIZE}` +
+            (timestamp ? `&timestamp=${timestamp}` : ''));
+        return { files: data.files || [], next: data.next_timestamp, hasMore: data.has_more };
+    });
+}
+
+async function loadStatus() {
+    try {
+        const [status, stats] = await Promise.all([api('/status'), api('/stats')]);
+        const chains = (status.chains || []).map(chain =>
+            `${escapeHtml(chain.chain_name)} ${escapeHtml(chain.current_sync_height)}/${escapeHtml(chain.latest_block_height)}`);
+        document.getElementById('status-bar').innerHTML =
+            `${escapeHtml(stats.total_files)} files indexed · ${chains.join(' · ')}`;
+    } catch (err) {
+        document.getElementById('status-bar').textContent = 'Status unavailable';
+    }
+}
+
+async function route() {
+    const [, page = '', ...rest] = location.hash.split('/');
+    const arg = decodeURIComponent(rest.join('/'));
+    try {
+        switch (page) {
+            case 'file':
+                await showFile(arg);
+                break;
+            case 'user':
+                await showUser(arg);
+                break;
+            case 'search':
+                document.getElementById('search-input').value = arg;
+                await showSearch(arg);
+                break;
+            default:
+                await showRecent();
+        }
+    } catch (err) {
+        showError(err);
+    }
+}
+
+document.getElementById('search-form').addEventListener('submit', event => {
+    event.preventDefault();
+    const query = document.getElementById('search-input').value.trim();
+    if (query) location.hash = `#/search/${encodeURIComponent(query)}`;
+});
+
+window.addEventListener('hashchange', route);
+loadStatus();
+route();
@@ -0,0 +1,21 @@
+// Package explorer embeds the indexer's built-in explorer UI: a small static
+// client of the indexer API for browsing recent files, searching and looking
+// up files and users.
+package explorer
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// FS returns the explorer assets (index.html, explorer.js, explorer.css) at its root
+func FS() fs.FS {
+	sub, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err) // "assets" is embedded above
+	}
+	return sub
+}